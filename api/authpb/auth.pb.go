@@ -24,24 +24,81 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+// AdminPermission is a cluster administration capability, granted to the
+// roles instead of the root role.
+type AdminPermission int32
+
+const (
+	// MEMBER allows adding, removing, updating and promoting members.
+	MEMBER AdminPermission = 0
+	// ALARM allows activating and deactivating alarms.
+	ALARM AdminPermission = 1
+	// DEFRAGMENT allows defragmenting the backends of the members.
+	DEFRAGMENT AdminPermission = 2
+	// SNAPSHOT allows streaming snapshots of the backends of the members.
+	SNAPSHOT AdminPermission = 3
+	// AUTH allows managing the users and roles, and enabling and disabling
+	// authentication. Since it allows granting the root role, it is as
+	// powerful as root.
+	AUTH AdminPermission = 4
+	// MAINTENANCE allows the other maintenance operations, as hashing the
+	// backends and changing the log level.
+	MAINTENANCE AdminPermission = 5
+)
+
+var AdminPermission_name = map[int32]string{
+	0: "MEMBER",
+	1: "ALARM",
+	2: "DEFRAGMENT",
+	3: "SNAPSHOT",
+	4: "AUTH",
+	5: "MAINTENANCE",
+}
+
+var AdminPermission_value = map[string]int32{
+	"MEMBER":      0,
+	"ALARM":       1,
+	"DEFRAGMENT":  2,
+	"SNAPSHOT":    3,
+	"AUTH":        4,
+	"MAINTENANCE": 5,
+}
+
+func (x AdminPermission) String() string {
+	return proto.EnumName(AdminPermission_name, int32(x))
+}
+
+func (AdminPermission) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_8bbd6f3875b0e874, []int{0}
+}
+
 type Permission_Type int32
 
 const (
+	// READ allows ranges and watches.
 	READ      Permission_Type = 0
 	WRITE     Permission_Type = 1
 	READWRITE Permission_Type = 2
+	// WATCH allows watches only.
+	WATCH Permission_Type = 3
+	// RANGE allows ranges only, without watches.
+	RANGE Permission_Type = 4
 )
 
 var Permission_Type_name = map[int32]string{
 	0: "READ",
 	1: "WRITE",
 	2: "READWRITE",
+	3: "WATCH",
+	4: "RANGE",
 }
 
 var Permission_Type_value = map[string]int32{
 	"READ":      0,
 	"WRITE":     1,
 	"READWRITE": 2,
+	"WATCH":     3,
+	"RANGE":     4,
 }
 
 func (x Permission_Type) String() string {
@@ -49,11 +106,14 @@ func (x Permission_Type) String() string {
 }
 
 func (Permission_Type) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_8bbd6f3875b0e874, []int{2, 0}
+	return fileDescriptor_8bbd6f3875b0e874, []int{3, 0}
 }
 
 type UserAddOptions struct {
-	NoPassword           bool     `protobuf:"varint,1,opt,name=no_password,json=noPassword,proto3" json:"no_password,omitempty"`
+	NoPassword bool `protobuf:"varint,1,opt,name=no_password,json=noPassword,proto3" json:"no_password,omitempty"`
+	// home_prefix, if set, scopes relative keys (keys not starting with '/')
+	// of the user under the given prefix.
+	HomePrefix           string   `protobuf:"bytes,2,opt,name=home_prefix,json=homePrefix,proto3" json:"home_prefix,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -94,13 +154,19 @@ var xxx_messageInfo_UserAddOptions proto.InternalMessageInfo
 
 // User is a single entry in the bucket authUsers
 type User struct {
-	Name                 []byte          `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Password             []byte          `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
-	Roles                []string        `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
-	Options              *UserAddOptions `protobuf:"bytes,4,opt,name=options,proto3" json:"options,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
-	XXX_unrecognized     []byte          `json:"-"`
-	XXX_sizecache        int32           `json:"-"`
+	Name     []byte          `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Password []byte          `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	Roles    []string        `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
+	Options  *UserAddOptions `protobuf:"bytes,4,opt,name=options,proto3" json:"options,omitempty"`
+	// password_changed_at is the time the password was last set, in seconds
+	// since the unix epoch, or zero if unknown.
+	PasswordChangedAt int64 `protobuf:"varint,5,opt,name=password_changed_at,json=passwordChangedAt,proto3" json:"password_changed_at,omitempty"`
+	// role_expirations are the expirations of the roles granted until a time,
+	// sorted by role.
+	RoleExpirations      []*RoleExpiration `protobuf:"bytes,6,rep,name=role_expirations,json=roleExpirations,proto3" json:"role_expirations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *User) Reset()         { *m = User{} }
@@ -136,6 +202,49 @@ func (m *User) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_User proto.InternalMessageInfo
 
+// RoleExpiration is the time a role granted to a user is revoked at.
+type RoleExpiration struct {
+	Role string `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	// expires_at is in seconds since the unix epoch.
+	ExpiresAt            int64    `protobuf:"varint,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RoleExpiration) Reset()         { *m = RoleExpiration{} }
+func (m *RoleExpiration) String() string { return proto.CompactTextString(m) }
+func (*RoleExpiration) ProtoMessage()    {}
+func (*RoleExpiration) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8bbd6f3875b0e874, []int{2}
+}
+func (m *RoleExpiration) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RoleExpiration) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RoleExpiration.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RoleExpiration) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RoleExpiration.Merge(m, src)
+}
+func (m *RoleExpiration) XXX_Size() int {
+	return m.Size()
+}
+func (m *RoleExpiration) XXX_DiscardUnknown() {
+	xxx_messageInfo_RoleExpiration.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RoleExpiration proto.InternalMessageInfo
+
 // Permission is a single entity
 type Permission struct {
 	PermType             Permission_Type `protobuf:"varint,1,opt,name=permType,proto3,enum=authpb.Permission_Type" json:"permType,omitempty"`
@@ -150,7 +259,7 @@ func (m *Permission) Reset()         { *m = Permission{} }
 func (m *Permission) String() string { return proto.CompactTextString(m) }
 func (*Permission) ProtoMessage()    {}
 func (*Permission) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8bbd6f3875b0e874, []int{2}
+	return fileDescriptor_8bbd6f3875b0e874, []int{3}
 }
 func (m *Permission) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -181,18 +290,19 @@ var xxx_messageInfo_Permission proto.InternalMessageInfo
 
 // Role is a single entry in the bucket authRoles
 type Role struct {
-	Name                 []byte        `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	KeyPermission        []*Permission `protobuf:"bytes,2,rep,name=keyPermission,proto3" json:"keyPermission,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
-	XXX_unrecognized     []byte        `json:"-"`
-	XXX_sizecache        int32         `json:"-"`
+	Name                 []byte            `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	KeyPermission        []*Permission     `protobuf:"bytes,2,rep,name=keyPermission,proto3" json:"keyPermission,omitempty"`
+	AdminPermission      []AdminPermission `protobuf:"varint,3,rep,packed,name=adminPermission,proto3,enum=authpb.AdminPermission" json:"adminPermission,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *Role) Reset()         { *m = Role{} }
 func (m *Role) String() string { return proto.CompactTextString(m) }
 func (*Role) ProtoMessage()    {}
 func (*Role) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8bbd6f3875b0e874, []int{3}
+	return fileDescriptor_8bbd6f3875b0e874, []int{4}
 }
 func (m *Role) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -222,9 +332,11 @@ func (m *Role) XXX_DiscardUnknown() {
 var xxx_messageInfo_Role proto.InternalMessageInfo
 
 func init() {
+	proto.RegisterEnum("authpb.AdminPermission", AdminPermission_name, AdminPermission_value)
 	proto.RegisterEnum("authpb.Permission_Type", Permission_Type_name, Permission_Type_value)
 	proto.RegisterType((*UserAddOptions)(nil), "authpb.UserAddOptions")
 	proto.RegisterType((*User)(nil), "authpb.User")
+	proto.RegisterType((*RoleExpiration)(nil), "authpb.RoleExpiration")
 	proto.RegisterType((*Permission)(nil), "authpb.Permission")
 	proto.RegisterType((*Role)(nil), "authpb.Role")
 }
@@ -232,29 +344,41 @@ func init() {
 func init() { proto.RegisterFile("auth.proto", fileDescriptor_8bbd6f3875b0e874) }
 
 var fileDescriptor_8bbd6f3875b0e874 = []byte{
-	// 338 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x91, 0xcf, 0x4e, 0xea, 0x40,
-	0x14, 0xc6, 0x3b, 0xb4, 0x70, 0xdb, 0xc3, 0x85, 0x90, 0x13, 0x72, 0x6f, 0x83, 0x49, 0x6d, 0xba,
-	0x6a, 0x5c, 0x54, 0x85, 0x8d, 0x5b, 0x8c, 0x2c, 0x5c, 0x49, 0x26, 0x18, 0x97, 0xa4, 0xa4, 0x13,
-	0x24, 0xc0, 0x4c, 0x33, 0x83, 0x31, 0x6c, 0x7c, 0x0e, 0x17, 0x3e, 0x10, 0x4b, 0x1e, 0x41, 0xf0,
-	0x45, 0x4c, 0x67, 0xf8, 0x13, 0xa2, 0xbb, 0xef, 0x7c, 0xe7, 0xfb, 0x66, 0x7e, 0x99, 0x01, 0x48,
-	0x5f, 0x16, 0xcf, 0x49, 0x2e, 0xc5, 0x42, 0x60, 0xa5, 0xd0, 0xf9, 0xa8, 0xd5, 0x1c, 0x8b, 0xb1,
-	0xd0, 0xd6, 0x65, 0xa1, 0xcc, 0x36, 0xba, 0x86, 0xfa, 0xa3, 0x62, 0xb2, 0x9b, 0x65, 0x0f, 0xf9,
-	0x62, 0x22, 0xb8, 0xc2, 0x73, 0xa8, 0x72, 0x31, 0xcc, 0x53, 0xa5, 0x5e, 0x85, 0xcc, 0x7c, 0x12,
-	0x92, 0xd8, 0xa5, 0xc0, 0x45, 0x7f, 0xe7, 0x44, 0x6f, 0xe0, 0x14, 0x15, 0x44, 0x70, 0x78, 0x3a,
-	0x67, 0x3a, 0xf1, 0x97, 0x6a, 0x8d, 0x2d, 0x70, 0x0f, 0xcd, 0x92, 0xf6, 0x0f, 0x33, 0x36, 0xa1,
-	0x2c, 0xc5, 0x8c, 0x29, 0xdf, 0x0e, 0xed, 0xd8, 0xa3, 0x66, 0xc0, 0x2b, 0xf8, 0x23, 0xcc, 0xcd,
-	0xbe, 0x13, 0x92, 0xb8, 0xda, 0xfe, 0x97, 0x18, 0xe0, 0xe4, 0x94, 0x8b, 0xee, 0x63, 0xd1, 0x07,
-	0x01, 0xe8, 0x33, 0x39, 0x9f, 0x28, 0x35, 0x11, 0x1c, 0x3b, 0xe0, 0xe6, 0x4c, 0xce, 0x07, 0xcb,
-	0xdc, 0xa0, 0xd4, 0xdb, 0xff, 0xf7, 0x27, 0x1c, 0x53, 0x49, 0xb1, 0xa6, 0x87, 0x20, 0x36, 0xc0,
-	0x9e, 0xb2, 0xe5, 0x0e, 0xb1, 0x90, 0x78, 0x06, 0x9e, 0x4c, 0xf9, 0x98, 0x0d, 0x19, 0xcf, 0x7c,
-	0xdb, 0xa0, 0x6b, 0xa3, 0xc7, 0xb3, 0xe8, 0x02, 0x1c, 0x5d, 0x73, 0xc1, 0xa1, 0xbd, 0xee, 0x5d,
-	0xc3, 0x42, 0x0f, 0xca, 0x4f, 0xf4, 0x7e, 0xd0, 0x6b, 0x10, 0xac, 0x81, 0x57, 0x98, 0x66, 0x2c,
-	0x45, 0x03, 0x70, 0xa8, 0x98, 0xb1, 0x5f, 0x9f, 0xe7, 0x06, 0x6a, 0x53, 0xb6, 0x3c, 0x62, 0xf9,
-	0xa5, 0xd0, 0x8e, 0xab, 0x6d, 0xfc, 0x09, 0x4c, 0x4f, 0x83, 0xb7, 0xfe, 0x6a, 0x13, 0x58, 0xeb,
-	0x4d, 0x60, 0xad, 0xb6, 0x01, 0x59, 0x6f, 0x03, 0xf2, 0xb9, 0x0d, 0xc8, 0xfb, 0x57, 0x60, 0x8d,
-	0x2a, 0xfa, 0x23, 0x3b, 0xdf, 0x01, 0x00, 0x00, 0xff, 0xff, 0x61, 0x66, 0xc6, 0x9d, 0xf4, 0x01,
-	0x00, 0x00,
+	// 540 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x53, 0xc1, 0x6e, 0xd3, 0x4a,
+	0x14, 0xcd, 0xd8, 0x4e, 0x5e, 0x7c, 0xd3, 0x26, 0xf3, 0x86, 0x0a, 0xac, 0x22, 0x8c, 0xe5, 0x55,
+	0xc4, 0x22, 0xa0, 0x76, 0xc3, 0x92, 0x69, 0x3a, 0x34, 0x95, 0x88, 0x1b, 0x4d, 0x5d, 0xb1, 0xb4,
+	0x5c, 0x3c, 0x24, 0x51, 0x1b, 0x8f, 0x65, 0x1b, 0xd1, 0x7c, 0x09, 0x7c, 0x07, 0x5f, 0xd1, 0x65,
+	0x3f, 0x81, 0x86, 0x2f, 0xe0, 0x0f, 0xd0, 0x8c, 0x93, 0x34, 0x29, 0xec, 0xee, 0x3d, 0xe7, 0xcc,
+	0xbd, 0xe7, 0xdc, 0xc4, 0x00, 0xf1, 0x97, 0x72, 0xd2, 0xcb, 0x72, 0x59, 0x4a, 0xd2, 0x50, 0x75,
+	0x76, 0xb9, 0xbf, 0x37, 0x96, 0x63, 0xa9, 0xa1, 0xd7, 0xaa, 0xaa, 0x58, 0x9f, 0x43, 0xfb, 0xa2,
+	0x10, 0x39, 0x4d, 0x92, 0xb3, 0xac, 0x9c, 0xca, 0xb4, 0x20, 0x2f, 0xa1, 0x95, 0xca, 0x28, 0x8b,
+	0x8b, 0xe2, 0xab, 0xcc, 0x13, 0x07, 0x79, 0xa8, 0xdb, 0xe4, 0x90, 0xca, 0xd1, 0x12, 0x51, 0x82,
+	0x89, 0x9c, 0x89, 0x28, 0xcb, 0xc5, 0xe7, 0xe9, 0x8d, 0x63, 0x78, 0xa8, 0x6b, 0x73, 0x50, 0xd0,
+	0x48, 0x23, 0xfe, 0x6f, 0x04, 0x96, 0x1a, 0x4a, 0x08, 0x58, 0x69, 0x3c, 0x13, 0x7a, 0xc6, 0x0e,
+	0xd7, 0x35, 0xd9, 0x87, 0xe6, 0x7a, 0xb6, 0xa1, 0xf1, 0x75, 0x4f, 0xf6, 0xa0, 0x9e, 0xcb, 0x6b,
+	0x51, 0x38, 0xa6, 0x67, 0x76, 0x6d, 0x5e, 0x35, 0xe4, 0x0d, 0xfc, 0x27, 0x2b, 0x6f, 0x8e, 0xe5,
+	0xa1, 0x6e, 0xeb, 0xe0, 0x69, 0xaf, 0x8a, 0xd4, 0xdb, 0x76, 0xce, 0x57, 0x32, 0xd2, 0x83, 0x27,
+	0xab, 0x99, 0xd1, 0xa7, 0x49, 0x9c, 0x8e, 0x45, 0x12, 0xc5, 0xa5, 0x53, 0xf7, 0x50, 0xd7, 0xe4,
+	0xff, 0xaf, 0xa8, 0x7e, 0xc5, 0xd0, 0x92, 0x50, 0xc0, 0x6a, 0x55, 0x24, 0x6e, 0xb2, 0x69, 0x1e,
+	0x57, 0xab, 0x1a, 0x9e, 0xb9, 0xb9, 0x8a, 0xcb, 0x6b, 0xc1, 0xd6, 0x34, 0xef, 0xe4, 0x5b, 0x7d,
+	0xe1, 0xf7, 0xa1, 0xbd, 0x2d, 0x51, 0xe1, 0x95, 0x48, 0x87, 0xb7, 0xb9, 0xae, 0xc9, 0x0b, 0x00,
+	0xbd, 0x43, 0x14, 0xca, 0x8f, 0xa1, 0xfd, 0xd8, 0x4b, 0x84, 0x96, 0xfe, 0x0f, 0x04, 0x30, 0x12,
+	0xf9, 0x6c, 0x5a, 0x14, 0x6a, 0xc2, 0x21, 0x34, 0x33, 0x91, 0xcf, 0xc2, 0x79, 0x56, 0x4d, 0x69,
+	0x1f, 0x3c, 0x5b, 0xd9, 0x79, 0x50, 0xf5, 0x14, 0xcd, 0xd7, 0x42, 0x82, 0xc1, 0xbc, 0x12, 0xf3,
+	0xe5, 0x69, 0x55, 0x49, 0x9e, 0x83, 0x9d, 0xab, 0xa4, 0x91, 0x48, 0x13, 0xc7, 0xac, 0x4e, 0xae,
+	0x01, 0x96, 0x26, 0xfe, 0x3b, 0xb0, 0xf4, 0xb3, 0x26, 0x58, 0x9c, 0xd1, 0x63, 0x5c, 0x23, 0x36,
+	0xd4, 0x3f, 0xf2, 0xd3, 0x90, 0x61, 0x44, 0x76, 0xc1, 0x56, 0x60, 0xd5, 0x1a, 0x9a, 0xa1, 0x61,
+	0x7f, 0x80, 0x4d, 0x55, 0x72, 0x1a, 0x9c, 0x30, 0x6c, 0xf9, 0xdf, 0x10, 0x58, 0x2a, 0xfa, 0x3f,
+	0x7f, 0xed, 0xb7, 0xb0, 0x7b, 0x25, 0xe6, 0x0f, 0x6e, 0x1d, 0x43, 0x9f, 0x95, 0xfc, 0x9d, 0x83,
+	0x6f, 0x0b, 0x09, 0x85, 0x4e, 0x9c, 0xcc, 0xa6, 0xe9, 0xc6, 0x5b, 0xf5, 0xaf, 0xd8, 0xb8, 0x01,
+	0xdd, 0xa6, 0xf9, 0x63, 0xfd, 0xab, 0x18, 0x3a, 0x8f, 0x34, 0x04, 0xa0, 0x31, 0x64, 0xc3, 0x23,
+	0xc6, 0xab, 0xa0, 0xf4, 0x03, 0xe5, 0x43, 0x8c, 0x48, 0x1b, 0xe0, 0x98, 0xbd, 0xe7, 0xf4, 0x64,
+	0xc8, 0x82, 0x10, 0x1b, 0x64, 0x07, 0x9a, 0xe7, 0x01, 0x1d, 0x9d, 0x0f, 0xce, 0x42, 0x6c, 0xaa,
+	0xdb, 0xd0, 0x8b, 0x70, 0x80, 0x2d, 0xd2, 0x81, 0xd6, 0x90, 0x9e, 0x06, 0x21, 0x0b, 0x68, 0xd0,
+	0x67, 0xb8, 0x7e, 0xe4, 0xdc, 0xde, 0xbb, 0xb5, 0xbb, 0x7b, 0xb7, 0x76, 0xbb, 0x70, 0xd1, 0xdd,
+	0xc2, 0x45, 0x3f, 0x17, 0x2e, 0xfa, 0xfe, 0xcb, 0xad, 0x5d, 0x36, 0xf4, 0xf7, 0x75, 0xf8, 0x27,
+	0x00, 0x00, 0xff, 0xff, 0x97, 0x22, 0xa9, 0xdf, 0x8b, 0x03, 0x00, 0x00,
 }
 
 func (m *UserAddOptions) Marshal() (dAtA []byte, err error) {
@@ -281,6 +405,13 @@ func (m *UserAddOptions) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.HomePrefix) > 0 {
+		i -= len(m.HomePrefix)
+		copy(dAtA[i:], m.HomePrefix)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.HomePrefix)))
+		i--
+		dAtA[i] = 0x12
+	}
 	if m.NoPassword {
 		i--
 		if m.NoPassword {
@@ -318,6 +449,25 @@ func (m *User) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.RoleExpirations) > 0 {
+		for iNdEx := len(m.RoleExpirations) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.RoleExpirations[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintAuth(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x32
+		}
+	}
+	if m.PasswordChangedAt != 0 {
+		i = encodeVarintAuth(dAtA, i, uint64(m.PasswordChangedAt))
+		i--
+		dAtA[i] = 0x28
+	}
 	if m.Options != nil {
 		{
 			size, err := m.Options.MarshalToSizedBuffer(dAtA[:i])
@@ -356,6 +506,45 @@ func (m *User) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *RoleExpiration) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RoleExpiration) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RoleExpiration) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.ExpiresAt != 0 {
+		i = encodeVarintAuth(dAtA, i, uint64(m.ExpiresAt))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Role) > 0 {
+		i -= len(m.Role)
+		copy(dAtA[i:], m.Role)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.Role)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *Permission) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -426,6 +615,24 @@ func (m *Role) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.AdminPermission) > 0 {
+		dAtA3 := make([]byte, len(m.AdminPermission)*10)
+		var j2 int
+		for _, num := range m.AdminPermission {
+			for num >= 1<<7 {
+				dAtA3[j2] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j2++
+			}
+			dAtA3[j2] = uint8(num)
+			j2++
+		}
+		i -= j2
+		copy(dAtA[i:], dAtA3[:j2])
+		i = encodeVarintAuth(dAtA, i, uint64(j2))
+		i--
+		dAtA[i] = 0x1a
+	}
 	if len(m.KeyPermission) > 0 {
 		for iNdEx := len(m.KeyPermission) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -470,6 +677,10 @@ func (m *UserAddOptions) Size() (n int) {
 	if m.NoPassword {
 		n += 2
 	}
+	l = len(m.HomePrefix)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -500,6 +711,34 @@ func (m *User) Size() (n int) {
 		l = m.Options.Size()
 		n += 1 + l + sovAuth(uint64(l))
 	}
+	if m.PasswordChangedAt != 0 {
+		n += 1 + sovAuth(uint64(m.PasswordChangedAt))
+	}
+	if len(m.RoleExpirations) > 0 {
+		for _, e := range m.RoleExpirations {
+			l = e.Size()
+			n += 1 + l + sovAuth(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RoleExpiration) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Role)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	if m.ExpiresAt != 0 {
+		n += 1 + sovAuth(uint64(m.ExpiresAt))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -545,6 +784,13 @@ func (m *Role) Size() (n int) {
 			n += 1 + l + sovAuth(uint64(l))
 		}
 	}
+	if len(m.AdminPermission) > 0 {
+		l = 0
+		for _, e := range m.AdminPermission {
+			l += sovAuth(uint64(e))
+		}
+		n += 1 + sovAuth(uint64(l)) + l
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -606,6 +852,38 @@ func (m *UserAddOptions) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.NoPassword = bool(v != 0)
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HomePrefix", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.HomePrefix = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAuth(dAtA[iNdEx:])
@@ -793,6 +1071,161 @@ func (m *User) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PasswordChangedAt", wireType)
+			}
+			m.PasswordChangedAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.PasswordChangedAt |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RoleExpirations", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RoleExpirations = append(m.RoleExpirations, &RoleExpiration{})
+			if err := m.RoleExpirations[len(m.RoleExpirations)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAuth(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RoleExpiration) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAuth
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RoleExpiration: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RoleExpiration: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Role", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Role = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpiresAt", wireType)
+			}
+			m.ExpiresAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ExpiresAt |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAuth(dAtA[iNdEx:])
@@ -1050,6 +1483,75 @@ func (m *Role) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType == 0 {
+				var v AdminPermission
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowAuth
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= AdminPermission(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.AdminPermission = append(m.AdminPermission, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowAuth
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthAuth
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthAuth
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				if elementCount != 0 && len(m.AdminPermission) == 0 {
+					m.AdminPermission = make([]AdminPermission, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v AdminPermission
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowAuth
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= AdminPermission(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.AdminPermission = append(m.AdminPermission, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field AdminPermission", wireType)
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAuth(dAtA[iNdEx:])
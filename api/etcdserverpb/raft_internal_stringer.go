@@ -40,6 +40,11 @@ func (as *InternalRaftStringer) String() string {
 			as.Request.Header.String(),
 			as.Request.LeaseRevoke.ID,
 		)
+	case as.Request.LeaseExpire != nil:
+		return fmt.Sprintf("header:<%s> lease_expire:<id:%016x>",
+			as.Request.Header.String(),
+			as.Request.LeaseExpire.ID,
+		)
 	case as.Request.Authenticate != nil:
 		return fmt.Sprintf("header:<%s> authenticate:<name:%s simple_token:%s>",
 			as.Request.Header.String(),
@@ -61,6 +66,14 @@ func (as *InternalRaftStringer) String() string {
 			as.Request.Header.String(),
 			NewLoggablePutRequest(as.Request.Put).String(),
 		)
+	case as.Request.PutChunk != nil:
+		return fmt.Sprintf("header:<%s> put_chunk:<upload_id:%016x index:%d data_size:%d expire_time:%d>",
+			as.Request.Header.String(),
+			as.Request.PutChunk.UploadId,
+			as.Request.PutChunk.Index,
+			len(as.Request.PutChunk.Data),
+			as.Request.PutChunk.ExpireTime,
+		)
 	case as.Request.Txn != nil:
 		return fmt.Sprintf("header:<%s> txn:<%s>",
 			as.Request.Header.String(),
@@ -165,6 +178,8 @@ type loggablePutRequest struct {
 	PrevKv      bool   `protobuf:"varint,4,opt,name=prev_kv,proto3"`
 	IgnoreValue bool   `protobuf:"varint,5,opt,name=ignore_value,proto3"`
 	IgnoreLease bool   `protobuf:"varint,6,opt,name=ignore_lease,proto3"`
+	Ttl         int64  `protobuf:"varint,7,opt,name=ttl,proto3"`
+	ExpireTime  int64  `protobuf:"varint,8,opt,name=expire_time,proto3"`
 }
 
 func NewLoggablePutRequest(request *PutRequest) *loggablePutRequest {
@@ -175,6 +190,8 @@ func NewLoggablePutRequest(request *PutRequest) *loggablePutRequest {
 		request.PrevKv,
 		request.IgnoreValue,
 		request.IgnoreLease,
+		request.Ttl,
+		request.ExpireTime,
 	}
 }
 
@@ -71,8 +71,16 @@ var xxx_messageInfo_RaftAttributes proto.InternalMessageInfo
 
 // Attributes represents all the non-raft related attributes of an etcd member.
 type Attributes struct {
-	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	ClientUrls           []string `protobuf:"bytes,2,rep,name=client_urls,json=clientUrls,proto3" json:"client_urls,omitempty"`
+	Name       string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ClientUrls []string `protobuf:"bytes,2,rep,name=client_urls,json=clientUrls,proto3" json:"client_urls,omitempty"`
+	// election_priority is the priority of the member to be the leader; the
+	// leader hands its leadership over to a member of a higher priority.
+	ElectionPriority int64 `protobuf:"varint,3,opt,name=election_priority,json=electionPriority,proto3" json:"election_priority,omitempty"`
+	// labels are the labels of the member, as its zone.
+	Labels map[string]string `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// wal_durability is the durability of the raft log writes of the member if
+	// relaxed, "batched" or "none"; empty if fsynced on every write.
+	WalDurability        string   `protobuf:"bytes,5,opt,name=wal_durability,json=walDurability,proto3" json:"wal_durability,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -278,6 +286,7 @@ var xxx_messageInfo_DowngradeInfoSetRequest proto.InternalMessageInfo
 func init() {
 	proto.RegisterType((*RaftAttributes)(nil), "membershippb.RaftAttributes")
 	proto.RegisterType((*Attributes)(nil), "membershippb.Attributes")
+	proto.RegisterMapType((map[string]string)(nil), "membershippb.Attributes.LabelsEntry")
 	proto.RegisterType((*Member)(nil), "membershippb.Member")
 	proto.RegisterType((*ClusterVersionSetRequest)(nil), "membershippb.ClusterVersionSetRequest")
 	proto.RegisterType((*ClusterMemberAttrSetRequest)(nil), "membershippb.ClusterMemberAttrSetRequest")
@@ -287,33 +296,40 @@ func init() {
 func init() { proto.RegisterFile("membership.proto", fileDescriptor_949fe0d019050ef5) }
 
 var fileDescriptor_949fe0d019050ef5 = []byte{
-	// 401 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x52, 0xcd, 0xae, 0xd2, 0x40,
-	0x14, 0xbe, 0xd3, 0xde, 0xdc, 0xdb, 0x9e, 0x6b, 0x10, 0x27, 0x24, 0x36, 0xa0, 0xb5, 0x61, 0xc5,
-	0xaa, 0x24, 0x12, 0x36, 0xee, 0x54, 0x58, 0x60, 0xc4, 0xc5, 0x18, 0xdc, 0x92, 0x29, 0x1c, 0xb0,
-	0x49, 0x69, 0xeb, 0xcc, 0x14, 0xf7, 0x2e, 0x7d, 0x02, 0xdf, 0xc2, 0x95, 0xef, 0xc0, 0xd2, 0x47,
-	0x50, 0x7c, 0x11, 0xd3, 0x99, 0x42, 0x4b, 0x74, 0x75, 0x77, 0xa7, 0x5f, 0xcf, 0xf9, 0xfe, 0x5a,
-	0x68, 0xef, 0x70, 0x17, 0xa1, 0x90, 0x1f, 0xe3, 0x3c, 0xcc, 0x45, 0xa6, 0x32, 0xfa, 0xa0, 0x46,
-	0xf2, 0xa8, 0xdb, 0xd9, 0x66, 0xdb, 0x4c, 0xbf, 0x18, 0x96, 0x93, 0xd9, 0xe9, 0x06, 0xa8, 0x56,
-	0xeb, 0x21, 0xcf, 0xe3, 0xe1, 0x1e, 0x85, 0x8c, 0xb3, 0x34, 0x8f, 0x4e, 0x93, 0xd9, 0xe8, 0x2f,
-	0xa0, 0xc5, 0xf8, 0x46, 0xbd, 0x54, 0x4a, 0xc4, 0x51, 0xa1, 0x50, 0xd2, 0x1e, 0xb8, 0x39, 0xa2,
-	0x58, 0x16, 0x22, 0x91, 0x1e, 0x09, 0xec, 0x81, 0xcb, 0x9c, 0x12, 0x58, 0x88, 0x44, 0xd2, 0xa7,
-	0x00, 0xb1, 0x5c, 0x26, 0xc8, 0x45, 0x8a, 0xc2, 0xb3, 0x02, 0x32, 0x70, 0x98, 0x1b, 0xcb, 0xb7,
-	0x06, 0x78, 0x71, 0xfb, 0xe5, 0x87, 0x67, 0x8f, 0xc2, 0x71, 0xff, 0x0d, 0x40, 0x83, 0x92, 0xc2,
-	0x75, 0xca, 0x77, 0xe8, 0x91, 0x80, 0x0c, 0x5c, 0xa6, 0x67, 0xfa, 0x0c, 0xee, 0x56, 0x49, 0x8c,
-	0xa9, 0x32, 0x42, 0x96, 0x16, 0x02, 0x03, 0x95, 0x52, 0x35, 0xd7, 0x77, 0x02, 0x37, 0x73, 0x9d,
-	0x95, 0xb6, 0xc0, 0x9a, 0x4d, 0x34, 0xcd, 0x35, 0xb3, 0x66, 0x13, 0x3a, 0x85, 0x87, 0x82, 0x6f,
-	0xd4, 0x92, 0x9f, 0xb5, 0xb4, 0xa7, 0xbb, 0xe7, 0x4f, 0xc2, 0x66, 0x3b, 0xe1, 0x65, 0x44, 0xd6,
-	0x12, 0x97, 0x91, 0xa7, 0xf0, 0xc8, 0xac, 0x37, 0x89, 0x6c, 0x4d, 0xe4, 0x5d, 0x12, 0x35, 0x48,
-	0xaa, 0x2f, 0x52, 0x23, 0xb5, 0xe3, 0x31, 0x78, 0xaf, 0x93, 0x42, 0x2a, 0x14, 0x1f, 0x4c, 0xd9,
-	0xef, 0x51, 0x31, 0xfc, 0x54, 0xa0, 0x54, 0xb4, 0x0d, 0xf6, 0x1e, 0x45, 0x55, 0x45, 0x39, 0xd6,
-	0x67, 0x5f, 0x09, 0xf4, 0xaa, 0xbb, 0xf9, 0x99, 0xbb, 0x71, 0xda, 0x03, 0xb7, 0xb2, 0x79, 0x2e,
-	0xc1, 0x31, 0x80, 0xae, 0xe2, 0x3f, 0x19, 0xac, 0xfb, 0x67, 0x78, 0x07, 0x8f, 0x27, 0xd9, 0xe7,
-	0x74, 0x2b, 0xf8, 0x1a, 0x67, 0xe9, 0x26, 0x6b, 0xf8, 0xf0, 0xe0, 0x16, 0x53, 0x1e, 0x25, 0xb8,
-	0xd6, 0x2e, 0x1c, 0x76, 0x7a, 0x3c, 0x85, 0xb3, 0xfe, 0x0d, 0xf7, 0xaa, 0x73, 0xf8, 0xed, 0x5f,
-	0x1d, 0x8e, 0x3e, 0xf9, 0x79, 0xf4, 0xc9, 0xaf, 0xa3, 0x4f, 0xbe, 0xfd, 0xf1, 0xaf, 0xa2, 0x1b,
-	0xfd, 0x17, 0x8e, 0xfe, 0x06, 0x00, 0x00, 0xff, 0xff, 0xf1, 0xa3, 0xbd, 0xee, 0xdf, 0x02, 0x00,
-	0x00,
+	// 524 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x53, 0xc1, 0x6e, 0xd3, 0x4a,
+	0x14, 0xad, 0xed, 0x36, 0x89, 0x6f, 0xde, 0x0b, 0xe9, 0x28, 0x12, 0x56, 0x02, 0xc1, 0xaa, 0x58,
+	0x64, 0xe5, 0x48, 0x2d, 0x45, 0xd0, 0x1d, 0x25, 0x59, 0x44, 0x6a, 0x11, 0x1a, 0x54, 0xb6, 0xd1,
+	0x38, 0xb9, 0x09, 0x23, 0x26, 0xb6, 0x19, 0x8f, 0x13, 0x65, 0xcb, 0xb2, 0x5f, 0xc0, 0x5f, 0x20,
+	0x21, 0xf1, 0x0f, 0x5d, 0xf2, 0x09, 0x10, 0x7e, 0x04, 0x79, 0xec, 0xc4, 0x8e, 0x80, 0x0d, 0xbb,
+	0xeb, 0x3b, 0xe7, 0x9e, 0x7b, 0xce, 0x99, 0x31, 0x34, 0x17, 0xb8, 0xf0, 0x51, 0xc6, 0xef, 0x78,
+	0xe4, 0x45, 0x32, 0x54, 0x21, 0xf9, 0xaf, 0xe8, 0x44, 0x7e, 0xbb, 0x35, 0x0f, 0xe7, 0xa1, 0x3e,
+	0xe8, 0xa7, 0x55, 0x86, 0x69, 0xbb, 0xa8, 0x26, 0xd3, 0x3e, 0x8b, 0x78, 0x7f, 0x89, 0x32, 0xe6,
+	0x61, 0x10, 0xf9, 0xdb, 0x2a, 0x43, 0x9c, 0xdc, 0x40, 0x83, 0xb2, 0x99, 0x7a, 0xa1, 0x94, 0xe4,
+	0x7e, 0xa2, 0x30, 0x26, 0x1d, 0xb0, 0x23, 0x44, 0x39, 0x4e, 0xa4, 0x88, 0x1d, 0xc3, 0xb5, 0x7a,
+	0x36, 0xad, 0xa5, 0x8d, 0x1b, 0x29, 0x62, 0xf2, 0x10, 0x80, 0xc7, 0x63, 0x81, 0x4c, 0x06, 0x28,
+	0x1d, 0xd3, 0x35, 0x7a, 0x35, 0x6a, 0xf3, 0xf8, 0x2a, 0x6b, 0x5c, 0x54, 0x3f, 0x7e, 0x75, 0xac,
+	0x33, 0xef, 0xfc, 0xe4, 0x8b, 0x09, 0x50, 0xe2, 0x24, 0x70, 0x18, 0xb0, 0x05, 0x3a, 0x86, 0x6b,
+	0xf4, 0x6c, 0xaa, 0x6b, 0xf2, 0x08, 0xea, 0x13, 0xc1, 0x31, 0x50, 0xd9, 0x26, 0x53, 0x6f, 0x82,
+	0xac, 0xa5, 0x77, 0x3d, 0x81, 0x63, 0x14, 0x38, 0x51, 0x3c, 0x0c, 0xc6, 0x91, 0xe4, 0xa1, 0xe4,
+	0x6a, 0xed, 0x58, 0xae, 0xd1, 0xb3, 0x2e, 0xab, 0xb7, 0x7a, 0xcf, 0x53, 0xda, 0xdc, 0x22, 0x5e,
+	0xe7, 0x00, 0x32, 0x84, 0x8a, 0x60, 0x3e, 0x8a, 0xd8, 0x39, 0x74, 0xad, 0x5e, 0xfd, 0xf4, 0xb1,
+	0x57, 0xce, 0xc9, 0x2b, 0x44, 0x79, 0x57, 0x1a, 0x36, 0x0c, 0x94, 0x5c, 0x17, 0x84, 0xf9, 0x30,
+	0xf1, 0xa0, 0xb1, 0x62, 0x62, 0x3c, 0x4d, 0x24, 0xf3, 0xb9, 0x48, 0x37, 0x1f, 0xa5, 0xda, 0x0b,
+	0xe0, 0xff, 0x2b, 0x26, 0x06, 0xbb, 0xd3, 0xf6, 0x73, 0xa8, 0x97, 0xf8, 0x48, 0x13, 0xac, 0xf7,
+	0xb8, 0xce, 0xfd, 0xa6, 0x25, 0x69, 0xc1, 0xd1, 0x92, 0x89, 0x04, 0x75, 0x68, 0x36, 0xcd, 0x3e,
+	0x2e, 0xcc, 0x67, 0x46, 0x11, 0xda, 0x67, 0x03, 0x2a, 0xd7, 0x5a, 0x2c, 0x69, 0x80, 0x39, 0x1a,
+	0xe8, 0xf1, 0x43, 0x6a, 0x8e, 0x06, 0x64, 0x08, 0xf7, 0x24, 0x9b, 0xa9, 0x31, 0xdb, 0xc9, 0xd7,
+	0x3c, 0xf5, 0xd3, 0x07, 0xfb, 0xf6, 0xf6, 0xef, 0x92, 0x36, 0xe4, 0xfe, 0xdd, 0x0e, 0xe1, 0x38,
+	0x83, 0x97, 0x89, 0x2c, 0x4d, 0xe4, 0xfc, 0x2d, 0x27, 0x9a, 0x3f, 0xbd, 0xa2, 0x53, 0x28, 0x3e,
+	0x07, 0xe7, 0xa5, 0x48, 0x62, 0x85, 0xf2, 0x6d, 0xf6, 0xaa, 0xde, 0xa0, 0xa2, 0xf8, 0x21, 0xc1,
+	0x58, 0xa5, 0x11, 0x2c, 0x51, 0x6e, 0x23, 0x58, 0x96, 0x5f, 0xc7, 0xad, 0x01, 0x9d, 0x7c, 0xee,
+	0x7a, 0xc7, 0x5d, 0x1a, 0xed, 0x80, 0x9d, 0xcb, 0xdc, 0x85, 0x50, 0xcb, 0x1a, 0x3a, 0x8a, 0x3f,
+	0x78, 0x30, 0xff, 0xdd, 0xc3, 0x2b, 0xb8, 0x3f, 0x08, 0x57, 0xc1, 0x5c, 0xb2, 0x29, 0x8e, 0x82,
+	0x59, 0x58, 0xd2, 0xe1, 0x40, 0x15, 0x03, 0xe6, 0x0b, 0x9c, 0x6a, 0x15, 0x35, 0xba, 0xfd, 0xdc,
+	0x9a, 0x33, 0x7f, 0x37, 0x77, 0xd9, 0xba, 0xfb, 0xd1, 0x3d, 0xb8, 0xdb, 0x74, 0x8d, 0x6f, 0x9b,
+	0xae, 0xf1, 0x7d, 0xd3, 0x35, 0x3e, 0xfd, 0xec, 0x1e, 0xf8, 0x15, 0xfd, 0xbb, 0x9d, 0xfd, 0x0a,
+	0x00, 0x00, 0xff, 0xff, 0x0a, 0x3e, 0x1c, 0x13, 0xc8, 0x03, 0x00, 0x00,
 }
 
 func (m *RaftAttributes) Marshal() (dAtA []byte, err error) {
@@ -386,6 +402,37 @@ func (m *Attributes) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.WalDurability) > 0 {
+		i -= len(m.WalDurability)
+		copy(dAtA[i:], m.WalDurability)
+		i = encodeVarintMembership(dAtA, i, uint64(len(m.WalDurability)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.Labels) > 0 {
+		for k := range m.Labels {
+			v := m.Labels[k]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintMembership(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintMembership(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintMembership(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if m.ElectionPriority != 0 {
+		i = encodeVarintMembership(dAtA, i, uint64(m.ElectionPriority))
+		i--
+		dAtA[i] = 0x18
+	}
 	if len(m.ClientUrls) > 0 {
 		for iNdEx := len(m.ClientUrls) - 1; iNdEx >= 0; iNdEx-- {
 			i -= len(m.ClientUrls[iNdEx])
@@ -631,6 +678,21 @@ func (m *Attributes) Size() (n int) {
 			n += 1 + l + sovMembership(uint64(l))
 		}
 	}
+	if m.ElectionPriority != 0 {
+		n += 1 + sovMembership(uint64(m.ElectionPriority))
+	}
+	if len(m.Labels) > 0 {
+		for k, v := range m.Labels {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovMembership(uint64(len(k))) + 1 + len(v) + sovMembership(uint64(len(v)))
+			n += mapEntrySize + 1 + sovMembership(uint64(mapEntrySize))
+		}
+	}
+	l = len(m.WalDurability)
+	if l > 0 {
+		n += 1 + l + sovMembership(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -916,6 +978,184 @@ func (m *Attributes) Unmarshal(dAtA []byte) error {
 			}
 			m.ClientUrls = append(m.ClientUrls, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ElectionPriority", wireType)
+			}
+			m.ElectionPriority = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMembership
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ElectionPriority |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Labels", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMembership
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMembership
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMembership
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Labels == nil {
+				m.Labels = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMembership
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMembership
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthMembership
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthMembership
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMembership
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthMembership
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthMembership
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipMembership(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if (skippy < 0) || (iNdEx+skippy) < 0 {
+						return ErrInvalidLengthMembership
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Labels[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field WalDurability", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMembership
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthMembership
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMembership
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.WalDurability = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMembership(dAtA[iNdEx:])
@@ -65,7 +65,10 @@ type KeyValue struct {
 	// lease is the ID of the lease that attached to key.
 	// When the attached lease expires, the key will be deleted.
 	// If lease is 0, then no lease is attached to the key.
-	Lease                int64    `protobuf:"varint,6,opt,name=lease,proto3" json:"lease,omitempty"`
+	Lease int64 `protobuf:"varint,6,opt,name=lease,proto3" json:"lease,omitempty"`
+	// expire_time is the unix time in seconds after which the key is deleted.
+	// If expire_time is 0, then the key does not expire.
+	ExpireTime           int64    `protobuf:"varint,7,opt,name=expire_time,json=expireTime,proto3" json:"expire_time,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -164,26 +167,28 @@ func init() {
 func init() { proto.RegisterFile("kv.proto", fileDescriptor_2216fe83c9c12408) }
 
 var fileDescriptor_2216fe83c9c12408 = []byte{
-	// 303 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x90, 0x41, 0x4e, 0xc2, 0x40,
-	0x14, 0x86, 0x3b, 0x14, 0x0a, 0x3e, 0x08, 0x36, 0x13, 0x12, 0x27, 0x2e, 0x26, 0x95, 0x8d, 0x18,
-	0x13, 0x4c, 0xf0, 0x06, 0xc6, 0xae, 0x70, 0x61, 0x1a, 0x74, 0x4b, 0x4a, 0x79, 0x21, 0xa4, 0x94,
-	0x69, 0x4a, 0x9d, 0xa4, 0x37, 0x71, 0xef, 0xde, 0x73, 0xb0, 0xe4, 0x08, 0x52, 0x2f, 0x62, 0xfa,
-	0xc6, 0xe2, 0xc6, 0xcd, 0xe4, 0xfd, 0xff, 0xff, 0x65, 0xe6, 0x7f, 0x03, 0x9d, 0x58, 0x8f, 0xd3,
-	0x4c, 0xe5, 0x8a, 0x3b, 0x89, 0x8e, 0xa2, 0x74, 0x71, 0x39, 0x58, 0xa9, 0x95, 0x22, 0xeb, 0xae,
-	0x9a, 0x4c, 0x3a, 0xfc, 0x64, 0xd0, 0x99, 0x62, 0xf1, 0x1a, 0x6e, 0xde, 0x90, 0xbb, 0x60, 0xc7,
-	0x58, 0x08, 0xe6, 0xb1, 0x51, 0x2f, 0xa8, 0x46, 0x7e, 0x0d, 0xe7, 0x51, 0x86, 0x61, 0x8e, 0xf3,
-	0x0c, 0xf5, 0x7a, 0xb7, 0x56, 0x5b, 0xd1, 0xf0, 0xd8, 0xc8, 0x0e, 0xfa, 0xc6, 0x0e, 0x7e, 0x5d,
-	0x7e, 0x05, 0xbd, 0x44, 0x2d, 0xff, 0x28, 0x9b, 0xa8, 0x6e, 0xa2, 0x96, 0x27, 0x44, 0x40, 0x5b,
-	0x63, 0x46, 0x69, 0x93, 0xd2, 0x5a, 0xf2, 0x01, 0xb4, 0x74, 0x55, 0x40, 0xb4, 0xe8, 0x65, 0x23,
-	0x2a, 0x77, 0x83, 0xe1, 0x0e, 0x85, 0x43, 0xb4, 0x11, 0xc3, 0x0f, 0x06, 0x2d, 0x5f, 0xe3, 0x36,
-	0xe7, 0xb7, 0xd0, 0xcc, 0x8b, 0x14, 0xa9, 0x6e, 0x7f, 0x72, 0x31, 0x36, 0x7b, 0x8e, 0x29, 0x34,
-	0xe7, 0xac, 0x48, 0x31, 0x20, 0x88, 0x7b, 0xd0, 0x88, 0x35, 0x75, 0xef, 0x4e, 0xdc, 0x1a, 0xad,
-	0x17, 0x0f, 0x1a, 0xb1, 0xe6, 0x37, 0xd0, 0x4e, 0x33, 0xd4, 0xf3, 0x58, 0x53, 0xf9, 0xff, 0x30,
-	0xa7, 0x02, 0xa6, 0x7a, 0xe8, 0xc1, 0xd9, 0xe9, 0x7e, 0xde, 0x06, 0xfb, 0xf9, 0x65, 0xe6, 0x5a,
-	0x1c, 0xc0, 0x79, 0xf4, 0x9f, 0xfc, 0x99, 0xef, 0xb2, 0x07, 0xb1, 0x3f, 0x4a, 0xeb, 0x70, 0x94,
-	0xd6, 0xbe, 0x94, 0xec, 0x50, 0x4a, 0xf6, 0x55, 0x4a, 0xf6, 0xfe, 0x2d, 0xad, 0x85, 0x43, 0xff,
-	0x7e, 0xff, 0x13, 0x00, 0x00, 0xff, 0xff, 0xb5, 0x45, 0x92, 0x5d, 0xa1, 0x01, 0x00, 0x00,
+	// 322 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x91, 0xcd, 0x4e, 0xc2, 0x40,
+	0x14, 0x85, 0x3b, 0x14, 0x5a, 0xbc, 0x10, 0x6c, 0x26, 0x24, 0x4e, 0x5c, 0xd4, 0xca, 0x46, 0x8c,
+	0x09, 0x26, 0xf8, 0x06, 0xc6, 0xae, 0x70, 0x61, 0x9a, 0xea, 0x96, 0xf0, 0x73, 0x43, 0x9a, 0x52,
+	0xa6, 0x19, 0xea, 0xc4, 0xbe, 0x89, 0x7b, 0x5f, 0x86, 0x9d, 0x3c, 0x82, 0xe0, 0x8b, 0x98, 0xde,
+	0x11, 0xdc, 0xb8, 0x69, 0xee, 0x39, 0xe7, 0x4b, 0x67, 0xce, 0x1d, 0x68, 0xa6, 0x7a, 0x90, 0x2b,
+	0x59, 0x48, 0xee, 0x64, 0x7a, 0x36, 0xcb, 0xa7, 0xe7, 0xdd, 0x85, 0x5c, 0x48, 0xb2, 0x6e, 0xab,
+	0xc9, 0xa4, 0xbd, 0x4f, 0x06, 0xcd, 0x11, 0x96, 0x2f, 0x93, 0xe5, 0x2b, 0x72, 0x0f, 0xec, 0x14,
+	0x4b, 0xc1, 0x02, 0xd6, 0x6f, 0x47, 0xd5, 0xc8, 0xaf, 0xe0, 0x74, 0xa6, 0x70, 0x52, 0xe0, 0x58,
+	0xa1, 0x4e, 0xd6, 0x89, 0x5c, 0x89, 0x5a, 0xc0, 0xfa, 0x76, 0xd4, 0x31, 0x76, 0xf4, 0xeb, 0xf2,
+	0x4b, 0x68, 0x67, 0x72, 0xfe, 0x47, 0xd9, 0x44, 0xb5, 0x32, 0x39, 0x3f, 0x22, 0x02, 0x5c, 0x8d,
+	0x8a, 0xd2, 0x3a, 0xa5, 0x07, 0xc9, 0xbb, 0xd0, 0xd0, 0xd5, 0x05, 0x44, 0x83, 0x4e, 0x36, 0xa2,
+	0x72, 0x97, 0x38, 0x59, 0xa3, 0x70, 0x88, 0x36, 0x82, 0x5f, 0x40, 0x0b, 0xdf, 0xf2, 0x44, 0xe1,
+	0xb8, 0x48, 0x32, 0x14, 0x2e, 0x65, 0x60, 0xac, 0x38, 0xc9, 0xb0, 0xf7, 0xc1, 0xa0, 0x11, 0x6a,
+	0x5c, 0x15, 0xfc, 0x06, 0xea, 0x45, 0x99, 0x23, 0xf5, 0xe9, 0x0c, 0xcf, 0x06, 0x66, 0x11, 0x03,
+	0x0a, 0xcd, 0x37, 0x2e, 0x73, 0x8c, 0x08, 0xe2, 0x01, 0xd4, 0x52, 0x4d, 0xe5, 0x5a, 0x43, 0xef,
+	0x80, 0x1e, 0x36, 0x13, 0xd5, 0x52, 0xcd, 0xaf, 0xc1, 0xcd, 0x15, 0xea, 0x71, 0xaa, 0xa9, 0xdd,
+	0x7f, 0x98, 0x53, 0x01, 0x23, 0xdd, 0x0b, 0xe0, 0xe4, 0xf8, 0x7f, 0xee, 0x82, 0xfd, 0xf4, 0x1c,
+	0x7b, 0x16, 0x07, 0x70, 0x1e, 0xc2, 0xc7, 0x30, 0x0e, 0x3d, 0x76, 0x2f, 0x36, 0x3b, 0xdf, 0xda,
+	0xee, 0x7c, 0x6b, 0xb3, 0xf7, 0xd9, 0x76, 0xef, 0xb3, 0xaf, 0xbd, 0xcf, 0xde, 0xbf, 0x7d, 0x6b,
+	0xea, 0xd0, 0xc3, 0xdc, 0xfd, 0x04, 0x00, 0x00, 0xff, 0xff, 0x8a, 0x1c, 0x64, 0x74, 0xc2, 0x01,
+	0x00, 0x00,
 }
 
 func (m *KeyValue) Marshal() (dAtA []byte, err error) {
@@ -210,6 +215,11 @@ func (m *KeyValue) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.ExpireTime != 0 {
+		i = encodeVarintKv(dAtA, i, uint64(m.ExpireTime))
+		i--
+		dAtA[i] = 0x38
+	}
 	if m.Lease != 0 {
 		i = encodeVarintKv(dAtA, i, uint64(m.Lease))
 		i--
@@ -340,6 +350,9 @@ func (m *KeyValue) Size() (n int) {
 	if m.Lease != 0 {
 		n += 1 + sovKv(uint64(m.Lease))
 	}
+	if m.ExpireTime != 0 {
+		n += 1 + sovKv(uint64(m.ExpireTime))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -548,6 +561,25 @@ func (m *KeyValue) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpireTime", wireType)
+			}
+			m.ExpireTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowKv
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ExpireTime |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipKv(dAtA[iNdEx:])
@@ -25,19 +25,37 @@ var (
 	ErrGRPCKeyNotFound             = status.New(codes.InvalidArgument, "etcdserver: key not found").Err()
 	ErrGRPCValueProvided           = status.New(codes.InvalidArgument, "etcdserver: value is provided").Err()
 	ErrGRPCLeaseProvided           = status.New(codes.InvalidArgument, "etcdserver: lease is provided").Err()
+	ErrGRPCInvalidKeyTTL           = status.New(codes.InvalidArgument, "etcdserver: invalid key ttl").Err()
+	ErrGRPCKeyExists               = status.New(codes.FailedPrecondition, "etcdserver: key already exists").Err()
+	ErrGRPCKeyReserved             = status.New(codes.InvalidArgument, "etcdserver: key is reserved").Err()
 	ErrGRPCTooManyOps              = status.New(codes.InvalidArgument, "etcdserver: too many operations in txn request").Err()
 	ErrGRPCDuplicateKey            = status.New(codes.InvalidArgument, "etcdserver: duplicate key given in txn request").Err()
 	ErrGRPCInvalidClientAPIVersion = status.New(codes.InvalidArgument, "etcdserver: invalid client api version").Err()
 	ErrGRPCInvalidSortOption       = status.New(codes.InvalidArgument, "etcdserver: invalid sort option").Err()
+	ErrGRPCInvalidContinueToken    = status.New(codes.InvalidArgument, "etcdserver: invalid continue token").Err()
 	ErrGRPCCompacted               = status.New(codes.OutOfRange, "etcdserver: mvcc: required revision has been compacted").Err()
 	ErrGRPCFutureRev               = status.New(codes.OutOfRange, "etcdserver: mvcc: required revision is a future revision").Err()
 	ErrGRPCNoSpace                 = status.New(codes.ResourceExhausted, "etcdserver: mvcc: database space exceeded").Err()
+	ErrGRPCPrefixQuotaExceeded     = status.New(codes.ResourceExhausted, "etcdserver: prefix quota exceeded").Err()
 
 	ErrGRPCLeaseNotFound    = status.New(codes.NotFound, "etcdserver: requested lease not found").Err()
 	ErrGRPCLeaseExist       = status.New(codes.FailedPrecondition, "etcdserver: lease already exists").Err()
 	ErrGRPCLeaseTTLTooLarge = status.New(codes.OutOfRange, "etcdserver: too large lease TTL").Err()
+	ErrGRPCTooManyLeases    = status.New(codes.ResourceExhausted, "etcdserver: too many leases held by the user").Err()
 
-	ErrGRPCWatchCanceled = status.New(codes.Canceled, "etcdserver: watch canceled").Err()
+	ErrGRPCWatchCanceled              = status.New(codes.Canceled, "etcdserver: watch canceled").Err()
+	ErrGRPCWatchCompareFailed         = status.New(codes.FailedPrecondition, "etcdserver: watch compare failed").Err()
+	ErrGRPCWatchInvalidValuePredicate = status.New(codes.InvalidArgument, "etcdserver: invalid watch value predicate").Err()
+	ErrGRPCWatchInvalidResumeToken    = status.New(codes.InvalidArgument, "etcdserver: invalid watch resume token").Err()
+	ErrGRPCWatchInvalidBatchOptions   = status.New(codes.InvalidArgument, "etcdserver: invalid watch batch options").Err()
+	ErrGRPCWatchInvalidProjection     = status.New(codes.InvalidArgument, "etcdserver: invalid watch projection").Err()
+	ErrGRPCWatchStuck                 = status.New(codes.ResourceExhausted, "etcdserver: watcher canceled for its stuck backlog").Err()
+	ErrGRPCWatchInvalidProgressNotify = status.New(codes.InvalidArgument, "etcdserver: invalid watch progress notify interval").Err()
+	ErrGRPCWatchCanceledByAdmin       = status.New(codes.Aborted, "etcdserver: watcher canceled by an administrator").Err()
+	ErrGRPCWatcherNotFound            = status.New(codes.NotFound, "etcdserver: watcher not found").Err()
+	ErrGRPCValueNotNumeric            = status.New(codes.FailedPrecondition, "etcdserver: value is not numeric").Err()
+	ErrGRPCIncrementOverflow          = status.New(codes.OutOfRange, "etcdserver: increment overflows the value").Err()
+	ErrGRPCBulkWriteOpNotSupported    = status.New(codes.InvalidArgument, "etcdserver: bulk write only supports put and delete range requests").Err()
 
 	ErrGRPCMemberExist            = status.New(codes.FailedPrecondition, "etcdserver: member ID already exist").Err()
 	ErrGRPCPeerURLExist           = status.New(codes.FailedPrecondition, "etcdserver: Peer URLs already exists").Err()
@@ -47,6 +65,9 @@ var (
 	ErrGRPCMemberNotLearner       = status.New(codes.FailedPrecondition, "etcdserver: can only promote a learner member").Err()
 	ErrGRPCLearnerNotReady        = status.New(codes.FailedPrecondition, "etcdserver: can only promote a learner member which is in sync with leader").Err()
 	ErrGRPCTooManyLearners        = status.New(codes.FailedPrecondition, "etcdserver: too many learner members in cluster").Err()
+	ErrGRPCNoMemberChange         = status.New(codes.InvalidArgument, "etcdserver: no member added or removed").Err()
+	ErrGRPCMemberChangedTwice     = status.New(codes.InvalidArgument, "etcdserver: member added or removed more than once").Err()
+	ErrGRPCNoVotingMember         = status.New(codes.FailedPrecondition, "etcdserver: re-configuration failed due to no voting member left").Err()
 
 	ErrGRPCRequestTooLarge        = status.New(codes.InvalidArgument, "etcdserver: request is too large").Err()
 	ErrGRPCRequestTooManyRequests = status.New(codes.ResourceExhausted, "etcdserver: too many requests").Err()
@@ -68,6 +89,13 @@ var (
 	ErrGRPCInvalidAuthToken     = status.New(codes.Unauthenticated, "etcdserver: invalid auth token").Err()
 	ErrGRPCInvalidAuthMgmt      = status.New(codes.InvalidArgument, "etcdserver: invalid auth management").Err()
 	ErrGRPCAuthOldRevision      = status.New(codes.InvalidArgument, "etcdserver: revision of auth store is old").Err()
+	ErrGRPCInvalidHomePrefix    = status.New(codes.InvalidArgument, "etcdserver: home prefix must be an absolute key").Err()
+	ErrGRPCAuthLockedOut        = status.New(codes.ResourceExhausted, "etcdserver: too many failed authentication attempts, try again later").Err()
+	ErrGRPCPasswordTooShort     = status.New(codes.InvalidArgument, "etcdserver: password is shorter than the password policy allows").Err()
+	ErrGRPCPasswordTooSimple    = status.New(codes.InvalidArgument, "etcdserver: password has fewer classes of characters than the password policy allows").Err()
+	ErrGRPCPasswordExpired      = status.New(codes.FailedPrecondition, "etcdserver: password expired, it must be changed").Err()
+	ErrGRPCRoleGrantExpired     = status.New(codes.InvalidArgument, "etcdserver: role grant expiration is not in the future").Err()
+	ErrGRPCLDAPUnavailable      = status.New(codes.Unavailable, "etcdserver: LDAP directory unavailable").Err()
 
 	ErrGRPCNoLeader                   = status.New(codes.Unavailable, "etcdserver: no leader").Err()
 	ErrGRPCNotLeader                  = status.New(codes.FailedPrecondition, "etcdserver: not leader").Err()
@@ -81,6 +109,8 @@ var (
 	ErrGRPCUnhealthy                  = status.New(codes.Unavailable, "etcdserver: unhealthy cluster").Err()
 	ErrGRPCCorrupt                    = status.New(codes.DataLoss, "etcdserver: corrupt cluster").Err()
 	ErrGRPCNotSupportedForLearner     = status.New(codes.FailedPrecondition, "etcdserver: rpc not supported for learner").Err()
+	ErrGRPCNotSupportedForWitness     = status.New(codes.FailedPrecondition, "etcdserver: rpc not supported for witness").Err()
+	ErrGRPCNotSupportedByCluster      = status.New(codes.FailedPrecondition, "etcdserver: rpc not supported by the cluster version").Err()
 	ErrGRPCBadLeaderTransferee        = status.New(codes.FailedPrecondition, "etcdserver: bad leader transferee").Err()
 
 	ErrGRPCWrongDowngradeVersionFormat   = status.New(codes.InvalidArgument, "etcdserver: wrong downgrade target version format").Err()
@@ -89,6 +119,16 @@ var (
 	ErrGRPCDowngradeInProcess            = status.New(codes.FailedPrecondition, "etcdserver: cluster has a downgrade job in progress").Err()
 	ErrGRPCNoInflightDowngrade           = status.New(codes.FailedPrecondition, "etcdserver: no inflight downgrade job").Err()
 
+	ErrGRPCInvalidBackendBatchInterval = status.New(codes.InvalidArgument, "etcdserver: backend batch interval must be between 1ms and 10s").Err()
+	ErrGRPCInvalidBackendBatchLimit    = status.New(codes.InvalidArgument, "etcdserver: backend batch limit must be between 1 and 1000000").Err()
+	ErrGRPCNoSpaceNotResolved          = status.New(codes.FailedPrecondition, "etcdserver: database size is still close to the space quota").Err()
+
+	ErrGRPCInvalidLogLevel          = status.New(codes.InvalidArgument, "etcdserver: invalid log level").Err()
+	ErrGRPCLogLevelNotAdjustable    = status.New(codes.FailedPrecondition, "etcdserver: log level of the member cannot be changed at runtime").Err()
+	ErrGRPCGRPCTracingNotAdjustable = status.New(codes.FailedPrecondition, "etcdserver: gRPC tracing of the member cannot be changed at runtime").Err()
+
+	ErrGRPCHotKeySamplingDisabled = status.New(codes.FailedPrecondition, "etcdserver: hot key sampling is disabled").Err()
+
 	ErrGRPCCanceled         = status.New(codes.Canceled, "etcdserver: request canceled").Err()
 	ErrGRPCDeadlineExceeded = status.New(codes.DeadlineExceeded, "etcdserver: context deadline exceeded").Err()
 
@@ -97,17 +137,36 @@ var (
 		ErrorDesc(ErrGRPCKeyNotFound):   ErrGRPCKeyNotFound,
 		ErrorDesc(ErrGRPCValueProvided): ErrGRPCValueProvided,
 		ErrorDesc(ErrGRPCLeaseProvided): ErrGRPCLeaseProvided,
+		ErrorDesc(ErrGRPCInvalidKeyTTL): ErrGRPCInvalidKeyTTL,
+		ErrorDesc(ErrGRPCKeyExists):     ErrGRPCKeyExists,
+		ErrorDesc(ErrGRPCKeyReserved):   ErrGRPCKeyReserved,
 
-		ErrorDesc(ErrGRPCTooManyOps):        ErrGRPCTooManyOps,
-		ErrorDesc(ErrGRPCDuplicateKey):      ErrGRPCDuplicateKey,
-		ErrorDesc(ErrGRPCInvalidSortOption): ErrGRPCInvalidSortOption,
-		ErrorDesc(ErrGRPCCompacted):         ErrGRPCCompacted,
-		ErrorDesc(ErrGRPCFutureRev):         ErrGRPCFutureRev,
-		ErrorDesc(ErrGRPCNoSpace):           ErrGRPCNoSpace,
+		ErrorDesc(ErrGRPCTooManyOps):           ErrGRPCTooManyOps,
+		ErrorDesc(ErrGRPCDuplicateKey):         ErrGRPCDuplicateKey,
+		ErrorDesc(ErrGRPCInvalidSortOption):    ErrGRPCInvalidSortOption,
+		ErrorDesc(ErrGRPCInvalidContinueToken): ErrGRPCInvalidContinueToken,
+		ErrorDesc(ErrGRPCCompacted):            ErrGRPCCompacted,
+		ErrorDesc(ErrGRPCFutureRev):            ErrGRPCFutureRev,
+		ErrorDesc(ErrGRPCNoSpace):              ErrGRPCNoSpace,
+		ErrorDesc(ErrGRPCPrefixQuotaExceeded):  ErrGRPCPrefixQuotaExceeded,
 
 		ErrorDesc(ErrGRPCLeaseNotFound):    ErrGRPCLeaseNotFound,
 		ErrorDesc(ErrGRPCLeaseExist):       ErrGRPCLeaseExist,
 		ErrorDesc(ErrGRPCLeaseTTLTooLarge): ErrGRPCLeaseTTLTooLarge,
+		ErrorDesc(ErrGRPCTooManyLeases):    ErrGRPCTooManyLeases,
+
+		ErrorDesc(ErrGRPCWatchCompareFailed):         ErrGRPCWatchCompareFailed,
+		ErrorDesc(ErrGRPCWatchInvalidValuePredicate): ErrGRPCWatchInvalidValuePredicate,
+		ErrorDesc(ErrGRPCWatchInvalidResumeToken):    ErrGRPCWatchInvalidResumeToken,
+		ErrorDesc(ErrGRPCWatchInvalidBatchOptions):   ErrGRPCWatchInvalidBatchOptions,
+		ErrorDesc(ErrGRPCWatchInvalidProjection):     ErrGRPCWatchInvalidProjection,
+		ErrorDesc(ErrGRPCWatchStuck):                 ErrGRPCWatchStuck,
+		ErrorDesc(ErrGRPCWatchInvalidProgressNotify): ErrGRPCWatchInvalidProgressNotify,
+		ErrorDesc(ErrGRPCWatchCanceledByAdmin):       ErrGRPCWatchCanceledByAdmin,
+		ErrorDesc(ErrGRPCWatcherNotFound):            ErrGRPCWatcherNotFound,
+		ErrorDesc(ErrGRPCValueNotNumeric):            ErrGRPCValueNotNumeric,
+		ErrorDesc(ErrGRPCIncrementOverflow):          ErrGRPCIncrementOverflow,
+		ErrorDesc(ErrGRPCBulkWriteOpNotSupported):    ErrGRPCBulkWriteOpNotSupported,
 
 		ErrorDesc(ErrGRPCMemberExist):            ErrGRPCMemberExist,
 		ErrorDesc(ErrGRPCPeerURLExist):           ErrGRPCPeerURLExist,
@@ -117,6 +176,9 @@ var (
 		ErrorDesc(ErrGRPCMemberNotLearner):       ErrGRPCMemberNotLearner,
 		ErrorDesc(ErrGRPCLearnerNotReady):        ErrGRPCLearnerNotReady,
 		ErrorDesc(ErrGRPCTooManyLearners):        ErrGRPCTooManyLearners,
+		ErrorDesc(ErrGRPCNoMemberChange):         ErrGRPCNoMemberChange,
+		ErrorDesc(ErrGRPCMemberChangedTwice):     ErrGRPCMemberChangedTwice,
+		ErrorDesc(ErrGRPCNoVotingMember):         ErrGRPCNoVotingMember,
 
 		ErrorDesc(ErrGRPCRequestTooLarge):        ErrGRPCRequestTooLarge,
 		ErrorDesc(ErrGRPCRequestTooManyRequests): ErrGRPCRequestTooManyRequests,
@@ -137,6 +199,13 @@ var (
 		ErrorDesc(ErrGRPCInvalidAuthToken):     ErrGRPCInvalidAuthToken,
 		ErrorDesc(ErrGRPCInvalidAuthMgmt):      ErrGRPCInvalidAuthMgmt,
 		ErrorDesc(ErrGRPCAuthOldRevision):      ErrGRPCAuthOldRevision,
+		ErrorDesc(ErrGRPCInvalidHomePrefix):    ErrGRPCInvalidHomePrefix,
+		ErrorDesc(ErrGRPCAuthLockedOut):        ErrGRPCAuthLockedOut,
+		ErrorDesc(ErrGRPCPasswordTooShort):     ErrGRPCPasswordTooShort,
+		ErrorDesc(ErrGRPCPasswordTooSimple):    ErrGRPCPasswordTooSimple,
+		ErrorDesc(ErrGRPCPasswordExpired):      ErrGRPCPasswordExpired,
+		ErrorDesc(ErrGRPCRoleGrantExpired):     ErrGRPCRoleGrantExpired,
+		ErrorDesc(ErrGRPCLDAPUnavailable):      ErrGRPCLDAPUnavailable,
 
 		ErrorDesc(ErrGRPCNoLeader):                   ErrGRPCNoLeader,
 		ErrorDesc(ErrGRPCNotLeader):                  ErrGRPCNotLeader,
@@ -149,6 +218,8 @@ var (
 		ErrorDesc(ErrGRPCUnhealthy):                  ErrGRPCUnhealthy,
 		ErrorDesc(ErrGRPCCorrupt):                    ErrGRPCCorrupt,
 		ErrorDesc(ErrGRPCNotSupportedForLearner):     ErrGRPCNotSupportedForLearner,
+		ErrorDesc(ErrGRPCNotSupportedForWitness):     ErrGRPCNotSupportedForWitness,
+		ErrorDesc(ErrGRPCNotSupportedByCluster):      ErrGRPCNotSupportedByCluster,
 		ErrorDesc(ErrGRPCBadLeaderTransferee):        ErrGRPCBadLeaderTransferee,
 
 		ErrorDesc(ErrGRPCClusterVersionUnavailable):     ErrGRPCClusterVersionUnavailable,
@@ -156,25 +227,54 @@ var (
 		ErrorDesc(ErrGRPCInvalidDowngradeTargetVersion): ErrGRPCInvalidDowngradeTargetVersion,
 		ErrorDesc(ErrGRPCDowngradeInProcess):            ErrGRPCDowngradeInProcess,
 		ErrorDesc(ErrGRPCNoInflightDowngrade):           ErrGRPCNoInflightDowngrade,
+
+		ErrorDesc(ErrGRPCInvalidBackendBatchInterval): ErrGRPCInvalidBackendBatchInterval,
+		ErrorDesc(ErrGRPCInvalidBackendBatchLimit):    ErrGRPCInvalidBackendBatchLimit,
+		ErrorDesc(ErrGRPCNoSpaceNotResolved):          ErrGRPCNoSpaceNotResolved,
+
+		ErrorDesc(ErrGRPCInvalidLogLevel):          ErrGRPCInvalidLogLevel,
+		ErrorDesc(ErrGRPCLogLevelNotAdjustable):    ErrGRPCLogLevelNotAdjustable,
+		ErrorDesc(ErrGRPCGRPCTracingNotAdjustable): ErrGRPCGRPCTracingNotAdjustable,
+
+		ErrorDesc(ErrGRPCHotKeySamplingDisabled): ErrGRPCHotKeySamplingDisabled,
 	}
 )
 
 // client-side error
 var (
-	ErrEmptyKey          = Error(ErrGRPCEmptyKey)
-	ErrKeyNotFound       = Error(ErrGRPCKeyNotFound)
-	ErrValueProvided     = Error(ErrGRPCValueProvided)
-	ErrLeaseProvided     = Error(ErrGRPCLeaseProvided)
-	ErrTooManyOps        = Error(ErrGRPCTooManyOps)
-	ErrDuplicateKey      = Error(ErrGRPCDuplicateKey)
-	ErrInvalidSortOption = Error(ErrGRPCInvalidSortOption)
-	ErrCompacted         = Error(ErrGRPCCompacted)
-	ErrFutureRev         = Error(ErrGRPCFutureRev)
-	ErrNoSpace           = Error(ErrGRPCNoSpace)
+	ErrEmptyKey             = Error(ErrGRPCEmptyKey)
+	ErrKeyNotFound          = Error(ErrGRPCKeyNotFound)
+	ErrValueProvided        = Error(ErrGRPCValueProvided)
+	ErrLeaseProvided        = Error(ErrGRPCLeaseProvided)
+	ErrInvalidKeyTTL        = Error(ErrGRPCInvalidKeyTTL)
+	ErrKeyExists            = Error(ErrGRPCKeyExists)
+	ErrKeyReserved          = Error(ErrGRPCKeyReserved)
+	ErrTooManyOps           = Error(ErrGRPCTooManyOps)
+	ErrDuplicateKey         = Error(ErrGRPCDuplicateKey)
+	ErrInvalidSortOption    = Error(ErrGRPCInvalidSortOption)
+	ErrInvalidContinueToken = Error(ErrGRPCInvalidContinueToken)
+	ErrCompacted            = Error(ErrGRPCCompacted)
+	ErrFutureRev            = Error(ErrGRPCFutureRev)
+	ErrNoSpace              = Error(ErrGRPCNoSpace)
+	ErrPrefixQuotaExceeded  = Error(ErrGRPCPrefixQuotaExceeded)
 
 	ErrLeaseNotFound    = Error(ErrGRPCLeaseNotFound)
 	ErrLeaseExist       = Error(ErrGRPCLeaseExist)
 	ErrLeaseTTLTooLarge = Error(ErrGRPCLeaseTTLTooLarge)
+	ErrTooManyLeases    = Error(ErrGRPCTooManyLeases)
+
+	ErrWatchCompareFailed         = Error(ErrGRPCWatchCompareFailed)
+	ErrWatchInvalidValuePredicate = Error(ErrGRPCWatchInvalidValuePredicate)
+	ErrWatchInvalidResumeToken    = Error(ErrGRPCWatchInvalidResumeToken)
+	ErrWatchInvalidBatchOptions   = Error(ErrGRPCWatchInvalidBatchOptions)
+	ErrWatchInvalidProjection     = Error(ErrGRPCWatchInvalidProjection)
+	ErrWatchStuck                 = Error(ErrGRPCWatchStuck)
+	ErrWatchInvalidProgressNotify = Error(ErrGRPCWatchInvalidProgressNotify)
+	ErrWatchCanceledByAdmin       = Error(ErrGRPCWatchCanceledByAdmin)
+	ErrWatcherNotFound            = Error(ErrGRPCWatcherNotFound)
+	ErrValueNotNumeric            = Error(ErrGRPCValueNotNumeric)
+	ErrIncrementOverflow          = Error(ErrGRPCIncrementOverflow)
+	ErrBulkWriteOpNotSupported    = Error(ErrGRPCBulkWriteOpNotSupported)
 
 	ErrMemberExist            = Error(ErrGRPCMemberExist)
 	ErrPeerURLExist           = Error(ErrGRPCPeerURLExist)
@@ -184,6 +284,9 @@ var (
 	ErrMemberNotLearner       = Error(ErrGRPCMemberNotLearner)
 	ErrMemberLearnerNotReady  = Error(ErrGRPCLearnerNotReady)
 	ErrTooManyLearners        = Error(ErrGRPCTooManyLearners)
+	ErrNoMemberChange         = Error(ErrGRPCNoMemberChange)
+	ErrMemberChangedTwice     = Error(ErrGRPCMemberChangedTwice)
+	ErrNoVotingMember         = Error(ErrGRPCNoVotingMember)
 
 	ErrRequestTooLarge = Error(ErrGRPCRequestTooLarge)
 	ErrTooManyRequests = Error(ErrGRPCRequestTooManyRequests)
@@ -204,6 +307,13 @@ var (
 	ErrInvalidAuthToken     = Error(ErrGRPCInvalidAuthToken)
 	ErrAuthOldRevision      = Error(ErrGRPCAuthOldRevision)
 	ErrInvalidAuthMgmt      = Error(ErrGRPCInvalidAuthMgmt)
+	ErrInvalidHomePrefix    = Error(ErrGRPCInvalidHomePrefix)
+	ErrAuthLockedOut        = Error(ErrGRPCAuthLockedOut)
+	ErrPasswordTooShort     = Error(ErrGRPCPasswordTooShort)
+	ErrPasswordTooSimple    = Error(ErrGRPCPasswordTooSimple)
+	ErrPasswordExpired      = Error(ErrGRPCPasswordExpired)
+	ErrRoleGrantExpired     = Error(ErrGRPCRoleGrantExpired)
+	ErrLDAPUnavailable      = Error(ErrGRPCLDAPUnavailable)
 
 	ErrNoLeader                   = Error(ErrGRPCNoLeader)
 	ErrNotLeader                  = Error(ErrGRPCNotLeader)
@@ -223,6 +333,16 @@ var (
 	ErrInvalidDowngradeTargetVersion = Error(ErrGRPCInvalidDowngradeTargetVersion)
 	ErrDowngradeInProcess            = Error(ErrGRPCDowngradeInProcess)
 	ErrNoInflightDowngrade           = Error(ErrGRPCNoInflightDowngrade)
+
+	ErrInvalidBackendBatchInterval = Error(ErrGRPCInvalidBackendBatchInterval)
+	ErrInvalidBackendBatchLimit    = Error(ErrGRPCInvalidBackendBatchLimit)
+	ErrNoSpaceNotResolved          = Error(ErrGRPCNoSpaceNotResolved)
+
+	ErrInvalidLogLevel          = Error(ErrGRPCInvalidLogLevel)
+	ErrLogLevelNotAdjustable    = Error(ErrGRPCLogLevelNotAdjustable)
+	ErrGRPCTracingNotAdjustable = Error(ErrGRPCGRPCTracingNotAdjustable)
+
+	ErrHotKeySamplingDisabled = Error(ErrGRPCHotKeySamplingDisabled)
 )
 
 // EtcdError defines gRPC server errors.
@@ -19,4 +19,13 @@ var (
 	MetadataHasLeader        = "true"
 
 	MetadataClientAPIVersionKey = "client-api-version"
+
+	// MetadataDebugTraceKey asks the server to attach the timing breakdown of
+	// the request to the response header. It requires admin permission.
+	MetadataDebugTraceKey     = "etcd-debug-trace"
+	MetadataDebugTraceEnabled = "true"
+
+	// MetadataWatchCompressionKey lists the comma separated codecs of the watch
+	// responses the client accepts, such as "zstd,gzip", in order of preference.
+	MetadataWatchCompressionKey = "watch-compression"
 )
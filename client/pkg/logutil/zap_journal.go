@@ -24,6 +24,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"go.etcd.io/etcd/client/pkg/v3/systemd"
 
@@ -57,28 +58,11 @@ func (w *journalWriter) Write(p []byte) (int, error) {
 		return 0, err
 	}
 
-	var pri journal.Priority
-	switch line.Level {
-	case zapcore.DebugLevel.String():
-		pri = journal.PriDebug
-	case zapcore.InfoLevel.String():
-		pri = journal.PriInfo
-
-	case zapcore.WarnLevel.String():
-		pri = journal.PriWarning
-	case zapcore.ErrorLevel.String():
-		pri = journal.PriErr
-
-	case zapcore.DPanicLevel.String():
-		pri = journal.PriCrit
-	case zapcore.PanicLevel.String():
-		pri = journal.PriCrit
-	case zapcore.FatalLevel.String():
-		pri = journal.PriCrit
-
-	default:
+	var lvl zapcore.Level
+	if err := lvl.UnmarshalText([]byte(line.Level)); err != nil {
 		panic(fmt.Errorf("unknown log level: %q", line.Level))
 	}
+	pri := journalPriority(lvl)
 
 	err := journal.Send(string(p), pri, map[string]string{
 		"PACKAGE":           filepath.Dir(line.Caller),
@@ -91,3 +75,54 @@ func (w *journalWriter) Write(p []byte) (int, error) {
 	}
 	return 0, nil
 }
+
+func journalPriority(lvl zapcore.Level) journal.Priority {
+	switch lvl {
+	case zapcore.DebugLevel:
+		return journal.PriDebug
+	case zapcore.InfoLevel:
+		return journal.PriInfo
+	case zapcore.WarnLevel:
+		return journal.PriWarning
+	case zapcore.ErrorLevel:
+		return journal.PriErr
+	default:
+		return journal.PriCrit
+	}
+}
+
+// journalEntryWriter sends entries to the local systemd journal with the
+// priority and code location of the entry, falling back to stderr like
+// journalWriter.
+type journalEntryWriter struct{}
+
+func newJournalEntryWriter() (entryWriter, error) {
+	if err := systemd.DialJournal(); err != nil {
+		return nil, fmt.Errorf("can't find journal (%v)", err)
+	}
+	return journalEntryWriter{}, nil
+}
+
+func (journalEntryWriter) WriteEntry(ent zapcore.Entry, p []byte) error {
+	vars := map[string]string{
+		"SYSLOG_IDENTIFIER": filepath.Base(os.Args[0]),
+	}
+	if ent.Caller.Defined {
+		vars["PACKAGE"] = filepath.Dir(ent.Caller.TrimmedPath())
+		vars["CODE_FILE"] = ent.Caller.File
+		vars["CODE_LINE"] = strconv.Itoa(ent.Caller.Line)
+		if ent.Caller.Function != "" {
+			vars["CODE_FUNC"] = ent.Caller.Function
+		}
+	}
+	if ent.LoggerName != "" {
+		vars["LOGGER"] = ent.LoggerName
+	}
+	if err := journal.Send(string(p), journalPriority(ent.Level), vars); err != nil {
+		_, err = os.Stderr.Write(append(p, '\n'))
+		return err
+	}
+	return nil
+}
+
+func (journalEntryWriter) Sync() error { return nil }
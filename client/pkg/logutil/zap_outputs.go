@@ -0,0 +1,138 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logutil
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// SystemdJournalLogOutput is the log output writing to the local
+	// systemd journal.
+	SystemdJournalLogOutput = "systemd-journal"
+	// SyslogLogOutputScheme is the URL scheme of log outputs writing to a
+	// syslog server over UDP, as "syslog://host:port".
+	SyslogLogOutputScheme = "syslog"
+)
+
+// IsSystemLogOutput returns true if output is the systemd journal or a syslog
+// server, which NewLogger writes to with native priorities instead of as a
+// zap sink.
+func IsSystemLogOutput(output string) bool {
+	return output == SystemdJournalLogOutput || strings.HasPrefix(output, SyslogLogOutputScheme+"://")
+}
+
+// NewLogger builds a logger from cfg like zap.Config.Build, writing to the
+// SystemdJournalLogOutput and syslog outputs of cfg.OutputPaths with the
+// priority of the entry level.
+func NewLogger(cfg zap.Config, opts ...zap.Option) (*zap.Logger, error) {
+	var paths []string
+	var cores []zapcore.Core
+	for _, output := range cfg.OutputPaths {
+		if !IsSystemLogOutput(output) {
+			paths = append(paths, output)
+			continue
+		}
+		w, err := newSystemLogWriter(output)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, &entryCore{LevelEnabler: cfg.Level, enc: newEncoder(cfg), out: w})
+	}
+	if len(cores) == 0 {
+		return cfg.Build(opts...)
+	}
+
+	cfg.OutputPaths = paths
+	opts = append(opts, zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		if len(paths) == 0 {
+			return zapcore.NewTee(cores...)
+		}
+		return zapcore.NewTee(append([]zapcore.Core{c}, cores...)...)
+	}))
+	return cfg.Build(opts...)
+}
+
+func newSystemLogWriter(output string) (entryWriter, error) {
+	if output == SystemdJournalLogOutput {
+		return newJournalEntryWriter()
+	}
+	u, err := url.Parse(output)
+	if err != nil || u.Host == "" || u.Port() == "" {
+		return nil, fmt.Errorf("invalid syslog log output %q, expected syslog://host:port", output)
+	}
+	return newSyslogEntryWriter(u.Host)
+}
+
+func newEncoder(cfg zap.Config) zapcore.Encoder {
+	if cfg.Encoding == ConsoleLogFormat {
+		return zapcore.NewConsoleEncoder(cfg.EncoderConfig)
+	}
+	return zapcore.NewJSONEncoder(cfg.EncoderConfig)
+}
+
+// entryWriter writes encoded log entries with the priority of their level.
+type entryWriter interface {
+	WriteEntry(ent zapcore.Entry, p []byte) error
+	Sync() error
+}
+
+// entryCore is a zapcore.Core writing to an entryWriter.
+type entryCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	out entryWriter
+}
+
+func (c *entryCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &entryCore{LevelEnabler: c.LevelEnabler, enc: enc, out: c.out}
+}
+
+func (c *entryCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *entryCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	err = c.out.WriteEntry(ent, []byte(strings.TrimSuffix(buf.String(), "\n")))
+	buf.Free()
+	if err != nil {
+		return err
+	}
+	if ent.Level > zapcore.ErrorLevel {
+		// flush before a panic or fatal exit, like zapcore.NewCore
+		c.Sync()
+	}
+	return nil
+}
+
+func (c *entryCore) Sync() error {
+	return c.out.Sync()
+}
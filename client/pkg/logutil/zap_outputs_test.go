@@ -0,0 +1,105 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+// +build !windows
+
+package logutil
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsSystemLogOutput(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{SystemdJournalLogOutput, true},
+		{"syslog://localhost:514", true},
+		{"systemd/journal", false},
+		{"stderr", false},
+		{"/var/log/etcd.log", false},
+	}
+	for _, tt := range tests {
+		if got := IsSystemLogOutput(tt.output); got != tt.want {
+			t.Errorf("IsSystemLogOutput(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestNewLoggerInvalidSyslogOutput(t *testing.T) {
+	cfg := DefaultZapLoggerConfig
+	cfg.OutputPaths = []string{"syslog://localhost"}
+	if _, err := NewLogger(cfg); err == nil {
+		t.Fatal("expected error for syslog output without port")
+	}
+}
+
+func TestNewLoggerSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	file := filepath.Join(t.TempDir(), "etcd.log")
+	cfg := DefaultZapLoggerConfig
+	cfg.OutputPaths = []string{"syslog://" + conn.LocalAddr().String(), file}
+	lg, err := NewLogger(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg.Info("info message")
+	lg.Warn("warn message")
+	lg.Sync()
+
+	// facility daemon (3) * 8 + severity
+	for _, want := range []struct{ pri, msg string }{{"<30>", "info message"}, {"<28>", "warn message"}} {
+		buf := make([]byte, 4096)
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := string(buf[:n])
+		if !strings.HasPrefix(got, want.pri) || !strings.Contains(got, want.msg) {
+			t.Errorf("expected syslog message %q with priority %s, got %q", want.msg, want.pri, got)
+		}
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "info message") || !strings.Contains(string(b), "warn message") {
+		t.Errorf("expected log file to contain both messages, got %q", b)
+	}
+}
+
+func TestNewLoggerJournal(t *testing.T) {
+	cfg := DefaultZapLoggerConfig
+	cfg.OutputPaths = []string{SystemdJournalLogOutput}
+	lg, err := NewLogger(cfg)
+	if err != nil {
+		t.Skip(err)
+	}
+	lg.Info("TestNewLoggerJournal")
+	lg.Sync()
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+// +build !windows
+
+package logutil
+
+import (
+	"log/syslog"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type syslogEntryWriter struct {
+	w *syslog.Writer
+}
+
+func newSyslogEntryWriter(addr string) (entryWriter, error) {
+	w, err := syslog.Dial("udp", addr, syslog.LOG_DAEMON|syslog.LOG_INFO, filepath.Base(os.Args[0]))
+	if err != nil {
+		return nil, err
+	}
+	return &syslogEntryWriter{w: w}, nil
+}
+
+func (s *syslogEntryWriter) WriteEntry(ent zapcore.Entry, p []byte) error {
+	msg := string(p)
+	switch ent.Level {
+	case zapcore.DebugLevel:
+		return s.w.Debug(msg)
+	case zapcore.InfoLevel:
+		return s.w.Info(msg)
+	case zapcore.WarnLevel:
+		return s.w.Warning(msg)
+	case zapcore.ErrorLevel:
+		return s.w.Err(msg)
+	default:
+		return s.w.Crit(msg)
+	}
+}
+
+func (s *syslogEntryWriter) Sync() error { return nil }
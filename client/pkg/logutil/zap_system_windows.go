@@ -0,0 +1,44 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+// +build windows
+
+package logutil
+
+import (
+	"errors"
+	"os"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// stderrEntryWriter writes entries to stderr, as there is no systemd
+// journal on windows.
+type stderrEntryWriter struct{}
+
+func newJournalEntryWriter() (entryWriter, error) {
+	return stderrEntryWriter{}, nil
+}
+
+func (stderrEntryWriter) WriteEntry(_ zapcore.Entry, p []byte) error {
+	_, err := os.Stderr.Write(append(p, '\n'))
+	return err
+}
+
+func (stderrEntryWriter) Sync() error { return os.Stderr.Sync() }
+
+func newSyslogEntryWriter(string) (entryWriter, error) {
+	return nil, errors.New("syslog log output is not supported on windows")
+}
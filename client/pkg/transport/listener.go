@@ -28,6 +28,7 @@ import (
 	"math/big"
 	"net"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -49,13 +50,28 @@ func NewListenerWithOpts(addr, scheme string, opts ...ListenerOption) (net.Liste
 }
 
 func newListener(addr, scheme string, opts ...ListenerOption) (net.Listener, error) {
+	lnOpts := newListenOpts(opts...)
+
+	if lnOpts.preopened != nil {
+		return newPreopenedListener(scheme, lnOpts)
+	}
+
 	if scheme == "unix" || scheme == "unixs" {
 		// unix sockets via unix://laddr
-		return NewUnixListener(addr)
+		ln, err := NewUnixListener(addr)
+		if err != nil {
+			return nil, err
+		}
+		if lnOpts.unixSocketMode != 0 {
+			if err = os.Chmod(addr, lnOpts.unixSocketMode); err != nil {
+				ln.Close()
+				return nil, err
+			}
+		}
+		lnOpts.preopened = ln
+		return newPreopenedListener(scheme, lnOpts)
 	}
 
-	lnOpts := newListenOpts(opts...)
-
 	switch {
 	case lnOpts.IsSocketOpts():
 		// new ListenConfig with socket options.
@@ -102,6 +118,21 @@ func newListener(addr, scheme string, opts ...ListenerOption) (net.Listener, err
 	return wrapTLS(scheme, lnOpts.tlsInfo, lnOpts.Listener)
 }
 
+func newPreopenedListener(scheme string, lnOpts *ListenerOptions) (net.Listener, error) {
+	lnOpts.Listener = lnOpts.preopened
+	if lnOpts.IsTimeout() {
+		lnOpts.Listener = &rwTimeoutListener{
+			Listener:     lnOpts.preopened,
+			readTimeout:  lnOpts.readTimeout,
+			writeTimeout: lnOpts.writeTimeout,
+		}
+	}
+	if lnOpts.skipTLSInfoCheck && !lnOpts.IsTLS() {
+		return lnOpts.Listener, nil
+	}
+	return wrapTLS(scheme, lnOpts.tlsInfo, lnOpts.Listener)
+}
+
 func wrapTLS(scheme string, tlsinfo *TLSInfo, l net.Listener) (net.Listener, error) {
 	if scheme != "https" && scheme != "unixs" {
 		return l, nil
@@ -123,6 +154,15 @@ func newListenConfig(sopts *SocketOpts) (net.ListenConfig, error) {
 	return lc, nil
 }
 
+// X509Source provides a certificate and the trusted CAs verifying the
+// certificates of the peers, both of which may change over time.
+type X509Source interface {
+	// Certificate returns the current certificate.
+	Certificate() (*tls.Certificate, error)
+	// TrustedCAs returns the current trusted CAs.
+	TrustedCAs() *x509.CertPool
+}
+
 type TLSInfo struct {
 	// CertFile is the _server_ cert, it will also be used as a _client_ certificate if ClientCertFile is empty
 	CertFile string
@@ -165,6 +205,18 @@ type TLSInfo struct {
 	// certificate provided by a client.
 	AllowedHostname string
 
+	// AllowedURI is a pattern, as of path.Match, that a URI SAN of the TLS
+	// certificate provided by a client must match, such as the SPIFFE ID
+	// pattern "spiffe://example.org/etcd/*".
+	AllowedURI string
+
+	// X509Source provides the certificate and the trusted CAs in place of
+	// the certificate, key and trusted CA files, such as the X.509 SVIDs of
+	// the SPIFFE Workload API. The certificates of the servers are verified
+	// against the trusted CAs only, not their host names, so AllowedURI
+	// should be set to authorize them.
+	X509Source X509Source
+
 	// Logger logs TLS errors.
 	// If nil, all logs are discarded.
 	Logger *zap.Logger
@@ -172,6 +224,10 @@ type TLSInfo struct {
 	// EmptyCN indicates that the cert must have empty CN.
 	// If true, ClientConfig() will return an error for a cert with non empty CN.
 	EmptyCN bool
+
+	// trustedCAs is set by EnableReload to verify peers against
+	// the trusted CA pool as of the last Reload.
+	trustedCAs *trustedCAPool
 }
 
 func (info TLSInfo) String() string {
@@ -179,7 +235,7 @@ func (info TLSInfo) String() string {
 }
 
 func (info TLSInfo) Empty() bool {
-	return info.CertFile == "" && info.KeyFile == ""
+	return info.CertFile == "" && info.KeyFile == "" && info.X509Source == nil
 }
 
 func SelfCert(lg *zap.Logger, dirpath string, hosts []string, selfSignedCertValidity uint, additionalUsages ...x509.ExtKeyUsage) (info TLSInfo, err error) {
@@ -342,29 +398,15 @@ func SelfCert(lg *zap.Logger, dirpath string, hosts []string, selfSignedCertVali
 // rest of the certificates on every new TLS connection, even when client
 // SNI is empty (e.g. cert only includes IPs).
 func (info TLSInfo) baseConfig() (*tls.Config, error) {
-	if info.KeyFile == "" || info.CertFile == "" {
-		return nil, fmt.Errorf("KeyFile and CertFile must both be present[key: %v, cert: %v]", info.KeyFile, info.CertFile)
+	if info.X509Source == nil {
+		if err := info.checkCertFiles(); err != nil {
+			return nil, err
+		}
 	}
 	if info.Logger == nil {
 		info.Logger = zap.NewNop()
 	}
 
-	_, err := tlsutil.NewCert(info.CertFile, info.KeyFile, info.parseFunc)
-	if err != nil {
-		return nil, err
-	}
-
-	// Perform prevalidation of client cert and key if either are provided. This makes sure we crash before accepting any connections.
-	if (info.ClientKeyFile == "") != (info.ClientCertFile == "") {
-		return nil, fmt.Errorf("ClientKeyFile and ClientCertFile must both be present or both absent: key: %v, cert: %v]", info.ClientKeyFile, info.ClientCertFile)
-	}
-	if info.ClientCertFile != "" {
-		_, err := tlsutil.NewCert(info.ClientCertFile, info.ClientKeyFile, info.parseFunc)
-		if err != nil {
-			return nil, err
-		}
-	}
-
 	cfg := &tls.Config{
 		MinVersion: tls.VersionTLS12,
 		ServerName: info.ServerName,
@@ -390,6 +432,22 @@ func (info TLSInfo) baseConfig() (*tls.Config, error) {
 			return cert.VerifyHostname(info.AllowedHostname) == nil
 		}
 	}
+	if info.AllowedURI != "" {
+		if info.AllowedCN != "" || info.AllowedHostname != "" {
+			return nil, fmt.Errorf("AllowedURI is mutually exclusive with AllowedCN and AllowedHostname (uri=%q, cn=%q, hostname=%q)", info.AllowedURI, info.AllowedCN, info.AllowedHostname)
+		}
+		if _, err := path.Match(info.AllowedURI, ""); err != nil {
+			return nil, fmt.Errorf("invalid AllowedURI %q: %w", info.AllowedURI, err)
+		}
+		verifyCertificate = func(cert *x509.Certificate) bool {
+			for _, u := range cert.URIs {
+				if ok, _ := path.Match(info.AllowedURI, u.String()); ok {
+					return true
+				}
+			}
+			return false
+		}
+	}
 	if verifyCertificate != nil {
 		cfg.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
 			for _, chains := range verifiedChains {
@@ -403,6 +461,19 @@ func (info TLSInfo) baseConfig() (*tls.Config, error) {
 		}
 	}
 
+	if info.X509Source != nil {
+		getCertificate := func() (*tls.Certificate, error) {
+			cert, err := info.X509Source.Certificate()
+			if err != nil {
+				info.Logger.Warn("failed to get certificate from X.509 source", zap.Error(err))
+			}
+			return cert, err
+		}
+		cfg.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) { return getCertificate() }
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) { return getCertificate() }
+		return cfg, nil
+	}
+
 	// this only reloads certs when there's a client request
 	// TODO: support server-side refresh (e.g. inotify, SIGHUP), caching
 	cfg.GetCertificate = func(clientHello *tls.ClientHelloInfo) (cert *tls.Certificate, err error) {
@@ -458,6 +529,28 @@ func (info TLSInfo) baseConfig() (*tls.Config, error) {
 	return cfg, nil
 }
 
+// checkCertFiles checks that the certificates and keys load. This makes sure
+// we crash before accepting any connections.
+func (info TLSInfo) checkCertFiles() error {
+	if info.KeyFile == "" || info.CertFile == "" {
+		return fmt.Errorf("KeyFile and CertFile must both be present[key: %v, cert: %v]", info.KeyFile, info.CertFile)
+	}
+	if _, err := tlsutil.NewCert(info.CertFile, info.KeyFile, info.parseFunc); err != nil {
+		return err
+	}
+
+	// Perform prevalidation of client cert and key if either are provided.
+	if (info.ClientKeyFile == "") != (info.ClientCertFile == "") {
+		return fmt.Errorf("ClientKeyFile and ClientCertFile must both be present or both absent: key: %v, cert: %v]", info.ClientKeyFile, info.ClientCertFile)
+	}
+	if info.ClientCertFile != "" {
+		if _, err := tlsutil.NewCert(info.ClientCertFile, info.ClientKeyFile, info.parseFunc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // cafiles returns a list of CA file paths.
 func (info TLSInfo) cafiles() []string {
 	cs := make([]string, 0)
@@ -479,7 +572,7 @@ func (info TLSInfo) ServerConfig() (*tls.Config, error) {
 	}
 
 	cfg.ClientAuth = tls.NoClientCert
-	if info.TrustedCAFile != "" || info.ClientCertAuth {
+	if info.TrustedCAFile != "" || info.ClientCertAuth || info.X509Source != nil {
 		cfg.ClientAuth = tls.RequireAndVerifyClientCert
 	}
 
@@ -502,6 +595,13 @@ func (info TLSInfo) ServerConfig() (*tls.Config, error) {
 	// setting Max TLS version to TLS 1.2 for go 1.13
 	cfg.MaxVersion = tls.VersionTLS12
 
+	switch {
+	case info.X509Source != nil:
+		info.reloadableServerConfig(cfg, info.X509Source.TrustedCAs)
+	case info.trustedCAs != nil && len(cs) > 0:
+		info.reloadableServerConfig(cfg, info.trustedCAs.get)
+	}
+
 	return cfg, nil
 }
 
@@ -532,7 +632,7 @@ func (info TLSInfo) ClientConfig() (*tls.Config, error) {
 		cfg.InsecureSkipVerify = true
 	}
 
-	if info.EmptyCN {
+	if info.EmptyCN && info.X509Source == nil {
 		hasNonEmptyCN := false
 		cn := ""
 		_, err := tlsutil.NewCert(info.CertFile, info.KeyFile, func(certPEMBlock []byte, keyPEMBlock []byte) (tls.Certificate, error) {
@@ -561,6 +661,14 @@ func (info TLSInfo) ClientConfig() (*tls.Config, error) {
 	// setting Max TLS version to TLS 1.2 for go 1.13
 	cfg.MaxVersion = tls.VersionTLS12
 
+	switch {
+	case cfg.InsecureSkipVerify:
+	case info.X509Source != nil:
+		info.reloadableClientConfig(cfg, info.X509Source.TrustedCAs, false)
+	case info.trustedCAs != nil && len(cs) > 0:
+		info.reloadableClientConfig(cfg, info.trustedCAs.get, true)
+	}
+
 	return cfg, nil
 }
 
@@ -16,6 +16,7 @@ package transport
 
 import (
 	"net"
+	"os"
 	"time"
 )
 
@@ -23,6 +24,8 @@ type ListenerOptions struct {
 	Listener     net.Listener
 	ListenConfig net.ListenConfig
 
+	preopened        net.Listener
+	unixSocketMode   os.FileMode
 	socketOpts       *SocketOpts
 	tlsInfo          *TLSInfo
 	skipTLSInfoCheck bool
@@ -88,3 +91,15 @@ func WithTLSInfo(t *TLSInfo) ListenerOption {
 func WithSkipTLSInfoCheck(skip bool) ListenerOption {
 	return func(lo *ListenerOptions) { lo.skipTLSInfoCheck = skip }
 }
+
+// WithListener serves on an already open listener, e.g. one passed by systemd
+// socket activation, instead of listening on the address. Socket options do
+// not apply to such a listener.
+func WithListener(l net.Listener) ListenerOption {
+	return func(lo *ListenerOptions) { lo.preopened = l }
+}
+
+// WithUnixSocketMode sets the file mode of the socket file of unix listeners.
+func WithUnixSocketMode(mode os.FileMode) ListenerOption {
+	return func(lo *ListenerOptions) { lo.unixSocketMode = mode }
+}
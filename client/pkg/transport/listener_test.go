@@ -15,12 +15,18 @@
 package transport
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
+	"math/big"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -117,6 +123,57 @@ func TestNewListenerWithOpts(t *testing.T) {
 	}
 }
 
+func TestNewListenerWithListener(t *testing.T) {
+	tlsInfo, err := createSelfCert(t)
+	if err != nil {
+		t.Fatalf("unable to create cert: %v", err)
+	}
+
+	tests := map[string]struct {
+		opts   []ListenerOption
+		scheme string
+		tls    bool
+	}{
+		"http scheme": {
+			scheme: "http",
+		},
+		"http scheme with timeout": {
+			opts:   []ListenerOption{WithTimeout(5*time.Second, 5*time.Second)},
+			scheme: "http",
+		},
+		"https scheme with TLSInfo": {
+			opts:   []ListenerOption{WithTLSInfo(tlsInfo)},
+			scheme: "https",
+			tls:    true,
+		},
+		"https scheme no TLSInfo with skip check": {
+			opts:   []ListenerOption{WithSkipTLSInfoCheck(true)},
+			scheme: "https",
+		},
+	}
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the address is not listened on when a listener is given
+			ln, err := NewListenerWithOpts("127.0.0.1:-1", test.scheme, append(test.opts, WithListener(l))...)
+			if err != nil {
+				l.Close()
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer ln.Close()
+			if ln.Addr().String() != l.Addr().String() {
+				t.Fatalf("addr = %s, want %s", ln.Addr(), l.Addr())
+			}
+			if _, ok := ln.(*tlsListener); ok != test.tls {
+				t.Fatalf("TLS listener = %v, want %v", ok, test.tls)
+			}
+		})
+	}
+}
+
 func TestNewListenerWithSocketOpts(t *testing.T) {
 	tlsInfo, err := createSelfCert(t)
 	if err != nil {
@@ -516,6 +573,37 @@ func TestNewListenerUnixSocket(t *testing.T) {
 	l.Close()
 }
 
+func TestNewListenerUnixSocketMode(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "testsocket")
+	l, err := NewListenerWithOpts(addr, "unix", WithUnixSocketMode(0600))
+	if err != nil {
+		t.Fatalf("error listening on unix socket (%v)", err)
+	}
+	defer l.Close()
+	fi, err := os.Stat(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Fatalf("socket mode = %v, want %v", fi.Mode().Perm(), os.FileMode(0600))
+	}
+}
+
+func TestNewListenerUnixsSocketTLS(t *testing.T) {
+	tlsInfo, err := createSelfCert(t)
+	if err != nil {
+		t.Fatalf("unable to create cert: %v", err)
+	}
+	l, err := NewListenerWithOpts(filepath.Join(t.TempDir(), "testsocket"), "unixs", WithTLSInfo(tlsInfo))
+	if err != nil {
+		t.Fatalf("error listening on unix socket (%v)", err)
+	}
+	defer l.Close()
+	if _, ok := l.(*tlsListener); !ok {
+		t.Fatalf("expected TLS listener, got %T", l)
+	}
+}
+
 // TestNewListenerTLSInfoSelfCert tests that a new certificate accepts connections.
 func TestNewListenerTLSInfoSelfCert(t *testing.T) {
 	tmpdir := t.TempDir()
@@ -558,3 +646,127 @@ func TestSocktOptsEmpty(t *testing.T) {
 		}
 	}
 }
+
+type staticX509Source struct {
+	cert *tls.Certificate
+	cas  *x509.CertPool
+}
+
+func (s *staticX509Source) Certificate() (*tls.Certificate, error) { return s.cert, nil }
+func (s *staticX509Source) TrustedCAs() *x509.CertPool             { return s.cas }
+
+// newStaticX509Source returns a source of a self-signed certificate of the
+// URI SAN, trusting the certificate only.
+func newStaticX509Source(t *testing.T, uri string) *staticX509Source {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		URIs:                  []*url.URL{u},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cas := x509.NewCertPool()
+	cas.AddCert(cert)
+	return &staticX509Source{cert: &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}, cas: cas}
+}
+
+// TestTLSInfoX509Source ensures that the certificates of an X509Source are
+// used and verified against its trusted CAs and the AllowedURI pattern.
+func TestTLSInfoX509Source(t *testing.T) {
+	src := newStaticX509Source(t, "spiffe://example.org/etcd/member1")
+	other := newStaticX509Source(t, "spiffe://example.org/etcd/member2")
+	src.cas.AddCert(other.cert.Leaf)
+	other.cas = src.cas
+
+	tests := []struct {
+		allowedURI string
+		client     *staticX509Source
+		ok         bool
+	}{
+		{"", other, true},
+		{"spiffe://example.org/etcd/*", other, true},
+		{"spiffe://example.org/etcd/member2", other, true},
+		{"spiffe://example.org/etcd/member3", other, false},
+		{"spiffe://example.org/*", other, false},
+		// not trusted
+		{"", newStaticX509Source(t, "spiffe://example.org/etcd/member2"), false},
+	}
+	for i, tt := range tests {
+		sinfo := TLSInfo{X509Source: src, AllowedURI: tt.allowedURI}
+		if sinfo.Empty() {
+			t.Fatalf("#%d: expected TLSInfo with X509Source to be non-empty", i)
+		}
+		scfg, err := sinfo.ServerConfig()
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		ccfg, err := TLSInfo{X509Source: tt.client, AllowedURI: "spiffe://example.org/etcd/member1"}.ClientConfig()
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+
+		serr, cerr := tlsHandshake(t, scfg, ccfg)
+		if ok := serr == nil && cerr == nil; ok != tt.ok {
+			t.Errorf("#%d: handshake succeeded = %v, want %v (server: %v, client: %v)", i, ok, tt.ok, serr, cerr)
+		}
+	}
+}
+
+// tlsHandshake returns the errors of the server and the client handshaking
+// over a TCP connection, so that an alert cannot block either of them.
+func tlsHandshake(t *testing.T, scfg, ccfg *tls.Config) (serr, cerr error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	serrc := make(chan error, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			serrc <- err
+			return
+		}
+		defer conn.Close()
+		serrc <- tls.Server(conn, scfg).Handshake()
+	}()
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cerr = tls.Client(conn, ccfg).Handshake()
+	conn.Close()
+	return <-serrc, cerr
+}
+
+func TestTLSInfoAllowedURIInvalid(t *testing.T) {
+	src := newStaticX509Source(t, "spiffe://example.org/etcd/member1")
+	for _, info := range []TLSInfo{
+		{X509Source: src, AllowedURI: "spiffe://example.org/etcd/*", AllowedCN: "member1"},
+		{X509Source: src, AllowedURI: "spiffe://example.org/etcd/*", AllowedHostname: "127.0.0.1"},
+		{X509Source: src, AllowedURI: "spiffe://example.org/etcd/["},
+	} {
+		if _, err := info.ServerConfig(); err == nil {
+			t.Errorf("expected error for %+v", info)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"sync"
+
+	"go.etcd.io/etcd/client/pkg/v3/tlsutil"
+)
+
+// trustedCAPool holds the CA pool loaded from the trusted CA files. It is
+// shared by all copies of the TLSInfo it was enabled on, so that the TLS
+// configurations created from them observe a reloaded pool.
+type trustedCAPool struct {
+	mu   sync.RWMutex
+	pool *x509.CertPool
+}
+
+func (p *trustedCAPool) get() *x509.CertPool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.pool
+}
+
+func (p *trustedCAPool) set(pool *x509.CertPool) {
+	p.mu.Lock()
+	p.pool = pool
+	p.mu.Unlock()
+}
+
+// EnableReload makes the TLS configurations created from this TLSInfo
+// verify peers against the trusted CA pool as of the last Reload, rather
+// than the pool loaded when the configuration was created. It must be
+// called before ServerConfig or ClientConfig.
+func (info *TLSInfo) EnableReload() error {
+	if info.trustedCAs != nil {
+		return nil
+	}
+	cas := &trustedCAPool{}
+	if cs := info.cafiles(); len(cs) > 0 {
+		cp, err := tlsutil.NewCertPool(cs)
+		if err != nil {
+			return err
+		}
+		cas.set(cp)
+	}
+	info.trustedCAs = cas
+	return nil
+}
+
+// Reload re-reads the certificate, key and trusted CA files. Certificates
+// are loaded from disk on every handshake, so Reload only validates them;
+// the trusted CA pool is swapped only if EnableReload was called. Nothing
+// is changed if any of the files fails to load.
+func (info TLSInfo) Reload() error {
+	if info.Empty() || info.X509Source != nil {
+		return nil
+	}
+	if _, err := tlsutil.NewCert(info.CertFile, info.KeyFile, info.parseFunc); err != nil {
+		return fmt.Errorf("failed to load cert %q and key %q: %w", info.CertFile, info.KeyFile, err)
+	}
+	if info.ClientCertFile != "" {
+		if _, err := tlsutil.NewCert(info.ClientCertFile, info.ClientKeyFile, info.parseFunc); err != nil {
+			return fmt.Errorf("failed to load client cert %q and key %q: %w", info.ClientCertFile, info.ClientKeyFile, err)
+		}
+	}
+	cs := info.cafiles()
+	if info.trustedCAs == nil || len(cs) == 0 {
+		return nil
+	}
+	cp, err := tlsutil.NewCertPool(cs)
+	if err != nil {
+		return fmt.Errorf("failed to load trusted CA files %q: %w", cs, err)
+	}
+	// a partially written file must not leave the member trusting no one
+	if cp.Equal(x509.NewCertPool()) {
+		return fmt.Errorf("no certificates found in trusted CA files %q", cs)
+	}
+	info.trustedCAs.set(cp)
+	return nil
+}
+
+// reloadableServerConfig makes cfg verify client certificates against the
+// current trusted CA pool, returned by roots.
+func (info TLSInfo) reloadableServerConfig(cfg *tls.Config, roots func() *x509.CertPool) {
+	base := cfg.Clone()
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		c := base.Clone()
+		c.ClientCAs = roots()
+		return c, nil
+	}
+}
+
+// reloadableClientConfig makes cfg verify server certificates against the
+// current trusted CA pool, returned by roots, and their host names if
+// verifyName. The standard verification is replaced since RootCAs cannot be
+// changed once the configuration is in use. The IP addresses are not sent
+// as server name, so the connections to them must be made by dialTLS,
+// unless cfg has a ServerName.
+func (info TLSInfo) reloadableClientConfig(cfg *tls.Config, roots func() *x509.CertPool, verifyName bool) {
+	verifyPeer := cfg.VerifyPeerCertificate
+	serverName := cfg.ServerName
+	cfg.InsecureSkipVerify = true
+	cfg.VerifyPeerCertificate = nil
+	cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return fmt.Errorf("tls: server did not provide a certificate")
+		}
+		opts := x509.VerifyOptions{
+			Roots:         roots(),
+			Intermediates: x509.NewCertPool(),
+		}
+		if verifyName {
+			opts.DNSName = cs.ServerName
+			if opts.DNSName == "" {
+				opts.DNSName = serverName
+			}
+			if opts.DNSName == "" {
+				return fmt.Errorf("tls: no server name to verify the server certificate against")
+			}
+		}
+		for _, cert := range cs.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		chains, err := cs.PeerCertificates[0].Verify(opts)
+		if err != nil {
+			return err
+		}
+		if verifyPeer != nil {
+			return verifyPeer(nil, chains)
+		}
+		return nil
+	}
+}
+
+// dialTLS dials a TLS connection to addr with cfg, verifying the server
+// certificate against the dialed host unless cfg has a ServerName. The
+// connection state lacks the host if it is an IP address, which is not sent
+// as server name, and the reloadable configurations need it.
+func dialTLS(ctx context.Context, dial func(ctx context.Context, network, addr string) (net.Conn, error), cfg *tls.Config, network, addr string) (net.Conn, error) {
+	conn, err := dial(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	c := cfg.Clone()
+	if c.ServerName == "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		c.ServerName = host
+	}
+	if verify := c.VerifyConnection; verify != nil {
+		serverName := c.ServerName
+		c.VerifyConnection = func(cs tls.ConnectionState) error {
+			if cs.ServerName == "" {
+				cs.ServerName = serverName
+			}
+			return verify(cs)
+		}
+	}
+	tconn := tls.Client(conn, c)
+	if err = tconn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tconn, nil
+}
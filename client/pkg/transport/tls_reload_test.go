@@ -0,0 +1,189 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/client/pkg/v3/tlsutil"
+)
+
+func copyFile(t *testing.T, src, dst string) {
+	b, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(dst, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestTLSInfoReloadServerCA ensures that servers verify clients against
+// the trusted CA pool loaded by the last Reload.
+func TestTLSInfoReloadServerCA(t *testing.T) {
+	info1, err := createSelfCert(t)
+	if err != nil {
+		t.Fatalf("unable to create cert: %v", err)
+	}
+	info2, err := createSelfCert(t)
+	if err != nil {
+		t.Fatalf("unable to create cert: %v", err)
+	}
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	copyFile(t, info1.CertFile, caFile)
+
+	info := TLSInfo{CertFile: info1.CertFile, KeyFile: info1.KeyFile, TrustedCAFile: caFile}
+	if err = info.EnableReload(); err != nil {
+		t.Fatal(err)
+	}
+	scfg, err := info.ServerConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkClientCAs := func(certFile string) {
+		t.Helper()
+		want, err := tlsutil.NewCertPool([]string{certFile})
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := scfg.GetConfigForClient(&tls.ClientHelloInfo{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !c.ClientCAs.Equal(want) {
+			t.Fatalf("unexpected client CAs, want CAs from %s", certFile)
+		}
+	}
+	checkClientCAs(info1.CertFile)
+
+	copyFile(t, info2.CertFile, caFile)
+	checkClientCAs(info1.CertFile)
+	if err = info.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	checkClientCAs(info2.CertFile)
+
+	// a broken CA file keeps the last loaded pool
+	if err = os.WriteFile(caFile, []byte("broken"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = info.Reload(); err == nil {
+		t.Fatal("expected error reloading broken CA file")
+	}
+	checkClientCAs(info2.CertFile)
+}
+
+// TestTLSInfoReloadClientCA ensures that clients verify servers against
+// the trusted CA pool loaded by the last Reload.
+func TestTLSInfoReloadClientCA(t *testing.T) {
+	info1, err := createSelfCert(t)
+	if err != nil {
+		t.Fatalf("unable to create cert: %v", err)
+	}
+	info2, err := createSelfCert(t)
+	if err != nil {
+		t.Fatalf("unable to create cert: %v", err)
+	}
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	copyFile(t, info1.CertFile, caFile)
+
+	info := TLSInfo{CertFile: info1.CertFile, KeyFile: info1.KeyFile, TrustedCAFile: caFile, ServerName: "127.0.0.1"}
+	if err = info.EnableReload(); err != nil {
+		t.Fatal(err)
+	}
+	ccfg, err := info.ClientConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	scfg, err := (TLSInfo{CertFile: info2.CertFile, KeyFile: info2.KeyFile}).ServerConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handshake := func() error {
+		sconn, cconn := net.Pipe()
+		defer sconn.Close()
+		defer cconn.Close()
+		go tls.Server(sconn, scfg).Handshake()
+		return tls.Client(cconn, ccfg).Handshake()
+	}
+
+	if err = handshake(); err == nil {
+		t.Fatal("expected handshake to fail with untrusted server certificate")
+	}
+
+	copyFile(t, info2.CertFile, caFile)
+	if err = info.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if err = handshake(); err != nil {
+		t.Fatalf("expected handshake to succeed after reload, got %v", err)
+	}
+}
+
+// TestTLSInfoReloadVerifyIPSAN ensures that the transports created from a
+// reloadable TLSInfo verify the IP SANs of the server certificates, which
+// are not sent as server name.
+func TestTLSInfoReloadVerifyIPSAN(t *testing.T) {
+	tests := []struct {
+		host    string
+		wantErr bool
+	}{
+		{host: "127.0.0.1"},
+		{host: "10.0.0.1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			sinfo, err := createSelfCertEx(t, tt.host)
+			if err != nil {
+				t.Fatalf("unable to create cert: %v", err)
+			}
+			scfg, err := sinfo.ServerConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			ts.Listener = tls.NewListener(ts.Listener, scfg)
+			ts.Start()
+			defer ts.Close()
+
+			info := TLSInfo{TrustedCAFile: sinfo.CertFile}
+			if err = info.EnableReload(); err != nil {
+				t.Fatal(err)
+			}
+			tr, err := NewTransport(info, time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tr.CloseIdleConnections()
+
+			resp, err := (&http.Client{Transport: tr}).Get("https://" + ts.Listener.Addr().String())
+			if err == nil {
+				resp.Body.Close()
+			}
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
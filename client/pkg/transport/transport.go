@@ -41,6 +41,11 @@ func NewTransport(info TLSInfo, dialtimeoutd time.Duration) (*http.Transport, er
 		TLSHandshakeTimeout: 10 * time.Second,
 		TLSClientConfig:     cfg,
 	}
+	if cfg.VerifyConnection != nil {
+		t.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialTLS(ctx, t.DialContext, cfg, network, addr)
+		}
+	}
 
 	dialer := &net.Dialer{
 		Timeout:   dialtimeoutd,
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"go.etcd.io/etcd/api/v3/authpb"
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
@@ -42,15 +43,31 @@ type (
 	AuthRoleDeleteResponse           pb.AuthRoleDeleteResponse
 	AuthUserListResponse             pb.AuthUserListResponse
 	AuthRoleListResponse             pb.AuthRoleListResponse
-
-	PermissionType authpb.Permission_Type
-	Permission     authpb.Permission
+	AuthLockoutListResponse          pb.AuthLockoutListResponse
+	AuthLockoutClearResponse         pb.AuthLockoutClearResponse
+	AuthSessionListResponse          pb.AuthSessionListResponse
+	AuthSessionRevokeResponse        pb.AuthSessionRevokeResponse
+
+	PermissionType      authpb.Permission_Type
+	Permission          authpb.Permission
+	AdminPermissionType authpb.AdminPermission
 )
 
 const (
 	PermRead      = authpb.READ
 	PermWrite     = authpb.WRITE
 	PermReadWrite = authpb.READWRITE
+	PermWatch     = authpb.WATCH
+	PermRange     = authpb.RANGE
+)
+
+const (
+	AdminPermMember      = AdminPermissionType(authpb.MEMBER)
+	AdminPermAlarm       = AdminPermissionType(authpb.ALARM)
+	AdminPermDefragment  = AdminPermissionType(authpb.DEFRAGMENT)
+	AdminPermSnapshot    = AdminPermissionType(authpb.SNAPSHOT)
+	AdminPermAuth        = AdminPermissionType(authpb.AUTH)
+	AdminPermMaintenance = AdminPermissionType(authpb.MAINTENANCE)
 )
 
 type UserAddOptions authpb.UserAddOptions
@@ -83,6 +100,11 @@ type Auth interface {
 	// UserGrantRole grants a role to a user.
 	UserGrantRole(ctx context.Context, user string, role string) (*AuthUserGrantRoleResponse, error)
 
+	// UserGrantRoleUntil grants a role to a user until the given time, when
+	// the role is revoked from the user. Granting a role already granted
+	// changes the time, or grants it permanently if the time is zero.
+	UserGrantRoleUntil(ctx context.Context, user string, role string, expiresAt time.Time) (*AuthUserGrantRoleResponse, error)
+
 	// UserGet gets a detailed information of a user.
 	UserGet(ctx context.Context, name string) (*AuthUserGetResponse, error)
 
@@ -109,6 +131,28 @@ type Auth interface {
 
 	// RoleDelete deletes a role.
 	RoleDelete(ctx context.Context, role string) (*AuthRoleDeleteResponse, error)
+
+	// RoleGrantAdminPermission grants cluster administration permissions to a role.
+	RoleGrantAdminPermission(ctx context.Context, role string, perms ...AdminPermissionType) (*AuthRoleGrantPermissionResponse, error)
+
+	// RoleRevokeAdminPermission revokes cluster administration permissions from a role.
+	RoleRevokeAdminPermission(ctx context.Context, role string, perms ...AdminPermissionType) (*AuthRoleRevokePermissionResponse, error)
+
+	// AuthLockoutList lists the users and client addresses locked out of
+	// authenticating on the member the request is sent to.
+	AuthLockoutList(ctx context.Context) (*AuthLockoutListResponse, error)
+
+	// AuthLockoutClear lifts the lockouts of a user or a client address on the
+	// member the request is sent to, or all of them if both are empty.
+	AuthLockoutClear(ctx context.Context, user, address string) (*AuthLockoutClearResponse, error)
+
+	// AuthSessionList lists the sessions of the auth tokens currently valid on
+	// the member the request is sent to, of the user if not empty.
+	AuthSessionList(ctx context.Context, user string) (*AuthSessionListResponse, error)
+
+	// AuthSessionRevoke revokes the token of the session id, or the tokens of
+	// all the sessions of the user if id is zero.
+	AuthSessionRevoke(ctx context.Context, id uint64, user string) (*AuthSessionRevokeResponse, error)
 }
 
 type authClient struct {
@@ -177,6 +221,15 @@ func (auth *authClient) UserGrantRole(ctx context.Context, user string, role str
 	return (*AuthUserGrantRoleResponse)(resp), toErr(ctx, err)
 }
 
+func (auth *authClient) UserGrantRoleUntil(ctx context.Context, user string, role string, expiresAt time.Time) (*AuthUserGrantRoleResponse, error) {
+	var exp int64
+	if !expiresAt.IsZero() {
+		exp = expiresAt.Unix()
+	}
+	resp, err := auth.remote.UserGrantRole(ctx, &pb.AuthUserGrantRoleRequest{User: user, Role: role, ExpiresAt: exp}, auth.callOpts...)
+	return (*AuthUserGrantRoleResponse)(resp), toErr(ctx, err)
+}
+
 func (auth *authClient) UserGet(ctx context.Context, name string) (*AuthUserGetResponse, error) {
 	resp, err := auth.remote.UserGet(ctx, &pb.AuthUserGetRequest{Name: name}, auth.callOpts...)
 	return (*AuthUserGetResponse)(resp), toErr(ctx, err)
@@ -227,6 +280,44 @@ func (auth *authClient) RoleDelete(ctx context.Context, role string) (*AuthRoleD
 	return (*AuthRoleDeleteResponse)(resp), toErr(ctx, err)
 }
 
+func (auth *authClient) RoleGrantAdminPermission(ctx context.Context, role string, perms ...AdminPermissionType) (*AuthRoleGrantPermissionResponse, error) {
+	resp, err := auth.remote.RoleGrantPermission(ctx, &pb.AuthRoleGrantPermissionRequest{Name: role, AdminPerms: toAdminPerms(perms)}, auth.callOpts...)
+	return (*AuthRoleGrantPermissionResponse)(resp), toErr(ctx, err)
+}
+
+func (auth *authClient) RoleRevokeAdminPermission(ctx context.Context, role string, perms ...AdminPermissionType) (*AuthRoleRevokePermissionResponse, error) {
+	resp, err := auth.remote.RoleRevokePermission(ctx, &pb.AuthRoleRevokePermissionRequest{Role: role, AdminPerms: toAdminPerms(perms)}, auth.callOpts...)
+	return (*AuthRoleRevokePermissionResponse)(resp), toErr(ctx, err)
+}
+
+func (auth *authClient) AuthLockoutList(ctx context.Context) (*AuthLockoutListResponse, error) {
+	resp, err := auth.remote.AuthLockoutList(ctx, &pb.AuthLockoutListRequest{}, auth.callOpts...)
+	return (*AuthLockoutListResponse)(resp), toErr(ctx, err)
+}
+
+func (auth *authClient) AuthLockoutClear(ctx context.Context, user, address string) (*AuthLockoutClearResponse, error) {
+	resp, err := auth.remote.AuthLockoutClear(ctx, &pb.AuthLockoutClearRequest{User: user, Address: address}, auth.callOpts...)
+	return (*AuthLockoutClearResponse)(resp), toErr(ctx, err)
+}
+
+func (auth *authClient) AuthSessionList(ctx context.Context, user string) (*AuthSessionListResponse, error) {
+	resp, err := auth.remote.AuthSessionList(ctx, &pb.AuthSessionListRequest{User: user}, auth.callOpts...)
+	return (*AuthSessionListResponse)(resp), toErr(ctx, err)
+}
+
+func (auth *authClient) AuthSessionRevoke(ctx context.Context, id uint64, user string) (*AuthSessionRevokeResponse, error) {
+	resp, err := auth.remote.AuthSessionRevoke(ctx, &pb.AuthSessionRevokeRequest{ID: id, User: user}, auth.callOpts...)
+	return (*AuthSessionRevokeResponse)(resp), toErr(ctx, err)
+}
+
+func toAdminPerms(perms []AdminPermissionType) []authpb.AdminPermission {
+	ps := make([]authpb.AdminPermission, len(perms))
+	for i, p := range perms {
+		ps[i] = authpb.AdminPermission(p)
+	}
+	return ps
+}
+
 func StrToPermissionType(s string) (PermissionType, error) {
 	val, ok := authpb.Permission_Type_value[strings.ToUpper(s)]
 	if ok {
@@ -234,3 +325,11 @@ func StrToPermissionType(s string) (PermissionType, error) {
 	}
 	return PermissionType(-1), fmt.Errorf("invalid permission type: %s", s)
 }
+
+func StrToAdminPermissionType(s string) (AdminPermissionType, error) {
+	val, ok := authpb.AdminPermission_value[strings.ToUpper(s)]
+	if ok {
+		return AdminPermissionType(val), nil
+	}
+	return AdminPermissionType(-1), fmt.Errorf("invalid admin permission type: %s", s)
+}
@@ -301,3 +301,7 @@ func (mc *mockCluster) MemberUpdate(ctx context.Context, id uint64, peerAddrs []
 func (mc *mockCluster) MemberPromote(ctx context.Context, id uint64) (*MemberPromoteResponse, error) {
 	return nil, nil
 }
+
+func (mc *mockCluster) MemberReplace(ctx context.Context, add []*etcdserverpb.MemberAddRequest, remove []uint64) (*MemberReplaceResponse, error) {
+	return nil, nil
+}
@@ -30,6 +30,7 @@ type (
 	MemberRemoveResponse  pb.MemberRemoveResponse
 	MemberUpdateResponse  pb.MemberUpdateResponse
 	MemberPromoteResponse pb.MemberPromoteResponse
+	MemberReplaceResponse pb.MemberReplaceResponse
 )
 
 type Cluster interface {
@@ -50,6 +51,10 @@ type Cluster interface {
 
 	// MemberPromote promotes a member from raft learner (non-voting) to raft voting member.
 	MemberPromote(ctx context.Context, id uint64) (*MemberPromoteResponse, error)
+
+	// MemberReplace adds and removes several members at once through joint consensus,
+	// instead of adding and removing them one by one.
+	MemberReplace(ctx context.Context, add []*pb.MemberAddRequest, remove []uint64) (*MemberReplaceResponse, error)
 }
 
 type cluster struct {
@@ -139,3 +144,19 @@ func (c *cluster) MemberPromote(ctx context.Context, id uint64) (*MemberPromoteR
 	}
 	return (*MemberPromoteResponse)(resp), nil
 }
+
+func (c *cluster) MemberReplace(ctx context.Context, add []*pb.MemberAddRequest, remove []uint64) (*MemberReplaceResponse, error) {
+	// fail-fast before panic in rafthttp
+	for _, m := range add {
+		if _, err := types.NewURLs(m.PeerURLs); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &pb.MemberReplaceRequest{Add: add, Remove: remove}
+	resp, err := c.remote.MemberReplace(ctx, r, c.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*MemberReplaceResponse)(resp), nil
+}
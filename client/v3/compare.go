@@ -54,6 +54,14 @@ func Compare(cmp Cmp, result string, v interface{}) Cmp {
 			panic("bad compare value")
 		}
 		cmp.TargetUnion = &pb.Compare_Value{Value: []byte(val)}
+	case pb.Compare_VALUE_INT:
+		cmp.TargetUnion = &pb.Compare_ValueInt{ValueInt: mustInt64(v)}
+	case pb.Compare_VALUE_PREFIX:
+		val, ok := v.(string)
+		if !ok {
+			panic("bad compare value")
+		}
+		cmp.TargetUnion = &pb.Compare_ValuePrefix{ValuePrefix: []byte(val)}
 	case pb.Compare_VERSION:
 		cmp.TargetUnion = &pb.Compare_Version{Version: mustInt64(v)}
 	case pb.Compare_CREATE:
@@ -72,6 +80,18 @@ func Value(key string) Cmp {
 	return Cmp{Key: []byte(key), Target: pb.Compare_VALUE}
 }
 
+// ValueInt compares a key's value parsed as a base 10 integer to an int64.
+// The comparison fails if the value is not an integer.
+func ValueInt(key string) Cmp {
+	return Cmp{Key: []byte(key), Target: pb.Compare_VALUE_INT}
+}
+
+// ValuePrefix compares a key's value truncated to the length of a prefix to
+// the prefix, so that "=" holds if the value has the prefix.
+func ValuePrefix(key string) Cmp {
+	return Cmp{Key: []byte(key), Target: pb.Compare_VALUE_PREFIX}
+}
+
 func Version(key string) Cmp {
 	return Cmp{Key: []byte(key), Target: pb.Compare_VERSION}
 }
@@ -90,6 +110,18 @@ func LeaseValue(key string) Cmp {
 	return Cmp{Key: []byte(key), Target: pb.Compare_LEASE}
 }
 
+// KeyExists holds if the key exists. When passed WithPrefix() or
+// WithRange(), it holds if any key of the range exists.
+func KeyExists(key string) Cmp {
+	return Compare(CreateRevision(key), ">", 0)
+}
+
+// KeyMissing holds if the key does not exist. When passed WithPrefix() or
+// WithRange(), it holds if no key of the range exists.
+func KeyMissing(key string) Cmp {
+	return Compare(CreateRevision(key), "=", 0)
+}
+
 // KeyBytes returns the byte slice holding with the comparison key.
 func (cmp *Cmp) KeyBytes() []byte { return cmp.Key }
 
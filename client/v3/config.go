@@ -89,6 +89,12 @@ type Config struct {
 	// PermitWithoutStream when set will allow client to send keepalive pings to server without any active streams(RPCs).
 	PermitWithoutStream bool `json:"permit-without-stream"`
 
+	// WatchCompression lists the codecs of the watch events the client accepts,
+	// WatchCompressionZstd or WatchCompressionGzip, in order of preference. The
+	// server compresses the large events of each watch stream with the first
+	// codec it supports. By default the events are sent uncompressed.
+	WatchCompression []string `json:"watch-compression"`
+
 	// TODO: support custom balancer picker
 }
 
@@ -36,6 +36,20 @@ func WithRequireLeader(ctx context.Context) context.Context {
 	return metadata.NewOutgoingContext(ctx, copied)
 }
 
+// WithDebugTrace asks the server to attach the timing breakdown of the
+// requests sent with ctx to the Trace of their response header. It requires
+// admin permission if auth is enabled.
+func WithDebugTrace(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok { // no outgoing metadata ctx key, create one
+		md = metadata.Pairs(rpctypes.MetadataDebugTraceKey, rpctypes.MetadataDebugTraceEnabled)
+		return metadata.NewOutgoingContext(ctx, md)
+	}
+	copied := md.Copy() // avoid racey updates
+	copied.Set(rpctypes.MetadataDebugTraceKey, rpctypes.MetadataDebugTraceEnabled)
+	return metadata.NewOutgoingContext(ctx, copied)
+}
+
 // embeds client version
 func withVersion(ctx context.Context) context.Context {
 	md, ok := metadata.FromOutgoingContext(ctx)
@@ -65,3 +65,18 @@ func TestMetadataWithClientAPIVersion(t *testing.T) {
 		t.Fatalf("unexpected metadata for %q %v", rpctypes.MetadataClientAPIVersionKey, ss)
 	}
 }
+
+func TestMetadataWithDebugTrace(t *testing.T) {
+	ctx := WithDebugTrace(WithRequireLeader(context.TODO()))
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		t.Fatal("expected outgoing metadata ctx key")
+	}
+	if ss := md.Get(rpctypes.MetadataRequireLeaderKey); !reflect.DeepEqual(ss, []string{rpctypes.MetadataHasLeader}) {
+		t.Fatalf("unexpected metadata for %q %v", rpctypes.MetadataRequireLeaderKey, ss)
+	}
+	if ss := md.Get(rpctypes.MetadataDebugTraceKey); !reflect.DeepEqual(ss, []string{rpctypes.MetadataDebugTraceEnabled}) {
+		t.Fatalf("unexpected metadata for %q %v", rpctypes.MetadataDebugTraceKey, ss)
+	}
+}
@@ -24,11 +24,17 @@ import (
 )
 
 type (
-	CompactResponse pb.CompactionResponse
-	PutResponse     pb.PutResponse
-	GetResponse     pb.RangeResponse
-	DeleteResponse  pb.DeleteRangeResponse
-	TxnResponse     pb.TxnResponse
+	CompactResponse      pb.CompactionResponse
+	PutResponse          pb.PutResponse
+	GetResponse          pb.RangeResponse
+	DeleteResponse       pb.DeleteRangeResponse
+	TxnResponse          pb.TxnResponse
+	MoveResponse         pb.MoveResponse
+	SetLeaseResponse     pb.SetLeaseResponse
+	IncrementResponse    pb.IncrementResponse
+	PutIfAbsentResponse  pb.PutIfAbsentResponse
+	GetAndDeleteResponse pb.GetAndDeleteResponse
+	BulkWriteResponse    pb.BulkWriteResponse
 )
 
 type KV interface {
@@ -51,6 +57,46 @@ type KV interface {
 	// Delete deletes a key, or optionally using WithRange(end), [key, end).
 	Delete(ctx context.Context, key string, opts ...OpOption) (*DeleteResponse, error)
 
+	// Move moves a key to dst with its value and lease, atomically.
+	// When passed WithPrefix(), Move moves all the keys with the prefix key,
+	// key being replaced by dst in them.
+	// When passed WithOverwrite(), Move replaces the keys existing where
+	// the keys are moved to; the move fails with ErrKeyExists otherwise.
+	// When passed WithPrevKV(), Move returns the key-value pairs moved.
+	Move(ctx context.Context, key, dst string, opts ...OpOption) (*MoveResponse, error)
+
+	// SetLease attaches the lease id to a key without sending its value again,
+	// or detaches its lease if id is NoLease.
+	// When passed WithRange(end), WithPrefix() or WithFromKey(), SetLease sets
+	// the lease of all the keys in the range.
+	// Unless passed WithBumpRevision(), the keys keep their revisions and no
+	// event is generated.
+	SetLease(ctx context.Context, key string, id LeaseID, opts ...OpOption) (*SetLeaseResponse, error)
+
+	// Increment atomically adds delta to the integer value of a key and returns
+	// the new value. A key that does not exist is created with the value 0, or
+	// the one passed WithIncrementInitial(), before delta is added, and with the
+	// lease passed WithLease(); an existing key keeps its lease.
+	// The value is a base 10 integer, padded with leading zeros when passed
+	// WithIncrementWidth(), or 8 big-endian bytes when passed WithIncrementBinary().
+	Increment(ctx context.Context, key string, delta int64, opts ...OpOption) (*IncrementResponse, error)
+
+	// PutIfAbsent puts a key-value pair into etcd if the key does not exist,
+	// with the lease passed WithLease().
+	// If the key exists, Succeeded is false and PrevKv is the existing
+	// key-value pair.
+	PutIfAbsent(ctx context.Context, key, val string, opts ...OpOption) (*PutIfAbsentResponse, error)
+
+	// GetAndDelete deletes a key, returning the key-value pair deleted as
+	// PrevKv, nil if the key did not exist.
+	GetAndDelete(ctx context.Context, key string) (*GetAndDeleteResponse, error)
+
+	// BulkWrite applies many puts and deletes, created by OpPut and OpDelete,
+	// in a single request and revision. Unlike in a Txn, an op failing does
+	// not fail the others: the result of each op, in the order of ops, holds
+	// either its response or its error.
+	BulkWrite(ctx context.Context, ops ...Op) (*BulkWriteResponse, error)
+
 	// Compact compacts etcd KV history before the given rev.
 	Compact(ctx context.Context, rev int64, opts ...CompactOption) (*CompactResponse, error)
 
@@ -126,6 +172,79 @@ func (kv *kv) Delete(ctx context.Context, key string, opts ...OpOption) (*Delete
 	return r.del, toErr(ctx, err)
 }
 
+func (kv *kv) Move(ctx context.Context, key, dst string, opts ...OpOption) (*MoveResponse, error) {
+	op := Op{key: []byte(key)}
+	op.applyOpts(opts)
+	r := &pb.MoveRequest{Key: op.key, Destination: []byte(dst), Prefix: op.end != nil, Overwrite: op.overwrite, PrevKv: op.prevKV}
+	resp, err := kv.remote.Move(ctx, r, kv.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*MoveResponse)(resp), nil
+}
+
+func (kv *kv) SetLease(ctx context.Context, key string, id LeaseID, opts ...OpOption) (*SetLeaseResponse, error) {
+	op := Op{key: []byte(key)}
+	op.applyOpts(opts)
+	r := &pb.SetLeaseRequest{Key: op.key, RangeEnd: op.end, Lease: int64(id), BumpRevision: op.bumpRevision}
+	resp, err := kv.remote.SetLease(ctx, r, kv.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*SetLeaseResponse)(resp), nil
+}
+
+func (kv *kv) Increment(ctx context.Context, key string, delta int64, opts ...OpOption) (*IncrementResponse, error) {
+	op := Op{key: []byte(key)}
+	op.applyOpts(opts)
+	r := &pb.IncrementRequest{
+		Key:     op.key,
+		Delta:   delta,
+		Initial: op.incrInitial,
+		Width:   op.incrWidth,
+		Lease:   int64(op.leaseID),
+	}
+	if op.incrBinary {
+		r.Format = pb.IncrementRequest_INT64_BIG_ENDIAN
+	}
+	resp, err := kv.remote.Increment(ctx, r, kv.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*IncrementResponse)(resp), nil
+}
+
+func (kv *kv) PutIfAbsent(ctx context.Context, key, val string, opts ...OpOption) (*PutIfAbsentResponse, error) {
+	op := Op{key: []byte(key)}
+	op.applyOpts(opts)
+	r := &pb.PutIfAbsentRequest{Key: op.key, Value: []byte(val), Lease: int64(op.leaseID)}
+	resp, err := kv.remote.PutIfAbsent(ctx, r, kv.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*PutIfAbsentResponse)(resp), nil
+}
+
+func (kv *kv) GetAndDelete(ctx context.Context, key string) (*GetAndDeleteResponse, error) {
+	resp, err := kv.remote.GetAndDelete(ctx, &pb.GetAndDeleteRequest{Key: []byte(key)}, kv.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*GetAndDeleteResponse)(resp), nil
+}
+
+func (kv *kv) BulkWrite(ctx context.Context, ops ...Op) (*BulkWriteResponse, error) {
+	r := &pb.BulkWriteRequest{Ops: make([]*pb.RequestOp, 0, len(ops))}
+	for _, op := range ops {
+		r.Ops = append(r.Ops, op.toRequestOp())
+	}
+	resp, err := kv.remote.BulkWrite(ctx, r, kv.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*BulkWriteResponse)(resp), nil
+}
+
 func (kv *kv) Compact(ctx context.Context, rev int64, opts ...CompactOption) (*CompactResponse, error) {
 	resp, err := kv.remote.Compact(ctx, OpCompact(rev, opts...).toRequest(), kv.callOpts...)
 	if err != nil {
@@ -157,7 +276,7 @@ func (kv *kv) Do(ctx context.Context, op Op) (OpResponse, error) {
 		}
 	case tPut:
 		var resp *pb.PutResponse
-		r := &pb.PutRequest{Key: op.key, Value: op.val, Lease: int64(op.leaseID), PrevKv: op.prevKV, IgnoreValue: op.ignoreValue, IgnoreLease: op.ignoreLease}
+		r := &pb.PutRequest{Key: op.key, Value: op.val, Lease: int64(op.leaseID), PrevKv: op.prevKV, IgnoreValue: op.ignoreValue, IgnoreLease: op.ignoreLease, Ttl: op.ttl, ExpireTime: op.expireTime}
 		resp, err = kv.remote.Put(ctx, r, kv.callOpts...)
 		if err == nil {
 			return OpResponse{put: (*PutResponse)(resp)}, nil
@@ -24,12 +24,15 @@ import (
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type (
-	LeaseRevokeResponse pb.LeaseRevokeResponse
-	LeaseID             int64
+	LeaseRevokeResponse     pb.LeaseRevokeResponse
+	LeaseRevokeBulkResponse pb.LeaseRevokeBulkResponse
+	LeaseID                 int64
 )
 
 // LeaseGrantResponse wraps the protobuf message LeaseGrantResponse.
@@ -40,6 +43,14 @@ type LeaseGrantResponse struct {
 	Error string
 }
 
+// LeaseGrantBulkResponse wraps the protobuf message LeaseGrantBulkResponse.
+type LeaseGrantBulkResponse struct {
+	*pb.ResponseHeader
+	// Leases are the leases granted, in the order of the TTLs requested. A
+	// lease failing to be granted has its Error set.
+	Leases []LeaseGrantResponse
+}
+
 // LeaseKeepAliveResponse wraps the protobuf message LeaseKeepAliveResponse.
 type LeaseKeepAliveResponse struct {
 	*pb.ResponseHeader
@@ -65,13 +76,21 @@ type LeaseTimeToLiveResponse struct {
 // LeaseStatus represents a lease status.
 type LeaseStatus struct {
 	ID LeaseID `json:"id"`
-	// TODO: TTL int64
+	// TTL is the remaining TTL in seconds for the lease, -1 if not listed by
+	// the leader.
+	TTL int64 `json:"ttl"`
+	// GrantedTTL is the initial granted time in seconds upon lease creation/renewal.
+	GrantedTTL int64 `json:"granted-ttl"`
+	// KeyCount is the number of keys attached to the lease.
+	KeyCount int64 `json:"key-count"`
 }
 
 // LeaseLeasesResponse wraps the protobuf message LeaseLeasesResponse.
 type LeaseLeasesResponse struct {
 	*pb.ResponseHeader
 	Leases []LeaseStatus `json:"leases"`
+	// More indicates if there are more leases to list past the limit.
+	More bool `json:"more"`
 }
 
 const (
@@ -112,11 +131,19 @@ type Lease interface {
 	// Revoke revokes the given lease.
 	Revoke(ctx context.Context, id LeaseID) (*LeaseRevokeResponse, error)
 
+	// GrantBulk creates a lease for each given TTL in a single request.
+	GrantBulk(ctx context.Context, ttls []int64) (*LeaseGrantBulkResponse, error)
+
+	// RevokeBulk revokes the given leases in a single request. The leases not
+	// found are reported in the response rather than failing the request.
+	RevokeBulk(ctx context.Context, ids ...LeaseID) (*LeaseRevokeBulkResponse, error)
+
 	// TimeToLive retrieves the lease information of the given lease ID.
 	TimeToLive(ctx context.Context, id LeaseID, opts ...LeaseOption) (*LeaseTimeToLiveResponse, error)
 
-	// Leases retrieves all leases.
-	Leases(ctx context.Context) (*LeaseLeasesResponse, error)
+	// Leases retrieves the leases in the order of their IDs, all of them
+	// unless limited or filtered by the options.
+	Leases(ctx context.Context, opts ...LeaseOption) (*LeaseLeasesResponse, error)
 
 	// KeepAlive attempts to keep the given lease alive forever. If the keepalive responses posted
 	// to the channel are not consumed promptly the channel may become full. When full, the lease
@@ -156,9 +183,13 @@ type lessor struct {
 
 	remote pb.LeaseClient
 
-	stream       pb.Lease_LeaseKeepAliveClient
+	stream       keepAliveStream
 	streamCancel context.CancelFunc
 
+	// batchUnsupported is set once the server rejected LeaseKeepAliveBatch;
+	// keep alives are then sent with one request per lease.
+	batchUnsupported bool
+
 	stopCtx    context.Context
 	stopCancel context.CancelFunc
 
@@ -235,6 +266,42 @@ func (l *lessor) Revoke(ctx context.Context, id LeaseID) (*LeaseRevokeResponse,
 	return nil, toErr(ctx, err)
 }
 
+func (l *lessor) GrantBulk(ctx context.Context, ttls []int64) (*LeaseGrantBulkResponse, error) {
+	r := &pb.LeaseGrantBulkRequest{Leases: make([]*pb.LeaseGrantRequest, len(ttls))}
+	for i, ttl := range ttls {
+		r.Leases[i] = &pb.LeaseGrantRequest{TTL: ttl}
+	}
+	resp, err := l.remote.LeaseGrantBulk(ctx, r, l.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	gresp := &LeaseGrantBulkResponse{
+		ResponseHeader: resp.GetHeader(),
+		Leases:         make([]LeaseGrantResponse, len(resp.Leases)),
+	}
+	for i, lr := range resp.Leases {
+		gresp.Leases[i] = LeaseGrantResponse{
+			ResponseHeader: resp.GetHeader(),
+			ID:             LeaseID(lr.ID),
+			TTL:            lr.TTL,
+			Error:          lr.Error,
+		}
+	}
+	return gresp, nil
+}
+
+func (l *lessor) RevokeBulk(ctx context.Context, ids ...LeaseID) (*LeaseRevokeBulkResponse, error) {
+	r := &pb.LeaseRevokeBulkRequest{IDs: make([]int64, len(ids))}
+	for i, id := range ids {
+		r.IDs[i] = int64(id)
+	}
+	resp, err := l.remote.LeaseRevokeBulk(ctx, r, l.callOpts...)
+	if err == nil {
+		return (*LeaseRevokeBulkResponse)(resp), nil
+	}
+	return nil, toErr(ctx, err)
+}
+
 func (l *lessor) TimeToLive(ctx context.Context, id LeaseID, opts ...LeaseOption) (*LeaseTimeToLiveResponse, error) {
 	r := toLeaseTimeToLiveRequest(id, opts...)
 	resp, err := l.remote.LeaseTimeToLive(ctx, r, l.callOpts...)
@@ -251,14 +318,14 @@ func (l *lessor) TimeToLive(ctx context.Context, id LeaseID, opts ...LeaseOption
 	return gresp, nil
 }
 
-func (l *lessor) Leases(ctx context.Context) (*LeaseLeasesResponse, error) {
-	resp, err := l.remote.LeaseLeases(ctx, &pb.LeaseLeasesRequest{}, l.callOpts...)
+func (l *lessor) Leases(ctx context.Context, opts ...LeaseOption) (*LeaseLeasesResponse, error) {
+	resp, err := l.remote.LeaseLeases(ctx, toLeaseLeasesRequest(opts...), l.callOpts...)
 	if err == nil {
 		leases := make([]LeaseStatus, len(resp.Leases))
-		for i := range resp.Leases {
-			leases[i] = LeaseStatus{ID: LeaseID(resp.Leases[i].ID)}
+		for i, ls := range resp.Leases {
+			leases[i] = LeaseStatus{ID: LeaseID(ls.ID), TTL: ls.TTL, GrantedTTL: ls.GrantedTTL, KeyCount: ls.KeyCount}
 		}
-		return &LeaseLeasesResponse{ResponseHeader: resp.GetHeader(), Leases: leases}, nil
+		return &LeaseLeasesResponse{ResponseHeader: resp.GetHeader(), Leases: leases, More: resp.More}, nil
 	}
 	return nil, toErr(ctx, err)
 }
@@ -447,19 +514,29 @@ func (l *lessor) recvKeepAliveLoop() (gerr error) {
 			}
 		} else {
 			for {
-				resp, err := stream.Recv()
+				resps, err := stream.recv()
 				if err != nil {
 					if canceledByCaller(l.stopCtx, err) {
 						return err
 					}
 
+					if stream.batched() && status.Code(err) == codes.Unimplemented {
+						l.lg.Info("lease keepalive batching is not supported by the server; falling back to keepalive per lease")
+						l.mu.Lock()
+						l.batchUnsupported = true
+						l.mu.Unlock()
+						break
+					}
+
 					if toErr(l.stopCtx, err) == rpctypes.ErrNoLeader {
 						l.closeRequireLeader()
 					}
 					break
 				}
 
-				l.recvKeepAlive(resp)
+				for _, resp := range resps {
+					l.recvKeepAlive(resp)
+				}
 			}
 		}
 
@@ -472,9 +549,9 @@ func (l *lessor) recvKeepAliveLoop() (gerr error) {
 }
 
 // resetRecv opens a new lease stream and starts sending keep alive requests.
-func (l *lessor) resetRecv() (pb.Lease_LeaseKeepAliveClient, error) {
+func (l *lessor) resetRecv() (keepAliveStream, error) {
 	sctx, cancel := context.WithCancel(l.stopCtx)
-	stream, err := l.remote.LeaseKeepAlive(sctx, append(l.callOpts, withMax(0))...)
+	stream, err := l.openKeepAliveStream(sctx)
 	if err != nil {
 		cancel()
 		return nil, err
@@ -493,6 +570,28 @@ func (l *lessor) resetRecv() (pb.Lease_LeaseKeepAliveClient, error) {
 	return stream, nil
 }
 
+// openKeepAliveStream opens a batched keep alive stream unless the server is
+// known not to support it, in which case a per-lease stream is opened.
+func (l *lessor) openKeepAliveStream(ctx context.Context) (keepAliveStream, error) {
+	l.mu.Lock()
+	batch := !l.batchUnsupported
+	l.mu.Unlock()
+
+	opts := append(l.callOpts, withMax(0))
+	if batch {
+		stream, err := l.remote.LeaseKeepAliveBatch(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &batchKeepAliveStream{stream}, nil
+	}
+	stream, err := l.remote.LeaseKeepAlive(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &singleKeepAliveStream{stream}, nil
+}
+
 // recvKeepAlive updates a lease based on its LeaseKeepAliveResponse
 func (l *lessor) recvKeepAlive(resp *pb.LeaseKeepAliveResponse) {
 	karesp := &LeaseKeepAliveResponse{
@@ -558,7 +657,7 @@ func (l *lessor) deadlineLoop() {
 }
 
 // sendKeepAliveLoop sends keep alive requests for the lifetime of the given stream.
-func (l *lessor) sendKeepAliveLoop(stream pb.Lease_LeaseKeepAliveClient) {
+func (l *lessor) sendKeepAliveLoop(stream keepAliveStream) {
 	for {
 		var tosend []LeaseID
 
@@ -571,9 +670,8 @@ func (l *lessor) sendKeepAliveLoop(stream pb.Lease_LeaseKeepAliveClient) {
 		}
 		l.mu.Unlock()
 
-		for _, id := range tosend {
-			r := &pb.LeaseKeepAliveRequest{ID: int64(id)}
-			if err := stream.Send(r); err != nil {
+		if len(tosend) > 0 {
+			if err := stream.send(tosend); err != nil {
 				l.lg.Warn("error occurred during lease keep alive request sending",
 					zap.Error(err),
 				)
@@ -599,3 +697,73 @@ func (ka *keepAlive) close() {
 		close(ch)
 	}
 }
+
+// maxKeepAliveBatchSize limits the number of leases renewed by a single
+// LeaseKeepAliveBatchRequest.
+const maxKeepAliveBatchSize = 1000
+
+// keepAliveStream abstracts over the per-lease and the batched keep alive streams.
+type keepAliveStream interface {
+	Context() context.Context
+	send(ids []LeaseID) error
+	recv() ([]*pb.LeaseKeepAliveResponse, error)
+	batched() bool
+}
+
+type singleKeepAliveStream struct {
+	pb.Lease_LeaseKeepAliveClient
+}
+
+func (s *singleKeepAliveStream) send(ids []LeaseID) error {
+	for _, id := range ids {
+		if err := s.Send(&pb.LeaseKeepAliveRequest{ID: int64(id)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *singleKeepAliveStream) recv() ([]*pb.LeaseKeepAliveResponse, error) {
+	resp, err := s.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return []*pb.LeaseKeepAliveResponse{resp}, nil
+}
+
+func (s *singleKeepAliveStream) batched() bool { return false }
+
+type batchKeepAliveStream struct {
+	pb.Lease_LeaseKeepAliveBatchClient
+}
+
+func (s *batchKeepAliveStream) send(ids []LeaseID) error {
+	for len(ids) > 0 {
+		n := len(ids)
+		if n > maxKeepAliveBatchSize {
+			n = maxKeepAliveBatchSize
+		}
+		r := &pb.LeaseKeepAliveBatchRequest{IDs: make([]int64, n)}
+		for i, id := range ids[:n] {
+			r.IDs[i] = int64(id)
+		}
+		if err := s.Send(r); err != nil {
+			return err
+		}
+		ids = ids[n:]
+	}
+	return nil
+}
+
+func (s *batchKeepAliveStream) recv() ([]*pb.LeaseKeepAliveResponse, error) {
+	resp, err := s.Recv()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range resp.Responses {
+		r.Header = resp.Header
+	}
+	return resp.Responses, nil
+}
+
+func (s *batchKeepAliveStream) batched() bool { return true }
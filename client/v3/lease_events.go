@@ -0,0 +1,77 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// LeaseEventsPrefix is the prefix of the reserved keys the lifecycle events
+// of the leases are watched on: watch the prefix with WithPrefix for the
+// events of all the leases, or LeaseEventKey for the events of a lease. The
+// keys are virtual: the events are puts sent to the watchers as they happen,
+// never stored, so a watch only receives the events after it is created and
+// the keys cannot be put.
+const LeaseEventsPrefix = "\x00lease-events/"
+
+// LeaseEventKey returns the key the lifecycle events of the lease id are
+// watched on.
+func LeaseEventKey(id LeaseID) string {
+	return fmt.Sprintf("%s%016x", LeaseEventsPrefix, id)
+}
+
+// LeaseEventType is the type of a lifecycle event of a lease.
+type LeaseEventType string
+
+const (
+	// LeaseEventGranted is received once a lease is granted.
+	LeaseEventGranted LeaseEventType = "granted"
+	// LeaseEventRenewedAfterGap is received once a lease is renewed with
+	// less than a third of its TTL remaining. Only the leader sends it.
+	LeaseEventRenewedAfterGap LeaseEventType = "renewed-after-gap"
+	// LeaseEventExpired is received once a lease is revoked because it
+	// expired.
+	LeaseEventExpired LeaseEventType = "expired"
+	// LeaseEventRevoked is received once a lease is revoked on request.
+	LeaseEventRevoked LeaseEventType = "revoked"
+	// LeaseEventKeysDeleted is received after LeaseEventExpired or
+	// LeaseEventRevoked if keys were attached to the lease.
+	LeaseEventKeysDeleted LeaseEventType = "keys-deleted"
+)
+
+// LeaseEvent is a lifecycle event of a lease.
+type LeaseEvent struct {
+	Type LeaseEventType `json:"type"`
+	ID   LeaseID        `json:"id"`
+	// TTL is the TTL of the lease granted or renewed, in seconds.
+	TTL int64 `json:"ttl,omitempty"`
+	// Keys are the sorted keys deleted with the lease.
+	Keys []string `json:"keys,omitempty"`
+}
+
+// ParseLeaseEvent returns the lifecycle event of a lease a watch event on a
+// key under LeaseEventsPrefix carries.
+func ParseLeaseEvent(ev *Event) (*LeaseEvent, error) {
+	if ev.Kv == nil || !strings.HasPrefix(string(ev.Kv.Key), LeaseEventsPrefix) {
+		return nil, fmt.Errorf("etcdclient: not a lease event")
+	}
+	var le LeaseEvent
+	if err := json.Unmarshal(ev.Kv.Value, &le); err != nil {
+		return nil, fmt.Errorf("etcdclient: invalid lease event: %v", err)
+	}
+	return &le, nil
+}
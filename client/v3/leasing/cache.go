@@ -270,6 +270,10 @@ func (lc *leaseCache) evalCmp(cmps []v3.Cmp) (cmpVal bool, ok bool) {
 		if len(cmp.RangeEnd) > 0 {
 			return false, false
 		}
+		if cmp.Target == v3pb.Compare_VALUE_INT || cmp.Target == v3pb.Compare_VALUE_PREFIX {
+			// left to the server
+			return false, false
+		}
 		lk := lc.entries[string(cmp.Key)]
 		if lk == nil {
 			return false, false
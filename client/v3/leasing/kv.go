@@ -16,6 +16,7 @@ package leasing
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"sync"
 	"time"
@@ -47,6 +48,24 @@ type leasingKV struct {
 
 var closedCh chan struct{}
 
+// ErrMoveNotSupported is returned by the Move of a leasing KV.
+var ErrMoveNotSupported = errors.New("leasing: move is not supported")
+
+// ErrSetLeaseNotSupported is returned by the SetLease of a leasing KV.
+var ErrSetLeaseNotSupported = errors.New("leasing: set lease is not supported")
+
+// ErrIncrementNotSupported is returned by the Increment of a leasing KV.
+var ErrIncrementNotSupported = errors.New("leasing: increment is not supported")
+
+// ErrPutIfAbsentNotSupported is returned by the PutIfAbsent of a leasing KV.
+var ErrPutIfAbsentNotSupported = errors.New("leasing: put if absent is not supported")
+
+// ErrGetAndDeleteNotSupported is returned by the GetAndDelete of a leasing KV.
+var ErrGetAndDeleteNotSupported = errors.New("leasing: get and delete is not supported")
+
+// ErrBulkWriteNotSupported is returned by the BulkWrite of a leasing KV.
+var ErrBulkWriteNotSupported = errors.New("leasing: bulk write is not supported")
+
 func init() {
 	closedCh = make(chan struct{})
 	close(closedCh)
@@ -113,6 +132,42 @@ func (lkv *leasingKV) Do(ctx context.Context, op v3.Op) (v3.OpResponse, error) {
 	return v3.OpResponse{}, nil
 }
 
+// Move is not supported since the keys moved on the server side would not
+// have their leases revoked from the other clients.
+func (lkv *leasingKV) Move(ctx context.Context, key, dst string, opts ...v3.OpOption) (*v3.MoveResponse, error) {
+	return nil, ErrMoveNotSupported
+}
+
+// SetLease is not supported since the keys whose lease is set on the server
+// side would not have their leasing keys revoked from the other clients.
+func (lkv *leasingKV) SetLease(ctx context.Context, key string, id v3.LeaseID, opts ...v3.OpOption) (*v3.SetLeaseResponse, error) {
+	return nil, ErrSetLeaseNotSupported
+}
+
+// Increment is not supported since the keys incremented on the server side
+// would not have their leasing keys revoked from the other clients.
+func (lkv *leasingKV) Increment(ctx context.Context, key string, delta int64, opts ...v3.OpOption) (*v3.IncrementResponse, error) {
+	return nil, ErrIncrementNotSupported
+}
+
+// PutIfAbsent is not supported since the keys put on the server side would
+// not have their leasing keys revoked from the other clients.
+func (lkv *leasingKV) PutIfAbsent(ctx context.Context, key, val string, opts ...v3.OpOption) (*v3.PutIfAbsentResponse, error) {
+	return nil, ErrPutIfAbsentNotSupported
+}
+
+// GetAndDelete is not supported since the keys deleted on the server side
+// would not have their leasing keys revoked from the other clients.
+func (lkv *leasingKV) GetAndDelete(ctx context.Context, key string) (*v3.GetAndDeleteResponse, error) {
+	return nil, ErrGetAndDeleteNotSupported
+}
+
+// BulkWrite is not supported since the keys written on the server side
+// would not have their leasing keys revoked from the other clients.
+func (lkv *leasingKV) BulkWrite(ctx context.Context, ops ...v3.Op) (*v3.BulkWriteResponse, error) {
+	return nil, ErrBulkWriteNotSupported
+}
+
 func (lkv *leasingKV) Compact(ctx context.Context, rev int64, opts ...v3.CompactOption) (*v3.CompactResponse, error) {
 	return lkv.kv.Compact(ctx, rev, opts...)
 }
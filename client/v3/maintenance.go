@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.uber.org/zap"
@@ -34,7 +35,29 @@ type (
 	MoveLeaderResponse pb.MoveLeaderResponse
 	DowngradeResponse  pb.DowngradeResponse
 
+	BackendBatchResponse    pb.BackendBatchResponse
+	QuotaStatusResponse     pb.QuotaStatusResponse
+	ResetQuotaAlarmResponse pb.ResetQuotaAlarmResponse
+	LogLevelResponse        pb.LogLevelResponse
+	WatchStreamsResponse    pb.WatchStreamsResponse
+	WatchersResponse        pb.WatchersResponse
+	CancelWatcherResponse   pb.CancelWatcherResponse
+	HotKeysResponse         pb.HotKeysResponse
+	ClusterHistoryResponse  pb.ClusterHistoryResponse
+	RuntimeConfigResponse   pb.RuntimeConfigResponse
+
+	DefragmentStatusResponse pb.DefragmentStatusResponse
+
+	PrefixQuotaSetResponse    pb.PrefixQuotaSetResponse
+	PrefixQuotaDeleteResponse pb.PrefixQuotaDeleteResponse
+	PrefixQuotaListResponse   pb.PrefixQuotaListResponse
+	PrefixQuota               pb.PrefixQuota
+	PrefixStatsResponse       pb.PrefixStatsResponse
+	LearnerStatusResponse     pb.LearnerStatusResponse
+	LearnerProgress           pb.LearnerProgress
+
 	DowngradeAction pb.DowngradeRequest_DowngradeAction
+	GRPCTracing     pb.LogLevelRequest_GRPCTracing
 )
 
 const (
@@ -43,6 +66,12 @@ const (
 	DowngradeCancel   = DowngradeAction(pb.DowngradeRequest_CANCEL)
 )
 
+const (
+	GRPCTracingKeep    = GRPCTracing(pb.LogLevelRequest_KEEP)
+	GRPCTracingEnable  = GRPCTracing(pb.LogLevelRequest_ENABLE)
+	GRPCTracingDisable = GRPCTracing(pb.LogLevelRequest_DISABLE)
+)
+
 type Maintenance interface {
 	// AlarmList gets all active alarms.
 	AlarmList(ctx context.Context) (*AlarmResponse, error)
@@ -86,6 +115,96 @@ type Maintenance interface {
 	// on the cluster version.
 	// Supported since etcd 3.5.
 	Downgrade(ctx context.Context, action DowngradeAction, version string) (*DowngradeResponse, error)
+
+	// BackendBatch updates the backend commit interval and commit limit of a given
+	// etcd member at runtime. Zero values keep the current setting. The change is
+	// not persisted across restarts of the member.
+	// Supported since etcd 3.6.
+	BackendBatch(ctx context.Context, endpoint string, interval time.Duration, limit int) (*BackendBatchResponse, error)
+
+	// QuotaStatus reports the space quota consumption, the apply backlog and the
+	// active alarms as seen by a given etcd member.
+	// Supported since etcd 3.6.
+	QuotaStatus(ctx context.Context, endpoint string) (*QuotaStatusResponse, error)
+
+	// ResetQuotaAlarm disarms the NOSPACE alarm of a given etcd member. Unlike
+	// AlarmDisarm, the member first verifies that its backend is back within the
+	// space quota with enough headroom, typically after compaction and defragmentation.
+	// Supported since etcd 3.6.
+	ResetQuotaAlarm(ctx context.Context, endpoint string) (*ResetQuotaAlarmResponse, error)
+
+	// LogLevel changes the log level and toggles gRPC tracing of a given etcd
+	// member at runtime. An empty level keeps the current level. The change is
+	// not persisted across restarts of the member.
+	// Supported since etcd 3.6.
+	LogLevel(ctx context.Context, endpoint string, level string, grpcTracing GRPCTracing) (*LogLevelResponse, error)
+
+	// WatchStreams lists the watch streams of a given etcd member with the
+	// events they have not delivered yet, the slowest first. A limit of 0
+	// returns every stream.
+	// Supported since etcd 3.6.
+	WatchStreams(ctx context.Context, endpoint string, limit int64) (*WatchStreamsResponse, error)
+
+	// Watchers lists the watchers of a given etcd member with their ranges,
+	// start revision and lag, the slowest first. A streamID of 0 lists the
+	// watchers of every watch stream, and a limit of 0 every watcher.
+	// Supported since etcd 3.6.
+	Watchers(ctx context.Context, endpoint string, streamID int64, limit int64) (*WatchersResponse, error)
+
+	// CancelWatcher cancels a watcher of a watch stream of a given etcd
+	// member. Its client receives a response canceling it with
+	// rpctypes.ErrWatchCanceledByAdmin.
+	// Supported since etcd 3.6.
+	CancelWatcher(ctx context.Context, endpoint string, streamID int64, watchID int64) (*CancelWatcherResponse, error)
+
+	// HotKeys lists the most accessed key prefixes of a given etcd member
+	// over its sampling window, the most accessed first. A limit of 0
+	// returns every tracked prefix. It fails if the member does not sample
+	// key accesses.
+	// Supported since etcd 3.6.
+	HotKeys(ctx context.Context, endpoint string, limit int64) (*HotKeysResponse, error)
+
+	// ClusterHistory lists the cluster events recorded by a given etcd
+	// member, such as membership and leader changes, oldest first. A limit
+	// of 0 returns every recorded event.
+	// Supported since etcd 3.6.
+	ClusterHistory(ctx context.Context, endpoint string, limit int64) (*ClusterHistoryResponse, error)
+
+	// RuntimeConfig returns the resolved configuration a given etcd member
+	// is running with, including defaults, with secrets masked.
+	// Supported since etcd 3.6.
+	RuntimeConfig(ctx context.Context, endpoint string) (*RuntimeConfigResponse, error)
+
+	// DefragmentStatus returns the progress of the ongoing defragmentation
+	// of a given etcd member, or of the last one.
+	// Supported since etcd 3.6.
+	DefragmentStatus(ctx context.Context, endpoint string) (*DefragmentStatusResponse, error)
+
+	// PrefixQuotaSet sets the quota of the keys under prefix to maxBytes of
+	// keys and values and maxKeys keys, 0 for no limit. The puts exceeding
+	// it fail with rpctypes.ErrPrefixQuotaExceeded.
+	// Supported since etcd 3.6.
+	PrefixQuotaSet(ctx context.Context, prefix string, maxBytes, maxKeys int64) (*PrefixQuotaSetResponse, error)
+
+	// PrefixQuotaDelete deletes the quota of the keys under prefix.
+	// Supported since etcd 3.6.
+	PrefixQuotaDelete(ctx context.Context, prefix string) (*PrefixQuotaDeleteResponse, error)
+
+	// PrefixQuotaList lists the quotas of the key prefixes with their usage.
+	// Supported since etcd 3.6.
+	PrefixQuotaList(ctx context.Context) (*PrefixQuotaListResponse, error)
+
+	// PrefixStats returns the number of keys under prefix and the total size
+	// of their keys and values, every key if prefix is empty. The header
+	// revision is the revision they were computed at.
+	// Supported since etcd 3.6.
+	PrefixStats(ctx context.Context, prefix string) (*PrefixStatsResponse, error)
+
+	// LearnerStatus returns the progress of the learner id, or of all the
+	// learners if id is 0, and whether they are ready to be promoted. The
+	// endpoint must be the leader's.
+	// Supported since etcd 3.6.
+	LearnerStatus(ctx context.Context, endpoint string, id uint64) (*LearnerStatusResponse, error)
 }
 
 // SnapshotResponse is aggregated response from the snapshot stream.
@@ -211,6 +330,137 @@ func (m *maintenance) Defragment(ctx context.Context, endpoint string) (*Defragm
 	return (*DefragmentResponse)(resp), nil
 }
 
+func (m *maintenance) BackendBatch(ctx context.Context, endpoint string, interval time.Duration, limit int) (*BackendBatchResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	req := &pb.BackendBatchRequest{BatchIntervalMs: interval.Milliseconds(), BatchLimit: int64(limit)}
+	resp, err := remote.BackendBatch(ctx, req, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*BackendBatchResponse)(resp), nil
+}
+
+func (m *maintenance) QuotaStatus(ctx context.Context, endpoint string) (*QuotaStatusResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.QuotaStatus(ctx, &pb.QuotaStatusRequest{}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*QuotaStatusResponse)(resp), nil
+}
+
+func (m *maintenance) ResetQuotaAlarm(ctx context.Context, endpoint string) (*ResetQuotaAlarmResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.ResetQuotaAlarm(ctx, &pb.ResetQuotaAlarmRequest{}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*ResetQuotaAlarmResponse)(resp), nil
+}
+
+func (m *maintenance) LogLevel(ctx context.Context, endpoint string, level string, grpcTracing GRPCTracing) (*LogLevelResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.LogLevel(ctx, &pb.LogLevelRequest{Level: level, GrpcTracing: pb.LogLevelRequest_GRPCTracing(grpcTracing)}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*LogLevelResponse)(resp), nil
+}
+
+func (m *maintenance) WatchStreams(ctx context.Context, endpoint string, limit int64) (*WatchStreamsResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.WatchStreams(ctx, &pb.WatchStreamsRequest{Limit: limit}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*WatchStreamsResponse)(resp), nil
+}
+
+func (m *maintenance) Watchers(ctx context.Context, endpoint string, streamID int64, limit int64) (*WatchersResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.Watchers(ctx, &pb.WatchersRequest{StreamId: streamID, Limit: limit}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*WatchersResponse)(resp), nil
+}
+
+func (m *maintenance) CancelWatcher(ctx context.Context, endpoint string, streamID int64, watchID int64) (*CancelWatcherResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.CancelWatcher(ctx, &pb.CancelWatcherRequest{StreamId: streamID, WatchId: watchID}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*CancelWatcherResponse)(resp), nil
+}
+
+func (m *maintenance) HotKeys(ctx context.Context, endpoint string, limit int64) (*HotKeysResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.HotKeys(ctx, &pb.HotKeysRequest{Limit: limit}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*HotKeysResponse)(resp), nil
+}
+
+func (m *maintenance) ClusterHistory(ctx context.Context, endpoint string, limit int64) (*ClusterHistoryResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.ClusterHistory(ctx, &pb.ClusterHistoryRequest{Limit: limit}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*ClusterHistoryResponse)(resp), nil
+}
+
+func (m *maintenance) RuntimeConfig(ctx context.Context, endpoint string) (*RuntimeConfigResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.RuntimeConfig(ctx, &pb.RuntimeConfigRequest{}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*RuntimeConfigResponse)(resp), nil
+}
+
 func (m *maintenance) Status(ctx context.Context, endpoint string) (*StatusResponse, error) {
 	remote, cancel, err := m.dial(endpoint)
 	if err != nil {
@@ -356,3 +606,62 @@ func (m *maintenance) Downgrade(ctx context.Context, action DowngradeAction, ver
 	resp, err := m.remote.Downgrade(ctx, &pb.DowngradeRequest{Action: actionType, Version: version}, m.callOpts...)
 	return (*DowngradeResponse)(resp), toErr(ctx, err)
 }
+
+func (m *maintenance) DefragmentStatus(ctx context.Context, endpoint string) (*DefragmentStatusResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.DefragmentStatus(ctx, &pb.DefragmentStatusRequest{}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*DefragmentStatusResponse)(resp), nil
+}
+
+func (m *maintenance) PrefixQuotaSet(ctx context.Context, prefix string, maxBytes, maxKeys int64) (*PrefixQuotaSetResponse, error) {
+	req := &pb.PrefixQuotaSetRequest{Prefix: []byte(prefix), MaxBytes: maxBytes, MaxKeys: maxKeys}
+	resp, err := m.remote.PrefixQuotaSet(ctx, req, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*PrefixQuotaSetResponse)(resp), nil
+}
+
+func (m *maintenance) PrefixQuotaDelete(ctx context.Context, prefix string) (*PrefixQuotaDeleteResponse, error) {
+	resp, err := m.remote.PrefixQuotaDelete(ctx, &pb.PrefixQuotaDeleteRequest{Prefix: []byte(prefix)}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*PrefixQuotaDeleteResponse)(resp), nil
+}
+
+func (m *maintenance) PrefixQuotaList(ctx context.Context) (*PrefixQuotaListResponse, error) {
+	resp, err := m.remote.PrefixQuotaList(ctx, &pb.PrefixQuotaListRequest{}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*PrefixQuotaListResponse)(resp), nil
+}
+
+func (m *maintenance) PrefixStats(ctx context.Context, prefix string) (*PrefixStatsResponse, error) {
+	resp, err := m.remote.PrefixStats(ctx, &pb.PrefixStatsRequest{Prefix: []byte(prefix)}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*PrefixStatsResponse)(resp), nil
+}
+
+func (m *maintenance) LearnerStatus(ctx context.Context, endpoint string, id uint64) (*LearnerStatusResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.LearnerStatus(ctx, &pb.LearnerStatusRequest{ID: id}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*LearnerStatusResponse)(resp), nil
+}
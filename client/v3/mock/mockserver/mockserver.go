@@ -182,6 +182,30 @@ func (m *mockKVServer) Txn(context.Context, *pb.TxnRequest) (*pb.TxnResponse, er
 	return &pb.TxnResponse{}, nil
 }
 
+func (m *mockKVServer) Move(context.Context, *pb.MoveRequest) (*pb.MoveResponse, error) {
+	return &pb.MoveResponse{}, nil
+}
+
+func (m *mockKVServer) SetLease(context.Context, *pb.SetLeaseRequest) (*pb.SetLeaseResponse, error) {
+	return &pb.SetLeaseResponse{}, nil
+}
+
+func (m *mockKVServer) Increment(context.Context, *pb.IncrementRequest) (*pb.IncrementResponse, error) {
+	return &pb.IncrementResponse{}, nil
+}
+
+func (m *mockKVServer) PutIfAbsent(context.Context, *pb.PutIfAbsentRequest) (*pb.PutIfAbsentResponse, error) {
+	return &pb.PutIfAbsentResponse{}, nil
+}
+
+func (m *mockKVServer) GetAndDelete(context.Context, *pb.GetAndDeleteRequest) (*pb.GetAndDeleteResponse, error) {
+	return &pb.GetAndDeleteResponse{}, nil
+}
+
+func (m *mockKVServer) BulkWrite(context.Context, *pb.BulkWriteRequest) (*pb.BulkWriteResponse, error) {
+	return &pb.BulkWriteResponse{}, nil
+}
+
 func (m *mockKVServer) Compact(context.Context, *pb.CompactionRequest) (*pb.CompactionResponse, error) {
 	return &pb.CompactionResponse{}, nil
 }
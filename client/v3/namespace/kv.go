@@ -77,6 +77,84 @@ func (kv *kvPrefix) Delete(ctx context.Context, key string, opts ...clientv3.OpO
 	return del, nil
 }
 
+func (kv *kvPrefix) Move(ctx context.Context, key, dst string, opts ...clientv3.OpOption) (*clientv3.MoveResponse, error) {
+	if len(key) == 0 && !clientv3.IsOptsWithPrefix(opts) {
+		return nil, rpctypes.ErrEmptyKey
+	}
+	resp, err := kv.KV.Move(ctx, kv.pfx+key, kv.pfx+dst, opts...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range resp.PrevKvs {
+		resp.PrevKvs[i].Key = resp.PrevKvs[i].Key[len(kv.pfx):]
+	}
+	return resp, nil
+}
+
+func (kv *kvPrefix) SetLease(ctx context.Context, key string, id clientv3.LeaseID, opts ...clientv3.OpOption) (*clientv3.SetLeaseResponse, error) {
+	if len(key) == 0 && !(clientv3.IsOptsWithFromKey(opts) || clientv3.IsOptsWithPrefix(opts)) {
+		return nil, rpctypes.ErrEmptyKey
+	}
+	op := clientv3.OpDelete(key, opts...)
+	pfxKey, pfxEnd := kv.prefixInterval(op.KeyBytes(), op.RangeBytes())
+	return kv.KV.SetLease(ctx, string(pfxKey), id, append(opts, clientv3.WithRange(string(pfxEnd)))...)
+}
+
+func (kv *kvPrefix) Increment(ctx context.Context, key string, delta int64, opts ...clientv3.OpOption) (*clientv3.IncrementResponse, error) {
+	if len(key) == 0 {
+		return nil, rpctypes.ErrEmptyKey
+	}
+	return kv.KV.Increment(ctx, kv.pfx+key, delta, opts...)
+}
+
+func (kv *kvPrefix) PutIfAbsent(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutIfAbsentResponse, error) {
+	if len(key) == 0 {
+		return nil, rpctypes.ErrEmptyKey
+	}
+	resp, err := kv.KV.PutIfAbsent(ctx, kv.pfx+key, val, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if resp.PrevKv != nil {
+		resp.PrevKv.Key = resp.PrevKv.Key[len(kv.pfx):]
+	}
+	return resp, nil
+}
+
+func (kv *kvPrefix) GetAndDelete(ctx context.Context, key string) (*clientv3.GetAndDeleteResponse, error) {
+	if len(key) == 0 {
+		return nil, rpctypes.ErrEmptyKey
+	}
+	resp, err := kv.KV.GetAndDelete(ctx, kv.pfx+key)
+	if err != nil {
+		return nil, err
+	}
+	if resp.PrevKv != nil {
+		resp.PrevKv.Key = resp.PrevKv.Key[len(kv.pfx):]
+	}
+	return resp, nil
+}
+
+func (kv *kvPrefix) BulkWrite(ctx context.Context, ops ...clientv3.Op) (*clientv3.BulkWriteResponse, error) {
+	for _, op := range ops {
+		if len(op.KeyBytes()) == 0 {
+			return nil, rpctypes.ErrEmptyKey
+		}
+	}
+	resp, err := kv.KV.BulkWrite(ctx, kv.prefixOps(ops)...)
+	if err != nil {
+		return nil, err
+	}
+	tresp := &clientv3.TxnResponse{}
+	for _, r := range resp.Results {
+		if r.Response != nil {
+			tresp.Responses = append(tresp.Responses, r.Response)
+		}
+	}
+	kv.unprefixTxnResponse(tresp)
+	return resp, nil
+}
+
 func (kv *kvPrefix) Do(ctx context.Context, op clientv3.Op) (clientv3.OpResponse, error) {
 	if len(op.KeyBytes()) == 0 && !op.IsTxn() {
 		return clientv3.OpResponse{}, rpctypes.ErrEmptyKey
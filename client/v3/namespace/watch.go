@@ -38,6 +38,23 @@ func NewWatcher(w clientv3.Watcher, prefix string) clientv3.Watcher {
 }
 
 func (w *watcherPrefix) Watch(ctx context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	return w.watch(ctx, nil, key, opts...)
+}
+
+func (w *watcherPrefix) WatchIf(ctx context.Context, cmps []clientv3.Cmp, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	newCmps := make([]clientv3.Cmp, len(cmps))
+	for i := range cmps {
+		newCmps[i] = cmps[i]
+		pfxKey, endKey := prefixInterval(w.pfx, cmps[i].KeyBytes(), cmps[i].RangeEnd)
+		newCmps[i].WithKeyBytes(pfxKey)
+		if len(cmps[i].RangeEnd) != 0 {
+			newCmps[i].RangeEnd = endKey
+		}
+	}
+	return w.watch(ctx, newCmps, key, opts...)
+}
+
+func (w *watcherPrefix) watch(ctx context.Context, cmps []clientv3.Cmp, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
 	// since OpOption is opaque, determine range for prefixing through an OpGet
 	op := clientv3.OpGet(key, opts...)
 	end := op.RangeBytes()
@@ -46,7 +63,12 @@ func (w *watcherPrefix) Watch(ctx context.Context, key string, opts ...clientv3.
 		opts = append(opts, clientv3.WithRange(string(pfxEnd)))
 	}
 
-	wch := w.Watcher.Watch(ctx, string(pfxBegin), opts...)
+	var wch clientv3.WatchChan
+	if cmps == nil {
+		wch = w.Watcher.Watch(ctx, string(pfxBegin), opts...)
+	} else {
+		wch = w.Watcher.WatchIf(ctx, cmps, string(pfxBegin), opts...)
+	}
 
 	// translate watch events from prefixed to unprefixed
 	pfxWch := make(chan clientv3.WatchResponse)
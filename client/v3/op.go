@@ -14,7 +14,11 @@
 
 package clientv3
 
-import pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+import (
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
 
 type opType int
 
@@ -44,6 +48,10 @@ type Op struct {
 	maxModRev    int64
 	minCreateRev int64
 	maxCreateRev int64
+	// continueToken is the continue token of the previous page.
+	continueToken []byte
+	// maxStaleness bounds the staleness of a serializable range.
+	maxStaleness uint64
 
 	// for range, watch
 	rev int64
@@ -61,17 +69,45 @@ type Op struct {
 	ignoreValue bool
 	ignoreLease bool
 
+	// for move
+	overwrite bool
+
+	// for set lease
+	bumpRevision bool
+
+	// for increment
+	incrInitial int64
+	incrWidth   int64
+	incrBinary  bool
+
 	// progressNotify is for progress updates.
 	progressNotify bool
+	// progressInterval and progressHeartbeat override the progress updates.
+	progressInterval  time.Duration
+	progressHeartbeat bool
 	// createdNotify is for created event
 	createdNotify bool
 	// filters for watchers
-	filterPut    bool
-	filterDelete bool
+	filterPut       bool
+	filterDelete    bool
+	valuePredicates []*pb.WatchValuePredicate
+	// additional ranges for watchers
+	watchRanges []*pb.WatchKeyRange
+	// resume tokens for watchers
+	resumable   bool
+	resumeToken []byte
+	// event batches for watchers
+	batchMaxLatency time.Duration
+	batchMaxEvents  int
+	// event projections for watchers
+	projection          pb.WatchCreateRequest_Projection
+	projectionJSONPaths []string
 
 	// for put
-	val     []byte
-	leaseID LeaseID
+	val        []byte
+	leaseID    LeaseID
+	ttl        int64
+	expireTime int64
 
 	// txn
 	cmps    []Cmp
@@ -161,6 +197,8 @@ func (op Op) toRangeRequest() *pb.RangeRequest {
 		MaxModRevision:    op.maxModRev,
 		MinCreateRevision: op.minCreateRev,
 		MaxCreateRevision: op.maxCreateRev,
+		ContinueToken:     op.continueToken,
+		MaxStaleness:      op.maxStaleness,
 	}
 	if op.sort != nil {
 		r.SortOrder = pb.RangeRequest_SortOrder(op.sort.Order)
@@ -190,7 +228,7 @@ func (op Op) toRequestOp() *pb.RequestOp {
 	case tRange:
 		return &pb.RequestOp{Request: &pb.RequestOp_RequestRange{RequestRange: op.toRangeRequest()}}
 	case tPut:
-		r := &pb.PutRequest{Key: op.key, Value: op.val, Lease: int64(op.leaseID), PrevKv: op.prevKV, IgnoreValue: op.ignoreValue, IgnoreLease: op.ignoreLease}
+		r := &pb.PutRequest{Key: op.key, Value: op.val, Lease: int64(op.leaseID), PrevKv: op.prevKV, IgnoreValue: op.ignoreValue, IgnoreLease: op.ignoreLease, Ttl: op.ttl, ExpireTime: op.expireTime}
 		return &pb.RequestOp{Request: &pb.RequestOp_RequestPut{RequestPut: r}}
 	case tDeleteRange:
 		r := &pb.DeleteRangeRequest{Key: op.key, RangeEnd: op.end, PrevKv: op.prevKV}
@@ -259,8 +297,18 @@ func OpDelete(key string, opts ...OpOption) Op {
 		panic("unexpected mod revision filter in delete")
 	case ret.minCreateRev != 0, ret.maxCreateRev != 0:
 		panic("unexpected create revision filter in delete")
-	case ret.filterDelete, ret.filterPut:
+	case ret.filterDelete, ret.filterPut, len(ret.valuePredicates) != 0:
 		panic("unexpected filter in delete")
+	case len(ret.watchRanges) != 0:
+		panic("unexpected watch range in delete")
+	case ret.resumable, len(ret.resumeToken) != 0:
+		panic("unexpected resume token in delete")
+	case ret.batchMaxLatency != 0, ret.batchMaxEvents != 0:
+		panic("unexpected batch in delete")
+	case ret.progressInterval != 0, ret.progressHeartbeat:
+		panic("unexpected progress notify in delete")
+	case ret.projection != pb.WatchCreateRequest_FULL:
+		panic("unexpected projection in delete")
 	case ret.createdNotify:
 		panic("unexpected createdNotify in delete")
 	}
@@ -288,8 +336,18 @@ func OpPut(key, val string, opts ...OpOption) Op {
 		panic("unexpected mod revision filter in put")
 	case ret.minCreateRev != 0, ret.maxCreateRev != 0:
 		panic("unexpected create revision filter in put")
-	case ret.filterDelete, ret.filterPut:
+	case ret.filterDelete, ret.filterPut, len(ret.valuePredicates) != 0:
 		panic("unexpected filter in put")
+	case len(ret.watchRanges) != 0:
+		panic("unexpected watch range in put")
+	case ret.resumable, len(ret.resumeToken) != 0:
+		panic("unexpected resume token in put")
+	case ret.batchMaxLatency != 0, ret.batchMaxEvents != 0:
+		panic("unexpected batch in put")
+	case ret.progressInterval != 0, ret.progressHeartbeat:
+		panic("unexpected progress notify in put")
+	case ret.projection != pb.WatchCreateRequest_FULL:
+		panic("unexpected projection in put")
 	case ret.createdNotify:
 		panic("unexpected createdNotify in put")
 	}
@@ -341,6 +399,16 @@ func WithLease(leaseID LeaseID) OpOption {
 // If WithLimit is given a 0 limit, it is treated as no limit.
 func WithLimit(n int64) OpOption { return func(op *Op) { op.limit = n } }
 
+// WithContinueToken makes 'Get' request get the page of the range following
+// the one of the response it is the continue token of, at the same revision.
+// The other options must be the same as for the first page, except the limit.
+// The range fails with rpctypes.ErrCompacted if the revision is compacted
+// meanwhile.
+// Supported since etcd 3.6.
+func WithContinueToken(token []byte) OpOption {
+	return func(op *Op) { op.continueToken = token }
+}
+
 // WithRev specifies the store revision for 'Get' request.
 // Or the start revision of 'Watch' request.
 func WithRev(rev int64) OpOption { return func(op *Op) { op.rev = rev } }
@@ -425,6 +493,18 @@ func WithSerializable() OpOption {
 	return func(op *Op) { op.serializable = true }
 }
 
+// WithStaleness makes 'Get' request serializable, served by the member once
+// it has applied all the entries committed by the leader but maxLag of them.
+// It fails with rpctypes.ErrNoLeader if the member is not connected to the
+// leader. The revision served is the revision of the response header.
+// Supported since etcd 3.6; earlier versions serve it as a serializable read.
+func WithStaleness(maxLag uint64) OpOption {
+	return func(op *Op) {
+		op.serializable = true
+		op.maxStaleness = maxLag
+	}
+}
+
 // WithKeysOnly makes the 'Get' request return only the keys and the corresponding
 // values will be omitted.
 func WithKeysOnly() OpOption {
@@ -480,6 +560,27 @@ func WithProgressNotify() OpOption {
 	}
 }
 
+// WithProgressNotifyInterval makes watch server send the progress updates
+// of the watcher every interval when there is no incoming events, instead
+// of the interval of the server. The server may raise an interval shorter
+// than its minimum.
+func WithProgressNotifyInterval(interval time.Duration) OpOption {
+	return func(op *Op) {
+		op.progressNotify = true
+		op.progressInterval = interval
+	}
+}
+
+// WithProgressNotifyHeartbeat makes watch server send the progress updates
+// of the watcher at every interval, even after incoming events, as
+// heartbeats of the revision.
+func WithProgressNotifyHeartbeat() OpOption {
+	return func(op *Op) {
+		op.progressNotify = true
+		op.progressHeartbeat = true
+	}
+}
+
 // WithCreatedNotify makes watch server sends the created event.
 func WithCreatedNotify() OpOption {
 	return func(op *Op) {
@@ -497,6 +598,92 @@ func WithFilterDelete() OpOption {
 	return func(op *Op) { op.filterDelete = true }
 }
 
+// WithValueJSONFieldEqual discards the PUT events from the watcher whose
+// values are not JSON objects with the field at the dot-separated path, such
+// as "status.phase", equal to the JSON encoded value. The events are filtered
+// by the server, so that they are not sent.
+func WithValueJSONFieldEqual(path, value string) OpOption {
+	return withValuePredicate(&pb.WatchValuePredicate{Type: pb.WatchValuePredicate_JSON_FIELD_EQUAL, JsonPath: path, Value: []byte(value)})
+}
+
+// WithValuePrefix discards the PUT events from the watcher whose values do
+// not start with the prefix. The events are filtered by the server.
+func WithValuePrefix(prefix string) OpOption {
+	return withValuePredicate(&pb.WatchValuePredicate{Type: pb.WatchValuePredicate_PREFIX, Value: []byte(prefix)})
+}
+
+// WithValueSize discards the PUT events from the watcher whose values are
+// smaller than min bytes or, if max is not zero, larger than max bytes. The
+// events are filtered by the server.
+func WithValueSize(min, max int64) OpOption {
+	return withValuePredicate(&pb.WatchValuePredicate{Type: pb.WatchValuePredicate_SIZE, MinSize: min, MaxSize: max})
+}
+
+func withValuePredicate(p *pb.WatchValuePredicate) OpOption {
+	return func(op *Op) { op.valuePredicates = append(op.valuePredicates, p) }
+}
+
+// WithResumable makes the responses of the watcher carry the tokens resuming
+// it with WithResumeToken.
+func WithResumable() OpOption {
+	return func(op *Op) { op.resumable = true }
+}
+
+// WithResumeToken resumes a watcher right after the response with the
+// resume token, on any member, such as after the failure of the member of
+// the watcher. The key, the revision and the options of the watcher are the
+// ones of the token, and the ones given to Watch are ignored.
+func WithResumeToken(token []byte) OpOption {
+	return func(op *Op) {
+		op.resumable = true
+		op.resumeToken = token
+	}
+}
+
+// WithBatch makes the watch server batch the events of the watcher, sending
+// them at most maxLatency after the first event of a batch, up to 10 seconds,
+// or once the batch has maxEvents events if maxEvents is not zero. It reduces
+// the responses of the watchers of the keys changing often.
+func WithBatch(maxLatency time.Duration, maxEvents int) OpOption {
+	return func(op *Op) {
+		op.batchMaxLatency = maxLatency
+		op.batchMaxEvents = maxEvents
+	}
+}
+
+// WithEventKeysOnly makes the watcher receive only the keys and the mod
+// revisions of the key-values of the events.
+func WithEventKeysOnly() OpOption {
+	return func(op *Op) { op.projection = pb.WatchCreateRequest_KEYS_ONLY }
+}
+
+// WithEventMetadataOnly makes the watcher receive the key-values of the
+// events without their values.
+func WithEventMetadataOnly() OpOption {
+	return func(op *Op) { op.projection = pb.WatchCreateRequest_METADATA_ONLY }
+}
+
+// WithEventJSONFields makes the watcher receive, instead of the JSON values
+// of the events, the JSON objects of their fields at the dot separated
+// paths, keyed by the paths. The values are projected by the server.
+func WithEventJSONFields(paths ...string) OpOption {
+	return func(op *Op) {
+		op.projection = pb.WatchCreateRequest_JSON_FIELDS
+		op.projectionJSONPaths = paths
+	}
+}
+
+// WithWatchRange makes the watcher watch the range [key, end) besides its
+// key, or the single key if end is empty. An end of "\x00" watches all the
+// keys greater than or equal to the key, and GetPrefixRangeEnd(key) all the
+// keys with the prefix. The events of a revision on any of the ranges of the
+// watcher are sent in one response.
+func WithWatchRange(key, end string) OpOption {
+	return func(op *Op) {
+		op.watchRanges = append(op.watchRanges, &pb.WatchKeyRange{Key: []byte(key), RangeEnd: []byte(end)})
+	}
+}
+
 // WithPrevKV gets the previous key-value pair before the event happens. If the previous KV is already compacted,
 // nothing will be returned.
 func WithPrevKV() OpOption {
@@ -534,12 +721,62 @@ func WithIgnoreLease() OpOption {
 	}
 }
 
+// WithTTL makes the key in 'Put' request expire after ttl seconds, the
+// server deleting it without a lease being granted.
+// This option can not be combined with WithExpireTime.
+func WithTTL(ttl int64) OpOption {
+	return func(op *Op) { op.ttl = ttl }
+}
+
+// WithExpireTime makes the key in 'Put' request expire at t, the server
+// deleting it without a lease being granted.
+// This option can not be combined with WithTTL.
+func WithExpireTime(t time.Time) OpOption {
+	return func(op *Op) { op.expireTime = t.Unix() }
+}
+
+// WithOverwrite makes 'Move' request replace the keys existing where the
+// keys are moved to.
+func WithOverwrite() OpOption {
+	return func(op *Op) { op.overwrite = true }
+}
+
+// WithBumpRevision makes 'SetLease' request put the keys again with their
+// values, incrementing their revisions and generating put events.
+func WithBumpRevision() OpOption {
+	return func(op *Op) { op.bumpRevision = true }
+}
+
+// WithIncrementInitial sets the value 'Increment' request adds the delta to
+// if the key does not exist.
+func WithIncrementInitial(v int64) OpOption {
+	return func(op *Op) { op.incrInitial = v }
+}
+
+// WithIncrementWidth makes 'Increment' request pad the value with leading
+// zeros up to width digits, so that the values sort in numeric order.
+func WithIncrementWidth(width int64) OpOption {
+	return func(op *Op) { op.incrWidth = width }
+}
+
+// WithIncrementBinary makes 'Increment' request store the value as 8 bytes
+// in big-endian order rather than as a decimal string.
+func WithIncrementBinary() OpOption {
+	return func(op *Op) { op.incrBinary = true }
+}
+
 // LeaseOp represents an Operation that lease can execute.
 type LeaseOp struct {
 	id LeaseID
 
 	// for TimeToLive
 	attachedKeys bool
+
+	// for Leases
+	limit          int64
+	minID          LeaseID
+	keyPrefix      string
+	minTTL, maxTTL int64
 }
 
 // LeaseOption configures lease operations.
@@ -556,6 +793,44 @@ func WithAttachedKeys() LeaseOption {
 	return func(op *LeaseOp) { op.attachedKeys = true }
 }
 
+// WithLeasesLimit limits the number of leases listed by Leases, which sets
+// More in its response if leases are left.
+func WithLeasesLimit(n int64) LeaseOption {
+	return func(op *LeaseOp) { op.limit = n }
+}
+
+// WithLeasesFromID makes Leases list the leases with an ID greater than or
+// equal to id. The page following a response with More set starts at its
+// last lease ID + 1.
+func WithLeasesFromID(id LeaseID) LeaseOption {
+	return func(op *LeaseOp) { op.minID = id }
+}
+
+// WithLeasesKeyPrefix makes Leases list only the leases with a key attached
+// having the prefix.
+func WithLeasesKeyPrefix(prefix string) LeaseOption {
+	return func(op *LeaseOp) { op.keyPrefix = prefix }
+}
+
+// WithLeasesTTLRange makes Leases list only the leases whose remaining TTL in
+// seconds is in [min, max], a max of 0 not bounding it. It must be served
+// by the leader, the only member knowing the remaining TTLs.
+func WithLeasesTTLRange(min, max int64) LeaseOption {
+	return func(op *LeaseOp) { op.minTTL, op.maxTTL = min, max }
+}
+
+func toLeaseLeasesRequest(opts ...LeaseOption) *pb.LeaseLeasesRequest {
+	ret := &LeaseOp{}
+	ret.applyOpts(opts)
+	return &pb.LeaseLeasesRequest{
+		Limit:     ret.limit,
+		MinID:     int64(ret.minID),
+		KeyPrefix: []byte(ret.keyPrefix),
+		MinTTL:    ret.minTTL,
+		MaxTTL:    ret.maxTTL,
+	}
+}
+
 func toLeaseTimeToLiveRequest(id LeaseID, opts ...LeaseOption) *pb.LeaseTimeToLiveRequest {
 	ret := &LeaseOp{id: id}
 	ret.applyOpts(opts)
@@ -117,6 +117,30 @@ func (rkv *retryKVClient) Txn(ctx context.Context, in *pb.TxnRequest, opts ...gr
 	return rkv.kc.Txn(ctx, in, opts...)
 }
 
+func (rkv *retryKVClient) Move(ctx context.Context, in *pb.MoveRequest, opts ...grpc.CallOption) (resp *pb.MoveResponse, err error) {
+	return rkv.kc.Move(ctx, in, opts...)
+}
+
+func (rkv *retryKVClient) SetLease(ctx context.Context, in *pb.SetLeaseRequest, opts ...grpc.CallOption) (resp *pb.SetLeaseResponse, err error) {
+	return rkv.kc.SetLease(ctx, in, opts...)
+}
+
+func (rkv *retryKVClient) Increment(ctx context.Context, in *pb.IncrementRequest, opts ...grpc.CallOption) (resp *pb.IncrementResponse, err error) {
+	return rkv.kc.Increment(ctx, in, opts...)
+}
+
+func (rkv *retryKVClient) PutIfAbsent(ctx context.Context, in *pb.PutIfAbsentRequest, opts ...grpc.CallOption) (resp *pb.PutIfAbsentResponse, err error) {
+	return rkv.kc.PutIfAbsent(ctx, in, opts...)
+}
+
+func (rkv *retryKVClient) GetAndDelete(ctx context.Context, in *pb.GetAndDeleteRequest, opts ...grpc.CallOption) (resp *pb.GetAndDeleteResponse, err error) {
+	return rkv.kc.GetAndDelete(ctx, in, opts...)
+}
+
+func (rkv *retryKVClient) BulkWrite(ctx context.Context, in *pb.BulkWriteRequest, opts ...grpc.CallOption) (resp *pb.BulkWriteResponse, err error) {
+	return rkv.kc.BulkWrite(ctx, in, opts...)
+}
+
 func (rkv *retryKVClient) Compact(ctx context.Context, in *pb.CompactionRequest, opts ...grpc.CallOption) (resp *pb.CompactionResponse, err error) {
 	return rkv.kc.Compact(ctx, in, opts...)
 }
@@ -148,10 +172,26 @@ func (rlc *retryLeaseClient) LeaseRevoke(ctx context.Context, in *pb.LeaseRevoke
 	return rlc.lc.LeaseRevoke(ctx, in, append(opts, withRetryPolicy(repeatable))...)
 }
 
+func (rlc *retryLeaseClient) LeaseGrantBulk(ctx context.Context, in *pb.LeaseGrantBulkRequest, opts ...grpc.CallOption) (resp *pb.LeaseGrantBulkResponse, err error) {
+	return rlc.lc.LeaseGrantBulk(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rlc *retryLeaseClient) LeaseRevokeBulk(ctx context.Context, in *pb.LeaseRevokeBulkRequest, opts ...grpc.CallOption) (resp *pb.LeaseRevokeBulkResponse, err error) {
+	return rlc.lc.LeaseRevokeBulk(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rlc *retryLeaseClient) LeaseKeepAliveOnce(ctx context.Context, in *pb.LeaseKeepAliveRequest, opts ...grpc.CallOption) (resp *pb.LeaseKeepAliveResponse, err error) {
+	return rlc.lc.LeaseKeepAliveOnce(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
 func (rlc *retryLeaseClient) LeaseKeepAlive(ctx context.Context, opts ...grpc.CallOption) (stream pb.Lease_LeaseKeepAliveClient, err error) {
 	return rlc.lc.LeaseKeepAlive(ctx, append(opts, withRetryPolicy(repeatable))...)
 }
 
+func (rlc *retryLeaseClient) LeaseKeepAliveBatch(ctx context.Context, opts ...grpc.CallOption) (stream pb.Lease_LeaseKeepAliveBatchClient, err error) {
+	return rlc.lc.LeaseKeepAliveBatch(ctx, append(opts, withRetryPolicy(repeatable))...)
+}
+
 type retryClusterClient struct {
 	cc pb.ClusterClient
 }
@@ -183,6 +223,10 @@ func (rcc *retryClusterClient) MemberPromote(ctx context.Context, in *pb.MemberP
 	return rcc.cc.MemberPromote(ctx, in, opts...)
 }
 
+func (rcc *retryClusterClient) MemberReplace(ctx context.Context, in *pb.MemberReplaceRequest, opts ...grpc.CallOption) (resp *pb.MemberReplaceResponse, err error) {
+	return rcc.cc.MemberReplace(ctx, in, opts...)
+}
+
 type retryMaintenanceClient struct {
 	mc pb.MaintenanceClient
 }
@@ -226,6 +270,70 @@ func (rmc *retryMaintenanceClient) Downgrade(ctx context.Context, in *pb.Downgra
 	return rmc.mc.Downgrade(ctx, in, opts...)
 }
 
+func (rmc *retryMaintenanceClient) BackendBatch(ctx context.Context, in *pb.BackendBatchRequest, opts ...grpc.CallOption) (resp *pb.BackendBatchResponse, err error) {
+	return rmc.mc.BackendBatch(ctx, in, opts...)
+}
+
+func (rmc *retryMaintenanceClient) QuotaStatus(ctx context.Context, in *pb.QuotaStatusRequest, opts ...grpc.CallOption) (resp *pb.QuotaStatusResponse, err error) {
+	return rmc.mc.QuotaStatus(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) ResetQuotaAlarm(ctx context.Context, in *pb.ResetQuotaAlarmRequest, opts ...grpc.CallOption) (resp *pb.ResetQuotaAlarmResponse, err error) {
+	return rmc.mc.ResetQuotaAlarm(ctx, in, opts...)
+}
+
+func (rmc *retryMaintenanceClient) LogLevel(ctx context.Context, in *pb.LogLevelRequest, opts ...grpc.CallOption) (resp *pb.LogLevelResponse, err error) {
+	return rmc.mc.LogLevel(ctx, in, opts...)
+}
+
+func (rmc *retryMaintenanceClient) WatchStreams(ctx context.Context, in *pb.WatchStreamsRequest, opts ...grpc.CallOption) (resp *pb.WatchStreamsResponse, err error) {
+	return rmc.mc.WatchStreams(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) Watchers(ctx context.Context, in *pb.WatchersRequest, opts ...grpc.CallOption) (resp *pb.WatchersResponse, err error) {
+	return rmc.mc.Watchers(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) CancelWatcher(ctx context.Context, in *pb.CancelWatcherRequest, opts ...grpc.CallOption) (resp *pb.CancelWatcherResponse, err error) {
+	return rmc.mc.CancelWatcher(ctx, in, opts...)
+}
+
+func (rmc *retryMaintenanceClient) HotKeys(ctx context.Context, in *pb.HotKeysRequest, opts ...grpc.CallOption) (resp *pb.HotKeysResponse, err error) {
+	return rmc.mc.HotKeys(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) ClusterHistory(ctx context.Context, in *pb.ClusterHistoryRequest, opts ...grpc.CallOption) (resp *pb.ClusterHistoryResponse, err error) {
+	return rmc.mc.ClusterHistory(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) RuntimeConfig(ctx context.Context, in *pb.RuntimeConfigRequest, opts ...grpc.CallOption) (resp *pb.RuntimeConfigResponse, err error) {
+	return rmc.mc.RuntimeConfig(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) DefragmentStatus(ctx context.Context, in *pb.DefragmentStatusRequest, opts ...grpc.CallOption) (resp *pb.DefragmentStatusResponse, err error) {
+	return rmc.mc.DefragmentStatus(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) PrefixQuotaSet(ctx context.Context, in *pb.PrefixQuotaSetRequest, opts ...grpc.CallOption) (resp *pb.PrefixQuotaSetResponse, err error) {
+	return rmc.mc.PrefixQuotaSet(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) PrefixQuotaDelete(ctx context.Context, in *pb.PrefixQuotaDeleteRequest, opts ...grpc.CallOption) (resp *pb.PrefixQuotaDeleteResponse, err error) {
+	return rmc.mc.PrefixQuotaDelete(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) PrefixQuotaList(ctx context.Context, in *pb.PrefixQuotaListRequest, opts ...grpc.CallOption) (resp *pb.PrefixQuotaListResponse, err error) {
+	return rmc.mc.PrefixQuotaList(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) PrefixStats(ctx context.Context, in *pb.PrefixStatsRequest, opts ...grpc.CallOption) (resp *pb.PrefixStatsResponse, err error) {
+	return rmc.mc.PrefixStats(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) LearnerStatus(ctx context.Context, in *pb.LearnerStatusRequest, opts ...grpc.CallOption) (resp *pb.LearnerStatusResponse, err error) {
+	return rmc.mc.LearnerStatus(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
 type retryAuthClient struct {
 	ac pb.AuthClient
 }
@@ -301,6 +409,22 @@ func (rac *retryAuthClient) RoleRevokePermission(ctx context.Context, in *pb.Aut
 	return rac.ac.RoleRevokePermission(ctx, in, opts...)
 }
 
+func (rac *retryAuthClient) AuthLockoutList(ctx context.Context, in *pb.AuthLockoutListRequest, opts ...grpc.CallOption) (resp *pb.AuthLockoutListResponse, err error) {
+	return rac.ac.AuthLockoutList(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rac *retryAuthClient) AuthLockoutClear(ctx context.Context, in *pb.AuthLockoutClearRequest, opts ...grpc.CallOption) (resp *pb.AuthLockoutClearResponse, err error) {
+	return rac.ac.AuthLockoutClear(ctx, in, opts...)
+}
+
+func (rac *retryAuthClient) AuthSessionList(ctx context.Context, in *pb.AuthSessionListRequest, opts ...grpc.CallOption) (resp *pb.AuthSessionListResponse, err error) {
+	return rac.ac.AuthSessionList(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rac *retryAuthClient) AuthSessionRevoke(ctx context.Context, in *pb.AuthSessionRevokeRequest, opts ...grpc.CallOption) (resp *pb.AuthSessionRevokeResponse, err error) {
+	return rac.ac.AuthSessionRevoke(ctx, in, opts...)
+}
+
 func (rac *retryAuthClient) Authenticate(ctx context.Context, in *pb.AuthenticateRequest, opts ...grpc.CallOption) (resp *pb.AuthenticateResponse, err error) {
 	return rac.ac.Authenticate(ctx, in, opts...)
 }
@@ -327,6 +327,10 @@ func isSafeRetry(c *Client, err error, callOpts *options) bool {
 	if errors.Is(err, rpctypes.ErrGRPCNotSupportedForLearner) && len(c.Endpoints()) > 1 {
 		return true
 	}
+	// Same for a witness, which serves no client requests but Status.
+	if errors.Is(err, rpctypes.ErrGRPCNotSupportedForWitness) && len(c.Endpoints()) > 1 {
+		return true
+	}
 
 	switch callOpts.retryPolicy {
 	case repeatable:
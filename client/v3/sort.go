@@ -29,6 +29,7 @@ const (
 	SortByCreateRevision
 	SortByModRevision
 	SortByValue
+	SortByLease
 )
 
 type SortOption struct {
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -74,6 +75,17 @@ type Watcher interface {
 	// (see https://github.com/etcd-io/etcd/issues/8980)
 	Watch(ctx context.Context, key string, opts ...OpOption) WatchChan
 
+	// WatchIf watches on a key or prefix like Watch, but only if all of the
+	// given comparisons succeed when the watcher is created. The comparisons
+	// are evaluated atomically with the creation of the watcher: if no revision
+	// is requested, the watch starts right after the revision the comparisons
+	// were evaluated at, so no event between the check and the watch is missed.
+	// If a comparison fails, the returned channel posts a canceled response
+	// whose "Err()" is "rpctypes.ErrWatchCompareFailed" and is then closed.
+	// The comparisons are only checked once; a watcher resumed after a
+	// reconnection does not evaluate them again.
+	WatchIf(ctx context.Context, cmps []Cmp, key string, opts ...OpOption) WatchChan
+
 	// RequestProgress requests a progress notify response be sent in all watch channels.
 	RequestProgress(ctx context.Context) error
 
@@ -96,6 +108,12 @@ type WatchResponse struct {
 	// Created is used to indicate the creation of the watcher.
 	Created bool
 
+	// ResumeToken resumes the watcher right after the response with
+	// WithResumeToken, on any member. It is set on the responses of the
+	// watchers created with WithResumable, but on the ones of the broadcast
+	// progress notifications.
+	ResumeToken []byte
+
 	closeErr error
 
 	// cancelReason is a reason of canceling watch
@@ -144,6 +162,9 @@ type watcher struct {
 	// streams holds all the active grpc streams keyed by ctx value.
 	streams map[string]*watchGrpcStream
 	lg      *zap.Logger
+
+	// compression lists the codecs of the events the streams accept
+	compression string
 }
 
 // watchGrpcStream tracks all watch resources attached to a single grpc stream.
@@ -200,6 +221,9 @@ type watchRequest struct {
 	createdNotify bool
 	// progressNotify is for progress updates
 	progressNotify bool
+	// progressInterval and progressHeartbeat override the progress updates
+	progressInterval  time.Duration
+	progressHeartbeat bool
 	// fragmentation should be disabled by default
 	// if true, split watch events when total exceeds
 	// "--max-request-bytes" flag value + 512-byte
@@ -207,8 +231,24 @@ type watchRequest struct {
 
 	// filters is the list of events to filter out
 	filters []pb.WatchCreateRequest_FilterType
+	// valuePredicates filter out the put events whose values do not satisfy them
+	valuePredicates []*pb.WatchValuePredicate
+	// additionalRanges are the ranges watched besides [key, end)
+	additionalRanges []*pb.WatchKeyRange
+	// resumable is set so that the responses carry resume tokens
+	resumable bool
+	// resumeToken resumes a watcher, overriding the fields above
+	resumeToken []byte
+	// batchMaxLatency and batchMaxEvents bound the batches of the events
+	batchMaxLatency time.Duration
+	batchMaxEvents  int
+	// projection projects the key-values of the events
+	projection          pb.WatchCreateRequest_Projection
+	projectionJSONPaths []string
 	// get the previous key-value pair before the event happens
 	prevKV bool
+	// cmps is the list of comparisons that must succeed to create the watcher
+	cmps []*pb.Compare
 	// retc receives a chan WatchResponse once the watcher is established
 	retc chan chan WatchResponse
 }
@@ -249,6 +289,7 @@ func NewWatchFromWatchClient(wc pb.WatchClient, c *Client) Watcher {
 	if c != nil {
 		w.callOpts = c.callOpts
 		w.lg = c.lg
+		w.compression = strings.Join(c.cfg.WatchCompression, ",")
 	}
 	return w
 }
@@ -288,6 +329,19 @@ func (w *watcher) newWatcherGrpcStream(inctx context.Context) *watchGrpcStream {
 
 // Watch posts a watch request to run() and waits for a new watcher channel
 func (w *watcher) Watch(ctx context.Context, key string, opts ...OpOption) WatchChan {
+	return w.watch(ctx, nil, key, opts...)
+}
+
+// WatchIf posts a watch request guarded by the given comparisons.
+func (w *watcher) WatchIf(ctx context.Context, cmps []Cmp, key string, opts ...OpOption) WatchChan {
+	pbcmps := make([]*pb.Compare, len(cmps))
+	for i := range cmps {
+		pbcmps[i] = (*pb.Compare)(&cmps[i])
+	}
+	return w.watch(ctx, pbcmps, key, opts...)
+}
+
+func (w *watcher) watch(ctx context.Context, cmps []*pb.Compare, key string, opts ...OpOption) WatchChan {
 	ow := opWatch(key, opts...)
 
 	var filters []pb.WatchCreateRequest_FilterType
@@ -299,16 +353,27 @@ func (w *watcher) Watch(ctx context.Context, key string, opts ...OpOption) Watch
 	}
 
 	wr := &watchRequest{
-		ctx:            ctx,
-		createdNotify:  ow.createdNotify,
-		key:            string(ow.key),
-		end:            string(ow.end),
-		rev:            ow.rev,
-		progressNotify: ow.progressNotify,
-		fragment:       ow.fragment,
-		filters:        filters,
-		prevKV:         ow.prevKV,
-		retc:           make(chan chan WatchResponse, 1),
+		ctx:                 ctx,
+		createdNotify:       ow.createdNotify,
+		key:                 string(ow.key),
+		end:                 string(ow.end),
+		rev:                 ow.rev,
+		progressNotify:      ow.progressNotify,
+		progressInterval:    ow.progressInterval,
+		progressHeartbeat:   ow.progressHeartbeat,
+		fragment:            ow.fragment,
+		filters:             filters,
+		valuePredicates:     ow.valuePredicates,
+		additionalRanges:    ow.watchRanges,
+		resumable:           ow.resumable,
+		resumeToken:         ow.resumeToken,
+		batchMaxLatency:     ow.batchMaxLatency,
+		batchMaxEvents:      ow.batchMaxEvents,
+		projection:          ow.projection,
+		projectionJSONPaths: ow.projectionJSONPaths,
+		prevKV:              ow.prevKV,
+		cmps:                cmps,
+		retc:                make(chan chan WatchResponse, 1),
 	}
 
 	ok := false
@@ -457,6 +522,8 @@ func (w *watchGrpcStream) addSubstream(resp *pb.WatchResponse, ws *watcherStream
 		return
 	}
 	ws.id = resp.WatchId
+	// comparisons hold at creation; a resumed watcher must not check them again
+	ws.initReq.cmps = nil
 	w.substreams[ws.id] = ws
 }
 
@@ -576,6 +643,8 @@ func (w *watchGrpcStream) run() {
 				cur.Events = append(cur.Events, pbresp.Events...)
 				// update "Fragment" field; last response with "Fragment" == false
 				cur.Fragment = pbresp.Fragment
+				// the last response resumes the watcher after all of the events
+				cur.ResumeToken = pbresp.ResumeToken
 			}
 
 			switch {
@@ -598,7 +667,7 @@ func (w *watchGrpcStream) run() {
 				// reset for next iteration
 				cur = nil
 
-			case pbresp.Canceled && pbresp.CompactRevision == 0:
+			case pbresp.Canceled && pbresp.CompactRevision == 0 && pbresp.CancelReason == "":
 				delete(cancelSet, pbresp.WatchId)
 				if ws, ok := w.substreams[pbresp.WatchId]; ok {
 					// signal to stream goroutine to update closingc
@@ -713,6 +782,7 @@ func (w *watchGrpcStream) dispatchEvent(pbresp *pb.WatchResponse) bool {
 		CompactRevision: pbresp.CompactRevision,
 		Created:         pbresp.Created,
 		Canceled:        pbresp.Canceled,
+		ResumeToken:     pbresp.ResumeToken,
 		cancelReason:    pbresp.CancelReason,
 	}
 
@@ -755,6 +825,9 @@ func (w *watchGrpcStream) unicastResponse(wr *WatchResponse, watchId int64) bool
 func (w *watchGrpcStream) serveWatchClient(wc pb.Watch_WatchClient) {
 	for {
 		resp, err := wc.Recv()
+		if err == nil {
+			err = decompressWatchResponse(resp)
+		}
 		if err != nil {
 			select {
 			case w.errc <- err:
@@ -846,6 +919,10 @@ func (w *watchGrpcStream) serveSubstream(ws *watcherStream, resumec chan struct{
 				nextRev = wr.Events[len(wr.Events)-1].Kv.ModRevision + 1
 			}
 			ws.initReq.rev = nextRev
+			if len(wr.ResumeToken) != 0 && len(ws.initReq.resumeToken) != 0 {
+				// the initial token would resume before the response
+				ws.initReq.resumeToken = wr.ResumeToken
+			}
 
 			// created event is already sent above,
 			// watcher should not post duplicate events
@@ -977,7 +1054,7 @@ func (w *watchGrpcStream) openWatchClient() (ws pb.Watch_WatchClient, err error)
 			return nil, err
 		default:
 		}
-		if ws, err = w.remote.Watch(w.ctx, w.callOpts...); ws != nil && err == nil {
+		if ws, err = w.remote.Watch(w.streamCtx(), w.callOpts...); ws != nil && err == nil {
 			break
 		}
 		if isHaltErr(w.ctx, err) {
@@ -998,16 +1075,37 @@ func (w *watchGrpcStream) openWatchClient() (ws pb.Watch_WatchClient, err error)
 	return ws, nil
 }
 
+// streamCtx returns the context of the watch client, listing the codecs of
+// the events the stream accepts after the ones of the watch context.
+func (w *watchGrpcStream) streamCtx() context.Context {
+	if w.owner.compression == "" {
+		return w.ctx
+	}
+	return metadata.AppendToOutgoingContext(w.ctx, v3rpc.MetadataWatchCompressionKey, w.owner.compression)
+}
+
 // toPB converts an internal watch request structure to its protobuf WatchRequest structure.
 func (wr *watchRequest) toPB() *pb.WatchRequest {
 	req := &pb.WatchCreateRequest{
-		StartRevision:  wr.rev,
-		Key:            []byte(wr.key),
-		RangeEnd:       []byte(wr.end),
-		ProgressNotify: wr.progressNotify,
-		Filters:        wr.filters,
-		PrevKv:         wr.prevKV,
-		Fragment:       wr.fragment,
+		StartRevision:       wr.rev,
+		Key:                 []byte(wr.key),
+		RangeEnd:            []byte(wr.end),
+		ProgressNotify:      wr.progressNotify,
+		Filters:             wr.filters,
+		PrevKv:              wr.prevKV,
+		Fragment:            wr.fragment,
+		Compare:             wr.cmps,
+		ValuePredicates:     wr.valuePredicates,
+		AdditionalRanges:    wr.additionalRanges,
+		Resumable:           wr.resumable,
+		ResumeToken:         wr.resumeToken,
+		BatchMaxLatencyMs:   wr.batchMaxLatency.Milliseconds(),
+		BatchMaxEvents:      int64(wr.batchMaxEvents),
+		Projection:          wr.projection,
+		ProjectionJsonPaths: wr.projectionJSONPaths,
+
+		ProgressNotifyIntervalMs: wr.progressInterval.Milliseconds(),
+		ProgressNotifyHeartbeat:  wr.progressHeartbeat,
 	}
 	cr := &pb.WatchRequest_CreateRequest{CreateRequest: req}
 	return &pb.WatchRequest{RequestUnion: cr}
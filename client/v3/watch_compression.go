@@ -0,0 +1,79 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// WatchCompressionGzip accepts the watch events compressed with gzip.
+	WatchCompressionGzip = "gzip"
+	// WatchCompressionZstd accepts the watch events compressed with zstd.
+	WatchCompressionZstd = "zstd"
+)
+
+var (
+	zstdDecoderOnce sync.Once
+	zstdDecoder     *zstd.Decoder
+)
+
+// decompressWatchResponse replaces the compressed events of a watch response
+// by the events they hold.
+func decompressWatchResponse(resp *pb.WatchResponse) error {
+	if resp.Compression == pb.WatchResponse_NONE {
+		return nil
+	}
+	var (
+		data []byte
+		err  error
+	)
+	switch resp.Compression {
+	case pb.WatchResponse_GZIP:
+		var gr *gzip.Reader
+		if gr, err = gzip.NewReader(bytes.NewReader(resp.CompressedEvents)); err == nil {
+			data, err = io.ReadAll(gr)
+		}
+	case pb.WatchResponse_ZSTD:
+		zstdDecoderOnce.Do(func() {
+			// never fails without a reader; DecodeAll is safe for
+			// concurrent use by the watch streams.
+			zstdDecoder, _ = zstd.NewReader(nil)
+		})
+		data, err = zstdDecoder.DecodeAll(resp.CompressedEvents, nil)
+	default:
+		return fmt.Errorf("unknown watch compression %v", resp.Compression)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decompress watch events (%v)", err)
+	}
+
+	var evs pb.WatchResponse
+	if err := evs.Unmarshal(data); err != nil {
+		return err
+	}
+	resp.Events = evs.Events
+	resp.Compression = pb.WatchResponse_NONE
+	resp.CompressedEvents = nil
+	return nil
+}
@@ -16,6 +16,7 @@ package command
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/spf13/cobra"
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
@@ -32,6 +33,8 @@ func NewAuthCommand() *cobra.Command {
 	ac.AddCommand(newAuthEnableCommand())
 	ac.AddCommand(newAuthDisableCommand())
 	ac.AddCommand(newAuthStatusCommand())
+	ac.AddCommand(newAuthLockoutCommand())
+	ac.AddCommand(newAuthSessionCommand())
 
 	return ac
 }
@@ -121,3 +124,126 @@ func authDisableCommandFunc(cmd *cobra.Command, args []string) {
 
 	fmt.Println("Authentication Disabled")
 }
+
+var (
+	lockoutUser    string
+	lockoutAddress string
+)
+
+func newAuthLockoutCommand() *cobra.Command {
+	lc := &cobra.Command{
+		Use:   "lockout <subcommand>",
+		Short: "Lockout related commands",
+	}
+	lc.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "Lists the users and client addresses locked out of authenticating on the endpoint",
+		Run:   authLockoutListCommandFunc,
+	})
+	cc := &cobra.Command{
+		Use:   "clear",
+		Short: "Lifts the lockouts of a user or a client address on the endpoint, or all of them",
+		Run:   authLockoutClearCommandFunc,
+	}
+	cc.Flags().StringVar(&lockoutUser, "user", "", "user to lift the lockout of")
+	cc.Flags().StringVar(&lockoutAddress, "address", "", "client address to lift the lockout of")
+	lc.AddCommand(cc)
+	return lc
+}
+
+// authLockoutListCommandFunc executes the "auth lockout list" command.
+func authLockoutListCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("auth lockout list command does not accept any arguments"))
+	}
+
+	ctx, cancel := commandCtx(cmd)
+	result, err := mustClientFromCmd(cmd).Auth.AuthLockoutList(ctx)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+
+	display.AuthLockoutList(*result)
+}
+
+// authLockoutClearCommandFunc executes the "auth lockout clear" command.
+func authLockoutClearCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("auth lockout clear command does not accept any arguments"))
+	}
+
+	ctx, cancel := commandCtx(cmd)
+	result, err := mustClientFromCmd(cmd).Auth.AuthLockoutClear(ctx, lockoutUser, lockoutAddress)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+
+	display.AuthLockoutClear(*result)
+}
+
+var sessionUser string
+
+func newAuthSessionCommand() *cobra.Command {
+	sc := &cobra.Command{
+		Use:   "session <subcommand>",
+		Short: "Session related commands",
+	}
+	lc := &cobra.Command{
+		Use:   "list",
+		Short: "Lists the sessions of the auth tokens currently valid on the endpoint",
+		Run:   authSessionListCommandFunc,
+	}
+	lc.Flags().StringVar(&sessionUser, "user", "", "user to list the sessions of")
+	sc.AddCommand(lc)
+	rc := &cobra.Command{
+		Use:   "revoke [<session ID>]",
+		Short: "Revokes the token of a session, or of all the sessions of a user, on all the members",
+		Run:   authSessionRevokeCommandFunc,
+	}
+	rc.Flags().StringVar(&sessionUser, "user", "", "user to revoke the sessions of, or the session ID must be of")
+	sc.AddCommand(rc)
+	return sc
+}
+
+// authSessionListCommandFunc executes the "auth session list" command.
+func authSessionListCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("auth session list command does not accept any arguments"))
+	}
+
+	ctx, cancel := commandCtx(cmd)
+	result, err := mustClientFromCmd(cmd).Auth.AuthSessionList(ctx, sessionUser)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+
+	display.AuthSessionList(*result)
+}
+
+// authSessionRevokeCommandFunc executes the "auth session revoke" command.
+func authSessionRevokeCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("auth session revoke command accepts at most one session ID as argument"))
+	}
+	var id uint64
+	if len(args) == 1 {
+		var err error
+		if id, err = strconv.ParseUint(args[0], 10, 64); err != nil || id == 0 {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("bad session ID %q", args[0]))
+		}
+	} else if sessionUser == "" {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("auth session revoke command needs a session ID or --user"))
+	}
+
+	ctx, cancel := commandCtx(cmd)
+	result, err := mustClientFromCmd(cmd).Auth.AuthSessionRevoke(ctx, id, sessionUser)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+
+	display.AuthSessionRevoke(*result)
+}
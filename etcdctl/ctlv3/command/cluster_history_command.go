@@ -0,0 +1,77 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+)
+
+var clusterHistoryLimit int64
+
+// NewClusterHistoryCommand returns the cobra command for "cluster-history".
+func NewClusterHistoryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cluster-history",
+		Short: "Lists the cluster events recorded by the etcd members with given endpoints",
+		Long: `Lists the cluster events recorded by the etcd members with given endpoints,
+oldest first: member add, remove, update and promote, leader changes, downgrade
+enable and disable, and alarm activation and deactivation. Leader changes are
+recorded by each member as it observes them.`,
+		Run: clusterHistoryCommandFunc,
+	}
+	cmd.Flags().Int64Var(&clusterHistoryLimit, "limit", 0, "maximum number of the most recent events to list per member, 0 for all")
+	cmd.PersistentFlags().BoolVar(&epClusterEndpoints, "cluster", false, "use all endpoints from the cluster member list")
+	return cmd
+}
+
+func clusterHistoryCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("cluster-history takes no arguments"))
+	}
+
+	failures := 0
+	c := mustClientFromCmd(cmd)
+	for _, ep := range endpointsFromCluster(cmd) {
+		ctx, cancel := commandCtx(cmd)
+		resp, err := c.ClusterHistory(ctx, ep, clusterHistoryLimit)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to get cluster history of etcd member[%s] (%v)\n", ep, err)
+			failures++
+			continue
+		}
+		fmt.Printf("etcd member[%s] cluster history:\n", ep)
+		for _, ev := range resp.Events {
+			line := fmt.Sprintf("%s %s member: %x, term: %d",
+				time.Unix(0, ev.Time).UTC().Format(time.RFC3339Nano), ev.Type, ev.MemberID, ev.Term)
+			if ev.Index != 0 {
+				line += fmt.Sprintf(", index: %d", ev.Index)
+			}
+			if ev.Detail != "" {
+				line += ", " + ev.Detail
+			}
+			fmt.Println(line)
+		}
+	}
+
+	if failures != 0 {
+		os.Exit(cobrautl.ExitError)
+	}
+}
@@ -31,9 +31,18 @@ func NewDefragCommand() *cobra.Command {
 		Run:   defragCommandFunc,
 	}
 	cmd.PersistentFlags().BoolVar(&epClusterEndpoints, "cluster", false, "use all endpoints from the cluster member list")
+	cmd.AddCommand(newDefragStatusCommand())
 	return cmd
 }
 
+func newDefragStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Prints the progress of the defragmentation of the etcd members with given endpoints",
+		Run:   defragStatusCommandFunc,
+	}
+}
+
 func defragCommandFunc(cmd *cobra.Command, args []string) {
 
 	failures := 0
@@ -56,3 +65,27 @@ func defragCommandFunc(cmd *cobra.Command, args []string) {
 		os.Exit(cobrautl.ExitError)
 	}
 }
+
+func defragStatusCommandFunc(cmd *cobra.Command, args []string) {
+	failures := 0
+	c := mustClientFromCmd(cmd)
+	for _, ep := range endpointsFromCluster(cmd) {
+		ctx, cancel := commandCtx(cmd)
+		resp, err := c.DefragmentStatus(ctx, ep)
+		cancel()
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Failed to get the defragmentation status of etcd member[%s] (%v)\n", ep, err)
+			failures++
+		case !resp.InProgress:
+			fmt.Printf("No defragmentation in progress on etcd member[%s]\n", ep)
+		default:
+			took := time.Since(time.Unix(0, resp.StartTime)).Round(time.Millisecond)
+			fmt.Printf("Defragmenting etcd member[%s]: phase %s, copied %d/%d keys, %d keys pending, running for %s\n", ep, resp.Phase, resp.CopiedKeys, resp.TotalKeys, resp.PendingKeys, took)
+		}
+	}
+
+	if failures != 0 {
+		os.Exit(cobrautl.ExitError)
+	}
+}
@@ -45,6 +45,7 @@ func NewEndpointCommand() *cobra.Command {
 	ec.AddCommand(newEpHealthCommand())
 	ec.AddCommand(newEpStatusCommand())
 	ec.AddCommand(newEpHashKVCommand())
+	ec.AddCommand(newEpConfigCommand())
 
 	return ec
 }
@@ -88,6 +89,21 @@ type epHealth struct {
 }
 
 // epHealthCommandFunc executes the "endpoint-health" command.
+func newEpConfigCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "config",
+		Short: "Prints out the runtime configuration of endpoints specified in `--endpoints` flag",
+		Long: `Prints out the resolved configuration each endpoint is running with, by flag name,
+including defaults and experimental features, with secrets masked. Comparing the
+output of the members detects configuration drift across the cluster.
+
+When --write-out is set to simple, this command prints out one comma-separated
+endpoint, name, value list per configuration flag.
+`,
+		Run: epConfigCommandFunc,
+	}
+}
+
 func epHealthCommandFunc(cmd *cobra.Command, args []string) {
 	lg, err := logutil.CreateDefaultZapLogger(zap.InfoLevel)
 	if err != nil {
@@ -243,6 +259,35 @@ func epHashKVCommandFunc(cmd *cobra.Command, args []string) {
 	}
 }
 
+type epConfig struct {
+	Ep   string                          `json:"Endpoint"`
+	Resp *clientv3.RuntimeConfigResponse `json:"Config"`
+}
+
+func epConfigCommandFunc(cmd *cobra.Command, args []string) {
+	c := mustClientFromCmd(cmd)
+
+	configList := []epConfig{}
+	var err error
+	for _, ep := range endpointsFromCluster(cmd) {
+		ctx, cancel := commandCtx(cmd)
+		resp, cerr := c.RuntimeConfig(ctx, ep)
+		cancel()
+		if cerr != nil {
+			err = cerr
+			fmt.Fprintf(os.Stderr, "Failed to get the configuration of endpoint %s (%v)\n", ep, cerr)
+			continue
+		}
+		configList = append(configList, epConfig{Ep: ep, Resp: resp})
+	}
+
+	display.EndpointConfig(configList)
+
+	if err != nil {
+		os.Exit(cobrautl.ExitError)
+	}
+}
+
 func endpointsFromCluster(cmd *cobra.Command) []string {
 	if !epClusterEndpoints {
 		endpoints, err := cmd.Flags().GetStringSlice("endpoints")
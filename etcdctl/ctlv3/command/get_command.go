@@ -24,16 +24,17 @@ import (
 )
 
 var (
-	getConsistency string
-	getLimit       int64
-	getSortOrder   string
-	getSortTarget  string
-	getPrefix      bool
-	getFromKey     bool
-	getRev         int64
-	getKeysOnly    bool
-	getCountOnly   bool
-	printValueOnly bool
+	getConsistency  string
+	getMaxStaleness uint64
+	getLimit        int64
+	getSortOrder    string
+	getSortTarget   string
+	getPrefix       bool
+	getFromKey      bool
+	getRev          int64
+	getKeysOnly     bool
+	getCountOnly    bool
+	printValueOnly  bool
 )
 
 // NewGetCommand returns the cobra command for "get".
@@ -45,8 +46,9 @@ func NewGetCommand() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&getConsistency, "consistency", "l", "Linearizable(l) or Serializable(s)")
+	cmd.Flags().Uint64Var(&getMaxStaleness, "max-staleness", 0, "Maximum number of committed entries a serializable read may not see; requires --consistency=s")
 	cmd.Flags().StringVar(&getSortOrder, "order", "", "Order of results; ASCEND or DESCEND (ASCEND by default)")
-	cmd.Flags().StringVar(&getSortTarget, "sort-by", "", "Sort target; CREATE, KEY, MODIFY, VALUE, or VERSION")
+	cmd.Flags().StringVar(&getSortTarget, "sort-by", "", "Sort target; CREATE, KEY, LEASE, MODIFY, VALUE, or VERSION")
 	cmd.Flags().Int64Var(&getLimit, "limit", 0, "Maximum number of results")
 	cmd.Flags().BoolVar(&getPrefix, "prefix", false, "Get keys with matching prefix")
 	cmd.Flags().BoolVar(&getFromKey, "from-key", false, "Get keys that are greater than or equal to the given key using byte compare")
@@ -62,7 +64,7 @@ func NewGetCommand() *cobra.Command {
 		return []string{"ASCEND", "DESCEND"}, cobra.ShellCompDirectiveDefault
 	})
 	cmd.RegisterFlagCompletionFunc("sort-by", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
-		return []string{"CREATE", "KEY", "MODIFY", "VALUE", "VERSION"}, cobra.ShellCompDirectiveDefault
+		return []string{"CREATE", "KEY", "LEASE", "MODIFY", "VALUE", "VERSION"}, cobra.ShellCompDirectiveDefault
 	})
 
 	return cmd
@@ -107,10 +109,18 @@ func getGetOp(args []string) (string, []clientv3.OpOption) {
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("`--keys-only` and `--count-only` cannot be set at the same time, choose one"))
 	}
 
+	if getMaxStaleness != 0 && getConsistency != "s" {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("`--max-staleness` requires `--consistency=s`"))
+	}
+
 	opts := []clientv3.OpOption{}
 	switch getConsistency {
 	case "s":
-		opts = append(opts, clientv3.WithSerializable())
+		if getMaxStaleness != 0 {
+			opts = append(opts, clientv3.WithStaleness(getMaxStaleness))
+		} else {
+			opts = append(opts, clientv3.WithSerializable())
+		}
 	case "l":
 	default:
 		cobrautl.ExitWithError(cobrautl.ExitBadFeature, fmt.Errorf("unknown consistency flag %q", getConsistency))
@@ -149,6 +159,8 @@ func getGetOp(args []string) (string, []clientv3.OpOption) {
 		sortByTarget = clientv3.SortByCreateRevision
 	case sortTarget == "KEY":
 		sortByTarget = clientv3.SortByKey
+	case sortTarget == "LEASE":
+		sortByTarget = clientv3.SortByLease
 	case sortTarget == "MODIFY":
 		sortByTarget = clientv3.SortByModRevision
 	case sortTarget == "VALUE":
@@ -57,7 +57,8 @@ type GlobalFlags struct {
 	User     string
 	Password string
 
-	Debug bool
+	Debug      bool
+	DebugTrace bool
 }
 
 type discoveryCfg struct {
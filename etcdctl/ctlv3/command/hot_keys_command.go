@@ -0,0 +1,69 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+)
+
+var hotKeysLimit int64
+
+// NewHotKeysCommand returns the cobra command for "hot-keys".
+func NewHotKeysCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hot-keys",
+		Short: "Lists the most accessed key prefixes of the etcd members with given endpoints",
+		Long: `Lists the most accessed key prefixes of the etcd members with given endpoints,
+with the estimated number of reads and writes over the sampling window. The
+members must be started with --experimental-hot-key-sample-rate.`,
+		Run: hotKeysCommandFunc,
+	}
+	cmd.Flags().Int64Var(&hotKeysLimit, "limit", 10, "maximum number of prefixes to list per member, 0 for all")
+	cmd.PersistentFlags().BoolVar(&epClusterEndpoints, "cluster", false, "use all endpoints from the cluster member list")
+	return cmd
+}
+
+func hotKeysCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("hot-keys takes no arguments"))
+	}
+
+	failures := 0
+	c := mustClientFromCmd(cmd)
+	for _, ep := range endpointsFromCluster(cmd) {
+		ctx, cancel := commandCtx(cmd)
+		resp, err := c.HotKeys(ctx, ep, hotKeysLimit)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to get hot keys of etcd member[%s] (%v)\n", ep, err)
+			failures++
+			continue
+		}
+		window := time.Duration(resp.WindowMs) * time.Millisecond
+		fmt.Printf("etcd member[%s] hot keys over %v (sample rate %v):\n", ep, window, resp.SampleRate)
+		for _, p := range resp.Prefixes {
+			fmt.Printf("%q reads: %d, writes: %d\n", p.Prefix, p.Reads, p.Writes)
+		}
+	}
+
+	if failures != 0 {
+		os.Exit(cobrautl.ExitError)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+)
+
+var (
+	incrInitial  int64
+	incrWidth    int64
+	incrBinary   bool
+	incrLeaseStr string
+)
+
+// NewIncrCommand returns the cobra command for "incr".
+func NewIncrCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "incr [options] <key> [delta]",
+		Short: "Atomically adds a delta to the integer value of a key",
+		Long: `Atomically adds the delta, 1 if not given, to the integer value of the key
+and prints the new value. A key that does not exist is created with the
+initial value before the delta is added. A negative delta is given after --,
+e.g. 'incr -- counter -1'.`,
+		Run: incrCommandFunc,
+	}
+
+	cmd.Flags().Int64Var(&incrInitial, "initial", 0, "value of the key before the increment if it does not exist")
+	cmd.Flags().Int64Var(&incrWidth, "width", 0, "pad the value with leading zeros up to this number of digits")
+	cmd.Flags().BoolVar(&incrBinary, "binary", false, "store the value as 8 big-endian bytes rather than in decimal")
+	cmd.Flags().StringVar(&incrLeaseStr, "lease", "0", "lease ID (in hexadecimal) to attach to the key if it is created")
+	return cmd
+}
+
+// incrCommandFunc executes the "incr" command.
+func incrCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 && len(args) != 2 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("incr command needs the key and an optional delta"))
+	}
+	delta := int64(1)
+	if len(args) == 2 {
+		var err error
+		if delta, err = strconv.ParseInt(args[1], 10, 64); err != nil {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("bad delta (%v)", err))
+		}
+	}
+	id, err := strconv.ParseInt(incrLeaseStr, 16, 64)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("bad lease ID (%v), expecting ID in Hex", err))
+	}
+
+	opts := []clientv3.OpOption{
+		clientv3.WithIncrementInitial(incrInitial),
+		clientv3.WithIncrementWidth(incrWidth),
+		clientv3.WithLease(clientv3.LeaseID(id)),
+	}
+	if incrBinary {
+		opts = append(opts, clientv3.WithIncrementBinary())
+	}
+
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).Increment(ctx, args[0], delta, opts...)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	fmt.Println(resp.Value)
+}
@@ -17,6 +17,8 @@ package command
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
 	"strconv"
 
 	v3 "go.etcd.io/etcd/client/v3"
@@ -37,6 +39,9 @@ func NewLeaseCommand() *cobra.Command {
 	lc.AddCommand(NewLeaseTimeToLiveCommand())
 	lc.AddCommand(NewLeaseListCommand())
 	lc.AddCommand(NewLeaseKeepAliveCommand())
+	lc.AddCommand(NewLeaseAttachCommand())
+	lc.AddCommand(NewLeaseDetachCommand())
+	lc.AddCommand(NewLeaseWatchCommand())
 
 	return lc
 }
@@ -73,32 +78,84 @@ func leaseGrantCommandFunc(cmd *cobra.Command, args []string) {
 	display.Grant(*resp)
 }
 
+var revokePrefixOfAttachedKeys string
+
 // NewLeaseRevokeCommand returns the cobra command for "lease revoke".
 func NewLeaseRevokeCommand() *cobra.Command {
 	lc := &cobra.Command{
-		Use:   "revoke <leaseID>",
+		Use:   "revoke <leaseID> [<leaseID>...]",
 		Short: "Revokes leases",
 
 		Run: leaseRevokeCommandFunc,
 	}
+	lc.Flags().StringVar(&revokePrefixOfAttachedKeys, "prefix-of-attached-keys", "", "Revoke the leases attached to the keys with the given prefix instead of the given IDs")
 
 	return lc
 }
 
-// leaseRevokeCommandFunc executes the "lease grant" command.
+// leaseRevokeCommandFunc executes the "lease revoke" command.
 func leaseRevokeCommandFunc(cmd *cobra.Command, args []string) {
-	if len(args) != 1 {
-		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("lease revoke command needs 1 argument"))
+	prefixSet := cmd.Flags().Changed("prefix-of-attached-keys")
+	if prefixSet && len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("lease revoke command does not take lease IDs with --prefix-of-attached-keys"))
+	}
+	if !prefixSet && len(args) == 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("lease revoke command needs at least 1 argument"))
 	}
 
-	id := leaseFromArgs(args[0])
+	c := mustClientFromCmd(cmd)
+	if len(args) == 1 {
+		id := leaseFromArgs(args[0])
+		ctx, cancel := commandCtx(cmd)
+		resp, err := c.Revoke(ctx, id)
+		cancel()
+		if err != nil {
+			cobrautl.ExitWithError(cobrautl.ExitError, fmt.Errorf("failed to revoke lease (%v)", err))
+		}
+		display.Revoke(id, *resp)
+		return
+	}
+
+	var ids []v3.LeaseID
+	if prefixSet {
+		ids = leasesOfPrefix(cmd, c, revokePrefixOfAttachedKeys)
+	} else {
+		for _, arg := range args {
+			ids = append(ids, leaseFromArgs(arg))
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
 	ctx, cancel := commandCtx(cmd)
-	resp, err := mustClientFromCmd(cmd).Revoke(ctx, id)
+	resp, err := c.RevokeBulk(ctx, ids...)
 	cancel()
 	if err != nil {
-		cobrautl.ExitWithError(cobrautl.ExitError, fmt.Errorf("failed to revoke lease (%v)", err))
+		cobrautl.ExitWithError(cobrautl.ExitError, fmt.Errorf("failed to revoke leases (%v)", err))
+	}
+	display.RevokeBulk(*resp)
+}
+
+// leasesOfPrefix returns the sorted IDs of the leases attached to the keys
+// with the given prefix.
+func leasesOfPrefix(cmd *cobra.Command, c *v3.Client, prefix string) []v3.LeaseID {
+	ctx, cancel := commandCtx(cmd)
+	resp, err := c.Get(ctx, prefix, v3.WithPrefix(), v3.WithKeysOnly())
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, fmt.Errorf("failed to get the keys with prefix %q (%v)", prefix, err))
+	}
+	seen := make(map[int64]struct{})
+	var ids []v3.LeaseID
+	for _, kv := range resp.Kvs {
+		if _, ok := seen[kv.Lease]; ok || kv.Lease == int64(v3.NoLease) {
+			continue
+		}
+		seen[kv.Lease] = struct{}{}
+		ids = append(ids, v3.LeaseID(kv.Lease))
 	}
-	display.Revoke(id, *resp)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
 }
 
 var timeToLiveKeys bool
@@ -132,19 +189,40 @@ func leaseTimeToLiveCommandFunc(cmd *cobra.Command, args []string) {
 	display.TimeToLive(*resp, timeToLiveKeys)
 }
 
+var (
+	leaseListLimit     int64
+	leaseListFromID    string
+	leaseListKeyPrefix string
+	leaseListMinTTL    int64
+	leaseListMaxTTL    int64
+)
+
 // NewLeaseListCommand returns the cobra command for "lease list".
 func NewLeaseListCommand() *cobra.Command {
 	lc := &cobra.Command{
-		Use:   "list",
+		Use:   "list [options]",
 		Short: "List all active leases",
 		Run:   leaseListCommandFunc,
 	}
+	lc.Flags().Int64Var(&leaseListLimit, "limit", 0, "Maximum number of leases to list, in the order of their IDs")
+	lc.Flags().StringVar(&leaseListFromID, "from-id", "", "List the leases from this lease ID in Hex")
+	lc.Flags().StringVar(&leaseListKeyPrefix, "key-prefix", "", "List only the leases with a key attached having this prefix")
+	lc.Flags().Int64Var(&leaseListMinTTL, "min-ttl", 0, "List only the leases with a remaining TTL in seconds of at least this (served by the leader only)")
+	lc.Flags().Int64Var(&leaseListMaxTTL, "max-ttl", 0, "List only the leases with a remaining TTL in seconds of at most this (served by the leader only)")
 	return lc
 }
 
 // leaseListCommandFunc executes the "lease list" command.
 func leaseListCommandFunc(cmd *cobra.Command, args []string) {
-	resp, rerr := mustClientFromCmd(cmd).Leases(context.TODO())
+	opts := []v3.LeaseOption{
+		v3.WithLeasesLimit(leaseListLimit),
+		v3.WithLeasesKeyPrefix(leaseListKeyPrefix),
+		v3.WithLeasesTTLRange(leaseListMinTTL, leaseListMaxTTL),
+	}
+	if leaseListFromID != "" {
+		opts = append(opts, v3.WithLeasesFromID(leaseFromArgs(leaseListFromID)))
+	}
+	resp, rerr := mustClientFromCmd(cmd).Leases(context.TODO(), opts...)
 	if rerr != nil {
 		cobrautl.ExitWithError(cobrautl.ExitBadConnection, rerr)
 	}
@@ -199,6 +277,96 @@ func leaseKeepAliveCommandFunc(cmd *cobra.Command, args []string) {
 	}
 }
 
+var (
+	setLeasePrefix       bool
+	setLeaseFromKey      bool
+	setLeaseBumpRevision bool
+)
+
+// NewLeaseAttachCommand returns the cobra command for "lease attach".
+func NewLeaseAttachCommand() *cobra.Command {
+	lc := &cobra.Command{
+		Use:   "attach [options] <leaseID> <key> [range_end]",
+		Short: "Attaches a lease to existing keys",
+		Long: `Attaches the lease to the key, or to the keys in [key, range_end), without
+sending their values again. The keys keep their revisions unless
+--bump-revision is given.`,
+
+		Run: leaseAttachCommandFunc,
+	}
+	addSetLeaseFlags(lc)
+	return lc
+}
+
+// NewLeaseDetachCommand returns the cobra command for "lease detach".
+func NewLeaseDetachCommand() *cobra.Command {
+	lc := &cobra.Command{
+		Use:   "detach [options] <key> [range_end]",
+		Short: "Detaches the lease of existing keys",
+		Long: `Detaches the lease of the key, or of the keys in [key, range_end), without
+sending their values again. The keys keep their revisions unless
+--bump-revision is given.`,
+
+		Run: leaseDetachCommandFunc,
+	}
+	addSetLeaseFlags(lc)
+	return lc
+}
+
+func addSetLeaseFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&setLeasePrefix, "prefix", false, "set the lease of the keys with matching prefix")
+	cmd.Flags().BoolVar(&setLeaseFromKey, "from-key", false, "set the lease of the keys that are greater than or equal to the given key using byte compare")
+	cmd.Flags().BoolVar(&setLeaseBumpRevision, "bump-revision", false, "put the keys again, incrementing their revisions and generating put events")
+}
+
+// leaseAttachCommandFunc executes the "lease attach" command.
+func leaseAttachCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) < 2 || len(args) > 3 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("lease attach command needs lease ID and key as arguments"))
+	}
+	setLease(cmd, leaseFromArgs(args[0]), args[1:])
+}
+
+// leaseDetachCommandFunc executes the "lease detach" command.
+func leaseDetachCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("lease detach command needs key as argument"))
+	}
+	setLease(cmd, v3.NoLease, args)
+}
+
+// setLease sets the lease of the key, or of the keys in the range, of args
+// and prints the number of keys whose lease is set.
+func setLease(cmd *cobra.Command, id v3.LeaseID, args []string) {
+	if setLeasePrefix && setLeaseFromKey {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("`--prefix` and `--from-key` cannot be set at the same time, choose one"))
+	}
+	var opts []v3.OpOption
+	if len(args) == 2 {
+		if setLeasePrefix || setLeaseFromKey {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("too many arguments, only accept one argument when `--prefix` or `--from-key` is set"))
+		}
+		opts = append(opts, v3.WithRange(args[1]))
+	}
+	if setLeasePrefix {
+		opts = append(opts, v3.WithPrefix())
+	}
+	if setLeaseFromKey {
+		opts = append(opts, v3.WithFromKey())
+	}
+	if setLeaseBumpRevision {
+		opts = append(opts, v3.WithBumpRevision())
+	}
+
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).SetLease(ctx, args[0], id, opts...)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	fmt.Println(resp.Count)
+}
+
 func leaseFromArgs(arg string) v3.LeaseID {
 	id, err := strconv.ParseInt(arg, 16, 64)
 	if err != nil {
@@ -206,3 +374,46 @@ func leaseFromArgs(arg string) v3.LeaseID {
 	}
 	return v3.LeaseID(id)
 }
+
+// NewLeaseWatchCommand returns the cobra command for "lease watch".
+func NewLeaseWatchCommand() *cobra.Command {
+	lc := &cobra.Command{
+		Use:   "watch [leaseID]",
+		Short: "Watches the lifecycle events of leases",
+		Long: `Watches the lifecycle events of the lease, or of all the leases: granted,
+renewed after a gap, expired, revoked and keys deleted. Only the events
+after the watch is created are printed.`,
+
+		Run: leaseWatchCommandFunc,
+	}
+	return lc
+}
+
+// leaseWatchCommandFunc executes the "lease watch" command.
+func leaseWatchCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("lease watch command accepts at most one lease ID as argument"))
+	}
+	key, opts := v3.LeaseEventsPrefix, []v3.OpOption{v3.WithPrefix()}
+	if len(args) == 1 {
+		key, opts = v3.LeaseEventKey(leaseFromArgs(args[0])), nil
+	}
+
+	c := mustClientFromCmd(cmd)
+	for resp := range c.Watch(v3.WithRequireLeader(context.Background()), key, opts...) {
+		if resp.Canceled {
+			fmt.Fprintf(os.Stderr, "watch was canceled (%v)\n", resp.Err())
+		}
+		for _, ev := range resp.Events {
+			le, err := v3.ParseLeaseEvent(ev)
+			if err != nil {
+				cobrautl.ExitWithError(cobrautl.ExitError, err)
+			}
+			display.LeaseEvent(*le)
+		}
+	}
+	if err := c.Close(); err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadConnection, err)
+	}
+	cobrautl.ExitWithError(cobrautl.ExitInterrupted, fmt.Errorf("watch is canceled by the server"))
+}
@@ -0,0 +1,79 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+)
+
+var logLevelGRPCTracing string
+
+// NewLogLevelCommand returns the cobra command for "log-level".
+func NewLogLevelCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "log-level [level]",
+		Short: "Gets or changes the log level of the etcd members with given endpoints",
+		Long: `Gets or changes the log level (debug, info, warn, error, panic or fatal) and
+gRPC tracing of the etcd members with given endpoints at runtime. The change is
+not persisted across restarts of the members.`,
+		Run: logLevelCommandFunc,
+	}
+	cmd.Flags().StringVar(&logLevelGRPCTracing, "grpc-tracing", "", "'on' or 'off' to enable or disable writing gRPC internal logs to the member log")
+	cmd.PersistentFlags().BoolVar(&epClusterEndpoints, "cluster", false, "use all endpoints from the cluster member list")
+	return cmd
+}
+
+func logLevelCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("log-level takes at most one argument"))
+	}
+	level := ""
+	if len(args) == 1 {
+		level = args[0]
+	}
+	tracing := clientv3.GRPCTracingKeep
+	switch logLevelGRPCTracing {
+	case "":
+	case "on":
+		tracing = clientv3.GRPCTracingEnable
+	case "off":
+		tracing = clientv3.GRPCTracingDisable
+	default:
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("invalid --grpc-tracing %q, must be 'on' or 'off'", logLevelGRPCTracing))
+	}
+
+	failures := 0
+	c := mustClientFromCmd(cmd)
+	for _, ep := range endpointsFromCluster(cmd) {
+		ctx, cancel := commandCtx(cmd)
+		resp, err := c.LogLevel(ctx, ep, level, tracing)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to change log level of etcd member[%s] (%v)\n", ep, err)
+			failures++
+			continue
+		}
+		fmt.Printf("etcd member[%s] log level: %s, gRPC tracing: %v\n", ep, resp.Level, resp.GrpcTracing)
+	}
+
+	if failures != 0 {
+		os.Exit(cobrautl.ExitError)
+	}
+}
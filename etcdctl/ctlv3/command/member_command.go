@@ -15,12 +15,18 @@
 package command
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/pkg/v3/cobrautl"
 )
@@ -28,8 +34,19 @@ import (
 var (
 	memberPeerURLs string
 	isLearner      bool
+
+	replaceAddPeerURLs        []string
+	replaceAddLearnerPeerURLs []string
+	replaceRemoveIDs          []string
+
+	promoteWait        bool
+	promoteWaitTimeout time.Duration
 )
 
+// learnerPollInterval is the interval "member promote --wait" polls the
+// progress of the learner at.
+const learnerPollInterval = time.Second
+
 // NewMemberCommand returns the cobra command for "member".
 func NewMemberCommand() *cobra.Command {
 	mc := &cobra.Command{
@@ -42,6 +59,8 @@ func NewMemberCommand() *cobra.Command {
 	mc.AddCommand(NewMemberUpdateCommand())
 	mc.AddCommand(NewMemberListCommand())
 	mc.AddCommand(NewMemberPromoteCommand())
+	mc.AddCommand(NewMemberReplaceCommand())
+	mc.AddCommand(NewMemberLearnersCommand())
 
 	return mc
 }
@@ -108,11 +127,49 @@ func NewMemberPromoteCommand() *cobra.Command {
 		Use:   "promote <memberID>",
 		Short: "Promotes a non-voting member in the cluster",
 		Long: `Promotes a non-voting learner member to a voting one in the cluster.
+With --wait, waits for the learner to catch up with the leader before promoting it.
 `,
 
 		Run: memberPromoteCommandFunc,
 	}
 
+	cc.Flags().BoolVar(&promoteWait, "wait", false, "wait for the learner to be ready to be promoted")
+	cc.Flags().DurationVar(&promoteWaitTimeout, "wait-timeout", 0, "maximum time to wait for the learner with --wait, 0 for no limit")
+
+	return cc
+}
+
+// NewMemberLearnersCommand returns the cobra command for "member learners".
+func NewMemberLearnersCommand() *cobra.Command {
+	cc := &cobra.Command{
+		Use:   "learners [memberID]",
+		Short: "Lists the catch-up progress of the learner members",
+		Long: `Lists how far the learner members, or the given one, are behind the leader,
+the estimated time for them to catch up, and whether they are ready to be promoted.
+`,
+
+		Run: memberLearnersCommandFunc,
+	}
+
+	return cc
+}
+
+// NewMemberReplaceCommand returns the cobra command for "member replace".
+func NewMemberReplaceCommand() *cobra.Command {
+	cc := &cobra.Command{
+		Use:   "replace [options]",
+		Short: "Adds and removes several members at once",
+		Long: `Adds and removes several members at once through joint consensus, the cluster
+keeping the quorum of both its current and new voting members during the change.
+`,
+
+		Run: memberReplaceCommandFunc,
+	}
+
+	cc.Flags().StringArrayVar(&replaceAddPeerURLs, "add", nil, "comma separated peer URLs of a member to add; repeat the flag to add several members.")
+	cc.Flags().StringArrayVar(&replaceAddLearnerPeerURLs, "add-learner", nil, "comma separated peer URLs of a learner member to add; repeat the flag to add several learners.")
+	cc.Flags().StringSliceVar(&replaceRemoveIDs, "remove", nil, "comma separated IDs of the members to remove.")
+
 	return cc
 }
 
@@ -246,11 +303,151 @@ func memberPromoteCommandFunc(cmd *cobra.Command, args []string) {
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("bad member ID arg (%v), expecting ID in Hex", err))
 	}
 
+	c := mustClientFromCmd(cmd)
+	if promoteWait {
+		waitLearnerReady(cmd, c, id)
+	}
+
 	ctx, cancel := commandCtx(cmd)
-	resp, err := mustClientFromCmd(cmd).MemberPromote(ctx, id)
+	resp, err := c.MemberPromote(ctx, id)
 	cancel()
 	if err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitError, err)
 	}
 	display.MemberPromote(id, *resp)
 }
+
+// waitLearnerReady polls the progress of the learner id on the leader until
+// it is ready to be promoted.
+func waitLearnerReady(cmd *cobra.Command, c *clientv3.Client, id uint64) {
+	var deadline time.Time
+	if promoteWaitTimeout > 0 {
+		deadline = time.Now().Add(promoteWaitTimeout)
+	}
+	for {
+		ctx, cancel := commandCtx(cmd)
+		resp, err := learnerStatus(ctx, c, id)
+		cancel()
+		switch {
+		case err == nil && len(resp.Learners) == 1 && resp.Learners[0].Ready:
+			return
+		case err == nil && len(resp.Learners) == 1:
+			fmt.Fprintf(os.Stderr, "Waiting for learner %s\n", learnerProgressString(resp.Learners[0]))
+		case err == nil:
+			fmt.Fprintf(os.Stderr, "Waiting for learner %x to be added to raft\n", id)
+		case errors.Is(err, rpctypes.ErrMemberNotFound), errors.Is(err, rpctypes.ErrMemberNotLearner):
+			cobrautl.ExitWithError(cobrautl.ExitError, err)
+		default:
+			// the leader may have changed or not be elected yet
+			fmt.Fprintf(os.Stderr, "Failed to get the progress of learner %x (%v)\n", id, err)
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			cobrautl.ExitWithError(cobrautl.ExitError, fmt.Errorf("learner %x not ready after %v", id, promoteWaitTimeout))
+		}
+		time.Sleep(learnerPollInterval)
+	}
+}
+
+// learnerStatus returns the progress of the learner id, or of all the
+// learners if id is 0, from the leader.
+func learnerStatus(ctx context.Context, c *clientv3.Client, id uint64) (*clientv3.LearnerStatusResponse, error) {
+	ep, err := leaderEndpoint(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return c.LearnerStatus(ctx, ep, id)
+}
+
+// leaderEndpoint returns the client URL of the leader of the cluster, which
+// may not be in the endpoints of c.
+func leaderEndpoint(ctx context.Context, c *clientv3.Client) (string, error) {
+	var leader uint64
+	for _, ep := range c.Endpoints() {
+		resp, err := c.Status(ctx, ep)
+		if err == nil && resp.Leader != 0 {
+			leader = resp.Leader
+			break
+		}
+	}
+	if leader == 0 {
+		return "", rpctypes.ErrNoLeader
+	}
+	resp, err := c.MemberList(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, m := range resp.Members {
+		if m.ID == leader && len(m.ClientURLs) > 0 {
+			return m.ClientURLs[0], nil
+		}
+	}
+	return "", fmt.Errorf("no client URL of leader %x", leader)
+}
+
+func learnerProgressString(p *pb.LearnerProgress) string {
+	eta := "unknown"
+	if p.EtaMs >= 0 {
+		eta = (time.Duration(p.EtaMs) * time.Millisecond).String()
+	}
+	return fmt.Sprintf("%x match index: %d, leader index: %d, lag: %d entries (%s), eta: %s, ready: %v",
+		p.ID, p.MatchIndex, p.LeaderIndex, p.LagEntries, humanize.Bytes(p.LagBytes), eta, p.Ready)
+}
+
+// memberLearnersCommandFunc executes the "member learners" command.
+func memberLearnersCommandFunc(cmd *cobra.Command, args []string) {
+	var id uint64
+	switch len(args) {
+	case 0:
+	case 1:
+		var err error
+		if id, err = strconv.ParseUint(args[0], 16, 64); err != nil {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("bad member ID arg (%v), expecting ID in Hex", err))
+		}
+	default:
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("too many arguments"))
+	}
+
+	ctx, cancel := commandCtx(cmd)
+	resp, err := learnerStatus(ctx, mustClientFromCmd(cmd), id)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	for _, p := range resp.Learners {
+		fmt.Println(learnerProgressString(p))
+	}
+}
+
+// memberReplaceCommandFunc executes the "member replace" command.
+func memberReplaceCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("member replace takes no arguments"))
+	}
+
+	var add []*pb.MemberAddRequest
+	for _, urls := range replaceAddPeerURLs {
+		add = append(add, &pb.MemberAddRequest{PeerURLs: strings.Split(urls, ",")})
+	}
+	for _, urls := range replaceAddLearnerPeerURLs {
+		add = append(add, &pb.MemberAddRequest{PeerURLs: strings.Split(urls, ","), IsLearner: true})
+	}
+	remove := make([]uint64, len(replaceRemoveIDs))
+	for i, s := range replaceRemoveIDs {
+		id, err := strconv.ParseUint(s, 16, 64)
+		if err != nil {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("bad member ID arg (%v), expecting ID in Hex", err))
+		}
+		remove[i] = id
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("no member to add or remove provided"))
+	}
+
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).MemberReplace(ctx, add, remove)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	display.MemberReplace(remove, *resp)
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+)
+
+var (
+	mvPrefix    bool
+	mvOverwrite bool
+	mvPrevKV    bool
+)
+
+// NewMoveCommand returns the cobra command for "mv".
+func NewMoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mv [options] <key> <destination>",
+		Short: "Moves the specified key, or the keys with a prefix, to another key or prefix",
+		Long: `Moves the specified key to the destination key with its value and lease, in a
+single revision. With --prefix, every key with the prefix <key> is moved, the
+prefix being replaced by <destination>. The values are moved by the members,
+without being sent to the client.`,
+		Run: moveCommandFunc,
+	}
+
+	cmd.Flags().BoolVar(&mvPrefix, "prefix", false, "move the keys with matching prefix")
+	cmd.Flags().BoolVar(&mvOverwrite, "overwrite", false, "replace the keys existing at the destination")
+	cmd.Flags().BoolVar(&mvPrevKV, "prev-kv", false, "return the moved key-value pairs")
+	return cmd
+}
+
+// moveCommandFunc executes the "mv" command.
+func moveCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("mv command needs 2 arguments, the key and its destination"))
+	}
+
+	var opts []clientv3.OpOption
+	if mvPrefix {
+		opts = append(opts, clientv3.WithPrefix())
+	}
+	if mvOverwrite {
+		opts = append(opts, clientv3.WithOverwrite())
+	}
+	if mvPrevKV {
+		opts = append(opts, clientv3.WithPrevKV())
+	}
+
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).Move(ctx, args[0], args[1], opts...)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	fmt.Println(resp.Moved)
+	for _, kv := range resp.PrevKvs {
+		printKV(false, false, kv)
+	}
+}
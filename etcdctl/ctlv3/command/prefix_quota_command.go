@@ -0,0 +1,126 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+)
+
+var (
+	prefixQuotaMaxBytes int64
+	prefixQuotaMaxKeys  int64
+)
+
+// NewPrefixQuotaCommand returns the cobra command for "prefix-quota".
+func NewPrefixQuotaCommand() *cobra.Command {
+	pc := &cobra.Command{
+		Use:   "prefix-quota <subcommand>",
+		Short: "Prefix quota related commands",
+	}
+
+	pc.AddCommand(newPrefixQuotaSetCommand())
+	pc.AddCommand(newPrefixQuotaDeleteCommand())
+	pc.AddCommand(newPrefixQuotaListCommand())
+
+	return pc
+}
+
+func newPrefixQuotaSetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <prefix>",
+		Short: "Sets the quota of the keys under a prefix",
+		Long: `Sets the maximum total size of the keys and their values and the maximum
+number of keys under a prefix. The puts and transactions that would exceed
+it fail with a prefix quota exceeded error.`,
+		Run: prefixQuotaSetCommandFunc,
+	}
+	cmd.Flags().Int64Var(&prefixQuotaMaxBytes, "max-bytes", 0, "maximum total size of the keys and values under the prefix, 0 for no limit")
+	cmd.Flags().Int64Var(&prefixQuotaMaxKeys, "max-keys", 0, "maximum number of keys under the prefix, 0 for no limit")
+	return cmd
+}
+
+// prefixQuotaSetCommandFunc executes the "prefix-quota set" command.
+func prefixQuotaSetCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("prefix-quota set command needs 1 argument"))
+	}
+	if prefixQuotaMaxBytes < 0 || prefixQuotaMaxKeys < 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("--max-bytes and --max-keys must not be negative"))
+	}
+	ctx, cancel := commandCtx(cmd)
+	_, err := mustClientFromCmd(cmd).PrefixQuotaSet(ctx, args[0], prefixQuotaMaxBytes, prefixQuotaMaxKeys)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	fmt.Printf("Set quota of prefix %q\n", args[0])
+}
+
+func newPrefixQuotaDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <prefix>",
+		Short: "Deletes the quota of the keys under a prefix",
+		Run:   prefixQuotaDeleteCommandFunc,
+	}
+}
+
+// prefixQuotaDeleteCommandFunc executes the "prefix-quota delete" command.
+func prefixQuotaDeleteCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("prefix-quota delete command needs 1 argument"))
+	}
+	ctx, cancel := commandCtx(cmd)
+	_, err := mustClientFromCmd(cmd).PrefixQuotaDelete(ctx, args[0])
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	fmt.Printf("Deleted quota of prefix %q\n", args[0])
+}
+
+func newPrefixQuotaListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Lists the quotas of the key prefixes with their usage",
+		Run:   prefixQuotaListCommandFunc,
+	}
+}
+
+// prefixQuotaListCommandFunc executes the "prefix-quota list" command.
+func prefixQuotaListCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("prefix-quota list command accepts no arguments"))
+	}
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).PrefixQuotaList(ctx)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	for _, q := range resp.Quotas {
+		fmt.Printf("%q bytes: %d/%s, keys: %d/%s\n", q.Prefix, q.UsedBytes, quotaLimit(q.MaxBytes), q.UsedKeys, quotaLimit(q.MaxKeys))
+	}
+}
+
+func quotaLimit(max int64) string {
+	if max == 0 {
+		return "unlimited"
+	}
+	return strconv.FormatInt(max, 10)
+}
@@ -0,0 +1,52 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+)
+
+// NewPrefixStatsCommand returns the cobra command for "prefix-stats".
+func NewPrefixStatsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prefix-stats [prefix]",
+		Short: "Shows the number of keys under a prefix and the size of their keys and values",
+		Long: `Shows the number of keys under a prefix and the total size of their keys and
+values, every key if the prefix is omitted. They are computed by the member
+without sending the keys.`,
+		Run: prefixStatsCommandFunc,
+	}
+}
+
+// prefixStatsCommandFunc executes the "prefix-stats" command.
+func prefixStatsCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("prefix-stats command accepts at most 1 argument"))
+	}
+	var prefix string
+	if len(args) == 1 {
+		prefix = args[0]
+	}
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).PrefixStats(ctx, prefix)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	fmt.Printf("%q keys: %d, key bytes: %d, value bytes: %d, revision: %d\n", prefix, resp.Count, resp.KeyBytes, resp.ValueBytes, resp.Header.Revision)
+}
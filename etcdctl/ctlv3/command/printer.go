@@ -35,19 +35,23 @@ type printer interface {
 
 	Grant(r v3.LeaseGrantResponse)
 	Revoke(id v3.LeaseID, r v3.LeaseRevokeResponse)
+	RevokeBulk(r v3.LeaseRevokeBulkResponse)
 	KeepAlive(r v3.LeaseKeepAliveResponse)
 	TimeToLive(r v3.LeaseTimeToLiveResponse, keys bool)
 	Leases(r v3.LeaseLeasesResponse)
+	LeaseEvent(ev v3.LeaseEvent)
 
 	MemberAdd(v3.MemberAddResponse)
 	MemberRemove(id uint64, r v3.MemberRemoveResponse)
 	MemberUpdate(id uint64, r v3.MemberUpdateResponse)
 	MemberPromote(id uint64, r v3.MemberPromoteResponse)
+	MemberReplace(removed []uint64, r v3.MemberReplaceResponse)
 	MemberList(v3.MemberListResponse)
 
 	EndpointHealth([]epHealth)
 	EndpointStatus([]epStatus)
 	EndpointHashKV([]epHashKV)
+	EndpointConfig([]epConfig)
 	MoveLeader(leader, target uint64, r v3.MoveLeaderResponse)
 
 	DowngradeValidate(r v3.DowngradeResponse)
@@ -62,6 +66,7 @@ type printer interface {
 	RoleList(v3.AuthRoleListResponse)
 	RoleGrantPermission(role string, r v3.AuthRoleGrantPermissionResponse)
 	RoleRevokePermission(role string, key string, end string, r v3.AuthRoleRevokePermissionResponse)
+	RoleRevokeAdminPermission(role string, perms []string, r v3.AuthRoleRevokePermissionResponse)
 
 	UserAdd(user string, r v3.AuthUserAddResponse)
 	UserGet(user string, r v3.AuthUserGetResponse)
@@ -72,6 +77,10 @@ type printer interface {
 	UserDelete(user string, r v3.AuthUserDeleteResponse)
 
 	AuthStatus(r v3.AuthStatusResponse)
+	AuthLockoutList(r v3.AuthLockoutListResponse)
+	AuthLockoutClear(r v3.AuthLockoutClearResponse)
+	AuthSessionList(r v3.AuthSessionListResponse)
+	AuthSessionRevoke(r v3.AuthSessionRevokeResponse)
 }
 
 func NewPrinter(printerType string, isHex bool) printer {
@@ -103,9 +112,11 @@ func (p *printerRPC) Watch(r v3.WatchResponse) { p.p(&r) }
 
 func (p *printerRPC) Grant(r v3.LeaseGrantResponse)                      { p.p(r) }
 func (p *printerRPC) Revoke(id v3.LeaseID, r v3.LeaseRevokeResponse)     { p.p(r) }
+func (p *printerRPC) RevokeBulk(r v3.LeaseRevokeBulkResponse)            { p.p((*pb.LeaseRevokeBulkResponse)(&r)) }
 func (p *printerRPC) KeepAlive(r v3.LeaseKeepAliveResponse)              { p.p(r) }
 func (p *printerRPC) TimeToLive(r v3.LeaseTimeToLiveResponse, keys bool) { p.p(&r) }
 func (p *printerRPC) Leases(r v3.LeaseLeasesResponse)                    { p.p(&r) }
+func (p *printerRPC) LeaseEvent(ev v3.LeaseEvent)                        { p.p(ev) }
 
 func (p *printerRPC) MemberAdd(r v3.MemberAddResponse) { p.p((*pb.MemberAddResponse)(&r)) }
 func (p *printerRPC) MemberRemove(id uint64, r v3.MemberRemoveResponse) {
@@ -117,6 +128,9 @@ func (p *printerRPC) MemberUpdate(id uint64, r v3.MemberUpdateResponse) {
 func (p *printerRPC) MemberPromote(id uint64, r v3.MemberPromoteResponse) {
 	p.p((*pb.MemberPromoteResponse)(&r))
 }
+func (p *printerRPC) MemberReplace(removed []uint64, r v3.MemberReplaceResponse) {
+	p.p((*pb.MemberReplaceResponse)(&r))
+}
 func (p *printerRPC) MemberList(r v3.MemberListResponse) { p.p((*pb.MemberListResponse)(&r)) }
 func (p *printerRPC) Alarm(r v3.AlarmResponse)           { p.p((*pb.AlarmResponse)(&r)) }
 func (p *printerRPC) MoveLeader(leader, target uint64, r v3.MoveLeaderResponse) {
@@ -137,6 +151,9 @@ func (p *printerRPC) RoleGrantPermission(_ string, r v3.AuthRoleGrantPermissionR
 func (p *printerRPC) RoleRevokePermission(_ string, _ string, _ string, r v3.AuthRoleRevokePermissionResponse) {
 	p.p((*pb.AuthRoleRevokePermissionResponse)(&r))
 }
+func (p *printerRPC) RoleRevokeAdminPermission(_ string, _ []string, r v3.AuthRoleRevokePermissionResponse) {
+	p.p((*pb.AuthRoleRevokePermissionResponse)(&r))
+}
 func (p *printerRPC) UserAdd(_ string, r v3.AuthUserAddResponse) { p.p((*pb.AuthUserAddResponse)(&r)) }
 func (p *printerRPC) UserGet(_ string, r v3.AuthUserGetResponse) { p.p((*pb.AuthUserGetResponse)(&r)) }
 func (p *printerRPC) UserList(r v3.AuthUserListResponse)         { p.p((*pb.AuthUserListResponse)(&r)) }
@@ -155,6 +172,18 @@ func (p *printerRPC) UserDelete(_ string, r v3.AuthUserDeleteResponse) {
 func (p *printerRPC) AuthStatus(r v3.AuthStatusResponse) {
 	p.p((*pb.AuthStatusResponse)(&r))
 }
+func (p *printerRPC) AuthLockoutList(r v3.AuthLockoutListResponse) {
+	p.p((*pb.AuthLockoutListResponse)(&r))
+}
+func (p *printerRPC) AuthLockoutClear(r v3.AuthLockoutClearResponse) {
+	p.p((*pb.AuthLockoutClearResponse)(&r))
+}
+func (p *printerRPC) AuthSessionList(r v3.AuthSessionListResponse) {
+	p.p((*pb.AuthSessionListResponse)(&r))
+}
+func (p *printerRPC) AuthSessionRevoke(r v3.AuthSessionRevokeResponse) {
+	p.p((*pb.AuthSessionRevokeResponse)(&r))
+}
 
 type printerUnsupported struct{ printerRPC }
 
@@ -168,6 +197,7 @@ func newPrinterUnsupported(n string) printer {
 func (p *printerUnsupported) EndpointHealth([]epHealth) { p.p(nil) }
 func (p *printerUnsupported) EndpointStatus([]epStatus) { p.p(nil) }
 func (p *printerUnsupported) EndpointHashKV([]epHashKV) { p.p(nil) }
+func (p *printerUnsupported) EndpointConfig([]epConfig) { p.p(nil) }
 
 func (p *printerUnsupported) MoveLeader(leader, target uint64, r v3.MoveLeaderResponse) { p.p(nil) }
 func (p *printerUnsupported) DowngradeValidate(r v3.DowngradeResponse)                  { p.p(nil) }
@@ -232,6 +262,16 @@ func makeEndpointStatusTable(statusList []epStatus) (hdr []string, rows [][]stri
 	return hdr, rows
 }
 
+func makeEndpointConfigTable(configList []epConfig) (hdr []string, rows [][]string) {
+	hdr = []string{"endpoint", "name", "value"}
+	for _, c := range configList {
+		for _, e := range c.Resp.Entries {
+			rows = append(rows, []string{c.Ep, e.Name, e.Value})
+		}
+	}
+	return hdr, rows
+}
+
 func makeEndpointHashKVTable(hashList []epHashKV) (hdr []string, rows [][]string) {
 	hdr = []string{"endpoint", "hash"}
 	for _, h := range hashList {
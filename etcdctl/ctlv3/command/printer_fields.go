@@ -127,7 +127,11 @@ func (p *fieldsPrinter) Leases(r v3.LeaseLeasesResponse) {
 	p.hdr(r.ResponseHeader)
 	for _, item := range r.Leases {
 		fmt.Println(`"ID" :`, item.ID)
+		fmt.Println(`"TTL" :`, item.TTL)
+		fmt.Println(`"GrantedTTL" :`, item.GrantedTTL)
+		fmt.Println(`"KeyCount" :`, item.KeyCount)
 	}
+	fmt.Println(`"More" :`, r.More)
 }
 
 func (p *fieldsPrinter) MemberList(r v3.MemberListResponse) {
@@ -168,6 +172,15 @@ func (p *fieldsPrinter) EndpointStatus(eps []epStatus) {
 		fmt.Println(`"RaftIndex" :`, ep.Resp.RaftIndex)
 		fmt.Println(`"RaftTerm" :`, ep.Resp.RaftTerm)
 		fmt.Println(`"RaftAppliedIndex" :`, ep.Resp.RaftAppliedIndex)
+		fmt.Println(`"BackendBatchIntervalMs" :`, ep.Resp.BackendBatchIntervalMs)
+		fmt.Println(`"BackendBatchLimit" :`, ep.Resp.BackendBatchLimit)
+		for _, f := range ep.Resp.Followers {
+			fmt.Println(`"FollowerID" :`, f.ID)
+			fmt.Println(`"FollowerInflightMessages" :`, f.InflightMessages)
+			fmt.Println(`"FollowerInflightBytes" :`, f.InflightBytes)
+			fmt.Println(`"FollowerWindow" :`, f.Window)
+			fmt.Println(`"FollowerThrottled" :`, f.Throttled)
+		}
 		fmt.Println(`"Errors" :`, ep.Resp.Errors)
 		fmt.Printf("\"Endpoint\" : %q\n", ep.Ep)
 		fmt.Println()
@@ -183,6 +196,17 @@ func (p *fieldsPrinter) EndpointHashKV(hs []epHashKV) {
 	}
 }
 
+func (p *fieldsPrinter) EndpointConfig(cs []epConfig) {
+	for _, c := range cs {
+		p.hdr(c.Resp.Header)
+		for _, e := range c.Resp.Entries {
+			fmt.Printf("%q : %q\n", e.Name, e.Value)
+		}
+		fmt.Printf("\"Endpoint\" : %q\n", c.Ep)
+		fmt.Println()
+	}
+}
+
 func (p *fieldsPrinter) Alarm(r v3.AlarmResponse) {
 	p.hdr(r.Header)
 	for _, a := range r.Alarms {
@@ -200,6 +224,9 @@ func (p *fieldsPrinter) RoleGet(role string, r v3.AuthRoleGetResponse) {
 		fmt.Printf("\"Key\" : %q\n", string(p.Key))
 		fmt.Printf("\"RangeEnd\" : %q\n", string(p.RangeEnd))
 	}
+	for _, p := range r.AdminPerms {
+		fmt.Println(`"AdminPerm" : `, p.String())
+	}
 }
 func (p *fieldsPrinter) RoleDelete(role string, r v3.AuthRoleDeleteResponse) { p.hdr(r.Header) }
 func (p *fieldsPrinter) RoleList(r v3.AuthRoleListResponse) {
@@ -216,6 +243,9 @@ func (p *fieldsPrinter) RoleGrantPermission(role string, r v3.AuthRoleGrantPermi
 func (p *fieldsPrinter) RoleRevokePermission(role string, key string, end string, r v3.AuthRoleRevokePermissionResponse) {
 	p.hdr(r.Header)
 }
+func (p *fieldsPrinter) RoleRevokeAdminPermission(role string, perms []string, r v3.AuthRoleRevokePermissionResponse) {
+	p.hdr(r.Header)
+}
 func (p *fieldsPrinter) UserAdd(user string, r v3.AuthUserAddResponse)          { p.hdr(r.Header) }
 func (p *fieldsPrinter) UserChangePassword(r v3.AuthUserChangePasswordResponse) { p.hdr(r.Header) }
 func (p *fieldsPrinter) UserGrantRole(user string, role string, r v3.AuthUserGrantRoleResponse) {
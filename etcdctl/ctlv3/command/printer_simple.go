@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/client/pkg/v3/types"
@@ -91,6 +92,15 @@ func (s *simplePrinter) Revoke(id v3.LeaseID, r v3.LeaseRevokeResponse) {
 	fmt.Printf("lease %016x revoked\n", id)
 }
 
+func (s *simplePrinter) RevokeBulk(r v3.LeaseRevokeBulkResponse) {
+	for _, id := range r.Revoked {
+		fmt.Printf("lease %016x revoked\n", id)
+	}
+	for _, id := range r.NotFound {
+		fmt.Printf("lease %016x not found\n", id)
+	}
+}
+
 func (s *simplePrinter) KeepAlive(resp v3.LeaseKeepAliveResponse) {
 	fmt.Printf("lease %016x keepalived with TTL(%d)\n", resp.ID, resp.TTL)
 }
@@ -112,11 +122,27 @@ func (s *simplePrinter) TimeToLive(resp v3.LeaseTimeToLiveResponse, keys bool) {
 	fmt.Println(txt)
 }
 
+func (s *simplePrinter) LeaseEvent(ev v3.LeaseEvent) {
+	switch ev.Type {
+	case v3.LeaseEventGranted:
+		fmt.Printf("lease %016x granted with TTL(%ds)\n", ev.ID, ev.TTL)
+	case v3.LeaseEventRenewedAfterGap:
+		fmt.Printf("lease %016x renewed after a gap with TTL(%ds)\n", ev.ID, ev.TTL)
+	case v3.LeaseEventKeysDeleted:
+		fmt.Printf("lease %016x deleted keys(%v)\n", ev.ID, ev.Keys)
+	default:
+		fmt.Printf("lease %016x %s\n", ev.ID, ev.Type)
+	}
+}
+
 func (s *simplePrinter) Leases(resp v3.LeaseLeasesResponse) {
 	fmt.Printf("found %d leases\n", len(resp.Leases))
 	for _, item := range resp.Leases {
 		fmt.Printf("%016x\n", item.ID)
 	}
+	if resp.More && len(resp.Leases) > 0 {
+		fmt.Printf("more leases from %016x\n", resp.Leases[len(resp.Leases)-1].ID+1)
+	}
 }
 
 func (s *simplePrinter) Alarm(resp v3.AlarmResponse) {
@@ -141,6 +167,15 @@ func (s *simplePrinter) MemberPromote(id uint64, r v3.MemberPromoteResponse) {
 	fmt.Printf("Member %16x promoted in cluster %16x\n", id, r.Header.ClusterId)
 }
 
+func (s *simplePrinter) MemberReplace(removed []uint64, r v3.MemberReplaceResponse) {
+	for _, m := range r.Added {
+		fmt.Printf("Member %16x added to cluster %16x\n", m.ID, r.Header.ClusterId)
+	}
+	for _, id := range removed {
+		fmt.Printf("Member %16x removed from cluster %16x\n", id, r.Header.ClusterId)
+	}
+}
+
 func (s *simplePrinter) MemberList(resp v3.MemberListResponse) {
 	_, rows := makeMemberListTable(resp)
 	for _, row := range rows {
@@ -172,6 +207,13 @@ func (s *simplePrinter) EndpointHashKV(hashList []epHashKV) {
 	}
 }
 
+func (s *simplePrinter) EndpointConfig(configList []epConfig) {
+	_, rows := makeEndpointConfigTable(configList)
+	for _, row := range rows {
+		fmt.Println(strings.Join(row, ", "))
+	}
+}
+
 func (s *simplePrinter) MoveLeader(leader, target uint64, r v3.MoveLeaderResponse) {
 	fmt.Printf("Leadership transferred from %s to %s\n", types.ID(leader), types.ID(target))
 }
@@ -217,7 +259,7 @@ func (s *simplePrinter) RoleGet(role string, r v3.AuthRoleGetResponse) {
 	}
 
 	for _, perm := range r.Perm {
-		if perm.PermType == v3.PermRead || perm.PermType == v3.PermReadWrite {
+		if perm.PermType == v3.PermRead || perm.PermType == v3.PermReadWrite || perm.PermType == v3.PermRange {
 			if len(perm.RangeEnd) == 0 {
 				fmt.Printf("\t%s\n", string(perm.Key))
 			} else {
@@ -235,6 +277,22 @@ func (s *simplePrinter) RoleGet(role string, r v3.AuthRoleGetResponse) {
 			}
 		}
 	}
+	fmt.Println("KV Watch:")
+	for _, perm := range r.Perm {
+		if perm.PermType == v3.PermRead || perm.PermType == v3.PermReadWrite || perm.PermType == v3.PermWatch {
+			if len(perm.RangeEnd) == 0 {
+				fmt.Printf("\t%s\n", string(perm.Key))
+			} else {
+				printRange((*v3.Permission)(perm))
+			}
+		}
+	}
+	if len(r.AdminPerms) != 0 {
+		fmt.Println("Admin:")
+		for _, perm := range r.AdminPerms {
+			fmt.Printf("\t%s\n", perm.String())
+		}
+	}
 }
 
 func (s *simplePrinter) RoleList(r v3.AuthRoleListResponse) {
@@ -263,6 +321,10 @@ func (s *simplePrinter) RoleRevokePermission(role string, key string, end string
 	}
 }
 
+func (s *simplePrinter) RoleRevokeAdminPermission(role string, perms []string, r v3.AuthRoleRevokePermissionResponse) {
+	fmt.Printf("Admin permissions %s are revoked from role %s\n", strings.Join(perms, ", "), role)
+}
+
 func (s *simplePrinter) UserAdd(name string, r v3.AuthUserAddResponse) {
 	fmt.Printf("User %s created\n", name)
 }
@@ -274,6 +336,12 @@ func (s *simplePrinter) UserGet(name string, r v3.AuthUserGetResponse) {
 		fmt.Printf(" %s", role)
 	}
 	fmt.Printf("\n")
+	for _, e := range r.RoleExpirations {
+		fmt.Printf("Role %s expires at %s\n", e.Role, time.Unix(e.ExpiresAt, 0).Format(time.RFC3339))
+	}
+	if len(r.HomePrefix) != 0 {
+		fmt.Printf("Home prefix: %s\n", r.HomePrefix)
+	}
 }
 
 func (s *simplePrinter) UserChangePassword(v3.AuthUserChangePasswordResponse) {
@@ -302,3 +370,30 @@ func (s *simplePrinter) AuthStatus(r v3.AuthStatusResponse) {
 	fmt.Println("Authentication Status:", r.Enabled)
 	fmt.Println("AuthRevision:", r.AuthRevision)
 }
+
+func (s *simplePrinter) AuthLockoutList(r v3.AuthLockoutListResponse) {
+	for _, l := range r.Lockouts {
+		until := time.Unix(l.Until, 0).Format(time.RFC3339)
+		if l.User != "" {
+			fmt.Printf("user %s, %d failures, locked out until %s\n", l.User, l.Failures, until)
+		} else {
+			fmt.Printf("address %s, %d failures, locked out until %s\n", l.Address, l.Failures, until)
+		}
+	}
+}
+
+func (s *simplePrinter) AuthLockoutClear(r v3.AuthLockoutClearResponse) {
+	fmt.Printf("Cleared %d lockouts\n", r.Cleared)
+}
+
+func (s *simplePrinter) AuthSessionList(r v3.AuthSessionListResponse) {
+	for _, ss := range r.Sessions {
+		fmt.Printf("session %d, user %s, address %s, issued at %s, expires at %s\n",
+			ss.ID, ss.User, ss.Address,
+			time.Unix(ss.IssuedAt, 0).Format(time.RFC3339), time.Unix(ss.ExpiresAt, 0).Format(time.RFC3339))
+	}
+}
+
+func (s *simplePrinter) AuthSessionRevoke(r v3.AuthSessionRevokeResponse) {
+	fmt.Printf("Revoked %d sessions\n", r.Revoked)
+}
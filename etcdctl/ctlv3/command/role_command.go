@@ -41,6 +41,8 @@ func NewRoleCommand() *cobra.Command {
 	ac.AddCommand(newRoleListCommand())
 	ac.AddCommand(newRoleGrantPermissionCommand())
 	ac.AddCommand(newRoleRevokePermissionCommand())
+	ac.AddCommand(newRoleGrantAdminCommand())
+	ac.AddCommand(newRoleRevokeAdminCommand())
 
 	return ac
 }
@@ -103,6 +105,22 @@ func newRoleRevokePermissionCommand() *cobra.Command {
 	return cmd
 }
 
+func newRoleGrantAdminCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "grant-admin <role name> <admin permission>...",
+		Short: "Grants cluster administration permissions (member, alarm, defragment, snapshot, auth, maintenance) to a role",
+		Run:   roleGrantAdminCommandFunc,
+	}
+}
+
+func newRoleRevokeAdminCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke-admin <role name> <admin permission>...",
+		Short: "Revokes cluster administration permissions from a role",
+		Run:   roleRevokeAdminCommandFunc,
+	}
+}
+
 // roleAddCommandFunc executes the "role add" command.
 func roleAddCommandFunc(cmd *cobra.Command, args []string) {
 	if len(args) != 1 {
@@ -194,6 +212,45 @@ func roleRevokePermissionCommandFunc(cmd *cobra.Command, args []string) {
 	display.RoleRevokePermission(args[0], args[1], rangeEnd, *resp)
 }
 
+// roleGrantAdminCommandFunc executes the "role grant-admin" command.
+func roleGrantAdminCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) < 2 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("role grant-admin command requires role name and admin permissions as its argument"))
+	}
+
+	resp, err := mustClientFromCmd(cmd).Auth.RoleGrantAdminPermission(context.TODO(), args[0], adminPerms(args[1:])...)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+
+	display.RoleGrantPermission(args[0], *resp)
+}
+
+// roleRevokeAdminCommandFunc executes the "role revoke-admin" command.
+func roleRevokeAdminCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) < 2 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("role revoke-admin command requires role name and admin permissions as its argument"))
+	}
+
+	resp, err := mustClientFromCmd(cmd).Auth.RoleRevokeAdminPermission(context.TODO(), args[0], adminPerms(args[1:])...)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	display.RoleRevokeAdminPermission(args[0], args[1:], *resp)
+}
+
+func adminPerms(args []string) []clientv3.AdminPermissionType {
+	perms := make([]clientv3.AdminPermissionType, len(args))
+	for i, arg := range args {
+		perm, err := clientv3.StrToAdminPermissionType(arg)
+		if err != nil {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+		}
+		perms[i] = perm
+	}
+	return perms
+}
+
 func permRange(args []string) (string, string) {
 	key := args[0]
 	var rangeEnd string
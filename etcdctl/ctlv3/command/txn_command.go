@@ -194,6 +194,24 @@ func ParseCompare(line string) (*clientv3.Cmp, error) {
 		}
 	case "val", "value":
 		cmp = clientv3.Compare(clientv3.Value(key), op, val)
+	case "vint", "value_int":
+		if v, err = strconv.ParseInt(val, 10, 64); err == nil {
+			cmp = clientv3.Compare(clientv3.ValueInt(key), op, v)
+		}
+	case "vprefix", "value_prefix":
+		cmp = clientv3.Compare(clientv3.ValuePrefix(key), op, val)
+	case "exists":
+		switch val {
+		case "true":
+			cmp = clientv3.KeyExists(key)
+		case "false":
+			cmp = clientv3.KeyMissing(key)
+		default:
+			err = fmt.Errorf("bad exists value %q", val)
+		}
+		if op != "=" {
+			err = fmt.Errorf("bad exists operator %q", op)
+		}
 	case "lease":
 		cmp = clientv3.Compare(clientv3.Cmp{Target: pb.Compare_LEASE}, op, val)
 	default:
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bgentry/speakeasy"
 	"github.com/spf13/cobra"
@@ -27,6 +28,7 @@ import (
 
 var (
 	userShowDetail bool
+	grantRoleTTL   int64
 )
 
 // NewUserCommand returns the cobra command for "user".
@@ -51,6 +53,7 @@ var (
 	passwordInteractive bool
 	passwordFromFlag    string
 	noPassword          bool
+	homePrefix          string
 )
 
 func newUserAddCommand() *cobra.Command {
@@ -63,6 +66,7 @@ func newUserAddCommand() *cobra.Command {
 	cmd.Flags().BoolVar(&passwordInteractive, "interactive", true, "Read password from stdin instead of interactive terminal")
 	cmd.Flags().StringVar(&passwordFromFlag, "new-user-password", "", "Supply password from the command line flag")
 	cmd.Flags().BoolVar(&noPassword, "no-password", false, "Create a user without password (CN based auth only)")
+	cmd.Flags().StringVar(&homePrefix, "home-prefix", "", "Scope relative keys (not starting with '/') of the user under the given absolute prefix")
 
 	return &cmd
 }
@@ -108,11 +112,15 @@ func newUserChangePasswordCommand() *cobra.Command {
 }
 
 func newUserGrantRoleCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "grant-role <user name> <role name>",
+	cmd := cobra.Command{
+		Use:   "grant-role <user name> <role name> [options]",
 		Short: "Grants a role to a user",
 		Run:   userGrantRoleCommandFunc,
 	}
+
+	cmd.Flags().Int64Var(&grantRoleTTL, "ttl", 0, "Revoke the role from the user after the given number of seconds")
+
+	return &cmd
 }
 
 func newUserRevokeRoleCommand() *cobra.Command {
@@ -134,6 +142,7 @@ func userAddCommandFunc(cmd *cobra.Command, args []string) {
 
 	options := &clientv3.UserAddOptions{
 		NoPassword: false,
+		HomePrefix: homePrefix,
 	}
 
 	if !noPassword {
@@ -253,7 +262,15 @@ func userGrantRoleCommandFunc(cmd *cobra.Command, args []string) {
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("user grant command requires user name and role name as its argument"))
 	}
 
-	resp, err := mustClientFromCmd(cmd).Auth.UserGrantRole(context.TODO(), args[0], args[1])
+	if grantRoleTTL < 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("--ttl must be positive"))
+	}
+
+	var expiresAt time.Time
+	if grantRoleTTL > 0 {
+		expiresAt = time.Now().Add(time.Duration(grantRoleTTL) * time.Second)
+	}
+	resp, err := mustClientFromCmd(cmd).Auth.UserGrantRoleUntil(context.TODO(), args[0], args[1], expiresAt)
 	if err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitError, err)
 	}
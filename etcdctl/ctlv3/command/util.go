@@ -81,7 +81,11 @@ func commandCtx(cmd *cobra.Command) (context.Context, context.CancelFunc) {
 	if err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitError, err)
 	}
-	return context.WithTimeout(context.Background(), timeOut)
+	ctx := context.Background()
+	if debugTrace, _ := cmd.Flags().GetBool("debug-trace"); debugTrace {
+		ctx = clientv3.WithDebugTrace(ctx)
+	}
+	return context.WithTimeout(ctx, timeOut)
 }
 
 func isCommandTimeoutFlagSet(cmd *cobra.Command) bool {
@@ -22,6 +22,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/pkg/v3/cobrautl"
@@ -42,6 +43,21 @@ var (
 	watchInteractive bool
 	watchPrevKey     bool
 	progressNotify   bool
+
+	progressNotifyInterval  time.Duration
+	progressNotifyHeartbeat bool
+
+	watchValueJSONField string
+	watchValuePrefix    string
+	watchValueMinSize   int64
+	watchValueMaxSize   int64
+
+	watchBatchMaxLatency time.Duration
+	watchBatchMaxEvents  int
+
+	watchKeysOnly     bool
+	watchMetadataOnly bool
+	watchJSONFields   []string
 )
 
 // NewWatchCommand returns the cobra command for "watch".
@@ -57,6 +73,17 @@ func NewWatchCommand() *cobra.Command {
 	cmd.Flags().Int64Var(&watchRev, "rev", 0, "Revision to start watching")
 	cmd.Flags().BoolVar(&watchPrevKey, "prev-kv", false, "get the previous key-value pair before the event happens")
 	cmd.Flags().BoolVar(&progressNotify, "progress-notify", false, "get periodic watch progress notification from server")
+	cmd.Flags().DurationVar(&progressNotifyInterval, "progress-notify-interval", 0, "get the progress notifications at this interval instead of the one of the server")
+	cmd.Flags().BoolVar(&progressNotifyHeartbeat, "progress-notify-heartbeat", false, "get the progress notifications at every interval, even after events")
+	cmd.Flags().StringVar(&watchValueJSONField, "value-json-field", "", "only get the put events whose JSON values have the field equal, as 'path=json value' (e.g. 'status.phase=\"Running\"')")
+	cmd.Flags().StringVar(&watchValuePrefix, "value-prefix", "", "only get the put events whose values start with the prefix")
+	cmd.Flags().Int64Var(&watchValueMinSize, "value-min-size", 0, "only get the put events whose values are at least this many bytes")
+	cmd.Flags().Int64Var(&watchValueMaxSize, "value-max-size", 0, "only get the put events whose values are at most this many bytes (0 is unlimited)")
+	cmd.Flags().DurationVar(&watchBatchMaxLatency, "batch-max-latency", 0, "batch the events, getting them at most this long after the first event of a batch (0 is no batching)")
+	cmd.Flags().IntVar(&watchBatchMaxEvents, "batch-max-events", 0, "get a batch of events once it has this many events (0 is unlimited)")
+	cmd.Flags().BoolVar(&watchKeysOnly, "keys-only", false, "get only the keys of the events")
+	cmd.Flags().BoolVar(&watchMetadataOnly, "metadata-only", false, "get the events without their values")
+	cmd.Flags().StringSliceVar(&watchJSONFields, "json-fields", nil, "get only the fields at the paths of the JSON values of the events (e.g. 'status.phase,metadata.name')")
 
 	return cmd
 }
@@ -160,6 +187,39 @@ func getWatchChan(c *clientv3.Client, args []string) (clientv3.WatchChan, error)
 	if progressNotify {
 		opts = append(opts, clientv3.WithProgressNotify())
 	}
+	if progressNotifyInterval != 0 {
+		opts = append(opts, clientv3.WithProgressNotifyInterval(progressNotifyInterval))
+	}
+	if progressNotifyHeartbeat {
+		opts = append(opts, clientv3.WithProgressNotifyHeartbeat())
+	}
+	if watchValueJSONField != "" {
+		i := strings.Index(watchValueJSONField, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid --value-json-field %q (expected 'path=json value')", watchValueJSONField)
+		}
+		opts = append(opts, clientv3.WithValueJSONFieldEqual(watchValueJSONField[:i], watchValueJSONField[i+1:]))
+	}
+	if watchValuePrefix != "" {
+		opts = append(opts, clientv3.WithValuePrefix(watchValuePrefix))
+	}
+	if watchValueMinSize != 0 || watchValueMaxSize != 0 {
+		opts = append(opts, clientv3.WithValueSize(watchValueMinSize, watchValueMaxSize))
+	}
+	if watchBatchMaxLatency != 0 || watchBatchMaxEvents != 0 {
+		opts = append(opts, clientv3.WithBatch(watchBatchMaxLatency, watchBatchMaxEvents))
+	}
+	switch {
+	case watchKeysOnly && (watchMetadataOnly || len(watchJSONFields) != 0),
+		watchMetadataOnly && len(watchJSONFields) != 0:
+		return nil, fmt.Errorf("`--keys-only`, `--metadata-only` and `--json-fields` are mutually exclusive")
+	case watchKeysOnly:
+		opts = append(opts, clientv3.WithEventKeysOnly())
+	case watchMetadataOnly:
+		opts = append(opts, clientv3.WithEventMetadataOnly())
+	case len(watchJSONFields) != 0:
+		opts = append(opts, clientv3.WithEventJSONFields(watchJSONFields...))
+	}
 	return c.Watch(clientv3.WithRequireLeader(context.Background()), key, opts...), nil
 }
 
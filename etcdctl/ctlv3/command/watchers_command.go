@@ -0,0 +1,132 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+)
+
+var (
+	watchersStreamID int64
+	watchersLimit    int64
+)
+
+// NewWatchersCommand returns the cobra command for "watchers".
+func NewWatchersCommand() *cobra.Command {
+	wc := &cobra.Command{
+		Use:   "watchers <subcommand>",
+		Short: "Watcher inspection related commands",
+	}
+
+	wc.AddCommand(newWatchersListCommand())
+	wc.AddCommand(newWatchersCancelCommand())
+
+	return wc
+}
+
+func newWatchersListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Lists the watchers of the etcd members with given endpoints",
+		Long: `Lists the watchers of the etcd members with given endpoints, with the id of
+their watch stream, the address and user of its client, their key ranges,
+start revision and revision lag, the slowest first.`,
+		Run: watchersListCommandFunc,
+	}
+	cmd.Flags().Int64Var(&watchersStreamID, "stream-id", 0, "list only the watchers of the watch stream with the id, 0 for all")
+	cmd.Flags().Int64Var(&watchersLimit, "limit", 0, "maximum number of watchers to list per member, 0 for all")
+	cmd.PersistentFlags().BoolVar(&epClusterEndpoints, "cluster", false, "use all endpoints from the cluster member list")
+	return cmd
+}
+
+// watchersListCommandFunc executes the "watchers list" command.
+func watchersListCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("watchers list takes no arguments"))
+	}
+
+	failures := 0
+	c := mustClientFromCmd(cmd)
+	for _, ep := range endpointsFromCluster(cmd) {
+		ctx, cancel := commandCtx(cmd)
+		resp, err := c.Watchers(ctx, ep, watchersStreamID, watchersLimit)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to list the watchers of etcd member[%s] (%v)\n", ep, err)
+			failures++
+			continue
+		}
+		fmt.Printf("etcd member[%s] watchers at revision %d:\n", ep, resp.Header.Revision)
+		for _, w := range resp.Watchers {
+			fmt.Printf("stream: %d, watch: %d, remote: %s, user: %q, start revision: %d, revision lag: %d, ranges:",
+				w.StreamId, w.WatchId, w.Remote, w.User, w.StartRevision, w.RevisionLag)
+			for _, r := range w.Ranges {
+				if len(r.RangeEnd) == 0 {
+					fmt.Printf(" %q", r.Key)
+				} else {
+					fmt.Printf(" [%q, %q)", r.Key, r.RangeEnd)
+				}
+			}
+			fmt.Println()
+		}
+	}
+
+	if failures != 0 {
+		os.Exit(cobrautl.ExitError)
+	}
+}
+
+func newWatchersCancelCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel <stream-id> <watch-id>",
+		Short: "Cancels a watcher of the etcd member with the given endpoint",
+		Long: `Cancels a watcher of a watch stream of the etcd member with the given
+endpoint. Its client receives a response canceling it with
+"etcdserver: watcher canceled by an administrator".`,
+		Run: watchersCancelCommandFunc,
+	}
+}
+
+// watchersCancelCommandFunc executes the "watchers cancel" command.
+func watchersCancelCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("watchers cancel command needs 2 arguments"))
+	}
+	streamID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("bad stream id %q (%v)", args[0], err))
+	}
+	watchID, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("bad watch id %q (%v)", args[1], err))
+	}
+	eps := endpointsFromCluster(cmd)
+	if len(eps) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("watchers cancel needs the endpoint of the member of the watcher only"))
+	}
+
+	ctx, cancel := commandCtx(cmd)
+	_, err = mustClientFromCmd(cmd).CancelWatcher(ctx, eps[0], streamID, watchID)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	fmt.Printf("Canceled watcher %d of stream %d\n", watchID, streamID)
+}
@@ -50,6 +50,7 @@ var (
 func init() {
 	rootCmd.PersistentFlags().StringSliceVar(&globalFlags.Endpoints, "endpoints", []string{"127.0.0.1:2379"}, "gRPC endpoints")
 	rootCmd.PersistentFlags().BoolVar(&globalFlags.Debug, "debug", false, "enable client-side debug logging")
+	rootCmd.PersistentFlags().BoolVar(&globalFlags.DebugTrace, "debug-trace", false, "ask the server for the timing breakdown of the requests, returned in the response header (requires admin permission)")
 
 	rootCmd.PersistentFlags().StringVarP(&globalFlags.OutputFormat, "write-out", "w", "simple", "set the output format (fields, json, protobuf, simple, table)")
 	rootCmd.PersistentFlags().BoolVar(&globalFlags.IsHex, "hex", false, "print byte strings as hex encoded strings")
@@ -78,6 +79,8 @@ func init() {
 		command.NewGetCommand(),
 		command.NewPutCommand(),
 		command.NewDelCommand(),
+		command.NewMoveCommand(),
+		command.NewIncrCommand(),
 		command.NewTxnCommand(),
 		command.NewCompactionCommand(),
 		command.NewAlarmCommand(),
@@ -98,6 +101,12 @@ func init() {
 		command.NewCheckCommand(),
 		command.NewCompletionCommand(),
 		command.NewDowngradeCommand(),
+		command.NewLogLevelCommand(),
+		command.NewHotKeysCommand(),
+		command.NewClusterHistoryCommand(),
+		command.NewPrefixQuotaCommand(),
+		command.NewPrefixStatsCommand(),
+		command.NewWatchersCommand(),
 	)
 }
 
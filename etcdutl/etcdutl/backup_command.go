@@ -24,6 +24,7 @@ import (
 	"go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/client/pkg/v3/fileutil"
 	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
 	"go.etcd.io/etcd/pkg/v3/idutil"
 	"go.etcd.io/etcd/pkg/v3/pbutil"
 	"go.etcd.io/etcd/raft/v3/raftpb"
@@ -32,6 +33,7 @@ import (
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v2store"
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	"go.etcd.io/etcd/server/v3/storage/datadir"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
 	"go.etcd.io/etcd/server/v3/storage/schema"
 	"go.etcd.io/etcd/server/v3/storage/wal"
 	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
@@ -47,6 +49,8 @@ var (
 	backupDir    string
 	walDir       string
 	backupWalDir string
+
+	encryptionKeyFile string
 )
 
 func NewBackupCommand() *cobra.Command {
@@ -61,6 +65,7 @@ func NewBackupCommand() *cobra.Command {
 	cmd.Flags().StringVar(&walDir, "wal-dir", "", "Path to the etcd wal dir")
 	cmd.Flags().StringVar(&backupDir, "backup-dir", "", "Path to the backup dir")
 	cmd.Flags().StringVar(&backupWalDir, "backup-wal-dir", "", "Path to the backup wal dir")
+	cmd.Flags().StringVar(&encryptionKeyFile, "encryption-key-file", "", "Path to the file of the keys encrypting the WAL entries and the snapshots, to read and write them encrypted")
 	cmd.Flags().BoolVar(&withV3, "with-v3", true, "Backup v3 backend data. Note -with-v3=false is not supported since etcd v3.6. Please use v3.5.x client as the last supporting this deprecated functionality.")
 	cmd.MarkFlagRequired("data-dir")
 	cmd.MarkFlagRequired("backup-dir")
@@ -72,7 +77,11 @@ func NewBackupCommand() *cobra.Command {
 }
 
 func doBackup(cmd *cobra.Command, args []string) {
-	HandleBackup(withV3, dataDir, backupDir, walDir, backupWalDir)
+	kp, err := newKeyProvider(encryptionKeyFile)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+	}
+	HandleEncryptedBackup(withV3, dataDir, backupDir, walDir, backupWalDir, kp)
 }
 
 type desiredCluster struct {
@@ -105,6 +114,13 @@ func newDesiredCluster() desiredCluster {
 
 // HandleBackup handles a request that intends to do a backup.
 func HandleBackup(withV3 bool, srcDir string, destDir string, srcWAL string, destWAL string) error {
+	return HandleEncryptedBackup(withV3, srcDir, destDir, srcWAL, destWAL, nil)
+}
+
+// HandleEncryptedBackup handles a request that intends to do a backup of
+// the WAL entries and the snapshots encrypted with the keys wrapped by kp,
+// encrypting them with kp as well.
+func HandleEncryptedBackup(withV3 bool, srcDir string, destDir string, srcWAL string, destWAL string, kp encryption.KeyProvider) error {
 	lg := GetLogger()
 
 	if !withV3 {
@@ -131,8 +147,8 @@ func HandleBackup(withV3 bool, srcDir string, destDir string, srcWAL string, des
 	srcDbPath := datadir.ToBackendFileName(srcDir)
 	desired := newDesiredCluster()
 
-	walsnap := saveSnap(lg, destSnap, srcSnap, &desired)
-	metadata, state, ents := translateWAL(lg, srcWAL, walsnap)
+	walsnap := saveSnap(lg, destSnap, srcSnap, &desired, kp)
+	metadata, state, ents := translateWAL(lg, srcWAL, walsnap, kp)
 	saveDB(lg, destDbPath, srcDbPath, state.Commit, state.Term, &desired)
 
 	neww, err := wal.Create(lg, destWAL, pbutil.MustMarshal(&metadata))
@@ -140,6 +156,7 @@ func HandleBackup(withV3 bool, srcDir string, destDir string, srcWAL string, des
 		lg.Fatal("wal.Create failed", zap.Error(err))
 	}
 	defer neww.Close()
+	neww.SetKeyProvider(kp)
 	if err := neww.Save(state, ents); err != nil {
 		lg.Fatal("wal.Save failed ", zap.Error(err))
 	}
@@ -156,8 +173,9 @@ func HandleBackup(withV3 bool, srcDir string, destDir string, srcWAL string, des
 	return nil
 }
 
-func saveSnap(lg *zap.Logger, destSnap, srcSnap string, desired *desiredCluster) (walsnap walpb.Snapshot) {
+func saveSnap(lg *zap.Logger, destSnap, srcSnap string, desired *desiredCluster, kp encryption.KeyProvider) (walsnap walpb.Snapshot) {
 	ss := snap.New(lg, srcSnap)
+	ss.SetKeyProvider(kp)
 	snapshot, err := ss.Load()
 	if err != nil && err != snap.ErrNoSnapshot {
 		lg.Fatal("saveSnap(Snapshoter.Load) failed", zap.Error(err))
@@ -165,6 +183,7 @@ func saveSnap(lg *zap.Logger, destSnap, srcSnap string, desired *desiredCluster)
 	if snapshot != nil {
 		walsnap.Index, walsnap.Term, walsnap.ConfState = snapshot.Metadata.Index, snapshot.Metadata.Term, &desired.confState
 		newss := snap.New(lg, destSnap)
+		newss.SetKeyProvider(kp)
 		snapshot.Metadata.ConfState = desired.confState
 		snapshot.Data = mustTranslateV2store(lg, snapshot.Data, desired)
 		if err = newss.SaveSnap(*snapshot); err != nil {
@@ -194,12 +213,13 @@ func mustTranslateV2store(lg *zap.Logger, storeData []byte, desired *desiredClus
 	return outputData
 }
 
-func translateWAL(lg *zap.Logger, srcWAL string, walsnap walpb.Snapshot) (etcdserverpb.Metadata, raftpb.HardState, []raftpb.Entry) {
+func translateWAL(lg *zap.Logger, srcWAL string, walsnap walpb.Snapshot, kp encryption.KeyProvider) (etcdserverpb.Metadata, raftpb.HardState, []raftpb.Entry) {
 	w, err := wal.OpenForRead(lg, srcWAL, walsnap)
 	if err != nil {
 		lg.Fatal("wal.OpenForRead failed", zap.Error(err))
 	}
 	defer w.Close()
+	w.SetKeyProvider(kp)
 	wmetadata, state, ents, err := w.ReadAll()
 	switch err {
 	case nil:
@@ -221,8 +241,8 @@ func translateWAL(lg *zap.Logger, srcWAL string, walsnap walpb.Snapshot) (etcdse
 		// Also moving entries and computing offsets would get complicated if
 		// TERM changes (so there are superflous entries from previous term).
 
-		if ents[i].Type == raftpb.EntryConfChange {
-			lg.Info("ignoring EntryConfChange raft entry")
+		if ents[i].Type == raftpb.EntryConfChange || ents[i].Type == raftpb.EntryConfChangeV2 {
+			lg.Info("ignoring EntryConfChange raft entry", zap.Stringer("type", ents[i].Type))
 			raftEntryToNoOp(&ents[i])
 			continue
 		}
@@ -17,6 +17,7 @@ package etcdutl
 import (
 	"go.etcd.io/etcd/client/pkg/v3/logutil"
 	"go.etcd.io/etcd/pkg/v3/cobrautl"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -31,3 +32,12 @@ func GetLogger() *zap.Logger {
 	}
 	return lg
 }
+
+// newKeyProvider returns the provider of the keys of the file at path,
+// encrypting the WAL entries and the snapshots, nil if path is empty.
+func newKeyProvider(path string) (encryption.KeyProvider, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return encryption.NewFileKeyProvider(path)
+}
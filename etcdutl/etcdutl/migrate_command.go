@@ -55,6 +55,8 @@ type migrateOptions struct {
 	dataDir       string
 	targetVersion string
 	force         bool
+
+	encryptionKeyFile string
 }
 
 func newMigrateOptions() *migrateOptions {
@@ -70,6 +72,7 @@ func (o *migrateOptions) AddFlags(cmd *cobra.Command) {
 	cmd.MarkFlagRequired("target-version")
 
 	cmd.Flags().BoolVar(&o.force, "force", o.force, "Ignore migration failure and forcefully override storage version. Not recommended.")
+	cmd.Flags().StringVar(&o.encryptionKeyFile, "encryption-key-file", o.encryptionKeyFile, "Path to the file of the keys encrypting the WAL entries, to read them if encrypted")
 }
 
 func (o *migrateOptions) Config() (*migrateConfig, error) {
@@ -90,6 +93,11 @@ func (o *migrateOptions) Config() (*migrateConfig, error) {
 		return nil, fmt.Errorf(`target version %q not supported. Minimal "3.5"`, storageVersionToString(c.targetVersion))
 	}
 
+	kp, err := newKeyProvider(o.encryptionKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to read the encryption keys: %v`, err)
+	}
+
 	dbPath := datadir.ToBackendFileName(o.dataDir)
 	c.be = backend.NewDefaultBackend(GetLogger(), dbPath)
 
@@ -99,6 +107,7 @@ func (o *migrateOptions) Config() (*migrateConfig, error) {
 		return nil, fmt.Errorf(`failed to open wal: %v`, err)
 	}
 	defer w.Close()
+	w.SetKeyProvider(kp)
 	c.walVersion, err = wal.ReadWALVersion(w)
 	if err != nil {
 		return nil, fmt.Errorf(`failed to read wal: %v`, err)
@@ -0,0 +1,398 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdutl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/snap"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/datadir"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+	"go.etcd.io/etcd/server/v3/storage/wal"
+	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
+)
+
+// NewWALCommand returns the cobra command for "wal".
+func NewWALCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wal <subcommand>",
+		Short: "Inspects and repairs the WAL of an etcd member",
+	}
+	cmd.AddCommand(newWALRepairCommand())
+	cmd.AddCommand(newWALDumpCommand())
+	return cmd
+}
+
+func newWALRepairCommand() *cobra.Command {
+	o := &walRepairOptions{context: 3}
+	cmd := &cobra.Command{
+		Use:   "repair --data-dir {data dir} [--truncate-index {index}]",
+		Short: "Locates the first corrupt WAL record and truncates the WAL at a chosen entry",
+		Long: `Scans the WAL of a stopped member up to its first corrupt record, and prints the entries before it.
+With --truncate-index, truncates the WAL after that entry, once checked against the snapshot, the backend
+and the indexes reported by the other members (see "etcdctl endpoint status"). The segments changed are
+kept with the .broken suffix.
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := o.Config()
+			if err != nil {
+				cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+			}
+			if err = WALRepair(cfg, cmd.InOrStdin(), cmd.OutOrStdout()); err != nil {
+				cobrautl.ExitWithError(cobrautl.ExitError, err)
+			}
+		},
+	}
+	o.AddFlags(cmd)
+	return cmd
+}
+
+type walRepairOptions struct {
+	dataDir       string
+	walDir        string
+	truncateIndex uint64
+	context       int
+	memberIndexes []string
+	yes           bool
+	force         bool
+
+	encryptionKeyFile string
+}
+
+func (o *walRepairOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.dataDir, "data-dir", o.dataDir, "Path to the etcd data dir")
+	cmd.MarkFlagRequired("data-dir")
+	cmd.MarkFlagDirname("data-dir")
+	cmd.Flags().StringVar(&o.walDir, "wal-dir", o.walDir, "Path to the etcd wal dir (use --data-dir if none given)")
+	cmd.MarkFlagDirname("wal-dir")
+
+	cmd.Flags().Uint64Var(&o.truncateIndex, "truncate-index", o.truncateIndex, "Truncate the WAL after the entry of this index. Only inspects the WAL if 0.")
+	cmd.Flags().IntVar(&o.context, "context", o.context, "Number of entries printed around the corruption and the truncation index")
+	cmd.Flags().StringSliceVar(&o.memberIndexes, "member-index", o.memberIndexes, "Raft index reported by another member, as name=index (repeatable)")
+	cmd.Flags().BoolVar(&o.yes, "yes", o.yes, "Truncate without asking for confirmation")
+	cmd.Flags().BoolVar(&o.force, "force", o.force, "Truncate even if the checks fail. Not recommended.")
+	cmd.Flags().StringVar(&o.encryptionKeyFile, "encryption-key-file", o.encryptionKeyFile, "Path to the file of the keys encrypting the WAL entries and the snapshots, to read them if encrypted")
+}
+
+func (o *walRepairOptions) Config() (*WALRepairConfig, error) {
+	c := &WALRepairConfig{
+		Logger:        GetLogger(),
+		DataDir:       o.dataDir,
+		WALDir:        o.walDir,
+		TruncateIndex: o.truncateIndex,
+		Context:       o.context,
+		MemberIndexes: map[string]uint64{},
+		Yes:           o.yes,
+		Force:         o.force,
+	}
+	if c.WALDir == "" {
+		c.WALDir = datadir.ToWalDir(o.dataDir)
+	}
+	if c.Context < 0 {
+		return nil, fmt.Errorf("--context[%d] should not be negative", c.Context)
+	}
+	for _, mi := range o.memberIndexes {
+		kv := strings.SplitN(mi, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid --member-index %q, expected name=index", mi)
+		}
+		idx, err := strconv.ParseUint(kv[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --member-index %q: %v", mi, err)
+		}
+		c.MemberIndexes[kv[0]] = idx
+	}
+	var err error
+	if c.KeyProvider, err = newKeyProvider(o.encryptionKeyFile); err != nil {
+		return nil, fmt.Errorf("failed to read the encryption keys: %v", err)
+	}
+	return c, nil
+}
+
+// WALRepairConfig configures WALRepair.
+type WALRepairConfig struct {
+	Logger  *zap.Logger
+	DataDir string
+	WALDir  string
+	// TruncateIndex is the index of the entry after which the WAL is
+	// truncated, the WAL being only inspected if 0.
+	TruncateIndex uint64
+	// Context is the number of entries printed around the corruption and
+	// the truncation index.
+	Context int
+	// MemberIndexes are the raft indexes reported by the other members, by
+	// member name.
+	MemberIndexes map[string]uint64
+	// Yes truncates without asking for a confirmation on in.
+	Yes bool
+	// Force truncates even if the checks fail.
+	Force bool
+
+	KeyProvider encryption.KeyProvider
+}
+
+// walScan is what WALRepair learns from scanning the WAL.
+type walScan struct {
+	corruption *wal.Corruption
+	snapshots  []walpb.Snapshot
+	last       *raftpb.Entry // last entry read
+	commit     uint64        // commit index of the last hard state read
+	tail       []raftpb.Entry
+
+	// the last record of the entry of the truncation index, the entries
+	// around it, and the snapshots recorded before it
+	trunc       *wal.Record
+	truncEnts   map[uint64]raftpb.Entry
+	truncSnaps  []walpb.Snapshot
+	overwritten bool
+}
+
+// WALRepair scans the WAL up to its first corrupt record, and prints the
+// entries before it to out. If c.TruncateIndex is set, it checks that the
+// truncation after that entry keeps the member consistent with its
+// snapshot, its backend and the other members, asks for a confirmation on
+// in, and truncates the WAL.
+func WALRepair(c *WALRepairConfig, in io.Reader, out io.Writer) error {
+	s, err := scanWAL(c)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "WAL: %s\n", c.WALDir)
+	if s.corruption != nil {
+		fmt.Fprintf(out, "First corrupt record: segment %s, offset %d: %v\n", s.corruption.Segment, s.corruption.Offset, s.corruption.Err)
+	} else {
+		fmt.Fprintln(out, "No corrupt record found.")
+	}
+	if s.last == nil {
+		fmt.Fprintln(out, "No entry read.")
+	} else {
+		fmt.Fprintf(out, "Last entry read: index %d, term %d\n", s.last.Index, s.last.Term)
+	}
+	fmt.Fprintf(out, "Last commit index read: %d\n", s.commit)
+	if len(s.tail) > 0 {
+		fmt.Fprintln(out, "Last entries read:")
+		printEntries(out, s.tail)
+	}
+
+	if c.TruncateIndex == 0 {
+		if s.corruption != nil && s.last != nil {
+			fmt.Fprintf(out, "Run with --truncate-index %d to truncate the WAL after the last entry read.\n", s.last.Index)
+		}
+		return nil
+	}
+
+	n := c.TruncateIndex
+	if s.trunc == nil {
+		return fmt.Errorf("no entry of index %d read before the corruption", n)
+	}
+	if s.overwritten {
+		return fmt.Errorf("the entry of index %d was overwritten by a later entry of a lower index", n)
+	}
+	var around []raftpb.Entry
+	from := uint64(1)
+	if n > uint64(c.Context) {
+		from = n - uint64(c.Context)
+	}
+	for i := from; i <= n+uint64(c.Context); i++ {
+		if e, ok := s.truncEnts[i]; ok {
+			around = append(around, e)
+		}
+	}
+	fmt.Fprintf(out, "Entries around index %d:\n", n)
+	printEntries(out, around)
+
+	problems, warnings := checkWALTruncation(c, s)
+	for _, w := range warnings {
+		fmt.Fprintf(out, "Warning: %s\n", w)
+	}
+	for _, p := range problems {
+		fmt.Fprintf(out, "Check failed: %s\n", p)
+	}
+	if len(problems) > 0 && !c.Force {
+		return fmt.Errorf("the truncation after index %d failed %d check(s); use --force to truncate anyway", n, len(problems))
+	}
+
+	names, err := wal.SegmentNames(c.Logger, c.WALDir)
+	if err != nil {
+		return err
+	}
+	removed := 0
+	for _, name := range names {
+		// the names sort in sequence order
+		if name > s.trunc.Segment {
+			removed++
+		}
+	}
+	fmt.Fprintf(out, "Truncating segment %s at offset %d, and removing %d segment(s) after it.\n", s.trunc.Segment, s.trunc.End, removed)
+	if !c.Yes {
+		fmt.Fprint(out, "Proceed? [y/N] ")
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			return fmt.Errorf("truncation canceled")
+		}
+	}
+	if err = wal.Truncate(c.Logger, c.WALDir, s.trunc.Segment, s.trunc.End); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Truncated the WAL after index %d.\n", n)
+	return nil
+}
+
+func scanWAL(c *WALRepairConfig) (*walScan, error) {
+	s := &walScan{truncEnts: map[uint64]raftpb.Entry{}}
+	n, ctx := c.TruncateIndex, uint64(c.Context)
+	var err error
+	s.corruption, err = wal.Scan(c.Logger, c.WALDir, c.KeyProvider, func(r wal.Record) error {
+		switch {
+		case r.Snapshot != nil:
+			s.snapshots = append(s.snapshots, *r.Snapshot)
+		case r.State != nil:
+			s.commit = r.State.Commit
+		case r.Entry != nil:
+			e := *r.Entry
+			s.last = &e
+			s.tail = append(s.tail, e)
+			if len(s.tail) > c.Context {
+				s.tail = s.tail[1:]
+			}
+			if n == 0 {
+				break
+			}
+			// an entry overwrites the ones of higher indexes
+			for i := range s.truncEnts {
+				if i >= e.Index {
+					delete(s.truncEnts, i)
+				}
+			}
+			if e.Index+ctx >= n && e.Index <= n+ctx {
+				s.truncEnts[e.Index] = e
+			}
+			switch {
+			case e.Index == n:
+				rec := r
+				s.trunc, s.overwritten = &rec, false
+				s.truncSnaps = append([]walpb.Snapshot(nil), s.snapshots...)
+			case e.Index < n && s.trunc != nil:
+				s.overwritten = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// checkWALTruncation returns the reasons why the WAL should not be
+// truncated after c.TruncateIndex, and the warnings about it.
+func checkWALTruncation(c *WALRepairConfig, s *walScan) (problems, warnings []string) {
+	n := c.TruncateIndex
+
+	// the member starts from the newest snapshot recorded in the WAL kept
+	ss := snap.New(c.Logger, datadir.ToSnapDir(c.DataDir))
+	ss.SetKeyProvider(c.KeyProvider)
+	newest, err := ss.LoadNewestAvailable(s.snapshots)
+	switch {
+	case err == nil && newest.Metadata.Index > n:
+		problems = append(problems, fmt.Sprintf("the snapshot at index %d is beyond the truncation index", newest.Metadata.Index))
+	case err == nil:
+		if _, kerr := ss.LoadNewestAvailable(s.truncSnaps); kerr != nil {
+			problems = append(problems, fmt.Sprintf("no snapshot is recorded in the WAL kept: %v", kerr))
+		}
+	case err != snap.ErrNoSnapshot:
+		problems = append(problems, fmt.Sprintf("failed to load the snapshot: %v", err))
+	}
+
+	if dbPath := datadir.ToBackendFileName(c.DataDir); fileutil.Exist(dbPath) {
+		be := backend.NewDefaultBackend(c.Logger, dbPath)
+		ci, _ := schema.ReadConsistentIndex(be.ReadTx())
+		be.Close()
+		if ci > n {
+			problems = append(problems, fmt.Sprintf("the backend applied the entries up to index %d, beyond the truncation index", ci))
+		}
+	}
+
+	// the committed entries dropped must be held by the other members
+	committed := s.commit
+	if s.last != nil && s.last.Index < committed {
+		committed = s.last.Index
+	}
+	if committed > n {
+		var held bool
+		for _, idx := range c.MemberIndexes {
+			held = held || idx >= committed
+		}
+		switch {
+		case len(c.MemberIndexes) == 0:
+			warnings = append(warnings, fmt.Sprintf("the entries %d to %d were committed; check that the other members hold them with --member-index", n+1, committed))
+		case !held:
+			problems = append(problems, fmt.Sprintf("no member reports the committed entries up to index %d", committed))
+		}
+	}
+	return problems, warnings
+}
+
+func printEntries(out io.Writer, ents []raftpb.Entry) {
+	for _, e := range ents {
+		fmt.Fprintf(out, "  index %d, term %d, %s: %s\n", e.Index, e.Term, e.Type, entrySummary(e))
+	}
+}
+
+func entrySummary(e raftpb.Entry) string {
+	if len(e.Data) == 0 {
+		return "empty"
+	}
+	var s string
+	switch e.Type {
+	case raftpb.EntryNormal:
+		var r pb.InternalRaftRequest
+		if r.Unmarshal(e.Data) == nil {
+			s = r.String()
+		}
+	case raftpb.EntryConfChange:
+		var cc raftpb.ConfChange
+		if cc.Unmarshal(e.Data) == nil {
+			s = cc.String()
+		}
+	case raftpb.EntryConfChangeV2:
+		var cc raftpb.ConfChangeV2
+		if cc.Unmarshal(e.Data) == nil {
+			s = cc.String()
+		}
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return fmt.Sprintf("%d bytes", len(e.Data))
+	}
+	if len(s) > 120 {
+		s = s[:117] + "..."
+	}
+	return s
+}
@@ -0,0 +1,297 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdutl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/server/v3/storage/datadir"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
+	"go.etcd.io/etcd/server/v3/storage/wal"
+)
+
+func newWALDumpCommand() *cobra.Command {
+	o := &walDumpOptions{format: "json"}
+	cmd := &cobra.Command{
+		Use:   "dump --data-dir {data dir} [options]",
+		Short: "Dumps the WAL entries decoded into their etcd requests",
+		Long: `Dumps the entries of the WAL in the order they were written, the entries overwritten included, with
+their etcd requests decoded. The values are only dumped with --values, and the passwords and the tokens
+are redacted. The json format prints a JSON object per line.
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := o.Config()
+			if err != nil {
+				cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+			}
+			if err = WALDump(cfg, cmd.OutOrStdout()); err != nil {
+				cobrautl.ExitWithError(cobrautl.ExitError, err)
+			}
+		},
+	}
+	o.AddFlags(cmd)
+	return cmd
+}
+
+type walDumpOptions struct {
+	dataDir    string
+	walDir     string
+	startIndex uint64
+	endIndex   uint64
+	values     bool
+	format     string
+
+	encryptionKeyFile string
+}
+
+func (o *walDumpOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.dataDir, "data-dir", o.dataDir, "Path to the etcd data dir")
+	cmd.MarkFlagRequired("data-dir")
+	cmd.MarkFlagDirname("data-dir")
+	cmd.Flags().StringVar(&o.walDir, "wal-dir", o.walDir, "Path to the etcd wal dir (use --data-dir if none given)")
+	cmd.MarkFlagDirname("wal-dir")
+
+	cmd.Flags().Uint64Var(&o.startIndex, "start-index", o.startIndex, "Index of the first entry dumped")
+	cmd.Flags().Uint64Var(&o.endIndex, "end-index", o.endIndex, "Index of the last entry dumped, the last entry of the WAL if 0")
+	cmd.Flags().BoolVar(&o.values, "values", o.values, "Dump the values written along with the keys")
+	cmd.Flags().StringVar(&o.format, "format", o.format, "Output format (json, simple)")
+	cmd.RegisterFlagCompletionFunc("format", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "simple"}, cobra.ShellCompDirectiveDefault
+	})
+	cmd.Flags().StringVar(&o.encryptionKeyFile, "encryption-key-file", o.encryptionKeyFile, "Path to the file of the keys encrypting the WAL entries, to read them if encrypted")
+}
+
+func (o *walDumpOptions) Config() (*WALDumpConfig, error) {
+	c := &WALDumpConfig{
+		Logger:     GetLogger(),
+		WALDir:     o.walDir,
+		StartIndex: o.startIndex,
+		EndIndex:   o.endIndex,
+		Values:     o.values,
+		Format:     o.format,
+	}
+	if c.WALDir == "" {
+		c.WALDir = datadir.ToWalDir(o.dataDir)
+	}
+	if c.Format != "json" && c.Format != "simple" {
+		return nil, fmt.Errorf("unknown --format %q (expected json or simple)", c.Format)
+	}
+	if c.EndIndex != 0 && c.EndIndex < c.StartIndex {
+		return nil, fmt.Errorf("--end-index[%d] should not be lower than --start-index[%d]", c.EndIndex, c.StartIndex)
+	}
+	var err error
+	if c.KeyProvider, err = newKeyProvider(o.encryptionKeyFile); err != nil {
+		return nil, fmt.Errorf("failed to read the encryption keys: %v", err)
+	}
+	return c, nil
+}
+
+// WALDumpConfig configures WALDump.
+type WALDumpConfig struct {
+	Logger *zap.Logger
+	WALDir string
+	// StartIndex and EndIndex bound the indexes of the entries dumped, the
+	// entries not being bounded by EndIndex if 0.
+	StartIndex uint64
+	EndIndex   uint64
+	// Values dumps the values written, which are left out otherwise.
+	Values bool
+	// Format is "json" or "simple".
+	Format string
+
+	KeyProvider encryption.KeyProvider
+}
+
+// walDumpEntry is the JSON form of a dumped entry.
+type walDumpEntry struct {
+	Index   uint64                 `json:"index"`
+	Term    uint64                 `json:"term"`
+	Type    string                 `json:"type"`
+	Segment string                 `json:"segment"`
+	Offset  int64                  `json:"offset"`
+	ID      uint64                 `json:"id,omitempty"`
+	Request string                 `json:"request,omitempty"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	Size    int                    `json:"size,omitempty"`
+}
+
+// WALDump writes the entries of the WAL to out, in the order they were
+// written. It returns an error if the WAL is corrupted, once the entries
+// before the corruption are written.
+func WALDump(c *WALDumpConfig, out io.Writer) error {
+	enc := json.NewEncoder(out)
+	corruption, err := wal.Scan(c.Logger, c.WALDir, c.KeyProvider, func(r wal.Record) error {
+		e := r.Entry
+		if e == nil || e.Index < c.StartIndex || (c.EndIndex != 0 && e.Index > c.EndIndex) {
+			return nil
+		}
+		de := walDumpEntry{Index: e.Index, Term: e.Term, Type: e.Type.String(), Segment: r.Segment, Offset: r.Offset}
+		decodeEntry(&de, *e, c.Values)
+		if c.Format == "json" {
+			return enc.Encode(de)
+		}
+		fields, _ := json.Marshal(de.Fields)
+		if de.Fields == nil {
+			fields = nil
+		}
+		_, err := fmt.Fprintf(out, "%d\t%d\t%s\t%s\t%s\n", de.Index, de.Term, de.Type, de.Request, fields)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if corruption != nil {
+		return corruption
+	}
+	return nil
+}
+
+// decodeEntry decodes the request of e into de.
+func decodeEntry(de *walDumpEntry, e raftpb.Entry, values bool) {
+	if len(e.Data) == 0 {
+		return
+	}
+	var msg interface{}
+	switch e.Type {
+	case raftpb.EntryNormal:
+		var r pb.InternalRaftRequest
+		if r.Unmarshal(e.Data) != nil {
+			break
+		}
+		de.ID = r.ID
+		if r.Header != nil {
+			de.ID = r.Header.ID
+		}
+		// the request is the only field set other than the header and the ID
+		v := reflect.ValueOf(&r).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if f.Name == "Header" || f.Name == "ID" || v.Field(i).Kind() != reflect.Ptr || v.Field(i).IsNil() {
+				continue
+			}
+			de.Request = protoName(f)
+			msg = v.Field(i).Interface()
+			break
+		}
+	case raftpb.EntryConfChange:
+		var cc raftpb.ConfChange
+		if cc.Unmarshal(e.Data) == nil {
+			de.Request, msg = "conf_change", &cc
+		}
+	case raftpb.EntryConfChangeV2:
+		var cc raftpb.ConfChangeV2
+		if cc.Unmarshal(e.Data) == nil {
+			de.Request, msg = "conf_change_v2", &cc
+		}
+	}
+	if msg == nil {
+		de.Size = len(e.Data)
+		return
+	}
+	if fields, ok := dumpValue(reflect.ValueOf(msg), values).(map[string]interface{}); ok {
+		de.Fields = fields
+	}
+}
+
+// dumpValue converts v, a protobuf message or one of its fields, for the
+// JSON encoding, the bytes being written as strings.
+func dumpValue(v reflect.Value, values bool) interface{} {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return dumpValue(v.Elem(), values)
+	case reflect.Struct:
+		m := map[string]interface{}{}
+		for i := 0; i < v.NumField(); i++ {
+			f, fv := v.Type().Field(i), v.Field(i)
+			if f.PkgPath != "" || strings.HasPrefix(f.Name, "XXX_") || fv.IsZero() {
+				continue
+			}
+			if _, ok := f.Tag.Lookup("protobuf_oneof"); ok {
+				// the oneof wrapper holds a single field
+				w := fv.Elem().Elem()
+				fv, f = w.Field(0), w.Type().Field(0)
+			}
+			name := protoName(f)
+			switch {
+			case isSecretField(name):
+				m[name] = "[redacted]"
+			case isValueField(name) && !values && (fv.Kind() == reflect.String || fv.Kind() == reflect.Slice):
+				m[name+"_size"] = fv.Len()
+			default:
+				m[name] = dumpValue(fv, values)
+			}
+		}
+		return m
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return bytesString(v.Bytes())
+		}
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = dumpValue(v.Index(i), values)
+		}
+		return s
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok && v.Kind() == reflect.Int32 {
+		// the enums
+		return s.String()
+	}
+	return v.Interface()
+}
+
+// protoName returns the protobuf name of the field f.
+func protoName(f reflect.StructField) string {
+	for _, opt := range strings.Split(f.Tag.Get("protobuf"), ",") {
+		if strings.HasPrefix(opt, "name=") {
+			return strings.TrimPrefix(opt, "name=")
+		}
+	}
+	return f.Name
+}
+
+// isValueField returns whether the field name holds a value written: the
+// value of the puts and the txn compares, the data of the chunks and the
+// value of the v2 requests.
+func isValueField(name string) bool {
+	return name == "value" || name == "data" || name == "val"
+}
+
+func isSecretField(name string) bool {
+	return name == "password" || name == "hashedPassword" || name == "simple_token"
+}
+
+// bytesString returns b as a string, quoted if it is not valid UTF-8.
+func bytesString(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	q := strconv.Quote(string(b))
+	return q[1 : len(q)-1]
+}
@@ -0,0 +1,47 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package osutil
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+// ReloadHandler is a function that is called on receiving a SIGHUP signal.
+type ReloadHandler func() error
+
+// HandleReload calls the handler function on every SIGHUP received.
+// Errors returned by the handler are logged and do not stop the process.
+func HandleReload(lg *zap.Logger, h ReloadHandler) {
+	notifier := make(chan os.Signal, 1)
+	signal.Notify(notifier, syscall.SIGHUP)
+
+	go func() {
+		for sig := range notifier {
+			if lg != nil {
+				lg.Info("received signal; reloading configuration", zap.String("signal", sig.String()))
+			}
+			if err := h(); err != nil && lg != nil {
+				lg.Warn("failed to reload configuration", zap.Error(err))
+			}
+		}
+	}()
+}
@@ -1,4 +1,4 @@
-// Copyright 2018 The etcd Authors
+// Copyright 2022 The etcd Authors
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -15,14 +15,11 @@
 //go:build windows
 // +build windows
 
-package embed
+package osutil
 
-import (
-	"os"
+import "go.uber.org/zap"
 
-	"go.uber.org/zap/zapcore"
-)
+type ReloadHandler func() error
 
-func getJournalWriteSyncer() (zapcore.WriteSyncer, error) {
-	return zapcore.AddSync(os.Stderr), nil
-}
+// HandleReload is a no-op on windows
+func HandleReload(*zap.Logger, ReloadHandler) {}
@@ -160,6 +160,16 @@ type Config struct {
 	// overflowing that sending buffer. TODO (xiangli): feedback to application to
 	// limit the proposal rate?
 	MaxInflightMsgs int
+	// MaxInflightBytes limits the total byte size of the entries of the
+	// in-flight append messages to a follower during optimistic replication
+	// phase, so that a slow follower does not hold an unbounded amount of
+	// memory in the transportation layer. Note: 0 for no limit.
+	MaxInflightBytes uint64
+	// AdaptiveInflightMsgs adapts the limit of in-flight append messages to
+	// each follower: the limit is halved when the follower is reported
+	// unreachable during optimistic replication phase, and grows back by one
+	// message per acknowledgement, up to MaxInflightMsgs.
+	AdaptiveInflightMsgs bool
 
 	// CheckQuorum specifies if the leader should check quorum activity. Leader
 	// steps down when quorum is not active for an electionTimeout.
@@ -229,6 +239,10 @@ func (c *Config) validate() error {
 		return errors.New("max inflight messages must be greater than 0")
 	}
 
+	if c.MaxInflightBytes != 0 && c.MaxInflightBytes < c.MaxSizePerMsg {
+		return errors.New("max inflight bytes must be >= max message size")
+	}
+
 	if c.Logger == nil {
 		c.Logger = getLogger()
 	}
@@ -253,6 +267,9 @@ type raft struct {
 
 	maxMsgSize         uint64
 	maxUncommittedSize uint64
+	// adaptiveInflight adapts the limit of in-flight append messages to each
+	// follower.
+	adaptiveInflight bool
 	// TODO(tbg): rename to trk.
 	prs tracker.ProgressTracker
 
@@ -331,8 +348,9 @@ func newRaft(c *Config) *raft {
 		isLearner:                 false,
 		raftLog:                   raftlog,
 		maxMsgSize:                c.MaxSizePerMsg,
+		adaptiveInflight:          c.AdaptiveInflightMsgs,
 		maxUncommittedSize:        c.MaxUncommittedEntriesSize,
-		prs:                       tracker.MakeProgressTracker(c.MaxInflightMsgs),
+		prs:                       tracker.MakeProgressTrackerWithBytes(c.MaxInflightMsgs, c.MaxInflightBytes),
 		electionTimeout:           c.ElectionTick,
 		heartbeatTimeout:          c.HeartbeatTick,
 		logger:                    c.Logger,
@@ -479,7 +497,7 @@ func (r *raft) maybeSendAppend(to uint64, sendIfEmpty bool) bool {
 			case tracker.StateReplicate:
 				last := m.Entries[n-1].Index
 				pr.OptimisticUpdate(last)
-				pr.Inflights.Add(last)
+				pr.Inflights.AddWithBytes(last, payloadsSize(m.Entries))
 			case tracker.StateProbe:
 				pr.ProbeSent = true
 			default:
@@ -605,7 +623,7 @@ func (r *raft) reset(term uint64) {
 		*pr = tracker.Progress{
 			Match:     0,
 			Next:      r.raftLog.lastIndex() + 1,
-			Inflights: tracker.NewInflights(r.prs.MaxInflight),
+			Inflights: r.prs.MakeInflights(),
 			IsLearner: pr.IsLearner,
 		}
 		if id == r.id {
@@ -1333,6 +1351,9 @@ func stepLeader(r *raft, m pb.Message) error {
 		// During optimistic replication, if the remote becomes unreachable,
 		// there is huge probability that a MsgApp is lost.
 		if pr.State == tracker.StateReplicate {
+			if r.adaptiveInflight {
+				pr.Inflights.Throttle()
+			}
 			pr.BecomeProbe()
 		}
 		r.logger.Debugf("%x failed to send message to %x because it is unreachable [%s]", r.id, m.From, pr)
@@ -1591,7 +1612,7 @@ func (r *raft) restore(s pb.Snapshot) bool {
 	r.raftLog.restore(s)
 
 	// Reset the configuration and add the (potentially updated) peers in anew.
-	r.prs = tracker.MakeProgressTracker(r.prs.MaxInflight)
+	r.prs = tracker.MakeProgressTrackerWithBytes(r.prs.MaxInflight, r.prs.MaxInflightBytes)
 	cfg, prs, err := confchange.Restore(confchange.Changer{
 		Tracker:   r.prs,
 		LastIndex: r.raftLog.lastIndex(),
@@ -1759,10 +1780,7 @@ func (r *raft) responseToReadIndexReq(req pb.Message, readIndex uint64) pb.Messa
 // Empty payloads are never refused. This is used both for appending an empty
 // entry at a new leader's term, as well as leaving a joint configuration.
 func (r *raft) increaseUncommittedSize(ents []pb.Entry) bool {
-	var s uint64
-	for _, e := range ents {
-		s += uint64(PayloadSize(e))
-	}
+	s := payloadsSize(ents)
 
 	if r.uncommittedSize > 0 && s > 0 && r.uncommittedSize+s > r.maxUncommittedSize {
 		// If the uncommitted tail of the Raft log is empty, allow any size
@@ -1786,10 +1804,7 @@ func (r *raft) reduceUncommittedSize(ents []pb.Entry) {
 		return
 	}
 
-	var s uint64
-	for _, e := range ents {
-		s += uint64(PayloadSize(e))
-	}
+	s := payloadsSize(ents)
 	if s > r.uncommittedSize {
 		// uncommittedSize may underestimate the size of the uncommitted Raft
 		// log tail but will never overestimate it. Saturate at 0 instead of
@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	pb "go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/raft/v3/tracker"
 )
 
 // TestMsgAppFlowControlFull ensures:
@@ -154,3 +155,61 @@ func TestMsgAppFlowControlRecvHeartbeat(t *testing.T) {
 		r.readMessages()
 	}
 }
+
+// TestMsgAppFlowControlMaxInflightBytes ensures the msgApps sent stop once
+// the inflight bytes reach MaxInflightBytes, whatever the inflight messages.
+func TestMsgAppFlowControlMaxInflightBytes(t *testing.T) {
+	cfg := newTestConfig(1, 5, 1, newTestMemoryStorage(withPeers(1, 2)))
+	cfg.MaxSizePerMsg = 10
+	cfg.MaxInflightBytes = 20
+	r := newRaft(cfg)
+	r.becomeCandidate()
+	r.becomeLeader()
+
+	pr2 := r.prs.Progress[2]
+	// force the progress to be in replicate state
+	pr2.BecomeReplicate()
+	// the first msgApp carries the empty entry of the leader's term
+	for i := 0; i < 3; i++ {
+		r.Step(pb.Message{From: 1, To: 1, Type: pb.MsgProp, Entries: []pb.Entry{{Data: []byte("somedata10")}}})
+		ms := r.readMessages()
+		if len(ms) != 1 {
+			t.Fatalf("#%d: len(ms) = %d, want 1", i, len(ms))
+		}
+	}
+	if !pr2.Inflights.Full() || pr2.Inflights.Bytes() != 20 {
+		t.Fatalf("inflight bytes = %d (full %t), want 20 (full true)", pr2.Inflights.Bytes(), pr2.Inflights.Full())
+	}
+	r.Step(pb.Message{From: 1, To: 1, Type: pb.MsgProp, Entries: []pb.Entry{{Data: []byte("somedata10")}}})
+	if ms := r.readMessages(); len(ms) != 0 {
+		t.Fatalf("len(ms) = %d, want 0", len(ms))
+	}
+}
+
+// TestMsgAppFlowControlAdaptive ensures a follower reported unreachable
+// while replicating gets its inflight window halved with
+// AdaptiveInflightMsgs, and kept otherwise.
+func TestMsgAppFlowControlAdaptive(t *testing.T) {
+	for _, adaptive := range []bool{false, true} {
+		cfg := newTestConfig(1, 5, 1, newTestMemoryStorage(withPeers(1, 2)))
+		cfg.AdaptiveInflightMsgs = adaptive
+		r := newRaft(cfg)
+		r.becomeCandidate()
+		r.becomeLeader()
+
+		pr2 := r.prs.Progress[2]
+		pr2.BecomeReplicate()
+		r.Step(pb.Message{From: 2, To: 1, Type: pb.MsgUnreachable})
+
+		want := cfg.MaxInflightMsgs
+		if adaptive {
+			want /= 2
+		}
+		if w := pr2.Inflights.Window(); w != want {
+			t.Fatalf("adaptive %t: window = %d, want %d", adaptive, w, want)
+		}
+		if pr2.State != tracker.StateProbe {
+			t.Fatalf("adaptive %t: state = %s, want %s", adaptive, pr2.State, tracker.StateProbe)
+		}
+	}
+}
@@ -14,29 +14,55 @@
 
 package tracker
 
+// inflight describes an in-flight MsgApp message.
+type inflight struct {
+	index uint64 // the index of the last entry inside the message
+	bytes uint64 // the total byte size of the entries in the message
+}
+
 // Inflights limits the number of MsgApp (represented by the largest index
 // contained within) sent to followers but not yet acknowledged by them. Callers
 // use Full() to check whether more messages can be sent, call Add() whenever
 // they are sending a new append, and release "quota" via FreeLE() whenever an
 // ack is received.
+//
+// An Inflights created by NewInflightsWithBytes also limits the total byte
+// size of the inflight messages, and the number of inflight messages by a
+// window adapted to the follower: Throttle() halves it when the follower cannot
+// keep up, and each ack received grows it back by one message, up to size.
 type Inflights struct {
 	// the starting index in the buffer
 	start int
-	// number of inflights in the buffer
-	count int
 
-	// the size of the buffer
-	size int
+	count int    // number of inflight messages in the buffer
+	bytes uint64 // number of inflight bytes
 
-	// buffer contains the index of the last entry
-	// inside one message.
-	buffer []uint64
+	size     int    // the max number of inflight messages
+	maxBytes uint64 // the max total byte size of inflight messages
+	window   int    // the current max number of inflight messages, up to size
+	adaptive bool   // whether Throttle() shrinks the window
+
+	// buffer is a ring buffer containing info about all in-flight messages.
+	buffer []inflight
 }
 
 // NewInflights sets up an Inflights that allows up to 'size' inflight messages.
 func NewInflights(size int) *Inflights {
 	return &Inflights{
-		size: size,
+		size:   size,
+		window: size,
+	}
+}
+
+// NewInflightsWithBytes sets up an Inflights that allows up to size inflight
+// messages, with up to maxBytes bytes in total (0 for no limit), in a window
+// adapted to the follower.
+func NewInflightsWithBytes(size int, maxBytes uint64) *Inflights {
+	return &Inflights{
+		size:     size,
+		maxBytes: maxBytes,
+		window:   size,
+		adaptive: true,
 	}
 }
 
@@ -44,7 +70,7 @@ func NewInflights(size int) *Inflights {
 // the receiver.
 func (in *Inflights) Clone() *Inflights {
 	ins := *in
-	ins.buffer = append([]uint64(nil), in.buffer...)
+	ins.buffer = append([]inflight(nil), in.buffer...)
 	return &ins
 }
 
@@ -52,7 +78,12 @@ func (in *Inflights) Clone() *Inflights {
 // dispatched. Full() must be called prior to Add() to verify that there is room
 // for one more message, and consecutive calls to add Add() must provide a
 // monotonic sequence of indexes.
-func (in *Inflights) Add(inflight uint64) {
+func (in *Inflights) Add(index uint64) {
+	in.AddWithBytes(index, 0)
+}
+
+// AddWithBytes is like Add, for a message of the given byte size.
+func (in *Inflights) AddWithBytes(index, bytes uint64) {
 	if in.Full() {
 		panic("cannot add into a Full inflights")
 	}
@@ -64,8 +95,9 @@ func (in *Inflights) Add(inflight uint64) {
 	if next >= len(in.buffer) {
 		in.grow()
 	}
-	in.buffer[next] = inflight
+	in.buffer[next] = inflight{index: index, bytes: bytes}
 	in.count++
+	in.bytes += bytes
 }
 
 // grow the inflight buffer by doubling up to inflights.size. We grow on demand
@@ -78,24 +110,26 @@ func (in *Inflights) grow() {
 	} else if newSize > in.size {
 		newSize = in.size
 	}
-	newBuffer := make([]uint64, newSize)
+	newBuffer := make([]inflight, newSize)
 	copy(newBuffer, in.buffer)
 	in.buffer = newBuffer
 }
 
 // FreeLE frees the inflights smaller or equal to the given `to` flight.
 func (in *Inflights) FreeLE(to uint64) {
-	if in.count == 0 || to < in.buffer[in.start] {
+	if in.count == 0 || to < in.buffer[in.start].index {
 		// out of the left side of the window
 		return
 	}
 
 	idx := in.start
 	var i int
+	var bytes uint64
 	for i = 0; i < in.count; i++ {
-		if to < in.buffer[idx] { // found the first large inflight
+		if to < in.buffer[idx].index { // found the first large inflight
 			break
 		}
+		bytes += in.buffer[idx].bytes
 
 		// increase index and maybe rotate
 		size := in.size
@@ -105,28 +139,51 @@ func (in *Inflights) FreeLE(to uint64) {
 	}
 	// free i inflights and set new start index
 	in.count -= i
+	in.bytes -= bytes
 	in.start = idx
 	if in.count == 0 {
 		// inflights is empty, reset the start index so that we don't grow the
 		// buffer unnecessarily.
 		in.start = 0
 	}
+	if i > 0 && in.window < in.size {
+		in.window++
+	}
 }
 
 // FreeFirstOne releases the first inflight. This is a no-op if nothing is
 // inflight.
-func (in *Inflights) FreeFirstOne() { in.FreeLE(in.buffer[in.start]) }
+func (in *Inflights) FreeFirstOne() { in.FreeLE(in.buffer[in.start].index) }
+
+// Throttle halves the window of inflight messages, down to one message, as the
+// follower cannot keep up with the messages sent. This is a no-op unless the
+// Inflights was created by NewInflightsWithBytes.
+func (in *Inflights) Throttle() {
+	if !in.adaptive {
+		return
+	}
+	if in.window /= 2; in.window < 1 {
+		in.window = 1
+	}
+}
 
 // Full returns true if no more messages can be sent at the moment.
 func (in *Inflights) Full() bool {
-	return in.count == in.size
+	return in.count >= in.window || (in.maxBytes != 0 && in.bytes >= in.maxBytes)
 }
 
 // Count returns the number of inflight messages.
 func (in *Inflights) Count() int { return in.count }
 
-// reset frees all inflights.
+// Bytes returns the number of inflight bytes.
+func (in *Inflights) Bytes() uint64 { return in.bytes }
+
+// Window returns the current max number of inflight messages.
+func (in *Inflights) Window() int { return in.window }
+
+// reset frees all inflights. The window is kept, as adapted to the follower.
 func (in *Inflights) reset() {
 	in.count = 0
+	in.bytes = 0
 	in.start = 0
 }
@@ -23,19 +23,24 @@ func TestInflightsAdd(t *testing.T) {
 	// no rotating case
 	in := &Inflights{
 		size:   10,
-		buffer: make([]uint64, 10),
+		window: 10,
+		buffer: make([]inflight, 10),
 	}
 
 	for i := 0; i < 5; i++ {
-		in.Add(uint64(i))
+		in.AddWithBytes(uint64(i), uint64(100+i))
 	}
 
 	wantIn := &Inflights{
-		start: 0,
-		count: 5,
-		size:  10,
-		//               ↓------------
-		buffer: []uint64{0, 1, 2, 3, 4, 0, 0, 0, 0, 0},
+		start:  0,
+		count:  5,
+		bytes:  510,
+		size:   10,
+		window: 10,
+		buffer: inflightsBuffer(
+			//       ↓------------
+			[]uint64{0, 1, 2, 3, 4, 0, 0, 0, 0, 0},
+			[]uint64{100, 101, 102, 103, 104, 0, 0, 0, 0, 0}),
 	}
 
 	if !reflect.DeepEqual(in, wantIn) {
@@ -43,15 +48,19 @@ func TestInflightsAdd(t *testing.T) {
 	}
 
 	for i := 5; i < 10; i++ {
-		in.Add(uint64(i))
+		in.AddWithBytes(uint64(i), uint64(100+i))
 	}
 
 	wantIn2 := &Inflights{
-		start: 0,
-		count: 10,
-		size:  10,
-		//               ↓---------------------------
-		buffer: []uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		start:  0,
+		count:  10,
+		bytes:  1045,
+		size:   10,
+		window: 10,
+		buffer: inflightsBuffer(
+			//       ↓---------------------------
+			[]uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+			[]uint64{100, 101, 102, 103, 104, 105, 106, 107, 108, 109}),
 	}
 
 	if !reflect.DeepEqual(in, wantIn2) {
@@ -62,19 +71,24 @@ func TestInflightsAdd(t *testing.T) {
 	in2 := &Inflights{
 		start:  5,
 		size:   10,
-		buffer: make([]uint64, 10),
+		window: 10,
+		buffer: make([]inflight, 10),
 	}
 
 	for i := 0; i < 5; i++ {
-		in2.Add(uint64(i))
+		in2.AddWithBytes(uint64(i), uint64(100+i))
 	}
 
 	wantIn21 := &Inflights{
-		start: 5,
-		count: 5,
-		size:  10,
-		//                              ↓------------
-		buffer: []uint64{0, 0, 0, 0, 0, 0, 1, 2, 3, 4},
+		start:  5,
+		count:  5,
+		bytes:  510,
+		size:   10,
+		window: 10,
+		buffer: inflightsBuffer(
+			//                      ↓------------
+			[]uint64{0, 0, 0, 0, 0, 0, 1, 2, 3, 4},
+			[]uint64{0, 0, 0, 0, 0, 100, 101, 102, 103, 104}),
 	}
 
 	if !reflect.DeepEqual(in2, wantIn21) {
@@ -82,15 +96,19 @@ func TestInflightsAdd(t *testing.T) {
 	}
 
 	for i := 5; i < 10; i++ {
-		in2.Add(uint64(i))
+		in2.AddWithBytes(uint64(i), uint64(100+i))
 	}
 
 	wantIn22 := &Inflights{
-		start: 5,
-		count: 10,
-		size:  10,
-		//               -------------- ↓------------
-		buffer: []uint64{5, 6, 7, 8, 9, 0, 1, 2, 3, 4},
+		start:  5,
+		count:  10,
+		bytes:  1045,
+		size:   10,
+		window: 10,
+		buffer: inflightsBuffer(
+			//       -------------- ↓------------
+			[]uint64{5, 6, 7, 8, 9, 0, 1, 2, 3, 4},
+			[]uint64{105, 106, 107, 108, 109, 100, 101, 102, 103, 104}),
 	}
 
 	if !reflect.DeepEqual(in2, wantIn22) {
@@ -102,17 +120,21 @@ func TestInflightFreeTo(t *testing.T) {
 	// no rotating case
 	in := NewInflights(10)
 	for i := 0; i < 10; i++ {
-		in.Add(uint64(i))
+		in.AddWithBytes(uint64(i), uint64(100+i))
 	}
 
 	in.FreeLE(4)
 
 	wantIn := &Inflights{
-		start: 5,
-		count: 5,
-		size:  10,
-		//                              ↓------------
-		buffer: []uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		start:  5,
+		count:  5,
+		bytes:  535,
+		size:   10,
+		window: 10,
+		buffer: inflightsBuffer(
+			//                      ↓------------
+			[]uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+			[]uint64{100, 101, 102, 103, 104, 105, 106, 107, 108, 109}),
 	}
 
 	if !reflect.DeepEqual(in, wantIn) {
@@ -122,11 +144,15 @@ func TestInflightFreeTo(t *testing.T) {
 	in.FreeLE(8)
 
 	wantIn2 := &Inflights{
-		start: 9,
-		count: 1,
-		size:  10,
-		//                                          ↓
-		buffer: []uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		start:  9,
+		count:  1,
+		bytes:  109,
+		size:   10,
+		window: 10,
+		buffer: inflightsBuffer(
+			//                                  ↓
+			[]uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+			[]uint64{100, 101, 102, 103, 104, 105, 106, 107, 108, 109}),
 	}
 
 	if !reflect.DeepEqual(in, wantIn2) {
@@ -135,17 +161,21 @@ func TestInflightFreeTo(t *testing.T) {
 
 	// rotating case
 	for i := 10; i < 15; i++ {
-		in.Add(uint64(i))
+		in.AddWithBytes(uint64(i), uint64(100+i))
 	}
 
 	in.FreeLE(12)
 
 	wantIn3 := &Inflights{
-		start: 3,
-		count: 2,
-		size:  10,
-		//                           ↓-----
-		buffer: []uint64{10, 11, 12, 13, 14, 5, 6, 7, 8, 9},
+		start:  3,
+		count:  2,
+		bytes:  227,
+		size:   10,
+		window: 10,
+		buffer: inflightsBuffer(
+			//                   ↓-----
+			[]uint64{10, 11, 12, 13, 14, 5, 6, 7, 8, 9},
+			[]uint64{110, 111, 112, 113, 114, 105, 106, 107, 108, 109}),
 	}
 
 	if !reflect.DeepEqual(in, wantIn3) {
@@ -155,11 +185,14 @@ func TestInflightFreeTo(t *testing.T) {
 	in.FreeLE(14)
 
 	wantIn4 := &Inflights{
-		start: 0,
-		count: 0,
-		size:  10,
-		//               ↓
-		buffer: []uint64{10, 11, 12, 13, 14, 5, 6, 7, 8, 9},
+		start:  0,
+		count:  0,
+		size:   10,
+		window: 10,
+		buffer: inflightsBuffer(
+			//       ↓
+			[]uint64{10, 11, 12, 13, 14, 5, 6, 7, 8, 9},
+			[]uint64{110, 111, 112, 113, 114, 105, 106, 107, 108, 109}),
 	}
 
 	if !reflect.DeepEqual(in, wantIn4) {
@@ -170,20 +203,102 @@ func TestInflightFreeTo(t *testing.T) {
 func TestInflightFreeFirstOne(t *testing.T) {
 	in := NewInflights(10)
 	for i := 0; i < 10; i++ {
-		in.Add(uint64(i))
+		in.AddWithBytes(uint64(i), uint64(100+i))
 	}
 
 	in.FreeFirstOne()
 
 	wantIn := &Inflights{
-		start: 1,
-		count: 9,
-		size:  10,
-		//                  ↓------------------------
-		buffer: []uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		start:  1,
+		count:  9,
+		bytes:  945,
+		size:   10,
+		window: 10,
+		buffer: inflightsBuffer(
+			//          ↓------------------------
+			[]uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+			[]uint64{100, 101, 102, 103, 104, 105, 106, 107, 108, 109}),
 	}
 
 	if !reflect.DeepEqual(in, wantIn) {
 		t.Fatalf("in = %+v, want %+v", in, wantIn)
 	}
 }
+
+func TestInflightsFullBytes(t *testing.T) {
+	in := NewInflightsWithBytes(10, 1000)
+	for i := 0; i < 3; i++ {
+		if in.Full() {
+			t.Fatalf("#%d: unexpected full inflights", i)
+		}
+		in.AddWithBytes(uint64(i), 400)
+	}
+	if !in.Full() {
+		t.Fatalf("expected full inflights, with %d bytes", in.Bytes())
+	}
+	in.FreeLE(0)
+	if in.Full() {
+		t.Fatalf("unexpected full inflights, with %d bytes", in.Bytes())
+	}
+	if in.Bytes() != 800 {
+		t.Fatalf("bytes = %d, want 800", in.Bytes())
+	}
+}
+
+func TestInflightsThrottle(t *testing.T) {
+	in := NewInflightsWithBytes(8, 0)
+	in.Throttle()
+	in.Throttle()
+	if w := in.Window(); w != 2 {
+		t.Fatalf("window = %d, want 2", w)
+	}
+	in.Add(1)
+	in.Add(2)
+	if !in.Full() {
+		t.Fatalf("expected full inflights, with window %d", in.Window())
+	}
+	// each ack grows the window by one message
+	in.FreeLE(1)
+	if w := in.Window(); w != 3 {
+		t.Fatalf("window = %d, want 3", w)
+	}
+	in.Add(3)
+	in.Add(4)
+	if !in.Full() {
+		t.Fatalf("expected full inflights, with window %d", in.Window())
+	}
+	// the window is kept across resets, and never shrinks below one message
+	in.reset()
+	for i := 0; i < 3; i++ {
+		in.Throttle()
+	}
+	if w := in.Window(); w != 1 {
+		t.Fatalf("window = %d, want 1", w)
+	}
+	for i := 0; i < 10; i++ {
+		in.Add(uint64(10 + i))
+		in.FreeLE(uint64(10 + i))
+	}
+	if w := in.Window(); w != 8 {
+		t.Fatalf("window = %d, want 8", w)
+	}
+}
+
+func TestInflightsThrottleNotAdaptive(t *testing.T) {
+	in := NewInflights(8)
+	in.Throttle()
+	if w := in.Window(); w != 8 {
+		t.Fatalf("window = %d, want 8", w)
+	}
+}
+
+func inflightsBuffer(indices []uint64, sizes []uint64) []inflight {
+	if len(indices) != len(sizes) {
+		panic("len(indices) != len(sizes)")
+	}
+	buffer := make([]inflight, 0, len(indices))
+	for i, idx := range indices {
+		buffer = append(buffer, inflight{index: idx, bytes: sizes[i]})
+	}
+	return buffer
+}
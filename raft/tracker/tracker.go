@@ -121,13 +121,28 @@ type ProgressTracker struct {
 
 	Votes map[uint64]bool
 
-	MaxInflight int
+	MaxInflight      int
+	MaxInflightBytes uint64
+
+	// adaptive is set if the Inflights of the Progress adapt their window
+	// to the followers.
+	adaptive bool
 }
 
 // MakeProgressTracker initializes a ProgressTracker.
 func MakeProgressTracker(maxInflight int) ProgressTracker {
+	p := MakeProgressTrackerWithBytes(maxInflight, 0)
+	p.adaptive = false
+	return p
+}
+
+// MakeProgressTrackerWithBytes initializes a ProgressTracker whose Progress
+// limit the inflight messages to maxBytes bytes in total (0 for no limit), in
+// a window adapted to the follower.
+func MakeProgressTrackerWithBytes(maxInflight int, maxBytes uint64) ProgressTracker {
 	p := ProgressTracker{
-		MaxInflight: maxInflight,
+		MaxInflight:      maxInflight,
+		MaxInflightBytes: maxBytes,
 		Config: Config{
 			Voters: quorum.JointConfig{
 				quorum.MajorityConfig{},
@@ -138,10 +153,20 @@ func MakeProgressTracker(maxInflight int) ProgressTracker {
 		},
 		Votes:    map[uint64]bool{},
 		Progress: map[uint64]*Progress{},
+		adaptive: true,
 	}
 	return p
 }
 
+// MakeInflights returns the Inflights for a new Progress, as configured by
+// the constructor of the ProgressTracker.
+func (p *ProgressTracker) MakeInflights() *Inflights {
+	if p.adaptive {
+		return NewInflightsWithBytes(p.MaxInflight, p.MaxInflightBytes)
+	}
+	return NewInflights(p.MaxInflight)
+}
+
 // ConfState returns a ConfState representing the active configuration.
 func (p *ProgressTracker) ConfState() pb.ConfState {
 	return pb.ConfState{
@@ -161,6 +161,15 @@ func PayloadSize(e pb.Entry) int {
 	return len(e.Data)
 }
 
+// payloadsSize is the size of the payloads of the provided entries.
+func payloadsSize(ents []pb.Entry) uint64 {
+	var s uint64
+	for _, e := range ents {
+		s += uint64(PayloadSize(e))
+	}
+	return s
+}
+
 // DescribeEntry returns a concise human-readable description of an
 // Entry for debugging.
 func DescribeEntry(e pb.Entry, f EntryFormatter) string {
@@ -0,0 +1,97 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Authorizer decides on the client requests outside of etcd, in addition to
+// the permissions of the roles of the users.
+type Authorizer interface {
+	// Authorize returns the decision on the request. An error denies it.
+	Authorize(ctx context.Context, r *AuthorizationRequest) (*AuthorizationDecision, error)
+}
+
+// AuthorizationRequest describes a client request to an Authorizer.
+type AuthorizationRequest struct {
+	// User is the authenticated user, empty if none.
+	User string `json:"user"`
+	// Roles are the roles granted to the user outside of etcd.
+	Roles []string `json:"roles,omitempty"`
+	// Method is the full gRPC method, e.g. "/etcdserverpb.KV/Put".
+	Method string `json:"method"`
+	// Keys are the key ranges the request accesses.
+	Keys []AuthorizationKeyRange `json:"keys,omitempty"`
+}
+
+// AuthorizationKeyRange is a key range accessed by a request, by an operation
+// among "range", "put", "delete", "compare" and "watch".
+type AuthorizationKeyRange struct {
+	Op       string `json:"op"`
+	Key      []byte `json:"key"`
+	RangeEnd []byte `json:"range_end,omitempty"`
+}
+
+// AuthorizationDecision is the decision of an Authorizer.
+type AuthorizationDecision struct {
+	Allowed bool `json:"allowed"`
+	// Reason explains the decision.
+	Reason string `json:"reason,omitempty"`
+	// Limit, if positive, constrains a range request to return at most Limit
+	// keys.
+	Limit int64 `json:"limit,omitempty"`
+}
+
+type webhookAuthorizer struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookAuthorizer returns an Authorizer posting the requests as JSON to
+// url, which responds with the decisions as JSON.
+func NewWebhookAuthorizer(url string, timeout time.Duration) Authorizer {
+	return &webhookAuthorizer{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (w *webhookAuthorizer) Authorize(ctx context.Context, r *AuthorizationRequest) (*AuthorizationDecision, error) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("POST %s: %s", w.url, resp.Status)
+	}
+	d := &AuthorizationDecision{}
+	if err = json.NewDecoder(resp.Body).Decode(d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWebhookAuthorizer(t *testing.T) {
+	var got AuthorizationRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if got.User == "eve" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(AuthorizationDecision{Allowed: got.User == "alice", Reason: "policy", Limit: 10})
+	}))
+	defer srv.Close()
+
+	authz := NewWebhookAuthorizer(srv.URL, time.Second)
+	req := &AuthorizationRequest{
+		User:   "alice",
+		Roles:  []string{"role-dev"},
+		Method: "/etcdserverpb.KV/Range",
+		Keys:   []AuthorizationKeyRange{{Op: "range", Key: []byte("a"), RangeEnd: []byte("b")}},
+	}
+	d, err := authz.Authorize(context.TODO(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (AuthorizationDecision{Allowed: true, Reason: "policy", Limit: 10}); *d != want {
+		t.Fatalf("expected decision %+v, got %+v", want, *d)
+	}
+	if !reflect.DeepEqual(&got, req) {
+		t.Fatalf("expected request %+v, got %+v", req, got)
+	}
+
+	if d, err = authz.Authorize(context.TODO(), &AuthorizationRequest{User: "bob"}); err != nil || d.Allowed {
+		t.Fatalf("expected denied decision, got %+v, %v", d, err)
+	}
+	if _, err = authz.Authorize(context.TODO(), &AuthorizationRequest{User: "eve"}); err == nil {
+		t.Fatal("expected error on failed webhook")
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/x509"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// CertRoleRule grants a role to the client certificates having an attribute
+// matching a pattern.
+type CertRoleRule struct {
+	// Attribute is one of "cn", "o" and "ou" of the subject, "dns", "email",
+	// "uri" and "ip" of the subject alternative names, or "spiffe", the
+	// SPIFFE ID of an X.509 SVID.
+	Attribute string
+	// Pattern is a shell pattern, as of path.Match, the attribute matches.
+	Pattern string
+	Role    string
+}
+
+// ParseCertRoleRules parses the rules formatted as "attribute=pattern:role".
+func ParseCertRoleRules(ss []string) ([]CertRoleRule, error) {
+	var rules []CertRoleRule
+	for _, s := range ss {
+		i, j := strings.Index(s, "="), strings.LastIndex(s, ":")
+		if i <= 0 || j < i || j == len(s)-1 {
+			return nil, fmt.Errorf("invalid client cert role rule %q (expected \"attribute=pattern:role\")", s)
+		}
+		r := CertRoleRule{Attribute: s[:i], Pattern: s[i+1 : j], Role: s[j+1:]}
+		if certAttributes[r.Attribute] == nil {
+			return nil, fmt.Errorf("invalid client cert role rule %q (unknown attribute %q)", s, r.Attribute)
+		}
+		if _, err := path.Match(r.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid client cert role rule %q (%v)", s, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+var certAttributes = map[string]func(*x509.Certificate) []string{
+	"cn":    func(c *x509.Certificate) []string { return []string{c.Subject.CommonName} },
+	"o":     func(c *x509.Certificate) []string { return c.Subject.Organization },
+	"ou":    func(c *x509.Certificate) []string { return c.Subject.OrganizationalUnit },
+	"dns":   func(c *x509.Certificate) []string { return c.DNSNames },
+	"email": func(c *x509.Certificate) []string { return c.EmailAddresses },
+	"uri": func(c *x509.Certificate) []string {
+		var ss []string
+		for _, u := range c.URIs {
+			ss = append(ss, u.String())
+		}
+		return ss
+	},
+	"ip": func(c *x509.Certificate) []string {
+		var ss []string
+		for _, ip := range c.IPAddresses {
+			ss = append(ss, ip.String())
+		}
+		return ss
+	},
+	"spiffe": func(c *x509.Certificate) []string { return []string{spiffeID(c)} },
+}
+
+// spiffeID returns the SPIFFE ID of an X.509 SVID, its only URI SAN, of the
+// spiffe scheme, or "" if the certificate is not an SVID.
+func spiffeID(c *x509.Certificate) string {
+	if len(c.URIs) != 1 || c.URIs[0].Scheme != "spiffe" {
+		return ""
+	}
+	return c.URIs[0].String()
+}
+
+// matches returns whether the certificate is granted the role of the rule.
+func (r CertRoleRule) matches(c *x509.Certificate) bool {
+	for _, v := range certAttributes[r.Attribute](c) {
+		if v == "" {
+			continue
+		}
+		if ok, _ := path.Match(r.Pattern, v); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// certRoles returns the roles the rules grant to the certificate.
+func certRoles(rules []CertRoleRule, c *x509.Certificate) []string {
+	var roles []string
+	for _, r := range rules {
+		if r.matches(c) {
+			roles = append(roles, r.Role)
+		}
+	}
+	return uniqueSortedRoles(roles)
+}
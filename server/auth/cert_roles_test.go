@@ -0,0 +1,109 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestCertRoles(t *testing.T) {
+	u, err := url.Parse("spiffe://example.com/ns/prod/sa/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:         "api-1",
+			Organization:       []string{"example"},
+			OrganizationalUnit: []string{"platform", "ops"},
+		},
+		DNSNames:       []string{"api-1.prod.example.com"},
+		EmailAddresses: []string{"api@example.com"},
+		URIs:           []*url.URL{u},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+	}
+
+	tests := []struct {
+		rules []string
+		want  []string
+	}{
+		{rules: nil, want: nil},
+		{rules: []string{"cn=api-*:role-api"}, want: []string{"role-api"}},
+		{rules: []string{"o=example:role-o"}, want: []string{"role-o"}},
+		{rules: []string{"ou=ops:root", "ou=platform:role-platform"}, want: []string{"role-platform", "root"}},
+		{rules: []string{"dns=*.prod.example.com:role-prod"}, want: []string{"role-prod"}},
+		{rules: []string{"email=*@example.com:role-mail"}, want: []string{"role-mail"}},
+		{rules: []string{"uri=spiffe://example.com/ns/prod/*/*:role-prod"}, want: []string{"role-prod"}},
+		{rules: []string{"ip=10.0.0.*:role-net"}, want: []string{"role-net"}},
+		{rules: []string{"spiffe=spiffe://example.com/ns/prod/sa/*:role-sa"}, want: []string{"role-sa"}},
+		{rules: []string{"cn=api-*:role-api", "dns=api-*.prod.example.com:role-api"}, want: []string{"role-api"}},
+		{rules: []string{"cn=db-*:role-db", "ou=dev:role-dev", "dns=*.staging.example.com:role-dns"}, want: nil},
+	}
+	for i, tt := range tests {
+		rules, err := ParseCertRoleRules(tt.rules)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if got := certRoles(rules, cert); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("#%d: expected roles %v, got %v", i, tt.want, got)
+		}
+	}
+}
+
+func TestParseCertRoleRules(t *testing.T) {
+	rules, err := ParseCertRoleRules([]string{"uri=spiffe://example.com/*:role"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CertRoleRule{{Attribute: "uri", Pattern: "spiffe://example.com/*", Role: "role"}}
+	if !reflect.DeepEqual(rules, want) {
+		t.Fatalf("expected %+v, got %+v", want, rules)
+	}
+	for _, s := range []string{"", "cn", "=api:role", "cn=api", "cn=api:", "serial=1:role", "cn=[:role"} {
+		if _, err := ParseCertRoleRules([]string{s}); err == nil {
+			t.Errorf("expected error parsing %q", s)
+		}
+	}
+}
+
+func TestSPIFFEID(t *testing.T) {
+	parse := func(s string) *url.URL {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	tests := []struct {
+		uris []*url.URL
+		want string
+	}{
+		{nil, ""},
+		{[]*url.URL{parse("spiffe://example.com/etcd")}, "spiffe://example.com/etcd"},
+		{[]*url.URL{parse("https://example.com/etcd")}, ""},
+		// an SVID has a single URI SAN
+		{[]*url.URL{parse("spiffe://example.com/etcd"), parse("spiffe://example.com/other")}, ""},
+	}
+	for i, tt := range tests {
+		if got := spiffeID(&x509.Certificate{URIs: tt.uris}); got != tt.want {
+			t.Errorf("#%d: expected SPIFFE ID %q, got %q", i, tt.want, got)
+		}
+	}
+}
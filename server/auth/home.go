@@ -0,0 +1,76 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"go.etcd.io/etcd/api/v3/authpb"
+)
+
+// isAbsoluteKey returns true if the key is not subject to home prefix scoping.
+func isAbsoluteKey(key []byte) bool {
+	return len(key) > 0 && key[0] == '/'
+}
+
+func userHomePrefix(u *authpb.User) []byte {
+	if u == nil || u.Options == nil || len(u.Options.HomePrefix) == 0 {
+		return nil
+	}
+	return []byte(u.Options.HomePrefix)
+}
+
+// homeRangeEnd returns the end of the key range covered by the given home prefix.
+func homeRangeEnd(home []byte) []byte {
+	end := make([]byte, len(home))
+	copy(end, home)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i] = end[i] + 1
+			return end[:i+1]
+		}
+	}
+	// next prefix does not exist (e.g., 0xffff);
+	// default to the edge of the keyspace
+	return []byte{0}
+}
+
+// resolveHomeInterval prepends the home prefix to the given key range,
+// keeping ranges open to the end of the keyspace within the home prefix.
+func resolveHomeInterval(home, key, rangeEnd []byte) ([]byte, []byte) {
+	hkey := make([]byte, len(home)+len(key))
+	copy(hkey[copy(hkey, home):], key)
+
+	var hend []byte
+	if len(rangeEnd) == 1 && rangeEnd[0] == 0 {
+		hend = homeRangeEnd(home)
+	} else if len(rangeEnd) > 0 {
+		hend = make([]byte, len(home)+len(rangeEnd))
+		copy(hend[copy(hend, home):], rangeEnd)
+	}
+	return hkey, hend
+}
+
+func (as *authStore) ResolveHomeKey(authInfo *AuthInfo, key, rangeEnd []byte) ([]byte, []byte) {
+	if !as.IsAuthEnabled() || authInfo == nil || authInfo.Username == "" || isAbsoluteKey(key) {
+		return key, rangeEnd
+	}
+	tx := as.be.ReadTx()
+	tx.Lock()
+	home := userHomePrefix(tx.UnsafeGetUser(authInfo.Username))
+	tx.Unlock()
+	if home == nil {
+		return key, rangeEnd
+	}
+	return resolveHomeInterval(home, key, rangeEnd)
+}
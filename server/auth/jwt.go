@@ -19,6 +19,8 @@ import (
 	"crypto/ecdsa"
 	"crypto/rsa"
 	"errors"
+	"strconv"
+	"sync"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt"
@@ -31,13 +33,39 @@ type tokenJWT struct {
 	key        interface{}
 	ttl        time.Duration
 	verifyOnly bool
+
+	// the sessions of the tokens issued by the member and not expired, and
+	// the revocations, by ID until the tokens expire and by user, saved to
+	// the backend on apply so that the revoked tokens stay invalid after a
+	// restart. The tokens issued without an ID, to old members or
+	// internally, are not revoked.
+	mu            sync.Mutex
+	issued        map[uint64]Session
+	revoked       map[uint64]RevokedToken
+	revokedBefore map[string]uint64
 }
 
 func (t *tokenJWT) enable()                         {}
-func (t *tokenJWT) disable()                        {}
-func (t *tokenJWT) invalidateUser(string)           {}
 func (t *tokenJWT) genTokenPrefix() (string, error) { return "", nil }
 
+func (t *tokenJWT) disable() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.issued = make(map[uint64]Session)
+}
+
+func (t *tokenJWT) invalidateUser(username string) {
+	// the tokens are invalidated by the auth revision, only the sessions
+	// are forgotten
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, s := range t.issued {
+		if s.User == username {
+			delete(t.issued, id)
+		}
+	}
+}
+
 func (t *tokenJWT) info(ctx context.Context, token string, rev uint64) (*AuthInfo, bool) {
 	// rev isn't used in JWT, it is only used in simple token
 	var (
@@ -76,7 +104,34 @@ func (t *tokenJWT) info(ctx context.Context, token string, rev uint64) (*AuthInf
 	username = claims["username"].(string)
 	revision = uint64(claims["revision"].(float64))
 
-	return &AuthInfo{Username: username, Revision: revision}, true
+	if jti, ok := claims["jti"].(string); ok {
+		id, err := strconv.ParseUint(jti, 10, 64)
+		if err != nil || t.isRevoked(username, id) {
+			t.lg.Warn("revoked JWT token", zap.String("user-name", username), zap.String("jti", jti))
+			return nil, false
+		}
+	}
+
+	// the roles granted by the LDAP directory
+	var roles []string
+	if rs, ok := claims["roles"].([]interface{}); ok {
+		for _, r := range rs {
+			if s, ok := r.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+	}
+
+	return &AuthInfo{Username: username, Revision: revision, Roles: roles}, true
+}
+
+func (t *tokenJWT) isRevoked(username string, id uint64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if r, ok := t.revoked[id]; ok && (r.User == "" || r.User == username) {
+		return true
+	}
+	return id < t.revokedBefore[username]
 }
 
 func (t *tokenJWT) assign(ctx context.Context, username string, revision uint64) (string, error) {
@@ -86,12 +141,20 @@ func (t *tokenJWT) assign(ctx context.Context, username string, revision uint64)
 
 	// Future work: let a jwt token include permission information would be useful for
 	// permission checking in proxy side.
-	tk := jwt.NewWithClaims(t.signMethod,
-		jwt.MapClaims{
-			"username": username,
-			"revision": revision,
-			"exp":      time.Now().Add(t.ttl).Unix(),
-		})
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"username": username,
+		"revision": revision,
+		"exp":      now.Add(t.ttl).Unix(),
+	}
+	index, _ := ctx.Value(AuthenticateParamIndex{}).(uint64)
+	if index != 0 {
+		claims["jti"] = strconv.FormatUint(index, 10)
+	}
+	if roles, _ := ctx.Value(AuthenticateParamRoles{}).([]string); len(roles) != 0 {
+		claims["roles"] = roles
+	}
+	tk := jwt.NewWithClaims(t.signMethod, claims)
 
 	token, err := tk.SignedString(t.key)
 	if err != nil {
@@ -110,9 +173,82 @@ func (t *tokenJWT) assign(ctx context.Context, username string, revision uint64)
 		zap.Uint64("revision", revision),
 		zap.String("token", token),
 	)
+	if index != 0 {
+		addr, _ := ctx.Value(AuthenticateParamClientAddress{}).(string)
+		t.mu.Lock()
+		t.prune(now)
+		t.issued[index] = Session{ID: index, User: username, Address: addr, IssuedAt: now, ExpiresAt: time.Unix(now.Add(t.ttl).Unix(), 0)}
+		t.mu.Unlock()
+	}
 	return token, err
 }
 
+func (t *tokenJWT) sessions() []Session {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.prune(time.Now())
+	ss := make([]Session, 0, len(t.issued))
+	for _, s := range t.issued {
+		ss = append(ss, s)
+	}
+	return sortSessions(ss)
+}
+
+func (t *tokenJWT) revoke(tx AuthBatchTx, username string, id, index uint64) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.prune(now)
+	for rid, r := range tx.UnsafeGetRevokedTokens() {
+		if now.After(r.ExpiresAt) {
+			tx.UnsafeDeleteRevokedToken(rid)
+		}
+	}
+	if id == 0 {
+		t.revokedBefore[username] = index
+		tx.UnsafePutTokensRevokedBefore(username, index)
+	}
+	n := 0
+	for sid, s := range t.issued {
+		if s.matches(username, id) {
+			t.revoked[sid] = RevokedToken{ExpiresAt: s.ExpiresAt}
+			tx.UnsafePutRevokedToken(sid, t.revoked[sid])
+			delete(t.issued, sid)
+			n++
+		}
+	}
+	if id != 0 && n == 0 {
+		// the token may have been issued by another member, or before the
+		// member restarted
+		t.revoked[id] = RevokedToken{User: username, ExpiresAt: now.Add(t.ttl)}
+		tx.UnsafePutRevokedToken(id, t.revoked[id])
+	}
+	return n
+}
+
+func (t *tokenJWT) recoverRevocations(tx AuthReadTx) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.revoked = tx.UnsafeGetRevokedTokens()
+	t.revokedBefore = tx.UnsafeGetTokensRevokedBefore()
+	t.prune(time.Now())
+}
+
+// prune forgets the sessions and the revocations of the expired tokens. The
+// revocations saved to the backend are deleted by revoke.
+func (t *tokenJWT) prune(now time.Time) {
+	for id, s := range t.issued {
+		if now.After(s.ExpiresAt) {
+			delete(t.issued, id)
+		}
+	}
+	for id, r := range t.revoked {
+		if now.After(r.ExpiresAt) {
+			delete(t.revoked, id)
+		}
+	}
+}
+
 func newTokenProviderJWT(lg *zap.Logger, optMap map[string]string) (*tokenJWT, error) {
 	if lg == nil {
 		lg = zap.NewNop()
@@ -141,10 +277,13 @@ func newTokenProviderJWT(lg *zap.Logger, optMap map[string]string) (*tokenJWT, e
 	}
 
 	t := &tokenJWT{
-		lg:         lg,
-		ttl:        opts.TTL,
-		signMethod: opts.SignMethod,
-		key:        key,
+		lg:            lg,
+		ttl:           opts.TTL,
+		signMethod:    opts.SignMethod,
+		key:           key,
+		issued:        make(map[uint64]Session),
+		revoked:       make(map[uint64]RevokedToken),
+		revokedBefore: make(map[string]uint64),
 	}
 
 	switch t.signMethod.(type) {
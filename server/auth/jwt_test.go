@@ -17,7 +17,9 @@ package auth
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -202,3 +204,158 @@ func TestJWTBad(t *testing.T) {
 func testJWTOpts() string {
 	return fmt.Sprintf("%s,pub-key=%s,priv-key=%s,sign-method=RS256", tokenTypeJWT, jwtRSAPubKey, jwtRSAPrivKey)
 }
+
+func TestJWTRevoke(t *testing.T) {
+	tp, err := newTokenProviderJWT(zap.NewExample(), map[string]string{
+		"pub-key":     jwtRSAPubKey,
+		"priv-key":    jwtRSAPrivKey,
+		"sign-method": "RS256",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assign := func(user string, index uint64) string {
+		ctx := context.WithValue(context.WithValue(context.TODO(), AuthenticateParamIndex{}, index), AuthenticateParamClientAddress{}, "10.0.0.1")
+		token, aerr := tp.assign(ctx, user, 1)
+		if aerr != nil {
+			t.Fatal(aerr)
+		}
+		return token
+	}
+	valid := func(token string) bool {
+		_, ok := tp.info(context.TODO(), token, 1)
+		return ok
+	}
+
+	tx := newBackendMock().BatchTx()
+	alice1, alice2, bob := assign("alice", 10), assign("alice", 11), assign("bob", 12)
+	// the tokens issued internally have no session
+	root, err := tp.assign(context.TODO(), "root", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := tp.sessions()
+	if len(ss) != 3 || ss[0].ID != 10 || ss[0].User != "alice" || ss[0].Address != "10.0.0.1" || ss[2].ID != 12 {
+		t.Fatalf("unexpected sessions %+v", ss)
+	}
+
+	if n := tp.revoke(tx, "bob", 10, 13); n != 0 {
+		t.Fatalf("expected no session of bob revoked, got %d", n)
+	}
+	if !valid(alice1) {
+		t.Fatal("expected the token of alice to stay valid")
+	}
+	if n := tp.revoke(tx, "", 10, 13); n != 1 {
+		t.Fatalf("expected 1 session revoked, got %d", n)
+	}
+	if valid(alice1) || !valid(alice2) || !valid(bob) {
+		t.Fatal("expected only the token of the session revoked to be invalid")
+	}
+
+	if n := tp.revoke(tx, "alice", 0, 14); n != 1 {
+		t.Fatalf("expected 1 session revoked, got %d", n)
+	}
+	if valid(alice2) || !valid(bob) || !valid(root) {
+		t.Fatal("expected only the tokens of alice to be invalid")
+	}
+	if !valid(assign("alice", 15)) {
+		t.Fatal("expected the tokens issued after the revocation to be valid")
+	}
+	if ss = tp.sessions(); len(ss) != 2 {
+		t.Fatalf("expected 2 sessions, got %+v", ss)
+	}
+}
+
+// TestJWTRevokeRecover ensures the revoked tokens stay invalid once the
+// revocations are recovered, including the ones of the tokens issued by
+// another member.
+func TestJWTRevokeRecover(t *testing.T) {
+	opts := map[string]string{
+		"pub-key":     jwtRSAPubKey,
+		"priv-key":    jwtRSAPrivKey,
+		"sign-method": "RS256",
+	}
+	tp, err := newTokenProviderJWT(zap.NewExample(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assign := func(user string, index uint64) string {
+		ctx := context.WithValue(context.TODO(), AuthenticateParamIndex{}, index)
+		token, aerr := tp.assign(ctx, user, 1)
+		if aerr != nil {
+			t.Fatal(aerr)
+		}
+		return token
+	}
+	alice1, alice2, bob1, bob2, bob3 := assign("alice", 10), assign("alice", 11), assign("bob", 12), assign("bob", 14), assign("bob", 15)
+
+	be := newBackendMock()
+	// the sessions not issued by the member are revoked by another member
+	other, err := newTokenProviderJWT(zap.NewExample(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []struct {
+		tp    *tokenJWT
+		user  string
+		id    uint64
+		index uint64
+		wn    int
+	}{
+		{other, "bob", 0, 13, 0},
+		{tp, "", 10, 16, 1},
+		// not a session of bob
+		{other, "bob", 11, 17, 0},
+		{other, "", 14, 18, 0},
+		{other, "bob", 15, 19, 0},
+	} {
+		if n := r.tp.revoke(be.BatchTx(), r.user, r.id, r.index); n != r.wn {
+			t.Fatalf("revoked = %d, want %d", n, r.wn)
+		}
+	}
+	// an expired revocation is forgotten
+	be.revoked[20] = RevokedToken{ExpiresAt: time.Now().Add(-time.Minute)}
+
+	restarted, err := newTokenProviderJWT(zap.NewExample(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted.recoverRevocations(be.ReadTx())
+	for i, tt := range []struct {
+		token string
+		valid bool
+	}{
+		{alice1, false},
+		{alice2, true},
+		{bob1, false},
+		{bob2, false},
+		{bob3, false},
+	} {
+		if _, ok := restarted.info(context.TODO(), tt.token, 1); ok != tt.valid {
+			t.Errorf("#%d: valid = %t, want %t", i, ok, tt.valid)
+		}
+	}
+	if _, ok := restarted.revoked[20]; ok {
+		t.Error("expected the expired revocation to be forgotten")
+	}
+}
+
+func TestJWTRoles(t *testing.T) {
+	tp, err := newTokenProviderJWT(zap.NewExample(), map[string]string{
+		"pub-key":     jwtRSAPubKey,
+		"priv-key":    jwtRSAPrivKey,
+		"sign-method": "RS256",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.TODO(), AuthenticateParamRoles{}, []string{"admin", "dev"})
+	token, err := tp.assign(ctx, "alice", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ai, ok := tp.info(context.TODO(), token, 1)
+	if !ok || !reflect.DeepEqual(ai.Roles, []string{"admin", "dev"}) {
+		t.Fatalf("expected the roles of the token, got %+v", ai)
+	}
+}
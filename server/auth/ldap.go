@@ -0,0 +1,359 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultLDAPGroupAttribute is the attribute of the entries of the users
+	// listing their groups by default.
+	DefaultLDAPGroupAttribute = "memberOf"
+
+	// ldapRequestTimeout is the timeout of the authentication of a user by
+	// the directory.
+	ldapRequestTimeout = 10 * time.Second
+)
+
+// LDAPConfig configures the authentication of the users absent from the auth
+// store by an LDAP directory, such as Active Directory.
+type LDAPConfig struct {
+	// URL is the URL of the directory, ldap://host:port or
+	// ldaps://host:port. Empty disables the LDAP authentication.
+	URL string
+	// BindDNTemplates are the DNs the users bind as, tried in order, with
+	// "%s" replaced by the escaped user name, such as
+	// "uid=%s,ou=people,dc=example,dc=com".
+	BindDNTemplates []string
+	// GroupAttribute is the attribute of the entries of the users listing
+	// the DNs of their groups, DefaultLDAPGroupAttribute if empty.
+	GroupAttribute string
+	// RoleRules grant roles to the members of groups, each formatted as
+	// "group DN:role". The DNs are compared case-insensitively.
+	RoleRules []string
+	// CAFile is the file of the certificates of the CAs verifying the
+	// certificate of an ldaps:// directory, the system ones if empty.
+	CAFile string
+}
+
+type ldapRoleRule struct {
+	group, role string
+}
+
+func parseLDAPRoleRule(s string) (ldapRoleRule, error) {
+	i := strings.LastIndex(s, ":")
+	if i <= 0 || i == len(s)-1 {
+		return ldapRoleRule{}, fmt.Errorf("invalid LDAP role rule %q (expected \"group DN:role\")", s)
+	}
+	return ldapRoleRule{group: strings.TrimSpace(s[:i]), role: s[i+1:]}, nil
+}
+
+// LDAPAuthenticator authenticates the users by binding to an LDAP directory
+// as them, and grants them roles by the groups of their entries.
+type LDAPAuthenticator struct {
+	lg      *zap.Logger
+	cfg     LDAPConfig
+	rules   []ldapRoleRule
+	address string
+	tls     *tls.Config
+}
+
+// NewLDAPAuthenticator returns an LDAPAuthenticator of the directory of cfg.
+func NewLDAPAuthenticator(lg *zap.Logger, cfg LDAPConfig) (*LDAPAuthenticator, error) {
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.BindDNTemplates) == 0 {
+		return nil, errors.New("LDAP bind DN templates must be set")
+	}
+	for _, t := range cfg.BindDNTemplates {
+		if !strings.Contains(t, "%s") {
+			return nil, fmt.Errorf("LDAP bind DN template %q has no %%s", t)
+		}
+	}
+	if cfg.GroupAttribute == "" {
+		cfg.GroupAttribute = DefaultLDAPGroupAttribute
+	}
+	l := &LDAPAuthenticator{lg: lg, cfg: cfg, address: u.Host}
+	switch u.Scheme {
+	case "ldap":
+		if u.Port() == "" {
+			l.address = net.JoinHostPort(u.Hostname(), "389")
+		}
+		lg.Warn("LDAP directory is not accessed over TLS, the passwords are sent in plain text", zap.String("url", cfg.URL))
+	case "ldaps":
+		if u.Port() == "" {
+			l.address = net.JoinHostPort(u.Hostname(), "636")
+		}
+		l.tls = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+		if cfg.CAFile != "" {
+			pem, err := os.ReadFile(cfg.CAFile)
+			if err != nil {
+				return nil, err
+			}
+			l.tls.RootCAs = x509.NewCertPool()
+			if !l.tls.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificate in LDAP CA file %q", cfg.CAFile)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported LDAP URL %q (expected ldap:// or ldaps://)", cfg.URL)
+	}
+	for _, s := range cfg.RoleRules {
+		r, err := parseLDAPRoleRule(s)
+		if err != nil {
+			return nil, err
+		}
+		l.rules = append(l.rules, r)
+	}
+	return l, nil
+}
+
+// authenticate returns the roles granted to the user by its groups, if the
+// password is the one of the user in the directory. It returns
+// ErrAuthFailed if not, or if the user is granted no role.
+func (l *LDAPAuthenticator) authenticate(username, password string) ([]string, error) {
+	// binding with an empty password is an unauthenticated bind, which
+	// succeeds whatever the user
+	if username == "" || password == "" {
+		return nil, ErrAuthFailed
+	}
+	c, err := l.dial()
+	if err != nil {
+		l.lg.Warn("failed to connect to the LDAP directory", zap.String("url", l.cfg.URL), zap.Error(err))
+		return nil, ErrLDAPUnavailable
+	}
+	defer c.close()
+
+	var dn string
+	for _, t := range l.cfg.BindDNTemplates {
+		candidate := strings.ReplaceAll(t, "%s", escapeDN(username))
+		code, msg, err := c.bind(candidate, password)
+		if err != nil {
+			l.lg.Warn("failed to bind to the LDAP directory", zap.String("dn", candidate), zap.Error(err))
+			return nil, ErrLDAPUnavailable
+		}
+		if code == ldapResultSuccess {
+			dn = candidate
+			break
+		}
+		if code != ldapResultInvalidCredentials {
+			l.lg.Warn("LDAP bind failed", zap.String("dn", candidate), zap.Int64("result-code", code), zap.String("message", msg))
+		}
+	}
+	if dn == "" {
+		l.lg.Info("invalid LDAP credentials", zap.String("user-name", username))
+		return nil, ErrAuthFailed
+	}
+
+	groups, err := c.readAttribute(dn, l.cfg.GroupAttribute)
+	if err != nil {
+		l.lg.Warn("failed to read the groups of an LDAP user", zap.String("dn", dn), zap.Error(err))
+		return nil, ErrLDAPUnavailable
+	}
+	var roles []string
+	for _, r := range l.rules {
+		for _, g := range groups {
+			if strings.EqualFold(r.group, strings.TrimSpace(g)) {
+				roles = append(roles, r.role)
+				break
+			}
+		}
+	}
+	if len(roles) == 0 {
+		l.lg.Info("LDAP user is granted no role", zap.String("dn", dn), zap.Strings("groups", groups))
+		return nil, ErrAuthFailed
+	}
+	return uniqueSortedRoles(roles), nil
+}
+
+func (l *LDAPAuthenticator) dial() (*ldapConn, error) {
+	d := &net.Dialer{Timeout: ldapRequestTimeout}
+	var conn net.Conn
+	var err error
+	if l.tls != nil {
+		conn, err = tls.DialWithDialer(d, "tcp", l.address, l.tls)
+	} else {
+		conn, err = d.Dial("tcp", l.address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(ldapRequestTimeout))
+	return &ldapConn{conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+// ldapConn is a connection to an LDAP directory, sending one request at a
+// time.
+type ldapConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	id   int64
+}
+
+// send sends the operation, returning the id of its message.
+func (c *ldapConn) send(op *berElement) (int64, error) {
+	c.id++
+	_, err := c.conn.Write(ldapMessage(c.id, op).encode())
+	return c.id, err
+}
+
+// receive returns the operation of the next response to the message id.
+func (c *ldapConn) receive(id int64) (*berElement, error) {
+	for {
+		e, err := readBER(c.r)
+		if err != nil {
+			return nil, err
+		}
+		mid, op, err := parseLDAPMessage(e)
+		if err != nil {
+			return nil, err
+		}
+		if mid == id {
+			return op, nil
+		}
+	}
+}
+
+// bind binds as the dn, returning the result code and message.
+func (c *ldapConn) bind(dn, password string) (int64, string, error) {
+	id, err := c.send(berConstructed(ldapTagBindRequest,
+		berInt(berTagInteger, 3),
+		berString(berTagOctetString, dn),
+		berString(ldapTagSimpleAuth, password),
+	))
+	if err != nil {
+		return 0, "", err
+	}
+	op, err := c.receive(id)
+	if err != nil {
+		return 0, "", err
+	}
+	if op.tag != ldapTagBindResponse {
+		return 0, "", fmt.Errorf("unexpected response %#x to a bind request", op.tag)
+	}
+	return parseLDAPResult(op)
+}
+
+// readAttribute returns the values of the attribute of the entry of the dn.
+func (c *ldapConn) readAttribute(dn, attr string) ([]string, error) {
+	id, err := c.send(berConstructed(ldapTagSearchRequest,
+		berString(berTagOctetString, dn),
+		berInt(berTagEnumerated, 0), // baseObject
+		berInt(berTagEnumerated, 0), // neverDerefAliases
+		berInt(berTagInteger, 1),
+		berInt(berTagInteger, int64(ldapRequestTimeout/time.Second)),
+		&berElement{tag: berTagBoolean, value: []byte{0}},
+		berString(ldapTagFilterPresent, "objectClass"),
+		berConstructed(berTagSequence, berString(berTagOctetString, attr)),
+	))
+	if err != nil {
+		return nil, err
+	}
+	var vals []string
+	for {
+		op, err := c.receive(id)
+		if err != nil {
+			return nil, err
+		}
+		switch op.tag {
+		case ldapTagSearchResultEntry:
+			v, err := parseLDAPEntryAttribute(op, attr)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, v...)
+		case ldapTagSearchResultDone:
+			code, msg, err := parseLDAPResult(op)
+			if err != nil {
+				return nil, err
+			}
+			if code != ldapResultSuccess && code != ldapResultNoSuchObject {
+				return nil, fmt.Errorf("search failed with result code %d: %s", code, msg)
+			}
+			return vals, nil
+		}
+	}
+}
+
+func (c *ldapConn) close() {
+	c.send(&berElement{tag: ldapTagUnbindRequest})
+	c.conn.Close()
+}
+
+// escapeDN escapes the special characters of an attribute value of a DN, as
+// of RFC 4514, so that a user name cannot change the DN it is bound as.
+func escapeDN(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 0:
+			b.WriteString(`\00`)
+			continue
+		case strings.IndexByte(`"+,;<>\=`, c) >= 0,
+			(c == ' ' || c == '#') && i == 0,
+			c == ' ' && i == len(s)-1:
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func (as *authStore) SetLDAPAuthenticator(l *LDAPAuthenticator) {
+	as.ldap = l
+}
+
+func (as *authStore) CheckLDAPPassword(username, password string) (uint64, []string, error) {
+	if !as.IsAuthEnabled() {
+		return 0, nil, ErrAuthNotEnabled
+	}
+	if as.ldap == nil {
+		return 0, nil, ErrAuthFailed
+	}
+
+	tx := as.be.ReadTx()
+	tx.Lock()
+	user := tx.UnsafeGetUser(username)
+	revision := tx.UnsafeReadAuthRevision()
+	tx.Unlock()
+	if user != nil {
+		// the users of the auth store are not shadowed by the directory
+		return 0, nil, ErrAuthFailed
+	}
+
+	roles, err := as.ldap.authenticate(username, password)
+	if err != nil {
+		return 0, nil, err
+	}
+	return revision, roles, nil
+}
@@ -0,0 +1,221 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The subset of the BER encoding of LDAPv3 messages, RFC 4511, needed to bind
+// as a user and read the attributes of its entry.
+
+const (
+	berTagBoolean     = 0x01
+	berTagInteger     = 0x02
+	berTagOctetString = 0x04
+	berTagEnumerated  = 0x0a
+	berTagSequence    = 0x30
+	berTagSet         = 0x31
+
+	ldapTagBindRequest       = 0x60
+	ldapTagBindResponse      = 0x61
+	ldapTagUnbindRequest     = 0x42
+	ldapTagSearchRequest     = 0x63
+	ldapTagSearchResultEntry = 0x64
+	ldapTagSearchResultDone  = 0x65
+	ldapTagSimpleAuth        = 0x80
+	ldapTagFilterPresent     = 0x87
+
+	ldapResultSuccess            = 0
+	ldapResultNoSuchObject       = 32
+	ldapResultInvalidCredentials = 49
+
+	// berMaxLength is the maximum length of a message read, way more than a
+	// user entry needs.
+	berMaxLength = 1 << 20
+)
+
+// berElement is a BER encoded element, either primitive, with a value, or
+// constructed, with children.
+type berElement struct {
+	tag      byte
+	value    []byte
+	children []*berElement
+}
+
+func berConstructed(tag byte, children ...*berElement) *berElement {
+	return &berElement{tag: tag, children: children}
+}
+
+func berString(tag byte, s string) *berElement {
+	return &berElement{tag: tag, value: []byte(s)}
+}
+
+func berInt(tag byte, v int64) *berElement {
+	// the minimal two's complement big-endian encoding
+	b := []byte{byte(v)}
+	for v > 127 || v < -128 {
+		v >>= 8
+		b = append([]byte{byte(v)}, b...)
+	}
+	return &berElement{tag: tag, value: b}
+}
+
+func (e *berElement) constructed() bool {
+	return e.tag&0x20 != 0
+}
+
+func (e *berElement) int() (int64, error) {
+	if len(e.value) == 0 || len(e.value) > 8 {
+		return 0, fmt.Errorf("invalid integer of %d bytes", len(e.value))
+	}
+	v := int64(int8(e.value[0]))
+	for _, b := range e.value[1:] {
+		v = v<<8 | int64(b)
+	}
+	return v, nil
+}
+
+func (e *berElement) encode() []byte {
+	content := e.value
+	if e.constructed() {
+		content = nil
+		for _, c := range e.children {
+			content = append(content, c.encode()...)
+		}
+	}
+	b := []byte{e.tag}
+	switch n := len(content); {
+	case n < 0x80:
+		b = append(b, byte(n))
+	default:
+		var l []byte
+		for ; n > 0; n >>= 8 {
+			l = append([]byte{byte(n)}, l...)
+		}
+		b = append(append(b, 0x80|byte(len(l))), l...)
+	}
+	return append(b, content...)
+}
+
+// readBER reads an element of the definite length form, with a tag of a
+// single byte.
+func readBER(r *bufio.Reader) (*berElement, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if tag&0x1f == 0x1f {
+		return nil, fmt.Errorf("unsupported tag %#x", tag)
+	}
+	l, err := r.ReadByte()
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	n := int(l)
+	if l&0x80 != 0 {
+		if l == 0x80 || l&0x7f > 4 {
+			return nil, fmt.Errorf("unsupported length of %d bytes", l&0x7f)
+		}
+		n = 0
+		for i := 0; i < int(l&0x7f); i++ {
+			b, err := r.ReadByte()
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			n = n<<8 | int(b)
+		}
+	}
+	if n > berMaxLength {
+		return nil, fmt.Errorf("message of %d bytes too large", n)
+	}
+	content := make([]byte, n)
+	if _, err = io.ReadFull(r, content); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	e := &berElement{tag: tag, value: content}
+	if e.constructed() {
+		cr := bufio.NewReader(bytes.NewReader(content))
+		for {
+			c, err := readBER(cr)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			e.children = append(e.children, c)
+		}
+		e.value = nil
+	}
+	return e, nil
+}
+
+// unexpectedEOF returns io.ErrUnexpectedEOF for io.EOF, the end of the
+// input in the middle of an element.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// ldapMessage returns an LDAPMessage of the id and the protocol operation.
+func ldapMessage(id int64, op *berElement) *berElement {
+	return berConstructed(berTagSequence, berInt(berTagInteger, id), op)
+}
+
+// parseLDAPMessage returns the id and the protocol operation of an
+// LDAPMessage.
+func parseLDAPMessage(e *berElement) (int64, *berElement, error) {
+	if e.tag != berTagSequence || len(e.children) < 2 || e.children[0].tag != berTagInteger {
+		return 0, nil, errors.New("invalid LDAP message")
+	}
+	id, err := e.children[0].int()
+	return id, e.children[1], err
+}
+
+// parseLDAPResult returns the result code and the diagnostic message of an
+// LDAPResult.
+func parseLDAPResult(op *berElement) (int64, string, error) {
+	if len(op.children) < 3 || op.children[0].tag != berTagEnumerated {
+		return 0, "", errors.New("invalid LDAP result")
+	}
+	code, err := op.children[0].int()
+	return code, string(op.children[2].value), err
+}
+
+// parseLDAPEntryAttribute returns the values of the attribute of a
+// SearchResultEntry.
+func parseLDAPEntryAttribute(op *berElement, attr string) ([]string, error) {
+	if len(op.children) < 2 || op.children[1].tag != berTagSequence {
+		return nil, errors.New("invalid LDAP search result entry")
+	}
+	var vals []string
+	for _, a := range op.children[1].children {
+		if len(a.children) < 2 || !strings.EqualFold(string(a.children[0].value), attr) {
+			continue
+		}
+		for _, v := range a.children[1].children {
+			vals = append(vals, string(v.value))
+		}
+	}
+	return vals, nil
+}
@@ -0,0 +1,216 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type fakeLDAPEntry struct {
+	password string
+	groups   []string
+}
+
+// newFakeLDAPServer serves the binds as and the reads of the entries, by DN,
+// returning the URL of the server.
+func newFakeLDAPServer(t *testing.T, entries map[string]fakeLDAPEntry) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeLDAP(conn, entries)
+		}
+	}()
+	return "ldap://" + ln.Addr().String()
+}
+
+func serveFakeLDAP(conn net.Conn, entries map[string]fakeLDAPEntry) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(id int64, op *berElement) {
+		conn.Write(ldapMessage(id, op).encode())
+	}
+	result := func(tag byte, code int64) *berElement {
+		return berConstructed(tag, berInt(berTagEnumerated, code), berString(berTagOctetString, ""), berString(berTagOctetString, ""))
+	}
+	for {
+		e, err := readBER(r)
+		if err != nil {
+			return
+		}
+		id, op, err := parseLDAPMessage(e)
+		if err != nil {
+			return
+		}
+		switch op.tag {
+		case ldapTagBindRequest:
+			entry, ok := entries[string(op.children[1].value)]
+			if ok && entry.password == string(op.children[2].value) {
+				reply(id, result(ldapTagBindResponse, ldapResultSuccess))
+			} else {
+				reply(id, result(ldapTagBindResponse, ldapResultInvalidCredentials))
+			}
+		case ldapTagSearchRequest:
+			dn := string(op.children[0].value)
+			entry, ok := entries[dn]
+			if !ok {
+				reply(id, result(ldapTagSearchResultDone, ldapResultNoSuchObject))
+				continue
+			}
+			attr := string(op.children[7].children[0].value)
+			var vals []*berElement
+			for _, g := range entry.groups {
+				vals = append(vals, berString(berTagOctetString, g))
+			}
+			reply(id, berConstructed(ldapTagSearchResultEntry,
+				berString(berTagOctetString, dn),
+				berConstructed(berTagSequence, berConstructed(berTagSequence,
+					berString(berTagOctetString, attr),
+					berConstructed(berTagSet, vals...),
+				)),
+			))
+			reply(id, result(ldapTagSearchResultDone, ldapResultSuccess))
+		case ldapTagUnbindRequest:
+			return
+		}
+	}
+}
+
+func newTestLDAPAuthenticator(t *testing.T) *LDAPAuthenticator {
+	url := newFakeLDAPServer(t, map[string]fakeLDAPEntry{
+		"uid=alice,ou=people,dc=example,dc=com": {password: "secret", groups: []string{"cn=admins,ou=groups,dc=example,dc=com", "cn=other,ou=groups,dc=example,dc=com"}},
+		"uid=bob,ou=staff,dc=example,dc=com":    {password: "secret", groups: []string{"cn=devs,ou=groups,dc=example,dc=com"}},
+		"uid=carol,ou=people,dc=example,dc=com": {password: "secret", groups: []string{"cn=other,ou=groups,dc=example,dc=com"}},
+	})
+	l, err := NewLDAPAuthenticator(zaptest.NewLogger(t), LDAPConfig{
+		URL:             url,
+		BindDNTemplates: []string{"uid=%s,ou=people,dc=example,dc=com", "uid=%s,ou=staff,dc=example,dc=com"},
+		RoleRules: []string{
+			"CN=Admins,OU=Groups,DC=example,DC=com:admin",
+			"cn=devs,ou=groups,dc=example,dc=com:dev",
+			"cn=admins,ou=groups,dc=example,dc=com:dev",
+		},
+	})
+	require.NoError(t, err)
+	return l
+}
+
+func TestLDAPAuthenticate(t *testing.T) {
+	l := newTestLDAPAuthenticator(t)
+
+	tests := []struct {
+		username, password string
+		roles              []string
+		err                error
+	}{
+		{"alice", "secret", []string{"admin", "dev"}, nil},
+		// the second template
+		{"bob", "secret", []string{"dev"}, nil},
+		{"alice", "wrong", nil, ErrAuthFailed},
+		{"alice", "", nil, ErrAuthFailed},
+		{"dave", "secret", nil, ErrAuthFailed},
+		// no group granting a role
+		{"carol", "secret", nil, ErrAuthFailed},
+		// the user name cannot change the DN bound as
+		{"alice,ou=people", "secret", nil, ErrAuthFailed},
+	}
+	for _, tt := range tests {
+		roles, err := l.authenticate(tt.username, tt.password)
+		assert.Equal(t, tt.err, err, tt.username)
+		assert.Equal(t, tt.roles, roles, tt.username)
+	}
+}
+
+func TestLDAPUnavailable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "ldap://" + ln.Addr().String()
+	ln.Close()
+
+	l, err := NewLDAPAuthenticator(zaptest.NewLogger(t), LDAPConfig{URL: url, BindDNTemplates: []string{"uid=%s"}})
+	require.NoError(t, err)
+	_, err = l.authenticate("alice", "secret")
+	assert.Equal(t, ErrLDAPUnavailable, err)
+}
+
+func TestNewLDAPAuthenticatorInvalid(t *testing.T) {
+	for _, cfg := range []LDAPConfig{
+		{URL: "http://example.com", BindDNTemplates: []string{"uid=%s"}},
+		{URL: "ldap://example.com"},
+		{URL: "ldap://example.com", BindDNTemplates: []string{"uid=alice"}},
+		{URL: "ldap://example.com", BindDNTemplates: []string{"uid=%s"}, RoleRules: []string{"cn=admins"}},
+	} {
+		_, err := NewLDAPAuthenticator(zaptest.NewLogger(t), cfg)
+		assert.Error(t, err, cfg)
+	}
+}
+
+func TestEscapeDN(t *testing.T) {
+	tests := map[string]string{
+		"alice":     "alice",
+		"a,b+c=d":   `a\,b\+c\=d`,
+		`"<x>";\`:   `\"\<x\>\"\;\\`,
+		" #alice ":  `\ #alice\ `,
+		"#alice":    `\#alice`,
+		"al\x00ice": `al\00ice`,
+		"ünïcode":   "ünïcode",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, escapeDN(in), in)
+	}
+}
+
+func TestCheckLDAPPassword(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, _, err := as.CheckLDAPPassword("alice", "secret")
+	assert.Equal(t, ErrAuthFailed, err)
+
+	as.SetLDAPAuthenticator(newTestLDAPAuthenticator(t))
+	rev, roles, err := as.CheckLDAPPassword("alice", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, as.Revision(), rev)
+	assert.Equal(t, []string{"admin", "dev"}, roles)
+
+	// the users of the auth store are not shadowed by the directory
+	_, _, err = as.CheckLDAPPassword("foo", "bar")
+	assert.Equal(t, ErrAuthFailed, err)
+
+	ctx := context.WithValue(context.WithValue(context.TODO(), AuthenticateParamIndex{}, uint64(1)), AuthenticateParamSimpleTokenPrefix{}, "dummy")
+	_, err = as.Authenticate(ctx, "alice", "")
+	assert.Equal(t, ErrAuthFailed, err)
+	resp, err := as.Authenticate(context.WithValue(ctx, AuthenticateParamRoles{}, roles), "alice", "")
+	require.NoError(t, err)
+	ai, ok := as.tokenProvider.info(context.TODO(), resp.Token, as.Revision())
+	require.True(t, ok)
+	assert.Equal(t, &AuthInfo{Username: "alice", Revision: as.Revision(), Roles: roles}, ai)
+
+	ctx = context.WithValue(ctx, AuthenticateParamIndex{}, uint64(2))
+	_, err = as.Authenticate(context.WithValue(ctx, AuthenticateParamRoles{}, roles), "foo", "")
+	assert.Equal(t, ErrAuthFailed, err)
+}
@@ -0,0 +1,227 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// lockoutPruneInterval is the minimum interval between removing the failures
+// no longer counted, so that failing with many user names or addresses does
+// not grow the tracker unbounded.
+const lockoutPruneInterval = time.Minute
+
+// LockoutPolicy locks out the users and the client addresses failing to
+// authenticate too many times in a row.
+type LockoutPolicy struct {
+	// Threshold is the number of failures in a row locking out a user or an
+	// address. Zero disables the lockouts.
+	Threshold int
+	// Duration is the duration of the first lockout. Each following lockout,
+	// after Threshold more failures, doubles it.
+	Duration time.Duration
+	// MaxDuration caps the duration of the lockouts. The failures are
+	// forgotten after MaxDuration without any.
+	MaxDuration time.Duration
+}
+
+// Enabled returns whether the policy locks out anything.
+func (p LockoutPolicy) Enabled() bool {
+	return p.Threshold > 0 && p.Duration > 0
+}
+
+// Lockout is a user or a client address locked out of authenticating.
+type Lockout struct {
+	// User is the locked out user, empty if Address is locked out.
+	User    string
+	Address string
+	// Failures is the number of failures in a row.
+	Failures int
+	Until    time.Time
+}
+
+type lockoutEntry struct {
+	failures int
+	until    time.Time
+	last     time.Time
+}
+
+// LockoutTracker tracks the failed authentications on a member, locking out
+// the users and the addresses as of its LockoutPolicy.
+type LockoutTracker struct {
+	policy LockoutPolicy
+	now    func() time.Time
+
+	mu        sync.Mutex
+	users     map[string]*lockoutEntry
+	addrs     map[string]*lockoutEntry
+	lastPrune time.Time
+}
+
+func NewLockoutTracker(p LockoutPolicy) *LockoutTracker {
+	if p.MaxDuration < p.Duration {
+		p.MaxDuration = p.Duration
+	}
+	return &LockoutTracker{
+		policy: p,
+		now:    time.Now,
+		users:  make(map[string]*lockoutEntry),
+		addrs:  make(map[string]*lockoutEntry),
+	}
+}
+
+// Check returns ErrAuthLockedOut if the user or the address is locked out.
+func (t *LockoutTracker) Check(user, addr string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	if e, ok := t.users[user]; ok && now.Before(e.until) {
+		return ErrAuthLockedOut
+	}
+	if e, ok := t.addrs[addr]; ok && addr != "" && now.Before(e.until) {
+		return ErrAuthLockedOut
+	}
+	return nil
+}
+
+// Fail records a failed authentication of the user from the address, and
+// returns the lockouts it starts.
+func (t *LockoutTracker) Fail(user, addr string) []Lockout {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	if now.Sub(t.lastPrune) >= lockoutPruneInterval {
+		t.prune(now)
+	}
+
+	authFailures.Inc()
+	var lockouts []Lockout
+	if l, ok := t.fail(t.users, user, now); ok {
+		lockoutsTotal.WithLabelValues("user").Inc()
+		l.User = user
+		lockouts = append(lockouts, l)
+	}
+	if addr == "" {
+		return lockouts
+	}
+	if l, ok := t.fail(t.addrs, addr, now); ok {
+		lockoutsTotal.WithLabelValues("address").Inc()
+		l.Address = addr
+		lockouts = append(lockouts, l)
+	}
+	return lockouts
+}
+
+func (t *LockoutTracker) fail(entries map[string]*lockoutEntry, k string, now time.Time) (Lockout, bool) {
+	e, ok := entries[k]
+	if !ok || t.expired(e, now) {
+		e = &lockoutEntry{}
+		entries[k] = e
+	}
+	e.failures++
+	e.last = now
+	if e.failures%t.policy.Threshold != 0 {
+		return Lockout{}, false
+	}
+	d := t.policy.Duration
+	for i := 1; i < e.failures/t.policy.Threshold && d < t.policy.MaxDuration; i++ {
+		d *= 2
+	}
+	if d > t.policy.MaxDuration {
+		d = t.policy.MaxDuration
+	}
+	e.until = now.Add(d)
+	return Lockout{Failures: e.failures, Until: e.until}, true
+}
+
+// expired returns whether the failures of the entry are no longer counted.
+func (t *LockoutTracker) expired(e *lockoutEntry, now time.Time) bool {
+	return !now.Before(e.until) && now.Sub(e.last) >= t.policy.MaxDuration
+}
+
+func (t *LockoutTracker) prune(now time.Time) {
+	for _, entries := range []map[string]*lockoutEntry{t.users, t.addrs} {
+		for k, e := range entries {
+			if t.expired(e, now) {
+				delete(entries, k)
+			}
+		}
+	}
+	t.lastPrune = now
+}
+
+// Succeed forgets the failures of the user. The failures of the address are
+// kept, lest a valid user be used to reset them.
+func (t *LockoutTracker) Succeed(user string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.users, user)
+}
+
+// List returns the current lockouts, the users first.
+func (t *LockoutTracker) List() []Lockout {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	var users, addrs []Lockout
+	for k, e := range t.users {
+		if now.Before(e.until) {
+			users = append(users, Lockout{User: k, Failures: e.failures, Until: e.until})
+		}
+	}
+	for k, e := range t.addrs {
+		if now.Before(e.until) {
+			addrs = append(addrs, Lockout{Address: k, Failures: e.failures, Until: e.until})
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].User < users[j].User })
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Address < addrs[j].Address })
+	return append(users, addrs...)
+}
+
+// Clear lifts the lockout and forgets the failures of the user and of the
+// address, or of all of them if both are empty. It returns the number of
+// lockouts lifted.
+func (t *LockoutTracker) Clear(user, addr string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	n := 0
+	lift := func(entries map[string]*lockoutEntry, k string) {
+		if e, ok := entries[k]; ok {
+			if now.Before(e.until) {
+				n++
+			}
+			delete(entries, k)
+		}
+	}
+	if user == "" && addr == "" {
+		for _, entries := range []map[string]*lockoutEntry{t.users, t.addrs} {
+			for k := range entries {
+				lift(entries, k)
+			}
+		}
+		return n
+	}
+	if user != "" {
+		lift(t.users, user)
+	}
+	if addr != "" {
+		lift(t.addrs, addr)
+	}
+	return n
+}
@@ -0,0 +1,92 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLockoutTracker(p LockoutPolicy) (*LockoutTracker, *time.Time) {
+	now := time.Unix(1000, 0)
+	t := NewLockoutTracker(p)
+	t.now = func() time.Time { return now }
+	return t, &now
+}
+
+func TestLockoutTracker(t *testing.T) {
+	tr, now := newTestLockoutTracker(LockoutPolicy{Threshold: 3, Duration: time.Second, MaxDuration: 3 * time.Second})
+
+	for i := 0; i < 2; i++ {
+		assert.Empty(t, tr.Fail("alice", "10.0.0.1"))
+	}
+	assert.NoError(t, tr.Check("alice", "10.0.0.1"))
+
+	start := *now
+	lockouts := tr.Fail("alice", "10.0.0.1")
+	assert.Equal(t, []Lockout{
+		{User: "alice", Failures: 3, Until: start.Add(time.Second)},
+		{Address: "10.0.0.1", Failures: 3, Until: start.Add(time.Second)},
+	}, lockouts)
+	assert.Equal(t, ErrAuthLockedOut, tr.Check("alice", ""))
+	assert.Equal(t, ErrAuthLockedOut, tr.Check("bob", "10.0.0.1"))
+	assert.NoError(t, tr.Check("bob", "10.0.0.2"))
+	assert.Equal(t, lockouts, tr.List())
+
+	// the following lockouts double, up to the maximum duration
+	*now = now.Add(time.Second)
+	assert.NoError(t, tr.Check("alice", "10.0.0.2"))
+	for i := 0; i < 2; i++ {
+		assert.Empty(t, tr.Fail("alice", ""))
+	}
+	assert.Equal(t, []Lockout{{User: "alice", Failures: 6, Until: now.Add(2 * time.Second)}}, tr.Fail("alice", ""))
+	*now = now.Add(2 * time.Second)
+	tr.Fail("alice", "")
+	tr.Fail("alice", "")
+	assert.Equal(t, []Lockout{{User: "alice", Failures: 9, Until: now.Add(3 * time.Second)}}, tr.Fail("alice", ""))
+
+	// a success forgets the failures of the user, not of the address
+	*now = now.Add(3 * time.Second)
+	tr.Succeed("alice")
+	assert.Empty(t, tr.Fail("alice", "10.0.0.2"))
+	assert.Empty(t, tr.Fail("alice", "10.0.0.2"))
+	assert.Len(t, tr.Fail("carol", "10.0.0.2"), 1)
+
+	// the failures are forgotten after the maximum duration without any
+	*now = now.Add(time.Hour)
+	tr.Fail("dave", "")
+	tr.Fail("dave", "")
+	*now = now.Add(3 * time.Second)
+	assert.Empty(t, tr.Fail("dave", ""))
+}
+
+func TestLockoutTrackerClear(t *testing.T) {
+	tr, _ := newTestLockoutTracker(LockoutPolicy{Threshold: 1, Duration: time.Minute})
+
+	tr.Fail("alice", "10.0.0.1")
+	tr.Fail("bob", "10.0.0.2")
+	assert.Len(t, tr.List(), 4)
+
+	assert.Equal(t, 1, tr.Clear("alice", ""))
+	assert.NoError(t, tr.Check("alice", ""))
+	assert.Equal(t, ErrAuthLockedOut, tr.Check("alice", "10.0.0.1"))
+	assert.Equal(t, 0, tr.Clear("alice", ""))
+
+	assert.Equal(t, 3, tr.Clear("", ""))
+	assert.Empty(t, tr.List())
+	assert.NoError(t, tr.Check("bob", "10.0.0.1"))
+}
@@ -36,8 +36,25 @@ var (
 	// overridden by auth store initialization
 	reportCurrentAuthRevMu sync.RWMutex
 	reportCurrentAuthRev   = func() float64 { return 0 }
+
+	authFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "auth",
+		Name:      "authentication_failures_total",
+		Help:      "The total number of failed authentications tracked for lockouts.",
+	})
+	lockoutsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "auth",
+		Name:      "lockouts_total",
+		Help:      "The total number of lockouts of users and client addresses failing to authenticate.",
+	},
+		[]string{"kind"},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(currentAuthRevision)
+	prometheus.MustRegister(authFailures)
+	prometheus.MustRegister(lockoutsTotal)
 }
@@ -20,10 +20,13 @@ import (
 
 type tokenNop struct{}
 
-func (t *tokenNop) enable()                         {}
-func (t *tokenNop) disable()                        {}
-func (t *tokenNop) invalidateUser(string)           {}
-func (t *tokenNop) genTokenPrefix() (string, error) { return "", nil }
+func (t *tokenNop) enable()                                        {}
+func (t *tokenNop) disable()                                       {}
+func (t *tokenNop) invalidateUser(string)                          {}
+func (t *tokenNop) genTokenPrefix() (string, error)                { return "", nil }
+func (t *tokenNop) sessions() []Session                            { return nil }
+func (t *tokenNop) revoke(AuthBatchTx, string, uint64, uint64) int { return 0 }
+func (t *tokenNop) recoverRevocations(AuthReadTx)                  {}
 func (t *tokenNop) info(ctx context.Context, token string, rev uint64) (*AuthInfo, bool) {
 	return nil, false
 }
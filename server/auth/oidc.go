@@ -0,0 +1,347 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultOIDCUsernameClaim is the claim of the user name of an OIDC ID
+	// token by default.
+	DefaultOIDCUsernameClaim = "sub"
+	// DefaultOIDCUsernamePrefix is the prefix of the user names of the OIDC
+	// ID tokens by default.
+	DefaultOIDCUsernamePrefix = "oidc:"
+
+	// oidcKeysTTL is the duration the signing keys of the issuer are cached.
+	oidcKeysTTL = time.Hour
+	// oidcMinRefreshInterval is the minimum interval between two fetches of
+	// the signing keys, on tokens signed by unknown keys.
+	oidcMinRefreshInterval = 10 * time.Second
+	// oidcRequestTimeout is the timeout of the requests to the issuer.
+	oidcRequestTimeout = 10 * time.Second
+)
+
+// OIDCConfig configures the authentication of the users by the ID tokens of
+// an OpenID Connect issuer.
+type OIDCConfig struct {
+	// IssuerURL is the URL of the issuer, whose configuration is discovered
+	// at IssuerURL/.well-known/openid-configuration. The tokens are only
+	// accepted with IssuerURL as issuer. Empty disables the OIDC tokens.
+	IssuerURL string
+	// ClientID is the audience the tokens must be issued for.
+	ClientID string
+	// UsernameClaim is the claim of the user name, DefaultOIDCUsernameClaim
+	// if empty.
+	UsernameClaim string
+	// UsernamePrefix is prepended to the user names of the tokens, so that
+	// they do not clash with the users of etcd, e.g. a token of subject root
+	// is not the root user. It must not be empty.
+	UsernamePrefix string
+	// RoleRules grant roles to the users whose tokens have a claim of a given
+	// value, each formatted as "claim=value:role". A claim holding a list of
+	// strings matches if any of them is the value.
+	RoleRules []string
+}
+
+type oidcRoleRule struct {
+	claim, value, role string
+}
+
+func parseOIDCRoleRule(s string) (oidcRoleRule, error) {
+	i, j := strings.Index(s, "="), strings.LastIndex(s, ":")
+	if i <= 0 || j < i || j == len(s)-1 {
+		return oidcRoleRule{}, fmt.Errorf("invalid OIDC role rule %q (expected \"claim=value:role\")", s)
+	}
+	return oidcRoleRule{claim: s[:i], value: s[i+1 : j], role: s[j+1:]}, nil
+}
+
+// matches returns whether the claims grant the role of the rule.
+func (r oidcRoleRule) matches(claims jwt.MapClaims) bool {
+	switch v := claims[r.claim].(type) {
+	case string:
+		return v == r.value
+	case []interface{}:
+		for _, e := range v {
+			if s, ok := e.(string); ok && s == r.value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tokenOIDC authenticates the users by the ID tokens of an OIDC issuer,
+// leaving the other tokens to the provider of the tokens issued by etcd.
+type tokenOIDC struct {
+	TokenProvider
+
+	lg     *zap.Logger
+	cfg    OIDCConfig
+	rules  []oidcRoleRule
+	client *http.Client
+
+	mu sync.Mutex
+	// keys are the signing keys of the issuer by ID, fetched at fetched.
+	keys    map[string]interface{}
+	fetched time.Time
+}
+
+// NewTokenProviderOIDC returns a TokenProvider accepting the ID tokens of the
+// OIDC issuer of cfg, and the tokens of tp.
+func NewTokenProviderOIDC(lg *zap.Logger, tp TokenProvider, cfg OIDCConfig) (TokenProvider, error) {
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	if cfg.IssuerURL == "" || cfg.ClientID == "" {
+		return nil, errors.New("OIDC issuer URL and client ID must be set")
+	}
+	if cfg.UsernamePrefix == "" {
+		return nil, errors.New("OIDC username prefix must be set")
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = DefaultOIDCUsernameClaim
+	}
+	t := &tokenOIDC{
+		TokenProvider: tp,
+		lg:            lg,
+		cfg:           cfg,
+		client:        &http.Client{Timeout: oidcRequestTimeout},
+	}
+	for _, s := range cfg.RoleRules {
+		r, err := parseOIDCRoleRule(s)
+		if err != nil {
+			return nil, err
+		}
+		t.rules = append(t.rules, r)
+	}
+	return t, nil
+}
+
+func (t *tokenOIDC) info(ctx context.Context, token string, rev uint64) (*AuthInfo, bool) {
+	raw := strings.TrimPrefix(token, "Bearer ")
+	if !t.issued(raw) {
+		return t.TokenProvider.info(ctx, token, rev)
+	}
+
+	parsed, err := jwt.Parse(raw, t.key)
+	if err != nil {
+		t.lg.Warn("failed to parse an OIDC token", zap.Error(err))
+		return nil, false
+	}
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !parsed.Valid || !ok {
+		t.lg.Warn("failed to obtain claims from an OIDC token")
+		return nil, false
+	}
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) || !claims.VerifyAudience(t.cfg.ClientID, true) {
+		t.lg.Warn("OIDC token is expired or not issued for etcd", zap.String("client-id", t.cfg.ClientID))
+		return nil, false
+	}
+	username, _ := claims[t.cfg.UsernameClaim].(string)
+	if username == "" {
+		t.lg.Warn("OIDC token has no user name", zap.String("claim", t.cfg.UsernameClaim))
+		return nil, false
+	}
+
+	var roles []string
+	for _, r := range t.rules {
+		if r.matches(claims) {
+			roles = append(roles, r.role)
+		}
+	}
+	return &AuthInfo{Username: t.cfg.UsernamePrefix + username, Revision: rev, Roles: uniqueSortedRoles(roles)}, true
+}
+
+// issued returns whether the token is a JWT of the issuer.
+func (t *tokenOIDC) issued(token string) bool {
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, claims); err != nil {
+		return false
+	}
+	return claims.VerifyIssuer(t.cfg.IssuerURL, true)
+}
+
+// key returns the public key of the issuer the token is signed with.
+func (t *tokenOIDC) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+	default:
+		return nil, fmt.Errorf("unsupported signing method %q", token.Method.Alg())
+	}
+	kid, _ := token.Header["kid"].(string)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	k, ok := t.keys[kid]
+	since := time.Since(t.fetched)
+	if since > oidcKeysTTL || (!ok && since > oidcMinRefreshInterval) {
+		keys, err := t.fetchKeys()
+		if err != nil {
+			t.lg.Warn("failed to fetch the OIDC signing keys", zap.String("issuer", t.cfg.IssuerURL), zap.Error(err))
+		} else {
+			t.keys, t.fetched = keys, time.Now()
+			k, ok = t.keys[kid]
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return k, nil
+}
+
+// fetchKeys discovers the signing keys of the issuer.
+func (t *tokenOIDC) fetchKeys() (map[string]interface{}, error) {
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := t.getJSON(strings.TrimSuffix(t.cfg.IssuerURL, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, err
+	}
+	if discovery.Issuer != t.cfg.IssuerURL {
+		return nil, fmt.Errorf("discovered issuer %q, expected %q", discovery.Issuer, t.cfg.IssuerURL)
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := t.getJSON(discovery.JWKSURI, &jwks); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]interface{})
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		k, err := jwk.publicKey()
+		if err != nil {
+			t.lg.Warn("ignored an invalid OIDC signing key", zap.String("kid", jwk.Kid), zap.Error(err))
+			continue
+		}
+		keys[jwk.Kid] = k
+	}
+	return keys, nil
+}
+
+func (t *tokenOIDC) getJSON(url string, v interface{}) error {
+	resp, err := t.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// jsonWebKey is a public key of a JSON Web Key Set, RFC 7517.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// N and E are the modulus and exponent of an RSA key.
+	N string `json:"n"`
+	E string `json:"e"`
+	// Crv, X and Y are the curve and coordinates of an EC key.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeJWKInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func uniqueSortedRoles(roles []string) []string {
+	if len(roles) == 0 {
+		return nil
+	}
+	sort.Strings(roles)
+	n := 1
+	for _, r := range roles[1:] {
+		if r != roles[n-1] {
+			roles[n] = r
+			n++
+		}
+	}
+	return roles[:n]
+}
@@ -0,0 +1,209 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt"
+	"go.uber.org/zap/zaptest"
+)
+
+const testOIDCKeyID = "test-key"
+
+// newTestOIDCIssuer serves the discovery document and the signing key of an
+// OIDC issuer.
+func newTestOIDCIssuer(t *testing.T, key *rsa.PrivateKey) *httptest.Server {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": srv.URL, "jwks_uri": srv.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string][]jsonWebKey{"keys": {{
+			Kty: "RSA",
+			Kid: testOIDCKeyID,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+type tokenStub struct {
+	tokenNop
+	token string
+}
+
+func (t *tokenStub) info(ctx context.Context, token string, rev uint64) (*AuthInfo, bool) {
+	if token != t.token {
+		return nil, false
+	}
+	return &AuthInfo{Username: "foo", Revision: rev}, true
+}
+
+func TestOIDCInfo(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestOIDCIssuer(t, key)
+
+	tp, err := NewTokenProviderOIDC(zaptest.NewLogger(t), &tokenStub{token: "simple"}, OIDCConfig{
+		IssuerURL:      srv.URL,
+		ClientID:       "etcd",
+		UsernamePrefix: "oidc:",
+		RoleRules:      []string{"groups=etcd-admins:root", "groups=dev:role-dev", "team=dev:role-dev"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(method jwt.SigningMethod, k interface{}, claims jwt.MapClaims) string {
+		tok := jwt.NewWithClaims(method, claims)
+		tok.Header["kid"] = testOIDCKeyID
+		s, err := tok.SignedString(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	claims := func(aud string, exp time.Duration) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":    srv.URL,
+			"aud":    aud,
+			"sub":    "alice",
+			"exp":    time.Now().Add(exp).Unix(),
+			"groups": []string{"dev", "etcd-admins"},
+			"team":   "dev",
+		}
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  *AuthInfo
+	}{
+		{
+			name:  "valid",
+			token: sign(jwt.SigningMethodRS256, key, claims("etcd", time.Hour)),
+			want:  &AuthInfo{Username: "oidc:alice", Revision: 3, Roles: []string{"role-dev", "root"}},
+		},
+		{
+			name:  "bearer",
+			token: "Bearer " + sign(jwt.SigningMethodRS256, key, claims("etcd", time.Hour)),
+			want:  &AuthInfo{Username: "oidc:alice", Revision: 3, Roles: []string{"role-dev", "root"}},
+		},
+		{
+			name:  "wrong audience",
+			token: sign(jwt.SigningMethodRS256, key, claims("other", time.Hour)),
+		},
+		{
+			name:  "expired",
+			token: sign(jwt.SigningMethodRS256, key, claims("etcd", -time.Hour)),
+		},
+		{
+			name:  "HMAC signed",
+			token: sign(jwt.SigningMethodHS256, []byte("secret"), claims("etcd", time.Hour)),
+		},
+		{
+			name:  "etcd token",
+			token: "simple",
+			want:  &AuthInfo{Username: "foo", Revision: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ai, ok := tp.info(context.TODO(), tt.token, 3)
+			if ok != (tt.want != nil) {
+				t.Fatalf("expected ok %v, got %v", tt.want != nil, ok)
+			}
+			if !reflect.DeepEqual(ai, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, ai)
+			}
+		})
+	}
+}
+
+// TestOIDCRootSubject ensures a token of subject root is not the root user.
+func TestOIDCRootSubject(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestOIDCIssuer(t, key)
+
+	cfg := OIDCConfig{IssuerURL: srv.URL, ClientID: "etcd"}
+	if _, err = NewTokenProviderOIDC(zaptest.NewLogger(t), &tokenStub{}, cfg); err == nil {
+		t.Fatal("expected an error without username prefix")
+	}
+
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+	cfg.UsernamePrefix = DefaultOIDCUsernamePrefix
+	tp, err := NewTokenProviderOIDC(zaptest.NewLogger(t), as.tokenProvider, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": srv.URL,
+		"aud": "etcd",
+		"sub": "root",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	tok.Header["kid"] = testOIDCKeyID
+	signed, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ai, ok := tp.info(context.TODO(), signed, as.Revision())
+	if !ok {
+		t.Fatal("expected the token to be valid")
+	}
+	if ai.Username != "oidc:root" {
+		t.Fatalf("expected user name %q, got %q", "oidc:root", ai.Username)
+	}
+	if err = as.IsAdminPermitted(ai); err != ErrUserNotFound {
+		t.Fatalf("expected %v, got %v", ErrUserNotFound, err)
+	}
+}
+
+func TestParseOIDCRoleRule(t *testing.T) {
+	r, err := parseOIDCRoleRule("groups=team:a:role")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (oidcRoleRule{claim: "groups", value: "team:a", role: "role"}); r != want {
+		t.Fatalf("expected %+v, got %+v", want, r)
+	}
+	for _, s := range []string{"", "groups", "=team:role", "groups=team", "groups=team:"} {
+		if _, err := parseOIDCRoleRule(s); err == nil {
+			t.Errorf("expected error parsing %q", s)
+		}
+	}
+}
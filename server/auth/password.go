@@ -0,0 +1,184 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"time"
+	"unicode"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	PasswordHashBcrypt   = "bcrypt"
+	PasswordHashArgon2id = "argon2id"
+
+	// The default argon2id parameters, as recommended by RFC 9106 for
+	// memory constrained environments.
+	DefaultArgon2Time    = 3
+	DefaultArgon2Memory  = 64 * 1024
+	DefaultArgon2Threads = 4
+
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+// PasswordPolicy configures how the passwords are hashed, and the passwords
+// the users may set.
+type PasswordPolicy struct {
+	// Hash is the algorithm hashing the passwords, PasswordHashBcrypt, with
+	// the bcrypt cost of the auth store, or PasswordHashArgon2id. The
+	// passwords hashed otherwise are rehashed when the users authenticate.
+	Hash string
+	// Argon2Time, Argon2Memory, in KiB, and Argon2Threads are the parameters
+	// of argon2id.
+	Argon2Time    uint32
+	Argon2Memory  uint32
+	Argon2Threads uint8
+
+	// MinLength is the minimum number of characters of the passwords.
+	MinLength int
+	// MinClasses is the minimum number of the classes of characters, among
+	// lower case letters, upper case letters, digits and others, of the
+	// passwords.
+	MinClasses int
+	// MaxAge, if positive, is the age after which the password of a user
+	// other than root expires, and must be changed by an admin.
+	MaxAge time.Duration
+}
+
+// ValidatePasswordHash checks the hash algorithm and parameters of the
+// policy.
+func (p PasswordPolicy) ValidatePasswordHash() error {
+	switch p.Hash {
+	case "", PasswordHashBcrypt:
+		return nil
+	case PasswordHashArgon2id:
+		if p.Argon2Time == 0 || p.Argon2Memory == 0 || p.Argon2Threads == 0 {
+			return fmt.Errorf("argon2id time[%d], memory[%d] and threads[%d] should be positive", p.Argon2Time, p.Argon2Memory, p.Argon2Threads)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown password hash %q (expected %q or %q)", p.Hash, PasswordHashBcrypt, PasswordHashArgon2id)
+	}
+}
+
+// checkPassword returns ErrPasswordTooShort or ErrPasswordTooSimple if the
+// password does not satisfy the policy.
+func (p PasswordPolicy) checkPassword(password string) error {
+	if len([]rune(password)) < p.MinLength {
+		return ErrPasswordTooShort
+	}
+	if p.MinClasses <= 0 {
+		return nil
+	}
+	var lower, upper, digit, other int
+	for _, c := range password {
+		switch {
+		case unicode.IsLower(c):
+			lower = 1
+		case unicode.IsUpper(c):
+			upper = 1
+		case unicode.IsDigit(c):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+	if lower+upper+digit+other < p.MinClasses {
+		return ErrPasswordTooSimple
+	}
+	return nil
+}
+
+// hashPassword hashes the password as of the policy, with bcryptCost for
+// bcrypt.
+func (p PasswordPolicy) hashPassword(password string, bcryptCost int) ([]byte, error) {
+	if p.Hash != PasswordHashArgon2id {
+		return bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+	}
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key := argon2.IDKey([]byte(password), salt, p.Argon2Time, p.Argon2Memory, p.Argon2Threads, argon2KeyLen)
+	return []byte(fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Argon2Memory, p.Argon2Time, p.Argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))), nil
+}
+
+// needsRehash returns whether hashed is not hashed as of the policy.
+func (p PasswordPolicy) needsRehash(hashed []byte, bcryptCost int) bool {
+	if p.Hash != PasswordHashArgon2id {
+		cost, err := bcrypt.Cost(hashed)
+		return err != nil || cost != bcryptCost
+	}
+	h, err := parseArgon2Hash(hashed)
+	return err != nil || h.time != p.Argon2Time || h.memory != p.Argon2Memory || h.threads != p.Argon2Threads
+}
+
+// verifyPassword returns whether hashed is a hash of the password, by
+// bcrypt or argon2id.
+func verifyPassword(hashed []byte, password string) bool {
+	if !bytes.HasPrefix(hashed, []byte("$argon2id$")) {
+		return bcrypt.CompareHashAndPassword(hashed, []byte(password)) == nil
+	}
+	h, err := parseArgon2Hash(hashed)
+	if err != nil {
+		return false
+	}
+	key := argon2.IDKey([]byte(password), h.salt, h.time, h.memory, h.threads, uint32(len(h.key)))
+	return subtle.ConstantTimeCompare(key, h.key) == 1
+}
+
+type argon2Hash struct {
+	time, memory uint32
+	threads      uint8
+	salt, key    []byte
+}
+
+// parseArgon2Hash parses a hash formatted as
+// "$argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>".
+func parseArgon2Hash(hashed []byte) (*argon2Hash, error) {
+	parts := bytes.Split(hashed, []byte("$"))
+	if len(parts) != 6 || string(parts[1]) != PasswordHashArgon2id {
+		return nil, fmt.Errorf("invalid argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(string(parts[2]), "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2id version %q", parts[2])
+	}
+	h := &argon2Hash{}
+	if _, err := fmt.Sscanf(string(parts[3]), "m=%d,t=%d,p=%d", &h.memory, &h.time, &h.threads); err != nil {
+		return nil, fmt.Errorf("invalid argon2id parameters %q", parts[3])
+	}
+	var err error
+	if h.salt, err = base64.RawStdEncoding.DecodeString(string(parts[4])); err != nil {
+		return nil, err
+	}
+	if h.key, err = base64.RawStdEncoding.DecodeString(string(parts[5])); err != nil {
+		return nil, err
+	}
+	if len(h.key) == 0 {
+		return nil, fmt.Errorf("invalid argon2id hash")
+	}
+	return h, nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"go.etcd.io/etcd/api/v3/authpb"
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+func TestPasswordPolicyHash(t *testing.T) {
+	bcryptPolicy := PasswordPolicy{Hash: PasswordHashBcrypt}
+	argon2Policy := PasswordPolicy{Hash: PasswordHashArgon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1}
+
+	for _, p := range []PasswordPolicy{bcryptPolicy, argon2Policy} {
+		t.Run(p.Hash, func(t *testing.T) {
+			hashed, err := p.hashPassword("pass", bcrypt.MinCost)
+			require.NoError(t, err)
+			assert.True(t, verifyPassword(hashed, "pass"))
+			assert.False(t, verifyPassword(hashed, "wrong"))
+			assert.False(t, p.needsRehash(hashed, bcrypt.MinCost))
+		})
+	}
+
+	bcryptHash, err := bcryptPolicy.hashPassword("pass", bcrypt.MinCost)
+	require.NoError(t, err)
+	argon2Hash, err := argon2Policy.hashPassword("pass", bcrypt.MinCost)
+	require.NoError(t, err)
+	assert.True(t, bcryptPolicy.needsRehash(bcryptHash, bcrypt.MinCost+1))
+	assert.True(t, bcryptPolicy.needsRehash(argon2Hash, bcrypt.MinCost))
+	assert.True(t, argon2Policy.needsRehash(bcryptHash, bcrypt.MinCost))
+	stronger := argon2Policy
+	stronger.Argon2Time = 2
+	assert.True(t, stronger.needsRehash(argon2Hash, bcrypt.MinCost))
+
+	assert.False(t, verifyPassword([]byte("$argon2id$v=19$m=64,t=1,p=1$bad"), "pass"))
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	assert.NoError(t, PasswordPolicy{}.ValidatePasswordHash())
+	assert.NoError(t, PasswordPolicy{Hash: PasswordHashArgon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1}.ValidatePasswordHash())
+	assert.Error(t, PasswordPolicy{Hash: PasswordHashArgon2id}.ValidatePasswordHash())
+	assert.Error(t, PasswordPolicy{Hash: "md5"}.ValidatePasswordHash())
+}
+
+func TestPasswordPolicyCheck(t *testing.T) {
+	p := PasswordPolicy{MinLength: 8, MinClasses: 3}
+	tests := []struct {
+		password string
+		err      error
+	}{
+		{"Ab1", ErrPasswordTooShort},
+		{"abcdefgh", ErrPasswordTooSimple},
+		{"abcdEFGH", ErrPasswordTooSimple},
+		{"abcdEF12", nil},
+		{"abcd-fgh1", nil},
+		{"пароль-12", nil},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.err, p.checkPassword(tt.password), tt.password)
+	}
+	assert.NoError(t, PasswordPolicy{}.checkPassword(""))
+}
+
+func TestRehashPassword(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	prev, hashed, err := as.RehashPassword("foo", "bar")
+	require.NoError(t, err)
+	assert.Nil(t, hashed)
+
+	as.SetPasswordPolicy(PasswordPolicy{Hash: PasswordHashArgon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1})
+	prev, hashed, err = as.RehashPassword("foo", "bar")
+	require.NoError(t, err)
+	require.NotNil(t, hashed)
+
+	// a hash computed from a stale password is not applied
+	as.UpdatePasswordHash("foo", []byte("stale"), hashed)
+	assert.Equal(t, prev, as.be.GetUser("foo").Password)
+
+	rev := as.Revision()
+	as.UpdatePasswordHash("foo", prev, hashed)
+	assert.Equal(t, hashed, as.be.GetUser("foo").Password)
+	assert.Equal(t, rev, as.Revision())
+	_, err = as.CheckPassword("foo", "bar")
+	assert.NoError(t, err)
+
+	_, hashed, err = as.RehashPassword("foo", "bar")
+	require.NoError(t, err)
+	assert.Nil(t, hashed)
+}
+
+func TestPasswordExpired(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+	as.SetPasswordPolicy(PasswordPolicy{MaxAge: time.Hour})
+
+	// the passwords set before the policy never expire
+	_, err := as.CheckPassword("foo", "bar")
+	assert.NoError(t, err)
+
+	_, err = as.UserAdd(&pb.AuthUserAddRequest{Name: "old", HashedPassword: encodePassword("pass"), Options: &authpb.UserAddOptions{}, PasswordChangedAt: time.Now().Add(-2 * time.Hour).Unix()})
+	require.NoError(t, err)
+	_, err = as.CheckPassword("old", "pass")
+	assert.Equal(t, ErrPasswordExpired, err)
+	_, err = as.CheckPassword("old", "wrong")
+	assert.Equal(t, ErrAuthFailed, err)
+
+	_, err = as.UserChangePassword(&pb.AuthUserChangePasswordRequest{Name: "old", HashedPassword: encodePassword("pass"), PasswordChangedAt: time.Now().Unix()})
+	require.NoError(t, err)
+	_, err = as.CheckPassword("old", "pass")
+	assert.NoError(t, err)
+}
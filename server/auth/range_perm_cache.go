@@ -20,16 +20,24 @@ import (
 	"go.uber.org/zap"
 )
 
-func getMergedPerms(tx AuthReadTx, userName string) *unifiedRangePermissions {
+// getMergedPerms merges the permissions of the roles of the user and the
+// roles granted to it outside of etcd. The user does not need to exist if
+// granted roles.
+func getMergedPerms(tx AuthReadTx, userName string, grantedRoles []string) *unifiedRangePermissions {
 	user := tx.UnsafeGetUser(userName)
-	if user == nil {
+	if user == nil && len(grantedRoles) == 0 {
 		return nil
 	}
 
 	readPerms := adt.NewIntervalTree()
 	writePerms := adt.NewIntervalTree()
+	watchPerms := adt.NewIntervalTree()
 
-	for _, roleName := range user.Roles {
+	roles := grantedRoles
+	if user != nil {
+		roles = append(append([]string{}, user.Roles...), grantedRoles...)
+	}
+	for _, roleName := range roles {
 		role := tx.UnsafeGetRole(roleName)
 		if role == nil {
 			continue
@@ -53,19 +61,36 @@ func getMergedPerms(tx AuthReadTx, userName string) *unifiedRangePermissions {
 			case authpb.READWRITE:
 				readPerms.Insert(ivl, struct{}{})
 				writePerms.Insert(ivl, struct{}{})
+				watchPerms.Insert(ivl, struct{}{})
 
 			case authpb.READ:
 				readPerms.Insert(ivl, struct{}{})
+				watchPerms.Insert(ivl, struct{}{})
 
 			case authpb.WRITE:
 				writePerms.Insert(ivl, struct{}{})
+
+			case authpb.WATCH:
+				watchPerms.Insert(ivl, struct{}{})
+
+			case authpb.RANGE:
+				readPerms.Insert(ivl, struct{}{})
 			}
 		}
 	}
 
+	// users are implicitly granted read and write access to their home prefix
+	if home := userHomePrefix(user); home != nil {
+		ivl := adt.NewBytesAffineInterval(home, homeRangeEnd(home))
+		readPerms.Insert(ivl, struct{}{})
+		writePerms.Insert(ivl, struct{}{})
+		watchPerms.Insert(ivl, struct{}{})
+	}
+
 	return &unifiedRangePermissions{
 		readPerms:  readPerms,
 		writePerms: writePerms,
+		watchPerms: watchPerms,
 	}
 }
 
@@ -84,6 +109,8 @@ func checkKeyInterval(
 		return cachedPerms.readPerms.Contains(ivl)
 	case authpb.WRITE:
 		return cachedPerms.writePerms.Contains(ivl)
+	case authpb.WATCH:
+		return cachedPerms.watchPerms.Contains(ivl)
 	default:
 		lg.Panic("unknown auth type", zap.String("auth-type", permtyp.String()))
 	}
@@ -97,6 +124,8 @@ func checkKeyPoint(lg *zap.Logger, cachedPerms *unifiedRangePermissions, key []b
 		return cachedPerms.readPerms.Intersects(pt)
 	case authpb.WRITE:
 		return cachedPerms.writePerms.Intersects(pt)
+	case authpb.WATCH:
+		return cachedPerms.watchPerms.Intersects(pt)
 	default:
 		lg.Panic("unknown auth type", zap.String("auth-type", permtyp.String()))
 	}
@@ -107,7 +136,7 @@ func (as *authStore) isRangeOpPermitted(tx AuthReadTx, userName string, key, ran
 	// assumption: tx is Lock()ed
 	_, ok := as.rangePermCache[userName]
 	if !ok {
-		perms := getMergedPerms(tx, userName)
+		perms := getMergedPerms(tx, userName, nil)
 		if perms == nil {
 			as.lg.Error(
 				"failed to create a merged permission",
@@ -118,11 +147,15 @@ func (as *authStore) isRangeOpPermitted(tx AuthReadTx, userName string, key, ran
 		as.rangePermCache[userName] = perms
 	}
 
+	return checkPerms(as.lg, as.rangePermCache[userName], key, rangeEnd, permtyp)
+}
+
+func checkPerms(lg *zap.Logger, perms *unifiedRangePermissions, key, rangeEnd []byte, permtyp authpb.Permission_Type) bool {
 	if len(rangeEnd) == 0 {
-		return checkKeyPoint(as.lg, as.rangePermCache[userName], key, permtyp)
+		return checkKeyPoint(lg, perms, key, permtyp)
 	}
 
-	return checkKeyInterval(as.lg, as.rangePermCache[userName], key, rangeEnd, permtyp)
+	return checkKeyInterval(lg, perms, key, rangeEnd, permtyp)
 }
 
 func (as *authStore) clearCachedPerm() {
@@ -136,4 +169,5 @@ func (as *authStore) invalidateCachedPerm(userName string) {
 type unifiedRangePermissions struct {
 	readPerms  adt.IntervalTree
 	writePerms adt.IntervalTree
+	watchPerms adt.IntervalTree
 }
@@ -0,0 +1,114 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"sort"
+
+	"go.etcd.io/etcd/api/v3/authpb"
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+
+	"go.uber.org/zap"
+)
+
+// roleExpirationIndex returns the index of the expiration of the role granted
+// to the user, or where to insert it, and whether it is found.
+func roleExpirationIndex(user *authpb.User, role string) (int, bool) {
+	i := sort.Search(len(user.RoleExpirations), func(i int) bool { return user.RoleExpirations[i].Role >= role })
+	return i, i < len(user.RoleExpirations) && user.RoleExpirations[i].Role == role
+}
+
+// setRoleExpiration sets the time the role granted to the user expires at,
+// making the grant permanent if expiresAt is zero.
+func setRoleExpiration(user *authpb.User, role string, expiresAt int64) {
+	i, ok := roleExpirationIndex(user, role)
+	switch {
+	case ok && expiresAt == 0:
+		user.RoleExpirations = append(user.RoleExpirations[:i], user.RoleExpirations[i+1:]...)
+	case ok:
+		user.RoleExpirations[i].ExpiresAt = expiresAt
+	case expiresAt != 0:
+		user.RoleExpirations = append(user.RoleExpirations, nil)
+		copy(user.RoleExpirations[i+1:], user.RoleExpirations[i:])
+		user.RoleExpirations[i] = &authpb.RoleExpiration{Role: role, ExpiresAt: expiresAt}
+	}
+}
+
+// roleExpirationsWithout returns the expirations of the roles other than role.
+func roleExpirationsWithout(exps []*authpb.RoleExpiration, role string) []*authpb.RoleExpiration {
+	var ret []*authpb.RoleExpiration
+	for _, e := range exps {
+		if e.Role != role {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
+
+func (as *authStore) UserRoleExpire(r *pb.AuthUserRoleExpireRequest) {
+	tx := as.be.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+
+	expired := false
+	for _, user := range tx.UnsafeGetAllUsers() {
+		var roles []string
+		var exps []*authpb.RoleExpiration
+		for _, e := range user.RoleExpirations {
+			if e.ExpiresAt <= r.Time {
+				roles = append(roles, e.Role)
+			} else {
+				exps = append(exps, e)
+			}
+		}
+		if len(roles) == 0 {
+			continue
+		}
+
+		var kept []string
+		for _, role := range user.Roles {
+			if i := sort.SearchStrings(roles, role); i == len(roles) || roles[i] != role {
+				kept = append(kept, role)
+			}
+		}
+		user.Roles, user.RoleExpirations = kept, exps
+		tx.UnsafePutUser(user)
+		as.invalidateCachedPerm(string(user.Name))
+		expired = true
+
+		as.lg.Info(
+			"revoked expired roles from a user",
+			zap.String("user-name", string(user.Name)),
+			zap.Strings("expired-role-names", roles),
+		)
+	}
+	if expired {
+		as.commitRevision(tx)
+	}
+}
+
+func (as *authStore) HasExpiredRoleGrants(now int64) bool {
+	tx := as.be.ReadTx()
+	tx.Lock()
+	defer tx.Unlock()
+	for _, user := range tx.UnsafeGetAllUsers() {
+		for _, e := range user.RoleExpirations {
+			if e.ExpiresAt <= now {
+				return true
+			}
+		}
+	}
+	return false
+}
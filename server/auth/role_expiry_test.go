@@ -0,0 +1,90 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.etcd.io/etcd/api/v3/authpb"
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+func TestUserRoleExpire(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, err := as.RoleAdd(&pb.AuthRoleAddRequest{Name: "role-tmp"})
+	require.NoError(t, err)
+	_, err = as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: "role-test"})
+	require.NoError(t, err)
+	_, err = as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: "role-tmp", ExpiresAt: 100})
+	require.NoError(t, err)
+	_, err = as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "root", Role: "root", ExpiresAt: 100})
+	assert.Equal(t, ErrInvalidAuthMgmt, err)
+
+	// granting the role again changes the expiration
+	_, err = as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: "role-tmp", ExpiresAt: 200})
+	require.NoError(t, err)
+	resp, err := as.UserGet(&pb.AuthUserGetRequest{Name: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"role-test", "role-tmp"}, resp.Roles)
+	assert.Equal(t, []*authpb.RoleExpiration{{Role: "role-tmp", ExpiresAt: 200}}, resp.RoleExpirations)
+
+	// the expiration survives a password change
+	_, err = as.UserChangePassword(&pb.AuthUserChangePasswordRequest{Name: "foo", HashedPassword: encodePassword("baz")})
+	require.NoError(t, err)
+
+	assert.False(t, as.HasExpiredRoleGrants(199))
+	rev := as.Revision()
+	as.UserRoleExpire(&pb.AuthUserRoleExpireRequest{Time: 199})
+	assert.Equal(t, rev, as.Revision())
+
+	assert.True(t, as.HasExpiredRoleGrants(200))
+	as.UserRoleExpire(&pb.AuthUserRoleExpireRequest{Time: 200})
+	assert.Equal(t, rev+1, as.Revision())
+	assert.False(t, as.HasExpiredRoleGrants(300))
+	resp, err = as.UserGet(&pb.AuthUserGetRequest{Name: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"role-test"}, resp.Roles)
+	assert.Empty(t, resp.RoleExpirations)
+}
+
+func TestUserGrantRolePermanently(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, err := as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: "role-test", ExpiresAt: 100})
+	require.NoError(t, err)
+	_, err = as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: "role-test"})
+	require.NoError(t, err)
+	assert.False(t, as.HasExpiredRoleGrants(100))
+
+	// a role granted permanently does not expire when granted until a time
+	_, err = as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: "role-test", ExpiresAt: 100})
+	require.NoError(t, err)
+	assert.False(t, as.HasExpiredRoleGrants(100))
+
+	// revoking the role forgets its expiration
+	_, err = as.RoleAdd(&pb.AuthRoleAddRequest{Name: "role-tmp"})
+	require.NoError(t, err)
+	_, err = as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: "role-tmp", ExpiresAt: 100})
+	require.NoError(t, err)
+	_, err = as.UserRevokeRole(&pb.AuthUserRevokeRoleRequest{Name: "foo", Role: "role-tmp"})
+	require.NoError(t, err)
+	assert.False(t, as.HasExpiredRoleGrants(100))
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"sort"
+	"time"
+)
+
+// AuthenticateParamClientAddress is used for a key of context in the parameters of Authenticate()
+type AuthenticateParamClientAddress struct{}
+
+// Session is a token issued to a user. Its ID is the index of the
+// authentication issuing the token, so that it is the same on all the
+// members.
+type Session struct {
+	ID        uint64
+	User      string
+	Address   string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// matches returns whether the session is revoked by revoking the session id,
+// of the user if not empty, or all the sessions of the user if id is zero.
+func (s Session) matches(username string, id uint64) bool {
+	if id == 0 {
+		return s.User == username
+	}
+	return s.ID == id && (username == "" || s.User == username)
+}
+
+// RevokedToken is the revocation of the token of a session, issued to the
+// user if not empty, kept until the token expires.
+type RevokedToken struct {
+	User      string
+	ExpiresAt time.Time
+}
+
+func sortSessions(ss []Session) []Session {
+	sort.Slice(ss, func(i, j int) bool { return ss[i].ID < ss[j].ID })
+	return ss
+}
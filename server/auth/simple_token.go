@@ -104,6 +104,8 @@ type tokenSimple struct {
 	simpleTokenKeeper *simpleTokenTTLKeeper
 	simpleTokensMu    sync.Mutex
 	simpleTokens      map[string]string // token -> username
+	simpleSessions    map[string]Session
+	simpleRoles       map[string][]string // token -> roles granted by the LDAP directory
 	simpleTokenTTL    time.Duration
 }
 
@@ -150,6 +152,8 @@ func (t *tokenSimple) invalidateUser(username string) {
 	for token, name := range t.simpleTokens {
 		if name == username {
 			delete(t.simpleTokens, token)
+			delete(t.simpleSessions, token)
+			delete(t.simpleRoles, token)
 			t.simpleTokenKeeper.deleteSimpleToken(token)
 		}
 	}
@@ -174,6 +178,8 @@ func (t *tokenSimple) enable() {
 				zap.String("token", tk),
 			)
 			delete(t.simpleTokens, tk)
+			delete(t.simpleSessions, tk)
+			delete(t.simpleRoles, tk)
 		}
 	}
 	t.simpleTokenKeeper = &simpleTokenTTLKeeper{
@@ -192,6 +198,8 @@ func (t *tokenSimple) disable() {
 	tk := t.simpleTokenKeeper
 	t.simpleTokenKeeper = nil
 	t.simpleTokens = make(map[string]string) // invalidate all tokens
+	t.simpleSessions = make(map[string]Session)
+	t.simpleRoles = make(map[string][]string)
 	t.simpleTokensMu.Unlock()
 	if tk != nil {
 		tk.stop()
@@ -204,11 +212,12 @@ func (t *tokenSimple) info(ctx context.Context, token string, revision uint64) (
 	}
 	t.simpleTokensMu.Lock()
 	username, ok := t.simpleTokens[token]
+	roles := t.simpleRoles[token]
 	if ok && t.simpleTokenKeeper != nil {
 		t.simpleTokenKeeper.resetSimpleToken(token)
 	}
 	t.simpleTokensMu.Unlock()
-	return &AuthInfo{Username: username, Revision: revision}, ok
+	return &AuthInfo{Username: username, Revision: revision, Roles: roles}, ok
 }
 
 func (t *tokenSimple) assign(ctx context.Context, username string, rev uint64) (string, error) {
@@ -221,10 +230,62 @@ func (t *tokenSimple) assign(ctx context.Context, username string, rev uint64) (
 	simpleTokenPrefix := ctx.Value(AuthenticateParamSimpleTokenPrefix{}).(string)
 	token := fmt.Sprintf("%s.%d", simpleTokenPrefix, index)
 	t.assignSimpleTokenToUser(username, token)
+	if roles, _ := ctx.Value(AuthenticateParamRoles{}).([]string); len(roles) != 0 {
+		t.simpleTokensMu.Lock()
+		if t.simpleTokenKeeper != nil {
+			t.simpleRoles[token] = roles
+		}
+		t.simpleTokensMu.Unlock()
+	}
+	if index != 0 {
+		addr, _ := ctx.Value(AuthenticateParamClientAddress{}).(string)
+		t.simpleTokensMu.Lock()
+		if t.simpleTokenKeeper != nil {
+			t.simpleSessions[token] = Session{ID: index, User: username, Address: addr, IssuedAt: time.Now()}
+		}
+		t.simpleTokensMu.Unlock()
+	}
 
 	return token, nil
 }
 
+func (t *tokenSimple) sessions() []Session {
+	t.simpleTokensMu.Lock()
+	defer t.simpleTokensMu.Unlock()
+	if t.simpleTokenKeeper == nil {
+		return nil
+	}
+	var ss []Session
+	for token, s := range t.simpleSessions {
+		s.ExpiresAt = t.simpleTokenKeeper.tokens[token]
+		ss = append(ss, s)
+	}
+	return sortSessions(ss)
+}
+
+func (t *tokenSimple) revoke(tx AuthBatchTx, username string, id, index uint64) int {
+	// tx and index aren't used in simple token, the tokens are deleted and
+	// not valid after a restart
+	t.simpleTokensMu.Lock()
+	defer t.simpleTokensMu.Unlock()
+	if t.simpleTokenKeeper == nil {
+		return 0
+	}
+	n := 0
+	for token, s := range t.simpleSessions {
+		if s.matches(username, id) {
+			delete(t.simpleTokens, token)
+			delete(t.simpleSessions, token)
+			delete(t.simpleRoles, token)
+			t.simpleTokenKeeper.deleteSimpleToken(token)
+			n++
+		}
+	}
+	return n
+}
+
+func (t *tokenSimple) recoverRevocations(tx AuthReadTx) {}
+
 func (t *tokenSimple) isValidSimpleToken(ctx context.Context, token string) bool {
 	splitted := strings.Split(token, ".")
 	if len(splitted) != 2 {
@@ -251,6 +312,8 @@ func newTokenProviderSimple(lg *zap.Logger, indexWaiter func(uint64) <-chan stru
 	return &tokenSimple{
 		lg:             lg,
 		simpleTokens:   make(map[string]string),
+		simpleSessions: make(map[string]Session),
+		simpleRoles:    make(map[string][]string),
 		indexWaiter:    indexWaiter,
 		simpleTokenTTL: TokenTTL,
 	}
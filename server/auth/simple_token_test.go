@@ -68,3 +68,40 @@ func TestSimpleTokenAssign(t *testing.T) {
 		t.Errorf("expected ok == false after user is invalidated")
 	}
 }
+
+// TestSimpleTokenRevoke ensures that TokenProviderSimple lists the sessions of
+// the tokens assigned, and revokes them by ID or by user.
+func TestSimpleTokenRevoke(t *testing.T) {
+	tp := newTokenProviderSimple(zaptest.NewLogger(t), dummyIndexWaiter, simpleTokenTTLDefault)
+	tp.enable()
+	defer tp.disable()
+	var tokens []string
+	for i, user := range []string{"user1", "user1", "user2"} {
+		ctx := context.WithValue(context.WithValue(context.TODO(), AuthenticateParamIndex{}, uint64(i+1)), AuthenticateParamSimpleTokenPrefix{}, "dummy")
+		token, err := tp.assign(ctx, user, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens = append(tokens, token)
+	}
+	ss := tp.sessions()
+	if len(ss) != 3 || ss[0].ID != 1 || ss[0].User != "user1" || ss[0].ExpiresAt.IsZero() {
+		t.Fatalf("unexpected sessions %+v", ss)
+	}
+
+	if n := tp.revoke(newBackendMock().BatchTx(), "", 2, 0); n != 1 {
+		t.Fatalf("expected 1 session revoked, got %d", n)
+	}
+	if _, ok := tp.info(context.TODO(), tokens[1], 0); ok {
+		t.Errorf("expected the token of session 2 revoked")
+	}
+	if n := tp.revoke(newBackendMock().BatchTx(), "user2", 0, 0); n != 1 {
+		t.Fatalf("expected 1 session revoked, got %d", n)
+	}
+	if _, ok := tp.info(context.TODO(), tokens[0], 0); !ok {
+		t.Errorf("expected the token of session 1 valid")
+	}
+	if ss = tp.sessions(); len(ss) != 1 || ss[0].ID != 1 {
+		t.Fatalf("unexpected sessions %+v", ss)
+	}
+}
@@ -65,6 +65,13 @@ var (
 	ErrMissingKey           = errors.New("auth: missing key data")
 	ErrKeyMismatch          = errors.New("auth: public and private keys don't match")
 	ErrVerifyOnly           = errors.New("auth: token signing attempted with verify-only key")
+	ErrInvalidHomePrefix    = errors.New("auth: home prefix must be an absolute key")
+	ErrAuthLockedOut        = errors.New("auth: too many failed authentication attempts, try again later")
+	ErrPasswordTooShort     = errors.New("auth: password is shorter than the password policy allows")
+	ErrPasswordTooSimple    = errors.New("auth: password has fewer classes of characters than the password policy allows")
+	ErrPasswordExpired      = errors.New("auth: password expired, it must be changed")
+	ErrRoleGrantExpired     = errors.New("auth: role grant expiration is not in the future")
+	ErrLDAPUnavailable      = errors.New("auth: LDAP directory unavailable")
 )
 
 const (
@@ -78,6 +85,10 @@ const (
 type AuthInfo struct {
 	Username string
 	Revision uint64
+	// Roles are the roles granted to the user by its identity outside of
+	// etcd, such as an OIDC token, in addition to the roles of the user. A
+	// user granted roles does not need to exist.
+	Roles []string
 }
 
 // AuthenticateParamIndex is used for a key of context in the parameters of Authenticate()
@@ -86,6 +97,11 @@ type AuthenticateParamIndex struct{}
 // AuthenticateParamSimpleTokenPrefix is used for a key of context in the parameters of Authenticate()
 type AuthenticateParamSimpleTokenPrefix struct{}
 
+// AuthenticateParamRoles is used for a key of context in the parameters of
+// Authenticate(), the roles granted to a user absent from the auth store by
+// the LDAP directory
+type AuthenticateParamRoles struct{}
+
 // AuthStore defines auth storage interface.
 type AuthStore interface {
 	// AuthEnable turns on the authentication feature
@@ -121,6 +137,14 @@ type AuthStore interface {
 	// UserRevokeRole revokes a role of a user
 	UserRevokeRole(r *pb.AuthUserRevokeRoleRequest) (*pb.AuthUserRevokeRoleResponse, error)
 
+	// UserRoleExpire revokes the roles granted to the users until the time of
+	// r or before
+	UserRoleExpire(r *pb.AuthUserRoleExpireRequest)
+
+	// HasExpiredRoleGrants returns whether a role granted until now or before
+	// is not revoked yet
+	HasExpiredRoleGrants(now int64) bool
+
 	// RoleAdd adds a new role
 	RoleAdd(r *pb.AuthRoleAddRequest) (*pb.AuthRoleAddResponse, error)
 
@@ -148,12 +172,22 @@ type AuthStore interface {
 	// IsRangePermitted checks range permission of the user
 	IsRangePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error
 
+	// IsWatchPermitted checks watch permission of the user
+	IsWatchPermitted(authInfo *AuthInfo, key, rangeEnd []byte) error
+
 	// IsDeleteRangePermitted checks delete-range permission of the user
 	IsDeleteRangePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error
 
 	// IsAdminPermitted checks admin permission of the user
 	IsAdminPermitted(authInfo *AuthInfo) error
 
+	// IsAdminOpPermitted checks the user is root or granted the cluster
+	// administration permission by its roles
+	IsAdminOpPermitted(authInfo *AuthInfo, perm authpb.AdminPermission) error
+
+	// ResolveHomeKey scopes a relative key range of the user under the user's home prefix
+	ResolveHomeKey(authInfo *AuthInfo, key, rangeEnd []byte) ([]byte, []byte)
+
 	// GenTokenPrefix produces a random string in a case of simple token
 	// in a case of JWT, it produces an empty string
 	GenTokenPrefix() (string, error)
@@ -164,6 +198,15 @@ type AuthStore interface {
 	// CheckPassword checks a given pair of username and password is correct
 	CheckPassword(username, password string) (uint64, error)
 
+	// CheckLDAPPassword checks the password of a user absent from the auth
+	// store against the LDAP directory, returning the revision and the roles
+	// granted to the user by its groups
+	CheckLDAPPassword(username, password string) (uint64, []string, error)
+
+	// SetLDAPAuthenticator sets the LDAP directory of CheckLDAPPassword, nil
+	// disabling it.
+	SetLDAPAuthenticator(l *LDAPAuthenticator)
+
 	// Close does cleanup of AuthStore
 	Close() error
 
@@ -173,6 +216,10 @@ type AuthStore interface {
 	// AuthInfoFromTLS gets AuthInfo from TLS info of gRPC's context
 	AuthInfoFromTLS(ctx context.Context) *AuthInfo
 
+	// SetCertRoleRules sets the rules granting roles to the client certificates
+	// of AuthInfoFromTLS.
+	SetCertRoleRules(rules []CertRoleRule)
+
 	// WithRoot generates and installs a token that can be used as a root credential
 	WithRoot(ctx context.Context) context.Context
 
@@ -181,6 +228,31 @@ type AuthStore interface {
 
 	// BcryptCost gets strength of hashing bcrypted auth password
 	BcryptCost() int
+
+	// SetPasswordPolicy sets how the passwords are hashed, and the passwords
+	// the users may set.
+	SetPasswordPolicy(p PasswordPolicy)
+
+	// HashPassword checks the password satisfies the password policy, and
+	// hashes it.
+	HashPassword(password string) ([]byte, error)
+
+	// RehashPassword returns the hash of the password of the user and the
+	// password hashed as of the password policy, or nils if the password is
+	// already hashed as of the policy. The password must have been checked.
+	RehashPassword(username, password string) (prev, hashed []byte, err error)
+
+	// UpdatePasswordHash replaces the hash of the password of the user by
+	// hashed, provided it is still prev.
+	UpdatePasswordHash(username string, prev, hashed []byte)
+
+	// Sessions returns the sessions of the tokens currently valid on the
+	// member, of the user if not empty.
+	Sessions(username string) []Session
+
+	// SessionRevoke revokes the token of a session, or the tokens of all the
+	// sessions of a user. index is the index of the revocation.
+	SessionRevoke(index uint64, r *pb.AuthSessionRevokeRequest) (*pb.AuthSessionRevokeResponse, error)
 }
 
 type TokenProvider interface {
@@ -191,6 +263,16 @@ type TokenProvider interface {
 
 	invalidateUser(string)
 	genTokenPrefix() (string, error)
+
+	// sessions returns the sessions of the tokens currently valid, by ID.
+	sessions() []Session
+	// revoke invalidates the token of the session id, of the user if not
+	// empty, or of all the sessions of the user if id is zero, and returns
+	// the number of sessions revoked. index is the index of the revocation.
+	// The revocations the tokens cannot be deleted for are saved with tx.
+	revoke(tx AuthBatchTx, username string, id, index uint64) int
+	// recoverRevocations loads the revocations saved by revoke.
+	recoverRevocations(tx AuthReadTx)
 }
 
 type AuthBackend interface {
@@ -213,6 +295,9 @@ type AuthBatchTx interface {
 	UnsafeDeleteUser(string)
 	UnsafePutRole(*authpb.Role)
 	UnsafeDeleteRole(string)
+	UnsafePutRevokedToken(id uint64, r RevokedToken)
+	UnsafeDeleteRevokedToken(id uint64)
+	UnsafePutTokensRevokedBefore(username string, index uint64)
 }
 
 type AuthReadTx interface {
@@ -222,6 +307,8 @@ type AuthReadTx interface {
 	UnsafeGetRole(string) *authpb.Role
 	UnsafeGetAllUsers() []*authpb.User
 	UnsafeGetAllRoles() []*authpb.Role
+	UnsafeGetRevokedTokens() map[uint64]RevokedToken
+	UnsafeGetTokensRevokedBefore() map[string]uint64
 	Lock()
 	Unlock()
 }
@@ -239,6 +326,12 @@ type authStore struct {
 
 	tokenProvider TokenProvider
 	bcryptCost    int // the algorithm cost / strength for hashing auth passwords
+
+	certRoleRules []CertRoleRule
+
+	passwordPolicy PasswordPolicy
+
+	ldap *LDAPAuthenticator
 }
 
 func (as *authStore) AuthEnable() error {
@@ -313,11 +406,18 @@ func (as *authStore) Authenticate(ctx context.Context, username, password string
 		return nil, ErrAuthNotEnabled
 	}
 	user := as.be.GetUser(username)
+	roles, _ := ctx.Value(AuthenticateParamRoles{}).([]string)
 	if user == nil {
+		// a user of the LDAP directory is granted roles by its groups
+		if len(roles) == 0 {
+			return nil, ErrAuthFailed
+		}
+	} else if len(roles) != 0 {
+		// the user was added since it authenticated against the directory
 		return nil, ErrAuthFailed
 	}
 
-	if user.Options != nil && user.Options.NoPassword {
+	if user != nil && user.Options != nil && user.Options.NoPassword {
 		return nil, ErrAuthFailed
 	}
 
@@ -365,13 +465,28 @@ func (as *authStore) CheckPassword(username, password string) (uint64, error) {
 		return 0, err
 	}
 
-	if bcrypt.CompareHashAndPassword(user.Password, []byte(password)) != nil {
+	if !verifyPassword(user.Password, password) {
 		as.lg.Info("invalid password", zap.String("user-name", username))
 		return 0, ErrAuthFailed
 	}
+	if as.passwordExpired(user) {
+		as.lg.Info("expired password", zap.String("user-name", username))
+		return 0, ErrPasswordExpired
+	}
 	return revision, nil
 }
 
+// passwordExpired returns whether the password of the user is older than the
+// password policy allows. The password of root never expires, lest nobody
+// can change the passwords anymore.
+func (as *authStore) passwordExpired(user *authpb.User) bool {
+	maxAge := as.passwordPolicy.MaxAge
+	if maxAge <= 0 || user.PasswordChangedAt == 0 || string(user.Name) == rootUser {
+		return false
+	}
+	return time.Since(time.Unix(user.PasswordChangedAt, 0)) > maxAge
+}
+
 func (as *authStore) Recover(be AuthBackend) {
 	as.be = be
 	tx := be.ReadTx()
@@ -379,6 +494,7 @@ func (as *authStore) Recover(be AuthBackend) {
 
 	enabled := tx.UnsafeReadAuthEnabled()
 	as.setRevision(tx.UnsafeReadAuthRevision())
+	as.tokenProvider.recoverRevocations(tx)
 
 	tx.Unlock()
 
@@ -419,6 +535,10 @@ func (as *authStore) UserAdd(r *pb.AuthUserAddRequest) (*pb.AuthUserAddResponse,
 		}
 	}
 
+	if len(options.HomePrefix) != 0 && !isAbsoluteKey([]byte(options.HomePrefix)) {
+		return nil, ErrInvalidHomePrefix
+	}
+
 	var password []byte
 	var err error
 
@@ -430,15 +550,20 @@ func (as *authStore) UserAdd(r *pb.AuthUserAddRequest) (*pb.AuthUserAddResponse,
 	}
 
 	newUser := &authpb.User{
-		Name:     []byte(r.Name),
-		Password: password,
-		Options:  options,
+		Name:              []byte(r.Name),
+		Password:          password,
+		Options:           options,
+		PasswordChangedAt: r.PasswordChangedAt,
 	}
 	tx.UnsafePutUser(newUser)
 
 	as.commitRevision(tx)
 
-	as.lg.Info("added a user", zap.String("user-name", r.Name))
+	as.lg.Info(
+		"added a user",
+		zap.String("user-name", r.Name),
+		zap.String("home-prefix", options.HomePrefix),
+	)
 	return &pb.AuthUserAddResponse{}, nil
 }
 
@@ -492,10 +617,12 @@ func (as *authStore) UserChangePassword(r *pb.AuthUserChangePasswordRequest) (*p
 	}
 
 	updatedUser := &authpb.User{
-		Name:     []byte(r.Name),
-		Roles:    user.Roles,
-		Password: password,
-		Options:  user.Options,
+		Name:              []byte(r.Name),
+		Roles:             user.Roles,
+		Password:          password,
+		Options:           user.Options,
+		PasswordChangedAt: r.PasswordChangedAt,
+		RoleExpirations:   user.RoleExpirations,
 	}
 	tx.UnsafePutUser(updatedUser)
 
@@ -529,19 +656,29 @@ func (as *authStore) UserGrantRole(r *pb.AuthUserGrantRoleRequest) (*pb.AuthUser
 		}
 	}
 
+	if r.ExpiresAt != 0 && r.User == rootUser && r.Role == rootRole {
+		// 'root' user cannot lose 'root' role
+		return nil, ErrInvalidAuthMgmt
+	}
+
 	idx := sort.SearchStrings(user.Roles, r.Role)
 	if idx < len(user.Roles) && user.Roles[idx] == r.Role {
-		as.lg.Warn(
-			"ignored grant role request to a user",
-			zap.String("user-name", r.User),
-			zap.Strings("user-roles", user.Roles),
-			zap.String("duplicate-role-name", r.Role),
-		)
-		return &pb.AuthUserGrantRoleResponse{}, nil
+		// granting a role granted until a time again changes the time
+		i, ok := roleExpirationIndex(user, r.Role)
+		if !ok || user.RoleExpirations[i].ExpiresAt == r.ExpiresAt {
+			as.lg.Warn(
+				"ignored grant role request to a user",
+				zap.String("user-name", r.User),
+				zap.Strings("user-roles", user.Roles),
+				zap.String("duplicate-role-name", r.Role),
+			)
+			return &pb.AuthUserGrantRoleResponse{}, nil
+		}
+	} else {
+		user.Roles = append(user.Roles, r.Role)
+		sort.Strings(user.Roles)
 	}
-
-	user.Roles = append(user.Roles, r.Role)
-	sort.Strings(user.Roles)
+	setRoleExpiration(user, r.Role, r.ExpiresAt)
 
 	tx.UnsafePutUser(user)
 
@@ -567,6 +704,10 @@ func (as *authStore) UserGet(r *pb.AuthUserGetRequest) (*pb.AuthUserGetResponse,
 
 	var resp pb.AuthUserGetResponse
 	resp.Roles = append(resp.Roles, user.Roles...)
+	resp.RoleExpirations = user.RoleExpirations
+	if user.Options != nil {
+		resp.HomePrefix = user.Options.HomePrefix
+	}
 	return &resp, nil
 }
 
@@ -600,9 +741,11 @@ func (as *authStore) UserRevokeRole(r *pb.AuthUserRevokeRoleRequest) (*pb.AuthUs
 	}
 
 	updatedUser := &authpb.User{
-		Name:     user.Name,
-		Password: user.Password,
-		Options:  user.Options,
+		Name:              user.Name,
+		Password:          user.Password,
+		Options:           user.Options,
+		PasswordChangedAt: user.PasswordChangedAt,
+		RoleExpirations:   roleExpirationsWithout(user.RoleExpirations, r.Role),
 	}
 
 	for _, role := range user.Roles {
@@ -642,6 +785,7 @@ func (as *authStore) RoleGet(r *pb.AuthRoleGetRequest) (*pb.AuthRoleGetResponse,
 		resp.Perm = append(resp.Perm, &rootPerm)
 	} else {
 		resp.Perm = append(resp.Perm, role.KeyPermission...)
+		resp.AdminPerms = append(resp.AdminPerms, role.AdminPermission...)
 	}
 	return &resp, nil
 }
@@ -667,17 +811,32 @@ func (as *authStore) RoleRevokePermission(r *pb.AuthRoleRevokePermissionRequest)
 	}
 
 	updatedRole := &authpb.Role{
-		Name: role.Name,
+		Name:            role.Name,
+		KeyPermission:   role.KeyPermission,
+		AdminPermission: role.AdminPermission,
 	}
 
-	for _, perm := range role.KeyPermission {
-		if !bytes.Equal(perm.Key, r.Key) || !bytes.Equal(perm.RangeEnd, r.RangeEnd) {
-			updatedRole.KeyPermission = append(updatedRole.KeyPermission, perm)
+	if len(r.Key) != 0 || len(r.AdminPerms) == 0 {
+		updatedRole.KeyPermission = nil
+		for _, perm := range role.KeyPermission {
+			if !bytes.Equal(perm.Key, r.Key) || !bytes.Equal(perm.RangeEnd, r.RangeEnd) {
+				updatedRole.KeyPermission = append(updatedRole.KeyPermission, perm)
+			}
+		}
+		if len(role.KeyPermission) == len(updatedRole.KeyPermission) {
+			return nil, ErrPermissionNotGranted
 		}
 	}
-
-	if len(role.KeyPermission) == len(updatedRole.KeyPermission) {
-		return nil, ErrPermissionNotGranted
+	if len(r.AdminPerms) != 0 {
+		updatedRole.AdminPermission = nil
+		for _, perm := range role.AdminPermission {
+			if !hasAdminPerm(r.AdminPerms, perm) {
+				updatedRole.AdminPermission = append(updatedRole.AdminPermission, perm)
+			}
+		}
+		if len(role.AdminPermission)-len(updatedRole.AdminPermission) != len(uniqueSortedAdminPerms(r.AdminPerms)) {
+			return nil, ErrPermissionNotGranted
+		}
 	}
 
 	tx.UnsafePutRole(updatedRole)
@@ -693,6 +852,7 @@ func (as *authStore) RoleRevokePermission(r *pb.AuthRoleRevokePermissionRequest)
 		zap.String("role-name", r.Role),
 		zap.String("key", string(r.Key)),
 		zap.String("range-end", string(r.RangeEnd)),
+		zap.Stringer("admin-permissions", adminPerms(r.AdminPerms)),
 	)
 	return &pb.AuthRoleRevokePermissionResponse{}, nil
 }
@@ -717,9 +877,11 @@ func (as *authStore) RoleDelete(r *pb.AuthRoleDeleteRequest) (*pb.AuthRoleDelete
 	users := tx.UnsafeGetAllUsers()
 	for _, user := range users {
 		updatedUser := &authpb.User{
-			Name:     user.Name,
-			Password: user.Password,
-			Options:  user.Options,
+			Name:              user.Name,
+			Password:          user.Password,
+			Options:           user.Options,
+			PasswordChangedAt: user.PasswordChangedAt,
+			RoleExpirations:   roleExpirationsWithout(user.RoleExpirations, r.Role),
 		}
 
 		for _, role := range user.Roles {
@@ -788,7 +950,7 @@ func (perms permSlice) Swap(i, j int) {
 }
 
 func (as *authStore) RoleGrantPermission(r *pb.AuthRoleGrantPermissionRequest) (*pb.AuthRoleGrantPermissionResponse, error) {
-	if r.Perm == nil {
+	if r.Perm == nil && len(r.AdminPerms) == 0 {
 		return nil, ErrPermissionNotGiven
 	}
 
@@ -801,6 +963,20 @@ func (as *authStore) RoleGrantPermission(r *pb.AuthRoleGrantPermissionRequest) (
 		return nil, ErrRoleNotFound
 	}
 
+	if len(r.AdminPerms) != 0 {
+		role.AdminPermission = uniqueSortedAdminPerms(append(role.AdminPermission, r.AdminPerms...))
+	}
+	if r.Perm == nil {
+		tx.UnsafePutRole(role)
+		as.commitRevision(tx)
+		as.lg.Info(
+			"granted admin permissions to a role",
+			zap.String("role-name", r.Name),
+			zap.Stringer("admin-permissions", adminPerms(r.AdminPerms)),
+		)
+		return &pb.AuthRoleGrantPermissionResponse{}, nil
+	}
+
 	idx := sort.Search(len(role.KeyPermission), func(i int) bool {
 		return bytes.Compare(role.KeyPermission[i].Key, r.Perm.Key) >= 0
 	})
@@ -836,12 +1012,13 @@ func (as *authStore) RoleGrantPermission(r *pb.AuthRoleGrantPermissionRequest) (
 	return &pb.AuthRoleGrantPermissionResponse{}, nil
 }
 
-func (as *authStore) isOpPermitted(userName string, revision uint64, key, rangeEnd []byte, permTyp authpb.Permission_Type) error {
+func (as *authStore) isOpPermitted(authInfo *AuthInfo, key, rangeEnd []byte, permTyp authpb.Permission_Type) error {
 	// TODO(mitake): this function would be costly so we need a caching mechanism
 	if !as.IsAuthEnabled() {
 		return nil
 	}
 
+	userName, revision := authInfo.Username, authInfo.Revision
 	// only gets rev == 0 when passed AuthInfo{}; no user given
 	if revision == 0 {
 		return ErrUserEmpty
@@ -861,16 +1038,23 @@ func (as *authStore) isOpPermitted(userName string, revision uint64, key, rangeE
 	defer tx.Unlock()
 
 	user := tx.UnsafeGetUser(userName)
-	if user == nil {
+	if user == nil && len(authInfo.Roles) == 0 {
 		as.lg.Error("cannot find a user for permission check", zap.String("user-name", userName))
 		return ErrPermissionDenied
 	}
 
 	// root role should have permission on all ranges
-	if hasRootRole(user) {
+	if hasRootRole(user) || hasRole(authInfo.Roles, rootRole) {
 		return nil
 	}
 
+	if len(authInfo.Roles) > 0 {
+		// not cached, the roles granted outside of etcd varying per token
+		if perms := getMergedPerms(tx, userName, authInfo.Roles); perms != nil && checkPerms(as.lg, perms, key, rangeEnd, permTyp) {
+			return nil
+		}
+		return ErrPermissionDenied
+	}
 	if as.isRangeOpPermitted(tx, userName, key, rangeEnd, permTyp) {
 		return nil
 	}
@@ -879,15 +1063,19 @@ func (as *authStore) isOpPermitted(userName string, revision uint64, key, rangeE
 }
 
 func (as *authStore) IsPutPermitted(authInfo *AuthInfo, key []byte) error {
-	return as.isOpPermitted(authInfo.Username, authInfo.Revision, key, nil, authpb.WRITE)
+	return as.isOpPermitted(authInfo, key, nil, authpb.WRITE)
 }
 
 func (as *authStore) IsRangePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error {
-	return as.isOpPermitted(authInfo.Username, authInfo.Revision, key, rangeEnd, authpb.READ)
+	return as.isOpPermitted(authInfo, key, rangeEnd, authpb.READ)
+}
+
+func (as *authStore) IsWatchPermitted(authInfo *AuthInfo, key, rangeEnd []byte) error {
+	return as.isOpPermitted(authInfo, key, rangeEnd, authpb.WATCH)
 }
 
 func (as *authStore) IsDeleteRangePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error {
-	return as.isOpPermitted(authInfo.Username, authInfo.Revision, key, rangeEnd, authpb.WRITE)
+	return as.isOpPermitted(authInfo, key, rangeEnd, authpb.WRITE)
 }
 
 func (as *authStore) IsAdminPermitted(authInfo *AuthInfo) error {
@@ -903,6 +1091,10 @@ func (as *authStore) IsAdminPermitted(authInfo *AuthInfo) error {
 	defer tx.Unlock()
 	u := tx.UnsafeGetUser(authInfo.Username)
 
+	if hasRole(authInfo.Roles, rootRole) {
+		return nil
+	}
+
 	if u == nil {
 		return ErrUserNotFound
 	}
@@ -914,6 +1106,38 @@ func (as *authStore) IsAdminPermitted(authInfo *AuthInfo) error {
 	return nil
 }
 
+func (as *authStore) IsAdminOpPermitted(authInfo *AuthInfo, perm authpb.AdminPermission) error {
+	if !as.IsAuthEnabled() {
+		return nil
+	}
+	if authInfo == nil || authInfo.Username == "" {
+		return ErrUserEmpty
+	}
+
+	tx := as.be.ReadTx()
+	tx.Lock()
+	defer tx.Unlock()
+	u := tx.UnsafeGetUser(authInfo.Username)
+
+	if hasRootRole(u) || hasRole(authInfo.Roles, rootRole) {
+		return nil
+	}
+	if u == nil && len(authInfo.Roles) == 0 {
+		return ErrUserNotFound
+	}
+
+	roles := append([]string{}, authInfo.Roles...)
+	if u != nil {
+		roles = append(roles, u.Roles...)
+	}
+	for _, name := range roles {
+		if role := tx.UnsafeGetRole(name); role != nil && hasAdminPerm(role.AdminPermission, perm) {
+			return nil
+		}
+	}
+	return ErrPermissionDenied
+}
+
 func (as *authStore) IsAuthEnabled() bool {
 	as.enabledMu.RLock()
 	defer as.enabledMu.RUnlock()
@@ -956,6 +1180,7 @@ func NewAuthStore(lg *zap.Logger, be AuthBackend, tp TokenProvider, bcryptCost i
 	if enabled {
 		as.tokenProvider.enable()
 	}
+	as.tokenProvider.recoverRevocations(tx)
 
 	if as.Revision() == 0 {
 		as.commitRevision(tx)
@@ -970,9 +1195,48 @@ func NewAuthStore(lg *zap.Logger, be AuthBackend, tp TokenProvider, bcryptCost i
 }
 
 func hasRootRole(u *authpb.User) bool {
+	if u == nil {
+		return false
+	}
 	// u.Roles is sorted in UserGrantRole(), so we can use binary search.
-	idx := sort.SearchStrings(u.Roles, rootRole)
-	return idx != len(u.Roles) && u.Roles[idx] == rootRole
+	return hasRole(u.Roles, rootRole)
+}
+
+// hasRole returns whether the sorted roles include role.
+func hasRole(roles []string, role string) bool {
+	idx := sort.SearchStrings(roles, role)
+	return idx != len(roles) && roles[idx] == role
+}
+
+func hasAdminPerm(perms []authpb.AdminPermission, perm authpb.AdminPermission) bool {
+	for _, p := range perms {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueSortedAdminPerms(perms []authpb.AdminPermission) []authpb.AdminPermission {
+	var ps []authpb.AdminPermission
+	for _, p := range perms {
+		if !hasAdminPerm(ps, p) {
+			ps = append(ps, p)
+		}
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i] < ps[j] })
+	return ps
+}
+
+// adminPerms formats admin permissions for logging.
+type adminPerms []authpb.AdminPermission
+
+func (ps adminPerms) String() string {
+	ss := make([]string, len(ps))
+	for i, p := range ps {
+		ss[i] = p.String()
+	}
+	return strings.Join(ss, ",")
 }
 
 func (as *authStore) commitRevision(tx AuthBatchTx) {
@@ -999,9 +1263,16 @@ func (as *authStore) AuthInfoFromTLS(ctx context.Context) (ai *AuthInfo) {
 		if len(chains) < 1 {
 			continue
 		}
+		// the X.509 SVIDs of SPIFFE identify the workloads by their URI SAN,
+		// usually with no CN
+		username := chains[0].Subject.CommonName
+		if username == "" {
+			username = spiffeID(chains[0])
+		}
 		ai = &AuthInfo{
-			Username: chains[0].Subject.CommonName,
+			Username: username,
 			Revision: as.Revision(),
+			Roles:    certRoles(as.certRoleRules, chains[0]),
 		}
 		md, ok := metadata.FromIncomingContext(ctx)
 		if !ok {
@@ -1024,6 +1295,7 @@ func (as *authStore) AuthInfoFromTLS(ctx context.Context) (ai *AuthInfo) {
 			"found command name",
 			zap.String("common-name", ai.Username),
 			zap.String("user-name", ai.Username),
+			zap.Strings("roles", ai.Roles),
 			zap.Uint64("revision", ai.Revision),
 		)
 		break
@@ -1031,6 +1303,10 @@ func (as *authStore) AuthInfoFromTLS(ctx context.Context) (ai *AuthInfo) {
 	return ai
 }
 
+func (as *authStore) SetCertRoleRules(rules []CertRoleRule) {
+	as.certRoleRules = rules
+}
+
 func (as *authStore) AuthInfoFromCtx(ctx context.Context) (*AuthInfo, error) {
 	if !as.IsAuthEnabled() {
 		return nil, nil
@@ -1139,7 +1415,11 @@ func (as *authStore) WithRoot(ctx context.Context) context.Context {
 	}
 
 	var ctxForAssign context.Context
-	if ts, ok := as.tokenProvider.(*tokenSimple); ok && ts != nil {
+	tp := as.tokenProvider
+	if to, ok := tp.(*tokenOIDC); ok {
+		tp = to.TokenProvider
+	}
+	if ts, ok := tp.(*tokenSimple); ok && ts != nil {
 		ctx1 := context.WithValue(ctx, AuthenticateParamIndex{}, uint64(0))
 		prefix, err := ts.genTokenPrefix()
 		if err != nil {
@@ -1200,6 +1480,80 @@ func (as *authStore) BcryptCost() int {
 	return as.bcryptCost
 }
 
+func (as *authStore) SetPasswordPolicy(p PasswordPolicy) {
+	as.passwordPolicy = p
+}
+
+func (as *authStore) HashPassword(password string) ([]byte, error) {
+	if err := as.passwordPolicy.checkPassword(password); err != nil {
+		return nil, err
+	}
+	return as.passwordPolicy.hashPassword(password, as.bcryptCost)
+}
+
+func (as *authStore) RehashPassword(username, password string) (prev, hashed []byte, err error) {
+	tx := as.be.ReadTx()
+	tx.Lock()
+	user := tx.UnsafeGetUser(username)
+	tx.Unlock()
+	if user == nil || len(user.Password) == 0 || !as.passwordPolicy.needsRehash(user.Password, as.bcryptCost) {
+		return nil, nil, nil
+	}
+	hashed, err = as.passwordPolicy.hashPassword(password, as.bcryptCost)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user.Password, hashed, nil
+}
+
+func (as *authStore) UpdatePasswordHash(username string, prev, hashed []byte) {
+	tx := as.be.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+
+	user := tx.UnsafeGetUser(username)
+	if user == nil || !bytes.Equal(user.Password, prev) {
+		return
+	}
+	user.Password = hashed
+	// the password is the same, so the tokens and the revision are kept
+	tx.UnsafePutUser(user)
+
+	as.lg.Info("rehashed a password of a user", zap.String("user-name", username))
+}
+
+func (as *authStore) Sessions(username string) []Session {
+	ss := as.tokenProvider.sessions()
+	if username == "" {
+		return ss
+	}
+	var us []Session
+	for _, s := range ss {
+		if s.User == username {
+			us = append(us, s)
+		}
+	}
+	return us
+}
+
+func (as *authStore) SessionRevoke(index uint64, r *pb.AuthSessionRevokeRequest) (*pb.AuthSessionRevokeResponse, error) {
+	if r.ID == 0 && len(r.User) == 0 {
+		return nil, ErrUserEmpty
+	}
+	tx := as.be.BatchTx()
+	tx.Lock()
+	n := as.tokenProvider.revoke(tx, r.User, r.ID, index)
+	tx.Unlock()
+
+	as.lg.Info(
+		"revoked sessions",
+		zap.Uint64("session-id", r.ID),
+		zap.String("user-name", r.User),
+		zap.Int("revoked", n),
+	)
+	return &pb.AuthSessionRevokeResponse{Revoked: int64(n)}, nil
+}
+
 func (as *authStore) setupMetricsReporter() {
 	reportCurrentAuthRevMu.Lock()
 	reportCurrentAuthRev = func() float64 {
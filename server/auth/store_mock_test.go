@@ -17,16 +17,20 @@ package auth
 import "go.etcd.io/etcd/api/v3/authpb"
 
 type backendMock struct {
-	users    map[string]*authpb.User
-	roles    map[string]*authpb.Role
-	enabled  bool
-	revision uint64
+	users         map[string]*authpb.User
+	roles         map[string]*authpb.Role
+	enabled       bool
+	revision      uint64
+	revoked       map[uint64]RevokedToken
+	revokedBefore map[string]uint64
 }
 
 func newBackendMock() *backendMock {
 	return &backendMock{
-		users: make(map[string]*authpb.User),
-		roles: make(map[string]*authpb.Role),
+		users:         make(map[string]*authpb.User),
+		roles:         make(map[string]*authpb.Role),
+		revoked:       make(map[uint64]RevokedToken),
+		revokedBefore: make(map[string]uint64),
 	}
 }
 
@@ -129,3 +133,31 @@ func (t txMock) UnsafePutRole(role *authpb.Role) {
 func (t txMock) UnsafeDeleteRole(s string) {
 	delete(t.be.roles, s)
 }
+
+func (t txMock) UnsafePutRevokedToken(id uint64, r RevokedToken) {
+	t.be.revoked[id] = r
+}
+
+func (t txMock) UnsafeDeleteRevokedToken(id uint64) {
+	delete(t.be.revoked, id)
+}
+
+func (t txMock) UnsafePutTokensRevokedBefore(username string, index uint64) {
+	t.be.revokedBefore[username] = index
+}
+
+func (t txMock) UnsafeGetRevokedTokens() map[uint64]RevokedToken {
+	revoked := make(map[uint64]RevokedToken)
+	for id, r := range t.be.revoked {
+		revoked[id] = r
+	}
+	return revoked
+}
+
+func (t txMock) UnsafeGetTokensRevokedBefore() map[string]uint64 {
+	revokedBefore := make(map[string]uint64)
+	for u, index := range t.be.revokedBefore {
+		revokedBefore[u] = index
+	}
+	return revokedBefore
+}
@@ -349,7 +349,7 @@ func TestIsOpPermitted(t *testing.T) {
 
 	// check permission reflected to user
 
-	err = as.isOpPermitted("foo", as.Revision(), perm.Key, perm.RangeEnd, perm.PermType)
+	err = as.isOpPermitted(&AuthInfo{Username: "foo", Revision: as.Revision()}, perm.Key, perm.RangeEnd, perm.PermType)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -970,3 +970,217 @@ func TestUserChangePasswordWithOldLog(t *testing.T) {
 		t.Fatalf("expected %v, got %v", ErrUserNotFound, err)
 	}
 }
+
+func TestUserAddWithHomePrefix(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, err := as.UserAdd(&pb.AuthUserAddRequest{Name: "alice", HashedPassword: encodePassword("pwd"), Options: &authpb.UserAddOptions{HomePrefix: "users/alice/"}})
+	if err != ErrInvalidHomePrefix {
+		t.Fatalf("expected %v, got %v", ErrInvalidHomePrefix, err)
+	}
+
+	_, err = as.UserAdd(&pb.AuthUserAddRequest{Name: "alice", HashedPassword: encodePassword("pwd"), Options: &authpb.UserAddOptions{HomePrefix: "/users/alice/"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := as.UserGet(&pb.AuthUserGetRequest{Name: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "/users/alice/", u.HomePrefix)
+}
+
+func TestResolveHomeKey(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, err := as.UserAdd(&pb.AuthUserAddRequest{Name: "alice", HashedPassword: encodePassword("pwd"), Options: &authpb.UserAddOptions{HomePrefix: "/users/alice/"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := &AuthInfo{Username: "alice", Revision: as.Revision()}
+
+	tests := []struct {
+		name            string
+		authInfo        *AuthInfo
+		key, rangeEnd   []byte
+		wkey, wrangeEnd []byte
+	}{
+		{"relative key", alice, []byte("config"), nil, []byte("/users/alice/config"), nil},
+		{"relative range", alice, []byte("a"), []byte("c"), []byte("/users/alice/a"), []byte("/users/alice/c")},
+		{"relative from key", alice, []byte("a"), []byte{0}, []byte("/users/alice/a"), []byte("/users/alice0")},
+		{"absolute key", alice, []byte("/config"), nil, []byte("/config"), nil},
+		{"user without home", &AuthInfo{Username: "foo", Revision: as.Revision()}, []byte("config"), nil, []byte("config"), nil},
+		{"no user", nil, []byte("config"), nil, []byte("config"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, rangeEnd := as.ResolveHomeKey(tt.authInfo, tt.key, tt.rangeEnd)
+			assert.Equal(t, tt.wkey, key)
+			assert.Equal(t, tt.wrangeEnd, rangeEnd)
+		})
+	}
+
+	// users are implicitly permitted to access their home prefix only
+	if err = as.IsPutPermitted(alice, []byte("/users/alice/config")); err != nil {
+		t.Errorf("expected put in home prefix to be permitted, got %v", err)
+	}
+	if err = as.IsRangePermitted(alice, []byte("/users/alice/"), []byte("/users/alice0")); err != nil {
+		t.Errorf("expected range over home prefix to be permitted, got %v", err)
+	}
+	if err = as.IsPutPermitted(alice, []byte("/users/bob/config")); err != ErrPermissionDenied {
+		t.Errorf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+}
+
+func TestIsWatchPermitted(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	perms := []*authpb.Permission{
+		{PermType: authpb.READ, Key: []byte("read/"), RangeEnd: []byte("read0")},
+		{PermType: authpb.WATCH, Key: []byte("watch/"), RangeEnd: []byte("watch0")},
+		{PermType: authpb.RANGE, Key: []byte("range/"), RangeEnd: []byte("range0")},
+	}
+	for _, perm := range perms {
+		if _, err := as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{Name: "role-test", Perm: perm}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: "role-test"}); err != nil {
+		t.Fatal(err)
+	}
+	ai := &AuthInfo{Username: "foo", Revision: as.Revision()}
+
+	tests := []struct {
+		key, rangeEnd    []byte
+		rangeOK, watchOK bool
+	}{
+		{[]byte("read/a"), nil, true, true},
+		{[]byte("read/"), []byte("read0"), true, true},
+		{[]byte("watch/a"), nil, false, true},
+		{[]byte("watch/"), []byte("watch0"), false, true},
+		{[]byte("range/a"), nil, true, false},
+		{[]byte("range/"), []byte("range0"), true, false},
+		// watching beyond the granted range
+		{[]byte("watch/"), []byte("watch1"), false, false},
+	}
+	for i, tt := range tests {
+		if err := as.IsRangePermitted(ai, tt.key, tt.rangeEnd); (err == nil) != tt.rangeOK {
+			t.Errorf("#%d: range %q-%q: expected permitted %v, got %v", i, tt.key, tt.rangeEnd, tt.rangeOK, err)
+		}
+		if err := as.IsWatchPermitted(ai, tt.key, tt.rangeEnd); (err == nil) != tt.watchOK {
+			t.Errorf("#%d: watch %q-%q: expected permitted %v, got %v", i, tt.key, tt.rangeEnd, tt.watchOK, err)
+		}
+	}
+}
+
+func TestIsOpPermittedRoles(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	perm := &authpb.Permission{
+		PermType: authpb.READWRITE,
+		Key:      []byte("Keys"),
+		RangeEnd: []byte("RangeEnd"),
+	}
+	_, err := as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{
+		Name: "role-test",
+		Perm: perm,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// roles granted by the token of a user unknown to etcd
+	ai := &AuthInfo{Username: "oidc:alice", Revision: as.Revision(), Roles: []string{"role-test"}}
+	if err = as.IsPutPermitted(ai, perm.Key); err != nil {
+		t.Fatal(err)
+	}
+	if err = as.IsPutPermitted(ai, []byte("Z")); err != ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+	if err = as.IsAdminPermitted(ai); err != ErrUserNotFound {
+		t.Fatalf("expected %v, got %v", ErrUserNotFound, err)
+	}
+
+	// unknown roles grant nothing
+	ai.Roles = []string{"role-unknown"}
+	if err = as.IsPutPermitted(ai, perm.Key); err != ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+
+	ai.Roles = []string{"root"}
+	if err = as.IsPutPermitted(ai, []byte("Z")); err != nil {
+		t.Fatal(err)
+	}
+	if err = as.IsAdminPermitted(ai); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsAdminOpPermitted(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, err := as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{
+		Name:       "role-test",
+		AdminPerms: []authpb.AdminPermission{authpb.SNAPSHOT, authpb.DEFRAGMENT, authpb.SNAPSHOT},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: "role-test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rresp, err := as.RoleGet(&pb.AuthRoleGetRequest{Role: "role-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []authpb.AdminPermission{authpb.DEFRAGMENT, authpb.SNAPSHOT}, rresp.AdminPerms)
+
+	ai := &AuthInfo{Username: "foo", Revision: as.Revision()}
+	if err = as.IsAdminOpPermitted(ai, authpb.SNAPSHOT); err != nil {
+		t.Fatal(err)
+	}
+	if err = as.IsAdminOpPermitted(ai, authpb.MEMBER); err != ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+	if err = as.IsAdminPermitted(ai); err != ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+	if err = as.IsAdminOpPermitted(&AuthInfo{Username: "root", Revision: as.Revision()}, authpb.MEMBER); err != nil {
+		t.Fatal(err)
+	}
+	if err = as.IsAdminOpPermitted(&AuthInfo{Username: "oidc:alice", Revision: as.Revision(), Roles: []string{"role-test"}}, authpb.DEFRAGMENT); err != nil {
+		t.Fatal(err)
+	}
+
+	// revoking admin permissions keeps the key permissions
+	_, err = as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{
+		Name: "role-test",
+		Perm: &authpb.Permission{PermType: authpb.READ, Key: []byte("foo")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = as.RoleRevokePermission(&pb.AuthRoleRevokePermissionRequest{Role: "role-test", AdminPerms: []authpb.AdminPermission{authpb.SNAPSHOT}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = as.RoleRevokePermission(&pb.AuthRoleRevokePermissionRequest{Role: "role-test", AdminPerms: []authpb.AdminPermission{authpb.SNAPSHOT}}); err != ErrPermissionNotGranted {
+		t.Fatalf("expected %v, got %v", ErrPermissionNotGranted, err)
+	}
+	rresp, err = as.RoleGet(&pb.AuthRoleGetRequest{Role: "role-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, rresp.Perm, 1)
+	assert.Equal(t, []authpb.AdminPermission{authpb.DEFRAGMENT}, rresp.AdminPerms)
+	ai.Revision = as.Revision()
+	if err = as.IsAdminOpPermitted(ai, authpb.SNAPSHOT); err != ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", ErrPermissionDenied, err)
+	}
+}
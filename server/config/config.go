@@ -25,9 +25,16 @@ import (
 	"go.etcd.io/etcd/client/pkg/v3/transport"
 	"go.etcd.io/etcd/client/pkg/v3/types"
 	"go.etcd.io/etcd/pkg/v3/netutil"
+	"go.etcd.io/etcd/server/v3/auth"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/rafthttp"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3compactor"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3discovery"
+	"go.etcd.io/etcd/server/v3/etcdserver/degradation"
 	"go.etcd.io/etcd/server/v3/storage/datadir"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
+	"go.etcd.io/etcd/server/v3/storage/wal/archive"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/trace"
 
 	bolt "go.etcd.io/bbolt"
 	"go.uber.org/zap"
@@ -66,6 +73,13 @@ type ServerConfig struct {
 	// BackendBatchLimit is the maximum operations before commit the backend transaction.
 	BackendBatchLimit int
 
+	// DefragBatchLimit is the number of keys copied at a time by the incremental defragmentation.
+	DefragBatchLimit int
+	// DefragBatchInterval is the pause between the batches of the incremental defragmentation.
+	DefragBatchInterval time.Duration
+
+	// BackendEngine is the name of the storage engine of the backend.
+	BackendEngine string
 	// BackendFreelistType is the type of the backend boltdb freelist.
 	BackendFreelistType bolt.FreelistType
 
@@ -121,25 +135,131 @@ type ServerConfig struct {
 
 	AutoCompactionRetention time.Duration
 	AutoCompactionMode      string
+	// AutoCompactionPrefixRetentions override the periodic retention for
+	// the keys under their prefixes.
+	AutoCompactionPrefixRetentions []v3compactor.RetentionPolicy
+	// TimerJitter bounds the member specific delay before periodic timers start.
+	TimerJitter             time.Duration
 	CompactionBatchLimit    int
 	CompactionSleepInterval time.Duration
 	QuotaBackendBytes       int64
 	MaxTxnOps               uint
+	// MaxLeasesPerUser is the maximum number of leases an authenticated
+	// user can hold, 0 for no limit. It must be the same on all the members.
+	MaxLeasesPerUser int
+
+	// ValueCompression is the compression of the stored values.
+	ValueCompression string
+	// ValueCompressionThreshold is the size in bytes from which a value is compressed.
+	ValueCompressionThreshold int
+	// WALCompression is the compression of the WAL entries.
+	WALCompression string
+	// WALDurability is the durability of the WAL writes, one of the
+	// wal.Durability* values. It is wal.DurabilityNone with UnsafeNoFsync.
+	WALDurability string
+	// WALSyncInterval is the interval the WAL is fsynced at with the
+	// wal.DurabilityBatched durability.
+	WALSyncInterval time.Duration
+	// PeerCompression is the compression of the raft streams and snapshots
+	// sent to the peers.
+	PeerCompression rafthttp.PeerCompression
+	// MaxInflightMsgs limits the number of append messages sent to a
+	// follower and not yet acknowledged, DefaultMaxInflightMsgs of
+	// etcdserver if 0.
+	MaxInflightMsgs int
+	// MaxInflightBytes limits the total byte size of the entries sent to a
+	// follower and not yet acknowledged, 0 for no limit.
+	MaxInflightBytes uint64
+	// AdaptiveInflightMsgs halves the limit of inflight messages of a
+	// follower not keeping up, growing it back as it acknowledges them.
+	AdaptiveInflightMsgs bool
+	// WALIOURing writes the WAL through io_uring where available.
+	WALIOURing bool
+	// WALSegmentSize is the size in bytes from which a WAL segment is cut.
+	WALSegmentSize int64
+	// WALPreallocSize is the size in bytes preallocated for a WAL segment,
+	// WALSegmentSize if 0. The segments are not preallocated if negative.
+	WALPreallocSize int64
+	// WALTmpSegments is the number of WAL segments created ahead.
+	WALTmpSegments int
+	// EncryptionKeyProvider, if set, wraps the keys encrypting the WAL
+	// entries and the snapshots.
+	EncryptionKeyProvider encryption.KeyProvider
+	// WALArchiveSink, if set, stores the WAL segments once completed.
+	WALArchiveSink archive.Sink
+	// WALArchiveInterval is the interval between the archivings of the
+	// completed WAL segments.
+	WALArchiveInterval time.Duration
+
+	// RangeTombstoneThreshold is the number of keys from which a range deletion
+	// records a range tombstone instead of a tombstone for each of them.
+	RangeTombstoneThreshold int
+
+	// ReadCacheSize is the number of key-values decoded from the backend
+	// cached for the reads. Disabled if 0.
+	ReadCacheSize int
+
+	// WatchStuckPolicy is applied to the slow watchers whose backlog is
+	// larger than WatchStuckMaxBytes or older than WatchStuckMaxAge.
+	WatchStuckPolicy   string
+	WatchStuckMaxBytes int64
+	WatchStuckMaxAge   time.Duration
+
+	// WatchHistoryRetention is the number of revisions up to the compaction
+	// revision whose events are kept for the watchers. Disabled if 0.
+	WatchHistoryRetention int64
+
+	// BackendScrubInterval is the pause between the passes verifying the
+	// records of the backend. Disabled if 0.
+	BackendScrubInterval time.Duration
+	// BackendScrubRate is the number of backend records verified per second.
+	BackendScrubRate int
 
 	// MaxRequestBytes is the maximum request size to send over raft.
 	MaxRequestBytes uint
+	// MaxChunkedValueBytes is the maximum size of the value of a put too large
+	// to be sent over raft at once, sent in chunks instead. Disabled if 0.
+	MaxChunkedValueBytes uint
+
+	// BoundedStalenessWindow is how recently a follower or learner must have
+	// heard from the leader to bound the staleness of the reads by the commit
+	// index it knows, rather than by a read index request. Disabled if 0.
+	BoundedStalenessWindow time.Duration
 
 	WarningApplyDuration        time.Duration
 	WarningUnaryRequestDuration time.Duration
 
+	// SlowRequestThreshold is the duration after which a request is logged
+	// with the time it spent in each phase of the raft and apply pipeline.
+	// 0 disables slow request logs.
+	SlowRequestThreshold time.Duration
+
 	StrictReconfigCheck bool
 
 	// ClientCertAuthEnabled is true when cert has been signed by the client CA.
 	ClientCertAuthEnabled bool
+	// ClientCertRoleRules grant roles to the client certificates, each
+	// formatted as "attribute=pattern:role".
+	ClientCertRoleRules []string
 
 	AuthToken  string
 	BcryptCost uint
 	TokenTTL   uint
+	// AuthOIDC configures the authentication by the ID tokens of an OIDC
+	// issuer, alongside the tokens of AuthToken. No issuer disables it.
+	AuthOIDC auth.OIDCConfig
+	// AuthLDAP configures the authentication of the users absent from the
+	// auth store by an LDAP directory. No URL disables it.
+	AuthLDAP auth.LDAPConfig
+	// Authorizer, if set, allows, denies or constrains the client requests
+	// in addition to the permissions of the roles of the users.
+	Authorizer auth.Authorizer
+	// AuthLockout locks out the users and the client addresses failing to
+	// authenticate too many times in a row.
+	AuthLockout auth.LockoutPolicy
+	// AuthPasswordPolicy configures how the passwords are hashed, and the
+	// passwords the users may set.
+	AuthPasswordPolicy auth.PasswordPolicy
 
 	// InitialCorruptCheck is true to check data corruption on boot
 	// before serving any peer/client traffic.
@@ -149,11 +269,33 @@ type ServerConfig struct {
 	// PreVote is true to enable Raft Pre-Vote.
 	PreVote bool
 
+	// ElectionPriority is the priority of the member to be the leader; the
+	// leader hands its leadership over to a member of a higher priority.
+	ElectionPriority int64
+
+	// MemberLabels are the labels of the member, as its zone.
+	MemberLabels map[string]string
+	// LeaderZones are the zones the leader is preferably in, from the most
+	// preferred; the leader hands its leadership over to a member of a more
+	// preferred zone.
+	LeaderZones []string
+
+	// Witness is true for a member voting in the raft elections that keeps
+	// no KV data and serves no client traffic.
+	Witness bool
+
 	// SocketOpts are socket options passed to listener config.
 	SocketOpts transport.SocketOpts
 
 	// Logger logs server-side operations.
 	Logger *zap.Logger
+	// LoggerLevel is the level of Logger, nil if it cannot be changed at runtime.
+	LoggerLevel *zap.AtomicLevel
+
+	// AuditLogger records mutating and auth RPCs if set.
+	AuditLogger *zap.Logger
+	// AuditLogRedactValues omits the values of put requests from the audit log.
+	AuditLogRedactValues bool
 
 	ForceNewCluster bool
 
@@ -165,11 +307,16 @@ type ServerConfig struct {
 	LeaseCheckpointPersist bool
 
 	EnableGRPCGateway bool
+	// EnableGRPCReflection registers the gRPC server reflection service.
+	EnableGRPCReflection bool
 
 	// ExperimentalEnableDistributedTracing enables distributed tracing using OpenTelemetry protocol.
 	ExperimentalEnableDistributedTracing bool
 	// ExperimentalTracerOptions are options for OpenTelemetry gRPC interceptor.
 	ExperimentalTracerOptions []otelgrpc.Option
+	// ExperimentalTracerProvider provides the tracer of the spans of the raft
+	// and apply pipeline of sampled requests.
+	ExperimentalTracerProvider trace.TracerProvider
 
 	WatchProgressNotifyInterval time.Duration
 
@@ -187,6 +334,31 @@ type ServerConfig struct {
 	// be refined to mlock in-use area of bbolt only.
 	ExperimentalMemoryMlock bool `json:"experimental-memory-mlock"`
 
+	// HotKeySampleRate is the fraction of the client key accesses sampled to
+	// find the most accessed key prefixes. 0 disables the sampling.
+	HotKeySampleRate float64
+	// HotKeyWindow is the duration of the sliding window the sampled key
+	// accesses are counted over.
+	HotKeyWindow time.Duration
+
+	// ProfileApplyThreshold and ProfileFsyncThreshold trigger a capture of
+	// the goroutine, heap and CPU profiles when applying a request or an
+	// fsync of the WAL takes longer. 0 disables the trigger.
+	ProfileApplyThreshold time.Duration
+	ProfileFsyncThreshold time.Duration
+	// ProfileMinInterval is the minimum time between two profile captures.
+	ProfileMinInterval time.Duration
+	// ProfileCPUDuration is the duration of the captured CPU profile.
+	ProfileCPUDuration time.Duration
+	// ProfileURL is the HTTP endpoint the captured profiles are posted to
+	// instead of being written to ProfileDir.
+	ProfileURL string
+
+	// LeaderDegradation configures the detection of the sustained
+	// degradation of the local member, the leader transferring its
+	// leadership once degraded. No signal enabled disables it.
+	LeaderDegradation degradation.Config
+
 	// ExperimentalTxnModeWriteWithSharedBuffer enable write transaction to use
 	// a shared buffer in its readonly check operations.
 	ExperimentalTxnModeWriteWithSharedBuffer bool `json:"experimental-txn-mode-write-with-shared-buffer"`
@@ -200,6 +372,10 @@ type ServerConfig struct {
 
 	// V2Deprecation defines a phase of v2store deprecation process.
 	V2Deprecation V2DeprecationEnum `json:"v2-deprecation"`
+
+	// RuntimeConfig is the resolved configuration the member was started
+	// with by flag name, with secrets masked.
+	RuntimeConfig map[string]string
 }
 
 // VerifyBootstrap sanity-checks the initial config for bootstrap case
@@ -308,6 +484,8 @@ func (c *ServerConfig) WALDir() string {
 
 func (c *ServerConfig) SnapDir() string { return filepath.Join(c.MemberDir(), "snap") }
 
+func (c *ServerConfig) ProfileDir() string { return filepath.Join(c.MemberDir(), "profiles") }
+
 func (c *ServerConfig) ShouldDiscover() bool {
 	return c.DiscoveryURL != "" || len(c.DiscoveryCfg.Endpoints) > 0
 }
@@ -0,0 +1,192 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultClusterStartTimeout is the default time StartCluster waits for all
+// members to be ready.
+const DefaultClusterStartTimeout = time.Minute
+
+// ClusterConfig configures the members started by StartCluster.
+type ClusterConfig struct {
+	// Dir is the directory the member data directories are created in.
+	// A temporary directory, removed on Close, is used if empty.
+	Dir string
+	// Host is the address members listen on. Defaults to "127.0.0.1".
+	Host string
+	// Token is the initial cluster token. Defaults to "etcd-cluster".
+	Token string
+	// StartTimeout is the time to wait for all members to be ready.
+	// Defaults to DefaultClusterStartTimeout.
+	StartTimeout time.Duration
+	// Configure, if set, is called with the index and configuration of each
+	// member before it is started, e.g. to set the logger or TLS.
+	Configure func(i int, cfg *Config)
+}
+
+// Cluster is a set of in-process members started by StartCluster.
+type Cluster struct {
+	Members []*Etcd
+
+	dir       string
+	removeDir bool
+}
+
+// StartCluster starts n members in-process, each listening on unique ports
+// of ccfg.Host with its own data directory, wired together as a new
+// cluster. It returns once all members are ready. The members are meant to
+// be used in tests of applications that need a real multi-member cluster.
+func StartCluster(n int, ccfg ClusterConfig) (c *Cluster, err error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid cluster size %d", n)
+	}
+	if ccfg.Host == "" {
+		ccfg.Host = "127.0.0.1"
+	}
+	if ccfg.Token == "" {
+		ccfg.Token = "etcd-cluster"
+	}
+	if ccfg.StartTimeout == 0 {
+		ccfg.StartTimeout = DefaultClusterStartTimeout
+	}
+
+	c = &Cluster{dir: ccfg.Dir}
+	if c.dir == "" {
+		if c.dir, err = os.MkdirTemp("", "etcd-cluster-"); err != nil {
+			return nil, err
+		}
+		c.removeDir = true
+	}
+
+	// the listeners are opened upfront and handed over to the members, so
+	// that ports are not taken by others before the members bind them
+	var lns []net.Listener
+	defer func() {
+		if err != nil {
+			for _, l := range lns {
+				l.Close()
+			}
+			c.Close()
+			c = nil
+		}
+	}()
+	listen := func() (url.URL, error) {
+		l, lerr := net.Listen("tcp", net.JoinHostPort(ccfg.Host, "0"))
+		if lerr != nil {
+			return url.URL{}, lerr
+		}
+		lns = append(lns, l)
+		return url.URL{Scheme: "http", Host: l.Addr().String()}, nil
+	}
+
+	cfgs := make([]*Config, n)
+	cluster := make([]string, n)
+	for i := range cfgs {
+		cfg := NewConfig()
+		cfg.Name = fmt.Sprintf("m%d", i)
+		cfg.Dir = filepath.Join(c.dir, cfg.Name+".etcd")
+		cfg.InitialClusterToken = ccfg.Token
+		peer, lerr := listen()
+		if lerr != nil {
+			return c, lerr
+		}
+		client, lerr := listen()
+		if lerr != nil {
+			return c, lerr
+		}
+		cfg.LPUrls, cfg.APUrls = []url.URL{peer}, []url.URL{peer}
+		cfg.LCUrls, cfg.ACUrls = []url.URL{client}, []url.URL{client}
+		cfg.Listeners = append([]net.Listener(nil), lns[len(lns)-2:]...)
+		cfgs[i] = cfg
+		cluster[i] = fmt.Sprintf("%s=%s", cfg.Name, peer.String())
+	}
+
+	for i, cfg := range cfgs {
+		cfg.InitialCluster = strings.Join(cluster, ",")
+		if ccfg.Configure != nil {
+			ccfg.Configure(i, cfg)
+		}
+	}
+
+	// members of a new cluster only become ready once a quorum is started
+	c.Members = make([]*Etcd, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range cfgs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.Members[i], errs[i] = StartEtcd(cfgs[i])
+		}(i)
+	}
+	wg.Wait()
+	for i, serr := range errs {
+		if serr != nil {
+			return c, fmt.Errorf("failed to start member %q: %v", cfgs[i].Name, serr)
+		}
+	}
+
+	timeout := time.After(ccfg.StartTimeout)
+	for _, m := range c.Members {
+		select {
+		case <-m.Server.ReadyNotify():
+		case serr := <-m.Err():
+			return c, fmt.Errorf("member %q failed: %v", m.Config().Name, serr)
+		case <-timeout:
+			return c, fmt.Errorf("timed out after %v waiting for the cluster to be ready", ccfg.StartTimeout)
+		}
+	}
+	return c, nil
+}
+
+// ClientURLs returns the advertised client URLs of all members.
+func (c *Cluster) ClientURLs() []string {
+	var urls []string
+	for _, m := range c.Members {
+		cfg := m.Config()
+		urls = append(urls, cfg.getACURLs()...)
+	}
+	return urls
+}
+
+// Close stops all members and removes the data directories created by
+// StartCluster.
+func (c *Cluster) Close() {
+	var wg sync.WaitGroup
+	for _, m := range c.Members {
+		if m == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(m *Etcd) {
+			defer wg.Done()
+			m.Close()
+		}(m)
+	}
+	wg.Wait()
+	if c.removeDir {
+		os.RemoveAll(c.dir)
+	}
+}
@@ -17,11 +17,13 @@ package embed
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -34,15 +36,27 @@ import (
 	"go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/pkg/v3/flags"
 	"go.etcd.io/etcd/pkg/v3/netutil"
+	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/config"
 	"go.etcd.io/etcd/server/v3/etcdserver"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/rafthttp"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3compactor"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3discovery"
+	"go.etcd.io/etcd/server/v3/etcdserver/degradation"
+	"go.etcd.io/etcd/server/v3/etcdserver/diagnostics"
+	"go.etcd.io/etcd/server/v3/etcdserver/hotkey"
+	"go.etcd.io/etcd/server/v3/spiffe"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+	"go.etcd.io/etcd/server/v3/storage/wal"
+	"go.etcd.io/etcd/server/v3/storage/wal/archive"
 
 	bolt "go.etcd.io/bbolt"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc"
 	"sigs.k8s.io/yaml"
@@ -64,6 +78,12 @@ const (
 	DefaultGRPCKeepAliveTimeout        = 20 * time.Second
 	DefaultDowngradeCheckTime          = 5 * time.Second
 	DefaultWaitClusterReadyTimeout     = 5 * time.Second
+	DefaultBoundedStalenessWindow      = time.Second
+	DefaultWALArchiveInterval          = 10 * time.Second
+	DefaultUnsafeWALSyncInterval       = 100 * time.Millisecond
+	DefaultAuthorizationWebhookTimeout = time.Second
+	DefaultAuthLockoutDuration         = 30 * time.Second
+	DefaultAuthLockoutMaxDuration      = 30 * time.Minute
 
 	DefaultDiscoveryDialTimeout      = 2 * time.Second
 	DefaultDiscoveryRequestTimeOut   = 5 * time.Second
@@ -75,8 +95,10 @@ const (
 
 	DefaultLogOutput = "default"
 	JournalLogOutput = "systemd/journal"
-	StdErrLogOutput  = "stderr"
-	StdOutLogOutput  = "stdout"
+	// SystemdJournalLogOutput is an alias of JournalLogOutput.
+	SystemdJournalLogOutput = logutil.SystemdJournalLogOutput
+	StdErrLogOutput         = "stderr"
+	StdOutLogOutput         = "stdout"
 
 	// DefaultLogRotationConfig is the default configuration used for log rotation.
 	// Log rotation is disabled by default.
@@ -146,6 +168,10 @@ type Config struct {
 	Dir    string `json:"data-dir"`
 	WalDir string `json:"wal-dir"`
 
+	// PidFile is the path the PID of the process is written to on start,
+	// and removed from on Close. The data directory is locked regardless.
+	PidFile string `json:"pid-file"`
+
 	SnapshotCount uint64 `json:"snapshot-count"`
 
 	// SnapshotCatchUpEntries is the number of entries for a slow follower
@@ -199,11 +225,19 @@ type Config struct {
 	BackendBatchInterval time.Duration `json:"backend-batch-interval"`
 	// BackendBatchLimit is the maximum operations before commit the backend transaction.
 	BackendBatchLimit int `json:"backend-batch-limit"`
+	// BackendEngine is the name of the storage engine of the backend. bbolt
+	// is the default, other engines can be registered with backend.RegisterEngine.
+	BackendEngine string `json:"backend-engine"`
 	// BackendFreelistType specifies the type of freelist that boltdb backend uses (array and map are supported types).
 	BackendFreelistType string `json:"backend-bbolt-freelist-type"`
 	QuotaBackendBytes   int64  `json:"quota-backend-bytes"`
 	MaxTxnOps           uint   `json:"max-txn-ops"`
 	MaxRequestBytes     uint   `json:"max-request-bytes"`
+	// MaxLeasesPerUser is the maximum number of leases an authenticated
+	// user, by password or client certificate, can hold. 0 is no limit.
+	// It is enforced when the grants are applied, so it must be the same
+	// on all the members.
+	MaxLeasesPerUser int `json:"max-leases-per-user"`
 
 	LPUrls, LCUrls []url.URL
 	APUrls, ACUrls []url.URL
@@ -215,6 +249,11 @@ type Config struct {
 	// that are automatically generated by etcd when you specify ClientAutoTLS and PeerAutoTLS,
 	// the unit is year, and the default is 1
 	SelfSignedCertValidity uint `json:"self-signed-cert-validity"`
+	// SPIFFEWorkloadAPIAddr is the address of the SPIFFE Workload API,
+	// "unix:///path/to/socket" or "tcp://host:port", the X.509 SVIDs and the
+	// trust bundles of which replace the cert, key and trusted CA files of
+	// the client and peer TLS that have none. Empty disables it.
+	SPIFFEWorkloadAPIAddr string `json:"spiffe-workload-api-addr"`
 
 	// CipherSuites is a list of supported TLS cipher suites between
 	// client/server and peers. If empty, Go auto-populates the list.
@@ -241,6 +280,15 @@ type Config struct {
 	// If no time unit is provided and compaction mode is 'periodic',
 	// the unit defaults to hour. For example, '5' translates into 5-hour.
 	AutoCompactionRetention string `json:"auto-compaction-retention"`
+	// TimerJitter is the upper bound of the delay applied to the start of
+	// the auto compaction timer. The actual delay is derived from the member
+	// ID, so it is stable across restarts of the same member.
+	TimerJitter time.Duration `json:"timer-jitter"`
+
+	// GracefulShutdownTimeout is how long Close waits for in-flight client
+	// requests and streams to complete, after leadership is transferred.
+	// 0 means the request timeout, which is derived from ElectionMs.
+	GracefulShutdownTimeout time.Duration `json:"graceful-shutdown-timeout"`
 
 	// GRPCKeepAliveMinTime is the minimum interval that a client should
 	// wait before pinging server. When client pings "too fast", server
@@ -259,6 +307,9 @@ type Config struct {
 
 	// SocketOpts are socket options passed to listener config.
 	SocketOpts transport.SocketOpts `json:"socket-options"`
+	// UnixSocketMode is the octal file mode, e.g. "0660", of the socket
+	// files of unix:// and unixs:// listen URLs. Empty keeps the umask.
+	UnixSocketMode string `json:"unix-socket-mode"`
 
 	// PreVote is true to enable Raft Pre-Vote.
 	// If enabled, Raft runs an additional election phase
@@ -308,14 +359,119 @@ type Config struct {
 	//	embed.StartEtcd(cfg)
 	ServiceRegister func(*grpc.Server) `json:"-"`
 
+	// Listeners are already open listeners, e.g. passed by systemd socket
+	// activation. A listener whose address matches one of LPUrls or LCUrls is
+	// served instead of listening on that URL; the others are left unused.
+	Listeners []net.Listener `json:"-"`
+
 	AuthToken  string `json:"auth-token"`
 	BcryptCost uint   `json:"bcrypt-cost"`
 
 	//The AuthTokenTTL in seconds of the simple token
 	AuthTokenTTL uint `json:"auth-token-ttl"`
 
+	// AuthOIDCIssuerURL is the URL of the OpenID Connect issuer whose ID
+	// tokens authenticate the users, alongside the tokens of AuthToken.
+	// Empty disables the OIDC tokens.
+	AuthOIDCIssuerURL string `json:"auth-oidc-issuer-url"`
+	// AuthOIDCClientID is the audience the OIDC tokens must be issued for.
+	AuthOIDCClientID string `json:"auth-oidc-client-id"`
+	// AuthOIDCUsernameClaim is the claim of the user name of the OIDC tokens.
+	AuthOIDCUsernameClaim string `json:"auth-oidc-username-claim"`
+	// AuthOIDCUsernamePrefix is prepended to the user names of the OIDC
+	// tokens, so that they do not clash with the users of etcd. It must not
+	// be empty.
+	AuthOIDCUsernamePrefix string `json:"auth-oidc-username-prefix"`
+	// AuthOIDCRoleRules grant roles to the users of the OIDC tokens having a
+	// claim of a given value, each formatted as "claim=value:role". The users
+	// granted roles do not need to exist in etcd.
+	AuthOIDCRoleRules []string `json:"auth-oidc-role-rules"`
+
+	// AuthLDAPURL is the URL of the LDAP directory the users absent from the
+	// auth store authenticate against, ldap:// or ldaps://. Empty disables
+	// the LDAP authentication.
+	AuthLDAPURL string `json:"auth-ldap-url"`
+	// AuthLDAPBindDNTemplates are the DNs the users bind to the directory as,
+	// tried in order, with "%s" replaced by the user name.
+	AuthLDAPBindDNTemplates []string `json:"auth-ldap-bind-dn-templates"`
+	// AuthLDAPGroupAttribute is the attribute of the entries of the users
+	// listing the DNs of their groups.
+	AuthLDAPGroupAttribute string `json:"auth-ldap-group-attribute"`
+	// AuthLDAPRoleRules grant roles to the members of the groups, each
+	// formatted as "group DN:role". The users granted no role fail to
+	// authenticate.
+	AuthLDAPRoleRules []string `json:"auth-ldap-role-rules"`
+	// AuthLDAPCAFile is the file of the CA certificates verifying the
+	// certificate of an ldaps:// directory.
+	AuthLDAPCAFile string `json:"auth-ldap-ca-file"`
+
+	// ClientCertRoleRules grant roles to the client certificates authenticating
+	// the users with ClientTLSInfo.ClientCertAuth, each formatted as
+	// "attribute=pattern:role". The attribute is one of "cn", "o" and "ou" of
+	// the subject, or "dns", "email", "uri" and "ip" of the subject alternative
+	// names, matched against the shell pattern. The users granted roles do not
+	// need to exist in etcd.
+	ClientCertRoleRules []string `json:"client-cert-role-rules"`
+
+	// AuthorizationWebhookURL is the URL the client requests are posted to as
+	// JSON, with the authenticated user, the gRPC method and the key ranges,
+	// to be allowed, denied or constrained outside of etcd. Empty disables it.
+	AuthorizationWebhookURL string `json:"authorization-webhook-url"`
+	// AuthorizationWebhookTimeout is the timeout of the requests to the
+	// authorization webhook, after which the client requests are denied.
+	AuthorizationWebhookTimeout time.Duration `json:"authorization-webhook-timeout"`
+	// Authorizer, if set, authorizes the client requests instead of the
+	// AuthorizationWebhookURL, so that embedding applications can evaluate
+	// their policies in process.
+	Authorizer auth.Authorizer `json:"-"`
+
+	// AuthLockoutThreshold is the number of failed authentications in a row
+	// locking out a user, or a client address, on a member. Zero disables the
+	// lockouts.
+	AuthLockoutThreshold int `json:"auth-lockout-threshold"`
+	// AuthLockoutDuration is the duration of the first lockout, doubled by each
+	// following one.
+	AuthLockoutDuration time.Duration `json:"auth-lockout-duration"`
+	// AuthLockoutMaxDuration caps the duration of the lockouts. The failures
+	// are forgotten after AuthLockoutMaxDuration without any.
+	AuthLockoutMaxDuration time.Duration `json:"auth-lockout-max-duration"`
+
+	// AuthPasswordHash is the algorithm hashing the passwords, "bcrypt" or
+	// "argon2id". The passwords hashed otherwise are rehashed on login.
+	AuthPasswordHash string `json:"auth-password-hash"`
+	// AuthArgon2Time, AuthArgon2Memory, in KiB, and AuthArgon2Threads are the
+	// parameters of argon2id.
+	AuthArgon2Time    uint `json:"auth-argon2-time"`
+	AuthArgon2Memory  uint `json:"auth-argon2-memory"`
+	AuthArgon2Threads uint `json:"auth-argon2-threads"`
+	// AuthPasswordMinLength is the minimum number of characters of the
+	// passwords set.
+	AuthPasswordMinLength int `json:"auth-password-min-length"`
+	// AuthPasswordMinClasses is the minimum number of classes of characters,
+	// among lower case letters, upper case letters, digits and others, of the
+	// passwords set.
+	AuthPasswordMinClasses int `json:"auth-password-min-classes"`
+	// AuthPasswordMaxAge, if positive, expires the passwords of the users
+	// other than root when they get older.
+	AuthPasswordMaxAge time.Duration `json:"auth-password-max-age"`
+
 	ExperimentalInitialCorruptCheck bool          `json:"experimental-initial-corrupt-check"`
 	ExperimentalCorruptCheckTime    time.Duration `json:"experimental-corrupt-check-time"`
+	// ExperimentalElectionPriority is the priority of the member to be the leader, published in
+	// its membership attributes: the leader hands its leadership over to the member of the highest
+	// priority above its own, once connected and caught up with its log.
+	ExperimentalElectionPriority int64 `json:"experimental-election-priority"`
+	// ExperimentalMemberLabels are the comma separated key=value labels of the member, published
+	// in its membership attributes, e.g. "zone=us-east-1a,disk=ssd".
+	ExperimentalMemberLabels string `json:"experimental-member-labels"`
+	// ExperimentalLeaderZones are the comma separated zones the leader is preferably in, from the
+	// most preferred: the leader hands its leadership over to a member of a more preferred zone,
+	// by its "zone" label, before considering the election priorities.
+	ExperimentalLeaderZones string `json:"experimental-leader-zones"`
+	// ExperimentalWitness runs the member as a witness: it votes in the raft elections and
+	// persists the raft log, but does not apply the KV requests and rejects the client requests
+	// other than Status, MemberList, Alarm and Defragment, and hands its leadership over to another member once elected.
+	ExperimentalWitness bool `json:"experimental-witness"`
 	// ExperimentalEnableLeaseCheckpoint enables leader to send regular checkpoints to other members to prevent reset of remaining TTL on leader change.
 	ExperimentalEnableLeaseCheckpoint bool `json:"experimental-enable-lease-checkpoint"`
 	// ExperimentalEnableLeaseCheckpointPersist enables persisting remainingTTL to prevent indefinite auto-renewal of long lived leases. Always enabled in v3.6. Should be used to ensure smooth upgrade from v3.5 clusters with this feature enabled.
@@ -327,6 +483,90 @@ type Config struct {
 	// ExperimentalCompactionSleepInterval is the sleep interval between every etcd compaction loop.
 	ExperimentalCompactionSleepInterval     time.Duration `json:"experimental-compaction-sleep-interval"`
 	ExperimentalWatchProgressNotifyInterval time.Duration `json:"experimental-watch-progress-notify-interval"`
+	// ExperimentalDefragBatchLimit is the number of keys copied at a time by the incremental defragmentation,
+	// which keeps serving the requests between the batches. Defragmentation stops the world if not positive.
+	ExperimentalDefragBatchLimit int `json:"experimental-defrag-batch-limit"`
+	// ExperimentalDefragBatchInterval is the pause between the batches of the incremental defragmentation.
+	ExperimentalDefragBatchInterval time.Duration `json:"experimental-defrag-batch-interval"`
+	// ExperimentalValueCompression is the compression of the stored values: "none", "zstd" or "lz4".
+	// The values already stored compressed are read whatever the compression.
+	ExperimentalValueCompression string `json:"experimental-value-compression"`
+	// ExperimentalValueCompressionThreshold is the size in bytes from which a value is compressed.
+	ExperimentalValueCompressionThreshold int `json:"experimental-value-compression-threshold"`
+	// ExperimentalWALCompression is the compression of the WAL entries: "none" or "zstd".
+	// The WAL segments are read whatever the compression of their entries.
+	ExperimentalWALCompression string `json:"experimental-wal-compression"`
+	// ExperimentalPeerCompression is the compression of the raft streams and snapshots sent to the
+	// peers accepting it: "none" or "zstd", followed by the compressions by peer as
+	// <member-id>=<compression>, e.g. "none,8211f1d0f64f3269=zstd".
+	ExperimentalPeerCompression string `json:"experimental-peer-compression"`
+	// ExperimentalMaxInflightMsgs limits the number of append messages the leader sends to a follower
+	// and not yet acknowledged.
+	ExperimentalMaxInflightMsgs int `json:"experimental-max-inflight-msgs"`
+	// ExperimentalMaxInflightBytes limits the total byte size of the entries the leader sends to a
+	// follower and not yet acknowledged, bounding the memory a slow follower holds on the leader.
+	// 0 for no limit.
+	ExperimentalMaxInflightBytes uint64 `json:"experimental-max-inflight-bytes"`
+	// ExperimentalAdaptiveInflightMsgs halves the limit of inflight messages of a follower the leader
+	// fails to send messages to, growing it back by one message per acknowledgement.
+	ExperimentalAdaptiveInflightMsgs bool `json:"experimental-adaptive-inflight-msgs"`
+	// ExperimentalWALIOURing submits the WAL writes pending on a sync along with the fdatasync
+	// through io_uring, falling back to the regular writes where io_uring is not available.
+	ExperimentalWALIOURing bool `json:"experimental-wal-io-uring"`
+	// ExperimentalWALSegmentSize is the size in bytes from which a WAL segment is cut.
+	ExperimentalWALSegmentSize int64 `json:"experimental-wal-segment-size"`
+	// ExperimentalWALPreallocSize is the size in bytes preallocated for a WAL segment, the
+	// segment size if 0. The segments are not preallocated if negative.
+	ExperimentalWALPreallocSize int64 `json:"experimental-wal-prealloc-size"`
+	// ExperimentalWALTmpSegments is the number of WAL segments created ahead as .tmp files.
+	ExperimentalWALTmpSegments int `json:"experimental-wal-tmp-segments"`
+	// ExperimentalEncryptionKeyFile is the file of the key encryption keys wrapping the keys
+	// encrypting the WAL entries and the snapshots, the first one wrapping the new keys.
+	// The backend database and the *.snap.db snapshots received from the peers are not
+	// encrypted, so the key-values at rest must be protected by encrypting the disk.
+	ExperimentalEncryptionKeyFile string `json:"experimental-encryption-key-file"`
+	// EncryptionKeyProvider, if set, wraps the keys encrypting the WAL entries and the
+	// snapshots instead of the keys of ExperimentalEncryptionKeyFile, so that embedding
+	// applications can keep them in a key management service.
+	EncryptionKeyProvider encryption.KeyProvider `json:"-"`
+	// ExperimentalWALArchive is the sink the WAL segments are archived to once completed:
+	// "file:///path/to/dir", "s3://bucket/prefix?endpoint=<url>" or "exec:/path/to/command".
+	ExperimentalWALArchive string `json:"experimental-wal-archive"`
+	// ExperimentalWALArchiveInterval is the interval between the archivings of the completed WAL segments.
+	ExperimentalWALArchiveInterval time.Duration `json:"experimental-wal-archive-interval"`
+	// ExperimentalRangeTombstoneThreshold is the number of keys from which a range deletion records
+	// a single range tombstone, applied to the keys by the compaction, instead of a tombstone for
+	// each of them. It is disabled if 0 and must be the same on all the members.
+	ExperimentalRangeTombstoneThreshold int `json:"experimental-range-tombstone-threshold"`
+	// ExperimentalReadCacheSize is the number of key-values decoded from the backend cached for the reads,
+	// the revision of a key superseded by a write being evicted from the cache. It is disabled if 0.
+	ExperimentalReadCacheSize int `json:"experimental-read-cache-size"`
+	// ExperimentalWatchStuckPolicy is applied to the slow watchers whose backlog is larger than
+	// ExperimentalWatchStuckMaxBytes or older than ExperimentalWatchStuckMaxAge: "cancel", "pause" or "compress".
+	ExperimentalWatchStuckPolicy string `json:"experimental-watch-stuck-policy"`
+	// ExperimentalWatchStuckMaxBytes is the size in bytes of the backlog a slow watcher is stuck from. It is disabled if 0.
+	ExperimentalWatchStuckMaxBytes int64 `json:"experimental-watch-stuck-max-bytes"`
+	// ExperimentalWatchStuckMaxAge is the time a slow watcher is blocked for from which it is stuck. It is disabled if 0.
+	ExperimentalWatchStuckMaxAge time.Duration `json:"experimental-watch-stuck-max-age"`
+	// ExperimentalWatchHistoryRetention is the number of revisions up to the compaction revision whose events
+	// are kept for the watchers starting from a compacted revision. It is disabled if 0.
+	ExperimentalWatchHistoryRetention int64 `json:"experimental-watch-history-retention"`
+	// ExperimentalBackendScrubInterval is the pause between the background passes verifying the pages and
+	// records of the backend, raising a CORRUPT alarm on the first corruption found. It is disabled if 0.
+	ExperimentalBackendScrubInterval time.Duration `json:"experimental-backend-scrub-interval"`
+	// ExperimentalBackendScrubRate is the number of backend records verified per second by the scrubbing.
+	ExperimentalBackendScrubRate int `json:"experimental-backend-scrub-rate"`
+	// ExperimentalMaxChunkedValueBytes is the maximum size of the value of a put larger than the
+	// maximum request size, proposed in chunks staged in the backend then assembled. It is disabled if 0.
+	ExperimentalMaxChunkedValueBytes uint `json:"experimental-max-chunked-value-bytes"`
+	// ExperimentalAutoCompactionPrefixRetention overrides the periodic auto compaction retention for the keys
+	// under the given prefixes, as comma separated "prefix=duration" pairs (e.g. "/events/=1h,/config/=168h").
+	// The keys under several prefixes are retained for the retention of the longest one.
+	ExperimentalAutoCompactionPrefixRetention string `json:"experimental-auto-compaction-prefix-retention"`
+	// ExperimentalBoundedStalenessWindow is how recently a follower or learner must have heard from the leader
+	// to serve the reads with a bounded staleness against the commit index it knows. Otherwise, it gets the commit
+	// index of the leader by a read index request. Every read uses a read index request if 0.
+	ExperimentalBoundedStalenessWindow time.Duration `json:"experimental-bounded-staleness-window"`
 	// ExperimentalWarningApplyDuration is the time duration after which a warning is generated if applying request
 	// takes more time than this value.
 	ExperimentalWarningApplyDuration time.Duration `json:"experimental-warning-apply-duration"`
@@ -338,6 +578,39 @@ type Config struct {
 	ExperimentalWarningUnaryRequestDuration time.Duration `json:"experimental-warning-unary-request-duration"`
 	// ExperimentalMaxLearners sets a limit to the number of learner members that can exist in the cluster membership.
 	ExperimentalMaxLearners int `json:"experimental-max-learners"`
+	// ExperimentalHotKeySampleRate is the fraction of the client key accesses sampled to find the most
+	// accessed key prefixes. 0 disables the sampling.
+	ExperimentalHotKeySampleRate float64 `json:"experimental-hot-key-sample-rate"`
+	// ExperimentalHotKeyWindow is the duration of the sliding window the sampled key accesses are counted over.
+	ExperimentalHotKeyWindow time.Duration `json:"experimental-hot-key-window"`
+	// ExperimentalProfileApplyThreshold is the apply duration of a request after which goroutine, heap
+	// and CPU profiles are captured to the member directory. 0 disables the trigger.
+	ExperimentalProfileApplyThreshold time.Duration `json:"experimental-profile-apply-threshold"`
+	// ExperimentalProfileFsyncThreshold is the WAL fsync duration after which profiles are captured.
+	// 0 disables the trigger.
+	ExperimentalProfileFsyncThreshold time.Duration `json:"experimental-profile-fsync-threshold"`
+	// ExperimentalProfileMinInterval is the minimum time between two profile captures.
+	ExperimentalProfileMinInterval time.Duration `json:"experimental-profile-min-interval"`
+	// ExperimentalProfileCPUDuration is the duration of the captured CPU profiles.
+	ExperimentalProfileCPUDuration time.Duration `json:"experimental-profile-cpu-duration"`
+	// ExperimentalProfileURL is the HTTP endpoint the captured profiles are posted to instead of
+	// being written to the member directory.
+	ExperimentalProfileURL string `json:"experimental-profile-url"`
+
+	// ExperimentalLeaderDegradationFsyncThreshold is the mean WAL fsync duration above which the
+	// disk of the local member is degraded. 0 disables the signal.
+	ExperimentalLeaderDegradationFsyncThreshold time.Duration `json:"experimental-leader-degradation-fsync-threshold"`
+	// ExperimentalLeaderDegradationCPUStealThreshold is the percentage of the CPU time stolen by the
+	// hypervisor above which the CPU of the local member is degraded. 0 disables the signal.
+	ExperimentalLeaderDegradationCPUStealThreshold float64 `json:"experimental-leader-degradation-cpu-steal-threshold"`
+	// ExperimentalLeaderDegradationMemoryPressureThreshold is the percentage of the time some tasks
+	// stalled on memory over 10 seconds, from the Linux pressure stall information, above which the
+	// memory of the local member is degraded. 0 disables the signal.
+	ExperimentalLeaderDegradationMemoryPressureThreshold float64 `json:"experimental-leader-degradation-memory-pressure-threshold"`
+	// ExperimentalLeaderDegradationDuration is the duration a signal must stay above its threshold
+	// for the local member to be degraded, then below it to be recovered. A degraded leader
+	// transfers its leadership to another voting member.
+	ExperimentalLeaderDegradationDuration time.Duration `json:"experimental-leader-degradation-duration"`
 
 	// ForceNewCluster starts a new cluster even if previously started; unsafe.
 	ForceNewCluster bool `json:"force-new-cluster"`
@@ -382,21 +655,63 @@ type Config struct {
 	EnableLogRotation bool `json:"enable-log-rotation"`
 	// LogRotationConfigJSON is a passthrough allowing a log rotation JSON config to be passed directly.
 	LogRotationConfigJSON string `json:"log-rotation-config-json"`
+	// SlowRequestThreshold is the duration after which a request is logged with
+	// its key ranges and the time it spent waiting in queue, fsyncing the WAL
+	// and applying. 0 disables slow request logs.
+	SlowRequestThreshold time.Duration `json:"slow-request-threshold"`
 	// ZapLoggerBuilder is used to build the zap logger.
 	ZapLoggerBuilder func(*Config) error
+	// ZapLoggerCores are zap cores the server logs are also written to, so
+	// that embedding applications can ship them to their own pipeline. Each
+	// core filters the entries with its own level.
+	ZapLoggerCores []zapcore.Core `json:"-"`
+	// ZapLoggerHooks are called with each entry the server logs, so that
+	// embedding applications can capture specific events programmatically.
+	ZapLoggerHooks []func(zapcore.Entry) error `json:"-"`
+
+	// AuditLogOutput is "stderr", "stdout" or a file path to write the audit
+	// log of all the RPCs but the read-only ones to. Audit logging is
+	// disabled if empty.
+	AuditLogOutput string `json:"audit-log-output"`
+	// AuditLogRotationConfigJSON is the lumberjack configuration used to rotate
+	// an AuditLogOutput file.
+	AuditLogRotationConfigJSON string `json:"audit-log-rotation-config-json"`
+	// AuditLogRedactValues omits the values of put requests from the audit log.
+	AuditLogRedactValues bool `json:"audit-log-redact-values"`
+	// AuditLogger, if set, receives the audit log instead of AuditLogOutput,
+	// so that embedding applications can ship it to their own sink.
+	AuditLogger *zap.Logger `json:"-"`
 
 	// logger logs server-side operations. The default is nil,
 	// and "setupLogging" must be called before starting server.
 	// Do not set logger directly.
 	loggerMu *sync.RWMutex
 	logger   *zap.Logger
+	// configFile is the path the configuration was loaded from, if any.
+	configFile string
+	// configDir is the directory the configuration was loaded from, if any.
+	configDir string
+	// logLevel is the level of the logger built by etcd, adjusted on ReloadConfig.
+	// It is nil if the logger was provided through ZapLoggerBuilder.
+	logLevel *zap.AtomicLevel
 	// EnableGRPCGateway enables grpc gateway.
 	// The gateway translates a RESTful HTTP API into gRPC.
 	EnableGRPCGateway bool `json:"enable-grpc-gateway"`
+	// EnableGRPCReflection registers the gRPC server reflection service,
+	// so that tools like grpcurl can introspect the API.
+	EnableGRPCReflection bool `json:"enable-grpc-reflection"`
 
 	// UnsafeNoFsync disables all uses of fsync.
 	// Setting this is unsafe and will cause data loss.
 	UnsafeNoFsync bool `json:"unsafe-no-fsync"`
+	// UnsafeWALDurability is the durability of the raft log writes: "fsync", "batched" to fsync
+	// the WAL every UnsafeWALSyncInterval, or "none" to leave it to the OS. Other than "fsync" is
+	// unsafe: the entries acknowledged since the last fsync are lost on a power failure, which
+	// may lose committed data if several members fail at once. The members relaxing it publish
+	// it in their membership attributes.
+	UnsafeWALDurability string `json:"unsafe-wal-durability"`
+	// UnsafeWALSyncInterval is the interval the WAL is fsynced at with the "batched" durability.
+	UnsafeWALSyncInterval time.Duration `json:"unsafe-wal-sync-interval"`
 
 	ExperimentalDowngradeCheckTime time.Duration `json:"experimental-downgrade-check-time"`
 
@@ -445,8 +760,9 @@ type securityConfig struct {
 	AutoTLS        bool   `json:"auto-tls"`
 }
 
-// NewConfig creates a new Config populated with default values.
-func NewConfig() *Config {
+// NewConfig creates a new Config populated with default values, then
+// applies opts in order.
+func NewConfig(opts ...Option) *Config {
 	lpurl, _ := url.Parse(DefaultListenPeerURLs)
 	apurl, _ := url.Parse(DefaultInitialAdvertisePeerURLs)
 	lcurl, _ := url.Parse(DefaultListenClientURLs)
@@ -460,12 +776,29 @@ func NewConfig() *Config {
 		SnapshotCount:          etcdserver.DefaultSnapshotCount,
 		SnapshotCatchUpEntries: etcdserver.DefaultSnapshotCatchUpEntries,
 
+		BackendEngine:                    backend.EngineBolt,
 		MaxTxnOps:                        DefaultMaxTxnOps,
 		MaxRequestBytes:                  DefaultMaxRequestBytes,
 		ExperimentalWarningApplyDuration: DefaultWarningApplyDuration,
 
+		ExperimentalBoundedStalenessWindow: DefaultBoundedStalenessWindow,
+
 		ExperimentalWarningUnaryRequestDuration: DefaultWarningUnaryRequestDuration,
 
+		ExperimentalDefragBatchLimit:          backend.DefaultDefragBatchLimit,
+		ExperimentalValueCompression:          mvcc.CompressionNone,
+		ExperimentalValueCompressionThreshold: mvcc.DefaultValueCompressionThreshold,
+		ExperimentalWatchStuckPolicy:          mvcc.StuckWatcherCancel,
+		ExperimentalWALCompression:            wal.CompressionNone,
+		UnsafeWALDurability:                   wal.DurabilityFsync,
+		UnsafeWALSyncInterval:                 DefaultUnsafeWALSyncInterval,
+		ExperimentalPeerCompression:           rafthttp.CompressionNone,
+		ExperimentalMaxInflightMsgs:           etcdserver.DefaultMaxInflightMsgs,
+		ExperimentalWALSegmentSize:            wal.SegmentSizeBytes,
+		ExperimentalWALTmpSegments:            1,
+		ExperimentalWALArchiveInterval:        DefaultWALArchiveInterval,
+		ExperimentalBackendScrubRate:          backend.DefaultScrubRate,
+
 		GRPCKeepAliveMinTime:  DefaultGRPCKeepAliveMinTime,
 		GRPCKeepAliveInterval: DefaultGRPCKeepAliveInterval,
 		GRPCKeepAliveTimeout:  DefaultGRPCKeepAliveTimeout,
@@ -498,6 +831,20 @@ func NewConfig() *Config {
 		BcryptCost:   uint(bcrypt.DefaultCost),
 		AuthTokenTTL: 300,
 
+		AuthOIDCUsernameClaim:  auth.DefaultOIDCUsernameClaim,
+		AuthOIDCUsernamePrefix: auth.DefaultOIDCUsernamePrefix,
+		AuthLDAPGroupAttribute: auth.DefaultLDAPGroupAttribute,
+
+		AuthorizationWebhookTimeout: DefaultAuthorizationWebhookTimeout,
+
+		AuthLockoutDuration:    DefaultAuthLockoutDuration,
+		AuthLockoutMaxDuration: DefaultAuthLockoutMaxDuration,
+
+		AuthPasswordHash:  auth.PasswordHashBcrypt,
+		AuthArgon2Time:    auth.DefaultArgon2Time,
+		AuthArgon2Memory:  auth.DefaultArgon2Memory,
+		AuthArgon2Threads: auth.DefaultArgon2Threads,
+
 		PreVote: true,
 
 		loggerMu:              new(sync.RWMutex),
@@ -509,10 +856,18 @@ func NewConfig() *Config {
 		LogRotationConfigJSON: DefaultLogRotationConfig,
 		EnableGRPCGateway:     true,
 
+		AuditLogRotationConfigJSON: DefaultLogRotationConfig,
+		AuditLogRedactValues:       true,
+
 		ExperimentalDowngradeCheckTime:           DefaultDowngradeCheckTime,
 		ExperimentalMemoryMlock:                  false,
 		ExperimentalTxnModeWriteWithSharedBuffer: true,
 		ExperimentalMaxLearners:                  membership.DefaultMaxLearners,
+		ExperimentalHotKeyWindow:                 hotkey.DefaultWindow,
+		ExperimentalProfileMinInterval:           diagnostics.DefaultMinInterval,
+		ExperimentalProfileCPUDuration:           diagnostics.DefaultCPUDuration,
+
+		ExperimentalLeaderDegradationDuration: degradation.DefaultDuration,
 
 		V2Deprecation: config.V2_DEPR_DEFAULT,
 
@@ -529,26 +884,80 @@ func NewConfig() *Config {
 		},
 	}
 	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+	defaultCluster := cfg.InitialCluster
+	cfg.applyOpts(opts)
+	if cfg.InitialCluster == defaultCluster {
+		// follow the name and peer URLs set by opts
+		cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+	}
 	return cfg
 }
 
+// ConfigFromFile loads the Config from the YAML file at path. Files whose
+// names end in ".toml" or ".hcl" are parsed as TOML or HCL instead.
+// References to environment variables in the form "${NAME}" are expanded
+// in the values of the file, but not in its keys or comments; "$${" is a
+// literal "${".
 func ConfigFromFile(path string) (*Config, error) {
 	cfg := &configYAML{Config: *NewConfig()}
 	if err := cfg.configFromFile(path); err != nil {
 		return nil, err
 	}
+	cfg.Config.configFile = path
+	return &cfg.Config, nil
+}
+
+// ConfigFromDir loads the Config from the YAML files ("*.yaml" or "*.yml")
+// in dir. The files are merged in lexical order of their names, with fields
+// set in later files overriding those set in earlier ones.
+func ConfigFromDir(dir string) (*Config, error) {
+	b, err := readConfigDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &configYAML{Config: *NewConfig()}
+	if err = cfg.configFromBytes(b); err != nil {
+		return nil, err
+	}
+	cfg.Config.configDir = dir
 	return &cfg.Config, nil
 }
 
+// logLevelFromSource reads only the log level from the config file or
+// directory, so that it can be reloaded without validating and setting up
+// the whole config again.
+func (cfg *Config) logLevelFromSource() (string, error) {
+	var b []byte
+	var err error
+	if cfg.configDir != "" {
+		b, err = readConfigDir(cfg.configDir)
+	} else {
+		b, err = readConfigFile(cfg.configFile)
+	}
+	if err != nil {
+		return "", err
+	}
+	lc := struct {
+		LogLevel string `json:"log-level"`
+	}{LogLevel: logutil.DefaultLogLevel}
+	if err = yaml.Unmarshal(b, &lc); err != nil {
+		return "", err
+	}
+	return lc.LogLevel, nil
+}
+
 func (cfg *configYAML) configFromFile(path string) error {
-	b, err := os.ReadFile(path)
+	b, err := readConfigFile(path)
 	if err != nil {
 		return err
 	}
+	return cfg.configFromBytes(b)
+}
 
+func (cfg *configYAML) configFromBytes(b []byte) error {
 	defaultInitialCluster := cfg.InitialCluster
 
-	err = yaml.Unmarshal(b, cfg)
+	err := yaml.Unmarshal(b, cfg)
 	if err != nil {
 		return err
 	}
@@ -652,6 +1061,18 @@ func updateCipherSuites(tls *transport.TLSInfo, ss []string) error {
 	return nil
 }
 
+// unixSocketMode parses UnixSocketMode; 0 keeps the umask.
+func (cfg *Config) unixSocketMode() (os.FileMode, error) {
+	if cfg.UnixSocketMode == "" {
+		return 0, nil
+	}
+	m, err := strconv.ParseUint(cfg.UnixSocketMode, 8, 32)
+	if err != nil || m == 0 || m > 0777 {
+		return 0, fmt.Errorf("invalid unix-socket-mode %q, must be an octal file mode such as 0660", cfg.UnixSocketMode)
+	}
+	return os.FileMode(m), nil
+}
+
 // Validate ensures that '*embed.Config' fields are properly configured.
 func (cfg *Config) Validate() error {
 	if err := cfg.setupLogging(); err != nil {
@@ -670,9 +1091,9 @@ func (cfg *Config) Validate() error {
 		addrs := cfg.getAPURLs()
 		return fmt.Errorf(`--initial-advertise-peer-urls %q must be "host:port" (%v)`, strings.Join(addrs, ","), err)
 	}
-	if err := checkHostURLs(cfg.ACUrls); err != nil {
+	if err := checkHostURLs(withoutUnixPathURLs(cfg.ACUrls)); err != nil {
 		addrs := cfg.getACURLs()
-		return fmt.Errorf(`--advertise-client-urls %q must be "host:port" (%v)`, strings.Join(addrs, ","), err)
+		return fmt.Errorf(`--advertise-client-urls %q must be "host:port" or "unix:///path" (%v)`, strings.Join(addrs, ","), err)
 	}
 	// Check if conflicting flags are passed.
 	nSet := 0
@@ -725,6 +1146,142 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("--election-timeout[%vms] is too long, and should be set less than %vms", cfg.ElectionMs, maxElectionMs)
 	}
 
+	if err := backend.ValidateBatchLimits(cfg.BackendBatchInterval, cfg.BackendBatchLimit); err != nil {
+		return fmt.Errorf("--backend-batch-interval[%v] and --backend-batch-limit[%d] are not valid: (%v)", cfg.BackendBatchInterval, cfg.BackendBatchLimit, err)
+	}
+	if cfg.BackendEngine != "" && !backendEngineRegistered(cfg.BackendEngine) {
+		return fmt.Errorf("unknown --backend-engine %q (registered engines: %v)", cfg.BackendEngine, backend.Engines())
+	}
+	if cfg.ExperimentalDefragBatchInterval < 0 {
+		return fmt.Errorf("--experimental-defrag-batch-interval[%v] should not be negative", cfg.ExperimentalDefragBatchInterval)
+	}
+	if err := mvcc.ValidValueCompression(cfg.ExperimentalValueCompression); err != nil {
+		return fmt.Errorf("--experimental-value-compression is not valid: %v", err)
+	}
+	if cfg.ExperimentalValueCompressionThreshold < 0 {
+		return fmt.Errorf("--experimental-value-compression-threshold[%d] should not be negative", cfg.ExperimentalValueCompressionThreshold)
+	}
+	if err := wal.ValidCompression(cfg.ExperimentalWALCompression); err != nil {
+		return fmt.Errorf("--experimental-wal-compression is not valid: %v", err)
+	}
+	if err := wal.ValidDurability(cfg.UnsafeWALDurability); err != nil {
+		return fmt.Errorf("--unsafe-wal-durability is not valid: %v", err)
+	}
+	if cfg.UnsafeWALDurability == wal.DurabilityBatched && cfg.UnsafeWALSyncInterval <= 0 {
+		return fmt.Errorf("--unsafe-wal-sync-interval[%v] should be positive with --unsafe-wal-durability=%s", cfg.UnsafeWALSyncInterval, wal.DurabilityBatched)
+	}
+	if cfg.UnsafeNoFsync && cfg.UnsafeWALDurability == wal.DurabilityBatched {
+		return fmt.Errorf("--unsafe-no-fsync cannot be set with --unsafe-wal-durability=%s, disabling all uses of fsync", wal.DurabilityBatched)
+	}
+	if cfg.AuthOIDCIssuerURL != "" && cfg.AuthOIDCClientID == "" {
+		return errors.New("--auth-oidc-client-id must be set with --auth-oidc-issuer-url")
+	}
+	if cfg.AuthOIDCIssuerURL != "" && cfg.AuthOIDCUsernamePrefix == "" {
+		return errors.New("--auth-oidc-username-prefix must not be empty with --auth-oidc-issuer-url")
+	}
+	if cfg.AuthLDAPURL != "" && len(cfg.AuthLDAPBindDNTemplates) == 0 {
+		return errors.New("--auth-ldap-bind-dn-templates must be set with --auth-ldap-url")
+	}
+	if cfg.AuthorizationWebhookURL != "" {
+		if _, err := url.Parse(cfg.AuthorizationWebhookURL); err != nil {
+			return fmt.Errorf("--authorization-webhook-url is not valid: %v", err)
+		}
+		if cfg.AuthorizationWebhookTimeout <= 0 {
+			return fmt.Errorf("--authorization-webhook-timeout[%v] should be positive", cfg.AuthorizationWebhookTimeout)
+		}
+	}
+	if cfg.AuthLockoutThreshold < 0 {
+		return fmt.Errorf("--auth-lockout-threshold[%d] should be non-negative", cfg.AuthLockoutThreshold)
+	}
+	if cfg.AuthLockoutThreshold > 0 && cfg.AuthLockoutDuration <= 0 {
+		return fmt.Errorf("--auth-lockout-duration[%v] should be positive", cfg.AuthLockoutDuration)
+	}
+	if cfg.AuthArgon2Time > math.MaxUint32 || cfg.AuthArgon2Memory > math.MaxUint32 || cfg.AuthArgon2Threads > math.MaxUint8 {
+		return fmt.Errorf("--auth-argon2-time[%d], --auth-argon2-memory[%d] or --auth-argon2-threads[%d] is out of range", cfg.AuthArgon2Time, cfg.AuthArgon2Memory, cfg.AuthArgon2Threads)
+	}
+	if err := cfg.authPasswordPolicy().ValidatePasswordHash(); err != nil {
+		return fmt.Errorf("--auth-password-hash is not valid: %v", err)
+	}
+	if cfg.AuthPasswordMinLength < 0 {
+		return fmt.Errorf("--auth-password-min-length[%d] should be non-negative", cfg.AuthPasswordMinLength)
+	}
+	if cfg.AuthPasswordMinClasses < 0 || cfg.AuthPasswordMinClasses > 4 {
+		return fmt.Errorf("--auth-password-min-classes[%d] should be between 0 and 4", cfg.AuthPasswordMinClasses)
+	}
+	if len(cfg.ClientCertRoleRules) > 0 {
+		if !cfg.ClientTLSInfo.ClientCertAuth {
+			return errors.New("--client-cert-role-rules must be set with --client-cert-auth")
+		}
+		if _, err := auth.ParseCertRoleRules(cfg.ClientCertRoleRules); err != nil {
+			return err
+		}
+	}
+	if cfg.SPIFFEWorkloadAPIAddr != "" {
+		if _, _, err := spiffe.ParseAddr(cfg.SPIFFEWorkloadAPIAddr); err != nil {
+			return fmt.Errorf("--spiffe-workload-api-addr is not valid: %v", err)
+		}
+	}
+	if _, err := rafthttp.ParsePeerCompression(cfg.ExperimentalPeerCompression); err != nil {
+		return fmt.Errorf("--experimental-peer-compression is not valid: %v", err)
+	}
+	// the rafthttp stream buffers up to 4096 messages
+	if cfg.ExperimentalMaxInflightMsgs <= 0 || cfg.ExperimentalMaxInflightMsgs > 4096 {
+		return fmt.Errorf("--experimental-max-inflight-msgs[%d] should be between 1 and 4096", cfg.ExperimentalMaxInflightMsgs)
+	}
+	if cfg.ExperimentalMaxInflightBytes != 0 && cfg.ExperimentalMaxInflightBytes < etcdserver.MaxSizePerMsg {
+		return fmt.Errorf("--experimental-max-inflight-bytes[%d] should be 0 or at least the max raft message size %d", cfg.ExperimentalMaxInflightBytes, etcdserver.MaxSizePerMsg)
+	}
+	if cfg.ExperimentalWALSegmentSize <= 0 {
+		return fmt.Errorf("--experimental-wal-segment-size[%d] should be positive", cfg.ExperimentalWALSegmentSize)
+	}
+	if cfg.ExperimentalElectionPriority < 0 {
+		return fmt.Errorf("--experimental-election-priority[%d] should not be negative", cfg.ExperimentalElectionPriority)
+	}
+	if _, err := membership.ParseLabels(cfg.ExperimentalMemberLabels); err != nil {
+		return fmt.Errorf("--experimental-member-labels is not valid: %v", err)
+	}
+	if _, err := parseLeaderZones(cfg.ExperimentalLeaderZones); err != nil {
+		return fmt.Errorf("--experimental-leader-zones is not valid: %v", err)
+	}
+	if cfg.ExperimentalWitness && cfg.ForceNewCluster {
+		return fmt.Errorf("--experimental-witness cannot be set with --force-new-cluster, a witness keeping no KV data")
+	}
+	if cfg.ExperimentalWALTmpSegments < 1 {
+		return fmt.Errorf("--experimental-wal-tmp-segments[%d] should be at least 1", cfg.ExperimentalWALTmpSegments)
+	}
+	if cfg.ExperimentalWALArchive != "" {
+		if _, err := archive.NewSink(cfg.ExperimentalWALArchive); err != nil {
+			return fmt.Errorf("--experimental-wal-archive is not valid: %v", err)
+		}
+		if cfg.ExperimentalWALArchiveInterval <= 0 {
+			return fmt.Errorf("--experimental-wal-archive-interval[%v] should be positive", cfg.ExperimentalWALArchiveInterval)
+		}
+	}
+	if cfg.ExperimentalRangeTombstoneThreshold < 0 {
+		return fmt.Errorf("--experimental-range-tombstone-threshold[%d] should not be negative", cfg.ExperimentalRangeTombstoneThreshold)
+	}
+	if cfg.ExperimentalReadCacheSize < 0 {
+		return fmt.Errorf("--experimental-read-cache-size[%d] should not be negative", cfg.ExperimentalReadCacheSize)
+	}
+	if err := mvcc.ValidStuckWatcherPolicy(cfg.ExperimentalWatchStuckPolicy); err != nil {
+		return err
+	}
+	if cfg.ExperimentalWatchStuckMaxBytes < 0 {
+		return fmt.Errorf("--experimental-watch-stuck-max-bytes[%d] should not be negative", cfg.ExperimentalWatchStuckMaxBytes)
+	}
+	if cfg.ExperimentalWatchStuckMaxAge < 0 {
+		return fmt.Errorf("--experimental-watch-stuck-max-age[%v] should not be negative", cfg.ExperimentalWatchStuckMaxAge)
+	}
+	if cfg.ExperimentalWatchHistoryRetention < 0 {
+		return fmt.Errorf("--experimental-watch-history-retention[%d] should not be negative", cfg.ExperimentalWatchHistoryRetention)
+	}
+	if cfg.ExperimentalBackendScrubInterval < 0 {
+		return fmt.Errorf("--experimental-backend-scrub-interval[%v] should not be negative", cfg.ExperimentalBackendScrubInterval)
+	}
+	if cfg.ExperimentalBackendScrubInterval > 0 && cfg.ExperimentalBackendScrubRate <= 0 {
+		return fmt.Errorf("--experimental-backend-scrub-rate[%d] should be positive", cfg.ExperimentalBackendScrubRate)
+	}
+
 	// check this last since proxying in etcdmain may make this OK
 	if cfg.LCUrls != nil && cfg.ACUrls == nil {
 		return ErrUnsetAdvertiseClientURLsFlag
@@ -736,6 +1293,39 @@ func (cfg *Config) Validate() error {
 	default:
 		return fmt.Errorf("unknown auto-compaction-mode %q", cfg.AutoCompactionMode)
 	}
+	if _, err := v3compactor.ParseRetentionPolicies(cfg.ExperimentalAutoCompactionPrefixRetention); err != nil {
+		return fmt.Errorf("invalid --experimental-auto-compaction-prefix-retention: %v", err)
+	}
+	if cfg.ExperimentalAutoCompactionPrefixRetention != "" && cfg.AutoCompactionMode == CompactorModeRevision {
+		return fmt.Errorf("--experimental-auto-compaction-prefix-retention requires auto-compaction-mode %q", CompactorModePeriodic)
+	}
+
+	if cfg.TimerJitter < 0 {
+		return fmt.Errorf("timer-jitter must not be negative, got %v", cfg.TimerJitter)
+	}
+	if cfg.GracefulShutdownTimeout < 0 {
+		return fmt.Errorf("graceful-shutdown-timeout must not be negative, got %v", cfg.GracefulShutdownTimeout)
+	}
+	if _, err := cfg.unixSocketMode(); err != nil {
+		return err
+	}
+
+	if err := cfg.validateAuditLog(); err != nil {
+		return err
+	}
+
+	if cfg.ExperimentalHotKeySampleRate < 0 || cfg.ExperimentalHotKeySampleRate > 1 {
+		return fmt.Errorf("--experimental-hot-key-sample-rate must be between 0 and 1, got %v", cfg.ExperimentalHotKeySampleRate)
+	}
+	if cfg.ExperimentalHotKeySampleRate > 0 && cfg.ExperimentalHotKeyWindow < time.Second {
+		return fmt.Errorf("--experimental-hot-key-window must be at least 1s, got %v", cfg.ExperimentalHotKeyWindow)
+	}
+	if err := cfg.validateProfiling(); err != nil {
+		return err
+	}
+	if err := cfg.validateLeaderDegradation(); err != nil {
+		return err
+	}
 
 	// Validate distributed tracing configuration but only if enabled.
 	if cfg.ExperimentalEnableDistributedTracing {
@@ -755,6 +1345,50 @@ func (cfg *Config) Validate() error {
 	return nil
 }
 
+func (cfg *Config) validateProfiling() error {
+	if cfg.ExperimentalProfileApplyThreshold < 0 {
+		return fmt.Errorf("--experimental-profile-apply-threshold must not be negative, got %v", cfg.ExperimentalProfileApplyThreshold)
+	}
+	if cfg.ExperimentalProfileFsyncThreshold < 0 {
+		return fmt.Errorf("--experimental-profile-fsync-threshold must not be negative, got %v", cfg.ExperimentalProfileFsyncThreshold)
+	}
+	if cfg.ExperimentalProfileApplyThreshold == 0 && cfg.ExperimentalProfileFsyncThreshold == 0 {
+		return nil
+	}
+	if cfg.ExperimentalProfileMinInterval < cfg.ExperimentalProfileCPUDuration {
+		return fmt.Errorf("--experimental-profile-min-interval[%v] must be at least --experimental-profile-cpu-duration[%v]", cfg.ExperimentalProfileMinInterval, cfg.ExperimentalProfileCPUDuration)
+	}
+	if cfg.ExperimentalProfileCPUDuration <= 0 {
+		return fmt.Errorf("--experimental-profile-cpu-duration must be >0, got %v", cfg.ExperimentalProfileCPUDuration)
+	}
+	if cfg.ExperimentalProfileURL != "" {
+		u, err := url.Parse(cfg.ExperimentalProfileURL)
+		if err != nil {
+			return fmt.Errorf("--experimental-profile-url %q is not valid (%v)", cfg.ExperimentalProfileURL, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("--experimental-profile-url %q must be an http or https URL", cfg.ExperimentalProfileURL)
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) validateLeaderDegradation() error {
+	if cfg.ExperimentalLeaderDegradationFsyncThreshold < 0 {
+		return fmt.Errorf("--experimental-leader-degradation-fsync-threshold must not be negative, got %v", cfg.ExperimentalLeaderDegradationFsyncThreshold)
+	}
+	if t := cfg.ExperimentalLeaderDegradationCPUStealThreshold; t < 0 || t > 100 {
+		return fmt.Errorf("--experimental-leader-degradation-cpu-steal-threshold must be a percentage, got %v", t)
+	}
+	if t := cfg.ExperimentalLeaderDegradationMemoryPressureThreshold; t < 0 || t > 100 {
+		return fmt.Errorf("--experimental-leader-degradation-memory-pressure-threshold must be a percentage, got %v", t)
+	}
+	if cfg.ExperimentalLeaderDegradationDuration <= 0 {
+		return fmt.Errorf("--experimental-leader-degradation-duration must be >0, got %v", cfg.ExperimentalLeaderDegradationDuration)
+	}
+	return nil
+}
+
 // PeerURLsMapAndToken sets up an initial peer URLsMap and cluster token for bootstrap or discovery.
 func (cfg *Config) PeerURLsMapAndToken(which string) (urlsmap types.URLsMap, token string, err error) {
 	token = cfg.InitialClusterToken
@@ -881,6 +1515,30 @@ func (cfg Config) V2DeprecationEffective() config.V2DeprecationEnum {
 	return cfg.V2Deprecation
 }
 
+// walDurability returns the durability of the WAL writes, none if all uses
+// of fsync are disabled.
+func (cfg Config) walDurability() string {
+	if cfg.UnsafeNoFsync {
+		return wal.DurabilityNone
+	}
+	if cfg.UnsafeWALDurability == "" {
+		return wal.DurabilityFsync
+	}
+	return cfg.UnsafeWALDurability
+}
+
+func (cfg Config) authPasswordPolicy() auth.PasswordPolicy {
+	return auth.PasswordPolicy{
+		Hash:          cfg.AuthPasswordHash,
+		Argon2Time:    uint32(cfg.AuthArgon2Time),
+		Argon2Memory:  uint32(cfg.AuthArgon2Memory),
+		Argon2Threads: uint8(cfg.AuthArgon2Threads),
+		MinLength:     cfg.AuthPasswordMinLength,
+		MinClasses:    cfg.AuthPasswordMinClasses,
+		MaxAge:        cfg.AuthPasswordMaxAge,
+	}
+}
+
 func (cfg Config) defaultPeerHost() bool {
 	return len(cfg.APUrls) == 1 && cfg.APUrls[0].String() == DefaultInitialAdvertisePeerURLs
 }
@@ -1002,6 +1660,18 @@ func checkHostURLs(urls []url.URL) error {
 	return nil
 }
 
+// withoutUnixPathURLs filters out unix socket URLs given by path, such as
+// "unix:///var/run/etcd.sock", which clients dial by path instead of host.
+func withoutUnixPathURLs(urls []url.URL) (us []url.URL) {
+	for _, u := range urls {
+		if (u.Scheme == "unix" || u.Scheme == "unixs") && u.Host == "" && u.Path != "" {
+			continue
+		}
+		us = append(us, u)
+	}
+	return us
+}
+
 func (cfg *Config) getAPURLs() (ss []string) {
 	ss = make([]string, len(cfg.APUrls))
 	for i := range cfg.APUrls {
@@ -1042,6 +1712,15 @@ func (cfg *Config) getMetricsURLs() (ss []string) {
 	return ss
 }
 
+func backendEngineRegistered(name string) bool {
+	for _, e := range backend.Engines() {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
+
 func parseBackendFreelistType(freelistType string) bolt.FreelistType {
 	if freelistType == freelistArrayType {
 		return bolt.FreelistArrayType
@@ -1049,3 +1728,22 @@ func parseBackendFreelistType(freelistType string) bolt.FreelistType {
 
 	return bolt.FreelistMapType
 }
+
+// parseLeaderZones parses the comma separated leader zones, from the most preferred.
+func parseLeaderZones(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	zones := strings.Split(s, ",")
+	seen := make(map[string]struct{}, len(zones))
+	for _, z := range zones {
+		if z == "" {
+			return nil, fmt.Errorf("empty zone in %q", s)
+		}
+		if _, ok := seen[z]; ok {
+			return nil, fmt.Errorf("duplicate zone %q", z)
+		}
+		seen[z] = struct{}{}
+	}
+	return zones, nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.etcd.io/etcd/client/pkg/v3/logutil"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+func (cfg *Config) validateAuditLog() error {
+	switch cfg.AuditLogOutput {
+	case "", StdErrLogOutput, StdOutLogOutput:
+		return nil
+	}
+	_, err := cfg.auditLogRotation()
+	return err
+}
+
+func (cfg *Config) auditLogRotation() (*lumberjack.Logger, error) {
+	l := &lumberjack.Logger{}
+	if err := json.Unmarshal([]byte(cfg.AuditLogRotationConfigJSON), l); err != nil {
+		return nil, fmt.Errorf("invalid audit log rotation config: %v", err)
+	}
+	l.Filename = cfg.AuditLogOutput
+	return l, nil
+}
+
+// setupAuditLogger returns the logger the audit log is written to, or nil if
+// audit logging is disabled, and a function to close its output.
+func setupAuditLogger(cfg *Config) (*zap.Logger, func(), error) {
+	if cfg.AuditLogger != nil {
+		return cfg.AuditLogger, nil, nil
+	}
+
+	var ws zapcore.WriteSyncer
+	closer := func() {}
+	switch cfg.AuditLogOutput {
+	case "":
+		return nil, nil, nil
+	case StdErrLogOutput:
+		ws = zapcore.Lock(os.Stderr)
+	case StdOutLogOutput:
+		ws = zapcore.Lock(os.Stdout)
+	default:
+		l, err := cfg.auditLogRotation()
+		if err != nil {
+			return nil, nil, err
+		}
+		if err = os.MkdirAll(filepath.Dir(l.Filename), 0700); err != nil {
+			return nil, nil, err
+		}
+		ws = zapcore.AddSync(l)
+		closer = func() { l.Close() }
+	}
+
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(logutil.DefaultZapLoggerConfig.EncoderConfig), ws, zapcore.InfoLevel)
+	lg := zap.New(core)
+	return lg, func() {
+		lg.Sync()
+		closer()
+	}, nil
+}
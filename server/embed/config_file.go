@@ -0,0 +1,280 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hashicorp/hcl"
+	yamlv3 "gopkg.in/yaml.v3"
+	"sigs.k8s.io/yaml"
+)
+
+// envRefRegexp matches the "${NAME}" environment variable references and the
+// "$${" escape sequence.
+var envRefRegexp = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces every "${NAME}" in s with the value of the environment
+// variable NAME, and "$${" with a literal "${". The names of the variables
+// that are not set are appended to undefined.
+func expandEnv(s string, undefined *[]string) string {
+	return envRefRegexp.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "$${" {
+			return "${"
+		}
+		name := ref[2 : len(ref)-1]
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			*undefined = append(*undefined, name)
+		}
+		return v
+	})
+}
+
+// plainScalarRegexp matches the values that are written as plain YAML
+// scalars, in block and flow collections alike.
+var plainScalarRegexp = regexp.MustCompile(`^[A-Za-z0-9_./~]([A-Za-z0-9_./~+=:@%-]*[A-Za-z0-9_./~+=@%-])?$`)
+
+// yamlExpandEnv expands the environment variable references in the string
+// values of the YAML document b read from path. Keys and comments are left
+// as is. An unquoted reference is replaced with the value as a plain scalar
+// when possible, so that it is typed like any other unquoted value, and as
+// a quoted string otherwise, so that no value can alter the document.
+// Referencing a variable that is not set is an error, so that e.g. a missing
+// secret is not silently ignored.
+func yamlExpandEnv(path string, b []byte) ([]byte, error) {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %q: %w", path, err)
+	}
+	var scalars []*yamlv3.Node
+	collectValueScalars(&doc, &scalars)
+
+	lines := strings.Split(string(b), "\n")
+	var undefined []string
+	// the values are replaced from the last one, so that the positions of
+	// the previous ones on the same line still hold
+	for i := len(scalars) - 1; i >= 0; i-- {
+		n := scalars[i]
+		if !envRefRegexp.MatchString(n.Value) {
+			continue
+		}
+		line := []rune(lines[n.Line-1])
+		start := n.Column - 1
+		end := scalarEnd(n, line[start:])
+		if end < 0 {
+			return nil, fmt.Errorf("config file %q: environment variables are only expanded in single-line values (line %d)", path, n.Line)
+		}
+		v := expandEnv(n.Value, &undefined)
+		if n.Style != 0 || !plainScalarRegexp.MatchString(v) {
+			// JSON strings are valid double-quoted YAML scalars
+			q, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			v = string(q)
+		}
+		lines[n.Line-1] = string(line[:start]) + v + string(line[start+end:])
+	}
+	if len(undefined) > 0 {
+		return nil, fmt.Errorf("config file %q references undefined environment variables %q", path, undefined)
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// collectValueScalars appends the scalars of n that are not mapping keys to
+// scalars, in document order.
+func collectValueScalars(n *yamlv3.Node, scalars *[]*yamlv3.Node) {
+	switch n.Kind {
+	case yamlv3.ScalarNode:
+		*scalars = append(*scalars, n)
+	case yamlv3.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			collectValueScalars(n.Content[i], scalars)
+		}
+	default:
+		for _, c := range n.Content {
+			collectValueScalars(c, scalars)
+		}
+	}
+}
+
+// scalarEnd returns the length of the scalar n written at the start of
+// src, or -1 if it does not end on the same line.
+func scalarEnd(n *yamlv3.Node, src []rune) int {
+	switch n.Style {
+	case 0:
+		if strings.HasPrefix(string(src), n.Value) {
+			return len([]rune(n.Value))
+		}
+	case yamlv3.DoubleQuotedStyle:
+		for i := 1; i < len(src); i++ {
+			switch src[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+	case yamlv3.SingleQuotedStyle:
+		for i := 1; i < len(src); i++ {
+			if src[i] != '\'' {
+				continue
+			}
+			if i+1 < len(src) && src[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// decodedExpandEnv expands the environment variable references in the string
+// values of the decoded TOML or HCL document v.
+func decodedExpandEnv(v interface{}, undefined *[]string) interface{} {
+	switch t := v.(type) {
+	case string:
+		return expandEnv(t, undefined)
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = decodedExpandEnv(e, undefined)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = decodedExpandEnv(e, undefined)
+		}
+	case []map[string]interface{}:
+		for _, e := range t {
+			decodedExpandEnv(e, undefined)
+		}
+	}
+	return v
+}
+
+// readConfigFile reads the config file at path, expanding the environment
+// variable references in its values. TOML (".toml") and HCL (".hcl") files
+// are converted to JSON, which is valid YAML, so that all formats are decoded
+// into the same fields.
+func readConfigFile(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		if err = toml.Unmarshal(b, &m); err != nil {
+			return nil, fmt.Errorf("failed to parse TOML config file %q: %w", path, err)
+		}
+	case ".hcl":
+		if err = hcl.Unmarshal(b, &m); err != nil {
+			return nil, fmt.Errorf("failed to parse HCL config file %q: %w", path, err)
+		}
+		m = flattenHCLBlocks(m)
+	default:
+		return yamlExpandEnv(path, b)
+	}
+	var undefined []string
+	decodedExpandEnv(m, &undefined)
+	if len(undefined) > 0 {
+		return nil, fmt.Errorf("config file %q references undefined environment variables %q", path, undefined)
+	}
+	return json.Marshal(m)
+}
+
+// flattenHCLBlocks turns single HCL blocks, which are decoded as a list of
+// one object, into the object itself, e.g. for
+//
+//	client-transport-security {
+//	  cert-file = "server.crt"
+//	}
+func flattenHCLBlocks(m map[string]interface{}) map[string]interface{} {
+	for k, v := range m {
+		if blocks, ok := v.([]map[string]interface{}); ok && len(blocks) == 1 {
+			m[k] = flattenHCLBlocks(blocks[0])
+		}
+	}
+	return m
+}
+
+// readConfigDir merges the YAML files ("*.yaml" or "*.yml") in dir in lexical
+// order of their names and returns the result as JSON. Objects are merged
+// field by field, so that a later file can override a single field of e.g.
+// client-transport-security; any other value, including lists, is replaced.
+func readConfigDir(dir string) ([]byte, error) {
+	// entries are sorted by file name
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if ext := filepath.Ext(name); strings.HasPrefix(name, ".") || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		// follow symlinks, as used by e.g. mounted secrets
+		fi, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if fi.Mode().IsRegular() {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no YAML config files found in %q", dir)
+	}
+
+	merged := make(map[string]interface{})
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if b, err = yamlExpandEnv(path, b); err != nil {
+			return nil, err
+		}
+		var m map[string]interface{}
+		if err = yaml.Unmarshal(b, &m); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %q: %w", path, err)
+		}
+		mergeConfig(merged, m)
+	}
+	return json.Marshal(merged)
+}
+
+// mergeConfig merges src into dst, overriding the values set in dst.
+func mergeConfig(dst, src map[string]interface{}) {
+	for k, v := range src {
+		sm, ok := v.(map[string]interface{})
+		dm, dok := dst[k].(map[string]interface{})
+		if ok && dok {
+			mergeConfig(dm, sm)
+			continue
+		}
+		dst[k] = v
+	}
+}
@@ -19,16 +19,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/url"
-	"os"
 
 	"go.etcd.io/etcd/client/pkg/v3/logutil"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3rpc"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"go.uber.org/zap/zapgrpc"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/grpclog"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
@@ -65,15 +62,14 @@ func (cfg *Config) setupLogging() error {
 		}
 
 		outputPaths, errOutputPaths := make([]string, 0), make([]string, 0)
-		isJournal := false
 		for _, v := range cfg.LogOutputs {
 			switch v {
 			case DefaultLogOutput:
 				outputPaths = append(outputPaths, StdErrLogOutput)
 				errOutputPaths = append(errOutputPaths, StdErrLogOutput)
 
-			case JournalLogOutput:
-				isJournal = true
+			case JournalLogOutput, SystemdJournalLogOutput:
+				outputPaths = append(outputPaths, logutil.SystemdJournalLogOutput)
 
 			case StdErrLogOutput:
 				outputPaths = append(outputPaths, StdErrLogOutput)
@@ -84,6 +80,11 @@ func (cfg *Config) setupLogging() error {
 				errOutputPaths = append(errOutputPaths, StdOutLogOutput)
 
 			default:
+				if logutil.IsSystemLogOutput(v) {
+					// syslog://host:port
+					outputPaths = append(outputPaths, v)
+					continue
+				}
 				var path string
 				if cfg.EnableLogRotation {
 					// append rotate scheme to logs managed by lumberjack log rotation
@@ -99,70 +100,34 @@ func (cfg *Config) setupLogging() error {
 				errOutputPaths = append(errOutputPaths, path)
 			}
 		}
+		if len(errOutputPaths) == 0 {
+			// the journal and syslog only receive the logs, not zap's internal errors
+			errOutputPaths = append(errOutputPaths, StdErrLogOutput)
+		}
 
-		if !isJournal {
-			copied := logutil.DefaultZapLoggerConfig
-			copied.OutputPaths = outputPaths
-			copied.ErrorOutputPaths = errOutputPaths
-			copied = logutil.MergeOutputPaths(copied)
-			copied.Level = zap.NewAtomicLevelAt(logutil.ConvertToZapLevel(cfg.LogLevel))
-			encoding, err := logutil.ConvertToZapFormat(cfg.LogFormat)
-			if err != nil {
-				return err
-			}
-			copied.Encoding = encoding
-			if cfg.ZapLoggerBuilder == nil {
-				lg, err := copied.Build()
-				if err != nil {
-					return err
-				}
-				cfg.ZapLoggerBuilder = NewZapLoggerBuilder(lg)
-			}
-		} else {
-			if len(cfg.LogOutputs) > 1 {
-				for _, v := range cfg.LogOutputs {
-					if v != DefaultLogOutput {
-						return fmt.Errorf("running with systemd/journal but other '--log-outputs' values (%q) are configured with 'default'; override 'default' value with something else", cfg.LogOutputs)
-					}
-				}
-			}
-
-			// use stderr as fallback
-			syncer, lerr := getJournalWriteSyncer()
-			if lerr != nil {
-				return lerr
-			}
-
-			lvl := zap.NewAtomicLevelAt(logutil.ConvertToZapLevel(cfg.LogLevel))
-
-			var encoder zapcore.Encoder
-			encoding, err := logutil.ConvertToZapFormat(cfg.LogFormat)
+		copied := logutil.DefaultZapLoggerConfig
+		copied.OutputPaths = outputPaths
+		copied.ErrorOutputPaths = errOutputPaths
+		copied = logutil.MergeOutputPaths(copied)
+		copied.Level = zap.NewAtomicLevelAt(logutil.ConvertToZapLevel(cfg.LogLevel))
+		encoding, err := logutil.ConvertToZapFormat(cfg.LogFormat)
+		if err != nil {
+			return err
+		}
+		copied.Encoding = encoding
+		if cfg.ZapLoggerBuilder == nil {
+			lg, err := logutil.NewLogger(copied)
 			if err != nil {
 				return err
 			}
-
-			if encoding == logutil.ConsoleLogFormat {
-				encoder = zapcore.NewConsoleEncoder(logutil.DefaultZapLoggerConfig.EncoderConfig)
-			} else {
-				encoder = zapcore.NewJSONEncoder(logutil.DefaultZapLoggerConfig.EncoderConfig)
-			}
-
-			// WARN: do not change field names in encoder config
-			// journald logging writer assumes field names of "level" and "caller"
-			cr := zapcore.NewCore(
-				encoder,
-				syncer,
-				lvl,
-			)
-			if cfg.ZapLoggerBuilder == nil {
-				cfg.ZapLoggerBuilder = NewZapLoggerBuilder(zap.New(cr, zap.AddCaller(), zap.ErrorOutput(syncer)))
-			}
+			cfg.ZapLoggerBuilder = NewZapLoggerBuilder(lg)
+			cfg.logLevel = &copied.Level
 		}
 
-		err := cfg.ZapLoggerBuilder(cfg)
-		if err != nil {
+		if err := cfg.ZapLoggerBuilder(cfg); err != nil {
 			return err
 		}
+		cfg.registerZapLoggerCoresAndHooks()
 
 		logTLSHandshakeFailure := func(conn *tls.Conn, err error) {
 			state := conn.ConnectionState()
@@ -201,6 +166,26 @@ func (cfg *Config) setupLogging() error {
 	return nil
 }
 
+// registerZapLoggerCoresAndHooks tees the logger to ZapLoggerCores and
+// registers ZapLoggerHooks on it.
+func (cfg *Config) registerZapLoggerCoresAndHooks() {
+	if len(cfg.ZapLoggerCores) == 0 && len(cfg.ZapLoggerHooks) == 0 {
+		return
+	}
+	var opts []zap.Option
+	if len(cfg.ZapLoggerCores) > 0 {
+		opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(append([]zapcore.Core{core}, cfg.ZapLoggerCores...)...)
+		}))
+	}
+	if len(cfg.ZapLoggerHooks) > 0 {
+		opts = append(opts, zap.Hooks(cfg.ZapLoggerHooks...))
+	}
+	cfg.loggerMu.Lock()
+	defer cfg.loggerMu.Unlock()
+	cfg.logger = cfg.logger.WithOptions(opts...)
+}
+
 // NewZapLoggerBuilder generates a zap logger builder that sets given logger
 // for embedded etcd.
 func NewZapLoggerBuilder(lg *zap.Logger) func(*Config) error {
@@ -228,10 +213,9 @@ func (cfg *Config) SetupGlobalLoggers() {
 	if lg != nil {
 		if cfg.LogLevel == "debug" {
 			grpc.EnableTracing = true
-			grpclog.SetLoggerV2(zapgrpc.NewLogger(lg))
-		} else {
-			grpclog.SetLoggerV2(grpclog.NewLoggerV2(io.Discard, os.Stderr, os.Stderr))
 		}
+		// gRPC internal logs can be toggled at runtime with the LogLevel RPC
+		v3rpc.SetGRPCLogger(lg, cfg.LogLevel == "debug")
 		zap.ReplaceGlobals(lg)
 	}
 }
@@ -249,9 +233,12 @@ func setupLogRotation(logOutputs []string, logRotateConfigJSON string) error {
 	outputFilePaths := 0
 	for _, v := range logOutputs {
 		switch v {
-		case DefaultLogOutput, StdErrLogOutput, StdOutLogOutput:
+		case DefaultLogOutput, StdErrLogOutput, StdOutLogOutput, JournalLogOutput:
 			continue
 		default:
+			if logutil.IsSystemLogOutput(v) {
+				continue
+			}
 			outputFilePaths++
 		}
 	}
@@ -0,0 +1,227 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+	"go.etcd.io/etcd/server/v3/storage"
+
+	"go.uber.org/zap"
+)
+
+// Option are options which can be applied to the Config by NewConfig or
+// NewValidatedConfig.
+type Option func(*Config)
+
+func (cfg *Config) applyOpts(opts []Option) {
+	for _, opt := range opts {
+		opt(cfg)
+	}
+}
+
+// NewValidatedConfig is like NewConfig, but returns an error if opts
+// conflict with each other or the resulting Config does not pass Validate.
+func NewValidatedConfig(opts ...Option) (*Config, error) {
+	cfg := NewConfig(opts...)
+	if err := cfg.validateOpts(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// validateOpts rejects the combinations of options that Validate lets
+// through with a warning at most, since they are kept for the flags.
+func (cfg *Config) validateOpts() error {
+	if err := checkTLSURLs("client", cfg.LCUrls, cfg.ClientTLSInfo, cfg.ClientAutoTLS); err != nil {
+		return err
+	}
+	if err := checkTLSURLs("peer", cfg.LPUrls, cfg.PeerTLSInfo, cfg.PeerAutoTLS); err != nil {
+		return err
+	}
+	if cfg.QuotaBackendBytes > storage.MaxQuotaBytes {
+		return fmt.Errorf("quota %d exceeds the maximum backend quota %d", cfg.QuotaBackendBytes, storage.MaxQuotaBytes)
+	}
+	return nil
+}
+
+// checkTLSURLs checks that TLS is configured if and only if some of the urls
+// are secure.
+func checkTLSURLs(kind string, urls []url.URL, info transport.TLSInfo, autoTLS bool) error {
+	secure := false
+	ss := make([]string, len(urls))
+	for i, u := range urls {
+		if u.Scheme == "https" || u.Scheme == "unixs" {
+			secure = true
+		}
+		ss[i] = u.String()
+	}
+	hasTLS := !info.Empty() || autoTLS
+	switch {
+	case hasTLS && !secure:
+		return fmt.Errorf("%s TLS is set but none of the %s URLs %q is https", kind, kind, ss)
+	case !hasTLS && secure:
+		return fmt.Errorf("%s URLs %q are https but %s TLS is not set", kind, ss, kind)
+	}
+	return nil
+}
+
+// WithName sets the member name. Unless WithInitialCluster is given, the
+// initial cluster is derived from the name and advertised peer URLs.
+func WithName(name string) Option {
+	return func(cfg *Config) { cfg.Name = name }
+}
+
+// WithDataDir sets the data directory.
+func WithDataDir(dir string) Option {
+	return func(cfg *Config) { cfg.Dir = dir }
+}
+
+// WithWALDir sets a dedicated WAL directory, outside of the data directory.
+func WithWALDir(dir string) Option {
+	return func(cfg *Config) { cfg.WalDir = dir }
+}
+
+// WithClientURLs sets the URLs to listen on and to advertise for client
+// traffic. The advertised URLs default to the listen URLs if none are given.
+func WithClientURLs(listen []url.URL, advertise ...url.URL) Option {
+	return func(cfg *Config) {
+		cfg.LCUrls = listen
+		cfg.ACUrls = advertise
+		if len(advertise) == 0 {
+			cfg.ACUrls = listen
+		}
+	}
+}
+
+// WithPeerURLs sets the URLs to listen on and to advertise for peer
+// traffic. The advertised URLs default to the listen URLs if none are given.
+func WithPeerURLs(listen []url.URL, advertise ...url.URL) Option {
+	return func(cfg *Config) {
+		cfg.LPUrls = listen
+		cfg.APUrls = advertise
+		if len(advertise) == 0 {
+			cfg.APUrls = listen
+		}
+	}
+}
+
+// WithClientTLS serves client traffic with the given TLS credentials,
+// instead of auto-generated ones.
+func WithClientTLS(info transport.TLSInfo) Option {
+	return func(cfg *Config) {
+		cfg.ClientTLSInfo = info
+		cfg.ClientAutoTLS = false
+	}
+}
+
+// WithClientAutoTLS serves client traffic with auto-generated certificates.
+func WithClientAutoTLS() Option {
+	return func(cfg *Config) {
+		cfg.ClientTLSInfo = transport.TLSInfo{}
+		cfg.ClientAutoTLS = true
+	}
+}
+
+// WithPeerTLS serves and dials peer traffic with the given TLS credentials,
+// instead of auto-generated ones.
+func WithPeerTLS(info transport.TLSInfo) Option {
+	return func(cfg *Config) {
+		cfg.PeerTLSInfo = info
+		cfg.PeerAutoTLS = false
+	}
+}
+
+// WithPeerAutoTLS serves and dials peer traffic with auto-generated
+// certificates.
+func WithPeerAutoTLS() Option {
+	return func(cfg *Config) {
+		cfg.PeerTLSInfo = transport.TLSInfo{}
+		cfg.PeerAutoTLS = true
+	}
+}
+
+// WithInitialCluster sets the initial cluster configuration for
+// bootstrapping, in the form "name1=peerURL1,name2=peerURL2".
+func WithInitialCluster(cluster string) Option {
+	return func(cfg *Config) { cfg.InitialCluster = cluster }
+}
+
+// WithInitialClusterToken sets the token of the cluster during bootstrap.
+func WithInitialClusterToken(token string) Option {
+	return func(cfg *Config) { cfg.InitialClusterToken = token }
+}
+
+// WithExistingCluster joins an existing cluster instead of bootstrapping a
+// new one.
+func WithExistingCluster() Option {
+	return func(cfg *Config) { cfg.ClusterState = ClusterStateFlagExisting }
+}
+
+// WithQuota sets the raise alarms threshold of the backend size in bytes.
+func WithQuota(bytes int64) Option {
+	return func(cfg *Config) { cfg.QuotaBackendBytes = bytes }
+}
+
+// WithSnapshotCount sets the number of committed transactions to trigger a
+// snapshot to disk.
+func WithSnapshotCount(count uint64) Option {
+	return func(cfg *Config) { cfg.SnapshotCount = count }
+}
+
+// WithTimers sets the heartbeat interval and election timeout.
+func WithTimers(heartbeat, election time.Duration) Option {
+	return func(cfg *Config) {
+		cfg.TickMs = uint(heartbeat / time.Millisecond)
+		cfg.ElectionMs = uint(election / time.Millisecond)
+	}
+}
+
+// WithPeriodicCompaction compacts the keyspace every retention window.
+func WithPeriodicCompaction(retention time.Duration) Option {
+	return func(cfg *Config) {
+		cfg.AutoCompactionMode = CompactorModePeriodic
+		cfg.AutoCompactionRetention = retention.String()
+	}
+}
+
+// WithRevisionCompaction compacts the keyspace, keeping the given number of
+// revisions.
+func WithRevisionCompaction(revisions int64) Option {
+	return func(cfg *Config) {
+		cfg.AutoCompactionMode = CompactorModeRevision
+		cfg.AutoCompactionRetention = strconv.FormatInt(revisions, 10)
+	}
+}
+
+// WithLogLevel sets the log level of the default zap logger.
+func WithLogLevel(level string) Option {
+	return func(cfg *Config) { cfg.LogLevel = level }
+}
+
+// WithZapLogger logs with lg instead of building a zap logger.
+func WithZapLogger(lg *zap.Logger) Option {
+	return func(cfg *Config) {
+		cfg.Logger = "zap"
+		cfg.ZapLoggerBuilder = NewZapLoggerBuilder(lg)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.etcd.io/etcd/client/v3"
+)
+
+// maskedValue replaces the secrets in the runtime configuration.
+const maskedValue = "xxxxx"
+
+// runtimeConfigNames names the fields of Config without a json tag after
+// the flag setting them.
+var runtimeConfigNames = map[string]string{
+	"LPUrls":            "listen-peer-urls",
+	"LCUrls":            "listen-client-urls",
+	"APUrls":            "initial-advertise-peer-urls",
+	"ACUrls":            "advertise-client-urls",
+	"ClientTLSInfo":     "client-transport-security",
+	"ClientAutoTLS":     "auto-tls",
+	"PeerTLSInfo":       "peer-transport-security",
+	"PeerAutoTLS":       "peer-auto-tls",
+	"CORS":              "cors",
+	"HostWhitelist":     "host-whitelist",
+	"ListenMetricsUrls": "listen-metrics-urls",
+}
+
+// runtimeConfigSkipped lists the fields of Config left out of the runtime
+// configuration: ListenMetricsUrlsJSON is parsed into ListenMetricsUrls,
+// SnapshotCatchUpEntries is only changed by tests and ZapLoggerBuilder is
+// code, not configuration.
+var runtimeConfigSkipped = map[string]struct{}{
+	"ListenMetricsUrlsJSON":  {},
+	"SnapshotCatchUpEntries": {},
+	"ZapLoggerBuilder":       {},
+}
+
+// runtimeConfig returns the configuration by flag name, with the passwords
+// of the URLs and of the discovery client masked. Strings and values with
+// a String method are formatted as is, the others are JSON encoded.
+func (cfg *Config) runtimeConfig() (map[string]string, error) {
+	c := *cfg
+	if auth := c.DiscoveryCfg.Auth; auth != nil && auth.Password != "" {
+		c.DiscoveryCfg.Auth = &clientv3.AuthConfig{Username: auth.Username, Password: maskedValue}
+	}
+
+	rc := make(map[string]string)
+	v := reflect.ValueOf(c)
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		name, ok := runtimeConfigName(f)
+		if !ok {
+			continue
+		}
+		value, err := runtimeConfigValue(v.Field(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("failed to format configuration %q: %w", name, err)
+		}
+		rc[name] = value
+	}
+	return rc, nil
+}
+
+func runtimeConfigName(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" {
+		return "", false
+	}
+	if _, ok := runtimeConfigSkipped[f.Name]; ok {
+		return "", false
+	}
+	tag := strings.Split(f.Tag.Get("json"), ",")[0]
+	switch tag {
+	case "-":
+		return "", false
+	case "":
+		name, ok := runtimeConfigNames[f.Name]
+		return name, ok
+	}
+	return tag, true
+}
+
+func runtimeConfigValue(v interface{}) (string, error) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.String {
+		return redactURL(rv.String()), nil
+	}
+	switch v := v.(type) {
+	case fmt.Stringer:
+		return v.String(), nil
+	case []url.URL:
+		us := make([]string, len(v))
+		for i := range v {
+			us[i] = v[i].Redacted()
+		}
+		b, err := json.Marshal(us)
+		return string(b), err
+	case map[string]struct{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b, err := json.Marshal(keys)
+		return string(b), err
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// redactURL masks the password of s if it is a URL.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	return u.Redacted()
+}
@@ -20,6 +20,8 @@ import (
 	"net"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -27,6 +29,10 @@ import (
 	"go.etcd.io/etcd/client/pkg/v3/srv"
 	"go.etcd.io/etcd/client/pkg/v3/transport"
 	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/server/v3/storage"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
 	"sigs.k8s.io/yaml"
 )
@@ -86,6 +92,229 @@ func TestConfigFileOtherFields(t *testing.T) {
 	assert.Equal(t, false, cfg.SocketOpts.ReuseAddress, "ReuseAddress does not match")
 }
 
+// TestConfigFileFormats ensures that TOML and HCL config files are decoded
+// into the same fields as YAML config files.
+func TestConfigFileFormats(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "etcd.yml",
+			data: `
+name: infra1
+listen-client-urls: http://localhost:12379
+log-outputs: [/dev/null]
+heartbeat-interval: 200
+client-transport-security:
+  cert-file: ccert
+  client-cert-auth: true
+`,
+		},
+		{
+			name: "etcd.toml",
+			data: `
+name = "infra1"
+listen-client-urls = "http://localhost:12379"
+log-outputs = ["/dev/null"]
+heartbeat-interval = 200
+
+[client-transport-security]
+cert-file = "ccert"
+client-cert-auth = true
+`,
+		},
+		{
+			name: "etcd.hcl",
+			data: `
+name = "infra1"
+listen-client-urls = "http://localhost:12379"
+log-outputs = ["/dev/null"]
+heartbeat-interval = 200
+
+client-transport-security {
+  cert-file = "ccert"
+  client-cert-auth = true
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.name)
+			if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := ConfigFromFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, "infra1", cfg.Name)
+			assert.Equal(t, "http://localhost:12379", cfg.LCUrls[0].String())
+			assert.Equal(t, []string{"/dev/null"}, cfg.LogOutputs)
+			assert.Equal(t, uint(200), cfg.TickMs)
+			assert.Equal(t, "ccert", cfg.ClientTLSInfo.CertFile)
+			assert.Equal(t, true, cfg.ClientTLSInfo.ClientCertAuth)
+		})
+	}
+}
+
+func TestConfigFileFormatsInvalid(t *testing.T) {
+	for _, name := range []string{"etcd.toml", "etcd.hcl"} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, []byte("name = {"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ConfigFromFile(path); err == nil {
+			t.Errorf("%s: expected error parsing invalid config file", name)
+		}
+	}
+}
+
+// TestConfigFileExpandEnv ensures that environment variable references in
+// config files are expanded, unless escaped.
+func TestConfigFileExpandEnv(t *testing.T) {
+	t.Setenv("ETCD_TEST_CERT_DIR", "/etc/etcd/pki")
+	t.Setenv("ETCD_TEST_TOKEN", "secret-token")
+	t.Setenv("ETCD_TEST_HEARTBEAT", "200")
+
+	path := filepath.Join(t.TempDir(), "etcd.yml")
+	data := `
+name: $${ETCD_TEST_TOKEN}
+initial-cluster-token: ${ETCD_TEST_TOKEN}
+heartbeat-interval: ${ETCD_TEST_HEARTBEAT}
+log-outputs: [/dev/null]
+peer-transport-security:
+  cert-file: ${ETCD_TEST_CERT_DIR}/peer.crt
+`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ConfigFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "${ETCD_TEST_TOKEN}", cfg.Name)
+	assert.Equal(t, "secret-token", cfg.InitialClusterToken)
+	assert.Equal(t, uint(200), cfg.TickMs)
+	assert.Equal(t, "/etc/etcd/pki/peer.crt", cfg.PeerTLSInfo.CertFile)
+
+	if err = os.WriteFile(path, []byte("name: ${ETCD_TEST_UNDEFINED}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ConfigFromFile(path); err == nil {
+		t.Error("expected error referencing undefined environment variable")
+	}
+}
+
+// TestConfigFileExpandEnvValues ensures that environment variables are only
+// expanded in values, and that their values cannot alter the document.
+func TestConfigFileExpandEnvValues(t *testing.T) {
+	t.Setenv("ETCD_TEST_INJECT", "x\nlog-outputs: [/tmp/injected]")
+	t.Setenv("ETCD_TEST_COLON", "a: b # c")
+	t.Setenv("ETCD_TEST_NUMBER", "1234")
+	t.Setenv("ETCD_TEST_QUOTE", `it's "quoted"`)
+
+	path := filepath.Join(t.TempDir(), "etcd.yml")
+	data := `
+# ${ETCD_TEST_UNDEFINED} is not expanded in comments
+name: ${ETCD_TEST_INJECT}
+initial-cluster-token: "${ETCD_TEST_NUMBER}"
+discovery-proxy: ${ETCD_TEST_COLON}
+discovery-srv: '${ETCD_TEST_QUOTE}'
+log-outputs: [/dev/null, "${ETCD_TEST_NUMBER}"]
+`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ConfigFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "x\nlog-outputs: [/tmp/injected]", cfg.Name)
+	assert.Equal(t, "1234", cfg.InitialClusterToken)
+	assert.Equal(t, "a: b # c", cfg.Dproxy)
+	assert.Equal(t, `it's "quoted"`, cfg.DNSCluster)
+	assert.Equal(t, []string{"/dev/null", "1234"}, cfg.LogOutputs)
+
+	path = filepath.Join(t.TempDir(), "etcd.toml")
+	data = `
+# ${ETCD_TEST_UNDEFINED} is not expanded in comments
+name = "${ETCD_TEST_INJECT}"
+initial-cluster-token = "${ETCD_TEST_NUMBER}"
+log-outputs = ["/dev/null"]
+`
+	if err = os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err = ConfigFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "x\nlog-outputs: [/tmp/injected]", cfg.Name)
+	assert.Equal(t, "1234", cfg.InitialClusterToken)
+
+	path = filepath.Join(t.TempDir(), "etcd.hcl")
+	data = `
+# ${ETCD_TEST_UNDEFINED} is not expanded in comments
+name = "${ETCD_TEST_INJECT}"
+log-outputs = ["/dev/null"]
+
+client-transport-security {
+  cert-file = "${ETCD_TEST_NUMBER}.crt"
+}
+`
+	if err = os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err = ConfigFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "x\nlog-outputs: [/tmp/injected]", cfg.Name)
+	assert.Equal(t, "1234.crt", cfg.ClientTLSInfo.CertFile)
+}
+
+// TestConfigFromDir ensures that the config files in a directory are merged
+// in lexical order, with objects merged field by field.
+func TestConfigFromDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"01-base.yaml": `
+name: base
+log-outputs: [stderr]
+client-transport-security:
+  cert-file: ccert
+  key-file: ckey
+`,
+		"02-override.yaml": `
+name: override
+log-outputs: [/dev/null]
+client-transport-security:
+  trusted-ca-file: cca
+`,
+		".hidden.yaml":    `name: hidden`,
+		"03-ignored.json": `{"name": "ignored"}`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := ConfigFromDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "override", cfg.Name)
+	assert.Equal(t, []string{"/dev/null"}, cfg.LogOutputs)
+	assert.Equal(t, "ccert", cfg.ClientTLSInfo.CertFile)
+	assert.Equal(t, "ckey", cfg.ClientTLSInfo.KeyFile)
+	assert.Equal(t, "cca", cfg.ClientTLSInfo.TrustedCAFile)
+
+	if _, err = ConfigFromDir(t.TempDir()); err == nil {
+		t.Error("expected error loading config from empty directory")
+	}
+}
+
 // TestUpdateDefaultClusterFromName ensures that etcd can start with 'etcd --name=abc'.
 func TestUpdateDefaultClusterFromName(t *testing.T) {
 	cfg := NewConfig()
@@ -390,6 +619,11 @@ func TestLogRotation(t *testing.T) {
 			logOutputs:        []string{"/tmp/path"},
 			logRotationConfig: DefaultLogRotationConfig,
 		},
+		{
+			name:              "file and syslog outputs",
+			logOutputs:        []string{"/tmp/path", "syslog://127.0.0.1:514"},
+			logRotationConfig: DefaultLogRotationConfig,
+		},
 		{
 			name:              "invalid logger config",
 			logOutputs:        []string{"/tmp/path"},
@@ -428,3 +662,467 @@ func TestLogRotation(t *testing.T) {
 		})
 	}
 }
+
+func TestZapLoggerCoresAndHooks(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	var hooked []string
+	hook := func(e zapcore.Entry) error {
+		hooked = append(hooked, e.Message)
+		return nil
+	}
+
+	cfg := NewConfig()
+	cfg.LogOutputs = []string{filepath.Join(t.TempDir(), "etcd.log")}
+	cfg.ZapLoggerCores = []zapcore.Core{core}
+	cfg.ZapLoggerHooks = []func(zapcore.Entry) error{hook}
+	// validating twice must not register the cores and hooks twice
+	for i := 0; i < 2; i++ {
+		if err := cfg.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lg := cfg.GetLogger()
+	lg.Info("info log")
+	lg.Warn("warn log")
+
+	var observed []string
+	for _, e := range logs.All() {
+		observed = append(observed, e.Message)
+	}
+	assert.Equal(t, []string{"warn log"}, observed)
+	assert.Equal(t, []string{"info log", "warn log"}, hooked)
+}
+
+func TestBackendEngineValidation(t *testing.T) {
+	cfg := NewConfig()
+	cfg.LogOutputs = []string{filepath.Join(t.TempDir(), "etcd.log")}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected the default engine to be valid, got %v", err)
+	}
+	cfg.BackendEngine = "unknown"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "unknown --backend-engine") {
+		t.Fatalf("expected unknown engine error, got %v", err)
+	}
+}
+
+func TestValueCompressionValidation(t *testing.T) {
+	cfg := NewConfig()
+	cfg.LogOutputs = []string{filepath.Join(t.TempDir(), "etcd.log")}
+	cfg.ExperimentalValueCompression = "zstd"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected zstd to be valid, got %v", err)
+	}
+	cfg.ExperimentalValueCompression = "gzip"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "--experimental-value-compression") {
+		t.Fatalf("expected unknown compression error, got %v", err)
+	}
+}
+
+func TestWALCompressionValidation(t *testing.T) {
+	cfg := NewConfig()
+	cfg.LogOutputs = []string{filepath.Join(t.TempDir(), "etcd.log")}
+	cfg.ExperimentalWALCompression = "zstd"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected zstd to be valid, got %v", err)
+	}
+	cfg.ExperimentalWALCompression = "lz4"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "--experimental-wal-compression") {
+		t.Fatalf("expected unknown compression error, got %v", err)
+	}
+}
+
+func TestWALArchiveValidation(t *testing.T) {
+	cfg := NewConfig()
+	cfg.LogOutputs = []string{filepath.Join(t.TempDir(), "etcd.log")}
+	cfg.ExperimentalWALArchive = "file://" + t.TempDir()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected a file sink to be valid, got %v", err)
+	}
+	cfg.ExperimentalWALArchiveInterval = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "--experimental-wal-archive-interval") {
+		t.Fatalf("expected interval error, got %v", err)
+	}
+	cfg.ExperimentalWALArchive = "ftp://archive"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "--experimental-wal-archive") {
+		t.Fatalf("expected unknown sink error, got %v", err)
+	}
+}
+
+func TestWALSegmentValidation(t *testing.T) {
+	cfg := NewConfig()
+	cfg.LogOutputs = []string{filepath.Join(t.TempDir(), "etcd.log")}
+	cfg.ExperimentalWALSegmentSize = 4 * 1024 * 1024
+	cfg.ExperimentalWALPreallocSize = -1
+	cfg.ExperimentalWALTmpSegments = 3
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected the segment options to be valid, got %v", err)
+	}
+	cfg.ExperimentalWALTmpSegments = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "--experimental-wal-tmp-segments") {
+		t.Fatalf("expected tmp segments error, got %v", err)
+	}
+	cfg.ExperimentalWALTmpSegments = 1
+	cfg.ExperimentalWALSegmentSize = 0
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "--experimental-wal-segment-size") {
+		t.Fatalf("expected segment size error, got %v", err)
+	}
+}
+
+func TestElectionPriorityValidation(t *testing.T) {
+	cfg := NewConfig()
+	cfg.LogOutputs = []string{filepath.Join(t.TempDir(), "etcd.log")}
+	cfg.ExperimentalElectionPriority = 10
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected the election priority to be valid, got %v", err)
+	}
+	cfg.ExperimentalElectionPriority = -1
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "--experimental-election-priority") {
+		t.Fatalf("expected election priority error, got %v", err)
+	}
+}
+
+func TestLeaderPlacementValidation(t *testing.T) {
+	tests := []struct {
+		labels  string
+		zones   string
+		wantErr string
+	}{
+		{labels: "zone=us-east-1a,disk=ssd", zones: "us-east-1a,us-east-1b"},
+		{labels: "zone", wantErr: "--experimental-member-labels"},
+		{labels: "=us-east-1a", wantErr: "--experimental-member-labels"},
+		{labels: "zone=a,zone=b", wantErr: "--experimental-member-labels"},
+		{zones: "us-east-1a,", wantErr: "--experimental-leader-zones"},
+		{zones: "us-east-1a,us-east-1a", wantErr: "--experimental-leader-zones"},
+	}
+	for i, tt := range tests {
+		cfg := NewConfig()
+		cfg.LogOutputs = []string{filepath.Join(t.TempDir(), "etcd.log")}
+		cfg.ExperimentalMemberLabels = tt.labels
+		cfg.ExperimentalLeaderZones = tt.zones
+		err := cfg.Validate()
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("#%d: expected valid config, got %v", i, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("#%d: expected %s error, got %v", i, tt.wantErr, err)
+		}
+	}
+}
+
+func TestPeerCompressionValidation(t *testing.T) {
+	cfg := NewConfig()
+	cfg.LogOutputs = []string{filepath.Join(t.TempDir(), "etcd.log")}
+	cfg.ExperimentalPeerCompression = "none,8211f1d0f64f3269=zstd"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected the peer compression to be valid, got %v", err)
+	}
+	cfg.ExperimentalPeerCompression = "lz4"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "--experimental-peer-compression") {
+		t.Fatalf("expected peer compression error, got %v", err)
+	}
+}
+
+func TestMaxInflightValidation(t *testing.T) {
+	tests := []struct {
+		msgs    int
+		bytes   uint64
+		wantErr string
+	}{
+		{msgs: 512},
+		{msgs: 4096, bytes: 64 * 1024 * 1024},
+		{msgs: 0, wantErr: "--experimental-max-inflight-msgs"},
+		{msgs: 4097, wantErr: "--experimental-max-inflight-msgs"},
+		{msgs: 512, bytes: 1024, wantErr: "--experimental-max-inflight-bytes"},
+	}
+	for i, tt := range tests {
+		cfg := NewConfig()
+		cfg.LogOutputs = []string{filepath.Join(t.TempDir(), "etcd.log")}
+		cfg.ExperimentalMaxInflightMsgs = tt.msgs
+		cfg.ExperimentalMaxInflightBytes = tt.bytes
+		err := cfg.Validate()
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("#%d: expected valid config, got %v", i, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("#%d: expected %s error, got %v", i, tt.wantErr, err)
+		}
+	}
+}
+
+func TestWALDurabilityValidation(t *testing.T) {
+	tests := []struct {
+		durability string
+		interval   time.Duration
+		noFsync    bool
+
+		wantDurability string
+		wantErr        string
+	}{
+		{durability: "fsync", interval: DefaultUnsafeWALSyncInterval, wantDurability: "fsync"},
+		{durability: "batched", interval: DefaultUnsafeWALSyncInterval, wantDurability: "batched"},
+		{durability: "none", wantDurability: "none"},
+		{durability: "fsync", noFsync: true, wantDurability: "none"},
+		{durability: "async", wantErr: "--unsafe-wal-durability"},
+		{durability: "batched", wantErr: "--unsafe-wal-sync-interval"},
+		{durability: "batched", interval: DefaultUnsafeWALSyncInterval, noFsync: true, wantErr: "--unsafe-no-fsync"},
+	}
+	for i, tt := range tests {
+		cfg := NewConfig()
+		cfg.LogOutputs = []string{filepath.Join(t.TempDir(), "etcd.log")}
+		cfg.UnsafeWALDurability = tt.durability
+		cfg.UnsafeWALSyncInterval = tt.interval
+		cfg.UnsafeNoFsync = tt.noFsync
+		err := cfg.Validate()
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("#%d: expected valid config, got %v", i, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("#%d: expected %s error, got %v", i, tt.wantErr, err)
+		}
+		if d := cfg.walDurability(); tt.wantErr == "" && d != tt.wantDurability {
+			t.Errorf("#%d: durability = %q, want %q", i, d, tt.wantDurability)
+		}
+	}
+}
+
+func TestLeaderDegradationValidation(t *testing.T) {
+	tests := []struct {
+		fsync    time.Duration
+		steal    float64
+		pressure float64
+		duration time.Duration
+		wantErr  string
+	}{
+		{duration: time.Second},
+		{fsync: 100 * time.Millisecond, steal: 20, pressure: 50, duration: time.Second},
+		{fsync: -time.Second, duration: time.Second, wantErr: "--experimental-leader-degradation-fsync-threshold"},
+		{steal: 101, duration: time.Second, wantErr: "--experimental-leader-degradation-cpu-steal-threshold"},
+		{pressure: -1, duration: time.Second, wantErr: "--experimental-leader-degradation-memory-pressure-threshold"},
+		{wantErr: "--experimental-leader-degradation-duration"},
+	}
+	for i, tt := range tests {
+		cfg := NewConfig()
+		cfg.LogOutputs = []string{filepath.Join(t.TempDir(), "etcd.log")}
+		cfg.ExperimentalLeaderDegradationFsyncThreshold = tt.fsync
+		cfg.ExperimentalLeaderDegradationCPUStealThreshold = tt.steal
+		cfg.ExperimentalLeaderDegradationMemoryPressureThreshold = tt.pressure
+		cfg.ExperimentalLeaderDegradationDuration = tt.duration
+		err := cfg.Validate()
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("#%d: expected valid config, got %v", i, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("#%d: expected %s error, got %v", i, tt.wantErr, err)
+		}
+	}
+}
+
+func TestWitnessValidation(t *testing.T) {
+	cfg := NewConfig()
+	cfg.LogOutputs = []string{filepath.Join(t.TempDir(), "etcd.log")}
+	cfg.ExperimentalWitness = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected the witness to be valid, got %v", err)
+	}
+	cfg.ForceNewCluster = true
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "--experimental-witness") {
+		t.Fatalf("expected witness error, got %v", err)
+	}
+}
+
+func TestNewConfigOptions(t *testing.T) {
+	peer, _ := url.Parse("http://10.0.0.1:2380")
+	client, _ := url.Parse("https://10.0.0.1:2379")
+
+	cfg := NewConfig(
+		WithName("infra1"),
+		WithDataDir("/var/lib/etcd"),
+		WithPeerURLs([]url.URL{*peer}),
+		WithClientURLs([]url.URL{*client}),
+		WithClientAutoTLS(),
+		WithQuota(8*1024*1024*1024),
+		WithTimers(200*time.Millisecond, 2*time.Second),
+		WithPeriodicCompaction(time.Hour),
+	)
+	assert.Equal(t, "infra1", cfg.Name)
+	assert.Equal(t, "/var/lib/etcd", cfg.Dir)
+	assert.Equal(t, []url.URL{*client}, cfg.ACUrls)
+	assert.Equal(t, "infra1=http://10.0.0.1:2380", cfg.InitialCluster)
+	assert.True(t, cfg.ClientAutoTLS)
+	assert.Equal(t, int64(8*1024*1024*1024), cfg.QuotaBackendBytes)
+	assert.Equal(t, uint(200), cfg.TickMs)
+	assert.Equal(t, uint(2000), cfg.ElectionMs)
+	assert.Equal(t, CompactorModePeriodic, cfg.AutoCompactionMode)
+	assert.Equal(t, "1h0m0s", cfg.AutoCompactionRetention)
+	assert.NoError(t, cfg.Validate())
+
+	cfg = NewConfig(WithName("infra1"), WithInitialCluster("infra1=http://10.0.0.1:2380,infra2=http://10.0.0.2:2380"), WithExistingCluster())
+	assert.Equal(t, "infra1=http://10.0.0.1:2380,infra2=http://10.0.0.2:2380", cfg.InitialCluster)
+	assert.Equal(t, ClusterStateFlagExisting, cfg.ClusterState)
+}
+
+func TestNewValidatedConfig(t *testing.T) {
+	httpURL, _ := url.Parse("http://10.0.0.1:2379")
+	httpsURL, _ := url.Parse("https://10.0.0.1:2379")
+	httpPeer, _ := url.Parse("http://10.0.0.1:2380")
+	tlsInfo := transport.TLSInfo{CertFile: "server.crt", KeyFile: "server.key"}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{
+			name: "valid",
+			opts: []Option{WithName("infra1"), WithClientURLs([]url.URL{*httpsURL}), WithClientTLS(tlsInfo), WithQuota(storage.MaxQuotaBytes)},
+		},
+		{
+			name:    "client TLS files without https URLs",
+			opts:    []Option{WithClientURLs([]url.URL{*httpURL}), WithClientTLS(tlsInfo)},
+			wantErr: "client TLS is set but none of the client URLs",
+		},
+		{
+			name:    "peer auto TLS without https URLs",
+			opts:    []Option{WithPeerURLs([]url.URL{*httpPeer}), WithPeerAutoTLS()},
+			wantErr: "peer TLS is set but none of the peer URLs",
+		},
+		{
+			name:    "https URLs without client TLS",
+			opts:    []Option{WithClientURLs([]url.URL{*httpsURL})},
+			wantErr: "client URLs [\"https://10.0.0.1:2379\"] are https but client TLS is not set",
+		},
+		{
+			name:    "quota above the backend limit",
+			opts:    []Option{WithQuota(storage.MaxQuotaBytes + 1)},
+			wantErr: "exceeds the maximum backend quota",
+		},
+		{
+			name:    "invalid after options",
+			opts:    []Option{WithTimers(time.Second, time.Second)},
+			wantErr: "--election-timeout[1000ms] should be at least as 5 times as --heartbeat-interval[1000ms]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := NewValidatedConfig(tt.opts...)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				assert.NotNil(t, cfg)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+			assert.Nil(t, cfg)
+		})
+	}
+}
+
+func TestUnixSocketModeValidate(t *testing.T) {
+	tests := []struct {
+		mode  string
+		want  os.FileMode
+		valid bool
+	}{
+		{"", 0, true},
+		{"0660", 0660, true},
+		{"600", 0600, true},
+		{"0", 0, false},
+		{"0800", 0, false},
+		{"1777", 0, false},
+		{"rw", 0, false},
+	}
+	for _, tt := range tests {
+		cfg := NewConfig()
+		cfg.UnixSocketMode = tt.mode
+		mode, err := cfg.unixSocketMode()
+		if (err == nil) != tt.valid {
+			t.Errorf("%q: expected valid %v, got %v", tt.mode, tt.valid, err)
+		}
+		if mode != tt.want {
+			t.Errorf("%q: mode = %v, want %v", tt.mode, mode, tt.want)
+		}
+		if err = cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%q: expected Validate to pass %v, got %v", tt.mode, tt.valid, err)
+		}
+	}
+}
+
+func TestHotKeySamplingValidate(t *testing.T) {
+	tests := []struct {
+		rate   float64
+		window time.Duration
+		valid  bool
+	}{
+		{0, 0, true},
+		{0.01, time.Minute, true},
+		{1, time.Second, true},
+		{-0.1, time.Minute, false},
+		{1.5, time.Minute, false},
+		{0.5, time.Millisecond, false},
+	}
+	for _, tt := range tests {
+		cfg := NewConfig()
+		cfg.ExperimentalHotKeySampleRate = tt.rate
+		cfg.ExperimentalHotKeyWindow = tt.window
+		if err := cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("rate %v, window %v: expected Validate to pass %v, got %v", tt.rate, tt.window, tt.valid, err)
+		}
+	}
+}
+
+func TestOIDCValidate(t *testing.T) {
+	if p := NewConfig().AuthOIDCUsernamePrefix; p == "" {
+		t.Fatal("expected a default OIDC username prefix")
+	}
+
+	tests := []struct {
+		name   string
+		issuer string
+		prefix string
+		valid  bool
+	}{
+		{"disabled", "", "", true},
+		{"prefix", "https://issuer.example.com", "oidc:", true},
+		{"empty prefix", "https://issuer.example.com", "", false},
+	}
+	for _, tt := range tests {
+		cfg := NewConfig()
+		cfg.AuthOIDCIssuerURL = tt.issuer
+		cfg.AuthOIDCClientID = "etcd"
+		cfg.AuthOIDCUsernamePrefix = tt.prefix
+		if err := cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%s: expected Validate to pass %v, got %v", tt.name, tt.valid, err)
+		}
+	}
+}
+
+func TestProfilingValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		apply time.Duration
+		fsync time.Duration
+		cpu   time.Duration
+		url   string
+		valid bool
+	}{
+		{"disabled", 0, 0, 0, "", true},
+		{"apply", time.Second, 0, 10 * time.Second, "", true},
+		{"fsync with url", 0, time.Second, 10 * time.Second, "http://127.0.0.1:8080/profiles", true},
+		{"negative threshold", -time.Second, 0, 10 * time.Second, "", false},
+		{"no cpu duration", time.Second, 0, 0, "", false},
+		{"cpu longer than interval", time.Second, 0, time.Hour, "", false},
+		{"invalid url", time.Second, 0, 10 * time.Second, "unix:///tmp/sock", false},
+	}
+	for _, tt := range tests {
+		cfg := NewConfig()
+		cfg.ExperimentalProfileApplyThreshold = tt.apply
+		cfg.ExperimentalProfileFsyncThreshold = tt.fsync
+		cfg.ExperimentalProfileCPUDuration = tt.cpu
+		cfg.ExperimentalProfileURL = tt.url
+		if err := cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%s: expected Validate to pass %v, got %v", tt.name, tt.valid, err)
+		}
+	}
+}
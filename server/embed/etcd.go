@@ -22,6 +22,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"runtime"
 	"sort"
 	"strconv"
@@ -30,20 +31,29 @@ import (
 	"time"
 
 	"go.etcd.io/etcd/api/v3/version"
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
 	"go.etcd.io/etcd/client/pkg/v3/transport"
 	"go.etcd.io/etcd/client/pkg/v3/types"
 	"go.etcd.io/etcd/pkg/v3/debugutil"
 	runtimeutil "go.etcd.io/etcd/pkg/v3/runtime"
+	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/config"
 	"go.etcd.io/etcd/server/v3/etcdserver"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/etcdhttp"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/rafthttp"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3compactor"
+	"go.etcd.io/etcd/server/v3/etcdserver/degradation"
+	"go.etcd.io/etcd/server/v3/spiffe"
 	"go.etcd.io/etcd/server/v3/storage"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
+	"go.etcd.io/etcd/server/v3/storage/wal/archive"
 	"go.etcd.io/etcd/server/v3/verify"
 
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/soheilhy/cmux"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
 )
@@ -60,6 +70,10 @@ const (
 	// in a cluster, so it should reserve 96.
 	// For the safety, we set the total reserved number to 150.
 	reservedInternalFDNum = 150
+
+	// spiffeFetchTimeout is how long the start waits for the first X.509
+	// SVID from the SPIFFE Workload API.
+	spiffeFetchTimeout = 30 * time.Second
 )
 
 // Etcd contains a running etcd server and its listeners.
@@ -71,12 +85,15 @@ type Etcd struct {
 	metricsListeners []net.Listener
 
 	tracingExporterShutdown func()
+	auditLogClose           func()
+	spiffeSource            *spiffe.X509Source
 
 	Server *etcdserver.EtcdServer
 
-	cfg   Config
-	stopc chan struct{}
-	errc  chan error
+	cfg     Config
+	stopc   chan struct{}
+	errc    chan error
+	dirLock *fileutil.LockedFile
 
 	closeOnce sync.Once
 }
@@ -111,6 +128,17 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 		e = nil
 	}()
 
+	if e.dirLock, err = lockDataDir(cfg.logger, cfg.Dir); err != nil {
+		return e, err
+	}
+	if cfg.PidFile != "" {
+		if err = writePidFile(cfg.PidFile); err != nil {
+			err = fmt.Errorf("cannot write pid file %q: %v", cfg.PidFile, err)
+			cfg.PidFile = ""
+			return e, err
+		}
+	}
+
 	if !cfg.SocketOpts.Empty() {
 		cfg.logger.Info(
 			"configuring socket options",
@@ -118,6 +146,11 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 			zap.Bool("reuse-port", cfg.SocketOpts.ReusePort),
 		)
 	}
+	if cfg.SPIFFEWorkloadAPIAddr != "" {
+		if e.spiffeSource, err = configureSPIFFE(cfg); err != nil {
+			return e, err
+		}
+	}
 	e.cfg.logger.Info(
 		"configuring peer listeners",
 		zap.Strings("listen-peer-urls", e.cfg.getLPURLs()),
@@ -138,6 +171,13 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 		e.Clients = append(e.Clients, sctx.l)
 	}
 
+	for _, l := range cfg.Listeners {
+		e.cfg.logger.Warn(
+			"pre-opened listener does not match any listen URL; ignoring",
+			zap.String("address", l.Addr().String()),
+		)
+	}
+
 	var (
 		urlsmap types.URLsMap
 		token   string
@@ -159,72 +199,189 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 	if err != nil {
 		return e, err
 	}
+	autoCompactionPrefixRetentions, err := v3compactor.ParseRetentionPolicies(cfg.ExperimentalAutoCompactionPrefixRetention)
+	if err != nil {
+		return e, err
+	}
+	memberLabels, err := membership.ParseLabels(cfg.ExperimentalMemberLabels)
+	if err != nil {
+		return e, err
+	}
+	leaderZones, err := parseLeaderZones(cfg.ExperimentalLeaderZones)
+	if err != nil {
+		return e, err
+	}
+	peerCompression, err := rafthttp.ParsePeerCompression(cfg.ExperimentalPeerCompression)
+	if err != nil {
+		return e, err
+	}
 
 	backendFreelistType := parseBackendFreelistType(cfg.BackendFreelistType)
 
+	runtimeConfig, err := cfg.runtimeConfig()
+	if err != nil {
+		return e, err
+	}
 	srvcfg := config.ServerConfig{
-		Name:                                     cfg.Name,
-		ClientURLs:                               cfg.ACUrls,
-		PeerURLs:                                 cfg.APUrls,
-		DataDir:                                  cfg.Dir,
-		DedicatedWALDir:                          cfg.WalDir,
-		SnapshotCount:                            cfg.SnapshotCount,
-		SnapshotCatchUpEntries:                   cfg.SnapshotCatchUpEntries,
-		MaxSnapFiles:                             cfg.MaxSnapFiles,
-		MaxWALFiles:                              cfg.MaxWalFiles,
-		InitialPeerURLsMap:                       urlsmap,
-		InitialClusterToken:                      token,
-		DiscoveryURL:                             cfg.Durl,
-		DiscoveryProxy:                           cfg.Dproxy,
-		DiscoveryCfg:                             cfg.DiscoveryCfg,
-		NewCluster:                               cfg.IsNewCluster(),
-		PeerTLSInfo:                              cfg.PeerTLSInfo,
-		TickMs:                                   cfg.TickMs,
-		ElectionTicks:                            cfg.ElectionTicks(),
-		WaitClusterReadyTimeout:                  cfg.ExperimentalWaitClusterReadyTimeout,
-		InitialElectionTickAdvance:               cfg.InitialElectionTickAdvance,
-		AutoCompactionRetention:                  autoCompactionRetention,
-		AutoCompactionMode:                       cfg.AutoCompactionMode,
-		QuotaBackendBytes:                        cfg.QuotaBackendBytes,
-		BackendBatchLimit:                        cfg.BackendBatchLimit,
-		BackendFreelistType:                      backendFreelistType,
-		BackendBatchInterval:                     cfg.BackendBatchInterval,
-		MaxTxnOps:                                cfg.MaxTxnOps,
-		MaxRequestBytes:                          cfg.MaxRequestBytes,
-		SocketOpts:                               cfg.SocketOpts,
-		StrictReconfigCheck:                      cfg.StrictReconfigCheck,
-		ClientCertAuthEnabled:                    cfg.ClientTLSInfo.ClientCertAuth,
-		AuthToken:                                cfg.AuthToken,
-		BcryptCost:                               cfg.BcryptCost,
-		TokenTTL:                                 cfg.AuthTokenTTL,
+		Name:                           cfg.Name,
+		ClientURLs:                     cfg.ACUrls,
+		PeerURLs:                       cfg.APUrls,
+		DataDir:                        cfg.Dir,
+		DedicatedWALDir:                cfg.WalDir,
+		SnapshotCount:                  cfg.SnapshotCount,
+		SnapshotCatchUpEntries:         cfg.SnapshotCatchUpEntries,
+		MaxSnapFiles:                   cfg.MaxSnapFiles,
+		MaxWALFiles:                    cfg.MaxWalFiles,
+		InitialPeerURLsMap:             urlsmap,
+		InitialClusterToken:            token,
+		DiscoveryURL:                   cfg.Durl,
+		DiscoveryProxy:                 cfg.Dproxy,
+		DiscoveryCfg:                   cfg.DiscoveryCfg,
+		NewCluster:                     cfg.IsNewCluster(),
+		PeerTLSInfo:                    cfg.PeerTLSInfo,
+		TickMs:                         cfg.TickMs,
+		ElectionTicks:                  cfg.ElectionTicks(),
+		WaitClusterReadyTimeout:        cfg.ExperimentalWaitClusterReadyTimeout,
+		InitialElectionTickAdvance:     cfg.InitialElectionTickAdvance,
+		AutoCompactionRetention:        autoCompactionRetention,
+		AutoCompactionMode:             cfg.AutoCompactionMode,
+		AutoCompactionPrefixRetentions: autoCompactionPrefixRetentions,
+		TimerJitter:                    cfg.TimerJitter,
+		QuotaBackendBytes:              cfg.QuotaBackendBytes,
+		BackendBatchLimit:              cfg.BackendBatchLimit,
+		BackendEngine:                  cfg.BackendEngine,
+		BackendFreelistType:            backendFreelistType,
+		BackendBatchInterval:           cfg.BackendBatchInterval,
+		MaxTxnOps:                      cfg.MaxTxnOps,
+		MaxRequestBytes:                cfg.MaxRequestBytes,
+		MaxLeasesPerUser:               cfg.MaxLeasesPerUser,
+		SocketOpts:                     cfg.SocketOpts,
+		StrictReconfigCheck:            cfg.StrictReconfigCheck,
+		ClientCertAuthEnabled:          cfg.ClientTLSInfo.ClientCertAuth,
+		ClientCertRoleRules:            cfg.ClientCertRoleRules,
+		AuthToken:                      cfg.AuthToken,
+		BcryptCost:                     cfg.BcryptCost,
+		TokenTTL:                       cfg.AuthTokenTTL,
+		AuthOIDC: auth.OIDCConfig{
+			IssuerURL:      cfg.AuthOIDCIssuerURL,
+			ClientID:       cfg.AuthOIDCClientID,
+			UsernameClaim:  cfg.AuthOIDCUsernameClaim,
+			UsernamePrefix: cfg.AuthOIDCUsernamePrefix,
+			RoleRules:      cfg.AuthOIDCRoleRules,
+		},
+		AuthLDAP: auth.LDAPConfig{
+			URL:             cfg.AuthLDAPURL,
+			BindDNTemplates: cfg.AuthLDAPBindDNTemplates,
+			GroupAttribute:  cfg.AuthLDAPGroupAttribute,
+			RoleRules:       cfg.AuthLDAPRoleRules,
+			CAFile:          cfg.AuthLDAPCAFile,
+		},
+		AuthLockout: auth.LockoutPolicy{
+			Threshold:   cfg.AuthLockoutThreshold,
+			Duration:    cfg.AuthLockoutDuration,
+			MaxDuration: cfg.AuthLockoutMaxDuration,
+		},
+		AuthPasswordPolicy:                       cfg.authPasswordPolicy(),
 		CORS:                                     cfg.CORS,
 		HostWhitelist:                            cfg.HostWhitelist,
 		InitialCorruptCheck:                      cfg.ExperimentalInitialCorruptCheck,
 		CorruptCheckTime:                         cfg.ExperimentalCorruptCheckTime,
 		PreVote:                                  cfg.PreVote,
+		Witness:                                  cfg.ExperimentalWitness,
+		ElectionPriority:                         cfg.ExperimentalElectionPriority,
+		MemberLabels:                             memberLabels,
+		LeaderZones:                              leaderZones,
 		Logger:                                   cfg.logger,
+		LoggerLevel:                              cfg.logLevel,
 		ForceNewCluster:                          cfg.ForceNewCluster,
 		EnableGRPCGateway:                        cfg.EnableGRPCGateway,
+		EnableGRPCReflection:                     cfg.EnableGRPCReflection,
 		ExperimentalEnableDistributedTracing:     cfg.ExperimentalEnableDistributedTracing,
 		UnsafeNoFsync:                            cfg.UnsafeNoFsync,
 		EnableLeaseCheckpoint:                    cfg.ExperimentalEnableLeaseCheckpoint,
 		LeaseCheckpointPersist:                   cfg.ExperimentalEnableLeaseCheckpointPersist,
 		CompactionBatchLimit:                     cfg.ExperimentalCompactionBatchLimit,
 		CompactionSleepInterval:                  cfg.ExperimentalCompactionSleepInterval,
+		DefragBatchLimit:                         cfg.ExperimentalDefragBatchLimit,
+		DefragBatchInterval:                      cfg.ExperimentalDefragBatchInterval,
+		ValueCompression:                         cfg.ExperimentalValueCompression,
+		ValueCompressionThreshold:                cfg.ExperimentalValueCompressionThreshold,
+		WALCompression:                           cfg.ExperimentalWALCompression,
+		WALDurability:                            cfg.walDurability(),
+		WALSyncInterval:                          cfg.UnsafeWALSyncInterval,
+		PeerCompression:                          peerCompression,
+		MaxInflightMsgs:                          cfg.ExperimentalMaxInflightMsgs,
+		MaxInflightBytes:                         cfg.ExperimentalMaxInflightBytes,
+		AdaptiveInflightMsgs:                     cfg.ExperimentalAdaptiveInflightMsgs,
+		WALIOURing:                               cfg.ExperimentalWALIOURing,
+		WALSegmentSize:                           cfg.ExperimentalWALSegmentSize,
+		WALPreallocSize:                          cfg.ExperimentalWALPreallocSize,
+		WALTmpSegments:                           cfg.ExperimentalWALTmpSegments,
+		RangeTombstoneThreshold:                  cfg.ExperimentalRangeTombstoneThreshold,
+		ReadCacheSize:                            cfg.ExperimentalReadCacheSize,
+		WatchStuckPolicy:                         cfg.ExperimentalWatchStuckPolicy,
+		WatchStuckMaxBytes:                       cfg.ExperimentalWatchStuckMaxBytes,
+		WatchStuckMaxAge:                         cfg.ExperimentalWatchStuckMaxAge,
+		WatchHistoryRetention:                    cfg.ExperimentalWatchHistoryRetention,
+		BackendScrubInterval:                     cfg.ExperimentalBackendScrubInterval,
+		BackendScrubRate:                         cfg.ExperimentalBackendScrubRate,
+		MaxChunkedValueBytes:                     cfg.ExperimentalMaxChunkedValueBytes,
 		WatchProgressNotifyInterval:              cfg.ExperimentalWatchProgressNotifyInterval,
 		DowngradeCheckTime:                       cfg.ExperimentalDowngradeCheckTime,
 		WarningApplyDuration:                     cfg.ExperimentalWarningApplyDuration,
+		BoundedStalenessWindow:                   cfg.ExperimentalBoundedStalenessWindow,
 		WarningUnaryRequestDuration:              cfg.ExperimentalWarningUnaryRequestDuration,
+		SlowRequestThreshold:                     cfg.SlowRequestThreshold,
 		ExperimentalMemoryMlock:                  cfg.ExperimentalMemoryMlock,
 		ExperimentalTxnModeWriteWithSharedBuffer: cfg.ExperimentalTxnModeWriteWithSharedBuffer,
 		ExperimentalBootstrapDefragThresholdMegabytes: cfg.ExperimentalBootstrapDefragThresholdMegabytes,
 		ExperimentalMaxLearners:                       cfg.ExperimentalMaxLearners,
+		HotKeySampleRate:                              cfg.ExperimentalHotKeySampleRate,
+		HotKeyWindow:                                  cfg.ExperimentalHotKeyWindow,
+		ProfileApplyThreshold:                         cfg.ExperimentalProfileApplyThreshold,
+		ProfileFsyncThreshold:                         cfg.ExperimentalProfileFsyncThreshold,
+		ProfileMinInterval:                            cfg.ExperimentalProfileMinInterval,
+		ProfileCPUDuration:                            cfg.ExperimentalProfileCPUDuration,
+		ProfileURL:                                    cfg.ExperimentalProfileURL,
 		V2Deprecation:                                 cfg.V2DeprecationEffective(),
+		RuntimeConfig:                                 runtimeConfig,
+		LeaderDegradation: degradation.Config{
+			FsyncThreshold:          cfg.ExperimentalLeaderDegradationFsyncThreshold,
+			CPUStealThreshold:       cfg.ExperimentalLeaderDegradationCPUStealThreshold,
+			MemoryPressureThreshold: cfg.ExperimentalLeaderDegradationMemoryPressureThreshold,
+			Duration:                cfg.ExperimentalLeaderDegradationDuration,
+		},
+	}
+
+	if srvcfg.AuditLogger, e.auditLogClose, err = setupAuditLogger(cfg); err != nil {
+		return e, err
+	}
+	srvcfg.AuditLogRedactValues = cfg.AuditLogRedactValues
+
+	srvcfg.Authorizer = cfg.Authorizer
+	if srvcfg.Authorizer == nil && cfg.AuthorizationWebhookURL != "" {
+		srvcfg.Authorizer = auth.NewWebhookAuthorizer(cfg.AuthorizationWebhookURL, cfg.AuthorizationWebhookTimeout)
+	}
+
+	srvcfg.EncryptionKeyProvider = cfg.EncryptionKeyProvider
+	if srvcfg.EncryptionKeyProvider == nil && cfg.ExperimentalEncryptionKeyFile != "" {
+		if srvcfg.EncryptionKeyProvider, err = encryption.NewFileKeyProvider(cfg.ExperimentalEncryptionKeyFile); err != nil {
+			return e, err
+		}
+	}
+	if srvcfg.EncryptionKeyProvider != nil {
+		cfg.logger.Warn("the WAL and the snapshots are encrypted at rest, but not the backend database")
+	}
+	if cfg.ExperimentalWALArchive != "" {
+		if srvcfg.WALArchiveSink, err = archive.NewSink(cfg.ExperimentalWALArchive); err != nil {
+			return e, err
+		}
+		srvcfg.WALArchiveInterval = cfg.ExperimentalWALArchiveInterval
 	}
 
 	if srvcfg.ExperimentalEnableDistributedTracing {
 		tctx := context.Background()
-		tracingExporter, opts, err := setupTracingExporter(tctx, cfg)
+		tracingExporter, tracerProvider, opts, err := setupTracingExporter(tctx, cfg)
 		if err != nil {
 			return e, err
 		}
@@ -233,6 +390,7 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 		}
 		e.tracingExporterShutdown = func() { tracingExporter.Shutdown(tctx) }
 		srvcfg.ExperimentalTracerOptions = opts
+		srvcfg.ExperimentalTracerProvider = tracerProvider
 
 		e.cfg.logger.Info(
 			"distributed tracing setup enabled",
@@ -339,10 +497,12 @@ func print(lg *zap.Logger, ec Config, sc config.ServerConfig, memberInitialized
 		zap.Int64("quota-size-bytes", quota),
 		zap.Bool("pre-vote", sc.PreVote),
 		zap.Bool("initial-corrupt-check", sc.InitialCorruptCheck),
+		zap.Bool("witness", sc.Witness),
 		zap.String("corrupt-check-time-interval", sc.CorruptCheckTime.String()),
 		zap.String("auto-compaction-mode", sc.AutoCompactionMode),
 		zap.Duration("auto-compaction-retention", sc.AutoCompactionRetention),
 		zap.String("auto-compaction-interval", sc.AutoCompactionRetention.String()),
+		zap.Duration("timer-jitter", sc.TimerJitter),
 		zap.String("discovery-url", sc.DiscoveryURL),
 		zap.String("discovery-proxy", sc.DiscoveryProxy),
 
@@ -369,6 +529,50 @@ func (e *Etcd) Config() Config {
 	return e.cfg
 }
 
+// ReloadConfig re-reads the client and peer TLS certificates, keys and
+// trusted CA files, and the log level if the configuration was loaded
+// from a file. New connections use the reloaded TLS assets; established
+// connections are not affected. Nothing is applied if any of them fails
+// to load.
+func (e *Etcd) ReloadConfig() error {
+	lg := e.GetLogger()
+
+	level := e.cfg.LogLevel
+	if e.cfg.configFile != "" || e.cfg.configDir != "" {
+		var err error
+		if level, err = e.cfg.logLevelFromSource(); err != nil {
+			return fmt.Errorf("failed to read configuration: %w", err)
+		}
+	}
+	var zapLevel zapcore.Level
+	if err := zapLevel.Set(level); err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+
+	if err := e.cfg.ClientTLSInfo.Reload(); err != nil {
+		return fmt.Errorf("failed to reload client TLS: %w", err)
+	}
+	if err := e.cfg.PeerTLSInfo.Reload(); err != nil {
+		return fmt.Errorf("failed to reload peer TLS: %w", err)
+	}
+
+	if e.cfg.logLevel == nil {
+		if level != e.cfg.LogLevel {
+			lg.Warn("cannot change log level of a logger not built by etcd", zap.String("log-level", level))
+		}
+	} else {
+		e.cfg.logLevel.SetLevel(zapLevel)
+	}
+
+	lg.Info(
+		"reloaded configuration",
+		zap.String("log-level", level),
+		zap.String("client-tls-info", e.cfg.ClientTLSInfo.String()),
+		zap.String("peer-tls-info", e.cfg.PeerTLSInfo.String()),
+	)
+	return nil
+}
+
 // Close gracefully shuts down all servers/listeners.
 // Client requests will be terminated with request timeout.
 // After timeout, enforce remaning requests be closed immediately.
@@ -395,18 +599,36 @@ func (e *Etcd) Close() {
 		close(e.stopc)
 	})
 
-	// close client requests with request timeout
+	// drain: reject new client streams and hand over leadership while
+	// in-flight requests are still served, so clients can fail over
+	if e.Server != nil {
+		e.Server.Drain()
+		if err := e.Server.TransferLeadership(); err != nil {
+			lg.Warn("leadership transfer failed", zap.String("local-member-id", e.Server.ID().String()), zap.Error(err))
+		}
+	}
+
+	// close client requests with graceful shutdown timeout
 	timeout := 2 * time.Second
 	if e.Server != nil {
 		timeout = e.Server.Cfg.ReqTimeout()
 	}
+	if e.cfg.GracefulShutdownTimeout > 0 {
+		timeout = e.cfg.GracefulShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	var wg sync.WaitGroup
 	for _, sctx := range e.sctxs {
 		for ss := range sctx.serversC {
-			ctx, cancel := context.WithTimeout(context.Background(), timeout)
-			stopServers(ctx, ss)
-			cancel()
+			wg.Add(1)
+			go func(ss *servers) {
+				defer wg.Done()
+				stopServers(ctx, ss)
+			}(ss)
 		}
 	}
+	wg.Wait()
+	cancel()
 
 	for _, sctx := range e.sctxs {
 		sctx.cancel()
@@ -427,9 +649,9 @@ func (e *Etcd) Close() {
 		e.tracingExporterShutdown()
 	}
 
-	// close rafthttp transports
+	// close rafthttp transports; the leadership was transferred when draining
 	if e.Server != nil {
-		e.Server.Stop()
+		e.Server.HardStop()
 	}
 
 	// close all idle connections in peer handler (wait up to 1-second)
@@ -440,11 +662,54 @@ func (e *Etcd) Close() {
 			cancel()
 		}
 	}
+	if e.auditLogClose != nil {
+		e.auditLogClose()
+	}
+	if e.spiffeSource != nil {
+		e.spiffeSource.Close()
+	}
+	if e.dirLock != nil {
+		if e.cfg.PidFile != "" {
+			os.Remove(e.cfg.PidFile)
+		}
+		e.dirLock.Close()
+	}
 	if e.errc != nil {
 		close(e.errc)
 	}
 }
 
+// configureSPIFFE fetches the X.509 SVID of the member from the SPIFFE
+// Workload API, used by the client and peer TLS of the https URLs with no
+// cert and key files.
+func configureSPIFFE(cfg *Config) (*spiffe.X509Source, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), spiffeFetchTimeout)
+	defer cancel()
+	src, err := spiffe.NewX509Source(ctx, cfg.logger, cfg.SPIFFEWorkloadAPIAddr)
+	if err != nil {
+		return nil, err
+	}
+	cfg.logger.Info(
+		"fetched X.509 SVID from SPIFFE Workload API",
+		zap.String("spiffe-workload-api-addr", cfg.SPIFFEWorkloadAPIAddr),
+		zap.String("spiffe-id", src.ID()),
+	)
+	useSource := func(info *transport.TLSInfo, urls []url.URL) {
+		if info.CertFile != "" || info.KeyFile != "" {
+			return
+		}
+		for _, u := range urls {
+			if u.Scheme == "https" || u.Scheme == "unixs" {
+				info.X509Source = src
+				return
+			}
+		}
+	}
+	useSource(&cfg.PeerTLSInfo, cfg.LPUrls)
+	useSource(&cfg.ClientTLSInfo, cfg.LCUrls)
+	return src, nil
+}
+
 func stopServers(ctx context.Context, ss *servers) {
 	// first, close the http.Server
 	ss.http.Shutdown(ctx)
@@ -490,6 +755,9 @@ func configurePeerListeners(cfg *Config) (peers []*peerListener, err error) {
 	if err = cfg.PeerSelfCert(); err != nil {
 		cfg.logger.Fatal("failed to get peer self-signed certs", zap.Error(err))
 	}
+	if err = cfg.PeerTLSInfo.EnableReload(); err != nil {
+		return nil, err
+	}
 	if !cfg.PeerTLSInfo.Empty() {
 		cfg.logger.Info(
 			"starting with peer TLS",
@@ -517,8 +785,12 @@ func configurePeerListeners(cfg *Config) (peers []*peerListener, err error) {
 		}
 	}()
 
+	mode, err := cfg.unixSocketMode()
+	if err != nil {
+		return nil, err
+	}
 	for i, u := range cfg.LPUrls {
-		if u.Scheme == "http" {
+		if u.Scheme == "http" || u.Scheme == "unix" {
 			if !cfg.PeerTLSInfo.Empty() {
 				cfg.logger.Warn("scheme is HTTP while key and cert files are present; ignoring key and cert files", zap.String("peer-url", u.String()))
 			}
@@ -526,12 +798,22 @@ func configurePeerListeners(cfg *Config) (peers []*peerListener, err error) {
 				cfg.logger.Warn("scheme is HTTP while --peer-client-cert-auth is enabled; ignoring client cert auth for this URL", zap.String("peer-url", u.String()))
 			}
 		}
+		network, addr := "tcp", u.Host
+		if u.Scheme == "unix" || u.Scheme == "unixs" {
+			network, addr = "unix", u.Host+u.Path
+		}
 		peers[i] = &peerListener{close: func(context.Context) error { return nil }}
-		peers[i].Listener, err = transport.NewListenerWithOpts(u.Host, u.Scheme,
+		opts := []transport.ListenerOption{
 			transport.WithTLSInfo(&cfg.PeerTLSInfo),
 			transport.WithSocketOpts(&cfg.SocketOpts),
 			transport.WithTimeout(rafthttp.ConnReadTimeout, rafthttp.ConnWriteTimeout),
-		)
+			transport.WithUnixSocketMode(mode),
+		}
+		if l := cfg.takeListener(network, addr); l != nil {
+			cfg.logger.Info("using pre-opened peer listener", zap.String("peer-url", u.String()))
+			opts = append(opts, transport.WithListener(l))
+		}
+		peers[i].Listener, err = transport.NewListenerWithOpts(addr, u.Scheme, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -584,6 +866,7 @@ func (e *Etcd) servePeers() (err error) {
 	// start peer servers in a goroutine
 	for _, pl := range e.Peers {
 		go func(l *peerListener) {
+			defer e.recoverServe()
 			u := l.Addr().String()
 			e.cfg.logger.Info(
 				"serving peer traffic",
@@ -602,10 +885,17 @@ func configureClientListeners(cfg *Config) (sctxs map[string]*serveCtx, err erro
 	if err = cfg.ClientSelfCert(); err != nil {
 		cfg.logger.Fatal("failed to get client self-signed certs", zap.Error(err))
 	}
+	if err = cfg.ClientTLSInfo.EnableReload(); err != nil {
+		return nil, err
+	}
 	if cfg.EnablePprof {
 		cfg.logger.Info("pprof is enabled", zap.String("path", debugutil.HTTPPrefixPProf))
 	}
 
+	mode, err := cfg.unixSocketMode()
+	if err != nil {
+		return nil, err
+	}
 	sctxs = make(map[string]*serveCtx)
 	for _, u := range cfg.LCUrls {
 		sctx := newServeCtx(cfg.logger)
@@ -637,10 +927,16 @@ func configureClientListeners(cfg *Config) (sctxs map[string]*serveCtx, err erro
 			continue
 		}
 
-		if sctx.l, err = transport.NewListenerWithOpts(addr, u.Scheme,
+		opts := []transport.ListenerOption{
 			transport.WithSocketOpts(&cfg.SocketOpts),
 			transport.WithSkipTLSInfoCheck(true),
-		); err != nil {
+			transport.WithUnixSocketMode(mode),
+		}
+		if l := cfg.takeListener(network, addr); l != nil {
+			cfg.logger.Info("using pre-opened client listener", zap.String("client-url", u.String()))
+			opts = append(opts, transport.WithListener(l))
+		}
+		if sctx.l, err = transport.NewListenerWithOpts(addr, u.Scheme, opts...); err != nil {
 			return nil, err
 		}
 		// net.Listener will rewrite ipv4 0.0.0.0 to ipv6 [::], breaking
@@ -690,6 +986,41 @@ func configureClientListeners(cfg *Config) (sctxs map[string]*serveCtx, err erro
 	return sctxs, nil
 }
 
+// takeListener removes the listener listening on addr from cfg.Listeners and
+// returns it, or returns nil if there is none.
+func (cfg *Config) takeListener(network, addr string) net.Listener {
+	for i, l := range cfg.Listeners {
+		if !listensOn(l, network, addr) {
+			continue
+		}
+		ls := make([]net.Listener, 0, len(cfg.Listeners)-1)
+		ls = append(ls, cfg.Listeners[:i]...)
+		cfg.Listeners = append(ls, cfg.Listeners[i+1:]...)
+		return l
+	}
+	return nil
+}
+
+// listensOn returns true if l listens on addr. An unspecified IP address,
+// e.g. "0.0.0.0", matches any other unspecified address.
+func listensOn(l net.Listener, network, addr string) bool {
+	switch la := l.Addr().(type) {
+	case *net.UnixAddr:
+		return network == "unix" && la.Name == addr
+	case *net.TCPAddr:
+		if network != "tcp" {
+			return false
+		}
+		ta, err := net.ResolveTCPAddr("tcp", addr)
+		if err != nil || ta.Port != la.Port {
+			return false
+		}
+		unspecified := func(ip net.IP) bool { return len(ip) == 0 || ip.IsUnspecified() }
+		return ta.IP.Equal(la.IP) || (unspecified(ta.IP) && unspecified(la.IP))
+	}
+	return false
+}
+
 func (e *Etcd) serveClients() (err error) {
 	if !e.cfg.ClientTLSInfo.Empty() {
 		e.cfg.logger.Info(
@@ -703,7 +1034,7 @@ func (e *Etcd) serveClients() (err error) {
 	mux := http.NewServeMux()
 	etcdhttp.HandleDebug(mux)
 	etcdhttp.HandleVersion(mux, e.Server)
-	etcdhttp.HandleMetrics(mux)
+	e.handleMetrics(mux)
 	etcdhttp.HandleHealth(e.cfg.logger, mux, e.Server)
 
 	gopts := []grpc.ServerOption{}
@@ -724,12 +1055,23 @@ func (e *Etcd) serveClients() (err error) {
 	// start client servers in each goroutine
 	for _, sctx := range e.sctxs {
 		go func(s *serveCtx) {
+			defer e.recoverServe()
 			e.errHandler(s.serve(e.Server, &e.cfg.ClientTLSInfo, mux, e.errHandler, gopts...))
 		}(sctx)
 	}
 	return nil
 }
 
+// handleMetrics registers the metrics handler, which serves the exemplars
+// linking the latency histograms to the traces if tracing is enabled.
+func (e *Etcd) handleMetrics(mux *http.ServeMux) {
+	if e.cfg.ExperimentalEnableDistributedTracing {
+		etcdhttp.HandleMetricsWithExemplars(mux)
+		return
+	}
+	etcdhttp.HandleMetrics(mux)
+}
+
 func (e *Etcd) serveMetrics() (err error) {
 	if e.cfg.Metrics == "extensive" {
 		grpc_prometheus.EnableHandlingTimeHistogram()
@@ -737,7 +1079,7 @@ func (e *Etcd) serveMetrics() (err error) {
 
 	if len(e.cfg.ListenMetricsUrls) > 0 {
 		metricsMux := http.NewServeMux()
-		etcdhttp.HandleMetrics(metricsMux)
+		e.handleMetrics(metricsMux)
 		etcdhttp.HandleHealth(e.cfg.logger, metricsMux, e.Server)
 
 		for _, murl := range e.cfg.ListenMetricsUrls {
@@ -754,6 +1096,7 @@ func (e *Etcd) serveMetrics() (err error) {
 			}
 			e.metricsListeners = append(e.metricsListeners, ml)
 			go func(u url.URL, ln net.Listener) {
+				defer e.recoverServe()
 				e.cfg.logger.Info(
 					"serving metrics",
 					zap.String("address", u.String()),
@@ -777,6 +1120,15 @@ func (e *Etcd) errHandler(err error) {
 	}
 }
 
+// recoverServe reports a panic of a serving goroutine as an error on Err,
+// so that it can be handled like a listener error.
+func (e *Etcd) recoverServe() {
+	if r := recover(); r != nil {
+		e.GetLogger().Error("panic while serving", zap.Any("panic", r), zap.Stack("stack"))
+		e.errHandler(fmt.Errorf("panic while serving: %v", r))
+	}
+}
+
 // GetLogger returns the logger.
 func (e *Etcd) GetLogger() *zap.Logger {
 	e.cfg.loggerMu.RLock()
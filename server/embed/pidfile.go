@@ -0,0 +1,72 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embed
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+
+	"go.uber.org/zap"
+)
+
+// DataDirLockFile is the name of the file in the data directory that is
+// locked by a running etcd server, and contains its PID.
+const DataDirLockFile = "etcd.lock"
+
+// lockDataDir locks dir for the current process, so that a second server
+// started on the same data directory fails right away instead of on the
+// backend or WAL file locks.
+func lockDataDir(lg *zap.Logger, dir string) (*fileutil.LockedFile, error) {
+	if err := fileutil.TouchDirAll(lg, dir); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, DataDirLockFile)
+	l, err := fileutil.TryLockFile(path, os.O_WRONLY|os.O_CREATE, fileutil.PrivateFileMode)
+	if err == fileutil.ErrLocked {
+		if b, rerr := os.ReadFile(path); rerr == nil && len(b) > 0 {
+			return nil, fmt.Errorf("data-dir %q is in use by another etcd instance (pid %s)", dir, strings.TrimSpace(string(b)))
+		}
+		return nil, fmt.Errorf("data-dir %q is in use by another etcd instance", dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = l.Truncate(0); err == nil {
+		_, err = l.WriteString(strconv.Itoa(os.Getpid()) + "\n")
+	}
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// writePidFile atomically writes the PID of the current process to path.
+func writePidFile(path string) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strings"
 
 	"go.etcd.io/etcd/api/v3/version"
 	"go.etcd.io/etcd/client/pkg/v3/logutil"
@@ -62,8 +63,14 @@ type config struct {
 	ec           embed.Config
 	cf           configFlags
 	configFile   string
+	configDir    string
 	printVersion bool
 	ignored      []string
+
+	// validateConfig prints a validation report instead of starting the server
+	validateConfig bool
+	// autoRestart restarts the server in-process on recoverable failures
+	autoRestart bool
 }
 
 // configFlags has the set of flags used for command line parsing a Config
@@ -72,6 +79,11 @@ type configFlags struct {
 	clusterState  *flags.SelectiveStringValue
 	fallback      *flags.SelectiveStringValue
 	v2deprecation *flags.SelectiveStringsValue
+
+	// ldapBindDNTemplates and ldapRoleRules are separated by semicolons, the
+	// DNs having commas.
+	ldapBindDNTemplates string
+	ldapRoleRules       string
 }
 
 func newConfig() *config {
@@ -100,11 +112,14 @@ func newConfig() *config {
 		fmt.Fprintln(os.Stderr, usageline)
 	}
 
-	fs.StringVar(&cfg.configFile, "config-file", "", "Path to the server configuration file. Note that if a configuration file is provided, other command line flags and environment variables will be ignored.")
+	fs.StringVar(&cfg.configFile, "config-file", "", "Path to the server configuration file in YAML, or in TOML or HCL if its name ends in .toml or .hcl. Note that if a configuration file is provided, other command line flags and environment variables will be ignored.")
+	fs.StringVar(&cfg.configDir, "config-dir", "", "Path to a directory of server configuration files (*.yaml, *.yml), merged in lexical order with later files overriding earlier ones. Cannot be used with --config-file; other command line flags and environment variables will be ignored.")
 
 	// member
 	fs.StringVar(&cfg.ec.Dir, "data-dir", cfg.ec.Dir, "Path to the data directory.")
 	fs.StringVar(&cfg.ec.WalDir, "wal-dir", cfg.ec.WalDir, "Path to the dedicated wal directory.")
+	fs.StringVar(&cfg.ec.PidFile, "pid-file", cfg.ec.PidFile, "Path to the file the process PID is written to on start.")
+	fs.BoolVar(&cfg.autoRestart, "auto-restart-on-failure", false, "Restart the server in-process with an exponential backoff on listener errors or panics while serving, instead of exiting.")
 	fs.Var(
 		flags.NewUniqueURLsWithExceptions(embed.DefaultListenPeerURLs, ""),
 		"listen-peer-urls",
@@ -127,16 +142,20 @@ func newConfig() *config {
 	fs.UintVar(&cfg.ec.ElectionMs, "election-timeout", cfg.ec.ElectionMs, "Time (in milliseconds) for an election to timeout.")
 	fs.BoolVar(&cfg.ec.InitialElectionTickAdvance, "initial-election-tick-advance", cfg.ec.InitialElectionTickAdvance, "Whether to fast-forward initial election ticks on boot for faster election.")
 	fs.Int64Var(&cfg.ec.QuotaBackendBytes, "quota-backend-bytes", cfg.ec.QuotaBackendBytes, "Raise alarms when backend size exceeds the given quota. 0 means use the default quota.")
+	fs.StringVar(&cfg.ec.BackendEngine, "backend-engine", cfg.ec.BackendEngine, "Storage engine of the backend. 'bbolt' is the only engine built in, others can be registered by embedding applications.")
 	fs.StringVar(&cfg.ec.BackendFreelistType, "backend-bbolt-freelist-type", cfg.ec.BackendFreelistType, "BackendFreelistType specifies the type of freelist that boltdb backend uses(array and map are supported types)")
 	fs.DurationVar(&cfg.ec.BackendBatchInterval, "backend-batch-interval", cfg.ec.BackendBatchInterval, "BackendBatchInterval is the maximum time before commit the backend transaction.")
 	fs.IntVar(&cfg.ec.BackendBatchLimit, "backend-batch-limit", cfg.ec.BackendBatchLimit, "BackendBatchLimit is the maximum operations before commit the backend transaction.")
 	fs.UintVar(&cfg.ec.MaxTxnOps, "max-txn-ops", cfg.ec.MaxTxnOps, "Maximum number of operations permitted in a transaction.")
 	fs.UintVar(&cfg.ec.MaxRequestBytes, "max-request-bytes", cfg.ec.MaxRequestBytes, "Maximum client request size in bytes the server will accept.")
+	fs.IntVar(&cfg.ec.MaxLeasesPerUser, "max-leases-per-user", cfg.ec.MaxLeasesPerUser, "Maximum number of leases an authenticated user can hold (0 for no limit).")
 	fs.DurationVar(&cfg.ec.GRPCKeepAliveMinTime, "grpc-keepalive-min-time", cfg.ec.GRPCKeepAliveMinTime, "Minimum interval duration that a client should wait before pinging server.")
 	fs.DurationVar(&cfg.ec.GRPCKeepAliveInterval, "grpc-keepalive-interval", cfg.ec.GRPCKeepAliveInterval, "Frequency duration of server-to-client ping to check if a connection is alive (0 to disable).")
 	fs.DurationVar(&cfg.ec.GRPCKeepAliveTimeout, "grpc-keepalive-timeout", cfg.ec.GRPCKeepAliveTimeout, "Additional duration of wait before closing a non-responsive connection (0 to disable).")
+	fs.DurationVar(&cfg.ec.GracefulShutdownTimeout, "graceful-shutdown-timeout", cfg.ec.GracefulShutdownTimeout, "Maximum duration to wait for in-flight client requests and streams to complete on shutdown, after leadership is transferred. 0 means the request timeout derived from --election-timeout.")
 	fs.BoolVar(&cfg.ec.SocketOpts.ReusePort, "socket-reuse-port", cfg.ec.SocketOpts.ReusePort, "Enable to set socket option SO_REUSEPORT on listeners allowing rebinding of a port already in use.")
 	fs.BoolVar(&cfg.ec.SocketOpts.ReuseAddress, "socket-reuse-address", cfg.ec.SocketOpts.ReuseAddress, "Enable to set socket option SO_REUSEADDR on listeners allowing binding to an address in `TIME_WAIT` state.")
+	fs.StringVar(&cfg.ec.UnixSocketMode, "unix-socket-mode", cfg.ec.UnixSocketMode, "Octal file mode (e.g. 0660) of the socket files of unix:// and unixs:// listen URLs. Defaults to the umask.")
 
 	// raft connection timeouts
 	fs.DurationVar(&rafthttp.ConnReadTimeout, "raft-read-timeout", rafthttp.DefaultConnReadTimeout, "Read timeout set on each rafthttp connection")
@@ -194,8 +213,10 @@ func newConfig() *config {
 	fs.StringVar(&cfg.ec.ClientTLSInfo.ClientCertFile, "client-cert-file", "", "Path to an explicit peer client TLS cert file otherwise cert file will be used when client auth is required.")
 	fs.StringVar(&cfg.ec.ClientTLSInfo.ClientKeyFile, "client-key-file", "", "Path to an explicit peer client TLS key file otherwise key file will be used when client auth is required.")
 	fs.BoolVar(&cfg.ec.ClientTLSInfo.ClientCertAuth, "client-cert-auth", false, "Enable client cert authentication.")
+	fs.Var(flags.NewStringsValue(""), "client-cert-role-rules", "Comma-separated list of 'attribute=pattern:role' rules granting roles to the client certificates.")
 	fs.StringVar(&cfg.ec.ClientTLSInfo.CRLFile, "client-crl-file", "", "Path to the client certificate revocation list file.")
 	fs.StringVar(&cfg.ec.ClientTLSInfo.AllowedHostname, "client-cert-allowed-hostname", "", "Allowed TLS hostname for client cert authentication.")
+	fs.StringVar(&cfg.ec.ClientTLSInfo.AllowedURI, "client-cert-allowed-uri", "", "Allowed URI SAN pattern, such as a SPIFFE ID, for client cert authentication.")
 	fs.StringVar(&cfg.ec.ClientTLSInfo.TrustedCAFile, "trusted-ca-file", "", "Path to the client server TLS trusted CA cert file.")
 	fs.BoolVar(&cfg.ec.ClientAutoTLS, "auto-tls", false, "Client TLS using generated certificates")
 	fs.StringVar(&cfg.ec.PeerTLSInfo.CertFile, "peer-cert-file", "", "Path to the peer server TLS cert file.")
@@ -209,6 +230,8 @@ func newConfig() *config {
 	fs.StringVar(&cfg.ec.PeerTLSInfo.CRLFile, "peer-crl-file", "", "Path to the peer certificate revocation list file.")
 	fs.StringVar(&cfg.ec.PeerTLSInfo.AllowedCN, "peer-cert-allowed-cn", "", "Allowed CN for inter peer authentication.")
 	fs.StringVar(&cfg.ec.PeerTLSInfo.AllowedHostname, "peer-cert-allowed-hostname", "", "Allowed TLS hostname for inter peer authentication.")
+	fs.StringVar(&cfg.ec.PeerTLSInfo.AllowedURI, "peer-cert-allowed-uri", "", "Allowed URI SAN pattern, such as a SPIFFE ID, for inter peer authentication.")
+	fs.StringVar(&cfg.ec.SPIFFEWorkloadAPIAddr, "spiffe-workload-api-addr", "", "Address of the SPIFFE Workload API providing the client and peer TLS certificates in place of the cert and key files.")
 	fs.Var(flags.NewStringsValue(""), "cipher-suites", "Comma-separated list of supported TLS cipher suites between client/server and peers (empty will be auto-populated by Go).")
 	fs.BoolVar(&cfg.ec.PeerTLSInfo.SkipClientSANVerify, "experimental-peer-skip-client-san-verification", false, "Skip verification of SAN field in client certificate for peer connections.")
 
@@ -221,17 +244,25 @@ func newConfig() *config {
 
 	// logging
 	fs.StringVar(&cfg.ec.Logger, "logger", "zap", "Currently only supports 'zap' for structured logging.")
-	fs.Var(flags.NewUniqueStringsValue(embed.DefaultLogOutput), "log-outputs", "Specify 'stdout' or 'stderr' to skip journald logging even when running under systemd, or list of comma separated output targets.")
+	fs.Var(flags.NewUniqueStringsValue(embed.DefaultLogOutput), "log-outputs", "Specify 'stdout' or 'stderr' to skip journald logging even when running under systemd, or list of comma separated output targets, including 'systemd-journal' and 'syslog://host:port'.")
 	fs.StringVar(&cfg.ec.LogLevel, "log-level", logutil.DefaultLogLevel, "Configures log level. Only supports debug, info, warn, error, panic, or fatal. Default 'info'.")
 	fs.StringVar(&cfg.ec.LogFormat, "log-format", logutil.DefaultLogFormat, "Configures log format. Only supports json, console. Default is 'json'.")
 	fs.BoolVar(&cfg.ec.EnableLogRotation, "enable-log-rotation", false, "Enable log rotation of a single log-outputs file target.")
 	fs.StringVar(&cfg.ec.LogRotationConfigJSON, "log-rotation-config-json", embed.DefaultLogRotationConfig, "Configures log rotation if enabled with a JSON logger config. Default: MaxSize=100(MB), MaxAge=0(days,no limit), MaxBackups=0(no limit), LocalTime=false(UTC), Compress=false(gzip)")
+	fs.DurationVar(&cfg.ec.SlowRequestThreshold, "slow-request-threshold", 0, "Duration after which a request is logged with the time it spent in each phase of the raft and apply pipeline. 0 disables slow request logs.")
+
+	// audit logging
+	fs.StringVar(&cfg.ec.AuditLogOutput, "audit-log-output", cfg.ec.AuditLogOutput, "Specify 'stdout', 'stderr' or a file path to write the audit log of all the RPCs but the read-only ones to. Disabled if empty.")
+	fs.StringVar(&cfg.ec.AuditLogRotationConfigJSON, "audit-log-rotation-config-json", cfg.ec.AuditLogRotationConfigJSON, "Configures rotation of an audit log file with a JSON logger config.")
+	fs.BoolVar(&cfg.ec.AuditLogRedactValues, "audit-log-redact-values", cfg.ec.AuditLogRedactValues, "Omit the values of put requests from the audit log.")
 
 	// version
 	fs.BoolVar(&cfg.printVersion, "version", false, "Print the version and exit.")
+	fs.BoolVar(&cfg.validateConfig, "validate-config", false, "Validate the configuration, print a JSON report and exit without starting the server.")
 
 	fs.StringVar(&cfg.ec.AutoCompactionRetention, "auto-compaction-retention", "0", "Auto compaction retention for mvcc key value store. 0 means disable auto compaction.")
 	fs.StringVar(&cfg.ec.AutoCompactionMode, "auto-compaction-mode", "periodic", "interpret 'auto-compaction-retention' one of: periodic|revision. 'periodic' for duration based retention, defaulting to hours if no time unit is provided (e.g. '5m'). 'revision' for revision number based retention.")
+	fs.DurationVar(&cfg.ec.TimerJitter, "timer-jitter", 0, "Maximum delay applied to the start of periodic background timers (auto compaction) to desynchronize members. The delay is derived from the member ID. 0 means disable jitter.")
 
 	// pprof profiler via HTTP
 	fs.BoolVar(&cfg.ec.EnablePprof, "enable-pprof", false, "Enable runtime profiling data via HTTP server. Address is at client URL + \"/debug/pprof/\"")
@@ -250,31 +281,98 @@ func newConfig() *config {
 	fs.StringVar(&cfg.ec.AuthToken, "auth-token", cfg.ec.AuthToken, "Specify auth token specific options.")
 	fs.UintVar(&cfg.ec.BcryptCost, "bcrypt-cost", cfg.ec.BcryptCost, "Specify bcrypt algorithm cost factor for auth password hashing.")
 	fs.UintVar(&cfg.ec.AuthTokenTTL, "auth-token-ttl", cfg.ec.AuthTokenTTL, "The lifetime in seconds of the auth token.")
+	fs.StringVar(&cfg.ec.AuthOIDCIssuerURL, "auth-oidc-issuer-url", cfg.ec.AuthOIDCIssuerURL, "URL of the OpenID Connect issuer whose ID tokens authenticate the users, alongside the tokens of --auth-token.")
+	fs.StringVar(&cfg.ec.AuthOIDCClientID, "auth-oidc-client-id", cfg.ec.AuthOIDCClientID, "Audience the OIDC ID tokens must be issued for.")
+	fs.StringVar(&cfg.ec.AuthOIDCUsernameClaim, "auth-oidc-username-claim", cfg.ec.AuthOIDCUsernameClaim, "Claim of the user name of the OIDC ID tokens.")
+	fs.StringVar(&cfg.ec.AuthOIDCUsernamePrefix, "auth-oidc-username-prefix", cfg.ec.AuthOIDCUsernamePrefix, "Prefix of the user names of the OIDC ID tokens, must not be empty.")
+	fs.Var(flags.NewStringsValue(""), "auth-oidc-role-rules", "Comma-separated list of 'claim=value:role' rules granting roles to the users of the OIDC ID tokens.")
+	fs.StringVar(&cfg.ec.AuthLDAPURL, "auth-ldap-url", cfg.ec.AuthLDAPURL, "URL of the LDAP directory the users absent from etcd authenticate against, ldap:// or ldaps://.")
+	fs.StringVar(&cfg.cf.ldapBindDNTemplates, "auth-ldap-bind-dn-templates", "", "Semicolon-separated list of the DNs the users bind to the LDAP directory as, with '%s' replaced by the user name.")
+	fs.StringVar(&cfg.ec.AuthLDAPGroupAttribute, "auth-ldap-group-attribute", cfg.ec.AuthLDAPGroupAttribute, "Attribute of the LDAP entries of the users listing the DNs of their groups.")
+	fs.StringVar(&cfg.cf.ldapRoleRules, "auth-ldap-role-rules", "", "Semicolon-separated list of 'group DN:role' rules granting roles to the members of the LDAP groups.")
+	fs.StringVar(&cfg.ec.AuthLDAPCAFile, "auth-ldap-ca-file", cfg.ec.AuthLDAPCAFile, "Path to the CA certificates verifying the certificate of an ldaps:// directory.")
+	fs.StringVar(&cfg.ec.AuthorizationWebhookURL, "authorization-webhook-url", cfg.ec.AuthorizationWebhookURL, "URL the client requests are posted to for authorization. Disabled if empty.")
+	fs.DurationVar(&cfg.ec.AuthorizationWebhookTimeout, "authorization-webhook-timeout", cfg.ec.AuthorizationWebhookTimeout, "Timeout of the requests to the authorization webhook, after which the client requests are denied.")
+	fs.IntVar(&cfg.ec.AuthLockoutThreshold, "auth-lockout-threshold", cfg.ec.AuthLockoutThreshold, "Number of failed authentications in a row locking out a user or a client address on a member. 0 disables the lockouts.")
+	fs.DurationVar(&cfg.ec.AuthLockoutDuration, "auth-lockout-duration", cfg.ec.AuthLockoutDuration, "Duration of the first lockout, doubled by each following one.")
+	fs.DurationVar(&cfg.ec.AuthLockoutMaxDuration, "auth-lockout-max-duration", cfg.ec.AuthLockoutMaxDuration, "Maximum duration of the lockouts, after which without failures they are forgotten.")
+	fs.StringVar(&cfg.ec.AuthPasswordHash, "auth-password-hash", cfg.ec.AuthPasswordHash, "Algorithm hashing the passwords, 'bcrypt' or 'argon2id'. The passwords hashed otherwise are rehashed on login.")
+	fs.UintVar(&cfg.ec.AuthArgon2Time, "auth-argon2-time", cfg.ec.AuthArgon2Time, "Number of passes over the memory of argon2id.")
+	fs.UintVar(&cfg.ec.AuthArgon2Memory, "auth-argon2-memory", cfg.ec.AuthArgon2Memory, "Memory of argon2id, in KiB.")
+	fs.UintVar(&cfg.ec.AuthArgon2Threads, "auth-argon2-threads", cfg.ec.AuthArgon2Threads, "Number of threads of argon2id.")
+	fs.IntVar(&cfg.ec.AuthPasswordMinLength, "auth-password-min-length", cfg.ec.AuthPasswordMinLength, "Minimum number of characters of the passwords set.")
+	fs.IntVar(&cfg.ec.AuthPasswordMinClasses, "auth-password-min-classes", cfg.ec.AuthPasswordMinClasses, "Minimum number of classes of characters, among lower case letters, upper case letters, digits and others, of the passwords set.")
+	fs.DurationVar(&cfg.ec.AuthPasswordMaxAge, "auth-password-max-age", cfg.ec.AuthPasswordMaxAge, "Age after which the passwords of the users other than root expire. 0 disables the expiry.")
 
 	// gateway
 	fs.BoolVar(&cfg.ec.EnableGRPCGateway, "enable-grpc-gateway", cfg.ec.EnableGRPCGateway, "Enable GRPC gateway.")
+	fs.BoolVar(&cfg.ec.EnableGRPCReflection, "enable-grpc-reflection", cfg.ec.EnableGRPCReflection, "Enable gRPC server reflection on the client listeners, for tools like grpcurl to introspect the API.")
 
 	// experimental
 	fs.BoolVar(&cfg.ec.ExperimentalInitialCorruptCheck, "experimental-initial-corrupt-check", cfg.ec.ExperimentalInitialCorruptCheck, "Enable to check data corruption before serving any client/peer traffic.")
 	fs.DurationVar(&cfg.ec.ExperimentalCorruptCheckTime, "experimental-corrupt-check-time", cfg.ec.ExperimentalCorruptCheckTime, "Duration of time between cluster corruption check passes.")
+	fs.Int64Var(&cfg.ec.ExperimentalElectionPriority, "experimental-election-priority", 0, "Priority of the member to be the leader; the leader hands its leadership over to a member of a higher priority.")
+	fs.StringVar(&cfg.ec.ExperimentalMemberLabels, "experimental-member-labels", "", "Comma separated key=value labels of the member, as its zone (e.g. 'zone=us-east-1a,disk=ssd').")
+	fs.StringVar(&cfg.ec.ExperimentalLeaderZones, "experimental-leader-zones", "", "Comma separated zones the leader is preferably in, from the most preferred (e.g. 'us-east-1a,us-east-1b').")
+	fs.BoolVar(&cfg.ec.ExperimentalWitness, "experimental-witness", false, "Run the member as a witness voting in the elections without keeping KV data nor serving clients.")
 
 	fs.BoolVar(&cfg.ec.ExperimentalEnableLeaseCheckpoint, "experimental-enable-lease-checkpoint", false, "Enable leader to send regular checkpoints to other members to prevent reset of remaining TTL on leader change.")
 	// TODO: delete in v3.7
 	fs.BoolVar(&cfg.ec.ExperimentalEnableLeaseCheckpointPersist, "experimental-enable-lease-checkpoint-persist", false, "Enable persisting remainingTTL to prevent indefinite auto-renewal of long lived leases. Always enabled in v3.6. Should be used to ensure smooth upgrade from v3.5 clusters with this feature enabled. Requires experimental-enable-lease-checkpoint to be enabled.")
 	fs.IntVar(&cfg.ec.ExperimentalCompactionBatchLimit, "experimental-compaction-batch-limit", cfg.ec.ExperimentalCompactionBatchLimit, "Sets the maximum revisions deleted in each compaction batch.")
 	fs.DurationVar(&cfg.ec.ExperimentalCompactionSleepInterval, "experimental-compaction-sleep-interval", cfg.ec.ExperimentalCompactionSleepInterval, "Sets the sleep interval between each compaction batch.")
+	fs.IntVar(&cfg.ec.ExperimentalDefragBatchLimit, "experimental-defrag-batch-limit", cfg.ec.ExperimentalDefragBatchLimit, "Number of keys copied at a time by the incremental defragmentation. Defragmentation stops the world if not positive.")
+	fs.DurationVar(&cfg.ec.ExperimentalDefragBatchInterval, "experimental-defrag-batch-interval", cfg.ec.ExperimentalDefragBatchInterval, "Pause between the batches of the incremental defragmentation.")
+	fs.StringVar(&cfg.ec.ExperimentalValueCompression, "experimental-value-compression", cfg.ec.ExperimentalValueCompression, "Compression of the stored values: 'none', 'zstd' or 'lz4'.")
+	fs.IntVar(&cfg.ec.ExperimentalValueCompressionThreshold, "experimental-value-compression-threshold", cfg.ec.ExperimentalValueCompressionThreshold, "Size in bytes from which a stored value is compressed.")
+	fs.StringVar(&cfg.ec.ExperimentalWALCompression, "experimental-wal-compression", cfg.ec.ExperimentalWALCompression, "Compression of the WAL entries: 'none' or 'zstd'.")
+	fs.IntVar(&cfg.ec.ExperimentalMaxInflightMsgs, "experimental-max-inflight-msgs", cfg.ec.ExperimentalMaxInflightMsgs, "Max number of append messages sent to a follower and not yet acknowledged.")
+	fs.Uint64Var(&cfg.ec.ExperimentalMaxInflightBytes, "experimental-max-inflight-bytes", 0, "Max total byte size of the entries sent to a follower and not yet acknowledged (0 for no limit).")
+	fs.BoolVar(&cfg.ec.ExperimentalAdaptiveInflightMsgs, "experimental-adaptive-inflight-msgs", false, "Halve the max number of inflight messages of a follower failing to receive them, growing it back as it acknowledges them.")
+	fs.StringVar(&cfg.ec.ExperimentalPeerCompression, "experimental-peer-compression", cfg.ec.ExperimentalPeerCompression, "Compression of the raft streams and snapshots sent to the peers: 'none' or 'zstd', followed by the compressions by peer as <member-id>=<compression>.")
+	fs.BoolVar(&cfg.ec.ExperimentalWALIOURing, "experimental-wal-io-uring", false, "Write the WAL through io_uring on Linux, falling back to the regular writes where it is not available.")
+	fs.Int64Var(&cfg.ec.ExperimentalWALSegmentSize, "experimental-wal-segment-size", cfg.ec.ExperimentalWALSegmentSize, "Size in bytes from which a WAL segment is cut.")
+	fs.Int64Var(&cfg.ec.ExperimentalWALPreallocSize, "experimental-wal-prealloc-size", cfg.ec.ExperimentalWALPreallocSize, "Size in bytes preallocated for a WAL segment, the segment size if 0. The segments are not preallocated if negative.")
+	fs.IntVar(&cfg.ec.ExperimentalWALTmpSegments, "experimental-wal-tmp-segments", cfg.ec.ExperimentalWALTmpSegments, "Number of WAL segments created ahead as .tmp files.")
+	fs.StringVar(&cfg.ec.ExperimentalWALArchive, "experimental-wal-archive", "", "Sink the completed WAL segments are archived to: 'file:///path/to/dir', 's3://bucket/prefix?endpoint=<url>' or 'exec:/path/to/command'.")
+	fs.DurationVar(&cfg.ec.ExperimentalWALArchiveInterval, "experimental-wal-archive-interval", cfg.ec.ExperimentalWALArchiveInterval, "Interval between the archivings of the completed WAL segments.")
+	fs.StringVar(&cfg.ec.ExperimentalEncryptionKeyFile, "experimental-encryption-key-file", "", "Path to the file of the keys encrypting the WAL entries and the snapshots at rest. The backend database is not encrypted.")
+	fs.IntVar(&cfg.ec.ExperimentalRangeTombstoneThreshold, "experimental-range-tombstone-threshold", cfg.ec.ExperimentalRangeTombstoneThreshold, "Number of keys from which a range deletion records a range tombstone applied by the compaction. Disabled if 0.")
+	fs.IntVar(&cfg.ec.ExperimentalReadCacheSize, "experimental-read-cache-size", cfg.ec.ExperimentalReadCacheSize, "Number of key-values decoded from the backend cached for the reads. Disabled if 0.")
+	fs.StringVar(&cfg.ec.ExperimentalWatchStuckPolicy, "experimental-watch-stuck-policy", cfg.ec.ExperimentalWatchStuckPolicy, "Policy applied to the stuck slow watchers: 'cancel', 'pause' or 'compress'.")
+	fs.Int64Var(&cfg.ec.ExperimentalWatchStuckMaxBytes, "experimental-watch-stuck-max-bytes", cfg.ec.ExperimentalWatchStuckMaxBytes, "Size in bytes of the backlog a slow watcher is stuck from. Disabled if 0.")
+	fs.DurationVar(&cfg.ec.ExperimentalWatchStuckMaxAge, "experimental-watch-stuck-max-age", cfg.ec.ExperimentalWatchStuckMaxAge, "Time a slow watcher is blocked for from which it is stuck. Disabled if 0.")
+	fs.Int64Var(&cfg.ec.ExperimentalWatchHistoryRetention, "experimental-watch-history-retention", cfg.ec.ExperimentalWatchHistoryRetention, "Number of revisions up to the compaction revision whose events are kept for the watchers. Disabled if 0.")
+	fs.DurationVar(&cfg.ec.ExperimentalBackendScrubInterval, "experimental-backend-scrub-interval", cfg.ec.ExperimentalBackendScrubInterval, "Pause between the background passes verifying the pages and records of the backend. Disabled if 0.")
+	fs.IntVar(&cfg.ec.ExperimentalBackendScrubRate, "experimental-backend-scrub-rate", cfg.ec.ExperimentalBackendScrubRate, "Number of backend records verified per second by the background scrubbing.")
+	fs.StringVar(&cfg.ec.ExperimentalAutoCompactionPrefixRetention, "experimental-auto-compaction-prefix-retention", "", "Periodic auto compaction retention of the keys under prefixes, as comma separated prefix=duration pairs (e.g. '/events/=1h,/config/=168h').")
+	fs.UintVar(&cfg.ec.ExperimentalMaxChunkedValueBytes, "experimental-max-chunked-value-bytes", cfg.ec.ExperimentalMaxChunkedValueBytes, "Maximum size in bytes of a put value larger than --max-request-bytes, proposed in chunks. Disabled if 0.")
 	fs.DurationVar(&cfg.ec.ExperimentalWatchProgressNotifyInterval, "experimental-watch-progress-notify-interval", cfg.ec.ExperimentalWatchProgressNotifyInterval, "Duration of periodic watch progress notifications.")
 	fs.DurationVar(&cfg.ec.ExperimentalDowngradeCheckTime, "experimental-downgrade-check-time", cfg.ec.ExperimentalDowngradeCheckTime, "Duration of time between two downgrade status check.")
+	fs.DurationVar(&cfg.ec.ExperimentalBoundedStalenessWindow, "experimental-bounded-staleness-window", cfg.ec.ExperimentalBoundedStalenessWindow, "How recently a follower or learner must have heard from the leader to serve the reads with a bounded staleness against the commit index it knows, without a read index request. Disabled if 0.")
 	fs.DurationVar(&cfg.ec.ExperimentalWarningApplyDuration, "experimental-warning-apply-duration", cfg.ec.ExperimentalWarningApplyDuration, "Time duration after which a warning is generated if request takes more time.")
 	fs.DurationVar(&cfg.ec.ExperimentalWarningUnaryRequestDuration, "experimental-warning-unary-request-duration", cfg.ec.ExperimentalWarningUnaryRequestDuration, "Time duration after which a warning is generated if a unary request takes more time.")
 	fs.BoolVar(&cfg.ec.ExperimentalMemoryMlock, "experimental-memory-mlock", cfg.ec.ExperimentalMemoryMlock, "Enable to enforce etcd pages (in particular bbolt) to stay in RAM.")
 	fs.BoolVar(&cfg.ec.ExperimentalTxnModeWriteWithSharedBuffer, "experimental-txn-mode-write-with-shared-buffer", true, "Enable the write transaction to use a shared buffer in its readonly check operations.")
 	fs.UintVar(&cfg.ec.ExperimentalBootstrapDefragThresholdMegabytes, "experimental-bootstrap-defrag-threshold-megabytes", 0, "Enable the defrag during etcd server bootstrap on condition that it will free at least the provided threshold of disk space. Needs to be set to non-zero value to take effect.")
 	fs.IntVar(&cfg.ec.ExperimentalMaxLearners, "experimental-max-learners", membership.DefaultMaxLearners, "Sets the maximum number of learners that can be available in the cluster membership.")
+	fs.Float64Var(&cfg.ec.ExperimentalHotKeySampleRate, "experimental-hot-key-sample-rate", 0, "Fraction of the client key accesses sampled to find the most accessed key prefixes, between 0 and 1. 0 disables the sampling.")
+	fs.DurationVar(&cfg.ec.ExperimentalHotKeyWindow, "experimental-hot-key-window", cfg.ec.ExperimentalHotKeyWindow, "Duration of the sliding window the sampled key accesses are counted over.")
+	fs.DurationVar(&cfg.ec.ExperimentalProfileApplyThreshold, "experimental-profile-apply-threshold", 0, "Apply duration of a request after which goroutine, heap and CPU profiles are captured to the member directory. 0 disables the trigger.")
+	fs.DurationVar(&cfg.ec.ExperimentalProfileFsyncThreshold, "experimental-profile-fsync-threshold", 0, "WAL fsync duration after which goroutine, heap and CPU profiles are captured to the member directory. 0 disables the trigger.")
+	fs.DurationVar(&cfg.ec.ExperimentalProfileMinInterval, "experimental-profile-min-interval", cfg.ec.ExperimentalProfileMinInterval, "Minimum time between two profile captures.")
+	fs.DurationVar(&cfg.ec.ExperimentalProfileCPUDuration, "experimental-profile-cpu-duration", cfg.ec.ExperimentalProfileCPUDuration, "Duration of the captured CPU profiles.")
+	fs.StringVar(&cfg.ec.ExperimentalProfileURL, "experimental-profile-url", "", "HTTP endpoint the captured profiles are posted to instead of being written to the member directory.")
+	fs.DurationVar(&cfg.ec.ExperimentalLeaderDegradationFsyncThreshold, "experimental-leader-degradation-fsync-threshold", 0, "Mean WAL fsync duration above which the local member is degraded. 0 disables the signal.")
+	fs.Float64Var(&cfg.ec.ExperimentalLeaderDegradationCPUStealThreshold, "experimental-leader-degradation-cpu-steal-threshold", 0, "Percentage of the CPU time stolen by the hypervisor above which the local member is degraded. 0 disables the signal.")
+	fs.Float64Var(&cfg.ec.ExperimentalLeaderDegradationMemoryPressureThreshold, "experimental-leader-degradation-memory-pressure-threshold", 0, "Percentage of the time some tasks stalled on memory above which the local member is degraded. 0 disables the signal.")
+	fs.DurationVar(&cfg.ec.ExperimentalLeaderDegradationDuration, "experimental-leader-degradation-duration", cfg.ec.ExperimentalLeaderDegradationDuration, "Duration a signal must stay above its threshold for the local member to be degraded, then below it to be recovered.")
 	fs.DurationVar(&cfg.ec.ExperimentalWaitClusterReadyTimeout, "experimental-wait-cluster-ready-timeout", cfg.ec.ExperimentalWaitClusterReadyTimeout, "Maximum duration to wait for the cluster to be ready.")
 
 	// unsafe
 	fs.BoolVar(&cfg.ec.UnsafeNoFsync, "unsafe-no-fsync", false, "Disables fsync, unsafe, will cause data loss.")
+	fs.StringVar(&cfg.ec.UnsafeWALDurability, "unsafe-wal-durability", cfg.ec.UnsafeWALDurability, "Durability of the raft log writes: 'fsync', 'batched' or 'none'. Other than 'fsync' is unsafe, may cause data loss.")
+	fs.DurationVar(&cfg.ec.UnsafeWALSyncInterval, "unsafe-wal-sync-interval", cfg.ec.UnsafeWALSyncInterval, "Interval the WAL is fsynced at with --unsafe-wal-durability=batched.")
 	fs.BoolVar(&cfg.ec.ForceNewCluster, "force-new-cluster", false, "Force to create a new one member cluster.")
 
 	// ignored
@@ -314,6 +412,13 @@ func (cfg *config) parse(arguments []string) error {
 	if cfg.configFile == "" {
 		cfg.configFile = os.Getenv(flags.FlagToEnv("ETCD", "config-file"))
 	}
+	if cfg.configDir == "" {
+		cfg.configDir = os.Getenv(flags.FlagToEnv("ETCD", "config-dir"))
+	}
+
+	if cfg.configFile != "" && cfg.configDir != "" {
+		return fmt.Errorf("--config-file and --config-dir cannot be set at the same time")
+	}
 
 	if cfg.configFile != "" {
 		err = cfg.configFromFile(cfg.configFile)
@@ -323,6 +428,14 @@ func (cfg *config) parse(arguments []string) error {
 				zap.String("path", cfg.configFile),
 			)
 		}
+	} else if cfg.configDir != "" {
+		err = cfg.configFromDir(cfg.configDir)
+		if lg := cfg.ec.GetLogger(); lg != nil {
+			lg.Info(
+				"loaded server configuration directory, other configuration command line flags and environment variables will be ignored if provided",
+				zap.String("path", cfg.configDir),
+			)
+		}
 	} else {
 		err = cfg.configFromCmdLine()
 	}
@@ -379,6 +492,10 @@ func (cfg *config) configFromCmdLine() error {
 	cfg.ec.HostWhitelist = flags.UniqueStringsMapFromFlag(cfg.cf.flagSet, "host-whitelist")
 
 	cfg.ec.CipherSuites = flags.StringsFromFlag(cfg.cf.flagSet, "cipher-suites")
+	cfg.ec.AuthOIDCRoleRules = flags.StringsFromFlag(cfg.cf.flagSet, "auth-oidc-role-rules")
+	cfg.ec.AuthLDAPBindDNTemplates = splitSemicolons(cfg.cf.ldapBindDNTemplates)
+	cfg.ec.AuthLDAPRoleRules = splitSemicolons(cfg.cf.ldapRoleRules)
+	cfg.ec.ClientCertRoleRules = flags.StringsFromFlag(cfg.cf.flagSet, "client-cert-role-rules")
 
 	cfg.ec.LogOutputs = flags.UniqueStringsFromFlag(cfg.cf.flagSet, "log-outputs")
 
@@ -410,9 +527,31 @@ func (cfg *config) configFromFile(path string) error {
 	return nil
 }
 
+func (cfg *config) configFromDir(dir string) error {
+	eCfg, err := embed.ConfigFromDir(dir)
+	if err != nil {
+		return err
+	}
+	cfg.ec = *eCfg
+
+	return nil
+}
+
 func (cfg *config) validate() error {
 	if cfg.cf.fallback.String() == fallbackFlagProxy {
 		return fmt.Errorf("v2 proxy is deprecated, and --discovery-fallback can't be configured as %q", fallbackFlagProxy)
 	}
 	return cfg.ec.Validate()
 }
+
+// splitSemicolons returns the non-empty elements of the semicolon-separated
+// list s.
+func splitSemicolons(s string) []string {
+	var ss []string
+	for _, e := range strings.Split(s, ";") {
+		if e = strings.TrimSpace(e); e != "" {
+			ss = append(ss, e)
+		}
+	}
+	return ss
+}
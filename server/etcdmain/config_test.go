@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -87,6 +88,55 @@ func TestConfigFileMemberFields(t *testing.T) {
 	validateMemberFlags(t, cfg)
 }
 
+func TestConfigDirMemberFields(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"00-base.yaml": `
+data-dir: testdir
+name: basename
+max-wals: 10
+max-snapshots: 5
+listen-peer-urls: http://localhost:8000,https://localhost:8001
+listen-client-urls: http://localhost:7000,https://localhost:7001
+advertise-client-urls: http://localhost:7000,https://localhost:7001
+`,
+		"10-env.yml": `
+name: testname
+max-snapshots: 10
+`,
+		"20-secret.yaml": `
+snapshot-count: 10
+`,
+		"README": `name: ignored`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := newConfig()
+	if err := cfg.parse([]string{fmt.Sprintf("--config-dir=%s", dir)}); err != nil {
+		t.Fatal(err)
+	}
+
+	validateMemberFlags(t, cfg)
+}
+
+func TestConfigFileAndDirConflict(t *testing.T) {
+	tmpfile := mustCreateCfgFile(t, []byte("name: testname"))
+	defer os.Remove(tmpfile.Name())
+
+	args := []string{
+		fmt.Sprintf("--config-file=%s", tmpfile.Name()),
+		fmt.Sprintf("--config-dir=%s", t.TempDir()),
+	}
+	cfg := newConfig()
+	if err := cfg.parse(args); err == nil {
+		t.Fatal("expected error when both --config-file and --config-dir are set")
+	}
+}
+
 func TestConfigParsingClusteringFlags(t *testing.T) {
 	args := []string{
 		"-initial-cluster=0=http://localhost:8000",
@@ -15,11 +15,13 @@
 package etcdmain
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
 	"strings"
 
+	"go.etcd.io/etcd/api/v3/version"
 	"go.etcd.io/etcd/client/pkg/v3/fileutil"
 	"go.etcd.io/etcd/client/pkg/v3/logutil"
 	"go.etcd.io/etcd/client/pkg/v3/types"
@@ -27,7 +29,9 @@ import (
 	"go.etcd.io/etcd/server/v3/embed"
 	"go.etcd.io/etcd/server/v3/etcdserver"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v2discovery"
+	"go.etcd.io/etcd/server/v3/storage/schema"
 
+	"github.com/coreos/go-systemd/v22/activation"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
@@ -47,6 +51,9 @@ func startEtcdOrProxyV2(args []string) {
 	defaultInitialCluster := cfg.ec.InitialCluster
 
 	err := cfg.parse(args[1:])
+	if cfg.validateConfig {
+		validateConfigAndExit(&cfg.ec, err)
+	}
 	lg := cfg.ec.GetLogger()
 	// If we failed to parse the whole configuration, print the error using
 	// preferably the resolved logger from the config,
@@ -57,7 +64,7 @@ func startEtcdOrProxyV2(args []string) {
 		lg, zapError = logutil.CreateDefaultZapLogger(zap.InfoLevel)
 		if zapError != nil {
 			fmt.Printf("error creating zap logger %v", zapError)
-			os.Exit(1)
+			exitWithError()
 		}
 	}
 	lg.Info("Running: ", zap.Strings("args", args))
@@ -67,7 +74,7 @@ func startEtcdOrProxyV2(args []string) {
 		case embed.ErrUnsetAdvertiseClientURLsFlag:
 			lg.Warn("advertise client URLs are not set", zap.Error(err))
 		}
-		os.Exit(1)
+		exitWithError()
 	}
 
 	cfg.ec.SetupGlobalLoggers()
@@ -98,8 +105,7 @@ func startEtcdOrProxyV2(args []string) {
 		)
 	}
 
-	var stopped <-chan struct{}
-	var errc <-chan error
+	var s *server
 
 	which := identifyDataDirOrDie(cfg.ec.GetLogger(), cfg.ec.Dir)
 	if which != dirEmpty {
@@ -110,7 +116,7 @@ func startEtcdOrProxyV2(args []string) {
 		)
 		switch which {
 		case dirMember:
-			stopped, errc, err = startEtcd(&cfg.ec)
+			s, err = startEtcd(&cfg.ec)
 		case dirProxy:
 			lg.Panic("v2 http proxy has already been deprecated in 3.6", zap.String("dir-type", string(which)))
 		default:
@@ -120,7 +126,7 @@ func startEtcdOrProxyV2(args []string) {
 			)
 		}
 	} else {
-		stopped, errc, err = startEtcd(&cfg.ec)
+		s, err = startEtcd(&cfg.ec)
 		if err != nil {
 			lg.Warn("failed to start etcd", zap.Error(err))
 		}
@@ -160,7 +166,7 @@ func startEtcdOrProxyV2(args []string) {
 				)
 				lg.Warn("do not reuse discovery token; generate a new one to bootstrap a cluster")
 			}
-			os.Exit(1)
+			exitWithError()
 		}
 
 		if strings.Contains(err.Error(), "include") && strings.Contains(err.Error(), "--initial-cluster") {
@@ -174,9 +180,21 @@ func startEtcdOrProxyV2(args []string) {
 			if cfg.ec.InitialCluster == cfg.ec.InitialClusterFromName(cfg.ec.Name) && len(cfg.ec.Durl) == 0 && len(cfg.ec.DiscoveryCfg.Endpoints) == 0 {
 				lg.Warn("V2 discovery settings (i.e., --discovery) or v3 discovery settings (i.e., --discovery-token, --discovery-endpoints) are not set")
 			}
-			os.Exit(1)
+			exitWithError()
+		}
+
+		var verr *schema.VersionMismatchError
+		if errors.As(err, &verr) {
+			lg.Warn(
+				"failed to start; data dir storage version is not supported by this etcd binary",
+				zap.String("data-dir", cfg.ec.Dir),
+				zap.String("storage-version", verr.StorageVersion.String()),
+				zap.String("binary-version", version.Version),
+			)
+			lg.Warn(verr.Resolution())
+			exitWithError()
 		}
-		lg.Fatal("discovery failed", zap.Error(err))
+		fatal(lg, "discovery failed", zap.Error(err))
 	}
 
 	osutil.HandleInterrupts(lg)
@@ -187,29 +205,55 @@ func startEtcdOrProxyV2(args []string) {
 	// joined with the cluster and ready to serve incoming
 	// connections.
 	notifySystemd(lg)
+	notifyWindowsService(lg)
 
-	select {
-	case lerr := <-errc:
-		// fatal out on listener errors
-		lg.Fatal("listener failed", zap.Error(lerr))
-	case <-stopped:
+	if serr := superviseServer(lg, s, cfg.autoRestart); serr != nil {
+		// fatal out on listener errors or a failed restart
+		fatal(lg, "server failed", zap.Error(serr))
 	}
 
+	exitWindowsService(0)
 	osutil.Exit(0)
 }
 
-// startEtcd runs StartEtcd in addition to hooks needed for standalone etcd.
-func startEtcd(cfg *embed.Config) (<-chan struct{}, <-chan error, error) {
-	e, err := embed.StartEtcd(cfg)
+// startEtcd runs StartEtcd in addition to hooks needed for standalone etcd,
+// and returns a handle to stop or restart the server.
+func startEtcd(cfg *embed.Config) (*server, error) {
+	// sockets passed by systemd socket activation (LISTEN_FDS)
+	ls, err := activation.Listeners()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	for _, l := range ls {
+		if l != nil {
+			cfg.Listeners = append(cfg.Listeners, l)
+		}
 	}
-	osutil.RegisterInterruptHandler(e.Close)
-	select {
-	case <-e.Server.ReadyNotify(): // wait for e.Server to join the cluster
-	case <-e.Server.StopNotify(): // publish aborted from 'ErrStopped'
+	if len(cfg.Listeners) > 0 {
+		cfg.GetLogger().Info("received socket-activated listeners", zap.Int("count", len(cfg.Listeners)))
 	}
-	return e.Server.StopNotify(), e.Err(), nil
+
+	s, err := newServer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	osutil.RegisterInterruptHandler(s.Stop)
+	registerServiceStop(s.Stop)
+	osutil.HandleReload(cfg.GetLogger(), s.ReloadConfig)
+	return s, nil
+}
+
+// exitWithError exits with code 1, reporting the Windows service as failed
+// first, since os.Exit bypasses exitWindowsService.
+func exitWithError() {
+	exitWindowsService(1)
+	os.Exit(1)
+}
+
+// fatal is like lg.Fatal, but reports the Windows service as failed first.
+func fatal(lg *zap.Logger, msg string, fields ...zap.Field) {
+	exitWindowsService(1)
+	lg.Fatal(msg, fields...)
 }
 
 // identifyDataDirOrDie returns the type of the data dir.
@@ -220,7 +264,7 @@ func identifyDataDirOrDie(lg *zap.Logger, dir string) dirType {
 		if os.IsNotExist(err) {
 			return dirEmpty
 		}
-		lg.Fatal("failed to list data directory", zap.String("dir", dir), zap.Error(err))
+		fatal(lg, "failed to list data directory", zap.String("dir", dir), zap.Error(err))
 	}
 
 	var m, p bool
@@ -230,6 +274,7 @@ func identifyDataDirOrDie(lg *zap.Logger, dir string) dirType {
 			m = true
 		case dirProxy:
 			p = true
+		case embed.DataDirLockFile:
 		default:
 			lg.Warn(
 				"found invalid file under data directory",
@@ -240,7 +285,7 @@ func identifyDataDirOrDie(lg *zap.Logger, dir string) dirType {
 	}
 
 	if m && p {
-		lg.Fatal("invalid datadir; both member and proxy directories exist")
+		fatal(lg, "invalid datadir; both member and proxy directories exist")
 	}
 	if m {
 		return dirMember
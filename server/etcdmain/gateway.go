@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"go.etcd.io/etcd/client/pkg/v3/logutil"
+	"go.etcd.io/etcd/client/pkg/v3/transport"
 	"go.etcd.io/etcd/server/v3/proxy/tcpproxy"
 
 	"github.com/spf13/cobra"
@@ -68,7 +69,7 @@ func newGatewayStartCommand() *cobra.Command {
 		Run:   startGateway,
 	}
 
-	cmd.Flags().StringVar(&gatewayListenAddr, "listen-addr", "127.0.0.1:23790", "listen address")
+	cmd.Flags().StringVar(&gatewayListenAddr, "listen-addr", "127.0.0.1:23790", "listen address, or unix://<path> to listen on a unix socket")
 	cmd.Flags().StringVar(&gatewayDNSCluster, "discovery-srv", "", "DNS domain used to bootstrap initial cluster")
 	cmd.Flags().StringVar(&gatewayDNSClusterServiceName, "discovery-srv-name", "", "service name to query when using DNS discovery")
 	cmd.Flags().BoolVar(&gatewayInsecureDiscovery, "insecure-discovery", false, "accept insecure SRV records")
@@ -122,6 +123,40 @@ func startGateway(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	if len(srvs.Endpoints) == 0 {
+		fmt.Println("no endpoints found")
+		os.Exit(1)
+	}
+
+	var l net.Listener
+	if u, perr := url.Parse(gatewayListenAddr); perr == nil && u.Scheme == "unix" {
+		// a unix socket cannot be one of the TCP endpoints
+		l, err = transport.NewUnixListener(u.Host + u.Path)
+	} else {
+		checkGatewayListenAddr(srvs.SRVs)
+		l, err = net.Listen("tcp", gatewayListenAddr)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	tp := tcpproxy.TCPProxy{
+		Logger:          lg,
+		Listener:        l,
+		Endpoints:       srvs.SRVs,
+		MonitorInterval: gatewayRetryDelay,
+	}
+
+	// At this point, etcd gateway listener is initialized
+	notifySystemd(lg)
+
+	tp.Run()
+}
+
+// checkGatewayListenAddr exits if any of the endpoints resolves to the
+// gateway listen address.
+func checkGatewayListenAddr(srvs []*net.SRV) {
 	lhost, lport, err := net.SplitHostPort(gatewayListenAddr)
 	if err != nil {
 		fmt.Println("failed to validate listen address:", gatewayListenAddr)
@@ -138,7 +173,7 @@ func startGateway(cmd *cobra.Command, args []string) {
 		laddrsMap[addr] = true
 	}
 
-	for _, srv := range srvs.SRVs {
+	for _, srv := range srvs {
 		var eaddrs []string
 		eaddrs, err = net.LookupHost(srv.Target)
 		if err != nil {
@@ -156,28 +191,4 @@ func startGateway(cmd *cobra.Command, args []string) {
 			}
 		}
 	}
-
-	if len(srvs.Endpoints) == 0 {
-		fmt.Println("no endpoints found")
-		os.Exit(1)
-	}
-
-	var l net.Listener
-	l, err = net.Listen("tcp", gatewayListenAddr)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
-	}
-
-	tp := tcpproxy.TCPProxy{
-		Logger:          lg,
-		Listener:        l,
-		Endpoints:       srvs.SRVs,
-		MonitorInterval: gatewayRetryDelay,
-	}
-
-	// At this point, etcd gateway listener is initialized
-	notifySystemd(lg)
-
-	tp.Run()
 }
@@ -89,7 +89,8 @@ var (
 	grpcProxyEnablePprof    bool
 	grpcProxyEnableOrdering bool
 
-	grpcProxyDebug bool
+	grpcProxyDebug      bool
+	grpcProxyLogOutputs []string
 
 	// GRPC keep alive related options.
 	grpcKeepAliveMinTime  time.Duration
@@ -158,6 +159,7 @@ func newGRPCProxyStartCommand() *cobra.Command {
 	cmd.Flags().StringVar(&grpcProxyLeasing, "experimental-leasing-prefix", "", "leasing metadata prefix for disconnected linearized reads.")
 
 	cmd.Flags().BoolVar(&grpcProxyDebug, "debug", false, "Enable debug-level logging for grpc-proxy.")
+	cmd.Flags().StringSliceVar(&grpcProxyLogOutputs, "log-outputs", []string{"stderr"}, "comma separated log output targets: 'stderr', 'stdout', file paths, 'systemd-journal' or 'syslog://host:port'.")
 
 	return &cmd
 }
@@ -169,7 +171,10 @@ func startGRPCProxy(cmd *cobra.Command, args []string) {
 		lvl = zap.DebugLevel
 		grpc.EnableTracing = true
 	}
-	lg, err := logutil.CreateDefaultZapLogger(lvl)
+	lcfg := logutil.DefaultZapLoggerConfig
+	lcfg.Level = zap.NewAtomicLevelAt(lvl)
+	lcfg.OutputPaths = grpcProxyLogOutputs
+	lg, err := logutil.NewLogger(lcfg)
 	if err != nil {
 		panic(err)
 	}
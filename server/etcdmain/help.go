@@ -36,14 +36,35 @@ var (
   etcd -h | --help
     Show the help information about etcd.
 
+  etcd --validate-config
+    Validate the configuration from flags, environment variables or --config-file (TLS files, data directory permissions, backend quota), print a JSON report and exit with status 1 if it is invalid, without starting the server.
+
   etcd --config-file
-    Path to the server configuration file. Note that if a configuration file is provided, other command line flags and environment variables will be ignored.
+    Path to the server configuration file in YAML, or in TOML or HCL if its name ends in .toml or .hcl. Note that if a configuration file is provided, other command line flags and environment variables will be ignored.
+
+  etcd --config-dir
+    Path to a directory of server configuration files (*.yaml, *.yml), merged in lexical order with later files overriding earlier ones. Cannot be used with --config-file; other command line flags and environment variables will be ignored.
+
+  Configuration values may reference environment variables as ${NAME}, which are expanded
+  after the file is parsed; undefined variables are an error. Use $${ for a literal ${.
+
+  When started by systemd socket activation, etcd serves the passed sockets (LISTEN_FDS)
+  whose addresses match --listen-peer-urls or --listen-client-urls instead of binding them.
+
+  On SIGHUP, etcd reloads the client and peer TLS certificates, keys and trusted CA files,
+  and the log level if started with --config-file or --config-dir, without restarting the member.
 
   etcd gateway
     Run the stateless pass-through etcd TCP connection forwarding proxy.
 
   etcd grpc-proxy
     Run the stateless etcd v3 gRPC L7 reverse proxy.
+
+  etcd service install|uninstall|start|stop
+    Manage etcd as a Windows service (Windows only). Flags after "install --" are passed to etcd when the service starts.
+
+  etcd preflight
+    Check the environment before starting etcd (WAL fsync latency, clock skew against peers, port availability, file descriptor limit, cgroup memory limit and free disk space), print a JSON report and exit with status 1 if a check fails.
 `
 	flagsline = `
 Member:
@@ -53,6 +74,10 @@ Member:
     Path to the data directory.
   --wal-dir ''
     Path to the dedicated wal directory.
+  --pid-file ''
+    Path to the file the process PID is written to on start. etcd refuses to start if another instance holds the lock on the data directory.
+  --auto-restart-on-failure 'false'
+    Restart the server in-process with the same data directory on listener errors or panics while serving, instead of exiting. Restarts back off exponentially from 1s to 1m, and etcd exits after 10 consecutive failures. The backoff and the failure count are reset once the server has run for 10m.
   --snapshot-count '100000'
     Number of committed transactions to trigger a snapshot to disk.
   --heartbeat-interval '100'
@@ -62,15 +87,17 @@ Member:
   --initial-election-tick-advance 'true'
     Whether to fast-forward initial election ticks on boot for faster election.
   --listen-peer-urls 'http://localhost:2380'
-    List of URLs to listen on for peer traffic.
+    List of URLs to listen on for peer traffic. unix://<name> and unixs://<name> listen on a unix socket file.
   --listen-client-urls 'http://localhost:2379'
-    List of URLs to listen on for client traffic.
+    List of URLs to listen on for client traffic. unix://<path> and unixs://<path> listen on a unix socket file.
   --max-snapshots '` + strconv.Itoa(embed.DefaultMaxSnapshots) + `'
     Maximum number of snapshot files to retain (0 is unlimited).
   --max-wals '` + strconv.Itoa(embed.DefaultMaxWALs) + `'
     Maximum number of wal files to retain (0 is unlimited).
   --quota-backend-bytes '0'
     Raise alarms when backend size exceeds the given quota (0 defaults to low space quota).
+  --backend-engine 'bbolt'
+    Storage engine of the backend. 'bbolt' is the only engine built in, others can be registered by embedding applications.
   --backend-bbolt-freelist-type 'map'
     BackendFreelistType specifies the type of freelist that boltdb backend uses(array and map are supported types).
   --backend-batch-interval ''
@@ -81,16 +108,22 @@ Member:
     Maximum number of operations permitted in a transaction.
   --max-request-bytes '1572864'
     Maximum client request size in bytes the server will accept.
+  --max-leases-per-user '0'
+    Maximum number of leases an authenticated user can hold (0 for no limit). Enforced when the leases are granted, so it must be the same on all the members.
   --grpc-keepalive-min-time '5s'
     Minimum duration interval that a client should wait before pinging server.
   --grpc-keepalive-interval '2h'
     Frequency duration of server-to-client ping to check if a connection is alive (0 to disable).
   --grpc-keepalive-timeout '20s'
     Additional duration of wait before closing a non-responsive connection (0 to disable).
+  --graceful-shutdown-timeout '0s'
+    Maximum duration to wait for in-flight client requests and streams to complete on shutdown, after leadership is transferred. 0 means the request timeout derived from --election-timeout.
   --socket-reuse-port 'false'
     Enable to set socket option SO_REUSEPORT on listeners allowing rebinding of a port already in use.
   --socket-reuse-address 'false'
 	Enable to set socket option SO_REUSEADDR on listeners allowing binding to an address in TIME_WAIT state.
+  --unix-socket-mode ''
+    Octal file mode (e.g. 0660) of the socket files of unix:// and unixs:// listen URLs. Defaults to the umask.
 
 Clustering:
   --initial-advertise-peer-urls 'http://localhost:2380'
@@ -149,6 +182,8 @@ Clustering:
     Auto compaction retention length. 0 means disable auto compaction.
   --auto-compaction-mode 'periodic'
     Interpret 'auto-compaction-retention' one of: periodic|revision. 'periodic' for duration based retention, defaulting to hours if no time unit is provided (e.g. '5m'). 'revision' for revision number based retention.
+  --timer-jitter '0s'
+    Maximum delay applied to the start of periodic background timers (auto compaction) to desynchronize members. The delay is derived from the member ID, so it is stable across restarts. 0 means disable jitter.
   --v2-deprecation '` + string(cconfig.V2_DEPR_DEFAULT) + `'
     Phase of v2store deprecation. Allows to opt-in for higher compatibility mode.
     Supported values:
@@ -164,10 +199,14 @@ Security:
     Path to the client server TLS key file.
   --client-cert-auth 'false'
     Enable client cert authentication.
+  --client-cert-role-rules ''
+    Comma-separated list of 'attribute=pattern:role' rules granting roles to the client certificates, the attribute being one of 'cn', 'o', 'ou', 'dns', 'email', 'uri', 'ip' and 'spiffe'.
   --client-crl-file ''
     Path to the client certificate revocation list file.
   --client-cert-allowed-hostname ''
     Allowed TLS hostname for client cert authentication.
+  --client-cert-allowed-uri ''
+    Allowed URI SAN pattern for client cert authentication, such as 'spiffe://example.org/etcd-client/*'.
   --trusted-ca-file ''
     Path to the client server TLS trusted CA cert file.
   --auto-tls 'false'
@@ -184,12 +223,16 @@ Security:
     Required CN for client certs connecting to the peer endpoint.
   --peer-cert-allowed-hostname ''
     Allowed TLS hostname for inter peer authentication.
+  --peer-cert-allowed-uri ''
+    Allowed URI SAN pattern for inter peer authentication, such as 'spiffe://example.org/etcd/*'.
   --peer-auto-tls 'false'
     Peer TLS using self-generated certificates if --peer-key-file and --peer-cert-file are not provided.
   --self-signed-cert-validity '1'
     The validity period of the client and peer certificates that are automatically generated by etcd when you specify ClientAutoTLS and PeerAutoTLS, the unit is year, and the default is 1.
   --peer-crl-file ''
     Path to the peer certificate revocation list file.
+  --spiffe-workload-api-addr ''
+    Address of the SPIFFE Workload API, 'unix:///path/to/socket' or 'tcp://host:port', the rotated X.509 SVIDs of which are the client and peer TLS certificates of the HTTPS URLs with no cert and key files.
   --cipher-suites ''
     Comma-separated list of supported TLS cipher suites between client/server and peers (empty will be auto-populated by Go).
   --cors '*'
@@ -204,10 +247,56 @@ Auth:
     Specify the cost / strength of the bcrypt algorithm for hashing auth passwords. Valid values are between ` + fmt.Sprintf("%d", bcrypt.MinCost) + ` and ` + fmt.Sprintf("%d", bcrypt.MaxCost) + `.
   --auth-token-ttl 300
     Time (in seconds) of the auth-token-ttl.
+  --auth-oidc-issuer-url ''
+    URL of the OpenID Connect issuer whose ID tokens authenticate the users, alongside the tokens of --auth-token.
+  --auth-oidc-client-id ''
+    Audience the OIDC ID tokens must be issued for.
+  --auth-oidc-username-claim 'sub'
+    Claim of the user name of the OIDC ID tokens.
+  --auth-oidc-username-prefix 'oidc:'
+    Prefix of the user names of the OIDC ID tokens, so that they do not clash with the users of etcd (e.g. a token of subject 'root' is not the root user). Must not be empty.
+  --auth-oidc-role-rules ''
+    Comma-separated list of 'claim=value:role' rules granting roles to the users of the OIDC ID tokens, who then do not need to exist in etcd.
+  --auth-ldap-url ''
+    URL of the LDAP directory the users absent from etcd authenticate against with their passwords, ldap:// or ldaps://. Disabled if empty.
+  --auth-ldap-bind-dn-templates ''
+    Semicolon-separated list of the DNs the users bind to the LDAP directory as, tried in order, with '%s' replaced by the user name, such as 'uid=%s,ou=people,dc=example,dc=com'.
+  --auth-ldap-group-attribute 'memberOf'
+    Attribute of the LDAP entries of the users listing the DNs of their groups.
+  --auth-ldap-role-rules ''
+    Semicolon-separated list of 'group DN:role' rules granting roles to the members of the LDAP groups. The users granted no role fail to authenticate.
+  --auth-ldap-ca-file ''
+    Path to the CA certificates verifying the certificate of an ldaps:// directory, the system ones if empty.
+  --authorization-webhook-url ''
+    URL the client requests are posted to as JSON, with the user, the gRPC method and the key ranges, for an {"allowed": bool, "reason": string, "limit": int} decision. Disabled if empty.
+  --authorization-webhook-timeout '1s'
+    Timeout of the requests to the authorization webhook, after which the client requests are denied.
+  --auth-lockout-threshold 0
+    Number of failed authentications in a row locking out a user, or a client address, on a member. 0 disables the lockouts.
+  --auth-lockout-duration '30s'
+    Duration of the first lockout. Each following lockout, after as many more failures, doubles it.
+  --auth-lockout-max-duration '30m0s'
+    Maximum duration of the lockouts. The failures are forgotten after it passes without any.
+  --auth-password-hash 'bcrypt'
+    Algorithm hashing the passwords, 'bcrypt' or 'argon2id'. The passwords hashed otherwise are rehashed on login.
+  --auth-argon2-time 3
+    Number of passes over the memory of argon2id.
+  --auth-argon2-memory 65536
+    Memory of argon2id, in KiB.
+  --auth-argon2-threads 4
+    Number of threads of argon2id.
+  --auth-password-min-length 0
+    Minimum number of characters of the passwords set.
+  --auth-password-min-classes 0
+    Minimum number of classes of characters, among lower case letters, upper case letters, digits and others, of the passwords set.
+  --auth-password-max-age 0
+    Age after which the passwords of the users other than root expire. 0 disables the expiry.
 
 Profiling and Monitoring:
   --enable-pprof 'false'
     Enable runtime profiling data via HTTP server. Address is at client URL + "/debug/pprof/"
+  --enable-grpc-reflection 'false'
+    Enable gRPC server reflection on the client listeners, for tools like grpcurl to introspect the API.
   --metrics 'basic'
     Set level of detail for exported metrics, specify 'extensive' to include server side grpc histogram metrics.
   --listen-metrics-urls ''
@@ -217,7 +306,7 @@ Logging:
   --logger 'zap'
     Currently only supports 'zap' for structured logging.
   --log-outputs 'default'
-    Specify 'stdout' or 'stderr' to skip journald logging even when running under systemd, or list of comma separated output targets.
+    Specify 'stdout' or 'stderr' to skip journald logging even when running under systemd, or list of comma separated output targets: file paths, 'systemd-journal' to write to the systemd journal, or 'syslog://host:port' to send to a syslog server over UDP, both with native log priorities.
   --log-level 'info'
     Configures log level. Only supports debug, info, warn, error, panic, or fatal.
   --log-format 'json'
@@ -226,10 +315,20 @@ Logging:
     Enable log rotation of a single log-outputs file target.
   --log-rotation-config-json '{"maxsize": 100, "maxage": 0, "maxbackups": 0, "localtime": false, "compress": false}'
     Configures log rotation if enabled with a JSON logger config. MaxSize(MB), MaxAge(days,0=no limit), MaxBackups(0=no limit), LocalTime(use computers local time), Compress(gzip)". 
+  --slow-request-threshold '0s'
+    Duration after which a request is logged with its type, key ranges, txn size, and the time it spent waiting for commit, in the apply queue (or for the read index), fsyncing the WAL and applying. 0 disables slow request logs.
+
+Audit logging:
+  --audit-log-output ''
+    Specify 'stdout', 'stderr' or a file path to write a JSON audit log of all the RPCs but the read-only KV, lease, cluster and maintenance ones to, with the authenticated user, client address, key ranges, result and latency. Disabled if empty.
+  --audit-log-rotation-config-json '{"maxsize": 100, "maxage": 0, "maxbackups": 0, "localtime": false, "compress": false}'
+    Configures rotation of an --audit-log-output file with a JSON logger config, see --log-rotation-config-json.
+  --audit-log-redact-values 'true'
+    Omit the values of put requests from the audit log. Passwords are never logged.
 
 Experimental distributed tracing:
   --experimental-enable-distributed-tracing 'false'
-    Enable experimental distributed tracing.
+    Enable experimental distributed tracing of gRPC requests and their raft proposal, append, fsync, commit and apply.
   --experimental-distributed-tracing-address 'localhost:4317'
     Distributed tracing collector address.
   --experimental-distributed-tracing-service-name 'etcd'
@@ -244,14 +343,76 @@ Experimental feature:
     Enable to check data corruption before serving any client/peer traffic.
   --experimental-corrupt-check-time '0s'
     Duration of time between cluster corruption check passes.
+  --experimental-election-priority '0'
+    Priority of the member to be the leader, published in its membership attributes. The leader hands its leadership over to the member of the highest priority above its own once it is connected and caught up with the log, so that the preferred members lead unless unavailable.
+  --experimental-member-labels ''
+    Comma separated key=value labels of the member, published in its membership attributes (e.g. 'zone=us-east-1a,disk=ssd'). The 'zone' label places the member in a zone for --experimental-leader-zones.
+  --experimental-leader-zones ''
+    Comma separated zones the leader is preferably in, from the most preferred (e.g. 'us-east-1a,us-east-1b'). The leader hands its leadership over to a caught up member of a more preferred zone, the members out of the zones listed coming last, then to a member of a higher election priority within its zone. Must be the same on all the members.
+  --experimental-witness 'false'
+    Run the member as a witness, a tiebreaker voting in the raft elections that persists the raft log but keeps no KV data: it rejects the client requests other than Status, MemberList, Alarm and Defragment, clears the KV data of the snapshots it receives, skips the corruption checks and hands its leadership over to another member once elected.
   --experimental-enable-lease-checkpoint 'false'
     ExperimentalEnableLeaseCheckpoint enables primary lessor to persist lease remainingTTL to prevent indefinite auto-renewal of long lived leases.
   --experimental-compaction-batch-limit 1000
     ExperimentalCompactionBatchLimit sets the maximum revisions deleted in each compaction batch.
+  --experimental-defrag-batch-limit 10000
+    Number of keys copied at a time by the incremental defragmentation, which keeps serving the requests between the batches. Defragmentation stops the world if not positive.
+  --experimental-defrag-batch-interval '0s'
+    Pause between the batches of the incremental defragmentation.
+  --experimental-value-compression 'none'
+    Compression of the stored values: 'none', 'zstd' or 'lz4'. The values already stored compressed are read whatever the compression.
+  --experimental-value-compression-threshold 1024
+    Size in bytes from which a stored value is compressed.
+  --experimental-wal-compression 'none'
+    Compression of the WAL entries: 'none' or 'zstd'. The WAL segments are read whatever the compression of their entries.
+  --experimental-max-inflight-msgs '512'
+    Max number of append messages the leader sends to a follower and not yet acknowledged, up to 4096.
+  --experimental-max-inflight-bytes '0'
+    Max total byte size of the entries the leader sends to a follower and not yet acknowledged, bounding the memory a slow follower holds on the leader (0 for no limit, at least 1048576 otherwise).
+  --experimental-adaptive-inflight-msgs 'false'
+    Halve the max number of inflight messages of a follower the leader fails to send messages to, growing it back by one message per acknowledgement. The flow control of the followers is exposed by the endpoint status of the leader and its etcd_server_follower_* metrics.
+  --experimental-peer-compression 'none'
+    Compression of the raft streams and snapshots sent to the peers accepting it: 'none' or 'zstd', followed by the comma separated compressions by peer as <member-id>=<compression> (e.g. 'none,8211f1d0f64f3269=zstd' to compress the messages sent to a member across a WAN link only). The members of older versions are sent the messages uncompressed.
+  --experimental-wal-io-uring 'false'
+    Write the WAL through io_uring on Linux, the writes pending on a sync being submitted along with the fdatasync in a single system call. Falls back to the regular writes where io_uring is not available.
+  --experimental-wal-segment-size '64000000'
+    Size in bytes from which a WAL segment is cut.
+  --experimental-wal-prealloc-size '0'
+    Size in bytes preallocated for a WAL segment, the segment size if 0. The segments are not preallocated if negative.
+  --experimental-wal-tmp-segments '1'
+    Number of WAL segments created ahead as .tmp files, so that cutting a segment does not wait for the allocation.
+  --experimental-wal-archive ''
+    Sink the completed WAL segments are archived to, as '<member id>/<segment name>': 'file:///path/to/dir', 's3://bucket/prefix?endpoint=<url>[&region=<region>]' with the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY credentials, or 'exec:/path/to/command [args]' run with the segment path and name appended. The last segment archived is recorded in the backend.
+  --experimental-wal-archive-interval '10s'
+    Interval between the archivings of the completed WAL segments.
+  --experimental-encryption-key-file ''
+    Path to the file of the keys encrypting the WAL entries and the snapshots at rest, one '<id>:<base64 32 bytes key>' per line. The first key wraps the new data keys; keep the previous ones until the WAL segments and snapshots they wrapped the keys of are purged. The backend database ('member/snap/db') and the '*.snap.db' snapshots received from the peers are NOT encrypted: the key-values at rest must be protected by encrypting the disk.
+  --experimental-range-tombstone-threshold 0
+    Number of keys from which a range deletion records a single range tombstone, applied to the keys by the compaction, instead of a tombstone for each of them. Must be the same on all the members. Watchers catching up from an older revision do not see the keys it deletes. Disabled if 0.
+  --experimental-read-cache-size 0
+    Number of key-values decoded from the backend cached for the reads, sparing the hot keys a backend lookup and decoding. The revision of a key superseded by a write is evicted from the cache. Disabled if 0.
+  --experimental-watch-stuck-policy 'cancel'
+    Policy applied to the slow watchers blocked with a backlog over the stuck thresholds: 'cancel' cancels them with a CancelReason, 'pause' drops their backlog until their stream is drained then catches them up from the store, 'compress' keeps only the last event of each key of their backlog.
+  --experimental-watch-stuck-max-bytes 0
+    Size in bytes of the buffered backlog of a slow watcher from which it is stuck. Disabled if 0.
+  --experimental-watch-stuck-max-age '0s'
+    Time a slow watcher is blocked on its stream for from which it is stuck. Disabled if 0.
+  --experimental-watch-history-retention 0
+    Number of revisions up to the compaction revision whose events are kept by the compaction in a history bucket, so that the watchers starting from a compacted revision in the history replay its events instead of being canceled with ErrCompacted. The keys are compacted from the index and the key bucket as before. Disabled if 0.
+  --experimental-backend-scrub-interval '0s'
+    Pause between the background passes verifying the pages and records of the backend, raising a CORRUPT alarm on the first corruption found. bbolt has no page checksums: the pass checks the page structure, the key order and that the records decode. Disabled if 0.
+  --experimental-backend-scrub-rate 10000
+    Number of backend records verified per second by the background scrubbing.
+  --experimental-auto-compaction-prefix-retention ''
+    Periodic auto compaction retention of the keys under prefixes, as comma separated prefix=duration pairs (e.g. '/events/=1h,/config/=168h'), overriding --auto-compaction-retention. The keys under several prefixes are retained for the retention of the longest one. The ranges of keys retained for less than the others are compacted at a revision of their own, before which the hash of the keys cannot be checked.
+  --experimental-max-chunked-value-bytes 0
+    Maximum size in bytes of the value of a put larger than --max-request-bytes, proposed in chunks staged in the backend then assembled into the stored value. Only plain puts are chunked, not the puts of a transaction, and the clients must allow sending messages that large. Disabled if 0.
   --experimental-peer-skip-client-san-verification 'false'
     Skip verification of SAN field in client certificate for peer connections.
   --experimental-watch-progress-notify-interval '10m'
     Duration of periodical watch progress notification.
+  --experimental-bounded-staleness-window '1s'
+    How recently a follower or learner must have heard from the leader to serve the reads with a bounded staleness against the commit index it knows, without a read index request. Disabled if 0.
   --experimental-warning-apply-duration '100ms'
     Warning is generated if requests take more than this duration.
   --experimental-txn-mode-write-with-shared-buffer 'true'
@@ -264,12 +425,38 @@ Experimental feature:
     Set the max number of learner members allowed in the cluster membership.
   --experimental-wait-cluster-ready-timeout '5s'
     Set the maximum time duration to wait for the cluster to be ready.
+  --experimental-hot-key-sample-rate '0'
+    Fraction of the client key accesses sampled to find the most accessed key prefixes, between 0 and 1. 0 disables the sampling.
+  --experimental-hot-key-window '1m'
+    Duration of the sliding window the sampled key accesses are counted over.
+  --experimental-profile-apply-threshold '0s'
+    Apply duration of a request after which goroutine, heap and CPU profiles are captured to <data-dir>/member/profiles. 0 disables the trigger.
+  --experimental-profile-fsync-threshold '0s'
+    WAL fsync duration after which goroutine, heap and CPU profiles are captured to <data-dir>/member/profiles. 0 disables the trigger.
+  --experimental-profile-min-interval '10m'
+    Minimum time between two profile captures.
+  --experimental-profile-cpu-duration '10s'
+    Duration of the captured CPU profiles.
+  --experimental-profile-url ''
+    HTTP endpoint the captured profiles are posted to instead of being written to the member directory.
+  --experimental-leader-degradation-fsync-threshold '0s'
+    Mean duration of the WAL fsyncs over a second above which the local member is degraded. 0 disables the signal.
+  --experimental-leader-degradation-cpu-steal-threshold '0'
+    Percentage of the CPU time stolen by the hypervisor, read from /proc/stat, above which the local member is degraded. 0 disables the signal.
+  --experimental-leader-degradation-memory-pressure-threshold '0'
+    Percentage of the time some tasks stalled on memory over 10 seconds, read from /proc/pressure/memory, above which the local member is degraded. 0 disables the signal.
+  --experimental-leader-degradation-duration '30s'
+    Duration a signal must stay above its threshold for the local member to be degraded, then below it to be recovered. A degraded leader transfers its leadership to another voting member, at most once per duration. The degradation is exposed by the etcd_server_local_degraded metric.
 
 Unsafe feature:
   --force-new-cluster 'false'
     Force to create a new one-member cluster.
   --unsafe-no-fsync 'false'
     Disables fsync, unsafe, will cause data loss.
+  --unsafe-wal-durability 'fsync'
+    Durability of the raft log writes: 'fsync' fsyncs the WAL before acknowledging them, 'batched' fsyncs it every --unsafe-wal-sync-interval, 'none' leaves it to the OS. Other than 'fsync' loses the entries written since the last fsync on a power failure, which may lose committed data if several members fail at once; meant for ephemeral clusters such as caches or CI. The members relaxing it publish it in their membership attributes, listed by 'etcdctl member list -w json'.
+  --unsafe-wal-sync-interval '100ms'
+    Interval the WAL is fsynced at with --unsafe-wal-durability=batched.
 
 CAUTIOUS with unsafe flag! It may break the guarantees given by the consensus protocol!
 `
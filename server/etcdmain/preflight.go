@@ -0,0 +1,261 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdmain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+	runtimeutil "go.etcd.io/etcd/pkg/v3/runtime"
+	"go.etcd.io/etcd/server/v3/embed"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/rafthttp"
+	"go.etcd.io/etcd/server/v3/storage"
+
+	"github.com/spf13/cobra"
+	"github.com/xiang90/probing"
+)
+
+// preflightConfig holds the thresholds used by "etcd preflight".
+type preflightConfig struct {
+	dataDir        string
+	walDir         string
+	clientURLs     []string
+	listenPeerURLs []string
+	peerURLs       []string
+	peerTLSInfo    transport.TLSInfo
+	fsyncCount     int
+	maxFsync       time.Duration
+	maxClockSkew   time.Duration
+	minFDs         uint64
+	minFreeBytes   uint64
+	quotaBytes     int64
+	requestTimeout time.Duration
+}
+
+var preflightCfg preflightConfig
+
+func init() {
+	rootCmd.AddCommand(newPreflightCommand())
+}
+
+// newPreflightCommand returns the cobra command for "preflight".
+func newPreflightCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "preflight",
+		Short: "check the environment before starting etcd",
+		Run:   runPreflightAndExit,
+	}
+
+	cmd.Flags().StringVar(&preflightCfg.dataDir, "data-dir", "default.etcd", "Path to the data directory.")
+	cmd.Flags().StringVar(&preflightCfg.walDir, "wal-dir", "", "Path to the dedicated wal directory. Defaults to the wal directory under --data-dir.")
+	cmd.Flags().StringSliceVar(&preflightCfg.clientURLs, "listen-client-urls", []string{embed.DefaultListenClientURLs}, "Comma-separated list of URLs etcd will listen on for client traffic.")
+	cmd.Flags().StringSliceVar(&preflightCfg.listenPeerURLs, "listen-peer-urls", []string{embed.DefaultListenPeerURLs}, "Comma-separated list of URLs etcd will listen on for peer traffic.")
+	cmd.Flags().StringSliceVar(&preflightCfg.peerURLs, "peer-urls", nil, "Comma-separated list of peer URLs of running members to check the clock skew against.")
+	cmd.Flags().StringVar(&preflightCfg.peerTLSInfo.CertFile, "peer-cert-file", "", "Path to the peer server TLS cert file.")
+	cmd.Flags().StringVar(&preflightCfg.peerTLSInfo.KeyFile, "peer-key-file", "", "Path to the peer server TLS key file.")
+	cmd.Flags().StringVar(&preflightCfg.peerTLSInfo.TrustedCAFile, "peer-trusted-ca-file", "", "Path to the peer server TLS trusted CA file.")
+	cmd.Flags().IntVar(&preflightCfg.fsyncCount, "fsync-count", 100, "Number of WAL-sized writes to fsync in the wal directory.")
+	cmd.Flags().DurationVar(&preflightCfg.maxFsync, "max-fsync-latency", 10*time.Millisecond, "Maximum allowed 99th percentile fsync latency.")
+	cmd.Flags().DurationVar(&preflightCfg.maxClockSkew, "max-clock-skew", time.Second, "Maximum allowed clock skew against peers.")
+	cmd.Flags().Uint64Var(&preflightCfg.minFDs, "min-file-descriptors", 65536, "Minimum allowed open file descriptor limit.")
+	cmd.Flags().Uint64Var(&preflightCfg.minFreeBytes, "min-free-bytes", uint64(storage.DefaultQuotaBytes), "Minimum allowed free space on the data and wal directory disks.")
+	cmd.Flags().Int64Var(&preflightCfg.quotaBytes, "quota-backend-bytes", storage.DefaultQuotaBytes, "Backend quota the memory limit is compared against.")
+	cmd.Flags().DurationVar(&preflightCfg.requestTimeout, "request-timeout", 5*time.Second, "Timeout of requests to peers.")
+
+	return &cmd
+}
+
+func runPreflightAndExit(cmd *cobra.Command, args []string) {
+	r := runPreflight(&preflightCfg)
+	if err := writeReport(os.Stdout, r); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write preflight report: %v\n", err)
+		os.Exit(1)
+	}
+	if !r.Valid {
+		os.Exit(1)
+	}
+}
+
+// runPreflight runs all environment checks of pc and reports the results.
+func runPreflight(pc *preflightConfig) *validationReport {
+	r := &validationReport{Valid: true}
+
+	walDir := pc.walDir
+	if walDir == "" {
+		walDir = filepath.Join(pc.dataDir, "member", "wal")
+	}
+	r.add("wal-fsync", checkFsync(existingDir(walDir), pc.fsyncCount, pc.maxFsync))
+	r.add("clock-skew", checkClockSkew(pc.peerURLs, pc.peerTLSInfo, pc.maxClockSkew, pc.requestTimeout))
+	r.add("ports", checkPorts(append(append([]string{}, pc.clientURLs...), pc.listenPeerURLs...)))
+
+	if n, err := runtimeutil.FDLimit(); err != nil {
+		r.warn("file-descriptors", err.Error())
+	} else if n < pc.minFDs {
+		r.add("file-descriptors", fmt.Errorf("open file descriptor limit %d is lower than %d", n, pc.minFDs))
+	} else {
+		r.add("file-descriptors", nil)
+	}
+
+	if limit, err := memoryLimit(); err != nil {
+		r.warn("memory-limit", err.Error())
+	} else if limit > 0 && pc.quotaBytes > 0 && limit < uint64(pc.quotaBytes) {
+		r.warn("memory-limit", fmt.Sprintf("cgroup memory limit %d is lower than the backend quota %d", limit, pc.quotaBytes))
+	} else {
+		r.add("memory-limit", nil)
+	}
+
+	dirs := []string{existingDir(pc.dataDir)}
+	if d := existingDir(walDir); d != dirs[0] {
+		dirs = append(dirs, d)
+	}
+	for _, dir := range dirs {
+		free, err := freeBytes(dir)
+		if err != nil {
+			r.warn("disk-free", err.Error())
+			continue
+		}
+		if free < pc.minFreeBytes {
+			r.add("disk-free", fmt.Errorf("%q has %d bytes free, lower than %d", dir, free, pc.minFreeBytes))
+			continue
+		}
+		r.add("disk-free", nil)
+	}
+	return r
+}
+
+// existingDir returns dir, or the closest existing parent it would be
+// created in.
+func existingDir(dir string) string {
+	dir = filepath.Clean(dir)
+	for !fileutil.Exist(dir) && dir != filepath.Dir(dir) {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+// checkFsync writes and fsyncs count WAL-sized records to a temporary file
+// in dir and fails if the 99th percentile latency exceeds max.
+func checkFsync(dir string, count int, max time.Duration) error {
+	if count <= 0 {
+		return nil
+	}
+	f, err := os.CreateTemp(dir, ".etcd-preflight-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	// WAL entries of small requests are a few KiB
+	buf := make([]byte, 2300)
+	lats := make([]time.Duration, 0, count)
+	for i := 0; i < count; i++ {
+		if _, err = f.Write(buf); err != nil {
+			return err
+		}
+		start := time.Now()
+		if err = fileutil.Fdatasync(f); err != nil {
+			return err
+		}
+		lats = append(lats, time.Since(start))
+	}
+	sort.Slice(lats, func(i, j int) bool { return lats[i] < lats[j] })
+	p99 := lats[(len(lats)*99-1)/100]
+	if p99 > max {
+		return fmt.Errorf("99th percentile fsync latency %v in %q exceeds %v", p99, dir, max)
+	}
+	return nil
+}
+
+// checkClockSkew compares the local clock with the clock of each peer, the
+// same way members do when probing each other.
+func checkClockSkew(peerURLs []string, tlsInfo transport.TLSInfo, max, timeout time.Duration) error {
+	if len(peerURLs) == 0 {
+		return nil
+	}
+	rt, err := transport.NewTimeoutTransport(tlsInfo, timeout, timeout, timeout)
+	if err != nil {
+		return err
+	}
+	defer rt.CloseIdleConnections()
+	cli := &http.Client{Transport: rt, Timeout: timeout}
+
+	var errs []string
+	for _, u := range peerURLs {
+		start := time.Now()
+		resp, err := cli.Get(strings.TrimSuffix(u, "/") + rafthttp.ProbingPrefix)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		var hh probing.Health
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("unexpected status %s", resp.Status)
+		} else {
+			err = json.NewDecoder(resp.Body).Decode(&hh)
+		}
+		resp.Body.Close()
+		if err != nil || !hh.OK {
+			errs = append(errs, fmt.Sprintf("unexpected probing response from %s (%v)", u, err))
+			continue
+		}
+		skew := time.Since(hh.Now) - time.Since(start)/2
+		if skew < 0 {
+			skew = -skew
+		}
+		if skew > max {
+			errs = append(errs, fmt.Sprintf("clock skew %v against %s exceeds %v", skew, u, max))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// checkPorts ensures the addresses of all listen URLs can be bound.
+func checkPorts(listenURLs []string) error {
+	var errs []string
+	for _, s := range listenURLs {
+		u, err := url.Parse(s)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if u.Scheme == "unix" || u.Scheme == "unixs" {
+			continue
+		}
+		l, err := net.Listen("tcp", u.Host)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		l.Close()
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdmain
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/server/v3/etcdserver/api/rafthttp"
+
+	"github.com/xiang90/probing"
+)
+
+func TestRunPreflight(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle(rafthttp.ProbingPrefix, probing.NewHandler())
+	peer := httptest.NewServer(mux)
+	defer peer.Close()
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+
+	newConfig := func() *preflightConfig {
+		return &preflightConfig{
+			dataDir:        filepath.Join(t.TempDir(), "member"),
+			clientURLs:     []string{"http://127.0.0.1:0"},
+			peerURLs:       []string{peer.URL},
+			fsyncCount:     10,
+			maxFsync:       time.Minute,
+			maxClockSkew:   time.Second,
+			requestTimeout: time.Second,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(pc *preflightConfig)
+		failed string
+	}{
+		{
+			name:   "pass",
+			modify: func(pc *preflightConfig) {},
+		},
+		{
+			name:   "port in use",
+			modify: func(pc *preflightConfig) { pc.listenPeerURLs = []string{"http://" + busy.Addr().String()} },
+			failed: "ports",
+		},
+		{
+			name:   "unresponsive peer",
+			modify: func(pc *preflightConfig) { pc.peerURLs = []string{"http://" + busy.Addr().String()} },
+			failed: "clock-skew",
+		},
+		{
+			name:   "slow fsync",
+			modify: func(pc *preflightConfig) { pc.maxFsync = -1 },
+			failed: "wal-fsync",
+		},
+		{
+			name:   "file descriptors",
+			modify: func(pc *preflightConfig) { pc.minFDs = ^uint64(0) },
+			failed: "file-descriptors",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc := newConfig()
+			tt.modify(pc)
+			r := runPreflight(pc)
+			if r.Valid != (tt.failed == "") {
+				t.Fatalf("valid = %v, want failed check %q (%+v)", r.Valid, tt.failed, r.Checks)
+			}
+			for _, c := range r.Checks {
+				if (c.Error != "") != (c.Name == tt.failed) {
+					t.Errorf("check %q error = %q, want failed check %q", c.Name, c.Error, tt.failed)
+				}
+			}
+		})
+	}
+}
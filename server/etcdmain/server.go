@@ -0,0 +1,227 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdmain
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.etcd.io/etcd/server/v3/embed"
+
+	"go.uber.org/zap"
+)
+
+var errServerStopped = errors.New("etcdmain: server stopped")
+
+const (
+	autoRestartMinBackoff = time.Second
+	autoRestartMaxBackoff = time.Minute
+	// autoRestartMaxAttempts is the number of consecutive failed restarts
+	// after which the supervisor gives up.
+	autoRestartMaxAttempts = 10
+	// autoRestartResetAfter is how long a restarted server must run without
+	// failing for the backoff and the attempt count to be reset.
+	autoRestartResetAfter = 10 * time.Minute
+)
+
+// server is a handle to an embedded etcd server started by startEtcd, which
+// can be stopped and restarted in-process with the same configuration.
+type server struct {
+	cfg embed.Config
+
+	mu      sync.Mutex
+	e       *embed.Etcd
+	stopped bool
+
+	donec    chan struct{}
+	doneOnce sync.Once
+	errc     chan error
+}
+
+// newServer starts an etcd server with cfg and waits until it joined the
+// cluster or stopped.
+func newServer(cfg *embed.Config) (*server, error) {
+	s := &server{
+		cfg:   *cfg,
+		donec: make(chan struct{}),
+		errc:  make(chan error, 1),
+	}
+	e, err := s.start(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.e = e
+	// pre-opened listeners are closed with the first server
+	s.cfg.Listeners = nil
+	go s.monitor(e)
+	return s, nil
+}
+
+func (s *server) start(cfg *embed.Config) (*embed.Etcd, error) {
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-e.Server.ReadyNotify(): // wait for e.Server to join the cluster
+	case <-e.Server.StopNotify(): // publish aborted from 'ErrStopped'
+	}
+	return e, nil
+}
+
+// monitor reports errors of e, and marks the server done once e stops,
+// unless e was stopped to be replaced by Restart.
+func (s *server) monitor(e *embed.Etcd) {
+	select {
+	case err := <-e.Err():
+		if err != nil {
+			s.fail(err)
+			return
+		}
+	case <-e.Server.StopNotify():
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.e == e {
+		s.doneOnce.Do(func() { close(s.donec) })
+	}
+}
+
+func (s *server) fail(err error) {
+	select {
+	case s.errc <- err:
+	default:
+	}
+}
+
+// Etcd returns the currently running embedded server, or nil if it failed
+// to restart.
+func (s *server) Etcd() *embed.Etcd {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.e
+}
+
+// StopNotify returns a channel that is closed once the server stopped,
+// other than to be restarted.
+func (s *server) StopNotify() <-chan struct{} { return s.donec }
+
+// Err returns a channel receiving fatal errors of the server, such as
+// listener errors, panics while serving or a failed restart.
+func (s *server) Err() <-chan error { return s.errc }
+
+// Stop stops the server. It cannot be restarted afterwards.
+func (s *server) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return
+	}
+	s.stopped = true
+	if s.e != nil {
+		s.e.Close()
+	}
+	s.doneOnce.Do(func() { close(s.donec) })
+}
+
+// Restart stops the running server, if any, and starts it again with the
+// same configuration, waiting until it rejoined the cluster.
+func (s *server) Restart() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return errServerStopped
+	}
+	if s.e != nil {
+		s.e.GetLogger().Info("restarting etcd server")
+		s.e.Close()
+		s.e = nil
+	}
+
+	cfg := s.cfg
+	e, err := s.start(&cfg)
+	if err != nil {
+		err = fmt.Errorf("failed to restart etcd server: %v", err)
+		s.fail(err)
+		return err
+	}
+	s.e = e
+	go s.monitor(e)
+	return nil
+}
+
+// ReloadConfig reloads the configuration of the running server.
+func (s *server) ReloadConfig() error {
+	e := s.Etcd()
+	if e == nil {
+		return errServerStopped
+	}
+	return e.ReloadConfig()
+}
+
+// superviseServer blocks until s stopped or failed. If autoRestart is set,
+// failures such as listener errors or panics while serving restart s
+// in-process with an exponential backoff, instead of being returned.
+//
+// The backoff starts at autoRestartMinBackoff and doubles up to
+// autoRestartMaxBackoff. A failed restart counts as another failure, and
+// the error is returned after autoRestartMaxAttempts consecutive restarts.
+// Both the backoff and the attempt count are reset once a restarted server
+// has run for autoRestartResetAfter. Stopping s, for instance on SIGTERM,
+// is never followed by a restart, including while backing off.
+func superviseServer(lg *zap.Logger, s *server, autoRestart bool) error {
+	backoff, attempts := autoRestartMinBackoff, 0
+	var restartedAt time.Time
+	for {
+		var err error
+		select {
+		case err = <-s.Err():
+		case <-s.StopNotify():
+			return nil
+		}
+		if !autoRestart {
+			return err
+		}
+		if !restartedAt.IsZero() && time.Since(restartedAt) > autoRestartResetAfter {
+			backoff, attempts = autoRestartMinBackoff, 0
+		}
+		if attempts == autoRestartMaxAttempts {
+			return fmt.Errorf("giving up after %d restarts: %v", attempts, err)
+		}
+		attempts++
+		lg.Warn(
+			"etcd server failed; restarting",
+			zap.Error(err),
+			zap.Int("attempt", attempts),
+			zap.Duration("backoff", backoff),
+		)
+		select {
+		case <-time.After(backoff):
+		case <-s.StopNotify():
+			return nil
+		}
+		if backoff *= 2; backoff > autoRestartMaxBackoff {
+			backoff = autoRestartMaxBackoff
+		}
+		if rerr := s.Restart(); rerr == errServerStopped {
+			return nil
+		}
+		// a failed restart is reported on s.Err and retried
+		restartedAt = time.Now()
+	}
+}
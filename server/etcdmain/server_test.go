@@ -0,0 +1,143 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdmain
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/embed"
+
+	"go.uber.org/zap"
+)
+
+func freeURL(t *testing.T) url.URL {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return url.URL{Scheme: "http", Host: l.Addr().String()}
+}
+
+func newTestConfig(t *testing.T) *embed.Config {
+	curl, purl := freeURL(t), freeURL(t)
+	return embed.NewConfig(
+		embed.WithDataDir(filepath.Join(t.TempDir(), "default.etcd")),
+		embed.WithClientURLs([]url.URL{curl}),
+		embed.WithPeerURLs([]url.URL{purl}),
+		embed.WithLogLevel("error"),
+	)
+}
+
+func TestServerRestart(t *testing.T) {
+	s, err := newServer(newTestConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := s.Etcd()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err = first.Server.Put(ctx, &pb.PutRequest{Key: []byte("foo"), Value: []byte("bar")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = s.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	if s.Etcd() == first {
+		t.Fatal("expected a new server after restart")
+	}
+	resp, err := s.Etcd().Server.Range(ctx, &pb.RangeRequest{Key: []byte("foo")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Kvs) != 1 || string(resp.Kvs[0].Value) != "bar" {
+		t.Fatalf("unexpected range response after restart %+v", resp.Kvs)
+	}
+	select {
+	case <-s.StopNotify():
+		t.Fatal("restart must not stop the handle")
+	case err = <-s.Err():
+		t.Fatalf("unexpected error %v", err)
+	default:
+	}
+
+	s.Stop()
+	select {
+	case <-s.StopNotify():
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the server to stop")
+	}
+	if err = s.Restart(); err != errServerStopped {
+		t.Fatalf("restart after stop error = %v, want %v", err, errServerStopped)
+	}
+}
+
+func TestSuperviseServer(t *testing.T) {
+	s, err := newServer(newTestConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	errFailed := errors.New("listener failed")
+	s.fail(errFailed)
+	if err = superviseServer(zap.NewNop(), s, false); err != errFailed {
+		t.Fatalf("supervise error = %v, want %v", err, errFailed)
+	}
+}
+
+func TestSuperviseServerAutoRestart(t *testing.T) {
+	s, err := newServer(newTestConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := s.Etcd()
+
+	errc := make(chan error, 1)
+	go func() { errc <- superviseServer(zap.NewNop(), s, true) }()
+
+	s.fail(errors.New("listener failed"))
+	deadline := time.Now().Add(10 * time.Second)
+	for e := s.Etcd(); e == first || e == nil; e = s.Etcd() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the server to restart")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	select {
+	case <-s.Etcd().Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the restarted server")
+	}
+
+	s.Stop()
+	select {
+	case err = <-errc:
+		if err != nil {
+			t.Fatalf("unexpected supervise error %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for supervise to return")
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright 2018 The etcd Authors
+// Copyright 2022 The etcd Authors
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -15,22 +15,18 @@
 //go:build !windows
 // +build !windows
 
-package embed
+package etcdmain
 
-import (
-	"fmt"
-	"os"
+import "go.uber.org/zap"
 
-	"go.etcd.io/etcd/client/pkg/v3/logutil"
+// startWindowsService is a no-op on non-windows
+func startWindowsService() {}
 
-	"go.uber.org/zap/zapcore"
-)
+// registerServiceStop is a no-op on non-windows
+func registerServiceStop(func()) {}
 
-// use stderr as fallback
-func getJournalWriteSyncer() (zapcore.WriteSyncer, error) {
-	jw, err := logutil.NewJournalWriter(os.Stderr)
-	if err != nil {
-		return nil, fmt.Errorf("can't find journal (%v)", err)
-	}
-	return zapcore.AddSync(jw), nil
-}
+// notifyWindowsService is a no-op on non-windows
+func notifyWindowsService(*zap.Logger) {}
+
+// exitWindowsService is a no-op on non-windows
+func exitWindowsService(int) {}
@@ -0,0 +1,269 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+// +build windows
+
+package etcdmain
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+var (
+	serviceName        string
+	serviceDisplayName string
+	serviceTimeout     time.Duration
+)
+
+// windowsService reports the state of etcd to the service control manager
+// when etcd is started as a Windows service.
+type windowsService struct {
+	readyc chan struct{}
+	exitc  chan struct{}
+	donec  chan struct{}
+
+	mu   sync.Mutex
+	stop func()
+
+	exitOnce sync.Once
+	exitCode uint32
+}
+
+// service is set if etcd runs as a Windows service.
+var service *windowsService
+
+func init() {
+	rootCmd.AddCommand(newServiceCommand())
+}
+
+// newServiceCommand returns the cobra command for "service".
+func newServiceCommand() *cobra.Command {
+	sc := &cobra.Command{
+		Use:   "service <subcommand>",
+		Short: "Windows service related command",
+	}
+	sc.PersistentFlags().StringVar(&serviceName, "service-name", "etcd", "name of the Windows service")
+	sc.PersistentFlags().DurationVar(&serviceTimeout, "timeout", 30*time.Second, "time to wait for the service to start or stop")
+
+	install := &cobra.Command{
+		Use:   "install [-- etcd flags]",
+		Short: "install etcd as a Windows service started with the given etcd flags",
+		Run:   serviceCommandFunc(installService),
+	}
+	install.Flags().StringVar(&serviceDisplayName, "display-name", "etcd", "display name of the Windows service")
+
+	sc.AddCommand(
+		install,
+		&cobra.Command{Use: "uninstall", Short: "uninstall the Windows service", Run: serviceCommandFunc(uninstallService)},
+		&cobra.Command{Use: "start", Short: "start the Windows service", Run: serviceCommandFunc(startService)},
+		&cobra.Command{Use: "stop", Short: "stop the Windows service", Run: serviceCommandFunc(stopService)},
+	)
+	return sc
+}
+
+func serviceCommandFunc(f func(m *mgr.Mgr, args []string) error) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		m, err := mgr.Connect()
+		if err == nil {
+			err = f(m, args)
+			m.Disconnect()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %q failed: %v\n", cmd.Name(), serviceName, err)
+			os.Exit(1)
+		}
+	}
+}
+
+func installService(m *mgr.Mgr, args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if s, err := m.OpenService(serviceName); err == nil {
+		s.Close()
+		return fmt.Errorf("service already exists")
+	}
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: serviceDisplayName,
+		Description: "etcd distributed reliable key-value store",
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		return err
+	}
+	return s.Close()
+}
+
+func uninstallService(m *mgr.Mgr, _ []string) error {
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	return s.Delete()
+}
+
+func startService(m *mgr.Mgr, _ []string) error {
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	if err = s.Start(); err != nil {
+		return err
+	}
+	return waitServiceState(s, svc.Running)
+}
+
+func stopService(m *mgr.Mgr, _ []string) error {
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	if _, err = s.Control(svc.Stop); err != nil {
+		return err
+	}
+	return waitServiceState(s, svc.Stopped)
+}
+
+func waitServiceState(s *mgr.Service, want svc.State) error {
+	deadline := time.Now().Add(serviceTimeout)
+	for {
+		st, err := s.Query()
+		if err != nil {
+			return err
+		}
+		if st.State == want {
+			return nil
+		}
+		if st.State == svc.Stopped {
+			return fmt.Errorf("service stopped")
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %v waiting for the service", serviceTimeout)
+		}
+		time.Sleep(300 * time.Millisecond)
+	}
+}
+
+// startWindowsService connects to the service control manager if etcd was
+// started as a Windows service. The service is reported as starting until
+// notifyWindowsService is called.
+func startWindowsService() {
+	is, err := svc.IsWindowsService()
+	if err != nil || !is {
+		return
+	}
+	service = &windowsService{
+		readyc: make(chan struct{}),
+		exitc:  make(chan struct{}),
+		donec:  make(chan struct{}),
+	}
+	go func() {
+		if err := svc.Run(serviceName, service); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to run as Windows service: %v\n", err)
+			os.Exit(1)
+		}
+		close(service.donec)
+		select {
+		case <-service.exitc:
+		default:
+			// stopped before etcd was started
+			os.Exit(0)
+		}
+	}()
+}
+
+// registerServiceStop registers the function to stop etcd on a stop or
+// shutdown request of the service control manager.
+func registerServiceStop(stop func()) {
+	if service == nil {
+		return
+	}
+	service.mu.Lock()
+	service.stop = stop
+	service.mu.Unlock()
+}
+
+// notifyWindowsService reports the service as running.
+func notifyWindowsService(lg *zap.Logger) {
+	if service == nil {
+		return
+	}
+	lg.Info("notifying Windows service control manager")
+	close(service.readyc)
+}
+
+// exitWindowsService reports the service as stopped with the exit code of
+// etcd before etcd exits.
+func exitWindowsService(code int) {
+	if service == nil {
+		return
+	}
+	service.exit(code)
+}
+
+// exit stops Execute with code and waits for the service control manager
+// to be notified.
+func (s *windowsService) exit(code int) {
+	s.exitOnce.Do(func() {
+		s.exitCode = uint32(code)
+		close(s.exitc)
+	})
+	<-s.donec
+}
+
+// Execute implements svc.Handler.
+func (s *windowsService) Execute(_ []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	const accepts = svc.AcceptStop | svc.AcceptShutdown
+	changes <- svc.Status{State: svc.StartPending}
+	readyc := s.readyc
+	for {
+		select {
+		case <-readyc:
+			readyc = nil
+			changes <- svc.Status{State: svc.Running, Accepts: accepts}
+		case c := <-r:
+			switch c.Cmd {
+			case svc.Interrogate:
+				changes <- c.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				s.mu.Lock()
+				stop := s.stop
+				s.mu.Unlock()
+				if stop == nil {
+					// not started yet
+					return false, 0
+				}
+				go stop()
+			}
+		case <-s.exitc:
+			// a non-zero code is reported as service specific, to trigger
+			// the recovery actions of the service
+			return s.exitCode != 0, s.exitCode
+		}
+	}
+}
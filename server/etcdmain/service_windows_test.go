@@ -0,0 +1,121 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+// +build windows
+
+package etcdmain
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+type executeResult struct {
+	ssec  bool
+	errno uint32
+}
+
+// startExecute runs Execute of a new windowsService, like svc.Run does.
+func startExecute(t *testing.T) (*windowsService, chan<- svc.ChangeRequest, <-chan svc.Status, <-chan executeResult) {
+	s := &windowsService{
+		readyc: make(chan struct{}),
+		exitc:  make(chan struct{}),
+		donec:  make(chan struct{}),
+	}
+	r := make(chan svc.ChangeRequest)
+	changes := make(chan svc.Status, 10)
+	resc := make(chan executeResult, 1)
+	go func() {
+		ssec, errno := s.Execute(nil, r, changes)
+		resc <- executeResult{ssec, errno}
+		close(s.donec)
+	}()
+	expectState(t, changes, svc.StartPending)
+	return s, r, changes, resc
+}
+
+func expectState(t *testing.T, changes <-chan svc.Status, want svc.State) svc.Status {
+	t.Helper()
+	select {
+	case st := <-changes:
+		if st.State != want {
+			t.Fatalf("state = %v, want %v", st.State, want)
+		}
+		return st
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for state %v", want)
+	}
+	return svc.Status{}
+}
+
+func expectResult(t *testing.T, resc <-chan executeResult, want executeResult) {
+	t.Helper()
+	select {
+	case res := <-resc:
+		if res != want {
+			t.Fatalf("Execute returned %+v, want %+v", res, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for Execute to return")
+	}
+}
+
+func TestWindowsServiceStop(t *testing.T) {
+	for _, cmd := range []svc.Cmd{svc.Stop, svc.Shutdown} {
+		s, r, changes, resc := startExecute(t)
+		stopc := make(chan struct{})
+		s.stop = func() { close(stopc) }
+
+		close(s.readyc)
+		st := expectState(t, changes, svc.Running)
+		if st.Accepts != svc.AcceptStop|svc.AcceptShutdown {
+			t.Fatalf("accepts = %v, want stop and shutdown", st.Accepts)
+		}
+		r <- svc.ChangeRequest{Cmd: svc.Interrogate, CurrentStatus: st}
+		expectState(t, changes, svc.Running)
+
+		r <- svc.ChangeRequest{Cmd: cmd}
+		expectState(t, changes, svc.StopPending)
+		select {
+		case <-stopc:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%v did not stop etcd", cmd)
+		}
+
+		// etcd exits once stopped
+		s.exit(0)
+		expectResult(t, resc, executeResult{false, 0})
+	}
+}
+
+func TestWindowsServiceStopBeforeStart(t *testing.T) {
+	_, r, changes, resc := startExecute(t)
+	r <- svc.ChangeRequest{Cmd: svc.Stop}
+	expectState(t, changes, svc.StopPending)
+	expectResult(t, resc, executeResult{false, 0})
+}
+
+func TestWindowsServiceExitWithError(t *testing.T) {
+	s, _, changes, resc := startExecute(t)
+	close(s.readyc)
+	expectState(t, changes, svc.Running)
+
+	s.exit(1)
+	expectResult(t, resc, executeResult{true, 1})
+	// the code of the first exit is reported
+	s.exit(0)
+}
@@ -0,0 +1,165 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdmain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+	"go.etcd.io/etcd/server/v3/embed"
+	"go.etcd.io/etcd/server/v3/storage"
+)
+
+// validationCheck is the result of a single --validate-config check. A
+// check with an error fails the validation; a warning does not.
+type validationCheck struct {
+	Name    string `json:"name"`
+	Error   string `json:"error,omitempty"`
+	Warning string `json:"warning,omitempty"`
+}
+
+// validationReport is printed as JSON by --validate-config.
+type validationReport struct {
+	Valid  bool              `json:"valid"`
+	Checks []validationCheck `json:"checks"`
+}
+
+func (r *validationReport) add(name string, err error) {
+	c := validationCheck{Name: name}
+	if err != nil {
+		c.Error = err.Error()
+		r.Valid = false
+	}
+	r.Checks = append(r.Checks, c)
+}
+
+func (r *validationReport) warn(name string, warning string) {
+	r.Checks = append(r.Checks, validationCheck{Name: name, Warning: warning})
+}
+
+// validateConfig checks the configuration parsed with parseErr without
+// starting the server. The remaining checks are skipped if parsing failed.
+func validateConfig(cfg *embed.Config, parseErr error) *validationReport {
+	r := &validationReport{Valid: true}
+	r.add("config", parseErr)
+	if parseErr != nil {
+		return r
+	}
+
+	// the SVIDs of the SPIFFE Workload API are fetched on start, like the
+	// certificates generated by auto TLS
+	spiffe := cfg.SPIFFEWorkloadAPIAddr != ""
+	r.add("client-tls", validateTLS(cfg.LCUrls, cfg.ClientTLSInfo, cfg.ClientAutoTLS || spiffe, false))
+	r.add("peer-tls", validateTLS(cfg.LPUrls, cfg.PeerTLSInfo, cfg.PeerAutoTLS || spiffe, true))
+
+	switch {
+	case cfg.QuotaBackendBytes < 0:
+		r.warn("quota-backend-bytes", "negative quota disables the backend quota")
+	case cfg.QuotaBackendBytes > storage.MaxQuotaBytes:
+		r.warn("quota-backend-bytes", fmt.Sprintf("quota %d exceeds the maximum suggested quota %d", cfg.QuotaBackendBytes, storage.MaxQuotaBytes))
+	default:
+		r.add("quota-backend-bytes", nil)
+	}
+
+	dir := cfg.Dir
+	if dir == "" {
+		dir = fmt.Sprintf("%v.etcd", cfg.Name)
+	}
+	validateDir(r, "data-dir", dir)
+	if cfg.WalDir != "" {
+		validateDir(r, "wal-dir", cfg.WalDir)
+	}
+	return r
+}
+
+// validateTLS loads the TLS files used to serve urls, as etcd would on start.
+func validateTLS(urls []url.URL, info transport.TLSInfo, autoTLS bool, peer bool) error {
+	secure := false
+	for _, u := range urls {
+		if u.Scheme == "https" || u.Scheme == "unixs" {
+			secure = true
+		}
+	}
+	if info.Empty() {
+		if secure && !autoTLS && !peer {
+			return fmt.Errorf("TLS key/cert (--cert-file, --key-file) must be provided for client urls with HTTPS scheme")
+		}
+		return nil
+	}
+	if _, err := info.ServerConfig(); err != nil {
+		return err
+	}
+	if peer {
+		if _, err := info.ClientConfig(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateDir checks that dir, or the closest existing parent it would be
+// created in, is writable. Like on start, a directory with permissions
+// other than 0700 only yields a warning.
+func validateDir(r *validationReport, name, dir string) {
+	if fileutil.Exist(dir) {
+		if err := fileutil.IsDirWriteable(dir); err != nil {
+			r.add(name, err)
+			return
+		}
+		if err := fileutil.CheckDirPermission(dir, fileutil.PrivateDirMode); err != nil {
+			r.warn(name, err.Error())
+			return
+		}
+		r.add(name, nil)
+		return
+	}
+	parent := filepath.Dir(filepath.Clean(dir))
+	for !fileutil.Exist(parent) && parent != filepath.Dir(parent) {
+		parent = filepath.Dir(parent)
+	}
+	if err := fileutil.IsDirWriteable(parent); err != nil {
+		r.add(name, fmt.Errorf("cannot create %q: %w", dir, err))
+		return
+	}
+	r.add(name, nil)
+}
+
+// writeReport writes r to w as indented JSON.
+func writeReport(w io.Writer, r *validationReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// validateConfigAndExit prints the validation report as JSON and exits with
+// status 1 if the configuration is invalid.
+func validateConfigAndExit(cfg *embed.Config, parseErr error) {
+	r := validateConfig(cfg, parseErr)
+	if err := writeReport(os.Stdout, r); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write validation report: %v\n", err)
+		os.Exit(1)
+	}
+	if !r.Valid {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,123 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdmain
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateConfig(t *testing.T) {
+	dir := t.TempDir()
+	privateDir := filepath.Join(dir, "private")
+	if err := os.Mkdir(privateDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	publicDir := filepath.Join(dir, "public")
+	if err := os.Mkdir(publicDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		valid    bool
+		failed   string
+		warnings []string
+	}{
+		{
+			name:  "valid",
+			args:  []string{"--data-dir=" + privateDir},
+			valid: true,
+		},
+		{
+			name:  "data dir created on start",
+			args:  []string{"--data-dir=" + filepath.Join(dir, "new", "member")},
+			valid: true,
+		},
+		{
+			name:     "data dir permission",
+			args:     []string{"--data-dir=" + publicDir},
+			valid:    true,
+			warnings: []string{"data-dir"},
+		},
+		{
+			name:     "quota",
+			args:     []string{"--data-dir=" + privateDir, "--quota-backend-bytes=-1"},
+			valid:    true,
+			warnings: []string{"quota-backend-bytes"},
+		},
+		{
+			name:   "missing cert file",
+			args:   []string{"--data-dir=" + privateDir, "--cert-file=" + filepath.Join(dir, "missing.crt"), "--key-file=" + filepath.Join(dir, "missing.key")},
+			failed: "client-tls",
+		},
+		{
+			name: "https without cert",
+			args: []string{
+				"--data-dir=" + privateDir,
+				"--listen-client-urls=https://127.0.0.1:2379",
+				"--advertise-client-urls=https://127.0.0.1:2379",
+			},
+			failed: "client-tls",
+		},
+		{
+			name: "wal dir is a file",
+			args: []string{
+				"--data-dir=" + privateDir,
+				"--wal-dir=" + filepath.Join(publicDir, "file", "wal"),
+			},
+			failed: "wal-dir",
+		},
+	}
+	if err := os.WriteFile(filepath.Join(publicDir, "file"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newConfig()
+			err := cfg.parse(tt.args)
+			r := validateConfig(&cfg.ec, err)
+			if r.Valid != tt.valid {
+				t.Fatalf("valid = %v, want %v (%+v)", r.Valid, tt.valid, r.Checks)
+			}
+			var warnings []string
+			for _, c := range r.Checks {
+				if c.Warning != "" {
+					warnings = append(warnings, c.Name)
+				}
+				if (c.Error != "") != (c.Name == tt.failed) {
+					t.Errorf("check %q error = %q, want failed check %q", c.Name, c.Error, tt.failed)
+				}
+			}
+			if len(warnings) != len(tt.warnings) || (len(warnings) > 0 && warnings[0] != tt.warnings[0]) {
+				t.Errorf("warnings = %v, want %v", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestValidateConfigParseError(t *testing.T) {
+	r := validateConfig(&newConfig().ec, errors.New("invalid"))
+	if r.Valid {
+		t.Fatal("expected parse error to fail validation")
+	}
+	if len(r.Checks) != 1 || r.Checks[0].Name != "config" || r.Checks[0].Error != "invalid" {
+		t.Fatalf("unexpected checks %+v", r.Checks)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clusterhistory persists the history of the events of the cluster,
+// such as membership and leader changes, so that they can be queried after
+// the fact.
+package clusterhistory
+
+import (
+	"sync"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+// DefaultLimit is the default number of events kept in the history.
+const DefaultLimit = 1000
+
+// History persists the last events of the cluster to the backend. A nil
+// History is valid and drops every event.
+type History struct {
+	limit int
+
+	mu sync.Mutex
+	be backend.Backend
+	// events are the recorded events, oldest first, mirrored in the backend
+	// under their sequence numbers.
+	events []schema.ClusterEventRecord
+}
+
+// New returns a History keeping the last limit events of be, or
+// DefaultLimit events if limit is not positive.
+func New(be backend.Backend, limit int) *History {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	h := &History{limit: limit}
+	h.Recover(be)
+	return h
+}
+
+// Recover loads the history from be, which the events are persisted to
+// from now on.
+func (h *History) Recover(be backend.Backend) {
+	if h == nil {
+		return
+	}
+	tx := be.BatchTx()
+	tx.LockOutsideApply()
+	schema.UnsafeCreateClusterHistoryBucket(tx)
+	events := schema.MustUnsafeGetAllClusterEvents(tx)
+	tx.Unlock()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.be = be
+	h.events = events
+}
+
+// Record records ev, caused by the entry being applied.
+func (h *History) Record(ev *pb.ClusterEvent) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	tx := h.be.BatchTx()
+	tx.LockInsideApply()
+	defer tx.Unlock()
+	h.unsafeRecord(tx, ev)
+}
+
+// RecordOutsideApply records ev, observed by the member outside of the
+// apply of an entry.
+func (h *History) RecordOutsideApply(ev *pb.ClusterEvent) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	tx := h.be.BatchTx()
+	tx.LockOutsideApply()
+	defer tx.Unlock()
+	h.unsafeRecord(tx, ev)
+}
+
+func (h *History) unsafeRecord(tx backend.BatchTx, ev *pb.ClusterEvent) {
+	var seq uint64
+	if n := len(h.events); n > 0 {
+		seq = h.events[n-1].Seq + 1
+	}
+	schema.MustUnsafePutClusterEvent(tx, seq, ev)
+	h.events = append(h.events, schema.ClusterEventRecord{Seq: seq, Event: ev})
+	for len(h.events) > h.limit {
+		schema.UnsafeDeleteClusterEvent(tx, h.events[0].Seq)
+		h.events = h.events[1:]
+	}
+}
+
+// Events returns the last limit recorded events, oldest first, or every
+// event if limit is not positive.
+func (h *History) Events(limit int) []*pb.ClusterEvent {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	rs := h.events
+	if limit > 0 && len(rs) > limit {
+		rs = rs[len(rs)-limit:]
+	}
+	evs := make([]*pb.ClusterEvent, len(rs))
+	for i, r := range rs {
+		evs[i] = r.Event
+	}
+	return evs
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterhistory
+
+import (
+	"reflect"
+	"testing"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+func memberIDs(evs []*pb.ClusterEvent) []uint64 {
+	ids := make([]uint64, len(evs))
+	for i, ev := range evs {
+		ids[i] = ev.MemberID
+	}
+	return ids
+}
+
+func TestHistory(t *testing.T) {
+	be, tmpPath := betesting.NewDefaultTmpBackend(t)
+	h := New(be, 3)
+
+	h.Record(&pb.ClusterEvent{Type: pb.ClusterEvent_MEMBER_ADD, MemberID: 1})
+	h.RecordOutsideApply(&pb.ClusterEvent{Type: pb.ClusterEvent_LEADER_CHANGE, MemberID: 2})
+	if got := memberIDs(h.Events(0)); !reflect.DeepEqual(got, []uint64{1, 2}) {
+		t.Fatalf("expected events of members [1 2], got %v", got)
+	}
+
+	// the oldest events are dropped beyond the limit
+	h.Record(&pb.ClusterEvent{Type: pb.ClusterEvent_MEMBER_PROMOTE, MemberID: 3})
+	h.Record(&pb.ClusterEvent{Type: pb.ClusterEvent_MEMBER_REMOVE, MemberID: 4})
+	if got := memberIDs(h.Events(0)); !reflect.DeepEqual(got, []uint64{2, 3, 4}) {
+		t.Fatalf("expected events of members [2 3 4], got %v", got)
+	}
+	if got := memberIDs(h.Events(2)); !reflect.DeepEqual(got, []uint64{3, 4}) {
+		t.Fatalf("expected the last 2 events, got %v", got)
+	}
+
+	// the events are persisted
+	be.ForceCommit()
+	be.Close()
+	be = backend.NewDefaultBackend(zaptest.NewLogger(t), tmpPath)
+	defer betesting.Close(t, be)
+	h = New(be, 3)
+	if got := memberIDs(h.Events(0)); !reflect.DeepEqual(got, []uint64{2, 3, 4}) {
+		t.Fatalf("expected persisted events of members [2 3 4], got %v", got)
+	}
+	h.Record(&pb.ClusterEvent{Type: pb.ClusterEvent_ALARM_ACTIVATE, MemberID: 5})
+	if got := memberIDs(h.Events(0)); !reflect.DeepEqual(got, []uint64{3, 4, 5}) {
+		t.Fatalf("expected events of members [3 4 5], got %v", got)
+	}
+}
+
+func TestHistoryRecover(t *testing.T) {
+	be, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, be)
+	h := New(be, 0)
+	h.Record(&pb.ClusterEvent{MemberID: 1})
+
+	newbe, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, newbe)
+	New(newbe, 0).Record(&pb.ClusterEvent{MemberID: 2})
+
+	h.Recover(newbe)
+	if got := memberIDs(h.Events(0)); !reflect.DeepEqual(got, []uint64{2}) {
+		t.Fatalf("expected the events of the recovered backend, got %v", got)
+	}
+}
+
+func TestHistoryNil(t *testing.T) {
+	var h *History
+	h.Recover(nil)
+	h.Record(&pb.ClusterEvent{})
+	h.RecordOutsideApply(&pb.ClusterEvent{})
+	if evs := h.Events(0); evs != nil {
+		t.Errorf("expected no events, got %v", evs)
+	}
+}
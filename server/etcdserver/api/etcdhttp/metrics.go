@@ -17,6 +17,7 @@ package etcdhttp
 import (
 	"net/http"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -29,3 +30,13 @@ const (
 func HandleMetrics(mux *http.ServeMux) {
 	mux.Handle(PathMetrics, promhttp.Handler())
 }
+
+// HandleMetricsWithExemplars registers prometheus handler on '/metrics'
+// that also serves the OpenMetrics format to the scrapers accepting it,
+// since only that format carries the exemplars of the histograms.
+func HandleMetricsWithExemplars(mux *http.ServeMux) {
+	mux.Handle(PathMetrics, promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	))
+}
@@ -64,6 +64,7 @@ func newPeerHandler(
 	if leaseHandler != nil {
 		mux.Handle(leasehttp.LeasePrefix, leaseHandler)
 		mux.Handle(leasehttp.LeaseInternalPrefix, leaseHandler)
+		mux.Handle(leasehttp.LeaseBatchPrefix, leaseHandler)
 	}
 	if downgradeEnabledHandler != nil {
 		mux.Handle(etcdserver.DowngradeEnabledPath, downgradeEnabledHandler)
@@ -71,6 +71,16 @@ type ConfigChangeContext struct {
 	IsPromote bool `json:"isPromote"`
 }
 
+// ConfigChangeV2Context represents a context for a ConfChangeV2 adding and
+// removing several members at once through joint consensus.
+type ConfigChangeV2Context struct {
+	// ID identifies the request waiting for the change to be applied, as
+	// ConfChangeV2 has no ID of its own.
+	ID uint64 `json:"id"`
+	// Members are the members added by the change.
+	Members []Member `json:"members,omitempty"`
+}
+
 type ShouldApplyV3 bool
 
 const (
@@ -385,6 +395,87 @@ func (c *RaftCluster) ValidateConfigurationChange(cc raftpb.ConfChange) error {
 	return nil
 }
 
+// ValidateConfigurationChangeV2 takes a proposed ConfChangeV2 adding and
+// removing members, and ensures that it is still valid.
+func (c *RaftCluster) ValidateConfigurationChangeV2(cc raftpb.ConfChangeV2, ccc ConfigChangeV2Context) error {
+	if len(cc.Changes) == 0 {
+		return ErrNoMemberChange
+	}
+	membersMap, removedMap := membersFromStore(c.lg, c.v2store)
+	added := make(map[types.ID]*Member)
+	for i := range ccc.Members {
+		added[ccc.Members[i].ID] = &ccc.Members[i]
+	}
+
+	urls := make(map[string]bool)
+	for _, m := range membersMap {
+		for _, u := range m.PeerURLs {
+			urls[u] = true
+		}
+	}
+	changed := make(map[types.ID]bool)
+	for _, chg := range cc.Changes {
+		id := types.ID(chg.NodeID)
+		if changed[id] {
+			return ErrMemberChangedTwice
+		}
+		changed[id] = true
+		if removedMap[id] {
+			return ErrIDRemoved
+		}
+		switch chg.Type {
+		case raftpb.ConfChangeAddNode, raftpb.ConfChangeAddLearnerNode:
+			m := added[id]
+			if m == nil {
+				c.lg.Panic("got no member for the added member ID", zap.String("member-id", id.String()))
+			}
+			if membersMap[id] != nil {
+				return ErrIDExists
+			}
+			for _, u := range m.PeerURLs {
+				if urls[u] {
+					return ErrPeerURLexists
+				}
+				urls[u] = true
+			}
+		case raftpb.ConfChangeRemoveNode:
+			if membersMap[id] == nil {
+				return ErrIDNotFound
+			}
+		default:
+			c.lg.Panic("unexpected ConfChange type", zap.String("type", chg.Type.String()))
+		}
+	}
+
+	var members []*Member
+	for id, m := range membersMap {
+		if !changed[id] {
+			members = append(members, m)
+		}
+	}
+	nvoters := 0
+	for _, chg := range cc.Changes {
+		if m := added[types.ID(chg.NodeID)]; m != nil {
+			if m.IsLearner {
+				scaleUpLearners := true
+				if err := ValidateMaxLearnerConfig(c.maxLearners, members, scaleUpLearners); err != nil {
+					return err
+				}
+			}
+			members = append(members, m)
+		}
+	}
+	for _, m := range members {
+		if !m.IsLearner {
+			nvoters++
+		}
+	}
+	if nvoters == 0 {
+		return ErrNoVotingMember
+	}
+	return nil
+}
+
 // AddMember adds a new Member into the cluster, and saves the given member's
 // raftAttributes into the store. The given member should have empty attributes.
 // A Member with a matching id must not exist.
@@ -660,6 +751,52 @@ func (c *RaftCluster) IsReadyToPromoteMember(id uint64) bool {
 	return true
 }
 
+// IsReadyToReplaceMembers returns if the started voting members left once the
+// members are replaced are a quorum of both the current and the new voting
+// members, as joint consensus requires.
+func (c *RaftCluster) IsReadyToReplaceMembers(add []Member, remove []types.ID) bool {
+	removed := make(map[types.ID]bool)
+	for _, id := range remove {
+		removed[id] = true
+	}
+	nmembers, nstarted := 0, 0
+	nmembersNew, nstartedNew := 0, 0
+	for _, member := range c.VotingMembers() {
+		nmembers++
+		if member.IsStarted() {
+			nstarted++
+		}
+		if removed[member.ID] {
+			continue
+		}
+		nmembersNew++
+		if member.IsStarted() {
+			nstartedNew++
+		}
+	}
+	for _, m := range add {
+		if !m.IsLearner {
+			nmembersNew++
+		}
+	}
+
+	nquorum, nquorumNew := nmembers/2+1, nmembersNew/2+1
+	if nstarted < nquorum || nstartedNew < nquorumNew {
+		c.lg.Warn(
+			"rejecting member replace; started member will be less than quorum",
+			zap.Int("number-of-started-member", nstarted),
+			zap.Int("quorum", nquorum),
+			zap.Int("number-of-started-member-after-replace", nstartedNew),
+			zap.Int("quorum-after-replace", nquorumNew),
+			zap.String("cluster-id", c.cid.String()),
+			zap.String("local-member-id", c.localID.String()),
+		)
+		return false
+	}
+
+	return true
+}
+
 func membersFromStore(lg *zap.Logger, st v2store.Store) (map[types.ID]*Member, map[types.ID]bool) {
 	members := make(map[types.ID]*Member)
 	removed := make(map[types.ID]bool)
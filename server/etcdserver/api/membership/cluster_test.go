@@ -461,6 +461,55 @@ func TestClusterValidateConfigurationChange(t *testing.T) {
 	}
 }
 
+func TestClusterValidateConfigurationChangeV2(t *testing.T) {
+	cl := NewCluster(zaptest.NewLogger(t), WithMaxLearners(1))
+	cl.SetStore(v2store.New())
+	for i := 1; i <= 4; i++ {
+		attr := RaftAttributes{PeerURLs: []string{fmt.Sprintf("http://127.0.0.1:%d", i)}, IsLearner: i == 1}
+		cl.AddMember(&Member{ID: types.ID(i), RaftAttributes: attr}, true)
+	}
+	cl.RemoveMember(4, true)
+
+	member := func(id, port int, isLearner bool) Member {
+		return Member{ID: types.ID(id), RaftAttributes: RaftAttributes{PeerURLs: []string{fmt.Sprintf("http://127.0.0.1:%d", port)}, IsLearner: isLearner}}
+	}
+	tests := []struct {
+		add    []Member
+		remove []uint64
+		werr   error
+	}{
+		{nil, nil, ErrNoMemberChange},
+		{[]Member{member(5, 5, false)}, []uint64{2}, nil},
+		{[]Member{member(5, 5, false)}, []uint64{2, 3}, nil},
+		{[]Member{member(6, 6, true)}, []uint64{1}, nil},
+		{[]Member{member(5, 5, false), member(6, 6, true)}, nil, ErrTooManyLearners},
+		{[]Member{member(5, 1, false)}, nil, ErrPeerURLexists},
+		{[]Member{member(5, 5, false), member(6, 5, false)}, nil, ErrPeerURLexists},
+		{[]Member{member(4, 5, false)}, nil, ErrIDRemoved},
+		{[]Member{member(2, 5, false)}, nil, ErrIDExists},
+		{nil, []uint64{7}, ErrIDNotFound},
+		{nil, []uint64{2, 2}, ErrMemberChangedTwice},
+		{nil, []uint64{2, 3}, ErrNoVotingMember},
+	}
+	for i, tt := range tests {
+		var cc raftpb.ConfChangeV2
+		for _, m := range tt.add {
+			typ := raftpb.ConfChangeAddNode
+			if m.IsLearner {
+				typ = raftpb.ConfChangeAddLearnerNode
+			}
+			cc.Changes = append(cc.Changes, raftpb.ConfChangeSingle{Type: typ, NodeID: uint64(m.ID)})
+		}
+		for _, id := range tt.remove {
+			cc.Changes = append(cc.Changes, raftpb.ConfChangeSingle{Type: raftpb.ConfChangeRemoveNode, NodeID: id})
+		}
+		err := cl.ValidateConfigurationChangeV2(cc, ConfigChangeV2Context{Members: tt.add})
+		if err != tt.werr {
+			t.Errorf("#%d: ValidateConfigurationChangeV2 error = %v, want %v", i, err, tt.werr)
+		}
+	}
+}
+
 func TestClusterGenID(t *testing.T) {
 	cs := newTestCluster(t, []*Member{
 		newTestMember(1, nil, "", nil),
@@ -975,3 +1024,86 @@ func TestIsReadyToPromoteMember(t *testing.T) {
 		}
 	}
 }
+
+func TestIsReadyToReplaceMembers(t *testing.T) {
+	tests := []struct {
+		members []*Member
+		add     []Member
+		remove  []types.ID
+		want    bool
+	}{
+		{
+			// 3/3 members ready, replacing one member by a new one should be fine
+			[]*Member{
+				newTestMember(1, nil, "1", nil),
+				newTestMember(2, nil, "2", nil),
+				newTestMember(3, nil, "3", nil),
+			},
+			[]Member{*newTestMember(4, nil, "", nil)},
+			[]types.ID{3},
+			true,
+		},
+		{
+			// 3/3 members ready, replacing two members by new ones should fail
+			// (1 started member out of 3 in the new configuration)
+			[]*Member{
+				newTestMember(1, nil, "1", nil),
+				newTestMember(2, nil, "2", nil),
+				newTestMember(3, nil, "3", nil),
+			},
+			[]Member{*newTestMember(4, nil, "", nil), *newTestMember(5, nil, "", nil)},
+			[]types.ID{2, 3},
+			false,
+		},
+		{
+			// 3/3 members ready, replacing two members by new learners should be fine
+			[]*Member{
+				newTestMember(1, nil, "1", nil),
+				newTestMember(2, nil, "2", nil),
+				newTestMember(3, nil, "3", nil),
+			},
+			[]Member{*newTestMemberAsLearner(4, nil, "", nil), *newTestMemberAsLearner(5, nil, "", nil)},
+			[]types.ID{2, 3},
+			true,
+		},
+		{
+			// 2/3 members ready, replacing a started member should fail
+			[]*Member{
+				newTestMember(1, nil, "1", nil),
+				newTestMember(2, nil, "2", nil),
+				newTestMember(3, nil, "", nil),
+			},
+			[]Member{*newTestMember(4, nil, "", nil)},
+			[]types.ID{2},
+			false,
+		},
+		{
+			// 2/3 members ready, replacing the unstarted member should be fine
+			[]*Member{
+				newTestMember(1, nil, "1", nil),
+				newTestMember(2, nil, "2", nil),
+				newTestMember(3, nil, "", nil),
+			},
+			[]Member{*newTestMember(4, nil, "", nil)},
+			[]types.ID{3},
+			true,
+		},
+		{
+			// 1/3 members ready, should fail
+			[]*Member{
+				newTestMember(1, nil, "1", nil),
+				newTestMember(2, nil, "", nil),
+				newTestMember(3, nil, "", nil),
+			},
+			nil,
+			[]types.ID{3},
+			false,
+		},
+	}
+	for i, tt := range tests {
+		c := newTestCluster(t, tt.members)
+		if got := c.IsReadyToReplaceMembers(tt.add, tt.remove); got != tt.want {
+			t.Errorf("%d: IsReadyToReplaceMembers returned %t, want %t", i, got, tt.want)
+		}
+	}
+}
@@ -21,12 +21,15 @@ import (
 )
 
 var (
-	ErrIDRemoved        = errors.New("membership: ID removed")
-	ErrIDExists         = errors.New("membership: ID exists")
-	ErrIDNotFound       = errors.New("membership: ID not found")
-	ErrPeerURLexists    = errors.New("membership: peerURL exists")
-	ErrMemberNotLearner = errors.New("membership: can only promote a learner member")
-	ErrTooManyLearners  = errors.New("membership: too many learner members in cluster")
+	ErrIDRemoved          = errors.New("membership: ID removed")
+	ErrIDExists           = errors.New("membership: ID exists")
+	ErrIDNotFound         = errors.New("membership: ID not found")
+	ErrPeerURLexists      = errors.New("membership: peerURL exists")
+	ErrMemberNotLearner   = errors.New("membership: can only promote a learner member")
+	ErrTooManyLearners    = errors.New("membership: too many learner members in cluster")
+	ErrNoMemberChange     = errors.New("membership: no member added or removed")
+	ErrMemberChangedTwice = errors.New("membership: member added or removed more than once")
+	ErrNoVotingMember     = errors.New("membership: no voting member left")
 )
 
 func isKeyNotFound(err error) bool {
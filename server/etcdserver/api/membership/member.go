@@ -39,6 +39,39 @@ type RaftAttributes struct {
 type Attributes struct {
 	Name       string   `json:"name,omitempty"`
 	ClientURLs []string `json:"clientURLs,omitempty"`
+	// ElectionPriority is the priority of the member to be the leader.
+	ElectionPriority int64 `json:"electionPriority,omitempty"`
+	// Labels are the labels of the member, as its zone.
+	Labels map[string]string `json:"labels,omitempty"`
+	// WALDurability is the durability of the raft log writes of the member
+	// if relaxed, empty if fsynced on every write.
+	WALDurability string `json:"walDurability,omitempty"`
+}
+
+// ZoneLabel is the label of the zone of a member.
+const ZoneLabel = "zone"
+
+// Zone returns the zone of the member, from its labels.
+func (a Attributes) Zone() string { return a.Labels[ZoneLabel] }
+
+// ParseLabels parses comma separated key=value labels, e.g.
+// "zone=us-east-1a,disk=ssd".
+func ParseLabels(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	labels := make(map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		i := strings.Index(kv, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid label %q (expected key=value)", kv)
+		}
+		if _, ok := labels[kv[:i]]; ok {
+			return nil, fmt.Errorf("duplicate label %q", kv[:i])
+		}
+		labels[kv[:i]] = kv[i+1:]
+	}
+	return labels, nil
 }
 
 type Member struct {
@@ -107,7 +140,9 @@ func (m *Member) Clone() *Member {
 			IsLearner: m.IsLearner,
 		},
 		Attributes: Attributes{
-			Name: m.Name,
+			Name:             m.Name,
+			ElectionPriority: m.ElectionPriority,
+			WALDurability:    m.WALDurability,
 		},
 	}
 	if m.PeerURLs != nil {
@@ -118,6 +153,12 @@ func (m *Member) Clone() *Member {
 		mm.ClientURLs = make([]string, len(m.ClientURLs))
 		copy(mm.ClientURLs, m.ClientURLs)
 	}
+	if m.Labels != nil {
+		mm.Labels = make(map[string]string, len(m.Labels))
+		for k, v := range m.Labels {
+			mm.Labels[k] = v
+		}
+	}
 	return mm
 }
 
@@ -94,6 +94,9 @@ func TestMemberClone(t *testing.T) {
 		newTestMember(1, []string{"http://a"}, "abc", nil),
 		newTestMember(1, nil, "abc", []string{"http://b"}),
 		newTestMember(1, []string{"http://a"}, "abc", []string{"http://b"}),
+		{ID: 1, Attributes: Attributes{Name: "abc", ElectionPriority: 10}},
+		{ID: 1, Attributes: Attributes{Name: "abc", Labels: map[string]string{ZoneLabel: "us-east-1a"}}},
+		{ID: 1, Attributes: Attributes{Name: "abc", WALDurability: "batched"}},
 	}
 	for i, tt := range tests {
 		nm := tt.Clone()
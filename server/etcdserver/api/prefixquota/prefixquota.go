@@ -0,0 +1,345 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package prefixquota enforces quotas on the total size and number of the
+// keys under key prefixes, so that a tenant of a cluster cannot exhaust the
+// backend space quota of the others.
+package prefixquota
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+// scanLimit is the number of keys read at once when computing the usage of
+// a prefix.
+const scanLimit = 1000
+
+// Store keeps the quotas of the key prefixes and their usage. The quotas are
+// persisted to the backend, their usage is computed from the keys when they
+// are recovered and kept up to date from the writes. The size of a key is
+// the length of the key and its value. A nil Store has no quota.
+type Store struct {
+	mu sync.RWMutex
+	be backend.Backend
+	// quotas are the quotas of the prefixes with their usage.
+	quotas map[string]*pb.PrefixQuota
+	// sizes are the sizes of the keys under at least one of the prefixes.
+	sizes map[string]int64
+}
+
+// New returns a Store without quota, until it is recovered.
+func New() *Store {
+	return &Store{quotas: make(map[string]*pb.PrefixQuota), sizes: make(map[string]int64)}
+}
+
+// Recover loads the quotas from be, which they are persisted to from now on,
+// and computes their usage from the keys of rv.
+func (s *Store) Recover(be backend.Backend, rv mvcc.ReadView) {
+	if s == nil {
+		return
+	}
+	tx := be.BatchTx()
+	tx.LockOutsideApply()
+	schema.UnsafeCreatePrefixQuotaBucket(tx)
+	stored := schema.MustUnsafeGetAllPrefixQuotas(tx)
+	tx.Unlock()
+
+	quotas := make(map[string]*pb.PrefixQuota, len(stored))
+	sizes := make(map[string]int64)
+	for _, q := range stored {
+		q.UsedBytes, q.UsedKeys = usage(rv, q.Prefix, sizes)
+		quotas[string(q.Prefix)] = q
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.be = be
+	s.quotas = quotas
+	s.sizes = sizes
+}
+
+// Set sets the quota of the prefix of r, computing its usage from the keys
+// of rv if the prefix had no quota.
+func (s *Store) Set(rv mvcc.ReadView, r *pb.PrefixQuotaSetRequest) {
+	if s == nil {
+		return
+	}
+	s.mu.RLock()
+	q, ok := s.quotas[string(r.Prefix)]
+	s.mu.RUnlock()
+	// only the applied writes change the usage, so it cannot change meanwhile
+	var sizes map[string]int64
+	if !ok {
+		sizes = make(map[string]int64)
+		q = &pb.PrefixQuota{Prefix: r.Prefix}
+		q.UsedBytes, q.UsedKeys = usage(rv, r.Prefix, sizes)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q.MaxBytes, q.MaxKeys = r.MaxBytes, r.MaxKeys
+	s.quotas[string(q.Prefix)] = q
+	for k, size := range sizes {
+		s.sizes[k] = size
+	}
+	tx := s.be.BatchTx()
+	tx.LockInsideApply()
+	defer tx.Unlock()
+	schema.MustUnsafePutPrefixQuota(tx, q)
+}
+
+// Delete deletes the quota of prefix.
+func (s *Store) Delete(prefix []byte) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.quotas[string(prefix)]; !ok {
+		return
+	}
+	delete(s.quotas, string(prefix))
+	for k := range s.sizes {
+		if len(s.covering([]byte(k))) == 0 {
+			delete(s.sizes, k)
+		}
+	}
+	tx := s.be.BatchTx()
+	tx.LockInsideApply()
+	defer tx.Unlock()
+	schema.UnsafeDeletePrefixQuota(tx, prefix)
+}
+
+// List returns the quotas with their usage, sorted by prefix.
+func (s *Store) List() []*pb.PrefixQuota {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	qs := make([]*pb.PrefixQuota, 0, len(s.quotas))
+	for _, q := range s.quotas {
+		c := *q
+		qs = append(qs, &c)
+	}
+	sort.Slice(qs, func(i, j int) bool { return bytes.Compare(qs[i].Prefix, qs[j].Prefix) < 0 })
+	return qs
+}
+
+// Observe updates the usage of the quotas from the changes of a write txn.
+// It is meant to be the mvcc.StoreConfig.OnWrite hook.
+func (s *Store) Observe(changes []mvccpb.KeyValue) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.quotas) == 0 {
+		return
+	}
+	for _, kv := range changes {
+		qs := s.covering(kv.Key)
+		if len(qs) == 0 {
+			continue
+		}
+		old, existed := s.sizes[string(kv.Key)]
+		var size, keys int64
+		// the changes of the deleted keys have no create revision
+		if kv.CreateRevision != 0 {
+			size = keySize(kv.Key, kv.Value)
+			s.sizes[string(kv.Key)] = size
+			keys++
+		} else {
+			delete(s.sizes, string(kv.Key))
+		}
+		if existed {
+			keys--
+		}
+		for _, q := range qs {
+			q.UsedBytes += size - old
+			q.UsedKeys += keys
+		}
+	}
+}
+
+// ObserveDeleteRange updates the usage of the quotas from a range tombstone
+// deleting the keys in [key, end). It is meant to be the
+// mvcc.StoreConfig.OnDeleteRange hook.
+func (s *Store) ObserveDeleteRange(key, end []byte) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, size := range s.sizes {
+		if k < string(key) || (len(end) != 0 && k >= string(end)) {
+			continue
+		}
+		delete(s.sizes, k)
+		for _, q := range s.covering([]byte(k)) {
+			q.UsedBytes -= size
+			q.UsedKeys--
+		}
+	}
+}
+
+// Available returns whether the puts of req, a put or txn request, fit in
+// the quotas of their prefixes. The puts of either branch of a txn must fit
+// since which one is taken is only known when it is applied. Overwriting a
+// key with a value no larger is always allowed, as is deleting keys.
+func (s *Store) Available(req interface{}) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.quotas) == 0 {
+		return true
+	}
+	var ds deltas
+	switch r := req.(type) {
+	case *pb.PutRequest:
+		ds = make(deltas)
+		s.addPut(ds, r)
+	case *pb.TxnRequest:
+		ds = s.txnDeltas(r)
+	}
+	for q, d := range ds {
+		if q.MaxBytes > 0 && d.bytes > 0 && q.UsedBytes+d.bytes > q.MaxBytes {
+			return false
+		}
+		if q.MaxKeys > 0 && d.keys > 0 && q.UsedKeys+d.keys > q.MaxKeys {
+			return false
+		}
+	}
+	return true
+}
+
+// delta is the change of the usage of a quota.
+type delta struct {
+	bytes, keys int64
+}
+
+type deltas map[*pb.PrefixQuota]delta
+
+func (s *Store) txnDeltas(r *pb.TxnRequest) deltas {
+	ds := s.opsDeltas(r.Success)
+	for q, fd := range s.opsDeltas(r.Failure) {
+		d := ds[q]
+		if fd.bytes > d.bytes {
+			d.bytes = fd.bytes
+		}
+		if fd.keys > d.keys {
+			d.keys = fd.keys
+		}
+		ds[q] = d
+	}
+	return ds
+}
+
+func (s *Store) opsDeltas(ops []*pb.RequestOp) deltas {
+	ds := make(deltas)
+	for _, op := range ops {
+		switch tv := op.Request.(type) {
+		case *pb.RequestOp_RequestPut:
+			s.addPut(ds, tv.RequestPut)
+		case *pb.RequestOp_RequestTxn:
+			for q, td := range s.txnDeltas(tv.RequestTxn) {
+				d := ds[q]
+				d.bytes += td.bytes
+				d.keys += td.keys
+				ds[q] = d
+			}
+		}
+	}
+	return ds
+}
+
+func (s *Store) addPut(ds deltas, r *pb.PutRequest) {
+	if r.IgnoreValue {
+		// the key keeps its size, or the put fails if it does not exist
+		return
+	}
+	old, existed := s.sizes[string(r.Key)]
+	for _, q := range s.covering(r.Key) {
+		d := ds[q]
+		d.bytes += keySize(r.Key, r.Value) - old
+		if !existed {
+			d.keys++
+		}
+		ds[q] = d
+	}
+}
+
+// covering returns the quotas of the prefixes of key.
+func (s *Store) covering(key []byte) []*pb.PrefixQuota {
+	var qs []*pb.PrefixQuota
+	for _, q := range s.quotas {
+		if bytes.HasPrefix(key, q.Prefix) {
+			qs = append(qs, q)
+		}
+	}
+	return qs
+}
+
+// usage returns the total size and number of the keys of rv under prefix,
+// recording their sizes to sizes.
+func usage(rv mvcc.ReadView, prefix []byte, sizes map[string]int64) (used, keys int64) {
+	end := prefixEnd(prefix)
+	var rev int64
+	for key := prefix; ; {
+		r, err := rv.Range(context.TODO(), key, end, mvcc.RangeOptions{Limit: scanLimit, Rev: rev})
+		if err != nil {
+			panic(err)
+		}
+		rev = r.Rev
+		for _, kv := range r.KVs {
+			size := keySize(kv.Key, kv.Value)
+			sizes[string(kv.Key)] = size
+			used += size
+			keys++
+		}
+		if len(r.KVs) < scanLimit {
+			return used, keys
+		}
+		key = append(append([]byte{}, r.KVs[len(r.KVs)-1].Key...), 0)
+	}
+}
+
+// prefixEnd returns the end of the range of the keys prefixed by prefix.
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// the prefix is all 0xff, range to the last key
+	return []byte{}
+}
+
+func keySize(key, value []byte) int64 {
+	return int64(len(key) + len(value))
+}
@@ -0,0 +1,133 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prefixquota
+
+import (
+	"reflect"
+	"testing"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestStore(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, b)
+	s := New()
+	kv := mvcc.NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, mvcc.StoreConfig{OnWrite: s.Observe})
+	defer kv.Close()
+	s.Recover(b, kv)
+
+	kv.Put([]byte("a/1"), []byte("xx"), lease.NoLease)
+	s.Set(kv, &pb.PrefixQuotaSetRequest{Prefix: []byte("a/"), MaxBytes: 20, MaxKeys: 3})
+	checkUsage(t, s, []*pb.PrefixQuota{{Prefix: []byte("a/"), MaxBytes: 20, MaxKeys: 3, UsedBytes: 5, UsedKeys: 1}})
+
+	put := func(key, value string) *pb.PutRequest { return &pb.PutRequest{Key: []byte(key), Value: []byte(value)} }
+	if !s.Available(put("a/2", "0123456789")) {
+		t.Fatal("put within the quota is not available")
+	}
+	kv.Put([]byte("a/2"), []byte("0123456789"), lease.NoLease)
+	checkUsage(t, s, []*pb.PrefixQuota{{Prefix: []byte("a/"), MaxBytes: 20, MaxKeys: 3, UsedBytes: 18, UsedKeys: 2}})
+
+	tests := []struct {
+		name string
+		req  interface{}
+
+		wavailable bool
+	}{
+		{"exceeding put", put("a/3", "x"), false},
+		{"put of another prefix", put("b/1", "0123456789012345678901234567890"), true},
+		{"overwrite no larger", put("a/2", "01234"), true},
+		{"overwrite larger", put("a/2", "0123456789012"), false},
+		{"put ignoring the value", &pb.PutRequest{Key: []byte("a/2"), IgnoreValue: true}, true},
+		{"exceeding txn", &pb.TxnRequest{Failure: []*pb.RequestOp{{Request: &pb.RequestOp_RequestPut{RequestPut: put("a/3", "x")}}}}, false},
+		{"exceeding nested txn", &pb.TxnRequest{Success: []*pb.RequestOp{{Request: &pb.RequestOp_RequestTxn{RequestTxn: &pb.TxnRequest{
+			Success: []*pb.RequestOp{{Request: &pb.RequestOp_RequestPut{RequestPut: put("a/3", "x")}}},
+		}}}}}, false},
+		{"txn of another prefix", &pb.TxnRequest{Success: []*pb.RequestOp{{Request: &pb.RequestOp_RequestPut{RequestPut: put("b/1", "x")}}}}, true},
+	}
+	for _, tt := range tests {
+		if available := s.Available(tt.req); available != tt.wavailable {
+			t.Errorf("%s: available = %v, want %v", tt.name, available, tt.wavailable)
+		}
+	}
+
+	kv.DeleteRange([]byte("a/1"), nil)
+	kv.Put([]byte("b/1"), []byte("x"), lease.NoLease)
+	want := []*pb.PrefixQuota{{Prefix: []byte("a/"), MaxBytes: 20, MaxKeys: 3, UsedBytes: 13, UsedKeys: 1}}
+	checkUsage(t, s, want)
+
+	// the quotas are persisted and their usage computed again
+	s2 := New()
+	s2.Recover(b, kv)
+	checkUsage(t, s2, want)
+
+	s.Delete([]byte("a/"))
+	checkUsage(t, s, []*pb.PrefixQuota{})
+	if !s.Available(put("a/3", "0123456789012345678901234567890")) {
+		t.Error("put is not available after deleting the quota")
+	}
+	s2.Recover(b, kv)
+	checkUsage(t, s2, []*pb.PrefixQuota{})
+}
+
+func TestStoreRangeTombstone(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, b)
+	s := New()
+	kv := mvcc.NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, mvcc.StoreConfig{
+		OnWrite:                 s.Observe,
+		OnDeleteRange:           s.ObserveDeleteRange,
+		RangeTombstoneThreshold: 2,
+	})
+	defer kv.Close()
+	s.Recover(b, kv)
+
+	kv.Put([]byte("a/1"), []byte("x"), lease.NoLease)
+	kv.Put([]byte("a/2"), []byte("x"), lease.NoLease)
+	kv.Put([]byte("a/3"), []byte("x"), lease.NoLease)
+	s.Set(kv, &pb.PrefixQuotaSetRequest{Prefix: []byte("a/"), MaxKeys: 3})
+	checkUsage(t, s, []*pb.PrefixQuota{{Prefix: []byte("a/"), MaxKeys: 3, UsedBytes: 12, UsedKeys: 3}})
+
+	if n, _ := kv.DeleteRange([]byte("a/1"), []byte("a/3")); n != 2 {
+		t.Fatalf("n = %d, want 2", n)
+	}
+	checkUsage(t, s, []*pb.PrefixQuota{{Prefix: []byte("a/"), MaxKeys: 3, UsedBytes: 4, UsedKeys: 1}})
+}
+
+func checkUsage(t *testing.T, s *Store, want []*pb.PrefixQuota) {
+	t.Helper()
+	if got := s.List(); !reflect.DeepEqual(got, want) {
+		t.Errorf("quotas = %+v, want %+v", got, want)
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	tests := []struct {
+		prefix, wend string
+	}{
+		{"a", "b"},
+		{"a\xff", "b"},
+		{"\xff\xff", ""},
+	}
+	for _, tt := range tests {
+		if end := string(prefixEnd([]byte(tt.prefix))); end != tt.wend {
+			t.Errorf("prefixEnd(%q) = %q, want %q", tt.prefix, end, tt.wend)
+		}
+	}
+}
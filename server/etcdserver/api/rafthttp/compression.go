@@ -0,0 +1,201 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rafthttp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.etcd.io/etcd/client/pkg/v3/types"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// CompressionNone sends the messages as is.
+	CompressionNone = "none"
+	// CompressionZstd sends the streams and the snapshots compressed with
+	// zstd to the peers accepting it.
+	CompressionZstd = "zstd"
+
+	// The stream readers list the compressions they accept in the
+	// acceptCompressionHeader of their requests; the stream writers set the
+	// compression of the stream in the compressionHeader of their responses.
+	acceptCompressionHeader = "X-Raft-Accept-Compression"
+	compressionHeader       = "X-Raft-Compression"
+)
+
+// PeerCompression is the compression of the messages sent to the peers.
+// The zero value sends them uncompressed.
+type PeerCompression struct {
+	// Default is the compression of the messages sent to the peers not in Peers.
+	Default string
+	// Peers overrides the compression by peer.
+	Peers map[types.ID]string
+}
+
+// ParsePeerCompression parses the comma separated compression of the
+// messages sent to the peers, and the compressions by peer as
+// <member-id>=<compression>, e.g. "zstd" or "none,8211f1d0f64f3269=zstd".
+func ParsePeerCompression(s string) (PeerCompression, error) {
+	var pc PeerCompression
+	if s == "" {
+		return pc, nil
+	}
+	for _, c := range strings.Split(s, ",") {
+		i := strings.Index(c, "=")
+		if i < 0 {
+			if pc.Default != "" {
+				return pc, fmt.Errorf("duplicate default compression %q", c)
+			}
+			if err := validCompression(c); err != nil {
+				return pc, err
+			}
+			pc.Default = c
+			continue
+		}
+		id, err := types.IDFromString(c[:i])
+		if err != nil {
+			return pc, fmt.Errorf("invalid member ID %q (%v)", c[:i], err)
+		}
+		if err := validCompression(c[i+1:]); err != nil {
+			return pc, err
+		}
+		if pc.Peers == nil {
+			pc.Peers = make(map[types.ID]string)
+		}
+		if _, ok := pc.Peers[id]; ok {
+			return pc, fmt.Errorf("duplicate compression of member %s", id)
+		}
+		pc.Peers[id] = c[i+1:]
+	}
+	return pc, nil
+}
+
+func validCompression(c string) error {
+	switch c {
+	case CompressionNone, CompressionZstd:
+		return nil
+	}
+	return fmt.Errorf("unknown peer compression %q (expected %q or %q)", c, CompressionNone, CompressionZstd)
+}
+
+// compression returns the compression of the messages sent to the peer id.
+func (pc PeerCompression) compression(id types.ID) string {
+	if c, ok := pc.Peers[id]; ok {
+		return c
+	}
+	if pc.Default == "" {
+		return CompressionNone
+	}
+	return pc.Default
+}
+
+// acceptsZstd returns true if the header lists zstd in the compressions accepted.
+func acceptsZstd(h http.Header) bool {
+	for _, c := range strings.Split(h.Get(acceptCompressionHeader), ",") {
+		if strings.TrimSpace(c) == CompressionZstd {
+			return true
+		}
+	}
+	return false
+}
+
+func newZstdWriter(w io.Writer) *zstd.Encoder {
+	// never fails with these options; a single goroutine encodes the
+	// stream, so that the flushed messages are written out at once.
+	enc, _ := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	return enc
+}
+
+// zstdReadCloser decompresses the stream read from the closer.
+type zstdReadCloser struct {
+	*zstd.Decoder
+	closer io.Closer
+}
+
+func newZstdReadCloser(rc io.ReadCloser) (io.ReadCloser, error) {
+	// a single goroutine decodes the stream, so that the messages flushed
+	// are read without waiting for the next ones.
+	dec, err := zstd.NewReader(rc, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(uint64(snapshotLimitByte)))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdReadCloser{Decoder: dec, closer: rc}, nil
+}
+
+// Close closes the stream, unblocking a concurrent Read; decoding
+// synchronously, the decoder holds no other resource to release.
+func (r *zstdReadCloser) Close() error { return r.closer.Close() }
+
+// zstdFlusher flushes the compressed stream, then the underlying one.
+type zstdFlusher struct {
+	enc *zstd.Encoder
+	f   http.Flusher
+}
+
+func (f *zstdFlusher) Flush() {
+	// a failure is surfaced by the next write
+	f.enc.Flush()
+	f.f.Flush()
+}
+
+// zstdCloser ends the compressed stream, then closes the underlying one.
+type zstdCloser struct {
+	enc *zstd.Encoder
+	c   io.Closer
+}
+
+func (c *zstdCloser) Close() error {
+	c.enc.Close()
+	return c.c.Close()
+}
+
+// compressConn returns the conn sending the messages compressed with zstd.
+func compressConn(conn *outgoingConn) *outgoingConn {
+	enc := newZstdWriter(conn.Writer)
+	cc := *conn
+	cc.Writer = enc
+	cc.Flusher = &zstdFlusher{enc: enc, f: conn.Flusher}
+	cc.Closer = &zstdCloser{enc: enc, c: conn.Closer}
+	return &cc
+}
+
+// compressBody returns the body compressed with zstd, as read. Closing it
+// closes the body.
+func compressBody(body io.ReadCloser) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		enc := newZstdWriter(pw)
+		_, err := io.Copy(enc, body)
+		if cerr := enc.Close(); err == nil {
+			err = cerr
+		}
+		pw.CloseWithError(err)
+	}()
+	return &compressedBody{PipeReader: pr, body: body}
+}
+
+type compressedBody struct {
+	*io.PipeReader
+	body io.Closer
+}
+
+func (b *compressedBody) Close() error {
+	b.PipeReader.Close()
+	return b.body.Close()
+}
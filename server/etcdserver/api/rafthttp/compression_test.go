@@ -0,0 +1,61 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rafthttp
+
+import (
+	"reflect"
+	"testing"
+
+	"go.etcd.io/etcd/client/pkg/v3/types"
+)
+
+func TestParsePeerCompression(t *testing.T) {
+	tests := []struct {
+		s    string
+		w    PeerCompression
+		werr bool
+	}{
+		{"", PeerCompression{}, false},
+		{"zstd", PeerCompression{Default: CompressionZstd}, false},
+		{"none,8211f1d0f64f3269=zstd", PeerCompression{Default: CompressionNone, Peers: map[types.ID]string{0x8211f1d0f64f3269: CompressionZstd}}, false},
+		{"91bc3c398fb3c146=none", PeerCompression{Peers: map[types.ID]string{0x91bc3c398fb3c146: CompressionNone}}, false},
+		{"lz4", PeerCompression{}, true},
+		{"zstd,none", PeerCompression{}, true},
+		{"foo=zstd", PeerCompression{}, true},
+		{"8211f1d0f64f3269=zstd,8211f1d0f64f3269=none", PeerCompression{}, true},
+	}
+	for i, tt := range tests {
+		pc, err := ParsePeerCompression(tt.s)
+		if (err != nil) != tt.werr {
+			t.Fatalf("#%d: err = %v, want error %v", i, err, tt.werr)
+		}
+		if err == nil && !reflect.DeepEqual(pc, tt.w) {
+			t.Errorf("#%d: compression = %+v, want %+v", i, pc, tt.w)
+		}
+	}
+}
+
+func TestPeerCompression(t *testing.T) {
+	pc := PeerCompression{Default: CompressionZstd, Peers: map[types.ID]string{1: CompressionNone}}
+	if c := pc.compression(1); c != CompressionNone {
+		t.Errorf("compression of peer 1 = %q, want %q", c, CompressionNone)
+	}
+	if c := pc.compression(2); c != CompressionZstd {
+		t.Errorf("compression of peer 2 = %q, want %q", c, CompressionZstd)
+	}
+	if c := (PeerCompression{}).compression(1); c != CompressionNone {
+		t.Errorf("default compression = %q, want %q", c, CompressionNone)
+	}
+}
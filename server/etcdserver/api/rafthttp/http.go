@@ -57,6 +57,12 @@ var (
 	errClusterIDMismatch   = errors.New("cluster ID mismatch")
 )
 
+// clusterIDMismatchCause is logged along with cluster ID mismatches to point
+// operators at the usual root causes.
+const clusterIDMismatchCause = "the two members belong to different clusters; " +
+	"one of them was likely started with a stale data dir from a previous cluster, " +
+	"or a peer URL, --initial-cluster-token or discovery token was reused across clusters"
+
 type peerGetter interface {
 	Get(id types.ID) Peer
 }
@@ -216,6 +222,16 @@ func (h *snapshotHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	addRemoteFromRequest(h.tr, r)
 
+	if r.Header.Get("Content-Encoding") == CompressionZstd {
+		body, err := newZstdReadCloser(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			snapshotReceiveFailures.WithLabelValues(unknownSnapshotSender).Inc()
+			return
+		}
+		r.Body = body
+	}
+
 	dec := &messageDecoder{r: r.Body}
 	// let snapshots be very large since they can exceed 512MB for large installations
 	m, err := dec.decodeLimit(snapshotLimitByte)
@@ -435,17 +451,26 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	acceptsZstd := acceptsZstd(r.Header)
+	compress := acceptsZstd && h.tr.Compression.compression(from) == CompressionZstd
+	if compress {
+		w.Header().Set(compressionHeader, CompressionZstd)
+	}
 	w.WriteHeader(http.StatusOK)
 	w.(http.Flusher).Flush()
 
 	c := newCloseNotifier()
 	conn := &outgoingConn{
-		t:       t,
-		Writer:  w,
-		Flusher: w.(http.Flusher),
-		Closer:  c,
-		localID: h.tr.ID,
-		peerID:  from,
+		t:           t,
+		Writer:      w,
+		Flusher:     w.(http.Flusher),
+		Closer:      c,
+		localID:     h.tr.ID,
+		peerID:      from,
+		acceptsZstd: acceptsZstd,
+	}
+	if compress {
+		conn = compressConn(conn)
 	}
 	p.attachOutgoingConn(conn)
 	<-c.closeNotify()
@@ -497,16 +522,18 @@ func checkClusterCompatibilityFromHeader(lg *zap.Logger, localID types.ID, heade
 		return errIncompatibleVersion
 	}
 	if gcid := header.Get("X-Etcd-Cluster-ID"); gcid != cid.String() {
-		lg.Warn(
-			"request cluster ID mismatch",
+		lg.Error(
+			"rejected request from remote peer due to cluster ID mismatch",
 			zap.String("local-member-id", localID.String()),
 			zap.String("local-member-cluster-id", cid.String()),
 			zap.String("local-member-server-version", localVs),
 			zap.String("local-member-server-minimum-cluster-version", localMinClusterVs),
 			zap.String("remote-peer-server-name", remoteName),
+			zap.String("remote-peer-urls", header.Get("X-PeerURLs")),
 			zap.String("remote-peer-server-version", remoteVs),
 			zap.String("remote-peer-server-minimum-cluster-version", remoteMinClusterVs),
 			zap.String("remote-peer-cluster-id", gcid),
+			zap.String("likely-cause", clusterIDMismatchCause),
 		)
 		return errClusterIDMismatch
 	}
@@ -31,7 +31,9 @@ import (
 	"go.etcd.io/etcd/pkg/v3/pbutil"
 	"go.etcd.io/etcd/raft/v3/raftpb"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/snap"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestServeRaftPrefix(t *testing.T) {
@@ -322,6 +324,30 @@ func TestServeRaftStreamPrefixBad(t *testing.T) {
 	}
 }
 
+func TestCheckClusterCompatibilityFromHeaderClusterIDMismatch(t *testing.T) {
+	h := http.Header{}
+	h.Set("X-Server-From", "2")
+	h.Set("X-Server-Version", version.Version)
+	h.Set("X-Etcd-Cluster-ID", "2")
+	h.Set("X-PeerURLs", "http://10.0.0.2:2380")
+
+	core, logs := observer.New(zap.ErrorLevel)
+	if err := checkClusterCompatibilityFromHeader(zap.New(core), types.ID(1), h, types.ID(1)); err != errClusterIDMismatch {
+		t.Fatalf("err = %v, want %v", err, errClusterIDMismatch)
+	}
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 error entry, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["remote-peer-urls"] != "http://10.0.0.2:2380" || fields["remote-peer-cluster-id"] != "2" || fields["local-member-cluster-id"] != "1" {
+		t.Errorf("unexpected fields %v", fields)
+	}
+	if fields["likely-cause"] != clusterIDMismatchCause {
+		t.Errorf("likely-cause = %v, want %q", fields["likely-cause"], clusterIDMismatchCause)
+	}
+}
+
 func TestCloseNotifier(t *testing.T) {
 	c := newCloseNotifier()
 	select {
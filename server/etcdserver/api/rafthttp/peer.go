@@ -274,6 +274,7 @@ func (p *peer) update(urls types.URLs) {
 }
 
 func (p *peer) attachOutgoingConn(conn *outgoingConn) {
+	p.snapSender.setAcceptsZstd(conn.acceptsZstd)
 	var ok bool
 	switch conn.t {
 	case streamTypeMsgAppV2:
@@ -19,6 +19,7 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"go.etcd.io/etcd/client/pkg/v3/types"
@@ -46,6 +47,10 @@ type snapshotSender struct {
 	r      Raft
 	errorc chan error
 
+	// acceptsZstd is 1 if the peer accepts the snapshots compressed with
+	// zstd, as its stream readers last told.
+	acceptsZstd uint32
+
 	stopc chan struct{}
 }
 
@@ -65,6 +70,19 @@ func newSnapshotSender(tr *Transport, picker *urlPicker, to types.ID, status *pe
 
 func (s *snapshotSender) stop() { close(s.stopc) }
 
+func (s *snapshotSender) setAcceptsZstd(accepts bool) {
+	var v uint32
+	if accepts {
+		v = 1
+	}
+	atomic.StoreUint32(&s.acceptsZstd, v)
+}
+
+// compress returns true if the snapshots are sent compressed with zstd.
+func (s *snapshotSender) compress() bool {
+	return atomic.LoadUint32(&s.acceptsZstd) == 1 && s.tr.Compression.compression(s.to) == CompressionZstd
+}
+
 func (s *snapshotSender) send(merged snap.Message) {
 	start := time.Now()
 
@@ -72,10 +90,17 @@ func (s *snapshotSender) send(merged snap.Message) {
 	to := types.ID(m.To).String()
 
 	body := createSnapBody(s.tr.Logger, merged)
+	compress := s.compress()
+	if compress {
+		body = compressBody(body)
+	}
 	defer body.Close()
 
 	u := s.picker.pick()
 	req := createPostRequest(s.tr.Logger, u, RaftSnapshotPrefix, body, "application/octet-stream", s.tr.URLs, s.from, s.cid)
+	if compress {
+		req.Header.Set("Content-Encoding", CompressionZstd)
+	}
 
 	snapshotSizeVal := uint64(merged.TotalSize)
 	snapshotSize := humanize.Bytes(snapshotSizeVal)
@@ -86,6 +111,7 @@ func (s *snapshotSender) send(merged snap.Message) {
 			zap.String("remote-peer-id", to),
 			zap.Uint64("bytes", snapshotSizeVal),
 			zap.String("size", snapshotSize),
+			zap.Bool("compressed", compress),
 		)
 	}
 
@@ -39,6 +39,8 @@ func TestSnapshotSend(t *testing.T) {
 		m    raftpb.Message
 		rc   io.ReadCloser
 		size int64
+		// compress sends the snapshot compressed with zstd
+		compress bool
 
 		wsent  bool
 		wfiles int
@@ -76,13 +78,33 @@ func TestSnapshotSend(t *testing.T) {
 			rc:   strReaderCloser{strings.NewReader("hello")},
 			size: 1,
 
+			wsent:  false,
+			wfiles: 0,
+		},
+		// sent compressed and received with no errors
+		{
+			m:        raftpb.Message{Type: raftpb.MsgSnap, To: 1},
+			rc:       strReaderCloser{strings.NewReader("hello")},
+			size:     5,
+			compress: true,
+
+			wsent:  true,
+			wfiles: 1,
+		},
+		// sends compressed less than the given snapshot length
+		{
+			m:        raftpb.Message{Type: raftpb.MsgSnap, To: 1},
+			rc:       strReaderCloser{strings.NewReader("hello")},
+			size:     10000,
+			compress: true,
+
 			wsent:  false,
 			wfiles: 0,
 		},
 	}
 
 	for i, tt := range tests {
-		sent, files := testSnapshotSend(t, snap.NewMessage(tt.m, tt.rc, tt.size))
+		sent, files := testSnapshotSend(t, snap.NewMessage(tt.m, tt.rc, tt.size), tt.compress)
 		if tt.wsent != sent {
 			t.Errorf("#%d: snapshot expected %v, got %v", i, tt.wsent, sent)
 		}
@@ -92,7 +114,7 @@ func TestSnapshotSend(t *testing.T) {
 	}
 }
 
-func testSnapshotSend(t *testing.T, sm *snap.Message) (bool, []os.DirEntry) {
+func testSnapshotSend(t *testing.T, sm *snap.Message, compress bool) (bool, []os.DirEntry) {
 	d := t.TempDir()
 
 	r := &fakeRaft{}
@@ -105,6 +127,10 @@ func testSnapshotSend(t *testing.T, sm *snap.Message) (bool, []os.DirEntry) {
 	picker := mustNewURLPicker(t, []string{srv.URL})
 	snapsend := newSnapshotSender(tr, picker, types.ID(1), newPeerStatus(zaptest.NewLogger(t), types.ID(0), types.ID(1)))
 	defer snapsend.stop()
+	if compress {
+		tr.Compression = PeerCompression{Default: CompressionZstd}
+		snapsend.setAcceptsZstd(true)
+	}
 
 	snapsend.send(*sm)
 
@@ -108,6 +108,9 @@ type outgoingConn struct {
 
 	localID types.ID
 	peerID  types.ID
+
+	// acceptsZstd is true if the peer accepts the messages compressed with zstd.
+	acceptsZstd bool
 }
 
 // streamWriter writes messages to the attached outgoingConn.
@@ -584,6 +587,7 @@ func (cr *streamReader) dial(t streamType) (io.ReadCloser, error) {
 	req.Header.Set("X-Min-Cluster-Version", version.MinClusterVersion)
 	req.Header.Set("X-Etcd-Cluster-ID", cr.tr.ClusterID.String())
 	req.Header.Set("X-Raft-To", cr.peerID.String())
+	req.Header.Set(acceptCompressionHeader, CompressionZstd)
 
 	setPeerURLsHeader(req, cr.tr.URLs)
 
@@ -620,6 +624,14 @@ func (cr *streamReader) dial(t streamType) (io.ReadCloser, error) {
 		return nil, errMemberRemoved
 
 	case http.StatusOK:
+		if resp.Header.Get(compressionHeader) == CompressionZstd {
+			rc, err := newZstdReadCloser(resp.Body)
+			if err != nil {
+				httputil.GracefulClose(resp)
+				return nil, err
+			}
+			return rc, nil
+		}
 		return resp.Body, nil
 
 	case http.StatusNotFound:
@@ -650,12 +662,14 @@ func (cr *streamReader) dial(t streamType) (io.ReadCloser, error) {
 
 		case errClusterIDMismatch.Error():
 			if cr.lg != nil {
-				cr.lg.Warn(
+				cr.lg.Error(
 					"request sent was ignored by remote peer due to cluster ID mismatch",
 					zap.String("remote-peer-id", cr.peerID.String()),
+					zap.String("remote-peer-url", u.String()),
 					zap.String("remote-peer-cluster-id", resp.Header.Get("X-Etcd-Cluster-ID")),
 					zap.String("local-member-id", cr.tr.ID.String()),
 					zap.String("local-member-cluster-id", cr.tr.ClusterID.String()),
+					zap.String("likely-cause", clusterIDMismatchCause),
 					zap.Error(errClusterIDMismatch),
 				)
 			}
@@ -279,28 +279,44 @@ func TestStream(t *testing.T) {
 	}
 
 	tests := []struct {
-		t  streamType
-		m  raftpb.Message
-		wc chan raftpb.Message
+		t        streamType
+		m        raftpb.Message
+		wc       chan raftpb.Message
+		compress bool
 	}{
 		{
 			streamTypeMessage,
 			raftpb.Message{Type: raftpb.MsgProp, To: 2},
 			propc,
+			false,
 		},
 		{
 			streamTypeMessage,
 			msgapp,
 			recvc,
+			false,
 		},
 		{
 			streamTypeMsgAppV2,
 			msgapp,
 			recvc,
+			false,
+		},
+		{
+			streamTypeMessage,
+			msgapp,
+			recvc,
+			true,
+		},
+		{
+			streamTypeMsgAppV2,
+			msgapp,
+			recvc,
+			true,
 		},
 	}
 	for i, tt := range tests {
-		h := &fakeStreamHandler{t: tt.t}
+		h := &fakeStreamHandler{t: tt.t, compress: tt.compress}
 		srv := httptest.NewServer(h)
 		defer srv.Close()
 
@@ -440,17 +456,27 @@ func (wfc *fakeWriteFlushCloser) Closed() bool {
 type fakeStreamHandler struct {
 	t  streamType
 	sw *streamWriter
+	// compress sends the stream compressed with zstd, if accepted.
+	compress bool
 }
 
 func (h *fakeStreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("X-Server-Version", version.Version)
+	compress := h.compress && acceptsZstd(r.Header)
+	if compress {
+		w.Header().Set(compressionHeader, CompressionZstd)
+	}
 	w.(http.Flusher).Flush()
 	c := newCloseNotifier()
-	h.sw.attach(&outgoingConn{
+	conn := &outgoingConn{
 		t:       h.t,
 		Writer:  w,
 		Flusher: w.(http.Flusher),
 		Closer:  c,
-	})
+	}
+	if compress {
+		conn = compressConn(conn)
+	}
+	h.sw.attach(conn)
 	<-c.closeNotify()
 }
@@ -118,6 +118,9 @@ type Transport struct {
 	// When an error is received from ErrorC, user should stop raft state
 	// machine and thus stop the Transport.
 	ErrorC chan error
+	// Compression is the compression of the streams and the snapshots sent
+	// to the peers, used with the peers accepting it.
+	Compression PeerCompression
 
 	streamRt   http.RoundTripper // roundTripper used by streams
 	pipelineRt http.RoundTripper // roundTripper used by pipelines
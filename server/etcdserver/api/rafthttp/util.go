@@ -99,8 +99,10 @@ func checkPostResponse(lg *zap.Logger, resp *http.Response, body []byte, req *ht
 				lg.Error(
 					"request sent was ignored due to cluster ID mismatch",
 					zap.String("remote-peer-id", to.String()),
+					zap.String("remote-peer-url", req.URL.Scheme+"://"+req.URL.Host),
 					zap.String("remote-peer-cluster-id", resp.Header.Get("X-Etcd-Cluster-ID")),
 					zap.String("local-member-cluster-id", req.Header.Get("X-Etcd-Cluster-ID")),
+					zap.String("likely-cause", clusterIDMismatchCause),
 				)
 			}
 			return errClusterIDMismatch
@@ -30,6 +30,7 @@ import (
 	"go.etcd.io/etcd/raft/v3"
 	"go.etcd.io/etcd/raft/v3/raftpb"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/snap/snappb"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
 	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
 
 	"go.uber.org/zap"
@@ -37,10 +38,16 @@ import (
 
 const snapSuffix = ".snap"
 
+// encryptedMark starts the data of the snap files encrypted, followed by
+// the marshaled raftpb.Snapshot encrypted. A marshaled raftpb.Snapshot
+// starts with the tag of its data or metadata, never with encryptedMark.
+const encryptedMark byte = 0
+
 var (
 	ErrNoSnapshot    = errors.New("snap: no available snapshot")
 	ErrEmptySnapshot = errors.New("snap: empty snapshot")
 	ErrCRCMismatch   = errors.New("snap: crc mismatch")
+	ErrSnapshotKey   = errors.New("snap: failed to unwrap the key of an encrypted snapshot")
 	crcTable         = crc32.MakeTable(crc32.Castagnoli)
 
 	// A map of valid files that can be present in the snap folder.
@@ -52,6 +59,9 @@ var (
 type Snapshotter struct {
 	lg  *zap.Logger
 	dir string
+	// kp wraps the keys encrypting the snapshots saved, if set, and
+	// unwraps the ones of the snapshots loaded.
+	kp encryption.KeyProvider
 }
 
 func New(lg *zap.Logger, dir string) *Snapshotter {
@@ -64,6 +74,13 @@ func New(lg *zap.Logger, dir string) *Snapshotter {
 	}
 }
 
+// SetKeyProvider sets the provider of the keys encrypting the snapshots
+// saved, and decrypting the ones loaded. The snapshots saved without
+// encryption are loaded as well.
+func (s *Snapshotter) SetKeyProvider(kp encryption.KeyProvider) {
+	s.kp = kp
+}
+
 func (s *Snapshotter) SaveSnap(snapshot raftpb.Snapshot) error {
 	if raft.IsEmptySnap(snapshot) {
 		return nil
@@ -76,6 +93,13 @@ func (s *Snapshotter) save(snapshot *raftpb.Snapshot) error {
 
 	fname := fmt.Sprintf("%016x-%016x%s", snapshot.Metadata.Term, snapshot.Metadata.Index, snapSuffix)
 	b := pbutil.MustMarshal(snapshot)
+	if s.kp != nil {
+		eb, err := encryption.Encrypt(s.kp, b)
+		if err != nil {
+			return err
+		}
+		b = append([]byte{encryptedMark}, eb...)
+	}
 	crc := crc32.Update(0, crcTable, b)
 	snap := snappb.Snapshot{Crc: crc, Data: b}
 	d, err := snap.Marshal()
@@ -129,17 +153,18 @@ func (s *Snapshotter) loadMatching(matchFn func(*raftpb.Snapshot) bool) (*raftpb
 	}
 	var snap *raftpb.Snapshot
 	for _, name := range names {
-		if snap, err = loadSnap(s.lg, s.dir, name); err == nil && matchFn(snap) {
+		if snap, err = loadSnap(s.lg, s.dir, name, s.kp); err == nil && matchFn(snap) {
 			return snap, nil
 		}
 	}
 	return nil, ErrNoSnapshot
 }
 
-func loadSnap(lg *zap.Logger, dir, name string) (*raftpb.Snapshot, error) {
+func loadSnap(lg *zap.Logger, dir, name string, kp encryption.KeyProvider) (*raftpb.Snapshot, error) {
 	fpath := filepath.Join(dir, name)
-	snap, err := Read(lg, fpath)
-	if err != nil {
+	snap, err := ReadWithKeyProvider(lg, fpath, kp)
+	// a snapshot whose key cannot be unwrapped is not broken
+	if err != nil && !errors.Is(err, ErrSnapshotKey) {
 		brokenPath := fpath + ".broken"
 		if lg != nil {
 			lg.Warn("failed to read a snap file", zap.String("path", fpath), zap.Error(err))
@@ -159,6 +184,12 @@ func loadSnap(lg *zap.Logger, dir, name string) (*raftpb.Snapshot, error) {
 
 // Read reads the snapshot named by snapname and returns the snapshot.
 func Read(lg *zap.Logger, snapname string) (*raftpb.Snapshot, error) {
+	return ReadWithKeyProvider(lg, snapname, nil)
+}
+
+// ReadWithKeyProvider reads the snapshot named by snapname, decrypting it
+// with a key unwrapped by kp if it is encrypted, and returns the snapshot.
+func ReadWithKeyProvider(lg *zap.Logger, snapname string, kp encryption.KeyProvider) (*raftpb.Snapshot, error) {
 	b, err := os.ReadFile(snapname)
 	if err != nil {
 		if lg != nil {
@@ -201,8 +232,21 @@ func Read(lg *zap.Logger, snapname string) (*raftpb.Snapshot, error) {
 		return nil, ErrCRCMismatch
 	}
 
+	data := serializedSnap.Data
+	if data[0] == encryptedMark {
+		if data, err = encryption.Decrypt(kp, data[1:]); err != nil {
+			if err != encryption.ErrCorruptedData && err != encryption.ErrCorruptedKey {
+				err = fmt.Errorf("%w: %v", ErrSnapshotKey, err)
+			}
+			if lg != nil {
+				lg.Warn("failed to decrypt snap file", zap.String("path", snapname), zap.Error(err))
+			}
+			return nil, err
+		}
+	}
+
 	var snap raftpb.Snapshot
-	if err = snap.Unmarshal(serializedSnap.Data); err != nil {
+	if err = snap.Unmarshal(data); err != nil {
 		if lg != nil {
 			lg.Warn("failed to unmarshal raftpb.Snapshot", zap.String("path", snapname), zap.Error(err))
 		}
@@ -15,6 +15,9 @@
 package snap
 
 import (
+	"bytes"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"hash/crc32"
 	"os"
@@ -24,6 +27,7 @@ import (
 
 	"go.etcd.io/etcd/client/pkg/v3/fileutil"
 	"go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
 	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
 	"go.uber.org/zap/zaptest"
 )
@@ -61,6 +65,48 @@ func TestSaveAndLoad(t *testing.T) {
 	}
 }
 
+func TestSaveAndLoadEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	kpath := filepath.Join(t.TempDir(), "keys")
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, encryption.KeySize))
+	if err := os.WriteFile(kpath, []byte("k1:"+key+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	kp, err := encryption.NewFileKeyProvider(kpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := New(zaptest.NewLogger(t), dir)
+	ss.SetKeyProvider(kp)
+	if err = ss.save(testSnap); err != nil {
+		t.Fatal(err)
+	}
+	fpath := filepath.Join(dir, fmt.Sprintf("%016x-%016x.snap", 1, 1))
+	b, err := os.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, testSnap.Data) {
+		t.Error("the snapshot is not encrypted")
+	}
+
+	// the snapshot is not renamed as broken without its key
+	if _, err = New(zaptest.NewLogger(t), dir).Load(); err != ErrNoSnapshot {
+		t.Errorf("err = %v, want %v", err, ErrNoSnapshot)
+	}
+	if _, err = Read(zaptest.NewLogger(t), fpath); !errors.Is(err, ErrSnapshotKey) {
+		t.Errorf("err = %v, want %v", err, ErrSnapshotKey)
+	}
+
+	g, err := ss.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g, testSnap) {
+		t.Errorf("snap = %#v, want %#v", g, testSnap)
+	}
+}
+
 func TestBadCRC(t *testing.T) {
 	dir := filepath.Join(os.TempDir(), "snapshot")
 	err := os.Mkdir(dir, 0700)
@@ -16,7 +16,9 @@ package v3compactor
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
+	"hash/fnv"
 	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
@@ -52,10 +54,13 @@ type RevGetter interface {
 }
 
 // New returns a new Compactor based on given "mode".
+// The first compaction cycle is postponed by "delay" so that members
+// configured identically do not compact at the same moment.
 func New(
 	lg *zap.Logger,
 	mode string,
 	retention time.Duration,
+	delay time.Duration,
 	rg RevGetter,
 	c Compactable,
 ) (Compactor, error) {
@@ -64,10 +69,28 @@ func New(
 	}
 	switch mode {
 	case ModePeriodic:
-		return newPeriodic(lg, clockwork.NewRealClock(), retention, rg, c), nil
+		pc := newPeriodic(lg, clockwork.NewRealClock(), retention, rg, c)
+		pc.delay = delay
+		return pc, nil
 	case ModeRevision:
-		return newRevision(lg, clockwork.NewRealClock(), int64(retention), rg, c), nil
+		rc := newRevision(lg, clockwork.NewRealClock(), int64(retention), rg, c)
+		rc.delay = delay
+		return rc, nil
 	default:
 		return nil, fmt.Errorf("unsupported compaction mode %s", mode)
 	}
 }
+
+// JitterDelay returns a delay in [0, window) derived from the given member ID.
+// The same member always gets the same delay, so the schedule is stable
+// across restarts while different members are spread over the window.
+func JitterDelay(memberID uint64, window time.Duration) time.Duration {
+	if window <= 0 {
+		return 0
+	}
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], memberID)
+	h := fnv.New64a()
+	h.Write(b[:])
+	return time.Duration(h.Sum64() % uint64(window))
+}
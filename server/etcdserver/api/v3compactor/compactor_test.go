@@ -17,6 +17,8 @@ package v3compactor
 import (
 	"context"
 	"sync/atomic"
+	"testing"
+	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/client/pkg/v3/testutil"
@@ -45,3 +47,25 @@ func (fr *fakeRevGetter) Rev() int64 {
 func (fr *fakeRevGetter) SetRev(rev int64) {
 	atomic.StoreInt64(&fr.rev, rev)
 }
+
+func TestJitterDelay(t *testing.T) {
+	window := time.Minute
+	if d := JitterDelay(1, 0); d != 0 {
+		t.Errorf("delay with no window = %v, want 0", d)
+	}
+
+	seen := make(map[time.Duration]struct{})
+	for id := uint64(1); id <= 5; id++ {
+		d := JitterDelay(id, window)
+		if d < 0 || d >= window {
+			t.Errorf("delay for member %x = %v, want in [0, %v)", id, d, window)
+		}
+		if d2 := JitterDelay(id, window); d2 != d {
+			t.Errorf("delay for member %x is not stable: %v != %v", id, d, d2)
+		}
+		seen[d] = struct{}{}
+	}
+	if len(seen) == 1 {
+		t.Errorf("expected members to be spread over the window, got %v", seen)
+	}
+}
@@ -32,6 +32,8 @@ type Periodic struct {
 	lg     *zap.Logger
 	clock  clockwork.Clock
 	period time.Duration
+	// delay postpones the first compaction cycle
+	delay time.Duration
 
 	rg RevGetter
 	c  Compactable
@@ -102,6 +104,19 @@ func (pc *Periodic) Run() {
 	retentions := pc.getRetentions()
 
 	go func() {
+		if pc.delay > 0 {
+			pc.lg.Info(
+				"delaying auto periodic compaction",
+				zap.Duration("compact-period", pc.period),
+				zap.Duration("delay", pc.delay),
+			)
+			select {
+			case <-pc.ctx.Done():
+				return
+			case <-pc.clock.After(pc.delay):
+			}
+		}
+
 		lastSuccess := pc.clock.Now()
 		baseInterval := pc.period
 		for {
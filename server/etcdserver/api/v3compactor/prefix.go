@@ -0,0 +1,345 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3compactor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+
+	"github.com/jonboulle/clockwork"
+	"go.uber.org/zap"
+)
+
+// RetentionPolicy is the retention of the revisions of the keys under a
+// prefix, overriding the retention of the compactor for them.
+type RetentionPolicy struct {
+	Prefix    string
+	Retention time.Duration
+}
+
+// ParseRetentionPolicies parses retention policies given as comma separated
+// "prefix=duration" pairs, e.g. "/events/=1h,/config/=168h".
+func ParseRetentionPolicies(s string) ([]RetentionPolicy, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var policies []RetentionPolicy
+	seen := make(map[string]struct{})
+	for _, kv := range strings.Split(s, ",") {
+		i := strings.LastIndex(kv, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid retention policy %q, want prefix=duration", kv)
+		}
+		prefix := kv[:i]
+		retention, err := time.ParseDuration(kv[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid retention of prefix %q: %v", prefix, err)
+		}
+		if retention <= 0 {
+			return nil, fmt.Errorf("retention of prefix %q must be positive", prefix)
+		}
+		if _, ok := seen[prefix]; ok {
+			return nil, fmt.Errorf("duplicate retention policy for prefix %q", prefix)
+		}
+		seen[prefix] = struct{}{}
+		policies = append(policies, RetentionPolicy{Prefix: prefix, Retention: retention})
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Prefix < policies[j].Prefix })
+	return policies, nil
+}
+
+// PrefixPeriodic compacts the log like Periodic, the keys under the prefix
+// of a retention policy being retained for the retention of the policy of
+// their longest prefix instead. The log is compacted at the minimum of the
+// revisions safe to compact at for each policy, and the ranges of keys
+// retained for less are then compacted further.
+type PrefixPeriodic struct {
+	lg        *zap.Logger
+	clock     clockwork.Clock
+	retention time.Duration
+	policies  []RetentionPolicy
+	// delay postpones the first compaction cycle
+	delay time.Duration
+
+	rg RevGetter
+	c  Compactable
+
+	// samples are the revisions sampled at every retry interval, the oldest
+	// first.
+	samples []revSample
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	// mu protects paused
+	mu     sync.RWMutex
+	paused bool
+}
+
+type revSample struct {
+	t   time.Time
+	rev int64
+}
+
+// NewPrefixPeriodic creates a new PrefixPeriodic compactor retaining the
+// log for retention, but for the keys under the prefixes of the policies.
+func NewPrefixPeriodic(lg *zap.Logger, retention time.Duration, policies []RetentionPolicy, delay time.Duration, rg RevGetter, c Compactable) *PrefixPeriodic {
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	pc := newPrefixPeriodic(lg, clockwork.NewRealClock(), retention, policies, rg, c)
+	pc.delay = delay
+	return pc
+}
+
+func newPrefixPeriodic(lg *zap.Logger, clock clockwork.Clock, retention time.Duration, policies []RetentionPolicy, rg RevGetter, c Compactable) *PrefixPeriodic {
+	pc := &PrefixPeriodic{
+		lg:        lg,
+		clock:     clock,
+		retention: retention,
+		policies:  policies,
+		rg:        rg,
+		c:         c,
+	}
+	pc.ctx, pc.cancel = context.WithCancel(context.Background())
+	return pc
+}
+
+// Run runs the compactor, every hour, or every shortest retention if less.
+func (pc *PrefixPeriodic) Run() {
+	shortest, longest := pc.retention, pc.retention
+	for _, p := range pc.policies {
+		if p.Retention < shortest {
+			shortest = p.Retention
+		}
+		if p.Retention > longest {
+			longest = p.Retention
+		}
+	}
+	compactInterval := shortest
+	if compactInterval > time.Hour {
+		compactInterval = time.Hour
+	}
+	retryInterval := compactInterval / retryDivisor
+
+	go func() {
+		if pc.delay > 0 {
+			pc.lg.Info(
+				"delaying auto periodic compaction",
+				zap.Duration("compact-period", pc.retention),
+				zap.Duration("delay", pc.delay),
+			)
+			select {
+			case <-pc.ctx.Done():
+				return
+			case <-pc.clock.After(pc.delay):
+			}
+		}
+
+		lastSuccess := pc.clock.Now()
+		baseInterval := shortest
+		for {
+			now := pc.clock.Now()
+			pc.samples = append(pc.samples, revSample{t: now, rev: pc.rg.Rev()})
+			// the second sample is old enough for the longest retention
+			for len(pc.samples) > 1 && !pc.samples[1].t.After(now.Add(-longest)) {
+				pc.samples = pc.samples[1:]
+			}
+
+			select {
+			case <-pc.ctx.Done():
+				return
+			case <-pc.clock.After(retryInterval):
+				pc.mu.RLock()
+				p := pc.paused
+				pc.mu.RUnlock()
+				if p {
+					continue
+				}
+			}
+
+			if pc.clock.Now().Sub(lastSuccess) < baseInterval {
+				continue
+			}
+
+			// wait up to the shortest retention
+			if baseInterval == shortest {
+				baseInterval = compactInterval
+			}
+			if pc.compact() {
+				lastSuccess = pc.clock.Now()
+			}
+		}
+	}()
+}
+
+// compact runs a compaction cycle, returning whether it succeeded.
+func (pc *PrefixPeriodic) compact() bool {
+	rev, ranges := pc.plan(pc.clock.Now())
+	reqs := ranges
+	if rev != 0 {
+		reqs = append([]*pb.CompactionRequest{{Revision: rev}}, ranges...)
+	}
+	for _, r := range reqs {
+		pc.lg.Info(
+			"starting auto periodic compaction",
+			zap.Int64("revision", r.Revision),
+			zap.ByteString("key", r.Key),
+			zap.ByteString("range-end", r.RangeEnd),
+			zap.Duration("compact-period", pc.retention),
+		)
+		startTime := pc.clock.Now()
+		_, err := pc.c.Compact(pc.ctx, r)
+		if err != nil && err != mvcc.ErrCompacted {
+			pc.lg.Warn(
+				"failed auto periodic compaction",
+				zap.Int64("revision", r.Revision),
+				zap.ByteString("key", r.Key),
+				zap.ByteString("range-end", r.RangeEnd),
+				zap.Error(err),
+			)
+			return false
+		}
+		pc.lg.Info(
+			"completed auto periodic compaction",
+			zap.Int64("revision", r.Revision),
+			zap.ByteString("key", r.Key),
+			zap.ByteString("range-end", r.RangeEnd),
+			zap.Duration("took", pc.clock.Now().Sub(startTime)),
+		)
+	}
+	return true
+}
+
+// plan returns the revision to compact the log at, 0 if none, and the
+// compactions of the ranges of keys retained for less than its revision.
+func (pc *PrefixPeriodic) plan(now time.Time) (rev int64, ranges []*pb.CompactionRequest) {
+	// the policies split the keyspace at their prefixes and prefix ends
+	bounds := []string{""}
+	for _, p := range pc.policies {
+		bounds = append(bounds, p.Prefix)
+		if end := prefixEnd(p.Prefix); end != "" {
+			bounds = append(bounds, end)
+		}
+	}
+	sort.Strings(bounds)
+	n := 1
+	for _, b := range bounds[1:] {
+		if b != bounds[n-1] {
+			bounds[n] = b
+			n++
+		}
+	}
+	bounds = bounds[:n]
+
+	type region struct {
+		key, end string
+		rev      int64
+	}
+	var regions []region
+	for i, key := range bounds {
+		end := ""
+		if i+1 < len(bounds) {
+			end = bounds[i+1]
+		}
+		r := region{key: key, end: end, rev: pc.revAt(now, pc.retentionOf(key))}
+		if n := len(regions); n != 0 && regions[n-1].rev == r.rev {
+			regions[n-1].end = r.end
+			continue
+		}
+		regions = append(regions, r)
+	}
+
+	rev = regions[0].rev
+	for _, r := range regions {
+		if r.rev < rev {
+			rev = r.rev
+		}
+	}
+	for _, r := range regions {
+		if r.rev <= rev {
+			continue
+		}
+		end := []byte(r.end)
+		if r.end == "" {
+			end = []byte{0}
+		}
+		ranges = append(ranges, &pb.CompactionRequest{Revision: r.rev, Key: []byte(r.key), RangeEnd: end})
+	}
+	return rev, ranges
+}
+
+// retentionOf returns the retention of key, the one of the policy of its
+// longest prefix.
+func (pc *PrefixPeriodic) retentionOf(key string) time.Duration {
+	retention, prefix := pc.retention, ""
+	for _, p := range pc.policies {
+		if strings.HasPrefix(key, p.Prefix) && len(p.Prefix) > len(prefix) {
+			retention, prefix = p.Retention, p.Prefix
+		}
+	}
+	return retention
+}
+
+// revAt returns the last revision sampled at least retention before now, 0
+// if none is.
+func (pc *PrefixPeriodic) revAt(now time.Time, retention time.Duration) int64 {
+	rev := int64(0)
+	for _, s := range pc.samples {
+		if s.t.After(now.Add(-retention)) {
+			break
+		}
+		rev = s.rev
+	}
+	return rev
+}
+
+// prefixEnd returns the end of the range of the keys with the given prefix,
+// "" if it is the end of the keyspace.
+func prefixEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+	return ""
+}
+
+// Stop stops the compactor.
+func (pc *PrefixPeriodic) Stop() {
+	pc.cancel()
+}
+
+// Pause pauses the compactor.
+func (pc *PrefixPeriodic) Pause() {
+	pc.mu.Lock()
+	pc.paused = true
+	pc.mu.Unlock()
+}
+
+// Resume resumes the compactor.
+func (pc *PrefixPeriodic) Resume() {
+	pc.mu.Lock()
+	pc.paused = false
+	pc.mu.Unlock()
+}
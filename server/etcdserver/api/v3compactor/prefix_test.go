@@ -0,0 +1,83 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3compactor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/jonboulle/clockwork"
+)
+
+func TestParseRetentionPolicies(t *testing.T) {
+	policies, err := ParseRetentionPolicies("/events/=1h,/config/=168h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RetentionPolicy{{Prefix: "/config/", Retention: 168 * time.Hour}, {Prefix: "/events/", Retention: time.Hour}}
+	if !reflect.DeepEqual(policies, want) {
+		t.Errorf("policies = %+v, want %+v", policies, want)
+	}
+
+	for _, s := range []string{"/events/", "=1h", "/events/=0s", "/events/=1x", "/a/=1h,/a/=2h"} {
+		if _, err := ParseRetentionPolicies(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestPrefixPeriodicPlan(t *testing.T) {
+	fc := clockwork.NewFakeClock()
+	policies := []RetentionPolicy{
+		{Prefix: "/config/", Retention: 7 * time.Hour},
+		{Prefix: "/events/", Retention: time.Hour},
+		{Prefix: "/events/audit/", Retention: 7 * time.Hour},
+	}
+	pc := newPrefixPeriodic(zaptest.NewLogger(t), fc, 2*time.Hour, policies, nil, nil)
+	// one revision sampled every hour, the newest at revision 100
+	now := fc.Now()
+	for i := 10; i >= 0; i-- {
+		pc.samples = append(pc.samples, revSample{t: now.Add(-time.Duration(i) * time.Hour), rev: int64(100 - i)})
+	}
+
+	rev, ranges := pc.plan(now)
+	if rev != 93 {
+		t.Errorf("rev = %d, want 93, the revision 7 hours ago", rev)
+	}
+	wranges := []*pb.CompactionRequest{
+		{Revision: 98, Key: []byte(""), RangeEnd: []byte("/config/")},
+		{Revision: 98, Key: []byte("/config0"), RangeEnd: []byte("/events/")},
+		{Revision: 99, Key: []byte("/events/"), RangeEnd: []byte("/events/audit/")},
+		{Revision: 99, Key: []byte("/events/audit0"), RangeEnd: []byte("/events0")},
+		{Revision: 98, Key: []byte("/events0"), RangeEnd: []byte{0}},
+	}
+	if !reflect.DeepEqual(ranges, wranges) {
+		t.Errorf("ranges = %v, want %v", ranges, wranges)
+	}
+
+	// the revisions of the keys retained for 7 hours are not sampled yet
+	pc.samples = pc.samples[5:]
+	rev, ranges = pc.plan(now)
+	if rev != 0 {
+		t.Errorf("rev = %d, want 0", rev)
+	}
+	if len(ranges) != 5 {
+		t.Errorf("ranges = %v, want the 5 ranges not retained for 7 hours", ranges)
+	}
+}
@@ -33,6 +33,8 @@ type Revision struct {
 
 	clock     clockwork.Clock
 	retention int64
+	// delay postpones the first compaction cycle
+	delay time.Duration
 
 	rg RevGetter
 	c  Compactable
@@ -64,6 +66,19 @@ const revInterval = 5 * time.Minute
 func (rc *Revision) Run() {
 	prev := int64(0)
 	go func() {
+		if rc.delay > 0 {
+			rc.lg.Info(
+				"delaying auto revision compaction",
+				zap.Int64("revision-compaction-retention", rc.retention),
+				zap.Duration("delay", rc.delay),
+			)
+			select {
+			case <-rc.ctx.Done():
+				return
+			case <-rc.clock.After(rc.delay):
+			}
+		}
+
 		for {
 			select {
 			case <-rc.ctx.Done():
@@ -69,6 +69,36 @@ func TestRevision(t *testing.T) {
 	}
 }
 
+func TestRevisionDelay(t *testing.T) {
+	fc := clockwork.NewFakeClock()
+	rg := &fakeRevGetter{testutil.NewRecorderStreamWithWaitTimout(10 * time.Millisecond), 99} // will be 100
+	compactable := &fakeCompactable{testutil.NewRecorderStreamWithWaitTimout(10 * time.Millisecond)}
+	tb := newRevision(zaptest.NewLogger(t), fc, 10, rg, compactable)
+	tb.delay = time.Minute
+
+	tb.Run()
+	defer tb.Stop()
+
+	// the delay elapses, but the first compaction cycle has not yet
+	fc.BlockUntil(1)
+	fc.Advance(revInterval)
+	if _, err := compactable.Wait(1); err == nil {
+		t.Fatal("unexpected compaction before the first cycle after the delay")
+	}
+
+	expectedRevision := int64(90)
+	fc.BlockUntil(1)
+	fc.Advance(revInterval)
+	rg.Wait(1)
+	a, err := compactable.Wait(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a[0].Params[0], &pb.CompactionRequest{Revision: expectedRevision}) {
+		t.Errorf("compact request = %v, want %v", a[0].Params[0], &pb.CompactionRequest{Revision: expectedRevision})
+	}
+}
+
 func TestRevisionPause(t *testing.T) {
 	fc := clockwork.NewFakeClock()
 	rg := &fakeRevGetter{testutil.NewRecorderStream(), 99} // will be 100
@@ -747,6 +747,30 @@ func (fkv *fakeBaseKV) Do(ctx context.Context, op clientv3.Op) (clientv3.OpRespo
 	return clientv3.OpResponse{}, nil
 }
 
+func (fkv *fakeBaseKV) Move(ctx context.Context, key, dst string, opts ...clientv3.OpOption) (*clientv3.MoveResponse, error) {
+	return nil, nil
+}
+
+func (fkv *fakeBaseKV) SetLease(ctx context.Context, key string, id clientv3.LeaseID, opts ...clientv3.OpOption) (*clientv3.SetLeaseResponse, error) {
+	return nil, nil
+}
+
+func (fkv *fakeBaseKV) Increment(ctx context.Context, key string, delta int64, opts ...clientv3.OpOption) (*clientv3.IncrementResponse, error) {
+	return nil, nil
+}
+
+func (fkv *fakeBaseKV) PutIfAbsent(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutIfAbsentResponse, error) {
+	return nil, nil
+}
+
+func (fkv *fakeBaseKV) GetAndDelete(ctx context.Context, key string) (*clientv3.GetAndDeleteResponse, error) {
+	return nil, nil
+}
+
+func (fkv *fakeBaseKV) BulkWrite(ctx context.Context, ops ...clientv3.Op) (*clientv3.BulkWriteResponse, error) {
+	return nil, nil
+}
+
 func (fkv *fakeBaseKV) Txn(ctx context.Context) clientv3.Txn {
 	return nil
 }
@@ -758,6 +782,10 @@ func (fw *fakeBaseWatcher) Watch(ctx context.Context, key string, opts ...client
 	return nil
 }
 
+func (fw *fakeBaseWatcher) WatchIf(ctx context.Context, cmps []clientv3.Cmp, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	return nil
+}
+
 func (fw *fakeBaseWatcher) RequestProgress(ctx context.Context) error {
 	return nil
 }
@@ -0,0 +1,386 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/server/v3/etcdserver"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// unauditedMethods are the read-only RPCs of the etcdserverpb services,
+// which are not recorded in the audit log. All the other RPCs, including
+// the ones added later, are recorded.
+var unauditedMethods = map[string]struct{}{
+	"/etcdserverpb.KV/Range":                     {},
+	"/etcdserverpb.Lease/LeaseKeepAliveOnce":     {},
+	"/etcdserverpb.Lease/LeaseTimeToLive":        {},
+	"/etcdserverpb.Lease/LeaseLeases":            {},
+	"/etcdserverpb.Cluster/MemberList":           {},
+	"/etcdserverpb.Maintenance/Status":           {},
+	"/etcdserverpb.Maintenance/Hash":             {},
+	"/etcdserverpb.Maintenance/HashKV":           {},
+	"/etcdserverpb.Maintenance/QuotaStatus":      {},
+	"/etcdserverpb.Maintenance/WatchStreams":     {},
+	"/etcdserverpb.Maintenance/Watchers":         {},
+	"/etcdserverpb.Maintenance/HotKeys":          {},
+	"/etcdserverpb.Maintenance/ClusterHistory":   {},
+	"/etcdserverpb.Maintenance/RuntimeConfig":    {},
+	"/etcdserverpb.Maintenance/DefragmentStatus": {},
+	"/etcdserverpb.Maintenance/PrefixQuotaList":  {},
+	"/etcdserverpb.Maintenance/PrefixStats":      {},
+	"/etcdserverpb.Maintenance/LearnerStatus":    {},
+}
+
+func isAuditedMethod(method string) bool {
+	if !strings.HasPrefix(method, "/etcdserverpb.") {
+		return false
+	}
+	_, ok := unauditedMethods[method]
+	return !ok
+}
+
+// isReadOnlyRequest returns true for the requests of audited methods that
+// do not mutate anything, like listing the alarms in health checks.
+func isReadOnlyRequest(req interface{}) bool {
+	r, ok := req.(*pb.AlarmRequest)
+	return ok && r.Action == pb.AlarmRequest_GET
+}
+
+func newAuditUnaryInterceptor(s *etcdserver.EtcdServer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !isAuditedMethod(info.FullMethod) || isReadOnlyRequest(req) {
+			return handler(ctx, req)
+		}
+		startTime := time.Now()
+		resp, err := handler(ctx, req)
+		logAuditRequest(ctx, s.Cfg.AuditLogger, auditUser(ctx, s, req), info.FullMethod, s.Cfg.AuditLogRedactValues, startTime, req, resp, err)
+		return resp, err
+	}
+}
+
+// auditUser returns the name of the user that sent req, or an empty
+// string if authentication is disabled or failed.
+func auditUser(ctx context.Context, s *etcdserver.EtcdServer, req interface{}) string {
+	if r, ok := req.(*pb.AuthenticateRequest); ok {
+		return r.Name
+	}
+	ai, err := s.AuthInfoFromCtx(ctx)
+	if err != nil || ai == nil {
+		return ""
+	}
+	return ai.Username
+}
+
+func logAuditRequest(ctx context.Context, lg *zap.Logger, user, method string, redactValues bool, startTime time.Time, req, resp interface{}, err error) {
+	remote := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		remote = p.Addr.String()
+	}
+	fields := []zap.Field{
+		zap.String("method", method),
+		zap.String("user", user),
+		zap.String("remote", remote),
+		zap.Duration("took", time.Since(startTime)),
+	}
+	fields = append(fields, auditRequestFields(req, resp, redactValues)...)
+	if err != nil {
+		fields = append(fields,
+			zap.String("result", "error"),
+			zap.String("code", status.Code(err).String()),
+			zap.Error(err),
+		)
+	} else {
+		fields = append(fields, zap.String("result", "ok"))
+	}
+	lg.Info("audit", fields...)
+}
+
+// auditRequestFields returns the key ranges and targets of req. Passwords
+// are never logged, values only if redactValues is false.
+func auditRequestFields(req, resp interface{}, redactValues bool) []zap.Field {
+	switch r := req.(type) {
+	case *pb.PutRequest:
+		return []zap.Field{zap.Array("keys", auditKeyRanges{putKeyRange(r, redactValues)})}
+	case *pb.DeleteRangeRequest:
+		return []zap.Field{zap.Array("keys", auditKeyRanges{deleteKeyRange(r)})}
+	case *pb.TxnRequest:
+		fields := []zap.Field{zap.Array("keys", txnKeyRanges(r, redactValues))}
+		if tr, ok := resp.(*pb.TxnResponse); ok && tr != nil {
+			fields = append(fields, zap.Bool("succeeded", tr.Succeeded))
+		}
+		return fields
+	case *pb.BulkWriteRequest:
+		fields := []zap.Field{zap.Array("keys", txnKeyRanges(&pb.TxnRequest{Success: r.Ops}, redactValues))}
+		if br, ok := resp.(*pb.BulkWriteResponse); ok && br != nil {
+			failed := 0
+			for _, res := range br.Results {
+				if res.Error != "" {
+					failed++
+				}
+			}
+			fields = append(fields, zap.Int("failed", failed))
+		}
+		return fields
+	case *pb.MoveRequest:
+		fields := []zap.Field{
+			zap.Array("keys", auditKeyRanges{
+				{op: "move", key: string(r.Key), rangeEnd: string(moveRangeEnd(r))},
+				{op: "move-to", key: string(r.Destination)},
+			}),
+			zap.Bool("overwrite", r.Overwrite),
+		}
+		if mr, ok := resp.(*pb.MoveResponse); ok && mr != nil {
+			fields = append(fields, zap.Int64("moved", mr.Moved))
+		}
+		return fields
+	case *pb.SetLeaseRequest:
+		fields := []zap.Field{
+			zap.Array("keys", auditKeyRanges{{op: "set-lease", key: string(r.Key), rangeEnd: string(r.RangeEnd), lease: r.Lease}}),
+			zap.Bool("bump-revision", r.BumpRevision),
+		}
+		if sr, ok := resp.(*pb.SetLeaseResponse); ok && sr != nil {
+			fields = append(fields, zap.Int64("count", sr.Count))
+		}
+		return fields
+	case *pb.IncrementRequest:
+		fields := []zap.Field{
+			zap.Array("keys", auditKeyRanges{{op: "increment", key: string(r.Key), lease: r.Lease}}),
+			zap.Int64("delta", r.Delta),
+		}
+		if ir, ok := resp.(*pb.IncrementResponse); ok && ir != nil {
+			fields = append(fields, zap.Bool("created", ir.Created))
+		}
+		return fields
+	case *pb.PutIfAbsentRequest:
+		kr := auditKeyRange{op: "put-if-absent", key: string(r.Key), lease: r.Lease}
+		if !redactValues {
+			kr.value = string(r.Value)
+		}
+		fields := []zap.Field{zap.Array("keys", auditKeyRanges{kr})}
+		if pr, ok := resp.(*pb.PutIfAbsentResponse); ok && pr != nil {
+			fields = append(fields, zap.Bool("succeeded", pr.Succeeded))
+		}
+		return fields
+	case *pb.GetAndDeleteRequest:
+		fields := []zap.Field{zap.Array("keys", auditKeyRanges{{op: "get-and-delete", key: string(r.Key)}})}
+		if gr, ok := resp.(*pb.GetAndDeleteResponse); ok && gr != nil {
+			fields = append(fields, zap.Bool("deleted", gr.PrevKv != nil))
+		}
+		return fields
+	case *pb.CompactionRequest:
+		return []zap.Field{zap.Int64("revision", r.Revision)}
+	case *pb.LeaseGrantRequest:
+		id := r.ID
+		if lr, ok := resp.(*pb.LeaseGrantResponse); ok && lr != nil {
+			id = lr.ID
+		}
+		return []zap.Field{zap.Int64("lease-id", id), zap.Int64("ttl", r.TTL)}
+	case *pb.LeaseRevokeRequest:
+		return []zap.Field{zap.Int64("lease-id", r.ID)}
+	case *pb.LeaseGrantBulkRequest:
+		ids, ttls := make([]int64, len(r.Leases)), make([]int64, len(r.Leases))
+		for i, l := range r.Leases {
+			if l != nil {
+				ids[i], ttls[i] = l.ID, l.TTL
+			}
+		}
+		if br, ok := resp.(*pb.LeaseGrantBulkResponse); ok && br != nil {
+			for i, l := range br.Leases {
+				if i < len(ids) && l != nil && l.Error == "" {
+					ids[i] = l.ID
+				}
+			}
+		}
+		return []zap.Field{zap.Int64s("lease-ids", ids), zap.Int64s("ttls", ttls)}
+	case *pb.LeaseRevokeBulkRequest:
+		fields := []zap.Field{zap.Int64s("lease-ids", r.IDs)}
+		if br, ok := resp.(*pb.LeaseRevokeBulkResponse); ok && br != nil {
+			fields = append(fields, zap.Int64s("not-found", br.NotFound))
+		}
+		return fields
+	case *pb.MemberAddRequest:
+		fields := []zap.Field{zap.Strings("peer-urls", r.PeerURLs), zap.Bool("learner", r.IsLearner)}
+		if mr, ok := resp.(*pb.MemberAddResponse); ok && mr != nil && mr.Member != nil {
+			fields = append(fields, auditMember(mr.Member.ID))
+		}
+		return fields
+	case *pb.MemberRemoveRequest:
+		return []zap.Field{auditMember(r.ID)}
+	case *pb.MemberUpdateRequest:
+		return []zap.Field{auditMember(r.ID), zap.Strings("peer-urls", r.PeerURLs)}
+	case *pb.MemberPromoteRequest:
+		return []zap.Field{auditMember(r.ID)}
+	case *pb.MemberReplaceRequest:
+		var peerURLs []string
+		for _, a := range r.Add {
+			peerURLs = append(peerURLs, a.PeerURLs...)
+		}
+		removed := make([]string, len(r.Remove))
+		for i, id := range r.Remove {
+			removed[i] = types.ID(id).String()
+		}
+		return []zap.Field{zap.Strings("peer-urls", peerURLs), zap.Strings("removed-members", removed)}
+	case *pb.AlarmRequest:
+		return []zap.Field{zap.String("action", r.Action.String()), zap.String("alarm", r.Alarm.String()), auditMember(r.MemberID)}
+	case *pb.MoveLeaderRequest:
+		return []zap.Field{auditMember(r.TargetID)}
+	case *pb.DowngradeRequest:
+		return []zap.Field{zap.String("action", r.Action.String()), zap.String("version", r.Version)}
+	case *pb.BackendBatchRequest:
+		return []zap.Field{zap.Int64("batch-interval-ms", r.BatchIntervalMs), zap.Int64("batch-limit", r.BatchLimit)}
+	case *pb.LogLevelRequest:
+		return []zap.Field{zap.String("level", r.Level), zap.String("grpc-tracing", r.GrpcTracing.String())}
+	case *pb.CancelWatcherRequest:
+		return []zap.Field{zap.Int64("stream-id", r.StreamId), zap.Int64("watch-id", r.WatchId)}
+	case *pb.PrefixQuotaSetRequest:
+		return []zap.Field{
+			zap.Array("keys", auditKeyRanges{{op: "prefix-quota", key: string(r.Prefix)}}),
+			zap.Int64("max-bytes", r.MaxBytes),
+			zap.Int64("max-keys", r.MaxKeys),
+		}
+	case *pb.PrefixQuotaDeleteRequest:
+		return []zap.Field{zap.Array("keys", auditKeyRanges{{op: "prefix-quota", key: string(r.Prefix)}})}
+	case *pb.AuthUserAddRequest:
+		return []zap.Field{zap.String("target-user", r.Name)}
+	case *pb.AuthUserGetRequest:
+		return []zap.Field{zap.String("target-user", r.Name)}
+	case *pb.AuthUserDeleteRequest:
+		return []zap.Field{zap.String("target-user", r.Name)}
+	case *pb.AuthUserChangePasswordRequest:
+		return []zap.Field{zap.String("target-user", r.Name)}
+	case *pb.AuthUserGrantRoleRequest:
+		return []zap.Field{zap.String("target-user", r.User), zap.String("target-role", r.Role)}
+	case *pb.AuthUserRevokeRoleRequest:
+		return []zap.Field{zap.String("target-user", r.Name), zap.String("target-role", r.Role)}
+	case *pb.AuthRoleAddRequest:
+		return []zap.Field{zap.String("target-role", r.Name)}
+	case *pb.AuthRoleGetRequest:
+		return []zap.Field{zap.String("target-role", r.Role)}
+	case *pb.AuthRoleDeleteRequest:
+		return []zap.Field{zap.String("target-role", r.Role)}
+	case *pb.AuthRoleGrantPermissionRequest:
+		fields := []zap.Field{zap.String("target-role", r.Name)}
+		if r.Perm != nil {
+			fields = append(fields,
+				zap.String("permission", r.Perm.PermType.String()),
+				zap.Array("keys", auditKeyRanges{{op: "permission", key: string(r.Perm.Key), rangeEnd: string(r.Perm.RangeEnd)}}),
+			)
+		}
+		return fields
+	case *pb.AuthLockoutClearRequest:
+		fields := []zap.Field{zap.String("target-user", r.User), zap.String("target-address", r.Address)}
+		if cr, ok := resp.(*pb.AuthLockoutClearResponse); ok && cr != nil {
+			fields = append(fields, zap.Int64("cleared", cr.Cleared))
+		}
+		return fields
+	case *pb.AuthSessionRevokeRequest:
+		fields := []zap.Field{zap.Uint64("target-session", r.ID), zap.String("target-user", r.User)}
+		if rr, ok := resp.(*pb.AuthSessionRevokeResponse); ok && rr != nil {
+			fields = append(fields, zap.Int64("revoked", rr.Revoked))
+		}
+		return fields
+	case *pb.AuthRoleRevokePermissionRequest:
+		return []zap.Field{
+			zap.String("target-role", r.Role),
+			zap.Array("keys", auditKeyRanges{{op: "permission", key: string(r.Key), rangeEnd: string(r.RangeEnd)}}),
+		}
+	}
+	return nil
+}
+
+func auditMember(id uint64) zap.Field {
+	return zap.String("target-member", types.ID(id).String())
+}
+
+func putKeyRange(r *pb.PutRequest, redactValues bool) auditKeyRange {
+	kr := auditKeyRange{op: "put", key: string(r.Key), lease: r.Lease}
+	if !redactValues {
+		kr.value = string(r.Value)
+	}
+	return kr
+}
+
+func deleteKeyRange(r *pb.DeleteRangeRequest) auditKeyRange {
+	return auditKeyRange{op: "delete", key: string(r.Key), rangeEnd: string(r.RangeEnd)}
+}
+
+func txnKeyRanges(r *pb.TxnRequest, redactValues bool) auditKeyRanges {
+	var krs auditKeyRanges
+	for _, c := range r.Compare {
+		krs = append(krs, auditKeyRange{op: "compare", key: string(c.Key), rangeEnd: string(c.RangeEnd)})
+	}
+	for _, ops := range [][]*pb.RequestOp{r.Success, r.Failure} {
+		for _, op := range ops {
+			switch o := op.Request.(type) {
+			case *pb.RequestOp_RequestRange:
+				krs = append(krs, auditKeyRange{op: "range", key: string(o.RequestRange.Key), rangeEnd: string(o.RequestRange.RangeEnd)})
+			case *pb.RequestOp_RequestPut:
+				krs = append(krs, putKeyRange(o.RequestPut, redactValues))
+			case *pb.RequestOp_RequestDeleteRange:
+				krs = append(krs, deleteKeyRange(o.RequestDeleteRange))
+			case *pb.RequestOp_RequestTxn:
+				krs = append(krs, txnKeyRanges(o.RequestTxn, redactValues)...)
+			}
+		}
+	}
+	return krs
+}
+
+type auditKeyRange struct {
+	op       string
+	key      string
+	rangeEnd string
+	value    string
+	lease    int64
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (kr auditKeyRange) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("op", kr.op)
+	enc.AddString("key", kr.key)
+	if kr.rangeEnd != "" {
+		enc.AddString("range-end", kr.rangeEnd)
+	}
+	if kr.value != "" {
+		enc.AddString("value", kr.value)
+	}
+	if kr.lease != 0 {
+		enc.AddInt64("lease-id", kr.lease)
+	}
+	return nil
+}
+
+type auditKeyRanges []auditKeyRange
+
+// MarshalLogArray implements zapcore.ArrayMarshaler.
+func (krs auditKeyRanges) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, kr := range krs {
+		if err := enc.AppendObject(kr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+)
+
+func TestIsAuditedMethod(t *testing.T) {
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{"/etcdserverpb.KV/Put", true},
+		{"/etcdserverpb.KV/Txn", true},
+		{"/etcdserverpb.KV/Range", false},
+		{"/etcdserverpb.Lease/LeaseGrant", true},
+		{"/etcdserverpb.Lease/LeaseTimeToLive", false},
+		{"/etcdserverpb.Auth/Authenticate", true},
+		{"/etcdserverpb.Auth/UserAdd", true},
+		{"/etcdserverpb.Maintenance/Status", false},
+		{"/etcdserverpb.Maintenance/Defragment", true},
+		{"/etcdserverpb.Maintenance/MoveLeader", true},
+		{"/etcdserverpb.Cluster/MemberAdd", true},
+		{"/etcdserverpb.Cluster/MemberList", false},
+		{"/grpc.health.v1.Health/Check", false},
+	}
+	for _, tt := range tests {
+		if got := isAuditedMethod(tt.method); got != tt.want {
+			t.Errorf("isAuditedMethod(%q) = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+}
+
+// auditedWithoutFields are the audited methods whose requests have no
+// targets to record besides the user.
+var auditedWithoutFields = map[string]struct{}{
+	"/etcdserverpb.Maintenance/Defragment":      {},
+	"/etcdserverpb.Maintenance/ResetQuotaAlarm": {},
+	"/etcdserverpb.Auth/AuthEnable":             {},
+	"/etcdserverpb.Auth/AuthDisable":            {},
+	"/etcdserverpb.Auth/AuthStatus":             {},
+	"/etcdserverpb.Auth/Authenticate":           {},
+	"/etcdserverpb.Auth/UserList":               {},
+	"/etcdserverpb.Auth/RoleList":               {},
+	"/etcdserverpb.Auth/AuthLockoutList":        {},
+	"/etcdserverpb.Auth/AuthSessionList":        {},
+}
+
+// TestAuditedMethodsCoverage fails when an RPC is added without being
+// audited, or without describing its targets to the audit log.
+func TestAuditedMethodsCoverage(t *testing.T) {
+	impls := map[string]interface{}{
+		"etcdserverpb.KV":          &pb.UnimplementedKVServer{},
+		"etcdserverpb.Watch":       &pb.UnimplementedWatchServer{},
+		"etcdserverpb.Lease":       &pb.UnimplementedLeaseServer{},
+		"etcdserverpb.Cluster":     &pb.UnimplementedClusterServer{},
+		"etcdserverpb.Maintenance": &pb.UnimplementedMaintenanceServer{},
+		"etcdserverpb.Auth":        &pb.UnimplementedAuthServer{},
+	}
+	gs := grpc.NewServer()
+	pb.RegisterKVServer(gs, impls["etcdserverpb.KV"].(pb.KVServer))
+	pb.RegisterWatchServer(gs, impls["etcdserverpb.Watch"].(pb.WatchServer))
+	pb.RegisterLeaseServer(gs, impls["etcdserverpb.Lease"].(pb.LeaseServer))
+	pb.RegisterClusterServer(gs, impls["etcdserverpb.Cluster"].(pb.ClusterServer))
+	pb.RegisterMaintenanceServer(gs, impls["etcdserverpb.Maintenance"].(pb.MaintenanceServer))
+	pb.RegisterAuthServer(gs, impls["etcdserverpb.Auth"].(pb.AuthServer))
+
+	known := make(map[string]struct{})
+	for service, info := range gs.GetServiceInfo() {
+		for _, m := range info.Methods {
+			method := "/" + service + "/" + m.Name
+			known[method] = struct{}{}
+			if m.IsClientStream || m.IsServerStream {
+				// not intercepted
+				continue
+			}
+			if _, ok := unauditedMethods[method]; ok {
+				continue
+			}
+			if !isAuditedMethod(method) {
+				t.Errorf("%s is not audited", method)
+			}
+			req := reflect.New(reflect.ValueOf(impls[service]).MethodByName(m.Name).Type().In(1).Elem()).Interface()
+			_, noFields := auditedWithoutFields[method]
+			switch hasFields := auditRequestFields(req, nil, false) != nil; {
+			case !hasFields && !noFields:
+				t.Errorf("%s has no audit fields; describe the targets of %T in auditRequestFields, or add it to unauditedMethods if it is read-only", method, req)
+			case hasFields && noFields:
+				t.Errorf("%s has audit fields but is listed in auditedWithoutFields", method)
+			}
+		}
+	}
+	for _, methods := range []map[string]struct{}{unauditedMethods, auditedWithoutFields} {
+		for method := range methods {
+			if _, ok := known[method]; !ok {
+				t.Errorf("unknown method %s", method)
+			}
+		}
+	}
+}
+
+func TestLogAuditRequest(t *testing.T) {
+	txn := &pb.TxnRequest{
+		Compare: []*pb.Compare{{Key: []byte("a")}},
+		Success: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("b"), Value: []byte("secret")}}},
+		},
+		Failure: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestDeleteRange{RequestDeleteRange: &pb.DeleteRangeRequest{Key: []byte("c"), RangeEnd: []byte("d")}}},
+		},
+	}
+	tests := []struct {
+		name         string
+		req, resp    interface{}
+		err          error
+		redactValues bool
+
+		wantKeys []interface{}
+		want     map[string]interface{}
+	}{
+		{
+			name:         "put redacted",
+			req:          &pb.PutRequest{Key: []byte("foo"), Value: []byte("bar")},
+			redactValues: true,
+			wantKeys:     []interface{}{map[string]interface{}{"op": "put", "key": "foo"}},
+			want:         map[string]interface{}{"result": "ok"},
+		},
+		{
+			name:     "put with value",
+			req:      &pb.PutRequest{Key: []byte("foo"), Value: []byte("bar"), Lease: 5},
+			wantKeys: []interface{}{map[string]interface{}{"op": "put", "key": "foo", "value": "bar", "lease-id": int64(5)}},
+			want:     map[string]interface{}{"result": "ok"},
+		},
+		{
+			name:         "txn",
+			req:          txn,
+			resp:         &pb.TxnResponse{Succeeded: true},
+			redactValues: true,
+			wantKeys: []interface{}{
+				map[string]interface{}{"op": "compare", "key": "a"},
+				map[string]interface{}{"op": "put", "key": "b"},
+				map[string]interface{}{"op": "delete", "key": "c", "range-end": "d"},
+			},
+			want: map[string]interface{}{"result": "ok", "succeeded": true},
+		},
+		{
+			name:     "move prefix",
+			req:      &pb.MoveRequest{Key: []byte("a/"), Destination: []byte("b/"), Prefix: true},
+			resp:     &pb.MoveResponse{Moved: 2},
+			wantKeys: []interface{}{map[string]interface{}{"op": "move", "key": "a/", "range-end": "a0"}, map[string]interface{}{"op": "move-to", "key": "b/"}},
+			want:     map[string]interface{}{"result": "ok", "overwrite": false, "moved": int64(2)},
+		},
+		{
+			name:     "set lease",
+			req:      &pb.SetLeaseRequest{Key: []byte("a"), RangeEnd: []byte("b"), Lease: 5},
+			resp:     &pb.SetLeaseResponse{Count: 3},
+			wantKeys: []interface{}{map[string]interface{}{"op": "set-lease", "key": "a", "range-end": "b", "lease-id": int64(5)}},
+			want:     map[string]interface{}{"result": "ok", "bump-revision": false, "count": int64(3)},
+		},
+		{
+			name:     "increment",
+			req:      &pb.IncrementRequest{Key: []byte("ctr"), Delta: -2, Lease: 5},
+			resp:     &pb.IncrementResponse{Value: 3, Created: true},
+			wantKeys: []interface{}{map[string]interface{}{"op": "increment", "key": "ctr", "lease-id": int64(5)}},
+			want:     map[string]interface{}{"result": "ok", "delta": int64(-2), "created": true},
+		},
+		{
+			name:         "put if absent redacted",
+			req:          &pb.PutIfAbsentRequest{Key: []byte("foo"), Value: []byte("bar"), Lease: 5},
+			resp:         &pb.PutIfAbsentResponse{Succeeded: true},
+			redactValues: true,
+			wantKeys:     []interface{}{map[string]interface{}{"op": "put-if-absent", "key": "foo", "lease-id": int64(5)}},
+			want:         map[string]interface{}{"result": "ok", "succeeded": true},
+		},
+		{
+			name:     "get and delete",
+			req:      &pb.GetAndDeleteRequest{Key: []byte("foo")},
+			resp:     &pb.GetAndDeleteResponse{},
+			wantKeys: []interface{}{map[string]interface{}{"op": "get-and-delete", "key": "foo"}},
+			want:     map[string]interface{}{"result": "ok", "deleted": false},
+		},
+		{
+			name: "bulk write",
+			req: &pb.BulkWriteRequest{Ops: []*pb.RequestOp{
+				{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("a"), Value: []byte("secret")}}},
+				{Request: &pb.RequestOp_RequestDeleteRange{RequestDeleteRange: &pb.DeleteRangeRequest{Key: []byte("b"), RangeEnd: []byte("c")}}},
+			}},
+			resp:         &pb.BulkWriteResponse{Results: []*pb.BulkWriteResult{{}, {Error: "etcdserver: key not found"}}},
+			redactValues: true,
+			wantKeys: []interface{}{
+				map[string]interface{}{"op": "put", "key": "a"},
+				map[string]interface{}{"op": "delete", "key": "b", "range-end": "c"},
+			},
+			want: map[string]interface{}{"result": "ok", "failed": int64(1)},
+		},
+		{
+			name: "lease grant bulk",
+			req: &pb.LeaseGrantBulkRequest{Leases: []*pb.LeaseGrantRequest{
+				{TTL: 10},
+				{ID: 7, TTL: 20},
+			}},
+			resp: &pb.LeaseGrantBulkResponse{Leases: []*pb.LeaseGrantResponse{
+				{ID: 5, TTL: 10},
+				{ID: 7, Error: "lease already exists"},
+			}},
+			want: map[string]interface{}{
+				"result":    "ok",
+				"lease-ids": []interface{}{int64(5), int64(7)},
+				"ttls":      []interface{}{int64(10), int64(20)},
+			},
+		},
+		{
+			name: "lease revoke bulk",
+			req:  &pb.LeaseRevokeBulkRequest{IDs: []int64{5, 7}},
+			resp: &pb.LeaseRevokeBulkResponse{Revoked: []int64{5}, NotFound: []int64{7}},
+			want: map[string]interface{}{
+				"result":    "ok",
+				"lease-ids": []interface{}{int64(5), int64(7)},
+				"not-found": []interface{}{int64(7)},
+			},
+		},
+		{
+			name: "user add failed",
+			req:  &pb.AuthUserAddRequest{Name: "alice", Password: "hunter2"},
+			err:  rpctypes.ErrGRPCUserAlreadyExist,
+			want: map[string]interface{}{"result": "error", "code": "FailedPrecondition", "target-user": "alice"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.InfoLevel)
+			logAuditRequest(context.Background(), zap.New(core), "root", "/method", tt.redactValues, time.Now(), tt.req, tt.resp, tt.err)
+
+			entries := logs.All()
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 audit entry, got %d", len(entries))
+			}
+			fields := entries[0].ContextMap()
+			if fields["user"] != "root" || fields["method"] != "/method" {
+				t.Errorf("unexpected user or method in %v", fields)
+			}
+			for k, v := range tt.want {
+				if !reflect.DeepEqual(fields[k], v) {
+					t.Errorf("field %q = %v, want %v", k, fields[k], v)
+				}
+			}
+			if tt.wantKeys != nil {
+				keys, _ := fields["keys"].([]interface{})
+				if len(keys) != len(tt.wantKeys) {
+					t.Fatalf("keys = %v, want %v", keys, tt.wantKeys)
+				}
+				for i := range keys {
+					got, want := keys[i].(map[string]interface{}), tt.wantKeys[i].(map[string]interface{})
+					if len(got) != len(want) {
+						t.Errorf("keys[%d] = %v, want %v", i, got, want)
+						continue
+					}
+					for k, v := range want {
+						if got[k] != v {
+							t.Errorf("keys[%d][%q] = %v, want %v", i, k, got[k], v)
+						}
+					}
+				}
+			}
+			for _, v := range fields {
+				if v == "hunter2" {
+					t.Errorf("password logged in %v", fields)
+				}
+			}
+		})
+	}
+}
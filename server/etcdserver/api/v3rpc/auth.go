@@ -157,6 +157,38 @@ func (as *AuthServer) UserRevokeRole(ctx context.Context, r *pb.AuthUserRevokeRo
 	return resp, nil
 }
 
+func (as *AuthServer) AuthLockoutList(ctx context.Context, r *pb.AuthLockoutListRequest) (*pb.AuthLockoutListResponse, error) {
+	resp, err := as.authenticator.AuthLockoutList(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (as *AuthServer) AuthLockoutClear(ctx context.Context, r *pb.AuthLockoutClearRequest) (*pb.AuthLockoutClearResponse, error) {
+	resp, err := as.authenticator.AuthLockoutClear(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (as *AuthServer) AuthSessionList(ctx context.Context, r *pb.AuthSessionListRequest) (*pb.AuthSessionListResponse, error) {
+	resp, err := as.authenticator.AuthSessionList(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (as *AuthServer) AuthSessionRevoke(ctx context.Context, r *pb.AuthSessionRevokeRequest) (*pb.AuthSessionRevokeResponse, error) {
+	resp, err := as.authenticator.AuthSessionRevoke(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
 func (as *AuthServer) UserChangePassword(ctx context.Context, r *pb.AuthUserChangePasswordRequest) (*pb.AuthUserChangePasswordResponse, error) {
 	resp, err := as.authenticator.UserChangePassword(ctx, r)
 	if err != nil {
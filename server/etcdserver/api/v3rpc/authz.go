@@ -0,0 +1,143 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"context"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/server/v3/auth"
+	"go.etcd.io/etcd/server/v3/etcdserver"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+const (
+	authenticateMethod = "/etcdserverpb.Auth/Authenticate"
+	watchMethod        = "/etcdserverpb.Watch/Watch"
+)
+
+// newAuthzUnaryInterceptor denies the requests the external authorizer of
+// the server does not allow, except for authentications, where no user is
+// known yet.
+func newAuthzUnaryInterceptor(s *etcdserver.EtcdServer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if info.FullMethod == authenticateMethod {
+			return handler(ctx, req)
+		}
+		ai, err := s.AuthInfoFromCtx(ctx)
+		if err != nil {
+			// the handler fails authenticating the request as well
+			return handler(ctx, req)
+		}
+		d, ok := authorize(ctx, s.Logger(), s.Cfg.Authorizer, ai, info.FullMethod, resolveAuthzHomeKeys(s.AuthStore(), ai, authzKeyRanges(req)))
+		if !ok {
+			return nil, rpctypes.ErrGRPCPermissionDenied
+		}
+		if r, ok := req.(*pb.RangeRequest); ok && d.Limit > 0 && (r.Limit <= 0 || r.Limit > d.Limit) {
+			rr := *r
+			rr.Limit = d.Limit
+			req = &rr
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authorize returns the decision of the authorizer on the request of the user,
+// and whether the request is allowed.
+func authorize(ctx context.Context, lg *zap.Logger, authz auth.Authorizer, ai *auth.AuthInfo, method string, keys []auth.AuthorizationKeyRange) (*auth.AuthorizationDecision, bool) {
+	r := &auth.AuthorizationRequest{Method: method, Keys: keys}
+	if ai != nil {
+		r.User, r.Roles = ai.Username, ai.Roles
+	}
+	d, err := authz.Authorize(ctx, r)
+	if err != nil {
+		if lg != nil {
+			lg.Warn("failed to authorize request", zap.String("method", method), zap.String("user", r.User), zap.Error(err))
+		}
+		return nil, false
+	}
+	if d == nil || !d.Allowed {
+		if lg != nil && d != nil {
+			lg.Debug("request denied by authorizer", zap.String("method", method), zap.String("user", r.User), zap.String("reason", d.Reason))
+		}
+		return d, false
+	}
+	return d, true
+}
+
+// authzKeyRanges returns the key ranges accessed by req.
+func authzKeyRanges(req interface{}) []auth.AuthorizationKeyRange {
+	switch r := req.(type) {
+	case *pb.RangeRequest:
+		return []auth.AuthorizationKeyRange{{Op: "range", Key: r.Key, RangeEnd: r.RangeEnd}}
+	case *pb.PutRequest:
+		return []auth.AuthorizationKeyRange{{Op: "put", Key: r.Key}}
+	case *pb.DeleteRangeRequest:
+		return []auth.AuthorizationKeyRange{{Op: "delete", Key: r.Key, RangeEnd: r.RangeEnd}}
+	case *pb.TxnRequest:
+		return txnAuthzKeyRanges(r)
+	case *pb.MoveRequest:
+		return []auth.AuthorizationKeyRange{
+			{Op: "move", Key: r.Key, RangeEnd: moveRangeEnd(r)},
+			{Op: "move-to", Key: r.Destination, RangeEnd: moveDestinationRangeEnd(r)},
+		}
+	case *pb.SetLeaseRequest:
+		return []auth.AuthorizationKeyRange{{Op: "set-lease", Key: r.Key, RangeEnd: r.RangeEnd}}
+	case *pb.IncrementRequest:
+		return []auth.AuthorizationKeyRange{{Op: "increment", Key: r.Key}}
+	case *pb.PutIfAbsentRequest:
+		return []auth.AuthorizationKeyRange{{Op: "put-if-absent", Key: r.Key}}
+	case *pb.GetAndDeleteRequest:
+		return []auth.AuthorizationKeyRange{{Op: "get-and-delete", Key: r.Key}}
+	case *pb.BulkWriteRequest:
+		return txnAuthzKeyRanges(&pb.TxnRequest{Success: r.Ops})
+	}
+	return nil
+}
+
+// resolveAuthzHomeKeys scopes the relative key ranges under the home prefix
+// of the user, as the handlers do, so that the authorizer sees the keys
+// actually accessed.
+func resolveAuthzHomeKeys(as auth.AuthStore, ai *auth.AuthInfo, krs []auth.AuthorizationKeyRange) []auth.AuthorizationKeyRange {
+	for i := range krs {
+		krs[i].Key, krs[i].RangeEnd = as.ResolveHomeKey(ai, krs[i].Key, krs[i].RangeEnd)
+	}
+	return krs
+}
+
+func txnAuthzKeyRanges(r *pb.TxnRequest) []auth.AuthorizationKeyRange {
+	var krs []auth.AuthorizationKeyRange
+	for _, c := range r.Compare {
+		krs = append(krs, auth.AuthorizationKeyRange{Op: "compare", Key: c.Key, RangeEnd: c.RangeEnd})
+	}
+	for _, ops := range [][]*pb.RequestOp{r.Success, r.Failure} {
+		for _, op := range ops {
+			switch o := op.Request.(type) {
+			case *pb.RequestOp_RequestRange:
+				krs = append(krs, authzKeyRanges(o.RequestRange)...)
+			case *pb.RequestOp_RequestPut:
+				krs = append(krs, authzKeyRanges(o.RequestPut)...)
+			case *pb.RequestOp_RequestDeleteRange:
+				krs = append(krs, authzKeyRanges(o.RequestDeleteRange)...)
+			case *pb.RequestOp_RequestTxn:
+				krs = append(krs, txnAuthzKeyRanges(o.RequestTxn)...)
+			}
+		}
+	}
+	return krs
+}
@@ -0,0 +1,51 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"context"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/authpb"
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/etcdserver"
+
+	"google.golang.org/grpc"
+)
+
+// debugTraceUnary serves a request whose client asked for its timing
+// breakdown with the etcd-debug-trace metadata, and attaches the breakdown
+// to the response header. Since the breakdown reveals the state of the
+// member, it requires admin permission.
+func debugTraceUnary(ctx context.Context, s *etcdserver.EtcdServer, req interface{}, handler grpc.UnaryHandler) (interface{}, error) {
+	authInfo, err := s.AuthInfoFromCtx(ctx)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	if err = s.AuthStore().IsAdminOpPermitted(authInfo, authpb.MAINTENANCE); err != nil {
+		return nil, togRPCError(err)
+	}
+
+	start := time.Now()
+	ctx, rt := etcdserver.WithRequestTrace(ctx)
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+	if r, ok := resp.(interface{ GetHeader() *pb.ResponseHeader }); ok && r.GetHeader() != nil {
+		r.GetHeader().Trace = rt.Proto(time.Since(start))
+	}
+	return resp, nil
+}
@@ -28,6 +28,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
 const (
@@ -48,6 +49,12 @@ func Server(s *etcdserver.EtcdServer, tls *tls.Config, interceptor grpc.UnarySer
 		newUnaryInterceptor(s),
 		grpc_prometheus.UnaryServerInterceptor,
 	}
+	if s.Cfg.Authorizer != nil {
+		chainUnaryInterceptors = append(chainUnaryInterceptors, newAuthzUnaryInterceptor(s))
+	}
+	if s.Cfg.AuditLogger != nil {
+		chainUnaryInterceptors = append([]grpc.UnaryServerInterceptor{newAuditUnaryInterceptor(s)}, chainUnaryInterceptors...)
+	}
 	if interceptor != nil {
 		chainUnaryInterceptors = append(chainUnaryInterceptors, interceptor)
 	}
@@ -66,7 +73,11 @@ func Server(s *etcdserver.EtcdServer, tls *tls.Config, interceptor grpc.UnarySer
 	opts = append(opts, grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(chainUnaryInterceptors...)))
 	opts = append(opts, grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(chainStreamInterceptors...)))
 
-	opts = append(opts, grpc.MaxRecvMsgSize(int(s.Cfg.MaxRequestBytes+grpcOverheadBytes)))
+	maxRecvBytes := s.Cfg.MaxRequestBytes
+	if s.Cfg.MaxChunkedValueBytes > maxRecvBytes {
+		maxRecvBytes = s.Cfg.MaxChunkedValueBytes
+	}
+	opts = append(opts, grpc.MaxRecvMsgSize(int(maxRecvBytes+grpcOverheadBytes)))
 	opts = append(opts, grpc.MaxSendMsgSize(maxSendBytes))
 	opts = append(opts, grpc.MaxConcurrentStreams(maxStreams))
 
@@ -86,6 +97,10 @@ func Server(s *etcdserver.EtcdServer, tls *tls.Config, interceptor grpc.UnarySer
 	hsrv.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
 	healthpb.RegisterHealthServer(grpcServer, hsrv)
 
+	if s.Cfg.EnableGRPCReflection {
+		reflection.Register(grpcServer)
+	}
+
 	// set zero values for metrics registered for this grpc server
 	grpc_prometheus.Register(grpcServer)
 
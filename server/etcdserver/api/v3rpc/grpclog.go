@@ -0,0 +1,140 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"io"
+	"os"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapgrpc"
+	"google.golang.org/grpc/grpclog"
+)
+
+// tracingLogger is the gRPC logger that writes gRPC internal logs to the
+// server logger while tracing is enabled, and only errors to stderr
+// otherwise.
+type tracingLogger struct {
+	enabled int32
+	on, off grpclog.LoggerV2
+}
+
+// grpcLogger is the gRPC logger installed by SetGRPCLogger, if any.
+var grpcLogger atomic.Value
+
+// SetGRPCLogger installs the gRPC logger, with gRPC internal logs written
+// to lg if tracing is true. Like grpclog.SetLoggerV2, it must be called
+// before any gRPC functions.
+func SetGRPCLogger(lg *zap.Logger, tracing bool) {
+	l := &tracingLogger{
+		on:  zapgrpc.NewLogger(lg),
+		off: grpclog.NewLoggerV2(io.Discard, os.Stderr, os.Stderr),
+	}
+	l.setEnabled(tracing)
+	grpclog.SetLoggerV2(l)
+	grpcLogger.Store(l)
+}
+
+// grpcTracing returns whether gRPC internal logs are written to the server
+// log, and false for ok if the gRPC logger was not installed by etcd.
+func grpcTracing() (enabled, ok bool) {
+	l, ok := grpcLogger.Load().(*tracingLogger)
+	if !ok {
+		return false, false
+	}
+	return l.isEnabled(), true
+}
+
+// setGRPCTracing enables or disables writing gRPC internal logs to the
+// server log. It returns false if the gRPC logger was not installed by etcd.
+func setGRPCTracing(enabled bool) bool {
+	l, ok := grpcLogger.Load().(*tracingLogger)
+	if ok {
+		l.setEnabled(enabled)
+	}
+	return ok
+}
+
+func (l *tracingLogger) isEnabled() bool { return atomic.LoadInt32(&l.enabled) == 1 }
+
+func (l *tracingLogger) setEnabled(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&l.enabled, v)
+}
+
+func (l *tracingLogger) logger() grpclog.LoggerV2 {
+	if l.isEnabled() {
+		return l.on
+	}
+	return l.off
+}
+
+// The methods below implement grpclog.LoggerV2.
+
+func (l *tracingLogger) Info(args ...interface{}) {
+	l.logger().Info(args...)
+}
+
+func (l *tracingLogger) Infoln(args ...interface{}) {
+	l.logger().Infoln(args...)
+}
+
+func (l *tracingLogger) Infof(format string, args ...interface{}) {
+	l.logger().Infof(format, args...)
+}
+
+func (l *tracingLogger) Warning(args ...interface{}) {
+	l.logger().Warning(args...)
+}
+
+func (l *tracingLogger) Warningln(args ...interface{}) {
+	l.logger().Warningln(args...)
+}
+
+func (l *tracingLogger) Warningf(format string, args ...interface{}) {
+	l.logger().Warningf(format, args...)
+}
+
+func (l *tracingLogger) Error(args ...interface{}) {
+	l.logger().Error(args...)
+}
+
+func (l *tracingLogger) Errorln(args ...interface{}) {
+	l.logger().Errorln(args...)
+}
+
+func (l *tracingLogger) Errorf(format string, args ...interface{}) {
+	l.logger().Errorf(format, args...)
+}
+
+func (l *tracingLogger) Fatal(args ...interface{}) {
+	l.logger().Fatal(args...)
+}
+
+func (l *tracingLogger) Fatalln(args ...interface{}) {
+	l.logger().Fatalln(args...)
+}
+
+func (l *tracingLogger) Fatalf(format string, args ...interface{}) {
+	l.logger().Fatalf(format, args...)
+}
+
+func (l *tracingLogger) V(level int) bool {
+	return l.logger().V(level)
+}
@@ -0,0 +1,52 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"io"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zapgrpc"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc/grpclog"
+)
+
+func TestTracingLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := &tracingLogger{
+		on:  zapgrpc.NewLogger(zap.New(core)),
+		off: grpclog.NewLoggerV2(io.Discard, io.Discard, io.Discard),
+	}
+
+	l.Info("disabled")
+	if logs.Len() != 0 {
+		t.Fatalf("expected no logs while tracing is disabled, got %d", logs.Len())
+	}
+
+	l.setEnabled(true)
+	l.Infof("enabled %d", 1)
+	l.Warning("enabled")
+	if logs.Len() != 2 {
+		t.Fatalf("expected 2 logs while tracing is enabled, got %d", logs.Len())
+	}
+
+	l.setEnabled(false)
+	l.Error("disabled")
+	if logs.Len() != 2 || l.isEnabled() {
+		t.Fatalf("expected tracing to be disabled, got %d logs", logs.Len())
+	}
+}
@@ -53,6 +53,10 @@ func newUnaryInterceptor(s *etcdserver.EtcdServer) grpc.UnaryServerInterceptor {
 			return nil, rpctypes.ErrGRPCNotSupportedForLearner
 		}
 
+		if s.IsWitness() && !isRPCSupportedForWitness(req) {
+			return nil, rpctypes.ErrGRPCNotSupportedForWitness
+		}
+
 		md, ok := metadata.FromIncomingContext(ctx)
 		if ok {
 			ver, vs := "unknown", md.Get(rpctypes.MetadataClientAPIVersionKey)
@@ -69,6 +73,10 @@ func newUnaryInterceptor(s *etcdserver.EtcdServer) grpc.UnaryServerInterceptor {
 					return nil, rpctypes.ErrGRPCNoLeader
 				}
 			}
+
+			if vs := md.Get(rpctypes.MetadataDebugTraceKey); len(vs) > 0 && vs[0] == rpctypes.MetadataDebugTraceEnabled {
+				return debugTraceUnary(ctx, s, req, handler)
+			}
 		}
 
 		return handler(ctx, req)
@@ -222,6 +230,15 @@ func newStreamInterceptor(s *etcdserver.EtcdServer) grpc.StreamServerInterceptor
 			return rpctypes.ErrGRPCNotSupportedForLearner
 		}
 
+		if s.IsWitness() { // witness keeps no KV data to watch or snapshot
+			return rpctypes.ErrGRPCNotSupportedForWitness
+		}
+
+		// no new streams while draining; clients reconnect to other members
+		if s.IsDraining() {
+			return rpctypes.ErrGRPCStopped
+		}
+
 		md, ok := metadata.FromIncomingContext(ss.Context())
 		if ok {
 			ver, vs := "unknown", md.Get(rpctypes.MetadataClientAPIVersionKey)
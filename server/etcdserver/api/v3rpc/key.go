@@ -16,37 +16,60 @@
 package v3rpc
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"math"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.etcd.io/etcd/pkg/v3/adt"
 	"go.etcd.io/etcd/server/v3/etcdserver"
+	"go.etcd.io/etcd/server/v3/etcdserver/hotkey"
+	"go.etcd.io/etcd/server/v3/lease"
 )
 
 type kvServer struct {
 	hdr header
 	kv  etcdserver.RaftKV
+	ag  AuthGetter
 	// maxTxnOps is the max operations per txn.
 	// e.g suppose maxTxnOps = 128.
 	// Txn.Success can have at most 128 operations,
 	// and Txn.Failure can have at most 128 operations.
 	maxTxnOps uint
+	// hotKeys samples the keys accessed by the requests, nil if disabled.
+	// Only the first key of a range is sampled, and Compact is not.
+	hotKeys *hotkey.Sampler
 }
 
 func NewKVServer(s *etcdserver.EtcdServer) pb.KVServer {
-	return &kvServer{hdr: newHeader(s), kv: s, maxTxnOps: s.Cfg.MaxTxnOps}
+	return &kvServer{hdr: newHeader(s), kv: s, ag: s, maxTxnOps: s.Cfg.MaxTxnOps, hotKeys: s.HotKeys()}
 }
 
 func (s *kvServer) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeResponse, error) {
 	if err := checkRangeRequest(r); err != nil {
 		return nil, err
 	}
+	r.Key, r.RangeEnd = s.resolveHomeKey(ctx, r.Key, r.RangeEnd)
+	if len(r.ContinueToken) != 0 {
+		if err := continueRange(r); err != nil {
+			return nil, err
+		}
+	}
 
 	resp, err := s.kv.Range(ctx, r)
 	if err != nil {
 		return nil, togRPCError(err)
 	}
+	s.hotKeys.Record(hotkey.Read, r.Key)
+	if resp.More && len(resp.Kvs) != 0 && isSortedByKey(r) {
+		rev := r.Revision
+		if rev == 0 {
+			rev = resp.Header.Revision
+		}
+		resp.ContinueToken = encodeContinueToken(rev, resp.Kvs[len(resp.Kvs)-1].Key)
+	}
 
 	s.hdr.fill(resp.Header)
 	return resp, nil
@@ -56,11 +79,120 @@ func (s *kvServer) Put(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse,
 	if err := checkPutRequest(r); err != nil {
 		return nil, err
 	}
+	r.Key, _ = s.resolveHomeKey(ctx, r.Key, nil)
 
 	resp, err := s.kv.Put(ctx, r)
 	if err != nil {
 		return nil, togRPCError(err)
 	}
+	s.hotKeys.Record(hotkey.Write, r.Key)
+
+	s.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (s *kvServer) Move(ctx context.Context, r *pb.MoveRequest) (*pb.MoveResponse, error) {
+	if err := checkMoveRequest(r); err != nil {
+		return nil, err
+	}
+	r.Key, _ = s.resolveHomeKey(ctx, r.Key, nil)
+	r.Destination, _ = s.resolveHomeKey(ctx, r.Destination, nil)
+
+	resp, err := s.kv.Move(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	s.hotKeys.Record(hotkey.Write, r.Key)
+	s.hotKeys.Record(hotkey.Write, r.Destination)
+
+	s.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (s *kvServer) SetLease(ctx context.Context, r *pb.SetLeaseRequest) (*pb.SetLeaseResponse, error) {
+	if err := checkSetLeaseRequest(r); err != nil {
+		return nil, err
+	}
+	r.Key, r.RangeEnd = s.resolveHomeKey(ctx, r.Key, r.RangeEnd)
+
+	resp, err := s.kv.SetLease(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	s.hotKeys.Record(hotkey.Write, r.Key)
+
+	s.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (s *kvServer) Increment(ctx context.Context, r *pb.IncrementRequest) (*pb.IncrementResponse, error) {
+	if err := checkIncrementRequest(r); err != nil {
+		return nil, err
+	}
+	r.Key, _ = s.resolveHomeKey(ctx, r.Key, nil)
+
+	resp, err := s.kv.Increment(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	s.hotKeys.Record(hotkey.Write, r.Key)
+
+	s.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (s *kvServer) PutIfAbsent(ctx context.Context, r *pb.PutIfAbsentRequest) (*pb.PutIfAbsentResponse, error) {
+	if err := checkPutIfAbsentRequest(r); err != nil {
+		return nil, err
+	}
+	r.Key, _ = s.resolveHomeKey(ctx, r.Key, nil)
+
+	resp, err := s.kv.PutIfAbsent(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	s.hotKeys.Record(hotkey.Write, r.Key)
+
+	s.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (s *kvServer) GetAndDelete(ctx context.Context, r *pb.GetAndDeleteRequest) (*pb.GetAndDeleteResponse, error) {
+	if len(r.Key) == 0 {
+		return nil, rpctypes.ErrGRPCEmptyKey
+	}
+	r.Key, _ = s.resolveHomeKey(ctx, r.Key, nil)
+
+	resp, err := s.kv.GetAndDelete(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	s.hotKeys.Record(hotkey.Write, r.Key)
+
+	s.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (s *kvServer) BulkWrite(ctx context.Context, r *pb.BulkWriteRequest) (*pb.BulkWriteResponse, error) {
+	if err := checkBulkWriteRequest(r); err != nil {
+		return nil, err
+	}
+	for _, op := range r.Ops {
+		switch tv := op.Request.(type) {
+		case *pb.RequestOp_RequestPut:
+			tv.RequestPut.Key, _ = s.resolveHomeKey(ctx, tv.RequestPut.Key, nil)
+		case *pb.RequestOp_RequestDeleteRange:
+			tv.RequestDeleteRange.Key, tv.RequestDeleteRange.RangeEnd = s.resolveHomeKey(ctx, tv.RequestDeleteRange.Key, tv.RequestDeleteRange.RangeEnd)
+		}
+	}
+
+	resp, err := s.kv.BulkWrite(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	if s.hotKeys != nil {
+		recordBulkWriteKeys(s.hotKeys, r, resp)
+	}
 
 	s.hdr.fill(resp.Header)
 	return resp, nil
@@ -70,11 +202,13 @@ func (s *kvServer) DeleteRange(ctx context.Context, r *pb.DeleteRangeRequest) (*
 	if err := checkDeleteRequest(r); err != nil {
 		return nil, err
 	}
+	r.Key, r.RangeEnd = s.resolveHomeKey(ctx, r.Key, r.RangeEnd)
 
 	resp, err := s.kv.DeleteRange(ctx, r)
 	if err != nil {
 		return nil, togRPCError(err)
 	}
+	s.hotKeys.Record(hotkey.Write, r.Key)
 
 	s.hdr.fill(resp.Header)
 	return resp, nil
@@ -91,11 +225,15 @@ func (s *kvServer) Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnResponse,
 	if _, _, err := checkIntervals(r.Failure); err != nil {
 		return nil, err
 	}
+	s.resolveTxnHomeKeys(ctx, r)
 
 	resp, err := s.kv.Txn(ctx, r)
 	if err != nil {
 		return nil, togRPCError(err)
 	}
+	if s.hotKeys != nil {
+		recordTxnKeys(s.hotKeys, r, resp)
+	}
 
 	s.hdr.fill(resp.Header)
 	return resp, nil
@@ -111,6 +249,37 @@ func (s *kvServer) Compact(ctx context.Context, r *pb.CompactionRequest) (*pb.Co
 	return resp, nil
 }
 
+// resolveHomeKey scopes a relative key range under the home prefix of the
+// requesting user, if the user has one configured.
+func (s *kvServer) resolveHomeKey(ctx context.Context, key, rangeEnd []byte) ([]byte, []byte) {
+	authInfo, err := s.ag.AuthInfoFromCtx(ctx)
+	if err != nil || authInfo == nil {
+		// invalid tokens are rejected later by the permission checks
+		return key, rangeEnd
+	}
+	return s.ag.AuthStore().ResolveHomeKey(authInfo, key, rangeEnd)
+}
+
+func (s *kvServer) resolveTxnHomeKeys(ctx context.Context, r *pb.TxnRequest) {
+	for _, c := range r.Compare {
+		c.Key, c.RangeEnd = s.resolveHomeKey(ctx, c.Key, c.RangeEnd)
+	}
+	for _, ops := range [][]*pb.RequestOp{r.Success, r.Failure} {
+		for _, op := range ops {
+			switch tv := op.Request.(type) {
+			case *pb.RequestOp_RequestRange:
+				tv.RequestRange.Key, tv.RequestRange.RangeEnd = s.resolveHomeKey(ctx, tv.RequestRange.Key, tv.RequestRange.RangeEnd)
+			case *pb.RequestOp_RequestPut:
+				tv.RequestPut.Key, _ = s.resolveHomeKey(ctx, tv.RequestPut.Key, nil)
+			case *pb.RequestOp_RequestDeleteRange:
+				tv.RequestDeleteRange.Key, tv.RequestDeleteRange.RangeEnd = s.resolveHomeKey(ctx, tv.RequestDeleteRange.Key, tv.RequestDeleteRange.RangeEnd)
+			case *pb.RequestOp_RequestTxn:
+				s.resolveTxnHomeKeys(ctx, tv.RequestTxn)
+			}
+		}
+	}
+}
+
 func checkRangeRequest(r *pb.RangeRequest) error {
 	if len(r.Key) == 0 {
 		return rpctypes.ErrGRPCEmptyKey
@@ -127,6 +296,51 @@ func checkRangeRequest(r *pb.RangeRequest) error {
 	return nil
 }
 
+// continueTokenVersion is the version of the format of the continue tokens.
+const continueTokenVersion = 1
+
+// encodeContinueToken returns the token to get the page of a range following
+// lastKey at rev: its version, then rev as an uvarint, then lastKey.
+func encodeContinueToken(rev int64, lastKey []byte) []byte {
+	token := make([]byte, 1+binary.MaxVarintLen64+len(lastKey))
+	token[0] = continueTokenVersion
+	n := 1 + binary.PutUvarint(token[1:], uint64(rev))
+	return append(token[:n], lastKey...)
+}
+
+// continueRange makes r get the page of its range following the one of its
+// continue token, at the revision of the first page.
+func continueRange(r *pb.RangeRequest) error {
+	if len(r.ContinueToken) < 3 || r.ContinueToken[0] != continueTokenVersion {
+		return rpctypes.ErrGRPCInvalidContinueToken
+	}
+	rev, n := binary.Uvarint(r.ContinueToken[1:])
+	if n <= 0 || rev == 0 || rev > math.MaxInt64 {
+		return rpctypes.ErrGRPCInvalidContinueToken
+	}
+	lastKey := r.ContinueToken[1+n:]
+	// the last key must be in the range, which must be sorted by key
+	switch {
+	case len(lastKey) == 0, len(r.RangeEnd) == 0, !isSortedByKey(r):
+		return rpctypes.ErrGRPCInvalidContinueToken
+	case bytes.Compare(lastKey, r.Key) < 0:
+		return rpctypes.ErrGRPCInvalidContinueToken
+	case !bytes.Equal(r.RangeEnd, []byte{0}) && bytes.Compare(lastKey, r.RangeEnd) >= 0:
+		return rpctypes.ErrGRPCInvalidContinueToken
+	case r.Revision != 0 && r.Revision != int64(rev):
+		return rpctypes.ErrGRPCInvalidContinueToken
+	}
+	r.Key = append(append([]byte{}, lastKey...), 0)
+	r.Revision = int64(rev)
+	return nil
+}
+
+// isSortedByKey returns whether the keys of the response to r are sorted by
+// key in ascending order.
+func isSortedByKey(r *pb.RangeRequest) bool {
+	return r.SortTarget == pb.RangeRequest_KEY && r.SortOrder != pb.RangeRequest_DESCEND
+}
+
 func checkPutRequest(r *pb.PutRequest) error {
 	if len(r.Key) == 0 {
 		return rpctypes.ErrGRPCEmptyKey
@@ -137,6 +351,12 @@ func checkPutRequest(r *pb.PutRequest) error {
 	if r.IgnoreLease && r.Lease != 0 {
 		return rpctypes.ErrGRPCLeaseProvided
 	}
+	if r.Ttl < 0 || r.ExpireTime < 0 || (r.Ttl != 0 && r.ExpireTime != 0) {
+		return rpctypes.ErrGRPCInvalidKeyTTL
+	}
+	if isReservedKey(r.Key) {
+		return rpctypes.ErrGRPCKeyReserved
+	}
 	return nil
 }
 
@@ -147,6 +367,106 @@ func checkDeleteRequest(r *pb.DeleteRangeRequest) error {
 	return nil
 }
 
+func checkMoveRequest(r *pb.MoveRequest) error {
+	if len(r.Key) == 0 || len(r.Destination) == 0 {
+		return rpctypes.ErrGRPCEmptyKey
+	}
+	if isReservedKey(r.Destination) {
+		return rpctypes.ErrGRPCKeyReserved
+	}
+	return nil
+}
+
+// moveRangeEnd returns the range end of the keys moved by r, nil if it
+// moves a single key.
+func moveRangeEnd(r *pb.MoveRequest) []byte {
+	if !r.Prefix {
+		return nil
+	}
+	return prefixRangeEnd(r.Key)
+}
+
+// moveDestinationRangeEnd returns the range end of the keys r moves to, nil
+// if it moves a single key.
+func moveDestinationRangeEnd(r *pb.MoveRequest) []byte {
+	if !r.Prefix {
+		return nil
+	}
+	return prefixRangeEnd(r.Destination)
+}
+
+func prefixRangeEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return []byte{0}
+}
+
+// isReservedKey returns whether key is reserved to the virtual keys the
+// lease events are watched on.
+func isReservedKey(key []byte) bool {
+	return bytes.HasPrefix(key, []byte(lease.EventsPrefix))
+}
+
+func checkSetLeaseRequest(r *pb.SetLeaseRequest) error {
+	if len(r.Key) == 0 {
+		return rpctypes.ErrGRPCEmptyKey
+	}
+	return nil
+}
+
+func checkIncrementRequest(r *pb.IncrementRequest) error {
+	if len(r.Key) == 0 {
+		return rpctypes.ErrGRPCEmptyKey
+	}
+	if isReservedKey(r.Key) {
+		return rpctypes.ErrGRPCKeyReserved
+	}
+	return nil
+}
+
+func checkPutIfAbsentRequest(r *pb.PutIfAbsentRequest) error {
+	if len(r.Key) == 0 {
+		return rpctypes.ErrGRPCEmptyKey
+	}
+	if isReservedKey(r.Key) {
+		return rpctypes.ErrGRPCKeyReserved
+	}
+	return nil
+}
+
+// checkBulkWriteRequest checks the requests of a bulk write as the ones of
+// a txn, which only puts and deletes.
+func checkBulkWriteRequest(r *pb.BulkWriteRequest) error {
+	for _, op := range r.Ops {
+		switch tv := op.Request.(type) {
+		case *pb.RequestOp_RequestPut:
+			if tv.RequestPut == nil {
+				return rpctypes.ErrGRPCBulkWriteOpNotSupported
+			}
+			if err := checkPutRequest(tv.RequestPut); err != nil {
+				return err
+			}
+		case *pb.RequestOp_RequestDeleteRange:
+			if tv.RequestDeleteRange == nil {
+				return rpctypes.ErrGRPCBulkWriteOpNotSupported
+			}
+			if err := checkDeleteRequest(tv.RequestDeleteRange); err != nil {
+				return err
+			}
+		default:
+			return rpctypes.ErrGRPCBulkWriteOpNotSupported
+		}
+	}
+	_, _, err := checkIntervals(r.Ops)
+	return err
+}
+
 func checkTxnRequest(r *pb.TxnRequest, maxTxnOps int) error {
 	opc := len(r.Compare)
 	if opc < len(r.Success) {
@@ -266,6 +586,9 @@ func checkRequestOp(u *pb.RequestOp, maxTxnOps int) error {
 	// TODO: ensure only one of the field is set.
 	switch uv := u.Request.(type) {
 	case *pb.RequestOp_RequestRange:
+		if len(uv.RequestRange.ContinueToken) != 0 {
+			return rpctypes.ErrGRPCInvalidContinueToken
+		}
 		return checkRangeRequest(uv.RequestRange)
 	case *pb.RequestOp_RequestPut:
 		return checkPutRequest(uv.RequestPut)
@@ -278,3 +601,46 @@ func checkRequestOp(u *pb.RequestOp, maxTxnOps int) error {
 		return rpctypes.ErrGRPCKeyNotFound
 	}
 }
+
+// recordTxnKeys samples the keys compared by r and accessed by the
+// operations of the branch it executed.
+// recordBulkWriteKeys records the keys of the requests of r applied.
+func recordBulkWriteKeys(hk *hotkey.Sampler, r *pb.BulkWriteRequest, resp *pb.BulkWriteResponse) {
+	for i, op := range r.Ops {
+		if i < len(resp.Results) && resp.Results[i].Error != "" {
+			continue
+		}
+		switch tv := op.Request.(type) {
+		case *pb.RequestOp_RequestPut:
+			hk.Record(hotkey.Write, tv.RequestPut.Key)
+		case *pb.RequestOp_RequestDeleteRange:
+			hk.Record(hotkey.Write, tv.RequestDeleteRange.Key)
+		}
+	}
+}
+
+func recordTxnKeys(hk *hotkey.Sampler, r *pb.TxnRequest, resp *pb.TxnResponse) {
+	for _, c := range r.Compare {
+		hk.Record(hotkey.Read, c.Key)
+	}
+	ops := r.Failure
+	if resp.Succeeded {
+		ops = r.Success
+	}
+	for i, op := range ops {
+		switch tv := op.Request.(type) {
+		case *pb.RequestOp_RequestRange:
+			hk.Record(hotkey.Read, tv.RequestRange.Key)
+		case *pb.RequestOp_RequestPut:
+			hk.Record(hotkey.Write, tv.RequestPut.Key)
+		case *pb.RequestOp_RequestDeleteRange:
+			hk.Record(hotkey.Write, tv.RequestDeleteRange.Key)
+		case *pb.RequestOp_RequestTxn:
+			if i < len(resp.Responses) {
+				if tresp := resp.Responses[i].GetResponseTxn(); tresp != nil {
+					recordTxnKeys(hk, tv.RequestTxn, tresp)
+				}
+			}
+		}
+	}
+}
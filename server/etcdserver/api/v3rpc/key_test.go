@@ -65,3 +65,38 @@ func getError(err error) string {
 
 	return err.Error()
 }
+
+func TestContinueRange(t *testing.T) {
+	token := encodeContinueToken(5, []byte("foo2"))
+	r := &pb.RangeRequest{Key: []byte("foo"), RangeEnd: []byte("fop"), Limit: 2, ContinueToken: token}
+	if err := continueRange(r); err != nil {
+		t.Fatal(err)
+	}
+	if string(r.Key) != "foo2\x00" || r.Revision != 5 {
+		t.Errorf("expected range from %q at revision 5, got from %q at revision %d", "foo2\x00", r.Key, r.Revision)
+	}
+
+	tests := []struct {
+		name string
+		r    *pb.RangeRequest
+	}{
+		{"corrupted token", &pb.RangeRequest{Key: []byte("foo"), RangeEnd: []byte("fop"), ContinueToken: token[:2]}},
+		{"unknown version", &pb.RangeRequest{Key: []byte("foo"), RangeEnd: []byte("fop"), ContinueToken: append([]byte{2}, token[1:]...)}},
+		{"single key", &pb.RangeRequest{Key: []byte("foo"), ContinueToken: token}},
+		{"key before the range", &pb.RangeRequest{Key: []byte("foo3"), RangeEnd: []byte("fop"), ContinueToken: token}},
+		{"key after the range", &pb.RangeRequest{Key: []byte("foo"), RangeEnd: []byte("foo1"), ContinueToken: token}},
+		{"other revision", &pb.RangeRequest{Key: []byte("foo"), RangeEnd: []byte("fop"), Revision: 4, ContinueToken: token}},
+		{"sorted by value", &pb.RangeRequest{Key: []byte("foo"), RangeEnd: []byte("fop"), SortTarget: pb.RangeRequest_VALUE, ContinueToken: token}},
+		{"sorted descending", &pb.RangeRequest{Key: []byte("foo"), RangeEnd: []byte("fop"), SortOrder: pb.RangeRequest_DESCEND, ContinueToken: token}},
+	}
+	for _, tt := range tests {
+		if err := continueRange(tt.r); err != rpctypes.ErrGRPCInvalidContinueToken {
+			t.Errorf("%s: expected %v, got %v", tt.name, rpctypes.ErrGRPCInvalidContinueToken, err)
+		}
+	}
+
+	r = &pb.RangeRequest{Key: []byte("foo"), RangeEnd: []byte{0}, Revision: 5, ContinueToken: token}
+	if err := continueRange(r); err != nil {
+		t.Errorf("expected the range from key to continue, got %v", err)
+	}
+}
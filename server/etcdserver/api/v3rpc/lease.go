@@ -59,6 +59,24 @@ func (ls *LeaseServer) LeaseRevoke(ctx context.Context, rr *pb.LeaseRevokeReques
 	return resp, nil
 }
 
+func (ls *LeaseServer) LeaseGrantBulk(ctx context.Context, cr *pb.LeaseGrantBulkRequest) (*pb.LeaseGrantBulkResponse, error) {
+	resp, err := ls.le.LeaseGrantBulk(ctx, cr)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	ls.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ls *LeaseServer) LeaseRevokeBulk(ctx context.Context, rr *pb.LeaseRevokeBulkRequest) (*pb.LeaseRevokeBulkResponse, error) {
+	resp, err := ls.le.LeaseRevokeBulk(ctx, rr)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	ls.hdr.fill(resp.Header)
+	return resp, nil
+}
+
 func (ls *LeaseServer) LeaseTimeToLive(ctx context.Context, rr *pb.LeaseTimeToLiveRequest) (*pb.LeaseTimeToLiveResponse, error) {
 	resp, err := ls.le.LeaseTimeToLive(ctx, rr)
 	if err != nil && err != lease.ErrLeaseNotFound {
@@ -155,3 +173,88 @@ func (ls *LeaseServer) leaseKeepAlive(stream pb.Lease_LeaseKeepAliveServer) erro
 		}
 	}
 }
+
+func (ls *LeaseServer) LeaseKeepAliveOnce(ctx context.Context, req *pb.LeaseKeepAliveRequest) (*pb.LeaseKeepAliveResponse, error) {
+	// Create header before renewing the lease, like leaseKeepAlive.
+	resp := &pb.LeaseKeepAliveResponse{ID: req.ID, Header: &pb.ResponseHeader{}}
+	ls.hdr.fill(resp.Header)
+
+	ttl, err := ls.le.LeaseKeepAliveOnce(ctx, lease.LeaseID(req.ID))
+	if err == lease.ErrLeaseNotFound {
+		err = nil
+		ttl = 0
+	}
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	resp.TTL = ttl
+	return resp, nil
+}
+
+func (ls *LeaseServer) LeaseKeepAliveBatch(stream pb.Lease_LeaseKeepAliveBatchServer) (err error) {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- ls.leaseKeepAliveBatch(stream)
+	}()
+	select {
+	case err = <-errc:
+	case <-stream.Context().Done():
+		// the only server-side cancellation is noleader for now.
+		err = stream.Context().Err()
+		if err == context.Canceled {
+			err = rpctypes.ErrGRPCNoLeader
+		}
+	}
+	return err
+}
+
+func (ls *LeaseServer) leaseKeepAliveBatch(stream pb.Lease_LeaseKeepAliveBatchServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if isClientCtxErr(stream.Context().Err(), err) {
+				ls.lg.Debug("failed to receive lease keepalive batch request from gRPC stream", zap.Error(err))
+			} else {
+				ls.lg.Warn("failed to receive lease keepalive batch request from gRPC stream", zap.Error(err))
+				streamFailures.WithLabelValues("receive", "lease-keepalive-batch").Inc()
+			}
+			return err
+		}
+
+		// Create header before we sent out the renew request, see leaseKeepAlive.
+		resp := &pb.LeaseKeepAliveBatchResponse{Header: &pb.ResponseHeader{}}
+		ls.hdr.fill(resp.Header)
+
+		ids := make([]lease.LeaseID, len(req.IDs))
+		for i, id := range req.IDs {
+			ids[i] = lease.LeaseID(id)
+		}
+		results, err := ls.le.LeaseRenewBatch(stream.Context(), ids)
+		if err != nil {
+			return togRPCError(err)
+		}
+
+		resp.Responses = make([]*pb.LeaseKeepAliveResponse, len(results))
+		for i, r := range results {
+			ttl := r.TTL
+			if r.Err == lease.ErrLeaseNotFound {
+				ttl = 0
+			}
+			resp.Responses[i] = &pb.LeaseKeepAliveResponse{ID: int64(r.ID), TTL: ttl}
+		}
+
+		err = stream.Send(resp)
+		if err != nil {
+			if isClientCtxErr(stream.Context().Err(), err) {
+				ls.lg.Debug("failed to send lease keepalive batch response to gRPC stream", zap.Error(err))
+			} else {
+				ls.lg.Warn("failed to send lease keepalive batch response to gRPC stream", zap.Error(err))
+				streamFailures.WithLabelValues("send", "lease-keepalive-batch").Inc()
+			}
+			return err
+		}
+	}
+}
@@ -18,21 +18,29 @@ import (
 	"context"
 	"crypto/sha256"
 	"io"
+	"sort"
 	"time"
 
 	"github.com/dustin/go-humanize"
+	"go.etcd.io/etcd/api/v3/authpb"
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.etcd.io/etcd/api/v3/version"
+	"go.etcd.io/etcd/client/pkg/v3/types"
 	"go.etcd.io/etcd/raft/v3"
 	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/etcdserver"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/clusterhistory"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/prefixquota"
+	"go.etcd.io/etcd/server/v3/etcdserver/hotkey"
 	serverversion "go.etcd.io/etcd/server/v3/etcdserver/version"
+	"go.etcd.io/etcd/server/v3/storage"
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
 	"go.etcd.io/etcd/server/v3/storage/schema"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 type KVGetter interface {
@@ -50,6 +58,11 @@ type Alarmer interface {
 	Alarm(ctx context.Context, ar *pb.AlarmRequest) (*pb.AlarmResponse, error)
 }
 
+type PrefixQuotaSetter interface {
+	PrefixQuotaSet(ctx context.Context, r *pb.PrefixQuotaSetRequest) (*pb.PrefixQuotaSetResponse, error)
+	PrefixQuotaDelete(ctx context.Context, r *pb.PrefixQuotaDeleteRequest) (*pb.PrefixQuotaDeleteResponse, error)
+}
+
 type Downgrader interface {
 	Downgrade(ctx context.Context, dr *pb.DowngradeRequest) (*pb.DowngradeResponse, error)
 }
@@ -67,6 +80,17 @@ type ClusterStatusGetter interface {
 	IsLearner() bool
 }
 
+type FlowControlGetter interface {
+	// FollowerFlowControl returns the flow control of the followers, nil if not leader.
+	FollowerFlowControl() []*pb.FollowerFlowControl
+}
+
+type LearnerStatusGetter interface {
+	// LearnerStatus returns the progress of the learner id, or of all the
+	// learners if id is 0. It fails if not leader.
+	LearnerStatus(id uint64) ([]*pb.LearnerProgress, error)
+}
+
 type maintenanceServer struct {
 	lg  *zap.Logger
 	rg  etcdserver.RaftStatusGetter
@@ -76,12 +100,28 @@ type maintenanceServer struct {
 	lt  LeaderTransferrer
 	hdr header
 	cs  ClusterStatusGetter
+	fc  FlowControlGetter
+	ls  LearnerStatusGetter
 	d   Downgrader
+	pq  PrefixQuotaSetter
 	vs  serverversion.Server
+
+	// quotaBytes is the backend space quota, zero if disabled.
+	quotaBytes int64
+	// logLevel is the level of lg, nil if it cannot be changed at runtime.
+	logLevel *zap.AtomicLevel
+	// hotKeys samples the key accesses of client requests, nil if disabled.
+	hotKeys *hotkey.Sampler
+	// history is the history of the cluster events recorded by the member.
+	history *clusterhistory.History
+	// runtimeConfig is the configuration the member was started with.
+	runtimeConfig map[string]string
+	// prefixQuotas are the quotas of the key prefixes with their usage.
+	prefixQuotas *prefixquota.Store
 }
 
 func NewMaintenanceServer(s *etcdserver.EtcdServer) pb.MaintenanceServer {
-	srv := &maintenanceServer{lg: s.Cfg.Logger, rg: s, kg: s, bg: s, a: s, lt: s, hdr: newHeader(s), cs: s, d: s, vs: etcdserver.NewServerVersionAdapter(s)}
+	srv := &maintenanceServer{lg: s.Cfg.Logger, rg: s, kg: s, bg: s, a: s, lt: s, hdr: newHeader(s), cs: s, fc: s, ls: s, d: s, pq: s, vs: etcdserver.NewServerVersionAdapter(s), quotaBytes: storage.QuotaBackendBytes(s.Cfg), logLevel: s.Cfg.LoggerLevel, hotKeys: s.HotKeys(), history: s.ClusterHistory(), runtimeConfig: s.Cfg.RuntimeConfig, prefixQuotas: s.PrefixQuotas()}
 	if srv.lg == nil {
 		srv.lg = zap.NewNop()
 	}
@@ -99,6 +139,23 @@ func (ms *maintenanceServer) Defragment(ctx context.Context, sr *pb.DefragmentRe
 	return &pb.DefragmentResponse{}, nil
 }
 
+func (ms *maintenanceServer) DefragmentStatus(ctx context.Context, r *pb.DefragmentStatusRequest) (*pb.DefragmentStatusResponse, error) {
+	st := ms.bg.Backend().DefragStatus()
+	resp := &pb.DefragmentStatusResponse{
+		Header:      &pb.ResponseHeader{},
+		InProgress:  st.InProgress,
+		Phase:       st.Phase,
+		CopiedKeys:  st.CopiedKeys,
+		TotalKeys:   st.TotalKeys,
+		PendingKeys: st.PendingKeys,
+	}
+	if !st.Started.IsZero() {
+		resp.StartTime = st.Started.UnixNano()
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
 // big enough size to hold >1 OS pages in the buffer
 const snapshotSendBufferSize = 32 * 1024
 
@@ -236,7 +293,11 @@ func (ms *maintenanceServer) Status(ctx context.Context, ar *pb.StatusRequest) (
 		DbSize:           ms.bg.Backend().Size(),
 		DbSizeInUse:      ms.bg.Backend().SizeInUse(),
 		IsLearner:        ms.cs.IsLearner(),
+		Followers:        ms.fc.FollowerFlowControl(),
 	}
+	batchInterval, batchLimit := ms.bg.Backend().BatchLimits()
+	resp.BackendBatchIntervalMs = batchInterval.Milliseconds()
+	resp.BackendBatchLimit = int64(batchLimit)
 	if storageVersion := ms.vs.GetStorageVersion(); storageVersion != nil {
 		resp.StorageVersion = storageVersion.String()
 	}
@@ -270,22 +331,338 @@ func (ms *maintenanceServer) Downgrade(ctx context.Context, r *pb.DowngradeReque
 	return resp, nil
 }
 
+func (ms *maintenanceServer) BackendBatch(ctx context.Context, r *pb.BackendBatchRequest) (*pb.BackendBatchResponse, error) {
+	if r.BatchIntervalMs > backend.MaxBatchInterval.Milliseconds() {
+		return nil, togRPCError(backend.ErrInvalidBatchInterval)
+	}
+	if r.BatchLimit > backend.MaxBatchLimit {
+		return nil, togRPCError(backend.ErrInvalidBatchLimit)
+	}
+	interval := time.Duration(r.BatchIntervalMs) * time.Millisecond
+	if err := backend.ValidateBatchLimits(interval, int(r.BatchLimit)); err != nil {
+		return nil, togRPCError(err)
+	}
+	be := ms.bg.Backend()
+	be.SetBatchLimits(interval, int(r.BatchLimit))
+	batchInterval, batchLimit := be.BatchLimits()
+	resp := &pb.BackendBatchResponse{
+		Header:          &pb.ResponseHeader{},
+		BatchIntervalMs: batchInterval.Milliseconds(),
+		BatchLimit:      int64(batchLimit),
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) QuotaStatus(ctx context.Context, r *pb.QuotaStatusRequest) (*pb.QuotaStatusResponse, error) {
+	resp := &pb.QuotaStatusResponse{
+		Header:            &pb.ResponseHeader{},
+		QuotaBackendBytes: ms.quotaBytes,
+		DbSize:            ms.bg.Backend().Size(),
+		DbSizeInUse:       ms.bg.Backend().SizeInUse(),
+		ApplyBacklogLimit: etcdserver.MaxGapBetweenApplyAndCommitIndex,
+		Alarms:            ms.a.Alarms(),
+	}
+	if ci, ai := ms.rg.CommittedIndex(), ms.rg.AppliedIndex(); ci > ai {
+		resp.ApplyBacklog = ci - ai
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+// quotaAlarmResetHeadroomPercent is the share of the space quota that must be
+// free before the NOSPACE alarm can be reset, so that the next writes do not
+// immediately raise it again.
+const quotaAlarmResetHeadroomPercent = 5
+
+func (ms *maintenanceServer) ResetQuotaAlarm(ctx context.Context, r *pb.ResetQuotaAlarmRequest) (*pb.ResetQuotaAlarmResponse, error) {
+	id := uint64(ms.rg.ID())
+	armed := false
+	for _, a := range ms.a.Alarms() {
+		if a.MemberID == id && a.Alarm == pb.AlarmType_NOSPACE {
+			armed = true
+		}
+	}
+
+	if armed {
+		if ms.quotaBytes > 0 {
+			size := ms.bg.Backend().Size()
+			if size+ms.quotaBytes*quotaAlarmResetHeadroomPercent/100 >= ms.quotaBytes {
+				ms.lg.Warn(
+					"refused to reset NOSPACE alarm; backend is still close to the space quota",
+					zap.Int64("backend-size-bytes", size),
+					zap.Int64("quota-size-bytes", ms.quotaBytes),
+				)
+				return nil, rpctypes.ErrGRPCNoSpaceNotResolved
+			}
+		}
+		ar := &pb.AlarmRequest{MemberID: id, Action: pb.AlarmRequest_DEACTIVATE, Alarm: pb.AlarmType_NOSPACE}
+		if _, err := ms.a.Alarm(ctx, ar); err != nil {
+			return nil, togRPCError(err)
+		}
+		ms.lg.Info("reset NOSPACE alarm", zap.String("member-id", types.ID(id).String()))
+	}
+
+	resp := &pb.ResetQuotaAlarmResponse{Header: &pb.ResponseHeader{}, Alarms: ms.a.Alarms()}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) LogLevel(ctx context.Context, r *pb.LogLevelRequest) (*pb.LogLevelResponse, error) {
+	if r.Level != "" {
+		var level zapcore.Level
+		if err := level.Set(r.Level); err != nil {
+			return nil, rpctypes.ErrGRPCInvalidLogLevel
+		}
+		if ms.logLevel == nil {
+			return nil, rpctypes.ErrGRPCLogLevelNotAdjustable
+		}
+		if old := ms.logLevel.Level(); old != level {
+			ms.logLevel.SetLevel(level)
+			ms.lg.Info("changed log level", zap.Stringer("from", old), zap.Stringer("to", level))
+		}
+	}
+	if r.GrpcTracing != pb.LogLevelRequest_KEEP {
+		enable := r.GrpcTracing == pb.LogLevelRequest_ENABLE
+		if !setGRPCTracing(enable) {
+			return nil, rpctypes.ErrGRPCGRPCTracingNotAdjustable
+		}
+		ms.lg.Info("changed gRPC tracing", zap.Bool("enabled", enable))
+	}
+
+	resp := &pb.LogLevelResponse{Header: &pb.ResponseHeader{}}
+	if ms.logLevel != nil {
+		resp.Level = ms.logLevel.Level().String()
+	}
+	resp.GrpcTracing, _ = grpcTracing()
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) WatchStreams(ctx context.Context, r *pb.WatchStreamsRequest) (*pb.WatchStreamsResponse, error) {
+	stats := ms.kg.KV().WatchStreamStats()
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].RevisionLag != stats[j].RevisionLag {
+			return stats[i].RevisionLag > stats[j].RevisionLag
+		}
+		return stats[i].PendingBytes > stats[j].PendingBytes
+	})
+	if r.Limit > 0 && int64(len(stats)) > r.Limit {
+		stats = stats[:r.Limit]
+	}
+
+	resp := &pb.WatchStreamsResponse{Header: &pb.ResponseHeader{}, Streams: make([]*pb.WatchStreamStatus, len(stats))}
+	for i, st := range stats {
+		resp.Streams[i] = &pb.WatchStreamStatus{
+			Id:            st.ID,
+			Remote:        st.Label,
+			Watchers:      int64(st.Watchers),
+			PendingEvents: st.PendingEvents,
+			PendingBytes:  st.PendingBytes,
+			RevisionLag:   st.RevisionLag,
+		}
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) Watchers(ctx context.Context, r *pb.WatchersRequest) (*pb.WatchersResponse, error) {
+	stats := ms.kg.KV().WatcherStats(r.StreamId)
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].RevisionLag != stats[j].RevisionLag {
+			return stats[i].RevisionLag > stats[j].RevisionLag
+		}
+		if stats[i].StreamID != stats[j].StreamID {
+			return stats[i].StreamID < stats[j].StreamID
+		}
+		return stats[i].ID < stats[j].ID
+	})
+	if r.Limit > 0 && int64(len(stats)) > r.Limit {
+		stats = stats[:r.Limit]
+	}
+
+	resp := &pb.WatchersResponse{Header: &pb.ResponseHeader{}, Watchers: make([]*pb.WatcherStatus, len(stats))}
+	for i, st := range stats {
+		ranges := make([]*pb.WatchKeyRange, len(st.Ranges))
+		for j, kr := range st.Ranges {
+			ranges[j] = &pb.WatchKeyRange{Key: kr.Key, RangeEnd: kr.End}
+		}
+		resp.Watchers[i] = &pb.WatcherStatus{
+			StreamId:      st.StreamID,
+			WatchId:       int64(st.ID),
+			Remote:        st.StreamLabel,
+			User:          st.StreamUser,
+			Ranges:        ranges,
+			StartRevision: st.StartRev,
+			RevisionLag:   st.RevisionLag,
+		}
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) CancelWatcher(ctx context.Context, r *pb.CancelWatcherRequest) (*pb.CancelWatcherResponse, error) {
+	if err := ms.kg.KV().CancelWatcher(r.StreamId, mvcc.WatchID(r.WatchId)); err != nil {
+		if err == mvcc.ErrWatcherNotExist {
+			return nil, rpctypes.ErrGRPCWatcherNotFound
+		}
+		return nil, togRPCError(err)
+	}
+	ms.lg.Warn(
+		"canceled watcher",
+		zap.Int64("stream-id", r.StreamId),
+		zap.Int64("watch-id", r.WatchId),
+	)
+	resp := &pb.CancelWatcherResponse{Header: &pb.ResponseHeader{}}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) HotKeys(ctx context.Context, r *pb.HotKeysRequest) (*pb.HotKeysResponse, error) {
+	if ms.hotKeys == nil {
+		return nil, rpctypes.ErrGRPCHotKeySamplingDisabled
+	}
+	stats := ms.hotKeys.Top(int(r.Limit))
+
+	resp := &pb.HotKeysResponse{
+		Header:     &pb.ResponseHeader{},
+		WindowMs:   ms.hotKeys.Window().Milliseconds(),
+		SampleRate: ms.hotKeys.SampleRate(),
+		Prefixes:   make([]*pb.HotKeyPrefix, len(stats)),
+	}
+	for i, st := range stats {
+		resp.Prefixes[i] = &pb.HotKeyPrefix{Prefix: st.Prefix, Reads: st.Reads, Writes: st.Writes}
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) ClusterHistory(ctx context.Context, r *pb.ClusterHistoryRequest) (*pb.ClusterHistoryResponse, error) {
+	resp := &pb.ClusterHistoryResponse{
+		Header: &pb.ResponseHeader{},
+		Events: ms.history.Events(int(r.Limit)),
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) RuntimeConfig(ctx context.Context, r *pb.RuntimeConfigRequest) (*pb.RuntimeConfigResponse, error) {
+	resp := &pb.RuntimeConfigResponse{
+		Header:  &pb.ResponseHeader{},
+		Entries: make([]*pb.ConfigEntry, 0, len(ms.runtimeConfig)),
+	}
+	for name, value := range ms.runtimeConfig {
+		// the log level can be changed at runtime
+		if name == "log-level" && ms.logLevel != nil {
+			value = ms.logLevel.Level().String()
+		}
+		resp.Entries = append(resp.Entries, &pb.ConfigEntry{Name: name, Value: value})
+	}
+	sort.Slice(resp.Entries, func(i, j int) bool { return resp.Entries[i].Name < resp.Entries[j].Name })
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) PrefixQuotaSet(ctx context.Context, r *pb.PrefixQuotaSetRequest) (*pb.PrefixQuotaSetResponse, error) {
+	if len(r.Prefix) == 0 {
+		return nil, rpctypes.ErrGRPCEmptyKey
+	}
+	resp, err := ms.pq.PrefixQuotaSet(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	resp.Header = &pb.ResponseHeader{}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) PrefixQuotaDelete(ctx context.Context, r *pb.PrefixQuotaDeleteRequest) (*pb.PrefixQuotaDeleteResponse, error) {
+	if len(r.Prefix) == 0 {
+		return nil, rpctypes.ErrGRPCEmptyKey
+	}
+	resp, err := ms.pq.PrefixQuotaDelete(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	resp.Header = &pb.ResponseHeader{}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) PrefixQuotaList(ctx context.Context, r *pb.PrefixQuotaListRequest) (*pb.PrefixQuotaListResponse, error) {
+	resp := &pb.PrefixQuotaListResponse{
+		Header: &pb.ResponseHeader{},
+		Quotas: ms.prefixQuotas.List(),
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) PrefixStats(ctx context.Context, r *pb.PrefixStatsRequest) (*pb.PrefixStatsResponse, error) {
+	key, end := prefixRange(r.Prefix)
+	st, err := ms.kg.KV().Stats(ctx, key, end)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	resp := &pb.PrefixStatsResponse{
+		Header:     &pb.ResponseHeader{},
+		Count:      st.Count,
+		KeyBytes:   st.KeyBytes,
+		ValueBytes: st.ValueBytes,
+	}
+	ms.hdr.fill(resp.Header)
+	// the statistics are of the revision they were computed at
+	resp.Header.Revision = st.Rev
+	return resp, nil
+}
+
+func (ms *maintenanceServer) LearnerStatus(ctx context.Context, r *pb.LearnerStatusRequest) (*pb.LearnerStatusResponse, error) {
+	ls, err := ms.ls.LearnerStatus(r.ID)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	resp := &pb.LearnerStatusResponse{Header: &pb.ResponseHeader{}, Learners: ls}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+// prefixRange returns the range of the keys prefixed by prefix, every key if
+// it is empty.
+func prefixRange(prefix []byte) (key, end []byte) {
+	if len(prefix) == 0 {
+		return []byte{0}, []byte{}
+	}
+	end = make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return prefix, end[:i+1]
+		}
+	}
+	// the prefix is all 0xff, range to the last key
+	return prefix, []byte{}
+}
+
 type authMaintenanceServer struct {
 	*maintenanceServer
 	ag AuthGetter
 }
 
-func (ams *authMaintenanceServer) isAuthenticated(ctx context.Context) error {
+func (ams *authMaintenanceServer) isAuthenticated(ctx context.Context, perm authpb.AdminPermission) error {
 	authInfo, err := ams.ag.AuthInfoFromCtx(ctx)
 	if err != nil {
-		return err
+		return togRPCError(err)
 	}
 
-	return ams.ag.AuthStore().IsAdminPermitted(authInfo)
+	if err = ams.ag.AuthStore().IsAdminOpPermitted(authInfo, perm); err != nil {
+		return togRPCError(err)
+	}
+	return nil
 }
 
 func (ams *authMaintenanceServer) Defragment(ctx context.Context, sr *pb.DefragmentRequest) (*pb.DefragmentResponse, error) {
-	if err := ams.isAuthenticated(ctx); err != nil {
+	if err := ams.isAuthenticated(ctx, authpb.DEFRAGMENT); err != nil {
 		return nil, err
 	}
 
@@ -293,15 +670,25 @@ func (ams *authMaintenanceServer) Defragment(ctx context.Context, sr *pb.Defragm
 }
 
 func (ams *authMaintenanceServer) Snapshot(sr *pb.SnapshotRequest, srv pb.Maintenance_SnapshotServer) error {
-	if err := ams.isAuthenticated(srv.Context()); err != nil {
+	if err := ams.isAuthenticated(srv.Context(), authpb.SNAPSHOT); err != nil {
 		return err
 	}
 
 	return ams.maintenanceServer.Snapshot(sr, srv)
 }
 
+// Alarm requires the ALARM permission to activate or deactivate alarms.
+func (ams *authMaintenanceServer) Alarm(ctx context.Context, ar *pb.AlarmRequest) (*pb.AlarmResponse, error) {
+	if ar.Action != pb.AlarmRequest_GET {
+		if err := ams.isAuthenticated(ctx, authpb.ALARM); err != nil {
+			return nil, err
+		}
+	}
+	return ams.maintenanceServer.Alarm(ctx, ar)
+}
+
 func (ams *authMaintenanceServer) Hash(ctx context.Context, r *pb.HashRequest) (*pb.HashResponse, error) {
-	if err := ams.isAuthenticated(ctx); err != nil {
+	if err := ams.isAuthenticated(ctx, authpb.MAINTENANCE); err != nil {
 		return nil, err
 	}
 
@@ -309,7 +696,7 @@ func (ams *authMaintenanceServer) Hash(ctx context.Context, r *pb.HashRequest) (
 }
 
 func (ams *authMaintenanceServer) HashKV(ctx context.Context, r *pb.HashKVRequest) (*pb.HashKVResponse, error) {
-	if err := ams.isAuthenticated(ctx); err != nil {
+	if err := ams.isAuthenticated(ctx, authpb.MAINTENANCE); err != nil {
 		return nil, err
 	}
 	return ams.maintenanceServer.HashKV(ctx, r)
@@ -326,3 +713,112 @@ func (ams *authMaintenanceServer) MoveLeader(ctx context.Context, tr *pb.MoveLea
 func (ams *authMaintenanceServer) Downgrade(ctx context.Context, r *pb.DowngradeRequest) (*pb.DowngradeResponse, error) {
 	return ams.maintenanceServer.Downgrade(ctx, r)
 }
+
+func (ams *authMaintenanceServer) QuotaStatus(ctx context.Context, r *pb.QuotaStatusRequest) (*pb.QuotaStatusResponse, error) {
+	return ams.maintenanceServer.QuotaStatus(ctx, r)
+}
+
+func (ams *authMaintenanceServer) ResetQuotaAlarm(ctx context.Context, r *pb.ResetQuotaAlarmRequest) (*pb.ResetQuotaAlarmResponse, error) {
+	if err := ams.isAuthenticated(ctx, authpb.ALARM); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.ResetQuotaAlarm(ctx, r)
+}
+
+func (ams *authMaintenanceServer) LogLevel(ctx context.Context, r *pb.LogLevelRequest) (*pb.LogLevelResponse, error) {
+	if err := ams.isAuthenticated(ctx, authpb.MAINTENANCE); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.LogLevel(ctx, r)
+}
+
+func (ams *authMaintenanceServer) WatchStreams(ctx context.Context, r *pb.WatchStreamsRequest) (*pb.WatchStreamsResponse, error) {
+	if err := ams.isAuthenticated(ctx, authpb.MAINTENANCE); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.WatchStreams(ctx, r)
+}
+
+func (ams *authMaintenanceServer) Watchers(ctx context.Context, r *pb.WatchersRequest) (*pb.WatchersResponse, error) {
+	if err := ams.isAuthenticated(ctx, authpb.MAINTENANCE); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.Watchers(ctx, r)
+}
+
+func (ams *authMaintenanceServer) CancelWatcher(ctx context.Context, r *pb.CancelWatcherRequest) (*pb.CancelWatcherResponse, error) {
+	if err := ams.isAuthenticated(ctx, authpb.MAINTENANCE); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.CancelWatcher(ctx, r)
+}
+
+func (ams *authMaintenanceServer) HotKeys(ctx context.Context, r *pb.HotKeysRequest) (*pb.HotKeysResponse, error) {
+	if err := ams.isAuthenticated(ctx, authpb.MAINTENANCE); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.HotKeys(ctx, r)
+}
+
+func (ams *authMaintenanceServer) ClusterHistory(ctx context.Context, r *pb.ClusterHistoryRequest) (*pb.ClusterHistoryResponse, error) {
+	if err := ams.isAuthenticated(ctx, authpb.MAINTENANCE); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.ClusterHistory(ctx, r)
+}
+
+func (ams *authMaintenanceServer) RuntimeConfig(ctx context.Context, r *pb.RuntimeConfigRequest) (*pb.RuntimeConfigResponse, error) {
+	if err := ams.isAuthenticated(ctx, authpb.MAINTENANCE); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.RuntimeConfig(ctx, r)
+}
+
+func (ams *authMaintenanceServer) DefragmentStatus(ctx context.Context, r *pb.DefragmentStatusRequest) (*pb.DefragmentStatusResponse, error) {
+	if err := ams.isAuthenticated(ctx, authpb.DEFRAGMENT); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.DefragmentStatus(ctx, r)
+}
+
+func (ams *authMaintenanceServer) BackendBatch(ctx context.Context, r *pb.BackendBatchRequest) (*pb.BackendBatchResponse, error) {
+	if err := ams.isAuthenticated(ctx, authpb.MAINTENANCE); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.BackendBatch(ctx, r)
+}
+
+func (ams *authMaintenanceServer) PrefixQuotaSet(ctx context.Context, r *pb.PrefixQuotaSetRequest) (*pb.PrefixQuotaSetResponse, error) {
+	if err := ams.isAuthenticated(ctx, authpb.MAINTENANCE); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.PrefixQuotaSet(ctx, r)
+}
+
+func (ams *authMaintenanceServer) PrefixQuotaDelete(ctx context.Context, r *pb.PrefixQuotaDeleteRequest) (*pb.PrefixQuotaDeleteResponse, error) {
+	if err := ams.isAuthenticated(ctx, authpb.MAINTENANCE); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.PrefixQuotaDelete(ctx, r)
+}
+
+func (ams *authMaintenanceServer) PrefixQuotaList(ctx context.Context, r *pb.PrefixQuotaListRequest) (*pb.PrefixQuotaListResponse, error) {
+	if err := ams.isAuthenticated(ctx, authpb.MAINTENANCE); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.PrefixQuotaList(ctx, r)
+}
+
+func (ams *authMaintenanceServer) PrefixStats(ctx context.Context, r *pb.PrefixStatsRequest) (*pb.PrefixStatsResponse, error) {
+	if err := ams.isAuthenticated(ctx, authpb.MAINTENANCE); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.PrefixStats(ctx, r)
+}
+
+func (ams *authMaintenanceServer) LearnerStatus(ctx context.Context, r *pb.LearnerStatusRequest) (*pb.LearnerStatusResponse, error) {
+	if err := ams.isAuthenticated(ctx, authpb.MAINTENANCE); err != nil {
+		return nil, err
+	}
+	return ams.maintenanceServer.LearnerStatus(ctx, r)
+}
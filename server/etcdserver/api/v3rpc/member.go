@@ -105,6 +105,33 @@ func (cs *ClusterServer) MemberPromote(ctx context.Context, r *pb.MemberPromoteR
 	return &pb.MemberPromoteResponse{Header: cs.header(), Members: membersToProtoMembers(membs)}, nil
 }
 
+func (cs *ClusterServer) MemberReplace(ctx context.Context, r *pb.MemberReplaceRequest) (*pb.MemberReplaceResponse, error) {
+	now := time.Now()
+	add := make([]membership.Member, len(r.Add))
+	added := make([]*pb.Member, len(r.Add))
+	for i, ar := range r.Add {
+		urls, err := types.NewURLs(ar.PeerURLs)
+		if err != nil {
+			return nil, rpctypes.ErrGRPCMemberBadURLs
+		}
+		if ar.IsLearner {
+			add[i] = *membership.NewMemberAsLearner("", urls, "", &now)
+		} else {
+			add[i] = *membership.NewMember("", urls, "", &now)
+		}
+		added[i] = &pb.Member{
+			ID:        uint64(add[i].ID),
+			PeerURLs:  add[i].PeerURLs,
+			IsLearner: add[i].IsLearner,
+		}
+	}
+	membs, err := cs.server.ReplaceMembers(ctx, add, r.Remove)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return &pb.MemberReplaceResponse{Header: cs.header(), Added: added, Members: membersToProtoMembers(membs)}, nil
+}
+
 func (cs *ClusterServer) header() *pb.ResponseHeader {
 	return &pb.ResponseHeader{ClusterId: uint64(cs.cluster.ID()), MemberId: uint64(cs.server.ID()), RaftTerm: cs.server.Term()}
 }
@@ -118,6 +145,10 @@ func membersToProtoMembers(membs []*membership.Member) []*pb.Member {
 			PeerURLs:   membs[i].PeerURLs,
 			ClientURLs: membs[i].ClientURLs,
 			IsLearner:  membs[i].IsLearner,
+
+			ElectionPriority: membs[i].ElectionPriority,
+			Labels:           membs[i].Labels,
+			WalDurability:    membs[i].WALDurability,
 		}
 	}
 	return protoMembs
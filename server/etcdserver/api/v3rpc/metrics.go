@@ -48,6 +48,24 @@ var (
 	},
 		[]string{"type", "client_api_version"},
 	)
+
+	watchCompressionInputBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "network",
+		Name:      "watch_compression_input_bytes_total",
+		Help:      "The total number of bytes of the watch events sent compressed, before compression.",
+	},
+		[]string{"codec"},
+	)
+
+	watchCompressionSavedBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "network",
+		Name:      "watch_compression_saved_bytes_total",
+		Help:      "The total number of bytes saved by compressing the watch events.",
+	},
+		[]string{"codec"},
+	)
 )
 
 func init() {
@@ -55,4 +73,6 @@ func init() {
 	prometheus.MustRegister(receivedBytes)
 	prometheus.MustRegister(streamFailures)
 	prometheus.MustRegister(clientRequests)
+	prometheus.MustRegister(watchCompressionInputBytes)
+	prometheus.MustRegister(watchCompressionSavedBytes)
 }
@@ -71,6 +71,27 @@ func (s *quotaKVServer) Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnRespo
 	return s.KVServer.Txn(ctx, r)
 }
 
+func (s *quotaKVServer) Increment(ctx context.Context, r *pb.IncrementRequest) (*pb.IncrementResponse, error) {
+	if err := s.qa.check(ctx, r); err != nil {
+		return nil, err
+	}
+	return s.KVServer.Increment(ctx, r)
+}
+
+func (s *quotaKVServer) PutIfAbsent(ctx context.Context, r *pb.PutIfAbsentRequest) (*pb.PutIfAbsentResponse, error) {
+	if err := s.qa.check(ctx, r); err != nil {
+		return nil, err
+	}
+	return s.KVServer.PutIfAbsent(ctx, r)
+}
+
+func (s *quotaKVServer) BulkWrite(ctx context.Context, r *pb.BulkWriteRequest) (*pb.BulkWriteResponse, error) {
+	if err := s.qa.check(ctx, &pb.TxnRequest{Success: r.Ops}); err != nil {
+		return nil, err
+	}
+	return s.KVServer.BulkWrite(ctx, r)
+}
+
 type quotaLeaseServer struct {
 	pb.LeaseServer
 	qa quotaAlarmer
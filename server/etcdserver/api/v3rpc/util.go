@@ -25,6 +25,7 @@ import (
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
 	"go.etcd.io/etcd/server/v3/etcdserver/version"
 	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/storage/backend"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
 
 	"google.golang.org/grpc/codes"
@@ -38,14 +39,19 @@ var toGRPCErrorMap = map[error]error{
 	membership.ErrPeerURLexists:           rpctypes.ErrGRPCPeerURLExist,
 	membership.ErrMemberNotLearner:        rpctypes.ErrGRPCMemberNotLearner,
 	membership.ErrTooManyLearners:         rpctypes.ErrGRPCTooManyLearners,
+	membership.ErrNoMemberChange:          rpctypes.ErrGRPCNoMemberChange,
+	membership.ErrMemberChangedTwice:      rpctypes.ErrGRPCMemberChangedTwice,
+	membership.ErrNoVotingMember:          rpctypes.ErrGRPCNoVotingMember,
 	etcdserver.ErrNotEnoughStartedMembers: rpctypes.ErrMemberNotEnoughStarted,
 	etcdserver.ErrLearnerNotReady:         rpctypes.ErrGRPCLearnerNotReady,
 
-	mvcc.ErrCompacted:             rpctypes.ErrGRPCCompacted,
-	mvcc.ErrFutureRev:             rpctypes.ErrGRPCFutureRev,
-	etcdserver.ErrRequestTooLarge: rpctypes.ErrGRPCRequestTooLarge,
-	etcdserver.ErrNoSpace:         rpctypes.ErrGRPCNoSpace,
-	etcdserver.ErrTooManyRequests: rpctypes.ErrTooManyRequests,
+	mvcc.ErrCompacted:                 rpctypes.ErrGRPCCompacted,
+	mvcc.ErrFutureRev:                 rpctypes.ErrGRPCFutureRev,
+	etcdserver.ErrRequestTooLarge:     rpctypes.ErrGRPCRequestTooLarge,
+	etcdserver.ErrNoSpace:             rpctypes.ErrGRPCNoSpace,
+	etcdserver.ErrPrefixQuotaExceeded: rpctypes.ErrGRPCPrefixQuotaExceeded,
+	etcdserver.ErrTooManyRequests:     rpctypes.ErrTooManyRequests,
+	etcdserver.ErrTooManyLeases:       rpctypes.ErrGRPCTooManyLeases,
 
 	etcdserver.ErrNoLeader:                   rpctypes.ErrGRPCNoLeader,
 	etcdserver.ErrNotLeader:                  rpctypes.ErrGRPCNotLeader,
@@ -57,8 +63,12 @@ var toGRPCErrorMap = map[error]error{
 	etcdserver.ErrTimeoutWaitAppliedIndex:    rpctypes.ErrGRPCTimeoutWaitAppliedIndex,
 	etcdserver.ErrUnhealthy:                  rpctypes.ErrGRPCUnhealthy,
 	etcdserver.ErrKeyNotFound:                rpctypes.ErrGRPCKeyNotFound,
+	etcdserver.ErrKeyExists:                  rpctypes.ErrGRPCKeyExists,
+	etcdserver.ErrValueNotNumeric:            rpctypes.ErrGRPCValueNotNumeric,
+	etcdserver.ErrIncrementOverflow:          rpctypes.ErrGRPCIncrementOverflow,
 	etcdserver.ErrCorrupt:                    rpctypes.ErrGRPCCorrupt,
 	etcdserver.ErrBadLeaderTransferee:        rpctypes.ErrGRPCBadLeaderTransferee,
+	etcdserver.ErrNotSupportedByCluster:      rpctypes.ErrGRPCNotSupportedByCluster,
 
 	etcdserver.ErrClusterVersionUnavailable:   rpctypes.ErrGRPCClusterVersionUnavailable,
 	etcdserver.ErrWrongDowngradeVersionFormat: rpctypes.ErrGRPCWrongDowngradeVersionFormat,
@@ -66,6 +76,9 @@ var toGRPCErrorMap = map[error]error{
 	version.ErrDowngradeInProcess:             rpctypes.ErrGRPCDowngradeInProcess,
 	version.ErrNoInflightDowngrade:            rpctypes.ErrGRPCNoInflightDowngrade,
 
+	backend.ErrInvalidBatchInterval: rpctypes.ErrGRPCInvalidBackendBatchInterval,
+	backend.ErrInvalidBatchLimit:    rpctypes.ErrGRPCInvalidBackendBatchLimit,
+
 	lease.ErrLeaseNotFound:    rpctypes.ErrGRPCLeaseNotFound,
 	lease.ErrLeaseExists:      rpctypes.ErrGRPCLeaseExist,
 	lease.ErrLeaseTTLTooLarge: rpctypes.ErrGRPCLeaseTTLTooLarge,
@@ -87,6 +100,13 @@ var toGRPCErrorMap = map[error]error{
 	auth.ErrInvalidAuthToken:     rpctypes.ErrGRPCInvalidAuthToken,
 	auth.ErrInvalidAuthMgmt:      rpctypes.ErrGRPCInvalidAuthMgmt,
 	auth.ErrAuthOldRevision:      rpctypes.ErrGRPCAuthOldRevision,
+	auth.ErrInvalidHomePrefix:    rpctypes.ErrGRPCInvalidHomePrefix,
+	auth.ErrAuthLockedOut:        rpctypes.ErrGRPCAuthLockedOut,
+	auth.ErrPasswordTooShort:     rpctypes.ErrGRPCPasswordTooShort,
+	auth.ErrPasswordTooSimple:    rpctypes.ErrGRPCPasswordTooSimple,
+	auth.ErrPasswordExpired:      rpctypes.ErrGRPCPasswordExpired,
+	auth.ErrRoleGrantExpired:     rpctypes.ErrGRPCRoleGrantExpired,
+	auth.ErrLDAPUnavailable:      rpctypes.ErrGRPCLDAPUnavailable,
 
 	// In sync with status.FromContextError
 	context.Canceled:         rpctypes.ErrGRPCCanceled,
@@ -148,3 +168,14 @@ func isRPCSupportedForLearner(req interface{}) bool {
 		return false
 	}
 }
+
+// witness serves endpoint status, the membership, the alarms and the defragmentation
+// of its backend, but holds no KV data
+func isRPCSupportedForWitness(req interface{}) bool {
+	switch req.(type) {
+	case *pb.StatusRequest, *pb.MemberListRequest, *pb.AlarmRequest, *pb.DefragmentRequest:
+		return true
+	default:
+		return false
+	}
+}
@@ -24,11 +24,13 @@ import (
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/mvccpb"
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/pkg/v3/traceutil"
 	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/etcdserver"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
 
 	"go.uber.org/zap"
+	"google.golang.org/grpc/peer"
 )
 
 const minWatchProgressInterval = 100 * time.Millisecond
@@ -44,6 +46,7 @@ type watchServer struct {
 	sg        etcdserver.RaftStatusGetter
 	watchable mvcc.WatchableKV
 	ag        AuthGetter
+	authz     auth.Authorizer
 }
 
 // NewWatchServer returns a new watch server.
@@ -59,6 +62,7 @@ func NewWatchServer(s *etcdserver.EtcdServer) pb.WatchServer {
 		sg:        s,
 		watchable: s.Watchable(),
 		ag:        s,
+		authz:     s.Cfg.Authorizer,
 	}
 	if srv.lg == nil {
 		srv.lg = zap.NewNop()
@@ -127,20 +131,33 @@ type serverWatchStream struct {
 	sg        etcdserver.RaftStatusGetter
 	watchable mvcc.WatchableKV
 	ag        AuthGetter
+	authz     auth.Authorizer
 
 	gRPCStream  pb.Watch_WatchServer
 	watchStream mvcc.WatchStream
 	ctrlStream  chan *pb.WatchResponse
+	// compression is the codec of the events negotiated with the client
+	compression pb.WatchResponse_Compression
 
-	// mu protects progress, prevKV, fragment
+	// mu protects progress, progressOpts, prevKV, fragment, resume, batch,
+	// projection
 	mu sync.RWMutex
 	// tracks the watchID that stream might need to send progress to
 	// TODO: combine progress and prevKV into a single struct?
 	progress map[mvcc.WatchID]bool
+	// records the progress options of the watch IDs with their own
+	// progress notifications
+	progressOpts map[mvcc.WatchID]watchProgressOptions
 	// record watch IDs that need return previous key-value pair
 	prevKV map[mvcc.WatchID]bool
 	// records fragmented watch IDs
 	fragment map[mvcc.WatchID]bool
+	// records the templates of the resume tokens of resumable watch IDs
+	resume map[mvcc.WatchID]*pb.WatchCreateRequest
+	// records the batch options of batching watch IDs
+	batch map[mvcc.WatchID]watchBatchOptions
+	// records the projections of the events of projecting watch IDs
+	projection map[mvcc.WatchID]func(*mvccpb.Event) *mvccpb.Event
 
 	// closec indicates the stream is closed.
 	closec chan struct{}
@@ -161,18 +178,32 @@ func (ws *watchServer) Watch(stream pb.Watch_WatchServer) (err error) {
 		sg:        ws.sg,
 		watchable: ws.watchable,
 		ag:        ws.ag,
+		authz:     ws.authz,
 
 		gRPCStream:  stream,
 		watchStream: ws.watchable.NewWatchStream(),
 		// chan for sending control response like watcher created and canceled.
 		ctrlStream: make(chan *pb.WatchResponse, ctrlStreamBufLen),
 
-		progress: make(map[mvcc.WatchID]bool),
-		prevKV:   make(map[mvcc.WatchID]bool),
-		fragment: make(map[mvcc.WatchID]bool),
+		compression: negotiateWatchCompression(stream.Context()),
+
+		progress:     make(map[mvcc.WatchID]bool),
+		progressOpts: make(map[mvcc.WatchID]watchProgressOptions),
+		prevKV:       make(map[mvcc.WatchID]bool),
+		fragment:     make(map[mvcc.WatchID]bool),
+		resume:       make(map[mvcc.WatchID]*pb.WatchCreateRequest),
+		batch:        make(map[mvcc.WatchID]watchBatchOptions),
+
+		projection: make(map[mvcc.WatchID]func(*mvccpb.Event) *mvccpb.Event),
 
 		closec: make(chan struct{}),
 	}
+	if p, ok := peer.FromContext(stream.Context()); ok {
+		sws.watchStream.SetLabel(p.Addr.String())
+	}
+	if ai, err := ws.ag.AuthInfoFromCtx(stream.Context()); err == nil && ai != nil {
+		sws.watchStream.SetUser(ai.Username)
+	}
 
 	sws.wg.Add(1)
 	go func() {
@@ -229,10 +260,73 @@ func (sws *serverWatchStream) isWatchPermitted(wcr *pb.WatchCreateRequest) bool
 		return false
 	}
 	if authInfo == nil {
-		// if auth is enabled, IsRangePermitted() can cause an error
+		// if auth is enabled, IsWatchPermitted() can cause an error
 		authInfo = &auth.AuthInfo{}
 	}
-	return sws.ag.AuthStore().IsRangePermitted(authInfo, wcr.Key, wcr.RangeEnd) == nil
+	if sws.ag.AuthStore().IsWatchPermitted(authInfo, wcr.Key, wcr.RangeEnd) != nil {
+		return false
+	}
+	for _, r := range wcr.AdditionalRanges {
+		if sws.ag.AuthStore().IsWatchPermitted(authInfo, r.Key, r.RangeEnd) != nil {
+			return false
+		}
+	}
+	for _, c := range wcr.Compare {
+		// the compares read the keys
+		if sws.ag.AuthStore().IsRangePermitted(authInfo, c.Key, c.RangeEnd) != nil {
+			return false
+		}
+	}
+	if sws.authz != nil {
+		keys := []auth.AuthorizationKeyRange{{Op: "watch", Key: wcr.Key, RangeEnd: wcr.RangeEnd}}
+		for _, r := range wcr.AdditionalRanges {
+			keys = append(keys, auth.AuthorizationKeyRange{Op: "watch", Key: r.Key, RangeEnd: r.RangeEnd})
+		}
+		for _, c := range wcr.Compare {
+			keys = append(keys, auth.AuthorizationKeyRange{Op: "compare", Key: c.Key, RangeEnd: c.RangeEnd})
+		}
+		if _, ok := authorize(sws.gRPCStream.Context(), sws.lg, sws.authz, authInfo, watchMethod, keys); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// applyCompares evaluates the comparisons of the create request and returns
+// the revision of the store they were evaluated at.
+func (sws *serverWatchStream) applyCompares(wcr *pb.WatchCreateRequest) (int64, bool) {
+	txn := sws.watchable.Read(mvcc.ConcurrentReadTxMode, traceutil.TODO())
+	defer txn.End()
+	return txn.Rev(), etcdserver.ApplyCompares(txn, wcr.Compare)
+}
+
+// resolveHomeKey scopes a relative watch range under the home prefix of the
+// user owning the stream, if the user has one configured.
+func (sws *serverWatchStream) resolveHomeKey(key, rangeEnd []byte) ([]byte, []byte) {
+	authInfo, err := sws.ag.AuthInfoFromCtx(sws.gRPCStream.Context())
+	if err != nil || authInfo == nil {
+		return key, rangeEnd
+	}
+	return sws.ag.AuthStore().ResolveHomeKey(authInfo, key, rangeEnd)
+}
+
+// normalizeWatchRange converts the key and the range end of a watch request
+// to the ones of mvcc.WatchStream.
+func normalizeWatchRange(key, rangeEnd []byte) ([]byte, []byte) {
+	if len(key) == 0 {
+		// \x00 is the smallest key
+		key = []byte{0}
+	}
+	if len(rangeEnd) == 0 {
+		// force nil since watchstream.Watch distinguishes
+		// between nil and []byte{} for single key / >=
+		rangeEnd = nil
+	}
+	if len(rangeEnd) == 1 && rangeEnd[0] == 0 {
+		// support  >= key queries
+		rangeEnd = []byte{}
+	}
+	return key, rangeEnd
 }
 
 func (sws *serverWatchStream) recvLoop() error {
@@ -252,18 +346,36 @@ func (sws *serverWatchStream) recvLoop() error {
 			}
 
 			creq := uv.CreateRequest
-			if len(creq.Key) == 0 {
-				// \x00 is the smallest key
-				creq.Key = []byte{0}
+			if len(creq.ResumeToken) != 0 {
+				rreq, err := parseResumeToken(creq)
+				if err != nil {
+					wr := &pb.WatchResponse{
+						Header:       sws.newResponseHeader(sws.watchStream.Rev()),
+						WatchId:      creq.WatchId,
+						Canceled:     true,
+						Created:      true,
+						CancelReason: rpctypes.ErrorDesc(err),
+					}
+
+					select {
+					case sws.ctrlStream <- wr:
+						continue
+					case <-sws.closec:
+						return nil
+					}
+				}
+				creq = rreq
 			}
-			if len(creq.RangeEnd) == 0 {
-				// force nil since watchstream.Watch distinguishes
-				// between nil and []byte{} for single key / >=
-				creq.RangeEnd = nil
+			var resumeTmpl *pb.WatchCreateRequest
+			if creq.Resumable {
+				resumeTmpl = newResumeTemplate(creq)
 			}
-			if len(creq.RangeEnd) == 1 && creq.RangeEnd[0] == 0 {
-				// support  >= key queries
-				creq.RangeEnd = []byte{}
+			creq.Key, creq.RangeEnd = normalizeWatchRange(sws.resolveHomeKey(creq.Key, creq.RangeEnd))
+			for _, r := range creq.AdditionalRanges {
+				r.Key, r.RangeEnd = normalizeWatchRange(sws.resolveHomeKey(r.Key, r.RangeEnd))
+			}
+			for _, c := range creq.Compare {
+				c.Key, c.RangeEnd = sws.resolveHomeKey(c.Key, c.RangeEnd)
 			}
 
 			if !sws.isWatchPermitted(creq) {
@@ -284,24 +396,109 @@ func (sws *serverWatchStream) recvLoop() error {
 			}
 
 			filters := FiltersFromRequest(creq)
+			vfilters, err := ValueFiltersFromRequest(creq)
+			if err != nil {
+				wr := &pb.WatchResponse{
+					Header:       sws.newResponseHeader(sws.watchStream.Rev()),
+					WatchId:      creq.WatchId,
+					Canceled:     true,
+					Created:      true,
+					CancelReason: rpctypes.ErrorDesc(err),
+				}
+
+				select {
+				case sws.ctrlStream <- wr:
+					continue
+				case <-sws.closec:
+					return nil
+				}
+			}
+			filters = append(filters, vfilters...)
+
+			bopts, batching, err := batchOptionsFromRequest(creq)
+			var project func(*mvccpb.Event) *mvccpb.Event
+			if err == nil {
+				project, err = ProjectionFromRequest(creq)
+			}
+			var (
+				popts       watchProgressOptions
+				ownProgress bool
+			)
+			if err == nil {
+				popts, ownProgress, err = progressOptionsFromRequest(creq)
+			}
+			if err != nil {
+				wr := &pb.WatchResponse{
+					Header:       sws.newResponseHeader(sws.watchStream.Rev()),
+					WatchId:      creq.WatchId,
+					Canceled:     true,
+					Created:      true,
+					CancelReason: rpctypes.ErrorDesc(err),
+				}
+
+				select {
+				case sws.ctrlStream <- wr:
+					continue
+				case <-sws.closec:
+					return nil
+				}
+			}
 
 			wsrev := sws.watchStream.Rev()
+			if len(creq.Compare) != 0 {
+				// start from the revision the comparisons observed so that
+				// no event after the check is missed by the watcher
+				crev, ok := sws.applyCompares(creq)
+				if !ok {
+					wr := &pb.WatchResponse{
+						Header:       sws.newResponseHeader(crev),
+						WatchId:      creq.WatchId,
+						Canceled:     true,
+						Created:      true,
+						CancelReason: rpctypes.ErrorDesc(rpctypes.ErrGRPCWatchCompareFailed),
+					}
+
+					select {
+					case sws.ctrlStream <- wr:
+						continue
+					case <-sws.closec:
+						return nil
+					}
+				}
+				wsrev = crev
+			}
 			rev := creq.StartRevision
 			if rev == 0 {
 				rev = wsrev + 1
 			}
-			id, err := sws.watchStream.Watch(mvcc.WatchID(creq.WatchId), creq.Key, creq.RangeEnd, rev, filters...)
+			ranges := []mvcc.KeyRange{{Key: creq.Key, End: creq.RangeEnd}}
+			for _, r := range creq.AdditionalRanges {
+				ranges = append(ranges, mvcc.KeyRange{Key: r.Key, End: r.RangeEnd})
+			}
+			id, err := sws.watchStream.WatchRanges(mvcc.WatchID(creq.WatchId), ranges, rev, filters...)
 			if err == nil {
 				sws.mu.Lock()
-				if creq.ProgressNotify {
+				if creq.ProgressNotify || ownProgress {
 					sws.progress[id] = true
 				}
+				if ownProgress {
+					sws.progressOpts[id] = popts
+				}
 				if creq.PrevKv {
 					sws.prevKV[id] = true
 				}
 				if creq.Fragment {
 					sws.fragment[id] = true
 				}
+				if resumeTmpl != nil {
+					sws.resume[id] = resumeTmpl
+				}
+				if batching {
+					sws.batch[id] = bopts
+				}
+				if project != nil {
+					sws.projection[id] = project
+				}
 				sws.mu.Unlock()
 			}
 			wr := &pb.WatchResponse{
@@ -312,6 +509,8 @@ func (sws *serverWatchStream) recvLoop() error {
 			}
 			if err != nil {
 				wr.CancelReason = err.Error()
+			} else if resumeTmpl != nil {
+				wr.ResumeToken = resumeToken(resumeTmpl, rev)
 			}
 			select {
 			case sws.ctrlStream <- wr:
@@ -331,8 +530,12 @@ func (sws *serverWatchStream) recvLoop() error {
 					}
 					sws.mu.Lock()
 					delete(sws.progress, mvcc.WatchID(id))
+					delete(sws.progressOpts, mvcc.WatchID(id))
 					delete(sws.prevKV, mvcc.WatchID(id))
 					delete(sws.fragment, mvcc.WatchID(id))
+					delete(sws.resume, mvcc.WatchID(id))
+					delete(sws.batch, mvcc.WatchID(id))
+					delete(sws.projection, mvcc.WatchID(id))
 					sws.mu.Unlock()
 				}
 			}
@@ -361,8 +564,60 @@ func (sws *serverWatchStream) sendLoop() {
 	interval := GetProgressReportInterval()
 	progressTicker := time.NewTicker(interval)
 
+	// batches of the events of the batching watchers, sent when full or
+	// when batchTimer fires at their earliest deadline
+	batcher := newWatchBatcher(sws.maxRequestBytes)
+	var (
+		batchTimer    *time.Timer
+		batchc        <-chan time.Time
+		batchDeadline time.Time
+	)
+	resetBatchTimer := func() {
+		next, ok := batcher.next()
+		if ok && batchc != nil && next.Equal(batchDeadline) {
+			return
+		}
+		if batchTimer != nil {
+			batchTimer.Stop()
+		}
+		batchc = nil
+		if ok {
+			batchTimer, batchDeadline = time.NewTimer(time.Until(next)), next
+			batchc = batchTimer.C
+		}
+	}
+
+	// progress notifications of the watchers with their own interval, sent
+	// when progressTimer fires at the earliest due time
+	progressor := newWatchProgressScheduler()
+	var (
+		progressTimer *time.Timer
+		progressc     <-chan time.Time
+		progressDue   time.Time
+	)
+	resetProgressTimer := func() {
+		next, ok := progressor.next()
+		if ok && progressc != nil && next.Equal(progressDue) {
+			return
+		}
+		if progressTimer != nil {
+			progressTimer.Stop()
+		}
+		progressc = nil
+		if ok {
+			progressTimer, progressDue = time.NewTimer(time.Until(next)), next
+			progressc = progressTimer.C
+		}
+	}
+
 	defer func() {
 		progressTicker.Stop()
+		if batchTimer != nil {
+			batchTimer.Stop()
+		}
+		if progressTimer != nil {
+			progressTimer.Stop()
+		}
 		// drain the chan to clean up pending events
 		for ws := range sws.watchStream.Chan() {
 			mvcc.ReportEventReceived(len(ws.Events))
@@ -380,6 +635,7 @@ func (sws *serverWatchStream) sendLoop() {
 			if !ok {
 				return
 			}
+			sws.watchStream.ReportReceived(wresp)
 
 			// TODO: evs is []mvccpb.Event type
 			// either return []*mvccpb.Event from the mvcc package
@@ -388,6 +644,8 @@ func (sws *serverWatchStream) sendLoop() {
 			events := make([]*mvccpb.Event, len(evs))
 			sws.mu.RLock()
 			needPrevKV := sws.prevKV[wresp.WatchID]
+			resumeTmpl := sws.resume[wresp.WatchID]
+			project := sws.projection[wresp.WatchID]
 			sws.mu.RUnlock()
 			for i := range evs {
 				events[i] = &evs[i]
@@ -398,9 +656,12 @@ func (sws *serverWatchStream) sendLoop() {
 						events[i].PrevKv = &(r.KVs[0])
 					}
 				}
+				if project != nil {
+					events[i] = project(events[i])
+				}
 			}
 
-			canceled := wresp.CompactRevision != 0
+			canceled := wresp.CompactRevision != 0 || wresp.Err != nil
 			wr := &pb.WatchResponse{
 				Header:          sws.newResponseHeader(wresp.Revision),
 				WatchId:         int64(wresp.WatchID),
@@ -408,6 +669,21 @@ func (sws *serverWatchStream) sendLoop() {
 				CompactRevision: wresp.CompactRevision,
 				Canceled:        canceled,
 			}
+			switch wresp.Err {
+			case mvcc.ErrWatcherStuck:
+				wr.CancelReason = rpctypes.ErrorDesc(rpctypes.ErrGRPCWatchStuck)
+			case mvcc.ErrWatcherCanceled:
+				wr.CancelReason = rpctypes.ErrorDesc(rpctypes.ErrGRPCWatchCanceledByAdmin)
+			}
+			if resumeTmpl != nil && !canceled {
+				// an unsynced watcher may be sent the events up to a
+				// revision before the one of the response
+				next := wresp.Revision + 1
+				if len(evs) > 0 {
+					next = evs[len(evs)-1].Kv.ModRevision + 1
+				}
+				wr.ResumeToken = resumeToken(resumeTmpl, next)
+			}
 
 			if _, okID := ids[wresp.WatchID]; !okID {
 				// buffer if id not yet announced
@@ -419,24 +695,18 @@ func (sws *serverWatchStream) sendLoop() {
 			mvcc.ReportEventReceived(len(evs))
 
 			sws.mu.RLock()
-			fragmented, ok := sws.fragment[wresp.WatchID]
+			bopts, batching := sws.batch[wresp.WatchID]
 			sws.mu.RUnlock()
 
-			var serr error
-			if !fragmented && !ok {
-				serr = sws.gRPCStream.Send(wr)
-			} else {
-				serr = sendFragments(wr, sws.maxRequestBytes, sws.gRPCStream.Send)
+			wrs := []*pb.WatchResponse{wr}
+			if batching {
+				wrs = batcher.add(wresp.WatchID, wr, bopts, time.Now())
+				resetBatchTimer()
 			}
-
-			if serr != nil {
-				if isClientCtxErr(sws.gRPCStream.Context().Err(), serr) {
-					sws.lg.Debug("failed to send watch response to gRPC stream", zap.Error(serr))
-				} else {
-					sws.lg.Warn("failed to send watch response to gRPC stream", zap.Error(serr))
-					streamFailures.WithLabelValues("send", "watch").Inc()
+			for _, wr := range wrs {
+				if !sws.sendWatchResponse(wr) {
+					return
 				}
-				return
 			}
 
 			sws.mu.Lock()
@@ -446,11 +716,30 @@ func (sws *serverWatchStream) sendLoop() {
 			}
 			sws.mu.Unlock()
 
+		case <-batchc:
+			batchc = nil
+			for _, wr := range batcher.expired(time.Now()) {
+				if !sws.sendWatchResponse(wr) {
+					return
+				}
+			}
+			resetBatchTimer()
+
 		case c, ok := <-sws.ctrlStream:
 			if !ok {
 				return
 			}
 
+			if c.WatchId == -1 && !c.Canceled {
+				// the progress of all the watchers is after the events
+				// of their batches
+				for _, wr := range batcher.flush() {
+					if !sws.sendWatchResponse(wr) {
+						return
+					}
+				}
+				resetBatchTimer()
+			}
 			if err := sws.gRPCStream.Send(c); err != nil {
 				if isClientCtxErr(sws.gRPCStream.Context().Err(), err) {
 					sws.lg.Debug("failed to send watch control response to gRPC stream", zap.Error(err))
@@ -465,14 +754,25 @@ func (sws *serverWatchStream) sendLoop() {
 			wid := mvcc.WatchID(c.WatchId)
 			if c.Canceled {
 				delete(ids, wid)
+				batcher.drop(wid)
+				progressor.drop(wid)
+				resetProgressTimer()
 				continue
 			}
 			if c.Created {
+				sws.mu.RLock()
+				popts, ok := sws.progressOpts[wid]
+				sws.mu.RUnlock()
+				if ok {
+					progressor.schedule(wid, popts.interval, time.Now())
+					resetProgressTimer()
+				}
+
 				// flush buffered events
 				ids[wid] = struct{}{}
 				for _, v := range pending[wid] {
 					mvcc.ReportEventReceived(len(v.Events))
-					if err := sws.gRPCStream.Send(v); err != nil {
+					if err := sws.send(v); err != nil {
 						if isClientCtxErr(sws.gRPCStream.Context().Err(), err) {
 							sws.lg.Debug("failed to send pending watch response to gRPC stream", zap.Error(err))
 						} else {
@@ -485,9 +785,30 @@ func (sws *serverWatchStream) sendLoop() {
 				delete(pending, wid)
 			}
 
+		case <-progressc:
+			progressc = nil
+			now := time.Now()
+			sws.mu.Lock()
+			for _, id := range progressor.expired(now) {
+				popts, ok := sws.progressOpts[id]
+				if !ok {
+					continue
+				}
+				if popts.heartbeat || sws.progress[id] {
+					sws.watchStream.RequestProgress(id)
+				}
+				sws.progress[id] = true
+				progressor.schedule(id, popts.interval, now)
+			}
+			sws.mu.Unlock()
+			resetProgressTimer()
+
 		case <-progressTicker.C:
 			sws.mu.Lock()
 			for id, ok := range sws.progress {
+				if _, own := sws.progressOpts[id]; own {
+					continue
+				}
 				if ok {
 					sws.watchStream.RequestProgress(id)
 				}
@@ -501,6 +822,38 @@ func (sws *serverWatchStream) sendLoop() {
 	}
 }
 
+// sendWatchResponse sends the response of a watcher, in fragments if the
+// watcher enables them. It returns false if the stream fails.
+func (sws *serverWatchStream) sendWatchResponse(wr *pb.WatchResponse) bool {
+	sws.mu.RLock()
+	fragmented, ok := sws.fragment[mvcc.WatchID(wr.WatchId)]
+	sws.mu.RUnlock()
+
+	var serr error
+	if !fragmented && !ok {
+		serr = sws.send(wr)
+	} else {
+		serr = sendFragments(wr, sws.maxRequestBytes, sws.send)
+	}
+
+	if serr != nil {
+		if isClientCtxErr(sws.gRPCStream.Context().Err(), serr) {
+			sws.lg.Debug("failed to send watch response to gRPC stream", zap.Error(serr))
+		} else {
+			sws.lg.Warn("failed to send watch response to gRPC stream", zap.Error(serr))
+			streamFailures.WithLabelValues("send", "watch").Inc()
+		}
+		return false
+	}
+	return true
+}
+
+// send sends a response to the gRPC stream, its events compressed with the
+// codec negotiated with the client.
+func (sws *serverWatchStream) send(wr *pb.WatchResponse) error {
+	return sws.gRPCStream.Send(compressWatchResponse(wr, sws.compression))
+}
+
 func IsCreateEvent(e mvccpb.Event) bool {
 	return e.Type == mvccpb.PUT && e.Kv.CreateRevision == e.Kv.ModRevision
 }
@@ -518,6 +871,8 @@ func sendFragments(
 	ow := *wr
 	ow.Events = make([]*mvccpb.Event, 0)
 	ow.Fragment = true
+	// only the last fragment resumes the watcher after all of the events
+	ow.ResumeToken = nil
 
 	var idx int
 	for {
@@ -533,6 +888,7 @@ func sendFragments(
 		if idx == len(wr.Events) {
 			// last response has no more fragment
 			cur.Fragment = false
+			cur.ResumeToken = wr.ResumeToken
 		}
 		if err := sendFunc(&cur); err != nil {
 			return err
@@ -0,0 +1,140 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+// maxWatchBatchLatency is the longest time the events of a watcher may be
+// held in a batch.
+const maxWatchBatchLatency = 10 * time.Second
+
+// watchBatchOptions bounds the batches of the events of a watcher.
+type watchBatchOptions struct {
+	maxLatency time.Duration
+	// maxEvents is unbounded if zero
+	maxEvents int
+}
+
+// batchOptionsFromRequest returns the batch options of a watch create
+// request, and whether the events of the watcher are batched.
+func batchOptionsFromRequest(creq *pb.WatchCreateRequest) (watchBatchOptions, bool, error) {
+	latency := time.Duration(creq.BatchMaxLatencyMs) * time.Millisecond
+	switch {
+	case creq.BatchMaxLatencyMs < 0, creq.BatchMaxEvents < 0, latency > maxWatchBatchLatency:
+		return watchBatchOptions{}, false, rpctypes.ErrGRPCWatchInvalidBatchOptions
+	case creq.BatchMaxEvents != 0 && latency == 0:
+		// the events would be held until the batch is full
+		return watchBatchOptions{}, false, rpctypes.ErrGRPCWatchInvalidBatchOptions
+	case latency == 0:
+		return watchBatchOptions{}, false, nil
+	}
+	return watchBatchOptions{maxLatency: latency, maxEvents: int(creq.BatchMaxEvents)}, true, nil
+}
+
+type watchBatch struct {
+	wr       *pb.WatchResponse
+	size     int
+	deadline time.Time
+}
+
+// watchBatcher coalesces the responses of the batching watchers of a
+// stream, until their batches are full or expired.
+type watchBatcher struct {
+	// maxBytes is the size a batch is sent at
+	maxBytes int
+	batches  map[mvcc.WatchID]*watchBatch
+}
+
+func newWatchBatcher(maxBytes int) *watchBatcher {
+	return &watchBatcher{maxBytes: maxBytes, batches: make(map[mvcc.WatchID]*watchBatch)}
+}
+
+// add adds the response of a watcher to its batch, returning the responses
+// to send now, in order. A response with no event, such as a progress
+// notification, flushes the batch before it.
+func (b *watchBatcher) add(id mvcc.WatchID, wr *pb.WatchResponse, opts watchBatchOptions, now time.Time) []*pb.WatchResponse {
+	batch := b.batches[id]
+	if len(wr.Events) == 0 || wr.Canceled {
+		if batch == nil {
+			return []*pb.WatchResponse{wr}
+		}
+		delete(b.batches, id)
+		return []*pb.WatchResponse{batch.wr, wr}
+	}
+
+	if batch == nil {
+		batch = &watchBatch{wr: wr, deadline: now.Add(opts.maxLatency)}
+		b.batches[id] = batch
+	} else {
+		batch.wr.Header = wr.Header
+		batch.wr.Events = append(batch.wr.Events, wr.Events...)
+		if len(wr.ResumeToken) != 0 {
+			batch.wr.ResumeToken = wr.ResumeToken
+		}
+	}
+	for _, ev := range wr.Events {
+		batch.size += ev.Size()
+	}
+	if (opts.maxEvents > 0 && len(batch.wr.Events) >= opts.maxEvents) || batch.size >= b.maxBytes {
+		delete(b.batches, id)
+		return []*pb.WatchResponse{batch.wr}
+	}
+	return nil
+}
+
+// expired removes the batches expired at now, returning their responses.
+func (b *watchBatcher) expired(now time.Time) []*pb.WatchResponse {
+	var wrs []*pb.WatchResponse
+	for id, batch := range b.batches {
+		if !now.Before(batch.deadline) {
+			wrs = append(wrs, batch.wr)
+			delete(b.batches, id)
+		}
+	}
+	return wrs
+}
+
+// flush removes all of the batches, returning their responses.
+func (b *watchBatcher) flush() []*pb.WatchResponse {
+	var wrs []*pb.WatchResponse
+	for id, batch := range b.batches {
+		wrs = append(wrs, batch.wr)
+		delete(b.batches, id)
+	}
+	return wrs
+}
+
+// next returns the earliest deadline of the batches, and false if there is
+// no batch.
+func (b *watchBatcher) next() (time.Time, bool) {
+	var next time.Time
+	for _, batch := range b.batches {
+		if next.IsZero() || batch.deadline.Before(next) {
+			next = batch.deadline
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// drop drops the batch of a canceled watcher.
+func (b *watchBatcher) drop(id mvcc.WatchID) {
+	delete(b.batches, id)
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"strings"
+	"sync"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/metadata"
+)
+
+// watchCompressionMinBytes is the size of the events under which the watch
+// responses are sent uncompressed, as compressing them saves too little.
+const watchCompressionMinBytes = 1024
+
+var (
+	gzipWriters = sync.Pool{New: func() interface{} {
+		// never fails with a valid level
+		gw, _ := gzip.NewWriterLevel(nil, gzip.BestSpeed)
+		return gw
+	}}
+
+	zstdEncoderOnce sync.Once
+	zstdEncoder     *zstd.Encoder
+)
+
+// negotiateWatchCompression returns the first codec listed by the client in
+// the metadata of the watch stream that the server supports.
+func negotiateWatchCompression(ctx context.Context) pb.WatchResponse_Compression {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return pb.WatchResponse_NONE
+	}
+	for _, v := range md.Get(rpctypes.MetadataWatchCompressionKey) {
+		for _, name := range strings.Split(v, ",") {
+			c, ok := pb.WatchResponse_Compression_value[strings.ToUpper(strings.TrimSpace(name))]
+			if ok && c != int32(pb.WatchResponse_NONE) {
+				return pb.WatchResponse_Compression(c)
+			}
+		}
+	}
+	return pb.WatchResponse_NONE
+}
+
+// compressWatchResponse returns the response with its events compressed with
+// the codec, or the response itself if compressing them saves nothing.
+func compressWatchResponse(wr *pb.WatchResponse, codec pb.WatchResponse_Compression) *pb.WatchResponse {
+	if codec == pb.WatchResponse_NONE || len(wr.Events) == 0 {
+		return wr
+	}
+	evs := pb.WatchResponse{Events: wr.Events}
+	data, err := evs.Marshal()
+	if err != nil || len(data) < watchCompressionMinBytes {
+		return wr
+	}
+	compressed, err := compressWatchEvents(data, codec)
+	if err != nil || len(compressed) >= len(data) {
+		return wr
+	}
+	name := strings.ToLower(codec.String())
+	watchCompressionInputBytes.WithLabelValues(name).Add(float64(len(data)))
+	watchCompressionSavedBytes.WithLabelValues(name).Add(float64(len(data) - len(compressed)))
+
+	cwr := *wr
+	cwr.Events = nil
+	cwr.Compression = codec
+	cwr.CompressedEvents = compressed
+	return &cwr
+}
+
+func compressWatchEvents(data []byte, codec pb.WatchResponse_Compression) ([]byte, error) {
+	switch codec {
+	case pb.WatchResponse_GZIP:
+		var buf bytes.Buffer
+		gw := gzipWriters.Get().(*gzip.Writer)
+		defer gzipWriters.Put(gw)
+		gw.Reset(&buf)
+		if _, err := gw.Write(data); err != nil {
+			return nil, err
+		}
+		if err := gw.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case pb.WatchResponse_ZSTD:
+		zstdEncoderOnce.Do(func() {
+			// never fails with these options; EncodeAll is safe for
+			// concurrent use by the watch streams.
+			zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest))
+		})
+		return zstdEncoder.EncodeAll(data, make([]byte, 0, len(data)/2)), nil
+	}
+	return data, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+// ValueFiltersFromRequest returns the "mvcc.FilterFunc" filtering out the put
+// events whose values do not satisfy all of the value predicates of a watch
+// create request. It returns ErrGRPCWatchInvalidValuePredicate if any of the
+// predicates is invalid.
+func ValueFiltersFromRequest(creq *pb.WatchCreateRequest) ([]mvcc.FilterFunc, error) {
+	if len(creq.ValuePredicates) == 0 {
+		return nil, nil
+	}
+	preds := make([]func([]byte) bool, 0, len(creq.ValuePredicates))
+	for _, p := range creq.ValuePredicates {
+		pred, ok := valuePredicate(p)
+		if !ok {
+			return nil, rpctypes.ErrGRPCWatchInvalidValuePredicate
+		}
+		preds = append(preds, pred)
+	}
+	filter := func(e mvccpb.Event) bool {
+		if e.Type != mvccpb.PUT || e.Kv == nil {
+			return false
+		}
+		for _, pred := range preds {
+			if !pred(e.Kv.Value) {
+				return true
+			}
+		}
+		return false
+	}
+	return []mvcc.FilterFunc{filter}, nil
+}
+
+// valuePredicate returns whether a value satisfies the predicate, or false
+// if the predicate is invalid.
+func valuePredicate(p *pb.WatchValuePredicate) (func([]byte) bool, bool) {
+	switch p.Type {
+	case pb.WatchValuePredicate_JSON_FIELD_EQUAL:
+		path, ok := parseJSONPath(p.JsonPath)
+		if !ok {
+			return nil, false
+		}
+		var want interface{}
+		if err := json.Unmarshal(p.Value, &want); err != nil {
+			return nil, false
+		}
+		return func(v []byte) bool {
+			got, ok := jsonField(v, path)
+			return ok && reflect.DeepEqual(got, want)
+		}, true
+
+	case pb.WatchValuePredicate_PREFIX:
+		prefix := p.Value
+		return func(v []byte) bool { return bytes.HasPrefix(v, prefix) }, true
+
+	case pb.WatchValuePredicate_SIZE:
+		min, max := p.MinSize, p.MaxSize
+		if min < 0 || max < 0 || (max != 0 && max < min) {
+			return nil, false
+		}
+		return func(v []byte) bool {
+			n := int64(len(v))
+			return n >= min && (max == 0 || n <= max)
+		}, true
+	}
+	return nil, false
+}
+
+// parseJSONPath returns the fields of a dot separated path, and false if a
+// field is empty.
+func parseJSONPath(s string) ([]string, bool) {
+	path := strings.Split(s, ".")
+	for _, f := range path {
+		if f == "" {
+			return nil, false
+		}
+	}
+	return path, true
+}
+
+// jsonField returns the field at the path of a JSON value, and whether it is
+// found.
+func jsonField(v []byte, path []string) (interface{}, bool) {
+	var doc interface{}
+	if err := json.Unmarshal(v, &doc); err != nil {
+		return nil, false
+	}
+	return lookupJSONField(doc, path)
+}
+
+// lookupJSONField returns the field at the path of a decoded JSON value, and
+// whether it is found.
+func lookupJSONField(doc interface{}, path []string) (interface{}, bool) {
+	for _, f := range path {
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if doc, ok = obj[f]; !ok {
+			return nil, false
+		}
+	}
+	return doc, true
+}
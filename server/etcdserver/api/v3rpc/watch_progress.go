@@ -0,0 +1,95 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+// watchProgressOptions overrides the progress notifications of a watcher.
+type watchProgressOptions struct {
+	interval time.Duration
+	// heartbeat notifies the progress even after the events of the watcher
+	heartbeat bool
+}
+
+// progressOptionsFromRequest returns the progress options of a watch create
+// request, and whether they override the server-wide progress
+// notifications.
+func progressOptionsFromRequest(creq *pb.WatchCreateRequest) (watchProgressOptions, bool, error) {
+	if creq.ProgressNotifyIntervalMs < 0 {
+		return watchProgressOptions{}, false, rpctypes.ErrGRPCWatchInvalidProgressNotify
+	}
+	if creq.ProgressNotifyIntervalMs == 0 && !creq.ProgressNotifyHeartbeat {
+		return watchProgressOptions{}, false, nil
+	}
+	interval := time.Duration(creq.ProgressNotifyIntervalMs) * time.Millisecond
+	switch {
+	case interval == 0:
+		interval = GetProgressReportInterval()
+	case interval < minWatchProgressInterval:
+		interval = minWatchProgressInterval
+	}
+	return watchProgressOptions{interval: interval, heartbeat: creq.ProgressNotifyHeartbeat}, true, nil
+}
+
+// watchProgressScheduler schedules the progress notifications of the
+// watchers with their own interval.
+type watchProgressScheduler struct {
+	due map[mvcc.WatchID]time.Time
+}
+
+func newWatchProgressScheduler() *watchProgressScheduler {
+	return &watchProgressScheduler{due: make(map[mvcc.WatchID]time.Time)}
+}
+
+// schedule schedules the next progress notification of a watcher.
+func (ps *watchProgressScheduler) schedule(id mvcc.WatchID, interval time.Duration, now time.Time) {
+	ps.due[id] = now.Add(interval)
+}
+
+// expired returns the watchers whose progress notification is due at now,
+// unscheduling them.
+func (ps *watchProgressScheduler) expired(now time.Time) []mvcc.WatchID {
+	var ids []mvcc.WatchID
+	for id, due := range ps.due {
+		if !now.Before(due) {
+			ids = append(ids, id)
+			delete(ps.due, id)
+		}
+	}
+	return ids
+}
+
+// next returns the earliest due time of the progress notifications, and
+// false if none is scheduled.
+func (ps *watchProgressScheduler) next() (time.Time, bool) {
+	var next time.Time
+	for _, due := range ps.due {
+		if next.IsZero() || due.Before(next) {
+			next = due
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// drop unschedules a canceled watcher.
+func (ps *watchProgressScheduler) drop(id mvcc.WatchID) {
+	delete(ps.due, id)
+}
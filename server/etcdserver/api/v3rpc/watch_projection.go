@@ -0,0 +1,108 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"bytes"
+	"encoding/json"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+)
+
+// ProjectionFromRequest returns the function projecting the events of the
+// watcher of a watch create request, or nil if the events are not
+// projected. The function returns a new event, leaving the event and its
+// key-values, which may be shared by the watchers, unchanged. It returns
+// ErrGRPCWatchInvalidProjection if the projection is invalid.
+func ProjectionFromRequest(creq *pb.WatchCreateRequest) (func(*mvccpb.Event) *mvccpb.Event, error) {
+	if creq.Projection != pb.WatchCreateRequest_JSON_FIELDS && len(creq.ProjectionJsonPaths) != 0 {
+		return nil, rpctypes.ErrGRPCWatchInvalidProjection
+	}
+	var project func(*mvccpb.KeyValue) *mvccpb.KeyValue
+	switch creq.Projection {
+	case pb.WatchCreateRequest_FULL:
+		return nil, nil
+
+	case pb.WatchCreateRequest_KEYS_ONLY:
+		project = func(kv *mvccpb.KeyValue) *mvccpb.KeyValue {
+			return &mvccpb.KeyValue{Key: kv.Key, ModRevision: kv.ModRevision}
+		}
+
+	case pb.WatchCreateRequest_METADATA_ONLY:
+		project = func(kv *mvccpb.KeyValue) *mvccpb.KeyValue {
+			pkv := *kv
+			pkv.Value = nil
+			return &pkv
+		}
+
+	case pb.WatchCreateRequest_JSON_FIELDS:
+		if len(creq.ProjectionJsonPaths) == 0 {
+			return nil, rpctypes.ErrGRPCWatchInvalidProjection
+		}
+		paths := make([][]string, len(creq.ProjectionJsonPaths))
+		for i, p := range creq.ProjectionJsonPaths {
+			path, ok := parseJSONPath(p)
+			if !ok {
+				return nil, rpctypes.ErrGRPCWatchInvalidProjection
+			}
+			paths[i] = path
+		}
+		project = func(kv *mvccpb.KeyValue) *mvccpb.KeyValue {
+			pkv := *kv
+			if len(kv.Value) != 0 {
+				pkv.Value = projectJSONFields(kv.Value, creq.ProjectionJsonPaths, paths)
+			}
+			return &pkv
+		}
+
+	default:
+		return nil, rpctypes.ErrGRPCWatchInvalidProjection
+	}
+
+	return func(ev *mvccpb.Event) *mvccpb.Event {
+		pev := *ev
+		if ev.Kv != nil {
+			pev.Kv = project(ev.Kv)
+		}
+		if ev.PrevKv != nil {
+			pev.PrevKv = project(ev.PrevKv)
+		}
+		return &pev
+	}, nil
+}
+
+// projectJSONFields returns the JSON object of the fields of a JSON value at
+// the paths, keyed by the names of the paths.
+func projectJSONFields(v []byte, names []string, paths [][]string) []byte {
+	fields := make(map[string]interface{}, len(paths))
+	dec := json.NewDecoder(bytes.NewReader(v))
+	// keep the numbers as they are
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err == nil {
+		for i, path := range paths {
+			if f, ok := lookupJSONField(doc, path); ok {
+				fields[names[i]] = f
+			}
+		}
+	}
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return []byte("{}")
+	}
+	return b
+}
@@ -0,0 +1,77 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+)
+
+// resumeTokenVersion is the version of the encoding of the resume tokens,
+// the first byte of a token followed by the create request resuming the
+// watcher. A token holds no state of the member issuing it, so that any
+// member resumes the watcher.
+const resumeTokenVersion byte = 1
+
+// newResumeTemplate returns the create request a resume token of the watcher
+// of creq is made of, before the keys of creq are scoped and normalized.
+func newResumeTemplate(creq *pb.WatchCreateRequest) *pb.WatchCreateRequest {
+	b, err := creq.Marshal()
+	if err != nil {
+		return nil
+	}
+	tmpl := &pb.WatchCreateRequest{}
+	if err = tmpl.Unmarshal(b); err != nil {
+		return nil
+	}
+	// the comparisons only guard the creation of the watcher
+	tmpl.Compare = nil
+	tmpl.WatchId = 0
+	tmpl.ResumeToken = nil
+	tmpl.Resumable = true
+	return tmpl
+}
+
+// resumeToken returns the token resuming the watcher of the template from
+// rev.
+func resumeToken(tmpl *pb.WatchCreateRequest, rev int64) []byte {
+	req := *tmpl
+	req.StartRevision = rev
+	b := make([]byte, 1+req.Size())
+	b[0] = resumeTokenVersion
+	if _, err := req.MarshalTo(b[1:]); err != nil {
+		return nil
+	}
+	return b
+}
+
+// parseResumeToken returns the create request resuming the watcher of the
+// token, with the watch ID of creq.
+func parseResumeToken(creq *pb.WatchCreateRequest) (*pb.WatchCreateRequest, error) {
+	token := creq.ResumeToken
+	if len(token) == 0 || token[0] != resumeTokenVersion {
+		return nil, rpctypes.ErrGRPCWatchInvalidResumeToken
+	}
+	req := &pb.WatchCreateRequest{}
+	if err := req.Unmarshal(token[1:]); err != nil {
+		return nil, rpctypes.ErrGRPCWatchInvalidResumeToken
+	}
+	if req.StartRevision <= 0 || len(req.ResumeToken) != 0 || len(req.Compare) != 0 {
+		return nil, rpctypes.ErrGRPCWatchInvalidResumeToken
+	}
+	req.WatchId = creq.WatchId
+	req.Resumable = true
+	return req, nil
+}
@@ -16,11 +16,21 @@ package v3rpc
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
 	"math"
+	"math/rand"
+	"reflect"
 	"testing"
+	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/metadata"
 )
 
 func TestSendFragment(t *testing.T) {
@@ -93,3 +103,404 @@ func createResponse(dataSize, events int) (resp *pb.WatchResponse) {
 	}
 	return resp
 }
+
+func TestValueFiltersFromRequest(t *testing.T) {
+	jsonEqual := func(path, value string) *pb.WatchValuePredicate {
+		return &pb.WatchValuePredicate{Type: pb.WatchValuePredicate_JSON_FIELD_EQUAL, JsonPath: path, Value: []byte(value)}
+	}
+	prefix := &pb.WatchValuePredicate{Type: pb.WatchValuePredicate_PREFIX, Value: []byte(`{"kind":"pod"`)}
+	size := &pb.WatchValuePredicate{Type: pb.WatchValuePredicate_SIZE, MinSize: 2, MaxSize: 60}
+
+	tests := []struct {
+		preds []*pb.WatchValuePredicate
+		ev    mvccpb.Event
+		// whether the event is filtered out
+		filtered bool
+	}{
+		{nil, putEvent(`anything`), false},
+		{[]*pb.WatchValuePredicate{jsonEqual("status.phase", `"Running"`)}, putEvent(`{"status":{"phase":"Running"}}`), false},
+		{[]*pb.WatchValuePredicate{jsonEqual("status.phase", `"Running"`)}, putEvent(`{"status":{"phase":"Pending"}}`), true},
+		{[]*pb.WatchValuePredicate{jsonEqual("status.phase", `"Running"`)}, putEvent(`{"status":"Running"}`), true},
+		{[]*pb.WatchValuePredicate{jsonEqual("status.phase", `"Running"`)}, putEvent(`not json`), true},
+		{[]*pb.WatchValuePredicate{jsonEqual("replicas", `3`)}, putEvent(`{"replicas":3.0}`), false},
+		{[]*pb.WatchValuePredicate{jsonEqual("labels", `{"a":"b"}`)}, putEvent(`{"labels":{"a":"b"}}`), false},
+		{[]*pb.WatchValuePredicate{prefix}, putEvent(`{"kind":"pod","name":"a"}`), false},
+		{[]*pb.WatchValuePredicate{prefix}, putEvent(`{"kind":"node"}`), true},
+		{[]*pb.WatchValuePredicate{size}, putEvent(`x`), true},
+		{[]*pb.WatchValuePredicate{size}, putEvent(`xx`), false},
+		{[]*pb.WatchValuePredicate{{Type: pb.WatchValuePredicate_SIZE, MinSize: 2}}, putEvent(string(make([]byte, 1024))), false},
+		// all of the predicates must be satisfied
+		{[]*pb.WatchValuePredicate{prefix, size, jsonEqual("name", `"a"`)}, putEvent(`{"kind":"pod","name":"a"}`), false},
+		{[]*pb.WatchValuePredicate{prefix, size, jsonEqual("name", `"b"`)}, putEvent(`{"kind":"pod","name":"a"}`), true},
+		// delete events are not filtered
+		{[]*pb.WatchValuePredicate{prefix}, mvccpb.Event{Type: mvccpb.DELETE, Kv: &mvccpb.KeyValue{Key: []byte("foo")}}, false},
+	}
+	for i, tt := range tests {
+		filters, err := ValueFiltersFromRequest(&pb.WatchCreateRequest{ValuePredicates: tt.preds})
+		if err != nil {
+			t.Fatalf("#%d: unexpected error %v", i, err)
+		}
+		filtered := false
+		for _, f := range filters {
+			filtered = filtered || f(tt.ev)
+		}
+		if filtered != tt.filtered {
+			t.Errorf("#%d: expected filtered %v, got %v", i, tt.filtered, filtered)
+		}
+	}
+}
+
+func TestValueFiltersFromRequestInvalid(t *testing.T) {
+	for i, p := range []*pb.WatchValuePredicate{
+		{Type: pb.WatchValuePredicate_JSON_FIELD_EQUAL, Value: []byte(`1`)},
+		{Type: pb.WatchValuePredicate_JSON_FIELD_EQUAL, JsonPath: "a..b", Value: []byte(`1`)},
+		{Type: pb.WatchValuePredicate_JSON_FIELD_EQUAL, JsonPath: "a", Value: []byte(`not json`)},
+		{Type: pb.WatchValuePredicate_SIZE, MinSize: -1},
+		{Type: pb.WatchValuePredicate_SIZE, MinSize: 10, MaxSize: 5},
+		{Type: 42},
+	} {
+		_, err := ValueFiltersFromRequest(&pb.WatchCreateRequest{ValuePredicates: []*pb.WatchValuePredicate{p}})
+		if err != rpctypes.ErrGRPCWatchInvalidValuePredicate {
+			t.Errorf("#%d: expected %v, got %v", i, rpctypes.ErrGRPCWatchInvalidValuePredicate, err)
+		}
+	}
+}
+
+func putEvent(v string) mvccpb.Event {
+	return mvccpb.Event{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte("foo"), Value: []byte(v)}}
+}
+
+func TestSendFragmentResumeToken(t *testing.T) {
+	wr := createResponse(11, 5)
+	wr.ResumeToken = []byte("token")
+	var resps []*pb.WatchResponse
+	if err := sendFragments(wr, 20, func(wr *pb.WatchResponse) error {
+		resps = append(resps, wr)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for i, resp := range resps {
+		last := i == len(resps)-1
+		if got := len(resp.ResumeToken) != 0; got != last {
+			t.Errorf("#%d: expected resume token %v, got %q", i, last, resp.ResumeToken)
+		}
+	}
+}
+
+func TestResumeToken(t *testing.T) {
+	creq := &pb.WatchCreateRequest{
+		Key:              []byte("foo"),
+		RangeEnd:         []byte("fop"),
+		StartRevision:    3,
+		PrevKv:           true,
+		Filters:          []pb.WatchCreateRequest_FilterType{pb.WatchCreateRequest_NODELETE},
+		AdditionalRanges: []*pb.WatchKeyRange{{Key: []byte("bar")}},
+		Compare:          []*pb.Compare{{Key: []byte("foo")}},
+		WatchId:          7,
+		Resumable:        true,
+	}
+	tmpl := newResumeTemplate(creq)
+	// the template is not changed with the request
+	creq.AdditionalRanges[0].Key = []byte("baz")
+
+	rreq, err := parseResumeToken(&pb.WatchCreateRequest{WatchId: 9, ResumeToken: resumeToken(tmpl, 42)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &pb.WatchCreateRequest{
+		Key:              []byte("foo"),
+		RangeEnd:         []byte("fop"),
+		StartRevision:    42,
+		PrevKv:           true,
+		Filters:          []pb.WatchCreateRequest_FilterType{pb.WatchCreateRequest_NODELETE},
+		AdditionalRanges: []*pb.WatchKeyRange{{Key: []byte("bar")}},
+		WatchId:          9,
+		Resumable:        true,
+	}
+	if !reflect.DeepEqual(rreq, want) {
+		t.Errorf("expected %+v, got %+v", want, rreq)
+	}
+
+	for i, token := range [][]byte{
+		{},
+		[]byte("\x02garbage"),
+		{resumeTokenVersion, 0xff},
+		// no start revision
+		resumeToken(tmpl, 0),
+	} {
+		if _, err = parseResumeToken(&pb.WatchCreateRequest{ResumeToken: token}); err != rpctypes.ErrGRPCWatchInvalidResumeToken {
+			t.Errorf("#%d: expected %v, got %v", i, rpctypes.ErrGRPCWatchInvalidResumeToken, err)
+		}
+	}
+}
+
+func TestBatchOptionsFromRequest(t *testing.T) {
+	tests := []struct {
+		latencyMs, events int64
+		opts              watchBatchOptions
+		batching          bool
+		err               error
+	}{
+		{0, 0, watchBatchOptions{}, false, nil},
+		{50, 0, watchBatchOptions{maxLatency: 50 * time.Millisecond}, true, nil},
+		{50, 1000, watchBatchOptions{maxLatency: 50 * time.Millisecond, maxEvents: 1000}, true, nil},
+		{0, 1000, watchBatchOptions{}, false, rpctypes.ErrGRPCWatchInvalidBatchOptions},
+		{-1, 0, watchBatchOptions{}, false, rpctypes.ErrGRPCWatchInvalidBatchOptions},
+		{50, -1, watchBatchOptions{}, false, rpctypes.ErrGRPCWatchInvalidBatchOptions},
+		{11000, 0, watchBatchOptions{}, false, rpctypes.ErrGRPCWatchInvalidBatchOptions},
+	}
+	for i, tt := range tests {
+		opts, batching, err := batchOptionsFromRequest(&pb.WatchCreateRequest{BatchMaxLatencyMs: tt.latencyMs, BatchMaxEvents: tt.events})
+		if opts != tt.opts || batching != tt.batching || err != tt.err {
+			t.Errorf("#%d: expected (%+v, %v, %v), got (%+v, %v, %v)", i, tt.opts, tt.batching, tt.err, opts, batching, err)
+		}
+	}
+}
+
+func TestWatchBatcher(t *testing.T) {
+	b := newWatchBatcher(math.MaxInt32)
+	opts := watchBatchOptions{maxLatency: 50 * time.Millisecond, maxEvents: 3}
+	now := time.Now()
+	resp := func(rev int64, events int) *pb.WatchResponse {
+		wr := createResponse(1, events)
+		wr.WatchId = 1
+		wr.Header = &pb.ResponseHeader{Revision: rev}
+		return wr
+	}
+
+	if wrs := b.add(1, resp(2, 1), opts, now); len(wrs) != 0 {
+		t.Fatalf("expected the events batched, got %+v", wrs)
+	}
+	if next, ok := b.next(); !ok || !next.Equal(now.Add(opts.maxLatency)) {
+		t.Fatalf("expected the deadline %v, got %v, %v", now.Add(opts.maxLatency), next, ok)
+	}
+	if wrs := b.expired(now.Add(opts.maxLatency - time.Millisecond)); len(wrs) != 0 {
+		t.Fatalf("expected no expired batch, got %+v", wrs)
+	}
+	wrs := b.expired(now.Add(opts.maxLatency))
+	if len(wrs) != 1 || len(wrs[0].Events) != 1 {
+		t.Fatalf("expected the expired batch of one event, got %+v", wrs)
+	}
+
+	// full batch
+	b.add(1, resp(3, 2), opts, now)
+	wrs = b.add(1, resp(4, 1), opts, now)
+	if len(wrs) != 1 || len(wrs[0].Events) != 3 || wrs[0].Header.Revision != 4 {
+		t.Fatalf("expected the full batch of three events at revision 4, got %+v", wrs)
+	}
+	if _, ok := b.next(); ok {
+		t.Fatal("expected no batch")
+	}
+
+	// a progress notification is sent after the batch
+	b.add(1, resp(5, 1), opts, now)
+	wrs = b.add(1, resp(6, 0), opts, now)
+	if len(wrs) != 2 || len(wrs[0].Events) != 1 || len(wrs[1].Events) != 0 {
+		t.Fatalf("expected the batch and the progress notification, got %+v", wrs)
+	}
+
+	// the batches of the canceled watchers are dropped
+	b.add(1, resp(7, 1), opts, now)
+	b.drop(1)
+	if wrs = b.flush(); len(wrs) != 0 {
+		t.Fatalf("expected no batch, got %+v", wrs)
+	}
+
+	// a batch is sent once as large as a request may be
+	b = newWatchBatcher(2 * resp(1, 1).Events[0].Size())
+	b.add(1, resp(8, 1), opts, now)
+	if wrs = b.add(1, resp(9, 1), opts, now); len(wrs) != 1 {
+		t.Fatalf("expected the batch sent by size, got %+v", wrs)
+	}
+}
+
+func TestProjectionFromRequest(t *testing.T) {
+	kv := &mvccpb.KeyValue{Key: []byte("foo"), Value: []byte(`{"a":{"b":12345678901234567890},"c":"x","d":true}`), CreateRevision: 2, ModRevision: 3, Version: 2, Lease: 7}
+	prevKV := &mvccpb.KeyValue{Key: []byte("foo"), Value: []byte(`not json`), CreateRevision: 2, ModRevision: 2, Version: 1}
+	tests := []struct {
+		projection  pb.WatchCreateRequest_Projection
+		paths       []string
+		kv, prevKV  *mvccpb.KeyValue
+		notProjects bool
+	}{
+		{projection: pb.WatchCreateRequest_FULL, notProjects: true},
+		{
+			projection: pb.WatchCreateRequest_KEYS_ONLY,
+			kv:         &mvccpb.KeyValue{Key: []byte("foo"), ModRevision: 3},
+			prevKV:     &mvccpb.KeyValue{Key: []byte("foo"), ModRevision: 2},
+		},
+		{
+			projection: pb.WatchCreateRequest_METADATA_ONLY,
+			kv:         &mvccpb.KeyValue{Key: []byte("foo"), CreateRevision: 2, ModRevision: 3, Version: 2, Lease: 7},
+			prevKV:     &mvccpb.KeyValue{Key: []byte("foo"), CreateRevision: 2, ModRevision: 2, Version: 1},
+		},
+		{
+			projection: pb.WatchCreateRequest_JSON_FIELDS,
+			paths:      []string{"a.b", "c", "e"},
+			kv:         &mvccpb.KeyValue{Key: []byte("foo"), Value: []byte(`{"a.b":12345678901234567890,"c":"x"}`), CreateRevision: 2, ModRevision: 3, Version: 2, Lease: 7},
+			prevKV:     &mvccpb.KeyValue{Key: []byte("foo"), Value: []byte(`{}`), CreateRevision: 2, ModRevision: 2, Version: 1},
+		},
+	}
+	for i, tt := range tests {
+		project, err := ProjectionFromRequest(&pb.WatchCreateRequest{Projection: tt.projection, ProjectionJsonPaths: tt.paths})
+		if err != nil {
+			t.Fatalf("#%d: unexpected error %v", i, err)
+		}
+		if tt.notProjects {
+			if project != nil {
+				t.Errorf("#%d: expected no projection", i)
+			}
+			continue
+		}
+		ev := &mvccpb.Event{Type: mvccpb.PUT, Kv: kv, PrevKv: prevKV}
+		pev := project(ev)
+		if !reflect.DeepEqual(pev.Kv, tt.kv) || !reflect.DeepEqual(pev.PrevKv, tt.prevKV) {
+			t.Errorf("#%d: expected %+v and %+v, got %+v and %+v", i, tt.kv, tt.prevKV, pev.Kv, pev.PrevKv)
+		}
+		if ev.Kv != kv || ev.PrevKv != prevKV || len(kv.Value) == 0 {
+			t.Errorf("#%d: expected the event unchanged", i)
+		}
+	}
+
+	for i, creq := range []*pb.WatchCreateRequest{
+		{Projection: pb.WatchCreateRequest_JSON_FIELDS},
+		{Projection: pb.WatchCreateRequest_JSON_FIELDS, ProjectionJsonPaths: []string{"a..b"}},
+		{Projection: pb.WatchCreateRequest_KEYS_ONLY, ProjectionJsonPaths: []string{"a"}},
+		{ProjectionJsonPaths: []string{"a"}},
+		{Projection: 42},
+	} {
+		if _, err := ProjectionFromRequest(creq); err != rpctypes.ErrGRPCWatchInvalidProjection {
+			t.Errorf("#%d: expected %v, got %v", i, rpctypes.ErrGRPCWatchInvalidProjection, err)
+		}
+	}
+}
+
+func TestProgressOptionsFromRequest(t *testing.T) {
+	tests := []struct {
+		intervalMs int64
+		heartbeat  bool
+		opts       watchProgressOptions
+		own        bool
+		err        error
+	}{
+		{0, false, watchProgressOptions{}, false, nil},
+		{500, false, watchProgressOptions{interval: 500 * time.Millisecond}, true, nil},
+		{500, true, watchProgressOptions{interval: 500 * time.Millisecond, heartbeat: true}, true, nil},
+		{0, true, watchProgressOptions{interval: GetProgressReportInterval(), heartbeat: true}, true, nil},
+		// raised to the minimum
+		{1, false, watchProgressOptions{interval: minWatchProgressInterval}, true, nil},
+		{-1, false, watchProgressOptions{}, false, rpctypes.ErrGRPCWatchInvalidProgressNotify},
+	}
+	for i, tt := range tests {
+		opts, own, err := progressOptionsFromRequest(&pb.WatchCreateRequest{ProgressNotifyIntervalMs: tt.intervalMs, ProgressNotifyHeartbeat: tt.heartbeat})
+		if tt.intervalMs == 0 && tt.heartbeat {
+			// the server-wide interval is jittered
+			if opts.interval < GetProgressReportInterval()/2 || !opts.heartbeat || !own || err != nil {
+				t.Errorf("#%d: expected the server-wide interval, got (%+v, %v, %v)", i, opts, own, err)
+			}
+			continue
+		}
+		if opts != tt.opts || own != tt.own || err != tt.err {
+			t.Errorf("#%d: expected (%+v, %v, %v), got (%+v, %v, %v)", i, tt.opts, tt.own, tt.err, opts, own, err)
+		}
+	}
+}
+
+func TestWatchProgressScheduler(t *testing.T) {
+	ps := newWatchProgressScheduler()
+	now := time.Now()
+	if _, ok := ps.next(); ok {
+		t.Fatal("expected no progress scheduled")
+	}
+	ps.schedule(1, time.Second, now)
+	ps.schedule(2, 2*time.Second, now)
+	if next, ok := ps.next(); !ok || !next.Equal(now.Add(time.Second)) {
+		t.Fatalf("expected the due time %v, got %v, %v", now.Add(time.Second), next, ok)
+	}
+	if ids := ps.expired(now.Add(time.Second - time.Millisecond)); len(ids) != 0 {
+		t.Fatalf("expected no progress due, got %v", ids)
+	}
+	if ids := ps.expired(now.Add(time.Second)); len(ids) != 1 || ids[0] != 1 {
+		t.Fatalf("expected the progress of watcher 1 due, got %v", ids)
+	}
+	ps.drop(2)
+	if _, ok := ps.next(); ok {
+		t.Fatal("expected no progress scheduled after drop")
+	}
+}
+
+func TestNegotiateWatchCompression(t *testing.T) {
+	tests := []struct {
+		accept []string
+		want   pb.WatchResponse_Compression
+	}{
+		{accept: nil, want: pb.WatchResponse_NONE},
+		{accept: []string{"snappy"}, want: pb.WatchResponse_NONE},
+		{accept: []string{"zstd,gzip"}, want: pb.WatchResponse_ZSTD},
+		{accept: []string{"snappy, gzip", "zstd"}, want: pb.WatchResponse_GZIP},
+	}
+	for _, tt := range tests {
+		md := metadata.MD{}
+		for _, v := range tt.accept {
+			md.Append(rpctypes.MetadataWatchCompressionKey, v)
+		}
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		if got := negotiateWatchCompression(ctx); got != tt.want {
+			t.Errorf("%v: compression = %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestCompressWatchResponse(t *testing.T) {
+	ev := func(val []byte) *mvccpb.Event {
+		return &mvccpb.Event{Kv: &mvccpb.KeyValue{Key: []byte("foo"), Value: val, ModRevision: 2}}
+	}
+	small := &pb.WatchResponse{WatchId: 1, Events: []*mvccpb.Event{ev([]byte("bar"))}}
+	large := &pb.WatchResponse{WatchId: 1, Events: []*mvccpb.Event{ev(bytes.Repeat([]byte("bar"), 1024))}}
+	random := make([]byte, 4096)
+	rand.Read(random)
+	incompressible := &pb.WatchResponse{WatchId: 1, Events: []*mvccpb.Event{ev(random)}}
+
+	for _, codec := range []pb.WatchResponse_Compression{pb.WatchResponse_GZIP, pb.WatchResponse_ZSTD} {
+		if got := compressWatchResponse(small, codec); got != small {
+			t.Errorf("%v: compressed the small response %+v", codec, got)
+		}
+		if got := compressWatchResponse(incompressible, codec); got != incompressible {
+			t.Errorf("%v: compressed the incompressible response", codec)
+		}
+
+		got := compressWatchResponse(large, codec)
+		if got.Compression != codec || got.Events != nil || got.WatchId != large.WatchId {
+			t.Fatalf("%v: unexpected compressed response %+v", codec, got)
+		}
+		if got.Size() >= large.Size() {
+			t.Errorf("%v: compressed size %d, want less than %d", codec, got.Size(), large.Size())
+		}
+		var data []byte
+		var err error
+		switch codec {
+		case pb.WatchResponse_GZIP:
+			var gr *gzip.Reader
+			if gr, err = gzip.NewReader(bytes.NewReader(got.CompressedEvents)); err == nil {
+				data, err = io.ReadAll(gr)
+			}
+		case pb.WatchResponse_ZSTD:
+			var dec *zstd.Decoder
+			if dec, err = zstd.NewReader(nil); err == nil {
+				data, err = dec.DecodeAll(got.CompressedEvents, nil)
+				dec.Close()
+			}
+		}
+		if err != nil {
+			t.Fatalf("%v: %v", codec, err)
+		}
+		var evs pb.WatchResponse
+		if err = evs.Unmarshal(data); err != nil {
+			t.Fatalf("%v: %v", codec, err)
+		}
+		if !reflect.DeepEqual(evs.Events, large.Events) {
+			t.Errorf("%v: decompressed events differ", codec)
+		}
+	}
+}
@@ -16,6 +16,7 @@ package etcdserver
 
 import (
 	"bytes"
+	"container/heap"
 	"context"
 	"fmt"
 	"sort"
@@ -31,10 +32,12 @@ import (
 	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/etcdserver/api"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/prefixquota"
 	"go.etcd.io/etcd/server/v3/etcdserver/version"
 	"go.etcd.io/etcd/server/v3/lease"
 	serverstorage "go.etcd.io/etcd/server/v3/storage"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
+	"go.etcd.io/etcd/server/v3/storage/schema"
 
 	"github.com/gogo/protobuf/proto"
 	"go.uber.org/zap"
@@ -69,15 +72,33 @@ type applierV3 interface {
 	Range(ctx context.Context, txn mvcc.TxnRead, r *pb.RangeRequest) (*pb.RangeResponse, error)
 	DeleteRange(txn mvcc.TxnWrite, dr *pb.DeleteRangeRequest) (*pb.DeleteRangeResponse, error)
 	Txn(ctx context.Context, rt *pb.TxnRequest) (*pb.TxnResponse, *traceutil.Trace, error)
+	Move(ctx context.Context, r *pb.MoveRequest) (*pb.MoveResponse, *traceutil.Trace, error)
+	SetLease(ctx context.Context, r *pb.SetLeaseRequest) (*pb.SetLeaseResponse, *traceutil.Trace, error)
+	Increment(ctx context.Context, r *pb.IncrementRequest) (*pb.IncrementResponse, *traceutil.Trace, error)
+	PutIfAbsent(ctx context.Context, r *pb.PutIfAbsentRequest) (*pb.PutIfAbsentResponse, *traceutil.Trace, error)
+	GetAndDelete(ctx context.Context, r *pb.GetAndDeleteRequest) (*pb.GetAndDeleteResponse, *traceutil.Trace, error)
+	BulkWrite(ctx context.Context, r *pb.BulkWriteRequest) (*pb.BulkWriteResponse, *traceutil.Trace, error)
 	Compaction(compaction *pb.CompactionRequest) (*pb.CompactionResponse, <-chan struct{}, *traceutil.Trace, error)
 
 	LeaseGrant(lc *pb.LeaseGrantRequest) (*pb.LeaseGrantResponse, error)
 	LeaseRevoke(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevokeResponse, error)
+	LeaseExpire(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevokeResponse, error)
+	LeaseGrantBulk(lc *pb.LeaseGrantBulkRequest) (*pb.LeaseGrantBulkResponse, error)
+	LeaseRevokeBulk(lc *pb.LeaseRevokeBulkRequest) (*pb.LeaseRevokeBulkResponse, error)
 
 	LeaseCheckpoint(lc *pb.LeaseCheckpointRequest) (*pb.LeaseCheckpointResponse, error)
 
 	Alarm(*pb.AlarmRequest) (*pb.AlarmResponse, error)
 
+	PutChunk(r *pb.PutChunkRequest) (*pb.EmptyResponse, error)
+	PutChunked(ctx context.Context, r *pb.PutChunkedRequest) (*pb.PutResponse, *traceutil.Trace, error)
+
+	KeyExpire(r *pb.KeyExpireRequest) (*pb.EmptyResponse, error)
+	PutChunkExpire(r *pb.PutChunkExpireRequest) (*pb.EmptyResponse, error)
+
+	PrefixQuotaSet(r *pb.PrefixQuotaSetRequest) (*pb.PrefixQuotaSetResponse, error)
+	PrefixQuotaDelete(r *pb.PrefixQuotaDeleteRequest) (*pb.PrefixQuotaDeleteResponse, error)
+
 	Authenticate(r *pb.InternalAuthenticateRequest) (*pb.AuthenticateResponse, error)
 
 	AuthEnable() (*pb.AuthEnableResponse, error)
@@ -94,6 +115,8 @@ type applierV3 interface {
 	RoleGrantPermission(ua *pb.AuthRoleGrantPermissionRequest) (*pb.AuthRoleGrantPermissionResponse, error)
 	RoleGet(ua *pb.AuthRoleGetRequest) (*pb.AuthRoleGetResponse, error)
 	RoleRevokePermission(ua *pb.AuthRoleRevokePermissionRequest) (*pb.AuthRoleRevokePermissionResponse, error)
+	SessionRevoke(ua *pb.AuthSessionRevokeRequest) (*pb.AuthSessionRevokeResponse, error)
+	UserRoleExpire(ua *pb.AuthUserRoleExpireRequest) (*pb.EmptyResponse, error)
 	RoleDelete(ua *pb.AuthRoleDeleteRequest) (*pb.AuthRoleDeleteResponse, error)
 	UserList(ua *pb.AuthUserListRequest) (*pb.AuthUserListResponse, error)
 	RoleList(ua *pb.AuthRoleListRequest) (*pb.AuthRoleListResponse, error)
@@ -129,6 +152,7 @@ func (s *EtcdServer) newApplierV3() applierV3 {
 		s.AuthStore(),
 		newQuotaApplierV3(s, s.newApplierV3Backend()),
 		s.lessor,
+		s.Cfg.MaxLeasesPerUser,
 	)
 }
 
@@ -137,7 +161,7 @@ func (a *applierV3backend) Apply(r *pb.InternalRaftRequest, shouldApplyV3 member
 	ar := &applyResult{}
 	defer func(start time.Time) {
 		success := ar.err == nil || ar.err == mvcc.ErrCompacted
-		applySec.WithLabelValues(v3Version, op, strconv.FormatBool(success)).Observe(time.Since(start).Seconds())
+		observeWithTrace(applySec.WithLabelValues(v3Version, op, strconv.FormatBool(success)), time.Since(start).Seconds(), a.s.tracer.spanContextOf(r))
 		warnOfExpensiveRequest(a.s.Logger(), a.s.Cfg.WarningApplyDuration, start, &pb.InternalRaftStringer{Request: r}, ar.resp, ar.err)
 		if !success {
 			warnOfFailedRequest(a.s.Logger(), start, &pb.InternalRaftStringer{Request: r}, ar.resp, ar.err)
@@ -171,12 +195,42 @@ func (a *applierV3backend) Apply(r *pb.InternalRaftRequest, shouldApplyV3 member
 	case r.Put != nil:
 		op = "Put"
 		ar.resp, ar.trace, ar.err = a.s.applyV3.Put(context.TODO(), nil, r.Put)
+	case r.PutChunk != nil:
+		op = "PutChunk"
+		ar.resp, ar.err = a.s.applyV3.PutChunk(r.PutChunk)
+	case r.PutChunked != nil:
+		op = "PutChunked"
+		ar.resp, ar.trace, ar.err = a.s.applyV3.PutChunked(context.TODO(), r.PutChunked)
+	case r.KeyExpire != nil:
+		op = "KeyExpire"
+		ar.resp, ar.err = a.s.applyV3.KeyExpire(r.KeyExpire)
+	case r.PutChunkExpire != nil:
+		op = "PutChunkExpire"
+		ar.resp, ar.err = a.s.applyV3.PutChunkExpire(r.PutChunkExpire)
 	case r.DeleteRange != nil:
 		op = "DeleteRange"
 		ar.resp, ar.err = a.s.applyV3.DeleteRange(nil, r.DeleteRange)
 	case r.Txn != nil:
 		op = "Txn"
 		ar.resp, ar.trace, ar.err = a.s.applyV3.Txn(context.TODO(), r.Txn)
+	case r.Move != nil:
+		op = "Move"
+		ar.resp, ar.trace, ar.err = a.s.applyV3.Move(context.TODO(), r.Move)
+	case r.SetLease != nil:
+		op = "SetLease"
+		ar.resp, ar.trace, ar.err = a.s.applyV3.SetLease(context.TODO(), r.SetLease)
+	case r.Increment != nil:
+		op = "Increment"
+		ar.resp, ar.trace, ar.err = a.s.applyV3.Increment(context.TODO(), r.Increment)
+	case r.PutIfAbsent != nil:
+		op = "PutIfAbsent"
+		ar.resp, ar.trace, ar.err = a.s.applyV3.PutIfAbsent(context.TODO(), r.PutIfAbsent)
+	case r.GetAndDelete != nil:
+		op = "GetAndDelete"
+		ar.resp, ar.trace, ar.err = a.s.applyV3.GetAndDelete(context.TODO(), r.GetAndDelete)
+	case r.BulkWrite != nil:
+		op = "BulkWrite"
+		ar.resp, ar.trace, ar.err = a.s.applyV3.BulkWrite(context.TODO(), r.BulkWrite)
 	case r.Compaction != nil:
 		op = "Compaction"
 		ar.resp, ar.physc, ar.trace, ar.err = a.s.applyV3.Compaction(r.Compaction)
@@ -186,12 +240,27 @@ func (a *applierV3backend) Apply(r *pb.InternalRaftRequest, shouldApplyV3 member
 	case r.LeaseRevoke != nil:
 		op = "LeaseRevoke"
 		ar.resp, ar.err = a.s.applyV3.LeaseRevoke(r.LeaseRevoke)
+	case r.LeaseExpire != nil:
+		op = "LeaseExpire"
+		ar.resp, ar.err = a.s.applyV3.LeaseExpire(r.LeaseExpire)
+	case r.LeaseGrantBulk != nil:
+		op = "LeaseGrantBulk"
+		ar.resp, ar.err = a.s.applyV3.LeaseGrantBulk(r.LeaseGrantBulk)
+	case r.LeaseRevokeBulk != nil:
+		op = "LeaseRevokeBulk"
+		ar.resp, ar.err = a.s.applyV3.LeaseRevokeBulk(r.LeaseRevokeBulk)
 	case r.LeaseCheckpoint != nil:
 		op = "LeaseCheckpoint"
 		ar.resp, ar.err = a.s.applyV3.LeaseCheckpoint(r.LeaseCheckpoint)
 	case r.Alarm != nil:
 		op = "Alarm"
 		ar.resp, ar.err = a.s.applyV3.Alarm(r.Alarm)
+	case r.PrefixQuotaSet != nil:
+		op = "PrefixQuotaSet"
+		ar.resp, ar.err = a.s.applyV3.PrefixQuotaSet(r.PrefixQuotaSet)
+	case r.PrefixQuotaDelete != nil:
+		op = "PrefixQuotaDelete"
+		ar.resp, ar.err = a.s.applyV3.PrefixQuotaDelete(r.PrefixQuotaDelete)
 	case r.Authenticate != nil:
 		op = "Authenticate"
 		ar.resp, ar.err = a.s.applyV3.Authenticate(r.Authenticate)
@@ -242,6 +311,12 @@ func (a *applierV3backend) Apply(r *pb.InternalRaftRequest, shouldApplyV3 member
 	case r.AuthRoleList != nil:
 		op = "AuthRoleList"
 		ar.resp, ar.err = a.s.applyV3.RoleList(r.AuthRoleList)
+	case r.AuthSessionRevoke != nil:
+		op = "AuthSessionRevoke"
+		ar.resp, ar.err = a.s.applyV3.SessionRevoke(r.AuthSessionRevoke)
+	case r.AuthUserRoleExpire != nil:
+		op = "AuthUserRoleExpire"
+		ar.resp, ar.err = a.s.applyV3.UserRoleExpire(r.AuthUserRoleExpire)
 	default:
 		a.s.lg.Panic("not implemented apply", zap.Stringer("raft-request", r))
 	}
@@ -299,7 +374,7 @@ func (a *applierV3backend) Put(ctx context.Context, txn mvcc.TxnWrite, p *pb.Put
 		}
 	}
 
-	resp.Header.Revision = txn.Put(p.Key, val, leaseID)
+	resp.Header.Revision = txn.PutExpiring(p.Key, val, leaseID, p.ExpireTime)
 	trace.AddField(traceutil.Field{Key: "response_revision", Value: resp.Header.Revision})
 	return resp, trace, nil
 }
@@ -409,15 +484,15 @@ func (a *applierV3backend) Range(ctx context.Context, txn mvcc.TxnRead, r *pb.Ra
 			sorter = &kvSortByMod{&kvSort{rr.KVs}}
 		case r.SortTarget == pb.RangeRequest_VALUE:
 			sorter = &kvSortByValue{&kvSort{rr.KVs}}
+		case r.SortTarget == pb.RangeRequest_LEASE:
+			sorter = &kvSortByLease{&kvSort{rr.KVs}}
 		default:
 			lg.Panic("unexpected sort target", zap.Int32("sort-target", int32(r.SortTarget)))
 		}
-		switch {
-		case sortOrder == pb.RangeRequest_ASCEND:
-			sort.Sort(sorter)
-		case sortOrder == pb.RangeRequest_DESCEND:
-			sort.Sort(sort.Reverse(sorter))
+		if sortOrder == pb.RangeRequest_DESCEND {
+			sorter = sort.Reverse(sorter)
 		}
+		sortKVs(sorter, int(r.Limit))
 	}
 
 	if r.Limit > 0 && len(rr.KVs) > int(r.Limit) {
@@ -534,7 +609,7 @@ func newTxnResp(rt *pb.TxnRequest, txnPath []bool) (txnResp *pb.TxnResponse, txn
 func compareToPath(rv mvcc.ReadView, rt *pb.TxnRequest) []bool {
 	txnPath := make([]bool, 1)
 	ops := rt.Success
-	if txnPath[0] = applyCompares(rv, rt.Compare); !txnPath[0] {
+	if txnPath[0] = ApplyCompares(rv, rt.Compare); !txnPath[0] {
 		ops = rt.Failure
 	}
 	for _, op := range ops {
@@ -547,7 +622,9 @@ func compareToPath(rv mvcc.ReadView, rt *pb.TxnRequest) []bool {
 	return txnPath
 }
 
-func applyCompares(rv mvcc.ReadView, cmps []*pb.Compare) bool {
+// ApplyCompares evaluates the given comparisons against the read view.
+// It returns true only if all of them succeed.
+func ApplyCompares(rv mvcc.ReadView, cmps []*pb.Compare) bool {
 	for _, c := range cmps {
 		if !applyCompare(rv, c) {
 			return false
@@ -569,7 +646,8 @@ func applyCompare(rv mvcc.ReadView, c *pb.Compare) bool {
 		return false
 	}
 	if len(rr.KVs) == 0 {
-		if c.Target == pb.Compare_VALUE {
+		switch c.Target {
+		case pb.Compare_VALUE, pb.Compare_VALUE_INT, pb.Compare_VALUE_PREFIX:
 			// Always fail if comparing a value on a key/keys that doesn't exist;
 			// nil == empty string in grpc; no way to represent missing value
 			return false
@@ -594,6 +672,25 @@ func compareKV(c *pb.Compare, ckv mvccpb.KeyValue) bool {
 			v = tv.Value
 		}
 		result = bytes.Compare(ckv.Value, v)
+	case pb.Compare_VALUE_INT:
+		v, err := strconv.ParseInt(string(ckv.Value), 10, 64)
+		if err != nil {
+			return false
+		}
+		if tv, _ := c.TargetUnion.(*pb.Compare_ValueInt); tv != nil {
+			rev = tv.ValueInt
+		}
+		result = compareInt64(v, rev)
+	case pb.Compare_VALUE_PREFIX:
+		var prefix []byte
+		if tv, _ := c.TargetUnion.(*pb.Compare_ValuePrefix); tv != nil {
+			prefix = tv.ValuePrefix
+		}
+		v := ckv.Value
+		if len(v) > len(prefix) {
+			v = v[:len(prefix)]
+		}
+		result = bytes.Compare(v, prefix)
 	case pb.Compare_CREATE:
 		if tv, _ := c.TargetUnion.(*pb.Compare_CreateRevision); tv != nil {
 			rev = tv.CreateRevision
@@ -687,7 +784,18 @@ func (a *applierV3backend) Compaction(compaction *pb.CompactionRequest) (*pb.Com
 		traceutil.Field{Key: "revision", Value: compaction.Revision},
 	)
 
-	ch, err := a.s.KV().Compact(trace, compaction.Revision)
+	var ch <-chan struct{}
+	var err error
+	if len(compaction.Key) != 0 || len(compaction.RangeEnd) != 0 {
+		key, end := compaction.Key, mkGteRange(compaction.RangeEnd)
+		if len(compaction.RangeEnd) == 0 {
+			// the single key
+			end = append(append([]byte{}, key...), 0)
+		}
+		ch, err = a.s.KV().CompactRange(trace, key, end, compaction.Revision)
+	} else {
+		ch, err = a.s.KV().Compact(trace, compaction.Revision)
+	}
 	if err != nil {
 		return nil, ch, nil, err
 	}
@@ -713,6 +821,45 @@ func (a *applierV3backend) LeaseRevoke(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevo
 	return &pb.LeaseRevokeResponse{Header: newHeader(a.s)}, err
 }
 
+// LeaseExpire revokes the lease of lc the primary lessor found expired.
+func (a *applierV3backend) LeaseExpire(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevokeResponse, error) {
+	err := a.s.lessor.Expire(lease.LeaseID(lc.ID))
+	return &pb.LeaseRevokeResponse{Header: newHeader(a.s)}, err
+}
+
+// LeaseGrantBulk grants the leases of lc independently, a lease failing to
+// be granted carrying its error in its response.
+func (a *applierV3backend) LeaseGrantBulk(lc *pb.LeaseGrantBulkRequest) (*pb.LeaseGrantBulkResponse, error) {
+	resp := &pb.LeaseGrantBulkResponse{Header: newHeader(a.s), Leases: make([]*pb.LeaseGrantResponse, 0, len(lc.Leases))}
+	for _, r := range lc.Leases {
+		lr, err := a.LeaseGrant(r)
+		if err != nil {
+			lr = &pb.LeaseGrantResponse{ID: r.ID, Error: err.Error()}
+		}
+		lr.Header = nil
+		resp.Leases = append(resp.Leases, lr)
+	}
+	return resp, nil
+}
+
+// LeaseRevokeBulk revokes the leases of lc, reporting the leases not found
+// rather than failing on them.
+func (a *applierV3backend) LeaseRevokeBulk(lc *pb.LeaseRevokeBulkRequest) (*pb.LeaseRevokeBulkResponse, error) {
+	resp := &pb.LeaseRevokeBulkResponse{}
+	for _, id := range lc.IDs {
+		switch err := a.s.lessor.Revoke(lease.LeaseID(id)); err {
+		case nil:
+			resp.Revoked = append(resp.Revoked, id)
+		case lease.ErrLeaseNotFound:
+			resp.NotFound = append(resp.NotFound, id)
+		default:
+			return nil, err
+		}
+	}
+	resp.Header = newHeader(a.s)
+	return resp, nil
+}
+
 func (a *applierV3backend) LeaseCheckpoint(lc *pb.LeaseCheckpointRequest) (*pb.LeaseCheckpointResponse, error) {
 	for _, c := range lc.Checkpoints {
 		err := a.s.lessor.Checkpoint(lease.LeaseID(c.ID), c.Remaining_TTL)
@@ -746,6 +893,7 @@ func (a *applierV3backend) Alarm(ar *pb.AlarmRequest) (*pb.AlarmResponse, error)
 		}
 
 		lg.Warn("alarm raised", zap.String("alarm", m.Alarm.String()), zap.String("from", types.ID(m.MemberID).String()))
+		a.s.recordClusterEvent(pb.ClusterEvent_ALARM_ACTIVATE, types.ID(m.MemberID), m.Alarm.String(), membership.ApplyBoth)
 		switch m.Alarm {
 		case pb.AlarmType_CORRUPT:
 			a.s.applyV3 = newApplierV3Corrupt(a)
@@ -764,6 +912,7 @@ func (a *applierV3backend) Alarm(ar *pb.AlarmRequest) (*pb.AlarmResponse, error)
 		if !deactivated {
 			break
 		}
+		a.s.recordClusterEvent(pb.ClusterEvent_ALARM_DEACTIVATE, types.ID(m.MemberID), m.Alarm.String(), membership.ApplyBoth)
 
 		switch m.Alarm {
 		case pb.AlarmType_NOSPACE, pb.AlarmType_CORRUPT:
@@ -779,6 +928,316 @@ func (a *applierV3backend) Alarm(ar *pb.AlarmRequest) (*pb.AlarmResponse, error)
 	return resp, nil
 }
 
+func (a *applierV3backend) PutChunk(r *pb.PutChunkRequest) (*pb.EmptyResponse, error) {
+	tx := a.s.Backend().BatchTx()
+	tx.LockInsideApply()
+	defer tx.Unlock()
+	schema.UnsafeCreatePutChunkBucket(tx)
+	schema.UnsafePutChunk(tx, r.UploadId, r.Index, r.ExpireTime, r.Data)
+	return &pb.EmptyResponse{}, nil
+}
+
+// PutChunkExpire deletes the chunks expired at the time of r, left by the
+// uploads that were neither applied nor discarded.
+func (a *applierV3backend) PutChunkExpire(r *pb.PutChunkExpireRequest) (*pb.EmptyResponse, error) {
+	tx := a.s.Backend().BatchTx()
+	tx.LockInsideApply()
+	defer tx.Unlock()
+	schema.UnsafeCreatePutChunkBucket(tx)
+	if n := schema.UnsafeDeleteExpiredChunks(tx, r.Time); n > 0 {
+		a.s.Logger().Info("deleted expired put chunks", zap.Int("chunks", n))
+	}
+	return &pb.EmptyResponse{}, nil
+}
+
+// PutChunked applies the put of r with the value assembled from its staged
+// chunks, which are deleted whether the put succeeds or not.
+func (a *applierV3backend) PutChunked(ctx context.Context, r *pb.PutChunkedRequest) (*pb.PutResponse, *traceutil.Trace, error) {
+	tx := a.s.Backend().BatchTx()
+	tx.LockInsideApply()
+	schema.UnsafeCreatePutChunkBucket(tx)
+	var value []byte
+	chunks := int64(0)
+	if r.Put != nil {
+		for _, c := range schema.UnsafeReadChunks(tx, r.UploadId, r.Chunks) {
+			value = append(value, c...)
+			chunks++
+		}
+	}
+	schema.UnsafeDeleteChunks(tx, r.UploadId)
+	tx.Unlock()
+
+	if r.Put == nil {
+		return &pb.PutResponse{Header: newHeader(a.s)}, nil, nil
+	}
+	if chunks != r.Chunks {
+		return nil, nil, ErrPutChunksMissing
+	}
+	p := *r.Put
+	p.Value = value
+	return a.s.applyV3.Put(ctx, nil, &p)
+}
+
+// Move applies the move of r as a txn deleting the keys moved and putting
+// them where they are moved to, so that the txn checks and quotas apply.
+func (a *applierV3backend) Move(ctx context.Context, r *pb.MoveRequest) (*pb.MoveResponse, *traceutil.Trace, error) {
+	var end []byte
+	if r.Prefix {
+		end = prefixRangeEnd(r.Key)
+	}
+	rr, err := a.s.KV().Range(ctx, r.Key, mkGteRange(end), mvcc.RangeOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rr.KVs) == 0 {
+		if !r.Prefix {
+			return nil, nil, ErrKeyNotFound
+		}
+		return &pb.MoveResponse{Header: newHeader(a.s)}, nil, nil
+	}
+
+	moved := make(map[string]struct{}, len(rr.KVs))
+	for _, kv := range rr.KVs {
+		moved[string(kv.Key)] = struct{}{}
+	}
+	txn := &pb.TxnRequest{Success: []*pb.RequestOp{{Request: &pb.RequestOp_RequestDeleteRange{
+		RequestDeleteRange: &pb.DeleteRangeRequest{Key: r.Key, RangeEnd: end},
+	}}}}
+	for _, kv := range rr.KVs {
+		dst := append(append([]byte{}, r.Destination...), kv.Key[len(r.Key):]...)
+		if _, ok := moved[string(dst)]; !ok && !r.Overwrite {
+			dr, err := a.s.KV().Range(ctx, dst, nil, mvcc.RangeOptions{Count: true})
+			if err != nil {
+				return nil, nil, err
+			}
+			if dr.Count != 0 {
+				return nil, nil, ErrKeyExists
+			}
+		}
+		txn.Success = append(txn.Success, &pb.RequestOp{Request: &pb.RequestOp_RequestPut{
+			RequestPut: &pb.PutRequest{Key: dst, Value: kv.Value, Lease: kv.Lease, ExpireTime: kv.ExpireTime},
+		}})
+	}
+	tresp, trace, err := a.s.applyV3.Txn(ctx, txn)
+	if err != nil {
+		return nil, trace, err
+	}
+	resp := &pb.MoveResponse{Header: tresp.Header, Moved: int64(len(rr.KVs))}
+	if r.PrevKv {
+		for i := range rr.KVs {
+			resp.PrevKvs = append(resp.PrevKvs, &rr.KVs[i])
+		}
+	}
+	return resp, trace, nil
+}
+
+// SetLease sets the lease of the keys of r in place, keeping their
+// revisions, or puts them again with their values as a txn if the revision
+// is to be bumped, so that the txn checks and quotas apply.
+func (a *applierV3backend) SetLease(ctx context.Context, r *pb.SetLeaseRequest) (*pb.SetLeaseResponse, *traceutil.Trace, error) {
+	leaseID := lease.LeaseID(r.Lease)
+	if leaseID != lease.NoLease && a.s.lessor.Lookup(leaseID) == nil {
+		return nil, nil, lease.ErrLeaseNotFound
+	}
+
+	if r.BumpRevision {
+		rr, err := a.s.KV().Range(ctx, r.Key, mkGteRange(r.RangeEnd), mvcc.RangeOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rr.KVs) == 0 {
+			return &pb.SetLeaseResponse{Header: newHeader(a.s)}, nil, nil
+		}
+		txn := &pb.TxnRequest{}
+		for _, kv := range rr.KVs {
+			txn.Success = append(txn.Success, &pb.RequestOp{Request: &pb.RequestOp_RequestPut{
+				RequestPut: &pb.PutRequest{Key: kv.Key, Lease: r.Lease, IgnoreValue: true, ExpireTime: kv.ExpireTime},
+			}})
+		}
+		tresp, trace, err := a.s.applyV3.Txn(ctx, txn)
+		if err != nil {
+			return nil, trace, err
+		}
+		return &pb.SetLeaseResponse{Header: tresp.Header, Count: int64(len(rr.KVs))}, trace, nil
+	}
+
+	trace := traceutil.New("set_lease",
+		a.s.Logger(),
+		traceutil.Field{Key: "key", Value: string(r.Key)},
+		traceutil.Field{Key: "range_end", Value: string(r.RangeEnd)},
+	)
+	txn := a.s.KV().Write(trace)
+	rr, err := txn.Range(ctx, r.Key, mkGteRange(r.RangeEnd), mvcc.RangeOptions{})
+	if err != nil {
+		txn.End()
+		return nil, trace, err
+	}
+	for _, kv := range rr.KVs {
+		txn.SetLease(kv.Key, leaseID)
+	}
+	txn.End()
+	return &pb.SetLeaseResponse{Header: newHeader(a.s), Count: int64(len(rr.KVs))}, trace, nil
+}
+
+// Increment adds the delta of r to the value of its key, or to its initial
+// value if the key does not exist, putting the new value as a txn so that
+// the txn checks and quotas apply. An existing key keeps its lease and
+// expiry time.
+func (a *applierV3backend) Increment(ctx context.Context, r *pb.IncrementRequest) (*pb.IncrementResponse, *traceutil.Trace, error) {
+	rr, err := a.s.KV().Range(ctx, r.Key, nil, mvcc.RangeOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	put := &pb.PutRequest{Key: r.Key}
+	v, created := r.Initial, len(rr.KVs) == 0
+	if created {
+		put.Lease = r.Lease
+	} else {
+		if v, err = parseCounter(rr.KVs[0].Value, r.Format); err != nil {
+			return nil, nil, err
+		}
+		put.IgnoreLease, put.ExpireTime = true, rr.KVs[0].ExpireTime
+	}
+	if v, err = addCounter(v, r.Delta); err != nil {
+		return nil, nil, err
+	}
+	put.Value = formatCounter(v, r.Format, r.Width)
+
+	txn := &pb.TxnRequest{Success: []*pb.RequestOp{{Request: &pb.RequestOp_RequestPut{RequestPut: put}}}}
+	tresp, trace, err := a.s.applyV3.Txn(ctx, txn)
+	if err != nil {
+		return nil, trace, err
+	}
+	return &pb.IncrementResponse{Header: tresp.Header, Value: v, Created: created}, trace, nil
+}
+
+// PutIfAbsent puts the key of r as a txn if it does not exist, so that the
+// txn checks and quotas apply, or returns the existing key.
+func (a *applierV3backend) PutIfAbsent(ctx context.Context, r *pb.PutIfAbsentRequest) (*pb.PutIfAbsentResponse, *traceutil.Trace, error) {
+	rr, err := a.s.KV().Range(ctx, r.Key, nil, mvcc.RangeOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rr.KVs) != 0 {
+		return &pb.PutIfAbsentResponse{Header: newHeader(a.s), PrevKv: &rr.KVs[0]}, nil, nil
+	}
+	txn := &pb.TxnRequest{Success: []*pb.RequestOp{{Request: &pb.RequestOp_RequestPut{
+		RequestPut: &pb.PutRequest{Key: r.Key, Value: r.Value, Lease: r.Lease},
+	}}}}
+	tresp, trace, err := a.s.applyV3.Txn(ctx, txn)
+	if err != nil {
+		return nil, trace, err
+	}
+	return &pb.PutIfAbsentResponse{Header: tresp.Header, Succeeded: true}, trace, nil
+}
+
+// GetAndDelete deletes the key of r as a txn, returning the key deleted.
+func (a *applierV3backend) GetAndDelete(ctx context.Context, r *pb.GetAndDeleteRequest) (*pb.GetAndDeleteResponse, *traceutil.Trace, error) {
+	txn := &pb.TxnRequest{Success: []*pb.RequestOp{{Request: &pb.RequestOp_RequestDeleteRange{
+		RequestDeleteRange: &pb.DeleteRangeRequest{Key: r.Key, PrevKv: true},
+	}}}}
+	tresp, trace, err := a.s.applyV3.Txn(ctx, txn)
+	if err != nil {
+		return nil, trace, err
+	}
+	resp := &pb.GetAndDeleteResponse{Header: tresp.Header}
+	if prevKvs := tresp.Responses[0].GetResponseDeleteRange().PrevKvs; len(prevKvs) != 0 {
+		resp.PrevKv = prevKvs[0]
+	}
+	return resp, trace, nil
+}
+
+// BulkWrite applies the requests of r independently in a single revision, a
+// request failing its checks carrying its error in its result rather than
+// failing the others.
+func (a *applierV3backend) BulkWrite(ctx context.Context, r *pb.BulkWriteRequest) (*pb.BulkWriteResponse, *traceutil.Trace, error) {
+	trace := traceutil.New("bulk_write",
+		a.s.Logger(),
+		traceutil.Field{Key: "number_of_requests", Value: len(r.Ops)},
+	)
+	ctx = context.WithValue(ctx, traceutil.TraceKey, trace)
+	txn := a.s.KV().Write(trace)
+	resp := &pb.BulkWriteResponse{Results: make([]*pb.BulkWriteResult, 0, len(r.Ops))}
+	for _, op := range r.Ops {
+		var (
+			respOp *pb.ResponseOp
+			err    error
+		)
+		switch tv := op.Request.(type) {
+		case *pb.RequestOp_RequestPut:
+			// the checks of a txn put, which the put does not do in a txn
+			if err = a.checkPut(txn, op); err == nil {
+				var pr *pb.PutResponse
+				if pr, _, err = a.Put(ctx, txn, tv.RequestPut); err == nil {
+					pr.Header = nil
+					respOp = &pb.ResponseOp{Response: &pb.ResponseOp_ResponsePut{ResponsePut: pr}}
+				}
+			}
+		case *pb.RequestOp_RequestDeleteRange:
+			var dr *pb.DeleteRangeResponse
+			if dr, err = a.DeleteRange(txn, tv.RequestDeleteRange); err == nil {
+				dr.Header = nil
+				respOp = &pb.ResponseOp{Response: &pb.ResponseOp_ResponseDeleteRange{ResponseDeleteRange: dr}}
+			}
+		}
+		result := &pb.BulkWriteResult{Response: respOp}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	txn.End()
+	resp.Header = newHeader(a.s)
+	return resp, trace, nil
+}
+
+// prefixRangeEnd returns the range end of the keys with prefix, "\x00" if
+// they range to the end of the keyspace.
+func prefixRangeEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return []byte{0}
+}
+
+// KeyExpire deletes the keys expired at the time of r, which are the same
+// on every member.
+func (a *applierV3backend) KeyExpire(r *pb.KeyExpireRequest) (*pb.EmptyResponse, error) {
+	keys := a.s.KV().ExpiredKeys(r.Time, int(r.Limit))
+	if len(keys) == 0 {
+		return &pb.EmptyResponse{}, nil
+	}
+	txn := a.s.KV().Write(traceutil.TODO())
+	for _, key := range keys {
+		txn.DeleteRange(key, nil)
+	}
+	txn.End()
+	a.s.Logger().Debug("deleted expired keys", zap.Int("keys", len(keys)))
+	return &pb.EmptyResponse{}, nil
+}
+
+func (a *applierV3backend) PrefixQuotaSet(r *pb.PrefixQuotaSetRequest) (*pb.PrefixQuotaSetResponse, error) {
+	a.s.prefixQuotas.Set(a.s.KV(), r)
+	a.s.Logger().Info(
+		"set prefix quota",
+		zap.String("prefix", string(r.Prefix)),
+		zap.Int64("max-bytes", r.MaxBytes),
+		zap.Int64("max-keys", r.MaxKeys),
+	)
+	return &pb.PrefixQuotaSetResponse{}, nil
+}
+
+func (a *applierV3backend) PrefixQuotaDelete(r *pb.PrefixQuotaDeleteRequest) (*pb.PrefixQuotaDeleteResponse, error) {
+	a.s.prefixQuotas.Delete(r.Prefix)
+	a.s.Logger().Info("deleted prefix quota", zap.String("prefix", string(r.Prefix)))
+	return &pb.PrefixQuotaDeleteResponse{}, nil
+}
+
 type applierV3Capped struct {
 	applierV3
 	q serverstorage.BackendQuota
@@ -799,10 +1258,22 @@ func (a *applierV3Capped) Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnRes
 	return a.applierV3.Txn(ctx, r)
 }
 
+func (a *applierV3Capped) BulkWrite(ctx context.Context, r *pb.BulkWriteRequest) (*pb.BulkWriteResponse, *traceutil.Trace, error) {
+	return nil, nil, ErrNoSpace
+}
+
+func (a *applierV3Capped) PutChunk(r *pb.PutChunkRequest) (*pb.EmptyResponse, error) {
+	return nil, ErrNoSpace
+}
+
 func (a *applierV3Capped) LeaseGrant(lc *pb.LeaseGrantRequest) (*pb.LeaseGrantResponse, error) {
 	return nil, ErrNoSpace
 }
 
+func (a *applierV3Capped) LeaseGrantBulk(lc *pb.LeaseGrantBulkRequest) (*pb.LeaseGrantBulkResponse, error) {
+	return nil, ErrNoSpace
+}
+
 func (a *applierV3backend) AuthEnable() (*pb.AuthEnableResponse, error) {
 	err := a.s.AuthStore().AuthEnable()
 	if err != nil {
@@ -824,6 +1295,13 @@ func (a *applierV3backend) AuthStatus() (*pb.AuthStatusResponse, error) {
 
 func (a *applierV3backend) Authenticate(r *pb.InternalAuthenticateRequest) (*pb.AuthenticateResponse, error) {
 	ctx := context.WithValue(context.WithValue(a.s.ctx, auth.AuthenticateParamIndex{}, a.s.consistIndex.ConsistentIndex()), auth.AuthenticateParamSimpleTokenPrefix{}, r.SimpleToken)
+	ctx = context.WithValue(ctx, auth.AuthenticateParamClientAddress{}, r.ClientAddress)
+	if len(r.Roles) != 0 {
+		ctx = context.WithValue(ctx, auth.AuthenticateParamRoles{}, r.Roles)
+	}
+	if len(r.HashedPassword) != 0 {
+		a.s.AuthStore().UpdatePasswordHash(r.Name, r.PreviousHashedPassword, r.HashedPassword)
+	}
 	resp, err := a.s.AuthStore().Authenticate(ctx, r.Name, r.Password)
 	if resp != nil {
 		resp.Header = newHeader(a.s)
@@ -911,6 +1389,21 @@ func (a *applierV3backend) RoleRevokePermission(r *pb.AuthRoleRevokePermissionRe
 	return resp, err
 }
 
+func (a *applierV3backend) SessionRevoke(r *pb.AuthSessionRevokeRequest) (*pb.AuthSessionRevokeResponse, error) {
+	resp, err := a.s.AuthStore().SessionRevoke(a.s.consistIndex.ConsistentIndex(), r)
+	if resp != nil {
+		resp.Header = newHeader(a.s)
+	}
+	return resp, err
+}
+
+// UserRoleExpire revokes the roles granted until the time of r, which is the
+// same on every member.
+func (a *applierV3backend) UserRoleExpire(r *pb.AuthUserRoleExpireRequest) (*pb.EmptyResponse, error) {
+	a.s.AuthStore().UserRoleExpire(r)
+	return &pb.EmptyResponse{}, nil
+}
+
 func (a *applierV3backend) RoleDelete(r *pb.AuthRoleDeleteRequest) (*pb.AuthRoleDeleteResponse, error) {
 	resp, err := a.s.AuthStore().RoleDelete(r)
 	if resp != nil {
@@ -956,8 +1449,11 @@ func (a *applierV3backend) ClusterMemberAttrSet(r *membershippb.ClusterMemberAtt
 	a.s.cluster.UpdateAttributes(
 		types.ID(r.Member_ID),
 		membership.Attributes{
-			Name:       r.MemberAttributes.Name,
-			ClientURLs: r.MemberAttributes.ClientUrls,
+			Name:             r.MemberAttributes.Name,
+			ClientURLs:       r.MemberAttributes.ClientUrls,
+			ElectionPriority: r.MemberAttributes.ElectionPriority,
+			Labels:           r.MemberAttributes.Labels,
+			WALDurability:    r.MemberAttributes.WalDurability,
 		},
 		shouldApplyV3,
 	)
@@ -969,18 +1465,29 @@ func (a *applierV3backend) DowngradeInfoSet(r *membershippb.DowngradeInfoSetRequ
 		d = version.DowngradeInfo{Enabled: true, TargetVersion: r.Ver}
 	}
 	a.s.cluster.SetDowngradeInfo(&d, shouldApplyV3)
+	if r.Enabled {
+		a.s.recordClusterEvent(pb.ClusterEvent_DOWNGRADE_ENABLE, 0, "target-version="+r.Ver, shouldApplyV3)
+	} else {
+		a.s.recordClusterEvent(pb.ClusterEvent_DOWNGRADE_DISABLE, 0, "", shouldApplyV3)
+	}
 }
 
 type quotaApplierV3 struct {
 	applierV3
 	q serverstorage.Quota
+	// pq are the quotas of the key prefixes, which unlike q are enforced
+	// before applying the request.
+	pq *prefixquota.Store
 }
 
 func newQuotaApplierV3(s *EtcdServer, app applierV3) applierV3 {
-	return &quotaApplierV3{app, serverstorage.NewBackendQuota(s.Cfg, s.Backend(), "v3-applier")}
+	return &quotaApplierV3{app, serverstorage.NewBackendQuota(s.Cfg, s.Backend(), "v3-applier"), s.prefixQuotas}
 }
 
 func (a *quotaApplierV3) Put(ctx context.Context, txn mvcc.TxnWrite, p *pb.PutRequest) (*pb.PutResponse, *traceutil.Trace, error) {
+	if !a.pq.Available(p) {
+		return nil, nil, ErrPrefixQuotaExceeded
+	}
 	ok := a.q.Available(p)
 	resp, trace, err := a.applierV3.Put(ctx, txn, p)
 	if err == nil && !ok {
@@ -990,6 +1497,9 @@ func (a *quotaApplierV3) Put(ctx context.Context, txn mvcc.TxnWrite, p *pb.PutRe
 }
 
 func (a *quotaApplierV3) Txn(ctx context.Context, rt *pb.TxnRequest) (*pb.TxnResponse, *traceutil.Trace, error) {
+	if !a.pq.Available(rt) {
+		return nil, nil, ErrPrefixQuotaExceeded
+	}
 	ok := a.q.Available(rt)
 	resp, trace, err := a.applierV3.Txn(ctx, rt)
 	if err == nil && !ok {
@@ -998,6 +1508,49 @@ func (a *quotaApplierV3) Txn(ctx context.Context, rt *pb.TxnRequest) (*pb.TxnRes
 	return resp, trace, err
 }
 
+// BulkWrite checks the quotas of the requests of r as the ones of a txn.
+func (a *quotaApplierV3) BulkWrite(ctx context.Context, r *pb.BulkWriteRequest) (*pb.BulkWriteResponse, *traceutil.Trace, error) {
+	rt := &pb.TxnRequest{Success: r.Ops}
+	if !a.pq.Available(rt) {
+		return nil, nil, ErrPrefixQuotaExceeded
+	}
+	ok := a.q.Available(rt)
+	resp, trace, err := a.applierV3.BulkWrite(ctx, r)
+	if err == nil && !ok {
+		err = ErrNoSpace
+	}
+	return resp, trace, err
+}
+
+func (a *quotaApplierV3) Increment(ctx context.Context, r *pb.IncrementRequest) (*pb.IncrementResponse, *traceutil.Trace, error) {
+	ok := a.q.Available(r)
+	resp, trace, err := a.applierV3.Increment(ctx, r)
+	if err == nil && !ok {
+		err = ErrNoSpace
+	}
+	return resp, trace, err
+}
+
+func (a *quotaApplierV3) PutIfAbsent(ctx context.Context, r *pb.PutIfAbsentRequest) (*pb.PutIfAbsentResponse, *traceutil.Trace, error) {
+	ok := a.q.Available(r)
+	resp, trace, err := a.applierV3.PutIfAbsent(ctx, r)
+	if err == nil && !ok {
+		err = ErrNoSpace
+	}
+	return resp, trace, err
+}
+
+// PutChunk charges the staged chunk against the backend quota. The prefix
+// quotas are checked by the put of the value.
+func (a *quotaApplierV3) PutChunk(r *pb.PutChunkRequest) (*pb.EmptyResponse, error) {
+	ok := a.q.Available(r)
+	resp, err := a.applierV3.PutChunk(r)
+	if err == nil && !ok {
+		err = ErrNoSpace
+	}
+	return resp, err
+}
+
 func (a *quotaApplierV3) LeaseGrant(lc *pb.LeaseGrantRequest) (*pb.LeaseGrantResponse, error) {
 	ok := a.q.Available(lc)
 	resp, err := a.applierV3.LeaseGrant(lc)
@@ -1007,6 +1560,15 @@ func (a *quotaApplierV3) LeaseGrant(lc *pb.LeaseGrantRequest) (*pb.LeaseGrantRes
 	return resp, err
 }
 
+func (a *quotaApplierV3) LeaseGrantBulk(lc *pb.LeaseGrantBulkRequest) (*pb.LeaseGrantBulkResponse, error) {
+	ok := a.q.Available(lc)
+	resp, err := a.applierV3.LeaseGrantBulk(lc)
+	if err == nil && !ok {
+		err = ErrNoSpace
+	}
+	return resp, err
+}
+
 type kvSort struct{ kvs []mvccpb.KeyValue }
 
 func (s *kvSort) Swap(i, j int) {
@@ -1046,6 +1608,50 @@ func (s *kvSortByValue) Less(i, j int) bool {
 	return bytes.Compare(s.kvs[i].Value, s.kvs[j].Value) < 0
 }
 
+type kvSortByLease struct{ *kvSort }
+
+func (s *kvSortByLease) Less(i, j int) bool {
+	return s.kvs[i].Lease < s.kvs[j].Lease
+}
+
+// sortKVs sorts the first limit key-value pairs of sorter, all of them if
+// limit is 0. The first ones are selected with a heap rather than sorting
+// the whole range, which is costly for large ranges and small limits.
+func sortKVs(sorter sort.Interface, limit int) {
+	n := sorter.Len()
+	if limit <= 0 || limit >= n {
+		sort.Sort(sorter)
+		return
+	}
+	first := firstKVs{sorter, limit}
+	h := kvMaxHeap{first}
+	heap.Init(h)
+	for i := limit; i < n; i++ {
+		if sorter.Less(i, 0) {
+			// smaller than the largest of the first ones
+			sorter.Swap(i, 0)
+			heap.Fix(h, 0)
+		}
+	}
+	sort.Sort(first)
+}
+
+// firstKVs restricts a sort.Interface to its first n elements.
+type firstKVs struct {
+	sort.Interface
+	n int
+}
+
+func (f firstKVs) Len() int { return f.n }
+
+// kvMaxHeap is a heap of the first key-value pairs of a sorter, the largest
+// at the root. Only heap.Init and heap.Fix are used.
+type kvMaxHeap struct{ firstKVs }
+
+func (h kvMaxHeap) Less(i, j int) bool { return h.firstKVs.Less(j, i) }
+func (h kvMaxHeap) Push(interface{})   { panic("unexpected push") }
+func (h kvMaxHeap) Pop() interface{}   { panic("unexpected pop") }
+
 func checkRequests(rv mvcc.ReadView, rt *pb.TxnRequest, txnPath []bool, f checkReqFunc) (int, error) {
 	txnCount := 0
 	reqs := rt.Success
@@ -1136,6 +1742,12 @@ func noSideEffect(r *pb.InternalRaftRequest) bool {
 	return r.Range != nil || r.AuthUserGet != nil || r.AuthRoleGet != nil || r.AuthStatus != nil
 }
 
+// isAppliedByWitness returns if a witness applies r, keeping the cluster
+// versions, the member attributes and the alarms but no KV data.
+func isAppliedByWitness(r *pb.InternalRaftRequest) bool {
+	return r.ClusterVersionSet != nil || r.ClusterMemberAttrSet != nil || r.DowngradeInfoSet != nil || r.Alarm != nil
+}
+
 func removeNeedlessRangeReqs(txn *pb.TxnRequest) {
 	f := func(ops []*pb.RequestOp) []*pb.RequestOp {
 		j := 0
@@ -18,6 +18,7 @@ import (
 	"context"
 	"sync"
 
+	"go.etcd.io/etcd/api/v3/authpb"
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/pkg/v3/traceutil"
 	"go.etcd.io/etcd/server/v3/auth"
@@ -30,6 +31,9 @@ type authApplierV3 struct {
 	applierV3
 	as     auth.AuthStore
 	lessor lease.Lessor
+	// maxLeasesPerUser is the maximum number of leases a user can hold, 0
+	// for no limit.
+	maxLeasesPerUser int
 
 	// mu serializes Apply so that user isn't corrupted and so that
 	// serialized requests don't leak data from TOCTOU errors
@@ -38,8 +42,8 @@ type authApplierV3 struct {
 	authInfo auth.AuthInfo
 }
 
-func newAuthApplierV3(as auth.AuthStore, base applierV3, lessor lease.Lessor) *authApplierV3 {
-	return &authApplierV3{applierV3: base, as: as, lessor: lessor}
+func newAuthApplierV3(as auth.AuthStore, base applierV3, lessor lease.Lessor, maxLeasesPerUser int) *authApplierV3 {
+	return &authApplierV3{applierV3: base, as: as, lessor: lessor, maxLeasesPerUser: maxLeasesPerUser}
 }
 
 func (aa *authApplierV3) Apply(r *pb.InternalRaftRequest, shouldApplyV3 membership.ShouldApplyV3) *applyResult {
@@ -50,17 +54,20 @@ func (aa *authApplierV3) Apply(r *pb.InternalRaftRequest, shouldApplyV3 membersh
 		// does not have header field
 		aa.authInfo.Username = r.Header.Username
 		aa.authInfo.Revision = r.Header.AuthRevision
+		aa.authInfo.Roles = r.Header.Roles
 	}
 	if needAdminPermission(r) {
-		if err := aa.as.IsAdminPermitted(&aa.authInfo); err != nil {
+		if err := aa.as.IsAdminOpPermitted(&aa.authInfo, authpb.AUTH); err != nil {
 			aa.authInfo.Username = ""
 			aa.authInfo.Revision = 0
+			aa.authInfo.Roles = nil
 			return &applyResult{err: err}
 		}
 	}
 	ret := aa.applierV3.Apply(r, shouldApplyV3)
 	aa.authInfo.Username = ""
 	aa.authInfo.Revision = 0
+	aa.authInfo.Roles = nil
 	return ret
 }
 
@@ -169,6 +176,137 @@ func (aa *authApplierV3) Txn(ctx context.Context, rt *pb.TxnRequest) (*pb.TxnRes
 	return aa.applierV3.Txn(ctx, rt)
 }
 
+// Move checks the keys moved can be read and the keys they are moved to can
+// be written, before the txn moving them is checked.
+func (aa *authApplierV3) Move(ctx context.Context, r *pb.MoveRequest) (*pb.MoveResponse, *traceutil.Trace, error) {
+	var end, dstEnd []byte
+	if r.Prefix {
+		end, dstEnd = prefixRangeEnd(r.Key), prefixRangeEnd(r.Destination)
+	}
+	if err := aa.as.IsRangePermitted(&aa.authInfo, r.Key, end); err != nil {
+		return nil, nil, err
+	}
+	if err := aa.as.IsDeleteRangePermitted(&aa.authInfo, r.Destination, dstEnd); err != nil {
+		return nil, nil, err
+	}
+	return aa.applierV3.Move(ctx, r)
+}
+
+// SetLease checks the keys whose lease is set can be written, and so can the
+// keys attached to the lease, as a put does.
+func (aa *authApplierV3) SetLease(ctx context.Context, r *pb.SetLeaseRequest) (*pb.SetLeaseResponse, *traceutil.Trace, error) {
+	if err := aa.as.IsDeleteRangePermitted(&aa.authInfo, r.Key, r.RangeEnd); err != nil {
+		return nil, nil, err
+	}
+	if err := aa.checkLeasePuts(lease.LeaseID(r.Lease)); err != nil {
+		return nil, nil, err
+	}
+	return aa.applierV3.SetLease(ctx, r)
+}
+
+// LeaseGrant records the user granting the lease as its owner, to count the
+// leases held by the user.
+func (aa *authApplierV3) LeaseGrant(lc *pb.LeaseGrantRequest) (*pb.LeaseGrantResponse, error) {
+	if err := aa.checkLeasesPerUser(1); err != nil {
+		return nil, err
+	}
+	resp, err := aa.applierV3.LeaseGrant(lc)
+	if err == nil && aa.authInfo.Username != "" {
+		err = aa.lessor.SetOwner(lease.LeaseID(resp.ID), aa.authInfo.Username)
+	}
+	return resp, err
+}
+
+func (aa *authApplierV3) LeaseGrantBulk(lc *pb.LeaseGrantBulkRequest) (*pb.LeaseGrantBulkResponse, error) {
+	if err := aa.checkLeasesPerUser(len(lc.Leases)); err != nil {
+		return nil, err
+	}
+	resp, err := aa.applierV3.LeaseGrantBulk(lc)
+	if err != nil || aa.authInfo.Username == "" {
+		return resp, err
+	}
+	for _, l := range resp.Leases {
+		if l.Error != "" {
+			continue
+		}
+		if err = aa.lessor.SetOwner(lease.LeaseID(l.ID), aa.authInfo.Username); err != nil {
+			return resp, err
+		}
+	}
+	return resp, nil
+}
+
+// checkLeasesPerUser returns ErrTooManyLeases if the user would hold more
+// than the maximum leases per user once granted n more leases. The leases
+// granted concurrently pass the check before the proposal, so that it is
+// only enforced here.
+func (aa *authApplierV3) checkLeasesPerUser(n int) error {
+	if aa.maxLeasesPerUser <= 0 || aa.authInfo.Username == "" {
+		return nil
+	}
+	if aa.lessor.OwnedLeases(aa.authInfo.Username)+n > aa.maxLeasesPerUser {
+		return ErrTooManyLeases
+	}
+	return nil
+}
+
+// Increment checks the key can be read, as its new value is returned, and
+// written, and so can the keys attached to the lease it may be created with.
+func (aa *authApplierV3) Increment(ctx context.Context, r *pb.IncrementRequest) (*pb.IncrementResponse, *traceutil.Trace, error) {
+	if err := aa.as.IsPutPermitted(&aa.authInfo, r.Key); err != nil {
+		return nil, nil, err
+	}
+	if err := aa.as.IsRangePermitted(&aa.authInfo, r.Key, nil); err != nil {
+		return nil, nil, err
+	}
+	if err := aa.checkLeasePuts(lease.LeaseID(r.Lease)); err != nil {
+		return nil, nil, err
+	}
+	return aa.applierV3.Increment(ctx, r)
+}
+
+// PutIfAbsent checks the key can be written, and read as it is returned if
+// it exists, and so can the keys attached to the lease it is put with.
+func (aa *authApplierV3) PutIfAbsent(ctx context.Context, r *pb.PutIfAbsentRequest) (*pb.PutIfAbsentResponse, *traceutil.Trace, error) {
+	if err := aa.as.IsPutPermitted(&aa.authInfo, r.Key); err != nil {
+		return nil, nil, err
+	}
+	if err := aa.as.IsRangePermitted(&aa.authInfo, r.Key, nil); err != nil {
+		return nil, nil, err
+	}
+	if err := aa.checkLeasePuts(lease.LeaseID(r.Lease)); err != nil {
+		return nil, nil, err
+	}
+	return aa.applierV3.PutIfAbsent(ctx, r)
+}
+
+// GetAndDelete checks the key can be deleted, and read as it is returned.
+func (aa *authApplierV3) GetAndDelete(ctx context.Context, r *pb.GetAndDeleteRequest) (*pb.GetAndDeleteResponse, *traceutil.Trace, error) {
+	if err := aa.as.IsDeleteRangePermitted(&aa.authInfo, r.Key, nil); err != nil {
+		return nil, nil, err
+	}
+	if err := aa.as.IsRangePermitted(&aa.authInfo, r.Key, nil); err != nil {
+		return nil, nil, err
+	}
+	return aa.applierV3.GetAndDelete(ctx, r)
+}
+
+// BulkWrite checks the requests of r as the ones of a txn, failing all of
+// them if any is denied.
+func (aa *authApplierV3) BulkWrite(ctx context.Context, r *pb.BulkWriteRequest) (*pb.BulkWriteResponse, *traceutil.Trace, error) {
+	if err := checkTxnReqsPermission(aa.as, &aa.authInfo, r.Ops); err != nil {
+		return nil, nil, err
+	}
+	for _, op := range r.Ops {
+		if put := op.GetRequestPut(); put != nil {
+			if err := aa.checkLeasePuts(lease.LeaseID(put.Lease)); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return aa.applierV3.BulkWrite(ctx, r)
+}
+
 func (aa *authApplierV3) LeaseRevoke(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevokeResponse, error) {
 	if err := aa.checkLeasePuts(lease.LeaseID(lc.ID)); err != nil {
 		return nil, err
@@ -176,6 +314,17 @@ func (aa *authApplierV3) LeaseRevoke(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevoke
 	return aa.applierV3.LeaseRevoke(lc)
 }
 
+// LeaseRevokeBulk checks the keys attached to all the leases can be written
+// before revoking any of them.
+func (aa *authApplierV3) LeaseRevokeBulk(lc *pb.LeaseRevokeBulkRequest) (*pb.LeaseRevokeBulkResponse, error) {
+	for _, id := range lc.IDs {
+		if err := aa.checkLeasePuts(lease.LeaseID(id)); err != nil {
+			return nil, err
+		}
+	}
+	return aa.applierV3.LeaseRevokeBulk(lc)
+}
+
 func (aa *authApplierV3) checkLeasePuts(leaseID lease.LeaseID) error {
 	lease := aa.lessor.Lookup(leaseID)
 	if lease != nil {
@@ -190,7 +339,7 @@ func (aa *authApplierV3) checkLeasePuts(leaseID lease.LeaseID) error {
 }
 
 func (aa *authApplierV3) UserGet(r *pb.AuthUserGetRequest) (*pb.AuthUserGetResponse, error) {
-	err := aa.as.IsAdminPermitted(&aa.authInfo)
+	err := aa.as.IsAdminOpPermitted(&aa.authInfo, authpb.AUTH)
 	if err != nil && r.Name != aa.authInfo.Username {
 		aa.authInfo.Username = ""
 		aa.authInfo.Revision = 0
@@ -201,7 +350,7 @@ func (aa *authApplierV3) UserGet(r *pb.AuthUserGetRequest) (*pb.AuthUserGetRespo
 }
 
 func (aa *authApplierV3) RoleGet(r *pb.AuthRoleGetRequest) (*pb.AuthRoleGetResponse, error) {
-	err := aa.as.IsAdminPermitted(&aa.authInfo)
+	err := aa.as.IsAdminOpPermitted(&aa.authInfo, authpb.AUTH)
 	if err != nil && !aa.as.HasRole(aa.authInfo.Username, r.Role) {
 		aa.authInfo.Username = ""
 		aa.authInfo.Revision = 0
@@ -241,6 +390,8 @@ func needAdminPermission(r *pb.InternalRaftRequest) bool {
 		return true
 	case r.AuthRoleList != nil:
 		return true
+	case r.AuthSessionRevoke != nil:
+		return true
 	default:
 		return false
 	}
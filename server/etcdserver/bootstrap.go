@@ -16,6 +16,7 @@ package etcdserver
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -197,7 +198,9 @@ func bootstrapSnapshot(cfg config.ServerConfig) *snap.Snapshotter {
 			zap.Error(err),
 		)
 	}
-	return snap.New(cfg.Logger, cfg.SnapDir())
+	ss := snap.New(cfg.Logger, cfg.SnapDir())
+	ss.SetKeyProvider(cfg.EncryptionKeyProvider)
+	return ss
 }
 
 func bootstrapBackend(cfg config.ServerConfig, haveWAL bool, st v2store.Store, ss *snap.Snapshotter) (backend *bootstrappedBackend, err error) {
@@ -210,6 +213,13 @@ func bootstrapBackend(cfg config.ServerConfig, haveWAL bool, st v2store.Store, s
 			be.Close()
 		}
 	}()
+	// Validate the storage version as found on disk before anything
+	// (defragmentation, snapshot recovery) gets a chance to modify it.
+	if beExist {
+		if err = validateStorageVersion(cfg, be); err != nil {
+			return nil, err
+		}
+	}
 	ci.SetBackend(be)
 	schema.CreateMetaBucket(be.BatchTx())
 	if cfg.ExperimentalBootstrapDefragThresholdMegabytes != 0 {
@@ -230,13 +240,6 @@ func bootstrapBackend(cfg config.ServerConfig, haveWAL bool, st v2store.Store, s
 			return nil, err
 		}
 	}
-	if beExist {
-		err = schema.Validate(cfg.Logger, be.ReadTx())
-		if err != nil {
-			cfg.Logger.Error("Failed to validate schema", zap.Error(err))
-			return nil, err
-		}
-	}
 
 	return &bootstrappedBackend{
 		beHooks:  beHooks,
@@ -247,6 +250,25 @@ func bootstrapBackend(cfg config.ServerConfig, haveWAL bool, st v2store.Store, s
 	}, nil
 }
 
+func validateStorageVersion(cfg config.ServerConfig, be backend.Backend) error {
+	err := schema.Validate(cfg.Logger, be.ReadTx())
+	if err == nil {
+		return nil
+	}
+	var verr *schema.VersionMismatchError
+	if errors.As(err, &verr) {
+		cfg.Logger.Error("Refusing to start, storage version is not supported by this etcd binary",
+			zap.String("data-dir", cfg.DataDir),
+			zap.String("storage-version", verr.StorageVersion.String()),
+			zap.String("binary-version", verr.BinaryVersion.String()),
+			zap.String("resolution", verr.Resolution()),
+		)
+	} else {
+		cfg.Logger.Error("Failed to validate schema", zap.Error(err))
+	}
+	return err
+}
+
 func maybeDefragBackend(cfg config.ServerConfig, be backend.Backend) error {
 	size := be.Size()
 	sizeInUse := be.SizeInUse()
@@ -295,6 +317,9 @@ func bootstrapExistingClusterNoWAL(cfg config.ServerConfig, prt http.RoundTrippe
 	if gerr != nil {
 		return nil, fmt.Errorf("cannot fetch cluster info from peer urls: %v", gerr)
 	}
+	if err := checkRemoteClusterIDs(cfg.Logger, existingCluster.ID(), getRemotePeerURLs(cl, cfg.Name), cfg.ReqTimeout(), prt); err != nil {
+		return nil, err
+	}
 	if err := membership.ValidateClusterAndAssignIDs(cfg.Logger, cl, existingCluster); err != nil {
 		return nil, fmt.Errorf("error validating peerURLs %s: %v", existingCluster, err)
 	}
@@ -521,16 +546,22 @@ func bootstrapRaftFromWAL(cfg config.ServerConfig, bwal *bootstrappedWAL) *boots
 }
 
 func raftConfig(cfg config.ServerConfig, id uint64, s *raft.MemoryStorage) *raft.Config {
+	maxInflightMsgs := cfg.MaxInflightMsgs
+	if maxInflightMsgs == 0 {
+		maxInflightMsgs = DefaultMaxInflightMsgs
+	}
 	return &raft.Config{
-		ID:              id,
-		ElectionTick:    cfg.ElectionTicks,
-		HeartbeatTick:   1,
-		Storage:         s,
-		MaxSizePerMsg:   maxSizePerMsg,
-		MaxInflightMsgs: maxInflightMsgs,
-		CheckQuorum:     true,
-		PreVote:         cfg.PreVote,
-		Logger:          NewRaftLoggerZap(cfg.Logger.Named("raft")),
+		ID:                   id,
+		ElectionTick:         cfg.ElectionTicks,
+		HeartbeatTick:        1,
+		Storage:              s,
+		MaxSizePerMsg:        MaxSizePerMsg,
+		MaxInflightMsgs:      maxInflightMsgs,
+		MaxInflightBytes:     cfg.MaxInflightBytes,
+		AdaptiveInflightMsgs: cfg.AdaptiveInflightMsgs,
+		CheckQuorum:          true,
+		PreVote:              cfg.PreVote,
+		Logger:               NewRaftLoggerZap(cfg.Logger.Named("raft")),
 	}
 }
 
@@ -608,6 +639,13 @@ func openWALFromSnapshot(cfg config.ServerConfig, snapshot *raftpb.Snapshot) (*w
 		if cfg.UnsafeNoFsync {
 			w.SetUnsafeNoFsync()
 		}
+		w.SetDurability(cfg.WALDurability, cfg.WALSyncInterval)
+		w.SetCompression(cfg.WALCompression)
+		w.SetKeyProvider(cfg.EncryptionKeyProvider)
+		w.SetIOURing(cfg.WALIOURing)
+		if err = w.SetSegmentOptions(walSegmentOptions(cfg)); err != nil {
+			cfg.Logger.Fatal("failed to set WAL segment options", zap.Error(err))
+		}
 		wmetadata, st, ents, err := w.ReadAll()
 		if err != nil {
 			w.Close()
@@ -632,6 +670,18 @@ func openWALFromSnapshot(cfg config.ServerConfig, snapshot *raftpb.Snapshot) (*w
 	}
 }
 
+func walSegmentOptions(cfg config.ServerConfig) wal.SegmentOptions {
+	opts := wal.SegmentOptions{
+		Size:         cfg.WALSegmentSize,
+		PreallocSize: cfg.WALPreallocSize,
+		TmpSegments:  cfg.WALTmpSegments,
+	}
+	if opts.Size == 0 {
+		opts.Size = wal.SegmentSizeBytes
+	}
+	return opts
+}
+
 type snapshotMetadata struct {
 	nodeID, clusterID types.ID
 }
@@ -650,6 +700,13 @@ func bootstrapNewWAL(cfg config.ServerConfig, cl *bootstrapedCluster) *bootstrap
 	if cfg.UnsafeNoFsync {
 		w.SetUnsafeNoFsync()
 	}
+	w.SetDurability(cfg.WALDurability, cfg.WALSyncInterval)
+	w.SetCompression(cfg.WALCompression)
+	w.SetKeyProvider(cfg.EncryptionKeyProvider)
+	w.SetIOURing(cfg.WALIOURing)
+	if err = w.SetSegmentOptions(walSegmentOptions(cfg)); err != nil {
+		cfg.Logger.Panic("failed to set WAL segment options", zap.Error(err))
+	}
 	return &bootstrappedWAL{
 		lg: cfg.Logger,
 		w:  w,
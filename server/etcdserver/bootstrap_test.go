@@ -19,6 +19,7 @@ package etcdserver
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -27,6 +28,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/coreos/go-semver/semver"
 	bolt "go.etcd.io/bbolt"
 	"go.etcd.io/etcd/server/v3/storage/datadir"
 	"go.etcd.io/etcd/server/v3/storage/schema"
@@ -171,6 +173,11 @@ func TestBootstrapBackend(t *testing.T) {
 			expectedConsistentIdx: 5,
 			expectedError:         nil,
 		},
+		{
+			name:          "bootstrap backend failure: storage version written by newer etcd",
+			prepareData:   prepareNewerStorageVersion,
+			expectedError: errors.New("downgrade it first by running `etcdutl migrate --target-version 3.6` from etcd v3.7"),
+		},
 		// TODO(ahrtr): add more test cases
 		// https://github.com/etcd-io/etcd/issues/13507
 	}
@@ -207,6 +214,9 @@ func TestBootstrapBackend(t *testing.T) {
 			if hasError && !strings.Contains(err.Error(), tt.expectedError.Error()) {
 				t.Fatalf("expected error to contain: %q, got: %q", tt.expectedError.Error(), err.Error())
 			}
+			if hasError {
+				return
+			}
 
 			if backend.ci.ConsistentIndex() != tt.expectedConsistentIdx {
 				t.Errorf("expected consistent index: %d, got: %d", tt.expectedConsistentIdx, backend.ci.ConsistentIndex())
@@ -241,6 +251,17 @@ func prepareData(cfg config.ServerConfig) (err error) {
 	return createSnapshotAndBackendDB(cfg, snapshotTerm, snapshotIndex)
 }
 
+// prepare a backend whose storage version is newer than the local binary
+func prepareNewerStorageVersion(cfg config.ServerConfig) error {
+	be := serverstorage.OpenBackend(cfg, nil)
+	tx := be.BatchTx()
+	tx.Lock()
+	schema.UnsafeCreateMetaBucket(tx)
+	schema.UnsafeSetStorageVersion(tx, &semver.Version{Major: 3, Minor: 7})
+	tx.Unlock()
+	return be.Close()
+}
+
 func createWALFileWithSnapshotRecord(cfg config.ServerConfig, snapshotTerm, snapshotIndex uint64) (err error) {
 	var w *wal.WAL
 	if w, err = wal.Create(cfg.Logger, cfg.WALDir(), []byte("somedata")); err != nil {
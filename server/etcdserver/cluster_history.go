@@ -0,0 +1,64 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
+)
+
+// recordClusterEvent records an event caused by the entry being applied,
+// unless the entry was applied to the backend before the member restarted.
+func (s *EtcdServer) recordClusterEvent(typ pb.ClusterEvent_EventType, id types.ID, detail string, shouldApplyV3 membership.ShouldApplyV3) {
+	if !shouldApplyV3 {
+		return
+	}
+	ev := &pb.ClusterEvent{
+		Type:     typ,
+		Time:     time.Now().UnixNano(),
+		MemberID: uint64(id),
+		Term:     s.getTerm(),
+		Detail:   detail,
+	}
+	if s.consistIndex != nil {
+		ev.Index, _ = s.consistIndex.ConsistentApplyingIndex()
+	}
+	s.history.Record(ev)
+}
+
+// recordLeaderChange records the election of lead in term, as observed by
+// the member. It does not block the raft loop on the backend.
+func (s *EtcdServer) recordLeaderChange(lead, term uint64) {
+	ev := &pb.ClusterEvent{
+		Type:     pb.ClusterEvent_LEADER_CHANGE,
+		Time:     time.Now().UnixNano(),
+		MemberID: lead,
+		Term:     term,
+	}
+	s.GoAttach(func() { s.history.RecordOutsideApply(ev) })
+}
+
+func memberEventDetail(peerURLs []string, isLearner bool) string {
+	detail := fmt.Sprintf("peer-urls=%s", strings.Join(peerURLs, ","))
+	if isLearner {
+		detail += " learner"
+	}
+	return detail
+}
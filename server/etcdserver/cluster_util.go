@@ -120,6 +120,42 @@ func getClusterFromRemotePeers(lg *zap.Logger, urls []string, timeout time.Durat
 	return nil, fmt.Errorf("could not retrieve cluster information from the given URLs")
 }
 
+// joinClusterIDMismatchCause is returned along with the cluster ID mismatches
+// found when joining a cluster to point operators at the usual root causes.
+const joinClusterIDMismatchCause = "the peers listed in --initial-cluster belong to different clusters; " +
+	"one of them was likely started with a stale data dir from a previous cluster, " +
+	"or a peer URL, --initial-cluster-token or discovery token was reused across clusters"
+
+// checkRemoteClusterIDs returns an error if a peer at the given URLs answers
+// for another cluster than cid, the one the joining member got the members
+// of. The peers that cannot be reached are skipped.
+func checkRemoteClusterIDs(lg *zap.Logger, cid types.ID, urls []string, timeout time.Duration, rt http.RoundTripper) error {
+	cc := &http.Client{
+		Transport: rt,
+		Timeout:   timeout,
+	}
+	for _, u := range urls {
+		resp, err := cc.Get(u + "/members")
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+		rcid, err := types.IDFromString(resp.Header.Get("X-Etcd-Cluster-ID"))
+		if err != nil || rcid == cid {
+			continue
+		}
+		lg.Error(
+			"refusing to join cluster, peers disagree on the cluster ID",
+			zap.String("cluster-id", cid.String()),
+			zap.String("remote-peer-url", u),
+			zap.String("remote-peer-cluster-id", rcid.String()),
+			zap.String("likely-cause", joinClusterIDMismatchCause),
+		)
+		return fmt.Errorf("cluster ID mismatch: peer %s belongs to cluster %s instead of %s: %s", u, rcid, cid, joinClusterIDMismatchCause)
+	}
+	return nil
+}
+
 // getRemotePeerURLs returns peer urls of remote members in the cluster. The
 // returned list is sorted in ascending lexicographical order.
 func getRemotePeerURLs(cl *membership.RaftCluster, local string) []string {
@@ -15,11 +15,17 @@
 package etcdserver
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"go.etcd.io/etcd/api/v3/version"
 	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/coreos/go-semver/semver"
 )
@@ -176,3 +182,42 @@ func TestDecideAllowedVersionRange(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckRemoteClusterIDs(t *testing.T) {
+	peer := func(cid string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Etcd-Cluster-ID", cid)
+			w.Write([]byte("[]"))
+		}))
+	}
+	a, b, stale := peer("a"), peer("a"), peer("b")
+	defer a.Close()
+	defer b.Close()
+	defer stale.Close()
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	lg := zaptest.NewLogger(t)
+	if err := checkRemoteClusterIDs(lg, types.ID(0xa), []string{a.URL, unreachable.URL, b.URL}, time.Second, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	core, logs := observer.New(zap.ErrorLevel)
+	err := checkRemoteClusterIDs(zap.New(core), types.ID(0xa), []string{a.URL, stale.URL, b.URL}, time.Second, nil)
+	if err == nil {
+		t.Fatal("expected the join to be refused")
+	}
+	for _, want := range []string{stale.URL, "cluster b instead of a", "stale data dir"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 error entry, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["remote-peer-url"] != stale.URL || fields["remote-peer-cluster-id"] != "b" || fields["cluster-id"] != "a" {
+		t.Errorf("unexpected fields %v", fields)
+	}
+}
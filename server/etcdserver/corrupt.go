@@ -37,7 +37,8 @@ import (
 // before serving any peer/client traffic. Only mismatch when hashes
 // are different at requested revision, with same compact revision.
 func (s *EtcdServer) CheckInitialHashKV() error {
-	if !s.Cfg.InitialCorruptCheck {
+	// a witness has no KV data to check
+	if !s.Cfg.InitialCorruptCheck || s.Cfg.Witness {
 		return nil
 	}
 
@@ -122,7 +123,7 @@ func (s *EtcdServer) CheckInitialHashKV() error {
 
 func (s *EtcdServer) monitorKVHash() {
 	t := s.Cfg.CorruptCheckTime
-	if t == 0 {
+	if t == 0 || s.Cfg.Witness {
 		return
 	}
 
@@ -313,6 +314,18 @@ func (a *applierV3Corrupt) Put(ctx context.Context, txn mvcc.TxnWrite, p *pb.Put
 	return nil, nil, ErrCorrupt
 }
 
+func (a *applierV3Corrupt) PutChunk(r *pb.PutChunkRequest) (*pb.EmptyResponse, error) {
+	return nil, ErrCorrupt
+}
+
+func (a *applierV3Corrupt) KeyExpire(r *pb.KeyExpireRequest) (*pb.EmptyResponse, error) {
+	return nil, ErrCorrupt
+}
+
+func (a *applierV3Corrupt) PutChunkExpire(r *pb.PutChunkExpireRequest) (*pb.EmptyResponse, error) {
+	return nil, ErrCorrupt
+}
+
 func (a *applierV3Corrupt) Range(ctx context.Context, txn mvcc.TxnRead, p *pb.RangeRequest) (*pb.RangeResponse, error) {
 	return nil, ErrCorrupt
 }
@@ -325,6 +338,10 @@ func (a *applierV3Corrupt) Txn(ctx context.Context, rt *pb.TxnRequest) (*pb.TxnR
 	return nil, nil, ErrCorrupt
 }
 
+func (a *applierV3Corrupt) BulkWrite(ctx context.Context, r *pb.BulkWriteRequest) (*pb.BulkWriteResponse, *traceutil.Trace, error) {
+	return nil, nil, ErrCorrupt
+}
+
 func (a *applierV3Corrupt) Compaction(compaction *pb.CompactionRequest) (*pb.CompactionResponse, <-chan struct{}, *traceutil.Trace, error) {
 	return nil, nil, nil, ErrCorrupt
 }
@@ -358,6 +375,10 @@ func (h *hashKVHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "bad path", http.StatusBadRequest)
 		return
 	}
+	if h.server.IsWitness() {
+		http.Error(w, "witness member keeps no KV data", http.StatusNotImplemented)
+		return
+	}
 
 	defer r.Body.Close()
 	b, err := io.ReadAll(r.Body)
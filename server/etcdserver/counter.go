@@ -0,0 +1,79 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+// maxCounterWidth is the number of digits of the largest int64, past which
+// padding a DECIMAL counter does not change its order.
+const maxCounterWidth = 19
+
+// parseCounter parses the value of a counter stored in format.
+func parseCounter(val []byte, format pb.IncrementRequest_Format) (int64, error) {
+	if format == pb.IncrementRequest_INT64_BIG_ENDIAN {
+		if len(val) != 8 {
+			return 0, ErrValueNotNumeric
+		}
+		return int64(binary.BigEndian.Uint64(val)), nil
+	}
+	v, err := strconv.ParseInt(string(val), 10, 64)
+	if err != nil {
+		return 0, ErrValueNotNumeric
+	}
+	return v, nil
+}
+
+// formatCounter formats the value of a counter in format, padding a
+// DECIMAL value with leading zeros up to width digits.
+func formatCounter(v int64, format pb.IncrementRequest_Format, width int64) []byte {
+	if format == pb.IncrementRequest_INT64_BIG_ENDIAN {
+		val := make([]byte, 8)
+		binary.BigEndian.PutUint64(val, uint64(v))
+		return val
+	}
+	val := strconv.AppendInt(nil, v, 10)
+	if width > maxCounterWidth {
+		width = maxCounterWidth
+	}
+	digits := len(val)
+	if v < 0 {
+		digits--
+	}
+	if pad := int(width) - digits; pad > 0 {
+		padded := make([]byte, 0, len(val)+pad)
+		if v < 0 {
+			padded, val = append(padded, '-'), val[1:]
+		}
+		for ; pad > 0; pad-- {
+			padded = append(padded, '0')
+		}
+		val = append(padded, val...)
+	}
+	return val
+}
+
+// addCounter returns v plus delta, or ErrIncrementOverflow if it overflows.
+func addCounter(v, delta int64) (int64, error) {
+	if (delta > 0 && v > math.MaxInt64-delta) || (delta < 0 && v < math.MinInt64-delta) {
+		return 0, ErrIncrementOverflow
+	}
+	return v + delta, nil
+}
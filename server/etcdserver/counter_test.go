@@ -0,0 +1,89 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+func TestFormatCounter(t *testing.T) {
+	tests := []struct {
+		v      int64
+		format pb.IncrementRequest_Format
+		width  int64
+
+		want []byte
+	}{
+		{v: 42, want: []byte("42")},
+		{v: 42, width: 5, want: []byte("00042")},
+		{v: -42, width: 5, want: []byte("-00042")},
+		{v: 123456, width: 3, want: []byte("123456")},
+		{v: 1, width: 100, want: []byte("0000000000000000001")},
+		{v: 258, format: pb.IncrementRequest_INT64_BIG_ENDIAN, want: []byte{0, 0, 0, 0, 0, 0, 1, 2}},
+		{v: -1, format: pb.IncrementRequest_INT64_BIG_ENDIAN, want: bytes.Repeat([]byte{0xff}, 8)},
+	}
+	for i, tt := range tests {
+		got := formatCounter(tt.v, tt.format, tt.width)
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("#%d: formatCounter = %q, want %q", i, got, tt.want)
+		}
+		v, err := parseCounter(got, tt.format)
+		if err != nil || v != tt.v {
+			t.Errorf("#%d: parseCounter = %d, %v, want %d", i, v, err, tt.v)
+		}
+	}
+}
+
+func TestParseCounterNotNumeric(t *testing.T) {
+	tests := []struct {
+		val    []byte
+		format pb.IncrementRequest_Format
+	}{
+		{val: []byte("abc")},
+		{val: []byte("")},
+		{val: []byte("99999999999999999999")},
+		{val: []byte("1234"), format: pb.IncrementRequest_INT64_BIG_ENDIAN},
+	}
+	for i, tt := range tests {
+		if _, err := parseCounter(tt.val, tt.format); err != ErrValueNotNumeric {
+			t.Errorf("#%d: err = %v, want %v", i, err, ErrValueNotNumeric)
+		}
+	}
+}
+
+func TestAddCounter(t *testing.T) {
+	tests := []struct {
+		v, delta int64
+
+		want    int64
+		wantErr error
+	}{
+		{v: 1, delta: 2, want: 3},
+		{v: 1, delta: -2, want: -1},
+		{v: math.MaxInt64 - 1, delta: 1, want: math.MaxInt64},
+		{v: math.MaxInt64, delta: 1, wantErr: ErrIncrementOverflow},
+		{v: math.MinInt64, delta: -1, wantErr: ErrIncrementOverflow},
+	}
+	for i, tt := range tests {
+		got, err := addCounter(tt.v, tt.delta)
+		if got != tt.want || err != tt.wantErr {
+			t.Errorf("#%d: addCounter = %d, %v, want %d, %v", i, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
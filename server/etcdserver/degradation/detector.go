@@ -0,0 +1,220 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package degradation detects the sustained degradation of the local member,
+// a slow disk or a starved host, so that a leader can hand its leadership
+// over instead of slowing down the writes of the whole cluster.
+package degradation
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultDuration is the default duration a signal must stay over or
+	// under its threshold for the member to be considered degraded or
+	// recovered.
+	DefaultDuration = 30 * time.Second
+
+	// SignalFsync, SignalCPUSteal and SignalMemoryPressure are the names of
+	// the signals of the degradation.
+	SignalFsync          = "fsync"
+	SignalCPUSteal       = "cpu_steal"
+	SignalMemoryPressure = "memory_pressure"
+)
+
+// Config configures a Detector.
+type Config struct {
+	// FsyncThreshold is the mean duration of the WAL fsyncs over a sample
+	// interval above which the disk is degraded. 0 disables the signal.
+	FsyncThreshold time.Duration
+	// CPUStealThreshold is the percentage of the CPU time stolen by the
+	// hypervisor above which the CPU is degraded. 0 disables the signal.
+	CPUStealThreshold float64
+	// MemoryPressureThreshold is the percentage of the time some tasks
+	// stalled on memory over the last 10 seconds, as reported by the Linux
+	// pressure stall information, above which the memory is degraded. 0
+	// disables the signal.
+	MemoryPressureThreshold float64
+	// Duration is the duration a signal must stay over its threshold for
+	// the member to be degraded, then under it to be recovered. Defaults to
+	// DefaultDuration.
+	Duration time.Duration
+}
+
+// Detector samples the signals of the degradation of the local member. A nil
+// Detector is valid and never detects a degradation, so callers do not need
+// to check whether the detection is enabled.
+type Detector struct {
+	lg  *zap.Logger
+	cfg Config
+
+	mu sync.Mutex
+	// fsyncTotal and fsyncs are the total duration and the number of the
+	// fsyncs observed since the last sample.
+	fsyncTotal time.Duration
+	fsyncs     int
+	// cpu is the CPU time read at the last sample, if read.
+	cpu    cpuTimes
+	cpuSet bool
+	// signals are the states of the enabled signals by name.
+	signals map[string]*signal
+
+	readCPU      func() (cpuTimes, error)
+	readPressure func() (float64, error)
+}
+
+// signal is the state of a signal, with hysteresis: it flips once the value
+// stays on the other side of the threshold for the duration.
+type signal struct {
+	degraded bool
+	// since is the time the value crossed the threshold the last, zero if
+	// on the side of the current state.
+	since time.Time
+}
+
+// New returns a Detector, nil if no signal is enabled.
+func New(lg *zap.Logger, cfg Config) *Detector {
+	if cfg.FsyncThreshold <= 0 && cfg.CPUStealThreshold <= 0 && cfg.MemoryPressureThreshold <= 0 {
+		return nil
+	}
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	if cfg.Duration <= 0 {
+		cfg.Duration = DefaultDuration
+	}
+	d := &Detector{
+		lg:           lg,
+		cfg:          cfg,
+		signals:      make(map[string]*signal),
+		readCPU:      readCPUTimes,
+		readPressure: readMemoryPressure,
+	}
+	if cfg.FsyncThreshold > 0 {
+		d.signals[SignalFsync] = &signal{}
+	}
+	if cfg.CPUStealThreshold > 0 {
+		d.signals[SignalCPUSteal] = &signal{}
+	}
+	if cfg.MemoryPressureThreshold > 0 {
+		d.signals[SignalMemoryPressure] = &signal{}
+	}
+	for name := range d.signals {
+		degraded.WithLabelValues(name).Set(0)
+	}
+	return d
+}
+
+// Duration returns the duration a signal must stay over its threshold for
+// the member to be degraded, 0 if d is nil.
+func (d *Detector) Duration() time.Duration {
+	if d == nil {
+		return 0
+	}
+	return d.cfg.Duration
+}
+
+// ObserveFsync observes the time taken by an fsync of the WAL.
+func (d *Detector) ObserveFsync(took time.Duration) {
+	if d == nil || d.cfg.FsyncThreshold <= 0 {
+		return
+	}
+	d.mu.Lock()
+	d.fsyncTotal += took
+	d.fsyncs++
+	d.mu.Unlock()
+}
+
+// Sample reads the signals, and updates their state at the given time. The
+// signals not read, no fsync being observed since the last sample or the
+// host not reporting them, keep their state.
+func (d *Detector) Sample(now time.Time) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if s, ok := d.signals[SignalFsync]; ok && d.fsyncs > 0 {
+		mean := d.fsyncTotal / time.Duration(d.fsyncs)
+		d.update(SignalFsync, s, mean > d.cfg.FsyncThreshold, now)
+		d.fsyncTotal, d.fsyncs = 0, 0
+	}
+	if s, ok := d.signals[SignalCPUSteal]; ok {
+		if cpu, err := d.readCPU(); err == nil {
+			if d.cpuSet {
+				if steal, ok := cpu.stealPercent(d.cpu); ok {
+					d.update(SignalCPUSteal, s, steal > d.cfg.CPUStealThreshold, now)
+				}
+			}
+			d.cpu, d.cpuSet = cpu, true
+		}
+	}
+	if s, ok := d.signals[SignalMemoryPressure]; ok {
+		if pressure, err := d.readPressure(); err == nil {
+			d.update(SignalMemoryPressure, s, pressure > d.cfg.MemoryPressureThreshold, now)
+		}
+	}
+}
+
+func (d *Detector) update(name string, s *signal, over bool, now time.Time) {
+	if over == s.degraded {
+		s.since = time.Time{}
+		return
+	}
+	if s.since.IsZero() {
+		s.since = now
+	}
+	if now.Sub(s.since) < d.cfg.Duration {
+		return
+	}
+	s.degraded, s.since = over, time.Time{}
+	if over {
+		d.lg.Warn("local member degraded", zap.String("signal", name), zap.Duration("duration", d.cfg.Duration))
+		degraded.WithLabelValues(name).Set(1)
+	} else {
+		d.lg.Info("local member recovered", zap.String("signal", name), zap.Duration("duration", d.cfg.Duration))
+		degraded.WithLabelValues(name).Set(0)
+	}
+}
+
+// Degraded returns the sorted names of the signals degraded.
+func (d *Detector) Degraded() []string {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var names []string
+	for name, s := range d.signals {
+		if s.degraded {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ObserveTransfer counts a leadership transfer because of the degraded
+// signals.
+func (d *Detector) ObserveTransfer(signals []string) {
+	for _, name := range signals {
+		transfers.WithLabelValues(name).Inc()
+	}
+}
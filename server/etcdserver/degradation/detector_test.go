@@ -0,0 +1,140 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package degradation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestDetectorDisabled(t *testing.T) {
+	d := New(zaptest.NewLogger(t), Config{Duration: time.Second})
+	if d != nil {
+		t.Fatalf("expected no detector without signal, got %+v", d)
+	}
+	// a nil detector is valid
+	d.ObserveFsync(time.Second)
+	d.Sample(time.Now())
+	if signals := d.Degraded(); signals != nil {
+		t.Errorf("expected nil detector not to be degraded, got %v", signals)
+	}
+}
+
+func TestDetectorFsyncHysteresis(t *testing.T) {
+	d := New(zaptest.NewLogger(t), Config{FsyncThreshold: 100 * time.Millisecond, Duration: 3 * time.Second})
+	now := time.Now()
+	sample := func(took time.Duration) []string {
+		now = now.Add(time.Second)
+		if took > 0 {
+			d.ObserveFsync(took)
+			d.ObserveFsync(took)
+		}
+		d.Sample(now)
+		return d.Degraded()
+	}
+
+	// degraded once over the threshold for the duration
+	for i := 0; i < 3; i++ {
+		if signals := sample(200 * time.Millisecond); signals != nil {
+			t.Fatalf("#%d: expected not degraded before the duration, got %v", i, signals)
+		}
+	}
+	// a sample under the threshold resets the duration
+	sample(10 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		sample(200 * time.Millisecond)
+	}
+	if signals := sample(200 * time.Millisecond); !reflect.DeepEqual(signals, []string{SignalFsync}) {
+		t.Fatalf("expected fsync degraded, got %v", signals)
+	}
+
+	// no fsync observed keeps the state
+	if signals := sample(0); !reflect.DeepEqual(signals, []string{SignalFsync}) {
+		t.Fatalf("expected fsync still degraded without fsync, got %v", signals)
+	}
+	// recovered once under the threshold for the duration
+	for i := 0; i < 3; i++ {
+		if signals := sample(10 * time.Millisecond); signals == nil {
+			t.Fatalf("#%d: expected still degraded before the duration", i)
+		}
+	}
+	if signals := sample(10 * time.Millisecond); signals != nil {
+		t.Fatalf("expected recovered, got %v", signals)
+	}
+}
+
+func TestDetectorHostSignals(t *testing.T) {
+	d := New(zaptest.NewLogger(t), Config{CPUStealThreshold: 20, MemoryPressureThreshold: 50, Duration: time.Second})
+	cpu := cpuTimes{}
+	d.readCPU = func() (cpuTimes, error) {
+		// 30% stolen
+		cpu.total += 100
+		cpu.steal += 30
+		return cpu, nil
+	}
+	d.readPressure = func() (float64, error) { return 0, errors.New("not supported") }
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		now = now.Add(time.Second)
+		d.Sample(now)
+	}
+	// the pressure not read keeps its state
+	if signals := d.Degraded(); !reflect.DeepEqual(signals, []string{SignalCPUSteal}) {
+		t.Fatalf("expected cpu steal degraded, got %v", signals)
+	}
+
+	d.readPressure = func() (float64, error) { return 75, nil }
+	for i := 0; i < 2; i++ {
+		now = now.Add(time.Second)
+		d.Sample(now)
+	}
+	if signals := d.Degraded(); !reflect.DeepEqual(signals, []string{SignalCPUSteal, SignalMemoryPressure}) {
+		t.Fatalf("expected cpu steal and memory pressure degraded, got %v", signals)
+	}
+}
+
+func TestParseCPUTimes(t *testing.T) {
+	c, err := parseCPUTimes([]byte("cpu  10 1 5 80 2 0 1 3 4 0\ncpu0 5 0 2 40 1 0 0 1 2 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (cpuTimes{total: 102, steal: 3}); c != want {
+		t.Errorf("cpu times = %+v, want %+v", c, want)
+	}
+	if steal, ok := (cpuTimes{total: 202, steal: 13}).stealPercent(c); !ok || steal != 10 {
+		t.Errorf("steal = %v, %v, want 10, true", steal, ok)
+	}
+	if _, err = parseCPUTimes([]byte("intr 1 2 3")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestParseMemoryPressure(t *testing.T) {
+	p, err := parseMemoryPressure([]byte("some avg10=12.50 avg60=3.00 avg300=1.00 total=12345\nfull avg10=1.00 avg60=0.00 avg300=0.00 total=123\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != 12.5 {
+		t.Errorf("pressure = %v, want 12.5", p)
+	}
+	if _, err = parseMemoryPressure([]byte("full avg10=1.00\n")); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package degradation
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	degraded = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "local_degraded",
+		Help:      "Whether the local member is degraded (1) or not (0), by signal of the degradation.",
+	},
+		[]string{"signal"},
+	)
+
+	transfers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "degraded_leader_transfers_total",
+		Help:      "The total number of leadership transfers of the local member because of its degradation, by signal of the degradation.",
+	},
+		[]string{"signal"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(degraded)
+	prometheus.MustRegister(transfers)
+}
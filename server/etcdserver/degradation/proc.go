@@ -0,0 +1,103 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package degradation
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	procStatPath           = "/proc/stat"
+	procMemoryPressurePath = "/proc/pressure/memory"
+)
+
+// cpuTimes are the total and stolen CPU times of the host, in ticks.
+type cpuTimes struct {
+	total, steal uint64
+}
+
+// stealPercent returns the percentage of the CPU time stolen since prev,
+// false if no time elapsed.
+func (c cpuTimes) stealPercent(prev cpuTimes) (float64, bool) {
+	if c.total <= prev.total || c.steal < prev.steal {
+		return 0, false
+	}
+	return float64(c.steal-prev.steal) * 100 / float64(c.total-prev.total), true
+}
+
+// readCPUTimes reads the CPU times of the host from the "cpu" line of
+// /proc/stat: user, nice, system, idle, iowait, irq, softirq and steal, the
+// guest times being accounted in the user ones.
+func readCPUTimes() (cpuTimes, error) {
+	b, err := os.ReadFile(procStatPath)
+	if err != nil {
+		return cpuTimes{}, err
+	}
+	return parseCPUTimes(b)
+}
+
+func parseCPUTimes(b []byte) (cpuTimes, error) {
+	line := b
+	if i := bytes.IndexByte(b, '\n'); i >= 0 {
+		line = b[:i]
+	}
+	fields := strings.Fields(string(line))
+	if len(fields) < 9 || fields[0] != "cpu" {
+		return cpuTimes{}, fmt.Errorf("unexpected %s line %q", procStatPath, line)
+	}
+	var c cpuTimes
+	for i, f := range fields[1:9] {
+		v, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return cpuTimes{}, fmt.Errorf("unexpected %s line %q (%v)", procStatPath, line, err)
+		}
+		c.total += v
+		if i == 7 {
+			c.steal = v
+		}
+	}
+	return c, nil
+}
+
+// readMemoryPressure reads the percentage of the time some tasks stalled on
+// memory over the last 10 seconds from /proc/pressure/memory.
+func readMemoryPressure() (float64, error) {
+	b, err := os.ReadFile(procMemoryPressurePath)
+	if err != nil {
+		return 0, err
+	}
+	return parseMemoryPressure(b)
+}
+
+func parseMemoryPressure(b []byte) (float64, error) {
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 || fields[0] != "some" {
+			continue
+		}
+		for _, f := range fields[1:] {
+			if v := strings.TrimPrefix(f, "avg10="); v != f {
+				return strconv.ParseFloat(v, 64)
+			}
+		}
+	}
+	return 0, fmt.Errorf("no some avg10 in %s", procMemoryPressurePath)
+}
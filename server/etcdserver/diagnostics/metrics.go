@@ -0,0 +1,42 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnostics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	captures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd_debugging",
+		Subsystem: "server",
+		Name:      "profile_captures_total",
+		Help:      "The total number of profile captures triggered by a latency threshold.",
+	},
+		[]string{"trigger"},
+	)
+
+	capturesSkipped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd_debugging",
+		Subsystem: "server",
+		Name:      "profile_captures_skipped_total",
+		Help:      "The total number of latencies over a profiling threshold that did not trigger a capture because of the rate limit.",
+	},
+		[]string{"trigger"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(captures)
+	prometheus.MustRegister(capturesSkipped)
+}
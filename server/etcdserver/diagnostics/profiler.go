@@ -0,0 +1,271 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diagnostics captures profiles of the server when its latency
+// crosses configured thresholds, so that transient stalls can be analyzed
+// after the fact.
+package diagnostics
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"time"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultMinInterval is the default minimum time between two captures.
+	DefaultMinInterval = 10 * time.Minute
+	// DefaultCPUDuration is the default duration of the CPU profile.
+	DefaultCPUDuration = 10 * time.Second
+	// DefaultMaxCaptures is the default number of captures kept in the
+	// profile directory.
+	DefaultMaxCaptures = 10
+
+	// TriggerApply and TriggerFsync are the names of the latencies that
+	// trigger captures.
+	TriggerApply = "apply"
+	TriggerFsync = "fsync"
+
+	uploadTimeout = 30 * time.Second
+)
+
+// Config configures a Profiler.
+type Config struct {
+	// ApplyThreshold triggers a capture when applying a request takes
+	// longer. 0 disables the trigger.
+	ApplyThreshold time.Duration
+	// FsyncThreshold triggers a capture when an fsync of the WAL takes
+	// longer. 0 disables the trigger.
+	FsyncThreshold time.Duration
+	// MinInterval is the minimum time between two captures. Defaults to
+	// DefaultMinInterval.
+	MinInterval time.Duration
+	// CPUDuration is the duration of the CPU profile. Defaults to
+	// DefaultCPUDuration.
+	CPUDuration time.Duration
+	// Dir is the directory the captures are written to, one sub-directory
+	// per capture, if URL is empty.
+	Dir string
+	// MaxCaptures is the number of captures kept in Dir; the oldest are
+	// removed. Defaults to DefaultMaxCaptures.
+	MaxCaptures int
+	// URL is the HTTP endpoint each profile is posted to instead of being
+	// written to Dir, with the capture and profile names in the "capture"
+	// and "profile" query parameters.
+	URL string
+}
+
+// Profiler captures goroutine, heap and CPU profiles when the observed
+// latencies cross their thresholds, at most once per MinInterval. A nil
+// Profiler is valid and ignores the observations.
+type Profiler struct {
+	lg     *zap.Logger
+	cfg    Config
+	client *http.Client
+
+	mu        sync.Mutex
+	last      time.Time
+	capturing bool
+	stopped   bool
+	stopc     chan struct{}
+	wg        sync.WaitGroup
+}
+
+// New returns a Profiler, or nil if both thresholds are 0.
+func New(lg *zap.Logger, cfg Config) *Profiler {
+	if cfg.ApplyThreshold <= 0 && cfg.FsyncThreshold <= 0 {
+		return nil
+	}
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	if cfg.MinInterval <= 0 {
+		cfg.MinInterval = DefaultMinInterval
+	}
+	if cfg.CPUDuration <= 0 {
+		cfg.CPUDuration = DefaultCPUDuration
+	}
+	if cfg.MaxCaptures <= 0 {
+		cfg.MaxCaptures = DefaultMaxCaptures
+	}
+	return &Profiler{
+		lg:     lg,
+		cfg:    cfg,
+		client: &http.Client{Timeout: uploadTimeout},
+		stopc:  make(chan struct{}),
+	}
+}
+
+// ObserveApply observes the time taken to apply a request.
+func (p *Profiler) ObserveApply(took time.Duration) {
+	if p != nil && p.cfg.ApplyThreshold > 0 && took >= p.cfg.ApplyThreshold {
+		p.trigger(TriggerApply, took, p.cfg.ApplyThreshold)
+	}
+}
+
+// ObserveFsync observes the time taken by an fsync of the WAL.
+func (p *Profiler) ObserveFsync(took time.Duration) {
+	if p != nil && p.cfg.FsyncThreshold > 0 && took >= p.cfg.FsyncThreshold {
+		p.trigger(TriggerFsync, took, p.cfg.FsyncThreshold)
+	}
+}
+
+// Stop aborts the in-flight capture, if any, and waits for it to finish.
+func (p *Profiler) Stop() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	if !p.stopped {
+		p.stopped = true
+		close(p.stopc)
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+func (p *Profiler) trigger(reason string, took, threshold time.Duration) {
+	now := time.Now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped || p.capturing || (!p.last.IsZero() && now.Sub(p.last) < p.cfg.MinInterval) {
+		capturesSkipped.WithLabelValues(reason).Inc()
+		return
+	}
+	p.capturing = true
+	p.last = now
+
+	p.lg.Warn(
+		"latency crossed profiling threshold; capturing profiles",
+		zap.String("trigger", reason),
+		zap.Duration("took", took),
+		zap.Duration("threshold", threshold),
+	)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.capture(now, reason)
+		p.mu.Lock()
+		p.capturing = false
+		p.mu.Unlock()
+	}()
+}
+
+// capture takes the goroutine and heap profiles first, to record the state
+// of the server during the stall, and then profiles the CPU.
+func (p *Profiler) capture(now time.Time, reason string) {
+	name := now.UTC().Format("20060102T150405Z") + "-" + reason
+	profiles := make(map[string][]byte)
+	for _, prof := range []string{"goroutine", "heap"} {
+		var buf bytes.Buffer
+		if err := pprof.Lookup(prof).WriteTo(&buf, 0); err != nil {
+			p.lg.Warn("failed to capture profile", zap.String("profile", prof), zap.Error(err))
+			continue
+		}
+		profiles[prof] = buf.Bytes()
+	}
+
+	var buf bytes.Buffer
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		// the CPU may already be profiled through the pprof HTTP handlers
+		p.lg.Warn("failed to capture profile", zap.String("profile", "cpu"), zap.Error(err))
+	} else {
+		select {
+		case <-time.After(p.cfg.CPUDuration):
+		case <-p.stopc:
+		}
+		pprof.StopCPUProfile()
+		profiles["cpu"] = buf.Bytes()
+	}
+
+	var (
+		location string
+		err      error
+	)
+	if p.cfg.URL != "" {
+		location, err = p.cfg.URL, p.upload(name, profiles)
+	} else {
+		location = filepath.Join(p.cfg.Dir, name)
+		err = p.save(location, profiles)
+	}
+	if err != nil {
+		p.lg.Warn("failed to store profiles", zap.String("capture", name), zap.String("location", location), zap.Error(err))
+		return
+	}
+	captures.WithLabelValues(reason).Inc()
+	p.lg.Info("captured profiles", zap.String("capture", name), zap.String("location", location), zap.Int("profiles", len(profiles)))
+}
+
+func (p *Profiler) save(dir string, profiles map[string][]byte) error {
+	if err := fileutil.TouchDirAll(p.lg, dir); err != nil {
+		return err
+	}
+	for prof, data := range profiles {
+		if err := os.WriteFile(filepath.Join(dir, prof+".pb.gz"), data, fileutil.PrivateFileMode); err != nil {
+			return err
+		}
+	}
+	return p.purge()
+}
+
+// purge removes the oldest captures beyond MaxCaptures. The names of the
+// captures start with their time, so they sort by age.
+func (p *Profiler) purge() error {
+	names, err := fileutil.ReadDir(p.cfg.Dir)
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+	for len(names) > p.cfg.MaxCaptures {
+		if err := os.RemoveAll(filepath.Join(p.cfg.Dir, names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+func (p *Profiler) upload(name string, profiles map[string][]byte) error {
+	for prof, data := range profiles {
+		u, err := url.Parse(p.cfg.URL)
+		if err != nil {
+			return err
+		}
+		q := u.Query()
+		q.Set("capture", name)
+		q.Set("profile", prof)
+		u.RawQuery = q.Encode()
+
+		resp, err := p.client.Post(u.String(), "application/octet-stream", bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("unexpected status %q uploading %s profile", resp.Status, prof)
+		}
+	}
+	return nil
+}
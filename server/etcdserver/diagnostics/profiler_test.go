@@ -0,0 +1,150 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnostics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestProfilerDisabled(t *testing.T) {
+	p := New(zaptest.NewLogger(t), Config{Dir: t.TempDir()})
+	if p != nil {
+		t.Fatalf("expected nil profiler, got %+v", p)
+	}
+	p.ObserveApply(time.Hour)
+	p.ObserveFsync(time.Hour)
+	p.Stop()
+}
+
+func TestProfilerCapture(t *testing.T) {
+	dir := t.TempDir()
+	p := New(zaptest.NewLogger(t), Config{
+		ApplyThreshold: 100 * time.Millisecond,
+		CPUDuration:    time.Hour,
+		Dir:            dir,
+	})
+
+	p.ObserveApply(10 * time.Millisecond)
+	p.ObserveFsync(time.Hour)
+	p.ObserveApply(time.Second)
+	// rate limited while the first capture is still profiling the CPU
+	p.ObserveApply(time.Second)
+	// aborts the CPU profile
+	p.Stop()
+
+	names, err := fileutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || !strings.HasSuffix(names[0], "-"+TriggerApply) {
+		t.Fatalf("expected 1 apply capture, got %v", names)
+	}
+	profiles, err := fileutil.ReadDir(filepath.Join(dir, names[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"cpu.pb.gz", "goroutine.pb.gz", "heap.pb.gz"}
+	if !reflect.DeepEqual(profiles, want) {
+		t.Errorf("expected profiles %v, got %v", want, profiles)
+	}
+
+	// no capture after stop
+	p.ObserveApply(time.Second)
+	p.Stop()
+	if names, _ = fileutil.ReadDir(dir); len(names) != 1 {
+		t.Errorf("expected 1 capture after stop, got %v", names)
+	}
+}
+
+func TestProfilerPurge(t *testing.T) {
+	dir := t.TempDir()
+	p := New(zaptest.NewLogger(t), Config{FsyncThreshold: time.Second, Dir: dir, MaxCaptures: 2})
+	for _, name := range []string{"20220101T000002Z-fsync", "20220101T000000Z-apply", "20220101T000001Z-fsync"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.purge(); err != nil {
+		t.Fatal(err)
+	}
+	names, err := fileutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"20220101T000001Z-fsync", "20220101T000002Z-fsync"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("expected captures %v, got %v", want, names)
+	}
+}
+
+func TestProfilerUpload(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		profiles []string
+		captures = make(map[string]struct{})
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		profiles = append(profiles, r.URL.Query().Get("profile"))
+		captures[r.URL.Query().Get("capture")] = struct{}{}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	p := New(zaptest.NewLogger(t), Config{
+		FsyncThreshold: time.Second,
+		CPUDuration:    10 * time.Millisecond,
+		Dir:            dir,
+		URL:            srv.URL + "/profiles",
+	})
+	p.ObserveFsync(2 * time.Second)
+	p.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	sort.Strings(profiles)
+	if want := []string{"cpu", "goroutine", "heap"}; !reflect.DeepEqual(profiles, want) {
+		t.Errorf("expected uploaded profiles %v, got %v", want, profiles)
+	}
+	if len(captures) != 1 {
+		t.Errorf("expected profiles of 1 capture, got %v", captures)
+	}
+	for c := range captures {
+		if !strings.HasSuffix(c, "-"+TriggerFsync) {
+			t.Errorf("expected fsync capture, got %q", c)
+		}
+	}
+	if names, _ := fileutil.ReadDir(dir); len(names) != 0 {
+		t.Errorf("expected no capture in the directory, got %v", names)
+	}
+}
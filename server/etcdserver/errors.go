@@ -35,11 +35,18 @@ var (
 	ErrNotLeader                   = errors.New("etcdserver: not leader")
 	ErrRequestTooLarge             = errors.New("etcdserver: request is too large")
 	ErrNoSpace                     = errors.New("etcdserver: no space")
+	ErrPrefixQuotaExceeded         = errors.New("etcdserver: prefix quota exceeded")
+	ErrPutChunksMissing            = errors.New("etcdserver: chunks of the put value are missing")
 	ErrTooManyRequests             = errors.New("etcdserver: too many requests")
+	ErrTooManyLeases               = errors.New("etcdserver: too many leases held by the user")
 	ErrUnhealthy                   = errors.New("etcdserver: unhealthy cluster")
 	ErrKeyNotFound                 = errors.New("etcdserver: key not found")
+	ErrKeyExists                   = errors.New("etcdserver: key already exists")
+	ErrValueNotNumeric             = errors.New("etcdserver: value is not numeric")
+	ErrIncrementOverflow           = errors.New("etcdserver: increment overflows the value")
 	ErrCorrupt                     = errors.New("etcdserver: corrupt cluster")
 	ErrBadLeaderTransferee         = errors.New("etcdserver: bad leader transferee")
+	ErrNotSupportedByCluster       = errors.New("etcdserver: rpc not supported by the cluster version")
 	ErrClusterVersionUnavailable   = errors.New("etcdserver: cluster version not found during downgrade")
 	ErrWrongDowngradeVersionFormat = errors.New("etcdserver: wrong downgrade target version format")
 )
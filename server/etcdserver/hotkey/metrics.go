@@ -0,0 +1,67 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hotkey
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsTopN is the number of prefixes reported to Prometheus, which bounds
+// the cardinality of the prefix label.
+const metricsTopN = 10
+
+var (
+	hotKeyRequestsDesc = prometheus.NewDesc(
+		"etcd_debugging_server_hot_key_prefix_requests",
+		"Estimated number of requests to the most accessed key prefixes over the sampling window.",
+		[]string{"prefix", "type"}, nil)
+
+	// overridden by sampler initialization
+	reportTopMu sync.RWMutex
+	reportTop   = func(n int) []KeyStats { return nil }
+)
+
+func init() {
+	prometheus.MustRegister(topCollector{})
+}
+
+// topCollector reports the metricsTopN most accessed prefixes. The other
+// prefixes are returned by Sampler.Top.
+type topCollector struct{}
+
+func (topCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- hotKeyRequestsDesc
+}
+
+func (topCollector) Collect(ch chan<- prometheus.Metric) {
+	reportTopMu.RLock()
+	stats := reportTop(metricsTopN)
+	reportTopMu.RUnlock()
+
+	seen := make(map[string]struct{}, len(stats))
+	for _, st := range stats {
+		// label values must be valid UTF-8, and unique once sanitized
+		prefix := strings.ToValidUTF8(string(st.Prefix), "\uFFFD")
+		if _, ok := seen[prefix]; ok {
+			continue
+		}
+		seen[prefix] = struct{}{}
+		ch <- prometheus.MustNewConstMetric(hotKeyRequestsDesc, prometheus.GaugeValue, float64(st.Reads), prefix, "read")
+		ch <- prometheus.MustNewConstMetric(hotKeyRequestsDesc, prometheus.GaugeValue, float64(st.Writes), prefix, "write")
+	}
+}
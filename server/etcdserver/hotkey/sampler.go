@@ -0,0 +1,258 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hotkey samples the key accesses of client requests to find the
+// most accessed key prefixes.
+package hotkey
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWindow is the default duration of the sliding window the
+	// accesses are counted over.
+	DefaultWindow = time.Minute
+
+	// DefaultCapacity is the default maximum number of prefixes tracked in
+	// each bucket of the window.
+	DefaultCapacity = 1024
+
+	// buckets is the number of buckets the window is split into; the window
+	// slides by a bucket at a time.
+	buckets = 6
+
+	// maxPrefixLen bounds the memory used by each tracked prefix.
+	maxPrefixLen = 256
+)
+
+// Op is the type of a key access.
+type Op int
+
+const (
+	Read Op = iota
+	Write
+)
+
+// KeyStats is the estimated number of accesses to the keys with a prefix
+// over the sampling window. The counts of a prefix tracked after another one
+// was evicted to stay under capacity include the counts of the evicted
+// prefix, so they are upper bounds.
+type KeyStats struct {
+	Prefix []byte
+	Reads  int64
+	Writes int64
+}
+
+// Config configures a Sampler.
+type Config struct {
+	// SampleRate is the fraction of the accesses sampled, in (0, 1].
+	SampleRate float64
+	// Window is the duration of the sliding window, at least a second.
+	// Defaults to DefaultWindow.
+	Window time.Duration
+	// Capacity is the maximum number of prefixes tracked in each bucket of
+	// the window. Defaults to DefaultCapacity.
+	Capacity int
+}
+
+// Sampler counts a sample of key accesses by prefix over a sliding window.
+// A nil Sampler is valid and drops every access, so callers do not need to
+// check whether sampling is enabled.
+type Sampler struct {
+	rate     float64
+	window   time.Duration
+	capacity int
+	now      func() time.Time
+
+	mu sync.Mutex
+	// buckets[cur] counts the accesses since start; the other buckets count
+	// the accesses of the previous intervals of the window.
+	buckets [buckets]map[string]*counts
+	cur     int
+	start   time.Time
+}
+
+type counts struct {
+	reads, writes int64
+}
+
+func (c *counts) total() int64 { return c.reads + c.writes }
+
+// NewSampler returns a Sampler, or nil if cfg.SampleRate is not positive.
+// The most accessed prefixes of the last created Sampler are reported to
+// Prometheus.
+func NewSampler(cfg Config) *Sampler {
+	if cfg.SampleRate <= 0 {
+		return nil
+	}
+	if cfg.SampleRate > 1 {
+		cfg.SampleRate = 1
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultWindow
+	}
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = DefaultCapacity
+	}
+	s := &Sampler{
+		rate:     cfg.SampleRate,
+		window:   cfg.Window,
+		capacity: cfg.Capacity,
+		now:      time.Now,
+	}
+	for i := range s.buckets {
+		s.buckets[i] = make(map[string]*counts)
+	}
+	s.start = s.now()
+
+	reportTopMu.Lock()
+	reportTop = s.Top
+	reportTopMu.Unlock()
+	return s
+}
+
+// SampleRate returns the fraction of the accesses sampled.
+func (s *Sampler) SampleRate() float64 {
+	if s == nil {
+		return 0
+	}
+	return s.rate
+}
+
+// Window returns the duration of the sliding window.
+func (s *Sampler) Window() time.Duration {
+	if s == nil {
+		return 0
+	}
+	return s.window
+}
+
+// Record samples an access of type op to key.
+func (s *Sampler) Record(op Op, key []byte) {
+	if s == nil || len(key) == 0 {
+		return
+	}
+	if s.rate < 1 && rand.Float64() >= s.rate {
+		return
+	}
+	p := Prefix(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.advance()
+	b := s.buckets[s.cur]
+	c, ok := b[string(p)]
+	if !ok {
+		c = &counts{}
+		if len(b) >= s.capacity {
+			// evict the least accessed prefix and take over its counts, so
+			// that a prefix accessed often enough is never evicted
+			min := ""
+			for k, v := range b {
+				if min == "" || v.total() < b[min].total() {
+					min = k
+				}
+			}
+			*c = *b[min]
+			delete(b, min)
+		}
+		b[string(p)] = c
+	}
+	switch op {
+	case Read:
+		c.reads++
+	case Write:
+		c.writes++
+	}
+}
+
+// Top returns the n most accessed prefixes over the window, with the counts
+// scaled by the sample rate. It returns every tracked prefix if n is not
+// positive.
+func (s *Sampler) Top(n int) []KeyStats {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	s.advance()
+	merged := make(map[string]*counts)
+	for _, b := range s.buckets {
+		for k, v := range b {
+			c, ok := merged[k]
+			if !ok {
+				c = &counts{}
+				merged[k] = c
+			}
+			c.reads += v.reads
+			c.writes += v.writes
+		}
+	}
+	s.mu.Unlock()
+
+	stats := make([]KeyStats, 0, len(merged))
+	for k, c := range merged {
+		stats = append(stats, KeyStats{
+			Prefix: []byte(k),
+			Reads:  int64(float64(c.reads) / s.rate),
+			Writes: int64(float64(c.writes) / s.rate),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		ti, tj := stats[i].Reads+stats[i].Writes, stats[j].Reads+stats[j].Writes
+		if ti != tj {
+			return ti > tj
+		}
+		return bytes.Compare(stats[i].Prefix, stats[j].Prefix) < 0
+	})
+	if n > 0 && len(stats) > n {
+		stats = stats[:n]
+	}
+	return stats
+}
+
+// advance moves to the bucket of the current time, clearing the buckets of
+// the intervals that fell out of the window. It must be called with mu held.
+func (s *Sampler) advance() {
+	width := s.window / buckets
+	elapsed := int(s.now().Sub(s.start) / width)
+	if elapsed <= 0 {
+		return
+	}
+	s.start = s.start.Add(time.Duration(elapsed) * width)
+	if elapsed > buckets {
+		elapsed = buckets
+	}
+	for i := 0; i < elapsed; i++ {
+		s.cur = (s.cur + 1) % buckets
+		s.buckets[s.cur] = make(map[string]*counts)
+	}
+}
+
+// Prefix returns the prefix key is counted under: the key up to and
+// including its last '/', or the whole key if it has no '/' after its
+// first byte. The prefix is truncated to maxPrefixLen bytes.
+func Prefix(key []byte) []byte {
+	if i := bytes.LastIndexByte(key, '/'); i > 0 {
+		key = key[:i+1]
+	}
+	if len(key) > maxPrefixLen {
+		key = key[:maxPrefixLen]
+	}
+	return key
+}
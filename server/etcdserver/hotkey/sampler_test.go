@@ -0,0 +1,151 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hotkey
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPrefix(t *testing.T) {
+	tests := []struct {
+		key, want string
+	}{
+		{"foo", "foo"},
+		{"/foo", "/foo"},
+		{"/registry/pods/default/nginx", "/registry/pods/default/"},
+		{"/registry/pods/", "/registry/pods/"},
+		{"a/b", "a/"},
+		{strings.Repeat("a", maxPrefixLen+1), strings.Repeat("a", maxPrefixLen)},
+	}
+	for _, tt := range tests {
+		if got := string(Prefix([]byte(tt.key))); got != tt.want {
+			t.Errorf("Prefix(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func newTestSampler(cfg Config) (*Sampler, *time.Time) {
+	now := time.Unix(0, 0)
+	s := NewSampler(cfg)
+	s.now = func() time.Time { return now }
+	s.start = now
+	return s, &now
+}
+
+func TestSamplerTop(t *testing.T) {
+	s, _ := newTestSampler(Config{SampleRate: 1})
+	for i := 0; i < 3; i++ {
+		s.Record(Read, []byte("/a/1"))
+		s.Record(Write, []byte("/b/1"))
+	}
+	s.Record(Read, []byte("/a/2"))
+	s.Record(Write, []byte("/a/2"))
+	s.Record(Read, []byte("/c/1"))
+	s.Record(Read, nil)
+
+	want := []KeyStats{
+		{Prefix: []byte("/a/"), Reads: 4, Writes: 1},
+		{Prefix: []byte("/b/"), Writes: 3},
+		{Prefix: []byte("/c/"), Reads: 1},
+	}
+	if got := s.Top(0); !reflect.DeepEqual(got, want) {
+		t.Errorf("Top(0) = %+v, want %+v", got, want)
+	}
+	if got := s.Top(2); !reflect.DeepEqual(got, want[:2]) {
+		t.Errorf("Top(2) = %+v, want %+v", got, want[:2])
+	}
+}
+
+func TestSamplerScalesBySampleRate(t *testing.T) {
+	s, _ := newTestSampler(Config{SampleRate: 0.5})
+	for i := 0; i < 10000; i++ {
+		s.Record(Read, []byte("/a/1"))
+	}
+	st := s.Top(1)
+	if len(st) != 1 {
+		t.Fatalf("expected 1 prefix, got %d", len(st))
+	}
+	// the estimate is the sampled count scaled by the sample rate
+	if st[0].Reads < 9000 || st[0].Reads > 11000 {
+		t.Errorf("estimated reads = %d, want about 10000", st[0].Reads)
+	}
+}
+
+func TestSamplerWindow(t *testing.T) {
+	s, now := newTestSampler(Config{SampleRate: 1, Window: 6 * time.Second})
+	s.Record(Read, []byte("/old/1"))
+	*now = now.Add(3 * time.Second)
+	s.Record(Read, []byte("/new/1"))
+
+	if got := len(s.Top(0)); got != 2 {
+		t.Fatalf("expected 2 prefixes in the window, got %d", got)
+	}
+
+	// the bucket of /old/ slides out of the window
+	*now = now.Add(3 * time.Second)
+	want := []KeyStats{{Prefix: []byte("/new/"), Reads: 1}}
+	if got := s.Top(0); !reflect.DeepEqual(got, want) {
+		t.Errorf("Top(0) = %+v, want %+v", got, want)
+	}
+
+	*now = now.Add(time.Hour)
+	if got := s.Top(0); len(got) != 0 {
+		t.Errorf("expected no prefixes after the window, got %+v", got)
+	}
+}
+
+func TestSamplerCapacity(t *testing.T) {
+	s, _ := newTestSampler(Config{SampleRate: 1, Capacity: 2})
+	for i := 0; i < 5; i++ {
+		s.Record(Read, []byte("/hot/1"))
+	}
+	s.Record(Read, []byte("/cold/1"))
+	// evicts /cold/ and takes over its count
+	s.Record(Write, []byte("/new/1"))
+
+	want := []KeyStats{
+		{Prefix: []byte("/hot/"), Reads: 5},
+		{Prefix: []byte("/new/"), Reads: 1, Writes: 1},
+	}
+	if got := s.Top(0); !reflect.DeepEqual(got, want) {
+		t.Errorf("Top(0) = %+v, want %+v", got, want)
+	}
+}
+
+func TestSamplerDisabled(t *testing.T) {
+	s := NewSampler(Config{})
+	if s != nil {
+		t.Fatalf("expected nil sampler, got %+v", s)
+	}
+	s.Record(Read, []byte("foo"))
+	if got := s.Top(0); got != nil {
+		t.Errorf("expected no prefixes, got %+v", got)
+	}
+}
+
+func TestTopCollector(t *testing.T) {
+	s, _ := newTestSampler(Config{SampleRate: 1})
+	for i := 0; i < metricsTopN+5; i++ {
+		s.Record(Read, []byte{'/', byte('a' + i), '/'})
+	}
+	if got := testutil.CollectAndCount(topCollector{}); got != 2*metricsTopN {
+		t.Errorf("expected %d metrics, got %d", 2*metricsTopN, got)
+	}
+}
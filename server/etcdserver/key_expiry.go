@@ -0,0 +1,131 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// keyExpiryInterval is how often the leader looks for expired keys.
+	keyExpiryInterval = 500 * time.Millisecond
+	// maxExpiredKeysPerRequest is the maximum number of expired keys
+	// deleted by a KeyExpireRequest.
+	maxExpiredKeysPerRequest = 1000
+	// putChunkExpiryInterval is how often the leader looks for expired
+	// put chunks.
+	putChunkExpiryInterval = time.Minute
+)
+
+// monitorKeyExpiry proposes the deletion of the keys put with a ttl once
+// they expired, on the leader. The keys deleted are the ones expired at the
+// time proposed on every member, whatever the time they apply it at. Members
+// before 3.6 cannot apply the deletion, and never put keys with a ttl.
+func (s *EtcdServer) monitorKeyExpiry() {
+	lg := s.Logger()
+	for {
+		select {
+		case <-s.stopping:
+			return
+		case <-time.After(keyExpiryInterval):
+		}
+		if !s.isLeader() || !s.isClusterVersion36() {
+			continue
+		}
+		now := time.Now().Unix()
+		if len(s.KV().ExpiredKeys(now, 1)) == 0 {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(s.ctx, s.Cfg.ReqTimeout())
+		_, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{KeyExpire: &pb.KeyExpireRequest{Time: now, Limit: maxExpiredKeysPerRequest}})
+		cancel()
+		if err != nil {
+			lg.Warn("failed to delete expired keys", zap.Error(err))
+		}
+	}
+}
+
+// monitorPutChunkExpiry proposes the deletion of the staged put chunks once
+// they expired, on the leader, like monitorKeyExpiry. Members before 3.6
+// cannot apply the deletion, and never staged chunks.
+func (s *EtcdServer) monitorPutChunkExpiry() {
+	lg := s.Logger()
+	for {
+		select {
+		case <-s.stopping:
+			return
+		case <-time.After(putChunkExpiryInterval):
+		}
+		if !s.isLeader() || !s.isClusterVersion36() {
+			continue
+		}
+		now := time.Now().Unix()
+		if !schema.HasExpiredChunks(s.Backend().ReadTx(), now) {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(s.ctx, s.Cfg.ReqTimeout())
+		_, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{PutChunkExpire: &pb.PutChunkExpireRequest{Time: now}})
+		cancel()
+		if err != nil {
+			lg.Warn("failed to delete expired put chunks", zap.Error(err))
+		}
+	}
+}
+
+// setExpireTimes sets the expire time of the puts of r with a ttl, from
+// now, so that they expire at the same time on every member. It returns
+// whether r puts any key with an expire time.
+func setExpireTimes(r *pb.InternalRaftRequest, now time.Time) bool {
+	switch {
+	case r.Put != nil:
+		return setPutExpireTime(r.Put, now)
+	case r.Txn != nil:
+		return setTxnExpireTimes(r.Txn, now)
+	case r.PutChunked != nil && r.PutChunked.Put != nil:
+		return setPutExpireTime(r.PutChunked.Put, now)
+	case r.BulkWrite != nil:
+		return setTxnExpireTimes(&pb.TxnRequest{Success: r.BulkWrite.Ops}, now)
+	}
+	return false
+}
+
+func setTxnExpireTimes(r *pb.TxnRequest, now time.Time) bool {
+	set := false
+	for _, ops := range [][]*pb.RequestOp{r.Success, r.Failure} {
+		for _, op := range ops {
+			switch tv := op.Request.(type) {
+			case *pb.RequestOp_RequestPut:
+				set = setPutExpireTime(tv.RequestPut, now) || set
+			case *pb.RequestOp_RequestTxn:
+				set = setTxnExpireTimes(tv.RequestTxn, now) || set
+			}
+		}
+	}
+	return set
+}
+
+func setPutExpireTime(r *pb.PutRequest, now time.Time) bool {
+	if r.Ttl > 0 {
+		r.ExpireTime = now.Add(time.Duration(r.Ttl) * time.Second).Unix()
+		r.Ttl = 0
+	}
+	return r.ExpireTime != 0
+}
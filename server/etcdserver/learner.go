@@ -0,0 +1,210 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
+)
+
+const (
+	// learnerSampleInterval is the interval the leader samples the
+	// progress of the learners at to estimate their catch-up rate.
+	learnerSampleInterval = time.Second
+
+	// learnerRateWeight is the weight of the last sample in the smoothed
+	// catch-up rate of a learner.
+	learnerRateWeight = 0.3
+)
+
+// learnerTracker tracks the rate the learners catch up with the leader at.
+type learnerTracker struct {
+	mu      sync.Mutex
+	samples map[uint64]learnerSample
+}
+
+type learnerSample struct {
+	at time.Time
+	// deficit is the number of entries the learner misses to be ready.
+	deficit float64
+	// rate is the smoothed number of entries per second the deficit
+	// decreases by, unknown until two samples are taken.
+	rate  float64
+	rated bool
+}
+
+func newLearnerTracker() *learnerTracker {
+	return &learnerTracker{samples: make(map[uint64]learnerSample)}
+}
+
+// observe records the deficit of the learner id at the given time.
+func (t *learnerTracker) observe(id uint64, deficit float64, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := learnerSample{at: now, deficit: deficit}
+	if prev, ok := t.samples[id]; ok && now.After(prev.at) {
+		s.rate = (prev.deficit - deficit) / now.Sub(prev.at).Seconds()
+		if prev.rated {
+			s.rate = learnerRateWeight*s.rate + (1-learnerRateWeight)*prev.rate
+		}
+		s.rated = true
+	}
+	t.samples[id] = s
+}
+
+// eta returns the estimated time in milliseconds for the learner id to make
+// up the deficit, 0 if none, -1 if the learner is not catching up.
+func (t *learnerTracker) eta(id uint64, deficit float64) int64 {
+	if deficit <= 0 {
+		return 0
+	}
+	t.mu.Lock()
+	s, ok := t.samples[id]
+	t.mu.Unlock()
+	if !ok || !s.rated || s.rate <= 0 {
+		return -1
+	}
+	return int64(math.Ceil(deficit / s.rate * 1000))
+}
+
+// retain drops the samples of the learners not in ids.
+func (t *learnerTracker) retain(ids map[uint64]struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id := range t.samples {
+		if _, ok := ids[id]; !ok {
+			delete(t.samples, id)
+		}
+	}
+}
+
+// learnerDeficit returns the number of entries the learner misses to be
+// ready to be promoted, its match index reaching readyPercent of the leader's.
+func learnerDeficit(match, leaderMatch uint64) float64 {
+	return float64(leaderMatch)*readyPercent - float64(match)
+}
+
+// LearnerStatus returns the progress of the learner id, or of all the
+// learners if id is 0. It must be called on the leader.
+func (s *EtcdServer) LearnerStatus(id uint64) ([]*pb.LearnerProgress, error) {
+	rs := s.raftStatus()
+
+	// leader's raftStatus.Progress is not nil
+	if rs.Progress == nil {
+		return nil, ErrNotLeader
+	}
+	if id != 0 {
+		m := s.cluster.Member(types.ID(id))
+		if m == nil {
+			return nil, membership.ErrIDNotFound
+		}
+		if !m.IsLearner {
+			return nil, membership.ErrMemberNotLearner
+		}
+	}
+
+	leaderMatch := rs.Progress[rs.ID].Match
+	var ls []*pb.LearnerProgress
+	for _, m := range s.cluster.Members() {
+		if !m.IsLearner || (id != 0 && uint64(m.ID) != id) {
+			continue
+		}
+		pr, ok := rs.Progress[uint64(m.ID)]
+		if !ok {
+			// added to the cluster, not yet to raft
+			continue
+		}
+		deficit := learnerDeficit(pr.Match, leaderMatch)
+		p := &pb.LearnerProgress{
+			ID:          uint64(m.ID),
+			MatchIndex:  pr.Match,
+			LeaderIndex: leaderMatch,
+			LagBytes:    s.learnerLagBytes(pr.Match),
+			EtaMs:       s.learners.eta(uint64(m.ID), deficit),
+			Ready:       deficit <= 0,
+		}
+		if leaderMatch > pr.Match {
+			p.LagEntries = leaderMatch - pr.Match
+		}
+		if p.Ready && s.Cfg.StrictReconfigCheck {
+			p.Ready = s.cluster.IsReadyToPromoteMember(uint64(m.ID))
+		}
+		ls = append(ls, p)
+	}
+	return ls, nil
+}
+
+// learnerLagBytes estimates the size of the data a member whose log matches
+// the leader's up to match misses: the size of the entries after match, plus
+// the size of the backend if the leader has to send a snapshot.
+func (s *EtcdServer) learnerLagBytes(match uint64) uint64 {
+	first, err := s.r.raftStorage.FirstIndex()
+	if err != nil {
+		return 0
+	}
+	last, err := s.r.raftStorage.LastIndex()
+	if err != nil {
+		return 0
+	}
+	var lag uint64
+	lo := match + 1
+	if lo < first {
+		lag += uint64(s.Backend().Size())
+		lo = first
+	}
+	if lo > last {
+		return lag
+	}
+	ents, err := s.r.raftStorage.Entries(lo, last+1, math.MaxUint64)
+	if err != nil {
+		return lag
+	}
+	for _, e := range ents {
+		lag += uint64(e.Size())
+	}
+	return lag
+}
+
+// monitorLearners samples the progress of the learners while the member is
+// leader, to estimate the time they take to catch up.
+func (s *EtcdServer) monitorLearners() {
+	for {
+		select {
+		case <-time.After(learnerSampleInterval):
+		case <-s.stopping:
+			return
+		}
+		learners := make(map[uint64]struct{})
+		if rs := s.raftStatus(); rs.Progress != nil {
+			now := time.Now()
+			leaderMatch := rs.Progress[rs.ID].Match
+			for _, m := range s.cluster.Members() {
+				pr, ok := rs.Progress[uint64(m.ID)]
+				if !m.IsLearner || !ok {
+					continue
+				}
+				learners[uint64(m.ID)] = struct{}{}
+				s.learners.observe(uint64(m.ID), learnerDeficit(pr.Match, leaderMatch), now)
+			}
+		}
+		// drop the learners promoted or removed, or all of them once not leader
+		s.learners.retain(learners)
+	}
+}
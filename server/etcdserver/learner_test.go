@@ -0,0 +1,77 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLearnerTrackerETA(t *testing.T) {
+	lt := newLearnerTracker()
+	now := time.Now()
+
+	if eta := lt.eta(1, 100); eta != -1 {
+		t.Errorf("eta without samples = %d, want -1", eta)
+	}
+	lt.observe(1, 1000, now)
+	if eta := lt.eta(1, 1000); eta != -1 {
+		t.Errorf("eta with one sample = %d, want -1", eta)
+	}
+
+	// catching up by 100 entries per second
+	lt.observe(1, 900, now.Add(time.Second))
+	if eta := lt.eta(1, 900); eta != 9000 {
+		t.Errorf("eta = %d, want 9000", eta)
+	}
+	// the rate is smoothed: 0.3*300 + 0.7*100 = 160 entries per second
+	lt.observe(1, 600, now.Add(2*time.Second))
+	if eta := lt.eta(1, 800); eta != 5000 {
+		t.Errorf("eta = %d, want 5000", eta)
+	}
+	if eta := lt.eta(1, 0); eta != 0 {
+		t.Errorf("eta caught up = %d, want 0", eta)
+	}
+
+	// falling behind
+	lt.observe(2, 100, now)
+	lt.observe(2, 200, now.Add(time.Second))
+	if eta := lt.eta(2, 200); eta != -1 {
+		t.Errorf("eta falling behind = %d, want -1", eta)
+	}
+
+	lt.retain(map[uint64]struct{}{2: {}})
+	if eta := lt.eta(1, 800); eta != -1 {
+		t.Errorf("eta of learner dropped = %d, want -1", eta)
+	}
+}
+
+func TestLearnerDeficit(t *testing.T) {
+	tests := []struct {
+		match, leaderMatch uint64
+		ready              bool
+	}{
+		{0, 0, true},
+		{0, 10, false},
+		{89, 100, false},
+		{90, 100, true},
+		{100, 100, true},
+	}
+	for i, tt := range tests {
+		if ready := learnerDeficit(tt.match, tt.leaderMatch) <= 0; ready != tt.ready {
+			t.Errorf("#%d: ready = %v, want %v", i, ready, tt.ready)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"go.etcd.io/etcd/pkg/v3/runtime"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -76,6 +77,12 @@ var (
 		Name:      "slow_apply_total",
 		Help:      "The total number of slow apply requests (likely overloaded from slow disk).",
 	})
+	slowRequests = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "slow_request_total",
+		Help:      "The total number of requests that took longer than the slow request threshold.",
+	})
 	applySnapshotInProgress = prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: "etcd",
 		Subsystem: "server",
@@ -147,6 +154,35 @@ var (
 	},
 		[]string{"server_id"})
 
+	followerInflightMessages = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "follower_inflight_messages",
+		Help:      "The number of append messages sent to the follower and not yet acknowledged, while this member is leader.",
+	},
+		[]string{"To"})
+	followerInflightBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "follower_inflight_bytes",
+		Help:      "The total byte size of the entries sent to the follower and not yet acknowledged, while this member is leader.",
+	},
+		[]string{"To"})
+	followerInflightWindow = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "follower_inflight_window",
+		Help:      "The current limit of inflight append messages to the follower, while this member is leader.",
+	},
+		[]string{"To"})
+	followerThrottled = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "follower_throttled",
+		Help:      "Whether or not the append messages to the follower are throttled, while this member is leader. 1 if they are, 0 otherwise.",
+	},
+		[]string{"To"})
+
 	fdUsed = prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: "os",
 		Subsystem: "fd",
@@ -178,6 +214,7 @@ func init() {
 	prometheus.MustRegister(leaderChanges)
 	prometheus.MustRegister(heartbeatSendFailures)
 	prometheus.MustRegister(slowApplies)
+	prometheus.MustRegister(slowRequests)
 	prometheus.MustRegister(applySnapshotInProgress)
 	prometheus.MustRegister(proposalsCommitted)
 	prometheus.MustRegister(proposalsApplied)
@@ -192,6 +229,10 @@ func init() {
 	prometheus.MustRegister(isLearner)
 	prometheus.MustRegister(learnerPromoteSucceed)
 	prometheus.MustRegister(learnerPromoteFailed)
+	prometheus.MustRegister(followerInflightMessages)
+	prometheus.MustRegister(followerInflightBytes)
+	prometheus.MustRegister(followerInflightWindow)
+	prometheus.MustRegister(followerThrottled)
 	prometheus.MustRegister(fdUsed)
 	prometheus.MustRegister(fdLimit)
 	prometheus.MustRegister(applySec)
@@ -204,6 +245,16 @@ func init() {
 	}).Set(1)
 }
 
+// observeWithTrace observes v, with the trace ID of sc as exemplar if sc
+// is sampled, so that dashboards can link the observation to its trace.
+func observeWithTrace(o prometheus.Observer, v float64, sc trace.SpanContext) {
+	if eo, ok := o.(prometheus.ExemplarObserver); ok && sc.IsSampled() {
+		eo.ObserveWithExemplar(v, prometheus.Labels{"trace_id": sc.TraceID().String()})
+		return
+	}
+	o.Observe(v)
+}
+
 func monitorFileDescriptor(lg *zap.Logger, done <-chan struct{}) {
 	// This ticker will check File Descriptor Requirements ,and count all fds in used.
 	// And recorded some logs when in used >= limit/5*4. Just recorded message.
@@ -33,10 +33,10 @@ import (
 const (
 	// The max throughput of etcd will not exceed 100MB/s (100K * 1KB value).
 	// Assuming the RTT is around 10ms, 1MB max size is large enough.
-	maxSizePerMsg = 1 * 1024 * 1024
+	MaxSizePerMsg = 1 * 1024 * 1024
 	// Never overflow the rafthttp buffer, which is 4096.
 	// TODO: a better const?
-	maxInflightMsgs = 4096 / 8
+	DefaultMaxInflightMsgs = 4096 / 8
 )
 
 var (
@@ -208,6 +208,9 @@ func (r *raftNode) start(rh *raftReadyHandler) {
 				}
 
 				updateCommittedIndex(&ap, rh)
+				if len(rd.CommittedEntries) != 0 {
+					rh.tracer.committed(rd.CommittedEntries)
+				}
 
 				select {
 				case r.applyc <- ap:
@@ -234,9 +237,19 @@ func (r *raftNode) start(rh *raftReadyHandler) {
 				}
 
 				// gofail: var raftBeforeSave struct{}
+				saveStart := time.Now()
 				if err := r.storage.Save(rd.HardState, rd.Entries); err != nil {
 					r.lg.Fatal("failed to save Raft hard state and entries", zap.Error(err))
 				}
+				if len(rd.Entries) != 0 {
+					rh.tracer.appended(rd.Entries, r.storage, saveStart, time.Now(), rd.MustSync)
+					if st, ok := r.storage.(syncTimer); ok && rd.MustSync {
+						if syncStart, took := st.LastSync(); !syncStart.Before(saveStart) {
+							rh.profiler.ObserveFsync(took)
+							rh.degradation.ObserveFsync(took)
+						}
+					}
+				}
 				if !raft.IsEmptyHardState(rd.HardState) {
 					proposalsCommitted.Set(float64(rd.HardState.Commit))
 				}
@@ -283,7 +296,7 @@ func (r *raftNode) start(rh *raftReadyHandler) {
 					// We might improve this later on if it causes unnecessary long blocking issues.
 					waitApply := false
 					for _, ent := range rd.CommittedEntries {
-						if ent.Type == raftpb.EntryConfChange {
+						if ent.Type == raftpb.EntryConfChange || ent.Type == raftpb.EntryConfChangeV2 {
 							waitApply = true
 							break
 						}
@@ -304,6 +317,24 @@ func (r *raftNode) start(rh *raftReadyHandler) {
 				} else {
 					// leader already processed 'MsgSnap' and signaled
 					notifyc <- struct{}{}
+
+					// Leader needs to wait for the ConfChangeV2 entries to be applied
+					// before advancing, raft initiating the automatic transition out of
+					// the joint configuration they enter on advancing past them.
+					waitApply := false
+					for _, ent := range rd.CommittedEntries {
+						if ent.Type == raftpb.EntryConfChangeV2 {
+							waitApply = true
+							break
+						}
+					}
+					if waitApply {
+						select {
+						case notifyc <- struct{}{}:
+						case <-r.stopped:
+							return
+						}
+					}
 				}
 
 				r.Advance()
@@ -0,0 +1,72 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+type requestTraceKey struct{}
+
+// RequestTrace collects the timing breakdown of a request, to be returned
+// to the client that asked for it.
+type RequestTrace struct {
+	mu sync.Mutex
+	pt proposalTimings
+}
+
+// WithRequestTrace returns a context that collects the timing breakdown of
+// the request served with it into the returned RequestTrace.
+func WithRequestTrace(ctx context.Context) (context.Context, *RequestTrace) {
+	rt := &RequestTrace{}
+	return context.WithValue(ctx, requestTraceKey{}, rt), rt
+}
+
+func requestTraceFromContext(ctx context.Context) *RequestTrace {
+	rt, _ := ctx.Value(requestTraceKey{}).(*RequestTrace)
+	return rt
+}
+
+// record adds pt to the breakdown, since a request can go through several
+// phases of the same kind.
+func (rt *RequestTrace) record(pt proposalTimings) {
+	if rt == nil {
+		return
+	}
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.pt.commitWait += pt.commitWait
+	rt.pt.queueWait += pt.queueWait
+	rt.pt.fsync += pt.fsync
+	rt.pt.apply += pt.apply
+}
+
+// Proto returns the breakdown of a request that the member took total to
+// serve.
+func (rt *RequestTrace) Proto(total time.Duration) *pb.RequestTrace {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return &pb.RequestTrace{
+		TotalNs: total.Nanoseconds(),
+		RaftNs:  rt.pt.commitWait.Nanoseconds(),
+		QueueNs: rt.pt.queueWait.Nanoseconds(),
+		FsyncNs: rt.pt.fsync.Nanoseconds(),
+		ApplyNs: rt.pt.apply.Nanoseconds(),
+	}
+}
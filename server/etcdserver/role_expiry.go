@@ -0,0 +1,54 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+
+	"go.uber.org/zap"
+)
+
+// roleGrantExpiryInterval is how often the leader looks for expired role
+// grants.
+const roleGrantExpiryInterval = time.Second
+
+// monitorRoleGrantExpiry proposes the revocation of the roles granted until
+// a time once it passed, on the leader.
+func (s *EtcdServer) monitorRoleGrantExpiry() {
+	lg := s.Logger()
+	for {
+		select {
+		case <-s.stopping:
+			return
+		case <-time.After(roleGrantExpiryInterval):
+		}
+		if !s.isLeader() {
+			continue
+		}
+		now := time.Now().Unix()
+		if !s.AuthStore().HasExpiredRoleGrants(now) {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(s.ctx, s.Cfg.ReqTimeout())
+		_, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{AuthUserRoleExpire: &pb.AuthUserRoleExpireRequest{Time: now}})
+		cancel()
+		if err != nil {
+			lg.Warn("failed to revoke expired role grants", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+
+	"go.uber.org/zap"
+)
+
+// scrubBatchesPerSecond is the number of batches the records verified per
+// second by the backend scrubbing are split into.
+const scrubBatchesPerSecond = 10
+
+// monitorBackendScrub verifies the backend in the background, so that a
+// corruption of the local database is detected before it is sent to the
+// other members in a snapshot. The member raises a CORRUPT alarm for itself
+// on the first corruption found.
+func (s *EtcdServer) monitorBackendScrub() {
+	interval := s.Cfg.BackendScrubInterval
+	if interval == 0 {
+		return
+	}
+	rate := s.Cfg.BackendScrubRate
+	batch := rate / scrubBatchesPerSecond
+	if batch < 1 {
+		batch = 1
+	}
+	pause := time.Second * time.Duration(batch) / time.Duration(rate)
+
+	lg := s.Logger()
+	lg.Info(
+		"enabled backend scrubbing",
+		zap.String("local-member-id", s.ID().String()),
+		zap.Duration("interval", interval),
+		zap.Int("rate", rate),
+	)
+
+	var pos backend.ScrubPosition
+	wait := interval
+	for {
+		select {
+		case <-s.stopping:
+			return
+		case <-time.After(wait):
+		}
+		next, done, err := s.Backend().Scrub(pos, batch, verifyBackendRecord)
+		if err == nil && !done {
+			pos, wait = next, pause
+			continue
+		}
+		pos, wait = backend.ScrubPosition{}, interval
+		var cerr *backend.CorruptionError
+		switch {
+		case errors.As(err, &cerr):
+			lg.Error(
+				"found backend corruption",
+				zap.String("local-member-id", s.ID().String()),
+				zap.Error(err),
+			)
+			a := &pb.AlarmRequest{
+				MemberID: uint64(s.ID()),
+				Action:   pb.AlarmRequest_ACTIVATE,
+				Alarm:    pb.AlarmType_CORRUPT,
+			}
+			s.GoAttach(func() {
+				s.raftRequest(s.ctx, pb.InternalRaftRequest{Alarm: a})
+			})
+		case err != nil:
+			lg.Warn("failed to scrub backend", zap.Error(err))
+		default:
+			lg.Debug("completed backend scrubbing pass")
+		}
+	}
+}
+
+// verifyBackendRecord verifies the records of the buckets whose format is
+// known, the keys being verified for all of them.
+func verifyBackendRecord(bucket, key, value []byte) error {
+	if bytes.Equal(bucket, schema.Key.Name()) {
+		return mvcc.VerifyKeyRecord(key, value)
+	}
+	return nil
+}
@@ -36,6 +36,7 @@ import (
 	"go.etcd.io/etcd/server/v3/config"
 	"go.uber.org/zap"
 
+	"go.etcd.io/etcd/api/v3/authpb"
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/membershippb"
 	"go.etcd.io/etcd/api/v3/version"
@@ -52,8 +53,10 @@ import (
 	"go.etcd.io/etcd/raft/v3/raftpb"
 	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/etcdserver/api"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/clusterhistory"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/etcdhttp/types"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/prefixquota"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/rafthttp"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/snap"
 	stats "go.etcd.io/etcd/server/v3/etcdserver/api/v2stats"
@@ -61,6 +64,9 @@ import (
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3alarm"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3compactor"
 	"go.etcd.io/etcd/server/v3/etcdserver/cindex"
+	"go.etcd.io/etcd/server/v3/etcdserver/degradation"
+	"go.etcd.io/etcd/server/v3/etcdserver/diagnostics"
+	"go.etcd.io/etcd/server/v3/etcdserver/hotkey"
 	serverversion "go.etcd.io/etcd/server/v3/etcdserver/version"
 	"go.etcd.io/etcd/server/v3/lease"
 	"go.etcd.io/etcd/server/v3/lease/leasehttp"
@@ -68,6 +74,7 @@ import (
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
 	"go.etcd.io/etcd/server/v3/storage/schema"
+	"go.etcd.io/etcd/server/v3/storage/wal"
 )
 
 const (
@@ -102,6 +109,14 @@ const (
 
 	readyPercent = 0.9
 
+	// followerFlowControlInterval is the interval of the updates of the
+	// follower flow control metrics.
+	followerFlowControlInterval = time.Second
+
+	// degradationSampleInterval is the interval the signals of the
+	// degradation of the local member are sampled at.
+	degradationSampleInterval = time.Second
+
 	DowngradeEnabledPath = "/downgrade/enabled"
 )
 
@@ -170,6 +185,10 @@ type Server interface {
 	// return ErrLearnerNotReady if the member are not ready.
 	// return ErrMemberNotLearner if the member is not a learner.
 	PromoteMember(ctx context.Context, id uint64) ([]*membership.Member, error)
+	// ReplaceMembers attempts to add and remove several members at once
+	// through joint consensus. It will return ErrNoMemberChange if no member
+	// is added or removed, or the errors of AddMember and RemoveMember.
+	ReplaceMembers(ctx context.Context, add []membership.Member, remove []uint64) ([]*membership.Member, error)
 
 	// ClusterVersion is the cluster-wide minimum major.minor version.
 	// Cluster version is set to the min version that an etcd member is
@@ -210,6 +229,9 @@ type EtcdServer struct {
 	committedIndex    uint64 // must use atomic operations to access; keep 64-bit aligned.
 	term              uint64 // must use atomic operations to access; keep 64-bit aligned.
 	lead              uint64 // must use atomic operations to access; keep 64-bit aligned.
+	// leaderHeardAt is the time in unix nanoseconds the last heartbeat or
+	// append from the leader was received at.
+	leaderHeardAt int64 // must use atomic operations to access; keep 64-bit aligned.
 
 	consistIndex cindex.ConsistentIndexer // consistIndex is used to get/set/save consistentIndex
 	r            raftNode                 // uses 64-bit atomics; keep 64-bit aligned.
@@ -236,6 +258,9 @@ type EtcdServer struct {
 	stopping chan struct{}
 	// done is closed when all goroutines from start() complete.
 	done chan struct{}
+	// draining is set once the server starts draining on shutdown;
+	// must use atomic operations to access.
+	draining int32
 	// leaderChanged is used to notify the linearizable read loop to drop the old read requests.
 	leaderChanged *notify.Notifier
 
@@ -265,6 +290,8 @@ type EtcdServer struct {
 	beHooks    *serverstorage.BackendHooks
 	authStore  auth.AuthStore
 	alarmStore *v3alarm.AlarmStore
+	// authLockouts is nil unless the lockouts are enabled.
+	authLockouts *auth.LockoutTracker
 
 	stats  *stats.ServerStats
 	lstats *stats.LeaderStats
@@ -277,6 +304,34 @@ type EtcdServer struct {
 	peerRt   http.RoundTripper
 	reqIDGen *idutil.Generator
 
+	// tracer traces the raft and apply pipeline of sampled requests, and
+	// tracks its timings for slow request logs and request traces.
+	tracer *proposalTracer
+	// applyingTraceID is the trace ID of the sampled request being
+	// applied, set on the batch tx that commits its writes.
+	applyingTraceID atomic.Value // string
+
+	// hotKeys samples the key accesses of client requests, nil if disabled.
+	hotKeys *hotkey.Sampler
+
+	// profiler captures profiles when the apply or fsync latency crosses
+	// its thresholds, nil if disabled.
+	profiler *diagnostics.Profiler
+
+	// degradation detects the sustained degradation of the local member,
+	// nil if disabled.
+	degradation *degradation.Detector
+
+	// history persists the membership, leader, downgrade and alarm events
+	// of the cluster.
+	history *clusterhistory.History
+
+	// prefixQuotas are the quotas of the key prefixes with their usage.
+	prefixQuotas *prefixquota.Store
+
+	// learners tracks the catch-up rate of the learners while leader.
+	learners *learnerTracker
+
 	// wgMu blocks concurrent waitgroup mutation while server stopping
 	wgMu sync.RWMutex
 	// wg is used to wait for the goroutines that depends on the server state
@@ -298,6 +353,10 @@ type EtcdServer struct {
 	// forceSnapshot can force snapshot be triggered after apply, independent of the snapshotCount.
 	// Should only be set within apply code path. Used to force snapshot after cluster version downgrade.
 	forceSnapshot bool
+	// jointConfChangeID is the ID of the request waiting for the joint
+	// configuration it entered to be left. Should only be set within apply
+	// code path.
+	jointConfChangeID uint64
 }
 
 // NewServer creates a new EtcdServer from the supplied configuration. The
@@ -317,6 +376,16 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 	sstats := stats.NewServerStats(cfg.Name, b.cluster.cl.String())
 	lstats := stats.NewLeaderStats(cfg.Logger, b.cluster.nodeID.String())
 
+	var walDurability string
+	if cfg.WALDurability == wal.DurabilityBatched || cfg.WALDurability == wal.DurabilityNone {
+		walDurability = cfg.WALDurability
+		cfg.Logger.Warn(
+			"raft log writes are not fsynced on every write; committed data may be lost if several members fail at once",
+			zap.String("wal-durability", cfg.WALDurability),
+			zap.Duration("wal-sync-interval", cfg.WALSyncInterval),
+		)
+	}
+
 	heartbeat := time.Duration(cfg.TickMs) * time.Millisecond
 	srv = &EtcdServer{
 		readych:               make(chan struct{}),
@@ -328,7 +397,7 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 		snapshotter:           b.ss,
 		r:                     *b.raft.newRaftNode(b.ss, b.storage.wal.w, b.cluster.cl),
 		id:                    b.cluster.nodeID,
-		attributes:            membership.Attributes{Name: cfg.Name, ClientURLs: cfg.ClientURLs.StringSlice()},
+		attributes:            membership.Attributes{Name: cfg.Name, ClientURLs: cfg.ClientURLs.StringSlice(), ElectionPriority: cfg.ElectionPriority, Labels: cfg.MemberLabels, WALDurability: walDurability},
 		cluster:               b.cluster.cl,
 		stats:                 sstats,
 		lstats:                lstats,
@@ -339,6 +408,18 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 		consistIndex:          b.storage.backend.ci,
 		firstCommitInTerm:     notify.NewNotifier(),
 		clusterVersionChanged: notify.NewNotifier(),
+		tracer:                newProposalTracer(cfg.ExperimentalTracerProvider, cfg.SlowRequestThreshold > 0),
+		learners:              newLearnerTracker(),
+		hotKeys:               hotkey.NewSampler(hotkey.Config{SampleRate: cfg.HotKeySampleRate, Window: cfg.HotKeyWindow}),
+		profiler: diagnostics.New(cfg.Logger, diagnostics.Config{
+			ApplyThreshold: cfg.ProfileApplyThreshold,
+			FsyncThreshold: cfg.ProfileFsyncThreshold,
+			MinInterval:    cfg.ProfileMinInterval,
+			CPUDuration:    cfg.ProfileCPUDuration,
+			Dir:            cfg.ProfileDir(),
+			URL:            cfg.ProfileURL,
+		}),
+		degradation: degradation.New(cfg.Logger, cfg.LeaderDegradation),
 	}
 	serverID.With(prometheus.Labels{"server_id": b.cluster.nodeID.String()}).Set(1)
 	srv.cluster.SetVersionChangedNotifier(srv.clusterVersionChanged)
@@ -367,14 +448,49 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 		cfg.Logger.Warn("failed to create token provider", zap.Error(err))
 		return nil, err
 	}
+	if cfg.AuthOIDC.IssuerURL != "" {
+		if tp, err = auth.NewTokenProviderOIDC(cfg.Logger, tp, cfg.AuthOIDC); err != nil {
+			cfg.Logger.Warn("failed to create OIDC token provider", zap.Error(err))
+			return nil, err
+		}
+	}
+	certRoleRules, err := auth.ParseCertRoleRules(cfg.ClientCertRoleRules)
+	if err != nil {
+		cfg.Logger.Warn("failed to parse client cert role rules", zap.Error(err))
+		return nil, err
+	}
 
+	srv.prefixQuotas = prefixquota.New()
 	mvccStoreConfig := mvcc.StoreConfig{
-		CompactionBatchLimit:    cfg.CompactionBatchLimit,
-		CompactionSleepInterval: cfg.CompactionSleepInterval,
+		CompactionBatchLimit:      cfg.CompactionBatchLimit,
+		CompactionSleepInterval:   cfg.CompactionSleepInterval,
+		ValueCompression:          cfg.ValueCompression,
+		ValueCompressionThreshold: cfg.ValueCompressionThreshold,
+		OnWrite:                   srv.prefixQuotas.Observe,
+		OnDeleteRange:             srv.prefixQuotas.ObserveDeleteRange,
+		RangeTombstoneThreshold:   cfg.RangeTombstoneThreshold,
+		ReadCacheSize:             cfg.ReadCacheSize,
+		StuckWatcherPolicy:        cfg.WatchStuckPolicy,
+		StuckWatcherMaxBytes:      cfg.WatchStuckMaxBytes,
+		StuckWatcherMaxAge:        cfg.WatchStuckMaxAge,
+		HistoryRetention:          cfg.WatchHistoryRetention,
 	}
 	srv.kv = mvcc.New(srv.Logger(), srv.be, srv.lessor, mvccStoreConfig)
 
 	srv.authStore = auth.NewAuthStore(srv.Logger(), schema.NewAuthBackend(srv.Logger(), srv.be), tp, int(cfg.BcryptCost))
+	srv.authStore.SetCertRoleRules(certRoleRules)
+	srv.authStore.SetPasswordPolicy(cfg.AuthPasswordPolicy)
+	if cfg.AuthLDAP.URL != "" {
+		ldap, err := auth.NewLDAPAuthenticator(cfg.Logger, cfg.AuthLDAP)
+		if err != nil {
+			cfg.Logger.Warn("failed to create LDAP authenticator", zap.Error(err))
+			return nil, err
+		}
+		srv.authStore.SetLDAPAuthenticator(ldap)
+	}
+	if cfg.AuthLockout.Enabled() {
+		srv.authLockouts = auth.NewLockoutTracker(cfg.AuthLockout)
+	}
 
 	newSrv := srv // since srv == nil in defer if srv is returned as nil
 	defer func() {
@@ -384,10 +500,25 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 			newSrv.kv.Close()
 		}
 	}()
-	if num := cfg.AutoCompactionRetention; num != 0 {
-		srv.compactor, err = v3compactor.New(cfg.Logger, cfg.AutoCompactionMode, num, srv.kv, srv)
-		if err != nil {
-			return nil, err
+	// a witness has no revisions to compact
+	if num := cfg.AutoCompactionRetention; num != 0 && !cfg.Witness {
+		delay := v3compactor.JitterDelay(uint64(srv.ID()), cfg.TimerJitter)
+		if len(cfg.AutoCompactionPrefixRetentions) != 0 {
+			srv.compactor = v3compactor.NewPrefixPeriodic(cfg.Logger, num, cfg.AutoCompactionPrefixRetentions, delay, srv.kv, srv)
+		} else {
+			srv.compactor, err = v3compactor.New(cfg.Logger, cfg.AutoCompactionMode, num, delay, srv.kv, srv)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if cfg.TimerJitter > 0 {
+			cfg.Logger.Info(
+				"jittered auto compaction schedule",
+				zap.String("local-member-id", srv.ID().String()),
+				zap.String("auto-compaction-mode", cfg.AutoCompactionMode),
+				zap.Duration("timer-jitter", cfg.TimerJitter),
+				zap.Duration("start-delay", delay),
+			)
 		}
 		srv.compactor.Run()
 	}
@@ -397,6 +528,8 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 	if err = srv.restoreAlarms(); err != nil {
 		return nil, err
 	}
+	srv.history = clusterhistory.New(srv.be, 0)
+	srv.prefixQuotas.Recover(srv.be, srv.kv)
 
 	if srv.Cfg.EnableLeaseCheckpoint {
 		// setting checkpointer enables lease checkpoint feature.
@@ -407,7 +540,7 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 
 	// Set the hook after EtcdServer finishes the initialization to avoid
 	// the hook being called during the initialization process.
-	srv.be.SetTxPostLockInsideApplyHook(srv.getTxPostLockInsideApplyHook())
+	srv.be.SetTxPostLockInsideApplyHook(srv.getTxPostLockInsideApplyHook(srv.be))
 
 	// TODO: move transport initialization near the definition of remote
 	tr := &rafthttp.Transport{
@@ -422,6 +555,7 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 		ServerStats: sstats,
 		LeaderStats: lstats,
 		ErrorC:      srv.errorc,
+		Compression: cfg.PeerCompression,
 	}
 	if err = tr.Start(); err != nil {
 		return nil, err
@@ -533,7 +667,17 @@ func (s *EtcdServer) Start() {
 	s.GoAttach(s.monitorStorageVersion)
 	s.GoAttach(s.linearizableReadLoop)
 	s.GoAttach(s.monitorKVHash)
+	s.GoAttach(s.monitorBackendScrub)
+	s.GoAttach(s.monitorKeyExpiry)
+	s.GoAttach(s.monitorPutChunkExpiry)
+	s.GoAttach(s.monitorRoleGrantExpiry)
+	s.GoAttach(s.monitorWALArchive)
 	s.GoAttach(s.monitorDowngrade)
+	s.GoAttach(s.monitorWitnessLeadership)
+	s.GoAttach(s.monitorLeaderPlacement)
+	s.GoAttach(s.monitorLocalDegradation)
+	s.GoAttach(s.monitorFollowerFlowControl)
+	s.GoAttach(s.monitorLearners)
 }
 
 // start prepares and starts server in a new goroutine. It is no longer safe to
@@ -708,7 +852,14 @@ func (s *EtcdServer) Process(ctx context.Context, m raftpb.Message) error {
 	if m.Type == raftpb.MsgApp {
 		s.stats.RecvAppendReq(types.ID(m.From).String(), m.Size())
 	}
-	return s.r.Step(ctx, m)
+	if err := s.r.Step(ctx, m); err != nil {
+		return err
+	}
+	if m.Type == raftpb.MsgApp || m.Type == raftpb.MsgHeartbeat {
+		// the message refreshes the commit index known to the member
+		s.setLeaderHeardAt(time.Now())
+	}
+	return nil
 }
 
 func (s *EtcdServer) IsIDRemoved(id uint64) bool { return s.cluster.IsIDRemoved(types.ID(id)) }
@@ -736,6 +887,9 @@ type raftReadyHandler struct {
 	updateLead           func(lead uint64)
 	updateLeadership     func(newLeader bool)
 	updateCommittedIndex func(uint64)
+	tracer               *proposalTracer
+	profiler             *diagnostics.Profiler
+	degradation          *degradation.Detector
 }
 
 func (s *EtcdServer) run() {
@@ -790,6 +944,7 @@ func (s *EtcdServer) run() {
 			}
 			if newLeader {
 				s.leaderChanged.Notify()
+				s.recordLeaderChange(s.getLead(), s.getTerm())
 			}
 			// TODO: remove the nil checking
 			// current test utility does not provide the stats
@@ -803,6 +958,9 @@ func (s *EtcdServer) run() {
 				s.setCommittedIndex(ci)
 			}
 		},
+		tracer:      s.tracer,
+		profiler:    s.profiler,
+		degradation: s.degradation,
 	}
 	s.r.start(rh)
 
@@ -857,7 +1015,7 @@ func (s *EtcdServer) run() {
 					lid := lease.ID
 					s.GoAttach(func() {
 						ctx := s.authStore.WithRoot(s.ctx)
-						_, lerr := s.LeaseRevoke(ctx, &pb.LeaseRevokeRequest{ID: int64(lid)})
+						lerr := s.leaseExpire(ctx, lid)
 						if lerr == nil {
 							leaseExpired.Inc()
 						} else {
@@ -906,6 +1064,7 @@ func (s *EtcdServer) Cleanup() {
 	if s.compactor != nil {
 		s.compactor.Stop()
 	}
+	s.profiler.Stop()
 }
 
 func (s *EtcdServer) applyAll(ep *etcdProgress, apply *apply) {
@@ -924,6 +1083,12 @@ func (s *EtcdServer) applyAll(ep *etcdProgress, apply *apply) {
 	select {
 	// snapshot requested via send()
 	case m := <-s.r.msgSnapC:
+		if s.Cfg.Witness {
+			// a witness holds no KV data to send, the member catches up
+			// from a snapshot of the next leader.
+			s.r.ReportSnapshot(m.To, raft.SnapshotFailure)
+			break
+		}
 		merged := s.createMergedSnapshotMessage(m, ep.appliedt, ep.appliedi, ep.confState)
 		s.sendMergedSnap(merged)
 	default:
@@ -981,6 +1146,15 @@ func (s *EtcdServer) applySnapshot(ep *etcdProgress, apply *apply) {
 	s.consistIndex.SetBackend(newbe)
 	verifySnapshotIndex(apply.snapshot, s.consistIndex.ConsistentIndex())
 
+	if s.Cfg.Witness {
+		// a witness keeps the membership of the snapshot, not its KV data
+		tx := newbe.BatchTx()
+		tx.LockOutsideApply()
+		schema.UnsafeClearKV(tx)
+		tx.Unlock()
+		lg.Info("cleared KV data of the snapshot; local member is a witness")
+	}
+
 	// always recover lessor before kv. When we recover the mvcc.KV it will reattach keys to its leases.
 	// If we recover mvcc.KV first, it will attach the keys to the wrong lessor before it recovers.
 	if s.lessor != nil {
@@ -997,7 +1171,7 @@ func (s *EtcdServer) applySnapshot(ep *etcdProgress, apply *apply) {
 		lg.Panic("failed to restore mvcc store", zap.Error(err))
 	}
 
-	newbe.SetTxPostLockInsideApplyHook(s.getTxPostLockInsideApplyHook())
+	newbe.SetTxPostLockInsideApplyHook(s.getTxPostLockInsideApplyHook(newbe))
 
 	lg.Info("restored mvcc store", zap.Uint64("consistent-index", s.consistIndex.ConsistentIndex()))
 
@@ -1027,6 +1201,14 @@ func (s *EtcdServer) applySnapshot(ep *etcdProgress, apply *apply) {
 
 	lg.Info("restored alarm store")
 
+	lg.Info("restoring cluster history")
+	s.history.Recover(newbe)
+	lg.Info("restored cluster history")
+
+	lg.Info("restoring prefix quotas")
+	s.prefixQuotas.Recover(newbe, s.kv)
+	lg.Info("restored prefix quotas")
+
 	if s.authStore != nil {
 		lg.Info("restoring auth store")
 
@@ -1235,6 +1417,18 @@ func (s *EtcdServer) Stop() {
 	s.HardStop()
 }
 
+// Drain puts the server in the drain phase of a graceful shutdown, in
+// which new client streams, e.g. watches and lease keep-alives, are
+// rejected while in-flight requests are allowed to complete.
+func (s *EtcdServer) Drain() {
+	if atomic.CompareAndSwapInt32(&s.draining, 0, 1) {
+		s.Logger().Info("draining server", zap.String("local-member-id", s.ID().String()))
+	}
+}
+
+// IsDraining returns true if the server is draining.
+func (s *EtcdServer) IsDraining() bool { return atomic.LoadInt32(&s.draining) == 1 }
+
 // ReadyNotify returns a channel that will be closed when the server
 // is ready to serve client requests
 func (s *EtcdServer) ReadyNotify() <-chan struct{} { return s.readych }
@@ -1276,7 +1470,7 @@ func (s *EtcdServer) checkMembershipOperationPermission(ctx context.Context) err
 		return err
 	}
 
-	return s.AuthStore().IsAdminPermitted(authInfo)
+	return s.AuthStore().IsAdminOpPermitted(authInfo, authpb.MEMBER)
 }
 
 func (s *EtcdServer) AddMember(ctx context.Context, memb membership.Member) ([]*membership.Member, error) {
@@ -1355,6 +1549,80 @@ func (s *EtcdServer) RemoveMember(ctx context.Context, id uint64) ([]*membership
 	return s.configure(ctx, cc)
 }
 
+// ReplaceMembers adds and removes the members in a single configuration change
+// through joint consensus, the cluster keeping the quorum of both its current
+// and new voting members until the change is done. Members before 3.6 cannot
+// apply the change, so it is rejected until the cluster is at 3.6.
+func (s *EtcdServer) ReplaceMembers(ctx context.Context, add []membership.Member, remove []uint64) ([]*membership.Member, error) {
+	if !s.isClusterVersion36() {
+		return nil, ErrNotSupportedByCluster
+	}
+	if err := s.checkMembershipOperationPermission(ctx); err != nil {
+		return nil, err
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return nil, membership.ErrNoMemberChange
+	}
+
+	// by default StrictReconfigCheck is enabled; reject replacement if leads to quorum loss
+	if err := s.mayReplaceMembers(add, remove); err != nil {
+		return nil, err
+	}
+
+	ccc := membership.ConfigChangeV2Context{ID: s.reqIDGen.Next(), Members: add}
+	b, err := json.Marshal(ccc)
+	if err != nil {
+		return nil, err
+	}
+	cc := raftpb.ConfChangeV2{Context: b}
+	for _, m := range add {
+		typ := raftpb.ConfChangeAddNode
+		if m.IsLearner {
+			typ = raftpb.ConfChangeAddLearnerNode
+		}
+		cc.Changes = append(cc.Changes, raftpb.ConfChangeSingle{Type: typ, NodeID: uint64(m.ID)})
+	}
+	for _, id := range remove {
+		cc.Changes = append(cc.Changes, raftpb.ConfChangeSingle{Type: raftpb.ConfChangeRemoveNode, NodeID: id})
+	}
+	return s.configureV2(ctx, cc, ccc.ID)
+}
+
+func (s *EtcdServer) mayReplaceMembers(add []membership.Member, remove []uint64) error {
+	lg := s.Logger()
+	if !s.Cfg.StrictReconfigCheck {
+		return nil
+	}
+
+	ids := make([]types.ID, len(remove))
+	for i, id := range remove {
+		ids[i] = types.ID(id)
+	}
+	if !s.cluster.IsReadyToReplaceMembers(add, ids) {
+		lg.Warn(
+			"rejecting member replace request; not enough healthy members",
+			zap.String("local-member-id", s.ID().String()),
+			zap.Int("requested-member-add", len(add)),
+			zap.Int("requested-member-remove", len(remove)),
+			zap.Error(ErrNotEnoughStartedMembers),
+		)
+		return ErrNotEnoughStartedMembers
+	}
+
+	if !isConnectedFullySince(s.r.transport, time.Now().Add(-HealthInterval), s.ID(), s.cluster.VotingMembers()) {
+		lg.Warn(
+			"rejecting member replace request; local member has not been connected to all peers, reconfigure breaks active quorum",
+			zap.String("local-member-id", s.ID().String()),
+			zap.Int("requested-member-add", len(add)),
+			zap.Int("requested-member-remove", len(remove)),
+			zap.Error(ErrUnhealthy),
+		)
+		return ErrUnhealthy
+	}
+
+	return nil
+}
+
 // PromoteMember promotes a learner node to a voting node.
 func (s *EtcdServer) PromoteMember(ctx context.Context, id uint64) ([]*membership.Member, error) {
 	// only raft leader has information on whether the to-be-promoted learner node is ready. If promoteMember call
@@ -1560,6 +1828,19 @@ func (s *EtcdServer) getCommittedIndex() uint64 {
 	return atomic.LoadUint64(&s.committedIndex)
 }
 
+func (s *EtcdServer) setLeaderHeardAt(t time.Time) {
+	atomic.StoreInt64(&s.leaderHeardAt, t.UnixNano())
+}
+
+// getLeaderHeardAt returns the zero time if the member never heard from the
+// leader.
+func (s *EtcdServer) getLeaderHeardAt() time.Time {
+	if t := atomic.LoadInt64(&s.leaderHeardAt); t != 0 {
+		return time.Unix(0, t)
+	}
+	return time.Time{}
+}
+
 func (s *EtcdServer) setAppliedIndex(v uint64) {
 	atomic.StoreUint64(&s.appliedIndex, v)
 }
@@ -1659,6 +1940,45 @@ func (s *EtcdServer) configure(ctx context.Context, cc raftpb.ConfChange) ([]*me
 	}
 }
 
+// configureV2 sends a configuration change of several members through
+// consensus and then waits for the request id to be applied to the server,
+// the joint configuration it enters left. It will block until the change is
+// performed or there is an error.
+func (s *EtcdServer) configureV2(ctx context.Context, cc raftpb.ConfChangeV2, id uint64) ([]*membership.Member, error) {
+	if !s.isClusterVersion36() {
+		return nil, ErrNotSupportedByCluster
+	}
+	lg := s.Logger()
+	ch := s.w.Register(id)
+
+	start := time.Now()
+	if err := s.r.ProposeConfChange(ctx, cc); err != nil {
+		s.w.Trigger(id, nil)
+		return nil, err
+	}
+
+	select {
+	case x := <-ch:
+		if x == nil {
+			lg.Panic("failed to configure")
+		}
+		resp := x.(*confChangeResponse)
+		lg.Info(
+			"applied a configuration change through raft",
+			zap.String("local-member-id", s.ID().String()),
+			zap.Int("raft-conf-changes", len(cc.Changes)),
+		)
+		return resp.membs, resp.err
+
+	case <-ctx.Done():
+		s.w.Trigger(id, nil) // GC wait
+		return nil, s.parseProposeCtxErr(ctx.Err(), start)
+
+	case <-s.stopping:
+		return nil, ErrStopped
+	}
+}
+
 // sync proposes a SYNC request and is non-blocking.
 // This makes no guarantee that the request will be proposed or performed.
 // The request will be canceled after the given timeout.
@@ -1687,8 +2007,11 @@ func (s *EtcdServer) publishV3(timeout time.Duration) {
 	req := &membershippb.ClusterMemberAttrSetRequest{
 		Member_ID: uint64(s.id),
 		MemberAttributes: &membershippb.Attributes{
-			Name:       s.attributes.Name,
-			ClientUrls: s.attributes.ClientURLs,
+			Name:             s.attributes.Name,
+			ClientUrls:       s.attributes.ClientURLs,
+			ElectionPriority: s.attributes.ElectionPriority,
+			Labels:           s.attributes.Labels,
+			WalDurability:    s.attributes.WALDurability,
 		},
 	}
 	lg := s.Logger()
@@ -1812,10 +2135,27 @@ func (s *EtcdServer) apply(
 			shouldStop = shouldStop || removedSelf
 			s.w.Trigger(cc.ID, &confChangeResponse{s.cluster.Members(), err})
 
+		case raftpb.EntryConfChangeV2:
+			shouldApplyV3 := membership.ApplyV2storeOnly
+			if e.Index > s.consistIndex.ConsistentIndex() {
+				s.consistIndex.SetConsistentApplyingIndex(e.Index, e.Term)
+				shouldApplyV3 = membership.ApplyBoth
+			}
+
+			var cc raftpb.ConfChangeV2
+			pbutil.MustUnmarshal(&cc, e.Data)
+			removedSelf, id, err := s.applyConfChangeV2(cc, confState, shouldApplyV3)
+			s.setAppliedIndex(e.Index)
+			s.setTerm(e.Term)
+			shouldStop = shouldStop || removedSelf
+			if id != 0 {
+				s.w.Trigger(id, &confChangeResponse{s.cluster.Members(), err})
+			}
+
 		default:
 			lg := s.Logger()
 			lg.Panic(
-				"unknown entry type; must be either EntryNormal or EntryConfChange",
+				"unknown entry type; must be either EntryNormal, EntryConfChange or EntryConfChangeV2",
 				zap.String("type", e.Type.String()),
 			)
 		}
@@ -1885,13 +2225,32 @@ func (s *EtcdServer) applyEntryNormal(e *raftpb.Entry) {
 		id = raftReq.Header.ID
 	}
 
+	if s.Cfg.Witness && !isAppliedByWitness(&raftReq) {
+		// the consistent index still moves forward
+		return
+	}
+
 	needResult := s.w.IsRegistered(id)
 	if needResult || !noSideEffect(&raftReq) {
 		if !needResult && raftReq.Txn != nil {
 			removeNeedlessRangeReqs(raftReq.Txn)
 		}
 		applyV3Performed = true
+		traced := false
+		if sc := s.tracer.spanContextOf(&raftReq); sc.IsSampled() && !noSideEffect(&raftReq) {
+			// recorded by the txPostLockInsideApplyHook with the writes of
+			// the request, which may be committed before the lock is released
+			s.applyingTraceID.Store(sc.TraceID().String())
+			traced = true
+		}
+		start := time.Now()
 		ar = s.applyV3.Apply(&raftReq, shouldApplyV3)
+		end := time.Now()
+		if traced {
+			s.applyingTraceID.Store("")
+		}
+		s.tracer.applied(id, start, end)
+		s.profiler.ObserveApply(end.Sub(start))
 	}
 
 	// do not re-apply applied entries.
@@ -1961,8 +2320,11 @@ func (s *EtcdServer) applyConfChange(cc raftpb.ConfChange, confState *raftpb.Con
 		}
 		if confChangeContext.IsPromote {
 			s.cluster.PromoteMember(confChangeContext.Member.ID, shouldApplyV3)
+			s.recordClusterEvent(pb.ClusterEvent_MEMBER_PROMOTE, confChangeContext.Member.ID, "", shouldApplyV3)
 		} else {
 			s.cluster.AddMember(&confChangeContext.Member, shouldApplyV3)
+			s.recordClusterEvent(pb.ClusterEvent_MEMBER_ADD, confChangeContext.Member.ID,
+				memberEventDetail(confChangeContext.Member.PeerURLs, confChangeContext.Member.IsLearner), shouldApplyV3)
 
 			if confChangeContext.Member.ID != s.id {
 				s.r.transport.AddPeer(confChangeContext.Member.ID, confChangeContext.PeerURLs)
@@ -1979,12 +2341,9 @@ func (s *EtcdServer) applyConfChange(cc raftpb.ConfChange, confState *raftpb.Con
 		}
 
 	case raftpb.ConfChangeRemoveNode:
-		id := types.ID(cc.NodeID)
-		s.cluster.RemoveMember(id, shouldApplyV3)
-		if id == s.id {
+		if s.removeMember(types.ID(cc.NodeID), shouldApplyV3) {
 			return true, nil
 		}
-		s.r.transport.RemovePeer(id)
 
 	case raftpb.ConfChangeUpdateNode:
 		m := new(membership.Member)
@@ -1999,6 +2358,7 @@ func (s *EtcdServer) applyConfChange(cc raftpb.ConfChange, confState *raftpb.Con
 			)
 		}
 		s.cluster.UpdateRaftAttributes(m.ID, m.RaftAttributes, shouldApplyV3)
+		s.recordClusterEvent(pb.ClusterEvent_MEMBER_UPDATE, m.ID, memberEventDetail(m.PeerURLs, m.IsLearner), shouldApplyV3)
 		if m.ID != s.id {
 			s.r.transport.UpdatePeer(m.ID, m.PeerURLs)
 		}
@@ -2006,6 +2366,96 @@ func (s *EtcdServer) applyConfChange(cc raftpb.ConfChange, confState *raftpb.Con
 	return false, nil
 }
 
+// applyConfChangeV2 applies a ConfChangeV2 adding and removing several members
+// to the server, returning the ID of the request to notify, if any. It is only
+// invoked with a ConfChangeV2 that has already passed through Raft. The voting
+// members removed through joint consensus are only removed from the cluster
+// once the joint configuration is left, as they keep voting until then.
+func (s *EtcdServer) applyConfChangeV2(cc raftpb.ConfChangeV2, confState *raftpb.ConfState, shouldApplyV3 membership.ShouldApplyV3) (removedSelf bool, id uint64, err error) {
+	// The txPostLock callback is not called if the change writes nothing to
+	// the backend, so we should set the consistent index directly.
+	defer func() {
+		if s.consistIndex != nil && membership.ApplyBoth == shouldApplyV3 {
+			applyingIndex, applyingTerm := s.consistIndex.ConsistentApplyingIndex()
+			s.consistIndex.SetConsistentIndex(applyingIndex, applyingTerm)
+		}
+	}()
+
+	if cc.LeaveJoint() {
+		outgoing := confState.VotersOutgoing
+		*confState = *s.r.ApplyConfChange(cc)
+		s.beHooks.SetConfState(confState)
+		for _, mid := range outgoing {
+			if !inConfState(*confState, mid) && s.removeMember(types.ID(mid), shouldApplyV3) {
+				removedSelf = true
+			}
+		}
+		id, s.jointConfChangeID = s.jointConfChangeID, 0
+		return removedSelf, id, nil
+	}
+
+	lg := s.Logger()
+	var ccc membership.ConfigChangeV2Context
+	if err = json.Unmarshal(cc.Context, &ccc); err != nil {
+		lg.Panic("failed to unmarshal confChangeContext", zap.Error(err))
+	}
+	err = s.cluster.ValidateConfigurationChangeV2(cc, ccc)
+	if err == nil && !s.isClusterVersion36() {
+		// proposed by a member yet to learn the cluster version, the
+		// change is rejected by every member able to apply it
+		err = ErrNotSupportedByCluster
+	}
+	if err != nil {
+		for i := range cc.Changes {
+			cc.Changes[i].NodeID = raft.None
+		}
+		s.r.ApplyConfChange(cc)
+		return false, ccc.ID, err
+	}
+
+	*confState = *s.r.ApplyConfChange(cc)
+	s.beHooks.SetConfState(confState)
+	added := make(map[types.ID]*membership.Member)
+	for i := range ccc.Members {
+		added[ccc.Members[i].ID] = &ccc.Members[i]
+	}
+	for _, chg := range cc.Changes {
+		switch chg.Type {
+		case raftpb.ConfChangeAddNode, raftpb.ConfChangeAddLearnerNode:
+			m := added[types.ID(chg.NodeID)]
+			s.cluster.AddMember(m, shouldApplyV3)
+			s.recordClusterEvent(pb.ClusterEvent_MEMBER_ADD, m.ID, memberEventDetail(m.PeerURLs, m.IsLearner), shouldApplyV3)
+			if m.ID != s.id {
+				s.r.transport.AddPeer(m.ID, m.PeerURLs)
+			}
+
+		case raftpb.ConfChangeRemoveNode:
+			if !inConfState(*confState, chg.NodeID) && s.removeMember(types.ID(chg.NodeID), shouldApplyV3) {
+				removedSelf = true
+			}
+		}
+	}
+
+	if len(confState.VotersOutgoing) != 0 {
+		// notified once the joint configuration is left
+		s.jointConfChangeID = ccc.ID
+		return removedSelf, 0, nil
+	}
+	return removedSelf, ccc.ID, nil
+}
+
+// removeMember removes the member from the cluster and the transport. It
+// returns true if the member removed is the local member.
+func (s *EtcdServer) removeMember(id types.ID, shouldApplyV3 membership.ShouldApplyV3) bool {
+	s.cluster.RemoveMember(id, shouldApplyV3)
+	s.recordClusterEvent(pb.ClusterEvent_MEMBER_REMOVE, id, "", shouldApplyV3)
+	if id == s.id {
+		return true
+	}
+	s.r.transport.RemovePeer(id)
+	return false
+}
+
 // TODO: non-blocking snapshot
 func (s *EtcdServer) snapshot(snapi uint64, confState raftpb.ConfState) {
 	clone := s.v2store.Clone()
@@ -2252,6 +2702,147 @@ func (s *EtcdServer) monitorDowngrade() {
 	}
 }
 
+// monitorWitnessLeadership hands the leadership of a witness over to another
+// voting member, a witness serving no client requests and holding no KV data
+// to send to the lagging members.
+func (s *EtcdServer) monitorWitnessLeadership() {
+	if !s.Cfg.Witness {
+		return
+	}
+	lg := s.Logger()
+	for {
+		select {
+		case <-s.leaderChanged.Receive():
+		case <-time.After(s.Cfg.ElectionTimeout()):
+		case <-s.stopping:
+			return
+		}
+		if !s.isLeader() {
+			continue
+		}
+		lg.Info("transferring leadership; local member is a witness", zap.String("local-member-id", s.ID().String()))
+		if err := s.TransferLeadership(); err != nil {
+			lg.Warn("failed to transfer leadership of witness", zap.Error(err))
+		}
+	}
+}
+
+// monitorLeaderPlacement hands the leadership over to the voting member the
+// most preferred by the leader zones, then by the election priorities, if
+// preferred to the leader, once it is connected to the leader and caught up
+// with its log.
+func (s *EtcdServer) monitorLeaderPlacement() {
+	lg := s.Logger()
+	for {
+		select {
+		case <-time.After(s.Cfg.ElectionTimeout()):
+		case <-s.stopping:
+			return
+		}
+		if !s.isLeader() {
+			continue
+		}
+		lead := s.cluster.Member(s.ID())
+		if lead == nil {
+			continue
+		}
+		transferee, ok := preferredConnected(s.r.transport, lead, s.cluster.VotingMembers(), s.r.Status(), s.Cfg.LeaderZones)
+		if !ok {
+			continue
+		}
+		lg.Info(
+			"transferring leadership to preferred member",
+			zap.String("local-member-id", s.ID().String()),
+			zap.String("local-member-zone", lead.Zone()),
+			zap.Int64("local-member-election-priority", lead.ElectionPriority),
+			zap.String("transferee-member-id", transferee.String()),
+			zap.Strings("leader-zones", s.Cfg.LeaderZones),
+		)
+		ctx, cancel := context.WithTimeout(s.ctx, s.Cfg.ReqTimeout())
+		err := s.MoveLeader(ctx, uint64(s.ID()), uint64(transferee))
+		cancel()
+		if err != nil {
+			lg.Warn("failed to transfer leadership to preferred member", zap.Error(err))
+		}
+	}
+}
+
+// monitorLocalDegradation samples the signals of the degradation of the local
+// member, and hands the leadership over to another voting member while it is
+// degraded, at most once per degradation duration.
+func (s *EtcdServer) monitorLocalDegradation() {
+	if s.degradation == nil {
+		return
+	}
+	lg := s.Logger()
+	var lastTransfer time.Time
+	for {
+		select {
+		case <-time.After(degradationSampleInterval):
+		case <-s.stopping:
+			return
+		}
+		now := time.Now()
+		s.degradation.Sample(now)
+		signals := s.degradation.Degraded()
+		if len(signals) == 0 || !s.isLeader() || now.Sub(lastTransfer) < s.degradation.Duration() {
+			continue
+		}
+		lastTransfer = now
+		lg.Warn(
+			"transferring leadership; local member is degraded",
+			zap.String("local-member-id", s.ID().String()),
+			zap.Strings("signals", signals),
+		)
+		s.degradation.ObserveTransfer(signals)
+		if err := s.TransferLeadership(); err != nil {
+			lg.Warn("failed to transfer leadership of degraded member", zap.Error(err))
+		}
+	}
+}
+
+// FollowerFlowControl returns the flow control of the append messages sent
+// to the followers, nil if the member is not the leader.
+func (s *EtcdServer) FollowerFlowControl() []*pb.FollowerFlowControl {
+	return followerFlowControl(s.ID(), s.r.Status())
+}
+
+// monitorFollowerFlowControl exposes the flow control of the followers as
+// metrics while the member is leader.
+func (s *EtcdServer) monitorFollowerFlowControl() {
+	var followers map[string]struct{}
+	for {
+		select {
+		case <-time.After(followerFlowControlInterval):
+		case <-s.stopping:
+			return
+		}
+		current := make(map[string]struct{})
+		for _, f := range s.FollowerFlowControl() {
+			to := types.ID(f.ID).String()
+			current[to] = struct{}{}
+			followerInflightMessages.WithLabelValues(to).Set(float64(f.InflightMessages))
+			followerInflightBytes.WithLabelValues(to).Set(float64(f.InflightBytes))
+			followerInflightWindow.WithLabelValues(to).Set(float64(f.Window))
+			throttled := 0.0
+			if f.Throttled {
+				throttled = 1
+			}
+			followerThrottled.WithLabelValues(to).Set(throttled)
+		}
+		// drop the followers removed, or all of them once not leader
+		for to := range followers {
+			if _, ok := current[to]; !ok {
+				followerInflightMessages.DeleteLabelValues(to)
+				followerInflightBytes.DeleteLabelValues(to)
+				followerInflightWindow.DeleteLabelValues(to)
+				followerThrottled.DeleteLabelValues(to)
+			}
+		}
+		followers = current
+	}
+}
+
 func (s *EtcdServer) parseProposeCtxErr(err error, start time.Time) error {
 	switch err {
 	case context.Canceled:
@@ -2294,6 +2885,16 @@ func (s *EtcdServer) Backend() backend.Backend {
 
 func (s *EtcdServer) AuthStore() auth.AuthStore { return s.authStore }
 
+// HotKeys returns the sampler of the key accesses of client requests, nil if
+// sampling is disabled.
+func (s *EtcdServer) HotKeys() *hotkey.Sampler { return s.hotKeys }
+
+// ClusterHistory returns the events of the cluster recorded by the member.
+func (s *EtcdServer) ClusterHistory() *clusterhistory.History { return s.history }
+
+// PrefixQuotas returns the quotas of the key prefixes with their usage.
+func (s *EtcdServer) PrefixQuotas() *prefixquota.Store { return s.prefixQuotas }
+
 func (s *EtcdServer) restoreAlarms() error {
 	s.applyV3 = s.newApplierV3()
 	as, err := v3alarm.NewAlarmStore(s.lg, schema.NewAlarmBackend(s.lg, s.be))
@@ -2341,6 +2942,11 @@ func (s *EtcdServer) IsLearner() bool {
 	return s.cluster.IsLocalMemberLearner()
 }
 
+// IsWitness returns if the local member is a witness, keeping no KV data.
+func (s *EtcdServer) IsWitness() bool {
+	return s.Cfg.Witness
+}
+
 // IsMemberExist returns if the member with the given id exists in cluster.
 func (s *EtcdServer) IsMemberExist(id types.ID) bool {
 	return s.cluster.IsMemberExist(id)
@@ -2355,11 +2961,14 @@ func (s *EtcdServer) Version() *serverversion.Manager {
 	return serverversion.NewManager(s.Logger(), NewServerVersionAdapter(s))
 }
 
-func (s *EtcdServer) getTxPostLockInsideApplyHook() func() {
+func (s *EtcdServer) getTxPostLockInsideApplyHook(be backend.Backend) func() {
 	return func() {
 		applyingIdx, applyingTerm := s.consistIndex.ConsistentApplyingIndex()
 		if applyingIdx > s.consistIndex.UnsafeConsistentIndex() {
 			s.consistIndex.SetConsistentIndex(applyingIdx, applyingTerm)
 		}
+		if id, _ := s.applyingTraceID.Load().(string); id != "" {
+			be.UnsafeSetCommitTraceID(id)
+		}
 	}
 }
@@ -28,6 +28,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/coreos/go-semver/semver"
 	"github.com/stretchr/testify/assert"
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/membershippb"
@@ -641,6 +642,130 @@ func TestApplyConfChangeShouldStop(t *testing.T) {
 	}
 }
 
+// TestApplyConfChangeV2Joint ensures the members removed through joint
+// consensus are only removed once the joint configuration is left.
+func TestApplyConfChangeV2Joint(t *testing.T) {
+	cl := membership.NewCluster(zaptest.NewLogger(t))
+	cl.SetStore(v2store.New())
+	for i := 1; i <= 3; i++ {
+		cl.AddMember(&membership.Member{ID: types.ID(i), RaftAttributes: membership.RaftAttributes{PeerURLs: []string{fmt.Sprintf("http://127.0.0.1:%d", i)}}}, true)
+	}
+	cl.SetVersion(semver.New("3.6.0"), func(*zap.Logger, *semver.Version) {}, membership.ApplyBoth)
+	n := &nodeConfStateRecorder{nodeRecorder: *newNodeRecorder()}
+	r := newRaftNode(raftNodeConfig{
+		lg:        zaptest.NewLogger(t),
+		Node:      n,
+		transport: newNopTransporter(),
+	})
+	lg := zaptest.NewLogger(t)
+	srv := &EtcdServer{
+		lgMu:    new(sync.RWMutex),
+		lg:      lg,
+		id:      1,
+		r:       *r,
+		cluster: cl,
+		beHooks: serverstorage.NewBackendHooks(lg, nil),
+	}
+
+	replace := func(reqID uint64, add, remove uint64) raftpb.ConfChangeV2 {
+		m := membership.Member{ID: types.ID(add), RaftAttributes: membership.RaftAttributes{PeerURLs: []string{fmt.Sprintf("http://127.0.0.1:%d", add)}}}
+		b, err := json.Marshal(membership.ConfigChangeV2Context{ID: reqID, Members: []membership.Member{m}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raftpb.ConfChangeV2{
+			Changes: []raftpb.ConfChangeSingle{
+				{Type: raftpb.ConfChangeAddNode, NodeID: add},
+				{Type: raftpb.ConfChangeRemoveNode, NodeID: remove},
+			},
+			Context: b,
+		}
+	}
+	tests := []struct {
+		cc raftpb.ConfChangeV2
+		cs raftpb.ConfState
+
+		wRemovedSelf bool
+		wID          uint64
+		wMembers     []types.ID
+	}{
+		// replace 3 by 4, 3 still voting in the joint configuration
+		{replace(10, 4, 3), raftpb.ConfState{Voters: []uint64{1, 2, 4}, VotersOutgoing: []uint64{1, 2, 3}}, false, 0, []types.ID{1, 2, 3, 4}},
+		{raftpb.ConfChangeV2{}, raftpb.ConfState{Voters: []uint64{1, 2, 4}}, false, 10, []types.ID{1, 2, 4}},
+		// replace the local member 1 by 5
+		{replace(11, 5, 1), raftpb.ConfState{Voters: []uint64{2, 4, 5}, VotersOutgoing: []uint64{1, 2, 4}}, false, 0, []types.ID{1, 2, 4, 5}},
+		{raftpb.ConfChangeV2{}, raftpb.ConfState{Voters: []uint64{2, 4, 5}}, true, 11, []types.ID{2, 4, 5}},
+	}
+	confState := &raftpb.ConfState{Voters: []uint64{1, 2, 3}}
+	for i, tt := range tests {
+		n.cs = tt.cs
+		removedSelf, id, err := srv.applyConfChangeV2(tt.cc, confState, true)
+		if err != nil {
+			t.Fatalf("#%d: unexpected error %v", i, err)
+		}
+		if removedSelf != tt.wRemovedSelf {
+			t.Errorf("#%d: removedSelf = %t, want %t", i, removedSelf, tt.wRemovedSelf)
+		}
+		if id != tt.wID {
+			t.Errorf("#%d: id = %d, want %d", i, id, tt.wID)
+		}
+		if ids := cl.MemberIDs(); !reflect.DeepEqual(ids, tt.wMembers) {
+			t.Errorf("#%d: members = %v, want %v", i, ids, tt.wMembers)
+		}
+	}
+}
+
+// TestApplyConfChangeV2BeforeClusterVersion36 ensures a configuration change
+// of several members is rejected until the cluster is at 3.6.
+func TestApplyConfChangeV2BeforeClusterVersion36(t *testing.T) {
+	cl := membership.NewCluster(zaptest.NewLogger(t))
+	cl.SetStore(v2store.New())
+	for i := 1; i <= 3; i++ {
+		cl.AddMember(&membership.Member{ID: types.ID(i), RaftAttributes: membership.RaftAttributes{PeerURLs: []string{fmt.Sprintf("http://127.0.0.1:%d", i)}}}, true)
+	}
+	cl.SetVersion(semver.New("3.5.0"), func(*zap.Logger, *semver.Version) {}, membership.ApplyBoth)
+	n := &nodeConfStateRecorder{nodeRecorder: *newNodeRecorder()}
+	r := newRaftNode(raftNodeConfig{
+		lg:        zaptest.NewLogger(t),
+		Node:      n,
+		transport: newNopTransporter(),
+	})
+	lg := zaptest.NewLogger(t)
+	srv := &EtcdServer{
+		lgMu:    new(sync.RWMutex),
+		lg:      lg,
+		id:      1,
+		r:       *r,
+		cluster: cl,
+		beHooks: serverstorage.NewBackendHooks(lg, nil),
+	}
+
+	m := membership.Member{ID: 4, RaftAttributes: membership.RaftAttributes{PeerURLs: []string{"http://127.0.0.1:4"}}}
+	b, err := json.Marshal(membership.ConfigChangeV2Context{ID: 10, Members: []membership.Member{m}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := raftpb.ConfChangeV2{
+		Changes: []raftpb.ConfChangeSingle{
+			{Type: raftpb.ConfChangeAddNode, NodeID: 4},
+			{Type: raftpb.ConfChangeRemoveNode, NodeID: 3},
+		},
+		Context: b,
+	}
+	confState := &raftpb.ConfState{Voters: []uint64{1, 2, 3}}
+	n.cs = *confState
+	_, id, err := srv.applyConfChangeV2(cc, confState, true)
+	if err != ErrNotSupportedByCluster {
+		t.Errorf("err = %v, want %v", err, ErrNotSupportedByCluster)
+	}
+	if id != 10 {
+		t.Errorf("id = %d, want %d", id, 10)
+	}
+	if ids, wids := cl.MemberIDs(), []types.ID{1, 2, 3}; !reflect.DeepEqual(ids, wids) {
+		t.Errorf("members = %v, want %v", ids, wids)
+	}
+}
+
 // TestApplyConfigChangeUpdatesConsistIndex ensures a config change also updates the consistIndex
 // where consistIndex equals to applied index.
 func TestApplyConfigChangeUpdatesConsistIndex(t *testing.T) {
@@ -701,6 +826,36 @@ func TestApplyConfigChangeUpdatesConsistIndex(t *testing.T) {
 	assert.Equal(t, consistIndex, rindex)
 }
 
+// TestApplyWitnessSkipsKVRequests ensures that a witness does not apply the
+// KV requests but moves its consistent index forward.
+func TestApplyWitnessSkipsKVRequests(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+
+	be, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, be)
+	schema.CreateMetaBucket(be.BatchTx())
+
+	ci := cindex.NewConsistentIndex(be)
+	srv := &EtcdServer{
+		lgMu:         new(sync.RWMutex),
+		lg:           lg,
+		id:           1,
+		r:            *realisticRaftNode(lg),
+		Cfg:          config.ServerConfig{Witness: true},
+		w:            wait.New(),
+		consistIndex: ci,
+		beHooks:      serverstorage.NewBackendHooks(lg, ci),
+		// applyV3 is left nil, the witness applying none of the entries
+	}
+
+	req := &pb.InternalRaftRequest{Header: &pb.RequestHeader{ID: 1}, Put: &pb.PutRequest{Key: []byte("foo"), Value: []byte("bar")}}
+	ents := []raftpb.Entry{{Index: 2, Term: 4, Type: raftpb.EntryNormal, Data: pbutil.MustMarshal(req)}}
+
+	_, appliedi, _ := srv.apply(ents, &raftpb.ConfState{})
+	assert.Equal(t, uint64(2), appliedi)
+	assert.Equal(t, uint64(2), srv.consistIndex.ConsistentIndex())
+}
+
 func realisticRaftNode(lg *zap.Logger) *raftNode {
 	storage := raft.NewMemoryStorage()
 	storage.SetHardState(raftpb.HardState{Commit: 0, Term: 0})
@@ -1494,7 +1649,7 @@ func TestPublishV3(t *testing.T) {
 		w:          w,
 		reqIDGen:   idutil.NewGenerator(0, time.Time{}),
 		SyncTicker: &time.Ticker{},
-		authStore:  auth.NewAuthStore(lg, schema.NewAuthBackend(lg, be), nil, 0),
+		authStore:  auth.NewAuthStore(lg, schema.NewAuthBackend(lg, be), newTestTokenProvider(t), 0),
 		be:         be,
 		ctx:        ctx,
 		cancel:     cancel,
@@ -1565,7 +1720,7 @@ func TestPublishV3Retry(t *testing.T) {
 		cluster:    &membership.RaftCluster{},
 		reqIDGen:   idutil.NewGenerator(0, time.Time{}),
 		SyncTicker: &time.Ticker{},
-		authStore:  auth.NewAuthStore(lg, schema.NewAuthBackend(lg, be), nil, 0),
+		authStore:  auth.NewAuthStore(lg, schema.NewAuthBackend(lg, be), newTestTokenProvider(t), 0),
 		be:         be,
 		ctx:        ctx,
 		cancel:     cancel,
@@ -1829,6 +1984,18 @@ func (n *nodeConfChangeCommitterRecorder) ApplyConfChange(conf raftpb.ConfChange
 	return &raftpb.ConfState{}
 }
 
+// nodeConfStateRecorder returns cs as the configuration changes are applied.
+type nodeConfStateRecorder struct {
+	nodeRecorder
+	cs raftpb.ConfState
+}
+
+func (n *nodeConfStateRecorder) ApplyConfChange(conf raftpb.ConfChangeI) *raftpb.ConfState {
+	n.Record(testutil.Action{Name: "ApplyConfChange", Params: []interface{}{conf}})
+	cs := n.cs
+	return &cs
+}
+
 // nodeCommitter commits proposed data immediately.
 type nodeCommitter struct {
 	readyNode
@@ -1848,6 +2015,14 @@ func (n *nodeCommitter) Propose(ctx context.Context, data []byte) error {
 	return nil
 }
 
+func newTestTokenProvider(t testing.TB) auth.TokenProvider {
+	tp, err := auth.NewTokenProvider(zaptest.NewLogger(t), "", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tp
+}
+
 func newTestCluster(t testing.TB, membs []*membership.Member) *membership.RaftCluster {
 	c := membership.NewCluster(zaptest.NewLogger(t))
 	for _, m := range membs {
@@ -1973,3 +2148,315 @@ func TestWaitAppliedIndex(t *testing.T) {
 		})
 	}
 }
+
+func TestBoundedStalenessReadNotify(t *testing.T) {
+	cases := []struct {
+		name           string
+		lead           uint64
+		activePeers    []types.ID
+		appliedIndex   uint64
+		committedIndex uint64
+		// leaderCommittedIndex is the read index received from the leader
+		leaderCommittedIndex uint64
+		readIndexErr         error
+		// leaderHeardAgo is how long ago the member heard from the leader,
+		// never if 0
+		leaderHeardAgo time.Duration
+		window         time.Duration
+		maxStaleness   uint64
+		action         func(s *EtcdServer)
+		ExpectedError  error
+	}{
+		{
+			name:          "No leader",
+			ExpectedError: ErrNoLeader,
+		},
+		{
+			name:          "Not connected to the leader",
+			lead:          2,
+			ExpectedError: ErrNoLeader,
+		},
+		{
+			name:                 "The applied index is within the staleness",
+			lead:                 2,
+			activePeers:          []types.ID{2},
+			appliedIndex:         10,
+			committedIndex:       12,
+			leaderCommittedIndex: 12,
+			maxStaleness:         5,
+			ExpectedError:        nil,
+		},
+		{
+			name:           "The local member is the leader",
+			lead:           1,
+			appliedIndex:   12,
+			committedIndex: 12,
+			maxStaleness:   1,
+			ExpectedError:  nil,
+		},
+		{
+			name:                 "The applied index catches up",
+			lead:                 2,
+			activePeers:          []types.ID{2},
+			appliedIndex:         10,
+			committedIndex:       20,
+			leaderCommittedIndex: 20,
+			maxStaleness:         5,
+			action: func(s *EtcdServer) {
+				s.applyWait.Trigger(15)
+			},
+			ExpectedError: nil,
+		},
+		{
+			name:                 "Timed out waiting for the applied index",
+			lead:                 2,
+			activePeers:          []types.ID{2},
+			appliedIndex:         10,
+			committedIndex:       20,
+			leaderCommittedIndex: 20,
+			maxStaleness:         5,
+			ExpectedError:        context.DeadlineExceeded,
+		},
+		{
+			name:                 "A lagging learner waits for the commit index of the leader",
+			lead:                 2,
+			activePeers:          []types.ID{2},
+			appliedIndex:         10,
+			committedIndex:       10,
+			leaderCommittedIndex: 20,
+			maxStaleness:         5,
+			ExpectedError:        context.DeadlineExceeded,
+		},
+		{
+			name:                 "A lagging learner catches up with the commit index of the leader",
+			lead:                 2,
+			activePeers:          []types.ID{2},
+			appliedIndex:         10,
+			committedIndex:       10,
+			leaderCommittedIndex: 20,
+			maxStaleness:         5,
+			action: func(s *EtcdServer) {
+				s.applyWait.Trigger(15)
+			},
+			ExpectedError: nil,
+		},
+		{
+			name:           "The read index request fails",
+			lead:           2,
+			activePeers:    []types.ID{2},
+			appliedIndex:   10,
+			committedIndex: 10,
+			readIndexErr:   ErrTimeout,
+			maxStaleness:   5,
+			ExpectedError:  ErrTimeout,
+		},
+		{
+			name:           "The commit index was heard from the leader within the window",
+			lead:           2,
+			activePeers:    []types.ID{2},
+			appliedIndex:   10,
+			committedIndex: 12,
+			readIndexErr:   ErrTimeout,
+			leaderHeardAgo: 10 * time.Millisecond,
+			window:         time.Second,
+			maxStaleness:   5,
+			ExpectedError:  nil,
+		},
+		{
+			name:                 "The commit index was heard from the leader before the window",
+			lead:                 2,
+			activePeers:          []types.ID{2},
+			appliedIndex:         10,
+			committedIndex:       10,
+			leaderCommittedIndex: 20,
+			leaderHeardAgo:       2 * time.Second,
+			window:               time.Second,
+			maxStaleness:         5,
+			ExpectedError:        context.DeadlineExceeded,
+		},
+		{
+			name:           "The window is disabled",
+			lead:           2,
+			activePeers:    []types.ID{2},
+			appliedIndex:   10,
+			committedIndex: 10,
+			readIndexErr:   ErrTimeout,
+			leaderHeardAgo: 10 * time.Millisecond,
+			maxStaleness:   5,
+			ExpectedError:  ErrTimeout,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &EtcdServer{
+				id:             1,
+				lead:           tc.lead,
+				Cfg:            config.ServerConfig{BoundedStalenessWindow: tc.window},
+				r:              raftNode{raftNodeConfig: raftNodeConfig{transport: newNopTransporterWithActiveTime(tc.activePeers)}},
+				appliedIndex:   tc.appliedIndex,
+				committedIndex: tc.committedIndex,
+				applyWait:      wait.NewTimeList(),
+				readwaitc:      make(chan struct{}, 1),
+				readNotifier:   newNotifier(),
+				done:           make(chan struct{}),
+			}
+			defer close(s.done)
+			if tc.leaderHeardAgo != 0 {
+				s.setLeaderHeardAt(time.Now().Add(-tc.leaderHeardAgo))
+			}
+
+			// serve the read index request like the linearizable read loop
+			go func() {
+				select {
+				case <-s.readwaitc:
+				case <-s.done:
+					return
+				}
+				if tc.readIndexErr != nil {
+					s.readNotifier.notify(tc.readIndexErr)
+					return
+				}
+				s.readNotifier.notifyReadIndex(tc.leaderCommittedIndex)
+			}()
+			if tc.action != nil {
+				go tc.action(s)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			err := s.boundedStalenessReadNotify(ctx, tc.maxStaleness)
+
+			if err != tc.ExpectedError {
+				t.Errorf("Unexpected error, want (%v), got (%v)", tc.ExpectedError, err)
+			}
+		})
+	}
+}
+
+// TestRequestsBeforeClusterVersion36 ensures the requests added in 3.6 are
+// not proposed while the cluster version is unknown or below 3.6, as the
+// members before 3.6 cannot apply them.
+func TestRequestsBeforeClusterVersion36(t *testing.T) {
+	tests := []struct {
+		name string
+		req  func(s *EtcdServer) error
+		werr error
+	}{
+		{
+			name: "chunked put",
+			req: func(s *EtcdServer) error {
+				_, err := s.Put(context.Background(), &pb.PutRequest{Key: []byte("foo"), Value: make([]byte, 8192)})
+				return err
+			},
+			werr: ErrRequestTooLarge,
+		},
+		{
+			name: "put with a ttl",
+			req: func(s *EtcdServer) error {
+				_, err := s.Put(context.Background(), &pb.PutRequest{Key: []byte("foo"), Ttl: 10})
+				return err
+			},
+			werr: ErrNotSupportedByCluster,
+		},
+		{
+			name: "txn putting with a ttl",
+			req: func(s *EtcdServer) error {
+				put := &pb.RequestOp{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("foo"), Ttl: 10}}}
+				_, err := s.Txn(context.Background(), &pb.TxnRequest{Success: []*pb.RequestOp{put}})
+				return err
+			},
+			werr: ErrNotSupportedByCluster,
+		},
+		{
+			name: "move",
+			req: func(s *EtcdServer) error {
+				_, err := s.Move(context.Background(), &pb.MoveRequest{Key: []byte("foo"), Destination: []byte("bar")})
+				return err
+			},
+			werr: ErrNotSupportedByCluster,
+		},
+		{
+			name: "set lease",
+			req: func(s *EtcdServer) error {
+				_, err := s.SetLease(context.Background(), &pb.SetLeaseRequest{Key: []byte("foo")})
+				return err
+			},
+			werr: ErrNotSupportedByCluster,
+		},
+		{
+			name: "increment",
+			req: func(s *EtcdServer) error {
+				_, err := s.Increment(context.Background(), &pb.IncrementRequest{Key: []byte("foo"), Delta: 1})
+				return err
+			},
+			werr: ErrNotSupportedByCluster,
+		},
+		{
+			name: "put if absent",
+			req: func(s *EtcdServer) error {
+				_, err := s.PutIfAbsent(context.Background(), &pb.PutIfAbsentRequest{Key: []byte("foo")})
+				return err
+			},
+			werr: ErrNotSupportedByCluster,
+		},
+		{
+			name: "get and delete",
+			req: func(s *EtcdServer) error {
+				_, err := s.GetAndDelete(context.Background(), &pb.GetAndDeleteRequest{Key: []byte("foo")})
+				return err
+			},
+			werr: ErrNotSupportedByCluster,
+		},
+		{
+			name: "bulk write",
+			req: func(s *EtcdServer) error {
+				_, err := s.BulkWrite(context.Background(), &pb.BulkWriteRequest{})
+				return err
+			},
+			werr: ErrNotSupportedByCluster,
+		},
+		{
+			name: "lease grant bulk",
+			req: func(s *EtcdServer) error {
+				_, err := s.LeaseGrantBulk(context.Background(), &pb.LeaseGrantBulkRequest{Leases: []*pb.LeaseGrantRequest{{TTL: 10}}})
+				return err
+			},
+			werr: ErrNotSupportedByCluster,
+		},
+		{
+			name: "lease revoke bulk",
+			req: func(s *EtcdServer) error {
+				_, err := s.LeaseRevokeBulk(context.Background(), &pb.LeaseRevokeBulkRequest{IDs: []int64{1}})
+				return err
+			},
+			werr: ErrNotSupportedByCluster,
+		},
+		{
+			name: "replace members",
+			req: func(s *EtcdServer) error {
+				_, err := s.ReplaceMembers(context.Background(), []membership.Member{{ID: 2}}, []uint64{1})
+				return err
+			},
+			werr: ErrNotSupportedByCluster,
+		},
+	}
+	for _, v := range []*semver.Version{nil, semver.New("3.5.0")} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s at %v", tt.name, v), func(t *testing.T) {
+				cl := newTestCluster(t, nil)
+				if v != nil {
+					cl.SetVersion(v, func(*zap.Logger, *semver.Version) {}, membership.ApplyV2storeOnly)
+				}
+				s := &EtcdServer{
+					lgMu:    new(sync.RWMutex),
+					lg:      zaptest.NewLogger(t),
+					Cfg:     config.ServerConfig{MaxRequestBytes: 4096, MaxChunkedValueBytes: 16384},
+					cluster: cl,
+				}
+				if err := tt.req(s); err != tt.werr {
+					t.Errorf("err = %v, want %v", err, tt.werr)
+				}
+			})
+		}
+	}
+}
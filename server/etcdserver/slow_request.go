@@ -0,0 +1,116 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+
+	"go.uber.org/zap"
+)
+
+// logSlowRequest logs r with the time it spent in each phase of the
+// pipeline if it took longer than the slow request threshold.
+func (s *EtcdServer) logSlowRequest(r *pb.InternalRaftRequest, start time.Time, pt proposalTimings, err error) {
+	threshold := s.Cfg.SlowRequestThreshold
+	took := time.Since(start)
+	if threshold <= 0 || took <= threshold {
+		return
+	}
+	fields := []zap.Field{
+		zap.String("type", requestType(r)),
+		zap.Strings("key-ranges", requestKeyRanges(r)),
+		zap.Int("request-size", r.Size()),
+	}
+	if txn := r.Txn; txn != nil {
+		fields = append(fields,
+			zap.Int("txn-compares", len(txn.Compare)),
+			zap.Int("txn-success-ops", len(txn.Success)),
+			zap.Int("txn-failure-ops", len(txn.Failure)),
+		)
+	}
+	fields = append(fields,
+		zap.Duration("took", took),
+		zap.Duration("expected-duration", threshold),
+		zap.Duration("commit-wait", pt.commitWait),
+		zap.Duration("queue-wait", pt.queueWait),
+		zap.Duration("fsync-took", pt.fsync),
+		zap.Duration("apply-took", pt.apply),
+		zap.Error(err),
+	)
+	s.Logger().Warn("slow request", fields...)
+	slowRequests.Inc()
+}
+
+// requestType returns the name of the request set in r, such as "Put" or
+// "LeaseGrant".
+func requestType(r *pb.InternalRaftRequest) string {
+	v := reflect.ValueOf(r).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		name := v.Type().Field(i).Name
+		if name == "Header" || f.Kind() != reflect.Ptr || f.IsNil() {
+			continue
+		}
+		return name
+	}
+	return "Unknown"
+}
+
+// requestKeyRanges returns the keys and key ranges read or written by r.
+func requestKeyRanges(r *pb.InternalRaftRequest) []string {
+	switch {
+	case r.Range != nil:
+		return []string{keyRange(r.Range.Key, r.Range.RangeEnd)}
+	case r.Put != nil:
+		return []string{keyRange(r.Put.Key, nil)}
+	case r.DeleteRange != nil:
+		return []string{keyRange(r.DeleteRange.Key, r.DeleteRange.RangeEnd)}
+	case r.Txn != nil:
+		return txnKeyRanges(nil, r.Txn)
+	}
+	return nil
+}
+
+func txnKeyRanges(krs []string, txn *pb.TxnRequest) []string {
+	for _, c := range txn.Compare {
+		krs = append(krs, keyRange(c.Key, c.RangeEnd))
+	}
+	for _, ops := range [][]*pb.RequestOp{txn.Success, txn.Failure} {
+		for _, op := range ops {
+			switch req := op.Request.(type) {
+			case *pb.RequestOp_RequestRange:
+				krs = append(krs, keyRange(req.RequestRange.Key, req.RequestRange.RangeEnd))
+			case *pb.RequestOp_RequestPut:
+				krs = append(krs, keyRange(req.RequestPut.Key, nil))
+			case *pb.RequestOp_RequestDeleteRange:
+				krs = append(krs, keyRange(req.RequestDeleteRange.Key, req.RequestDeleteRange.RangeEnd))
+			case *pb.RequestOp_RequestTxn:
+				krs = txnKeyRanges(krs, req.RequestTxn)
+			}
+		}
+	}
+	return krs
+}
+
+func keyRange(key, end []byte) string {
+	if len(end) == 0 {
+		return string(key)
+	}
+	return fmt.Sprintf("[%s, %s)", key, end)
+}
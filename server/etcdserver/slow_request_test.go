@@ -0,0 +1,113 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRequestKeyRanges(t *testing.T) {
+	txn := &pb.TxnRequest{
+		Compare: []*pb.Compare{{Key: []byte("a")}},
+		Success: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("b")}}},
+			{Request: &pb.RequestOp_RequestTxn{RequestTxn: &pb.TxnRequest{
+				Success: []*pb.RequestOp{{Request: &pb.RequestOp_RequestRange{RequestRange: &pb.RangeRequest{Key: []byte("c"), RangeEnd: []byte("d")}}}},
+			}}},
+		},
+		Failure: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestDeleteRange{RequestDeleteRange: &pb.DeleteRangeRequest{Key: []byte("e")}}},
+		},
+	}
+	tests := []struct {
+		r        *pb.InternalRaftRequest
+		wantType string
+		want     []string
+	}{
+		{&pb.InternalRaftRequest{Range: &pb.RangeRequest{Key: []byte("foo"), RangeEnd: []byte("fop")}}, "Range", []string{"[foo, fop)"}},
+		{&pb.InternalRaftRequest{Put: &pb.PutRequest{Key: []byte("foo"), Value: []byte("bar")}}, "Put", []string{"foo"}},
+		{&pb.InternalRaftRequest{DeleteRange: &pb.DeleteRangeRequest{Key: []byte("foo")}}, "DeleteRange", []string{"foo"}},
+		{&pb.InternalRaftRequest{Txn: txn}, "Txn", []string{"a", "b", "[c, d)", "e"}},
+		{&pb.InternalRaftRequest{Header: &pb.RequestHeader{ID: 1}, LeaseGrant: &pb.LeaseGrantRequest{TTL: 5}}, "LeaseGrant", nil},
+	}
+	for _, tt := range tests {
+		if got := requestType(tt.r); got != tt.wantType {
+			t.Errorf("requestType(%v) = %q, want %q", tt.r, got, tt.wantType)
+		}
+		if got := requestKeyRanges(tt.r); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("requestKeyRanges(%v) = %v, want %v", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestLogSlowRequest(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	s := &EtcdServer{
+		lgMu: new(sync.RWMutex),
+		lg:   zap.New(core),
+		Cfg:  config.ServerConfig{SlowRequestThreshold: time.Second},
+	}
+	r := &pb.InternalRaftRequest{Txn: &pb.TxnRequest{
+		Compare: []*pb.Compare{{Key: []byte("a")}},
+		Success: []*pb.RequestOp{{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("a")}}}},
+	}}
+	pt := proposalTimings{commitWait: time.Millisecond, queueWait: 2 * time.Millisecond, fsync: 3 * time.Millisecond, apply: 4 * time.Millisecond}
+
+	s.logSlowRequest(r, time.Now(), pt, nil)
+	if logs.Len() != 0 {
+		t.Fatalf("expected no log for a fast request, got %d", logs.Len())
+	}
+
+	s.logSlowRequest(r, time.Now().Add(-2*time.Second), pt, nil)
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 slow request log, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	want := map[string]interface{}{
+		"type":            "Txn",
+		"txn-compares":    int64(1),
+		"txn-success-ops": int64(1),
+		"txn-failure-ops": int64(0),
+		"commit-wait":     time.Millisecond,
+		"queue-wait":      2 * time.Millisecond,
+		"fsync-took":      3 * time.Millisecond,
+		"apply-took":      4 * time.Millisecond,
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("field %q = %v, want %v", k, fields[k], v)
+		}
+	}
+	if krs, _ := fields["key-ranges"].([]interface{}); len(krs) != 2 {
+		t.Errorf("key-ranges = %v, want 2 key ranges", fields["key-ranges"])
+	}
+
+	s.Cfg.SlowRequestThreshold = 0
+	s.logSlowRequest(r, time.Now().Add(-2*time.Second), pt, nil)
+	if logs.Len() != 1 {
+		t.Errorf("expected no log with slow request logs disabled")
+	}
+}
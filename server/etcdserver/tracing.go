@@ -0,0 +1,304 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/pkg/v3/pbutil"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "go.etcd.io/etcd/server/v3/etcdserver"
+
+// proposalTracer traces the raft and apply pipeline of proposals sent by
+// sampled requests. The spans of each pipeline phase are children of the
+// span of the originating request. If it tracks timings, it also records the
+// duration of the pipeline phases of every proposal for slow request logs;
+// otherwise only of the proposals of requests with a RequestTrace.
+type proposalTracer struct {
+	// pending is the number of tracked proposals, so that the raft loop
+	// only decodes entries when it is non zero.
+	pending int64 // must use atomic operations to access; keep 64-bit aligned.
+
+	// tracer is nil if distributed tracing is disabled.
+	tracer  trace.Tracer
+	timings bool
+
+	mu        sync.Mutex
+	proposals map[uint64]*tracedProposal
+}
+
+type tracedProposal struct {
+	id  uint64
+	ctx context.Context
+	// span is nil if the request is not sampled.
+	span trace.Span
+
+	// proposed is when the proposal began. The fields below it are
+	// protected by proposalTracer.mu.
+	proposed   time.Time
+	committed  time.Time
+	fsync      time.Duration
+	applyStart time.Time
+	applyEnd   time.Time
+}
+
+// proposalTimings is the time spent by a request in each phase of the
+// pipeline, zero for the phases it did not reach.
+type proposalTimings struct {
+	// commitWait is the time from the proposal to the commit of the entry.
+	commitWait time.Duration
+	// queueWait is the time the committed entry waited to be applied, or
+	// the time a linearizable read waited for the read index.
+	queueWait time.Duration
+	// fsync is the time of the WAL fsync that persisted the entry.
+	fsync time.Duration
+	apply time.Duration
+}
+
+// syncTimer is implemented by storage reporting its last fsync.
+type syncTimer interface {
+	LastSync() (start time.Time, took time.Duration)
+}
+
+// newProposalTracer returns a tracer that only decodes the committed entries
+// while it tracks proposals.
+func newProposalTracer(tp trace.TracerProvider, timings bool) *proposalTracer {
+	t := &proposalTracer{
+		timings:   timings,
+		proposals: make(map[uint64]*tracedProposal),
+	}
+	if tp != nil {
+		t.tracer = tp.Tracer(tracerName)
+	}
+	return t
+}
+
+// tracing returns true if any proposal is tracked.
+func (t *proposalTracer) tracing() bool {
+	return t != nil && atomic.LoadInt64(&t.pending) > 0
+}
+
+// begin starts tracking the proposal of request id if ctx is sampled or
+// has a RequestTrace, or if timings are tracked. It returns nil if the
+// proposal is not tracked.
+func (t *proposalTracer) begin(ctx context.Context, id uint64) (context.Context, *tracedProposal) {
+	if t == nil {
+		return ctx, nil
+	}
+	sampled := t.tracer != nil && trace.SpanFromContext(ctx).SpanContext().IsSampled()
+	if !sampled && !t.timings && requestTraceFromContext(ctx) == nil {
+		return ctx, nil
+	}
+	p := &tracedProposal{id: id, proposed: time.Now()}
+	if sampled {
+		ctx, p.span = t.tracer.Start(ctx, "etcdserver.proposal", trace.WithAttributes(attribute.Int64("etcd.request_id", int64(id))))
+	}
+	p.ctx = ctx
+	t.mu.Lock()
+	t.proposals[id] = p
+	t.mu.Unlock()
+	atomic.AddInt64(&t.pending, 1)
+	return ctx, p
+}
+
+// end stops tracking p and ends its span with the result of the request.
+func (t *proposalTracer) end(p *tracedProposal, err error) {
+	if p == nil {
+		return
+	}
+	t.mu.Lock()
+	delete(t.proposals, p.id)
+	t.mu.Unlock()
+	atomic.AddInt64(&t.pending, -1)
+	if p.span == nil {
+		return
+	}
+	if err != nil {
+		p.span.RecordError(err)
+		p.span.SetStatus(codes.Error, err.Error())
+	}
+	p.span.End()
+}
+
+// timingsOf returns the timings of p.
+func (t *proposalTracer) timingsOf(p *tracedProposal) (pt proposalTimings) {
+	if p == nil {
+		return pt
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !p.committed.IsZero() {
+		pt.commitWait = p.committed.Sub(p.proposed)
+		if !p.applyStart.IsZero() {
+			pt.queueWait = p.applyStart.Sub(p.committed)
+		}
+	}
+	pt.fsync = p.fsync
+	if !p.applyEnd.IsZero() {
+		pt.apply = p.applyEnd.Sub(p.applyStart)
+	}
+	return pt
+}
+
+// spanContextOf returns the span context of the tracked proposal of r,
+// which is invalid if the request is not sampled.
+func (t *proposalTracer) spanContextOf(r *pb.InternalRaftRequest) trace.SpanContext {
+	if !t.tracing() {
+		return trace.SpanContext{}
+	}
+	id, ok := internalRequestID(r)
+	if !ok {
+		return trace.SpanContext{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if p, ok := t.proposals[id]; ok && p.span != nil {
+		return p.span.SpanContext()
+	}
+	return trace.SpanContext{}
+}
+
+// proposed records the hand-off of request id to raft.
+func (t *proposalTracer) proposed(id uint64, start, end time.Time) {
+	if !t.tracing() {
+		return
+	}
+	t.mu.Lock()
+	p, ok := t.proposals[id]
+	t.mu.Unlock()
+	if ok {
+		t.record(p, "raft.propose", start, end)
+	}
+}
+
+// appended records the raft log append of ents to storage, and the fsync
+// of the storage if it happened in the same time span.
+func (t *proposalTracer) appended(ents []raftpb.Entry, st interface{}, start, end time.Time, mustSync bool) {
+	ps := t.traced(ents)
+	if len(ps) == 0 {
+		return
+	}
+	var syncStart time.Time
+	var syncTook time.Duration
+	if s, ok := st.(syncTimer); ok && mustSync {
+		if syncStart, syncTook = s.LastSync(); syncStart.Before(start) {
+			syncStart = time.Time{}
+		}
+	}
+	for _, p := range ps {
+		t.record(p, "raft.append", start, end,
+			attribute.Int("raft.entries", len(ents)),
+			attribute.Bool("raft.must_sync", mustSync),
+		)
+		if !syncStart.IsZero() {
+			t.mu.Lock()
+			p.fsync = syncTook
+			t.mu.Unlock()
+			t.record(p, "wal.fsync", syncStart, syncStart.Add(syncTook))
+		}
+	}
+}
+
+// committed records the replication of ents to a quorum, from the time
+// they were proposed until they were committed.
+func (t *proposalTracer) committed(ents []raftpb.Entry) {
+	now := time.Now()
+	for _, p := range t.traced(ents) {
+		t.mu.Lock()
+		p.committed = now
+		t.mu.Unlock()
+		t.record(p, "raft.commit", p.proposed, now)
+	}
+}
+
+// applied records the apply of request id to the backend.
+func (t *proposalTracer) applied(id uint64, start, end time.Time) {
+	if !t.tracing() {
+		return
+	}
+	t.mu.Lock()
+	p, ok := t.proposals[id]
+	if ok {
+		p.applyStart, p.applyEnd = start, end
+	}
+	t.mu.Unlock()
+	if ok {
+		t.record(p, "etcdserver.apply", start, end)
+	}
+}
+
+// traced returns the tracked proposals of ents.
+func (t *proposalTracer) traced(ents []raftpb.Entry) []*tracedProposal {
+	if !t.tracing() {
+		return nil
+	}
+	var ps []*tracedProposal
+	for i := range ents {
+		id, ok := entryRequestID(&ents[i])
+		if !ok {
+			continue
+		}
+		t.mu.Lock()
+		p, ok := t.proposals[id]
+		t.mu.Unlock()
+		if ok {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+func (t *proposalTracer) record(p *tracedProposal, name string, start, end time.Time, attrs ...attribute.KeyValue) {
+	if p.span == nil {
+		return
+	}
+	_, span := t.tracer.Start(p.ctx, name, trace.WithTimestamp(start), trace.WithAttributes(attrs...))
+	span.End(trace.WithTimestamp(end))
+}
+
+// entryRequestID returns the ID of the request proposed in e, the same way
+// as applyEntryNormal.
+func entryRequestID(e *raftpb.Entry) (uint64, bool) {
+	if e.Type != raftpb.EntryNormal || len(e.Data) == 0 {
+		return 0, false
+	}
+	var r pb.InternalRaftRequest
+	if !pbutil.MaybeUnmarshal(&r, e.Data) {
+		return 0, false
+	}
+	return internalRequestID(&r)
+}
+
+// internalRequestID returns the ID of r, which is in its header for the
+// requests of the v3 API.
+func internalRequestID(r *pb.InternalRaftRequest) (uint64, bool) {
+	if r.ID != 0 {
+		return r.ID, true
+	}
+	if r.Header == nil {
+		return 0, false
+	}
+	return r.Header.ID, true
+}
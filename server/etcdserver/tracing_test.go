@@ -0,0 +1,231 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/pkg/v3/pbutil"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type fakeSyncTimer struct {
+	start time.Time
+	took  time.Duration
+}
+
+func (s fakeSyncTimer) LastSync() (time.Time, time.Duration) { return s.start, s.took }
+
+func TestProposalTracer(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(sr))
+	pt := newProposalTracer(tp, false)
+
+	const id = 42
+	ents := []raftpb.Entry{
+		{Type: raftpb.EntryNormal, Data: pbutil.MustMarshal(&pb.InternalRaftRequest{Header: &pb.RequestHeader{ID: id}})},
+		{Type: raftpb.EntryNormal, Data: pbutil.MustMarshal(&pb.InternalRaftRequest{Header: &pb.RequestHeader{ID: id + 1}})},
+	}
+
+	// unsampled requests are not traced
+	if _, p := pt.begin(context.Background(), id); p != nil || pt.tracing() {
+		t.Fatal("expected unsampled request not to be traced")
+	}
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "rpc")
+	_, p := pt.begin(ctx, id)
+	if !pt.tracing() {
+		t.Fatal("expected sampled request to be traced")
+	}
+
+	start := time.Now()
+	pt.proposed(id, start, start.Add(time.Millisecond))
+	pt.appended(ents, fakeSyncTimer{start: start.Add(2 * time.Millisecond), took: time.Millisecond}, start.Add(time.Millisecond), start.Add(4*time.Millisecond), true)
+	pt.committed(ents)
+	pt.applied(id, start.Add(5*time.Millisecond), start.Add(6*time.Millisecond))
+	pt.end(p, nil)
+	parent.End()
+
+	if pt.tracing() {
+		t.Fatal("expected no traced request after the proposal ended")
+	}
+
+	spans := sr.Ended()
+	var proposal tracesdk.ReadOnlySpan
+	children := make(map[string]tracesdk.ReadOnlySpan)
+	for _, s := range spans {
+		switch s.Name() {
+		case "rpc":
+		case "etcdserver.proposal":
+			proposal = s
+		default:
+			children[s.Name()] = s
+		}
+	}
+	if proposal == nil {
+		t.Fatalf("expected a proposal span, got %d spans", len(spans))
+	}
+	if proposal.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("expected proposal span to be a child of the request span")
+	}
+	for _, name := range []string{"raft.propose", "raft.append", "wal.fsync", "raft.commit", "etcdserver.apply"} {
+		s, ok := children[name]
+		if !ok {
+			t.Errorf("expected span %q", name)
+			continue
+		}
+		if s.Parent().SpanID() != proposal.SpanContext().SpanID() {
+			t.Errorf("expected span %q to be a child of the proposal span", name)
+		}
+	}
+	if len(children) != 5 {
+		t.Errorf("expected 5 pipeline spans, got %d", len(children))
+	}
+}
+
+func TestProposalTracerTimings(t *testing.T) {
+	pt := newProposalTracer(nil, true)
+	ents := []raftpb.Entry{
+		{Type: raftpb.EntryNormal, Data: pbutil.MustMarshal(&pb.InternalRaftRequest{Header: &pb.RequestHeader{ID: 1}})},
+	}
+
+	_, p := pt.begin(context.Background(), 1)
+	if p == nil {
+		t.Fatal("expected request to be tracked")
+	}
+	start := time.Now()
+	pt.proposed(1, start, start.Add(time.Millisecond))
+	pt.appended(ents, fakeSyncTimer{start: start.Add(2 * time.Millisecond), took: 3 * time.Millisecond}, start.Add(time.Millisecond), start.Add(6*time.Millisecond), true)
+	pt.committed(ents)
+	pt.mu.Lock()
+	committed := p.committed
+	pt.mu.Unlock()
+	pt.applied(1, committed.Add(5*time.Millisecond), committed.Add(12*time.Millisecond))
+	pt.end(p, nil)
+
+	got := pt.timingsOf(p)
+	if want := committed.Sub(p.proposed); got.commitWait != want {
+		t.Errorf("commit wait = %v, want %v", got.commitWait, want)
+	}
+	if got.queueWait != 5*time.Millisecond {
+		t.Errorf("queue wait = %v, want 5ms", got.queueWait)
+	}
+	if got.fsync != 3*time.Millisecond {
+		t.Errorf("fsync = %v, want 3ms", got.fsync)
+	}
+	if got.apply != 7*time.Millisecond {
+		t.Errorf("apply = %v, want 7ms", got.apply)
+	}
+	if pt.tracing() {
+		t.Fatal("expected no tracked request after the proposal ended")
+	}
+}
+
+func TestProposalTracerDisabled(t *testing.T) {
+	var pt *proposalTracer
+	ctx := context.Background()
+	got, p := pt.begin(ctx, 1)
+	if got != ctx || p != nil {
+		t.Errorf("expected nil tracer not to track the request")
+	}
+	// must not panic
+	pt.end(p, nil)
+	pt.proposed(1, time.Now(), time.Now())
+	pt.appended(nil, nil, time.Now(), time.Now(), true)
+	pt.committed(nil)
+	pt.applied(1, time.Now(), time.Now())
+}
+
+func TestProposalTracerRequestTrace(t *testing.T) {
+	pt := newProposalTracer(nil, false)
+	if _, p := pt.begin(context.Background(), 1); p != nil {
+		t.Fatal("expected request not to be tracked without timings")
+	}
+
+	ctx, rt := WithRequestTrace(context.Background())
+	_, p := pt.begin(ctx, 2)
+	if p == nil {
+		t.Fatal("expected request with a request trace to be tracked")
+	}
+	pt.end(p, nil)
+
+	// the phases of a request accumulate
+	requestTraceFromContext(ctx).record(proposalTimings{commitWait: time.Millisecond, apply: 2 * time.Millisecond})
+	requestTraceFromContext(ctx).record(proposalTimings{queueWait: 3 * time.Millisecond, fsync: 4 * time.Millisecond, apply: time.Millisecond})
+	got := rt.Proto(10 * time.Millisecond)
+	want := &pb.RequestTrace{
+		TotalNs: int64(10 * time.Millisecond),
+		RaftNs:  int64(time.Millisecond),
+		QueueNs: int64(3 * time.Millisecond),
+		FsyncNs: int64(4 * time.Millisecond),
+		ApplyNs: int64(3 * time.Millisecond),
+	}
+	if got.String() != want.String() {
+		t.Errorf("trace = %v, want %v", got, want)
+	}
+
+	// must not panic
+	requestTraceFromContext(context.Background()).record(proposalTimings{})
+}
+
+func TestProposalTracerSpanContext(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(sr))
+	pt := newProposalTracer(tp, true)
+	r := &pb.InternalRaftRequest{Header: &pb.RequestHeader{ID: 1}}
+
+	_, p := pt.begin(context.Background(), 1)
+	if pt.spanContextOf(r).IsValid() {
+		t.Error("expected no span context of an unsampled request")
+	}
+	pt.end(p, nil)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "rpc")
+	defer parent.End()
+	_, p = pt.begin(ctx, 1)
+	sc := pt.spanContextOf(r)
+	if sc.TraceID() != parent.SpanContext().TraceID() {
+		t.Errorf("expected trace ID %s, got %s", parent.SpanContext().TraceID(), sc.TraceID())
+	}
+
+	h := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test"})
+	observeWithTrace(h, 1, sc)
+	var m dto.Metric
+	if err := h.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	var exemplars []string
+	for _, b := range m.GetHistogram().GetBucket() {
+		for _, l := range b.GetExemplar().GetLabel() {
+			exemplars = append(exemplars, l.GetName()+"="+l.GetValue())
+		}
+	}
+	if want := "trace_id=" + sc.TraceID().String(); len(exemplars) != 1 || exemplars[0] != want {
+		t.Errorf("expected exemplar %s, got %v", want, exemplars)
+	}
+
+	pt.end(p, nil)
+	if pt.spanContextOf(r).IsValid() {
+		t.Error("expected no span context after the proposal ended")
+	}
+}
@@ -17,12 +17,15 @@ package etcdserver
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/golang/protobuf/proto"
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/raft/v3"
+	"go.etcd.io/etcd/raft/v3/raftpb"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/rafthttp"
 
@@ -87,17 +90,103 @@ func longestConnected(tp rafthttp.Transporter, membs []types.ID) (types.ID, bool
 	return longest, true
 }
 
+// preferredConnected chooses the member the most preferred to be the leader,
+// if preferred to the leader, among the active members whose log matches the
+// leader's commit index. It returns false, if there is none.
+func preferredConnected(tp rafthttp.Transporter, lead *membership.Member, membs []*membership.Member, st raft.Status, zones []string) (types.ID, bool) {
+	preferred := lead
+	for _, m := range membs {
+		if m.ID == lead.ID || !preferredLeader(m, preferred, zones) {
+			continue
+		}
+		if tp.ActiveSince(m.ID).IsZero() {
+			continue
+		}
+		if pr, ok := st.Progress[uint64(m.ID)]; !ok || pr.Match < st.Commit {
+			continue
+		}
+		preferred = m
+	}
+	return preferred.ID, preferred != lead
+}
+
+// preferredLeader returns if a is preferred to b to be the leader: a is in a
+// zone listed before the zone of b in zones, the members out of zones coming
+// last, or a is in the same zone and has a higher election priority.
+func preferredLeader(a, b *membership.Member, zones []string) bool {
+	if ra, rb := zoneRank(a, zones), zoneRank(b, zones); ra != rb {
+		return ra < rb
+	}
+	return a.ElectionPriority > b.ElectionPriority
+}
+
+func zoneRank(m *membership.Member, zones []string) int {
+	for i, z := range zones {
+		if m.Zone() == z {
+			return i
+		}
+	}
+	return len(zones)
+}
+
+// followerFlowControl returns the flow control of the append messages sent to
+// the followers from the raft status of the leader self, nil if not leader.
+func followerFlowControl(self types.ID, st raft.Status) []*pb.FollowerFlowControl {
+	if st.RaftState != raft.StateLeader {
+		return nil
+	}
+	var fs []*pb.FollowerFlowControl
+	for id, pr := range st.Progress {
+		if types.ID(id) == self {
+			continue
+		}
+		fs = append(fs, &pb.FollowerFlowControl{
+			ID:               id,
+			InflightMessages: uint64(pr.Inflights.Count()),
+			InflightBytes:    pr.Inflights.Bytes(),
+			Window:           uint64(pr.Inflights.Window()),
+			Throttled:        pr.IsPaused(),
+		})
+	}
+	sort.Slice(fs, func(i, j int) bool { return fs[i].ID < fs[j].ID })
+	return fs
+}
+
+// inConfState returns if the node is a voter or a learner of the
+// configuration, joint or not.
+func inConfState(cs raftpb.ConfState, id uint64) bool {
+	for _, ids := range [][]uint64{cs.Voters, cs.VotersOutgoing, cs.Learners, cs.LearnersNext} {
+		for _, n := range ids {
+			if n == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 type notifier struct {
 	c   chan struct{}
 	err error
+
+	// readIndexc is closed once readIndex, the commit index of the leader
+	// confirmed by the read index request, is received.
+	readIndexc chan struct{}
+	readIndex  uint64
 }
 
 func newNotifier() *notifier {
 	return &notifier{
-		c: make(chan struct{}),
+		c:          make(chan struct{}),
+		readIndexc: make(chan struct{}),
 	}
 }
 
+func (nc *notifier) notifyReadIndex(index uint64) {
+	nc.readIndex = index
+	close(nc.readIndexc)
+}
+
 func (nc *notifier) notify(err error) {
 	nc.err = err
 	close(nc.c)
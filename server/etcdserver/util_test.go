@@ -16,13 +16,17 @@ package etcdserver
 
 import (
 	"net/http"
+	"reflect"
 	"testing"
 	"time"
 
 	"go.uber.org/zap/zaptest"
 
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/raft/v3"
 	"go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/raft/v3/tracker"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/rafthttp"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/snap"
@@ -61,6 +65,112 @@ func TestLongestConnected(t *testing.T) {
 	}
 }
 
+func TestPreferredConnectedPriority(t *testing.T) {
+	membs := []*membership.Member{
+		{ID: 1, Attributes: membership.Attributes{ElectionPriority: 1}},
+		{ID: 2, Attributes: membership.Attributes{ElectionPriority: 3}},
+		{ID: 3, Attributes: membership.Attributes{ElectionPriority: 2}},
+		{ID: 4, Attributes: membership.Attributes{ElectionPriority: 5}},
+	}
+	// member 4 is not active
+	tr := newNopTransporterWithActiveTime([]types.ID{1, 2, 3})
+	st := raft.Status{
+		BasicStatus: raft.BasicStatus{HardState: raftpb.HardState{Commit: 10}},
+		Progress: map[uint64]tracker.Progress{
+			1: {Match: 10},
+			2: {Match: 8},
+			3: {Match: 10},
+			4: {Match: 10},
+		},
+	}
+
+	// member 2 is behind the commit index
+	transferee, ok := preferredConnected(tr, membs[0], membs, st, nil)
+	if !ok || transferee != 3 {
+		t.Fatalf("expected member 3 to be transferee, got %s (ok %v)", transferee, ok)
+	}
+
+	st.Progress[2] = tracker.Progress{Match: 10}
+	transferee, ok = preferredConnected(tr, membs[0], membs, st, nil)
+	if !ok || transferee != 2 {
+		t.Fatalf("expected member 2 to be transferee, got %s (ok %v)", transferee, ok)
+	}
+
+	// the leader has the highest priority of the active members
+	if transferee, ok = preferredConnected(tr, membs[1], membs, st, nil); ok {
+		t.Fatalf("unexpected transferee %s", transferee)
+	}
+}
+
+func TestPreferredConnectedZones(t *testing.T) {
+	zone := func(z string) map[string]string { return map[string]string{membership.ZoneLabel: z} }
+	membs := []*membership.Member{
+		{ID: 1, Attributes: membership.Attributes{Labels: zone("c"), ElectionPriority: 9}},
+		{ID: 2, Attributes: membership.Attributes{Labels: zone("b")}},
+		{ID: 3, Attributes: membership.Attributes{Labels: zone("b"), ElectionPriority: 1}},
+		{ID: 4, Attributes: membership.Attributes{ElectionPriority: 5}},
+	}
+	tr := newNopTransporterWithActiveTime([]types.ID{1, 2, 3, 4})
+	st := raft.Status{
+		BasicStatus: raft.BasicStatus{HardState: raftpb.HardState{Commit: 10}},
+		Progress: map[uint64]tracker.Progress{
+			1: {Match: 10},
+			2: {Match: 10},
+			3: {Match: 10},
+			4: {Match: 10},
+		},
+	}
+
+	tests := []struct {
+		lead  int
+		zones []string
+		want  types.ID
+	}{
+		// the zone comes before the election priority
+		{lead: 0, zones: []string{"a", "b", "c"}, want: 3},
+		{lead: 1, zones: []string{"a", "b", "c"}, want: 3},
+		{lead: 2, zones: []string{"a", "b", "c"}},
+		// the members out of the zones listed come last
+		{lead: 3, zones: []string{"c"}, want: 1},
+		{lead: 0, zones: []string{"c"}},
+		// without zones, the election priority decides
+		{lead: 3, want: 1},
+	}
+	for i, tt := range tests {
+		transferee, ok := preferredConnected(tr, membs[tt.lead], membs, st, tt.zones)
+		if ok != (tt.want != 0) || (ok && transferee != tt.want) {
+			t.Errorf("#%d: transferee = %s (ok %v), want %s", i, transferee, ok, tt.want)
+		}
+	}
+}
+
+func TestFollowerFlowControl(t *testing.T) {
+	in := tracker.NewInflightsWithBytes(4, 0)
+	in.Throttle()
+	in.AddWithBytes(5, 100)
+	in.AddWithBytes(6, 200)
+	st := raft.Status{
+		BasicStatus: raft.BasicStatus{SoftState: raft.SoftState{RaftState: raft.StateLeader}},
+		Progress: map[uint64]tracker.Progress{
+			1: {State: tracker.StateReplicate, Match: 6, Inflights: tracker.NewInflights(4)},
+			3: {State: tracker.StateReplicate, Match: 4, Inflights: in},
+			2: {State: tracker.StateProbe, Match: 6, Inflights: tracker.NewInflights(4)},
+		},
+	}
+	want := []*pb.FollowerFlowControl{
+		{ID: 2, Window: 4},
+		{ID: 3, InflightMessages: 2, InflightBytes: 300, Window: 2, Throttled: true},
+	}
+	if fs := followerFlowControl(1, st); !reflect.DeepEqual(fs, want) {
+		t.Fatalf("flow control = %v, want %v", fs, want)
+	}
+
+	st.RaftState = raft.StateFollower
+	if fs := followerFlowControl(1, st); fs != nil {
+		t.Fatalf("unexpected flow control %v on a follower", fs)
+	}
+}
+
 type nopTransporterWithActiveTime struct {
 	activeMap map[types.ID]time.Time
 }
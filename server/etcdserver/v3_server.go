@@ -19,11 +19,16 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/binary"
+	"net"
+	"sort"
 	"strconv"
+	"sync"
 	"time"
 
+	"go.etcd.io/etcd/api/v3/authpb"
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/version"
+	"go.etcd.io/etcd/client/pkg/v3/types"
 	"go.etcd.io/etcd/pkg/v3/traceutil"
 	"go.etcd.io/etcd/raft/v3"
 	"go.etcd.io/etcd/server/v3/auth"
@@ -32,9 +37,10 @@ import (
 	"go.etcd.io/etcd/server/v3/lease/leasehttp"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
 
+	"github.com/coreos/go-semver/semver"
 	"github.com/gogo/protobuf/proto"
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/peer"
 )
 
 const (
@@ -42,13 +48,21 @@ const (
 	// the applied index and committed index.
 	// However, if the committed entries are very heavy to apply, the gap might grow.
 	// We should stop accepting new proposals if the gap growing to a certain point.
-	maxGapBetweenApplyAndCommitIndex = 5000
+	MaxGapBetweenApplyAndCommitIndex = 5000
 	traceThreshold                   = 100 * time.Millisecond
 	readIndexRetryTime               = 500 * time.Millisecond
 
 	// The timeout for the node to catch up its applied index, and is used in
 	// lease related operations, such as LeaseRenew and LeaseTimeToLive.
 	applyTimeout = time.Second
+
+	// putChunkOverheadBytes bounds the size of the proposal of a chunk of a
+	// value, besides the chunk.
+	putChunkOverheadBytes = 1024
+	// putChunkTTL is how long the chunks of a value are staged before they
+	// can be deleted, if the put of the value was neither applied nor
+	// discarded, for instance because the member proposing it crashed.
+	putChunkTTL = 10 * time.Minute
 )
 
 type RaftKV interface {
@@ -56,6 +70,12 @@ type RaftKV interface {
 	Put(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse, error)
 	DeleteRange(ctx context.Context, r *pb.DeleteRangeRequest) (*pb.DeleteRangeResponse, error)
 	Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnResponse, error)
+	Move(ctx context.Context, r *pb.MoveRequest) (*pb.MoveResponse, error)
+	SetLease(ctx context.Context, r *pb.SetLeaseRequest) (*pb.SetLeaseResponse, error)
+	Increment(ctx context.Context, r *pb.IncrementRequest) (*pb.IncrementResponse, error)
+	PutIfAbsent(ctx context.Context, r *pb.PutIfAbsentRequest) (*pb.PutIfAbsentResponse, error)
+	GetAndDelete(ctx context.Context, r *pb.GetAndDeleteRequest) (*pb.GetAndDeleteResponse, error)
+	BulkWrite(ctx context.Context, r *pb.BulkWriteRequest) (*pb.BulkWriteResponse, error)
 	Compact(ctx context.Context, r *pb.CompactionRequest) (*pb.CompactionResponse, error)
 }
 
@@ -65,10 +85,27 @@ type Lessor interface {
 	// LeaseRevoke sends LeaseRevoke request to raft and apply it after committed.
 	LeaseRevoke(ctx context.Context, r *pb.LeaseRevokeRequest) (*pb.LeaseRevokeResponse, error)
 
+	// LeaseGrantBulk sends a LeaseGrantBulk request granting many leases to raft
+	// and apply it after committed.
+	LeaseGrantBulk(ctx context.Context, r *pb.LeaseGrantBulkRequest) (*pb.LeaseGrantBulkResponse, error)
+
+	// LeaseRevokeBulk sends a LeaseRevokeBulk request revoking many leases to raft
+	// and apply it after committed.
+	LeaseRevokeBulk(ctx context.Context, r *pb.LeaseRevokeBulkRequest) (*pb.LeaseRevokeBulkResponse, error)
+
 	// LeaseRenew renews the lease with given ID. The renewed TTL is returned. Or an error
 	// is returned.
 	LeaseRenew(ctx context.Context, id lease.LeaseID) (int64, error)
 
+	// LeaseRenewBatch renews the leases with given IDs. One result is returned per
+	// ID, in order. Leases that do not exist carry lease.ErrLeaseNotFound.
+	LeaseRenewBatch(ctx context.Context, ids []lease.LeaseID) ([]lease.RenewResult, error)
+
+	// LeaseKeepAliveOnce renews the lease with given ID like LeaseRenew, for a keep
+	// alive request sent alone. With auth enabled, the request must be authenticated,
+	// by the user owning the lease if any or by an admin.
+	LeaseKeepAliveOnce(ctx context.Context, id lease.LeaseID) (int64, error)
+
 	// LeaseTimeToLive retrieves lease information.
 	LeaseTimeToLive(ctx context.Context, r *pb.LeaseTimeToLiveRequest) (*pb.LeaseTimeToLiveResponse, error)
 
@@ -94,6 +131,10 @@ type Authenticator interface {
 	RoleDelete(ctx context.Context, r *pb.AuthRoleDeleteRequest) (*pb.AuthRoleDeleteResponse, error)
 	UserList(ctx context.Context, r *pb.AuthUserListRequest) (*pb.AuthUserListResponse, error)
 	RoleList(ctx context.Context, r *pb.AuthRoleListRequest) (*pb.AuthRoleListResponse, error)
+	AuthLockoutList(ctx context.Context, r *pb.AuthLockoutListRequest) (*pb.AuthLockoutListResponse, error)
+	AuthLockoutClear(ctx context.Context, r *pb.AuthLockoutClearRequest) (*pb.AuthLockoutClearResponse, error)
+	AuthSessionList(ctx context.Context, r *pb.AuthSessionListRequest) (*pb.AuthSessionListResponse, error)
+	AuthSessionRevoke(ctx context.Context, r *pb.AuthSessionRevokeRequest) (*pb.AuthSessionRevokeResponse, error)
 }
 
 func (s *EtcdServer) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeResponse, error) {
@@ -106,7 +147,12 @@ func (s *EtcdServer) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeRe
 
 	var resp *pb.RangeResponse
 	var err error
+	var pt proposalTimings
 	defer func(start time.Time) {
+		if s.Cfg.SlowRequestThreshold > 0 {
+			s.logSlowRequest(&pb.InternalRaftRequest{Range: r}, start, pt, err)
+		}
+		requestTraceFromContext(ctx).record(pt)
 		warnOfExpensiveReadOnlyRangeRequest(s.Logger(), s.Cfg.WarningApplyDuration, start, r, resp, err)
 		if resp != nil {
 			trace.AddField(
@@ -118,17 +164,31 @@ func (s *EtcdServer) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeRe
 	}(time.Now())
 
 	if !r.Serializable {
+		readStart := time.Now()
 		err = s.linearizableReadNotify(ctx)
+		pt.queueWait = time.Since(readStart)
 		trace.Step("agreement among raft nodes before linearized reading")
 		if err != nil {
 			return nil, err
 		}
+	} else if r.MaxStaleness != 0 {
+		readStart := time.Now()
+		err = s.boundedStalenessReadNotify(ctx, r.MaxStaleness)
+		pt.queueWait = time.Since(readStart)
+		trace.Step("applied entries committed by the leader before bounded staleness reading")
+		if err != nil {
+			return nil, err
+		}
 	}
 	chk := func(ai *auth.AuthInfo) error {
 		return s.authStore.IsRangePermitted(ai, r.Key, r.RangeEnd)
 	}
 
-	get := func() { resp, err = s.applyV3Base.Range(ctx, nil, r) }
+	get := func() {
+		applyStart := time.Now()
+		resp, err = s.applyV3Base.Range(ctx, nil, r)
+		pt.apply = time.Since(applyStart)
+	}
 	if serr := s.doSerialize(ctx, chk, get); serr != nil {
 		err = serr
 		return nil, err
@@ -137,6 +197,9 @@ func (s *EtcdServer) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeRe
 }
 
 func (s *EtcdServer) Put(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse, error) {
+	if s.Cfg.MaxChunkedValueBytes > 0 && r.Size()+putChunkOverheadBytes > int(s.Cfg.MaxRequestBytes) {
+		return s.putChunked(ctx, r)
+	}
 	ctx = context.WithValue(ctx, traceutil.StartTimeKey, time.Now())
 	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{Put: r})
 	if err != nil {
@@ -145,6 +208,85 @@ func (s *EtcdServer) Put(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse
 	return resp.(*pb.PutResponse), nil
 }
 
+// putChunked proposes the value of a put too large for a single proposal in
+// chunks staged in the backend, then the put of the value they assemble.
+// The chunks are discarded if the put fails, or deleted by the leader once
+// expired if the discard is lost.
+func (s *EtcdServer) putChunked(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse, error) {
+	// members before 3.6 cannot apply the chunks
+	if !s.isClusterVersion36() {
+		return nil, ErrRequestTooLarge
+	}
+	if len(r.Value) > int(s.Cfg.MaxChunkedValueBytes) {
+		return nil, ErrRequestTooLarge
+	}
+	chunkSize := int(s.Cfg.MaxRequestBytes) - putChunkOverheadBytes
+	if chunkSize <= 0 || len(r.Key)+putChunkOverheadBytes > int(s.Cfg.MaxRequestBytes) {
+		return nil, ErrRequestTooLarge
+	}
+	// fail before staging the chunks of a put that cannot be applied
+	ai, err := s.AuthInfoFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ai == nil {
+		ai = &auth.AuthInfo{}
+	}
+	if err = s.AuthStore().IsPutPermitted(ai, r.Key); err != nil {
+		return nil, err
+	}
+	if !s.prefixQuotas.Available(r) {
+		return nil, ErrPrefixQuotaExceeded
+	}
+
+	// leave a margin for the clock skew with the leader deleting the
+	// chunks once expired
+	expireTime := time.Now().Add(putChunkTTL).Unix()
+	ctx, cancel := context.WithTimeout(ctx, putChunkTTL/2)
+	defer cancel()
+
+	id := s.reqIDGen.Next()
+	var chunks int64
+	for off := 0; off < len(r.Value); off += chunkSize {
+		end := off + chunkSize
+		if end > len(r.Value) {
+			end = len(r.Value)
+		}
+		_, err = s.raftRequest(ctx, pb.InternalRaftRequest{PutChunk: &pb.PutChunkRequest{UploadId: id, Index: chunks, Data: r.Value[off:end], ExpireTime: expireTime}})
+		if err != nil {
+			break
+		}
+		chunks++
+	}
+	if err != nil {
+		s.discardChunks(id)
+		return nil, err
+	}
+
+	put := *r
+	put.Value = nil
+	ctx = context.WithValue(ctx, traceutil.StartTimeKey, time.Now())
+	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{PutChunked: &pb.PutChunkedRequest{UploadId: id, Chunks: chunks, Put: &put}})
+	if err != nil {
+		// proposed after the put, the discard does nothing if it is applied
+		s.discardChunks(id)
+		return nil, err
+	}
+	return resp.(*pb.PutResponse), nil
+}
+
+// discardChunks deletes the chunks staged for the upload id in the
+// background, since the request proposing them may have been canceled.
+func (s *EtcdServer) discardChunks(id uint64) {
+	s.GoAttach(func() {
+		ctx, cancel := context.WithTimeout(s.ctx, s.Cfg.ReqTimeout())
+		defer cancel()
+		if _, err := s.raftRequest(ctx, pb.InternalRaftRequest{PutChunked: &pb.PutChunkedRequest{UploadId: id}}); err != nil {
+			s.Logger().Warn("failed to discard put chunks", zap.Uint64("upload-id", id), zap.Error(err))
+		}
+	})
+}
+
 func (s *EtcdServer) DeleteRange(ctx context.Context, r *pb.DeleteRangeRequest) (*pb.DeleteRangeResponse, error) {
 	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{DeleteRange: r})
 	if err != nil {
@@ -153,6 +295,72 @@ func (s *EtcdServer) DeleteRange(ctx context.Context, r *pb.DeleteRangeRequest)
 	return resp.(*pb.DeleteRangeResponse), nil
 }
 
+func (s *EtcdServer) Move(ctx context.Context, r *pb.MoveRequest) (*pb.MoveResponse, error) {
+	if !s.isClusterVersion36() {
+		return nil, ErrNotSupportedByCluster
+	}
+	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{Move: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.MoveResponse), nil
+}
+
+func (s *EtcdServer) SetLease(ctx context.Context, r *pb.SetLeaseRequest) (*pb.SetLeaseResponse, error) {
+	if !s.isClusterVersion36() {
+		return nil, ErrNotSupportedByCluster
+	}
+	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{SetLease: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.SetLeaseResponse), nil
+}
+
+func (s *EtcdServer) Increment(ctx context.Context, r *pb.IncrementRequest) (*pb.IncrementResponse, error) {
+	if !s.isClusterVersion36() {
+		return nil, ErrNotSupportedByCluster
+	}
+	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{Increment: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.IncrementResponse), nil
+}
+
+func (s *EtcdServer) PutIfAbsent(ctx context.Context, r *pb.PutIfAbsentRequest) (*pb.PutIfAbsentResponse, error) {
+	if !s.isClusterVersion36() {
+		return nil, ErrNotSupportedByCluster
+	}
+	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{PutIfAbsent: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.PutIfAbsentResponse), nil
+}
+
+func (s *EtcdServer) GetAndDelete(ctx context.Context, r *pb.GetAndDeleteRequest) (*pb.GetAndDeleteResponse, error) {
+	if !s.isClusterVersion36() {
+		return nil, ErrNotSupportedByCluster
+	}
+	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{GetAndDelete: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.GetAndDeleteResponse), nil
+}
+
+func (s *EtcdServer) BulkWrite(ctx context.Context, r *pb.BulkWriteRequest) (*pb.BulkWriteResponse, error) {
+	if !s.isClusterVersion36() {
+		return nil, ErrNotSupportedByCluster
+	}
+	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{BulkWrite: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.BulkWriteResponse), nil
+}
+
 func (s *EtcdServer) Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnResponse, error) {
 	if isTxnReadonly(r) {
 		trace := traceutil.New("transaction",
@@ -160,15 +368,24 @@ func (s *EtcdServer) Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnResponse
 			traceutil.Field{Key: "read_only", Value: true},
 		)
 		ctx = context.WithValue(ctx, traceutil.TraceKey, trace)
+		var resp *pb.TxnResponse
+		var err error
+		var pt proposalTimings
+		defer func(start time.Time) {
+			if s.Cfg.SlowRequestThreshold > 0 {
+				s.logSlowRequest(&pb.InternalRaftRequest{Txn: r}, start, pt, err)
+			}
+			requestTraceFromContext(ctx).record(pt)
+		}(time.Now())
 		if !isTxnSerializable(r) {
-			err := s.linearizableReadNotify(ctx)
+			readStart := time.Now()
+			err = s.linearizableReadNotify(ctx)
+			pt.queueWait = time.Since(readStart)
 			trace.Step("agreement among raft nodes before linearized reading")
 			if err != nil {
 				return nil, err
 			}
 		}
-		var resp *pb.TxnResponse
-		var err error
 		chk := func(ai *auth.AuthInfo) error {
 			return checkTxnAuth(s.authStore, ai, r)
 		}
@@ -178,9 +395,14 @@ func (s *EtcdServer) Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnResponse
 			trace.LogIfLong(traceThreshold)
 		}(time.Now())
 
-		get := func() { resp, _, err = s.applyV3Base.Txn(ctx, r) }
+		get := func() {
+			applyStart := time.Now()
+			resp, _, err = s.applyV3Base.Txn(ctx, r)
+			pt.apply = time.Since(applyStart)
+		}
 		if serr := s.doSerialize(ctx, chk, get); serr != nil {
-			return nil, serr
+			err = serr
+			return nil, err
 		}
 		return resp, err
 	}
@@ -267,6 +489,9 @@ func (s *EtcdServer) Compact(ctx context.Context, r *pb.CompactionRequest) (*pb.
 }
 
 func (s *EtcdServer) LeaseGrant(ctx context.Context, r *pb.LeaseGrantRequest) (*pb.LeaseGrantResponse, error) {
+	if err := s.checkLeasesPerUser(ctx, 1); err != nil {
+		return nil, err
+	}
 	// no id given? choose one
 	for r.ID == int64(lease.NoLease) {
 		// only use positive int64 id's
@@ -279,6 +504,26 @@ func (s *EtcdServer) LeaseGrant(ctx context.Context, r *pb.LeaseGrantRequest) (*
 	return resp.(*pb.LeaseGrantResponse), nil
 }
 
+// checkLeasesPerUser returns ErrTooManyLeases if the authenticated user would
+// hold more than the maximum leases per user once granted n more leases.
+// It fails the requests early, the limit being enforced when applied.
+func (s *EtcdServer) checkLeasesPerUser(ctx context.Context, n int) error {
+	if s.Cfg.MaxLeasesPerUser <= 0 {
+		return nil
+	}
+	authInfo, err := s.AuthInfoFromCtx(ctx)
+	if err != nil {
+		return err
+	}
+	if authInfo == nil || authInfo.Username == "" {
+		return nil
+	}
+	if s.lessor.OwnedLeases(authInfo.Username)+n > s.Cfg.MaxLeasesPerUser {
+		return ErrTooManyLeases
+	}
+	return nil
+}
+
 func (s *EtcdServer) waitAppliedIndex() error {
 	select {
 	case <-s.ApplyWait():
@@ -299,6 +544,48 @@ func (s *EtcdServer) LeaseRevoke(ctx context.Context, r *pb.LeaseRevokeRequest)
 	return resp.(*pb.LeaseRevokeResponse), nil
 }
 
+// leaseExpire revokes the expired lease id, reported expired to the watchers
+// of the lease events once the cluster is at 3.6 or later.
+func (s *EtcdServer) leaseExpire(ctx context.Context, id lease.LeaseID) error {
+	r := &pb.LeaseRevokeRequest{ID: int64(id)}
+	if v := s.ClusterVersion(); v == nil || v.LessThan(semver.Version{Major: 3, Minor: 6}) {
+		_, err := s.LeaseRevoke(ctx, r)
+		return err
+	}
+	_, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{LeaseExpire: r})
+	return err
+}
+
+func (s *EtcdServer) LeaseGrantBulk(ctx context.Context, r *pb.LeaseGrantBulkRequest) (*pb.LeaseGrantBulkResponse, error) {
+	if !s.isClusterVersion36() {
+		return nil, ErrNotSupportedByCluster
+	}
+	if err := s.checkLeasesPerUser(ctx, len(r.Leases)); err != nil {
+		return nil, err
+	}
+	for _, l := range r.Leases {
+		for l.ID == int64(lease.NoLease) {
+			l.ID = int64(s.reqIDGen.Next() & ((1 << 63) - 1))
+		}
+	}
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{LeaseGrantBulk: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.LeaseGrantBulkResponse), nil
+}
+
+func (s *EtcdServer) LeaseRevokeBulk(ctx context.Context, r *pb.LeaseRevokeBulkRequest) (*pb.LeaseRevokeBulkResponse, error) {
+	if !s.isClusterVersion36() {
+		return nil, ErrNotSupportedByCluster
+	}
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{LeaseRevokeBulk: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.LeaseRevokeBulkResponse), nil
+}
+
 func (s *EtcdServer) LeaseRenew(ctx context.Context, id lease.LeaseID) (int64, error) {
 	if s.isLeader() {
 		if err := s.waitAppliedIndex(); err != nil {
@@ -340,6 +627,104 @@ func (s *EtcdServer) LeaseRenew(ctx context.Context, id lease.LeaseID) (int64, e
 	return -1, ErrCanceled
 }
 
+func (s *EtcdServer) LeaseKeepAliveOnce(ctx context.Context, id lease.LeaseID) (int64, error) {
+	if s.AuthStore().IsAuthEnabled() {
+		authInfo, err := s.AuthInfoFromCtx(ctx)
+		if err != nil {
+			return -1, err
+		}
+		if authInfo == nil {
+			return -1, auth.ErrUserEmpty
+		}
+		if l := s.lessor.Lookup(id); l != nil && l.Owner() != "" && l.Owner() != authInfo.Username {
+			if err = s.AuthStore().IsAdminPermitted(authInfo); err != nil {
+				return -1, err
+			}
+		}
+	}
+	return s.LeaseRenew(ctx, id)
+}
+
+func (s *EtcdServer) LeaseRenewBatch(ctx context.Context, ids []lease.LeaseID) ([]lease.RenewResult, error) {
+	if s.isLeader() {
+		if err := s.waitAppliedIndex(); err != nil {
+			return nil, err
+		}
+
+		results, err := s.lessor.RenewBatch(ids)
+		if err == nil {
+			return results, nil
+		}
+		if err != lease.ErrNotPrimary {
+			return nil, err
+		}
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, s.Cfg.ReqTimeout())
+	defer cancel()
+
+	// renewals don't go through raft; forward the batch to leader manually
+	for cctx.Err() == nil {
+		leader, lerr := s.waitLeader(cctx)
+		if lerr != nil {
+			return nil, lerr
+		}
+		for _, url := range leader.PeerURLs {
+			lurl := url + leasehttp.LeaseBatchPrefix
+			results, err := leasehttp.RenewBatchHTTP(cctx, ids, lurl, s.peerRt)
+			if err == nil {
+				return results, nil
+			}
+			if err == leasehttp.ErrLeaseHTTPBatchNotSupported {
+				return s.leaseRenewEach(ctx, ids)
+			}
+		}
+		// Throttle in case of e.g. connection problems.
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if cctx.Err() == context.DeadlineExceeded {
+		return nil, ErrTimeout
+	}
+	return nil, ErrCanceled
+}
+
+// maxConcurrentLeaseRenews bounds the renewals forwarded concurrently to a
+// leader without batched renewals.
+const maxConcurrentLeaseRenews = 16
+
+// leaseRenewEach renews the leases one by one, for leaders that predate the
+// batched renewals.
+func (s *EtcdServer) leaseRenewEach(ctx context.Context, ids []lease.LeaseID) ([]lease.RenewResult, error) {
+	results := make([]lease.RenewResult, len(ids))
+	errs := make([]error, len(ids))
+	sem := make(chan struct{}, maxConcurrentLeaseRenews)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, id lease.LeaseID) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			ttl, err := s.LeaseRenew(ctx, id)
+			if err != nil && err != lease.ErrLeaseNotFound {
+				errs[i] = err
+				return
+			}
+			results[i] = lease.RenewResult{ID: id, TTL: ttl, Err: err}
+		}(i, id)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
 func (s *EtcdServer) LeaseTimeToLive(ctx context.Context, r *pb.LeaseTimeToLiveRequest) (*pb.LeaseTimeToLiveResponse, error) {
 	if s.isLeader() {
 		if err := s.waitAppliedIndex(); err != nil {
@@ -390,13 +775,43 @@ func (s *EtcdServer) LeaseTimeToLive(ctx context.Context, r *pb.LeaseTimeToLiveR
 	return nil, ErrCanceled
 }
 
+// LeaseLeases lists the leases in the order of their IDs, from r.MinID and at
+// most r.Limit of them, with a key attached having r.KeyPrefix and a remaining
+// TTL in [r.MinTTL, r.MaxTTL] if set. The remaining TTLs are only known to the
+// leader: the other members report them -1 and reject a filter on them.
 func (s *EtcdServer) LeaseLeases(ctx context.Context, r *pb.LeaseLeasesRequest) (*pb.LeaseLeasesResponse, error) {
+	leader := s.isLeader()
+	if !leader && (r.MinTTL != 0 || r.MaxTTL != 0) {
+		return nil, ErrNotLeader
+	}
 	ls := s.lessor.Leases()
-	lss := make([]*pb.LeaseStatus, len(ls))
-	for i := range ls {
-		lss[i] = &pb.LeaseStatus{ID: int64(ls[i].ID)}
+	sort.Slice(ls, func(i, j int) bool { return ls[i].ID < ls[j].ID })
+	i := sort.Search(len(ls), func(i int) bool { return int64(ls[i].ID) >= r.MinID })
+
+	resp := &pb.LeaseLeasesResponse{Header: newHeader(s), Leases: []*pb.LeaseStatus{}}
+	for _, l := range ls[i:] {
+		ttl := int64(-1)
+		if leader {
+			ttl = int64(l.Remaining().Seconds())
+			if (r.MinTTL != 0 && ttl < r.MinTTL) || (r.MaxTTL != 0 && ttl > r.MaxTTL) {
+				continue
+			}
+		}
+		if len(r.KeyPrefix) != 0 && !l.HasKeyWithPrefix(string(r.KeyPrefix)) {
+			continue
+		}
+		if r.Limit > 0 && int64(len(resp.Leases)) == r.Limit {
+			resp.More = true
+			break
+		}
+		resp.Leases = append(resp.Leases, &pb.LeaseStatus{
+			ID:         int64(l.ID),
+			TTL:        ttl,
+			GrantedTTL: l.TTL(),
+			KeyCount:   int64(l.KeyCount()),
+		})
 	}
-	return &pb.LeaseLeasesResponse{Header: newHeader(s), Leases: lss}, nil
+	return resp, nil
 }
 
 func (s *EtcdServer) waitLeader(ctx context.Context) (*membership.Member, error) {
@@ -427,6 +842,22 @@ func (s *EtcdServer) Alarm(ctx context.Context, r *pb.AlarmRequest) (*pb.AlarmRe
 	return resp.(*pb.AlarmResponse), nil
 }
 
+func (s *EtcdServer) PrefixQuotaSet(ctx context.Context, r *pb.PrefixQuotaSetRequest) (*pb.PrefixQuotaSetResponse, error) {
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{PrefixQuotaSet: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.PrefixQuotaSetResponse), nil
+}
+
+func (s *EtcdServer) PrefixQuotaDelete(ctx context.Context, r *pb.PrefixQuotaDeleteRequest) (*pb.PrefixQuotaDeleteResponse, error) {
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{PrefixQuotaDelete: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.PrefixQuotaDeleteResponse), nil
+}
+
 func (s *EtcdServer) AuthEnable(ctx context.Context, r *pb.AuthEnableRequest) (*pb.AuthEnableResponse, error) {
 	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{AuthEnable: r})
 	if err != nil {
@@ -458,9 +889,25 @@ func (s *EtcdServer) Authenticate(ctx context.Context, r *pb.AuthenticateRequest
 
 	lg := s.Logger()
 
+	addr := clientAddr(ctx)
+	if s.authLockouts != nil {
+		if err := s.authLockouts.Check(r.Name, addr); err != nil {
+			lg.Warn(
+				"rejected authentication of a locked out user or address",
+				zap.String("user", r.Name),
+				zap.String("address", addr),
+			)
+			return nil, err
+		}
+	}
+
 	var resp proto.Message
 	for {
 		checkedRevision, err := s.AuthStore().CheckPassword(r.Name, r.Password)
+		var roles []string
+		if err == auth.ErrAuthFailed && s.ldapEnabled() {
+			checkedRevision, roles, err = s.AuthStore().CheckLDAPPassword(r.Name, r.Password)
+		}
 		if err != nil {
 			if err != auth.ErrAuthNotEnabled {
 				lg.Warn(
@@ -469,6 +916,9 @@ func (s *EtcdServer) Authenticate(ctx context.Context, r *pb.AuthenticateRequest
 					zap.Error(err),
 				)
 			}
+			if err == auth.ErrAuthFailed && s.authLockouts != nil {
+				s.lockOut(r.Name, addr)
+			}
 			return nil, err
 		}
 
@@ -483,6 +933,16 @@ func (s *EtcdServer) Authenticate(ctx context.Context, r *pb.AuthenticateRequest
 			Name:        r.Name,
 			SimpleToken: st,
 		}
+		if v := s.ClusterVersion(); v != nil && !v.LessThan(semver.Version{Major: 3, Minor: 6}) {
+			// rehash the password if the password hashing policy changed
+			prev, hashed, err := s.AuthStore().RehashPassword(r.Name, r.Password)
+			if err != nil {
+				return nil, err
+			}
+			internalReq.HashedPassword, internalReq.PreviousHashedPassword = hashed, prev
+			internalReq.ClientAddress = addr
+			internalReq.Roles = roles
+		}
 
 		resp, err = s.raftRequestOnce(ctx, pb.InternalRaftRequest{Authenticate: internalReq})
 		if err != nil {
@@ -495,17 +955,146 @@ func (s *EtcdServer) Authenticate(ctx context.Context, r *pb.AuthenticateRequest
 		lg.Info("revision when password checked became stale; retrying")
 	}
 
+	if s.authLockouts != nil {
+		s.authLockouts.Succeed(r.Name)
+	}
 	return resp.(*pb.AuthenticateResponse), nil
 }
 
+// ldapEnabled returns whether the users absent from the auth store may
+// authenticate against the LDAP directory, which older members cannot grant
+// roles to.
+func (s *EtcdServer) ldapEnabled() bool {
+	if s.Cfg.AuthLDAP.URL == "" {
+		return false
+	}
+	v := s.ClusterVersion()
+	return v != nil && !v.LessThan(semver.Version{Major: 3, Minor: 6})
+}
+
+// isClusterVersion36 returns whether the cluster is at 3.6 or later, so
+// that every member can apply the requests added in 3.6.
+func (s *EtcdServer) isClusterVersion36() bool {
+	v := s.ClusterVersion()
+	return v != nil && !v.LessThan(semver.Version{Major: 3, Minor: 6})
+}
+
+// lockOut records a failed authentication of the user from the client
+// address, logging the lockouts it starts to the audit log as well.
+func (s *EtcdServer) lockOut(user, addr string) {
+	for _, l := range s.authLockouts.Fail(user, addr) {
+		fields := []zap.Field{
+			zap.String("user", l.User),
+			zap.String("address", l.Address),
+			zap.Int("failures", l.Failures),
+			zap.Time("until", l.Until),
+		}
+		s.Logger().Warn("locked out of authenticating after too many failures", fields...)
+		if s.Cfg.AuditLogger != nil {
+			s.Cfg.AuditLogger.Info("audit", append([]zap.Field{zap.String("event", "auth-lockout")}, fields...)...)
+		}
+	}
+}
+
+// clientAddr returns the host of the client sending the request of ctx, or
+// an empty string if unknown.
+func clientAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func (s *EtcdServer) AuthLockoutList(ctx context.Context, r *pb.AuthLockoutListRequest) (*pb.AuthLockoutListResponse, error) {
+	if err := s.checkAuthLockoutPermission(ctx); err != nil {
+		return nil, err
+	}
+	resp := &pb.AuthLockoutListResponse{Header: newHeader(s)}
+	if s.authLockouts == nil {
+		return resp, nil
+	}
+	for _, l := range s.authLockouts.List() {
+		resp.Lockouts = append(resp.Lockouts, &pb.AuthLockout{
+			User:     l.User,
+			Address:  l.Address,
+			Failures: int64(l.Failures),
+			Until:    l.Until.Unix(),
+		})
+	}
+	return resp, nil
+}
+
+func (s *EtcdServer) AuthLockoutClear(ctx context.Context, r *pb.AuthLockoutClearRequest) (*pb.AuthLockoutClearResponse, error) {
+	if err := s.checkAuthLockoutPermission(ctx); err != nil {
+		return nil, err
+	}
+	resp := &pb.AuthLockoutClearResponse{Header: newHeader(s)}
+	if s.authLockouts == nil {
+		return resp, nil
+	}
+	resp.Cleared = int64(s.authLockouts.Clear(r.User, r.Address))
+	s.Logger().Info(
+		"cleared authentication lockouts",
+		zap.String("user", r.User),
+		zap.String("address", r.Address),
+		zap.Int64("cleared", resp.Cleared),
+	)
+	return resp, nil
+}
+
+func (s *EtcdServer) AuthSessionList(ctx context.Context, r *pb.AuthSessionListRequest) (*pb.AuthSessionListResponse, error) {
+	if err := s.checkAuthLockoutPermission(ctx); err != nil {
+		return nil, err
+	}
+	resp := &pb.AuthSessionListResponse{Header: newHeader(s)}
+	for _, ss := range s.AuthStore().Sessions(r.User) {
+		resp.Sessions = append(resp.Sessions, &pb.AuthSession{
+			ID:        ss.ID,
+			User:      ss.User,
+			Address:   ss.Address,
+			IssuedAt:  ss.IssuedAt.Unix(),
+			ExpiresAt: ss.ExpiresAt.Unix(),
+		})
+	}
+	return resp, nil
+}
+
+func (s *EtcdServer) AuthSessionRevoke(ctx context.Context, r *pb.AuthSessionRevokeRequest) (*pb.AuthSessionRevokeResponse, error) {
+	if v := s.ClusterVersion(); v == nil || v.LessThan(semver.Version{Major: 3, Minor: 6}) {
+		return nil, auth.ErrPermissionNotGiven
+	}
+	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthSessionRevoke: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.AuthSessionRevokeResponse), nil
+}
+
+// checkAuthLockoutPermission checks the lockouts and the sessions, local to
+// the member, are read or managed with the AUTH admin permission. They are
+// not replicated, so not checked on apply.
+func (s *EtcdServer) checkAuthLockoutPermission(ctx context.Context) error {
+	authInfo, err := s.AuthInfoFromCtx(ctx)
+	if err != nil {
+		return err
+	}
+	return s.AuthStore().IsAdminOpPermitted(authInfo, authpb.AUTH)
+}
+
 func (s *EtcdServer) UserAdd(ctx context.Context, r *pb.AuthUserAddRequest) (*pb.AuthUserAddResponse, error) {
 	if r.Options == nil || !r.Options.NoPassword {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(r.Password), s.authStore.BcryptCost())
+		hashedPassword, err := s.authStore.HashPassword(r.Password)
 		if err != nil {
 			return nil, err
 		}
 		r.HashedPassword = base64.StdEncoding.EncodeToString(hashedPassword)
 		r.Password = ""
+		r.PasswordChangedAt = s.passwordChangedAt()
 	}
 
 	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthUserAdd: r})
@@ -515,6 +1104,15 @@ func (s *EtcdServer) UserAdd(ctx context.Context, r *pb.AuthUserAddRequest) (*pb
 	return resp.(*pb.AuthUserAddResponse), nil
 }
 
+// passwordChangedAt returns the time a password is set at, for the password
+// policy to expire it, unless older members would not record it.
+func (s *EtcdServer) passwordChangedAt() int64 {
+	if v := s.ClusterVersion(); v == nil || v.LessThan(semver.Version{Major: 3, Minor: 6}) {
+		return 0
+	}
+	return time.Now().Unix()
+}
+
 func (s *EtcdServer) UserDelete(ctx context.Context, r *pb.AuthUserDeleteRequest) (*pb.AuthUserDeleteResponse, error) {
 	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthUserDelete: r})
 	if err != nil {
@@ -525,12 +1123,13 @@ func (s *EtcdServer) UserDelete(ctx context.Context, r *pb.AuthUserDeleteRequest
 
 func (s *EtcdServer) UserChangePassword(ctx context.Context, r *pb.AuthUserChangePasswordRequest) (*pb.AuthUserChangePasswordResponse, error) {
 	if r.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(r.Password), s.authStore.BcryptCost())
+		hashedPassword, err := s.authStore.HashPassword(r.Password)
 		if err != nil {
 			return nil, err
 		}
 		r.HashedPassword = base64.StdEncoding.EncodeToString(hashedPassword)
 		r.Password = ""
+		r.PasswordChangedAt = s.passwordChangedAt()
 	}
 
 	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthUserChangePassword: r})
@@ -541,6 +1140,14 @@ func (s *EtcdServer) UserChangePassword(ctx context.Context, r *pb.AuthUserChang
 }
 
 func (s *EtcdServer) UserGrantRole(ctx context.Context, r *pb.AuthUserGrantRoleRequest) (*pb.AuthUserGrantRoleResponse, error) {
+	if r.ExpiresAt != 0 {
+		if v := s.ClusterVersion(); v == nil || v.LessThan(semver.Version{Major: 3, Minor: 6}) {
+			return nil, auth.ErrPermissionNotGiven
+		}
+		if r.ExpiresAt <= time.Now().Unix() {
+			return nil, auth.ErrRoleGrantExpired
+		}
+	}
 	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthUserGrantRole: r})
 	if err != nil {
 		return nil, err
@@ -581,6 +1188,12 @@ func (s *EtcdServer) RoleAdd(ctx context.Context, r *pb.AuthRoleAddRequest) (*pb
 }
 
 func (s *EtcdServer) RoleGrantPermission(ctx context.Context, r *pb.AuthRoleGrantPermissionRequest) (*pb.AuthRoleGrantPermissionResponse, error) {
+	if err := s.checkAdminPermsSupported(r.AdminPerms); err != nil {
+		return nil, err
+	}
+	if err := s.checkPermTypeSupported(r.Perm); err != nil {
+		return nil, err
+	}
 	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthRoleGrantPermission: r})
 	if err != nil {
 		return nil, err
@@ -605,6 +1218,9 @@ func (s *EtcdServer) RoleList(ctx context.Context, r *pb.AuthRoleListRequest) (*
 }
 
 func (s *EtcdServer) RoleRevokePermission(ctx context.Context, r *pb.AuthRoleRevokePermissionRequest) (*pb.AuthRoleRevokePermissionResponse, error) {
+	if err := s.checkAdminPermsSupported(r.AdminPerms); err != nil {
+		return nil, err
+	}
 	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthRoleRevokePermission: r})
 	if err != nil {
 		return nil, err
@@ -612,6 +1228,32 @@ func (s *EtcdServer) RoleRevokePermission(ctx context.Context, r *pb.AuthRoleRev
 	return resp.(*pb.AuthRoleRevokePermissionResponse), nil
 }
 
+// checkAdminPermsSupported rejects granting or revoking admin permissions
+// before the cluster is at 3.6, since older members would drop them and
+// apply the request differently.
+func (s *EtcdServer) checkAdminPermsSupported(perms []authpb.AdminPermission) error {
+	if len(perms) == 0 {
+		return nil
+	}
+	if v := s.ClusterVersion(); v == nil || v.LessThan(semver.Version{Major: 3, Minor: 6}) {
+		return auth.ErrPermissionNotGiven
+	}
+	return nil
+}
+
+// checkPermTypeSupported rejects granting the watch and range permission
+// types before the cluster is at 3.6, since older members would grant
+// neither ranges nor watches for them.
+func (s *EtcdServer) checkPermTypeSupported(perm *authpb.Permission) error {
+	if perm == nil || (perm.PermType != authpb.WATCH && perm.PermType != authpb.RANGE) {
+		return nil
+	}
+	if v := s.ClusterVersion(); v == nil || v.LessThan(semver.Version{Major: 3, Minor: 6}) {
+		return auth.ErrPermissionNotGiven
+	}
+	return nil
+}
+
 func (s *EtcdServer) RoleDelete(ctx context.Context, r *pb.AuthRoleDeleteRequest) (*pb.AuthRoleDeleteResponse, error) {
 	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthRoleDelete: r})
 	if err != nil {
@@ -669,12 +1311,16 @@ func (s *EtcdServer) doSerialize(ctx context.Context, chk func(*auth.AuthInfo) e
 	return nil
 }
 
-func (s *EtcdServer) processInternalRaftRequestOnce(ctx context.Context, r pb.InternalRaftRequest) (*applyResult, error) {
+func (s *EtcdServer) processInternalRaftRequestOnce(ctx context.Context, r pb.InternalRaftRequest) (ar *applyResult, err error) {
 	ai := s.getAppliedIndex()
 	ci := s.getCommittedIndex()
-	if ci > ai+maxGapBetweenApplyAndCommitIndex {
+	if ci > ai+MaxGapBetweenApplyAndCommitIndex {
 		return nil, ErrTooManyRequests
 	}
+	// members before 3.6 would put the keys without expiring them
+	if setExpireTimes(&r, time.Now()) && !s.isClusterVersion36() {
+		return nil, ErrNotSupportedByCluster
+	}
 
 	r.Header = &pb.RequestHeader{
 		ID: s.reqIDGen.Next(),
@@ -689,6 +1335,13 @@ func (s *EtcdServer) processInternalRaftRequestOnce(ctx context.Context, r pb.In
 		if authInfo != nil {
 			r.Header.Username = authInfo.Username
 			r.Header.AuthRevision = authInfo.Revision
+			if len(authInfo.Roles) > 0 {
+				// members before 3.6 would drop the roles and deny the request
+				if v := s.ClusterVersion(); v == nil || v.LessThan(semver.Version{Major: 3, Minor: 6}) {
+					return nil, auth.ErrPermissionDenied
+				}
+				r.Header.Roles = authInfo.Roles
+			}
 		}
 	}
 
@@ -707,16 +1360,29 @@ func (s *EtcdServer) processInternalRaftRequestOnce(ctx context.Context, r pb.In
 	}
 	ch := s.w.Register(id)
 
+	start := time.Now()
+	ctx, p := s.tracer.begin(ctx, id)
+	defer func() {
+		s.tracer.end(p, err)
+		rerr := err
+		if err == nil {
+			rerr = ar.err
+		}
+		pt := s.tracer.timingsOf(p)
+		s.logSlowRequest(&r, start, pt, rerr)
+		requestTraceFromContext(ctx).record(pt)
+	}()
+
 	cctx, cancel := context.WithTimeout(ctx, s.Cfg.ReqTimeout())
 	defer cancel()
 
-	start := time.Now()
 	err = s.r.Propose(cctx, data)
 	if err != nil {
 		proposalsFailed.Inc()
 		s.w.Trigger(id, nil) // GC wait
 		return nil, err
 	}
+	s.tracer.proposed(id, start, time.Now())
 	proposalsPending.Inc()
 	defer proposalsPending.Dec()
 
@@ -726,9 +1392,11 @@ func (s *EtcdServer) processInternalRaftRequestOnce(ctx context.Context, r pb.In
 	case <-cctx.Done():
 		proposalsFailed.Inc()
 		s.w.Trigger(id, nil) // GC wait
-		return nil, s.parseProposeCtxErr(cctx.Err(), start)
+		err = s.parseProposeCtxErr(cctx.Err(), start)
+		return nil, err
 	case <-s.done:
-		return nil, ErrStopped
+		err = ErrStopped
+		return nil, err
 	}
 }
 
@@ -767,6 +1435,8 @@ func (s *EtcdServer) linearizableReadLoop() {
 		}
 
 		trace.Step("read index received")
+		// unblock all bounded staleness reads, which do not wait for confirmedIndex
+		nr.notifyReadIndex(confirmedIndex)
 
 		trace.AddField(traceutil.Field{Key: "readStateIndex", Value: confirmedIndex})
 
@@ -915,6 +1585,75 @@ func (s *EtcdServer) linearizableReadNotify(ctx context.Context) error {
 	}
 }
 
+// boundedStalenessReadNotify waits for the local member to have applied all
+// the entries committed by the leader but maxStaleness of them. A follower
+// or a learner uses the commit index it learned from the leader if it heard
+// from the leader within the bounded staleness window, and gets the commit
+// index of the leader by a read index request otherwise, since its own one
+// may lag behind arbitrarily, e.g. while partitioned from the leader.
+func (s *EtcdServer) boundedStalenessReadNotify(ctx context.Context, maxStaleness uint64) error {
+	lead := types.ID(s.Lead())
+	if lead == types.ID(raft.None) {
+		return ErrNoLeader
+	}
+	var ci uint64
+	if lead == s.ID() {
+		ci = s.getCommittedIndex()
+	} else {
+		if s.r.transport.ActiveSince(lead).IsZero() {
+			return ErrNoLeader
+		}
+		heardAt := s.getLeaderHeardAt()
+		if w := s.Cfg.BoundedStalenessWindow; w > 0 && !heardAt.IsZero() && time.Since(heardAt) <= w {
+			ci = s.getCommittedIndex()
+		} else {
+			var err error
+			if ci, err = s.leaderCommittedIndex(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	if ci <= maxStaleness || s.getAppliedIndex() >= ci-maxStaleness {
+		return nil
+	}
+	select {
+	case <-s.applyWait.Wait(ci - maxStaleness):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.done:
+		return ErrStopped
+	}
+}
+
+// leaderCommittedIndex returns the commit index of the leader, confirmed by
+// the next read index request of the linearizable read loop.
+func (s *EtcdServer) leaderCommittedIndex(ctx context.Context) (uint64, error) {
+	s.readMu.RLock()
+	nc := s.readNotifier
+	s.readMu.RUnlock()
+
+	select {
+	case s.readwaitc <- struct{}{}:
+	default:
+	}
+
+	select {
+	case <-nc.readIndexc:
+		return nc.readIndex, nil
+	case <-nc.c:
+		// the read index is notified before the reads are unblocked
+		if nc.err != nil {
+			return 0, nc.err
+		}
+		return nc.readIndex, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-s.done:
+		return 0, ErrStopped
+	}
+}
+
 func (s *EtcdServer) AuthInfoFromCtx(ctx context.Context) (*auth.AuthInfo, error) {
 	authInfo, err := s.AuthStore().AuthInfoFromCtx(ctx)
 	if authInfo != nil || err != nil {
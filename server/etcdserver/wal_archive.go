@@ -0,0 +1,95 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"time"
+
+	"go.etcd.io/etcd/server/v3/storage/schema"
+	"go.etcd.io/etcd/server/v3/storage/wal"
+
+	"go.uber.org/zap"
+)
+
+// monitorWALArchive copies the WAL segments to the archive sink once they
+// are completed, that is once the next one is written, in order. The last
+// segment archived is recorded in the backend, so that the archiving
+// resumes after it on restart. The segments are archived as
+// "<member id>/<segment name>", as every member archives its own WAL.
+func (s *EtcdServer) monitorWALArchive() {
+	sink := s.Cfg.WALArchiveSink
+	if sink == nil {
+		return
+	}
+	lg := s.Logger()
+	lg.Info(
+		"enabled WAL archiving",
+		zap.String("local-member-id", s.ID().String()),
+		zap.Duration("interval", s.Cfg.WALArchiveInterval),
+	)
+
+	tx := s.Backend().BatchTx()
+	tx.LockOutsideApply()
+	schema.UnsafeCreateWALArchiveBucket(tx)
+	archived := schema.UnsafeReadWALArchived(tx, s.ID())
+	tx.Unlock()
+
+	for {
+		select {
+		case <-s.stopping:
+			return
+		case <-time.After(s.Cfg.WALArchiveInterval):
+		}
+		names, err := wal.SegmentNames(lg, s.Cfg.WALDir())
+		if err != nil {
+			lg.Warn("failed to list WAL segments to archive", zap.Error(err))
+			continue
+		}
+		// the last segment is being written
+		for _, name := range names[:len(names)-1] {
+			if name <= archived {
+				continue
+			}
+			if archived != "" && !isNextWALSegment(archived, name) {
+				lg.Warn(
+					"WAL segments were purged before they were archived",
+					zap.String("last-archived", archived),
+					zap.String("next", name),
+				)
+			}
+			ctx, cancel := context.WithTimeout(s.ctx, s.Cfg.ReqTimeout()+s.Cfg.WALArchiveInterval)
+			err = sink.Archive(ctx, path.Join(s.ID().String(), name), filepath.Join(s.Cfg.WALDir(), name))
+			cancel()
+			if err != nil {
+				lg.Warn("failed to archive WAL segment", zap.String("name", name), zap.Error(err))
+				break
+			}
+			tx.LockOutsideApply()
+			schema.UnsafeSaveWALArchived(tx, s.ID(), name)
+			tx.Unlock()
+			archived = name
+			lg.Debug("archived WAL segment", zap.String("name", name))
+		}
+	}
+}
+
+func isNextWALSegment(prev, name string) bool {
+	pseq, perr := wal.SegmentSeq(prev)
+	seq, err := wal.SegmentSeq(name)
+	return perr == nil && err == nil && seq == pseq+1
+}
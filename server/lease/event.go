@@ -0,0 +1,71 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lease
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventsPrefix is the prefix of the reserved keys the lifecycle events of the
+// leases are watched on, the key of a lease being EventsPrefix followed by
+// its ID in 16 hexadecimal digits. The keys are virtual: the events are only
+// sent to the watchers of the keys, never stored, and the keys cannot be put.
+const EventsPrefix = "\x00lease-events/"
+
+// EventKey returns the key the lifecycle events of the lease id are watched
+// on.
+func EventKey(id LeaseID) string {
+	return fmt.Sprintf("%s%016x", EventsPrefix, id)
+}
+
+// EventType is the type of a lifecycle event of a lease.
+type EventType string
+
+const (
+	// EventGranted is emitted once a lease is granted.
+	EventGranted EventType = "granted"
+	// EventRenewedAfterGap is emitted once a lease is renewed with less than
+	// a third of its TTL remaining, its keepalives having missed for two
+	// thirds of its TTL. It is only emitted by the primary lessor.
+	EventRenewedAfterGap EventType = "renewed-after-gap"
+	// EventExpired is emitted once a lease is revoked because it expired.
+	EventExpired EventType = "expired"
+	// EventRevoked is emitted once a lease is revoked on request.
+	EventRevoked EventType = "revoked"
+	// EventKeysDeleted is emitted after EventExpired or EventRevoked if keys
+	// were attached to the lease, listing the keys deleted.
+	EventKeysDeleted EventType = "keys-deleted"
+)
+
+// Event is a lifecycle event of a lease.
+type Event struct {
+	Type EventType `json:"type"`
+	ID   LeaseID   `json:"id"`
+	// TTL is the TTL of the lease granted or renewed, in seconds.
+	TTL int64 `json:"ttl,omitempty"`
+	// Keys are the sorted keys deleted with the lease.
+	Keys []string `json:"keys,omitempty"`
+}
+
+// EventNotifier is notified of the lifecycle events of the leases, in the
+// order they happen. It is called without the lessor locked.
+type EventNotifier func(evs []Event)
+
+// renewedAfterGap returns whether a lease renewed with remaining time left
+// was renewed after a gap in its keepalives.
+func renewedAfterGap(l *Lease, remaining time.Duration) bool {
+	return remaining < time.Duration(l.ttl)*time.Second/3
+}
@@ -32,8 +32,12 @@ import (
 var (
 	LeasePrefix         = "/leases"
 	LeaseInternalPrefix = "/leases/internal"
+	LeaseBatchPrefix    = "/leases/batch"
 	applyTimeout        = time.Second
 	ErrLeaseHTTPTimeout = errors.New("waiting for node to catch up its applied index has timed out")
+	// ErrLeaseHTTPBatchNotSupported is returned by RenewBatchHTTP when the
+	// primary server predates the batched renewals.
+	ErrLeaseHTTPBatchNotSupported = errors.New("lease: batched renewals are not supported by the primary server")
 )
 
 // NewHandler returns an http Handler for lease renewals
@@ -91,6 +95,38 @@ func (h *leaseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+	case LeaseBatchPrefix:
+		lreq := pb.LeaseKeepAliveBatchRequest{}
+		if uerr := lreq.Unmarshal(b); uerr != nil {
+			http.Error(w, "error unmarshalling request", http.StatusBadRequest)
+			return
+		}
+		select {
+		case <-h.waitch():
+		case <-time.After(applyTimeout):
+			http.Error(w, ErrLeaseHTTPTimeout.Error(), http.StatusRequestTimeout)
+			return
+		}
+		ids := make([]lease.LeaseID, len(lreq.IDs))
+		for i, id := range lreq.IDs {
+			ids[i] = lease.LeaseID(id)
+		}
+		results, rerr := h.l.RenewBatch(ids)
+		if rerr != nil {
+			http.Error(w, rerr.Error(), http.StatusBadRequest)
+			return
+		}
+		// the leases not found are reported with a TTL of -1
+		resp := &pb.LeaseKeepAliveBatchResponse{Responses: make([]*pb.LeaseKeepAliveResponse, len(results))}
+		for i, r := range results {
+			resp.Responses[i] = &pb.LeaseKeepAliveResponse{ID: int64(r.ID), TTL: r.TTL}
+		}
+		v, err = resp.Marshal()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
 	case LeaseInternalPrefix:
 		lreq := leasepb.LeaseInternalRequest{}
 		if lerr := lreq.Unmarshal(b); lerr != nil {
@@ -142,7 +178,6 @@ func (h *leaseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 // RenewHTTP renews a lease at a given primary server.
-// TODO: Batch request in future?
 func RenewHTTP(ctx context.Context, id lease.LeaseID, url string, rt http.RoundTripper) (int64, error) {
 	// will post lreq protobuf to leader
 	lreq, err := (&pb.LeaseKeepAliveRequest{ID: int64(id)}).Marshal()
@@ -189,6 +224,65 @@ func RenewHTTP(ctx context.Context, id lease.LeaseID, url string, rt http.RoundT
 	return lresp.TTL, nil
 }
 
+// RenewBatchHTTP renews the leases with the given IDs at a given primary
+// server in a single request.
+func RenewBatchHTTP(ctx context.Context, ids []lease.LeaseID, url string, rt http.RoundTripper) ([]lease.RenewResult, error) {
+	lreq := &pb.LeaseKeepAliveBatchRequest{IDs: make([]int64, len(ids))}
+	for i, id := range ids {
+		lreq.IDs[i] = int64(id)
+	}
+	b, err := lreq.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/protobuf")
+	req = req.WithContext(ctx)
+
+	cc := &http.Client{Transport: rt}
+	resp, err := cc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err = readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusRequestTimeout:
+		return nil, ErrLeaseHTTPTimeout
+	case http.StatusNotFound:
+		// the path is not served by the primary server
+		return nil, ErrLeaseHTTPBatchNotSupported
+	default:
+		return nil, fmt.Errorf("lease: unknown error(%s)", string(b))
+	}
+
+	lresp := &pb.LeaseKeepAliveBatchResponse{}
+	if err := lresp.Unmarshal(b); err != nil {
+		return nil, fmt.Errorf(`lease: %v. data = "%s"`, err, string(b))
+	}
+	if len(lresp.Responses) != len(ids) {
+		return nil, fmt.Errorf("lease: renew batch size mismatch")
+	}
+	results := make([]lease.RenewResult, len(ids))
+	for i, r := range lresp.Responses {
+		if r.ID != int64(ids[i]) {
+			return nil, fmt.Errorf("lease: renew id mismatch")
+		}
+		results[i] = lease.RenewResult{ID: ids[i], TTL: r.TTL}
+		if r.TTL <= 0 {
+			results[i].Err = lease.ErrLeaseNotFound
+		}
+	}
+	return results, nil
+}
+
 // TimeToLiveHTTP retrieves lease information of the given lease ID.
 func TimeToLiveHTTP(ctx context.Context, id lease.LeaseID, keys bool, url string, rt http.RoundTripper) (*leasepb.LeaseInternalResponse, error) {
 	// will post lreq protobuf to leader
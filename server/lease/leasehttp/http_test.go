@@ -18,6 +18,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 	"time"
 
@@ -77,6 +78,45 @@ func TestTimeToLiveHTTP(t *testing.T) {
 	}
 }
 
+func TestRenewBatchHTTP(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	be, _ := betesting.NewTmpBackend(t, time.Hour, 10000)
+	defer betesting.Close(t, be)
+
+	le := lease.NewLessor(lg, be, nil, lease.LessorConfig{MinLeaseTTL: int64(5)})
+	le.Promote(time.Second)
+	for _, id := range []lease.LeaseID{1, 2} {
+		if _, err := le.Grant(id, int64(5)); err != nil {
+			t.Fatalf("failed to create lease: %v", err)
+		}
+	}
+
+	ts := httptest.NewServer(NewHandler(le, waitReady))
+	defer ts.Close()
+
+	results, err := RenewBatchHTTP(context.TODO(), []lease.LeaseID{1, 3, 2}, ts.URL+LeaseBatchPrefix, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []lease.RenewResult{{ID: 1, TTL: 5}, {ID: 3, TTL: -1, Err: lease.ErrLeaseNotFound}, {ID: 2, TTL: 5}}
+	if !reflect.DeepEqual(results, want) {
+		t.Fatalf("results = %+v, want %+v", results, want)
+	}
+}
+
+func TestRenewBatchHTTPNotSupported(t *testing.T) {
+	// the peer handler of the older servers does not serve the batch path
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", http.NotFound)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	_, err := RenewBatchHTTP(context.TODO(), []lease.LeaseID{1}, ts.URL+LeaseBatchPrefix, http.DefaultTransport)
+	if err != ErrLeaseHTTPBatchNotSupported {
+		t.Fatalf("err = %v, want %v", err, ErrLeaseHTTPBatchNotSupported)
+	}
+}
+
 func TestRenewHTTPTimeout(t *testing.T) {
 	testApplyTimeout(t, func(l *lease.Lease, serverURL string) error {
 		_, err := RenewHTTP(context.TODO(), l.ID, serverURL+LeasePrefix, http.DefaultTransport)
@@ -84,6 +124,13 @@ func TestRenewHTTPTimeout(t *testing.T) {
 	})
 }
 
+func TestRenewBatchHTTPTimeout(t *testing.T) {
+	testApplyTimeout(t, func(l *lease.Lease, serverURL string) error {
+		_, err := RenewBatchHTTP(context.TODO(), []lease.LeaseID{l.ID}, serverURL+LeaseBatchPrefix, http.DefaultTransport)
+		return err
+	})
+}
+
 func TestTimeToLiveHTTPTimeout(t *testing.T) {
 	testApplyTimeout(t, func(l *lease.Lease, serverURL string) error {
 		_, err := TimeToLiveHTTP(context.TODO(), l.ID, true, serverURL+LeaseInternalPrefix, http.DefaultTransport)
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"math"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -89,6 +90,10 @@ type Lessor interface {
 
 	SetCheckpointer(cp Checkpointer)
 
+	// SetEventNotifier lets the lessor notify the lifecycle events of the
+	// leases.
+	SetEventNotifier(en EventNotifier)
+
 	// Grant grants a lease that expires at least after TTL seconds.
 	Grant(id LeaseID, ttl int64) (*Lease, error)
 	// Revoke revokes a lease with given ID. The item attached to the
@@ -96,6 +101,17 @@ type Lessor interface {
 	// will be returned.
 	Revoke(id LeaseID) error
 
+	// Expire revokes an expired lease with given ID as Revoke does, the
+	// lease being reported expired rather than revoked.
+	Expire(id LeaseID) error
+
+	// SetOwner records the user who granted the lease with given ID, to
+	// count the leases the user holds.
+	SetOwner(id LeaseID, owner string) error
+
+	// OwnedLeases returns the number of leases held by the user owner.
+	OwnedLeases(owner string) int
+
 	// Checkpoint applies the remainingTTL of a lease. The remainingTTL is used in Promote to set
 	// the expiry of leases to less than the full TTL when possible.
 	Checkpoint(id LeaseID, remainingTTL int64) error
@@ -124,6 +140,11 @@ type Lessor interface {
 	// an error will be returned.
 	Renew(id LeaseID) (int64, error)
 
+	// RenewBatch renews the leases with given IDs in a single pass. It returns one
+	// result per ID in the same order. Leases that do not exist get ErrLeaseNotFound
+	// in their result; ErrNotPrimary is returned for the whole batch.
+	RenewBatch(ids []LeaseID) ([]RenewResult, error)
+
 	// Lookup gives the lease at a given lease id, if any
 	Lookup(id LeaseID) *Lease
 
@@ -154,6 +175,8 @@ type lessor struct {
 	leaseExpiredNotifier *LeaseExpiredNotifier
 	leaseCheckpointHeap  LeaseQueue
 	itemMap              map[LeaseItem]LeaseID
+	// owners counts the leases held by their owners.
+	owners map[string]int
 
 	// When a lease expires, the lessor will delete the
 	// leased range (or key) by the RangeDeleter.
@@ -163,6 +186,9 @@ type lessor struct {
 	// elections and restarts, the lessor will checkpoint the lease by the Checkpointer.
 	cp Checkpointer
 
+	// en is notified of the lifecycle events of the leases, if set.
+	en EventNotifier
+
 	// backend to persist leases. We only persist lease ID and expiry for now.
 	// The leased items can be recovered by iterating all the keys in kv.
 	b backend.Backend
@@ -177,6 +203,9 @@ type lessor struct {
 	// doneC is a channel whose closure indicates that the lessor is stopped.
 	doneC chan struct{}
 
+	// clears coalesces the clears of the remaining TTLs of the leases renewed.
+	clears ttlClears
+
 	lg *zap.Logger
 
 	// Wait duration between lease checkpoints.
@@ -217,6 +246,7 @@ func newLessor(lg *zap.Logger, b backend.Backend, cluster cluster, cfg LessorCon
 	l := &lessor{
 		leaseMap:                  make(map[LeaseID]*Lease),
 		itemMap:                   make(map[LeaseItem]LeaseID),
+		owners:                    make(map[string]int),
 		leaseExpiredNotifier:      newLeaseExpiredNotifier(),
 		leaseCheckpointHeap:       make(LeaseQueue, 0),
 		b:                         b,
@@ -228,12 +258,14 @@ func newLessor(lg *zap.Logger, b backend.Backend, cluster cluster, cfg LessorCon
 		expiredC: make(chan []*Lease, 16),
 		stopC:    make(chan struct{}),
 		doneC:    make(chan struct{}),
+		clears:   newTTLClears(),
 		lg:       lg,
 		cluster:  cluster,
 	}
 	l.initAndRecover()
 
 	go l.runLoop()
+	go l.clearLoop()
 
 	return l
 }
@@ -269,7 +301,33 @@ func (le *lessor) SetCheckpointer(cp Checkpointer) {
 	le.cp = cp
 }
 
+func (le *lessor) SetEventNotifier(en EventNotifier) {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	le.en = en
+}
+
+// notify notifies the event notifier, if set, of evs. le.mu must not be held.
+func (le *lessor) notify(evs []Event) {
+	le.mu.RLock()
+	en := le.en
+	le.mu.RUnlock()
+	if en != nil && len(evs) > 0 {
+		en(evs)
+	}
+}
+
 func (le *lessor) Grant(id LeaseID, ttl int64) (*Lease, error) {
+	l, err := le.grant(id, ttl)
+	if err != nil {
+		return nil, err
+	}
+	le.notify([]Event{{Type: EventGranted, ID: id, TTL: l.ttl}})
+	return l, nil
+}
+
+func (le *lessor) grant(id LeaseID, ttl int64) (*Lease, error) {
 	if id == NoLease {
 		return nil, ErrLeaseNotFound
 	}
@@ -320,19 +378,43 @@ func (le *lessor) Grant(id LeaseID, ttl int64) (*Lease, error) {
 }
 
 func (le *lessor) Revoke(id LeaseID) error {
+	return le.revoke(id, EventRevoked)
+}
+
+func (le *lessor) Expire(id LeaseID) error {
+	return le.revoke(id, EventExpired)
+}
+
+// revoke revokes the lease id, notifying an event of type typ followed by
+// the keys deleted.
+func (le *lessor) revoke(id LeaseID, typ EventType) error {
+	keys, err := le.deleteLease(id)
+	if err != nil {
+		return err
+	}
+	evs := []Event{{Type: typ, ID: id}}
+	if len(keys) > 0 {
+		evs = append(evs, Event{Type: EventKeysDeleted, ID: id, Keys: keys})
+	}
+	le.notify(evs)
+	return nil
+}
+
+// deleteLease deletes the lease id and its keys, returning the sorted keys.
+func (le *lessor) deleteLease(id LeaseID) ([]string, error) {
 	le.mu.Lock()
 
 	l := le.leaseMap[id]
 	if l == nil {
 		le.mu.Unlock()
-		return ErrLeaseNotFound
+		return nil, ErrLeaseNotFound
 	}
 	defer close(l.revokec)
 	// unlock before doing external work
 	le.mu.Unlock()
 
 	if le.rd == nil {
-		return nil
+		return nil, nil
 	}
 
 	txn := le.rd()
@@ -348,6 +430,7 @@ func (le *lessor) Revoke(id LeaseID) error {
 	le.mu.Lock()
 	defer le.mu.Unlock()
 	delete(le.leaseMap, l.ID)
+	le.unsafeDisown(l)
 	// lease deletion needs to be in the same backend transaction with the
 	// kv deletion. Or we might end up with not executing the revoke or not
 	// deleting the keys if etcdserver fails in between.
@@ -356,9 +439,42 @@ func (le *lessor) Revoke(id LeaseID) error {
 	txn.End()
 
 	leaseRevoked.Inc()
+	return keys, nil
+}
+
+func (le *lessor) SetOwner(id LeaseID, owner string) error {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	l := le.leaseMap[id]
+	if l == nil {
+		return ErrLeaseNotFound
+	}
+	le.unsafeDisown(l)
+	l.owner = owner
+	if owner != "" {
+		le.owners[owner]++
+	}
+	l.persistTo(le.b)
 	return nil
 }
 
+func (le *lessor) OwnedLeases(owner string) int {
+	le.mu.RLock()
+	defer le.mu.RUnlock()
+	return le.owners[owner]
+}
+
+// unsafeDisown stops counting the lease l for its owner, if any.
+func (le *lessor) unsafeDisown(l *Lease) {
+	if l.owner == "" {
+		return
+	}
+	if le.owners[l.owner]--; le.owners[l.owner] <= 0 {
+		delete(le.owners, l.owner)
+	}
+}
+
 func (le *lessor) Checkpoint(id LeaseID, remainingTTL int64) error {
 	le.mu.Lock()
 	defer le.mu.Unlock()
@@ -425,21 +541,112 @@ func (le *lessor) Renew(id LeaseID) (int64, error) {
 
 	// Clear remaining TTL when we renew if it is set
 	// By applying a RAFT entry only when the remainingTTL is already set, we limit the number
-	// of RAFT entries written per lease to a max of 2 per checkpoint interval.
+	// of RAFT entries written per lease to a max of 2 per checkpoint interval. The clears of
+	// the leases renewed concurrently share their RAFT entries.
 	if clearRemainingTTL {
-		le.cp(context.Background(), &pb.LeaseCheckpointRequest{Checkpoints: []*pb.LeaseCheckpoint{{ID: int64(l.ID), Remaining_TTL: 0}}})
+		if err := le.clearRemainingTTL([]LeaseID{l.ID}); err != nil {
+			return -1, err
+		}
 	}
 
 	le.mu.Lock()
+	gap := renewedAfterGap(l, l.Remaining())
 	l.refresh(0)
 	item := &LeaseWithTime{id: l.ID, time: l.expiry}
 	le.leaseExpiredNotifier.RegisterOrUpdate(item)
 	le.mu.Unlock()
 
 	leaseRenewed.Inc()
+	if gap {
+		le.notify([]Event{{Type: EventRenewedAfterGap, ID: l.ID, TTL: l.ttl}})
+	}
 	return l.ttl, nil
 }
 
+// RenewResult is the outcome of renewing a single lease of a batch.
+type RenewResult struct {
+	ID  LeaseID
+	TTL int64
+	Err error
+}
+
+func (le *lessor) RenewBatch(ids []LeaseID) ([]RenewResult, error) {
+	le.mu.RLock()
+	if !le.isPrimary() {
+		le.mu.RUnlock()
+		return nil, ErrNotPrimary
+	}
+
+	demotec := le.demotec
+
+	results := make([]RenewResult, len(ids))
+	leases := make([]*Lease, len(ids))
+	clearRemainingTTL := make([]bool, len(ids))
+	for i, id := range ids {
+		results[i] = RenewResult{ID: id, TTL: -1}
+		l := le.leaseMap[id]
+		if l == nil {
+			results[i].Err = ErrLeaseNotFound
+			continue
+		}
+		leases[i] = l
+		clearRemainingTTL[i] = le.cp != nil && l.remainingTTL > 0
+	}
+	le.mu.RUnlock()
+
+	var clears []LeaseID
+	for i, l := range leases {
+		if l == nil {
+			continue
+		}
+		if l.expired() {
+			// Same as Renew, an expired lease must wait for its pending revoke.
+			select {
+			case <-l.revokec:
+				results[i].Err = ErrLeaseNotFound
+				leases[i] = nil
+				continue
+			case <-demotec:
+				return nil, ErrNotPrimary
+			case <-le.stopC:
+				return nil, ErrNotPrimary
+			}
+		}
+		if clearRemainingTTL[i] {
+			clears = append(clears, l.ID)
+		}
+	}
+
+	if len(clears) > 0 {
+		if err := le.clearRemainingTTL(clears); err != nil {
+			return nil, err
+		}
+	}
+
+	renewed := 0
+	var evs []Event
+	le.mu.Lock()
+	for i, l := range leases {
+		if l == nil {
+			continue
+		}
+		if renewedAfterGap(l, l.Remaining()) {
+			evs = append(evs, Event{Type: EventRenewedAfterGap, ID: l.ID, TTL: l.ttl})
+		}
+		l.refresh(0)
+		item := &LeaseWithTime{id: l.ID, time: l.expiry}
+		le.leaseExpiredNotifier.RegisterOrUpdate(item)
+		results[i].TTL = l.ttl
+		renewed++
+	}
+	le.mu.Unlock()
+
+	leaseRenewed.Add(float64(renewed))
+	leaseRenewBatchSize.Observe(float64(len(ids)))
+	le.notify(evs)
+	return results, nil
+}
+
 func (le *lessor) Lookup(id LeaseID) *Lease {
 	le.mu.RLock()
 	defer le.mu.RUnlock()
@@ -596,6 +803,7 @@ func (le *lessor) Recover(b backend.Backend, rd RangeDeleter) {
 	le.rd = rd
 	le.leaseMap = make(map[LeaseID]*Lease)
 	le.itemMap = make(map[LeaseItem]LeaseID)
+	le.owners = make(map[string]int)
 	le.initAndRecover()
 }
 
@@ -606,6 +814,7 @@ func (le *lessor) ExpiredLeasesC() <-chan []*Lease {
 func (le *lessor) Stop() {
 	close(le.stopC)
 	<-le.doneC
+	<-le.clears.stoppedc
 }
 
 func (le *lessor) runLoop() {
@@ -815,6 +1024,10 @@ func (le *lessor) initAndRecover() {
 			expiry:       forever,
 			revokec:      make(chan struct{}),
 			remainingTTL: lpb.RemainingTTL,
+			owner:        lpb.Owner,
+		}
+		if lpb.Owner != "" {
+			le.owners[lpb.Owner]++
 		}
 	}
 	le.leaseExpiredNotifier.Init()
@@ -827,6 +1040,7 @@ type Lease struct {
 	ID           LeaseID
 	ttl          int64 // time to live of the lease in seconds
 	remainingTTL int64 // remaining time to live in seconds, if zero valued it is considered unset and the full ttl should be used
+	owner        string // user who granted the lease, if authenticated
 	// expiryMu protects concurrent accesses to expiry
 	expiryMu sync.RWMutex
 	// expiry is time when lease should expire. no expiration when expiry.IsZero() is true
@@ -843,7 +1057,7 @@ func (l *Lease) expired() bool {
 }
 
 func (l *Lease) persistTo(b backend.Backend) {
-	lpb := leasepb.Lease{ID: int64(l.ID), TTL: l.ttl, RemainingTTL: l.remainingTTL}
+	lpb := leasepb.Lease{ID: int64(l.ID), TTL: l.ttl, RemainingTTL: l.remainingTTL, Owner: l.owner}
 	tx := b.BatchTx()
 	tx.LockInsideApply()
 	defer tx.Unlock()
@@ -855,6 +1069,11 @@ func (l *Lease) TTL() int64 {
 	return l.ttl
 }
 
+// Owner returns the user who granted the lease, empty if not authenticated.
+func (l *Lease) Owner() string {
+	return l.owner
+}
+
 // RemainingTTL returns the last checkpointed remaining TTL of the lease.
 func (l *Lease) getRemainingTTL() int64 {
 	if l.remainingTTL > 0 {
@@ -889,6 +1108,25 @@ func (l *Lease) Keys() []string {
 	return keys
 }
 
+// KeyCount returns the number of keys attached to the lease.
+func (l *Lease) KeyCount() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.itemSet)
+}
+
+// HasKeyWithPrefix returns whether a key attached to the lease has the prefix.
+func (l *Lease) HasKeyWithPrefix(prefix string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for k := range l.itemSet {
+		if strings.HasPrefix(k.Key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // Remaining returns the remaining time of the lease.
 func (l *Lease) Remaining() time.Duration {
 	l.expiryMu.RLock()
@@ -917,10 +1155,18 @@ func (fl *FakeLessor) SetRangeDeleter(dr RangeDeleter) {}
 
 func (fl *FakeLessor) SetCheckpointer(cp Checkpointer) {}
 
+func (fl *FakeLessor) SetEventNotifier(en EventNotifier) {}
+
 func (fl *FakeLessor) Grant(id LeaseID, ttl int64) (*Lease, error) { return nil, nil }
 
 func (fl *FakeLessor) Revoke(id LeaseID) error { return nil }
 
+func (fl *FakeLessor) Expire(id LeaseID) error { return nil }
+
+func (fl *FakeLessor) SetOwner(id LeaseID, owner string) error { return nil }
+
+func (fl *FakeLessor) OwnedLeases(owner string) int { return 0 }
+
 func (fl *FakeLessor) Checkpoint(id LeaseID, remainingTTL int64) error { return nil }
 
 func (fl *FakeLessor) Attach(id LeaseID, items []LeaseItem) error { return nil }
@@ -934,6 +1180,14 @@ func (fl *FakeLessor) Demote() {}
 
 func (fl *FakeLessor) Renew(id LeaseID) (int64, error) { return 10, nil }
 
+func (fl *FakeLessor) RenewBatch(ids []LeaseID) ([]RenewResult, error) {
+	results := make([]RenewResult, len(ids))
+	for i, id := range ids {
+		results[i] = RenewResult{ID: id, TTL: 10}
+	}
+	return results, nil
+}
+
 func (fl *FakeLessor) Lookup(id LeaseID) *Lease { return nil }
 
 func (fl *FakeLessor) Leases() []*Lease { return nil }
@@ -99,7 +99,7 @@ func TestLessorGrant(t *testing.T) {
 	defer tx.Unlock()
 	lpb := schema.MustUnsafeGetLease(tx, int64(l.ID))
 	if lpb == nil {
-		t.Errorf("lpb = %d, want not nil", lpb)
+		t.Errorf("lpb = %v, want not nil", lpb)
 	}
 }
 
@@ -203,7 +203,64 @@ func TestLessorRevoke(t *testing.T) {
 	defer tx.Unlock()
 	lpb := schema.MustUnsafeGetLease(tx, int64(l.ID))
 	if lpb != nil {
-		t.Errorf("lpb = %d, want nil", lpb)
+		t.Errorf("lpb = %v, want nil", lpb)
+	}
+}
+
+// TestLessorEvents ensures Lessor notifies the lifecycle events of the leases.
+func TestLessorEvents(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer le.Stop()
+	le.SetRangeDeleter(func() TxnDelete { return newFakeDeleter(be) })
+	var evs []Event
+	le.SetEventNotifier(func(es []Event) { evs = append(evs, es...) })
+	le.Promote(0)
+
+	if _, err := le.Grant(1, 100); err != nil {
+		t.Fatal(err)
+	}
+	l, err := le.Grant(2, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = le.Attach(2, []LeaseItem{{"foo"}, {"bar"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// renewed on time
+	if _, err = le.Renew(1); err != nil {
+		t.Fatal(err)
+	}
+	// renewed after a gap
+	l.expiryMu.Lock()
+	l.expiry = time.Now().Add(time.Second)
+	l.expiryMu.Unlock()
+	if _, err = le.Renew(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = le.Revoke(1); err != nil {
+		t.Fatal(err)
+	}
+	if err = le.Expire(2); err != nil {
+		t.Fatal(err)
+	}
+
+	wevs := []Event{
+		{Type: EventGranted, ID: 1, TTL: 100},
+		{Type: EventGranted, ID: 2, TTL: 100},
+		{Type: EventRenewedAfterGap, ID: 2, TTL: 100},
+		{Type: EventRevoked, ID: 1},
+		{Type: EventExpired, ID: 2},
+		{Type: EventKeysDeleted, ID: 2, Keys: []string{"bar", "foo"}},
+	}
+	if !reflect.DeepEqual(evs, wevs) {
+		t.Errorf("events = %+v, want %+v", evs, wevs)
 	}
 }
 
@@ -241,6 +298,55 @@ func TestLessorRenew(t *testing.T) {
 	}
 }
 
+func TestLessorRenewBatch(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer be.Close()
+	defer os.RemoveAll(dir)
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer le.Stop()
+	le.Promote(0)
+
+	var ids []LeaseID
+	for i := 1; i <= 3; i++ {
+		l, err := le.Grant(LeaseID(i), minLeaseTTL)
+		if err != nil {
+			t.Fatalf("failed to grant lease (%v)", err)
+		}
+		// manually change the ttl field
+		le.mu.Lock()
+		l.ttl = 10
+		le.mu.Unlock()
+		ids = append(ids, l.ID)
+	}
+	ids = append(ids, LeaseID(100))
+
+	results, err := le.RenewBatch(ids)
+	if err != nil {
+		t.Fatalf("failed to renew leases (%v)", err)
+	}
+	if len(results) != len(ids) {
+		t.Fatalf("len(results) = %d, want %d", len(results), len(ids))
+	}
+	for i, r := range results[:3] {
+		if r.ID != ids[i] || r.TTL != 10 || r.Err != nil {
+			t.Errorf("#%d: result = %+v, want ID %d with TTL 10", i, r, ids[i])
+		}
+		if l := le.Lookup(r.ID); l.Remaining() < 9*time.Second {
+			t.Errorf("#%d: failed to renew the lease", i)
+		}
+	}
+	if r := results[3]; r.ID != 100 || r.TTL != -1 || r.Err != ErrLeaseNotFound {
+		t.Errorf("result = %+v, want ErrLeaseNotFound for lease 100", r)
+	}
+
+	le.Demote()
+	if _, err := le.RenewBatch(ids); err != ErrNotPrimary {
+		t.Errorf("err = %v, want %v", err, ErrNotPrimary)
+	}
+}
+
 func TestLessorRenewWithCheckpointer(t *testing.T) {
 	lg := zap.NewNop()
 	dir, be := NewTestBackend(t)
@@ -285,6 +391,85 @@ func TestLessorRenewWithCheckpointer(t *testing.T) {
 	}
 }
 
+// TestLessorRenewCoalescesTTLClears ensures the remaining TTLs of the leases
+// renewed while a clear is in flight are cleared together by the next one.
+func TestLessorRenewCoalescesTTLClears(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer be.Close()
+	defer os.RemoveAll(dir)
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer le.Stop()
+	var (
+		mu      sync.Mutex
+		batches []int
+	)
+	startedc, releasec := make(chan struct{}, 1), make(chan struct{})
+	le.SetCheckpointer(func(ctx context.Context, cp *pb.LeaseCheckpointRequest) {
+		mu.Lock()
+		batches = append(batches, len(cp.Checkpoints))
+		mu.Unlock()
+		select {
+		case startedc <- struct{}{}:
+		default:
+		}
+		<-releasec
+		for _, c := range cp.Checkpoints {
+			le.Checkpoint(LeaseID(c.ID), c.Remaining_TTL)
+		}
+	})
+	le.Promote(0)
+
+	const n = 5
+	for id := LeaseID(1); id <= n; id++ {
+		if _, err := le.Grant(id, 10); err != nil {
+			t.Fatal(err)
+		}
+		if err := le.Checkpoint(id, 5); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	errc := make(chan error, n)
+	renew := func(id LeaseID) {
+		_, err := le.Renew(id)
+		errc <- err
+	}
+	// the first clear is in flight while the others are requested
+	go renew(1)
+	<-startedc
+	for id := LeaseID(2); id <= n; id++ {
+		go renew(id)
+	}
+	for {
+		le.clears.mu.Lock()
+		pending := len(le.clears.ids)
+		le.clears.mu.Unlock()
+		if pending == n-1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(releasec)
+	for i := 0; i < n; i++ {
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if wbatches := []int{1, n - 1}; !reflect.DeepEqual(batches, wbatches) {
+		t.Errorf("batches = %v, want %v", batches, wbatches)
+	}
+	for id := LeaseID(1); id <= n; id++ {
+		if ttl := le.Lookup(id).getRemainingTTL(); ttl != 10 {
+			t.Errorf("lease %d remaining TTL = %d, want 10", id, ttl)
+		}
+	}
+}
+
 // TestLessorRenewExtendPileup ensures Lessor extends leases on promotion if too many
 // expire at the same time.
 func TestLessorRenewExtendPileup(t *testing.T) {
@@ -408,6 +593,52 @@ func TestLessorRecover(t *testing.T) {
 	}
 }
 
+// TestLessorOwnedLeases ensures Lessor counts the leases held by their owners,
+// across a recovery.
+func TestLessorOwnedLeases(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer le.Stop()
+	le.SetRangeDeleter(func() TxnDelete { return newFakeDeleter(be) })
+	for id := LeaseID(1); id <= 3; id++ {
+		if _, err := le.Grant(id, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for id, owner := range map[LeaseID]string{1: "alice", 2: "alice", 3: "bob"} {
+		if err := le.SetOwner(id, owner); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := le.SetOwner(4, "alice"); err != ErrLeaseNotFound {
+		t.Fatalf("err = %v, want %v", err, ErrLeaseNotFound)
+	}
+	if err := le.Revoke(3); err != nil {
+		t.Fatal(err)
+	}
+	if n := le.OwnedLeases("alice"); n != 2 {
+		t.Errorf("OwnedLeases(alice) = %d, want 2", n)
+	}
+	if n := le.OwnedLeases("bob"); n != 0 {
+		t.Errorf("OwnedLeases(bob) = %d, want 0", n)
+	}
+
+	// the owners are recovered from the backend
+	be.ForceCommit()
+	nle := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer nle.Stop()
+	if n := nle.OwnedLeases("alice"); n != 2 {
+		t.Errorf("recovered OwnedLeases(alice) = %d, want 2", n)
+	}
+	if owner := nle.Lookup(1).Owner(); owner != "alice" {
+		t.Errorf("recovered owner = %q, want %q", owner, "alice")
+	}
+}
+
 func TestLessorExpire(t *testing.T) {
 	lg := zap.NewNop()
 	dir, be := NewTestBackend(t)
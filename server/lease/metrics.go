@@ -40,6 +40,24 @@ var (
 		Help:      "The number of renewed leases seen by the leader.",
 	})
 
+	leaseRenewBatchSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "etcd_debugging",
+		Subsystem: "lease",
+		Name:      "renew_batch_size",
+		Help:      "Bucketed histogram of the number of leases renewed by a batched keepalive seen by the leader.",
+		// 1 -> 65536 leases
+		Buckets: prometheus.ExponentialBuckets(1, 2, 17),
+	})
+
+	leaseTTLClearBatchSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "etcd_debugging",
+		Subsystem: "lease",
+		Name:      "ttl_clear_batch_size",
+		Help:      "Bucketed histogram of the number of renewed leases whose remaining TTL is cleared by a proposal.",
+		// 1 -> 65536 leases
+		Buckets: prometheus.ExponentialBuckets(1, 2, 17),
+	})
+
 	leaseTotalTTLs = prometheus.NewHistogram(
 		prometheus.HistogramOpts{
 			Namespace: "etcd_debugging",
@@ -55,5 +73,7 @@ func init() {
 	prometheus.MustRegister(leaseGranted)
 	prometheus.MustRegister(leaseRevoked)
 	prometheus.MustRegister(leaseRenewed)
+	prometheus.MustRegister(leaseRenewBatchSize)
+	prometheus.MustRegister(leaseTTLClearBatchSize)
 	prometheus.MustRegister(leaseTotalTTLs)
 }
@@ -0,0 +1,111 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lease
+
+import (
+	"context"
+	"sync"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+// ttlClears coalesces the checkpoints clearing the remaining TTL of the
+// leases renewed by the primary lessor. A renewal of a checkpointed lease must
+// not be acknowledged before its remaining TTL is cleared through raft, or a
+// new leader would expire the lease early; the clears requested while a
+// proposal is in flight are proposed together once it is applied, so the
+// renewals of many checkpointed leases share a few proposals instead of
+// proposing one each.
+type ttlClears struct {
+	mu sync.Mutex
+	// ids are the leases to clear the remaining TTL of in the next proposal.
+	ids []LeaseID
+	// donec is closed once the ids are cleared.
+	donec chan struct{}
+
+	// flushc signals the ids are pending.
+	flushc chan struct{}
+	// stoppedc is closed once the loop proposing the clears returns.
+	stoppedc chan struct{}
+}
+
+func newTTLClears() ttlClears {
+	return ttlClears{flushc: make(chan struct{}, 1), stoppedc: make(chan struct{})}
+}
+
+// clearRemainingTTL clears the remaining TTL of the leases ids through the
+// checkpointer, returning once cleared.
+func (le *lessor) clearRemainingTTL(ids []LeaseID) error {
+	c := &le.clears
+	c.mu.Lock()
+	c.ids = append(c.ids, ids...)
+	if c.donec == nil {
+		c.donec = make(chan struct{})
+	}
+	donec := c.donec
+	c.mu.Unlock()
+
+	select {
+	case c.flushc <- struct{}{}:
+	default:
+	}
+	select {
+	case <-donec:
+		return nil
+	case <-le.stopC:
+		return ErrNotPrimary
+	}
+}
+
+// clearLoop proposes the pending clears of the remaining TTLs, one batch at a
+// time, until the lessor is stopped.
+func (le *lessor) clearLoop() {
+	c := &le.clears
+	defer close(c.stoppedc)
+
+	for {
+		select {
+		case <-c.flushc:
+		case <-le.stopC:
+			return
+		}
+
+		c.mu.Lock()
+		ids, donec := c.ids, c.donec
+		c.ids, c.donec = nil, nil
+		c.mu.Unlock()
+		if len(ids) == 0 {
+			continue
+		}
+
+		le.mu.RLock()
+		cp := le.cp
+		le.mu.RUnlock()
+		cps := make([]*pb.LeaseCheckpoint, len(ids))
+		for i, id := range ids {
+			cps[i] = &pb.LeaseCheckpoint{ID: int64(id), Remaining_TTL: 0}
+		}
+		for len(cps) > 0 {
+			n := len(cps)
+			if n > maxLeaseCheckpointBatchSize {
+				n = maxLeaseCheckpointBatchSize
+			}
+			cp(context.Background(), &pb.LeaseCheckpointRequest{Checkpoints: cps[:n]})
+			cps = cps[n:]
+		}
+		leaseTTLClearBatchSize.Observe(float64(len(ids)))
+		close(donec)
+	}
+}
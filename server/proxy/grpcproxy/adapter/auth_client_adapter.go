@@ -95,3 +95,19 @@ func (s *as2ac) UserRevokeRole(ctx context.Context, in *pb.AuthUserRevokeRoleReq
 func (s *as2ac) UserChangePassword(ctx context.Context, in *pb.AuthUserChangePasswordRequest, opts ...grpc.CallOption) (*pb.AuthUserChangePasswordResponse, error) {
 	return s.as.UserChangePassword(ctx, in)
 }
+
+func (s *as2ac) AuthLockoutList(ctx context.Context, in *pb.AuthLockoutListRequest, opts ...grpc.CallOption) (*pb.AuthLockoutListResponse, error) {
+	return s.as.AuthLockoutList(ctx, in)
+}
+
+func (s *as2ac) AuthLockoutClear(ctx context.Context, in *pb.AuthLockoutClearRequest, opts ...grpc.CallOption) (*pb.AuthLockoutClearResponse, error) {
+	return s.as.AuthLockoutClear(ctx, in)
+}
+
+func (s *as2ac) AuthSessionList(ctx context.Context, in *pb.AuthSessionListRequest, opts ...grpc.CallOption) (*pb.AuthSessionListResponse, error) {
+	return s.as.AuthSessionList(ctx, in)
+}
+
+func (s *as2ac) AuthSessionRevoke(ctx context.Context, in *pb.AuthSessionRevokeRequest, opts ...grpc.CallOption) (*pb.AuthSessionRevokeResponse, error) {
+	return s.as.AuthSessionRevoke(ctx, in)
+}
@@ -44,6 +44,30 @@ func (s *kvs2kvc) Txn(ctx context.Context, in *pb.TxnRequest, opts ...grpc.CallO
 	return s.kvs.Txn(ctx, in)
 }
 
+func (s *kvs2kvc) Move(ctx context.Context, in *pb.MoveRequest, opts ...grpc.CallOption) (*pb.MoveResponse, error) {
+	return s.kvs.Move(ctx, in)
+}
+
+func (s *kvs2kvc) SetLease(ctx context.Context, in *pb.SetLeaseRequest, opts ...grpc.CallOption) (*pb.SetLeaseResponse, error) {
+	return s.kvs.SetLease(ctx, in)
+}
+
+func (s *kvs2kvc) Increment(ctx context.Context, in *pb.IncrementRequest, opts ...grpc.CallOption) (*pb.IncrementResponse, error) {
+	return s.kvs.Increment(ctx, in)
+}
+
+func (s *kvs2kvc) PutIfAbsent(ctx context.Context, in *pb.PutIfAbsentRequest, opts ...grpc.CallOption) (*pb.PutIfAbsentResponse, error) {
+	return s.kvs.PutIfAbsent(ctx, in)
+}
+
+func (s *kvs2kvc) GetAndDelete(ctx context.Context, in *pb.GetAndDeleteRequest, opts ...grpc.CallOption) (*pb.GetAndDeleteResponse, error) {
+	return s.kvs.GetAndDelete(ctx, in)
+}
+
+func (s *kvs2kvc) BulkWrite(ctx context.Context, in *pb.BulkWriteRequest, opts ...grpc.CallOption) (*pb.BulkWriteResponse, error) {
+	return s.kvs.BulkWrite(ctx, in)
+}
+
 func (s *kvs2kvc) Compact(ctx context.Context, in *pb.CompactionRequest, opts ...grpc.CallOption) (*pb.CompactionResponse, error) {
 	return s.kvs.Compact(ctx, in)
 }
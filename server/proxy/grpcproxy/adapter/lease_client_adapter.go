@@ -38,6 +38,18 @@ func (c *ls2lc) LeaseRevoke(ctx context.Context, in *pb.LeaseRevokeRequest, opts
 	return c.leaseServer.LeaseRevoke(ctx, in)
 }
 
+func (c *ls2lc) LeaseGrantBulk(ctx context.Context, in *pb.LeaseGrantBulkRequest, opts ...grpc.CallOption) (*pb.LeaseGrantBulkResponse, error) {
+	return c.leaseServer.LeaseGrantBulk(ctx, in)
+}
+
+func (c *ls2lc) LeaseRevokeBulk(ctx context.Context, in *pb.LeaseRevokeBulkRequest, opts ...grpc.CallOption) (*pb.LeaseRevokeBulkResponse, error) {
+	return c.leaseServer.LeaseRevokeBulk(ctx, in)
+}
+
+func (c *ls2lc) LeaseKeepAliveOnce(ctx context.Context, in *pb.LeaseKeepAliveRequest, opts ...grpc.CallOption) (*pb.LeaseKeepAliveResponse, error) {
+	return c.leaseServer.LeaseKeepAliveOnce(ctx, in)
+}
+
 func (c *ls2lc) LeaseKeepAlive(ctx context.Context, opts ...grpc.CallOption) (pb.Lease_LeaseKeepAliveClient, error) {
 	cs := newPipeStream(ctx, func(ss chanServerStream) error {
 		return c.leaseServer.LeaseKeepAlive(&ls2lcServerStream{ss})
@@ -45,6 +57,13 @@ func (c *ls2lc) LeaseKeepAlive(ctx context.Context, opts ...grpc.CallOption) (pb
 	return &ls2lcClientStream{cs}, nil
 }
 
+func (c *ls2lc) LeaseKeepAliveBatch(ctx context.Context, opts ...grpc.CallOption) (pb.Lease_LeaseKeepAliveBatchClient, error) {
+	cs := newPipeStream(ctx, func(ss chanServerStream) error {
+		return c.leaseServer.LeaseKeepAliveBatch(&ls2lcBatchServerStream{ss})
+	})
+	return &ls2lcBatchClientStream{cs}, nil
+}
+
 func (c *ls2lc) LeaseTimeToLive(ctx context.Context, in *pb.LeaseTimeToLiveRequest, opts ...grpc.CallOption) (*pb.LeaseTimeToLiveResponse, error) {
 	return c.leaseServer.LeaseTimeToLive(ctx, in)
 }
@@ -80,3 +99,31 @@ func (s *ls2lcServerStream) Recv() (*pb.LeaseKeepAliveRequest, error) {
 	}
 	return v.(*pb.LeaseKeepAliveRequest), nil
 }
+
+// ls2lcBatchClientStream implements Lease_LeaseKeepAliveBatchClient
+type ls2lcBatchClientStream struct{ chanClientStream }
+
+// ls2lcBatchServerStream implements Lease_LeaseKeepAliveBatchServer
+type ls2lcBatchServerStream struct{ chanServerStream }
+
+func (s *ls2lcBatchClientStream) Send(rr *pb.LeaseKeepAliveBatchRequest) error {
+	return s.SendMsg(rr)
+}
+func (s *ls2lcBatchClientStream) Recv() (*pb.LeaseKeepAliveBatchResponse, error) {
+	var v interface{}
+	if err := s.RecvMsg(&v); err != nil {
+		return nil, err
+	}
+	return v.(*pb.LeaseKeepAliveBatchResponse), nil
+}
+
+func (s *ls2lcBatchServerStream) Send(rr *pb.LeaseKeepAliveBatchResponse) error {
+	return s.SendMsg(rr)
+}
+func (s *ls2lcBatchServerStream) Recv() (*pb.LeaseKeepAliveBatchRequest, error) {
+	var v interface{}
+	if err := s.RecvMsg(&v); err != nil {
+		return nil, err
+	}
+	return v.(*pb.LeaseKeepAliveBatchRequest), nil
+}
@@ -56,6 +56,70 @@ func (s *mts2mtc) Downgrade(ctx context.Context, r *pb.DowngradeRequest, opts ..
 	return s.mts.Downgrade(ctx, r)
 }
 
+func (s *mts2mtc) BackendBatch(ctx context.Context, r *pb.BackendBatchRequest, opts ...grpc.CallOption) (*pb.BackendBatchResponse, error) {
+	return s.mts.BackendBatch(ctx, r)
+}
+
+func (s *mts2mtc) QuotaStatus(ctx context.Context, r *pb.QuotaStatusRequest, opts ...grpc.CallOption) (*pb.QuotaStatusResponse, error) {
+	return s.mts.QuotaStatus(ctx, r)
+}
+
+func (s *mts2mtc) ResetQuotaAlarm(ctx context.Context, r *pb.ResetQuotaAlarmRequest, opts ...grpc.CallOption) (*pb.ResetQuotaAlarmResponse, error) {
+	return s.mts.ResetQuotaAlarm(ctx, r)
+}
+
+func (s *mts2mtc) LogLevel(ctx context.Context, r *pb.LogLevelRequest, opts ...grpc.CallOption) (*pb.LogLevelResponse, error) {
+	return s.mts.LogLevel(ctx, r)
+}
+
+func (s *mts2mtc) WatchStreams(ctx context.Context, r *pb.WatchStreamsRequest, opts ...grpc.CallOption) (*pb.WatchStreamsResponse, error) {
+	return s.mts.WatchStreams(ctx, r)
+}
+
+func (s *mts2mtc) Watchers(ctx context.Context, r *pb.WatchersRequest, opts ...grpc.CallOption) (*pb.WatchersResponse, error) {
+	return s.mts.Watchers(ctx, r)
+}
+
+func (s *mts2mtc) CancelWatcher(ctx context.Context, r *pb.CancelWatcherRequest, opts ...grpc.CallOption) (*pb.CancelWatcherResponse, error) {
+	return s.mts.CancelWatcher(ctx, r)
+}
+
+func (s *mts2mtc) HotKeys(ctx context.Context, r *pb.HotKeysRequest, opts ...grpc.CallOption) (*pb.HotKeysResponse, error) {
+	return s.mts.HotKeys(ctx, r)
+}
+
+func (s *mts2mtc) ClusterHistory(ctx context.Context, r *pb.ClusterHistoryRequest, opts ...grpc.CallOption) (*pb.ClusterHistoryResponse, error) {
+	return s.mts.ClusterHistory(ctx, r)
+}
+
+func (s *mts2mtc) RuntimeConfig(ctx context.Context, r *pb.RuntimeConfigRequest, opts ...grpc.CallOption) (*pb.RuntimeConfigResponse, error) {
+	return s.mts.RuntimeConfig(ctx, r)
+}
+
+func (s *mts2mtc) DefragmentStatus(ctx context.Context, r *pb.DefragmentStatusRequest, opts ...grpc.CallOption) (*pb.DefragmentStatusResponse, error) {
+	return s.mts.DefragmentStatus(ctx, r)
+}
+
+func (s *mts2mtc) PrefixQuotaSet(ctx context.Context, r *pb.PrefixQuotaSetRequest, opts ...grpc.CallOption) (*pb.PrefixQuotaSetResponse, error) {
+	return s.mts.PrefixQuotaSet(ctx, r)
+}
+
+func (s *mts2mtc) PrefixQuotaDelete(ctx context.Context, r *pb.PrefixQuotaDeleteRequest, opts ...grpc.CallOption) (*pb.PrefixQuotaDeleteResponse, error) {
+	return s.mts.PrefixQuotaDelete(ctx, r)
+}
+
+func (s *mts2mtc) PrefixQuotaList(ctx context.Context, r *pb.PrefixQuotaListRequest, opts ...grpc.CallOption) (*pb.PrefixQuotaListResponse, error) {
+	return s.mts.PrefixQuotaList(ctx, r)
+}
+
+func (s *mts2mtc) PrefixStats(ctx context.Context, r *pb.PrefixStatsRequest, opts ...grpc.CallOption) (*pb.PrefixStatsResponse, error) {
+	return s.mts.PrefixStats(ctx, r)
+}
+
+func (s *mts2mtc) LearnerStatus(ctx context.Context, r *pb.LearnerStatusRequest, opts ...grpc.CallOption) (*pb.LearnerStatusResponse, error) {
+	return s.mts.LearnerStatus(ctx, r)
+}
+
 func (s *mts2mtc) Snapshot(ctx context.Context, in *pb.SnapshotRequest, opts ...grpc.CallOption) (pb.Maintenance_SnapshotClient, error) {
 	cs := newPipeStream(ctx, func(ss chanServerStream) error {
 		return s.mts.Snapshot(in, &ss2scServerStream{ss})
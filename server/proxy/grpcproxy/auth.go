@@ -113,3 +113,23 @@ func (ap *AuthProxy) UserChangePassword(ctx context.Context, r *pb.AuthUserChang
 	conn := ap.client.ActiveConnection()
 	return pb.NewAuthClient(conn).UserChangePassword(ctx, r)
 }
+
+func (ap *AuthProxy) AuthLockoutList(ctx context.Context, r *pb.AuthLockoutListRequest) (*pb.AuthLockoutListResponse, error) {
+	conn := ap.client.ActiveConnection()
+	return pb.NewAuthClient(conn).AuthLockoutList(ctx, r)
+}
+
+func (ap *AuthProxy) AuthLockoutClear(ctx context.Context, r *pb.AuthLockoutClearRequest) (*pb.AuthLockoutClearResponse, error) {
+	conn := ap.client.ActiveConnection()
+	return pb.NewAuthClient(conn).AuthLockoutClear(ctx, r)
+}
+
+func (ap *AuthProxy) AuthSessionList(ctx context.Context, r *pb.AuthSessionListRequest) (*pb.AuthSessionListResponse, error) {
+	conn := ap.client.ActiveConnection()
+	return pb.NewAuthClient(conn).AuthSessionList(ctx, r)
+}
+
+func (ap *AuthProxy) AuthSessionRevoke(ctx context.Context, r *pb.AuthSessionRevokeRequest) (*pb.AuthSessionRevokeResponse, error) {
+	conn := ap.client.ActiveConnection()
+	return pb.NewAuthClient(conn).AuthSessionRevoke(ctx, r)
+}
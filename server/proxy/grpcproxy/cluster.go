@@ -134,6 +134,10 @@ func (cp *clusterProxy) MemberUpdate(ctx context.Context, r *pb.MemberUpdateRequ
 	return cp.clus.MemberUpdate(ctx, r)
 }
 
+func (cp *clusterProxy) MemberReplace(ctx context.Context, r *pb.MemberReplaceRequest) (*pb.MemberReplaceResponse, error) {
+	return cp.clus.MemberReplace(ctx, r)
+}
+
 func (cp *clusterProxy) membersFromUpdates() ([]*pb.Member, error) {
 	cp.umu.RLock()
 	defer cp.umu.RUnlock()
@@ -123,6 +123,105 @@ func (p *kvProxy) Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnResponse, e
 	return (*pb.TxnResponse)(resp), nil
 }
 
+func (p *kvProxy) Move(ctx context.Context, r *pb.MoveRequest) (*pb.MoveResponse, error) {
+	var opts []clientv3.OpOption
+	if r.Prefix {
+		opts = append(opts, clientv3.WithPrefix())
+	}
+	if r.Overwrite {
+		opts = append(opts, clientv3.WithOverwrite())
+	}
+	if r.PrevKv {
+		opts = append(opts, clientv3.WithPrevKV())
+	}
+	resp, err := p.kv.Move(ctx, string(r.Key), string(r.Destination), opts...)
+	if err == nil {
+		var end, dstEnd []byte
+		if r.Prefix {
+			end, dstEnd = []byte(clientv3.GetPrefixRangeEnd(string(r.Key))), []byte(clientv3.GetPrefixRangeEnd(string(r.Destination)))
+		}
+		p.cache.Invalidate(r.Key, end)
+		p.cache.Invalidate(r.Destination, dstEnd)
+	}
+
+	cacheKeys.Set(float64(p.cache.Size()))
+
+	return (*pb.MoveResponse)(resp), err
+}
+
+func (p *kvProxy) SetLease(ctx context.Context, r *pb.SetLeaseRequest) (*pb.SetLeaseResponse, error) {
+	opts := []clientv3.OpOption{clientv3.WithRange(string(r.RangeEnd))}
+	if r.BumpRevision {
+		opts = append(opts, clientv3.WithBumpRevision())
+	}
+	resp, err := p.kv.SetLease(ctx, string(r.Key), clientv3.LeaseID(r.Lease), opts...)
+	if err == nil {
+		p.cache.Invalidate(r.Key, r.RangeEnd)
+	}
+
+	cacheKeys.Set(float64(p.cache.Size()))
+
+	return (*pb.SetLeaseResponse)(resp), err
+}
+
+func (p *kvProxy) Increment(ctx context.Context, r *pb.IncrementRequest) (*pb.IncrementResponse, error) {
+	opts := []clientv3.OpOption{
+		clientv3.WithIncrementInitial(r.Initial),
+		clientv3.WithIncrementWidth(r.Width),
+		clientv3.WithLease(clientv3.LeaseID(r.Lease)),
+	}
+	if r.Format == pb.IncrementRequest_INT64_BIG_ENDIAN {
+		opts = append(opts, clientv3.WithIncrementBinary())
+	}
+	resp, err := p.kv.Increment(ctx, string(r.Key), r.Delta, opts...)
+	if err == nil {
+		p.cache.Invalidate(r.Key, nil)
+	}
+
+	cacheKeys.Set(float64(p.cache.Size()))
+
+	return (*pb.IncrementResponse)(resp), err
+}
+
+func (p *kvProxy) PutIfAbsent(ctx context.Context, r *pb.PutIfAbsentRequest) (*pb.PutIfAbsentResponse, error) {
+	resp, err := p.kv.PutIfAbsent(ctx, string(r.Key), string(r.Value), clientv3.WithLease(clientv3.LeaseID(r.Lease)))
+	if err == nil {
+		p.cache.Invalidate(r.Key, nil)
+	}
+
+	cacheKeys.Set(float64(p.cache.Size()))
+
+	return (*pb.PutIfAbsentResponse)(resp), err
+}
+
+func (p *kvProxy) GetAndDelete(ctx context.Context, r *pb.GetAndDeleteRequest) (*pb.GetAndDeleteResponse, error) {
+	resp, err := p.kv.GetAndDelete(ctx, string(r.Key))
+	if err == nil {
+		p.cache.Invalidate(r.Key, nil)
+	}
+
+	cacheKeys.Set(float64(p.cache.Size()))
+
+	return (*pb.GetAndDeleteResponse)(resp), err
+}
+
+func (p *kvProxy) BulkWrite(ctx context.Context, r *pb.BulkWriteRequest) (*pb.BulkWriteResponse, error) {
+	ops := make([]clientv3.Op, 0, len(r.Ops))
+	for _, op := range r.Ops {
+		ops = append(ops, requestOpToOp(op))
+	}
+	resp, err := p.kv.BulkWrite(ctx, ops...)
+	if err == nil {
+		for _, op := range ops {
+			p.cache.Invalidate(op.KeyBytes(), op.RangeBytes())
+		}
+	}
+
+	cacheKeys.Set(float64(p.cache.Size()))
+
+	return (*pb.BulkWriteResponse)(resp), err
+}
+
 func (p *kvProxy) Compact(ctx context.Context, r *pb.CompactionRequest) (*pb.CompactionResponse, error) {
 	var opts []clientv3.CompactOption
 	if r.Physical {
@@ -91,6 +91,33 @@ func (lp *leaseProxy) LeaseRevoke(ctx context.Context, rr *pb.LeaseRevokeRequest
 	return (*pb.LeaseRevokeResponse)(r), nil
 }
 
+func (lp *leaseProxy) LeaseGrantBulk(ctx context.Context, cr *pb.LeaseGrantBulkRequest) (*pb.LeaseGrantBulkResponse, error) {
+	rp, err := lp.leaseClient.LeaseGrantBulk(ctx, cr, grpc.WaitForReady(true))
+	if err != nil {
+		return nil, err
+	}
+	lp.leader.gotLeader()
+	return rp, nil
+}
+
+func (lp *leaseProxy) LeaseRevokeBulk(ctx context.Context, rr *pb.LeaseRevokeBulkRequest) (*pb.LeaseRevokeBulkResponse, error) {
+	rp, err := lp.leaseClient.LeaseRevokeBulk(ctx, rr, grpc.WaitForReady(true))
+	if err != nil {
+		return nil, err
+	}
+	lp.leader.gotLeader()
+	return rp, nil
+}
+
+func (lp *leaseProxy) LeaseKeepAliveOnce(ctx context.Context, rr *pb.LeaseKeepAliveRequest) (*pb.LeaseKeepAliveResponse, error) {
+	rp, err := lp.leaseClient.LeaseKeepAliveOnce(ctx, rr, grpc.WaitForReady(true))
+	if err != nil {
+		return nil, err
+	}
+	lp.leader.gotLeader()
+	return rp, nil
+}
+
 func (lp *leaseProxy) LeaseTimeToLive(ctx context.Context, rr *pb.LeaseTimeToLiveRequest) (*pb.LeaseTimeToLiveResponse, error) {
 	var (
 		r   *clientv3.LeaseTimeToLiveResponse
@@ -115,19 +142,18 @@ func (lp *leaseProxy) LeaseTimeToLive(ctx context.Context, rr *pb.LeaseTimeToLiv
 }
 
 func (lp *leaseProxy) LeaseLeases(ctx context.Context, rr *pb.LeaseLeasesRequest) (*pb.LeaseLeasesResponse, error) {
-	r, err := lp.lessor.Leases(ctx)
+	rp, err := lp.leaseClient.LeaseLeases(ctx, rr, grpc.WaitForReady(true))
 	if err != nil {
 		return nil, err
 	}
-	leases := make([]*pb.LeaseStatus, len(r.Leases))
-	for i := range r.Leases {
-		leases[i] = &pb.LeaseStatus{ID: int64(r.Leases[i].ID)}
-	}
-	rp := &pb.LeaseLeasesResponse{
-		Header: r.ResponseHeader,
-		Leases: leases,
-	}
-	return rp, err
+	lp.leader.gotLeader()
+	return rp, nil
+}
+
+// LeaseKeepAliveBatch is not proxied; clients fall back to LeaseKeepAlive,
+// which the proxy coalesces through its own lessor.
+func (lp *leaseProxy) LeaseKeepAliveBatch(stream pb.Lease_LeaseKeepAliveBatchServer) error {
+	return status.Error(codes.Unimplemented, "grpcproxy: lease keepalive batching is not supported")
 }
 
 func (lp *leaseProxy) LeaseKeepAlive(stream pb.Lease_LeaseKeepAliveServer) error {
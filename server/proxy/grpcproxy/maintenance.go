@@ -93,3 +93,83 @@ func (mp *maintenanceProxy) Downgrade(ctx context.Context, r *pb.DowngradeReques
 	conn := mp.client.ActiveConnection()
 	return pb.NewMaintenanceClient(conn).Downgrade(ctx, r)
 }
+
+func (mp *maintenanceProxy) BackendBatch(ctx context.Context, r *pb.BackendBatchRequest) (*pb.BackendBatchResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).BackendBatch(ctx, r)
+}
+
+func (mp *maintenanceProxy) QuotaStatus(ctx context.Context, r *pb.QuotaStatusRequest) (*pb.QuotaStatusResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).QuotaStatus(ctx, r)
+}
+
+func (mp *maintenanceProxy) ResetQuotaAlarm(ctx context.Context, r *pb.ResetQuotaAlarmRequest) (*pb.ResetQuotaAlarmResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).ResetQuotaAlarm(ctx, r)
+}
+
+func (mp *maintenanceProxy) LogLevel(ctx context.Context, r *pb.LogLevelRequest) (*pb.LogLevelResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).LogLevel(ctx, r)
+}
+
+func (mp *maintenanceProxy) WatchStreams(ctx context.Context, r *pb.WatchStreamsRequest) (*pb.WatchStreamsResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).WatchStreams(ctx, r)
+}
+
+func (mp *maintenanceProxy) Watchers(ctx context.Context, r *pb.WatchersRequest) (*pb.WatchersResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).Watchers(ctx, r)
+}
+
+func (mp *maintenanceProxy) CancelWatcher(ctx context.Context, r *pb.CancelWatcherRequest) (*pb.CancelWatcherResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).CancelWatcher(ctx, r)
+}
+
+func (mp *maintenanceProxy) HotKeys(ctx context.Context, r *pb.HotKeysRequest) (*pb.HotKeysResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).HotKeys(ctx, r)
+}
+
+func (mp *maintenanceProxy) ClusterHistory(ctx context.Context, r *pb.ClusterHistoryRequest) (*pb.ClusterHistoryResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).ClusterHistory(ctx, r)
+}
+
+func (mp *maintenanceProxy) RuntimeConfig(ctx context.Context, r *pb.RuntimeConfigRequest) (*pb.RuntimeConfigResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).RuntimeConfig(ctx, r)
+}
+
+func (mp *maintenanceProxy) DefragmentStatus(ctx context.Context, r *pb.DefragmentStatusRequest) (*pb.DefragmentStatusResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).DefragmentStatus(ctx, r)
+}
+
+func (mp *maintenanceProxy) PrefixQuotaSet(ctx context.Context, r *pb.PrefixQuotaSetRequest) (*pb.PrefixQuotaSetResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).PrefixQuotaSet(ctx, r)
+}
+
+func (mp *maintenanceProxy) PrefixQuotaDelete(ctx context.Context, r *pb.PrefixQuotaDeleteRequest) (*pb.PrefixQuotaDeleteResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).PrefixQuotaDelete(ctx, r)
+}
+
+func (mp *maintenanceProxy) PrefixQuotaList(ctx context.Context, r *pb.PrefixQuotaListRequest) (*pb.PrefixQuotaListResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).PrefixQuotaList(ctx, r)
+}
+
+func (mp *maintenanceProxy) PrefixStats(ctx context.Context, r *pb.PrefixStatsRequest) (*pb.PrefixStatsResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).PrefixStats(ctx, r)
+}
+
+func (mp *maintenanceProxy) LearnerStatus(ctx context.Context, r *pb.LearnerStatusRequest) (*pb.LearnerStatusResponse, error) {
+	conn := mp.client.ActiveConnection()
+	return pb.NewMaintenanceClient(conn).LearnerStatus(ctx, r)
+}
@@ -16,9 +16,11 @@ package grpcproxy
 
 import (
 	"context"
+	"errors"
 	"sync"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3rpc"
@@ -29,6 +31,19 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+var (
+	// errWatchRangesUnsupported is returned to the watchers on several
+	// ranges, whose events the proxy cannot coalesce atomically.
+	errWatchRangesUnsupported = errors.New("grpcproxy: watching several ranges is not supported")
+	// errWatchResumeUnsupported is returned to the resumable watchers, since
+	// the responses the proxy coalesces carry no resume token.
+	errWatchResumeUnsupported = errors.New("grpcproxy: resumable watchers are not supported")
+	// errWatchProgressUnsupported is returned to the watchers with their own
+	// progress notifications, since the proxy relays the ones of its
+	// coalesced watchers.
+	errWatchProgressUnsupported = errors.New("grpcproxy: per-watch progress notifications are not supported")
+)
+
 type watchProxy struct {
 	cw  clientv3.Watcher
 	ctx context.Context
@@ -43,7 +58,7 @@ type watchProxy struct {
 	// wg waits until all outstanding watch servers quit.
 	wg sync.WaitGroup
 
-	// kv is used for permission checking
+	// kv is used for evaluating the compares
 	kv clientv3.KV
 	lg *zap.Logger
 }
@@ -55,7 +70,7 @@ func NewWatchProxy(ctx context.Context, lg *zap.Logger, c *clientv3.Client) (pb.
 		ctx:    cctx,
 		leader: newLeader(cctx, c.Watcher),
 
-		kv: c.KV, // for permission checking
+		kv: c.KV, // for evaluating the compares
 		lg: lg,
 	}
 	wp.ranges = newWatchRanges(wp)
@@ -101,6 +116,7 @@ func (wp *watchProxy) Watch(stream pb.Watch_WatchServer) (err error) {
 		watchCh:  make(chan *pb.WatchResponse, 1024),
 		ctx:      ctx,
 		cancel:   cancel,
+		cw:       wp.cw,
 		kv:       wp.kv,
 		lg:       wp.lg,
 	}
@@ -183,7 +199,9 @@ type watchProxyStream struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	// kv is used for permission checking
+	// cw is used for permission checking
+	cw clientv3.Watcher
+	// kv is used for evaluating the compares
 	kv clientv3.KV
 	lg *zap.Logger
 }
@@ -207,22 +225,39 @@ func (wps *watchProxyStream) close() {
 	close(wps.watchCh)
 }
 
+// checkPermissionForWatch checks the client may watch the range by creating
+// a watch on it with the token of the client, which the server checks the
+// WATCH permission of. The token is sent as metadata so that the watch is not
+// created on the stream of another client.
 func (wps *watchProxyStream) checkPermissionForWatch(key, rangeEnd []byte) error {
-	if len(key) == 0 {
-		// If the length of the key is 0, we need to obtain full range.
-		// look at clientv3.WithPrefix()
-		key = []byte{0}
-		rangeEnd = []byte{0}
+	ctx, cancel := context.WithCancel(wps.ctx)
+	defer cancel()
+	if token := getAuthTokenFromClient(wps.stream.Context()); token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, rpctypes.TokenFieldNameGRPC, token)
+	}
+	wch := wps.cw.Watch(ctx, string(key), clientv3.WithRange(string(rangeEnd)), clientv3.WithCreatedNotify())
+	wr, ok := <-wch
+	if !ok {
+		return wps.ctx.Err()
+	}
+	return wr.Err()
+}
+
+// applyCompares evaluates the comparisons of a create request through the
+// cluster and returns the revision they were evaluated at.
+func (wps *watchProxyStream) applyCompares(cmps []*pb.Compare) (int64, error) {
+	ccmps := make([]clientv3.Cmp, len(cmps))
+	for i := range cmps {
+		ccmps[i] = clientv3.Cmp(*cmps[i])
+	}
+	resp, err := wps.kv.Txn(wps.ctx).If(ccmps...).Commit()
+	if err != nil {
+		return 0, err
 	}
-	req := &pb.RangeRequest{
-		Serializable: true,
-		Key:          key,
-		RangeEnd:     rangeEnd,
-		CountOnly:    true,
-		Limit:        1,
+	if !resp.Succeeded {
+		return resp.Header.Revision, rpctypes.ErrGRPCWatchCompareFailed
 	}
-	_, err := wps.kv.Do(wps.ctx, RangeRequestToOp(req))
-	return err
+	return resp.Header.Revision, nil
 }
 
 func (wps *watchProxyStream) recvLoop() error {
@@ -235,6 +270,26 @@ func (wps *watchProxyStream) recvLoop() error {
 		case *pb.WatchRequest_CreateRequest:
 			cr := uv.CreateRequest
 
+			var uerr error
+			switch {
+			case len(cr.AdditionalRanges) != 0:
+				uerr = errWatchRangesUnsupported
+			case cr.Resumable, len(cr.ResumeToken) != 0:
+				uerr = errWatchResumeUnsupported
+			case cr.ProgressNotifyIntervalMs != 0, cr.ProgressNotifyHeartbeat:
+				uerr = errWatchProgressUnsupported
+			}
+			if uerr != nil {
+				wps.watchCh <- &pb.WatchResponse{
+					Header:       &pb.ResponseHeader{},
+					WatchId:      -1,
+					Created:      true,
+					Canceled:     true,
+					CancelReason: uerr.Error(),
+				}
+				continue
+			}
+
 			if err := wps.checkPermissionForWatch(cr.Key, cr.RangeEnd); err != nil {
 				wps.watchCh <- &pb.WatchResponse{
 					Header:       &pb.ResponseHeader{},
@@ -246,16 +301,51 @@ func (wps *watchProxyStream) recvLoop() error {
 				continue
 			}
 
+			vfilters, err := v3rpc.ValueFiltersFromRequest(cr)
+			var project func(*mvccpb.Event) *mvccpb.Event
+			if err == nil {
+				project, err = v3rpc.ProjectionFromRequest(cr)
+			}
+			if err != nil {
+				wps.watchCh <- &pb.WatchResponse{
+					Header:       &pb.ResponseHeader{},
+					WatchId:      -1,
+					Created:      true,
+					Canceled:     true,
+					CancelReason: rpctypes.ErrorDesc(err),
+				}
+				continue
+			}
+
+			nextrev := cr.StartRevision
+			if len(cr.Compare) != 0 {
+				crev, err := wps.applyCompares(cr.Compare)
+				if err != nil {
+					wps.watchCh <- &pb.WatchResponse{
+						Header:       &pb.ResponseHeader{},
+						WatchId:      -1,
+						Created:      true,
+						Canceled:     true,
+						CancelReason: rpctypes.ErrorDesc(err),
+					}
+					continue
+				}
+				if nextrev == 0 {
+					nextrev = crev + 1
+				}
+			}
+
 			wps.mu.Lock()
 			w := &watcher{
 				wr:  watchRange{string(cr.Key), string(cr.RangeEnd)},
 				id:  wps.nextWatcherID,
 				wps: wps,
 
-				nextrev:  cr.StartRevision,
+				nextrev:  nextrev,
 				progress: cr.ProgressNotify,
 				prevKV:   cr.PrevKv,
-				filters:  v3rpc.FiltersFromRequest(cr),
+				filters:  append(v3rpc.FiltersFromRequest(cr), vfilters...),
+				project:  project,
 			}
 			if !w.wr.valid() {
 				w.post(&pb.WatchResponse{WatchId: -1, Created: true, Canceled: true})
@@ -263,7 +353,6 @@ func (wps *watchProxyStream) recvLoop() error {
 				continue
 			}
 			wps.nextWatcherID++
-			w.nextrev = cr.StartRevision
 			wps.watchers[w.id] = w
 			wps.ranges.add(w)
 			wps.mu.Unlock()
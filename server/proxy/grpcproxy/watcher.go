@@ -38,6 +38,7 @@ type watcher struct {
 	filters  []mvcc.FilterFunc
 	progress bool
 	prevKV   bool
+	project  func(*mvccpb.Event) *mvccpb.Event
 
 	// id is the id returned to the client on its watch stream.
 	id int64
@@ -94,6 +95,9 @@ func (w *watcher) send(wr clientv3.WatchResponse) {
 			evCopy.PrevKv = nil
 			ev = &evCopy
 		}
+		if w.project != nil {
+			ev = w.project(ev)
+		}
 		events = append(events, ev)
 	}
 
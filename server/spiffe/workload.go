@@ -0,0 +1,111 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spiffe
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The messages of the Workload API read, as of workload.proto of the SPIFFE
+// specifications, decoded from the wire format.
+
+// x509SVIDResponse is an X509SVIDResponse.
+type x509SVIDResponse struct {
+	svids []x509SVID
+	// the values of federated_bundles, the DER encoded CAs of the
+	// federated trust domains
+	federatedBundles [][]byte
+}
+
+// x509SVID is an X509SVID.
+type x509SVID struct {
+	id string
+	// the DER encoded certificate chain, leaf first
+	certs []byte
+	// the PKCS#8 DER encoded private key
+	key []byte
+	// the DER encoded CAs of the trust domain
+	bundle []byte
+}
+
+func decodeX509SVIDResponse(b []byte) (*x509SVIDResponse, error) {
+	resp := &x509SVIDResponse{}
+	err := decodeBytesFields(b, func(num protowire.Number, v []byte) error {
+		switch num {
+		case 1: // svids
+			var svid x509SVID
+			err := decodeBytesFields(v, func(num protowire.Number, v []byte) error {
+				switch num {
+				case 1:
+					svid.id = string(v)
+				case 2:
+					svid.certs = v
+				case 3:
+					svid.key = v
+				case 4:
+					svid.bundle = v
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			resp.svids = append(resp.svids, svid)
+		case 3: // federated_bundles, a map of the trust domains to their CAs
+			return decodeBytesFields(v, func(num protowire.Number, v []byte) error {
+				if num == 2 {
+					resp.federatedBundles = append(resp.federatedBundles, v)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid X509SVIDResponse: %w", err)
+	}
+	return resp, nil
+}
+
+// decodeBytesFields calls f with the length-delimited fields of the
+// message, skipping the others.
+func decodeBytesFields(b []byte, f func(protowire.Number, []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if err := f(num, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spiffe fetches the X.509 SVIDs, the identities of SPIFFE, of etcd
+// from the SPIFFE Workload API, such as the one of a SPIRE agent.
+package spiffe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	fetchX509SVIDMethod = "/SpiffeWorkloadAPI/FetchX509SVID"
+
+	// the header required by the Workload API on all the requests
+	workloadHeaderKey   = "workload.spiffe.io"
+	workloadHeaderValue = "true"
+
+	minRetryInterval = 100 * time.Millisecond
+	maxRetryInterval = 30 * time.Second
+)
+
+// X509Source is a transport.X509Source of the X.509 SVID of the workload,
+// the first one the Workload API returns, and of the trust bundles of its
+// trust domain and of the federated ones. The SVID and the bundles are
+// updated as the Workload API rotates them.
+type X509Source struct {
+	lg      *zap.Logger
+	network string
+	address string
+
+	mu     sync.RWMutex
+	id     string
+	cert   *tls.Certificate
+	roots  *x509.CertPool
+	readyc chan struct{}
+
+	cancel context.CancelFunc
+	donec  chan struct{}
+}
+
+// NewX509Source returns an X509Source of the Workload API at addr,
+// "unix:///path/to/socket" or "tcp://host:port". It waits for the first
+// SVID until ctx is done.
+func NewX509Source(ctx context.Context, lg *zap.Logger, addr string) (*X509Source, error) {
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	network, address, err := ParseAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	sctx, cancel := context.WithCancel(context.Background())
+	s := &X509Source{
+		lg:      lg,
+		network: network,
+		address: address,
+		readyc:  make(chan struct{}),
+		cancel:  cancel,
+		donec:   make(chan struct{}),
+	}
+	go s.run(sctx)
+
+	select {
+	case <-s.readyc:
+		return s, nil
+	case <-ctx.Done():
+		s.Close()
+		return nil, fmt.Errorf("failed to fetch X.509 SVID from SPIFFE Workload API %q: %w", addr, ctx.Err())
+	}
+}
+
+// ParseAddr returns the network and the address of a Workload API address.
+func ParseAddr(addr string) (network, address string, err error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid SPIFFE Workload API address %q: %w", addr, err)
+	}
+	switch {
+	case u.Scheme == "unix" && u.Path != "" && u.Host == "":
+		return "unix", u.Path, nil
+	case u.Scheme == "tcp" && u.Port() != "" && (u.Path == "" || u.Path == "/"):
+		return "tcp", u.Host, nil
+	}
+	return "", "", fmt.Errorf("invalid SPIFFE Workload API address %q (expected unix:///path or tcp://host:port)", addr)
+}
+
+// ID returns the SPIFFE ID of the current SVID.
+func (s *X509Source) ID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.id
+}
+
+// Certificate returns the current SVID.
+func (s *X509Source) Certificate() (*tls.Certificate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cert, nil
+}
+
+// TrustedCAs returns the current trust bundles.
+func (s *X509Source) TrustedCAs() *x509.CertPool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.roots
+}
+
+// Close stops watching the updates of the SVID.
+func (s *X509Source) Close() {
+	s.cancel()
+	<-s.donec
+}
+
+// run watches the updates of the SVID, reconnecting to the Workload API
+// with a backoff until the source is closed.
+func (s *X509Source) run(ctx context.Context) {
+	defer close(s.donec)
+	interval := minRetryInterval
+	for {
+		updated, err := s.watch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if updated {
+			interval = minRetryInterval
+		}
+		s.lg.Warn(
+			"failed to watch X.509 SVID from SPIFFE Workload API",
+			zap.String("address", s.address),
+			zap.Duration("retry-interval", interval),
+			zap.Error(err),
+		)
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return
+		}
+		if interval *= 2; interval > maxRetryInterval {
+			interval = maxRetryInterval
+		}
+	}
+}
+
+// watch updates the SVID on every response of a FetchX509SVID stream,
+// returning whether it was updated once at least when the stream fails.
+func (s *X509Source) watch(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, "passthrough:///"+s.address,
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, s.network, s.address)
+		}),
+	)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	ctx = metadata.AppendToOutgoingContext(ctx, workloadHeaderKey, workloadHeaderValue)
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, fetchX509SVIDMethod, grpc.ForceCodec(rawCodec{}))
+	if err != nil {
+		return false, err
+	}
+	// X509SVIDRequest has no field
+	if err = stream.SendMsg([]byte{}); err != nil {
+		return false, err
+	}
+	if err = stream.CloseSend(); err != nil {
+		return false, err
+	}
+
+	updated := false
+	for {
+		var b []byte
+		if err = stream.RecvMsg(&b); err != nil {
+			return updated, err
+		}
+		if err = s.update(b); err != nil {
+			s.lg.Warn("ignored invalid X.509 SVID response", zap.Error(err))
+			continue
+		}
+		updated = true
+	}
+}
+
+// update sets the SVID and the bundles of an X509SVIDResponse.
+func (s *X509Source) update(b []byte) error {
+	resp, err := decodeX509SVIDResponse(b)
+	if err != nil {
+		return err
+	}
+	if len(resp.svids) == 0 {
+		return errors.New("no X.509 SVID")
+	}
+	svid := resp.svids[0]
+
+	certs, err := x509.ParseCertificates(svid.certs)
+	if err != nil {
+		return fmt.Errorf("invalid X.509 SVID %q: %w", svid.id, err)
+	}
+	if len(certs) == 0 {
+		return fmt.Errorf("X.509 SVID %q has no certificate", svid.id)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(svid.key)
+	if err != nil {
+		return fmt.Errorf("invalid private key of X.509 SVID %q: %w", svid.id, err)
+	}
+	cert := &tls.Certificate{PrivateKey: key, Leaf: certs[0]}
+	for _, c := range certs {
+		cert.Certificate = append(cert.Certificate, c.Raw)
+	}
+
+	roots := x509.NewCertPool()
+	for _, bundle := range append([][]byte{svid.bundle}, resp.federatedBundles...) {
+		cas, err := x509.ParseCertificates(bundle)
+		if err != nil {
+			return fmt.Errorf("invalid trust bundle of X.509 SVID %q: %w", svid.id, err)
+		}
+		for _, ca := range cas {
+			roots.AddCert(ca)
+		}
+	}
+
+	s.mu.Lock()
+	first := s.cert == nil
+	s.id, s.cert, s.roots = svid.id, cert, roots
+	s.mu.Unlock()
+	if first {
+		close(s.readyc)
+	}
+
+	s.lg.Info(
+		"updated X.509 SVID from SPIFFE Workload API",
+		zap.String("spiffe-id", svid.id),
+		zap.Time("not-after", certs[0].NotAfter),
+	)
+	return nil
+}
+
+// rawCodec passes the messages as they are encoded, so that the Workload
+// API needs no generated code.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+// Name is the content subtype of the Workload API.
+func (rawCodec) Name() string { return "proto" }
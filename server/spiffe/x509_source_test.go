@@ -0,0 +1,193 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spiffe
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"math/big"
+	"net"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key}
+}
+
+// svidResponse returns an X509SVIDResponse of an SVID of the id issued by
+// the CA.
+func (ca *testCA) svidResponse(t *testing.T, id string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	u, err := url.Parse(id)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		URIs:         []*url.URL{u},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	var svid []byte
+	svid = protowire.AppendTag(svid, 1, protowire.BytesType)
+	svid = protowire.AppendString(svid, id)
+	svid = protowire.AppendTag(svid, 2, protowire.BytesType)
+	svid = protowire.AppendBytes(svid, der)
+	svid = protowire.AppendTag(svid, 3, protowire.BytesType)
+	svid = protowire.AppendBytes(svid, pkcs8)
+	svid = protowire.AppendTag(svid, 4, protowire.BytesType)
+	svid = protowire.AppendBytes(svid, ca.cert.Raw)
+
+	var resp []byte
+	resp = protowire.AppendTag(resp, 1, protowire.BytesType)
+	return protowire.AppendBytes(resp, svid)
+}
+
+// newFakeWorkloadAPI serves the responses sent on respc to the
+// FetchX509SVID streams, returning the address of the server.
+func newFakeWorkloadAPI(t *testing.T, respc <-chan []byte) string {
+	sock := filepath.Join(t.TempDir(), "agent.sock")
+	ln, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	srv := grpc.NewServer(
+		grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+			if m, _ := grpc.MethodFromServerStream(stream); m != fetchX509SVIDMethod {
+				return errors.New("unexpected method")
+			}
+			md, _ := metadata.FromIncomingContext(stream.Context())
+			if v := md.Get(workloadHeaderKey); len(v) != 1 || v[0] != workloadHeaderValue {
+				return errors.New("missing workload header")
+			}
+			var req []byte
+			if err := stream.RecvMsg(&req); err != nil {
+				return err
+			}
+			for {
+				select {
+				case resp := <-respc:
+					if err := stream.SendMsg(resp); err != nil {
+						return err
+					}
+				case <-stream.Context().Done():
+					return nil
+				}
+			}
+		}),
+	)
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+	return "unix://" + sock
+}
+
+func TestX509SourceRotation(t *testing.T) {
+	ca := newTestCA(t)
+	respc := make(chan []byte, 1)
+	respc <- ca.svidResponse(t, "spiffe://example.org/etcd/member1")
+	addr := newFakeWorkloadAPI(t, respc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	s, err := NewX509Source(ctx, zaptest.NewLogger(t), addr)
+	require.NoError(t, err)
+	defer s.Close()
+
+	check := func(id string) {
+		t.Helper()
+		assert.Equal(t, id, s.ID())
+		cert, err := s.Certificate()
+		require.NoError(t, err)
+		assert.Equal(t, id, cert.Leaf.URIs[0].String())
+		_, err = cert.Leaf.Verify(x509.VerifyOptions{Roots: s.TrustedCAs(), KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+		assert.NoError(t, err)
+	}
+	check("spiffe://example.org/etcd/member1")
+
+	respc <- ca.svidResponse(t, "spiffe://example.org/etcd/member2")
+	require.Eventually(t, func() bool { return s.ID() == "spiffe://example.org/etcd/member2" }, 10*time.Second, 10*time.Millisecond)
+	check("spiffe://example.org/etcd/member2")
+
+	// an invalid response keeps the last SVID
+	respc <- []byte{0xff}
+	respc <- ca.svidResponse(t, "spiffe://example.org/etcd/member3")
+	require.Eventually(t, func() bool { return s.ID() == "spiffe://example.org/etcd/member3" }, 10*time.Second, 10*time.Millisecond)
+}
+
+func TestX509SourceUnavailable(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err := NewX509Source(ctx, zaptest.NewLogger(t), "unix://"+filepath.Join(t.TempDir(), "agent.sock"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestParseAddr(t *testing.T) {
+	tests := []struct {
+		addr             string
+		network, address string
+		ok               bool
+	}{
+		{"unix:///run/spire/agent.sock", "unix", "/run/spire/agent.sock", true},
+		{"tcp://127.0.0.1:8081", "tcp", "127.0.0.1:8081", true},
+		{"unix://run/spire/agent.sock", "", "", false},
+		{"tcp://127.0.0.1", "", "", false},
+		{"/run/spire/agent.sock", "", "", false},
+		{"https://127.0.0.1:8081", "", "", false},
+	}
+	for _, tt := range tests {
+		network, address, err := ParseAddr(tt.addr)
+		assert.Equal(t, tt.ok, err == nil, tt.addr)
+		assert.Equal(t, tt.network, network, tt.addr)
+		assert.Equal(t, tt.address, address, tt.addr)
+	}
+}
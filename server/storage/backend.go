@@ -44,6 +44,9 @@ func newBackend(cfg config.ServerConfig, hooks backend.Hooks) backend.Backend {
 			cfg.Logger.Info("setting backend batch interval", zap.Duration("batch interval", cfg.BackendBatchInterval))
 		}
 	}
+	bcfg.Engine = cfg.BackendEngine
+	bcfg.DefragBatchLimit = cfg.DefragBatchLimit
+	bcfg.DefragBatchInterval = cfg.DefragBatchInterval
 	bcfg.BackendFreelistType = cfg.BackendFreelistType
 	bcfg.Logger = cfg.Logger
 	if cfg.QuotaBackendBytes > 0 && cfg.QuotaBackendBytes != DefaultQuotaBytes {
@@ -18,8 +18,6 @@ import (
 	"fmt"
 	"hash/crc32"
 	"io"
-	"os"
-	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -44,6 +42,33 @@ var (
 	minSnapshotWarningTimeout = 30 * time.Second
 )
 
+const (
+	// MinBatchInterval is the smallest accepted backend commit interval.
+	MinBatchInterval = time.Millisecond
+	// MaxBatchInterval is the largest accepted backend commit interval.
+	MaxBatchInterval = 10 * time.Second
+	// MaxBatchLimit is the largest accepted number of operations per backend commit.
+	MaxBatchLimit = 1000000
+)
+
+var (
+	ErrInvalidBatchInterval = fmt.Errorf("backend batch interval must be between %v and %v", MinBatchInterval, MaxBatchInterval)
+	ErrInvalidBatchLimit    = fmt.Errorf("backend batch limit must be between 1 and %d", MaxBatchLimit)
+)
+
+// ValidateBatchLimits checks that the given batch interval and batch limit are
+// within the accepted bounds. Zero values are ignored and mean "unchanged" or
+// "use the default".
+func ValidateBatchLimits(interval time.Duration, limit int) error {
+	if interval != 0 && (interval < MinBatchInterval || interval > MaxBatchInterval) {
+		return ErrInvalidBatchInterval
+	}
+	if limit < 0 || limit > MaxBatchLimit {
+		return ErrInvalidBatchLimit
+	}
+	return nil
+}
+
 type Backend interface {
 	// ReadTx returns a read transaction. It is replaced by ConcurrentReadTx in the main data path, see #10523.
 	ReadTx() ReadTx
@@ -64,12 +89,36 @@ type Backend interface {
 	SizeInUse() int64
 	// OpenReadTxN returns the number of currently open read transactions in the backend.
 	OpenReadTxN() int64
+	// Defrag reclaims the unused space of the backend. The engines
+	// implementing IncrementalDefragmenter are copied in batches while the
+	// backend keeps serving, unless the defragmentation batch limit is not
+	// positive.
 	Defrag() error
+	// DefragStatus returns the progress of the ongoing defragmentation, or
+	// of the last one.
+	DefragStatus() DefragStatus
+	// Scrub verifies at most limit records from pos with verify and returns
+	// the position of the next record, done once the pass is complete.
+	Scrub(pos ScrubPosition, limit int, verify ScrubVerifier) (next ScrubPosition, done bool, err error)
 	ForceCommit()
 	Close() error
 
 	// SetTxPostLockInsideApplyHook sets a txPostLockInsideApplyHook.
 	SetTxPostLockInsideApplyHook(func())
+
+	// BatchLimits returns the current batch interval and batch limit.
+	BatchLimits() (time.Duration, int)
+	// SetBatchLimits updates the batch interval and batch limit at runtime.
+	// Zero values keep the current setting. A new interval takes effect
+	// after the pending commit interval elapses.
+	SetBatchLimits(interval time.Duration, limit int)
+
+	// UnsafeSetCommitTraceID sets the ID of the trace of a request that
+	// writes to the pending batch, reported as the exemplar of the duration
+	// of the commit of the batch. The lock of the batch tx must be held, for
+	// instance by calling it from the txPostLockInsideApplyHook, so that the
+	// writes cannot be committed without it.
+	UnsafeSetCommitTraceID(traceID string)
 }
 
 type Snapshot interface {
@@ -102,12 +151,13 @@ type backend struct {
 	// mlock prevents backend database file to be swapped
 	mlock bool
 
-	mu    sync.RWMutex
-	bopts *bolt.Options
-	db    *bolt.DB
+	mu sync.RWMutex
+	db Engine
 
-	batchInterval time.Duration
-	batchLimit    int
+	// batchInterval and batchLimit are accessed atomically since they can be
+	// updated at runtime, see SetBatchLimits.
+	batchInterval int64
+	batchLimit    int64
 	batchTx       *batchTxBuffered
 
 	readTx *readTx
@@ -125,6 +175,20 @@ type backend struct {
 	// txPostLockInsideApplyHook is called each time right after locking the tx.
 	txPostLockInsideApplyHook func()
 
+	// commitTraceID is the trace ID set by UnsafeSetCommitTraceID,
+	// protected by the lock of batchTx.
+	commitTraceID string
+
+	// defragMu serializes the defragmentations.
+	defragMu            sync.Mutex
+	defragBatchLimit    int
+	defragBatchInterval time.Duration
+	// defragDirty records the keys written during an incremental
+	// defragmentation, nil otherwise. It is protected by the lock of batchTx.
+	defragDirty    *defragDirty
+	defragStatusMu sync.RWMutex
+	defragStatus   DefragStatus
+
 	lg *zap.Logger
 }
 
@@ -135,6 +199,15 @@ type BackendConfig struct {
 	BatchInterval time.Duration
 	// BatchLimit is the maximum puts before flushing the BatchTx.
 	BatchLimit int
+	// Engine is the name of the storage engine, EngineBolt if empty.
+	Engine string
+	// DefragBatchLimit is the number of keys copied at a time by the
+	// incremental defragmentation. The defragmentation stops the world if
+	// not positive.
+	DefragBatchLimit int
+	// DefragBatchInterval is the pause between the batches of the
+	// incremental defragmentation.
+	DefragBatchInterval time.Duration
 	// BackendFreelistType is the backend boltdb's freelist type.
 	BackendFreelistType bolt.FreelistType
 	// MmapSize is the number of bytes to mmap for the backend.
@@ -170,38 +243,30 @@ func NewDefaultBackend(lg *zap.Logger, path string) Backend {
 }
 
 func newBackend(bcfg BackendConfig) *backend {
-	bopts := &bolt.Options{}
-	if boltOpenOptions != nil {
-		*bopts = *boltOpenOptions
-	}
-	bopts.InitialMmapSize = bcfg.mmapSize()
-	bopts.FreelistType = bcfg.BackendFreelistType
-	bopts.NoSync = bcfg.UnsafeNoFsync
-	bopts.NoGrowSync = bcfg.UnsafeNoFsync
-	bopts.Mlock = bcfg.Mlock
-
-	db, err := bolt.Open(bcfg.Path, 0600, bopts)
+	db, err := openEngine(bcfg)
 	if err != nil {
-		bcfg.Logger.Panic("failed to open database", zap.String("path", bcfg.Path), zap.Error(err))
+		bcfg.Logger.Panic("failed to open database", zap.String("path", bcfg.Path), zap.String("engine", bcfg.Engine), zap.Error(err))
 	}
 
 	// In future, may want to make buffering optional for low-concurrency systems
 	// or dynamically swap between buffered/non-buffered depending on workload.
 	b := &backend{
-		bopts: bopts,
-		db:    db,
+		db: db,
 
-		batchInterval: bcfg.BatchInterval,
-		batchLimit:    bcfg.BatchLimit,
+		batchInterval: int64(bcfg.BatchInterval),
+		batchLimit:    int64(bcfg.BatchLimit),
 		mlock:         bcfg.Mlock,
 
+		defragBatchLimit:    bcfg.DefragBatchLimit,
+		defragBatchInterval: bcfg.DefragBatchInterval,
+
 		readTx: &readTx{
 			baseReadTx: baseReadTx{
 				buf: txReadBuffer{
 					txBuffer:   txBuffer{make(map[BucketID]*bucketBuffer)},
 					bufVersion: 0,
 				},
-				buckets: make(map[BucketID]*bolt.Bucket),
+				buckets: make(map[BucketID]EngineBucket),
 				txWg:    new(sync.WaitGroup),
 				txMu:    new(sync.RWMutex),
 			},
@@ -222,6 +287,9 @@ func newBackend(bcfg BackendConfig) *backend {
 	// We set it after newBatchTxBuffered to skip the 'empty' commit.
 	b.hooks = bcfg.Hooks
 
+	batchIntervalSec.Set(bcfg.BatchInterval.Seconds())
+	batchLimitOps.Set(float64(bcfg.BatchLimit))
+
 	go b.run()
 	return b
 }
@@ -241,6 +309,38 @@ func (b *backend) SetTxPostLockInsideApplyHook(hook func()) {
 	b.txPostLockInsideApplyHook = hook
 }
 
+func (b *backend) UnsafeSetCommitTraceID(traceID string) {
+	b.commitTraceID = traceID
+}
+
+func (b *backend) BatchLimits() (time.Duration, int) {
+	return b.getBatchInterval(), b.getBatchLimit()
+}
+
+func (b *backend) SetBatchLimits(interval time.Duration, limit int) {
+	if interval != 0 {
+		atomic.StoreInt64(&b.batchInterval, int64(interval))
+		batchIntervalSec.Set(interval.Seconds())
+	}
+	if limit != 0 {
+		atomic.StoreInt64(&b.batchLimit, int64(limit))
+		batchLimitOps.Set(float64(limit))
+	}
+	b.lg.Info(
+		"updated backend batch limits",
+		zap.Duration("batch-interval", b.getBatchInterval()),
+		zap.Int("batch-limit", b.getBatchLimit()),
+	)
+}
+
+func (b *backend) getBatchInterval() time.Duration {
+	return time.Duration(atomic.LoadInt64(&b.batchInterval))
+}
+
+func (b *backend) getBatchLimit() int {
+	return int(atomic.LoadInt64(&b.batchLimit))
+}
+
 func (b *backend) ReadTx() ReadTx { return b.readTx }
 
 // ConcurrentReadTx creates and returns a new ReadTx, which:
@@ -375,25 +475,21 @@ func (b *backend) Hash(ignores func(bucketName, keyName []byte) bool) (uint32, e
 
 	b.mu.RLock()
 	defer b.mu.RUnlock()
-	err := b.db.View(func(tx *bolt.Tx) error {
-		c := tx.Cursor()
-		for next, _ := c.First(); next != nil; next, _ = c.Next() {
-			b := tx.Bucket(next)
-			if b == nil {
-				return fmt.Errorf("cannot get hash of bucket %s", string(next))
+	tx, err := b.db.Begin(false)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	err = tx.ForEachBucket(func(next []byte, b EngineBucket) error {
+		h.Write(next)
+		return b.ForEach(func(k, v []byte) error {
+			if ignores != nil && !ignores(next, k) {
+				h.Write(k)
+				h.Write(v)
 			}
-			h.Write(next)
-			b.ForEach(func(k, v []byte) error {
-				if ignores != nil && !ignores(next, k) {
-					h.Write(k)
-					h.Write(v)
-				}
-				return nil
-			})
-		}
-		return nil
+			return nil
+		})
 	})
-
 	if err != nil {
 		return 0, err
 	}
@@ -411,7 +507,7 @@ func (b *backend) SizeInUse() int64 {
 
 func (b *backend) run() {
 	defer close(b.donec)
-	t := time.NewTimer(b.batchInterval)
+	t := time.NewTimer(b.getBatchInterval())
 	defer t.Stop()
 	for {
 		select {
@@ -423,13 +519,16 @@ func (b *backend) run() {
 		if b.batchTx.safePending() != 0 {
 			b.batchTx.Commit()
 		}
-		t.Reset(b.batchInterval)
+		t.Reset(b.getBatchInterval())
 	}
 }
 
 func (b *backend) Close() error {
 	close(b.stopc)
 	<-b.donec
+	// wait for an incremental defragmentation to abort.
+	b.defragMu.Lock()
+	defer b.defragMu.Unlock()
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	return b.db.Close()
@@ -441,6 +540,11 @@ func (b *backend) Commits() int64 {
 }
 
 func (b *backend) Defrag() error {
+	b.defragMu.Lock()
+	defer b.defragMu.Unlock()
+	if inc, ok := b.db.(IncrementalDefragmenter); ok && b.defragBatchLimit > 0 {
+		return b.defragIncremental(inc)
+	}
 	return b.defrag()
 }
 
@@ -448,8 +552,9 @@ func (b *backend) defrag() error {
 	now := time.Now()
 	isDefragActive.Set(1)
 	defer isDefragActive.Set(0)
+	b.updateDefragStatus(func(st *DefragStatus) { *st = DefragStatus{InProgress: true, Phase: DefragPhaseSwitch, Started: now} })
+	defer b.updateDefragStatus(func(st *DefragStatus) { st.InProgress = false })
 
-	// TODO: make this non-blocking?
 	// lock batchTx to ensure nobody is using previous tx, and then
 	// close previous ongoing tx.
 	b.batchTx.LockOutsideApply()
@@ -467,28 +572,6 @@ func (b *backend) defrag() error {
 
 	b.batchTx.tx = nil
 
-	// Create a temporary file to ensure we start with a clean slate.
-	// Snapshotter.cleanupSnapdir cleans up any of these that are found during startup.
-	dir := filepath.Dir(b.db.Path())
-	temp, err := os.CreateTemp(dir, "db.tmp.*")
-	if err != nil {
-		return err
-	}
-	options := bolt.Options{}
-	if boltOpenOptions != nil {
-		options = *boltOpenOptions
-	}
-	options.OpenFile = func(_ string, _ int, _ os.FileMode) (file *os.File, err error) {
-		return temp, nil
-	}
-	// Don't load tmp db into memory regardless of opening options
-	options.Mlock = false
-	tdbp := temp.Name()
-	tmpdb, err := bolt.Open(tdbp, 0600, &options)
-	if err != nil {
-		return err
-	}
-
 	dbp := b.db.Path()
 	size1, sizeInUse1 := b.Size(), b.SizeInUse()
 	if b.lg != nil {
@@ -501,43 +584,17 @@ func (b *backend) defrag() error {
 			zap.String("current-db-size-in-use", humanize.Bytes(uint64(sizeInUse1))),
 		)
 	}
-	// gofail: var defragBeforeCopy struct{}
-	err = defragdb(b.db, tmpdb, defragLimit)
-	if err != nil {
-		tmpdb.Close()
-		if rmErr := os.RemoveAll(tmpdb.Path()); rmErr != nil {
-			b.lg.Error("failed to remove db.tmp after defragmentation completed", zap.Error(rmErr))
-		}
+	if err := b.db.Defrag(); err != nil {
 		return err
 	}
-
-	err = b.db.Close()
-	if err != nil {
-		b.lg.Fatal("failed to close database", zap.Error(err))
-	}
-	err = tmpdb.Close()
-	if err != nil {
-		b.lg.Fatal("failed to close tmp database", zap.Error(err))
-	}
-	// gofail: var defragBeforeRename struct{}
-	err = os.Rename(tdbp, dbp)
-	if err != nil {
-		b.lg.Fatal("failed to rename tmp database", zap.Error(err))
-	}
-
-	b.db, err = bolt.Open(dbp, 0600, b.bopts)
-	if err != nil {
-		b.lg.Fatal("failed to open database", zap.String("path", dbp), zap.Error(err))
-	}
 	b.batchTx.tx = b.unsafeBegin(true)
 
 	b.readTx.reset()
 	b.readTx.tx = b.unsafeBegin(false)
 
 	size := b.readTx.tx.Size()
-	db := b.readTx.tx.DB()
 	atomic.StoreInt64(&b.size, size)
-	atomic.StoreInt64(&b.sizeInUse, size-(int64(db.Stats().FreePageN)*int64(db.Info().PageSize)))
+	atomic.StoreInt64(&b.sizeInUse, size-b.db.Stats().FreeBytes)
 
 	took := time.Since(now)
 	defragSec.Observe(took.Seconds())
@@ -559,81 +616,21 @@ func (b *backend) defrag() error {
 	return nil
 }
 
-func defragdb(odb, tmpdb *bolt.DB, limit int) error {
-	// open a tx on tmpdb for writes
-	tmptx, err := tmpdb.Begin(true)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			tmptx.Rollback()
-		}
-	}()
-
-	// open a tx on old db for read
-	tx, err := odb.Begin(false)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	c := tx.Cursor()
-
-	count := 0
-	for next, _ := c.First(); next != nil; next, _ = c.Next() {
-		b := tx.Bucket(next)
-		if b == nil {
-			return fmt.Errorf("backend: cannot defrag bucket %s", string(next))
-		}
-
-		tmpb, berr := tmptx.CreateBucketIfNotExists(next)
-		if berr != nil {
-			return berr
-		}
-		tmpb.FillPercent = 0.9 // for bucket2seq write in for each
-
-		if err = b.ForEach(func(k, v []byte) error {
-			count++
-			if count > limit {
-				err = tmptx.Commit()
-				if err != nil {
-					return err
-				}
-				tmptx, err = tmpdb.Begin(true)
-				if err != nil {
-					return err
-				}
-				tmpb = tmptx.Bucket(next)
-				tmpb.FillPercent = 0.9 // for bucket2seq write in for each
-
-				count = 0
-			}
-			return tmpb.Put(k, v)
-		}); err != nil {
-			return err
-		}
-	}
-
-	return tmptx.Commit()
-}
-
-func (b *backend) begin(write bool) *bolt.Tx {
+func (b *backend) begin(write bool) EngineTx {
 	b.mu.RLock()
 	tx := b.unsafeBegin(write)
+	stats := b.db.Stats()
 	b.mu.RUnlock()
 
 	size := tx.Size()
-	db := tx.DB()
-	stats := db.Stats()
 	atomic.StoreInt64(&b.size, size)
-	atomic.StoreInt64(&b.sizeInUse, size-(int64(stats.FreePageN)*int64(db.Info().PageSize)))
-	atomic.StoreInt64(&b.openReadTxN, int64(stats.OpenTxN))
+	atomic.StoreInt64(&b.sizeInUse, size-stats.FreeBytes)
+	atomic.StoreInt64(&b.openReadTxN, int64(stats.OpenReadTxN))
 
 	return tx
 }
 
-func (b *backend) unsafeBegin(write bool) *bolt.Tx {
+func (b *backend) unsafeBegin(write bool) EngineTx {
 	tx, err := b.db.Begin(write)
 	if err != nil {
 		b.lg.Fatal("failed to begin tx", zap.Error(err))
@@ -646,7 +643,7 @@ func (b *backend) OpenReadTxN() int64 {
 }
 
 type snapshot struct {
-	*bolt.Tx
+	EngineTx
 	stopc chan struct{}
 	donec chan struct{}
 }
@@ -654,5 +651,5 @@ type snapshot struct {
 func (s *snapshot) Close() error {
 	close(s.stopc)
 	<-s.donec
-	return s.Tx.Rollback()
+	return s.EngineTx.Rollback()
 }
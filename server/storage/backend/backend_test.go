@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	bolt "go.etcd.io/bbolt"
 	"go.etcd.io/etcd/server/v3/storage/backend"
@@ -122,6 +123,94 @@ func TestBackendBatchIntervalCommit(t *testing.T) {
 	}))
 }
 
+func TestBackendSetBatchLimits(t *testing.T) {
+	b, _ := betesting.NewTmpBackend(t, time.Hour, 10000)
+	defer betesting.Close(t, b)
+
+	b.SetBatchLimits(0, 1)
+	interval, limit := b.BatchLimits()
+	assert.Equal(t, time.Hour, interval)
+	assert.Equal(t, 1, limit)
+
+	pc := backend.CommitsForTest(b)
+
+	// with a batch limit of 1 every write is committed on unlock,
+	// long before the batch interval elapses.
+	tx := b.BatchTx()
+	tx.Lock()
+	tx.UnsafeCreateBucket(schema.Test)
+	tx.UnsafePut(schema.Test, []byte("foo"), []byte("bar"))
+	tx.Unlock()
+
+	assert.Equal(t, pc+1, backend.CommitsForTest(b))
+
+	b.SetBatchLimits(time.Millisecond, 0)
+	interval, limit = b.BatchLimits()
+	assert.Equal(t, time.Millisecond, interval)
+	assert.Equal(t, 1, limit)
+}
+
+func TestBackendCommitTraceID(t *testing.T) {
+	// the batch is committed as soon as the tx is unlocked
+	b, _ := betesting.NewTmpBackend(t, time.Hour, 1)
+	defer betesting.Close(t, b)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	tx := b.BatchTx()
+	tx.Lock()
+	tx.UnsafeCreateBucket(schema.Test)
+	tx.UnsafePut(schema.Test, []byte("foo"), []byte("bar"))
+	b.UnsafeSetCommitTraceID(traceID)
+	tx.Unlock()
+
+	assert.Equal(t, 1, commitExemplars(t, traceID))
+}
+
+// commitExemplars returns the number of buckets of the commit duration
+// histogram with an exemplar of traceID.
+func commitExemplars(t *testing.T, traceID string) int {
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, mf := range mfs {
+		if mf.GetName() != "etcd_disk_backend_commit_duration_seconds" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, bk := range m.GetHistogram().GetBucket() {
+				for _, l := range bk.GetExemplar().GetLabel() {
+					if l.GetName() == "trace_id" && l.GetValue() == traceID {
+						n++
+					}
+				}
+			}
+		}
+	}
+	return n
+}
+
+func TestValidateBatchLimits(t *testing.T) {
+	tcs := []struct {
+		interval time.Duration
+		limit    int
+		err      error
+	}{
+		{interval: 0, limit: 0},
+		{interval: backend.MinBatchInterval, limit: 1},
+		{interval: backend.MaxBatchInterval, limit: backend.MaxBatchLimit},
+		{interval: time.Microsecond, err: backend.ErrInvalidBatchInterval},
+		{interval: time.Minute, err: backend.ErrInvalidBatchInterval},
+		{interval: -time.Second, err: backend.ErrInvalidBatchInterval},
+		{limit: -1, err: backend.ErrInvalidBatchLimit},
+		{limit: backend.MaxBatchLimit + 1, err: backend.ErrInvalidBatchLimit},
+	}
+	for _, tc := range tcs {
+		assert.Equal(t, tc.err, backend.ValidateBatchLimits(tc.interval, tc.limit), "interval %v, limit %d", tc.interval, tc.limit)
+	}
+}
+
 func TestBackendDefrag(t *testing.T) {
 	bcfg := backend.DefaultBackendConfig(zaptest.NewLogger(t))
 	// Make sure we change BackendFreelistType
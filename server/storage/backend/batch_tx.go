@@ -21,7 +21,7 @@ import (
 	"sync/atomic"
 	"time"
 
-	bolt "go.etcd.io/bbolt"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
@@ -59,7 +59,7 @@ type BatchTx interface {
 
 type batchTx struct {
 	sync.Mutex
-	tx      *bolt.Tx
+	tx      EngineTx
 	backend *backend
 
 	pending int
@@ -94,7 +94,7 @@ func (t *batchTx) LockOutsideApply() {
 }
 
 func (t *batchTx) Unlock() {
-	if t.pending >= t.backend.batchLimit {
+	if t.pending >= t.backend.getBatchLimit() {
 		t.commit(false)
 	}
 	t.Mutex.Unlock()
@@ -113,26 +113,32 @@ func (t *batchTx) RUnlock() {
 }
 
 func (t *batchTx) UnsafeCreateBucket(bucket Bucket) {
-	_, err := t.tx.CreateBucket(bucket.Name())
-	if err != nil && err != bolt.ErrBucketExists {
+	err := t.tx.CreateBucket(bucket.Name())
+	if err != nil {
 		t.backend.lg.Fatal(
 			"failed to create a bucket",
 			zap.Stringer("bucket-name", bucket),
 			zap.Error(err),
 		)
 	}
+	if d := t.backend.defragDirty; d != nil {
+		d.createBucket(bucket.Name())
+	}
 	t.pending++
 }
 
 func (t *batchTx) UnsafeDeleteBucket(bucket Bucket) {
 	err := t.tx.DeleteBucket(bucket.Name())
-	if err != nil && err != bolt.ErrBucketNotFound {
+	if err != nil {
 		t.backend.lg.Fatal(
 			"failed to delete a bucket",
 			zap.Stringer("bucket-name", bucket),
 			zap.Error(err),
 		)
 	}
+	if d := t.backend.defragDirty; d != nil {
+		d.deleteBucket(bucket.Name())
+	}
 	t.pending++
 }
 
@@ -155,18 +161,22 @@ func (t *batchTx) unsafePut(bucketType Bucket, key []byte, value []byte, seq boo
 			zap.Stack("stack"),
 		)
 	}
+	var err error
 	if seq {
-		// it is useful to increase fill percent when the workloads are mostly append-only.
-		// this can delay the page split and reduce space usage.
-		bucket.FillPercent = 0.9
+		err = bucket.SeqPut(key, value)
+	} else {
+		err = bucket.Put(key, value)
 	}
-	if err := bucket.Put(key, value); err != nil {
+	if err != nil {
 		t.backend.lg.Fatal(
 			"failed to write to a bucket",
 			zap.Stringer("bucket-name", bucketType),
 			zap.Error(err),
 		)
 	}
+	if d := t.backend.defragDirty; d != nil {
+		d.write(bucketType.Name(), key)
+	}
 	t.pending++
 }
 
@@ -183,7 +193,7 @@ func (t *batchTx) UnsafeRange(bucketType Bucket, key, endKey []byte, limit int64
 	return unsafeRange(bucket.Cursor(), key, endKey, limit)
 }
 
-func unsafeRange(c *bolt.Cursor, key, endKey []byte, limit int64) (keys [][]byte, vs [][]byte) {
+func unsafeRange(c EngineCursor, key, endKey []byte, limit int64) (keys [][]byte, vs [][]byte) {
 	if limit <= 0 {
 		limit = math.MaxInt64
 	}
@@ -223,6 +233,9 @@ func (t *batchTx) UnsafeDelete(bucketType Bucket, key []byte) {
 			zap.Error(err),
 		)
 	}
+	if d := t.backend.defragDirty; d != nil {
+		d.write(bucketType.Name(), key)
+	}
 	t.pending++
 }
 
@@ -231,7 +244,7 @@ func (t *batchTx) UnsafeForEach(bucket Bucket, visitor func(k, v []byte) error)
 	return unsafeForEach(t.tx, bucket, visitor)
 }
 
-func unsafeForEach(tx *bolt.Tx, bucket Bucket, visitor func(k, v []byte) error) error {
+func unsafeForEach(tx EngineTx, bucket Bucket, visitor func(k, v []byte) error) error {
 	if b := tx.Bucket(bucket.Name()); b != nil {
 		return b.ForEach(visitor)
 	}
@@ -271,10 +284,13 @@ func (t *batchTx) commit(stop bool) {
 		err := t.tx.Commit()
 		// gofail: var afterCommit struct{}
 
-		rebalanceSec.Observe(t.tx.Stats().RebalanceTime.Seconds())
-		spillSec.Observe(t.tx.Stats().SpillTime.Seconds())
-		writeSec.Observe(t.tx.Stats().WriteTime.Seconds())
-		commitSec.Observe(time.Since(start).Seconds())
+		if id := t.backend.commitTraceID; id != "" {
+			commitSec.(prometheus.ExemplarObserver).ObserveWithExemplar(time.Since(start).Seconds(), prometheus.Labels{"trace_id": id})
+			t.backend.commitTraceID = ""
+		} else {
+			commitSec.Observe(time.Since(start).Seconds())
+		}
+		commitBatchSize.Observe(float64(t.pending))
 		atomic.AddInt64(&t.backend.commits, 1)
 
 		t.pending = 0
@@ -309,7 +325,7 @@ func (t *batchTxBuffered) Unlock() {
 		t.backend.readTx.Lock() // blocks txReadBuffer for writing.
 		t.buf.writeback(&t.backend.readTx.buf)
 		t.backend.readTx.Unlock()
-		if t.pending >= t.backend.batchLimit {
+		if t.pending >= t.backend.getBatchLimit() {
 			t.commit(false)
 		}
 	}
@@ -343,7 +359,7 @@ func (t *batchTxBuffered) unsafeCommit(stop bool) {
 	if t.backend.readTx.tx != nil {
 		// wait all store read transactions using the current boltdb tx to finish,
 		// then close the boltdb tx
-		go func(tx *bolt.Tx, wg *sync.WaitGroup) {
+		go func(tx EngineTx, wg *sync.WaitGroup) {
 			wg.Wait()
 			if err := tx.Rollback(); err != nil {
 				t.backend.lg.Fatal("failed to rollback tx", zap.Error(err))
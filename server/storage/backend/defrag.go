@@ -0,0 +1,457 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefragPhaseCopy copies the database as of the start of the defragmentation.
+	DefragPhaseCopy = "copy"
+	// DefragPhaseCatchUp copies the keys written since they were copied.
+	DefragPhaseCatchUp = "catch-up"
+	// DefragPhaseSwitch stops the writes to copy the last written keys and
+	// switch to the copy.
+	DefragPhaseSwitch = "switch"
+)
+
+// DefaultDefragBatchLimit is the default number of keys copied at a time by
+// the incremental defragmentation.
+const DefaultDefragBatchLimit = 10000
+
+// defragMaxCatchUpRounds bounds the catch-up rounds when the keys are
+// written faster than they are copied.
+const defragMaxCatchUpRounds = 10
+
+// ErrDefragAborted is returned by an incremental defragmentation interrupted
+// by closing the backend.
+var ErrDefragAborted = errors.New("backend: defragmentation aborted by closing the backend")
+
+// DefragStatus is the progress of a defragmentation.
+type DefragStatus struct {
+	InProgress bool
+	Phase      string
+	// CopiedKeys is the number of keys copied so far, out of TotalKeys when
+	// the defragmentation started.
+	CopiedKeys int64
+	TotalKeys  int64
+	// PendingKeys is the number of keys written since they were copied,
+	// copied again by the next catch-up round or on the switch.
+	PendingKeys int64
+	Started     time.Time
+}
+
+func (b *backend) DefragStatus() DefragStatus {
+	b.defragStatusMu.RLock()
+	st := b.defragStatus
+	b.defragStatusMu.RUnlock()
+	if st.InProgress {
+		b.batchTx.Mutex.Lock()
+		if b.defragDirty != nil {
+			st.PendingKeys = int64(b.defragDirty.n)
+		}
+		b.batchTx.Mutex.Unlock()
+	}
+	return st
+}
+
+func (b *backend) updateDefragStatus(f func(st *DefragStatus)) {
+	b.defragStatusMu.Lock()
+	f(&b.defragStatus)
+	b.defragStatusMu.Unlock()
+}
+
+// defragDirty records the keys written during an incremental
+// defragmentation, to copy them again.
+type defragDirty struct {
+	keys map[string]map[string]struct{}
+	// created and deleted are the created and deleted buckets, the deleted
+	// ones are copied again as a whole.
+	created map[string]struct{}
+	deleted map[string]struct{}
+	n       int
+}
+
+func newDefragDirty() *defragDirty {
+	return &defragDirty{
+		keys:    make(map[string]map[string]struct{}),
+		created: make(map[string]struct{}),
+		deleted: make(map[string]struct{}),
+	}
+}
+
+func (d *defragDirty) write(bucket, key []byte) {
+	keys, ok := d.keys[string(bucket)]
+	if !ok {
+		keys = make(map[string]struct{})
+		d.keys[string(bucket)] = keys
+	}
+	if _, ok := keys[string(key)]; !ok {
+		keys[string(key)] = struct{}{}
+		d.n++
+	}
+}
+
+func (d *defragDirty) createBucket(bucket []byte) {
+	d.created[string(bucket)] = struct{}{}
+	d.n++
+}
+
+func (d *defragDirty) deleteBucket(bucket []byte) {
+	d.n -= len(d.keys[string(bucket)])
+	delete(d.keys, string(bucket))
+	d.deleted[string(bucket)] = struct{}{}
+	d.n++
+}
+
+// defragIncremental copies the database into the target of inc in batches
+// of defragBatchLimit keys, pausing defragBatchInterval between batches,
+// while the backend keeps serving. The keys written meanwhile are copied
+// again in catch-up rounds, then the writes are stopped to copy the last
+// ones and switch to the copy.
+func (b *backend) defragIncremental(inc IncrementalDefragmenter) (err error) {
+	now := time.Now()
+	isDefragActive.Set(1)
+	defer isDefragActive.Set(0)
+	b.updateDefragStatus(func(st *DefragStatus) { *st = DefragStatus{InProgress: true, Phase: DefragPhaseCopy, Started: now} })
+	defer b.updateDefragStatus(func(st *DefragStatus) { st.InProgress = false })
+
+	target, err := inc.DefragTarget()
+	if err != nil {
+		return err
+	}
+	switched := false
+	defer func() {
+		if switched {
+			return
+		}
+		b.batchTx.Mutex.Lock()
+		b.defragDirty = nil
+		b.batchTx.Mutex.Unlock()
+		target.Close()
+		if rmErr := os.RemoveAll(target.Path()); rmErr != nil {
+			b.lg.Error("failed to remove db.tmp after defragmentation completed", zap.Error(rmErr))
+		}
+	}()
+
+	dbp := b.db.Path()
+	size1, sizeInUse1 := b.Size(), b.SizeInUse()
+	b.lg.Info(
+		"defragmenting incrementally",
+		zap.String("path", dbp),
+		zap.Int64("current-db-size-bytes", size1),
+		zap.String("current-db-size", humanize.Bytes(uint64(size1))),
+		zap.Int64("current-db-size-in-use-bytes", sizeInUse1),
+		zap.String("current-db-size-in-use", humanize.Bytes(uint64(sizeInUse1))),
+		zap.Int("batch-limit", b.defragBatchLimit),
+		zap.Duration("batch-interval", b.defragBatchInterval),
+	)
+
+	src, _, err := b.defragSnapshot()
+	if err != nil {
+		return err
+	}
+	total := int64(0)
+	src.ForEachBucket(func(_ []byte, bucket EngineBucket) error {
+		return bucket.ForEach(func(_, _ []byte) error {
+			total++
+			return nil
+		})
+	})
+	b.updateDefragStatus(func(st *DefragStatus) { st.TotalKeys = total })
+
+	c := b.newDefragCopier(target, true)
+	c.committed = func(n int) {
+		b.updateDefragStatus(func(st *DefragStatus) { st.CopiedKeys += int64(n) })
+	}
+	err = src.ForEachBucket(func(name []byte, bucket EngineBucket) error {
+		return c.copyBucket(name, bucket)
+	})
+	if err == nil {
+		err = c.commit()
+	}
+	src.Rollback()
+	if err != nil {
+		return err
+	}
+
+	c.committed = nil
+	b.updateDefragStatus(func(st *DefragStatus) { st.Phase = DefragPhaseCatchUp })
+	for round := 0; round < defragMaxCatchUpRounds && b.defragPending() > b.defragBatchLimit; round++ {
+		var dirty *defragDirty
+		if src, dirty, err = b.defragSnapshot(); err != nil {
+			return err
+		}
+		err = c.copyDirty(src, dirty)
+		src.Rollback()
+		if err != nil {
+			return err
+		}
+	}
+
+	b.updateDefragStatus(func(st *DefragStatus) { st.Phase = DefragPhaseSwitch })
+	pause := time.Now()
+
+	// stop the writes and the reads as the blocking defragmentation does.
+	b.batchTx.LockOutsideApply()
+	defer b.batchTx.Unlock()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.readTx.Lock()
+	defer b.readTx.Unlock()
+	if b.stopped() {
+		return ErrDefragAborted
+	}
+
+	b.batchTx.unsafeCommit(true)
+	b.batchTx.tx = nil
+
+	dirty := b.defragDirty
+	b.defragDirty = nil
+	src = b.unsafeBegin(false)
+	c.paced = false
+	err = c.copyDirty(src, dirty)
+	src.Rollback()
+	if err != nil {
+		b.batchTx.tx = b.unsafeBegin(true)
+		b.readTx.reset()
+		b.readTx.tx = b.unsafeBegin(false)
+		return err
+	}
+	switched = true
+	// gofail: var defragBeforeSwitch struct{}
+	if err = inc.ReplaceWith(target); err != nil {
+		b.lg.Fatal("failed to switch to the defragmented database", zap.Error(err))
+	}
+	b.batchTx.tx = b.unsafeBegin(true)
+
+	b.readTx.reset()
+	b.readTx.tx = b.unsafeBegin(false)
+
+	size := b.readTx.tx.Size()
+	atomic.StoreInt64(&b.size, size)
+	atomic.StoreInt64(&b.sizeInUse, size-b.db.Stats().FreeBytes)
+
+	took := time.Since(now)
+	defragSec.Observe(took.Seconds())
+
+	size2, sizeInUse2 := b.Size(), b.SizeInUse()
+	b.lg.Info(
+		"finished defragmenting directory",
+		zap.String("path", dbp),
+		zap.Int64("current-db-size-bytes-diff", size2-size1),
+		zap.Int64("current-db-size-bytes", size2),
+		zap.String("current-db-size", humanize.Bytes(uint64(size2))),
+		zap.Int64("current-db-size-in-use-bytes-diff", sizeInUse2-sizeInUse1),
+		zap.Int64("current-db-size-in-use-bytes", sizeInUse2),
+		zap.String("current-db-size-in-use", humanize.Bytes(uint64(sizeInUse2))),
+		zap.Duration("took", took),
+		zap.Duration("pause", time.Since(pause)),
+	)
+	return nil
+}
+
+// defragSnapshot commits the pending writes and begins a read transaction
+// seeing them. It returns the keys written since the previous call, and
+// records the ones written from now on.
+func (b *backend) defragSnapshot() (EngineTx, *defragDirty, error) {
+	b.batchTx.LockOutsideApply()
+	defer b.batchTx.Unlock()
+	// the batch transaction is stopped once stopc is closed.
+	if b.stopped() {
+		return nil, nil, ErrDefragAborted
+	}
+	b.batchTx.commit(false)
+	dirty := b.defragDirty
+	b.defragDirty = newDefragDirty()
+	return b.begin(false), dirty, nil
+}
+
+func (b *backend) stopped() bool {
+	select {
+	case <-b.stopc:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *backend) defragPending() int {
+	b.batchTx.Mutex.Lock()
+	defer b.batchTx.Mutex.Unlock()
+	return b.defragDirty.n
+}
+
+// defragCopier writes into the target in transactions of defragBatchLimit
+// keys.
+type defragCopier struct {
+	b      *backend
+	target Engine
+	tx     EngineTx
+	count  int
+	// paced pauses defragBatchInterval after each transaction.
+	paced bool
+	// committed is called with the number of writes of each committed
+	// transaction.
+	committed func(n int)
+}
+
+func (b *backend) newDefragCopier(target Engine, paced bool) *defragCopier {
+	return &defragCopier{b: b, target: target, paced: paced}
+}
+
+// next counts a write, it commits the transaction once full.
+func (c *defragCopier) next() error {
+	c.count++
+	if c.count < c.b.defragBatchLimit {
+		return nil
+	}
+	if err := c.commit(); err != nil {
+		return err
+	}
+	if c.paced {
+		select {
+		case <-time.After(c.b.defragBatchInterval):
+		case <-c.b.stopc:
+			return ErrDefragAborted
+		}
+	}
+	return nil
+}
+
+func (c *defragCopier) begin() (EngineTx, error) {
+	if c.tx == nil {
+		tx, err := c.target.Begin(true)
+		if err != nil {
+			return nil, err
+		}
+		c.tx = tx
+	}
+	return c.tx, nil
+}
+
+func (c *defragCopier) commit() error {
+	if c.tx == nil {
+		return nil
+	}
+	err := c.tx.Commit()
+	if err == nil && c.committed != nil {
+		c.committed(c.count)
+	}
+	c.tx, c.count = nil, 0
+	return err
+}
+
+func (c *defragCopier) bucket(name []byte) (EngineBucket, error) {
+	tx, err := c.begin()
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.CreateBucket(name); err != nil {
+		return nil, err
+	}
+	return tx.Bucket(name), nil
+}
+
+func (c *defragCopier) copyBucket(name []byte, bucket EngineBucket) error {
+	if _, err := c.bucket(name); err != nil {
+		return err
+	}
+	return bucket.ForEach(func(k, v []byte) error {
+		// the bucket of the transaction changes with the transaction.
+		tb, err := c.bucket(name)
+		if err != nil {
+			return err
+		}
+		if err = tb.SeqPut(k, v); err != nil {
+			return err
+		}
+		return c.next()
+	})
+}
+
+// copyDirty copies from src the buckets and the keys of dirty.
+func (c *defragCopier) copyDirty(src EngineTx, dirty *defragDirty) error {
+	for _, name := range sortedNames(dirty.deleted) {
+		tx, err := c.begin()
+		if err != nil {
+			return err
+		}
+		if err = tx.DeleteBucket([]byte(name)); err != nil {
+			return err
+		}
+		if bucket := src.Bucket([]byte(name)); bucket != nil {
+			if err = c.copyBucket([]byte(name), bucket); err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range sortedNames(dirty.created) {
+		if _, ok := dirty.deleted[name]; ok || src.Bucket([]byte(name)) == nil {
+			continue
+		}
+		if _, err := c.bucket([]byte(name)); err != nil {
+			return err
+		}
+	}
+	for name, keys := range dirty.keys {
+		sb := src.Bucket([]byte(name))
+		if _, ok := dirty.deleted[name]; ok || sb == nil {
+			continue
+		}
+		ks := make([]string, 0, len(keys))
+		for k := range keys {
+			ks = append(ks, k)
+		}
+		sort.Strings(ks)
+		cur := sb.Cursor()
+		for _, k := range ks {
+			tb, err := c.bucket([]byte(name))
+			if err != nil {
+				return err
+			}
+			if ck, cv := cur.Seek([]byte(k)); ck != nil && bytes.Equal(ck, []byte(k)) {
+				err = tb.Put([]byte(k), cv)
+			} else {
+				err = tb.Delete([]byte(k))
+			}
+			if err != nil {
+				return err
+			}
+			if err = c.next(); err != nil {
+				return err
+			}
+		}
+	}
+	return c.commit()
+}
+
+func sortedNames(names map[string]struct{}) []string {
+	s := make([]string, 0, len(names))
+	for name := range names {
+		s = append(s, name)
+	}
+	sort.Strings(s)
+	return s
+}
@@ -0,0 +1,139 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestBackendDefragIncremental ensures the writes done while the backend is
+// defragmented incrementally are kept.
+func TestBackendDefragIncremental(t *testing.T) {
+	bcfg := backend.DefaultBackendConfig(zaptest.NewLogger(t))
+	bcfg.DefragBatchLimit = 10
+	bcfg.DefragBatchInterval = time.Millisecond
+	b, _ := betesting.NewTmpBackendFromCfg(t, bcfg)
+	defer betesting.Close(t, b)
+
+	want := make(map[string]string)
+	tx := b.BatchTx()
+	tx.Lock()
+	tx.UnsafeCreateBucket(schema.Test)
+	tx.UnsafeCreateBucket(schema.Alarm)
+	for i := 0; i < 500; i++ {
+		k := fmt.Sprintf("foo_%03d", i)
+		tx.UnsafePut(schema.Test, []byte(k), []byte("bar"))
+		want[k] = "bar"
+	}
+	tx.UnsafePut(schema.Alarm, []byte("old"), []byte("alarm"))
+	tx.Unlock()
+	b.ForceCommit()
+
+	donec := make(chan error)
+	go func() { donec <- b.Defrag() }()
+
+	inProgress := false
+	for i := 0; ; i++ {
+		select {
+		case err := <-donec:
+			if err != nil {
+				t.Fatal(err)
+			}
+		default:
+			if st := b.DefragStatus(); st.InProgress {
+				inProgress = true
+			}
+			tx.Lock()
+			switch {
+			case i == 20:
+				tx.UnsafeDeleteBucket(schema.Alarm)
+			case i == 30:
+				tx.UnsafeCreateBucket(schema.Alarm)
+				tx.UnsafePut(schema.Alarm, []byte("new"), []byte("alarm"))
+			case i%2 == 0:
+				k := fmt.Sprintf("foo_%03d", i%500)
+				tx.UnsafePut(schema.Test, []byte(k), []byte(fmt.Sprintf("baz_%d", i)))
+				want[k] = fmt.Sprintf("baz_%d", i)
+			default:
+				k := fmt.Sprintf("foo_%03d", (i*7)%500)
+				tx.UnsafeDelete(schema.Test, []byte(k))
+				delete(want, k)
+			}
+			tx.Unlock()
+			time.Sleep(100 * time.Microsecond)
+			continue
+		}
+		if i <= 30 {
+			t.Fatalf("defragmentation finished after %d writes, want more than 30", i)
+		}
+		break
+	}
+	if !inProgress {
+		t.Error("defragmentation never reported in progress")
+	}
+	st := b.DefragStatus()
+	if st.InProgress || st.Phase != backend.DefragPhaseSwitch || st.TotalKeys < 500 || st.CopiedKeys != st.TotalKeys {
+		t.Errorf("status = %+v, want finished with all the keys copied", st)
+	}
+
+	b.ForceCommit()
+	got := make(map[string]string)
+	tx.Lock()
+	tx.UnsafeForEach(schema.Test, func(k, v []byte) error {
+		got[string(k)] = string(v)
+		return nil
+	})
+	ks, _ := tx.UnsafeRange(schema.Alarm, []byte{0}, []byte{0xff}, 0)
+	tx.Unlock()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("keys after defragmentation differ from the written ones")
+	}
+	if len(ks) != 1 || string(ks[0]) != "new" {
+		t.Errorf("keys of the recreated bucket = %q, want [new]", ks)
+	}
+}
+
+func TestBackendDefragIncrementalClose(t *testing.T) {
+	bcfg := backend.DefaultBackendConfig(zaptest.NewLogger(t))
+	bcfg.DefragBatchLimit = 1
+	bcfg.DefragBatchInterval = time.Hour
+	b, _ := betesting.NewTmpBackendFromCfg(t, bcfg)
+
+	tx := b.BatchTx()
+	tx.Lock()
+	tx.UnsafeCreateBucket(schema.Test)
+	tx.UnsafePut(schema.Test, []byte("foo"), []byte("bar"))
+	tx.UnsafePut(schema.Test, []byte("foo2"), []byte("bar"))
+	tx.Unlock()
+	b.ForceCommit()
+
+	donec := make(chan error)
+	go func() { donec <- b.Defrag() }()
+	for !b.DefragStatus().InProgress {
+		time.Sleep(time.Millisecond)
+	}
+	betesting.Close(t, b)
+	if err := <-donec; err != backend.ErrDefragAborted {
+		t.Errorf("err = %v, want %v", err, backend.ErrDefragAborted)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// EngineBolt is the name of the default storage engine, bbolt.
+const EngineBolt = "bbolt"
+
+// Engine is the storage engine the backend keeps its buckets in. The backend
+// batches the writes in a single writable transaction, committed periodically,
+// and serves the reads from a read transaction begun after each commit.
+type Engine interface {
+	// Path returns the path of the database.
+	Path() string
+	// Begin begins a transaction. At most one writable transaction is open
+	// at a time, while read transactions can be open concurrently.
+	Begin(writable bool) (EngineTx, error)
+	// Stats returns the statistics of the database.
+	Stats() EngineStats
+	// Defrag reclaims the unused space of the database. It is called with
+	// no open transaction.
+	Defrag() error
+	// Close closes the database. It is called with no open transaction.
+	Close() error
+}
+
+// IncrementalDefragmenter is implemented by the engines whose database can be
+// copied into a new one while the backend keeps serving, so that the backend
+// only stops the writes to switch to the copy.
+type IncrementalDefragmenter interface {
+	// DefragTarget creates an empty database to copy the database into.
+	DefragTarget() (Engine, error)
+	// ReplaceWith replaces the database with target, closing both. It is
+	// called with no open transaction.
+	ReplaceWith(target Engine) error
+}
+
+// EngineStats are the statistics of the database of an Engine.
+type EngineStats struct {
+	// FreeBytes is the number of bytes allocated by the database but not
+	// in use, e.g. free pages.
+	FreeBytes int64
+	// OpenReadTxN is the number of open read transactions.
+	OpenReadTxN int
+}
+
+// EngineTx is a transaction of an Engine.
+type EngineTx interface {
+	// Bucket returns the bucket with the given name, nil if it does not exist.
+	Bucket(name []byte) EngineBucket
+	// CreateBucket creates a bucket, it does nothing if the bucket exists.
+	CreateBucket(name []byte) error
+	// DeleteBucket deletes a bucket, it does nothing if the bucket does not exist.
+	DeleteBucket(name []byte) error
+	// ForEachBucket calls fn for each bucket, in the order of their names.
+	ForEachBucket(fn func(name []byte, b EngineBucket) error) error
+	// Size returns the size of the database seen by the transaction.
+	Size() int64
+	// WriteTo writes the database seen by the transaction to w. It is the
+	// snapshot sent to the other members and saved by clients.
+	WriteTo(w io.Writer) (n int64, err error)
+	// Commit commits the writes of a writable transaction.
+	Commit() error
+	// Rollback closes a read transaction or discards the writes of a
+	// writable transaction.
+	Rollback() error
+}
+
+// EngineBucket is a bucket of an EngineTx. The keys and values it returns
+// are valid until the end of the transaction.
+type EngineBucket interface {
+	Put(key, value []byte) error
+	// SeqPut puts a key greater than the existing ones, a hint for the
+	// engines that optimize append-only writes.
+	SeqPut(key, value []byte) error
+	Delete(key []byte) error
+	// Cursor returns a cursor over the keys of the bucket, in order.
+	Cursor() EngineCursor
+	ForEach(fn func(k, v []byte) error) error
+}
+
+// EngineCursor iterates over the keys of an EngineBucket. Both methods return
+// a nil key once there is no more key.
+type EngineCursor interface {
+	// Seek moves to the first key greater than or equal to key.
+	Seek(key []byte) (k, v []byte)
+	// Next moves to the next key.
+	Next() (k, v []byte)
+}
+
+// EngineFactory opens the database of an Engine with the given configuration.
+type EngineFactory func(cfg BackendConfig) (Engine, error)
+
+var (
+	enginesMu sync.RWMutex
+	engines   = map[string]EngineFactory{EngineBolt: openBoltEngine}
+)
+
+// RegisterEngine makes a storage engine available under the given name, to
+// be selected with BackendConfig.Engine. It panics if the name is taken.
+func RegisterEngine(name string, f EngineFactory) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	if _, ok := engines[name]; ok {
+		panic(fmt.Sprintf("backend: engine %q is already registered", name))
+	}
+	engines[name] = f
+}
+
+// Engines returns the names of the registered storage engines, sorted.
+func Engines() []string {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func openEngine(cfg BackendConfig) (Engine, error) {
+	name := cfg.Engine
+	if name == "" {
+		name = EngineBolt
+	}
+	enginesMu.RLock()
+	f, ok := engines[name]
+	enginesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown backend engine %q (registered engines: %v)", name, Engines())
+	}
+	return f(cfg)
+}
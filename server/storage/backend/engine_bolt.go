@@ -0,0 +1,269 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// boltEngine is the Engine storing the buckets in a bbolt database.
+type boltEngine struct {
+	lg    *zap.Logger
+	bopts *bolt.Options
+	db    *bolt.DB
+}
+
+func openBoltEngine(bcfg BackendConfig) (Engine, error) {
+	bopts := &bolt.Options{}
+	if boltOpenOptions != nil {
+		*bopts = *boltOpenOptions
+	}
+	bopts.InitialMmapSize = bcfg.mmapSize()
+	bopts.FreelistType = bcfg.BackendFreelistType
+	bopts.NoSync = bcfg.UnsafeNoFsync
+	bopts.NoGrowSync = bcfg.UnsafeNoFsync
+	bopts.Mlock = bcfg.Mlock
+
+	db, err := bolt.Open(bcfg.Path, 0600, bopts)
+	if err != nil {
+		return nil, err
+	}
+	return &boltEngine{lg: bcfg.Logger, bopts: bopts, db: db}, nil
+}
+
+func (e *boltEngine) Path() string { return e.db.Path() }
+
+func (e *boltEngine) Begin(writable bool) (EngineTx, error) {
+	tx, err := e.db.Begin(writable)
+	if err != nil {
+		return nil, err
+	}
+	return &boltTx{tx}, nil
+}
+
+func (e *boltEngine) Stats() EngineStats {
+	stats := e.db.Stats()
+	return EngineStats{
+		FreeBytes:   int64(stats.FreePageN) * int64(e.db.Info().PageSize),
+		OpenReadTxN: stats.OpenTxN,
+	}
+}
+
+func (e *boltEngine) Close() error { return e.db.Close() }
+
+// Defrag copies the database into a new file, which replaces the current one.
+func (e *boltEngine) Defrag() error {
+	target, err := e.DefragTarget()
+	if err != nil {
+		return err
+	}
+	tmpdb := target.(*boltEngine).db
+
+	// gofail: var defragBeforeCopy struct{}
+	err = defragdb(e.db, tmpdb, defragLimit)
+	if err != nil {
+		tmpdb.Close()
+		if rmErr := os.RemoveAll(tmpdb.Path()); rmErr != nil {
+			e.lg.Error("failed to remove db.tmp after defragmentation completed", zap.Error(rmErr))
+		}
+		return err
+	}
+	return e.ReplaceWith(target)
+}
+
+// DefragTarget creates a temporary file next to the database.
+func (e *boltEngine) DefragTarget() (Engine, error) {
+	// Create a temporary file to ensure we start with a clean slate.
+	// Snapshotter.cleanupSnapdir cleans up any of these that are found during startup.
+	dir := filepath.Dir(e.db.Path())
+	temp, err := os.CreateTemp(dir, "db.tmp.*")
+	if err != nil {
+		return nil, err
+	}
+	options := bolt.Options{}
+	if boltOpenOptions != nil {
+		options = *boltOpenOptions
+	}
+	options.OpenFile = func(_ string, _ int, _ os.FileMode) (file *os.File, err error) {
+		return temp, nil
+	}
+	// Don't load tmp db into memory regardless of opening options
+	options.Mlock = false
+	tmpdb, err := bolt.Open(temp.Name(), 0600, &options)
+	if err != nil {
+		return nil, err
+	}
+	return &boltEngine{lg: e.lg, bopts: e.bopts, db: tmpdb}, nil
+}
+
+// ReplaceWith renames the file of target over the database.
+func (e *boltEngine) ReplaceWith(target Engine) error {
+	t, ok := target.(*boltEngine)
+	if !ok {
+		return fmt.Errorf("backend: cannot replace a bbolt database with %T", target)
+	}
+	dbp, tdbp := e.db.Path(), t.db.Path()
+
+	err := e.db.Close()
+	if err != nil {
+		e.lg.Fatal("failed to close database", zap.Error(err))
+	}
+	err = t.db.Close()
+	if err != nil {
+		e.lg.Fatal("failed to close tmp database", zap.Error(err))
+	}
+	// gofail: var defragBeforeRename struct{}
+	err = os.Rename(tdbp, dbp)
+	if err != nil {
+		e.lg.Fatal("failed to rename tmp database", zap.Error(err))
+	}
+
+	e.db, err = bolt.Open(dbp, 0600, e.bopts)
+	if err != nil {
+		e.lg.Fatal("failed to open database", zap.String("path", dbp), zap.Error(err))
+	}
+	return nil
+}
+
+func defragdb(odb, tmpdb *bolt.DB, limit int) error {
+	// open a tx on tmpdb for writes
+	tmptx, err := tmpdb.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmptx.Rollback()
+		}
+	}()
+
+	// open a tx on old db for read
+	tx, err := odb.Begin(false)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	c := tx.Cursor()
+
+	count := 0
+	for next, _ := c.First(); next != nil; next, _ = c.Next() {
+		b := tx.Bucket(next)
+		if b == nil {
+			return fmt.Errorf("backend: cannot defrag bucket %s", string(next))
+		}
+
+		tmpb, berr := tmptx.CreateBucketIfNotExists(next)
+		if berr != nil {
+			return berr
+		}
+		tmpb.FillPercent = 0.9 // for bucket2seq write in for each
+
+		if err = b.ForEach(func(k, v []byte) error {
+			count++
+			if count > limit {
+				err = tmptx.Commit()
+				if err != nil {
+					return err
+				}
+				tmptx, err = tmpdb.Begin(true)
+				if err != nil {
+					return err
+				}
+				tmpb = tmptx.Bucket(next)
+				tmpb.FillPercent = 0.9 // for bucket2seq write in for each
+
+				count = 0
+			}
+			return tmpb.Put(k, v)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return tmptx.Commit()
+}
+
+type boltTx struct {
+	*bolt.Tx
+}
+
+func (tx *boltTx) Bucket(name []byte) EngineBucket {
+	b := tx.Tx.Bucket(name)
+	if b == nil {
+		return nil
+	}
+	return &boltBucket{b}
+}
+
+func (tx *boltTx) CreateBucket(name []byte) error {
+	_, err := tx.Tx.CreateBucket(name)
+	if err == bolt.ErrBucketExists {
+		return nil
+	}
+	return err
+}
+
+func (tx *boltTx) DeleteBucket(name []byte) error {
+	err := tx.Tx.DeleteBucket(name)
+	if err == bolt.ErrBucketNotFound {
+		return nil
+	}
+	return err
+}
+
+func (tx *boltTx) ForEachBucket(fn func(name []byte, b EngineBucket) error) error {
+	return tx.Tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+		return fn(name, &boltBucket{b})
+	})
+}
+
+// Check verifies the pages of the database, draining all the errors so that
+// the checking goroutine ends.
+func (tx *boltTx) Check() error {
+	var first error
+	for err := range tx.Tx.Check() {
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (tx *boltTx) Commit() error {
+	err := tx.Tx.Commit()
+	rebalanceSec.Observe(tx.Stats().RebalanceTime.Seconds())
+	spillSec.Observe(tx.Stats().SpillTime.Seconds())
+	writeSec.Observe(tx.Stats().WriteTime.Seconds())
+	return err
+}
+
+type boltBucket struct {
+	*bolt.Bucket
+}
+
+func (b *boltBucket) SeqPut(key, value []byte) error {
+	// it is useful to increase fill percent when the workloads are mostly append-only.
+	// this can delay the page split and reduce space usage.
+	b.FillPercent = 0.9
+	return b.Put(key, value)
+}
+
+func (b *boltBucket) Cursor() EngineCursor { return b.Bucket.Cursor() }
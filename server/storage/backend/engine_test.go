@@ -0,0 +1,83 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend_test
+
+import (
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+	"go.uber.org/zap/zaptest"
+)
+
+// countingEngine counts the writable transactions of the bbolt engine.
+type countingEngine struct {
+	backend.Engine
+	writes *int64
+}
+
+func (e *countingEngine) Begin(writable bool) (backend.EngineTx, error) {
+	if writable {
+		atomic.AddInt64(e.writes, 1)
+	}
+	return e.Engine.Begin(writable)
+}
+
+func TestBackendEngine(t *testing.T) {
+	var writes int64
+	backend.RegisterEngine("counting", func(bcfg backend.BackendConfig) (backend.Engine, error) {
+		e, err := backend.OpenBoltEngineForTest(bcfg)
+		if err != nil {
+			return nil, err
+		}
+		return &countingEngine{Engine: e, writes: &writes}, nil
+	})
+	assert.Contains(t, backend.Engines(), "counting")
+	assert.Contains(t, backend.Engines(), backend.EngineBolt)
+	assert.Panics(t, func() { backend.RegisterEngine("counting", nil) })
+
+	bcfg := backend.DefaultBackendConfig(zaptest.NewLogger(t))
+	bcfg.Engine = "counting"
+	b, _ := betesting.NewTmpBackendFromCfg(t, bcfg)
+	defer betesting.Close(t, b)
+
+	tx := b.BatchTx()
+	tx.Lock()
+	tx.UnsafeCreateBucket(schema.Test)
+	tx.UnsafePut(schema.Test, []byte("foo"), []byte("bar"))
+	tx.Unlock()
+	b.ForceCommit()
+	if atomic.LoadInt64(&writes) == 0 {
+		t.Fatal("expected the backend to write through the registered engine")
+	}
+
+	assert.NoError(t, b.Defrag())
+	rtx := b.ReadTx()
+	rtx.RLock()
+	_, vals := rtx.UnsafeRange(schema.Test, []byte("foo"), nil, 0)
+	rtx.RUnlock()
+	assert.Equal(t, [][]byte{[]byte("bar")}, vals)
+}
+
+func TestBackendUnknownEngine(t *testing.T) {
+	bcfg := backend.DefaultBackendConfig(zaptest.NewLogger(t))
+	bcfg.Path = filepath.Join(t.TempDir(), "db")
+	bcfg.Engine = "unknown"
+	assert.Panics(t, func() { backend.New(bcfg) })
+}
@@ -3,7 +3,7 @@ package backend
 import bolt "go.etcd.io/bbolt"
 
 func DbFromBackendForTest(b Backend) *bolt.DB {
-	return b.(*backend).db
+	return b.(*backend).db.(*boltEngine).db
 }
 
 func DefragLimitForTest() int {
@@ -13,3 +13,7 @@ func DefragLimitForTest() int {
 func CommitsForTest(b Backend) int64 {
 	return b.(*backend).Commits()
 }
+
+func OpenBoltEngineForTest(bcfg BackendConfig) (Engine, error) {
+	return openBoltEngine(bcfg)
+}
@@ -90,6 +90,52 @@ var (
 		Name:      "defrag_inflight",
 		Help:      "Whether or not defrag is active on the member. 1 means active, 0 means not.",
 	})
+
+	scrubbedRecords = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd_debugging",
+		Subsystem: "disk",
+		Name:      "backend_scrub_records_total",
+		Help:      "The total number of records verified by the backend scrubbing.",
+	})
+
+	scrubCorruptions = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "disk",
+		Name:      "backend_scrub_corruptions_total",
+		Help:      "The total number of corruptions found by the backend scrubbing.",
+	})
+
+	scrubLastPassTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "disk",
+		Name:      "backend_scrub_last_pass_timestamp_seconds",
+		Help:      "The unix time the last backend scrubbing pass completed without corruption.",
+	})
+
+	commitBatchSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "etcd_debugging",
+		Subsystem: "disk",
+		Name:      "backend_commit_batch_size",
+		Help:      "The distribution of the number of pending operations committed by each backend commit.",
+
+		// lowest bucket start of upper bound 1 with factor 2
+		// highest bucket start of 1 * 2^19 == 524288
+		Buckets: prometheus.ExponentialBuckets(1, 2, 20),
+	})
+
+	batchIntervalSec = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "etcd_debugging",
+		Subsystem: "disk",
+		Name:      "backend_batch_interval_seconds",
+		Help:      "The effective maximum time before the backend transaction is committed.",
+	})
+
+	batchLimitOps = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "etcd_debugging",
+		Subsystem: "disk",
+		Name:      "backend_batch_limit",
+		Help:      "The effective maximum number of operations before the backend transaction is committed.",
+	})
 )
 
 func init() {
@@ -100,4 +146,10 @@ func init() {
 	prometheus.MustRegister(defragSec)
 	prometheus.MustRegister(snapshotTransferSec)
 	prometheus.MustRegister(isDefragActive)
+	prometheus.MustRegister(scrubbedRecords)
+	prometheus.MustRegister(scrubCorruptions)
+	prometheus.MustRegister(scrubLastPassTimestamp)
+	prometheus.MustRegister(commitBatchSize)
+	prometheus.MustRegister(batchIntervalSec)
+	prometheus.MustRegister(batchLimitOps)
 }
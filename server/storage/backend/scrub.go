@@ -0,0 +1,144 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// DefaultScrubRate is the default number of records verified per second by
+// the background scrubbing.
+const DefaultScrubRate = 10000
+
+// Checker is implemented by the engine transactions able to verify the
+// consistency of the pages of the database they see.
+type Checker interface {
+	// Check returns the first inconsistency found in the database, nil if
+	// there is none.
+	Check() error
+}
+
+// ScrubPosition is the position of a scrubbing pass, the next record to
+// verify. The zero value is the start of a pass.
+type ScrubPosition struct {
+	Bucket []byte
+	Key    []byte
+}
+
+// ScrubVerifier verifies the content of a record, returning why it is
+// corrupted if it is.
+type ScrubVerifier func(bucket, key, value []byte) error
+
+// CorruptionError is a corruption of the database found by Scrub. Bucket
+// and Key are the corrupted record, both nil if the corruption was found in
+// the pages of the database.
+type CorruptionError struct {
+	Bucket []byte
+	Key    []byte
+	Err    error
+}
+
+func (e *CorruptionError) Error() string {
+	if e.Bucket == nil {
+		return fmt.Sprintf("backend: corrupted database: %v", e.Err)
+	}
+	return fmt.Sprintf("backend: corrupted record %q of bucket %q: %v", e.Key, e.Bucket, e.Err)
+}
+
+func (e *CorruptionError) Unwrap() error { return e.Err }
+
+// Scrub verifies at most limit records from pos, in the order of the buckets
+// and of their keys, and returns the position of the next record. The pass
+// is done once all the records were verified, the engine transactions
+// implementing Checker then verify the pages of the whole database. A
+// corruption is returned as a *CorruptionError.
+//
+// Each call reads from a new transaction, so that scrubbing a large
+// database in batches does not hold its pages from being reused.
+func (b *backend) Scrub(pos ScrubPosition, limit int, verify ScrubVerifier) (next ScrubPosition, done bool, err error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	tx, err := b.db.Begin(false)
+	if err != nil {
+		return pos, false, err
+	}
+	defer tx.Rollback()
+
+	var bucket, key []byte
+	n := 0
+	defer func() {
+		scrubbedRecords.Add(float64(n))
+		// a corrupted page can make the engine panic while reading it
+		if r := recover(); r != nil {
+			err = &CorruptionError{Bucket: bucket, Key: clone(key), Err: fmt.Errorf("%v", r)}
+		}
+		if _, ok := err.(*CorruptionError); ok {
+			scrubCorruptions.Inc()
+		}
+	}()
+
+	var names [][]byte
+	if err = tx.ForEachBucket(func(name []byte, _ EngineBucket) error {
+		if bytes.Compare(name, pos.Bucket) >= 0 {
+			names = append(names, clone(name))
+		}
+		return nil
+	}); err != nil {
+		return pos, false, err
+	}
+	for _, bucket = range names {
+		c := tx.Bucket(bucket).Cursor()
+		seek := []byte{}
+		if bytes.Equal(bucket, pos.Bucket) && pos.Key != nil {
+			seek = pos.Key
+		}
+		var prev []byte
+		for k, v := c.Seek(seek); k != nil; k, v = c.Next() {
+			key = k
+			if n == limit {
+				return ScrubPosition{Bucket: bucket, Key: clone(k)}, false, nil
+			}
+			if prev != nil && bytes.Compare(prev, k) >= 0 {
+				return pos, false, &CorruptionError{Bucket: bucket, Key: clone(k), Err: fmt.Errorf("key not greater than the previous key %q", prev)}
+			}
+			if verify != nil {
+				if verr := verify(bucket, k, v); verr != nil {
+					return pos, false, &CorruptionError{Bucket: bucket, Key: clone(k), Err: verr}
+				}
+			}
+			prev = k
+			n++
+		}
+	}
+
+	bucket, key = nil, nil
+	if c, ok := tx.(Checker); ok {
+		if cerr := c.Check(); cerr != nil {
+			return pos, false, &CorruptionError{Err: cerr}
+		}
+	}
+	scrubLastPassTimestamp.Set(float64(time.Now().Unix()))
+	return ScrubPosition{}, true, nil
+}
+
+// clone copies b out of the transaction it was read from.
+func clone(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+func TestBackendScrub(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, b)
+
+	tx := b.BatchTx()
+	tx.Lock()
+	tx.UnsafeCreateBucket(schema.Test)
+	tx.UnsafeCreateBucket(schema.Alarm)
+	for i := 0; i < 10; i++ {
+		tx.UnsafePut(schema.Test, []byte(fmt.Sprintf("foo_%02d", i)), []byte("bar"))
+	}
+	tx.UnsafePut(schema.Alarm, []byte("alarm"), []byte("bar"))
+	tx.Unlock()
+	b.ForceCommit()
+
+	seen := make(map[string]int)
+	verify := func(bucket, key, value []byte) error {
+		seen[string(bucket)+"/"+string(key)]++
+		return nil
+	}
+	var pos backend.ScrubPosition
+	for batches := 1; ; batches++ {
+		next, done, err := b.Scrub(pos, 3, verify)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done {
+			break
+		}
+		if batches > 100 {
+			t.Fatal("scrubbing pass never done")
+		}
+		pos = next
+	}
+	for i := 0; i < 10; i++ {
+		if k := fmt.Sprintf("test/foo_%02d", i); seen[k] != 1 {
+			t.Errorf("%q verified %d times, want 1", k, seen[k])
+		}
+	}
+	if seen["alarm/alarm"] != 1 {
+		t.Errorf("alarm verified %d times, want 1", seen["alarm/alarm"])
+	}
+
+	errCorrupted := errors.New("corrupted")
+	_, _, err := b.Scrub(backend.ScrubPosition{}, 100, func(bucket, key, value []byte) error {
+		if string(key) == "foo_05" {
+			return errCorrupted
+		}
+		return nil
+	})
+	var cerr *backend.CorruptionError
+	if !errors.As(err, &cerr) || !errors.Is(err, errCorrupted) {
+		t.Fatalf("err = %v, want corruption error", err)
+	}
+	if string(cerr.Bucket) != "test" || string(cerr.Key) != "foo_05" {
+		t.Errorf("corrupted record = %q/%q, want test/foo_05", cerr.Bucket, cerr.Key)
+	}
+}
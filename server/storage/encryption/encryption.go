@@ -0,0 +1,193 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package encryption encrypts the WAL records and the snapshot files at rest.
+//
+// The data is encrypted with AES-256-GCM by data encryption keys (DEK),
+// generated at random for each WAL segment and snapshot file. A DEK is
+// stored along the data it encrypts, wrapped by a key encryption key (KEK)
+// of a KeyProvider, which only it holds. Rotating the KEK wraps the next
+// DEKs with a new one; the previous KEKs must remain available to unwrap
+// the DEKs of the data written before, until it is purged.
+//
+// The backend database, and the database snapshots sent to the members
+// catching up, are not encrypted.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// KeySize is the size in bytes of the keys, for AES-256.
+const KeySize = 32
+
+var (
+	ErrKeyNotFound      = errors.New("encryption: key encryption key not found")
+	ErrCorruptedKey     = errors.New("encryption: corrupted wrapped key")
+	ErrCorruptedData    = errors.New("encryption: corrupted encrypted data")
+	ErrNoKeyProvider    = errors.New("encryption: the data is encrypted but no key provider is set")
+	ErrInvalidKeyLength = fmt.Errorf("encryption: keys must be %d bytes long", KeySize)
+)
+
+// KeyProvider wraps and unwraps the data encryption keys with key encryption
+// keys it holds, such as the ones of a file or of a key management service.
+type KeyProvider interface {
+	// WrapKey encrypts dek with the current key encryption key, returning
+	// the id of that key.
+	WrapKey(dek []byte) (kekID string, wrapped []byte, err error)
+	// UnwrapKey decrypts dek wrapped with the key encryption key kekID.
+	UnwrapKey(kekID string, wrapped []byte) (dek []byte, err error)
+}
+
+// DataKey is a data encryption key.
+type DataKey struct {
+	aead cipher.AEAD
+	// wrapped is the marshaled id of the key encryption key and the data
+	// encryption key it wrapped.
+	wrapped []byte
+}
+
+// NewDataKey generates a data encryption key wrapped by kp.
+func NewDataKey(kp KeyProvider) (*DataKey, error) {
+	dek := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return nil, err
+	}
+	kekID, wrapped, err := kp.WrapKey(dek)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 0, 2*binary.MaxVarintLen64+len(kekID)+len(wrapped))
+	b = appendBytes(b, []byte(kekID))
+	b = appendBytes(b, wrapped)
+	return &DataKey{aead: aead, wrapped: b}, nil
+}
+
+// OpenDataKey unwraps with kp the data encryption key wrapped returned by
+// the Wrapped method of a DataKey.
+func OpenDataKey(kp KeyProvider, wrapped []byte) (*DataKey, error) {
+	if kp == nil {
+		return nil, ErrNoKeyProvider
+	}
+	kekID, rest, ok := readBytes(wrapped)
+	if !ok {
+		return nil, ErrCorruptedKey
+	}
+	wdek, rest, ok := readBytes(rest)
+	if !ok || len(rest) != 0 {
+		return nil, ErrCorruptedKey
+	}
+	dek, err := kp.UnwrapKey(string(kekID), wdek)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return nil, err
+	}
+	return &DataKey{aead: aead, wrapped: wrapped}, nil
+}
+
+// Wrapped returns the key wrapped, to store along the data it encrypts.
+func (k *DataKey) Wrapped() []byte { return k.wrapped }
+
+// Seal returns plaintext encrypted, prefixed by its random nonce.
+func (k *DataKey) Seal(plaintext []byte) []byte {
+	return seal(k.aead, plaintext)
+}
+
+// Open returns the plaintext of ciphertext returned by Seal.
+func (k *DataKey) Open(ciphertext []byte) ([]byte, error) {
+	return open(k.aead, ciphertext)
+}
+
+// Encrypt encrypts plaintext with a new data encryption key wrapped by kp,
+// returning the wrapped key followed by the ciphertext.
+func Encrypt(kp KeyProvider, plaintext []byte) ([]byte, error) {
+	k, err := NewDataKey(kp)
+	if err != nil {
+		return nil, err
+	}
+	return append(appendBytes(nil, k.wrapped), k.Seal(plaintext)...), nil
+}
+
+// Decrypt returns the plaintext of b returned by Encrypt, unwrapping its
+// key with kp.
+func Decrypt(kp KeyProvider, b []byte) ([]byte, error) {
+	wrapped, ciphertext, ok := readBytes(b)
+	if !ok {
+		return nil, ErrCorruptedKey
+	}
+	k, err := OpenDataKey(kp, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	return k.Open(ciphertext)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKeyLength
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext []byte) []byte {
+	out := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, out); err != nil {
+		// crypto/rand never fails on the supported platforms
+		panic(err)
+	}
+	return aead.Seal(out, out, plaintext, nil)
+}
+
+func open(aead cipher.AEAD, ciphertext []byte) ([]byte, error) {
+	n := aead.NonceSize()
+	if len(ciphertext) < n+aead.Overhead() {
+		return nil, ErrCorruptedData
+	}
+	b, err := aead.Open(nil, ciphertext[:n], ciphertext[n:], nil)
+	if err != nil {
+		return nil, ErrCorruptedData
+	}
+	return b, nil
+}
+
+func appendBytes(b, v []byte) []byte {
+	var l [binary.MaxVarintLen64]byte
+	b = append(b, l[:binary.PutUvarint(l[:], uint64(len(v)))]...)
+	return append(b, v...)
+}
+
+func readBytes(b []byte) (v, rest []byte, ok bool) {
+	l, n := binary.Uvarint(b)
+	if n <= 0 || l > uint64(len(b)-n) {
+		return nil, nil, false
+	}
+	return b[n : n+int(l)], b[n+int(l):], true
+}
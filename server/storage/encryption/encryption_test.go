@@ -0,0 +1,116 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeKeyFile(t *testing.T, ids ...string) string {
+	var lines []string
+	for _, id := range ids {
+		// the same id is always the same key
+		key := bytes.Repeat([]byte(id[len(id)-1:]), KeySize)
+		lines = append(lines, id+":"+base64.StdEncoding.EncodeToString(key))
+	}
+	p := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(p, []byte("# key encryption keys\n"+strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	kp, err := NewFileKeyProvider(writeKeyFile(t, "k1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte("some secret data")
+	b, err := Encrypt(kp, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, plaintext) {
+		t.Fatal("the encrypted data contains the plaintext")
+	}
+	d, err := Decrypt(kp, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(d, plaintext) {
+		t.Errorf("decrypted %q, want %q", d, plaintext)
+	}
+
+	b[len(b)-1] ^= 1
+	if _, err = Decrypt(kp, b); err != ErrCorruptedData {
+		t.Errorf("err = %v, want %v", err, ErrCorruptedData)
+	}
+	if _, err = Decrypt(nil, b); err != ErrNoKeyProvider {
+		t.Errorf("err = %v, want %v", err, ErrNoKeyProvider)
+	}
+}
+
+func TestFileKeyProviderRotation(t *testing.T) {
+	kp1, err := NewFileKeyProvider(writeKeyFile(t, "k1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Encrypt(kp1, []byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// k2 wraps the new keys, k1 still unwraps the ones it wrapped
+	kp2, err := NewFileKeyProvider(writeKeyFile(t, "k2", "k1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Decrypt(kp2, b); err != nil {
+		t.Fatal(err)
+	}
+	k, err := NewDataKey(kp2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = OpenDataKey(kp1, k.Wrapped()); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("err = %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func TestNewFileKeyProviderErrors(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(make([]byte, KeySize))
+	for _, content := range []string{
+		"",
+		"k1",
+		":" + key,
+		"k1:not base64",
+		"k1:" + base64.StdEncoding.EncodeToString(make([]byte, 16)),
+		"k1:" + key + "\nk1:" + key,
+	} {
+		p := filepath.Join(t.TempDir(), "keys")
+		if err := os.WriteFile(p, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewFileKeyProvider(p); err == nil {
+			t.Errorf("%q: expected error", content)
+		}
+	}
+}
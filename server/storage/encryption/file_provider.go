@@ -0,0 +1,85 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// fileKeyProvider wraps the data encryption keys with the key encryption
+// keys of a file.
+type fileKeyProvider struct {
+	current string
+	keks    map[string]cipher.AEAD
+}
+
+// NewFileKeyProvider returns a KeyProvider holding the key encryption keys
+// of the file at path, one "<id>:<base64 encoded 32 bytes key>" per line,
+// the empty lines and the ones starting with '#' being ignored. The first
+// key wraps the data encryption keys, the others only unwrap the ones
+// they wrapped before: a key is rotated by adding a new one first.
+func NewFileKeyProvider(path string) (KeyProvider, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	kp := &fileKeyProvider{keks: make(map[string]cipher.AEAD)}
+	for i, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, ":", 2)
+		if len(fields) != 2 || fields[0] == "" {
+			return nil, fmt.Errorf("encryption: %s:%d: expected <id>:<base64 key>", path, i+1)
+		}
+		id := fields[0]
+		key, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("encryption: %s:%d: %v", path, i+1, err)
+		}
+		if _, ok := kp.keks[id]; ok {
+			return nil, fmt.Errorf("encryption: %s:%d: duplicate key id %q", path, i+1, id)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("encryption: %s:%d: %v", path, i+1, err)
+		}
+		kp.keks[id] = aead
+		if kp.current == "" {
+			kp.current = id
+		}
+	}
+	if kp.current == "" {
+		return nil, fmt.Errorf("encryption: %s: no key encryption key", path)
+	}
+	return kp, nil
+}
+
+func (kp *fileKeyProvider) WrapKey(dek []byte) (string, []byte, error) {
+	return kp.current, seal(kp.keks[kp.current], dek), nil
+}
+
+func (kp *fileKeyProvider) UnwrapKey(kekID string, wrapped []byte) ([]byte, error) {
+	aead, ok := kp.keks[kekID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, kekID)
+	}
+	return open(aead, wrapped)
+}
@@ -0,0 +1,182 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+const (
+	// CompressionNone stores the values as is.
+	CompressionNone = "none"
+	// CompressionZstd compresses the values with zstd.
+	CompressionZstd = "zstd"
+	// CompressionLZ4 compresses the values with lz4.
+	CompressionLZ4 = "lz4"
+
+	// DefaultValueCompressionThreshold is the size in bytes from which a
+	// value is compressed.
+	DefaultValueCompressionThreshold = 1024
+)
+
+// A compressed revision is stored as compressedMark, the codec, the uvarint
+// size of the marshaled mvccpb.KeyValue and the compressed marshaled
+// mvccpb.KeyValue. A marshaled mvccpb.KeyValue starts with the tag of its
+// non-empty key, so it never starts with compressedMark, and the revisions
+// stored without compression are read as before.
+const (
+	compressedMark byte = 0
+
+	codecZstd byte = 1
+	codecLZ4  byte = 2
+)
+
+// maxDecompressedSize bounds the memory allocated to decompress a value
+// read from a corrupted database.
+const maxDecompressedSize = 1 << 30
+
+var errCorruptedValue = errors.New("mvcc: corrupted compressed value")
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+func zstdCodec() (*zstd.Encoder, *zstd.Decoder) {
+	zstdOnce.Do(func() {
+		// both never fail with these options
+		zstdEncoder, _ = zstd.NewWriter(nil)
+		zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+	})
+	return zstdEncoder, zstdDecoder
+}
+
+// ValidValueCompression returns an error if c is not a value compression.
+func ValidValueCompression(c string) error {
+	switch c {
+	case "", CompressionNone, CompressionZstd, CompressionLZ4:
+		return nil
+	}
+	return fmt.Errorf("unknown value compression %q (expected %q, %q or %q)", c, CompressionNone, CompressionZstd, CompressionLZ4)
+}
+
+// valueCompressor compresses the marshaled mvccpb.KeyValue of the values
+// of at least threshold bytes.
+type valueCompressor struct {
+	codec     byte
+	threshold int
+}
+
+// newValueCompressor returns nil if the values are not compressed.
+func newValueCompressor(compression string, threshold int) *valueCompressor {
+	if threshold <= 0 {
+		threshold = DefaultValueCompressionThreshold
+	}
+	switch compression {
+	case CompressionZstd:
+		return &valueCompressor{codec: codecZstd, threshold: threshold}
+	case CompressionLZ4:
+		return &valueCompressor{codec: codecLZ4, threshold: threshold}
+	}
+	return nil
+}
+
+// compress returns the revision to store for kv marshaled into d. The
+// revision is stored as is if the value is under the threshold or does not
+// compress.
+func (c *valueCompressor) compress(kv *mvccpb.KeyValue, d []byte) []byte {
+	if c == nil || len(kv.Value) < c.threshold {
+		return d
+	}
+	out := make([]byte, 2+binary.MaxVarintLen64, 2+binary.MaxVarintLen64+len(d))
+	out[0], out[1] = compressedMark, c.codec
+	out = out[:2+binary.PutUvarint(out[2:], uint64(len(d)))]
+	switch c.codec {
+	case codecZstd:
+		enc, _ := zstdCodec()
+		out = enc.EncodeAll(d, out)
+	case codecLZ4:
+		buf := make([]byte, lz4.CompressBlockBound(len(d)))
+		n, err := lz4.CompressBlock(d, buf, nil)
+		if err != nil || n == 0 {
+			return d
+		}
+		out = append(out, buf[:n]...)
+	}
+	if len(out) >= len(d) {
+		return d
+	}
+	valueCompressionUncompressedBytes.Add(float64(len(d)))
+	valueCompressionCompressedBytes.Add(float64(len(out)))
+	valueCompressionRatio.Observe(float64(len(d)) / float64(len(out)))
+	return out
+}
+
+// decompressRevision returns the marshaled mvccpb.KeyValue of the revision
+// value v read from the key bucket, whether it is compressed or not.
+func decompressRevision(v []byte) ([]byte, error) {
+	if len(v) == 0 || v[0] != compressedMark {
+		return v, nil
+	}
+	if len(v) < 3 {
+		return nil, errCorruptedValue
+	}
+	size, n := binary.Uvarint(v[2:])
+	if n <= 0 || size > maxDecompressedSize {
+		return nil, errCorruptedValue
+	}
+	src := v[2+n:]
+	switch v[1] {
+	case codecZstd:
+		_, dec := zstdCodec()
+		d, err := dec.DecodeAll(src, make([]byte, 0, size))
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(d)) != size {
+			return nil, errCorruptedValue
+		}
+		return d, nil
+	case codecLZ4:
+		d := make([]byte, size)
+		m, err := lz4.UncompressBlock(src, d)
+		if err != nil {
+			return nil, err
+		}
+		if uint64(m) != size {
+			return nil, errCorruptedValue
+		}
+		return d, nil
+	}
+	return nil, fmt.Errorf("mvcc: unknown value compression codec %d", v[1])
+}
+
+// UnmarshalKeyValue unmarshals into kv the revision value v read from the
+// key bucket, decompressing it if needed.
+func UnmarshalKeyValue(v []byte, kv *mvccpb.KeyValue) error {
+	d, err := decompressRevision(v)
+	if err != nil {
+		return err
+	}
+	return kv.Unmarshal(d)
+}
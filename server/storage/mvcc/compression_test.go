@@ -0,0 +1,123 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"reflect"
+	"testing"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestValueCompressor(t *testing.T) {
+	random := make([]byte, 4096)
+	rand.Read(random)
+
+	tests := []struct {
+		name  string
+		value []byte
+
+		wcompressed bool
+	}{
+		{"under threshold", bytes.Repeat([]byte("a"), 100), false},
+		{"compressible", bytes.Repeat([]byte(`{"name":"etcd","tags":["a","b"]}`), 128), true},
+		{"incompressible", random, false},
+	}
+	for _, compression := range []string{CompressionZstd, CompressionLZ4} {
+		c := newValueCompressor(compression, 1024)
+		for _, tt := range tests {
+			t.Run(compression+"/"+tt.name, func(t *testing.T) {
+				kv := mvccpb.KeyValue{Key: []byte("foo"), Value: tt.value, CreateRevision: 2, ModRevision: 2, Version: 1}
+				d, err := kv.Marshal()
+				if err != nil {
+					t.Fatal(err)
+				}
+				v := c.compress(&kv, d)
+				if compressed := v[0] == compressedMark; compressed != tt.wcompressed {
+					t.Fatalf("compressed = %v, want %v", compressed, tt.wcompressed)
+				}
+				if tt.wcompressed && len(v) >= len(d) {
+					t.Errorf("compressed size = %d, want less than %d", len(v), len(d))
+				}
+				var got mvccpb.KeyValue
+				if err := UnmarshalKeyValue(v, &got); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, kv) {
+					t.Errorf("kv = %+v, want %+v", got, kv)
+				}
+			})
+		}
+	}
+}
+
+func TestValueCompressionCorrupted(t *testing.T) {
+	for _, v := range [][]byte{
+		{compressedMark},
+		{compressedMark, codecZstd, 0xff},
+		{compressedMark, codecLZ4, 10, 1, 2, 3},
+		{compressedMark, 42, 1, 0},
+	} {
+		var kv mvccpb.KeyValue
+		if err := UnmarshalKeyValue(v, &kv); err == nil {
+			t.Errorf("expected an error unmarshaling %v", v)
+		}
+	}
+}
+
+// TestStoreValueCompression ensures the compressed values are read back,
+// also by a store not compressing them, and do not change the hash.
+func TestStoreValueCompression(t *testing.T) {
+	for _, compression := range []string{CompressionZstd, CompressionLZ4} {
+		t.Run(compression, func(t *testing.T) {
+			hashes := make(map[string]uint32)
+			for _, c := range []string{CompressionNone, compression} {
+				b, _ := betesting.NewDefaultTmpBackend(t)
+				s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{ValueCompression: c, ValueCompressionThreshold: 64})
+				value := bytes.Repeat([]byte("bar"), 100)
+				s.Put([]byte("foo"), value, lease.NoLease)
+				s.Put([]byte("small"), []byte("bar"), lease.NoLease)
+				s.DeleteRange([]byte("small"), nil)
+
+				hash, _, _, err := s.HashByRev(0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				hashes[c] = hash
+				s.Close()
+
+				s = NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+				r, err := s.Range(context.TODO(), []byte("foo"), nil, RangeOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(r.KVs) != 1 || !bytes.Equal(r.KVs[0].Value, value) {
+					t.Errorf("range = %+v, want value %q", r.KVs, value)
+				}
+				s.Close()
+				b.Close()
+			}
+			if hashes[CompressionNone] != hashes[compression] {
+				t.Errorf("hash = %d, want %d as without compression", hashes[compression], hashes[CompressionNone])
+			}
+		})
+	}
+}
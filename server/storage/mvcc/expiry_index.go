@@ -0,0 +1,103 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/google/btree"
+)
+
+// expiryIndex indexes the keys put with an expire time by the time they
+// expire at. It is the same on every member, as it is only changed by the
+// applied writes, so that the keys it returns as expired are too.
+type expiryIndex struct {
+	sync.Mutex
+	tree *btree.BTree
+	// times is the expire time of each key indexed.
+	times map[string]int64
+}
+
+type expiryItem struct {
+	t   int64
+	key string
+}
+
+func (a expiryItem) Less(b btree.Item) bool {
+	bi := b.(expiryItem)
+	if a.t != bi.t {
+		return a.t < bi.t
+	}
+	return a.key < bi.key
+}
+
+func newExpiryIndex() *expiryIndex {
+	return &expiryIndex{tree: btree.New(32), times: make(map[string]int64)}
+}
+
+// set indexes key expiring at t, in place of its previous expire time, or
+// removes it from the index if t is 0.
+func (ei *expiryIndex) set(key []byte, t int64) {
+	ei.Lock()
+	defer ei.Unlock()
+	ei.unsafeRemove(string(key))
+	if t != 0 {
+		ei.tree.ReplaceOrInsert(expiryItem{t: t, key: string(key)})
+		ei.times[string(key)] = t
+	}
+}
+
+func (ei *expiryIndex) remove(key []byte) {
+	ei.Lock()
+	defer ei.Unlock()
+	ei.unsafeRemove(string(key))
+}
+
+// removeRange removes the keys in [key, end), an empty end being the end of
+// the keyspace.
+func (ei *expiryIndex) removeRange(key, end []byte) {
+	ei.Lock()
+	defer ei.Unlock()
+	for k := range ei.times {
+		if bytes.Compare([]byte(k), key) >= 0 && (len(end) == 0 || bytes.Compare([]byte(k), end) < 0) {
+			ei.unsafeRemove(k)
+		}
+	}
+}
+
+func (ei *expiryIndex) unsafeRemove(key string) {
+	if t, ok := ei.times[key]; ok {
+		ei.tree.Delete(expiryItem{t: t, key: key})
+		delete(ei.times, key)
+	}
+}
+
+// expired returns the keys expiring at t or before, in the order of their
+// expiration, at most limit of them if limit is positive.
+func (ei *expiryIndex) expired(t int64, limit int) [][]byte {
+	ei.Lock()
+	defer ei.Unlock()
+	var keys [][]byte
+	ei.tree.Ascend(func(item btree.Item) bool {
+		it := item.(expiryItem)
+		if it.t > t || (limit > 0 && len(keys) == limit) {
+			return false
+		}
+		keys = append(keys, []byte(it.key))
+		return true
+	})
+	return keys
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestStoreExpiredKeys(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+	defer cleanup(s, b, tmpPath)
+
+	s.PutExpiring([]byte("a"), []byte("1"), lease.NoLease, 30)
+	s.PutExpiring([]byte("b"), []byte("2"), lease.NoLease, 10)
+	s.PutExpiring([]byte("c"), []byte("3"), lease.NoLease, 20)
+	s.PutExpiring([]byte("d"), []byte("4"), lease.NoLease, 20)
+	s.Put([]byte("e"), []byte("5"), lease.NoLease)
+
+	r, err := s.Range(context.TODO(), []byte("b"), nil, RangeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.KVs[0].ExpireTime != 10 {
+		t.Errorf("expire time of b = %d, want 10", r.KVs[0].ExpireTime)
+	}
+
+	expired := func(now int64, limit int) []string {
+		var keys []string
+		for _, k := range s.ExpiredKeys(now, limit) {
+			keys = append(keys, string(k))
+		}
+		return keys
+	}
+	if keys := expired(5, 0); keys != nil {
+		t.Errorf("keys expired at 5 = %q, want none", keys)
+	}
+	if keys, want := expired(20, 0), []string{"b", "c", "d"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys expired at 20 = %q, want %q", keys, want)
+	}
+	if keys, want := expired(100, 2), []string{"b", "c"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("2 keys expired at 100 = %q, want %q", keys, want)
+	}
+
+	// putting a key again without an expire time, or deleting it, removes it
+	s.Put([]byte("b"), []byte("2"), lease.NoLease)
+	s.DeleteRange([]byte("c"), nil)
+	s.PutExpiring([]byte("d"), []byte("4"), lease.NoLease, 40)
+	if keys, want := expired(100, 0), []string{"a", "d"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys expired at 100 = %q, want %q", keys, want)
+	}
+
+	s.Commit()
+	ns := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+	defer ns.Close()
+	var keys []string
+	for _, k := range ns.ExpiredKeys(100, 0) {
+		keys = append(keys, string(k))
+	}
+	if want := []string{"a", "d"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("restored keys expired at 100 = %q, want %q", keys, want)
+	}
+}
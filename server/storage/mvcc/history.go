@@ -0,0 +1,96 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"sort"
+
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+// The history bucket keeps the revisions removed by the compactions as they
+// were stored in the key bucket, so that the watchers replay the events of
+// the compacted revisions. historyStartKey records the first revision of
+// the history; it sorts after the revisions, which start with a zero byte.
+var historyStartKey = []byte("start")
+
+// nextHistoryRev returns the first revision of the history kept with
+// retention after the compaction at rev, the history starting at start
+// before it.
+func nextHistoryRev(start, rev, retention int64) int64 {
+	if retention <= 0 {
+		return rev + 1
+	}
+	if min := rev - retention + 1; start < min {
+		return min
+	}
+	return start
+}
+
+// unsafeSaveHistory copies a revision removed by the compaction to the
+// history.
+func unsafeSaveHistory(tx backend.BatchTx, key, val []byte) {
+	tx.UnsafePut(schema.History, append([]byte{}, key...), append([]byte{}, val...))
+}
+
+// unsafePruneHistory removes the revisions before start from the history,
+// recording start.
+func unsafePruneHistory(tx backend.BatchTx, start int64) {
+	end := newRevBytes()
+	revToBytes(revision{main: start}, end)
+	keys, _ := tx.UnsafeRange(schema.History, newRevBytes(), end, 0)
+	for _, k := range keys {
+		tx.UnsafeDelete(schema.History, k)
+	}
+	tx.UnsafePut(schema.History, historyStartKey, end)
+}
+
+// unsafeReadHistoryStart returns the first revision of the history, and
+// false if no history was recorded.
+func unsafeReadHistoryStart(tx backend.ReadTx) (int64, bool) {
+	_, vs := tx.UnsafeRange(schema.History, historyStartKey, nil, 0)
+	if len(vs) == 0 {
+		return 0, false
+	}
+	return bytesToRev(vs[0]).main, true
+}
+
+// mergeHistory merges the revisions of the history into the ones of the
+// key bucket, in the order of revision, skipping the duplicates.
+func mergeHistory(hrevs, hvs, revs, vs [][]byte) ([][]byte, [][]byte) {
+	if len(hrevs) == 0 {
+		return revs, vs
+	}
+	type kv struct{ k, v []byte }
+	kvs := make([]kv, 0, len(hrevs)+len(revs))
+	for i := range hrevs {
+		kvs = append(kvs, kv{hrevs[i], hvs[i]})
+	}
+	for i := range revs {
+		kvs = append(kvs, kv{revs[i], vs[i]})
+	}
+	sort.SliceStable(kvs, func(i, j int) bool { return bytes.Compare(kvs[i].k, kvs[j].k) < 0 })
+
+	mrevs, mvs := make([][]byte, 0, len(kvs)), make([][]byte, 0, len(kvs))
+	for i := range kvs {
+		if i > 0 && bytes.Equal(kvs[i].k, kvs[i-1].k) {
+			continue
+		}
+		mrevs, mvs = append(mrevs, kvs[i].k), append(mvs, kvs[i].v)
+	}
+	return mrevs, mvs
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestWatchHistory(t *testing.T) {
+	tests := []struct {
+		retention int64
+		startRev  int64
+
+		wantCompactRev int64
+		wantRevs       []int64
+	}{
+		// the compacted revisions are replayed from the history
+		{retention: 100, startRev: 2, wantRevs: []int64{2, 3, 4, 5, 6}},
+		{retention: 2, startRev: 4, wantRevs: []int64{4, 5, 6}},
+		// the history starts at revision 4
+		{retention: 2, startRev: 3, wantCompactRev: 4},
+		{retention: 0, startRev: 2, wantCompactRev: 5},
+	}
+	for _, tt := range tests {
+		b, tmpPath := betesting.NewDefaultTmpBackend(t)
+		cfg := StoreConfig{HistoryRetention: tt.retention}
+		s := newWatchableStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, cfg)
+
+		for i := 0; i < 3; i++ {
+			s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+		}
+		s.DeleteRange([]byte("foo"), nil)
+		s.Put([]byte("foo"), []byte("baz"), lease.NoLease)
+		ch, err := s.Compact(traceutil.TODO(), 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		<-ch
+
+		// the history is restored with the store
+		s.Close()
+		s = newWatchableStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, cfg)
+
+		w := s.NewWatchStream()
+		w.Watch(0, []byte("foo"), nil, tt.startRev)
+		var revs []int64
+		for len(revs) < len(tt.wantRevs) || (tt.wantCompactRev != 0 && revs == nil) {
+			var resp WatchResponse
+			select {
+			case resp = <-w.Chan():
+			case <-time.After(5 * time.Second):
+				t.Fatalf("retention %d: timed out, got revisions %v", tt.retention, revs)
+			}
+			if resp.CompactRevision != tt.wantCompactRev {
+				t.Fatalf("retention %d: compact revision = %d, want %d", tt.retention, resp.CompactRevision, tt.wantCompactRev)
+			}
+			if resp.CompactRevision != 0 {
+				break
+			}
+			for _, ev := range resp.Events {
+				if wantDelete := ev.Kv.ModRevision == 5; wantDelete != (ev.Type == mvccpb.DELETE) {
+					t.Fatalf("retention %d: unexpected event %+v", tt.retention, ev)
+				}
+				revs = append(revs, ev.Kv.ModRevision)
+			}
+		}
+		if tt.wantRevs != nil && !reflect.DeepEqual(revs, tt.wantRevs) {
+			t.Errorf("retention %d: revisions = %v, want %v", tt.retention, revs, tt.wantRevs)
+		}
+
+		w.Close()
+		s.Close()
+		b.Close()
+		os.Remove(tmpPath)
+	}
+}
+
+func TestNextHistoryRev(t *testing.T) {
+	tests := []struct {
+		start, rev, retention int64
+		want                  int64
+	}{
+		{start: 11, rev: 20, retention: 0, want: 21},
+		{start: 11, rev: 20, retention: 100, want: 11},
+		{start: 11, rev: 20, retention: 5, want: 16},
+	}
+	for _, tt := range tests {
+		if got := nextHistoryRev(tt.start, tt.rev, tt.retention); got != tt.want {
+			t.Errorf("nextHistoryRev(%d, %d, %d) = %d, want %d", tt.start, tt.rev, tt.retention, got, tt.want)
+		}
+	}
+}
@@ -15,6 +15,7 @@
 package mvcc
 
 import (
+	"bytes"
 	"sort"
 	"sync"
 
@@ -32,16 +33,69 @@ type index interface {
 	RangeSince(key, end []byte, rev int64) []revision
 	Compact(rev int64) map[revision]struct{}
 	Keep(rev int64) map[revision]struct{}
+	// CompactRange compacts the keys in [key, end) at rev and returns the
+	// revisions it removed. An empty end is the end of the keyspace.
+	CompactRange(key, end []byte, rev int64) map[revision]struct{}
+	// KeepRange finds the revisions of the keys in [key, end) to be kept for
+	// a CompactRange at rev.
+	KeepRange(key, end []byte, rev int64) map[revision]struct{}
 	Equal(b index) bool
 
+	// DeleteRange records a range tombstone at rev deleting the keys in
+	// [key, end), in place of a tombstone for each of them.
+	DeleteRange(key, end []byte, rev revision)
+	// RangeTombstones returns the range tombstones not compacted yet, in the
+	// order of revision.
+	RangeTombstones() []rangeTombstone
+	// RangeDeleted returns the keys deleted by the range tombstone t.
+	RangeDeleted(t rangeTombstone) [][]byte
+	// Materialize tombstones ki at the revision of the first range tombstone
+	// before rev deleting it.
+	Materialize(ki *keyIndex, rev revision)
+
 	Insert(ki *keyIndex)
 	KeyIndex(ki *keyIndex) *keyIndex
 }
 
+// rangeTombstone deletes the keys in [key, end) last modified before rev.
+// The tree index hides these keys until the compaction at rev tombstones
+// each of them, or they are put again. An empty end is the end of the
+// keyspace.
+type rangeTombstone struct {
+	key, end []byte
+	rev      revision
+}
+
+func (t rangeTombstone) covers(key []byte) bool {
+	return bytes.Compare(key, t.key) >= 0 && (len(t.end) == 0 || bytes.Compare(key, t.end) < 0)
+}
+
+// deleted returns whether t deleted ki, which it did if the last revision
+// of ki before t is not a tombstone.
+func (t rangeTombstone) deleted(ki *keyIndex) bool {
+	if !t.covers(ki.key) {
+		return false
+	}
+	for gi := len(ki.generations) - 1; gi >= 0; gi-- {
+		revs := ki.generations[gi].revs
+		for i := len(revs) - 1; i >= 0; i-- {
+			if !t.rev.GreaterThan(revs[i]) {
+				continue
+			}
+			// the last revision of a generation but the current one is a tombstone
+			return i != len(revs)-1 || gi == len(ki.generations)-1
+		}
+	}
+	return false
+}
+
 type treeIndex struct {
 	sync.RWMutex
 	tree *btree.BTree
 	lg   *zap.Logger
+	// tombstones are the range tombstones not compacted yet, in the order
+	// of revision.
+	tombstones []rangeTombstone
 }
 
 func newTreeIndex(lg *zap.Logger) index {
@@ -63,6 +117,7 @@ func (ti *treeIndex) Put(key []byte, rev revision) {
 		return
 	}
 	okeyi := item.(*keyIndex)
+	ti.materialize(okeyi, rev)
 	okeyi.put(ti.lg, rev.main, rev.sub)
 }
 
@@ -73,7 +128,71 @@ func (ti *treeIndex) Get(key []byte, atRev int64) (modified, created revision, v
 	if keyi = ti.keyIndex(keyi); keyi == nil {
 		return revision{}, revision{}, 0, ErrRevisionNotFound
 	}
-	return keyi.get(ti.lg, atRev)
+	return ti.get(keyi, atRev)
+}
+
+// get gets the revisions and version of ki at atRev, unless a range
+// tombstone deleted it.
+func (ti *treeIndex) get(ki *keyIndex, atRev int64) (modified, created revision, ver int64, err error) {
+	if _, ok := ti.deletedAt(ki, revision{main: atRev + 1}); ok {
+		return revision{}, revision{}, 0, ErrRevisionNotFound
+	}
+	return ki.get(ti.lg, atRev)
+}
+
+// deletedAt returns the revision of the first range tombstone before rev
+// deleting ki, which they do unless ki was modified after them.
+func (ti *treeIndex) deletedAt(ki *keyIndex, rev revision) (revision, bool) {
+	for _, t := range ti.tombstones {
+		if !rev.GreaterThan(t.rev) {
+			break
+		}
+		if t.rev.GreaterThan(ki.modified) && t.covers(ki.key) {
+			return t.rev, true
+		}
+	}
+	return revision{}, false
+}
+
+// materialize tombstones ki at the revision of the first range tombstone
+// before rev deleting it, so that it is not hidden anymore.
+func (ti *treeIndex) materialize(ki *keyIndex, rev revision) {
+	t, ok := ti.deletedAt(ki, rev)
+	if !ok || ki.generations[len(ki.generations)-1].isEmpty() {
+		return
+	}
+	if err := ki.tombstone(ti.lg, t.main, t.sub); err != nil {
+		ti.lg.Panic("failed to tombstone a key deleted by a range tombstone", zap.Error(err))
+	}
+}
+
+func (ti *treeIndex) Materialize(ki *keyIndex, rev revision) {
+	ti.Lock()
+	defer ti.Unlock()
+	ti.materialize(ki, rev)
+}
+
+func (ti *treeIndex) DeleteRange(key, end []byte, rev revision) {
+	ti.Lock()
+	defer ti.Unlock()
+	ti.tombstones = append(ti.tombstones, rangeTombstone{key: key, end: end, rev: rev})
+}
+
+func (ti *treeIndex) RangeTombstones() []rangeTombstone {
+	ti.RLock()
+	defer ti.RUnlock()
+	return append([]rangeTombstone(nil), ti.tombstones...)
+}
+
+func (ti *treeIndex) RangeDeleted(t rangeTombstone) (keys [][]byte) {
+	ti.visit(t.key, t.end, func(ki *keyIndex) bool {
+		// the keys deleted by an earlier range tombstone are not deleted again
+		if _, ok := ti.deletedAt(ki, t.rev); !ok && t.deleted(ki) {
+			keys = append(keys, ki.key)
+		}
+		return true
+	})
+	return keys
 }
 
 func (ti *treeIndex) KeyIndex(keyi *keyIndex) *keyIndex {
@@ -115,7 +234,7 @@ func (ti *treeIndex) Revisions(key, end []byte, atRev int64, limit int) (revs []
 		return []revision{rev}, 1
 	}
 	ti.visit(key, end, func(ki *keyIndex) bool {
-		if rev, _, _, err := ki.get(ti.lg, atRev); err == nil {
+		if rev, _, _, err := ti.get(ki, atRev); err == nil {
 			if limit <= 0 || len(revs) < limit {
 				revs = append(revs, rev)
 			}
@@ -136,7 +255,7 @@ func (ti *treeIndex) CountRevisions(key, end []byte, atRev int64) int {
 	}
 	total := 0
 	ti.visit(key, end, func(ki *keyIndex) bool {
-		if _, _, _, err := ki.get(ti.lg, atRev); err == nil {
+		if _, _, _, err := ti.get(ki, atRev); err == nil {
 			total++
 		}
 		return true
@@ -153,7 +272,7 @@ func (ti *treeIndex) Range(key, end []byte, atRev int64) (keys [][]byte, revs []
 		return [][]byte{key}, []revision{rev}
 	}
 	ti.visit(key, end, func(ki *keyIndex) bool {
-		if rev, _, _, err := ki.get(ti.lg, atRev); err == nil {
+		if rev, _, _, err := ti.get(ki, atRev); err == nil {
 			revs = append(revs, rev)
 			keys = append(keys, ki.key)
 		}
@@ -221,6 +340,7 @@ func (ti *treeIndex) Compact(rev int64) map[revision]struct{} {
 		// Lock is needed here to prevent modification to the keyIndex while
 		// compaction is going on or revision added to empty before deletion
 		ti.Lock()
+		ti.materialize(keyi, revision{main: rev + 1})
 		keyi.compact(ti.lg, rev, available)
 		if keyi.isEmpty() {
 			item := ti.tree.Delete(keyi)
@@ -231,6 +351,15 @@ func (ti *treeIndex) Compact(rev int64) map[revision]struct{} {
 		ti.Unlock()
 		return true
 	})
+
+	// the keys deleted by the range tombstones up to rev are tombstoned now
+	ti.Lock()
+	n := 0
+	for n < len(ti.tombstones) && ti.tombstones[n].rev.main <= rev {
+		n++
+	}
+	ti.tombstones = ti.tombstones[n:]
+	ti.Unlock()
 	return available
 }
 
@@ -247,6 +376,53 @@ func (ti *treeIndex) Keep(rev int64) map[revision]struct{} {
 	return available
 }
 
+func (ti *treeIndex) CompactRange(key, end []byte, rev int64) map[revision]struct{} {
+	ti.lg.Info("compact range of tree index",
+		zap.ByteString("key", key),
+		zap.ByteString("range-end", end),
+		zap.Int64("revision", rev),
+	)
+	var kis []*keyIndex
+	ti.visit(key, end, func(ki *keyIndex) bool {
+		kis = append(kis, ki)
+		return true
+	})
+
+	removed := make(map[revision]struct{})
+	available := make(map[revision]struct{})
+	for _, keyi := range kis {
+		ti.Lock()
+		ti.materialize(keyi, revision{main: rev + 1})
+		for _, g := range keyi.generations {
+			for _, r := range g.revs {
+				if r.main <= rev {
+					removed[r] = struct{}{}
+				}
+			}
+		}
+		keyi.compact(ti.lg, rev, available)
+		if keyi.isEmpty() {
+			if item := ti.tree.Delete(keyi); item == nil {
+				ti.lg.Panic("failed to delete during range compaction")
+			}
+		}
+		ti.Unlock()
+	}
+	for r := range available {
+		delete(removed, r)
+	}
+	return removed
+}
+
+func (ti *treeIndex) KeepRange(key, end []byte, rev int64) map[revision]struct{} {
+	available := make(map[revision]struct{})
+	ti.visit(key, end, func(ki *keyIndex) bool {
+		ki.keep(rev, available)
+		return true
+	})
+	return available
+}
+
 func (ti *treeIndex) Equal(bi index) bool {
 	b := bi.(*treeIndex)
 
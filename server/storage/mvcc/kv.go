@@ -35,6 +35,15 @@ type RangeResult struct {
 	Count int
 }
 
+// StatsResult is the number of keys in a range and the total size of their
+// keys and values.
+type StatsResult struct {
+	Rev        int64
+	Count      int64
+	KeyBytes   int64
+	ValueBytes int64
+}
+
 type ReadView interface {
 	// FirstRev returns the first KV revision at the time of opening the txn.
 	// After a compaction, the first revision increases to the compaction
@@ -79,6 +88,18 @@ type WriteView interface {
 	// A put also increases the rev of the store, and generates one event in the event history.
 	// The returned rev is the current revision of the KV when the operation is executed.
 	Put(key, value []byte, lease lease.LeaseID) (rev int64)
+
+	// PutExpiring puts the given key, value like Put, the key expiring at
+	// the unix time expireTime in seconds. A key put with an expire time of
+	// 0 does not expire. The KV does not delete the expired keys itself.
+	PutExpiring(key, value []byte, lease lease.LeaseID, expireTime int64) (rev int64)
+
+	// SetLease attaches the lease to the key in place, the key keeping its
+	// revisions: unlike a put, it neither increases the rev of the store nor
+	// generates an event. A lease of NoLease detaches the lease of the key.
+	// It returns false if the key does not exist. The reads of the txn do
+	// not see the change, written as the txn ends.
+	SetLease(key []byte, lease lease.LeaseID) bool
 }
 
 // TxnWrite represents a transaction that can modify the store.
@@ -96,6 +117,12 @@ func (trw *txnReadWrite) DeleteRange(key, end []byte) (n, rev int64) { panic("un
 func (trw *txnReadWrite) Put(key, value []byte, lease lease.LeaseID) (rev int64) {
 	panic("unexpected Put")
 }
+func (trw *txnReadWrite) PutExpiring(key, value []byte, lease lease.LeaseID, expireTime int64) (rev int64) {
+	panic("unexpected PutExpiring")
+}
+func (trw *txnReadWrite) SetLease(key []byte, lease lease.LeaseID) bool {
+	panic("unexpected SetLease")
+}
 func (trw *txnReadWrite) Changes() []mvccpb.KeyValue { return nil }
 
 func NewReadOnlyTxnWrite(txn TxnRead) TxnWrite { return &txnReadWrite{txn} }
@@ -125,9 +152,25 @@ type KV interface {
 	// HashByRev computes the hash of all MVCC revisions up to a given revision.
 	HashByRev(rev int64) (hash uint32, revision int64, compactRev int64, err error)
 
+	// Stats computes the number of keys in the range [key, end) at the current
+	// revision and the total size of their keys and values, without returning
+	// them. An empty end is the end of the keyspace.
+	Stats(ctx context.Context, key, end []byte) (*StatsResult, error)
+
+	// ExpiredKeys returns the keys put expiring at the unix time t or
+	// before, in the order of their expiration, at most limit of them if
+	// limit is positive.
+	ExpiredKeys(t int64, limit int) [][]byte
+
 	// Compact frees all superseded keys with revisions less than rev.
 	Compact(trace *traceutil.Trace, rev int64) (<-chan struct{}, error)
 
+	// CompactRange frees the superseded revisions less than rev of the keys
+	// in [key, end), an empty end being the end of the keyspace. The range
+	// cannot be read nor watched before rev anymore. The compaction is
+	// recorded at a new revision, before which HashByRev cannot hash.
+	CompactRange(trace *traceutil.Trace, key, end []byte, rev int64) (<-chan struct{}, error)
+
 	// Commit commits outstanding txns into the underlying backend.
 	Commit()
 
@@ -147,4 +190,17 @@ type Watchable interface {
 	// NewWatchStream returns a WatchStream that can be used to
 	// watch events happened or happening on the KV.
 	NewWatchStream() WatchStream
+
+	// WatchStreamStats returns the pending events and revision lag of
+	// every open watch stream.
+	WatchStreamStats() []WatchStreamStats
+
+	// WatcherStats returns the ranges, start revision and revision lag of
+	// the watchers of the open watch stream streamID, or of every open
+	// stream if streamID is 0.
+	WatcherStats(streamID int64) []WatcherStats
+
+	// CancelWatcher cancels a watcher of an open watch stream, which is
+	// sent a response canceling it with ErrWatcherCanceled.
+	CancelWatcher(streamID int64, id WatchID) error
 }
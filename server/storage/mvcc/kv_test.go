@@ -394,6 +394,52 @@ func testKVPutWithSameLease(t *testing.T, f putFunc) {
 	}
 }
 
+func TestKVSetLease(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{ReadCacheSize: 16})
+	defer cleanup(s, b, tmpPath)
+
+	s.Put([]byte("foo"), []byte("bar"), 1)
+	// cache the revision of foo
+	if _, err := s.Range(context.TODO(), []byte("foo"), nil, RangeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !s.SetLease([]byte("foo"), 2) {
+		t.Fatal("expected foo to be found")
+	}
+	if s.SetLease([]byte("bar"), 2) {
+		t.Error("expected bar not to be found")
+	}
+	if rev := s.Rev(); rev != 2 {
+		t.Errorf("rev = %d, want 2", rev)
+	}
+	r, err := s.Range(context.TODO(), []byte("foo"), nil, RangeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wkvs := []mvccpb.KeyValue{
+		{Key: []byte("foo"), Value: []byte("bar"), CreateRevision: 2, ModRevision: 2, Version: 1, Lease: 2},
+	}
+	if !reflect.DeepEqual(r.KVs, wkvs) {
+		t.Errorf("kvs = %+v, want %+v", r.KVs, wkvs)
+	}
+
+	// a key put by the txn keeps the revision the txn writes
+	txn := s.Write(traceutil.TODO())
+	txn.Put([]byte("baz"), []byte("qux"), 1)
+	if !txn.SetLease([]byte("baz"), lease.NoLease) {
+		t.Fatal("expected baz to be found")
+	}
+	txn.End()
+	if r, err = s.Range(context.TODO(), []byte("baz"), nil, RangeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.KVs) != 1 || r.KVs[0].Lease != 0 || r.KVs[0].ModRevision != 3 {
+		t.Errorf("kvs = %+v, want baz without lease at revision 3", r.KVs)
+	}
+}
+
 // test that range, put, delete on single key in sequence repeatedly works correctly.
 func TestKVOperationInSequence(t *testing.T) {
 	b, tmpPath := betesting.NewDefaultTmpBackend(t)
@@ -54,3 +54,15 @@ func (wv *writeView) Put(key, value []byte, lease lease.LeaseID) (rev int64) {
 	defer tw.End()
 	return tw.Put(key, value, lease)
 }
+
+func (wv *writeView) PutExpiring(key, value []byte, lease lease.LeaseID, expireTime int64) (rev int64) {
+	tw := wv.kv.Write(traceutil.TODO())
+	defer tw.End()
+	return tw.PutExpiring(key, value, lease, expireTime)
+}
+
+func (wv *writeView) SetLease(key []byte, lease lease.LeaseID) bool {
+	tw := wv.kv.Write(traceutil.TODO())
+	defer tw.End()
+	return tw.SetLease(key, lease)
+}
@@ -15,6 +15,7 @@
 package mvcc
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -54,6 +55,41 @@ var minimumBatchInterval = 10 * time.Millisecond
 type StoreConfig struct {
 	CompactionBatchLimit    int
 	CompactionSleepInterval time.Duration
+	// ValueCompression is the compression of the stored values, one of
+	// CompressionNone, CompressionZstd or CompressionLZ4. The values stored
+	// compressed are read whatever the compression.
+	ValueCompression string
+	// ValueCompressionThreshold is the size in bytes from which a value is
+	// compressed, DefaultValueCompressionThreshold if not set.
+	ValueCompressionThreshold int
+	// OnWrite, if set, is called with the changes of each write txn before
+	// it ends. The changes of the deleted keys have no create revision.
+	OnWrite func(changes []mvccpb.KeyValue)
+	// OnDeleteRange, if set, is called with the range of each range
+	// tombstone of a write txn before it ends, before OnWrite.
+	OnDeleteRange func(key, end []byte)
+	// RangeTombstoneThreshold is the number of keys from which a range
+	// deletion records a range tombstone instead of a tombstone for each of
+	// them. The compaction tombstones the keys deleted by a range tombstone.
+	// Range tombstones are not recorded if not positive.
+	RangeTombstoneThreshold int
+	// ReadCacheSize is the number of key-values decoded from the backend
+	// cached for the reads, the revision of each key superseded by a write
+	// being evicted. Nothing is cached if not positive.
+	ReadCacheSize int
+	// StuckWatcherPolicy is applied to the slow watchers blocked on their
+	// stream with a backlog larger than StuckWatcherMaxBytes or older than
+	// StuckWatcherMaxAge: StuckWatcherCancel, StuckWatcherPause or
+	// StuckWatcherCompress, StuckWatcherCancel if not set. The watchers are
+	// not checked if neither threshold is positive.
+	StuckWatcherPolicy   string
+	StuckWatcherMaxBytes int64
+	StuckWatcherMaxAge   time.Duration
+	// HistoryRetention is the number of revisions up to the compaction
+	// revision whose events are kept by the compaction for the watchers
+	// starting from a compacted revision. No history is kept if not
+	// positive, the history kept before being left in the backend.
+	HistoryRetention int64
 }
 
 type store struct {
@@ -62,11 +98,17 @@ type store struct {
 
 	cfg StoreConfig
 
+	// compressor is nil if the values are stored as is.
+	compressor *valueCompressor
+	// cache is nil if the reads are not cached.
+	cache *readCache
+
 	// mu read locks for txns and write locks for non-txn store changes.
 	mu sync.RWMutex
 
 	b       backend.Backend
 	kvindex index
+	expiry  *expiryIndex
 
 	le lease.Lessor
 
@@ -78,6 +120,16 @@ type store struct {
 	currentRev int64
 	// compactMainRev is the main revision of the last compaction.
 	compactMainRev int64
+	// historyRev is the first revision of the history the watchers replay
+	// the compacted revisions from, compactMainRev+1 if there is none.
+	historyRev int64
+	// rangeCompactions are the range compactions at a revision greater than
+	// compactMainRev, in the order they were recorded. They are changed
+	// holding both mu and revMu.
+	rangeCompactions []rangeCompaction
+	// rangeCompactRev is the revision the last range compaction was
+	// recorded at.
+	rangeCompactRev int64
 
 	fifoSched schedule.Scheduler
 
@@ -99,9 +151,12 @@ func NewStore(lg *zap.Logger, b backend.Backend, le lease.Lessor, cfg StoreConfi
 		cfg.CompactionSleepInterval = minimumBatchInterval
 	}
 	s := &store{
-		cfg:     cfg,
-		b:       b,
-		kvindex: newTreeIndex(lg),
+		cfg:        cfg,
+		compressor: newValueCompressor(cfg.ValueCompression, cfg.ValueCompressionThreshold),
+		cache:      newReadCache(cfg.ReadCacheSize),
+		b:          b,
+		kvindex:    newTreeIndex(lg),
+		expiry:     newExpiryIndex(),
 
 		le: le,
 
@@ -123,6 +178,9 @@ func NewStore(lg *zap.Logger, b backend.Backend, le lease.Lessor, cfg StoreConfi
 	tx := s.b.BatchTx()
 	tx.LockOutsideApply()
 	tx.UnsafeCreateBucket(schema.Key)
+	tx.UnsafeCreateBucket(schema.RangeTombstone)
+	tx.UnsafeCreateBucket(schema.RangeCompaction)
+	tx.UnsafeCreateBucket(schema.History)
 	schema.UnsafeCreateMetaBucket(tx)
 	tx.Unlock()
 	s.b.ForceCommit()
@@ -173,9 +231,12 @@ func (s *store) HashByRev(rev int64) (hash uint32, currentRev int64, compactRev
 	s.mu.RLock()
 	s.revMu.RLock()
 	compactRev, currentRev = s.compactMainRev, s.currentRev
+	rangeCompactRev, rcs := s.rangeCompactRev, s.rangeCompactions
 	s.revMu.RUnlock()
 
-	if rev > 0 && rev <= compactRev {
+	// the revisions removed by a range compaction cannot be hashed at a
+	// revision before the one it was recorded at.
+	if rev > 0 && (rev <= compactRev || rev < rangeCompactRev) {
 		s.mu.RUnlock()
 		return 0, 0, compactRev, ErrCompacted
 	} else if rev > 0 && rev > currentRev {
@@ -187,6 +248,16 @@ func (s *store) HashByRev(rev int64) (hash uint32, currentRev int64, compactRev
 		rev = currentRev
 	}
 	keep := s.kvindex.Keep(rev)
+	// the revisions removed by the range compactions are skipped whether
+	// they are deleted yet or not.
+	rangeKeeps := make([]map[revision]struct{}, len(rcs))
+	rangeUpper := int64(0)
+	for i, c := range rcs {
+		rangeKeeps[i] = s.kvindex.KeepRange(c.key, c.end, c.rev)
+		if c.rev > rangeUpper {
+			rangeUpper = c.rev
+		}
+	}
 
 	tx := s.b.ReadTx()
 	tx.RLock()
@@ -210,8 +281,25 @@ func (s *store) HashByRev(rev int64) (hash uint32, currentRev int64, compactRev
 				return nil
 			}
 		}
+		// hash the revisions uncompressed so that the members agree
+		// whatever their value compression.
+		d, derr := decompressRevision(v)
+		if derr != nil {
+			return derr
+		}
+		if kr.main <= rangeUpper {
+			var kv mvccpb.KeyValue
+			if err := kv.Unmarshal(d); err != nil {
+				return err
+			}
+			for i, c := range rcs {
+				if _, ok := rangeKeeps[i][kr]; !ok && kr.main <= c.rev && c.covers(kv.Key) {
+					return nil
+				}
+			}
+		}
 		h.Write(k)
-		h.Write(v)
+		h.Write(d)
 		return nil
 	})
 	hash = h.Sum32()
@@ -235,6 +323,15 @@ func (s *store) updateCompactRev(rev int64) (<-chan struct{}, error) {
 	}
 
 	s.compactMainRev = rev
+	s.historyRev = nextHistoryRev(s.historyRev, rev, s.cfg.HistoryRetention)
+	// the range compactions up to rev are superseded
+	rcs := s.rangeCompactions[:0:0]
+	for _, c := range s.rangeCompactions {
+		if c.rev > rev {
+			rcs = append(rcs, c)
+		}
+	}
+	s.rangeCompactions = rcs
 
 	SetScheduledCompact(s.b.BatchTx(), rev)
 	// ensure that desired compaction is persisted
@@ -290,6 +387,124 @@ func (s *store) Compact(trace *traceutil.Trace, rev int64) (<-chan struct{}, err
 	return s.compact(trace, rev)
 }
 
+// rangeCompaction compacts the keys in [key, end) at rev. It is recorded at
+// a revision of its own, recordRev, so that the members agree on the
+// revisions removed up to each revision. An empty end is the end of the
+// keyspace.
+type rangeCompaction struct {
+	key, end  []byte
+	rev       int64
+	recordRev int64
+}
+
+func (c rangeCompaction) covers(key []byte) bool {
+	return bytes.Compare(key, c.key) >= 0 && (len(c.end) == 0 || bytes.Compare(key, c.end) < 0)
+}
+
+// intersects returns whether c compacts a key of [key, end), the single
+// key if end is nil and the end of the keyspace if end is empty.
+func (c rangeCompaction) intersects(key, end []byte) bool {
+	if end == nil {
+		return c.covers(key)
+	}
+	return (len(end) == 0 || bytes.Compare(c.key, end) < 0) && (len(c.end) == 0 || bytes.Compare(key, c.end) < 0)
+}
+
+// compactRevOf returns the revision [key, end) is compacted at, the greatest
+// of the compaction and of the range compactions of its keys. It must be
+// called holding either mu or revMu.
+func (s *store) compactRevOf(key, end []byte) int64 {
+	rev := s.compactMainRev
+	for _, c := range s.rangeCompactions {
+		if c.rev > rev && c.intersects(key, end) {
+			rev = c.rev
+		}
+	}
+	return rev
+}
+
+// watchCompactRevOf returns the revision the watchers of [key, end) are
+// compacted at, before the history if the history covers the compaction of
+// the keys. It must be called holding either mu or revMu.
+func (s *store) watchCompactRevOf(key, end []byte) int64 {
+	rev := s.compactRevOf(key, end)
+	if rev == s.compactMainRev && s.historyRev <= rev {
+		return s.historyRev
+	}
+	return rev
+}
+
+func (s *store) CompactRange(trace *traceutil.Trace, key, end []byte, rev int64) (<-chan struct{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revMu.Lock()
+	defer s.revMu.Unlock()
+
+	prev := -1
+	for i, c := range s.rangeCompactions {
+		if bytes.Equal(c.key, key) && bytes.Equal(c.end, end) {
+			prev = i
+		}
+	}
+	if rev <= s.compactMainRev || (prev != -1 && rev <= s.rangeCompactions[prev].rev) {
+		ch := make(chan struct{})
+		f := func(ctx context.Context) { s.compactBarrier(ctx, ch) }
+		s.fifoSched.Schedule(f)
+		return ch, ErrCompacted
+	}
+	if rev > s.currentRev {
+		return nil, ErrFutureRev
+	}
+
+	c := rangeCompaction{key: key, end: end, rev: rev, recordRev: s.currentRev + 1}
+	tx := s.b.BatchTx()
+	tx.LockInsideApply()
+	unsafePutRangeCompaction(tx, c)
+	rcs := append([]rangeCompaction{}, s.rangeCompactions...)
+	if prev != -1 {
+		unsafeDeleteRangeCompaction(tx, rcs[prev])
+		rcs = append(rcs[:prev], rcs[prev+1:]...)
+	}
+	tx.Unlock()
+	// ensure that desired compaction is persisted
+	s.b.ForceCommit()
+	trace.Step("record range compaction")
+
+	s.rangeCompactions = append(rcs, c)
+	s.rangeCompactRev = c.recordRev
+	s.currentRev = c.recordRev
+
+	return s.compactRange(trace, c), nil
+}
+
+func (s *store) compactRange(trace *traceutil.Trace, c rangeCompaction) <-chan struct{} {
+	ch := make(chan struct{})
+	var j = func(ctx context.Context) {
+		if ctx.Err() != nil {
+			s.compactBarrier(ctx, ch)
+			return
+		}
+		start := time.Now()
+		removed := s.kvindex.CompactRange(c.key, c.end, c.rev)
+		indexCompactionPauseMs.Observe(float64(time.Since(start) / time.Millisecond))
+		if !s.scheduleRangeCompaction(c, removed) {
+			s.compactBarrier(context.TODO(), ch)
+			return
+		}
+		close(ch)
+	}
+
+	s.fifoSched.Schedule(j)
+	trace.Step("schedule range compaction")
+	return ch
+}
+
+func (s *store) ExpiredKeys(t int64, limit int) [][]byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.expiry.expired(t, limit)
+}
+
 func (s *store) Commit() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -305,12 +520,16 @@ func (s *store) Restore(b backend.Backend) error {
 
 	s.b = b
 	s.kvindex = newTreeIndex(s.lg)
+	s.expiry = newExpiryIndex()
+	s.cache = newReadCache(s.cfg.ReadCacheSize)
 
 	{
 		// During restore the metrics might report 'special' values
 		s.revMu.Lock()
 		s.currentRev = 1
 		s.compactMainRev = -1
+		s.historyRev = 0
+		s.rangeCompactions, s.rangeCompactRev = nil, 0
 		s.revMu.Unlock()
 	}
 
@@ -328,6 +547,7 @@ func (s *store) restore() error {
 	revToBytes(revision{main: math.MaxInt64, sub: math.MaxInt64}, max)
 
 	keyToLease := make(map[string]lease.LeaseID)
+	keyToExpiry := make(map[string]int64)
 
 	// restore index
 	tx := s.b.ReadTx()
@@ -337,6 +557,12 @@ func (s *store) restore() error {
 	if found {
 		s.revMu.Lock()
 		s.compactMainRev = finishedCompact
+		s.historyRev = finishedCompact + 1
+		if s.cfg.HistoryRetention > 0 {
+			if start, ok := unsafeReadHistoryStart(tx); ok && start <= finishedCompact {
+				s.historyRev = start
+			}
+		}
 
 		s.lg.Info(
 			"restored last compact revision",
@@ -346,6 +572,19 @@ func (s *store) restore() error {
 		s.revMu.Unlock()
 	}
 	scheduledCompact, _ := UnsafeReadScheduledCompact(tx)
+	tombstones, err := unsafeReadRangeTombstones(tx)
+	if err != nil {
+		tx.Unlock()
+		return err
+	}
+	for _, t := range tombstones {
+		s.kvindex.DeleteRange(t.key, t.end, t.rev)
+	}
+	rcs, err := unsafeReadRangeCompactions(tx)
+	if err != nil {
+		tx.Unlock()
+		return err
+	}
 	// index keys concurrently as they're loaded in from tx
 	keysGauge.Set(0)
 	rkvc, revc := restoreIntoIndex(s.lg, s.kvindex)
@@ -356,7 +595,7 @@ func (s *store) restore() error {
 		}
 		// rkvc blocks if the total pending keys exceeds the restore
 		// chunk size to keep keys from consuming too much memory.
-		restoreChunk(s.lg, rkvc, keys, vals, keyToLease)
+		restoreChunk(s.lg, rkvc, keys, vals, keyToLease, keyToExpiry)
 		if len(keys) < restoreChunkKeys {
 			// partial set implies final set
 			break
@@ -371,6 +610,10 @@ func (s *store) restore() error {
 	{
 		s.revMu.Lock()
 		s.currentRev = <-revc
+		// the last range tombstone might be the last write
+		if n := len(tombstones); n != 0 && s.currentRev < tombstones[n-1].rev.main {
+			s.currentRev = tombstones[n-1].rev.main
+		}
 
 		// keys in the range [compacted revision -N, compaction] might all be deleted due to compaction.
 		// the correct revision should be set to compaction revision in the case, not the largest revision
@@ -378,6 +621,16 @@ func (s *store) restore() error {
 		if s.currentRev < s.compactMainRev {
 			s.currentRev = s.compactMainRev
 		}
+		// as the last range compaction might be the last revision
+		for _, c := range rcs {
+			if c.rev > s.compactMainRev {
+				s.rangeCompactions = append(s.rangeCompactions, c)
+			}
+			s.rangeCompactRev = c.recordRev
+		}
+		if s.currentRev < s.rangeCompactRev {
+			s.currentRev = s.rangeCompactRev
+		}
 		s.revMu.Unlock()
 	}
 
@@ -386,6 +639,10 @@ func (s *store) restore() error {
 	}
 
 	for key, lid := range keyToLease {
+		if _, _, _, err := s.kvindex.Get([]byte(key), s.currentRev); err != nil {
+			// deleted by a range tombstone
+			continue
+		}
 		if s.le == nil {
 			tx.Unlock()
 			panic("no lessor to attach lease")
@@ -400,10 +657,22 @@ func (s *store) restore() error {
 		}
 	}
 
+	for key, t := range keyToExpiry {
+		if _, _, _, err := s.kvindex.Get([]byte(key), s.currentRev); err == nil {
+			s.expiry.set([]byte(key), t)
+		}
+	}
+
 	tx.Unlock()
 
 	s.lg.Info("kvstore restored", zap.Int64("current-rev", s.currentRev))
 
+	// the range compactions are resumed whether they were done or not, their
+	// removed revisions being deleted again if so.
+	for _, c := range s.rangeCompactions {
+		s.compactRange(traceutil.TODO(), c)
+	}
+
 	if scheduledCompact != 0 {
 		if _, err := s.compactLockfree(scheduledCompact); err != nil {
 			s.lg.Warn("compaction encountered error", zap.Error(err))
@@ -454,6 +723,7 @@ func restoreIntoIndex(lg *zap.Logger, idx index) (chan<- revKeyValue, <-chan int
 			rev := bytesToRev(rkv.key)
 			currentRev = rev.main
 			if ok {
+				idx.Materialize(ki, rev)
 				if isTombstone(rkv.key) {
 					if err := ki.tombstone(lg, rev.main, rev.sub); err != nil {
 						lg.Warn("tombstone encountered error", zap.Error(err))
@@ -471,10 +741,10 @@ func restoreIntoIndex(lg *zap.Logger, idx index) (chan<- revKeyValue, <-chan int
 	return rkvc, revc
 }
 
-func restoreChunk(lg *zap.Logger, kvc chan<- revKeyValue, keys, vals [][]byte, keyToLease map[string]lease.LeaseID) {
+func restoreChunk(lg *zap.Logger, kvc chan<- revKeyValue, keys, vals [][]byte, keyToLease map[string]lease.LeaseID, keyToExpiry map[string]int64) {
 	for i, key := range keys {
 		rkv := revKeyValue{key: key}
-		if err := rkv.kv.Unmarshal(vals[i]); err != nil {
+		if err := UnmarshalKeyValue(vals[i], &rkv.kv); err != nil {
 			lg.Fatal("failed to unmarshal mvccpb.KeyValue", zap.Error(err))
 		}
 		rkv.kstr = string(rkv.kv.Key)
@@ -485,6 +755,11 @@ func restoreChunk(lg *zap.Logger, kvc chan<- revKeyValue, keys, vals [][]byte, k
 		} else {
 			delete(keyToLease, rkv.kstr)
 		}
+		if t := rkv.kv.ExpireTime; t != 0 && !isTombstone(key) {
+			keyToExpiry[rkv.kstr] = t
+		} else {
+			delete(keyToExpiry, rkv.kstr)
+		}
 		kvc <- rkv
 	}
 }
@@ -538,3 +813,31 @@ func appendMarkTombstone(lg *zap.Logger, b []byte) []byte {
 func isTombstone(b []byte) bool {
 	return len(b) == markedRevBytesLen && b[markBytePosition] == markTombstone
 }
+
+// VerifyKeyRecord verifies a record of the key bucket: its key must be a
+// revision, possibly marked as a tombstone, and its value the key-value
+// written at that revision. It is meant to be the backend.ScrubVerifier of
+// the key bucket.
+func VerifyKeyRecord(key, value []byte) error {
+	if len(key) != revBytesLen && !isTombstone(key) {
+		return fmt.Errorf("invalid revision key of %d bytes", len(key))
+	}
+	var kv mvccpb.KeyValue
+	if err := UnmarshalKeyValue(value, &kv); err != nil {
+		return fmt.Errorf("cannot unmarshal key-value: %w", err)
+	}
+	if len(kv.Key) == 0 {
+		return errors.New("empty key")
+	}
+	if isTombstone(key) {
+		return nil
+	}
+	rev := bytesToRev(key)
+	if kv.ModRevision != rev.main {
+		return fmt.Errorf("mod revision %d differs from the revision %d of the record", kv.ModRevision, rev.main)
+	}
+	if kv.CreateRevision <= 0 || kv.CreateRevision > kv.ModRevision || kv.Version <= 0 {
+		return fmt.Errorf("invalid create revision %d or version %d", kv.CreateRevision, kv.Version)
+	}
+	return nil
+}
@@ -16,6 +16,7 @@ package mvcc
 
 import (
 	"encoding/binary"
+	"sort"
 	"time"
 
 	"go.etcd.io/etcd/server/v3/storage/schema"
@@ -41,18 +42,32 @@ func (s *store) scheduleCompaction(compactMainRev int64, keep map[revision]struc
 
 		start := time.Now()
 
+		// the history may have been moved by a later compaction already
+		s.revMu.RLock()
+		historyRev := s.historyRev
+		s.revMu.RUnlock()
+
 		tx := s.b.BatchTx()
 		tx.LockOutsideApply()
-		keys, _ := tx.UnsafeRange(schema.Key, last, end, int64(batchNum))
-		for _, key := range keys {
+		keys, vals := tx.UnsafeRange(schema.Key, last, end, int64(batchNum))
+		for i, key := range keys {
 			rev = bytesToRev(key)
 			if _, ok := keep[rev]; !ok {
+				if s.cfg.HistoryRetention > 0 && rev.main >= historyRev {
+					unsafeSaveHistory(tx, key, vals[i])
+				}
 				tx.UnsafeDelete(schema.Key, key)
 				keyCompactions++
 			}
 		}
 
 		if len(keys) < batchNum {
+			if s.cfg.HistoryRetention > 0 {
+				unsafePruneHistory(tx, historyRev)
+			}
+			// the keys deleted by the range tombstones are tombstoned in the index
+			unsafeDeleteRangeTombstones(tx, compactMainRev)
+			unsafeDeleteRangeCompactions(tx, compactMainRev)
 			UnsafeSetFinishedCompact(tx, compactMainRev)
 			tx.Unlock()
 			s.lg.Info(
@@ -77,3 +92,63 @@ func (s *store) scheduleCompaction(compactMainRev int64, keep map[revision]struc
 		}
 	}
 }
+
+// scheduleRangeCompaction deletes the revisions removed from the index by
+// the range compaction c, in the order of revision.
+func (s *store) scheduleRangeCompaction(c rangeCompaction, removed map[revision]struct{}) bool {
+	totalStart := time.Now()
+	defer func() { dbCompactionTotalMs.Observe(float64(time.Since(totalStart) / time.Millisecond)) }()
+	keyCompactions := 0
+	defer func() { dbCompactionKeysCounter.Add(float64(keyCompactions)) }()
+
+	revs := make([]revision, 0, len(removed))
+	for rev := range removed {
+		revs = append(revs, rev)
+	}
+	sort.Slice(revs, func(i, j int) bool { return revs[j].GreaterThan(revs[i]) })
+
+	batchNum := s.cfg.CompactionBatchLimit
+	batchInterval := s.cfg.CompactionSleepInterval
+
+	for {
+		start := time.Now()
+
+		n := batchNum
+		if n > len(revs) {
+			n = len(revs)
+		}
+		tx := s.b.BatchTx()
+		tx.LockOutsideApply()
+		for _, rev := range revs[:n] {
+			rbytes := newRevBytes()
+			revToBytes(rev, rbytes)
+			// the revision is removed whether it is a tombstone or not
+			tx.UnsafeDelete(schema.Key, rbytes)
+			tx.UnsafeDelete(schema.Key, appendMarkTombstone(s.lg, rbytes))
+		}
+		tx.Unlock()
+		keyCompactions += n
+		revs = revs[n:]
+
+		if len(revs) == 0 {
+			s.lg.Info(
+				"finished scheduled range compaction",
+				zap.ByteString("key", c.key),
+				zap.ByteString("range-end", c.end),
+				zap.Int64("compact-revision", c.rev),
+				zap.Duration("took", time.Since(totalStart)),
+			)
+			return true
+		}
+
+		// Immediately commit the compaction deletes instead of letting them accumulate in the write buffer
+		s.b.ForceCommit()
+		dbCompactionPauseMs.Observe(float64(time.Since(start) / time.Millisecond))
+
+		select {
+		case <-time.After(batchInterval):
+		case <-s.stopc:
+			return false
+		}
+	}
+}
@@ -136,3 +136,81 @@ func TestCompactAllAndRestore(t *testing.T) {
 		t.Errorf("unexpect range error %v", err)
 	}
 }
+
+func TestCompactRangeAndRestore(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s0 := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+	defer os.Remove(tmpPath)
+
+	s0.Put([]byte("a"), []byte("1"), lease.NoLease)
+	s0.Put([]byte("a"), []byte("2"), lease.NoLease)
+	s0.Put([]byte("b"), []byte("1"), lease.NoLease)
+	s0.Put([]byte("b"), []byte("2"), lease.NoLease)
+
+	// compact the key "a" only
+	done, err := s0.CompactRange(traceutil.TODO(), []byte("a"), []byte("b"), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for range compaction to finish")
+	}
+	if rev := s0.Rev(); rev != 6 {
+		t.Errorf("rev = %d, want 6 as the range compaction is recorded at a revision of its own", rev)
+	}
+	if _, err = s0.CompactRange(traceutil.TODO(), []byte("a"), []byte("b"), 5); err != ErrCompacted {
+		t.Errorf("compacting the range again err = %v, want %v", err, ErrCompacted)
+	}
+	if _, _, _, err = s0.HashByRev(5); err != ErrCompacted {
+		t.Errorf("hash before the range compaction err = %v, want %v", err, ErrCompacted)
+	}
+	hash, _, _, err := s0.HashByRev(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s0.Commit()
+	tx := s0.b.ReadTx()
+	tx.RLock()
+	rbytes := newRevBytes()
+	revToBytes(revision{main: 2}, rbytes)
+	keys, _ := tx.UnsafeRange(schema.Key, rbytes, nil, 0)
+	tx.RUnlock()
+	if len(keys) != 0 {
+		t.Errorf("revision 2 of the compacted range is not deleted")
+	}
+
+	if err = s0.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s1 := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+	defer s1.Close()
+	if rev := s1.Rev(); rev != 6 {
+		t.Errorf("restored rev = %d, want 6", rev)
+	}
+	if _, err = s1.Range(context.TODO(), []byte("a"), nil, RangeOptions{Rev: 3}); err != ErrCompacted {
+		t.Errorf("range of the compacted key err = %v, want %v", err, ErrCompacted)
+	}
+	if _, err = s1.Range(context.TODO(), []byte("a"), []byte("c"), RangeOptions{Rev: 4}); err != ErrCompacted {
+		t.Errorf("range intersecting the compacted range err = %v, want %v", err, ErrCompacted)
+	}
+	r, err := s1.Range(context.TODO(), []byte("b"), nil, RangeOptions{Rev: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.KVs) != 1 || string(r.KVs[0].Value) != "1" {
+		t.Errorf("range of the key out of the compacted range = %+v, want b=1", r.KVs)
+	}
+	r, err = s1.Range(context.TODO(), []byte("a"), nil, RangeOptions{Rev: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.KVs) != 1 || string(r.KVs[0].Value) != "2" {
+		t.Errorf("range of the compacted key = %+v, want a=2", r.KVs)
+	}
+	if h, _, _, err := s1.HashByRev(0); err != nil || h != hash {
+		t.Errorf("restored hash = %d (%v), want %d", h, err, hash)
+	}
+}
@@ -533,12 +533,152 @@ func TestRestoreContinueUnfinishedCompaction(t *testing.T) {
 	}
 }
 
+// TestStoreRangeTombstone ensures that a range deletion over the threshold
+// hides the keys until the compaction tombstones them, across restores.
+func TestStoreRangeTombstone(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer b.Close()
+	cfg := StoreConfig{RangeTombstoneThreshold: 2}
+	s0 := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, cfg)
+
+	s0.Put([]byte("foo1"), []byte("bar"), lease.NoLease)
+	s0.Put([]byte("foo2"), []byte("bar"), lease.NoLease)
+	s0.Put([]byte("zoo"), []byte("bar"), lease.NoLease)
+	if n, rev := s0.DeleteRange([]byte("foo"), []byte("fop")); n != 2 || rev != 5 {
+		t.Fatalf("n, rev = %d, %d, want 2, 5", n, rev)
+	}
+	s0.Close()
+
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, cfg)
+	defer s.Close()
+	if s.Rev() != 5 {
+		t.Fatalf("rev = %d, want 5", s.Rev())
+	}
+	r, err := s.Range(context.TODO(), []byte("foo"), []byte("fop"), RangeOptions{})
+	if err != nil || r.Count != 0 {
+		t.Fatalf("range = %+v, %v, want no keys", r, err)
+	}
+	if r, _ = s.Range(context.TODO(), []byte("foo"), []byte("fop"), RangeOptions{Rev: 4}); r.Count != 2 {
+		t.Fatalf("range at rev 4 count = %d, want 2", r.Count)
+	}
+
+	// put again, the key starts a new generation
+	s.Put([]byte("foo2"), []byte("bar2"), lease.NoLease)
+	r, _ = s.Range(context.TODO(), []byte("foo"), []byte("fop"), RangeOptions{})
+	if r.Count != 1 || r.KVs[0].Version != 1 || r.KVs[0].CreateRevision != 6 {
+		t.Fatalf("range = %+v, want foo2 created at 6", r.KVs)
+	}
+
+	done, err := s.Compact(traceutil.TODO(), 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if ts := s.kvindex.RangeTombstones(); len(ts) != 0 {
+		t.Errorf("range tombstones = %v, want none", ts)
+	}
+	tx := s.b.BatchTx()
+	tx.Lock()
+	ts, err := unsafeReadRangeTombstones(tx)
+	ks, _ := tx.UnsafeRange(schema.Key, newTestRevBytes(revision{main: 2}), newTestRevBytes(revision{main: 4}), 0)
+	tx.Unlock()
+	if err != nil || len(ts) != 0 {
+		t.Errorf("stored range tombstones = %v, %v, want none", ts, err)
+	}
+	if len(ks) != 0 {
+		t.Errorf("%d revisions of the deleted keys are left, want none", len(ks))
+	}
+}
+
+func TestStoreStats(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer b.Close()
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+	defer s.Close()
+
+	s.Put([]byte("foo1"), []byte("bar"), lease.NoLease)
+	s.Put([]byte("foo2"), []byte("barbar"), lease.NoLease)
+	s.Put([]byte("foo2"), []byte("b"), lease.NoLease)
+	s.Put([]byte("zoo"), []byte("bar"), lease.NoLease)
+	s.DeleteRange([]byte("zoo"), nil)
+
+	tests := []struct {
+		key, end []byte
+
+		wr StatsResult
+	}{
+		{[]byte("foo"), []byte("fop"), StatsResult{Rev: 6, Count: 2, KeyBytes: 8, ValueBytes: 4}},
+		{[]byte("foo1"), []byte("foo2"), StatsResult{Rev: 6, Count: 1, KeyBytes: 4, ValueBytes: 3}},
+		{[]byte("zoo"), []byte{}, StatsResult{Rev: 6}},
+	}
+	for i, tt := range tests {
+		r, err := s.Stats(context.TODO(), tt.key, tt.end)
+		if err != nil {
+			t.Fatalf("#%d: stats error (%v)", i, err)
+		}
+		if *r != tt.wr {
+			t.Errorf("#%d: stats = %+v, want %+v", i, *r, tt.wr)
+		}
+	}
+}
+
 type hashKVResult struct {
 	hash       uint32
 	compactRev int64
 }
 
 // TestHashKVWhenCompacting ensures that HashKV returns correct hash when compacting.
+func TestVerifyKeyRecord(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer b.Close()
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+	defer s.Close()
+
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	s.Put([]byte("foo"), []byte("baz"), lease.NoLease)
+	s.DeleteRange([]byte("foo"), nil)
+	s.Commit()
+
+	n := 0
+	tx := b.ReadTx()
+	tx.RLock()
+	tx.UnsafeForEach(schema.Key, func(k, v []byte) error {
+		n++
+		if err := VerifyKeyRecord(k, v); err != nil {
+			t.Errorf("record %x: unexpected error %v", k, err)
+		}
+		return nil
+	})
+	tx.RUnlock()
+	if n != 3 {
+		t.Fatalf("verified %d records, want 3", n)
+	}
+
+	kv := mvccpb.KeyValue{Key: []byte("foo"), Value: []byte("bar"), CreateRevision: 2, ModRevision: 2, Version: 1}
+	v, err := kv.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := newRevBytes()
+	revToBytes(revision{main: 2}, key)
+	otherKey := newRevBytes()
+	revToBytes(revision{main: 3}, otherKey)
+	tests := []struct {
+		name       string
+		key, value []byte
+	}{
+		{"truncated key", key[:8], v},
+		{"invalid mark", append(append([]byte{}, key...), 'x'), v},
+		{"undecodable value", key, []byte("garbage")},
+		{"mismatching revision", otherKey, v},
+	}
+	for _, tt := range tests {
+		if err := VerifyKeyRecord(tt.key, tt.value); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
 func TestHashKVWhenCompacting(t *testing.T) {
 	b, tmpPath := betesting.NewDefaultTmpBackend(t)
 	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
@@ -861,6 +1001,7 @@ func newFakeStore(lg *zap.Logger) *store {
 		b:              b,
 		le:             &lease.FakeLessor{},
 		kvindex:        fi,
+		expiry:         newExpiryIndex(),
 		currentRev:     0,
 		compactMainRev: -1,
 		fifoSched:      schedule.NewFIFOScheduler(),
@@ -923,8 +1064,15 @@ func (b *fakeBackend) OpenReadTxN() int64
 func (b *fakeBackend) Snapshot() backend.Snapshot                                 { return nil }
 func (b *fakeBackend) ForceCommit()                                               {}
 func (b *fakeBackend) Defrag() error                                              { return nil }
+func (b *fakeBackend) DefragStatus() backend.DefragStatus                         { return backend.DefragStatus{} }
 func (b *fakeBackend) Close() error                                               { return nil }
 func (b *fakeBackend) SetTxPostLockInsideApplyHook(func())                        {}
+func (b *fakeBackend) BatchLimits() (time.Duration, int)                          { return 0, 0 }
+func (b *fakeBackend) SetBatchLimits(time.Duration, int)                          {}
+func (b *fakeBackend) UnsafeSetCommitTraceID(string)                              {}
+func (b *fakeBackend) Scrub(backend.ScrubPosition, int, backend.ScrubVerifier) (backend.ScrubPosition, bool, error) {
+	return backend.ScrubPosition{}, true, nil
+}
 
 type indexGetResp struct {
 	rev     revision
@@ -993,8 +1141,26 @@ func (i *fakeIndex) Keep(rev int64) map[revision]struct{} {
 	i.Recorder.Record(testutil.Action{Name: "keep", Params: []interface{}{rev}})
 	return <-i.indexCompactRespc
 }
+func (i *fakeIndex) CompactRange(key, end []byte, rev int64) map[revision]struct{} {
+	i.Recorder.Record(testutil.Action{Name: "compactRange", Params: []interface{}{key, end, rev}})
+	return nil
+}
+func (i *fakeIndex) KeepRange(key, end []byte, rev int64) map[revision]struct{} {
+	i.Recorder.Record(testutil.Action{Name: "keepRange", Params: []interface{}{key, end, rev}})
+	return nil
+}
 func (i *fakeIndex) Equal(b index) bool { return false }
 
+func (i *fakeIndex) DeleteRange(key, end []byte, rev revision) {
+	i.Recorder.Record(testutil.Action{Name: "deleteRange", Params: []interface{}{key, end, rev}})
+}
+
+func (i *fakeIndex) RangeTombstones() []rangeTombstone { return nil }
+
+func (i *fakeIndex) RangeDeleted(t rangeTombstone) [][]byte { return nil }
+
+func (i *fakeIndex) Materialize(ki *keyIndex, rev revision) {}
+
 func (i *fakeIndex) Insert(ki *keyIndex) {
 	i.Recorder.Record(testutil.Action{Name: "insert", Params: []interface{}{ki}})
 }
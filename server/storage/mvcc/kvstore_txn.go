@@ -31,11 +31,17 @@ type storeTxnRead struct {
 
 	firstRev int64
 	rev      int64
+	// cacheGen is the generation of the read cache when the txn opened.
+	cacheGen int64
 
 	trace *traceutil.Trace
 }
 
 func (s *store) Read(mode ReadTxMode, trace *traceutil.Trace) TxnRead {
+	return newMetricsTxnRead(s.read(mode, trace))
+}
+
+func (s *store) read(mode ReadTxMode, trace *traceutil.Trace) *storeTxnRead {
 	s.mu.RLock()
 	s.revMu.RLock()
 	// For read-only workloads, we use shared buffer by copying transaction read buffer
@@ -51,8 +57,9 @@ func (s *store) Read(mode ReadTxMode, trace *traceutil.Trace) TxnRead {
 
 	tx.RLock() // RLock is no-op. concurrentReadTx does not need to be locked after it is created.
 	firstRev, rev := s.compactMainRev, s.currentRev
+	cacheGen := s.cache.generation()
 	s.revMu.RUnlock()
-	return newMetricsTxnRead(&storeTxnRead{s, tx, firstRev, rev, trace})
+	return &storeTxnRead{s, tx, firstRev, rev, cacheGen, trace}
 }
 
 func (tr *storeTxnRead) FirstRev() int64 { return tr.firstRev }
@@ -70,7 +77,7 @@ func (tr *storeTxnRead) rangeKeys(ctx context.Context, key, end []byte, curRev i
 	if rev <= 0 {
 		rev = curRev
 	}
-	if rev < tr.s.compactMainRev {
+	if rev < tr.s.compactRevOf(key, end) {
 		return &RangeResult{KVs: nil, Count: -1, Rev: 0}, ErrCompacted
 	}
 	if ro.Count {
@@ -97,6 +104,10 @@ func (tr *storeTxnRead) rangeKeys(ctx context.Context, key, end []byte, curRev i
 			return nil, ctx.Err()
 		default:
 		}
+		if kv, ok := tr.s.cache.get(revpair); ok {
+			kvs[i] = kv
+			continue
+		}
 		revToBytes(revpair, revBytes)
 		_, vs := tr.tx.UnsafeRange(schema.Key, revBytes, nil, 0)
 		if len(vs) != 1 {
@@ -106,17 +117,51 @@ func (tr *storeTxnRead) rangeKeys(ctx context.Context, key, end []byte, curRev i
 				zap.Int64("revision-sub", revpair.sub),
 			)
 		}
-		if err := kvs[i].Unmarshal(vs[0]); err != nil {
+		if err := UnmarshalKeyValue(vs[0], &kvs[i]); err != nil {
 			tr.s.lg.Fatal(
 				"failed to unmarshal mvccpb.KeyValue",
 				zap.Error(err),
 			)
 		}
+		tr.s.cache.add(tr.cacheGen, revpair, kvs[i])
 	}
 	tr.trace.Step("range keys from bolt db")
 	return &RangeResult{KVs: kvs, Count: total, Rev: curRev}, nil
 }
 
+func (s *store) Stats(ctx context.Context, key, end []byte) (*StatsResult, error) {
+	tr := s.read(ConcurrentReadTxMode, traceutil.TODO())
+	defer tr.End()
+
+	r := &StatsResult{Rev: tr.Rev()}
+	_, revs := s.kvindex.Range(key, end, r.Rev)
+	var kv mvccpb.KeyValue
+	revBytes := newRevBytes()
+	for _, rev := range revs {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		revToBytes(rev, revBytes)
+		_, vs := tr.tx.UnsafeRange(schema.Key, revBytes, nil, 0)
+		if len(vs) != 1 {
+			s.lg.Fatal(
+				"range failed to find revision pair",
+				zap.Int64("revision-main", rev.main),
+				zap.Int64("revision-sub", rev.sub),
+			)
+		}
+		if err := UnmarshalKeyValue(vs[0], &kv); err != nil {
+			s.lg.Fatal("failed to unmarshal mvccpb.KeyValue", zap.Error(err))
+		}
+		r.Count++
+		r.KeyBytes += int64(len(kv.Key))
+		r.ValueBytes += int64(len(kv.Value))
+	}
+	return r, nil
+}
+
 func (tr *storeTxnRead) End() {
 	tr.tx.RUnlock() // RUnlock signals the end of concurrentReadTx.
 	tr.s.mu.RUnlock()
@@ -128,6 +173,11 @@ type storeTxnWrite struct {
 	// beginRev is the revision where the txn begins; it will write to the next revision.
 	beginRev int64
 	changes  []mvccpb.KeyValue
+	// rangeTombstones are the range tombstones recorded by the txn.
+	rangeTombstones []rangeTombstone
+	// leaseSets are the key-values whose lease the txn changed in place, by
+	// revision.
+	leaseSets map[revision]mvccpb.KeyValue
 }
 
 func (s *store) Write(trace *traceutil.Trace) TxnWrite {
@@ -135,7 +185,7 @@ func (s *store) Write(trace *traceutil.Trace) TxnWrite {
 	tx := s.b.BatchTx()
 	tx.LockInsideApply()
 	tw := &storeTxnWrite{
-		storeTxnRead: storeTxnRead{s, tx, 0, 0, trace},
+		storeTxnRead: storeTxnRead{s, tx, 0, 0, s.cache.generation(), trace},
 		tx:           tx,
 		beginRev:     s.currentRev,
 		changes:      make([]mvccpb.KeyValue, 0, 4),
@@ -147,39 +197,160 @@ func (tw *storeTxnWrite) Rev() int64 { return tw.beginRev }
 
 func (tw *storeTxnWrite) Range(ctx context.Context, key, end []byte, ro RangeOptions) (r *RangeResult, err error) {
 	rev := tw.beginRev
-	if len(tw.changes) > 0 {
+	if tw.modified() {
 		rev++
 	}
 	return tw.rangeKeys(ctx, key, end, rev, ro)
 }
 
 func (tw *storeTxnWrite) DeleteRange(key, end []byte) (int64, int64) {
-	if n := tw.deleteRange(key, end); n != 0 || len(tw.changes) > 0 {
+	if n := tw.deleteRange(key, end); n != 0 || tw.modified() {
 		return n, tw.beginRev + 1
 	}
 	return 0, tw.beginRev
 }
 
 func (tw *storeTxnWrite) Put(key, value []byte, lease lease.LeaseID) int64 {
-	tw.put(key, value, lease)
+	tw.put(key, value, lease, 0)
 	return tw.beginRev + 1
 }
 
+func (tw *storeTxnWrite) PutExpiring(key, value []byte, lease lease.LeaseID, expireTime int64) int64 {
+	tw.put(key, value, lease, expireTime)
+	return tw.beginRev + 1
+}
+
+func (tw *storeTxnWrite) SetLease(key []byte, leaseID lease.LeaseID) bool {
+	rev := tw.beginRev
+	if tw.modified() {
+		rev++
+	}
+	modified, _, _, err := tw.s.kvindex.Get(key, rev)
+	if err != nil {
+		return false
+	}
+	kv, ok := tw.leaseSets[modified]
+	if !ok {
+		ibytes := newRevBytes()
+		revToBytes(modified, ibytes)
+		_, vs := tw.tx.UnsafeRange(schema.Key, ibytes, nil, 0)
+		if len(vs) != 1 {
+			tw.s.lg.Fatal(
+				"range failed to find revision pair",
+				zap.Int64("revision-main", modified.main),
+				zap.Int64("revision-sub", modified.sub),
+			)
+		}
+		if err = UnmarshalKeyValue(vs[0], &kv); err != nil {
+			tw.s.lg.Fatal("failed to unmarshal mvccpb.KeyValue", zap.Error(err))
+		}
+	}
+	oldLease := lease.LeaseID(kv.Lease)
+	if oldLease == leaseID {
+		return true
+	}
+	if modified.main > tw.beginRev {
+		// put by the txn, the key is already at the revision the txn writes
+		tw.put(key, kv.Value, leaseID, kv.ExpireTime)
+		return true
+	}
+
+	// the revision is written once the txn ends, whatever the number of
+	// changes of its lease
+	kv.Lease = int64(leaseID)
+	if tw.leaseSets == nil {
+		tw.leaseSets = make(map[revision]mvccpb.KeyValue)
+	}
+	tw.leaseSets[modified] = kv
+	tw.trace.Step("set lease of kv pair in place")
+
+	if oldLease != lease.NoLease {
+		if tw.s.le == nil {
+			panic("no lessor to detach lease")
+		}
+		if err = tw.s.le.Detach(oldLease, []lease.LeaseItem{{Key: string(key)}}); err != nil {
+			tw.s.lg.Error("failed to detach old lease from a key", zap.Error(err))
+		}
+	}
+	if leaseID != lease.NoLease {
+		if tw.s.le == nil {
+			panic("no lessor to attach lease")
+		}
+		if err = tw.s.le.Attach(leaseID, []lease.LeaseItem{{Key: string(key)}}); err != nil {
+			panic("unexpected error from lease Attach")
+		}
+	}
+	return true
+}
+
+// writeLeaseSets writes the revisions whose lease the txn changed in place.
+func (tw *storeTxnWrite) writeLeaseSets() []revision {
+	revs := make([]revision, 0, len(tw.leaseSets))
+	for rev, kv := range tw.leaseSets {
+		kv := kv
+		d, err := kv.Marshal()
+		if err != nil {
+			tw.s.lg.Fatal("failed to marshal mvccpb.KeyValue", zap.Error(err))
+		}
+		d = tw.s.compressor.compress(&kv, d)
+		ibytes := newRevBytes()
+		revToBytes(rev, ibytes)
+		tw.tx.UnsafePut(schema.Key, ibytes, d)
+		revs = append(revs, rev)
+	}
+	return revs
+}
+
 func (tw *storeTxnWrite) End() {
+	var leaseSets []revision
+	if len(tw.leaseSets) != 0 {
+		leaseSets = tw.writeLeaseSets()
+	}
 	// only update index if the txn modifies the mvcc state.
-	if len(tw.changes) != 0 {
+	if tw.modified() {
+		for _, t := range tw.rangeTombstones {
+			tw.s.cache.invalidateRange(t.key, t.end)
+		}
+		for _, kv := range tw.changes {
+			tw.s.cache.invalidate(kv.Key)
+		}
+		if tw.s.cfg.OnDeleteRange != nil {
+			for _, t := range tw.rangeTombstones {
+				tw.s.cfg.OnDeleteRange(t.key, t.end)
+			}
+		}
+		if tw.s.cfg.OnWrite != nil && len(tw.changes) != 0 {
+			tw.s.cfg.OnWrite(tw.changes)
+		}
 		// hold revMu lock to prevent new read txns from opening until writeback.
 		tw.s.revMu.Lock()
 		tw.s.currentRev++
+	} else if len(tw.leaseSets) != 0 {
+		tw.s.revMu.Lock()
 	}
 	tw.tx.Unlock()
-	if len(tw.changes) != 0 {
+	if len(leaseSets) != 0 {
+		// evict the revisions changed in place once written back, the read
+		// txns opened before not caching them anymore
+		tw.s.cache.invalidateRevisions(leaseSets)
+	}
+	if tw.modified() || len(tw.leaseSets) != 0 {
 		tw.s.revMu.Unlock()
 	}
 	tw.s.mu.RUnlock()
 }
 
-func (tw *storeTxnWrite) put(key, value []byte, leaseID lease.LeaseID) {
+// modified returns whether the txn modifies the mvcc state.
+func (tw *storeTxnWrite) modified() bool {
+	return len(tw.changes) != 0 || len(tw.rangeTombstones) != 0
+}
+
+// sub returns the sub revision of the next write of the txn.
+func (tw *storeTxnWrite) sub() int64 {
+	return int64(len(tw.changes) + len(tw.rangeTombstones))
+}
+
+func (tw *storeTxnWrite) put(key, value []byte, leaseID lease.LeaseID, expireTime int64) {
 	rev := tw.beginRev + 1
 	c := rev
 	oldLease := lease.NoLease
@@ -189,11 +360,14 @@ func (tw *storeTxnWrite) put(key, value []byte, leaseID lease.LeaseID) {
 	_, created, ver, err := tw.s.kvindex.Get(key, rev)
 	if err == nil {
 		c = created.main
+	}
+	// the keys deleted by a range tombstone keep their lease until put again
+	if tw.s.le != nil {
 		oldLease = tw.s.le.GetLease(lease.LeaseItem{Key: string(key)})
-		tw.trace.Step("get key's previous created_revision and leaseID")
 	}
+	tw.trace.Step("get key's previous created_revision and leaseID")
 	ibytes := newRevBytes()
-	idxRev := revision{main: rev, sub: int64(len(tw.changes))}
+	idxRev := revision{main: rev, sub: tw.sub()}
 	revToBytes(idxRev, ibytes)
 
 	ver = ver + 1
@@ -204,6 +378,7 @@ func (tw *storeTxnWrite) put(key, value []byte, leaseID lease.LeaseID) {
 		ModRevision:    rev,
 		Version:        ver,
 		Lease:          int64(leaseID),
+		ExpireTime:     expireTime,
 	}
 
 	d, err := kv.Marshal()
@@ -214,9 +389,12 @@ func (tw *storeTxnWrite) put(key, value []byte, leaseID lease.LeaseID) {
 		)
 	}
 
+	d = tw.s.compressor.compress(&kv, d)
+
 	tw.trace.Step("marshal mvccpb.KeyValue")
 	tw.tx.UnsafeSeqPut(schema.Key, ibytes, d)
 	tw.s.kvindex.Put(key, idxRev)
+	tw.s.expiry.set(key, expireTime)
 	tw.changes = append(tw.changes, kv)
 	tw.trace.Step("store kv pair into bolt db")
 
@@ -251,9 +429,15 @@ func (tw *storeTxnWrite) put(key, value []byte, leaseID lease.LeaseID) {
 
 func (tw *storeTxnWrite) deleteRange(key, end []byte) int64 {
 	rrev := tw.beginRev
-	if len(tw.changes) > 0 {
+	if tw.modified() {
 		rrev++
 	}
+	if threshold := tw.s.cfg.RangeTombstoneThreshold; threshold > 0 && end != nil {
+		if n := tw.s.kvindex.CountRevisions(key, end, rrev); n >= threshold {
+			tw.tombstoneRange(key, end)
+			return int64(n)
+		}
+	}
 	keys, _ := tw.s.kvindex.Range(key, end, rrev)
 	if len(keys) == 0 {
 		return 0
@@ -264,9 +448,20 @@ func (tw *storeTxnWrite) deleteRange(key, end []byte) int64 {
 	return int64(len(keys))
 }
 
+// tombstoneRange records a range tombstone deleting the keys in [key, end),
+// which is a single write whatever their number.
+func (tw *storeTxnWrite) tombstoneRange(key, end []byte) {
+	t := rangeTombstone{key: key, end: end, rev: revision{main: tw.beginRev + 1, sub: tw.sub()}}
+	unsafePutRangeTombstone(tw.tx, t)
+	tw.s.kvindex.DeleteRange(key, end, t.rev)
+	tw.s.expiry.removeRange(key, end)
+	tw.rangeTombstones = append(tw.rangeTombstones, t)
+	tw.trace.Step("record range tombstone")
+}
+
 func (tw *storeTxnWrite) delete(key []byte) {
 	ibytes := newRevBytes()
-	idxRev := revision{main: tw.beginRev + 1, sub: int64(len(tw.changes))}
+	idxRev := revision{main: tw.beginRev + 1, sub: tw.sub()}
 	revToBytes(idxRev, ibytes)
 
 	ibytes = appendMarkTombstone(tw.storeTxnRead.s.lg, ibytes)
@@ -290,6 +485,7 @@ func (tw *storeTxnWrite) delete(key []byte) {
 			zap.Error(err),
 		)
 	}
+	tw.s.expiry.remove(key)
 	tw.changes = append(tw.changes, kv)
 
 	item := lease.LeaseItem{Key: string(key)}
@@ -85,6 +85,15 @@ var (
 			Help:      "Total number of unsynced slow watchers.",
 		})
 
+	stuckWatchersCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "etcd_debugging",
+			Subsystem: "mvcc",
+			Name:      "stuck_watchers_total",
+			Help:      "Total number of stuck slow watchers, by the policy applied to them.",
+		},
+		[]string{"policy"})
+
 	totalEventsCounter = prometheus.NewCounter(
 		prometheus.CounterOpts{
 			Namespace: "etcd_debugging",
@@ -101,6 +110,33 @@ var (
 			Help:      "Total number of pending events to be sent.",
 		})
 
+	watchFanout = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "etcd_debugging",
+			Subsystem: "mvcc",
+			Name:      "watch_fanout",
+			Help:      "Bucketed histogram of the number of synced watchers notified of a revision.",
+
+			// highest bucket start of 2^13 == 8192 watchers
+			Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+		})
+
+	watchStreamPendingEventsDesc = prometheus.NewDesc(
+		"etcd_debugging_mvcc_watch_stream_pending_events_max",
+		"Largest number of events pending to be sent on a watch stream.",
+		nil, nil)
+	watchStreamPendingBytesDesc = prometheus.NewDesc(
+		"etcd_debugging_mvcc_watch_stream_pending_bytes_max",
+		"Largest size in bytes of the events pending to be sent on a watch stream.",
+		nil, nil)
+	watchStreamRevisionLagDesc = prometheus.NewDesc(
+		"etcd_debugging_mvcc_watch_stream_revision_lag_max",
+		"Largest number of revisions a watch stream is behind the current revision.",
+		nil, nil)
+	// set by mvcc initialization, and cleared when the store is closed
+	reportWatchStreamStatsMu    sync.RWMutex
+	reportWatchStreamStatsStore *watchableStore
+
 	indexCompactionPauseMs = prometheus.NewHistogram(
 		prometheus.HistogramOpts{
 			Namespace: "etcd_debugging",
@@ -265,6 +301,48 @@ var (
 			Name:      "total_put_size_in_bytes",
 			Help:      "The total size of put kv pairs seen by this member.",
 		})
+
+	valueCompressionUncompressedBytes = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "etcd",
+			Subsystem: "mvcc",
+			Name:      "value_compression_uncompressed_bytes_total",
+			Help:      "Total size in bytes of the compressed revisions before compression.",
+		})
+
+	valueCompressionCompressedBytes = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "etcd",
+			Subsystem: "mvcc",
+			Name:      "value_compression_compressed_bytes_total",
+			Help:      "Total size in bytes of the compressed revisions after compression.",
+		})
+
+	valueCompressionRatio = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "etcd",
+		Subsystem: "mvcc",
+		Name:      "value_compression_ratio",
+		Help:      "The distribution of the compression ratio of the compressed revisions.",
+
+		// lowest bucket start of upper bound 1 with factor 1.5
+		// highest bucket start of 1 * 1.5^11 == 86.5
+		Buckets: prometheus.ExponentialBuckets(1, 1.5, 12),
+	})
+
+	readCacheHits = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "etcd",
+			Subsystem: "mvcc",
+			Name:      "read_cache_hits_total",
+			Help:      "Total number of revisions read from the read cache.",
+		})
+	readCacheMisses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "etcd",
+			Subsystem: "mvcc",
+			Name:      "read_cache_misses_total",
+			Help:      "Total number of revisions read from the backend as they were not in the read cache.",
+		})
 )
 
 func init() {
@@ -276,8 +354,11 @@ func init() {
 	prometheus.MustRegister(watchStreamGauge)
 	prometheus.MustRegister(watcherGauge)
 	prometheus.MustRegister(slowWatcherGauge)
+	prometheus.MustRegister(stuckWatchersCounter)
 	prometheus.MustRegister(totalEventsCounter)
 	prometheus.MustRegister(pendingEventsGauge)
+	prometheus.MustRegister(watchFanout)
+	prometheus.MustRegister(watchStreamCollector{})
 	prometheus.MustRegister(indexCompactionPauseMs)
 	prometheus.MustRegister(dbCompactionPauseMs)
 	prometheus.MustRegister(dbCompactionTotalMs)
@@ -289,7 +370,12 @@ func init() {
 	prometheus.MustRegister(hashSec)
 	prometheus.MustRegister(hashRevSec)
 	prometheus.MustRegister(currentRev)
+	prometheus.MustRegister(valueCompressionUncompressedBytes)
+	prometheus.MustRegister(valueCompressionCompressedBytes)
+	prometheus.MustRegister(valueCompressionRatio)
 	prometheus.MustRegister(compactRev)
+	prometheus.MustRegister(readCacheHits)
+	prometheus.MustRegister(readCacheMisses)
 	prometheus.MustRegister(totalPutSizeGauge)
 }
 
@@ -300,3 +386,39 @@ func ReportEventReceived(n int) {
 	pendingEventsGauge.Sub(float64(n))
 	totalEventsCounter.Add(float64(n))
 }
+
+// watchStreamCollector reports the slowest watch streams. The streams are not
+// labeled individually to bound the cardinality of the metrics; the stats of
+// every stream are returned by Watchable.WatchStreamStats.
+type watchStreamCollector struct{}
+
+func (watchStreamCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- watchStreamPendingEventsDesc
+	ch <- watchStreamPendingBytesDesc
+	ch <- watchStreamRevisionLagDesc
+}
+
+func (watchStreamCollector) Collect(ch chan<- prometheus.Metric) {
+	reportWatchStreamStatsMu.RLock()
+	var stats []WatchStreamStats
+	if reportWatchStreamStatsStore != nil {
+		stats = reportWatchStreamStatsStore.WatchStreamStats()
+	}
+	reportWatchStreamStatsMu.RUnlock()
+
+	var max WatchStreamStats
+	for _, st := range stats {
+		if st.PendingEvents > max.PendingEvents {
+			max.PendingEvents = st.PendingEvents
+		}
+		if st.PendingBytes > max.PendingBytes {
+			max.PendingBytes = st.PendingBytes
+		}
+		if st.RevisionLag > max.RevisionLag {
+			max.RevisionLag = st.RevisionLag
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(watchStreamPendingEventsDesc, prometheus.GaugeValue, float64(max.PendingEvents))
+	ch <- prometheus.MustNewConstMetric(watchStreamPendingBytesDesc, prometheus.GaugeValue, float64(max.PendingBytes))
+	ch <- prometheus.MustNewConstMetric(watchStreamRevisionLagDesc, prometheus.GaugeValue, float64(max.RevisionLag))
+}
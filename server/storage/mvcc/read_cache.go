@@ -0,0 +1,144 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"sync"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+
+	"github.com/golang/groupcache/lru"
+)
+
+// readCache caches the last key-values decoded from the backend by the
+// reads, by revision. A revision is only changed in place by SetLease,
+// which evicts it and bumps the generation of the cache so that the reads
+// which may have decoded it before do not cache it again. Otherwise the
+// writes only evict the revision of each key they supersede, to make room
+// for the hot keys. The cached key-values must not be modified.
+type readCache struct {
+	mu  sync.Mutex
+	lru *lru.Cache
+	// revs is the cached revision of each key.
+	revs map[string]revision
+	// gen is the generation of the cache, bumped on each in place change.
+	gen int64
+}
+
+// newReadCache returns a cache of size key-values, nil if size is not
+// positive. A nil cache caches nothing.
+func newReadCache(size int) *readCache {
+	if size <= 0 {
+		return nil
+	}
+	c := &readCache{lru: lru.New(size), revs: make(map[string]revision)}
+	c.lru.OnEvicted = func(k lru.Key, v interface{}) {
+		key := string(v.(mvccpb.KeyValue).Key)
+		if c.revs[key] == k.(revision) {
+			delete(c.revs, key)
+		}
+	}
+	return c
+}
+
+func (c *readCache) get(rev revision) (mvccpb.KeyValue, bool) {
+	if c == nil {
+		return mvccpb.KeyValue{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.lru.Get(rev)
+	if !ok {
+		readCacheMisses.Inc()
+		return mvccpb.KeyValue{}, false
+	}
+	readCacheHits.Inc()
+	return v.(mvccpb.KeyValue), true
+}
+
+// generation returns the generation of the cache, to add to it the
+// key-values read from then.
+func (c *readCache) generation() int64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// add caches kv read at rev by a read started at the generation gen, in
+// place of the revision of its key cached unless it is a later one.
+func (c *readCache) add(gen int64, rev revision, kv mvccpb.KeyValue) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen {
+		return
+	}
+	key := string(kv.Key)
+	if old, ok := c.revs[key]; ok && old != rev {
+		if old.GreaterThan(rev) {
+			return
+		}
+		c.lru.Remove(old)
+	}
+	c.revs[key] = rev
+	c.lru.Add(rev, kv)
+}
+
+// invalidate evicts the revision cached of key.
+func (c *readCache) invalidate(key []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if rev, ok := c.revs[string(key)]; ok {
+		c.lru.Remove(rev)
+	}
+}
+
+// invalidateRevisions evicts the revisions changed in place, and bumps the
+// generation of the cache.
+func (c *readCache) invalidateRevisions(revs []revision) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rev := range revs {
+		c.lru.Remove(rev)
+	}
+	c.gen++
+}
+
+// invalidateRange evicts the revisions cached of the keys in [key, end),
+// an empty end being the end of the keyspace.
+func (c *readCache) invalidateRange(key, end []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, rev := range c.revs {
+		if bytes.Compare([]byte(k), key) >= 0 && (len(end) == 0 || bytes.Compare([]byte(k), end) < 0) {
+			c.lru.Remove(rev)
+		}
+	}
+}
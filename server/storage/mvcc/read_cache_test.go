@@ -0,0 +1,84 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"context"
+	"testing"
+
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestStoreReadCache(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{ReadCacheSize: 2})
+	defer cleanup(s, b, tmpPath)
+
+	get := func(key string, rev int64) string {
+		t.Helper()
+		r, err := s.Range(context.TODO(), []byte(key), nil, RangeOptions{Rev: rev})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(r.KVs) != 1 {
+			return ""
+		}
+		return string(r.KVs[0].Value)
+	}
+
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	if v := get("foo", 0); v != "bar" {
+		t.Fatalf("foo = %q, want bar", v)
+	}
+	if _, ok := s.cache.get(revision{main: 2}); !ok {
+		t.Fatal("revision 2 of foo is not cached")
+	}
+	if v := get("foo", 0); v != "bar" {
+		t.Fatalf("cached foo = %q, want bar", v)
+	}
+
+	s.Put([]byte("foo"), []byte("baz"), lease.NoLease)
+	if _, ok := s.cache.get(revision{main: 2}); ok {
+		t.Error("revision 2 of foo is cached after foo was put again")
+	}
+	if v := get("foo", 0); v != "baz" {
+		t.Errorf("foo = %q, want baz", v)
+	}
+	if v := get("foo", 2); v != "bar" {
+		t.Errorf("foo at revision 2 = %q, want bar", v)
+	}
+	// a single revision of a key is cached, the last one read
+	if _, ok := s.cache.get(revision{main: 3}); !ok || s.cache.lru.Len() != 1 {
+		t.Errorf("cached revisions = %d, want revision 3 of foo only", s.cache.lru.Len())
+	}
+
+	s.Put([]byte("a"), []byte("1"), lease.NoLease)
+	s.Put([]byte("b"), []byte("2"), lease.NoLease)
+	get("a", 0)
+	get("b", 0)
+	if _, ok := s.cache.get(revision{main: 3}); ok {
+		t.Error("least recently read revision is not evicted")
+	}
+
+	s.DeleteRange([]byte("a"), []byte("c"))
+	if n := s.cache.lru.Len(); n != 0 {
+		t.Errorf("cached revisions after deleting them = %d, want 0", n)
+	}
+	if v := get("a", 0); v != "" {
+		t.Errorf("deleted a = %q, want none", v)
+	}
+}
@@ -15,6 +15,10 @@
 package mvcc
 
 import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	"go.etcd.io/etcd/server/v3/storage/schema"
 )
@@ -58,3 +62,113 @@ func UnsafeSetFinishedCompact(tx backend.BatchTx, value int64) {
 	revToBytes(revision{main: value}, rbytes)
 	tx.UnsafePut(schema.Meta, schema.FinishedCompactKeyName, rbytes)
 }
+
+// unsafeReadRangeTombstones reads the range tombstones not compacted yet, in
+// the order of revision.
+func unsafeReadRangeTombstones(tx backend.ReadTx) ([]rangeTombstone, error) {
+	var ts []rangeTombstone
+	err := tx.UnsafeForEach(schema.RangeTombstone, func(k, v []byte) error {
+		n, l := binary.Uvarint(v)
+		if l <= 0 || uint64(len(v)-l) < n {
+			return fmt.Errorf("malformed range tombstone at revision %v", bytesToRev(k))
+		}
+		key := v[l : l+int(n)]
+		ts = append(ts, rangeTombstone{
+			key: append([]byte{}, key...),
+			end: append([]byte{}, v[l+int(n):]...),
+			rev: bytesToRev(k),
+		})
+		return nil
+	})
+	return ts, err
+}
+
+// unsafePutRangeTombstone records t, keyed by its revision.
+func unsafePutRangeTombstone(tx backend.BatchTx, t rangeTombstone) {
+	rbytes := newRevBytes()
+	revToBytes(t.rev, rbytes)
+	v := make([]byte, binary.MaxVarintLen64+len(t.key)+len(t.end))
+	n := binary.PutUvarint(v, uint64(len(t.key)))
+	n += copy(v[n:], t.key)
+	n += copy(v[n:], t.end)
+	tx.UnsafePut(schema.RangeTombstone, rbytes, v[:n])
+}
+
+// unsafeDeleteRangeTombstones deletes the range tombstones up to the main
+// revision rev.
+func unsafeDeleteRangeTombstones(tx backend.BatchTx, rev int64) {
+	var keys [][]byte
+	tx.UnsafeForEach(schema.RangeTombstone, func(k, v []byte) error {
+		if bytesToRev(k).main <= rev {
+			keys = append(keys, append([]byte{}, k...))
+		}
+		return nil
+	})
+	for _, k := range keys {
+		tx.UnsafeDelete(schema.RangeTombstone, k)
+	}
+}
+
+// unsafeReadRangeCompactions reads the range compactions, in the order of
+// the revisions they were recorded at.
+func unsafeReadRangeCompactions(tx backend.ReadTx) ([]rangeCompaction, error) {
+	var cs []rangeCompaction
+	err := tx.UnsafeForEach(schema.RangeCompaction, func(k, v []byte) error {
+		if len(v) < 8 {
+			return fmt.Errorf("malformed range compaction at revision %v", bytesToRev(k))
+		}
+		n, l := binary.Uvarint(v[8:])
+		if l <= 0 || uint64(len(v)-8-l) < n {
+			return fmt.Errorf("malformed range compaction at revision %v", bytesToRev(k))
+		}
+		key := v[8+l : 8+l+int(n)]
+		cs = append(cs, rangeCompaction{
+			key:       append([]byte{}, key...),
+			end:       append([]byte{}, v[8+l+int(n):]...),
+			rev:       int64(binary.BigEndian.Uint64(v)),
+			recordRev: bytesToRev(k).main,
+		})
+		return nil
+	})
+	return cs, err
+}
+
+// unsafePutRangeCompaction records c, keyed by the revision it is recorded
+// at.
+func unsafePutRangeCompaction(tx backend.BatchTx, c rangeCompaction) {
+	rbytes := newRevBytes()
+	revToBytes(revision{main: c.recordRev}, rbytes)
+	v := make([]byte, 8+binary.MaxVarintLen64+len(c.key)+len(c.end))
+	binary.BigEndian.PutUint64(v, uint64(c.rev))
+	n := 8 + binary.PutUvarint(v[8:], uint64(len(c.key)))
+	n += copy(v[n:], c.key)
+	n += copy(v[n:], c.end)
+	tx.UnsafePut(schema.RangeCompaction, rbytes, v[:n])
+}
+
+// unsafeDeleteRangeCompaction deletes the record of c.
+func unsafeDeleteRangeCompaction(tx backend.BatchTx, c rangeCompaction) {
+	rbytes := newRevBytes()
+	revToBytes(revision{main: c.recordRev}, rbytes)
+	tx.UnsafeDelete(schema.RangeCompaction, rbytes)
+}
+
+// unsafeDeleteRangeCompactions deletes the range compactions up to the main
+// revision rev, but the last one recorded, which keeps the revision it was
+// recorded at.
+func unsafeDeleteRangeCompactions(tx backend.BatchTx, rev int64) {
+	var keys [][]byte
+	var last []byte
+	tx.UnsafeForEach(schema.RangeCompaction, func(k, v []byte) error {
+		last = k
+		if len(v) >= 8 && int64(binary.BigEndian.Uint64(v)) <= rev {
+			keys = append(keys, append([]byte{}, k...))
+		}
+		return nil
+	})
+	for _, k := range keys {
+		if !bytes.Equal(k, last) {
+			tx.UnsafeDelete(schema.RangeCompaction, k)
+		}
+	}
+}
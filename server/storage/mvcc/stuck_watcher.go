@@ -0,0 +1,116 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.uber.org/zap"
+)
+
+const (
+	// StuckWatcherCancel cancels the stuck watchers with ErrWatcherStuck.
+	StuckWatcherCancel = "cancel"
+	// StuckWatcherPause drops the backlog of the stuck watchers, which
+	// catch up with the store from their first undelivered revision once
+	// their stream is drained.
+	StuckWatcherPause = "pause"
+	// StuckWatcherCompress keeps only the last event of each key of the
+	// backlog of the stuck watchers.
+	StuckWatcherCompress = "compress"
+)
+
+// ErrWatcherStuck is the error of the response canceling a stuck watcher.
+var ErrWatcherStuck = errors.New("mvcc: watcher is stuck")
+
+// ValidStuckWatcherPolicy returns an error if p is not a stuck watcher policy.
+func ValidStuckWatcherPolicy(p string) error {
+	switch p {
+	case "", StuckWatcherCancel, StuckWatcherPause, StuckWatcherCompress:
+		return nil
+	}
+	return fmt.Errorf("unknown stuck watcher policy %q (expected %q, %q or %q)", p, StuckWatcherCancel, StuckWatcherPause, StuckWatcherCompress)
+}
+
+// isStuck returns whether a victim watcher is stuck, its backlog being
+// larger than StuckWatcherMaxBytes or older than StuckWatcherMaxAge.
+func (s *watchableStore) isStuck(w *watcher, eb *eventBatch, now time.Time) bool {
+	maxBytes, maxAge := s.store.cfg.StuckWatcherMaxBytes, s.store.cfg.StuckWatcherMaxAge
+	if maxAge > 0 && !w.victimSince.IsZero() && now.Sub(w.victimSince) > maxAge {
+		return true
+	}
+	if maxBytes <= 0 {
+		return false
+	}
+	backlog := eventsSize(eb.evs)
+	if w.pending != nil {
+		_, pendingBytes := w.pending.load()
+		backlog += pendingBytes
+	}
+	return backlog > maxBytes
+}
+
+// handleStuck applies the stuck watcher policy to a victim watcher that
+// could not be sent its backlog eb, returning the batch it stays victim
+// with.
+func (s *watchableStore) handleStuck(w *watcher, eb *eventBatch, now time.Time) *eventBatch {
+	if eb.compressed || !s.isStuck(w, eb, now) {
+		return eb
+	}
+	policy := s.store.cfg.StuckWatcherPolicy
+	if policy == "" {
+		policy = StuckWatcherCancel
+	}
+	s.store.lg.Warn(
+		"watcher is stuck",
+		zap.Int64("watch-id", int64(w.id)),
+		zap.String("policy", policy),
+		zap.Int("backlog-events", len(eb.evs)),
+		zap.Duration("blocked", now.Sub(w.victimSince)),
+	)
+	stuckWatchersCounter.WithLabelValues(policy).Inc()
+
+	switch policy {
+	case StuckWatcherCompress:
+		return &eventBatch{evs: compressEvents(eb.evs), revs: eb.revs, moreRev: eb.moreRev, compressed: true}
+	case StuckWatcherPause:
+		if len(eb.evs) != 0 {
+			// resume from the first undelivered revision
+			w.minRev = eb.evs[0].Kv.ModRevision
+		}
+		w.paused = true
+	default:
+		w.cancelErr = ErrWatcherStuck
+	}
+	return &eventBatch{}
+}
+
+// compressEvents returns the last event of each key of evs, in revision order.
+func compressEvents(evs []mvccpb.Event) []mvccpb.Event {
+	last := make(map[string]int, len(evs))
+	for i := range evs {
+		last[string(evs[i].Kv.Key)] = i
+	}
+	cevs := make([]mvccpb.Event, 0, len(last))
+	for i := range evs {
+		if last[string(evs[i].Kv.Key)] == i {
+			cevs = append(cevs, evs[i])
+		}
+	}
+	return cevs
+}
@@ -0,0 +1,136 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestStuckWatcherPolicy(t *testing.T) {
+	tests := []struct {
+		policy string
+		// the revisions of the events of the stuck watcher received after
+		// its stream is drained
+		wantRevs []int64
+		wantErr  error
+	}{
+		{policy: StuckWatcherCancel, wantErr: ErrWatcherStuck},
+		{policy: StuckWatcherPause, wantRevs: []int64{2, 3, 4}},
+		{policy: StuckWatcherCompress, wantRevs: []int64{4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			oldChanBufLen := chanBufLen
+			b, tmpPath := betesting.NewDefaultTmpBackend(t)
+			s := newWatchableStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{
+				StuckWatcherPolicy:   tt.policy,
+				StuckWatcherMaxBytes: 1,
+			})
+			defer func() {
+				s.Close()
+				b.Close()
+				os.Remove(tmpPath)
+				chanBufLen = oldChanBufLen
+			}()
+
+			chanBufLen = 1
+			for i := 0; i < 3; i++ {
+				s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+			}
+			w := s.NewWatchStream()
+			defer w.Close()
+			if _, err := w.Watch(0, []byte("x"), nil, 0); err != nil {
+				t.Fatal(err)
+			}
+			// fill the channel, blocking the watcher catching up with foo
+			s.Put([]byte("x"), []byte("y"), lease.NoLease)
+			stuck := testutil.ToFloat64(stuckWatchersCounter.WithLabelValues(tt.policy))
+			id, err := w.Watch(0, []byte("foo"), nil, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			deadline := time.Now().Add(10 * time.Second)
+			for testutil.ToFloat64(stuckWatchersCounter.WithLabelValues(tt.policy)) == stuck {
+				if time.Now().After(deadline) {
+					t.Fatal("timed out waiting for the watcher to be stuck")
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			wr := <-w.Chan()
+			w.ReportReceived(wr)
+			if len(wr.Events) != 1 || string(wr.Events[0].Kv.Key) != "x" {
+				t.Fatalf("unexpected response %+v, want the event of x", wr)
+			}
+
+			var revs []int64
+			for len(revs) < len(tt.wantRevs) || (tt.wantErr != nil && revs == nil) {
+				select {
+				case wr = <-w.Chan():
+				case <-time.After(10 * time.Second):
+					t.Fatalf("timed out waiting for the response of the stuck watcher")
+				}
+				w.ReportReceived(wr)
+				if wr.WatchID != id {
+					t.Fatalf("watch id = %d, want %d", wr.WatchID, id)
+				}
+				if wr.Err != tt.wantErr {
+					t.Fatalf("err = %v, want %v", wr.Err, tt.wantErr)
+				}
+				if wr.Err != nil {
+					break
+				}
+				for _, ev := range wr.Events {
+					if ev.Type != mvccpb.PUT || string(ev.Kv.Key) != "foo" {
+						t.Fatalf("unexpected event %+v", ev)
+					}
+					revs = append(revs, ev.Kv.ModRevision)
+				}
+			}
+			for i := range tt.wantRevs {
+				if i >= len(revs) || revs[i] != tt.wantRevs[i] {
+					t.Fatalf("revisions = %v, want %v", revs, tt.wantRevs)
+				}
+			}
+
+			if err := w.Cancel(id); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestCompressEvents(t *testing.T) {
+	ev := func(key string, rev int64) mvccpb.Event {
+		return mvccpb.Event{Kv: &mvccpb.KeyValue{Key: []byte(key), ModRevision: rev}}
+	}
+	evs := compressEvents([]mvccpb.Event{ev("a", 2), ev("b", 3), ev("a", 4), ev("c", 5), ev("b", 6)})
+	var got []int64
+	for _, e := range evs {
+		got = append(got, e.Kv.ModRevision)
+	}
+	want := []int64{4, 5, 6}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("revisions = %v, want %v", got, want)
+	}
+}
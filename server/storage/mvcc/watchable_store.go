@@ -15,6 +15,8 @@
 package mvcc
 
 import (
+	"bytes"
+	"encoding/json"
 	"sync"
 	"time"
 
@@ -39,9 +41,10 @@ var (
 )
 
 type watchable interface {
-	watch(key, end []byte, startRev int64, id WatchID, ch chan<- WatchResponse, fcs ...FilterFunc) (*watcher, cancelFunc)
+	watch(ranges []KeyRange, startRev int64, id WatchID, ch chan<- WatchResponse, pending *pendingEvents, fcs ...FilterFunc) (*watcher, cancelFunc)
 	progress(w *watcher)
 	rev() int64
+	closeStream(ws *watchStream)
 }
 
 type watchableStore struct {
@@ -62,6 +65,10 @@ type watchableStore struct {
 	// The key of the map is the key that the watcher watches on.
 	synced watcherGroup
 
+	// streams are the open watch streams.
+	streams      map[*watchStream]struct{}
+	nextStreamID int64
+
 	stopc chan struct{}
 	wg    sync.WaitGroup
 }
@@ -83,6 +90,7 @@ func newWatchableStore(lg *zap.Logger, b backend.Backend, le lease.Lessor, cfg S
 		victimc:  make(chan struct{}, 1),
 		unsynced: newWatcherGroup(),
 		synced:   newWatcherGroup(),
+		streams:  make(map[*watchStream]struct{}),
 		stopc:    make(chan struct{}),
 	}
 	s.store.ReadView = &readView{s}
@@ -90,7 +98,11 @@ func newWatchableStore(lg *zap.Logger, b backend.Backend, le lease.Lessor, cfg S
 	if s.le != nil {
 		// use this store as the deleter so revokes trigger watch events
 		s.le.SetRangeDeleter(func() lease.TxnDelete { return s.Write(traceutil.TODO()) })
+		s.le.SetEventNotifier(s.notifyLeaseEvents)
 	}
+	reportWatchStreamStatsMu.Lock()
+	reportWatchStreamStatsStore = s
+	reportWatchStreamStatsMu.Unlock()
 	s.wg.Add(2)
 	go s.syncWatchersLoop()
 	go s.syncVictimsLoop()
@@ -100,27 +112,47 @@ func newWatchableStore(lg *zap.Logger, b backend.Backend, le lease.Lessor, cfg S
 func (s *watchableStore) Close() error {
 	close(s.stopc)
 	s.wg.Wait()
+	reportWatchStreamStatsMu.Lock()
+	// keep reporting a store opened since
+	if reportWatchStreamStatsStore == s {
+		reportWatchStreamStatsStore = nil
+	}
+	reportWatchStreamStatsMu.Unlock()
 	return s.store.Close()
 }
 
 func (s *watchableStore) NewWatchStream() WatchStream {
 	watchStreamGauge.Inc()
-	return &watchStream{
+	ws := &watchStream{
 		watchable: s,
 		ch:        make(chan WatchResponse, chanBufLen),
 		cancels:   make(map[WatchID]cancelFunc),
 		watchers:  make(map[WatchID]*watcher),
 	}
+	s.mu.Lock()
+	s.nextStreamID++
+	ws.id = s.nextStreamID
+	s.streams[ws] = struct{}{}
+	s.mu.Unlock()
+	return ws
+}
+
+func (s *watchableStore) closeStream(ws *watchStream) {
+	s.mu.Lock()
+	delete(s.streams, ws)
+	s.mu.Unlock()
 }
 
-func (s *watchableStore) watch(key, end []byte, startRev int64, id WatchID, ch chan<- WatchResponse, fcs ...FilterFunc) (*watcher, cancelFunc) {
+func (s *watchableStore) watch(ranges []KeyRange, startRev int64, id WatchID, ch chan<- WatchResponse, pending *pendingEvents, fcs ...FilterFunc) (*watcher, cancelFunc) {
 	wa := &watcher{
-		key:    key,
-		end:    end,
-		minRev: startRev,
-		id:     id,
-		ch:     ch,
-		fcs:    fcs,
+		key:     ranges[0].Key,
+		end:     ranges[0].End,
+		more:    ranges[1:],
+		minRev:  startRev,
+		id:      id,
+		ch:      ch,
+		pending: pending,
+		fcs:     fcs,
 	}
 
 	s.mu.Lock()
@@ -136,6 +168,7 @@ func (s *watchableStore) watch(key, end []byte, startRev int64, id WatchID, ch c
 		slowWatcherGauge.Inc()
 		s.unsynced.add(wa)
 	}
+	wa.startRev = wa.minRev
 	s.revMu.RUnlock()
 	s.mu.Unlock()
 
@@ -155,7 +188,7 @@ func (s *watchableStore) cancelWatcher(wa *watcher) {
 		} else if s.synced.delete(wa) {
 			watcherGauge.Dec()
 			break
-		} else if wa.compacted {
+		} else if wa.compacted || (!wa.victim && wa.cancelErr != nil) {
 			watcherGauge.Dec()
 			break
 		} else if wa.ch == nil {
@@ -273,14 +306,31 @@ func (s *watchableStore) moveVictims() (moved int) {
 	s.mu.Unlock()
 
 	var newVictim watcherBatch
+	now := time.Now()
 	for _, wb := range victims {
 		// try to send responses again
 		for w, eb := range wb {
 			// watcher has observed the store up to, but not including, w.minRev
 			rev := w.minRev - 1
-			if w.send(WatchResponse{WatchID: w.id, Events: eb.evs, Revision: rev}) {
-				pendingEventsGauge.Add(float64(len(eb.evs)))
-			} else {
+			sent := false
+			switch {
+			case w.cancelErr != nil:
+				sent = w.sendCancel(rev)
+			case w.paused:
+				// resume once the stream is drained
+				sent = w.pending == nil
+				if !sent {
+					events, _ := w.pending.load()
+					sent = events <= 0
+				}
+			default:
+				if sent = w.send(WatchResponse{WatchID: w.id, Events: eb.evs, Revision: rev}); sent {
+					pendingEventsGauge.Add(float64(len(eb.evs)))
+				} else {
+					eb = s.handleStuck(w, eb, now)
+				}
+			}
+			if !sent {
 				if newVictim == nil {
 					newVictim = make(watcherBatch)
 				}
@@ -300,6 +350,13 @@ func (s *watchableStore) moveVictims() (moved int) {
 				continue
 			}
 			w.victim = false
+			w.victimSince = time.Time{}
+			w.paused = false
+			if w.cancelErr != nil {
+				// canceled; removed from the store once the cancel is received
+				slowWatcherGauge.Dec()
+				continue
+			}
 			if eb.moreRev != 0 {
 				w.minRev = eb.moreRev
 			}
@@ -343,9 +400,8 @@ func (s *watchableStore) syncWatchers() int {
 	// find min revision index, and these revisions can be used to
 	// query the backend store of key-value pairs
 	curRev := s.store.currentRev
-	compactionRev := s.store.compactMainRev
 
-	wg, minRev := s.unsynced.choose(maxWatchersPerSync, curRev, compactionRev)
+	wg, minRev := s.unsynced.choose(maxWatchersPerSync, curRev, s.store.watchCompactRevOf)
 	minBytes, maxBytes := newRevBytes(), newRevBytes()
 	revToBytes(revision{main: minRev}, minBytes)
 	revToBytes(revision{main: curRev + 1}, maxBytes)
@@ -355,6 +411,13 @@ func (s *watchableStore) syncWatchers() int {
 	tx := s.store.b.ReadTx()
 	tx.RLock()
 	revs, vs := tx.UnsafeRange(schema.Key, minBytes, maxBytes, 0)
+	if minRev <= s.store.compactMainRev {
+		// replay the compacted revisions from the history
+		compactBytes := newRevBytes()
+		revToBytes(revision{main: s.store.compactMainRev + 1}, compactBytes)
+		hrevs, hvs := tx.UnsafeRange(schema.History, minBytes, compactBytes, 0)
+		revs, vs = mergeHistory(hrevs, hvs, revs, vs)
+	}
 	evs := kvsToEvents(s.store.lg, wg, revs, vs)
 	// Must unlock after kvsToEvents, because vs (come from boltdb memory) is not deep copy.
 	// We can only unlock after Unmarshal, which will do deep copy.
@@ -382,6 +445,7 @@ func (s *watchableStore) syncWatchers() int {
 			pendingEventsGauge.Add(float64(len(eb.evs)))
 		} else {
 			w.victim = true
+			w.victimSince = time.Now()
 		}
 
 		if w.victim {
@@ -410,7 +474,7 @@ func (s *watchableStore) syncWatchers() int {
 func kvsToEvents(lg *zap.Logger, wg *watcherGroup, revs, vals [][]byte) (evs []mvccpb.Event) {
 	for i, v := range vals {
 		var kv mvccpb.KeyValue
-		if err := kv.Unmarshal(v); err != nil {
+		if err := UnmarshalKeyValue(v, &kv); err != nil {
 			lg.Panic("failed to unmarshal mvccpb.KeyValue", zap.Error(err))
 		}
 
@@ -432,8 +496,17 @@ func kvsToEvents(lg *zap.Logger, wg *watcherGroup, revs, vals [][]byte) (evs []m
 // notify notifies the fact that given event at the given rev just happened to
 // watchers that watch on the key of the event.
 func (s *watchableStore) notify(rev int64, evs []mvccpb.Event) {
+	s.notifyBatch(rev, newWatcherBatch(&s.synced, evs))
+}
+
+// notifyBatch sends the events of wb at revision rev to their watchers,
+// moving the slow ones to the victims.
+func (s *watchableStore) notifyBatch(rev int64, wb watcherBatch) {
 	victim := make(watcherBatch)
-	for w, eb := range newWatcherBatch(&s.synced, evs) {
+	if len(wb) != 0 {
+		watchFanout.Observe(float64(len(wb)))
+	}
+	for w, eb := range wb {
 		if eb.revs != 1 {
 			s.store.lg.Panic(
 				"unexpected multiple revisions in watch notification",
@@ -446,6 +519,7 @@ func (s *watchableStore) notify(rev int64, evs []mvccpb.Event) {
 			// move slow watcher to victims
 			w.minRev = rev + 1
 			w.victim = true
+			w.victimSince = time.Now()
 			victim[w] = eb
 			s.synced.delete(w)
 			slowWatcherGauge.Inc()
@@ -454,6 +528,61 @@ func (s *watchableStore) notify(rev int64, evs []mvccpb.Event) {
 	s.addVictim(victim)
 }
 
+// notifyLeaseEvents notifies the synced watchers of the keys under
+// lease.EventsPrefix of the lifecycle events of the leases, as puts of the
+// keys of the leases at the current revision. The events are not stored, so
+// the watchers catching up with the store never see them, nor do the
+// watchers whose range is not confined to the prefix.
+func (s *watchableStore) notifyLeaseEvents(les []lease.Event) {
+	rev := s.store.Rev()
+	evs := make([]mvccpb.Event, 0, len(les))
+	for _, le := range les {
+		v, err := json.Marshal(le)
+		if err != nil {
+			s.store.lg.Panic("failed to marshal lease event", zap.Error(err))
+		}
+		kv := &mvccpb.KeyValue{
+			Key:            []byte(lease.EventKey(le.ID)),
+			Value:          v,
+			CreateRevision: rev,
+			ModRevision:    rev,
+			Version:        1,
+			Lease:          int64(le.ID),
+		}
+		evs = append(evs, mvccpb.Event{Type: mvccpb.PUT, Kv: kv})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the synced watchers have observed the current revision already, so the
+	// events are batched regardless of the minimum revision of the watchers
+	wb := make(watcherBatch)
+	for _, ev := range evs {
+		for w := range s.synced.watcherSetByKey(string(ev.Kv.Key)) {
+			if watchesLeaseEventsOnly(w) {
+				wb.add(w, ev)
+			}
+		}
+	}
+	s.notifyBatch(rev, wb)
+}
+
+var leaseEventsEnd = []byte(lease.EventsPrefix[:len(lease.EventsPrefix)-1] + "0")
+
+// watchesLeaseEventsOnly returns whether the ranges of w are confined to the
+// keys under lease.EventsPrefix.
+func watchesLeaseEventsOnly(w *watcher) bool {
+	for _, r := range w.keyRanges() {
+		if !bytes.HasPrefix(r.Key, []byte(lease.EventsPrefix)) {
+			return false
+		}
+		if r.End != nil && (len(r.End) == 0 || bytes.Compare(r.End, leaseEventsEnd) > 0) {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *watchableStore) addVictim(victim watcherBatch) {
 	if len(victim) == 0 {
 		return
@@ -484,9 +613,19 @@ type watcher struct {
 	// end indicates the end of the range to watch.
 	// If end is set, the watcher is on a range.
 	end []byte
+	// more are the other ranges of a watcher on several ranges.
+	more []KeyRange
 
 	// victim is set when ch is blocked and undergoing victim processing
 	victim bool
+	// victimSince is when the watcher last became a victim
+	victimSince time.Time
+	// paused is set when the backlog of a stuck victim is dropped until
+	// its stream is drained
+	paused bool
+	// cancelErr is set when the watcher is canceled by the store, such as
+	// ErrWatcherStuck for a stuck victim
+	cancelErr error
 
 	// compacted is set when the watcher is removed because of compaction
 	compacted bool
@@ -501,12 +640,44 @@ type watcher struct {
 
 	// minRev is the minimum revision update the watcher will accept
 	minRev int64
-	id     WatchID
+	// startRev is the revision the watcher was created at
+	startRev int64
+	id       WatchID
 
 	fcs []FilterFunc
 	// a chan to send out the watch response.
 	// The chan might be shared with other watchers.
 	ch chan<- WatchResponse
+	// pending counts the events sent to ch, shared by the watchers of ch.
+	pending *pendingEvents
+}
+
+// keyRanges returns all the ranges the watcher watches.
+func (w *watcher) keyRanges() []KeyRange {
+	return append([]KeyRange{{Key: w.key, End: w.end}}, w.more...)
+}
+
+// compactRev returns the latest revision any of the ranges of the watcher is
+// compacted at.
+func (w *watcher) compactRev(compactRev func(key, end []byte) int64) int64 {
+	rev := compactRev(w.key, w.end)
+	for _, r := range w.more {
+		if crev := compactRev(r.Key, r.End); crev > rev {
+			rev = crev
+		}
+	}
+	return rev
+}
+
+// sendCancel sends the response canceling the watcher with its cancelErr,
+// and returns whether it is sent.
+func (w *watcher) sendCancel(rev int64) bool {
+	select {
+	case w.ch <- WatchResponse{WatchID: w.id, Revision: rev, Err: w.cancelErr}:
+		return true
+	default:
+		return false
+	}
 }
 
 func (w *watcher) send(wr WatchResponse) bool {
@@ -535,8 +706,172 @@ func (w *watcher) send(wr WatchResponse) bool {
 	}
 	select {
 	case w.ch <- wr:
+		w.pending.add(wr.Events, 1)
 		return true
 	default:
 		return false
 	}
 }
+
+// WatchStreamStats returns the stats of the open watch streams.
+func (s *watchableStore) WatchStreamStats() []WatchStreamStats {
+	streams := s.openStreams()
+
+	// the stream lock must not be acquired while holding s.mu
+	stats := make([]WatchStreamStats, len(streams))
+	watchers := make([][]*watcher, len(streams))
+	for i, ws := range streams {
+		ws.mu.Lock()
+		stats[i] = WatchStreamStats{ID: ws.id, Label: ws.label, Watchers: len(ws.watchers)}
+		for _, w := range ws.watchers {
+			watchers[i] = append(watchers[i], w)
+		}
+		ws.mu.Unlock()
+		stats[i].PendingEvents, stats[i].PendingBytes = ws.pending.load()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	curRev := s.store.Rev()
+	victims := s.victimBatches()
+	for i := range stats {
+		for _, w := range watchers[i] {
+			if eb, ok := victims[w]; ok {
+				stats[i].PendingEvents += int64(len(eb.evs))
+				stats[i].PendingBytes += eventsSize(eb.evs)
+			}
+			if lag := s.revisionLag(w, victims[w], curRev); lag > stats[i].RevisionLag {
+				stats[i].RevisionLag = lag
+			}
+		}
+		if stats[i].PendingEvents < 0 {
+			// a response may be reported received before it is counted
+			stats[i].PendingEvents, stats[i].PendingBytes = 0, 0
+		}
+	}
+	return stats
+}
+
+// WatcherStats returns the stats of the watchers of the open watch stream
+// streamID, or of every open stream if streamID is 0.
+func (s *watchableStore) WatcherStats(streamID int64) []WatcherStats {
+	var (
+		stats    []WatcherStats
+		watchers []*watcher
+	)
+	for _, ws := range s.openStreams() {
+		if streamID != 0 && ws.id != streamID {
+			continue
+		}
+		ws.mu.Lock()
+		for id, w := range ws.watchers {
+			stats = append(stats, WatcherStats{
+				StreamID:    ws.id,
+				StreamLabel: ws.label,
+				StreamUser:  ws.user,
+				ID:          id,
+				Ranges:      w.keyRanges(),
+			})
+			watchers = append(watchers, w)
+		}
+		ws.mu.Unlock()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	curRev := s.store.Rev()
+	victims := s.victimBatches()
+	for i, w := range watchers {
+		stats[i].StartRev = w.startRev
+		stats[i].RevisionLag = s.revisionLag(w, victims[w], curRev)
+	}
+	return stats
+}
+
+// CancelWatcher cancels the watcher id of the open watch stream streamID,
+// sending the stream a response canceling it with ErrWatcherCanceled.
+func (s *watchableStore) CancelWatcher(streamID int64, id WatchID) error {
+	var w *watcher
+	for _, ws := range s.openStreams() {
+		if ws.id == streamID {
+			ws.mu.Lock()
+			w = ws.watchers[id]
+			ws.mu.Unlock()
+			break
+		}
+	}
+	if w == nil {
+		return ErrWatcherNotExist
+	}
+
+	for {
+		s.mu.Lock()
+		if w.cancelErr != nil || w.compacted || w.ch == nil {
+			// already canceled
+			s.mu.Unlock()
+			return nil
+		}
+		if s.unsynced.delete(w) {
+			break
+		}
+		if s.synced.delete(w) {
+			slowWatcherGauge.Inc()
+			break
+		}
+		if victims := s.victimBatches(); victims[w] != nil {
+			// the backlog of the victim is dropped
+			w.cancelErr = ErrWatcherCanceled
+			s.mu.Unlock()
+			return nil
+		}
+
+		// victim being processed so not accessible; retry
+		s.mu.Unlock()
+		time.Sleep(time.Millisecond)
+	}
+	defer s.mu.Unlock()
+
+	w.cancelErr = ErrWatcherCanceled
+	w.victim = true
+	w.victimSince = time.Now()
+	s.addVictim(watcherBatch{w: &eventBatch{}})
+	return nil
+}
+
+// openStreams returns the open watch streams.
+func (s *watchableStore) openStreams() []*watchStream {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	streams := make([]*watchStream, 0, len(s.streams))
+	for ws := range s.streams {
+		streams = append(streams, ws)
+	}
+	return streams
+}
+
+// victimBatches returns the backlogs of the victim watchers. s.mu must be
+// held.
+func (s *watchableStore) victimBatches() map[*watcher]*eventBatch {
+	victims := make(map[*watcher]*eventBatch)
+	for _, wb := range s.victims {
+		for w, eb := range wb {
+			victims[w] = eb
+		}
+	}
+	return victims
+}
+
+// revisionLag returns the number of revisions the watcher with the victim
+// backlog eb is behind curRev, 0 if it is synced. s.mu must be held.
+func (s *watchableStore) revisionLag(w *watcher, eb *eventBatch, curRev int64) int64 {
+	// the next revision the watcher has not received
+	next := w.minRev
+	if eb != nil {
+		if len(eb.evs) != 0 {
+			next = eb.evs[0].Kv.ModRevision
+		}
+	} else if _, ok := s.unsynced.watchers[w]; !ok && !w.victim {
+		return 0
+	}
+	return curRev - next + 1
+}
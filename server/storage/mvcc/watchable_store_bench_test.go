@@ -133,7 +133,8 @@ func BenchmarkWatchableStoreUnsyncedCancel(b *testing.B) {
 
 		// to make the test not crash from assigning to nil map.
 		// 'synced' doesn't get populated in this test.
-		synced: newWatcherGroup(),
+		synced:  newWatcherGroup(),
+		streams: make(map[*watchStream]struct{}),
 	}
 
 	defer func() {
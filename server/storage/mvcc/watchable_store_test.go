@@ -92,7 +92,8 @@ func TestCancelUnsynced(t *testing.T) {
 
 		// to make the test not crash from assigning to nil map.
 		// 'synced' doesn't get populated in this test.
-		synced: newWatcherGroup(),
+		synced:  newWatcherGroup(),
+		streams: make(map[*watchStream]struct{}),
 	}
 
 	defer func() {
@@ -145,6 +146,7 @@ func TestSyncWatchers(t *testing.T) {
 		store:    NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{}),
 		unsynced: newWatcherGroup(),
 		synced:   newWatcherGroup(),
+		streams:  make(map[*watchStream]struct{}),
 	}
 
 	defer func() {
@@ -300,6 +302,46 @@ func TestWatchFutureRev(t *testing.T) {
 	}
 }
 
+// TestWatchRangeTombstone ensures that synced watchers receive the delete
+// events of the keys deleted by a range tombstone.
+func TestWatchRangeTombstone(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := newWatchableStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{RangeTombstoneThreshold: 2})
+
+	defer func() {
+		b.Close()
+		s.Close()
+		os.Remove(tmpPath)
+	}()
+
+	s.Put([]byte("foo1"), []byte("bar"), lease.NoLease)
+	s.Put([]byte("foo2"), []byte("bar"), lease.NoLease)
+
+	w := s.NewWatchStream()
+	w.Watch(0, []byte("foo2"), nil, 0)
+
+	rev := s.Rev() + 1
+	if n, _ := s.DeleteRange([]byte("foo"), []byte("fop")); n != 2 {
+		t.Fatalf("n = %d, want 2", n)
+	}
+
+	select {
+	case resp := <-w.Chan():
+		if resp.Revision != rev {
+			t.Fatalf("rev = %d, want %d", resp.Revision, rev)
+		}
+		if len(resp.Events) != 1 {
+			t.Fatalf("len(events) = %d, want 1", len(resp.Events))
+		}
+		ev := resp.Events[0]
+		if ev.Type != mvccpb.DELETE || string(ev.Kv.Key) != "foo2" || ev.Kv.ModRevision != rev {
+			t.Fatalf("event = %+v, want delete of foo2 at %d", ev, rev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("failed to receive event in 1 second.")
+	}
+}
+
 func TestWatchRestore(t *testing.T) {
 	test := func(delay time.Duration) func(t *testing.T) {
 		return func(t *testing.T) {
@@ -656,3 +698,233 @@ func TestStressWatchCancelClose(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestWatchStreamStats(t *testing.T) {
+	oldChanBufLen := chanBufLen
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := newWatchableStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+
+	defer func() {
+		s.Close()
+		b.Close()
+		os.Remove(tmpPath)
+		chanBufLen = oldChanBufLen
+	}()
+
+	chanBufLen = 1
+	w := s.NewWatchStream()
+	w.SetLabel("127.0.0.1:1234")
+	idle := s.NewWatchStream()
+	defer idle.Close()
+	if _, err := w.Watch(0, []byte("foo"), nil, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first event fills the channel, the second blocks the watcher
+	// with the events of the next revisions left in the store
+	for i := 0; i < 3; i++ {
+		s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	}
+
+	stats := s.WatchStreamStats()
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 streams, got %d", len(stats))
+	}
+	var st WatchStreamStats
+	for _, st = range stats {
+		if st.Label == "127.0.0.1:1234" {
+			break
+		}
+	}
+	if st.Watchers != 1 || st.PendingEvents != 2 || st.PendingBytes == 0 {
+		t.Errorf("unexpected stream stats %+v, want 1 watcher and 2 pending events", st)
+	}
+	// the blocked watcher has not received the events of revisions 3 and 4
+	if st.RevisionLag != 2 {
+		t.Errorf("revision lag = %d, want 2", st.RevisionLag)
+	}
+
+	w.ReportReceived(<-w.Chan())
+	for _, st := range s.WatchStreamStats() {
+		if st.Label == "" && (st.Watchers != 0 || st.PendingEvents != 0 || st.RevisionLag != 0) {
+			t.Errorf("unexpected stats of idle stream %+v", st)
+		}
+	}
+
+	w.Close()
+	if stats := s.WatchStreamStats(); len(stats) != 1 {
+		t.Errorf("expected 1 stream after close, got %d", len(stats))
+	}
+}
+
+func TestWatchStreamStatsCollectorClose(t *testing.T) {
+	b1, tmpPath1 := betesting.NewDefaultTmpBackend(t)
+	defer os.Remove(tmpPath1)
+	defer b1.Close()
+	b2, tmpPath2 := betesting.NewDefaultTmpBackend(t)
+	defer os.Remove(tmpPath2)
+	defer b2.Close()
+
+	s1 := newWatchableStore(zaptest.NewLogger(t), b1, &lease.FakeLessor{}, StoreConfig{})
+	s2 := newWatchableStore(zaptest.NewLogger(t), b2, &lease.FakeLessor{}, StoreConfig{})
+
+	// closing a replaced store keeps reporting the current one
+	s1.Close()
+	reportWatchStreamStatsMu.RLock()
+	current := reportWatchStreamStatsStore
+	reportWatchStreamStatsMu.RUnlock()
+	if current != s2 {
+		t.Fatal("expected the stats of the open store to be reported")
+	}
+
+	s2.Close()
+	reportWatchStreamStatsMu.RLock()
+	current = reportWatchStreamStatsStore
+	reportWatchStreamStatsMu.RUnlock()
+	if current != nil {
+		t.Fatal("expected the stats of a closed store not to be reported")
+	}
+}
+
+func TestWatcherStatsAndCancel(t *testing.T) {
+	oldChanBufLen := chanBufLen
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := newWatchableStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+
+	defer func() {
+		s.Close()
+		b.Close()
+		os.Remove(tmpPath)
+		chanBufLen = oldChanBufLen
+	}()
+
+	chanBufLen = 1
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	w := s.NewWatchStream()
+	defer w.Close()
+	w.SetLabel("127.0.0.1:1234")
+	w.SetUser("alice")
+	synced, _ := w.Watch(0, []byte("foo"), nil, 0)
+	unsynced, _ := w.Watch(0, []byte("a"), []byte("b"), 1)
+
+	var stats []WatcherStats
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		// the unsynced watcher has no event to catch up with
+		if stats = s.WatcherStats(0); len(stats) == 2 && stats[0].RevisionLag == 0 && stats[1].RevisionLag == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected watcher stats %+v", stats)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for _, st := range stats {
+		if st.StreamLabel != "127.0.0.1:1234" || st.StreamUser != "alice" {
+			t.Errorf("unexpected stream of watcher stats %+v", st)
+		}
+		switch st.ID {
+		case synced:
+			if st.StartRev != 3 || len(st.Ranges) != 1 || string(st.Ranges[0].Key) != "foo" {
+				t.Errorf("unexpected synced watcher stats %+v", st)
+			}
+		case unsynced:
+			if st.StartRev != 1 || len(st.Ranges) != 1 || string(st.Ranges[0].End) != "b" {
+				t.Errorf("unexpected unsynced watcher stats %+v", st)
+			}
+		default:
+			t.Errorf("unexpected watcher %d", st.ID)
+		}
+	}
+	if got := s.WatcherStats(stats[0].StreamID + 1); len(got) != 0 {
+		t.Errorf("expected no watcher of another stream, got %+v", got)
+	}
+
+	if err := s.CancelWatcher(stats[0].StreamID, 100); err != ErrWatcherNotExist {
+		t.Fatalf("err = %v, want %v", err, ErrWatcherNotExist)
+	}
+	if err := s.CancelWatcher(stats[0].StreamID, synced); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case wr := <-w.Chan():
+		if wr.WatchID != synced || wr.Err != ErrWatcherCanceled {
+			t.Fatalf("unexpected response %+v, want the cancelation of watcher %d", wr, synced)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the cancelation of the watcher")
+	}
+	// the watcher is removed once its stream cancels it
+	if err := w.Cancel(synced); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.WatcherStats(0); len(got) != 1 || got[0].ID != unsynced {
+		t.Errorf("unexpected watcher stats after cancel %+v", got)
+	}
+	// the canceled watcher is not sent the events of its key
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	select {
+	case wr := <-w.Chan():
+		t.Fatalf("unexpected response %+v", wr)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWatchLeaseEvents(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := newWatchableStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+
+	defer func() {
+		s.Close()
+		b.Close()
+		os.Remove(tmpPath)
+	}()
+
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+
+	w := s.NewWatchStream()
+	defer w.Close()
+	prefixID, _ := w.Watch(0, []byte(lease.EventsPrefix), leaseEventsEnd, 0)
+	keyID, _ := w.Watch(0, []byte(lease.EventKey(2)), nil, 0)
+	// the whole keyspace, not confined to the prefix
+	w.Watch(0, []byte{0}, []byte{}, 0)
+
+	s.notifyLeaseEvents([]lease.Event{
+		{Type: lease.EventGranted, ID: 1, TTL: 10},
+		{Type: lease.EventExpired, ID: 2},
+	})
+
+	got := make(map[WatchID][]string)
+	for i := 0; i < 2; i++ {
+		select {
+		case resp := <-w.Chan():
+			if resp.Revision != 2 {
+				t.Errorf("revision = %d, want 2", resp.Revision)
+			}
+			for _, ev := range resp.Events {
+				if ev.Kv.ModRevision != 2 {
+					t.Errorf("mod revision = %d, want 2", ev.Kv.ModRevision)
+				}
+				got[resp.WatchID] = append(got[resp.WatchID], string(ev.Kv.Key)+" "+string(ev.Kv.Value))
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("failed to receive lease events")
+		}
+	}
+	select {
+	case resp := <-w.Chan():
+		t.Fatalf("unexpected response %+v", resp)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	wgot := map[WatchID][]string{
+		prefixID: {
+			lease.EventKey(1) + ` {"type":"granted","id":1,"ttl":10}`,
+			lease.EventKey(2) + ` {"type":"expired","id":2}`,
+		},
+		keyID: {lease.EventKey(2) + ` {"type":"expired","id":2}`},
+	}
+	if !reflect.DeepEqual(got, wgot) {
+		t.Errorf("events = %q, want %q", got, wgot)
+	}
+}
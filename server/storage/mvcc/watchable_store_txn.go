@@ -21,7 +21,7 @@ import (
 
 func (tw *watchableStoreTxnWrite) End() {
 	changes := tw.Changes()
-	if len(changes) == 0 {
+	if len(changes) == 0 && !tw.rangeTombstoned {
 		tw.TxnWrite.End()
 		return
 	}
@@ -41,16 +41,50 @@ func (tw *watchableStoreTxnWrite) End() {
 	// end write txn under watchable store lock so the updates are visible
 	// when asynchronous event posting checks the current store revision
 	tw.s.mu.Lock()
+	if tw.rangeTombstoned {
+		evs = append(evs, tw.s.rangeDeleteEvents(rev)...)
+	}
 	tw.s.notify(rev, evs)
 	tw.TxnWrite.End()
 	tw.s.mu.Unlock()
 }
 
+func (tw *watchableStoreTxnWrite) DeleteRange(key, end []byte) (n, rev int64) {
+	changes := len(tw.Changes())
+	n, rev = tw.TxnWrite.DeleteRange(key, end)
+	// a range tombstone deletes keys without a change for each of them
+	if n != 0 && len(tw.Changes()) == changes {
+		tw.rangeTombstoned = true
+	}
+	return n, rev
+}
+
+// rangeDeleteEvents returns the delete events of the keys deleted by the
+// range tombstones at rev. The keys are only listed for the range tombstones
+// with synced watchers; the unsynced watchers do not see them.
+func (s *watchableStore) rangeDeleteEvents(rev int64) (evs []mvccpb.Event) {
+	ts := s.store.kvindex.RangeTombstones()
+	for i := len(ts) - 1; i >= 0 && ts[i].rev.main == rev; i-- {
+		if !s.synced.intersects(string(ts[i].key), string(ts[i].end)) {
+			continue
+		}
+		for _, key := range s.store.kvindex.RangeDeleted(ts[i]) {
+			evs = append(evs, mvccpb.Event{
+				Type: mvccpb.DELETE,
+				Kv:   &mvccpb.KeyValue{Key: key, ModRevision: rev},
+			})
+		}
+	}
+	return evs
+}
+
 type watchableStoreTxnWrite struct {
 	TxnWrite
 	s *watchableStore
+	// rangeTombstoned is whether the txn recorded a range tombstone.
+	rangeTombstoned bool
 }
 
 func (s *watchableStore) Write(trace *traceutil.Trace) TxnWrite {
-	return &watchableStoreTxnWrite{s.store.Write(trace), s}
+	return &watchableStoreTxnWrite{TxnWrite: s.store.Write(trace), s: s}
 }
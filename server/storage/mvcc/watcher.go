@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"errors"
 	"sync"
+	"sync/atomic"
 
 	"go.etcd.io/etcd/api/v3/mvccpb"
 )
@@ -30,10 +31,19 @@ var (
 	ErrWatcherNotExist    = errors.New("mvcc: watcher does not exist")
 	ErrEmptyWatcherRange  = errors.New("mvcc: watcher range is empty")
 	ErrWatcherDuplicateID = errors.New("mvcc: duplicate watch ID provided on the WatchStream")
+	// ErrWatcherCanceled is the error of the response canceling a watcher
+	// with Watchable.CancelWatcher.
+	ErrWatcherCanceled = errors.New("mvcc: watcher is canceled by an administrator")
 )
 
 type WatchID int64
 
+// KeyRange is the key or the range [Key, End) a watcher watches. A nil End
+// is the single Key, and an empty End is the end of the keyspace.
+type KeyRange struct {
+	Key, End []byte
+}
+
 // FilterFunc returns true if the given event should be filtered out.
 type FilterFunc func(e mvccpb.Event) bool
 
@@ -50,6 +60,11 @@ type WatchStream interface {
 	// an auto-generated watch ID is returned.
 	Watch(id WatchID, key, end []byte, startRev int64, fcs ...FilterFunc) (WatchID, error)
 
+	// WatchRanges creates a watcher on several ranges, like Watch. The
+	// events of a revision on any of the ranges are sent to the watcher in
+	// one response, in the order of the revision.
+	WatchRanges(id WatchID, ranges []KeyRange, startRev int64, fcs ...FilterFunc) (WatchID, error)
+
 	// Chan returns a chan. All watch response will be sent to the returned chan.
 	Chan() <-chan WatchResponse
 
@@ -70,6 +85,18 @@ type WatchStream interface {
 
 	// Rev returns the current revision of the KV the stream watches on.
 	Rev() int64
+
+	// ReportReceived reports that wr was received from Chan, so that its
+	// events are no longer pending on the stream.
+	ReportReceived(wr WatchResponse)
+
+	// SetLabel sets the label identifying the stream in its
+	// WatchStreamStats, such as the address of the remote watcher.
+	SetLabel(label string)
+
+	// SetUser sets the authenticated user of the stream in the
+	// WatcherStats of its watchers.
+	SetUser(user string)
 }
 
 type WatchResponse struct {
@@ -88,6 +115,69 @@ type WatchResponse struct {
 
 	// CompactRevision is set when the watcher is cancelled due to compaction.
 	CompactRevision int64
+
+	// Err is set when the watcher is canceled for another reason, such as
+	// ErrWatcherStuck.
+	Err error
+}
+
+// WatchStreamStats describes the events a watch stream has not delivered.
+type WatchStreamStats struct {
+	// ID identifies the stream until it is closed.
+	ID int64
+	// Label is the label set with SetLabel.
+	Label    string
+	Watchers int
+	// PendingEvents is the number of events buffered in the stream
+	// channel or held for watchers blocked on the full channel.
+	PendingEvents int64
+	// PendingBytes is the size of the pending events.
+	PendingBytes int64
+	// RevisionLag is the number of revisions the slowest watcher of the
+	// stream is behind the current revision of the store.
+	RevisionLag int64
+}
+
+// WatcherStats describes a watcher of a watch stream.
+type WatcherStats struct {
+	// StreamID is the ID of the stream of the watcher.
+	StreamID int64
+	// StreamLabel and StreamUser are set with SetLabel and SetUser.
+	StreamLabel string
+	StreamUser  string
+	ID          WatchID
+	Ranges      []KeyRange
+	// StartRev is the revision the watcher was created at.
+	StartRev int64
+	// RevisionLag is the number of revisions the watcher is behind the
+	// current revision of the store, 0 if it is synced.
+	RevisionLag int64
+}
+
+// pendingEvents counts the events sent to a stream channel that are not
+// received yet.
+type pendingEvents struct {
+	events int64 // must use atomic operations to access
+	bytes  int64 // must use atomic operations to access
+}
+
+func (p *pendingEvents) add(evs []mvccpb.Event, sign int64) {
+	if p == nil || len(evs) == 0 {
+		return
+	}
+	atomic.AddInt64(&p.events, sign*int64(len(evs)))
+	atomic.AddInt64(&p.bytes, sign*eventsSize(evs))
+}
+
+func (p *pendingEvents) load() (events, bytes int64) {
+	return atomic.LoadInt64(&p.events), atomic.LoadInt64(&p.bytes)
+}
+
+func eventsSize(evs []mvccpb.Event) (n int64) {
+	for i := range evs {
+		n += int64(evs[i].Size())
+	}
+	return n
 }
 
 // watchStream contains a collection of watchers that share
@@ -95,6 +185,8 @@ type WatchResponse struct {
 type watchStream struct {
 	watchable watchable
 	ch        chan WatchResponse
+	id        int64
+	pending   pendingEvents
 
 	mu sync.Mutex // guards fields below it
 	// nextID is the ID pre-allocated for next new watcher in this stream
@@ -102,15 +194,32 @@ type watchStream struct {
 	closed   bool
 	cancels  map[WatchID]cancelFunc
 	watchers map[WatchID]*watcher
+	label    string
+	user     string
 }
 
 // Watch creates a new watcher in the stream and returns its WatchID.
 func (ws *watchStream) Watch(id WatchID, key, end []byte, startRev int64, fcs ...FilterFunc) (WatchID, error) {
-	// prevent wrong range where key >= end lexicographically
-	// watch request with 'WithFromKey' has empty-byte range end
-	if len(end) != 0 && bytes.Compare(key, end) != -1 {
+	return ws.WatchRanges(id, []KeyRange{{Key: key, End: end}}, startRev, fcs...)
+}
+
+// WatchRanges creates a new watcher on several ranges in the stream and
+// returns its WatchID.
+func (ws *watchStream) WatchRanges(id WatchID, ranges []KeyRange, startRev int64, fcs ...FilterFunc) (WatchID, error) {
+	if len(ranges) == 0 {
 		return -1, ErrEmptyWatcherRange
 	}
+	uniq := make([]KeyRange, 0, len(ranges))
+	for _, r := range ranges {
+		// prevent wrong range where key >= end lexicographically
+		// watch request with 'WithFromKey' has empty-byte range end
+		if len(r.End) != 0 && bytes.Compare(r.Key, r.End) != -1 {
+			return -1, ErrEmptyWatcherRange
+		}
+		if !containsKeyRange(uniq, r) {
+			uniq = append(uniq, r)
+		}
+	}
 
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
@@ -128,7 +237,7 @@ func (ws *watchStream) Watch(id WatchID, key, end []byte, startRev int64, fcs ..
 		return -1, ErrWatcherDuplicateID
 	}
 
-	w, c := ws.watchable.watch(key, end, startRev, id, ws.ch, fcs...)
+	w, c := ws.watchable.watch(uniq, startRev, id, ws.ch, &ws.pending, fcs...)
 
 	ws.cancels[id] = c
 	ws.watchers[id] = w
@@ -173,6 +282,7 @@ func (ws *watchStream) Close() {
 	}
 	ws.closed = true
 	close(ws.ch)
+	ws.watchable.closeStream(ws)
 	watchStreamGauge.Dec()
 }
 
@@ -182,6 +292,22 @@ func (ws *watchStream) Rev() int64 {
 	return ws.watchable.rev()
 }
 
+func (ws *watchStream) ReportReceived(wr WatchResponse) {
+	ws.pending.add(wr.Events, -1)
+}
+
+func (ws *watchStream) SetLabel(label string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.label = label
+}
+
+func (ws *watchStream) SetUser(user string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.user = user
+}
+
 func (ws *watchStream) RequestProgress(id WatchID) {
 	ws.mu.Lock()
 	w, ok := ws.watchers[id]
@@ -191,3 +317,12 @@ func (ws *watchStream) RequestProgress(id WatchID) {
 	}
 	ws.watchable.progress(w)
 }
+
+func containsKeyRange(ranges []KeyRange, r KeyRange) bool {
+	for _, o := range ranges {
+		if bytes.Equal(o.Key, r.Key) && bytes.Equal(o.End, r.End) && (o.End == nil) == (r.End == nil) {
+			return true
+		}
+	}
+	return false
+}
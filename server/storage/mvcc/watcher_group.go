@@ -36,6 +36,8 @@ type eventBatch struct {
 	revs int
 	// moreRev is first revision with more events following this batch
 	moreRev int64
+	// compressed is set when the batch of a stuck watcher is compressed
+	compressed bool
 }
 
 func (eb *eventBatch) add(ev mvccpb.Event) {
@@ -104,9 +106,11 @@ func (w watcherSet) add(wa *watcher) {
 	w[wa] = struct{}{}
 }
 
+// union adds the watchers of ws to w. A watcher on several overlapping ranges
+// may already be in w.
 func (w watcherSet) union(ws watcherSet) {
 	for wa := range ws {
-		w.add(wa)
+		w[wa] = struct{}{}
 	}
 }
 
@@ -119,17 +123,16 @@ func (w watcherSet) delete(wa *watcher) {
 
 type watcherSetByKey map[string]watcherSet
 
-func (w watcherSetByKey) add(wa *watcher) {
-	set := w[string(wa.key)]
+func (w watcherSetByKey) add(k string, wa *watcher) {
+	set := w[k]
 	if set == nil {
 		set = make(watcherSet)
-		w[string(wa.key)] = set
+		w[k] = set
 	}
 	set.add(wa)
 }
 
-func (w watcherSetByKey) delete(wa *watcher) bool {
-	k := string(wa.key)
+func (w watcherSetByKey) delete(k string, wa *watcher) bool {
 	if v, ok := w[k]; ok {
 		if _, ok := v[wa]; ok {
 			delete(v, wa)
@@ -164,13 +167,19 @@ func newWatcherGroup() watcherGroup {
 // add puts a watcher in the group.
 func (wg *watcherGroup) add(wa *watcher) {
 	wg.watchers.add(wa)
-	if wa.end == nil {
-		wg.keyWatchers.add(wa)
+	for _, r := range wa.keyRanges() {
+		wg.addRange(wa, r)
+	}
+}
+
+func (wg *watcherGroup) addRange(wa *watcher, r KeyRange) {
+	if r.End == nil {
+		wg.keyWatchers.add(string(r.Key), wa)
 		return
 	}
 
 	// interval already registered?
-	ivl := adt.NewStringAffineInterval(string(wa.key), string(wa.end))
+	ivl := adt.NewStringAffineInterval(string(r.Key), string(r.End))
 	if iv := wg.ranges.Find(ivl); iv != nil {
 		iv.Val.(watcherSet).add(wa)
 		return
@@ -188,6 +197,24 @@ func (wg *watcherGroup) contains(key string) bool {
 	return ok || wg.ranges.Intersects(adt.NewStringAffinePoint(key))
 }
 
+// intersects is whether a key in the range [key, end) has a watcher in the
+// group. An empty end is the end of the keyspace.
+func (wg *watcherGroup) intersects(key, end string) bool {
+	if key == "" {
+		// an empty begin is the end of the keyspace to the interval tree
+		key = "\x00"
+	}
+	if wg.ranges.Intersects(adt.NewStringAffineInterval(key, end)) {
+		return true
+	}
+	for k := range wg.keyWatchers {
+		if k >= key && (end == "" || k < end) {
+			return true
+		}
+	}
+	return false
+}
+
 // size gives the number of unique watchers in the group.
 func (wg *watcherGroup) size() int { return len(wg.watchers) }
 
@@ -197,12 +224,20 @@ func (wg *watcherGroup) delete(wa *watcher) bool {
 		return false
 	}
 	wg.watchers.delete(wa)
-	if wa.end == nil {
-		wg.keyWatchers.delete(wa)
+	ok := true
+	for _, r := range wa.keyRanges() {
+		ok = wg.deleteRange(wa, r) && ok
+	}
+	return ok
+}
+
+func (wg *watcherGroup) deleteRange(wa *watcher, r KeyRange) bool {
+	if r.End == nil {
+		wg.keyWatchers.delete(string(r.Key), wa)
 		return true
 	}
 
-	ivl := adt.NewStringAffineInterval(string(wa.key), string(wa.end))
+	ivl := adt.NewStringAffineInterval(string(r.Key), string(r.End))
 	iv := wg.ranges.Find(ivl)
 	if iv == nil {
 		return false
@@ -220,8 +255,9 @@ func (wg *watcherGroup) delete(wa *watcher) bool {
 	return true
 }
 
-// choose selects watchers from the watcher group to update
-func (wg *watcherGroup) choose(maxWatchers int, curRev, compactRev int64) (*watcherGroup, int64) {
+// choose selects watchers from the watcher group to update, given the
+// revision each range of keys is compacted at.
+func (wg *watcherGroup) choose(maxWatchers int, curRev int64, compactRev func(key, end []byte) int64) (*watcherGroup, int64) {
 	if len(wg.watchers) < maxWatchers {
 		return wg, wg.chooseAll(curRev, compactRev)
 	}
@@ -236,7 +272,7 @@ func (wg *watcherGroup) choose(maxWatchers int, curRev, compactRev int64) (*watc
 	return &ret, ret.chooseAll(curRev, compactRev)
 }
 
-func (wg *watcherGroup) chooseAll(curRev, compactRev int64) int64 {
+func (wg *watcherGroup) chooseAll(curRev int64, compactRev func(key, end []byte) int64) int64 {
 	minRev := int64(math.MaxInt64)
 	for w := range wg.watchers {
 		if w.minRev > curRev {
@@ -250,9 +286,9 @@ func (wg *watcherGroup) chooseAll(curRev, compactRev int64) int64 {
 			// mark 'restore' done, since it's chosen
 			w.restore = false
 		}
-		if w.minRev < compactRev {
+		if crev := w.compactRev(compactRev); w.minRev < crev {
 			select {
-			case w.ch <- WatchResponse{WatchID: w.id, CompactRevision: compactRev}:
+			case w.ch <- WatchResponse{WatchID: w.id, CompactRevision: crev}:
 				w.compacted = true
 				wg.delete(w)
 			default:
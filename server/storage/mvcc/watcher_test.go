@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/pkg/v3/traceutil"
 	"go.etcd.io/etcd/server/v3/lease"
 	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
 	"go.uber.org/zap/zaptest"
@@ -211,6 +212,89 @@ func TestWatcherWatchWrongRange(t *testing.T) {
 	}
 }
 
+// TestWatcherWatchRanges tests that a watcher on several ranges receives the
+// events of a revision on any of them in one response.
+func TestWatcherWatchRanges(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := newWatchableStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+	defer cleanup(s, b, tmpPath)
+
+	w := s.NewWatchStream()
+	defer w.Close()
+
+	ranges := []KeyRange{
+		{Key: []byte("a"), End: []byte("b")},
+		{Key: []byte("a"), End: []byte("a5")},
+		{Key: []byte("c")},
+		{Key: []byte("x"), End: []byte{}},
+		// duplicated
+		{Key: []byte("c")},
+	}
+	put := func() int64 {
+		txn := s.Write(traceutil.TODO())
+		for _, k := range []string{"a1", "b", "c", "c1", "z"} {
+			txn.Put([]byte(k), []byte("v"), lease.NoLease)
+		}
+		txn.End()
+		return s.Rev()
+	}
+	check := func(id WatchID, rev int64) {
+		t.Helper()
+		select {
+		case resp := <-w.Chan():
+			if resp.WatchID != id {
+				t.Fatalf("watch id = %d, want %d", resp.WatchID, id)
+			}
+			var keys []string
+			for _, ev := range resp.Events {
+				if ev.Kv.ModRevision != rev {
+					t.Errorf("event revision = %d, want %d", ev.Kv.ModRevision, rev)
+				}
+				keys = append(keys, string(ev.Kv.Key))
+			}
+			if want := []string{"a1", "c", "z"}; !reflect.DeepEqual(keys, want) {
+				t.Errorf("keys = %v, want %v", keys, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("failed to receive event in 5 seconds")
+		}
+	}
+
+	// synced
+	id, err := w.WatchRanges(0, ranges, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rev := put()
+	check(id, rev)
+	if err = w.Cancel(id); err != nil {
+		t.Fatal(err)
+	}
+
+	// unsynced
+	id, err = w.WatchRanges(0, ranges, rev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(id, rev)
+	if err = w.Cancel(id); err != nil {
+		t.Fatal(err)
+	}
+	if n := s.synced.size() + s.unsynced.size(); n != 0 {
+		t.Errorf("watchers = %d, want 0", n)
+	}
+	if n := s.synced.ranges.Len() + len(s.synced.keyWatchers); n != 0 {
+		t.Errorf("ranges of synced watchers = %d, want 0", n)
+	}
+
+	if _, err = w.WatchRanges(0, nil, 0); err != ErrEmptyWatcherRange {
+		t.Errorf("no range given; expected ErrEmptyWatcherRange, got %+v", err)
+	}
+	if _, err = w.WatchRanges(0, []KeyRange{{Key: []byte("a"), End: []byte("b")}, {Key: []byte("d"), End: []byte("c")}}, 0); err != ErrEmptyWatcherRange {
+		t.Errorf("key > end range given; expected ErrEmptyWatcherRange, got %+v", err)
+	}
+}
+
 func TestWatchDeleteRange(t *testing.T) {
 	b, tmpPath := betesting.NewDefaultTmpBackend(t)
 	s := newWatchableStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
@@ -299,6 +383,7 @@ func TestWatcherRequestProgress(t *testing.T) {
 		store:    NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{}),
 		unsynced: newWatcherGroup(),
 		synced:   newWatcherGroup(),
+		streams:  make(map[*watchStream]struct{}),
 	}
 
 	defer func() {
@@ -126,6 +126,19 @@ func NewBackendQuota(cfg config.ServerConfig, be backend.Backend, name string) Q
 	return &BackendQuota{be, cfg.QuotaBackendBytes}
 }
 
+// QuotaBackendBytes returns the backend quota in bytes enforced for the given
+// configuration, or zero if the quota is disabled.
+func QuotaBackendBytes(cfg config.ServerConfig) int64 {
+	switch {
+	case cfg.QuotaBackendBytes < 0:
+		return 0
+	case cfg.QuotaBackendBytes == 0:
+		return DefaultQuotaBytes
+	default:
+		return cfg.QuotaBackendBytes
+	}
+}
+
 func (b *BackendQuota) Available(v interface{}) bool {
 	cost := b.Cost(v)
 	// if there are no mutating requests, it's safe to pass through
@@ -144,6 +157,14 @@ func (b *BackendQuota) Cost(v interface{}) int {
 		return costTxn(r)
 	case *pb.LeaseGrantRequest:
 		return leaseOverhead
+	case *pb.LeaseGrantBulkRequest:
+		return leaseOverhead * len(r.Leases)
+	case *pb.PutChunkRequest:
+		return kvOverhead + len(r.Data)
+	case *pb.IncrementRequest:
+		return costIncrement(r)
+	case *pb.PutIfAbsentRequest:
+		return kvOverhead + len(r.Key) + len(r.Value)
 	default:
 		panic("unexpected cost")
 	}
@@ -151,6 +172,18 @@ func (b *BackendQuota) Cost(v interface{}) int {
 
 func costPut(r *pb.PutRequest) int { return kvOverhead + len(r.Key) + len(r.Value) }
 
+// costIncrement is the cost of the key incremented by r, whose value is at
+// most 20 digits, "-9223372036854775808", unless padded to a larger width.
+func costIncrement(r *pb.IncrementRequest) int {
+	n := 20
+	if r.Format == pb.IncrementRequest_INT64_BIG_ENDIAN {
+		n = 8
+	} else if int(r.Width) > n {
+		n = int(r.Width)
+	}
+	return kvOverhead + len(r.Key) + n
+}
+
 func costTxnReq(u *pb.RequestOp) int {
 	r := u.GetRequestPut()
 	if r == nil {
@@ -0,0 +1,90 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"go.etcd.io/etcd/server/v3/auth"
+)
+
+var (
+	// the revocations of the auth tokens are stored in the auth bucket,
+	// older versions of etcd ignore them
+	authRevokedTokenPrefix        = []byte("revokedToken/")
+	authTokensRevokedBeforePrefix = []byte("tokensRevokedBefore/")
+)
+
+func (atx *authBatchTx) UnsafePutRevokedToken(id uint64, r auth.RevokedToken) {
+	v := make([]byte, 8+len(r.User))
+	binary.BigEndian.PutUint64(v, uint64(r.ExpiresAt.Unix()))
+	copy(v[8:], r.User)
+	atx.tx.UnsafePut(Auth, revokedTokenKey(id), v)
+}
+
+func (atx *authBatchTx) UnsafeDeleteRevokedToken(id uint64) {
+	atx.tx.UnsafeDelete(Auth, revokedTokenKey(id))
+}
+
+func (atx *authBatchTx) UnsafePutTokensRevokedBefore(username string, index uint64) {
+	v := make([]byte, 8)
+	binary.BigEndian.PutUint64(v, index)
+	atx.tx.UnsafePut(Auth, append(append([]byte(nil), authTokensRevokedBeforePrefix...), username...), v)
+}
+
+func (atx *authBatchTx) UnsafeGetRevokedTokens() map[uint64]auth.RevokedToken {
+	arx := &authReadTx{tx: atx.tx, lg: atx.lg}
+	return arx.UnsafeGetRevokedTokens()
+}
+
+func (atx *authBatchTx) UnsafeGetTokensRevokedBefore() map[string]uint64 {
+	arx := &authReadTx{tx: atx.tx, lg: atx.lg}
+	return arx.UnsafeGetTokensRevokedBefore()
+}
+
+// UnsafeGetRevokedTokens returns the revocations of the tokens by ID.
+func (atx *authReadTx) UnsafeGetRevokedTokens() map[uint64]auth.RevokedToken {
+	revoked := make(map[uint64]auth.RevokedToken)
+	atx.tx.UnsafeForEach(Auth, func(k, v []byte) error {
+		if bytes.HasPrefix(k, authRevokedTokenPrefix) {
+			id := binary.BigEndian.Uint64(k[len(authRevokedTokenPrefix):])
+			revoked[id] = auth.RevokedToken{User: string(v[8:]), ExpiresAt: time.Unix(int64(binary.BigEndian.Uint64(v)), 0)}
+		}
+		return nil
+	})
+	return revoked
+}
+
+// UnsafeGetTokensRevokedBefore returns the indexes the tokens issued before
+// are revoked, by user.
+func (atx *authReadTx) UnsafeGetTokensRevokedBefore() map[string]uint64 {
+	revokedBefore := make(map[string]uint64)
+	atx.tx.UnsafeForEach(Auth, func(k, v []byte) error {
+		if bytes.HasPrefix(k, authTokensRevokedBeforePrefix) {
+			revokedBefore[string(k[len(authTokensRevokedBeforePrefix):])] = binary.BigEndian.Uint64(v)
+		}
+		return nil
+	})
+	return revokedBefore
+}
+
+func revokedTokenKey(id uint64) []byte {
+	k := make([]byte, len(authRevokedTokenPrefix)+8)
+	copy(k, authRevokedTokenPrefix)
+	binary.BigEndian.PutUint64(k[len(authRevokedTokenPrefix):], id)
+	return k
+}
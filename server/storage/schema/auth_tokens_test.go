@@ -0,0 +1,59 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/server/v3/auth"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+)
+
+// TestAuthRevokedTokens ensures the revocations of the tokens are read back
+// after a restart, apart from the auth revision.
+func TestAuthRevokedTokens(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	be, tmpPath := betesting.NewTmpBackend(t, time.Microsecond, 10)
+	abe := NewAuthBackend(lg, be)
+	abe.CreateAuthBuckets()
+
+	exp := time.Unix(time.Now().Add(time.Minute).Unix(), 0)
+	tx := abe.BatchTx()
+	tx.Lock()
+	tx.UnsafeSaveAuthRevision(5)
+	tx.UnsafePutRevokedToken(10, auth.RevokedToken{ExpiresAt: exp})
+	tx.UnsafePutRevokedToken(11, auth.RevokedToken{User: "alice", ExpiresAt: exp})
+	tx.UnsafePutRevokedToken(12, auth.RevokedToken{ExpiresAt: exp})
+	tx.UnsafeDeleteRevokedToken(12)
+	tx.UnsafePutTokensRevokedBefore("bob", 13)
+	tx.Unlock()
+	abe.ForceCommit()
+	be.Close()
+
+	be2 := backend.NewDefaultBackend(lg, tmpPath)
+	defer be2.Close()
+	abe2 := NewAuthBackend(lg, be2)
+	rtx := abe2.ReadTx()
+	rtx.Lock()
+	defer rtx.Unlock()
+	assert.Equal(t, map[uint64]auth.RevokedToken{10: {ExpiresAt: exp}, 11: {User: "alice", ExpiresAt: exp}}, rtx.UnsafeGetRevokedTokens())
+	assert.Equal(t, map[string]uint64{"bob": 13}, rtx.UnsafeGetTokensRevokedBefore())
+	assert.Equal(t, uint64(5), rtx.UnsafeReadAuthRevision())
+}
@@ -74,19 +74,19 @@ func (atx *authReadTx) UnsafeGetUser(username string) *authpb.User {
 }
 
 func (atx *authReadTx) UnsafeGetAllUsers() []*authpb.User {
-	_, vs := atx.tx.UnsafeRange(AuthUsers, []byte{0}, []byte{0xff}, -1)
-	if len(vs) == 0 {
-		return nil
-	}
-
-	users := make([]*authpb.User, len(vs))
-	for i := range vs {
+	// ranging over the users bucket is not safe with the buffer of a read
+	// transaction, see IsSafeRangeBucket
+	var users []*authpb.User
+	err := atx.tx.UnsafeForEach(AuthUsers, func(k, v []byte) error {
 		user := &authpb.User{}
-		err := user.Unmarshal(vs[i])
-		if err != nil {
-			atx.lg.Panic("failed to unmarshal 'authpb.User'", zap.Error(err))
+		if err := user.Unmarshal(v); err != nil {
+			return err
 		}
-		users[i] = user
+		users = append(users, user)
+		return nil
+	})
+	if err != nil {
+		atx.lg.Panic("failed to unmarshal 'authpb.User'", zap.Error(err))
 	}
 	return users
 }
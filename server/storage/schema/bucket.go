@@ -27,7 +27,14 @@ var (
 	leaseBucketName = []byte("lease")
 	alarmBucketName = []byte("alarm")
 
-	clusterBucketName = []byte("cluster")
+	clusterBucketName         = []byte("cluster")
+	clusterHistoryBucketName  = []byte("clusterHistory")
+	prefixQuotaBucketName     = []byte("prefixQuota")
+	rangeTombstoneBucketName  = []byte("rangeTombstone")
+	putChunkBucketName        = []byte("putChunk")
+	rangeCompactionBucketName = []byte("rangeCompaction")
+	walArchiveBucketName      = []byte("walArchive")
+	historyBucketName         = []byte("history")
 
 	membersBucketName        = []byte("members")
 	membersRemovedBucketName = []byte("members_removed")
@@ -46,9 +53,20 @@ var (
 	Alarm   = backend.Bucket(bucket{id: 4, name: alarmBucketName, safeRangeBucket: false})
 	Cluster = backend.Bucket(bucket{id: 5, name: clusterBucketName, safeRangeBucket: false})
 
+	ClusterHistory = backend.Bucket(bucket{id: 6, name: clusterHistoryBucketName, safeRangeBucket: false})
+	PrefixQuota    = backend.Bucket(bucket{id: 7, name: prefixQuotaBucketName, safeRangeBucket: false})
+	RangeTombstone = backend.Bucket(bucket{id: 8, name: rangeTombstoneBucketName, safeRangeBucket: false})
+	PutChunk       = backend.Bucket(bucket{id: 9, name: putChunkBucketName, safeRangeBucket: false})
+
 	Members        = backend.Bucket(bucket{id: 10, name: membersBucketName, safeRangeBucket: false})
 	MembersRemoved = backend.Bucket(bucket{id: 11, name: membersRemovedBucketName, safeRangeBucket: false})
 
+	RangeCompaction = backend.Bucket(bucket{id: 12, name: rangeCompactionBucketName, safeRangeBucket: false})
+	WALArchive      = backend.Bucket(bucket{id: 13, name: walArchiveBucketName, safeRangeBucket: false})
+	// History is written once by revision; the readers skip the duplicates
+	// of a compaction resumed after a restart.
+	History = backend.Bucket(bucket{id: 14, name: historyBucketName, safeRangeBucket: true})
+
 	Auth      = backend.Bucket(bucket{id: 20, name: authBucketName, safeRangeBucket: false})
 	AuthUsers = backend.Bucket(bucket{id: 21, name: authUsersBucketName, safeRangeBucket: false})
 	AuthRoles = backend.Bucket(bucket{id: 22, name: authRolesBucketName, safeRangeBucket: false})
@@ -56,6 +74,14 @@ var (
 	Test = backend.Bucket(bucket{id: 100, name: testBucketName, safeRangeBucket: false})
 )
 
+// UnsafeClearKV empties the buckets of the keys and the leases.
+func UnsafeClearKV(tx backend.BatchTx) {
+	for _, b := range []backend.Bucket{Key, Lease} {
+		tx.UnsafeDeleteBucket(b)
+		tx.UnsafeCreateBucket(b)
+	}
+}
+
 type bucket struct {
 	id              backend.BucketID
 	name            []byte
@@ -89,8 +115,10 @@ func DefaultIgnores(bucket, key []byte) bool {
 	// consistent index & term might be changed due to v2 internal sync, which
 	// is not controllable by the user.
 	// storage version might change after wal snapshot and is not controller by user.
+	// cluster history includes the leader changes observed by each member.
 	return bytes.Compare(bucket, Meta.Name()) == 0 &&
-		(bytes.Compare(key, MetaTermKeyName) == 0 || bytes.Compare(key, MetaConsistentIndexKeyName) == 0 || bytes.Compare(key, MetaStorageVersionName) == 0)
+		(bytes.Compare(key, MetaTermKeyName) == 0 || bytes.Compare(key, MetaConsistentIndexKeyName) == 0 || bytes.Compare(key, MetaStorageVersionName) == 0) ||
+		bytes.Compare(bucket, ClusterHistory.Name()) == 0
 }
 
 func BackendMemberKey(id types.ID) []byte {
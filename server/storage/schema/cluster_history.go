@@ -0,0 +1,77 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+)
+
+// UnsafeCreateClusterHistoryBucket creates the bucket of the cluster events.
+// Older versions of etcd ignore it.
+func UnsafeCreateClusterHistoryBucket(tx backend.BatchTx) {
+	tx.UnsafeCreateBucket(ClusterHistory)
+}
+
+// ClusterEventRecord is a cluster event with its sequence number.
+type ClusterEventRecord struct {
+	Seq   uint64
+	Event *etcdserverpb.ClusterEvent
+}
+
+// MustUnsafeGetAllClusterEvents returns the cluster events in the order
+// they were recorded.
+func MustUnsafeGetAllClusterEvents(tx backend.ReadTx) []ClusterEventRecord {
+	var rs []ClusterEventRecord
+	err := tx.UnsafeForEach(ClusterHistory, func(k, v []byte) error {
+		if len(k) != 8 {
+			return fmt.Errorf("invalid cluster event key %x", k)
+		}
+		var ev etcdserverpb.ClusterEvent
+		if err := ev.Unmarshal(v); err != nil {
+			return fmt.Errorf("failed to unmarshal cluster event %d: %v", binary.BigEndian.Uint64(k), err)
+		}
+		rs = append(rs, ClusterEventRecord{Seq: binary.BigEndian.Uint64(k), Event: &ev})
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	// the pending events are visited after the committed ones
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Seq < rs[j].Seq })
+	return rs
+}
+
+func MustUnsafePutClusterEvent(tx backend.BatchTx, seq uint64, ev *etcdserverpb.ClusterEvent) {
+	v, err := ev.Marshal()
+	if err != nil {
+		panic("failed to marshal cluster event")
+	}
+	tx.UnsafePut(ClusterHistory, clusterEventKey(seq), v)
+}
+
+func UnsafeDeleteClusterEvent(tx backend.BatchTx, seq uint64) {
+	tx.UnsafeDelete(ClusterHistory, clusterEventKey(seq))
+}
+
+func clusterEventKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+)
+
+// UnsafeCreatePrefixQuotaBucket creates the bucket of the quotas of the key
+// prefixes. Older versions of etcd ignore it.
+func UnsafeCreatePrefixQuotaBucket(tx backend.BatchTx) {
+	tx.UnsafeCreateBucket(PrefixQuota)
+}
+
+// MustUnsafeGetAllPrefixQuotas returns the quotas of the key prefixes. Their
+// usage is not persisted.
+func MustUnsafeGetAllPrefixQuotas(tx backend.ReadTx) []*etcdserverpb.PrefixQuota {
+	var qs []*etcdserverpb.PrefixQuota
+	err := tx.UnsafeForEach(PrefixQuota, func(k, v []byte) error {
+		var q etcdserverpb.PrefixQuota
+		if err := q.Unmarshal(v); err != nil {
+			return fmt.Errorf("failed to unmarshal quota of prefix %q: %v", k, err)
+		}
+		qs = append(qs, &q)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return qs
+}
+
+func MustUnsafePutPrefixQuota(tx backend.BatchTx, q *etcdserverpb.PrefixQuota) {
+	v, err := (&etcdserverpb.PrefixQuota{Prefix: q.Prefix, MaxBytes: q.MaxBytes, MaxKeys: q.MaxKeys}).Marshal()
+	if err != nil {
+		panic("failed to marshal prefix quota")
+	}
+	tx.UnsafePut(PrefixQuota, q.Prefix, v)
+}
+
+func UnsafeDeletePrefixQuota(tx backend.BatchTx, prefix []byte) {
+	tx.UnsafeDelete(PrefixQuota, prefix)
+}
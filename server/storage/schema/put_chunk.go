@@ -0,0 +1,99 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"go.etcd.io/etcd/server/v3/storage/backend"
+)
+
+// UnsafeCreatePutChunkBucket creates the bucket of the chunks of the values
+// too large to be proposed at once. Older versions of etcd ignore it.
+func UnsafeCreatePutChunkBucket(tx backend.BatchTx) {
+	tx.UnsafeCreateBucket(PutChunk)
+}
+
+// UnsafePutChunk stages the chunk index of the value uploaded as uploadID,
+// until expireTime in unix seconds.
+func UnsafePutChunk(tx backend.BatchTx, uploadID uint64, index int64, expireTime int64, data []byte) {
+	v := make([]byte, 8+len(data))
+	binary.BigEndian.PutUint64(v, uint64(expireTime))
+	copy(v[8:], data)
+	tx.UnsafePut(PutChunk, putChunkKey(uploadID, index), v)
+}
+
+// UnsafeReadChunks returns the first n chunks staged for uploadID, in order.
+// It returns fewer chunks if some are missing.
+func UnsafeReadChunks(tx backend.BatchTx, uploadID uint64, n int64) [][]byte {
+	_, vs := tx.UnsafeRange(PutChunk, putChunkKey(uploadID, 0), putChunkKey(uploadID, n), 0)
+	for i, v := range vs {
+		vs[i] = v[8:]
+	}
+	return vs
+}
+
+// UnsafeDeleteChunks deletes the chunks staged for uploadID.
+func UnsafeDeleteChunks(tx backend.BatchTx, uploadID uint64) {
+	ks, _ := tx.UnsafeRange(PutChunk, putChunkKey(uploadID, 0), putChunkKey(uploadID+1, 0), 0)
+	for _, k := range ks {
+		tx.UnsafeDelete(PutChunk, k)
+	}
+}
+
+// UnsafeDeleteExpiredChunks deletes the chunks expiring at t or before, and
+// returns their number.
+func UnsafeDeleteExpiredChunks(tx backend.BatchTx, t int64) int {
+	var ks [][]byte
+	tx.UnsafeForEach(PutChunk, func(k, v []byte) error {
+		if chunkExpired(v, t) {
+			ks = append(ks, append([]byte(nil), k...))
+		}
+		return nil
+	})
+	for _, k := range ks {
+		tx.UnsafeDelete(PutChunk, k)
+	}
+	return len(ks)
+}
+
+// HasExpiredChunks returns true if some chunks expire at t or before.
+func HasExpiredChunks(tx backend.ReadTx, t int64) bool {
+	tx.RLock()
+	defer tx.RUnlock()
+	found := false
+	tx.UnsafeForEach(PutChunk, func(k, v []byte) error {
+		if chunkExpired(v, t) {
+			found = true
+			return errStopIteration
+		}
+		return nil
+	})
+	return found
+}
+
+var errStopIteration = errors.New("stop iteration")
+
+func chunkExpired(v []byte, t int64) bool {
+	return len(v) < 8 || int64(binary.BigEndian.Uint64(v)) <= t
+}
+
+func putChunkKey(uploadID uint64, index int64) []byte {
+	k := make([]byte, 16)
+	binary.BigEndian.PutUint64(k, uploadID)
+	binary.BigEndian.PutUint64(k[8:], uint64(index))
+	return k
+}
@@ -0,0 +1,52 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+)
+
+func TestPutChunkExpiry(t *testing.T) {
+	be, _ := betesting.NewTmpBackend(t, time.Microsecond, 10)
+	defer betesting.Close(t, be)
+
+	tx := be.BatchTx()
+	tx.Lock()
+	UnsafeCreatePutChunkBucket(tx)
+	UnsafePutChunk(tx, 1, 0, 100, []byte("a"))
+	UnsafePutChunk(tx, 1, 1, 100, []byte("b"))
+	UnsafePutChunk(tx, 2, 0, 200, []byte("c"))
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b")}, UnsafeReadChunks(tx, 1, 2))
+	tx.Unlock()
+	be.ForceCommit()
+
+	assert.False(t, HasExpiredChunks(be.ReadTx(), 99))
+	assert.True(t, HasExpiredChunks(be.ReadTx(), 100))
+
+	tx.Lock()
+	assert.Equal(t, 0, UnsafeDeleteExpiredChunks(tx, 99))
+	assert.Equal(t, 2, UnsafeDeleteExpiredChunks(tx, 150))
+	assert.Empty(t, UnsafeReadChunks(tx, 1, 2))
+	assert.Equal(t, [][]byte{[]byte("c")}, UnsafeReadChunks(tx, 2, 1))
+	tx.Unlock()
+	be.ForceCommit()
+
+	assert.False(t, HasExpiredChunks(be.ReadTx(), 150))
+	assert.True(t, HasExpiredChunks(be.ReadTx(), 200))
+}
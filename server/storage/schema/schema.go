@@ -43,8 +43,43 @@ func unsafeValidate(lg *zap.Logger, tx backend.ReadTx) error {
 		lg.Warn("Failed to detect storage schema version. Please wait till wal snapshot before upgrading cluster.")
 		return nil
 	}
-	_, err = newPlan(lg, current, localBinaryVersion())
-	return err
+	binary := localBinaryVersion()
+	_, err = newPlan(lg, current, binary)
+	if err != nil {
+		return &VersionMismatchError{StorageVersion: current, BinaryVersion: binary}
+	}
+	return nil
+}
+
+// VersionMismatchError is returned when the storage version of an existing
+// data dir is not supported by the running etcd binary.
+type VersionMismatchError struct {
+	StorageVersion semver.Version
+	BinaryVersion  semver.Version
+}
+
+func (e *VersionMismatchError) Error() string {
+	return fmt.Sprintf("storage version %s of the data dir is not supported by etcd v%d.%d: %s",
+		e.StorageVersion.String(), e.BinaryVersion.Major, e.BinaryVersion.Minor, e.Resolution())
+}
+
+// Resolution describes whether a migration path exists between the storage
+// version and the binary version, and how to follow it.
+func (e *VersionMismatchError) Resolution() string {
+	storage, binary := e.StorageVersion, e.BinaryVersion
+	switch {
+	case storage.Major != binary.Major:
+		return "changing major storage version is not supported, no migration path exists"
+	case binary.LessThan(storage) && storage.Minor == binary.Minor+1:
+		return fmt.Sprintf("data dir was written by a newer etcd, downgrade it first by running `etcdutl migrate --target-version %d.%d` from etcd v%d.%d",
+			binary.Major, binary.Minor, storage.Major, storage.Minor)
+	case binary.LessThan(storage):
+		return fmt.Sprintf("data dir was written by a newer etcd, it can only be downgraded one minor version at a time using `etcdutl migrate` starting from etcd v%d.%d",
+			storage.Major, storage.Minor)
+	default:
+		return fmt.Sprintf("data dir was written by an older etcd, upgrade one minor version at a time starting from etcd v%d.%d",
+			storage.Major, storage.Minor+1)
+	}
 }
 
 func localBinaryVersion() semver.Version {
@@ -78,7 +78,7 @@ func TestValidate(t *testing.T) {
 			name:           `V3.7 schema is unknown and should return error`,
 			version:        V3_7,
 			expectError:    true,
-			expectErrorMsg: `version "3.7.0" is not supported`,
+			expectErrorMsg: "storage version 3.7.0 of the data dir is not supported by etcd v3.6: data dir was written by a newer etcd, downgrade it first by running `etcdutl migrate --target-version 3.6` from etcd v3.7",
 		},
 	}
 	for _, tc := range tcs {
@@ -99,6 +99,46 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestVersionMismatchErrorResolution(t *testing.T) {
+	tcs := []struct {
+		name    string
+		storage semver.Version
+		binary  semver.Version
+		expect  string
+	}{
+		{
+			name:    "Major version change has no migration path",
+			storage: semver.Version{Major: 4, Minor: 0},
+			binary:  V3_6,
+			expect:  "changing major storage version is not supported, no migration path exists",
+		},
+		{
+			name:    "Storage one minor version ahead can be downgraded with etcdutl",
+			storage: V3_7,
+			binary:  V3_6,
+			expect:  "data dir was written by a newer etcd, downgrade it first by running `etcdutl migrate --target-version 3.6` from etcd v3.7",
+		},
+		{
+			name:    "Storage two minor versions ahead needs stepwise downgrade",
+			storage: semver.Version{Major: 3, Minor: 8},
+			binary:  V3_6,
+			expect:  "data dir was written by a newer etcd, it can only be downgraded one minor version at a time using `etcdutl migrate` starting from etcd v3.8",
+		},
+		{
+			name:    "Storage too old needs stepwise upgrade",
+			storage: V3_4,
+			binary:  V3_6,
+			expect:  "data dir was written by an older etcd, upgrade one minor version at a time starting from etcd v3.5",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			err := &VersionMismatchError{StorageVersion: tc.storage, BinaryVersion: tc.binary}
+			assert.Equal(t, tc.expect, err.Resolution())
+		})
+	}
+}
+
 func TestMigrate(t *testing.T) {
 	tcs := []struct {
 		name    string
@@ -0,0 +1,51 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/binary"
+
+	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+)
+
+// UnsafeCreateWALArchiveBucket creates the bucket of the WAL archiving
+// progress. Older versions of etcd ignore it.
+func UnsafeCreateWALArchiveBucket(tx backend.BatchTx) {
+	tx.UnsafeCreateBucket(WALArchive)
+}
+
+// UnsafeSaveWALArchived records name as the last WAL segment of member id
+// archived. The progress is recorded by member, as the database is sent to
+// the other members in the snapshots.
+func UnsafeSaveWALArchived(tx backend.BatchTx, id types.ID, name string) {
+	tx.UnsafePut(WALArchive, walArchiveKey(id), []byte(name))
+}
+
+// UnsafeReadWALArchived returns the name of the last WAL segment of member
+// id archived, empty if none is.
+func UnsafeReadWALArchived(tx backend.ReadTx, id types.ID) string {
+	_, vs := tx.UnsafeRange(WALArchive, walArchiveKey(id), nil, 0)
+	if len(vs) == 0 {
+		return ""
+	}
+	return string(vs[0])
+}
+
+func walArchiveKey(id types.ID) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(id))
+	return k
+}
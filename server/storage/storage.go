@@ -16,6 +16,7 @@ package storage
 
 import (
 	"sync"
+	"time"
 
 	"github.com/coreos/go-semver/semver"
 	"go.etcd.io/etcd/raft/v3/raftpb"
@@ -54,6 +55,13 @@ func NewStorage(lg *zap.Logger, w *wal.WAL, s *snap.Snapshotter) Storage {
 	return &storage{lg: lg, w: w, s: s}
 }
 
+// LastSync returns the start time and duration of the last fsync of the WAL.
+func (st *storage) LastSync() (time.Time, time.Duration) {
+	st.mux.RLock()
+	defer st.mux.RUnlock()
+	return st.w.LastSync()
+}
+
 // SaveSnap saves the snapshot file to disk and writes the WAL snapshot entry.
 func (st *storage) SaveSnap(snap raftpb.Snapshot) error {
 	st.mux.RLock()
@@ -111,7 +111,7 @@ func CreateConfigChangeEnts(lg *zap.Logger, ids []uint64, self uint64, term, ind
 
 // GetEffectiveNodeIDsFromWalEntries returns an ordered set of IDs included in the given snapshot and
 // the entries. The given snapshot/entries can contain three kinds of
-// ID-related entry:
+// ID-related entry, alone or in a ConfChangeV2:
 // - ConfChangeAddNode, in which case the contained ID will Be added into the set.
 // - ConfChangeRemoveNode, in which case the contained ID will Be removed from the set.
 // - ConfChangeAddLearnerNode, in which the contained ID will Be added into the set.
@@ -123,22 +123,32 @@ func GetEffectiveNodeIDsFromWalEntries(lg *zap.Logger, snap *raftpb.Snapshot, en
 		}
 	}
 	for _, e := range ents {
-		if e.Type != raftpb.EntryConfChange {
+		var ccs []raftpb.ConfChangeSingle
+		switch e.Type {
+		case raftpb.EntryConfChange:
+			var cc raftpb.ConfChange
+			pbutil.MustUnmarshal(&cc, e.Data)
+			ccs = cc.AsV2().Changes
+		case raftpb.EntryConfChangeV2:
+			var cc raftpb.ConfChangeV2
+			pbutil.MustUnmarshal(&cc, e.Data)
+			ccs = cc.Changes
+		default:
 			continue
 		}
-		var cc raftpb.ConfChange
-		pbutil.MustUnmarshal(&cc, e.Data)
-		switch cc.Type {
-		case raftpb.ConfChangeAddLearnerNode:
-			ids[cc.NodeID] = true
-		case raftpb.ConfChangeAddNode:
-			ids[cc.NodeID] = true
-		case raftpb.ConfChangeRemoveNode:
-			delete(ids, cc.NodeID)
-		case raftpb.ConfChangeUpdateNode:
-			// do nothing
-		default:
-			lg.Panic("unknown ConfChange Type", zap.String("type", cc.Type.String()))
+		for _, cc := range ccs {
+			switch cc.Type {
+			case raftpb.ConfChangeAddLearnerNode:
+				ids[cc.NodeID] = true
+			case raftpb.ConfChangeAddNode:
+				ids[cc.NodeID] = true
+			case raftpb.ConfChangeRemoveNode:
+				delete(ids, cc.NodeID)
+			case raftpb.ConfChangeUpdateNode:
+				// do nothing
+			default:
+				lg.Panic("unknown ConfChange Type", zap.String("type", cc.Type.String()))
+			}
 		}
 	}
 	sids := make(types.Uint64Slice, 0, len(ids))
@@ -0,0 +1,116 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package archive copies the completed WAL segments to a sink, so that the
+// history of a member can be replayed beyond its snapshots.
+package archive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+)
+
+// Sink stores the archived WAL segments.
+type Sink interface {
+	// Archive stores the WAL segment of the file at path as name, a
+	// slash-separated relative path. Archiving the same segment again
+	// replaces it.
+	Archive(ctx context.Context, name, path string) error
+}
+
+// NewSink returns the sink described by spec:
+//
+//	file:///path/to/dir                  stores the segments in a directory
+//	s3://bucket/prefix?endpoint=<url>    puts the segments to an S3-compatible endpoint
+//	exec:/path/to/command [args...]      runs a command with the segment path and name appended
+//
+// The S3 sink takes an optional region query parameter, "us-east-1" by
+// default, and reads its credentials from the AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and optional AWS_SESSION_TOKEN environment
+// variables.
+func NewSink(spec string) (Sink, error) {
+	switch {
+	case strings.HasPrefix(spec, "file://"):
+		dir := strings.TrimPrefix(spec, "file://")
+		if !filepath.IsAbs(dir) {
+			return nil, fmt.Errorf("archive: %q: the directory must be an absolute path", spec)
+		}
+		return &fileSink{dir: dir}, nil
+	case strings.HasPrefix(spec, "s3://"):
+		return newS3Sink(spec)
+	case strings.HasPrefix(spec, "exec:"):
+		args := strings.Fields(strings.TrimPrefix(spec, "exec:"))
+		if len(args) == 0 {
+			return nil, fmt.Errorf("archive: %q: no command", spec)
+		}
+		return &execSink{args: args}, nil
+	}
+	return nil, fmt.Errorf("archive: unknown sink %q (expected file://, s3:// or exec:)", spec)
+}
+
+// fileSink stores the segments in a directory.
+type fileSink struct {
+	dir string
+}
+
+func (s *fileSink) Archive(ctx context.Context, name, path string) error {
+	dst := filepath.Join(s.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(dst), fileutil.PrivateDirMode); err != nil {
+		return err
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	// the segment appears complete or not at all
+	tmp := dst + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fileutil.PrivateFileMode)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, src); err == nil {
+		err = fileutil.Fsync(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+// execSink runs a command to archive each segment.
+type execSink struct {
+	args []string
+}
+
+func (s *execSink) Archive(ctx context.Context, name, path string) error {
+	args := append(append([]string{}, s.args[1:]...), path, name)
+	out, err := exec.CommandContext(ctx, s.args[0], args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("archive: %s: %v: %s", s.args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSegment = "0000000000000001-0000000000000010.wal"
+
+func writeSegment(t *testing.T) string {
+	p := filepath.Join(t.TempDir(), testSegment)
+	if err := os.WriteFile(p, []byte("segment data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestNewSink(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "id")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	for _, spec := range []string{
+		"file:///var/lib/etcd-archive",
+		"s3://bucket/prefix?endpoint=https://s3.example.com&region=eu-west-1",
+		"exec:/usr/local/bin/archive-wal --quiet",
+	} {
+		if _, err := NewSink(spec); err != nil {
+			t.Errorf("%q: unexpected error %v", spec, err)
+		}
+	}
+	for _, spec := range []string{
+		"",
+		"/var/lib/etcd-archive",
+		"file://relative/dir",
+		"s3://bucket/prefix",
+		"s3://bucket?endpoint=ftp://s3.example.com",
+		"exec:",
+	} {
+		if _, err := NewSink(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	if _, err := NewSink("s3://bucket?endpoint=https://s3.example.com"); err == nil {
+		t.Error("expected error without credentials")
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewSink("file://" + dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Archive(context.TODO(), "8e9e05c52164694d/"+testSegment, writeSegment(t)); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "8e9e05c52164694d", testSegment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "segment data" {
+		t.Errorf("archived %q, want %q", b, "segment data")
+	}
+}
+
+func TestExecSink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a shell")
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	script := filepath.Join(t.TempDir(), "archive.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$1 $2 $3\" > \"$1\"\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSink("exec:" + script + " " + out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := writeSegment(t)
+	if err = s.Archive(context.TODO(), "id/"+testSegment, p); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := out + " " + p + " id/" + testSegment + "\n"; string(b) != want {
+		t.Errorf("command arguments %q, want %q", b, want)
+	}
+
+	s, _ = NewSink("exec:/bin/false")
+	if err = s.Archive(context.TODO(), "id/"+testSegment, p); err == nil {
+		t.Error("expected error on command failure")
+	}
+}
+
+func TestS3Sink(t *testing.T) {
+	var (
+		method, path, auth, hash string
+		body                     []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		auth, hash = r.Header.Get("Authorization"), r.Header.Get("x-amz-content-sha256")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	s, err := NewSink("s3://bucket/etcd/wal?endpoint=" + srv.URL + "&region=eu-west-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.(*s3Sink).now = func() time.Time { return time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC) }
+	if err = s.Archive(context.TODO(), "id/"+testSegment, writeSegment(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	if method != http.MethodPut || path != "/bucket/etcd/wal/id/"+testSegment {
+		t.Errorf("request %s %s, want PUT /bucket/etcd/wal/id/%s", method, path, testSegment)
+	}
+	if string(body) != "segment data" {
+		t.Errorf("body %q, want %q", body, "segment data")
+	}
+	sum := sha256.Sum256([]byte("segment data"))
+	if hash != hex.EncodeToString(sum[:]) {
+		t.Errorf("x-amz-content-sha256 %q, want the hash of the body", hash)
+	}
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20220501/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") {
+		t.Errorf("authorization %q", auth)
+	}
+}
+
+func TestURIEncode(t *testing.T) {
+	if got, want := uriEncode("/bucket/a b/c+d~e"), "/bucket/a%20b/c%2Bd~e"; got != want {
+		t.Errorf("uriEncode = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"fmt"
+	"sync"
+
+	"go.etcd.io/etcd/server/v3/storage/encryption"
+	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// CompressionNone writes the entries as is.
+	CompressionNone = "none"
+	// CompressionZstd writes the entries compressed with zstd, record by record.
+	CompressionZstd = "zstd"
+
+	// entryCompressionThreshold is the size in bytes of a marshaled entry
+	// from which it is compressed; the smaller ones hardly compress.
+	entryCompressionThreshold = 512
+)
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+func zstdCodec() (*zstd.Encoder, *zstd.Decoder) {
+	zstdOnce.Do(func() {
+		// both never fail with these options
+		zstdEncoder, _ = zstd.NewWriter(nil)
+		zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(maxWALEntrySizeLimit)))
+	})
+	return zstdEncoder, zstdDecoder
+}
+
+// ValidCompression returns an error if c is not a WAL compression.
+func ValidCompression(c string) error {
+	switch c {
+	case "", CompressionNone, CompressionZstd:
+		return nil
+	}
+	return fmt.Errorf("unknown WAL compression %q (expected %q or %q)", c, CompressionNone, CompressionZstd)
+}
+
+// compressEntry returns the record to write for the marshaled entry b: a
+// compressedEntryType record if the entry is compressed, an entryType one
+// if it is under the threshold or does not compress.
+func compressEntry(b []byte) *walpb.Record {
+	if len(b) < entryCompressionThreshold {
+		return &walpb.Record{Type: entryType, Data: b}
+	}
+	enc, _ := zstdCodec()
+	c := enc.EncodeAll(b, make([]byte, 0, len(b)))
+	if len(c) >= len(b) {
+		return &walpb.Record{Type: entryType, Data: b}
+	}
+	walEntryUncompressedBytes.Add(float64(len(b)))
+	walEntryCompressedBytes.Add(float64(len(c)))
+	return &walpb.Record{Type: compressedEntryType, Data: c}
+}
+
+// entryData returns the marshaled entry of the entryType,
+// compressedEntryType or encryptedEntryType record rec, dek being the key
+// of the last keyType record read.
+func entryData(rec *walpb.Record, dek *encryption.DataKey) ([]byte, error) {
+	if rec.Type == encryptedEntryType {
+		var err error
+		if rec, err = decryptRecord(dek, rec); err != nil {
+			return nil, err
+		}
+	}
+	if rec.Type != compressedEntryType {
+		return rec.Data, nil
+	}
+	_, dec := zstdCodec()
+	b, err := dec.DecodeAll(rec.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("wal: failed to decompress entry: %v", err)
+	}
+	return b, nil
+}
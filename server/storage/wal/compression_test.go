@@ -0,0 +1,99 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCompressedEntries(t *testing.T) {
+	p := t.TempDir()
+	w, err := Create(zaptest.NewLogger(t), p, []byte("metadata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	big := bytes.Repeat([]byte("compressible "), 100)
+	ents := []raftpb.Entry{
+		{Index: 1, Term: 1, Data: big},
+		{Index: 2, Term: 1, Data: big},
+		{Index: 3, Term: 1, Data: []byte("small")},
+	}
+	state := raftpb.HardState{Term: 1, Commit: 4}
+	// the entries written before the compression is set are read as well
+	if err = w.Save(raftpb.HardState{}, ents[:1]); err != nil {
+		t.Fatal(err)
+	}
+	w.SetCompression(CompressionZstd)
+	if err = w.Save(state, ents[1:]); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	f, err := os.Open(filepath.Join(p, walName(0, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var types []int64
+	d := newDecoder(f)
+	rec := &walpb.Record{}
+	for err = d.decode(rec); err == nil; err = d.decode(rec) {
+		if rec.Type == entryType || rec.Type == compressedEntryType {
+			types = append(types, rec.Type)
+		}
+	}
+	if err != io.EOF {
+		t.Fatal(err)
+	}
+	if wtypes := []int64{entryType, compressedEntryType, entryType}; !reflect.DeepEqual(types, wtypes) {
+		t.Errorf("entry record types = %v, want %v", types, wtypes)
+	}
+
+	w, err = Open(zaptest.NewLogger(t), p, walpb.Snapshot{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	metadata, st, rents, err := w.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(metadata) != "metadata" || !reflect.DeepEqual(st, state) {
+		t.Errorf("metadata = %q, state = %+v, want %q, %+v", metadata, st, "metadata", state)
+	}
+	if !reflect.DeepEqual(rents, ents) {
+		t.Errorf("entries = %+v, want %+v", rents, ents)
+	}
+}
+
+func TestValidCompression(t *testing.T) {
+	for _, c := range []string{"", CompressionNone, CompressionZstd} {
+		if err := ValidCompression(c); err != nil {
+			t.Errorf("%q: unexpected error %v", c, err)
+		}
+	}
+	if err := ValidCompression("lz4"); err == nil {
+		t.Error("lz4: expected error")
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DurabilityFsync fsyncs the WAL before the raft entries and state
+	// saved are acknowledged.
+	DurabilityFsync = "fsync"
+	// DurabilityBatched fsyncs the WAL on a timer: the entries and state
+	// saved since the last fsync are lost on a power failure. Unsafe.
+	DurabilityBatched = "batched"
+	// DurabilityNone never fsyncs the WAL, leaving it to the OS. Unsafe.
+	DurabilityNone = "none"
+)
+
+// ValidDurability returns an error if d is not a WAL durability.
+func ValidDurability(d string) error {
+	switch d {
+	case "", DurabilityFsync, DurabilityBatched, DurabilityNone:
+		return nil
+	}
+	return fmt.Errorf("unknown WAL durability %q (expected %q, %q or %q)", d, DurabilityFsync, DurabilityBatched, DurabilityNone)
+}
+
+// SetDurability sets the durability of the writes, one of DurabilityFsync,
+// DurabilityBatched and DurabilityNone. With DurabilityBatched, the WAL is
+// fsynced every interval if written since the last fsync. It must be set
+// before the WAL is written.
+func (w *WAL) SetDurability(durability string, interval time.Duration) {
+	switch durability {
+	case DurabilityNone:
+		w.SetUnsafeNoFsync()
+	case DurabilityBatched:
+		if w.syncStopc != nil || interval <= 0 {
+			return
+		}
+		w.syncInterval = interval
+		w.syncStopc, w.syncDonec = make(chan struct{}), make(chan struct{})
+		go w.syncLoop(interval, w.syncStopc, w.syncDonec)
+	}
+}
+
+// syncLoop fsyncs the WAL every interval if written since the last fsync,
+// until stopc is closed.
+func (w *WAL) syncLoop(interval time.Duration, stopc <-chan struct{}, donec chan<- struct{}) {
+	defer close(donec)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-stopc:
+			return
+		}
+		w.mu.Lock()
+		if w.unsynced && w.tail() != nil {
+			if err := w.sync(); err != nil {
+				w.lg.Warn("failed to fsync WAL", zap.Error(err))
+			}
+		}
+		w.mu.Unlock()
+	}
+}
+
+// stopSyncLoop stops the loop fsyncing the WAL, if started.
+func (w *WAL) stopSyncLoop() {
+	if w.syncStopc == nil {
+		return
+	}
+	close(w.syncStopc)
+	<-w.syncDonec
+	w.syncStopc, w.syncDonec = nil, nil
+}
+
+// flushUnsynced flushes the records encoded to the tail segment, leaving
+// the fsync to the sync loop.
+func (w *WAL) flushUnsynced() error {
+	if err := w.encoder.flush(); err != nil {
+		return err
+	}
+	if w.uw != nil {
+		if err := w.uw.flush(); err != nil {
+			return err
+		}
+	}
+	w.unsynced = true
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBatchedDurability(t *testing.T) {
+	p := t.TempDir()
+	w, err := Create(zaptest.NewLogger(t), p, []byte("metadata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetDurability(DurabilityBatched, 10*time.Millisecond)
+
+	synced, _ := w.LastSync()
+	ents := []raftpb.Entry{{Index: 1, Term: 1, Data: []byte("a")}}
+	state := raftpb.HardState{Term: 1, Commit: 1}
+	if err = w.Save(state, ents); err != nil {
+		t.Fatal(err)
+	}
+	// the entries are fsynced by the sync loop, not by Save
+	if start, _ := w.LastSync(); !start.Equal(synced) {
+		t.Fatalf("expected Save not to fsync")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w.mu.Lock()
+		unsynced := w.unsynced
+		w.mu.Unlock()
+		if !unsynced {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the saved entries to be fsynced")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err = w.Save(state, []raftpb.Entry{{Index: 2, Term: 1, Data: []byte("b")}}); err != nil {
+		t.Fatal(err)
+	}
+	ents = append(ents, raftpb.Entry{Index: 2, Term: 1, Data: []byte("b")})
+	// Close stops the sync loop and fsyncs the last entries
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err = Open(zaptest.NewLogger(t), p, walpb.Snapshot{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _, rents, err := w.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rents, ents) {
+		t.Errorf("entries = %+v, want %+v", rents, ents)
+	}
+}
+
+func TestValidDurability(t *testing.T) {
+	for _, d := range []string{"", DurabilityFsync, DurabilityBatched, DurabilityNone} {
+		if err := ValidDurability(d); err != nil {
+			t.Errorf("%q: unexpected error %v", d, err)
+		}
+	}
+	if err := ValidDurability("async"); err == nil {
+		t.Error("async: expected error")
+	}
+}
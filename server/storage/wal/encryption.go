@@ -0,0 +1,48 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"errors"
+
+	"go.etcd.io/etcd/pkg/v3/pbutil"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
+	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
+)
+
+var ErrKeyNotRead = errors.New("wal: encrypted entry read before its key")
+
+// encryptRecord returns the encryptedEntryType record holding rec encrypted
+// with dek.
+func encryptRecord(dek *encryption.DataKey, rec *walpb.Record) *walpb.Record {
+	return &walpb.Record{Type: encryptedEntryType, Data: dek.Seal(pbutil.MustMarshal(rec))}
+}
+
+// decryptRecord returns the record encrypted in the encryptedEntryType
+// record rec with dek, the key of the last keyType record read.
+func decryptRecord(dek *encryption.DataKey, rec *walpb.Record) (*walpb.Record, error) {
+	if dek == nil {
+		return nil, ErrKeyNotRead
+	}
+	b, err := dek.Open(rec.Data)
+	if err != nil {
+		return nil, err
+	}
+	var inner walpb.Record
+	if err = inner.Unmarshal(b); err != nil {
+		return nil, err
+	}
+	return &inner, nil
+}
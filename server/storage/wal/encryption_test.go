@@ -0,0 +1,114 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
+	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
+	"go.uber.org/zap/zaptest"
+)
+
+func newTestKeyProvider(t *testing.T) encryption.KeyProvider {
+	p := filepath.Join(t.TempDir(), "keys")
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, encryption.KeySize))
+	if err := os.WriteFile(p, []byte("k1:"+key+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	kp, err := encryption.NewFileKeyProvider(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kp
+}
+
+func TestEncryptedEntries(t *testing.T) {
+	p := t.TempDir()
+	kp := newTestKeyProvider(t)
+	w, err := Create(zaptest.NewLogger(t), p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := bytes.Repeat([]byte("secret "), 100)
+	ents := []raftpb.Entry{
+		{Index: 1, Term: 1, Data: []byte("plain")},
+		{Index: 2, Term: 1, Data: secret},
+		{Index: 3, Term: 1, Data: secret},
+	}
+	state := raftpb.HardState{Term: 1, Commit: 3}
+	// the entries written before the key provider is set are read as well
+	if err = w.Save(raftpb.HardState{}, ents[:1]); err != nil {
+		t.Fatal(err)
+	}
+	w.SetKeyProvider(kp)
+	w.SetCompression(CompressionZstd)
+	if err = w.Save(raftpb.HardState{}, ents[1:2]); err != nil {
+		t.Fatal(err)
+	}
+	// the new segment has a key of its own
+	if err = w.cut(); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Save(state, ents[2:]); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	for _, name := range []string{walName(0, 0), walName(1, 3)} {
+		b, err := os.ReadFile(filepath.Join(p, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(b, []byte("secret")) {
+			t.Errorf("%s: the entries are not encrypted", name)
+		}
+	}
+
+	w, err = Open(zaptest.NewLogger(t), p, walpb.Snapshot{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err = w.ReadAll(); err != encryption.ErrNoKeyProvider {
+		t.Errorf("err = %v, want %v", err, encryption.ErrNoKeyProvider)
+	}
+	w.Close()
+
+	w, err = Open(zaptest.NewLogger(t), p, walpb.Snapshot{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.SetKeyProvider(kp)
+	_, st, rents, err := w.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(st, state) {
+		t.Errorf("state = %+v, want %+v", st, state)
+	}
+	if !reflect.DeepEqual(rents, ents) {
+		t.Errorf("entries = %+v, want %+v", rents, ents)
+	}
+	if _, err = Verify(zaptest.NewLogger(t), p, walpb.Snapshot{}); err != nil {
+		t.Errorf("verify: %v", err)
+	}
+}
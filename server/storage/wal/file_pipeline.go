@@ -32,6 +32,8 @@ type filePipeline struct {
 	dir string
 	// size of files to make, in bytes
 	size int64
+	// ahead is the number of files made ahead of Open
+	ahead int
 	// count number of files generated
 	count int
 
@@ -40,15 +42,20 @@ type filePipeline struct {
 	donec chan struct{}
 }
 
-func newFilePipeline(lg *zap.Logger, dir string, fileSize int64) *filePipeline {
+func newFilePipeline(lg *zap.Logger, dir string, fileSize int64, ahead int) *filePipeline {
 	if lg == nil {
 		lg = zap.NewNop()
 	}
+	if ahead < 1 {
+		ahead = 1
+	}
 	fp := &filePipeline{
 		lg:    lg,
 		dir:   dir,
 		size:  fileSize,
-		filec: make(chan *fileutil.LockedFile),
+		ahead: ahead,
+		// one file is held by run while the channel is full
+		filec: make(chan *fileutil.LockedFile, ahead-1),
 		errc:  make(chan error, 1),
 		donec: make(chan struct{}),
 	}
@@ -72,8 +79,8 @@ func (fp *filePipeline) Close() error {
 }
 
 func (fp *filePipeline) alloc() (f *fileutil.LockedFile, err error) {
-	// count % 2 so this file isn't the same as the one last published
-	fpath := filepath.Join(fp.dir, fmt.Sprintf("%d.tmp", fp.count%2))
+	// count % (ahead+1) so this file isn't the same as the one last published
+	fpath := filepath.Join(fp.dir, fmt.Sprintf("%d.tmp", fp.count%(fp.ahead+1)))
 	if f, err = fileutil.LockFile(fpath, os.O_CREATE|os.O_WRONLY, fileutil.PrivateFileMode); err != nil {
 		return nil, err
 	}
@@ -99,7 +106,15 @@ func (fp *filePipeline) run() {
 		case <-fp.donec:
 			os.Remove(f.Name())
 			f.Close()
-			return
+			for {
+				select {
+				case f = <-fp.filec:
+					os.Remove(f.Name())
+					f.Close()
+				default:
+					return
+				}
+			}
 		}
 	}
 }
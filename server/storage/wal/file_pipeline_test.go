@@ -16,7 +16,9 @@ package wal
 
 import (
 	"math"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"go.uber.org/zap/zaptest"
 )
@@ -24,7 +26,7 @@ import (
 func TestFilePipeline(t *testing.T) {
 	tdir := t.TempDir()
 
-	fp := newFilePipeline(zaptest.NewLogger(t), tdir, SegmentSizeBytes)
+	fp := newFilePipeline(zaptest.NewLogger(t), tdir, SegmentSizeBytes, 1)
 	defer fp.Close()
 
 	f, ferr := fp.Open()
@@ -34,10 +36,42 @@ func TestFilePipeline(t *testing.T) {
 	f.Close()
 }
 
+func TestFilePipelineAhead(t *testing.T) {
+	tdir := t.TempDir()
+
+	fp := newFilePipeline(zaptest.NewLogger(t), tdir, 4096, 3)
+	f, ferr := fp.Open()
+	if ferr != nil {
+		t.Fatal(ferr)
+	}
+	defer f.Close()
+
+	tmps := func() []string {
+		names, err := filepath.Glob(filepath.Join(tdir, "*.tmp"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return names
+	}
+	// the file opened and the 3 made ahead
+	for i := 0; len(tmps()) != 4; i++ {
+		if i == 100 {
+			t.Fatalf("tmp files = %v, want 4", tmps())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := fp.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if names := tmps(); len(names) != 1 || names[0] != f.Name() {
+		t.Errorf("tmp files after close = %v, want [%s]", names, f.Name())
+	}
+}
+
 func TestFilePipelineFailPreallocate(t *testing.T) {
 	tdir := t.TempDir()
 
-	fp := newFilePipeline(zaptest.NewLogger(t), tdir, math.MaxInt64)
+	fp := newFilePipeline(zaptest.NewLogger(t), tdir, math.MaxInt64, 1)
 	defer fp.Close()
 
 	f, ferr := fp.Open()
@@ -49,7 +83,7 @@ func TestFilePipelineFailPreallocate(t *testing.T) {
 func TestFilePipelineFailLockFile(t *testing.T) {
 	tdir := t.TempDir()
 
-	fp := newFilePipeline(zaptest.NewLogger(t), tdir, math.MaxInt64)
+	fp := newFilePipeline(zaptest.NewLogger(t), tdir, math.MaxInt64, 1)
 	defer fp.Close()
 
 	f, ferr := fp.Open()
@@ -34,9 +34,25 @@ var (
 		Name:      "wal_write_bytes_total",
 		Help:      "Total number of bytes written in WAL.",
 	})
+
+	walEntryUncompressedBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "disk",
+		Name:      "wal_entry_uncompressed_bytes_total",
+		Help:      "Total number of bytes of the WAL entries compressed, before compression.",
+	})
+
+	walEntryCompressedBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "disk",
+		Name:      "wal_entry_compressed_bytes_total",
+		Help:      "Total number of bytes of the WAL entries compressed, after compression.",
+	})
 )
 
 func init() {
 	prometheus.MustRegister(walFsyncSec)
 	prometheus.MustRegister(walWriteBytes)
+	prometheus.MustRegister(walEntryUncompressedBytes)
+	prometheus.MustRegister(walEntryCompressedBytes)
 }
@@ -0,0 +1,226 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/pkg/v3/pbutil"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
+	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
+
+	"go.uber.org/zap"
+)
+
+// Record is a record read by Scan.
+type Record struct {
+	// Segment is the name of the segment holding the record, Offset the
+	// offset of the record in the segment and End the offset following it.
+	Segment string
+	Offset  int64
+	End     int64
+
+	// Entry, State or Snapshot is set, according to the type of the record.
+	Entry    *raftpb.Entry
+	State    *raftpb.HardState
+	Snapshot *walpb.Snapshot
+}
+
+// Corruption locates the first record of a WAL that cannot be read.
+type Corruption struct {
+	Segment string
+	Offset  int64
+	Err     error
+}
+
+func (c *Corruption) Error() string {
+	return fmt.Sprintf("wal: %s at offset %d: %v", c.Segment, c.Offset, c.Err)
+}
+
+// Scan reads the segments of the WAL in dirpath in order, and passes its
+// entries, hard states and snapshots to fn, up to the first record that
+// cannot be read. It returns the location of that record, nil if the whole
+// WAL was read. kp unwraps the keys of the encrypted entries, if any.
+func Scan(lg *zap.Logger, dirpath string, kp encryption.KeyProvider, fn func(Record) error) (*Corruption, error) {
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	names, err := readWALNames(lg, dirpath)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		prevSeq uint64
+		prevCrc uint32
+	)
+	for i, name := range names {
+		seq, _, _ := parseWALName(name)
+		if i > 0 && seq != prevSeq+1 {
+			return &Corruption{Segment: name, Err: fmt.Errorf("segment %d follows segment %d", seq, prevSeq)}, nil
+		}
+		prevSeq = seq
+
+		var c *Corruption
+		if prevCrc, c, err = scanSegment(filepath.Join(dirpath, name), prevCrc, kp, fn); c != nil || err != nil {
+			return c, err
+		}
+	}
+	return nil, nil
+}
+
+// scanSegment scans the segment at path, chaining its crc from prevCrc, and
+// returns its last crc.
+func scanSegment(path string, prevCrc uint32, kp encryption.KeyProvider, fn func(Record) error) (uint32, *Corruption, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+
+	name := filepath.Base(path)
+	d := newDecoder(f)
+	var dek *encryption.DataKey
+	for {
+		off := d.lastOffset()
+		rec := &walpb.Record{}
+		err = d.decode(rec)
+		if err == io.EOF {
+			return d.crc.Sum32(), nil, nil
+		}
+		corrupted := func(err error) (uint32, *Corruption, error) {
+			return 0, &Corruption{Segment: name, Offset: off, Err: err}, nil
+		}
+		if err != nil {
+			return corrupted(err)
+		}
+
+		r := Record{Segment: name, Offset: off, End: d.lastOffset()}
+		switch rec.Type {
+		case crcType:
+			if prevCrc != 0 && rec.Validate(prevCrc) != nil {
+				return corrupted(ErrCRCMismatch)
+			}
+			d.updateCRC(rec.Crc)
+			continue
+
+		case keyType:
+			if dek, err = encryption.OpenDataKey(kp, rec.Data); err != nil {
+				return corrupted(err)
+			}
+			continue
+
+		case entryType, compressedEntryType, encryptedEntryType:
+			b, derr := entryData(rec, dek)
+			if derr != nil {
+				return corrupted(derr)
+			}
+			var e raftpb.Entry
+			if err = e.Unmarshal(b); err != nil {
+				return corrupted(err)
+			}
+			r.Entry = &e
+
+		case stateType:
+			var st raftpb.HardState
+			if err = st.Unmarshal(rec.Data); err != nil {
+				return corrupted(err)
+			}
+			r.State = &st
+
+		case snapshotType:
+			var snap walpb.Snapshot
+			pbutil.MustUnmarshal(&snap, rec.Data)
+			r.Snapshot = &snap
+
+		case metadataType:
+			continue
+
+		default:
+			return corrupted(fmt.Errorf("unexpected block type %d", rec.Type))
+		}
+		if err = fn(r); err != nil {
+			return 0, nil, err
+		}
+	}
+}
+
+// Truncate truncates the WAL in dirpath at offset end of the segment, and
+// removes the segments following it. The segments changed are kept with the
+// .broken suffix, as Repair does.
+func Truncate(lg *zap.Logger, dirpath, segment string, end int64) error {
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	names, err := readWALNames(lg, dirpath)
+	if err != nil {
+		return err
+	}
+	i := 0
+	for i < len(names) && names[i] != segment {
+		i++
+	}
+	if i == len(names) {
+		return ErrFileNotFound
+	}
+
+	for _, name := range names[i+1:] {
+		p := filepath.Join(dirpath, name)
+		if err = os.Rename(p, p+".broken"); err != nil {
+			return err
+		}
+		lg.Info("removed WAL segment", zap.String("path", p), zap.String("backup", p+".broken"))
+	}
+
+	p := filepath.Join(dirpath, segment)
+	f, err := fileutil.LockFile(p, os.O_RDWR, fileutil.PrivateFileMode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	bf, err := os.Create(p + ".broken")
+	if err != nil {
+		return err
+	}
+	defer bf.Close()
+	if _, err = io.Copy(bf, f); err != nil {
+		return err
+	}
+	if err = f.Truncate(end); err != nil {
+		return err
+	}
+	start := time.Now()
+	if err = fileutil.Fsync(f.File); err != nil {
+		return err
+	}
+	walFsyncSec.Observe(time.Since(start).Seconds())
+
+	df, err := fileutil.OpenDir(dirpath)
+	if err != nil {
+		return err
+	}
+	defer df.Close()
+	if err = fileutil.Fsync(df); err != nil {
+		return err
+	}
+	lg.Info("truncated WAL segment", zap.String("path", p), zap.Int64("offset", end), zap.String("backup", p+".broken"))
+	return nil
+}
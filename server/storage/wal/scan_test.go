@@ -0,0 +1,109 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestScanAndTruncate(t *testing.T) {
+	p := t.TempDir()
+	w, err := Create(zaptest.NewLogger(t), p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(1); i <= 10; i++ {
+		if err = w.Save(raftpb.HardState{Term: 1, Commit: i - 1}, []raftpb.Entry{{Index: i, Term: 1, Data: make([]byte, 100)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+
+	var recs []Record
+	scan := func() *Corruption {
+		recs = nil
+		c, err := Scan(zaptest.NewLogger(t), p, nil, func(r Record) error {
+			recs = append(recs, r)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	if c := scan(); c != nil {
+		t.Fatalf("unexpected corruption %v", c)
+	}
+	// the snapshot, then an entry and a state per save
+	if len(recs) != 21 || recs[0].Snapshot == nil || recs[1].Entry == nil || recs[2].State == nil {
+		t.Fatalf("records = %+v", recs)
+	}
+
+	// corrupt the data of entry 6
+	var ent6 Record
+	for _, r := range recs {
+		if r.Entry != nil && r.Entry.Index == 6 {
+			ent6 = r
+		}
+	}
+	seg := filepath.Join(p, ent6.Segment)
+	b, err := os.ReadFile(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[ent6.End-10] ^= 0xff
+	if err = os.WriteFile(seg, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	c := scan()
+	if c == nil || c.Segment != ent6.Segment || c.Offset != ent6.Offset {
+		t.Fatalf("corruption = %v, want at offset %d", c, ent6.Offset)
+	}
+	if last := recs[len(recs)-1]; last.State == nil || last.State.Commit != 4 {
+		t.Errorf("last record read = %+v, want the state following entry 5", last)
+	}
+
+	// truncate after entry 4
+	var ent4 Record
+	for _, r := range recs {
+		if r.Entry != nil && r.Entry.Index == 4 {
+			ent4 = r
+		}
+	}
+	if err = Truncate(zaptest.NewLogger(t), p, ent4.Segment, ent4.End); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(seg + ".broken"); err != nil {
+		t.Errorf("expected a backup of the segment: %v", err)
+	}
+	w, err = Open(zaptest.NewLogger(t), p, walpb.Snapshot{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	_, _, ents, err := w.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ents) != 4 || ents[3].Index != 4 {
+		t.Errorf("read %d entries after truncation, want 4", len(ents))
+	}
+}
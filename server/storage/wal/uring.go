@@ -0,0 +1,78 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// uringEntries is the size of the submission queue, which bounds the writes
+// submitted along with an fdatasync.
+const uringEntries = 32
+
+var errIOURingNotSupported = errors.New("wal: io_uring is not supported on this platform")
+
+// uringWriter appends to the tail segment through io_uring. The writes are
+// queued until the WAL is synced, and then submitted with the fdatasync
+// linked after them, so that a sync takes a single system call.
+type uringWriter struct {
+	ring *ioURing
+	f    *os.File
+	off  int64 // offset of the next write
+	bufs [][]byte
+}
+
+func newURingWriter(ring *ioURing, f *os.File, off int64) *uringWriter {
+	return &uringWriter{ring: ring, f: f, off: off}
+}
+
+func (uw *uringWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	// the caller may reuse p
+	uw.bufs = append(uw.bufs, append([]byte(nil), p...))
+	if len(uw.bufs) >= uringEntries-1 {
+		if err := uw.submit(false); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// flush submits the queued writes without syncing them.
+func (uw *uringWriter) flush() error { return uw.submit(false) }
+
+// sync submits the queued writes followed by an fdatasync.
+func (uw *uringWriter) sync() error { return uw.submit(true) }
+
+func (uw *uringWriter) submit(datasync bool) error {
+	if len(uw.bufs) == 0 && !datasync {
+		return nil
+	}
+	if err := uw.ring.writeAt(uw.f, uw.off, uw.bufs, datasync); err != nil {
+		return err
+	}
+	for i, b := range uw.bufs {
+		uw.off += int64(len(b))
+		uw.bufs[i] = nil
+	}
+	uw.bufs = uw.bufs[:0]
+	// the WAL relies on the file offset to cut the segments
+	_, err := uw.f.Seek(uw.off, io.SeekStart)
+	return err
+}
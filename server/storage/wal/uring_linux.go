@@ -0,0 +1,244 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package wal
+
+import (
+	"os"
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+
+	"golang.org/x/sys/unix"
+)
+
+// the io_uring ABI, see include/uapi/linux/io_uring.h
+const (
+	uringOffSQRing = 0
+	uringOffCQRing = 0x8000000
+	uringOffSQEs   = 0x10000000
+
+	uringOpWritev = 2
+	uringOpFsync  = 3
+
+	uringSQELink        = 1 << 2
+	uringFsyncDatasync  = 1
+	uringEnterGetEvents = 1
+)
+
+type uringSQOffsets struct {
+	head, tail, ringMask, ringEntries, flags, dropped, array, resv1 uint32
+	resv2                                                           uint64
+}
+
+type uringCQOffsets struct {
+	head, tail, ringMask, ringEntries, overflow, cqes, flags, resv1 uint32
+	resv2                                                           uint64
+}
+
+type uringParams struct {
+	sqEntries, cqEntries, flags, sqThreadCPU, sqThreadIdle, features, wqFd uint32
+	resv                                                                   [3]uint32
+	sqOff                                                                  uringSQOffsets
+	cqOff                                                                  uringCQOffsets
+}
+
+type uringSQE struct {
+	opcode   uint8
+	flags    uint8
+	ioprio   uint16
+	fd       int32
+	off      uint64
+	addr     uint64
+	len      uint32
+	opFlags  uint32
+	userData uint64
+	pad      [3]uint64
+}
+
+type uringCQE struct {
+	userData uint64
+	res      int32
+	flags    uint32
+}
+
+// ioURing is an io_uring instance, used by one goroutine at a time.
+type ioURing struct {
+	fd int
+
+	sqRing, cqRing, sqeMem []byte
+
+	sqTail  *uint32
+	sqMask  uint32
+	sqArray []uint32
+	sqes    []uringSQE
+
+	cqHead, cqTail *uint32
+	cqMask         uint32
+	cqes           []uringCQE
+}
+
+func newIOURing(entries uint32) (r *ioURing, err error) {
+	var p uringParams
+	fd, _, errno := unix.Syscall(unix.SYS_IO_URING_SETUP, uintptr(entries), uintptr(unsafe.Pointer(&p)), 0)
+	if errno != 0 {
+		return nil, os.NewSyscallError("io_uring_setup", errno)
+	}
+	r = &ioURing{fd: int(fd)}
+	defer func() {
+		if err != nil {
+			r.Close()
+		}
+	}()
+
+	prot, flags := unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED|unix.MAP_POPULATE
+	if r.sqRing, err = unix.Mmap(r.fd, uringOffSQRing, int(p.sqOff.array+p.sqEntries*4), prot, flags); err != nil {
+		return nil, err
+	}
+	cqeSize := uint32(unsafe.Sizeof(uringCQE{}))
+	if r.cqRing, err = unix.Mmap(r.fd, uringOffCQRing, int(p.cqOff.cqes+p.cqEntries*cqeSize), prot, flags); err != nil {
+		return nil, err
+	}
+	sqeSize := int(unsafe.Sizeof(uringSQE{}))
+	if r.sqeMem, err = unix.Mmap(r.fd, uringOffSQEs, int(p.sqEntries)*sqeSize, prot, flags); err != nil {
+		return nil, err
+	}
+
+	r.sqTail = (*uint32)(unsafe.Pointer(&r.sqRing[p.sqOff.tail]))
+	r.sqMask = *(*uint32)(unsafe.Pointer(&r.sqRing[p.sqOff.ringMask]))
+	r.sqArray = unsafe.Slice((*uint32)(unsafe.Pointer(&r.sqRing[p.sqOff.array])), p.sqEntries)
+	r.sqes = unsafe.Slice((*uringSQE)(unsafe.Pointer(&r.sqeMem[0])), p.sqEntries)
+	r.cqHead = (*uint32)(unsafe.Pointer(&r.cqRing[p.cqOff.head]))
+	r.cqTail = (*uint32)(unsafe.Pointer(&r.cqRing[p.cqOff.tail]))
+	r.cqMask = *(*uint32)(unsafe.Pointer(&r.cqRing[p.cqOff.ringMask]))
+	r.cqes = unsafe.Slice((*uringCQE)(unsafe.Pointer(&r.cqRing[p.cqOff.cqes])), p.cqEntries)
+	return r, nil
+}
+
+// writeAt writes bufs one after the other from off, and fdatasyncs f if
+// datasync, in a single submission of linked requests.
+func (r *ioURing) writeAt(f *os.File, off int64, bufs [][]byte, datasync bool) error {
+	fd := int32(f.Fd())
+	iovs := make([]unix.Iovec, len(bufs))
+	sqes := make([]uringSQE, 0, len(bufs)+1)
+	pos := off
+	for i, b := range bufs {
+		iovs[i].Base = &b[0]
+		iovs[i].SetLen(len(b))
+		sqes = append(sqes, uringSQE{
+			opcode:   uringOpWritev,
+			flags:    uringSQELink,
+			fd:       fd,
+			off:      uint64(pos),
+			addr:     uint64(uintptr(unsafe.Pointer(&iovs[i]))),
+			len:      1,
+			userData: uint64(i),
+		})
+		pos += int64(len(b))
+	}
+	if datasync {
+		sqes = append(sqes, uringSQE{
+			opcode:   uringOpFsync,
+			fd:       fd,
+			opFlags:  uringFsyncDatasync,
+			userData: uint64(len(bufs)),
+		})
+	}
+	sqes[len(sqes)-1].flags &^= uringSQELink
+
+	res, err := r.submit(sqes)
+	runtime.KeepAlive(iovs)
+	runtime.KeepAlive(bufs)
+	if err != nil {
+		return err
+	}
+
+	// a short write breaks the link and cancels the requests after it,
+	// which are completed with regular system calls
+	pos = off
+	for i, b := range bufs {
+		n := res[i]
+		if n < 0 {
+			if errno := syscall.Errno(-n); errno != unix.ECANCELED {
+				return &os.PathError{Op: "write", Path: f.Name(), Err: errno}
+			}
+			n = 0
+		}
+		if int(n) < len(b) {
+			if _, err = f.WriteAt(b[n:], pos+int64(n)); err != nil {
+				return err
+			}
+		}
+		pos += int64(len(b))
+	}
+	if !datasync {
+		return nil
+	}
+	if n := res[len(bufs)]; n < 0 {
+		if errno := syscall.Errno(-n); errno != unix.ECANCELED {
+			return &os.PathError{Op: "fdatasync", Path: f.Name(), Err: errno}
+		}
+		return fileutil.Fdatasync(f)
+	}
+	return nil
+}
+
+// submit submits sqes, and waits for them to complete. It returns the
+// result of each, indexed by its user data.
+func (r *ioURing) submit(sqes []uringSQE) ([]int32, error) {
+	tail := *r.sqTail
+	for i := range sqes {
+		idx := (tail + uint32(i)) & r.sqMask
+		r.sqes[idx] = sqes[i]
+		r.sqArray[idx] = idx
+	}
+	atomic.StoreUint32(r.sqTail, tail+uint32(len(sqes)))
+
+	res := make([]int32, len(sqes))
+	toSubmit, completed := len(sqes), 0
+	for completed < len(sqes) {
+		n, _, errno := unix.Syscall6(unix.SYS_IO_URING_ENTER, uintptr(r.fd), uintptr(toSubmit), uintptr(len(sqes)-completed), uringEnterGetEvents, 0, 0)
+		if errno == unix.EINTR {
+			continue
+		}
+		if errno != 0 {
+			return nil, os.NewSyscallError("io_uring_enter", errno)
+		}
+		toSubmit -= int(n)
+
+		head := *r.cqHead
+		for ; head != atomic.LoadUint32(r.cqTail); head++ {
+			cqe := r.cqes[head&r.cqMask]
+			res[cqe.userData] = cqe.res
+			completed++
+		}
+		atomic.StoreUint32(r.cqHead, head)
+	}
+	return res, nil
+}
+
+func (r *ioURing) Close() error {
+	for _, m := range [][]byte{r.sqRing, r.cqRing, r.sqeMem} {
+		if m != nil {
+			unix.Munmap(m)
+		}
+	}
+	return unix.Close(r.fd)
+}
@@ -0,0 +1,97 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package wal
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestIOURingWrites(t *testing.T) {
+	r, err := newIOURing(uringEntries)
+	if err != nil {
+		t.Skipf("io_uring is not available: %v", err)
+	}
+	r.Close()
+
+	defer func(size int64) { SegmentSizeBytes = size }(SegmentSizeBytes)
+	SegmentSizeBytes = 64 * 1024
+
+	p := t.TempDir()
+	w, err := Create(zaptest.NewLogger(t), p, []byte("metadata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetIOURing(true)
+	if w.uw == nil {
+		t.Fatal("expected the tail segment to be written through io_uring")
+	}
+	var ents []raftpb.Entry
+	for i := uint64(1); i <= 100; i++ {
+		ents = append(ents, raftpb.Entry{Index: i, Term: 1, Data: bytes.Repeat([]byte{byte(i)}, 4096)})
+	}
+	state := raftpb.HardState{Term: 1, Commit: 100}
+	for i := range ents {
+		if err = w.Save(state, ents[i:i+1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(w.locks) < 3 {
+		t.Errorf("segments = %d, want the WAL to be cut", len(w.locks))
+	}
+	w.Close()
+
+	w, err = Open(zaptest.NewLogger(t), p, walpb.Snapshot{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetIOURing(true)
+	metadata, st, rents, err := w.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(metadata) != "metadata" || !reflect.DeepEqual(st, state) {
+		t.Errorf("metadata = %q, state = %+v, want %q, %+v", metadata, st, "metadata", state)
+	}
+	if !reflect.DeepEqual(rents, ents) {
+		t.Errorf("read %d entries, want %d", len(rents), len(ents))
+	}
+
+	// appending after the entries read
+	next := raftpb.Entry{Index: 101, Term: 1, Data: []byte("next")}
+	if err = w.Save(state, []raftpb.Entry{next}); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	w, err = Open(zaptest.NewLogger(t), p, walpb.Snapshot{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if _, _, rents, err = w.ReadAll(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rents, append(ents, next)) {
+		t.Errorf("read %d entries, want %d", len(rents), len(ents)+1)
+	}
+}
@@ -99,6 +99,18 @@ func checkWalNames(lg *zap.Logger, names []string) []string {
 	return wnames
 }
 
+// SegmentNames returns the names of the WAL segments in dirpath, in the
+// order they are written.
+func SegmentNames(lg *zap.Logger, dirpath string) ([]string, error) {
+	return readWALNames(lg, dirpath)
+}
+
+// SegmentSeq returns the sequence number of the WAL segment name.
+func SegmentSeq(name string) (uint64, error) {
+	seq, _, err := parseWALName(name)
+	return seq, err
+}
+
 func parseWALName(str string) (seq, index uint64, err error) {
 	if !strings.HasSuffix(str, ".wal") {
 		return 0, 0, errBadWALName
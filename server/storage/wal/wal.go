@@ -30,6 +30,7 @@ import (
 	"go.etcd.io/etcd/pkg/v3/pbutil"
 	"go.etcd.io/etcd/raft/v3"
 	"go.etcd.io/etcd/raft/v3/raftpb"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
 	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
 
 	"go.uber.org/zap"
@@ -41,6 +42,17 @@ const (
 	stateType
 	crcType
 	snapshotType
+	// compressedEntryType records hold a marshaled entry compressed with
+	// zstd. They are only written with the zstd compression, the WAL
+	// being read whatever its records are compressed or not.
+	compressedEntryType
+	// keyType records hold the wrapped data encryption key of the
+	// encryptedEntryType records following it in the segment. Each segment
+	// written with a key provider has its own key.
+	keyType
+	// encryptedEntryType records hold an entryType or compressedEntryType
+	// record encrypted.
+	encryptedEntryType
 
 	// warnSyncDuration is the amount of time allotted to an fsync before
 	// logging a warning
@@ -85,7 +97,21 @@ type WAL struct {
 	decoder   *decoder       // decoder to decode records
 	readClose func() error   // closer for decode reader
 
-	unsafeNoSync bool // if set, do not fsync
+	unsafeNoSync bool  // if set, do not fsync
+	segmentSize  int64 // size from which the tail segment is cut, SegmentSizeBytes if 0
+	compress     bool  // if set, compress the entries
+
+	// kp wraps the keys encrypting the entries, if set, and unwraps the
+	// ones read.
+	kp encryption.KeyProvider
+	// dek encrypts the entries appended to the tail segment, once its
+	// keyType record is written.
+	dek *encryption.DataKey
+
+	// ring submits the appends and the fdatasyncs, if set, through uw for
+	// the tail segment.
+	ring *ioURing
+	uw   *uringWriter
 
 	mu      sync.Mutex
 	enti    uint64   // index of the last entry saved to the wal
@@ -93,6 +119,19 @@ type WAL struct {
 
 	locks []*fileutil.LockedFile // the locked files the WAL holds (the name is increasing)
 	fp    *filePipeline
+
+	// lastSyncStart and lastSyncTook are the start time and duration of the last fdatasync
+	lastSyncStart time.Time
+	lastSyncTook  time.Duration
+
+	// syncInterval is the interval the WAL is fsynced at if batched, the
+	// records saved being only flushed; unsynced is set until then.
+	syncInterval time.Duration
+	unsynced     bool
+	// syncStopc stops the loop fsyncing the batched WAL, which closes
+	// syncDonec once returned.
+	syncStopc chan struct{}
+	syncDonec chan struct{}
 }
 
 // Create creates a WAL ready for appending records. The given metadata is
@@ -246,6 +285,132 @@ func (w *WAL) SetUnsafeNoFsync() {
 	w.unsafeNoSync = true
 }
 
+// SetCompression sets the compression of the entries appended, one of
+// CompressionNone and CompressionZstd.
+func (w *WAL) SetCompression(compression string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.compress = compression == CompressionZstd
+}
+
+// SetKeyProvider sets the provider of the keys encrypting the entries
+// appended, and decrypting the ones read. The entries written without
+// encryption are read as well.
+func (w *WAL) SetKeyProvider(kp encryption.KeyProvider) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.kp = kp
+	w.dek = nil
+}
+
+// SegmentOptions configures the segments of a WAL.
+type SegmentOptions struct {
+	// Size is the size in bytes from which a segment is cut.
+	Size int64
+	// PreallocSize is the size in bytes preallocated for a new segment, Size
+	// if 0. The segments are not preallocated if negative.
+	PreallocSize int64
+	// TmpSegments is the number of segments created ahead as .tmp files, at
+	// least 1.
+	TmpSegments int
+}
+
+// SetSegmentOptions sets the size and the preallocation of the segments
+// created from then on. The tail segment is shrunk if it was preallocated
+// beyond the new preallocation size.
+func (w *WAL) SetSegmentOptions(opts SegmentOptions) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if opts.Size <= 0 {
+		return fmt.Errorf("wal: invalid segment size %d", opts.Size)
+	}
+	prealloc := opts.PreallocSize
+	switch {
+	case prealloc == 0:
+		prealloc = opts.Size
+	case prealloc < 0:
+		prealloc = 0
+	}
+	w.segmentSize = opts.Size
+
+	if w.fp != nil {
+		if err := w.fp.Close(); err != nil {
+			return err
+		}
+		w.fp = newFilePipeline(w.lg, w.dir, prealloc, opts.TmpSegments)
+	}
+
+	if w.encoder == nil || w.tail() == nil {
+		return nil
+	}
+	if err := w.sync(); err != nil {
+		return err
+	}
+	off, err := w.tail().Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	fi, err := w.tail().Stat()
+	if err != nil {
+		return err
+	}
+	if off < prealloc {
+		off = prealloc
+	}
+	if fi.Size() > off {
+		return w.tail().Truncate(off)
+	}
+	return nil
+}
+
+// SetIOURing makes the appends and the fdatasyncs go through io_uring, the
+// writes pending on a sync being submitted along with the fdatasync. Where
+// io_uring is not available, the WAL keeps writing with the regular system
+// calls.
+func (w *WAL) SetIOURing(enabled bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !enabled || w.ring != nil {
+		return
+	}
+	ring, err := newIOURing(uringEntries)
+	if err != nil {
+		w.lg.Warn("failed to set up io_uring; falling back to the regular WAL writes", zap.Error(err))
+		return
+	}
+	if w.encoder != nil && w.tail() != nil {
+		if err = w.encoder.flush(); err == nil {
+			var enc *encoder
+			w.ring = ring
+			if enc, err = w.newTailEncoder(w.encoder.crc.Sum32()); err == nil {
+				w.encoder = enc
+			}
+		}
+		if err != nil {
+			w.ring, w.uw = nil, nil
+			ring.Close()
+			w.lg.Warn("failed to switch the WAL writes to io_uring", zap.Error(err))
+			return
+		}
+	}
+	w.ring = ring
+	w.lg.Info("enabled io_uring WAL writes")
+}
+
+// newTailEncoder creates an encoder appending to the tail segment from its
+// current offset, through io_uring if enabled.
+func (w *WAL) newTailEncoder(prevCrc uint32) (*encoder, error) {
+	if w.ring == nil {
+		return newFileEncoder(w.tail().File, prevCrc)
+	}
+	offset, err := w.tail().Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	w.uw = newURingWriter(w.ring, w.tail().File, offset)
+	return newEncoder(w.uw, prevCrc, int(offset)), nil
+}
+
 func (w *WAL) cleanupWAL(lg *zap.Logger) {
 	var err error
 	if err = w.Close(); err != nil {
@@ -278,7 +443,7 @@ func (w *WAL) renameWAL(tmpdirpath string) (*WAL, error) {
 		}
 		return nil, err
 	}
-	w.fp = newFilePipeline(w.lg, w.dir, SegmentSizeBytes)
+	w.fp = newFilePipeline(w.lg, w.dir, SegmentSizeBytes, 1)
 	df, err := fileutil.OpenDir(w.dir)
 	w.dirFile = df
 	return w, err
@@ -365,7 +530,7 @@ func openAtIndex(lg *zap.Logger, dirpath string, snap walpb.Snapshot, write bool
 			closer()
 			return nil, err
 		}
-		w.fp = newFilePipeline(lg, w.dir, SegmentSizeBytes)
+		w.fp = newFilePipeline(lg, w.dir, SegmentSizeBytes, 1)
 	}
 
 	return w, nil
@@ -445,11 +610,26 @@ func (w *WAL) ReadAll() (metadata []byte, state raftpb.HardState, ents []raftpb.
 	}
 	decoder := w.decoder
 
-	var match bool
+	var (
+		match bool
+		dek   *encryption.DataKey
+	)
 	for err = decoder.decode(rec); err == nil; err = decoder.decode(rec) {
 		switch rec.Type {
-		case entryType:
-			e := mustUnmarshalEntry(rec.Data)
+		case keyType:
+			var kerr error
+			if dek, kerr = encryption.OpenDataKey(w.kp, rec.Data); kerr != nil {
+				state.Reset()
+				return nil, state, nil, kerr
+			}
+
+		case entryType, compressedEntryType, encryptedEntryType:
+			b, derr := entryData(rec, dek)
+			if derr != nil {
+				state.Reset()
+				return nil, state, nil, derr
+			}
+			e := mustUnmarshalEntry(b)
 			// 0 <= e.Index-w.start.Index - 1 < len(ents)
 			if e.Index > w.start.Index {
 				// prevent "panic: runtime error: slice bounds out of range [:13038096702221461992] with capacity 0"
@@ -545,7 +725,7 @@ func (w *WAL) ReadAll() (metadata []byte, state raftpb.HardState, ents []raftpb.
 
 	if w.tail() != nil {
 		// create encoder (chain crc with the decoder), enable appending
-		w.encoder, err = newFileEncoder(w.tail().File, w.decoder.lastCRC())
+		w.encoder, err = w.newTailEncoder(w.decoder.lastCRC())
 		if err != nil {
 			return
 		}
@@ -683,7 +863,7 @@ func Verify(lg *zap.Logger, walDir string, snap walpb.Snapshot) (*raftpb.HardSta
 			}
 		// We ignore all entry and state type records as these
 		// are not necessary for validating the WAL contents
-		case entryType:
+		case entryType, compressedEntryType, keyType, encryptedEntryType:
 		case stateType:
 			pbutil.MustUnmarshal(&state, rec.Data)
 		default:
@@ -732,8 +912,10 @@ func (w *WAL) cut() error {
 
 	// update writer and save the previous crc
 	w.locks = append(w.locks, newTail)
+	// the entries of the new segment are encrypted with a new key
+	w.dek = nil
 	prevCrc := w.encoder.crc.Sum32()
-	w.encoder, err = newFileEncoder(w.tail().File, prevCrc)
+	w.encoder, err = w.newTailEncoder(prevCrc)
 	if err != nil {
 		return err
 	}
@@ -782,7 +964,7 @@ func (w *WAL) cut() error {
 	w.locks[len(w.locks)-1] = newTail
 
 	prevCrc = w.encoder.crc.Sum32()
-	w.encoder, err = newFileEncoder(w.tail().File, prevCrc)
+	w.encoder, err = w.newTailEncoder(prevCrc)
 	if err != nil {
 		return err
 	}
@@ -799,13 +981,25 @@ func (w *WAL) sync() error {
 	}
 
 	if w.unsafeNoSync {
+		if w.uw != nil {
+			return w.uw.flush()
+		}
 		return nil
 	}
 
 	start := time.Now()
-	err := fileutil.Fdatasync(w.tail().File)
+	var err error
+	if w.uw != nil {
+		err = w.uw.sync()
+	} else {
+		err = fileutil.Fdatasync(w.tail().File)
+	}
 
 	took := time.Since(start)
+	w.lastSyncStart, w.lastSyncTook = start, took
+	if err == nil {
+		w.unsynced = false
+	}
 	if took > warnSyncDuration {
 		w.lg.Warn(
 			"slow fdatasync",
@@ -871,6 +1065,9 @@ func (w *WAL) ReleaseLockTo(index uint64) error {
 
 // Close closes the current WAL file and directory.
 func (w *WAL) Close() error {
+	// the sync loop locks mu
+	w.stopSyncLoop()
+
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
@@ -892,6 +1089,10 @@ func (w *WAL) Close() error {
 			w.lg.Error("failed to close WAL", zap.Error(err))
 		}
 	}
+	if w.ring != nil {
+		w.ring.Close()
+		w.ring, w.uw = nil, nil
+	}
 
 	return w.dirFile.Close()
 }
@@ -900,6 +1101,22 @@ func (w *WAL) saveEntry(e *raftpb.Entry) error {
 	// TODO: add MustMarshalTo to reduce one allocation.
 	b := pbutil.MustMarshal(e)
 	rec := &walpb.Record{Type: entryType, Data: b}
+	if w.compress {
+		rec = compressEntry(b)
+	}
+	if w.kp != nil {
+		if w.dek == nil {
+			dek, err := encryption.NewDataKey(w.kp)
+			if err != nil {
+				return err
+			}
+			if err = w.encoder.encode(&walpb.Record{Type: keyType, Data: dek.Wrapped()}); err != nil {
+				return err
+			}
+			w.dek = dek
+		}
+		rec = encryptRecord(w.dek, rec)
+	}
 	if err := w.encoder.encode(rec); err != nil {
 		return err
 	}
@@ -942,7 +1159,14 @@ func (w *WAL) Save(st raftpb.HardState, ents []raftpb.Entry) error {
 	if err != nil {
 		return err
 	}
-	if curOff < SegmentSizeBytes {
+	segmentSize := w.segmentSize
+	if segmentSize == 0 {
+		segmentSize = SegmentSizeBytes
+	}
+	if curOff < segmentSize {
+		if mustSync && w.syncInterval > 0 {
+			return w.flushUnsynced()
+		}
 		if mustSync {
 			return w.sync()
 		}
@@ -952,6 +1176,13 @@ func (w *WAL) Save(st raftpb.HardState, ents []raftpb.Entry) error {
 	return w.cut()
 }
 
+// LastSync returns the start time and duration of the last fdatasync of the WAL.
+func (w *WAL) LastSync() (start time.Time, took time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastSyncStart, w.lastSyncTook
+}
+
 func (w *WAL) SaveSnapshot(e walpb.Snapshot) error {
 	if err := walpb.ValidateSnapshotForWrite(&e); err != nil {
 		return err
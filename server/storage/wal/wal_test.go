@@ -368,6 +368,40 @@ func TestSaveWithCut(t *testing.T) {
 	}
 }
 
+func TestSegmentOptions(t *testing.T) {
+	p := t.TempDir()
+	w, err := Create(zaptest.NewLogger(t), p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err = w.SetSegmentOptions(SegmentOptions{Size: 2048, PreallocSize: 4096, TmpSegments: 2}); err != nil {
+		t.Fatal(err)
+	}
+	// the initial segment was preallocated with SegmentSizeBytes
+	if fi, err := w.tail().Stat(); err != nil || fi.Size() != 4096 {
+		t.Fatalf("tail size = %d (%v), want 4096", fi.Size(), err)
+	}
+
+	state := raftpb.HardState{Term: 1}
+	data := make([]byte, 500)
+	for i := uint64(1); len(w.locks) == 1; i++ {
+		if err = w.Save(state, []raftpb.Entry{{Index: i, Term: 1, Data: data}}); err != nil {
+			t.Fatal(err)
+		}
+		if i > 10 {
+			t.Fatal("expected the WAL to be cut")
+		}
+	}
+	if fi, err := w.tail().Stat(); err != nil || fi.Size() != 4096 {
+		t.Errorf("new tail size = %d (%v), want 4096", fi.Size(), err)
+	}
+
+	if err = w.SetSegmentOptions(SegmentOptions{Size: 0}); err == nil {
+		t.Error("expected error on invalid segment size")
+	}
+}
+
 func TestRecover(t *testing.T) {
 	p := t.TempDir()
 
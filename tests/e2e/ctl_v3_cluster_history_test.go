@@ -0,0 +1,37 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"testing"
+
+	"go.etcd.io/etcd/tests/v3/framework/e2e"
+)
+
+func TestCtlV3ClusterHistory(t *testing.T) {
+	testCtl(t, clusterHistoryTest, withCfg(*e2e.NewConfigNoTLS()))
+}
+
+func clusterHistoryTest(cx ctlCtx) {
+	cmdArgs := append(cx.PrefixArgs(), "cluster-history", "--cluster")
+	lines := []string{"cluster history:"}
+	for i := 0; i < cx.cfg.ClusterSize; i++ {
+		lines = append(lines, "MEMBER_ADD member:")
+	}
+	lines = append(lines, "LEADER_CHANGE member:")
+	if err := e2e.SpawnWithExpects(cmdArgs, cx.envMap, lines...); err != nil {
+		cx.t.Fatalf("clusterHistoryTest error (%v)", err)
+	}
+}
@@ -24,6 +24,10 @@ func TestCtlV3DefragOffline(t *testing.T) {
 	testCtlWithOffline(t, maintenanceInitKeys, defragOfflineTest)
 }
 
+func TestCtlV3DefragStatus(t *testing.T) {
+	testCtl(t, defragStatusTest, withCfg(*e2e.NewConfigNoTLS()))
+}
+
 func maintenanceInitKeys(cx ctlCtx) {
 	var kvs = []kv{{"key", "val1"}, {"key", "val2"}, {"key", "val3"}}
 	for i := range kvs {
@@ -53,3 +57,18 @@ func defragOfflineTest(cx ctlCtx) {
 		cx.t.Fatalf("defragTest ctlV3Defrag error (%v)", err)
 	}
 }
+
+func defragStatusTest(cx ctlCtx) {
+	maintenanceInitKeys(cx)
+	if err := ctlV3OnlineDefrag(cx); err != nil {
+		cx.t.Fatalf("defragStatusTest ctlV3OnlineDefrag error (%v)", err)
+	}
+	cmdArgs := append(cx.PrefixArgs(), "defrag", "status", "--cluster")
+	lines := make([]string, cx.epc.Cfg.ClusterSize)
+	for i := range lines {
+		lines[i] = "No defragmentation in progress on etcd member"
+	}
+	if err := e2e.SpawnWithExpects(cmdArgs, cx.envMap, lines...); err != nil {
+		cx.t.Fatalf("defragStatusTest error (%v)", err)
+	}
+}
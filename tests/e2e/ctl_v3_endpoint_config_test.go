@@ -0,0 +1,42 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"testing"
+
+	"go.etcd.io/etcd/tests/v3/framework/e2e"
+)
+
+func TestCtlV3EndpointConfig(t *testing.T) {
+	testCtl(t, endpointConfigTest, withCfg(*e2e.NewConfigNoTLS()))
+}
+
+func endpointConfigTest(cx ctlCtx) {
+	cmdArgs := append(cx.PrefixArgs(), "endpoint", "config", "--cluster")
+	var lines []string
+	for i := 0; i < cx.cfg.ClusterSize; i++ {
+		lines = append(lines,
+			", advertise-client-urls, [\"http://",
+			", experimental-max-learners, 1",
+			", log-level, ",
+			", name, test-",
+			", snapshot-count, ",
+		)
+	}
+	if err := e2e.SpawnWithExpects(cmdArgs, cx.envMap, lines...); err != nil {
+		cx.t.Fatalf("endpointConfigTest error (%v)", err)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"testing"
+
+	"go.etcd.io/etcd/tests/v3/framework/e2e"
+)
+
+func TestCtlV3HotKeys(t *testing.T) {
+	cfg := e2e.NewConfigNoTLS()
+	cfg.ClusterSize = 1
+	cfg.HotKeySampleRate = 1
+	testCtl(t, hotKeysTest, withCfg(*cfg))
+}
+
+func TestCtlV3HotKeysDisabled(t *testing.T) {
+	testCtl(t, hotKeysDisabledTest, withCfg(*e2e.NewConfigNoTLS()))
+}
+
+func hotKeysTest(cx ctlCtx) {
+	if err := ctlV3Put(cx, "/hot/key", "v", ""); err != nil {
+		cx.t.Fatalf("hotKeysTest ctlV3Put error (%v)", err)
+	}
+	cmdArgs := append(cx.PrefixArgs(), "hot-keys")
+	if err := e2e.SpawnWithExpects(cmdArgs, cx.envMap, "sample rate 1", `"/hot/" reads: 0, writes: 1`); err != nil {
+		cx.t.Fatalf("hotKeysTest error (%v)", err)
+	}
+}
+
+func hotKeysDisabledTest(cx ctlCtx) {
+	cmdArgs := append(cx.PrefixArgs(), "hot-keys")
+	if err := e2e.SpawnWithExpects(cmdArgs, cx.envMap, "hot key sampling is disabled"); err != nil {
+		cx.t.Fatalf("hotKeysDisabledTest error (%v)", err)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"testing"
+
+	"go.etcd.io/etcd/tests/v3/framework/e2e"
+)
+
+func TestCtlV3LogLevel(t *testing.T) { testCtl(t, logLevelTest) }
+
+func logLevelTest(cx ctlCtx) {
+	cmdArgs := append(cx.PrefixArgs(), "log-level", "debug", "--grpc-tracing=on")
+	if err := e2e.SpawnWithExpects(cmdArgs, cx.envMap, "log level: debug, gRPC tracing: true"); err != nil {
+		cx.t.Fatalf("logLevelTest error (%v)", err)
+	}
+
+	cmdArgs = append(cx.PrefixArgs(), "log-level", "--grpc-tracing=off")
+	if err := e2e.SpawnWithExpects(cmdArgs, cx.envMap, "log level: debug, gRPC tracing: false"); err != nil {
+		cx.t.Fatalf("logLevelTest error (%v)", err)
+	}
+
+	cmdArgs = append(cx.PrefixArgs(), "log-level", "verbose")
+	if err := e2e.SpawnWithExpects(cmdArgs, cx.envMap, "invalid log level"); err != nil {
+		cx.t.Fatalf("logLevelTest error (%v)", err)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"testing"
+
+	"go.etcd.io/etcd/tests/v3/framework/e2e"
+)
+
+func TestCtlV3PrefixQuota(t *testing.T) {
+	testCtl(t, prefixQuotaTest, withCfg(*e2e.NewConfigNoTLS()))
+}
+
+func prefixQuotaTest(cx ctlCtx) {
+	cmdArgs := append(cx.PrefixArgs(), "prefix-quota", "set", "tenant/", "--max-keys", "1")
+	if err := e2e.SpawnWithExpects(cmdArgs, cx.envMap, `Set quota of prefix "tenant/"`); err != nil {
+		cx.t.Fatalf("prefixQuotaTest set error (%v)", err)
+	}
+	if err := ctlV3Put(cx, "tenant/foo", "bar", ""); err != nil {
+		cx.t.Fatalf("prefixQuotaTest put error (%v)", err)
+	}
+	cmdArgs = append(cx.PrefixArgs(), "put", "tenant/baz", "bar")
+	if err := e2e.SpawnWithExpects(cmdArgs, cx.envMap, "prefix quota exceeded"); err != nil {
+		cx.t.Fatalf("prefixQuotaTest exceeding put error (%v)", err)
+	}
+	cmdArgs = append(cx.PrefixArgs(), "prefix-quota", "list")
+	if err := e2e.SpawnWithExpects(cmdArgs, cx.envMap, `"tenant/" bytes: 13/unlimited, keys: 1/1`); err != nil {
+		cx.t.Fatalf("prefixQuotaTest list error (%v)", err)
+	}
+	cmdArgs = append(cx.PrefixArgs(), "prefix-quota", "delete", "tenant/")
+	if err := e2e.SpawnWithExpects(cmdArgs, cx.envMap, `Deleted quota of prefix "tenant/"`); err != nil {
+		cx.t.Fatalf("prefixQuotaTest delete error (%v)", err)
+	}
+	if err := ctlV3Put(cx, "tenant/baz", "bar", ""); err != nil {
+		cx.t.Fatalf("prefixQuotaTest put error (%v)", err)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"testing"
+
+	"go.etcd.io/etcd/tests/v3/framework/e2e"
+)
+
+func TestCtlV3PrefixStats(t *testing.T) {
+	testCtl(t, prefixStatsTest, withCfg(*e2e.NewConfigNoTLS()))
+}
+
+func prefixStatsTest(cx ctlCtx) {
+	for _, kv := range [][2]string{{"tenant/foo", "bar"}, {"tenant/baz", "barbar"}, {"other", "bar"}} {
+		if err := ctlV3Put(cx, kv[0], kv[1], ""); err != nil {
+			cx.t.Fatalf("prefixStatsTest put error (%v)", err)
+		}
+	}
+	cmdArgs := append(cx.PrefixArgs(), "prefix-stats", "tenant/")
+	if err := e2e.SpawnWithExpects(cmdArgs, cx.envMap, `"tenant/" keys: 2, key bytes: 20, value bytes: 9, revision: 4`); err != nil {
+		cx.t.Fatalf("prefixStatsTest error (%v)", err)
+	}
+	cmdArgs = append(cx.PrefixArgs(), "prefix-stats")
+	if err := e2e.SpawnWithExpects(cmdArgs, cx.envMap, `"" keys: 3, key bytes: 25, value bytes: 12, revision: 4`); err != nil {
+		cx.t.Fatalf("prefixStatsTest all keys error (%v)", err)
+	}
+}
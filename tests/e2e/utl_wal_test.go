@@ -0,0 +1,113 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/tests/v3/framework/e2e"
+)
+
+func TestEtcdutlWALRepair(t *testing.T) {
+	e2e.BeforeTest(t)
+	dataDirPath := t.TempDir()
+
+	epc, err := e2e.NewEtcdProcessCluster(t, &e2e.EtcdProcessClusterConfig{
+		DataDirPath:  dataDirPath,
+		ClusterSize:  1,
+		InitialToken: "new",
+		KeepDataDir:  true,
+	})
+	if err != nil {
+		t.Fatalf("could not start etcd process cluster (%v)", err)
+	}
+	defer func() {
+		if errC := epc.Close(); errC != nil {
+			t.Fatalf("error closing etcd processes (%v)", errC)
+		}
+	}()
+
+	prefixArgs := []string{e2e.CtlBinPath, "--endpoints", strings.Join(epc.EndpointsV3(), ","), "--dial-timeout", (10 * time.Second).String()}
+	for i := 0; i < 5; i++ {
+		if err = e2e.SpawnWithExpect(append(prefixArgs, "put", fmt.Sprintf("%d", i), "value"), "OK"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = epc.Procs[0].Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	repairArgs := []string{e2e.UtlBinPath, "wal", "repair", "--data-dir", dataDirPath}
+	if err = e2e.SpawnWithExpect(repairArgs, "No corrupt record found."); err != nil {
+		t.Fatal(err)
+	}
+	// the puts were applied to the backend
+	if err = e2e.SpawnWithExpect(append(repairArgs, "--truncate-index", "2", "--yes"), "the backend applied the entries up to index"); err != nil {
+		t.Fatal(err)
+	}
+	if err = e2e.SpawnWithExpect(append(repairArgs, "--truncate-index", "2", "--member-index", "m1=x"), "invalid --member-index"); err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing was truncated
+	if err = epc.Procs[0].Restart(); err != nil {
+		t.Fatal(err)
+	}
+	if err = e2e.SpawnWithExpect(append(prefixArgs, "get", "4"), "value"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEtcdutlWALDump(t *testing.T) {
+	e2e.BeforeTest(t)
+	dataDirPath := t.TempDir()
+
+	epc, err := e2e.NewEtcdProcessCluster(t, &e2e.EtcdProcessClusterConfig{
+		DataDirPath:  dataDirPath,
+		ClusterSize:  1,
+		InitialToken: "new",
+		KeepDataDir:  true,
+	})
+	if err != nil {
+		t.Fatalf("could not start etcd process cluster (%v)", err)
+	}
+	defer func() {
+		if errC := epc.Close(); errC != nil {
+			t.Fatalf("error closing etcd processes (%v)", errC)
+		}
+	}()
+
+	prefixArgs := []string{e2e.CtlBinPath, "--endpoints", strings.Join(epc.EndpointsV3(), ","), "--dial-timeout", (10 * time.Second).String()}
+	if err = e2e.SpawnWithExpect(append(prefixArgs, "put", "foo", "bar"), "OK"); err != nil {
+		t.Fatal(err)
+	}
+	if err = epc.Procs[0].Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	dumpArgs := []string{e2e.UtlBinPath, "wal", "dump", "--data-dir", dataDirPath}
+	if err = e2e.SpawnWithExpect(dumpArgs, `"request":"put","fields":{"key":"foo","value_size":3}`); err != nil {
+		t.Fatal(err)
+	}
+	if err = e2e.SpawnWithExpect(append(dumpArgs, "--values"), `"request":"put","fields":{"key":"foo","value":"bar"}`); err != nil {
+		t.Fatal(err)
+	}
+	if err = e2e.SpawnWithExpect(append(dumpArgs, "--format", "yaml"), `unknown --format "yaml"`); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -42,6 +42,11 @@ func TestV3CurlLeaseKeepAliveNoTLS(t *testing.T) {
 		testCtl(t, testV3CurlLeaseKeepAlive, withApiPrefix(p), withCfg(*e2e.NewConfigNoTLS()))
 	}
 }
+func TestV3CurlLeaseKeepAliveOnceNoTLS(t *testing.T) {
+	for _, p := range apiPrefix {
+		testCtl(t, testV3CurlLeaseKeepAliveOnce, withApiPrefix(p), withCfg(*e2e.NewConfigNoTLS()))
+	}
+}
 
 type v3cURLTest struct {
 	endpoint string
@@ -139,6 +144,26 @@ func testV3CurlLeaseKeepAlive(cx ctlCtx) {
 	}
 }
 
+func testV3CurlLeaseKeepAliveOnce(cx ctlCtx) {
+	leaseID := e2e.RandomLeaseID()
+
+	tests := []v3cURLTest{
+		{
+			endpoint: "/lease/grant",
+			value:    gwLeaseGrant(cx, leaseID, 20),
+			expected: gwLeaseIDExpected(leaseID),
+		},
+		{
+			endpoint: "/lease/keepalive-once",
+			value:    gwLeaseKeepAlive(cx, leaseID),
+			expected: `"TTL":"20"`,
+		},
+	}
+	if err := CURLWithExpected(cx, tests); err != nil {
+		cx.t.Fatalf("testV3CurlLeaseKeepAliveOnce: %v", err)
+	}
+}
+
 func gwLeaseIDExpected(leaseID int64) string {
 	return fmt.Sprintf(`"ID":"%d"`, leaseID)
 }
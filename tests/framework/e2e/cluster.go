@@ -176,6 +176,7 @@ type EtcdProcessClusterConfig struct {
 	DiscoveryEndpoints []string // v3 discovery
 	DiscoveryToken     string
 	LogLevel           string
+	HotKeySampleRate   float64
 }
 
 // NewEtcdProcessCluster launches a new cluster from etcd processes, returning
@@ -341,6 +342,10 @@ func (cfg *EtcdProcessClusterConfig) EtcdServerProcessConfigs(tb testing.TB) []*
 			args = append(args, "--log-level", cfg.LogLevel)
 		}
 
+		if cfg.HotKeySampleRate != 0 {
+			args = append(args, "--experimental-hot-key-sample-rate", fmt.Sprint(cfg.HotKeySampleRate))
+		}
+
 		etcdCfgs[i] = &EtcdServerProcessConfig{
 			lg:           lg,
 			ExecPath:     cfg.ExecPath,
@@ -80,6 +80,8 @@ func (ctl *EtcdctlV3) Get(key string, o config.GetOptions) (*clientv3.GetRespons
 		args = append(args, "--sort-by=MODIFY")
 	case clientv3.SortByValue:
 		args = append(args, "--sort-by=VALUE")
+	case clientv3.SortByLease:
+		args = append(args, "--sort-by=LEASE")
 	case clientv3.SortByVersion:
 		args = append(args, "--sort-by=VERSION")
 	case clientv3.SortByKey:
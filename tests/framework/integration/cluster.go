@@ -41,6 +41,7 @@ import (
 	"go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/pkg/v3/grpc_testing"
 	"go.etcd.io/etcd/raft/v3"
+	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/config"
 	"go.etcd.io/etcd/server/v3/embed"
 	"go.etcd.io/etcd/server/v3/etcdserver"
@@ -53,6 +54,7 @@ import (
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3lock"
 	lockpb "go.etcd.io/etcd/server/v3/etcdserver/api/v3lock/v3lockpb"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3rpc"
+	"go.etcd.io/etcd/server/v3/etcdserver/hotkey"
 	"go.etcd.io/etcd/server/v3/verify"
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest"
@@ -144,6 +146,11 @@ type ClusterConfig struct {
 
 	MaxTxnOps              uint
 	MaxRequestBytes        uint
+	MaxChunkedValueBytes   uint
+	MaxLeasesPerUser       int
+	Authorizer             auth.Authorizer
+	AuthLockout            auth.LockoutPolicy
+	AuthPasswordPolicy     auth.PasswordPolicy
 	SnapshotCount          uint64
 	SnapshotCatchUpEntries uint64
 
@@ -170,6 +177,10 @@ type ClusterConfig struct {
 	ExperimentalMaxLearners     int
 	StrictReconfigCheck         bool
 	CorruptCheckTime            time.Duration
+	HotKeySampleRate            float64
+	EnableGRPCReflection        bool
+	DefragBatchLimit            int
+	DefragBatchInterval         time.Duration
 }
 
 type Cluster struct {
@@ -267,6 +278,11 @@ func (c *Cluster) mustNewMember(t testutil.TB) *Member {
 			QuotaBackendBytes:           c.Cfg.QuotaBackendBytes,
 			MaxTxnOps:                   c.Cfg.MaxTxnOps,
 			MaxRequestBytes:             c.Cfg.MaxRequestBytes,
+			MaxChunkedValueBytes:        c.Cfg.MaxChunkedValueBytes,
+			MaxLeasesPerUser:            c.Cfg.MaxLeasesPerUser,
+			Authorizer:                  c.Cfg.Authorizer,
+			AuthLockout:                 c.Cfg.AuthLockout,
+			AuthPasswordPolicy:          c.Cfg.AuthPasswordPolicy,
 			SnapshotCount:               c.Cfg.SnapshotCount,
 			SnapshotCatchUpEntries:      c.Cfg.SnapshotCatchUpEntries,
 			GrpcKeepAliveMinTime:        c.Cfg.GRPCKeepAliveMinTime,
@@ -284,6 +300,10 @@ func (c *Cluster) mustNewMember(t testutil.TB) *Member {
 			ExperimentalMaxLearners:     c.Cfg.ExperimentalMaxLearners,
 			StrictReconfigCheck:         c.Cfg.StrictReconfigCheck,
 			CorruptCheckTime:            c.Cfg.CorruptCheckTime,
+			HotKeySampleRate:            c.Cfg.HotKeySampleRate,
+			EnableGRPCReflection:        c.Cfg.EnableGRPCReflection,
+			DefragBatchLimit:            c.Cfg.DefragBatchLimit,
+			DefragBatchInterval:         c.Cfg.DefragBatchInterval,
 		})
 	m.DiscoveryURL = c.Cfg.DiscoveryURL
 	return m
@@ -588,6 +608,11 @@ type MemberConfig struct {
 	QuotaBackendBytes           int64
 	MaxTxnOps                   uint
 	MaxRequestBytes             uint
+	MaxChunkedValueBytes        uint
+	MaxLeasesPerUser            int
+	Authorizer                  auth.Authorizer
+	AuthLockout                 auth.LockoutPolicy
+	AuthPasswordPolicy          auth.PasswordPolicy
 	SnapshotCount               uint64
 	SnapshotCatchUpEntries      uint64
 	GrpcKeepAliveMinTime        time.Duration
@@ -605,6 +630,10 @@ type MemberConfig struct {
 	ExperimentalMaxLearners     int
 	StrictReconfigCheck         bool
 	CorruptCheckTime            time.Duration
+	HotKeySampleRate            float64
+	EnableGRPCReflection        bool
+	DefragBatchLimit            int
+	DefragBatchInterval         time.Duration
 }
 
 // MustNewMember return an inited member with the given name. If peerTLS is
@@ -664,6 +693,11 @@ func MustNewMember(t testutil.TB, mcfg MemberConfig) *Member {
 	if m.MaxRequestBytes == 0 {
 		m.MaxRequestBytes = embed.DefaultMaxRequestBytes
 	}
+	m.MaxChunkedValueBytes = mcfg.MaxChunkedValueBytes
+	m.MaxLeasesPerUser = mcfg.MaxLeasesPerUser
+	m.Authorizer = mcfg.Authorizer
+	m.AuthLockout = mcfg.AuthLockout
+	m.AuthPasswordPolicy = mcfg.AuthPasswordPolicy
 	m.SnapshotCount = etcdserver.DefaultSnapshotCount
 	if mcfg.SnapshotCount != 0 {
 		m.SnapshotCount = mcfg.SnapshotCount
@@ -705,6 +739,11 @@ func MustNewMember(t testutil.TB, mcfg MemberConfig) *Member {
 	m.LeaseCheckpointPersist = mcfg.LeaseCheckpointPersist
 
 	m.WatchProgressNotifyInterval = mcfg.WatchProgressNotifyInterval
+	m.HotKeySampleRate = mcfg.HotKeySampleRate
+	m.HotKeyWindow = hotkey.DefaultWindow
+	m.EnableGRPCReflection = mcfg.EnableGRPCReflection
+	m.DefragBatchLimit = mcfg.DefragBatchLimit
+	m.DefragBatchInterval = mcfg.DefragBatchInterval
 
 	m.InitialCorruptCheck = true
 	if mcfg.CorruptCheckTime > time.Duration(0) {
@@ -718,7 +757,7 @@ func MustNewMember(t testutil.TB, mcfg MemberConfig) *Member {
 	}
 	m.V2Deprecation = config.V2_DEPR_DEFAULT
 	m.GrpcServerRecorder = &grpc_testing.GrpcRecorder{}
-	m.Logger = memberLogger(t, mcfg.Name)
+	m.Logger, m.LoggerLevel = memberLogger(t, mcfg.Name)
 	m.StrictReconfigCheck = mcfg.StrictReconfigCheck
 	if err := m.listenGRPC(); err != nil {
 		t.Fatalf("listenGRPC FAILED: %v", err)
@@ -731,14 +770,14 @@ func MustNewMember(t testutil.TB, mcfg MemberConfig) *Member {
 	return m
 }
 
-func memberLogger(t testutil.TB, name string) *zap.Logger {
-	level := zapcore.InfoLevel
+func memberLogger(t testutil.TB, name string) (*zap.Logger, *zap.AtomicLevel) {
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
 	if os.Getenv("CLUSTER_DEBUG") != "" {
-		level = zapcore.DebugLevel
+		level.SetLevel(zapcore.DebugLevel)
 	}
 
 	options := zaptest.WrapOptions(zap.Fields(zap.String("member", name)))
-	return zaptest.NewLogger(t, zaptest.Level(level), options).Named(name)
+	return zaptest.NewLogger(t, zaptest.Level(level), options).Named(name), &level
 }
 
 // listenGRPC starts a grpc server over a unix domain socket on the member
@@ -900,7 +939,7 @@ func (m *Member) Clone(t testutil.TB) *Member {
 	mm.ElectionTicks = m.ElectionTicks
 	mm.PeerTLSInfo = m.PeerTLSInfo
 	mm.ClientTLSInfo = m.ClientTLSInfo
-	mm.Logger = memberLogger(t, mm.Name+"c")
+	mm.Logger, mm.LoggerLevel = memberLogger(t, mm.Name+"c")
 	return mm
 }
 
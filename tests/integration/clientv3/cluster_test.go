@@ -23,6 +23,8 @@ import (
 	"testing"
 	"time"
 
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.etcd.io/etcd/client/pkg/v3/types"
 	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
 )
@@ -216,6 +218,50 @@ func TestMemberAddForLearner(t *testing.T) {
 	}
 }
 
+func TestMemberReplace(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	capi := clus.Client(1)
+	resp, err := capi.MemberList(context.Background())
+	if err != nil {
+		t.Fatalf("failed to list member %v", err)
+	}
+
+	// find member that is not the client to replace
+	var rmvID uint64
+	for _, m := range resp.Members {
+		mURLs, _ := types.NewURLs(m.PeerURLs)
+		if !reflect.DeepEqual(mURLs, clus.Members[1].ServerConfig.PeerURLs) {
+			rmvID = m.ID
+			break
+		}
+	}
+
+	if _, err = capi.MemberReplace(context.Background(), nil, nil); err != rpctypes.ErrNoMemberChange {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrNoMemberChange, err)
+	}
+
+	urls := []string{"http://127.0.0.1:1234"}
+	rresp, err := capi.MemberReplace(context.Background(), []*pb.MemberAddRequest{{PeerURLs: urls}}, []uint64{rmvID})
+	if err != nil {
+		t.Fatalf("failed to replace member %v", err)
+	}
+	if len(rresp.Added) != 1 || !reflect.DeepEqual(rresp.Added[0].PeerURLs, urls) {
+		t.Fatalf("added = %v, want a member with urls %v", rresp.Added, urls)
+	}
+
+	ids := make(map[uint64]bool)
+	for _, m := range rresp.Members {
+		ids[m.ID] = true
+	}
+	if len(ids) != 3 || ids[rmvID] || !ids[rresp.Added[0].ID] {
+		t.Errorf("members = %v, want %x replaced by %x", rresp.Members, rmvID, rresp.Added[0].ID)
+	}
+}
+
 func TestMemberPromote(t *testing.T) {
 	integration2.BeforeTest(t)
 
@@ -18,8 +18,10 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
@@ -226,6 +228,89 @@ func TestKVPutWithRequireLeader(t *testing.T) {
 	clus.TakeClient(2)
 }
 
+func TestKVDebugTrace(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	kv := clus.RandClient()
+	ctx := clientv3.WithDebugTrace(context.Background())
+
+	presp, err := kv.Put(ctx, "foo", "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := presp.Header.Trace
+	if tr == nil {
+		t.Fatal("expected trace in the put response header")
+	}
+	if tr.TotalNs <= 0 || tr.RaftNs <= 0 || tr.ApplyNs <= 0 {
+		t.Errorf("expected total, raft and apply timings of the put, got %+v", tr)
+	}
+	if tr.RaftNs > tr.TotalNs {
+		t.Errorf("expected raft timing within the total, got %+v", tr)
+	}
+
+	gresp, err := kv.Get(ctx, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gresp.Header.Trace == nil || gresp.Header.Trace.TotalNs <= 0 {
+		t.Errorf("expected trace in the get response header, got %+v", gresp.Header.Trace)
+	}
+
+	gresp, err = kv.Get(context.Background(), "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gresp.Header.Trace != nil {
+		t.Errorf("expected no trace without the debug trace metadata, got %+v", gresp.Header.Trace)
+	}
+}
+
+func TestKVDebugTraceRequiresAdmin(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	authapi := clus.RandClient()
+	if _, err := authapi.UserAdd(context.TODO(), "user", "123"); err != nil {
+		t.Fatal(err)
+	}
+	authSetupRoot(t, authapi.Auth)
+
+	cfg := clientv3.Config{
+		Endpoints:   authapi.Endpoints(),
+		DialTimeout: 5 * time.Second,
+		DialOptions: []grpc.DialOption{grpc.WithBlock()},
+	}
+	cfg.Username, cfg.Password = "user", "123"
+	user, err := integration2.NewClient(t, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer user.Close()
+	if _, err = user.Get(clientv3.WithDebugTrace(context.Background()), "foo"); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+
+	cfg.Username, cfg.Password = "root", "123"
+	root, err := integration2.NewClient(t, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer root.Close()
+	resp, err := root.Get(clientv3.WithDebugTrace(context.Background()), "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Header.Trace == nil {
+		t.Error("expected trace in the response header of root")
+	}
+}
+
 func TestKVRange(t *testing.T) {
 	integration2.BeforeTest(t)
 
@@ -287,6 +372,174 @@ func TestKVRange(t *testing.T) {
 	}
 }
 
+// TestKVRangeContinueToken ensures a range is paginated with continue tokens
+// at the revision of its first page.
+func TestKVRangeContinueToken(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	kv := clus.RandClient()
+	ctx := context.TODO()
+
+	var want []string
+	for i := 0; i < 25; i++ {
+		key := fmt.Sprintf("foo%02d", i)
+		if _, err := kv.Put(ctx, key, "bar"); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, key)
+	}
+
+	var got []string
+	var token []byte
+	for pages := 0; ; pages++ {
+		resp, err := kv.Get(ctx, "foo", clientv3.WithPrefix(), clientv3.WithLimit(10), clientv3.WithContinueToken(token))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, kv := range resp.Kvs {
+			got = append(got, string(kv.Key))
+		}
+		if resp.More != (len(resp.ContinueToken) != 0) {
+			t.Fatalf("expected a continue token only with more keys, got more %v and token %q", resp.More, resp.ContinueToken)
+		}
+		if !resp.More {
+			if pages != 2 {
+				t.Errorf("expected 3 pages, got %d", pages+1)
+			}
+			break
+		}
+		token = resp.ContinueToken
+		// the keys written meanwhile are not returned
+		if _, err = kv.Put(ctx, fmt.Sprintf("foo%02d", 99-pages), "bar"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = kv.Delete(ctx, want[len(want)-1-pages]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected keys %v, got %v", want, got)
+	}
+
+	_, err := kv.Get(ctx, "fop", clientv3.WithPrefix(), clientv3.WithContinueToken(token))
+	if err != rpctypes.ErrInvalidContinueToken {
+		t.Errorf("expected %v, got %v", rpctypes.ErrInvalidContinueToken, err)
+	}
+	_, err = kv.Txn(ctx).Then(clientv3.OpGet("foo", clientv3.WithPrefix(), clientv3.WithContinueToken(token))).Commit()
+	if err != rpctypes.ErrInvalidContinueToken {
+		t.Errorf("expected %v in a txn, got %v", rpctypes.ErrInvalidContinueToken, err)
+	}
+}
+
+// TestKVGetStaleness ensures a follower serves the reads with bounded
+// staleness while connected to the leader, and fails them otherwise.
+func TestKVGetStaleness(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	lIdx := clus.WaitLeader(t)
+	fIdx := (lIdx + 1) % 3
+	ctx := context.TODO()
+
+	presp, err := clus.Client(lIdx).Put(ctx, "foo", "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the follower has applied the put once it served a linearizable read
+	cli := clus.Client(fIdx)
+	if _, err = cli.Get(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := cli.Get(ctx, "foo", clientv3.WithStaleness(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Kvs) != 1 || resp.Header.Revision < presp.Header.Revision {
+		t.Fatalf("expected the put at revision %d, got %+v", presp.Header.Revision, resp)
+	}
+
+	clus.Members[lIdx].Stop(t)
+	clus.Members[(fIdx+1)%3].Stop(t)
+	for i := 0; ; i++ {
+		_, err = cli.Get(ctx, "foo", clientv3.WithStaleness(1))
+		if err == rpctypes.ErrNoLeader {
+			break
+		}
+		if i == 50 {
+			t.Fatalf("expected %v, got %v", rpctypes.ErrNoLeader, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// TestKVGetStalenessLaggingLearner ensures a learner partitioned from the
+// leader does not serve the reads with bounded staleness from its stale
+// commit index.
+func TestKVGetStalenessLaggingLearner(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	clus.AddAndLaunchLearnerMember(t)
+	leader, learner := clus.Members[0], clus.Members[1]
+	<-learner.ReadyNotify()
+
+	cli, err := integration2.NewClient(t, clientv3.Config{
+		Endpoints:   []string{learner.GRPCURL()},
+		DialTimeout: 5 * time.Second,
+		DialOptions: []grpc.DialOption{grpc.WithBlock()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	kv := clus.Client(0)
+	if _, err = kv.Put(context.TODO(), "foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.Get(context.TODO(), "foo", clientv3.WithStaleness(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	learner.InjectPartition(t, leader)
+	if _, err = kv.Put(context.TODO(), "foo", "baz"); err != nil {
+		t.Fatal(err)
+	}
+	// the put of "foo" is beyond the staleness once the next one is committed
+	if _, err = kv.Put(context.TODO(), "abc", "def"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+	resp, err := cli.Get(ctx, "foo", clientv3.WithStaleness(1))
+	cancel()
+	if err == nil {
+		t.Fatalf("expected the read to fail while partitioned from the leader, got %+v", resp)
+	}
+
+	learner.RecoverPartition(t, leader)
+	for i := 0; ; i++ {
+		ctx, cancel = context.WithTimeout(context.TODO(), 2*time.Second)
+		resp, err = cli.Get(ctx, "foo", clientv3.WithStaleness(1))
+		cancel()
+		if err == nil {
+			break
+		}
+		if i == 10 {
+			t.Fatalf("expected the read to succeed once the partition is recovered, got %v", err)
+		}
+	}
+	if len(resp.Kvs) != 1 || string(resp.Kvs[0].Value) != "baz" {
+		t.Fatalf("expected the value %q, got %+v", "baz", resp.Kvs)
+	}
+}
+
 func TestKVGetErrConnClosed(t *testing.T) {
 	integration2.BeforeTest(t)
 
@@ -394,6 +647,397 @@ func TestKVDeleteRange(t *testing.T) {
 	}
 }
 
+func TestKVMove(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	kv := clus.RandClient()
+	ctx := context.TODO()
+
+	lresp, err := clus.RandClient().Grant(ctx, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a/1", "a/2", "b/1", "c"} {
+		if _, err = kv.Put(ctx, key, key, clientv3.WithLease(lresp.ID)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err = kv.Move(ctx, "x", "y"); err != rpctypes.ErrKeyNotFound {
+		t.Errorf("move of a missing key: expected %v, got %v", rpctypes.ErrKeyNotFound, err)
+	}
+	if _, err = kv.Move(ctx, "a/", "b/", clientv3.WithPrefix()); err != rpctypes.ErrKeyExists {
+		t.Errorf("move onto an existing key: expected %v, got %v", rpctypes.ErrKeyExists, err)
+	}
+
+	resp, err := kv.Move(ctx, "a/", "b/", clientv3.WithPrefix(), clientv3.WithOverwrite(), clientv3.WithPrevKV())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Moved != 2 || len(resp.PrevKvs) != 2 {
+		t.Errorf("moved %d keys, %d returned, want 2", resp.Moved, len(resp.PrevKvs))
+	}
+	gresp, err := kv.Get(ctx, "b/", clientv3.WithPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gresp.Kvs) != 2 || gresp.Kvs[0].ModRevision != resp.Header.Revision || gresp.Kvs[1].ModRevision != resp.Header.Revision {
+		t.Errorf("moved keys %v, want both at revision %d", gresp.Kvs, resp.Header.Revision)
+	}
+	if _, err = kv.Move(ctx, "c", "d"); err != nil {
+		t.Fatal(err)
+	}
+
+	gresp, err = kv.Get(ctx, "", clientv3.WithFromKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kvs []string
+	for _, kv := range gresp.Kvs {
+		if kv.Lease != int64(lresp.ID) {
+			t.Errorf("%q: lease %x, want %x", kv.Key, kv.Lease, lresp.ID)
+		}
+		kvs = append(kvs, string(kv.Key)+"="+string(kv.Value))
+	}
+	if wkvs := []string{"b/1=a/1", "b/2=a/2", "d=c"}; !reflect.DeepEqual(kvs, wkvs) {
+		t.Errorf("keys = %v, want %v", kvs, wkvs)
+	}
+}
+
+func TestKVSetLease(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	kv := clus.RandClient()
+	ctx := context.TODO()
+
+	lresp, err := clus.RandClient().Grant(ctx, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a/1", "a/2", "b"} {
+		if _, err = kv.Put(ctx, key, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err = kv.SetLease(ctx, "a/", 12345, clientv3.WithPrefix()); err != rpctypes.ErrLeaseNotFound {
+		t.Errorf("set of a missing lease: expected %v, got %v", rpctypes.ErrLeaseNotFound, err)
+	}
+
+	// attached in place
+	resp, err := kv.SetLease(ctx, "a/", lresp.ID, clientv3.WithPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Count != 2 {
+		t.Errorf("count = %d, want 2", resp.Count)
+	}
+	gresp, err := kv.Get(ctx, "", clientv3.WithFromKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gresp.Header.Revision != resp.Header.Revision || gresp.Header.Revision != 4 {
+		t.Errorf("revision = %d, want 4", gresp.Header.Revision)
+	}
+	for _, kv := range gresp.Kvs {
+		wlease := int64(lresp.ID)
+		if string(kv.Key) == "b" {
+			wlease = 0
+		}
+		if kv.Lease != wlease || kv.Version != 1 {
+			t.Errorf("%q: lease %x, version %d, want lease %x, version 1", kv.Key, kv.Lease, kv.Version, wlease)
+		}
+	}
+
+	// detached with a new revision
+	if resp, err = kv.SetLease(ctx, "a/1", clientv3.NoLease, clientv3.WithBumpRevision()); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Count != 1 || resp.Header.Revision != 5 {
+		t.Errorf("count = %d at revision %d, want 1 at revision 5", resp.Count, resp.Header.Revision)
+	}
+
+	if _, err = clus.RandClient().Revoke(ctx, lresp.ID); err != nil {
+		t.Fatal(err)
+	}
+	if gresp, err = kv.Get(ctx, "", clientv3.WithFromKey()); err != nil {
+		t.Fatal(err)
+	}
+	var kvs []string
+	for _, kv := range gresp.Kvs {
+		kvs = append(kvs, string(kv.Key)+"="+string(kv.Value))
+	}
+	if wkvs := []string{"a/1=a/1", "b=b"}; !reflect.DeepEqual(kvs, wkvs) {
+		t.Errorf("keys = %v, want %v", kvs, wkvs)
+	}
+}
+
+func TestKVIncrement(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	ctx := context.TODO()
+	kv := clus.RandClient()
+
+	lresp, err := kv.Grant(ctx, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := kv.Increment(ctx, "counter", 5, clientv3.WithIncrementInitial(10), clientv3.WithIncrementWidth(4), clientv3.WithLease(lresp.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Value != 15 || !resp.Created {
+		t.Errorf("value = %d, created = %v, want 15, true", resp.Value, resp.Created)
+	}
+
+	// the increments of the clients do not conflict
+	const clients, increments = 3, 10
+	errc := make(chan error, clients)
+	for i := 0; i < clients; i++ {
+		go func(c *clientv3.Client) {
+			for j := 0; j < increments; j++ {
+				if _, err := c.Increment(ctx, "counter", 1); err != nil {
+					errc <- err
+					return
+				}
+			}
+			errc <- nil
+		}(clus.Client(i))
+	}
+	for i := 0; i < clients; i++ {
+		if err = <-errc; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if resp, err = kv.Increment(ctx, "counter", -50, clientv3.WithIncrementWidth(4)); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Value != -5 || resp.Created {
+		t.Errorf("value = %d, created = %v, want -5, false", resp.Value, resp.Created)
+	}
+	gresp, err := kv.Get(ctx, "counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := string(gresp.Kvs[0].Value); v != "-0005" || gresp.Kvs[0].Lease != int64(lresp.ID) {
+		t.Errorf("value %q lease %x, want %q lease %x", v, gresp.Kvs[0].Lease, "-0005", lresp.ID)
+	}
+
+	if resp, err = kv.Increment(ctx, "binary", -2, clientv3.WithIncrementBinary()); err != nil {
+		t.Fatal(err)
+	}
+	if gresp, err = kv.Get(ctx, "binary"); err != nil {
+		t.Fatal(err)
+	}
+	if v := gresp.Kvs[0].Value; resp.Value != -2 || !bytes.Equal(v, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe}) {
+		t.Errorf("value %d stored as %x, want -2", resp.Value, v)
+	}
+
+	if _, err = kv.Put(ctx, "foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = kv.Increment(ctx, "foo", 1); err != rpctypes.ErrValueNotNumeric {
+		t.Errorf("increment of a non numeric value: expected %v, got %v", rpctypes.ErrValueNotNumeric, err)
+	}
+	if _, err = kv.Put(ctx, "max", strconv.FormatInt(math.MaxInt64, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = kv.Increment(ctx, "max", 1); err != rpctypes.ErrIncrementOverflow {
+		t.Errorf("increment overflowing the value: expected %v, got %v", rpctypes.ErrIncrementOverflow, err)
+	}
+}
+
+func TestKVPutIfAbsentGetAndDelete(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	ctx := context.TODO()
+	kv := clus.RandClient()
+
+	lresp, err := kv.Grant(ctx, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	presp, err := kv.PutIfAbsent(ctx, "foo", "bar", clientv3.WithLease(lresp.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !presp.Succeeded || presp.PrevKv != nil {
+		t.Errorf("succeeded = %v, prev kv = %v, want the key put", presp.Succeeded, presp.PrevKv)
+	}
+	if presp, err = kv.PutIfAbsent(ctx, "foo", "baz"); err != nil {
+		t.Fatal(err)
+	}
+	if presp.Succeeded || presp.PrevKv == nil || string(presp.PrevKv.Value) != "bar" || presp.PrevKv.Lease != int64(lresp.ID) {
+		t.Errorf("succeeded = %v, prev kv = %v, want foo=bar not overwritten", presp.Succeeded, presp.PrevKv)
+	}
+
+	dresp, err := kv.GetAndDelete(ctx, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dresp.PrevKv == nil || string(dresp.PrevKv.Value) != "bar" || dresp.PrevKv.ModRevision != presp.PrevKv.ModRevision {
+		t.Errorf("prev kv = %v, want foo=bar deleted", dresp.PrevKv)
+	}
+	if dresp, err = kv.GetAndDelete(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if dresp.PrevKv != nil {
+		t.Errorf("prev kv = %v, want nil for a missing key", dresp.PrevKv)
+	}
+	gresp, err := kv.Get(ctx, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gresp.Kvs) != 0 {
+		t.Errorf("foo = %v, want deleted", gresp.Kvs)
+	}
+
+	if _, err = kv.PutIfAbsent(ctx, "", "bar"); err != rpctypes.ErrEmptyKey {
+		t.Errorf("put of an empty key: expected %v, got %v", rpctypes.ErrEmptyKey, err)
+	}
+}
+
+func TestKVBulkWrite(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	ctx := context.TODO()
+	kv := clus.RandClient()
+
+	if _, err := kv.Put(ctx, "old", "1"); err != nil {
+		t.Fatal(err)
+	}
+	const puts = 1000
+	var ops []clientv3.Op
+	for i := 0; i < puts; i++ {
+		ops = append(ops, clientv3.OpPut(fmt.Sprintf("key/%04d", i), "v"))
+	}
+	// the put with a missing lease fails, but not the others
+	ops = append(ops, clientv3.OpPut("leased", "v", clientv3.WithLease(12345)), clientv3.OpDelete("old", clientv3.WithPrevKV()))
+	resp, err := kv.BulkWrite(ctx, ops...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != len(ops) {
+		t.Fatalf("got %d results, want %d", len(resp.Results), len(ops))
+	}
+	for i := 0; i < puts; i++ {
+		if r := resp.Results[i]; r.Error != "" || r.Response.GetResponsePut() == nil {
+			t.Fatalf("put #%d: unexpected result %+v", i, r)
+		}
+	}
+	if r := resp.Results[puts]; r.Error == "" || r.Response != nil {
+		t.Errorf("put with a missing lease: unexpected result %+v", r)
+	}
+	if dr := resp.Results[puts+1].Response.GetResponseDeleteRange(); dr == nil || dr.Deleted != 1 || string(dr.PrevKvs[0].Value) != "1" {
+		t.Errorf("delete: unexpected result %+v", resp.Results[puts+1])
+	}
+
+	gresp, err := kv.Get(ctx, "key/", clientv3.WithPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gresp.Kvs) != puts {
+		t.Fatalf("got %d keys, want %d", len(gresp.Kvs), puts)
+	}
+	for _, kv := range gresp.Kvs {
+		if kv.ModRevision != resp.Header.Revision {
+			t.Fatalf("%q: revision %d, want all the writes at revision %d", kv.Key, kv.ModRevision, resp.Header.Revision)
+		}
+	}
+	if gresp, err = kv.Get(ctx, "old"); err != nil || len(gresp.Kvs) != 0 {
+		t.Errorf("old = %v (%v), want deleted", gresp.Kvs, err)
+	}
+
+	if _, err = kv.BulkWrite(ctx, clientv3.OpPut("a", "1"), clientv3.OpPut("a", "2")); err != rpctypes.ErrDuplicateKey {
+		t.Errorf("bulk write of a key twice: expected %v, got %v", rpctypes.ErrDuplicateKey, err)
+	}
+	if _, err = kv.BulkWrite(ctx, clientv3.OpGet("a")); err != rpctypes.ErrBulkWriteOpNotSupported {
+		t.Errorf("bulk write of a get: expected %v, got %v", rpctypes.ErrBulkWriteOpNotSupported, err)
+	}
+}
+
+func TestKVGetSortByValueAndLease(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	ctx := context.TODO()
+	kv := clus.RandClient()
+
+	// the keys, values and leases sort in different orders
+	for i := 0; i < 20; i++ {
+		lresp, err := kv.Grant(ctx, 60)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, val := fmt.Sprintf("key/%02d", 20-i), fmt.Sprintf("%02d", (i*7)%20)
+		if _, err = kv.Put(ctx, key, val, clientv3.WithLease(lresp.ID)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gresp, err := kv.Get(ctx, "key/", clientv3.WithPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		target clientv3.SortTarget
+		order  clientv3.SortOrder
+		limit  int64
+	}{
+		{target: clientv3.SortByValue, order: clientv3.SortAscend},
+		{target: clientv3.SortByValue, order: clientv3.SortAscend, limit: 5},
+		{target: clientv3.SortByValue, order: clientv3.SortDescend, limit: 7},
+		{target: clientv3.SortByLease, order: clientv3.SortAscend},
+		{target: clientv3.SortByLease, order: clientv3.SortAscend, limit: 3},
+		{target: clientv3.SortByLease, order: clientv3.SortDescend, limit: 6},
+	}
+	for i, tt := range tests {
+		resp, err := kv.Get(ctx, "key/", clientv3.WithPrefix(), clientv3.WithSort(tt.target, tt.order), clientv3.WithLimit(tt.limit))
+		if err != nil {
+			t.Fatal(err)
+		}
+		wkvs := append([]*mvccpb.KeyValue{}, gresp.Kvs...)
+		sort.Slice(wkvs, func(i, j int) bool {
+			less := bytes.Compare(wkvs[i].Value, wkvs[j].Value) < 0
+			if tt.target == clientv3.SortByLease {
+				less = wkvs[i].Lease < wkvs[j].Lease
+			}
+			if tt.order == clientv3.SortDescend {
+				return !less
+			}
+			return less
+		})
+		if tt.limit != 0 {
+			wkvs = wkvs[:tt.limit]
+		}
+		if len(resp.Kvs) != len(wkvs) || resp.More != (tt.limit != 0) {
+			t.Fatalf("#%d: got %d keys (more %v), want %d", i, len(resp.Kvs), resp.More, len(wkvs))
+		}
+		for j := range wkvs {
+			if !bytes.Equal(resp.Kvs[j].Key, wkvs[j].Key) {
+				t.Errorf("#%d: key #%d = %q, want %q", i, j, resp.Kvs[j].Key, wkvs[j].Key)
+			}
+		}
+	}
+}
+
 func TestKVCompactError(t *testing.T) {
 	integration2.BeforeTest(t)
 
@@ -23,6 +23,7 @@ import (
 	"testing"
 	"time"
 
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/client/v3/concurrency"
@@ -95,6 +96,319 @@ func TestLeaseRevoke(t *testing.T) {
 	}
 }
 
+func TestLeaseBulk(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	lapi := clus.RandClient()
+
+	gresp, err := lapi.GrantBulk(context.Background(), []int64{10, 20})
+	if err != nil {
+		t.Fatalf("failed to create leases %v", err)
+	}
+	if len(gresp.Leases) != 2 {
+		t.Fatalf("len(leases) = %d, want 2", len(gresp.Leases))
+	}
+	var ids []clientv3.LeaseID
+	for i, ttl := range []int64{10, 20} {
+		if l := gresp.Leases[i]; l.Error != "" || l.TTL != ttl {
+			t.Fatalf("#%d: lease = %+v, want TTL %d", i, l, ttl)
+		}
+		ids = append(ids, gresp.Leases[i].ID)
+	}
+
+	rresp, err := lapi.RevokeBulk(context.Background(), append(ids, 1234)...)
+	if err != nil {
+		t.Fatalf("failed to revoke leases %v", err)
+	}
+	if len(rresp.Revoked) != 2 || len(rresp.NotFound) != 1 {
+		t.Fatalf("revoked = %v, not found = %v, want 2 revoked and 1 not found", rresp.Revoked, rresp.NotFound)
+	}
+
+	_, err = clus.RandClient().Put(context.TODO(), "foo", "bar", clientv3.WithLease(ids[0]))
+	if err != rpctypes.ErrLeaseNotFound {
+		t.Fatalf("err = %v, want %v", err, rpctypes.ErrLeaseNotFound)
+	}
+}
+
+// TestLeaseGrantTooManyLeases ensures an authenticated user cannot hold more
+// than the maximum leases per user.
+func TestLeaseGrantTooManyLeases(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1, MaxLeasesPerUser: 2})
+	defer clus.Terminate(t)
+
+	cli := clus.Client(0)
+	if _, err := cli.UserAdd(context.TODO(), "root", "123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.UserGrantRole(context.TODO(), "root", "root"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.AuthEnable(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	rootc, err := integration2.NewClient(t, clientv3.Config{
+		Endpoints:   cli.Endpoints(),
+		DialTimeout: 5 * time.Second,
+		Username:    "root",
+		Password:    "123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rootc.Close()
+
+	if _, err = rootc.GrantBulk(context.TODO(), []int64{10, 10, 10}); err != rpctypes.ErrTooManyLeases {
+		t.Fatalf("err = %v, want %v", err, rpctypes.ErrTooManyLeases)
+	}
+	var ids []clientv3.LeaseID
+	for i := 0; i < 2; i++ {
+		resp, gerr := rootc.Grant(context.TODO(), 10)
+		if gerr != nil {
+			t.Fatal(gerr)
+		}
+		ids = append(ids, resp.ID)
+	}
+	if _, err = rootc.Grant(context.TODO(), 10); err != rpctypes.ErrTooManyLeases {
+		t.Fatalf("err = %v, want %v", err, rpctypes.ErrTooManyLeases)
+	}
+
+	// a lease revoked frees its slot
+	if _, err = rootc.Revoke(context.TODO(), ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = rootc.Grant(context.TODO(), 10); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestLeaseGrantTooManyLeasesConcurrently ensures the maximum leases per
+// user holds for the grants proposed concurrently.
+func TestLeaseGrantTooManyLeasesConcurrently(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3, MaxLeasesPerUser: 2})
+	defer clus.Terminate(t)
+
+	cli := clus.Client(0)
+	if _, err := cli.UserAdd(context.TODO(), "root", "123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.UserGrantRole(context.TODO(), "root", "root"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.AuthEnable(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	var clients []*clientv3.Client
+	for i := range clus.Members {
+		c, err := integration2.NewClient(t, clientv3.Config{
+			Endpoints:   clus.Client(i).Endpoints(),
+			DialTimeout: 5 * time.Second,
+			Username:    "root",
+			Password:    "123",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		clients = append(clients, c)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		granted int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(c *clientv3.Client) {
+			defer wg.Done()
+			_, err := c.Grant(context.TODO(), 10)
+			if err == rpctypes.ErrTooManyLeases {
+				return
+			}
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			granted++
+			mu.Unlock()
+		}(clients[i%len(clients)])
+	}
+	wg.Wait()
+	if granted != 2 {
+		t.Fatalf("granted %d leases, want 2", granted)
+	}
+
+	resp, err := clients[0].Leases(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Leases) != 2 {
+		t.Fatalf("len(leases) = %d, want 2", len(resp.Leases))
+	}
+}
+
+// TestLeaseKeepAliveOnceAuth ensures a lease is kept alive by a single keep
+// alive request only from its owner or the root user once auth is enabled.
+func TestLeaseKeepAliveOnceAuth(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.Client(0)
+	for _, user := range []string{"root", "alice", "bob"} {
+		if _, err := cli.UserAdd(context.TODO(), user, "123"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := cli.UserGrantRole(context.TODO(), "root", "root"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.AuthEnable(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	userClient := func(user string) *clientv3.Client {
+		c, err := integration2.NewClient(t, clientv3.Config{
+			Endpoints:   cli.Endpoints(),
+			DialTimeout: 5 * time.Second,
+			Username:    user,
+			Password:    "123",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	alicec, bobc, rootc := userClient("alice"), userClient("bob"), userClient("root")
+	defer alicec.Close()
+	defer bobc.Close()
+	defer rootc.Close()
+
+	gresp, err := alicec.Grant(context.TODO(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &pb.LeaseKeepAliveRequest{ID: int64(gresp.ID)}
+
+	tests := []struct {
+		c    *clientv3.Client
+		werr error
+	}{
+		{alicec, nil},
+		{rootc, nil},
+		{bobc, rpctypes.ErrPermissionDenied},
+		{cli, rpctypes.ErrUserEmpty},
+	}
+	for i, tt := range tests {
+		resp, err := clientv3.RetryLeaseClient(tt.c).LeaseKeepAliveOnce(context.TODO(), req)
+		if err != nil {
+			if rpctypes.Error(err) != tt.werr {
+				t.Errorf("#%d: err = %v, want %v", i, err, tt.werr)
+			}
+			continue
+		}
+		if tt.werr != nil {
+			t.Errorf("#%d: err = nil, want %v", i, tt.werr)
+		} else if resp.TTL != 10 {
+			t.Errorf("#%d: TTL = %d, want 10", i, resp.TTL)
+		}
+	}
+}
+
+func TestLeaseEvents(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wch := cli.Watch(ctx, clientv3.LeaseEventsPrefix, clientv3.WithPrefix(), clientv3.WithCreatedNotify())
+	if resp := <-wch; !resp.Created {
+		t.Fatalf("expected created response, got %+v", resp)
+	}
+	// a watch of the whole keyspace does not receive the lease events
+	wchAll := cli.Watch(ctx, "\x00", clientv3.WithFromKey(), clientv3.WithCreatedNotify())
+	if resp := <-wchAll; !resp.Created {
+		t.Fatalf("expected created response, got %+v", resp)
+	}
+
+	var pending []*clientv3.Event
+	recv := func() clientv3.LeaseEvent {
+		for len(pending) == 0 {
+			select {
+			case resp := <-wch:
+				pending = resp.Events
+			case <-time.After(10 * time.Second):
+				t.Fatal("failed to receive lease event")
+			}
+		}
+		le, err := clientv3.ParseLeaseEvent(pending[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		pending = pending[1:]
+		return *le
+	}
+
+	gresp, err := cli.Grant(context.TODO(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.Put(context.TODO(), "foo", "bar", clientv3.WithLease(gresp.ID)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.Revoke(context.TODO(), gresp.ID); err != nil {
+		t.Fatal(err)
+	}
+	wevs := []clientv3.LeaseEvent{
+		{Type: clientv3.LeaseEventGranted, ID: gresp.ID, TTL: 10},
+		{Type: clientv3.LeaseEventRevoked, ID: gresp.ID},
+		{Type: clientv3.LeaseEventKeysDeleted, ID: gresp.ID, Keys: []string{"foo"}},
+	}
+	for i, wev := range wevs {
+		if ev := recv(); !reflect.DeepEqual(ev, wev) {
+			t.Errorf("#%d: event = %+v, want %+v", i, ev, wev)
+		}
+	}
+
+	gresp, err = cli.Grant(context.TODO(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev := recv(); ev.Type != clientv3.LeaseEventGranted || ev.ID != gresp.ID {
+		t.Errorf("event = %+v, want lease %016x granted", ev, gresp.ID)
+	}
+	if ev := recv(); ev.Type != clientv3.LeaseEventExpired || ev.ID != gresp.ID {
+		t.Errorf("event = %+v, want lease %016x expired", ev, gresp.ID)
+	}
+
+	for resp := range wchAll {
+		for _, ev := range resp.Events {
+			if string(ev.Kv.Key) != "foo" {
+				t.Fatalf("unexpected event on key %q", ev.Kv.Key)
+			}
+		}
+		if len(resp.Events) > 0 && resp.Events[0].Type == clientv3.EventTypeDelete {
+			break
+		}
+	}
+
+	_, err = cli.Put(context.TODO(), clientv3.LeaseEventKey(gresp.ID), "bar")
+	if err != rpctypes.ErrKeyReserved {
+		t.Fatalf("err = %v, want %v", err, rpctypes.ErrKeyReserved)
+	}
+}
+
 func TestLeaseKeepAliveOnce(t *testing.T) {
 	integration2.BeforeTest(t)
 
@@ -651,6 +965,70 @@ func TestLeaseLeases(t *testing.T) {
 	}
 }
 
+func TestLeaseLeasesPagedAndFiltered(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+
+	var ids []clientv3.LeaseID
+	for i, ttl := range []int64{10, 100, 10, 100, 10} {
+		resp, err := cli.Grant(context.Background(), ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, resp.ID)
+		key := fmt.Sprintf("/other/%d", i)
+		if i%2 == 1 {
+			key = fmt.Sprintf("/sessions/%d", i)
+		}
+		if _, err = cli.Put(context.Background(), key, "v", clientv3.WithLease(resp.ID)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// pages of 2 leases
+	var got []clientv3.LeaseID
+	from := clientv3.LeaseID(0)
+	for {
+		resp, err := cli.Leases(context.Background(), clientv3.WithLeasesLimit(2), clientv3.WithLeasesFromID(from))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, l := range resp.Leases {
+			if l.KeyCount != 1 || l.TTL <= 0 || (l.GrantedTTL != 10 && l.GrantedTTL != 100) {
+				t.Errorf("unexpected lease status %+v", l)
+			}
+			got = append(got, l.ID)
+		}
+		if !resp.More {
+			break
+		}
+		from = resp.Leases[len(resp.Leases)-1].ID + 1
+	}
+	if !reflect.DeepEqual(got, ids) {
+		t.Fatalf("leases = %v, want %v", got, ids)
+	}
+
+	resp, err := cli.Leases(context.Background(), clientv3.WithLeasesKeyPrefix("/sessions/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Leases) != 2 || resp.Leases[0].ID != ids[1] || resp.Leases[1].ID != ids[3] {
+		t.Errorf("leases with keys under /sessions/ = %+v, want %v and %v", resp.Leases, ids[1], ids[3])
+	}
+
+	resp, err = cli.Leases(context.Background(), clientv3.WithLeasesTTLRange(50, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Leases) != 2 || resp.Leases[0].ID != ids[1] || resp.Leases[1].ID != ids[3] {
+		t.Errorf("leases with a TTL of 50s or more = %+v, want %v and %v", resp.Leases, ids[1], ids[3])
+	}
+}
+
 // TestLeaseRenewLostQuorum ensures keepalives work after losing quorum
 // for a while.
 func TestLeaseRenewLostQuorum(t *testing.T) {
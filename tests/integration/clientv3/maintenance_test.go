@@ -21,13 +21,16 @@ import (
 	"io"
 	"math"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
 	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest"
 	"google.golang.org/grpc"
 
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.etcd.io/etcd/api/v3/version"
 	"go.etcd.io/etcd/client/v3"
@@ -317,3 +320,615 @@ func TestMaintenanceStatus(t *testing.T) {
 		t.Fatal("no leader found")
 	}
 }
+
+func TestMaintenanceBackendBatch(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ep := clus.Members[0].GRPCURL()
+
+	resp, err := cli.BackendBatch(context.TODO(), ep, 50*time.Millisecond, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.BatchIntervalMs != 50 || resp.BatchLimit != 500 {
+		t.Fatalf("expected batch interval 50ms and limit 500, got %dms and %d", resp.BatchIntervalMs, resp.BatchLimit)
+	}
+
+	// zero values keep the current setting
+	resp, err = cli.BackendBatch(context.TODO(), ep, 0, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.BatchIntervalMs != 50 || resp.BatchLimit != 1000 {
+		t.Fatalf("expected batch interval 50ms and limit 1000, got %dms and %d", resp.BatchIntervalMs, resp.BatchLimit)
+	}
+
+	sresp, err := cli.Status(context.TODO(), ep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sresp.BackendBatchIntervalMs != 50 || sresp.BackendBatchLimit != 1000 {
+		t.Fatalf("expected status batch interval 50ms and limit 1000, got %dms and %d", sresp.BackendBatchIntervalMs, sresp.BackendBatchLimit)
+	}
+
+	if _, err = cli.BackendBatch(context.TODO(), ep, time.Hour, 0); err != rpctypes.ErrInvalidBackendBatchInterval {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrInvalidBackendBatchInterval, err)
+	}
+	if _, err = cli.BackendBatch(context.TODO(), ep, 0, -1); err != rpctypes.ErrInvalidBackendBatchLimit {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrInvalidBackendBatchLimit, err)
+	}
+}
+
+func TestMaintenanceLogLevel(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ep := clus.Members[0].GRPCURL()
+
+	resp, err := cli.LogLevel(context.TODO(), ep, "debug", clientv3.GRPCTracingKeep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Level != "debug" {
+		t.Fatalf("expected log level debug, got %q", resp.Level)
+	}
+	if !clus.Members[0].Logger.Core().Enabled(zapcore.DebugLevel) {
+		t.Fatal("expected the member logger to be changed to debug")
+	}
+
+	// an empty level keeps the current level
+	resp, err = cli.LogLevel(context.TODO(), ep, "", clientv3.GRPCTracingKeep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Level != "debug" {
+		t.Fatalf("expected log level debug, got %q", resp.Level)
+	}
+
+	if _, err = cli.LogLevel(context.TODO(), ep, "verbose", clientv3.GRPCTracingKeep); err != rpctypes.ErrInvalidLogLevel {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrInvalidLogLevel, err)
+	}
+	// the gRPC logger is not installed by the integration framework
+	if _, err = cli.LogLevel(context.TODO(), ep, "", clientv3.GRPCTracingEnable); err != rpctypes.ErrGRPCTracingNotAdjustable {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrGRPCTracingNotAdjustable, err)
+	}
+}
+
+func TestMaintenanceWatchStreams(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ep := clus.Members[0].GRPCURL()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wch := cli.Watch(ctx, "foo", clientv3.WithCreatedNotify())
+	if wresp := <-wch; !wresp.Created {
+		t.Fatalf("expected created watch response, got %+v", wresp)
+	}
+
+	resp, err := cli.WatchStreams(context.TODO(), ep, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Streams) != 1 {
+		t.Fatalf("expected 1 watch stream, got %d", len(resp.Streams))
+	}
+	if st := resp.Streams[0]; st.Watchers != 1 || st.Remote == "" {
+		t.Fatalf("unexpected watch stream status %+v", st)
+	}
+}
+
+func TestMaintenanceWatchers(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ep := clus.Members[0].GRPCURL()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wch := cli.Watch(ctx, "foo", clientv3.WithPrefix(), clientv3.WithCreatedNotify())
+	if wresp := <-wch; !wresp.Created {
+		t.Fatalf("expected created watch response, got %+v", wresp)
+	}
+
+	resp, err := cli.Watchers(context.TODO(), ep, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Watchers) != 1 {
+		t.Fatalf("expected 1 watcher, got %d", len(resp.Watchers))
+	}
+	w := resp.Watchers[0]
+	if w.Remote == "" || len(w.Ranges) != 1 || string(w.Ranges[0].Key) != "foo" || string(w.Ranges[0].RangeEnd) != "fop" || w.RevisionLag != 0 {
+		t.Fatalf("unexpected watcher status %+v", w)
+	}
+
+	if _, err = cli.CancelWatcher(context.TODO(), ep, w.StreamId, w.WatchId+1); err != rpctypes.ErrWatcherNotFound {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrWatcherNotFound, err)
+	}
+	if _, err = cli.CancelWatcher(context.TODO(), ep, w.StreamId, w.WatchId); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case wresp, ok := <-wch:
+		if !ok || !wresp.Canceled || wresp.Err() != rpctypes.ErrWatchCanceledByAdmin {
+			t.Fatalf("expected %v, got canceled=%v err=%v", rpctypes.ErrWatchCanceledByAdmin, wresp.Canceled, wresp.Err())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the cancelation of the watcher")
+	}
+	if _, ok := <-wch; ok {
+		t.Fatal("expected the watch channel to be closed")
+	}
+}
+
+func TestMaintenanceHotKeys(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1, HotKeySampleRate: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ep := clus.Members[0].GRPCURL()
+
+	for i := 0; i < 3; i++ {
+		if _, err := cli.Put(context.TODO(), fmt.Sprintf("/hot/%d", i), "v"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := cli.Get(context.TODO(), "/hot/", clientv3.WithPrefix()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.Put(context.TODO(), "/cold/0", "v"); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := cli.HotKeys(context.TODO(), ep, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.SampleRate != 1 {
+		t.Errorf("expected sample rate 1, got %v", resp.SampleRate)
+	}
+	if len(resp.Prefixes) != 1 {
+		t.Fatalf("expected 1 hot key prefix, got %d", len(resp.Prefixes))
+	}
+	if p := resp.Prefixes[0]; string(p.Prefix) != "/hot/" || p.Reads != 1 || p.Writes != 3 {
+		t.Fatalf("expected /hot/ with 1 read and 3 writes, got %+v", p)
+	}
+}
+
+func TestMaintenanceHotKeysMoveAndIncrement(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1, HotKeySampleRate: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ep := clus.Members[0].GRPCURL()
+
+	if _, err := cli.Put(context.TODO(), "/src/0", "v"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.Move(context.TODO(), "/src/0", "/dst/0"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := cli.Increment(context.TODO(), "/ctr/0", 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp, err := cli.HotKeys(context.TODO(), ep, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writes := make(map[string]int64)
+	for _, p := range resp.Prefixes {
+		writes[string(p.Prefix)] = p.Writes
+	}
+	want := map[string]int64{"/src/": 2, "/dst/": 1, "/ctr/": 2}
+	if !reflect.DeepEqual(writes, want) {
+		t.Fatalf("expected writes %v, got %v", want, writes)
+	}
+}
+
+func TestMaintenanceHotKeysBulkWrite(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1, HotKeySampleRate: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ep := clus.Members[0].GRPCURL()
+
+	_, err := cli.BulkWrite(context.TODO(),
+		clientv3.OpPut("/a/0", "v"),
+		clientv3.OpPut("/a/1", "v"),
+		clientv3.OpDelete("/b/", clientv3.WithPrefix()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := cli.HotKeys(context.TODO(), ep, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writes := make(map[string]int64)
+	for _, p := range resp.Prefixes {
+		writes[string(p.Prefix)] = p.Writes
+	}
+	want := map[string]int64{"/a/": 2, "/b/": 1}
+	if !reflect.DeepEqual(writes, want) {
+		t.Fatalf("expected writes %v, got %v", want, writes)
+	}
+}
+
+func TestMaintenanceHotKeysDisabled(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	if _, err := cli.HotKeys(context.TODO(), clus.Members[0].GRPCURL(), 0); err != rpctypes.ErrHotKeySamplingDisabled {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrHotKeySamplingDisabled, err)
+	}
+}
+
+func TestMaintenanceClusterHistory(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ep := clus.Members[0].GRPCURL()
+
+	mresp, err := cli.MemberAddAsLearner(context.TODO(), []string{"http://127.0.0.1:1234"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.MemberRemove(context.TODO(), mresp.Member.ID); err != nil {
+		t.Fatal(err)
+	}
+	alarm := &pb.AlarmRequest{MemberID: 123, Action: pb.AlarmRequest_ACTIVATE, Alarm: pb.AlarmType_NOSPACE}
+	if _, err = integration2.ToGRPC(cli).Maintenance.Alarm(context.TODO(), alarm); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.AlarmDisarm(context.TODO(), &clientv3.AlarmMember{MemberID: 123, Alarm: pb.AlarmType_NOSPACE}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := cli.ClusterHistory(context.TODO(), ep, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		applied   []*pb.ClusterEvent
+		elections int
+	)
+	for _, ev := range resp.Events {
+		if ev.Type == pb.ClusterEvent_LEADER_CHANGE {
+			if ev.MemberID != uint64(clus.Members[0].ID()) {
+				t.Errorf("expected leader %s, got %x", clus.Members[0].ID(), ev.MemberID)
+			}
+			elections++
+			continue
+		}
+		applied = append(applied, ev)
+	}
+	if elections != 1 {
+		t.Errorf("expected 1 leader change, got %d", elections)
+	}
+
+	want := []struct {
+		typ      pb.ClusterEvent_EventType
+		memberID uint64
+		detail   string
+	}{
+		{pb.ClusterEvent_MEMBER_ADD, uint64(clus.Members[0].ID()), "peer-urls=" + clus.Members[0].PeerURLs.String()},
+		{pb.ClusterEvent_MEMBER_ADD, mresp.Member.ID, "peer-urls=http://127.0.0.1:1234 learner"},
+		{pb.ClusterEvent_MEMBER_REMOVE, mresp.Member.ID, ""},
+		{pb.ClusterEvent_ALARM_ACTIVATE, 123, "NOSPACE"},
+		{pb.ClusterEvent_ALARM_DEACTIVATE, 123, "NOSPACE"},
+	}
+	if len(applied) != len(want) {
+		t.Fatalf("expected %d applied events, got %+v", len(want), applied)
+	}
+	var index uint64
+	for i, w := range want {
+		ev := applied[i]
+		if ev.Type != w.typ || ev.MemberID != w.memberID || ev.Detail != w.detail {
+			t.Errorf("event %d: expected %v of %x with %q, got %+v", i, w.typ, w.memberID, w.detail, ev)
+		}
+		if ev.Index <= index {
+			t.Errorf("event %d: expected index greater than %d, got %d", i, index, ev.Index)
+		}
+		index = ev.Index
+	}
+
+	resp, err = cli.ClusterHistory(context.TODO(), ep, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Events) != 1 || resp.Events[0].Type != pb.ClusterEvent_ALARM_DEACTIVATE {
+		t.Errorf("expected the last event, got %+v", resp.Events)
+	}
+}
+
+// TestMaintenanceDefragmentStatus ensures the member keeps serving the writes
+// and reports its progress while defragmenting incrementally.
+func TestMaintenanceDefragmentStatus(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1, DefragBatchLimit: 10, DefragBatchInterval: 5 * time.Millisecond})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	ep := clus.Members[0].GRPCURL()
+	for i := 0; i < 200; i++ {
+		if _, err := cli.Put(context.TODO(), fmt.Sprintf("foo%d", i), "bar"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resp, err := cli.DefragmentStatus(context.TODO(), ep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.InProgress || resp.StartTime != 0 {
+		t.Fatalf("expected no defragmentation, got %+v", resp)
+	}
+
+	donec := make(chan error)
+	go func() {
+		_, err := cli.Defragment(context.TODO(), ep)
+		donec <- err
+	}()
+	inProgress, puts := false, 0
+	for done := false; !done; {
+		select {
+		case err = <-donec:
+			if err != nil {
+				t.Fatal(err)
+			}
+			done = true
+		default:
+			if resp, err = cli.DefragmentStatus(context.TODO(), ep); err != nil {
+				t.Fatal(err)
+			}
+			inProgress = inProgress || resp.InProgress
+			if _, err = cli.Put(context.TODO(), fmt.Sprintf("foo%d", 200+puts), "bar"); err != nil {
+				t.Fatal(err)
+			}
+			puts++
+		}
+	}
+	if !inProgress || puts == 0 {
+		t.Fatalf("expected writes while the defragmentation is in progress, got %d puts", puts)
+	}
+
+	if resp, err = cli.DefragmentStatus(context.TODO(), ep); err != nil {
+		t.Fatal(err)
+	}
+	if resp.InProgress || resp.TotalKeys == 0 || resp.CopiedKeys != resp.TotalKeys || resp.StartTime == 0 {
+		t.Errorf("expected a finished defragmentation with all the keys copied, got %+v", resp)
+	}
+	gresp, err := cli.Get(context.TODO(), "foo", clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gresp.Count != int64(200+puts) {
+		t.Errorf("expected %d keys, got %d", 200+puts, gresp.Count)
+	}
+}
+
+// TestMaintenancePrefixQuota ensures the quotas of the key prefixes are
+// enforced and their usage is reported by every member, also once restarted.
+func TestMaintenancePrefixQuota(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	if _, err := cli.Put(context.TODO(), "tenant/a/1", "bar"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.PrefixQuotaSet(context.TODO(), "tenant/a/", 100, 2); err != nil {
+		t.Fatal(err)
+	}
+	lresp, err := cli.Grant(context.TODO(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.Put(context.TODO(), "tenant/a/2", "bar", clientv3.WithLease(lresp.ID)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.Put(context.TODO(), "tenant/a/3", "bar"); err != rpctypes.ErrPrefixQuotaExceeded {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPrefixQuotaExceeded, err)
+	}
+	_, err = cli.Txn(context.TODO()).If(clientv3.Compare(clientv3.Version("tenant/a/1"), "=", 0)).Else(clientv3.OpPut("tenant/a/3", "bar")).Commit()
+	if err != rpctypes.ErrPrefixQuotaExceeded {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPrefixQuotaExceeded, err)
+	}
+	if _, err = cli.Put(context.TODO(), "tenant/b/1", "bar"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.Put(context.TODO(), "tenant/a/1", "baz"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*pb.PrefixQuota{{Prefix: []byte("tenant/a/"), MaxBytes: 100, MaxKeys: 2, UsedBytes: 26, UsedKeys: 2}}
+	for i := range clus.Members {
+		resp, err := clus.Client(i).PrefixQuotaList(context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(resp.Quotas, want) {
+			t.Errorf("member %d: expected quotas %+v, got %+v", i, want, resp.Quotas)
+		}
+	}
+
+	// revoking the lease deletes its key, freeing its quota
+	if _, err = cli.Revoke(context.TODO(), lresp.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.Put(context.TODO(), "tenant/a/3", "bar"); err != nil {
+		t.Fatal(err)
+	}
+
+	clus.Members[0].Stop(t)
+	if err = clus.Members[0].Restart(t); err != nil {
+		t.Fatal(err)
+	}
+	clus.WaitLeader(t)
+	resp, err := clus.Client(0).PrefixQuotaList(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(resp.Quotas, want) {
+		t.Errorf("expected quotas %+v after restart, got %+v", want, resp.Quotas)
+	}
+
+	if _, err = cli.PrefixQuotaDelete(context.TODO(), "tenant/a/"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.Put(context.TODO(), "tenant/a/4", "bar"); err != nil {
+		t.Fatal(err)
+	}
+	if resp, err = cli.PrefixQuotaList(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Quotas) != 0 {
+		t.Errorf("expected no quota, got %+v", resp.Quotas)
+	}
+}
+
+func TestMaintenancePrefixStats(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.RandClient()
+	for _, kv := range [][2]string{{"tenant/a/1", "bar"}, {"tenant/a/2", "barbar"}, {"tenant/b/1", "bar"}} {
+		if _, err := cli.Put(context.TODO(), kv[0], kv[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	presp, err := cli.Put(context.TODO(), "tenant/a/2", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		prefix string
+
+		wcount, wkeyBytes, wvalueBytes int64
+	}{
+		{"tenant/a/", 2, 20, 4},
+		{"tenant/", 3, 30, 7},
+		{"", 3, 30, 7},
+		{"none/", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		resp, err := cli.PrefixStats(context.TODO(), tt.prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Count != tt.wcount || resp.KeyBytes != tt.wkeyBytes || resp.ValueBytes != tt.wvalueBytes {
+			t.Errorf("prefix %q: expected %d keys, %d key bytes and %d value bytes, got %+v", tt.prefix, tt.wcount, tt.wkeyBytes, tt.wvalueBytes, resp)
+		}
+		if resp.Header.Revision != presp.Header.Revision {
+			t.Errorf("prefix %q: expected revision %d, got %d", tt.prefix, presp.Header.Revision, resp.Header.Revision)
+		}
+	}
+}
+
+func TestMaintenanceLearnerStatus(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	leaderIdx := clus.WaitLeader(t)
+	leaderEp := clus.Members[leaderIdx].GRPCURL()
+	followerIdx := (leaderIdx + 1) % 3
+	cli := clus.Client(followerIdx)
+
+	resp, err := cli.LearnerStatus(context.TODO(), leaderEp, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Learners) != 0 {
+		t.Fatalf("expected no learner, got %+v", resp.Learners)
+	}
+
+	addResp, err := cli.MemberAddAsLearner(context.TODO(), []string{"http://127.0.0.1:1234"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	learnerID := addResp.Member.ID
+
+	// the learner is not started yet
+	resp, err = cli.LearnerStatus(context.TODO(), leaderEp, learnerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Learners) != 1 {
+		t.Fatalf("expected 1 learner, got %+v", resp.Learners)
+	}
+	if p := resp.Learners[0]; p.ID != learnerID || p.Ready || p.MatchIndex != 0 || p.LagEntries != p.LeaderIndex || p.EtaMs != -1 {
+		t.Errorf("unexpected progress of the learner not started %+v", p)
+	}
+
+	if _, err = cli.LearnerStatus(context.TODO(), clus.Members[followerIdx].GRPCURL(), 0); err != rpctypes.ErrNotLeader {
+		t.Errorf("expected %v from a follower, got %v", rpctypes.ErrNotLeader, err)
+	}
+	if _, err = cli.LearnerStatus(context.TODO(), leaderEp, uint64(clus.Members[followerIdx].ID())); err != rpctypes.ErrMemberNotLearner {
+		t.Errorf("expected %v for a voting member, got %v", rpctypes.ErrMemberNotLearner, err)
+	}
+
+	learner := clus.MustNewMember(t, addResp)
+	if err = learner.Launch(); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(5 * time.Second)
+	for {
+		resp, err = cli.LearnerStatus(context.TODO(), leaderEp, learnerID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p := resp.Learners[0]; p.Ready {
+			if p.EtaMs != 0 || p.MatchIndex == 0 {
+				t.Errorf("unexpected progress of the learner ready %+v", p)
+			}
+			break
+		}
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("learner not ready, last progress %+v", resp.Learners[0])
+		}
+	}
+	if _, err = cli.MemberPromote(context.TODO(), learnerID); err != nil {
+		t.Fatal(err)
+	}
+}
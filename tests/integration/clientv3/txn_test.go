@@ -189,6 +189,52 @@ func TestTxnCompareRange(t *testing.T) {
 	}
 }
 
+func TestTxnCompareValueAndExistence(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	kv := clus.Client(0)
+	for k, v := range map[string]string{"count": "9", "name": "etcd-server", "jobs/a": "1"} {
+		if _, err := kv.Put(context.TODO(), k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		cmp  clientv3.Cmp
+		want bool
+	}{
+		// 9 < 10 numerically, not as bytes
+		{clientv3.Compare(clientv3.ValueInt("count"), "<", 10), true},
+		{clientv3.Compare(clientv3.Value("count"), "<", "10"), false},
+		{clientv3.Compare(clientv3.ValueInt("count"), "=", 9), true},
+		{clientv3.Compare(clientv3.ValueInt("name"), "!=", 9), false},
+		{clientv3.Compare(clientv3.ValueInt("missing"), "!=", 9), false},
+		{clientv3.Compare(clientv3.ValuePrefix("name"), "=", "etcd-"), true},
+		{clientv3.Compare(clientv3.ValuePrefix("name"), "!=", "etcd-"), false},
+		{clientv3.Compare(clientv3.ValuePrefix("name"), "=", "etcd-server-long"), false},
+		{clientv3.Compare(clientv3.ValuePrefix("name"), ">", "abc"), true},
+		{clientv3.KeyExists("name"), true},
+		{clientv3.KeyExists("missing"), false},
+		{clientv3.KeyMissing("missing"), true},
+		{clientv3.KeyMissing("name"), false},
+		{clientv3.KeyExists("jobs/").WithPrefix(), true},
+		{clientv3.KeyMissing("jobs/").WithPrefix(), false},
+		{clientv3.KeyMissing("none/").WithPrefix(), true},
+	}
+	for i, tt := range tests {
+		tresp, err := kv.Txn(context.TODO()).If(tt.cmp).Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tresp.Succeeded != tt.want {
+			t.Errorf("#%d: succeeded = %v, want %v", i, tresp.Succeeded, tt.want)
+		}
+	}
+}
+
 func TestTxnNested(t *testing.T) {
 	integration2.BeforeTest(t)
 
@@ -21,6 +21,7 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -939,6 +940,299 @@ func TestWatchWithCreatedNotification(t *testing.T) {
 	}
 }
 
+// TestWatchIf ensures that WatchIf only creates the watcher if the
+// comparisons succeed, and then starts at the compared revision.
+func TestWatchIf(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	cluster := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer cluster.Terminate(t)
+
+	client := cluster.RandClient()
+	ctx := context.Background()
+
+	presp, err := client.Put(ctx, "a", "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wch := client.WatchIf(ctx, []clientv3.Cmp{clientv3.Compare(clientv3.Value("a"), "=", "xyz")}, "a")
+	resp, ok := <-wch
+	if !ok {
+		t.Fatal("expected canceled response before the channel closes")
+	}
+	if !resp.Canceled || resp.Err() != rpctypes.ErrWatchCompareFailed {
+		t.Fatalf("expected %v, got canceled=%v err=%v", rpctypes.ErrWatchCompareFailed, resp.Canceled, resp.Err())
+	}
+	if _, ok = <-wch; ok {
+		t.Fatal("expected closed watch channel")
+	}
+
+	cmp := clientv3.Compare(clientv3.ModRevision("a"), "=", presp.Header.Revision)
+	wch = client.WatchIf(ctx, []clientv3.Cmp{cmp}, "a", clientv3.WithCreatedNotify())
+	resp = <-wch
+	if !resp.Created || resp.Err() != nil {
+		t.Fatalf("expected created response, got %+v (%v)", resp, resp.Err())
+	}
+	if resp.Header.Revision != presp.Header.Revision {
+		t.Fatalf("expected watch to start after revision %d, got %d", presp.Header.Revision, resp.Header.Revision)
+	}
+
+	if _, err = client.Put(ctx, "a", "def"); err != nil {
+		t.Fatal(err)
+	}
+	resp = <-wch
+	if len(resp.Events) != 1 || string(resp.Events[0].Kv.Value) != "def" {
+		t.Fatalf("expected put event of %q, got %+v", "def", resp.Events)
+	}
+	if resp.Events[0].Kv.ModRevision != presp.Header.Revision+1 {
+		t.Fatalf("expected event at revision %d, got %d", presp.Header.Revision+1, resp.Events[0].Kv.ModRevision)
+	}
+}
+
+// TestWatchValuePredicates ensures that the put events whose values do not
+// satisfy the value predicates are not sent to the watcher.
+func TestWatchValuePredicates(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	cluster := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer cluster.Terminate(t)
+
+	client := cluster.RandClient()
+	ctx := context.Background()
+
+	wch := client.Watch(ctx, "pods/", clientv3.WithPrefix(), clientv3.WithValueJSONFieldEqual("status.phase", `"Running"`), clientv3.WithValueSize(0, 100))
+	for _, kv := range [][2]string{
+		{"pods/a", `{"status":{"phase":"Pending"}}`},
+		{"pods/b", `{"status":{"phase":"Running"},"padding":"` + strings.Repeat("x", 100) + `"}`},
+		{"pods/c", `not json`},
+		{"pods/d", `{"status":{"phase":"Running"}}`},
+	} {
+		if _, err := client.Put(ctx, kv[0], kv[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := client.Delete(ctx, "pods/a"); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []*clientv3.Event
+	for len(events) < 2 {
+		resp := <-wch
+		if err := resp.Err(); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, resp.Events...)
+	}
+	if len(events) != 2 || string(events[0].Kv.Key) != "pods/d" || events[1].Type != mvccpb.DELETE || string(events[1].Kv.Key) != "pods/a" {
+		t.Fatalf("expected put of pods/d and delete of pods/a, got %+v", events)
+	}
+
+	wch = client.Watch(ctx, "pods/", clientv3.WithPrefix(), clientv3.WithValueSize(10, 5))
+	resp, ok := <-wch
+	if !ok || !resp.Canceled || resp.Err() != rpctypes.ErrWatchInvalidValuePredicate {
+		t.Fatalf("expected %v, got canceled=%v err=%v", rpctypes.ErrWatchInvalidValuePredicate, resp.Canceled, resp.Err())
+	}
+}
+
+// TestWatchAdditionalRanges tests that a watcher on several ranges receives
+// the events of a transaction on any of them in one response.
+func TestWatchAdditionalRanges(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	cluster := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer cluster.Terminate(t)
+
+	client := cluster.RandClient()
+	ctx := context.Background()
+
+	wch := client.Watch(ctx, "pods/", clientv3.WithPrefix(),
+		clientv3.WithWatchRange("services/", clientv3.GetPrefixRangeEnd("services/")),
+		clientv3.WithWatchRange("config", ""),
+	)
+	_, err := client.Txn(ctx).Then(
+		clientv3.OpPut("pods/a", "1"),
+		clientv3.OpPut("nodes/a", "1"),
+		clientv3.OpPut("services/a", "1"),
+		clientv3.OpPut("config", "1"),
+		clientv3.OpPut("config2", "1"),
+	).Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := <-wch
+	if err = resp.Err(); err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, ev := range resp.Events {
+		keys = append(keys, string(ev.Kv.Key))
+	}
+	if want := []string{"pods/a", "services/a", "config"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("expected %v in one response, got %v", want, keys)
+	}
+}
+
+// TestWatchResumeToken tests that a watcher resumed with a resume token on
+// another member receives the events after the response of the token, once.
+func TestWatchResumeToken(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	cluster := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer cluster.Terminate(t)
+
+	ctx := context.Background()
+	kv := cluster.Client(0)
+	wctx, wcancel := context.WithCancel(ctx)
+	wch := cluster.Client(1).Watch(wctx, "foo", clientv3.WithPrefix(), clientv3.WithResumable(), clientv3.WithPrevKV())
+
+	if _, err := kv.Put(ctx, "foo1", "1"); err != nil {
+		t.Fatal(err)
+	}
+	resp := <-wch
+	if err := resp.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Events) != 1 || len(resp.ResumeToken) == 0 {
+		t.Fatalf("expected one event with a resume token, got %+v", resp)
+	}
+	token := resp.ResumeToken
+	wcancel()
+
+	for _, k := range []string{"foo1", "bar", "foo3"} {
+		if _, err := kv.Put(ctx, k, "2"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the key and the options of the watcher are the ones of the token
+	wch = cluster.Client(2).Watch(ctx, "bar", clientv3.WithResumeToken(token))
+	var keys []string
+	for len(keys) < 2 {
+		resp = <-wch
+		if err := resp.Err(); err != nil {
+			t.Fatal(err)
+		}
+		for _, ev := range resp.Events {
+			keys = append(keys, string(ev.Kv.Key))
+			if string(ev.Kv.Key) == "foo1" && (ev.PrevKv == nil || string(ev.PrevKv.Value) != "1") {
+				t.Errorf("expected previous value 1 of foo1, got %+v", ev.PrevKv)
+			}
+		}
+	}
+	if want := []string{"foo1", "foo3"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("expected %v, got %v", want, keys)
+	}
+
+	wch = cluster.Client(2).Watch(ctx, "", clientv3.WithResumeToken([]byte("invalid")))
+	resp, ok := <-wch
+	if !ok || !resp.Canceled || resp.Err() != rpctypes.ErrWatchInvalidResumeToken {
+		t.Fatalf("expected %v, got canceled=%v err=%v", rpctypes.ErrWatchInvalidResumeToken, resp.Canceled, resp.Err())
+	}
+}
+
+// TestWatchBatch tests that the events of a batching watcher are sent in
+// batches bounded by the number of events and by the latency.
+func TestWatchBatch(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	cluster := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer cluster.Terminate(t)
+
+	client := cluster.RandClient()
+	ctx := context.Background()
+
+	wch := client.Watch(ctx, "foo", clientv3.WithPrefix(), clientv3.WithBatch(time.Second, 3))
+	for i := 0; i < 4; i++ {
+		if _, err := client.Put(ctx, fmt.Sprintf("foo%d", i), "bar"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := time.Now()
+	var batches []int
+	for n := 0; n < 4; {
+		resp := <-wch
+		if err := resp.Err(); err != nil {
+			t.Fatal(err)
+		}
+		batches = append(batches, len(resp.Events))
+		n += len(resp.Events)
+	}
+	if !reflect.DeepEqual(batches, []int{3, 1}) {
+		t.Fatalf("expected batches of 3 and 1 events, got %v", batches)
+	}
+	if d := time.Since(start); d < 500*time.Millisecond {
+		t.Errorf("expected the last event sent after the latency, got it after %v", d)
+	}
+
+	wch = client.Watch(ctx, "foo", clientv3.WithBatch(0, 3))
+	resp, ok := <-wch
+	if !ok || !resp.Canceled || resp.Err() != rpctypes.ErrWatchInvalidBatchOptions {
+		t.Fatalf("expected %v, got canceled=%v err=%v", rpctypes.ErrWatchInvalidBatchOptions, resp.Canceled, resp.Err())
+	}
+}
+
+// TestWatchProjection tests that the watchers receive the projections of the
+// key-values of the events.
+func TestWatchProjection(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	cluster := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer cluster.Terminate(t)
+
+	client := cluster.RandClient()
+	ctx := context.Background()
+
+	kch := client.Watch(ctx, "pods/", clientv3.WithPrefix(), clientv3.WithEventKeysOnly())
+	jch := client.Watch(ctx, "pods/", clientv3.WithPrefix(), clientv3.WithEventJSONFields("status.phase"), clientv3.WithPrevKV())
+	if _, err := client.Put(ctx, "pods/a", `{"status":{"phase":"Pending"},"spec":"`+strings.Repeat("x", 1024)+`"}`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Put(ctx, "pods/a", `{"status":{"phase":"Running"}}`); err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []*mvccpb.KeyValue
+	for len(keys) < 2 {
+		resp := <-kch
+		if err := resp.Err(); err != nil {
+			t.Fatal(err)
+		}
+		for _, ev := range resp.Events {
+			keys = append(keys, ev.Kv)
+		}
+	}
+	for _, kv := range keys {
+		if string(kv.Key) != "pods/a" || len(kv.Value) != 0 || kv.ModRevision == 0 || kv.Version != 0 {
+			t.Errorf("expected the key and the mod revision of pods/a, got %+v", kv)
+		}
+	}
+
+	var values []string
+	for len(values) < 2 {
+		resp := <-jch
+		if err := resp.Err(); err != nil {
+			t.Fatal(err)
+		}
+		for _, ev := range resp.Events {
+			values = append(values, string(ev.Kv.Value))
+			if ev.PrevKv != nil {
+				values = append(values, string(ev.PrevKv.Value))
+			}
+		}
+	}
+	want := []string{`{"status.phase":"Pending"}`, `{"status.phase":"Running"}`, `{"status.phase":"Pending"}`}
+	if !reflect.DeepEqual(values, want) {
+		t.Fatalf("expected %v, got %v", want, values)
+	}
+
+	wch := client.Watch(ctx, "pods/", clientv3.WithEventJSONFields())
+	resp, ok := <-wch
+	if !ok || !resp.Canceled || resp.Err() != rpctypes.ErrWatchInvalidProjection {
+		t.Fatalf("expected %v, got canceled=%v err=%v", rpctypes.ErrWatchInvalidProjection, resp.Canceled, resp.Err())
+	}
+}
+
 // TestWatchWithCreatedNotificationDropConn ensures that
 // a watcher with created notify does not post duplicate
 // created events from disconnect.
@@ -1209,3 +1503,105 @@ func testWatchClose(t *testing.T, wctx *watchctx) {
 		t.Fatalf("read wch got %v; expected closed channel", wresp)
 	}
 }
+
+// TestWatchProgressNotifyInterval tests that the watchers are notified of
+// the progress at their own interval, and at every interval with heartbeats.
+func TestWatchProgressNotifyInterval(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	cluster := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer cluster.Terminate(t)
+
+	client := cluster.RandClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	wch := client.Watch(ctx, "foo", clientv3.WithProgressNotifyInterval(200*time.Millisecond))
+	select {
+	case resp := <-wch:
+		if !resp.IsProgressNotify() {
+			t.Fatalf("expected a progress notification, got %+v", resp)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for the progress notification")
+	}
+
+	// the heartbeats are sent even after the events of the watcher
+	hch := client.Watch(ctx, "bar", clientv3.WithProgressNotifyInterval(200*time.Millisecond), clientv3.WithProgressNotifyHeartbeat())
+	donec := make(chan struct{})
+	defer close(donec)
+	go func() {
+		for {
+			select {
+			case <-donec:
+				return
+			case <-time.After(20 * time.Millisecond):
+				client.Put(ctx, "bar", "baz")
+			}
+		}
+	}()
+	timeout := time.After(3 * time.Second)
+	for heartbeat := false; !heartbeat; {
+		select {
+		case resp := <-hch:
+			if err := resp.Err(); err != nil {
+				t.Fatal(err)
+			}
+			heartbeat = resp.IsProgressNotify()
+		case <-timeout:
+			t.Fatal("timed out waiting for the heartbeat")
+		}
+	}
+
+	ich := client.Watch(ctx, "foo", clientv3.WithProgressNotifyInterval(-time.Second))
+	resp, ok := <-ich
+	if !ok || !resp.Canceled || resp.Err() != rpctypes.ErrWatchInvalidProgressNotify {
+		t.Fatalf("expected %v, got canceled=%v err=%v", rpctypes.ErrWatchInvalidProgressNotify, resp.Canceled, resp.Err())
+	}
+}
+
+func TestWatchCompression(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	cluster := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer cluster.Terminate(t)
+
+	val := strings.Repeat("bar", 4096)
+	for _, codec := range []string{clientv3.WatchCompressionGzip, clientv3.WatchCompressionZstd} {
+		t.Run(codec, func(t *testing.T) {
+			client, err := integration2.NewClient(t, clientv3.Config{
+				Endpoints:        []string{cluster.Members[0].GRPCURL()},
+				WatchCompression: []string{"snappy", codec},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			wch := client.Watch(ctx, "foo", clientv3.WithCreatedNotify())
+			if resp := <-wch; !resp.Created {
+				t.Fatalf("expected the created response, got %+v", resp)
+			}
+			if _, err = client.Put(ctx, "foo", val); err != nil {
+				t.Fatal(err)
+			}
+			resp := <-wch
+			if err = resp.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Events) != 1 || string(resp.Events[0].Kv.Value) != val {
+				t.Fatalf("unexpected events %+v", resp.Events)
+			}
+
+			saved, err := cluster.Members[0].Metric("etcd_network_watch_compression_saved_bytes_total", `codec="`+codec+`"`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n, _ := strconv.ParseFloat(saved, 64); n <= 0 {
+				t.Fatalf("saved bytes = %q, want the events compressed with %s", saved, codec)
+			}
+		})
+	}
+}
@@ -22,7 +22,9 @@ package embed_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -30,11 +32,13 @@ import (
 	"testing"
 	"time"
 
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/client/pkg/v3/testutil"
 	"go.etcd.io/etcd/client/pkg/v3/transport"
 	"go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/server/v3/embed"
 	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+	"go.uber.org/zap"
 )
 
 var (
@@ -185,6 +189,384 @@ func testEmbedEtcdGracefulStop(t *testing.T, secure bool) {
 	}
 }
 
+// TestEmbedEtcdGracefulShutdownTimeout ensures that open streams do not
+// hold Close for longer than GracefulShutdownTimeout.
+func TestEmbedEtcdGracefulShutdownTimeout(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	cfg := embed.NewConfig()
+	urls := newEmbedURLs(false, 2)
+	setupEmbedCfg(cfg, []url.URL{urls[0]}, []url.URL{urls[1]})
+	cfg.Dir = filepath.Join(t.TempDir(), "embed-etcd")
+	cfg.GracefulShutdownTimeout = time.Second
+
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-e.Server.ReadyNotify()
+
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{urls[0].String()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	// open watch stream, which is in-flight on Close
+	ws, err := pb.NewWatchClient(cli.ActiveConnection()).Watch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ws.Send(&pb.WatchRequest{RequestUnion: &pb.WatchRequest_CreateRequest{
+		CreateRequest: &pb.WatchCreateRequest{Key: []byte("foo")}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ws.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	e.Close()
+	if took := time.Since(start); took > cfg.GracefulShutdownTimeout+time.Second {
+		t.Fatalf("close took %v, want at most %v", took, cfg.GracefulShutdownTimeout)
+	}
+	if err = <-e.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestEmbedEtcdReloadConfig ensures that ReloadConfig applies the log level
+// from the config file and keeps serving TLS clients after reloading certs.
+func TestEmbedEtcdReloadConfig(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	urls := newEmbedURLs(true, 2)
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "etcd.yaml")
+	writeConfig := func(level string) {
+		tlsInfo := fmt.Sprintf("{cert-file: %q, key-file: %q, trusted-ca-file: %q, client-cert-auth: true}",
+			testTLSInfo.CertFile, testTLSInfo.KeyFile, testTLSInfo.TrustedCAFile)
+		cfg := fmt.Sprintf(`name: default
+data-dir: %s
+log-level: %s
+log-outputs: [/dev/null]
+listen-client-urls: %s
+advertise-client-urls: %s
+listen-peer-urls: %s
+initial-advertise-peer-urls: %s
+initial-cluster: default=%s
+client-transport-security: %s
+peer-transport-security: %s
+`, filepath.Join(dir, "data"), level, urls[0].String(), urls[0].String(), urls[1].String(), urls[1].String(), urls[1].String(), tlsInfo, tlsInfo)
+		if err := os.WriteFile(cfgPath, []byte(cfg), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig("info")
+
+	cfg, err := embed.ConfigFromFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	<-e.Server.ReadyNotify()
+
+	if e.GetLogger().Core().Enabled(zap.DebugLevel) {
+		t.Fatal("unexpected debug logging before reload")
+	}
+
+	writeConfig("debug")
+	if err = e.ReloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if !e.GetLogger().Core().Enabled(zap.DebugLevel) {
+		t.Fatal("expected debug logging after reload")
+	}
+
+	writeConfig("verbose")
+	if err = e.ReloadConfig(); err == nil {
+		t.Fatal("expected error reloading invalid log level")
+	}
+	if !e.GetLogger().Core().Enabled(zap.DebugLevel) {
+		t.Fatal("expected failed reload to keep the log level")
+	}
+
+	tls, err := testTLSInfo.ClientConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{urls[0].String()}, TLS: tls})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err = cli.Put(ctx, "foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestEmbedEtcdPreopenedListeners ensures that etcd serves on pre-opened
+// listeners matching its listen URLs, e.g. passed by socket activation.
+func TestEmbedEtcdPreopenedListeners(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	var ls []net.Listener
+	var urls []url.URL
+	for i := 0; i < 2; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ls = append(ls, l)
+		urls = append(urls, url.URL{Scheme: "http", Host: l.Addr().String()})
+	}
+
+	cfg := embed.NewConfig()
+	setupEmbedCfg(cfg, []url.URL{urls[0]}, []url.URL{urls[1]})
+	cfg.Dir = filepath.Join(t.TempDir(), "embed-etcd")
+	cfg.Listeners = ls
+
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	<-e.Server.ReadyNotify()
+
+	if got := e.Clients[0].Addr().String(); got != urls[0].Host {
+		t.Fatalf("client listener address = %s, want %s", got, urls[0].Host)
+	}
+	if got := e.Peers[0].Addr().String(); got != urls[1].Host {
+		t.Fatalf("peer listener address = %s, want %s", got, urls[1].Host)
+	}
+
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{urls[0].String()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err = cli.Put(ctx, "foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestEmbedStartCluster ensures that StartCluster starts a multi-member
+// cluster which replicates writes between the members.
+func TestEmbedStartCluster(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	c, err := embed.StartCluster(3, embed.ClusterConfig{
+		Dir: t.TempDir(),
+		Configure: func(i int, cfg *embed.Config) {
+			cfg.LogLevel = "error"
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	urls := c.ClientURLs()
+	if len(urls) != 3 {
+		t.Fatalf("client urls = %v, want 3", urls)
+	}
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: urls[:1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	mresp, err := cli.MemberList(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mresp.Members) != 3 {
+		t.Fatalf("members = %d, want 3", len(mresp.Members))
+	}
+	if _, err = cli.Put(ctx, "foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+
+	cli2, err := integration2.NewClient(t, clientv3.Config{Endpoints: urls[2:]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli2.Close()
+	resp, err := cli2.Get(ctx, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Kvs) != 1 || string(resp.Kvs[0].Value) != "bar" {
+		t.Fatalf("unexpected response %+v", resp.Kvs)
+	}
+}
+
+// TestEmbedEtcdDataDirLock ensures that a second server on the same data
+// directory is refused and that the pid file is written and removed.
+func TestEmbedEtcdDataDirLock(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	dir := filepath.Join(t.TempDir(), "embed-etcd")
+	pidFile := filepath.Join(t.TempDir(), "etcd.pid")
+
+	cfg := embed.NewConfig()
+	urls := newEmbedURLs(false, 2)
+	setupEmbedCfg(cfg, []url.URL{urls[0]}, []url.URL{urls[1]})
+	cfg.Dir = dir
+	cfg.PidFile = pidFile
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-e.Server.ReadyNotify()
+
+	b, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("%d\n", os.Getpid()); string(b) != want {
+		t.Fatalf("pid file = %q, want %q", b, want)
+	}
+
+	cfg2 := embed.NewConfig()
+	urls2 := newEmbedURLs(false, 2)
+	setupEmbedCfg(cfg2, []url.URL{urls2[0]}, []url.URL{urls2[1]})
+	cfg2.Dir = dir
+	if _, err = embed.StartEtcd(cfg2); err == nil || !strings.Contains(err.Error(), "in use by another etcd instance") {
+		t.Fatalf("expected data-dir in use error, got %v", err)
+	}
+
+	e.Close()
+	if err = <-e.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(pidFile); !os.IsNotExist(err) {
+		t.Fatalf("expected pid file to be removed, got %v", err)
+	}
+
+	// the lock is released on Close
+	e, err = embed.StartEtcd(cfg2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Close()
+}
+
+// TestEmbedEtcdUnixSocket ensures that clients and peers can listen on unix
+// sockets with the configured file mode.
+func TestEmbedEtcdUnixSocket(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	dir := t.TempDir()
+	clientSock := filepath.Join(dir, "client.sock")
+	// peer URLs are dialed by host, so the peer socket is relative to the
+	// working directory
+	peerSock := fmt.Sprintf("localhost:%d%d", os.Getpid(), time.Now().UnixNano()%10000)
+	curl := url.URL{Scheme: "unix", Path: clientSock}
+	purl := url.URL{Scheme: "unix", Host: peerSock}
+
+	cfg := embed.NewConfig()
+	setupEmbedCfg(cfg, []url.URL{curl}, []url.URL{purl})
+	cfg.Dir = filepath.Join(dir, "embed-etcd")
+	cfg.UnixSocketMode = "0600"
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	<-e.Server.ReadyNotify()
+
+	for _, sock := range []string{clientSock, peerSock} {
+		fi, err := os.Stat(sock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode()&os.ModeSocket == 0 || fi.Mode().Perm() != 0600 {
+			t.Fatalf("%s mode = %v, want socket with %v", sock, fi.Mode(), os.FileMode(0600))
+		}
+	}
+
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{curl.String()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err = cli.Put(ctx, "foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestEmbedEtcdAuditLog ensures that mutating RPCs are written to the audit
+// log file with redacted values.
+func TestEmbedEtcdAuditLog(t *testing.T) {
+	testutil.SkipTestIfShortMode(t, "Cannot start embedded cluster in --short tests")
+
+	dir := t.TempDir()
+	auditLog := filepath.Join(dir, "audit", "audit.log")
+
+	cfg := embed.NewConfig()
+	urls := newEmbedURLs(false, 2)
+	setupEmbedCfg(cfg, []url.URL{urls[0]}, []url.URL{urls[1]})
+	cfg.Dir = filepath.Join(dir, "embed-etcd")
+	cfg.AuditLogOutput = auditLog
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-e.Server.ReadyNotify()
+
+	cli, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{urls[0].String()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err = cli.Put(ctx, "foo", "secret-value"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cli.Get(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	cli.Close()
+	e.Close()
+
+	b, err := os.ReadFile(auditLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected only the put in the audit log, got %q", b)
+	}
+	var entry struct {
+		Method string `json:"method"`
+		Result string `json:"result"`
+		Keys   []struct {
+			Key string `json:"key"`
+		} `json:"keys"`
+	}
+	if err = json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Method != "/etcdserverpb.KV/Put" || entry.Result != "ok" || len(entry.Keys) != 1 || entry.Keys[0].Key != "foo" {
+		t.Fatalf("unexpected audit entry %s", lines[0])
+	}
+	if strings.Contains(lines[0], "secret-value") {
+		t.Fatalf("value not redacted in audit entry %s", lines[0])
+	}
+}
+
 func newEmbedURLs(secure bool, n int) (urls []url.URL) {
 	scheme := "unix"
 	if secure {
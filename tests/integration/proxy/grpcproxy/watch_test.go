@@ -0,0 +1,166 @@
+// Copyright 2022 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcproxy
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/proxy/grpcproxy"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+// TestWatchProxyAuthPermission ensures the proxy creates the watches of the
+// users holding the WATCH permission on the key, not the RANGE permission.
+func TestWatchProxyAuthPermission(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	ctx := context.Background()
+	c := clus.RandClient()
+	for _, u := range []struct {
+		name string
+		perm clientv3.PermissionType
+	}{
+		{"root", 0},
+		{"alice", clientv3.PermissionType(clientv3.PermRange)},
+		{"bob", clientv3.PermissionType(clientv3.PermWatch)},
+	} {
+		if _, err := c.RoleAdd(ctx, u.name); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.UserAdd(ctx, u.name, u.name); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.UserGrantRole(ctx, u.name, u.name); err != nil {
+			t.Fatal(err)
+		}
+		if u.name == "root" {
+			continue
+		}
+		if _, err := c.RoleGrantPermission(ctx, u.name, "foo", "", u.perm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.AuthEnable(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	wpts := newWatchProxyServer([]string{clus.Members[0].GRPCURL()}, t)
+	defer wpts.close()
+
+	conn, err := grpc.Dial(wpts.l.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	wc := pb.NewWatchClient(conn)
+
+	for _, tt := range []struct {
+		name    string
+		created bool
+	}{
+		{"alice", false},
+		{"bob", true},
+	} {
+		resp, err := c.Authenticate(ctx, tt.name, tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wctx, cancel := context.WithTimeout(metadata.AppendToOutgoingContext(ctx, rpctypes.TokenFieldNameGRPC, resp.Token), 10*time.Second)
+		ws, err := wc.Watch(wctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := &pb.WatchRequest{RequestUnion: &pb.WatchRequest_CreateRequest{
+			CreateRequest: &pb.WatchCreateRequest{Key: []byte("foo")}}}
+		if err = ws.Send(req); err != nil {
+			t.Fatal(err)
+		}
+		wresp, err := ws.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+		if tt.created {
+			if !wresp.Created || wresp.Canceled {
+				t.Errorf("%s: expected the watch to be created, got %+v", tt.name, wresp)
+			}
+			continue
+		}
+		if !wresp.Canceled || !strings.Contains(wresp.CancelReason, rpctypes.ErrGRPCPermissionDenied.Error()) {
+			t.Errorf("%s: expected the watch to be denied, got %+v", tt.name, wresp)
+		}
+	}
+}
+
+type watchProxyTestServer struct {
+	c      *clientv3.Client
+	cancel context.CancelFunc
+	donec  <-chan struct{}
+	server *grpc.Server
+	l      net.Listener
+}
+
+func (wpts *watchProxyTestServer) close() {
+	wpts.server.Stop()
+	wpts.l.Close()
+	wpts.cancel()
+	<-wpts.donec
+	wpts.c.Close()
+}
+
+func newWatchProxyServer(endpoints []string, t *testing.T) *watchProxyTestServer {
+	cfg := clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: 5 * time.Second,
+		DialOptions: []grpc.DialOption{
+			grpc.WithUnaryInterceptor(grpcproxy.AuthUnaryClientInterceptor),
+			grpc.WithStreamInterceptor(grpcproxy.AuthStreamClientInterceptor),
+		},
+	}
+	client, err := integration2.NewClient(t, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wp, donec := grpcproxy.NewWatchProxy(ctx, zaptest.NewLogger(t), client)
+
+	wpts := &watchProxyTestServer{c: client, cancel: cancel, donec: donec}
+	wpts.server = grpc.NewServer()
+	pb.RegisterWatchServer(wpts.server, wp)
+
+	wpts.l, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go wpts.server.Serve(wpts.l)
+
+	return wpts
+}
@@ -352,3 +352,80 @@ func TestV3CorruptAlarmWithLeaseCorrupted(t *testing.T) {
 		}
 	}
 }
+
+// TestV3MaintenanceResetQuotaAlarm ensures the NOSPACE alarm can only be reset
+// through ResetQuotaAlarm once the backend has room below the quota.
+func TestV3MaintenanceResetQuotaAlarm(t *testing.T) {
+	integration.BeforeTest(t)
+	quotasize := int64(16 * os.Getpagesize())
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 2})
+	defer clus.Terminate(t)
+	kvc1 := integration.ToGRPC(clus.Client(1)).KV
+
+	// Set a quota on one node
+	clus.Members[0].QuotaBackendBytes = quotasize
+	clus.Members[0].Stop(t)
+	clus.Members[0].Restart(t)
+	clus.WaitMembersForLeader(t, clus.Members)
+	kvc := integration.ToGRPC(clus.Client(0)).KV
+	mt := integration.ToGRPC(clus.Client(0)).Maintenance
+	waitForRestart(t, kvc)
+
+	// grow the backend past the quota through the member without one
+	key := []byte("abc")
+	if _, err := kvc1.Put(context.TODO(), &pb.PutRequest{Key: key, Value: make([]byte, quotasize)}); err != nil {
+		t.Fatal(err)
+	}
+
+	stopc := time.After(5 * time.Second)
+	for {
+		resp, err := mt.QuotaStatus(context.TODO(), &pb.QuotaStatusRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.QuotaBackendBytes != quotasize {
+			t.Fatalf("expected quota %d, got %d", quotasize, resp.QuotaBackendBytes)
+		}
+		// wait for both the alarm and the backend commit that pushes the db past the quota
+		if len(resp.Alarms) != 0 && resp.DbSize >= quotasize {
+			if resp.Alarms[0].Alarm != pb.AlarmType_NOSPACE {
+				t.Fatalf("expected NOSPACE alarm, got %v", resp.Alarms[0].Alarm)
+			}
+			break
+		}
+		select {
+		case <-stopc:
+			t.Fatalf("timed out waiting for alarm, got alarms %v with db size %d", resp.Alarms, resp.DbSize)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	if _, err := mt.ResetQuotaAlarm(context.TODO(), &pb.ResetQuotaAlarmRequest{}); !eqErrGRPC(err, rpctypes.ErrGRPCNoSpaceNotResolved) {
+		t.Fatalf("reset got %v, expected %v", err, rpctypes.ErrGRPCNoSpaceNotResolved)
+	}
+
+	// free up space so the alarm can be reset
+	dresp, err := kvc.DeleteRange(context.TODO(), &pb.DeleteRangeRequest{Key: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = kvc.Compact(context.TODO(), &pb.CompactionRequest{Revision: dresp.Header.Revision, Physical: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = mt.Defragment(context.TODO(), &pb.DefragmentRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	rresp, err := mt.ResetQuotaAlarm(context.TODO(), &pb.ResetQuotaAlarmRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rresp.Alarms) != 0 {
+		t.Fatalf("expected no alarms after reset, got %v", rresp.Alarms)
+	}
+
+	if _, err = kvc.Put(context.TODO(), &pb.PutRequest{Key: key, Value: []byte("bar")}); err != nil {
+		t.Fatal(err)
+	}
+}
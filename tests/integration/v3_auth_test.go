@@ -15,8 +15,10 @@
 package integration
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"sync"
 	"testing"
 	"time"
@@ -26,7 +28,9 @@ import (
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.etcd.io/etcd/client/pkg/v3/testutil"
 	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/tests/v3/framework/integration"
+	"google.golang.org/grpc/metadata"
 )
 
 // TestV3AuthEmptyUserGet ensures that a get with an empty user will return an empty user error.
@@ -421,3 +425,485 @@ func TestV3AuthOldRevConcurrent(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+// TestV3AuthHomePrefix ensures relative keys of a user with a home prefix
+// are transparently scoped under the prefix.
+func TestV3AuthHomePrefix(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	api := integration.ToGRPC(clus.Client(0))
+	if _, err := api.Auth.UserAdd(context.TODO(), &pb.AuthUserAddRequest{Name: "alice", Password: "alice-123", Options: &authpb.UserAddOptions{HomePrefix: "/users/alice/"}}); err != nil {
+		t.Fatal(err)
+	}
+	authSetupRoot(t, api.Auth)
+
+	alicec, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "alice", Password: "alice-123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer alicec.Close()
+
+	if _, err := alicec.Put(context.TODO(), "config", "v1"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := alicec.Get(context.TODO(), "", clientv3.WithPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Kvs) != 1 || string(resp.Kvs[0].Key) != "/users/alice/config" {
+		t.Fatalf("expected the resolved key /users/alice/config, got %+v", resp.Kvs)
+	}
+
+	rootc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "root", Password: "123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer rootc.Close()
+
+	resp, err = rootc.Get(context.TODO(), "/users/alice/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Kvs) != 1 || string(resp.Kvs[0].Value) != "v1" {
+		t.Fatalf("expected value v1 under the home prefix, got %+v", resp.Kvs)
+	}
+
+	// absolute keys are not scoped and require an explicit permission
+	if _, err = alicec.Put(context.TODO(), "/config", "v1"); !eqErrGRPC(err, rpctypes.ErrGRPCPermissionDenied) {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrGRPCPermissionDenied, err)
+	}
+}
+
+type authorizerFunc func(ctx context.Context, r *auth.AuthorizationRequest) (*auth.AuthorizationDecision, error)
+
+func (f authorizerFunc) Authorize(ctx context.Context, r *auth.AuthorizationRequest) (*auth.AuthorizationDecision, error) {
+	return f(ctx, r)
+}
+
+// TestV3AuthAuthorizer ensures that the external authorizer denies and
+// constrains the requests of the authenticated users.
+func TestV3AuthAuthorizer(t *testing.T) {
+	integration.BeforeTest(t)
+
+	var mu sync.Mutex
+	users := make(map[string]struct{})
+	authz := authorizerFunc(func(ctx context.Context, r *auth.AuthorizationRequest) (*auth.AuthorizationDecision, error) {
+		mu.Lock()
+		users[r.User] = struct{}{}
+		mu.Unlock()
+		for _, kr := range r.Keys {
+			if bytes.HasPrefix(kr.Key, []byte("/secret")) {
+				return &auth.AuthorizationDecision{Reason: "secret"}, nil
+			}
+		}
+		return &auth.AuthorizationDecision{Allowed: true, Limit: 2}, nil
+	})
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1, Authorizer: authz})
+	defer clus.Terminate(t)
+
+	api := integration.ToGRPC(clus.Client(0))
+	if _, err := api.Auth.UserAdd(context.TODO(), &pb.AuthUserAddRequest{Name: "alice", Password: "alice-123", Options: &authpb.UserAddOptions{HomePrefix: "/secret/alice/"}}); err != nil {
+		t.Fatal(err)
+	}
+	authSetupRoot(t, api.Auth)
+
+	c, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "root", Password: "123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+	for _, k := range []string{"a", "b", "c"} {
+		if _, err := c.Put(ctx, k, "v"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.Put(ctx, "/secret/a", "v"); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	_, err := c.Txn(ctx).Then(clientv3.OpPut("d", "v"), clientv3.OpDelete("/secret/", clientv3.WithPrefix())).Commit()
+	if err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+
+	if _, err = c.Move(ctx, "a", "/secret/a"); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	if _, err = c.Move(ctx, "/secret/", "e/", clientv3.WithPrefix()); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	if _, err = c.SetLease(ctx, "/secret/", clientv3.NoLease, clientv3.WithPrefix()); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	if _, err = c.Increment(ctx, "/secret/ctr", 1); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	if _, err = c.PutIfAbsent(ctx, "/secret/b", "v"); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	if _, err = c.GetAndDelete(ctx, "/secret/a"); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	if _, err = c.BulkWrite(ctx, clientv3.OpPut("e", "v"), clientv3.OpDelete("/secret/", clientv3.WithPrefix())); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+
+	// the authorizer sees the keys resolved under the home prefix
+	alicec, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "alice", Password: "alice-123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer alicec.Close()
+	if _, err = alicec.Put(ctx, "config", "v"); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+
+	resp, err := c.Get(ctx, "", clientv3.WithFromKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Kvs) != 2 || !resp.More {
+		t.Fatalf("expected 2 keys with more, got %d keys with more %v", len(resp.Kvs), resp.More)
+	}
+
+	wch := c.Watch(ctx, "/secret/", clientv3.WithPrefix())
+	wresp := <-wch
+	if !wresp.Canceled {
+		t.Fatalf("expected the watch to be canceled, got %+v", wresp)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := users["root"]; !ok {
+		t.Fatalf("expected requests of root to be authorized, got users %v", users)
+	}
+}
+
+// TestV3AuthWatchIfCompares ensures the keys compared by a watch create
+// request are read with the READ permission, the WATCH permission allowing to
+// watch them only.
+func TestV3AuthWatchIfCompares(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	api := integration.ToGRPC(clus.Client(0))
+	authSetupUsers(t, api.Auth, []user{{name: "alice", password: "alice-123", role: "watcher"}})
+	perm := &authpb.Permission{PermType: authpb.WATCH, Key: []byte("a"), RangeEnd: []byte("c")}
+	if _, err := api.Auth.RoleGrantPermission(context.TODO(), &pb.AuthRoleGrantPermissionRequest{Name: "watcher", Perm: perm}); err != nil {
+		t.Fatal(err)
+	}
+	authSetupRoot(t, api.Auth)
+
+	c, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "alice", Password: "alice-123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+	cmp := clientv3.Compare(clientv3.Version("b"), "=", 0)
+	if wresp := <-c.WatchIf(ctx, []clientv3.Cmp{cmp}, "a"); !wresp.Canceled {
+		t.Fatalf("expected the watch to be canceled, got %+v", wresp)
+	}
+
+	rootc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "root", Password: "123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer rootc.Close()
+	if _, err := rootc.RoleGrantPermission(ctx, "watcher", "b", "", clientv3.PermissionType(clientv3.PermRange)); err != nil {
+		t.Fatal(err)
+	}
+	if wresp := <-c.WatchIf(ctx, []clientv3.Cmp{cmp}, "a", clientv3.WithCreatedNotify()); !wresp.Created || wresp.Err() != nil {
+		t.Fatalf("expected the watch to be created, got %+v (%v)", wresp, wresp.Err())
+	}
+}
+
+// TestV3AuthAdminPermissions ensures that the admin permissions granted to a
+// role allow its users the administration operations they cover only.
+func TestV3AuthLockout(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{
+		Size:        1,
+		AuthLockout: auth.LockoutPolicy{Threshold: 2, Duration: time.Minute},
+	})
+	defer clus.Terminate(t)
+
+	authc := integration.ToGRPC(clus.Client(0)).Auth
+	authSetupRoot(t, authc)
+
+	// authenticated before the lockout
+	c, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "root", Password: "123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		if _, err := authc.Authenticate(ctx, &pb.AuthenticateRequest{Name: "root", Password: "wrong"}); !eqErrGRPC(err, rpctypes.ErrGRPCAuthFailed) {
+			t.Fatalf("#%d: expected %v, got %v", i, rpctypes.ErrGRPCAuthFailed, err)
+		}
+	}
+	// locked out even with the right password
+	if _, err := authc.Authenticate(ctx, &pb.AuthenticateRequest{Name: "root", Password: "123"}); !eqErrGRPC(err, rpctypes.ErrGRPCAuthLockedOut) {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrGRPCAuthLockedOut, err)
+	}
+
+	if _, err := authc.AuthLockoutList(ctx, &pb.AuthLockoutListRequest{}); err == nil {
+		t.Fatal("expected listing the lockouts without authentication to fail")
+	}
+	lresp, err := c.Auth.AuthLockoutList(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lresp.Lockouts) != 2 || lresp.Lockouts[0].User != "root" || lresp.Lockouts[1].Address == "" {
+		t.Fatalf("expected lockouts of root and of its address, got %+v", lresp.Lockouts)
+	}
+	cresp, err := c.Auth.AuthLockoutClear(ctx, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cresp.Cleared != 2 {
+		t.Fatalf("expected 2 lockouts cleared, got %d", cresp.Cleared)
+	}
+	if _, err = authc.Authenticate(ctx, &pb.AuthenticateRequest{Name: "root", Password: "123"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestV3AuthSessionRevoke(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	authc := integration.ToGRPC(clus.Client(0)).Auth
+	authSetupUsers(t, authc, []user{{name: "alice", password: "123", role: "alice", key: "foo"}})
+	authSetupRoot(t, authc)
+
+	rootc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(2).Endpoints(), Username: "root", Password: "123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer rootc.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+	var tokens []string
+	for i := 0; i < 2; i++ {
+		resp, err := authc.Authenticate(ctx, &pb.AuthenticateRequest{Name: "alice", Password: "123"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens = append(tokens, resp.Token)
+	}
+	// the sessions are applied on all the members, once the linearizable read
+	// catches up with the authentications
+	if _, err := rootc.Get(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	lresp, err := rootc.Auth.AuthSessionList(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lresp.Sessions) != 2 || lresp.Sessions[0].User != "alice" {
+		t.Fatalf("expected 2 sessions of alice, got %+v", lresp.Sessions)
+	}
+
+	authInfo := func(token string) error {
+		tctx := metadata.NewIncomingContext(ctx, metadata.Pairs(rpctypes.TokenFieldNameGRPC, token))
+		_, aerr := clus.Members[2].Server.AuthStore().AuthInfoFromCtx(tctx)
+		return aerr
+	}
+	rresp, err := rootc.Auth.AuthSessionRevoke(ctx, lresp.Sessions[0].ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rresp.Revoked != 1 {
+		t.Fatalf("expected 1 session revoked, got %d", rresp.Revoked)
+	}
+	if err = authInfo(tokens[0]); err != auth.ErrInvalidAuthToken {
+		t.Fatalf("expected %v, got %v", auth.ErrInvalidAuthToken, err)
+	}
+	if err = authInfo(tokens[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = authc.AuthSessionRevoke(ctx, &pb.AuthSessionRevokeRequest{User: "alice"}); err == nil {
+		t.Fatal("expected revoking sessions without authentication to fail")
+	}
+	if _, err = rootc.Auth.AuthSessionRevoke(ctx, 0, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err = authInfo(tokens[1]); err != auth.ErrInvalidAuthToken {
+		t.Fatalf("expected %v, got %v", auth.ErrInvalidAuthToken, err)
+	}
+}
+
+func TestV3AuthPasswordPolicy(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{
+		Size: 1,
+		AuthPasswordPolicy: auth.PasswordPolicy{
+			Hash:          auth.PasswordHashArgon2id,
+			Argon2Time:    1,
+			Argon2Memory:  64,
+			Argon2Threads: 1,
+			MinLength:     3,
+		},
+	})
+	defer clus.Terminate(t)
+
+	authc := integration.ToGRPC(clus.Client(0)).Auth
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+	if _, err := authc.UserAdd(ctx, &pb.AuthUserAddRequest{Name: "short", Password: "12"}); !eqErrGRPC(err, rpctypes.ErrGRPCPasswordTooShort) {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrGRPCPasswordTooShort, err)
+	}
+	authSetupRoot(t, authc)
+
+	// the password hashed by argon2id authenticates
+	if _, err := authc.Authenticate(ctx, &pb.AuthenticateRequest{Name: "root", Password: "123"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authc.Authenticate(ctx, &pb.AuthenticateRequest{Name: "root", Password: "321"}); !eqErrGRPC(err, rpctypes.ErrGRPCAuthFailed) {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrGRPCAuthFailed, err)
+	}
+}
+
+func TestV3AuthAdminPermissions(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	authSetupUsers(t, integration.ToGRPC(clus.Client(0)).Auth, []user{{name: "backup", password: "123", role: "backup-operator"}})
+	authSetupRoot(t, integration.ToGRPC(clus.Client(0)).Auth)
+
+	rootc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "root", Password: "123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer rootc.Close()
+	if _, err := rootc.RoleGrantAdminPermission(context.TODO(), "backup-operator", clientv3.AdminPermSnapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	c, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "backup", Password: "123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+	rc, err := c.Snapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = io.Copy(io.Discard, rc); err != nil {
+		t.Fatalf("expected snapshot to be permitted, got %v", err)
+	}
+	rc.Close()
+
+	if _, err = c.Defragment(ctx, clus.Members[0].GRPCURL()); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	if _, err = c.AlarmDisarm(ctx, &clientv3.AlarmMember{MemberID: uint64(clus.Members[0].ID()), Alarm: pb.AlarmType_NOSPACE}); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	if _, err = c.MemberAddAsLearner(ctx, []string{"http://127.0.0.1:1"}); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+	if _, err = c.UserAdd(ctx, "eve", "123"); err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+
+	if _, err = rootc.RoleGrantAdminPermission(ctx, "backup-operator", clientv3.AdminPermDefragment, clientv3.AdminPermAlarm); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.Defragment(ctx, clus.Members[0].GRPCURL()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.AlarmDisarm(ctx, &clientv3.AlarmMember{MemberID: uint64(clus.Members[0].ID()), Alarm: pb.AlarmType_NOSPACE}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = rootc.RoleRevokeAdminPermission(ctx, "backup-operator", clientv3.AdminPermSnapshot); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rootc.RoleGet(ctx, "backup-operator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.AdminPerms) != 2 || resp.AdminPerms[0] != authpb.ALARM || resp.AdminPerms[1] != authpb.DEFRAGMENT {
+		t.Fatalf("expected ALARM and DEFRAGMENT, got %v", resp.AdminPerms)
+	}
+	if rc, err = c.Snapshot(ctx); err == nil {
+		_, err = io.Copy(io.Discard, rc)
+		rc.Close()
+	}
+	if err != rpctypes.ErrPermissionDenied {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrPermissionDenied, err)
+	}
+}
+
+func TestV3AuthUserGrantRoleUntil(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	authSetupUsers(t, integration.ToGRPC(clus.Client(0)).Auth, []user{{name: "user1", password: "123", role: "role1", key: "foo"}})
+	authSetupRoot(t, integration.ToGRPC(clus.Client(0)).Auth)
+
+	rootc, cerr := integration.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "root", Password: "123"})
+	if cerr != nil {
+		t.Fatal(cerr)
+	}
+	defer rootc.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
+	defer cancel()
+	if _, err := rootc.RoleAdd(ctx, "break-glass"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rootc.UserGrantRoleUntil(ctx, "user1", "break-glass", time.Now().Add(-time.Second)); err != rpctypes.ErrRoleGrantExpired {
+		t.Fatalf("expected %v, got %v", rpctypes.ErrRoleGrantExpired, err)
+	}
+	expiresAt := time.Now().Add(2 * time.Second)
+	if _, err := rootc.UserGrantRoleUntil(ctx, "user1", "break-glass", expiresAt); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rootc.UserGet(ctx, "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.RoleExpirations) != 1 || resp.RoleExpirations[0].Role != "break-glass" || resp.RoleExpirations[0].ExpiresAt != expiresAt.Unix() {
+		t.Fatalf("unexpected role expirations %v", resp.RoleExpirations)
+	}
+
+	// the leader revokes the role once expired
+	for {
+		resp, err = rootc.UserGet(ctx, "user1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Roles) == 1 && len(resp.RoleExpirations) == 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("expected the role to be revoked, got %v", resp.Roles)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	if resp.Roles[0] != "role1" {
+		t.Fatalf("expected role1 to remain granted, got %v", resp.Roles)
+	}
+}
@@ -29,11 +29,13 @@ import (
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.etcd.io/etcd/client/pkg/v3/transport"
 	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/storage/schema"
 	"go.etcd.io/etcd/tests/v3/framework/integration"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
 	"google.golang.org/grpc/status"
 )
 
@@ -1285,6 +1287,18 @@ func TestV3StorageQuotaAPI(t *testing.T) {
 	if !eqErrGRPC(txnerr, rpctypes.ErrGRPCNoSpace) {
 		t.Fatalf("big txn got %v, expected %v", err, rpctypes.ErrGRPCNoSpace)
 	}
+
+	// test increment of a big key
+	_, err = kvc.Increment(context.TODO(), &pb.IncrementRequest{Key: bytes.Repeat([]byte("k"), int(quotasize)), Delta: 1})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCNoSpace) {
+		t.Fatalf("big increment got %v, expected %v", err, rpctypes.ErrGRPCNoSpace)
+	}
+
+	// test big put if absent
+	_, err = kvc.PutIfAbsent(context.TODO(), &pb.PutIfAbsentRequest{Key: []byte("def"), Value: bigbuf})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCNoSpace) {
+		t.Fatalf("big put if absent got %v, expected %v", err, rpctypes.ErrGRPCNoSpace)
+	}
 }
 
 func TestV3RangeRequest(t *testing.T) {
@@ -1927,6 +1941,153 @@ func TestV3LargeRequests(t *testing.T) {
 	}
 }
 
+// TestV3ChunkedPut ensures the puts larger than the maximum request size are
+// proposed in chunks when enabled, and still accounted against the quotas.
+func TestV3ChunkedPut(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3, MaxRequestBytes: 64 * 1024, MaxChunkedValueBytes: 1024 * 1024})
+	defer clus.Terminate(t)
+	kvcli := integration.ToGRPC(clus.RandClient()).KV
+
+	value := make([]byte, 300*1024)
+	rand.Read(value)
+	if _, err := kvcli.Put(context.TODO(), &pb.PutRequest{Key: []byte("foo"), Value: value}); err != nil {
+		t.Fatal(err)
+	}
+	for i := range clus.Members {
+		resp, err := integration.ToGRPC(clus.Client(i)).KV.Range(context.TODO(), &pb.RangeRequest{Key: []byte("foo")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Kvs) != 1 || !bytes.Equal(resp.Kvs[0].Value, value) {
+			t.Errorf("#%d: value of %d keys differs from the put value", i, len(resp.Kvs))
+		}
+	}
+
+	_, err := kvcli.Put(context.TODO(), &pb.PutRequest{Key: []byte("foo"), Value: make([]byte, 1024*1024+1)})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCRequestTooLarge) {
+		t.Errorf("expected %v, got %v", rpctypes.ErrGRPCRequestTooLarge, err)
+	}
+
+	if _, err = clus.RandClient().PrefixQuotaSet(context.TODO(), "quota/", 100*1024, 0); err != nil {
+		t.Fatal(err)
+	}
+	_, err = kvcli.Put(context.TODO(), &pb.PutRequest{Key: []byte("quota/foo"), Value: value})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCPrefixQuotaExceeded) {
+		t.Errorf("expected %v, got %v", rpctypes.ErrGRPCPrefixQuotaExceeded, err)
+	}
+
+	// the chunks of both puts are deleted once they are applied
+	for i, m := range clus.Members {
+		// a linearizable read waits for the member to apply the puts
+		if _, err = integration.ToGRPC(clus.Client(i)).KV.Range(context.TODO(), &pb.RangeRequest{Key: []byte("foo")}); err != nil {
+			t.Fatal(err)
+		}
+		tx := m.Server.Backend().BatchTx()
+		tx.LockOutsideApply()
+		ks, _ := tx.UnsafeRange(schema.PutChunk, []byte{0}, []byte{0xff}, 0)
+		tx.Unlock()
+		if len(ks) != 0 {
+			t.Errorf("#%d: %d chunks left", i, len(ks))
+		}
+	}
+}
+
+// TestV3PutTTL ensures the keys put with a ttl are deleted on every member
+// once expired, and only them.
+func TestV3PutTTL(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+	kvcli := integration.ToGRPC(clus.RandClient()).KV
+
+	_, err := kvcli.Put(context.TODO(), &pb.PutRequest{Key: []byte("foo"), Value: []byte("bar"), Ttl: -1})
+	if !eqErrGRPC(err, rpctypes.ErrGRPCInvalidKeyTTL) {
+		t.Errorf("expected %v, got %v", rpctypes.ErrGRPCInvalidKeyTTL, err)
+	}
+
+	if _, err = kvcli.Put(context.TODO(), &pb.PutRequest{Key: []byte("foo"), Value: []byte("bar"), Ttl: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = kvcli.Put(context.TODO(), &pb.PutRequest{Key: []byte("bar"), Value: []byte("foo"), Ttl: 1}); err != nil {
+		t.Fatal(err)
+	}
+	// put again without a ttl, bar does not expire anymore
+	if _, err = kvcli.Put(context.TODO(), &pb.PutRequest{Key: []byte("bar"), Value: []byte("foo")}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := kvcli.Range(context.TODO(), &pb.RangeRequest{Key: []byte("foo")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Kvs) != 1 || resp.Kvs[0].ExpireTime == 0 {
+		t.Fatalf("foo = %v, want a key with an expire time", resp.Kvs)
+	}
+
+	time.Sleep(3 * time.Second)
+	for i := range clus.Members {
+		resp, err = integration.ToGRPC(clus.Client(i)).KV.Range(context.TODO(), &pb.RangeRequest{Key: []byte("bar"), RangeEnd: []byte("fop")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Kvs) != 1 || string(resp.Kvs[0].Key) != "bar" {
+			t.Errorf("#%d: keys = %v, want bar only", i, resp.Kvs)
+		}
+	}
+}
+
+// TestV3GRPCReflection ensures the gRPC reflection service lists and
+// describes the etcd services only if enabled.
+func TestV3GRPCReflection(t *testing.T) {
+	integration.BeforeTest(t)
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enabled=%v", enabled), func(t *testing.T) {
+			clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1, EnableGRPCReflection: enabled})
+			defer clus.Terminate(t)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			stream, err := rpb.NewServerReflectionClient(clus.Client(0).ActiveConnection()).ServerReflectionInfo(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = stream.Send(&rpb.ServerReflectionRequest{MessageRequest: &rpb.ServerReflectionRequest_ListServices{}}); err != nil {
+				t.Fatal(err)
+			}
+			resp, err := stream.Recv()
+			if !enabled {
+				if status.Code(err) != codes.Unimplemented {
+					t.Fatalf("expected Unimplemented error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var services []string
+			for _, svc := range resp.GetListServicesResponse().GetService() {
+				services = append(services, svc.Name)
+			}
+			for _, want := range []string{"etcdserverpb.KV", "etcdserverpb.Maintenance", "grpc.health.v1.Health"} {
+				if !strings.Contains(strings.Join(services, ","), want) {
+					t.Errorf("expected service %q to be listed, got %v", want, services)
+				}
+			}
+
+			req := &rpb.ServerReflectionRequest{MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "etcdserverpb.KV"}}
+			if err = stream.Send(req); err != nil {
+				t.Fatal(err)
+			}
+			if resp, err = stream.Recv(); err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.GetFileDescriptorResponse().GetFileDescriptorProto()) == 0 {
+				t.Errorf("expected the file descriptor of etcdserverpb.KV, got %v", resp)
+			}
+		})
+	}
+}
+
 func eqErrGRPC(err1 error, err2 error) bool {
 	return !(err1 == nil && err2 != nil) || err1.Error() == err2.Error()
 }
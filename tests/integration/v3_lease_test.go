@@ -19,6 +19,8 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
@@ -314,6 +316,190 @@ func TestV3LeaseKeepAlive(t *testing.T) {
 	})
 }
 
+// TestV3LeaseKeepAliveBatch ensures many leases can be renewed by a single request
+// through every member, and that unknown leases are reported with a non-positive TTL.
+func TestV3LeaseKeepAliveBatch(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		lresp, err := integration.ToGRPC(clus.RandClient()).Lease.LeaseGrant(context.TODO(), &pb.LeaseGrantRequest{TTL: 30})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, lresp.ID)
+	}
+	ids = append(ids, 12345)
+
+	for i := range clus.Members {
+		ctx, cancel := context.WithCancel(context.Background())
+		lac, err := integration.ToGRPC(clus.Client(i)).Lease.LeaseKeepAliveBatch(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = lac.Send(&pb.LeaseKeepAliveBatchRequest{IDs: ids}); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := lac.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+
+		if resp.Header == nil {
+			t.Fatalf("#%d: expected response header", i)
+		}
+		if len(resp.Responses) != len(ids) {
+			t.Fatalf("#%d: expected %d responses, got %d", i, len(ids), len(resp.Responses))
+		}
+		for j, r := range resp.Responses {
+			if r.ID != ids[j] {
+				t.Errorf("#%d.%d: expected lease ID %v, got %v", i, j, ids[j], r.ID)
+			}
+			if j < 3 && r.TTL != 30 {
+				t.Errorf("#%d.%d: expected TTL 30, got %d", i, j, r.TTL)
+			}
+		}
+		if ttl := resp.Responses[3].TTL; ttl > 0 {
+			t.Errorf("#%d: expected non-positive TTL for unknown lease, got %d", i, ttl)
+		}
+	}
+}
+
+// TestV3LeaseKeepAliveBatchFollower ensures a follower forwards a batch of
+// renewals to the leader in a single request.
+func TestV3LeaseKeepAliveBatchFollower(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	lead := clus.WaitLeader(t)
+	follower := (lead + 1) % 3
+
+	greqs := make([]*pb.LeaseGrantRequest, 100)
+	for i := range greqs {
+		greqs[i] = &pb.LeaseGrantRequest{TTL: 30}
+	}
+	gresp, err := integration.ToGRPC(clus.Client(lead)).Lease.LeaseGrantBulk(context.TODO(), &pb.LeaseGrantBulkRequest{Leases: greqs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]int64, len(gresp.Leases))
+	for i, r := range gresp.Leases {
+		ids[i] = r.ID
+	}
+
+	// the batches renewed by the leader are observed by the histogram
+	metric := func(name string) float64 {
+		v, err := clus.Members[lead].Metric(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	count, sum := metric("etcd_debugging_lease_renew_batch_size_count"), metric("etcd_debugging_lease_renew_batch_size_sum")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lac, err := integration.ToGRPC(clus.Client(follower)).Lease.LeaseKeepAliveBatch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = lac.Send(&pb.LeaseKeepAliveBatchRequest{IDs: ids}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := lac.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range resp.Responses {
+		if r.ID != ids[i] || r.TTL != 30 {
+			t.Errorf("#%d: got lease %v with TTL %d, want lease %v with TTL 30", i, r.ID, r.TTL, ids[i])
+		}
+	}
+
+	count, sum = metric("etcd_debugging_lease_renew_batch_size_count")-count, metric("etcd_debugging_lease_renew_batch_size_sum")-sum
+	if count != 1 || sum != float64(len(ids)) {
+		t.Errorf("leader renewed %v batches of %v leases, want 1 batch of %d leases", count, sum, len(ids))
+	}
+}
+
+// TestV3LeaseBulk ensures many leases can be granted and revoked by a single request,
+// the keys attached to the leases revoked being deleted.
+func TestV3LeaseBulk(t *testing.T) {
+	integration.BeforeTest(t)
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	lc := integration.ToGRPC(clus.RandClient()).Lease
+	gresp, err := lc.LeaseGrantBulk(context.TODO(), &pb.LeaseGrantBulkRequest{Leases: []*pb.LeaseGrantRequest{
+		{TTL: 30}, {TTL: 60}, {TTL: 30, ID: 1234},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gresp.Leases) != 3 {
+		t.Fatalf("expected 3 leases, got %d", len(gresp.Leases))
+	}
+	var ids []int64
+	for i, wttl := range []int64{30, 60, 30} {
+		if l := gresp.Leases[i]; l.Error != "" || l.ID == 0 || l.TTL != wttl {
+			t.Fatalf("#%d: unexpected lease %+v", i, l)
+		}
+		ids = append(ids, gresp.Leases[i].ID)
+	}
+	if ids[2] != 1234 {
+		t.Fatalf("expected lease ID 1234, got %d", ids[2])
+	}
+
+	// granting a lease already granted fails that lease only
+	gresp, err = lc.LeaseGrantBulk(context.TODO(), &pb.LeaseGrantBulkRequest{Leases: []*pb.LeaseGrantRequest{
+		{TTL: 30, ID: 1234}, {TTL: 30},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gresp.Leases[0].Error == "" {
+		t.Errorf("expected an error granting lease %d twice", ids[2])
+	}
+	if gresp.Leases[1].Error != "" {
+		t.Errorf("unexpected error %q", gresp.Leases[1].Error)
+	}
+	ids = append(ids, gresp.Leases[1].ID)
+
+	kvc := integration.ToGRPC(clus.RandClient()).KV
+	for i, id := range ids {
+		if _, err = kvc.Put(context.TODO(), &pb.PutRequest{Key: []byte(fmt.Sprintf("foo%d", i)), Lease: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rresp, err := lc.LeaseRevokeBulk(context.TODO(), &pb.LeaseRevokeBulkRequest{IDs: append(ids[:3:3], 5678)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rresp.Revoked, ids[:3]) {
+		t.Errorf("expected revoked %v, got %v", ids[:3], rresp.Revoked)
+	}
+	if !reflect.DeepEqual(rresp.NotFound, []int64{5678}) {
+		t.Errorf("expected not found [5678], got %v", rresp.NotFound)
+	}
+
+	rr, err := kvc.Range(context.TODO(), &pb.RangeRequest{Key: []byte("foo"), RangeEnd: []byte("fop")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rr.Kvs) != 1 || string(rr.Kvs[0].Key) != "foo3" {
+		t.Errorf("expected only foo3 left, got %v", rr.Kvs)
+	}
+}
+
 // TestV3LeaseCheckpoint ensures a lease checkpoint results in a remaining TTL being persisted
 // across leader elections.
 func TestV3LeaseCheckpoint(t *testing.T) {
@@ -26,6 +26,7 @@ import (
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3rpc"
 	"go.etcd.io/etcd/tests/v3/framework/integration"
 )
@@ -1290,3 +1291,53 @@ func TestV3WatchCloseCancelRace(t *testing.T) {
 		t.Fatalf("expected %s watch, got %s", expected, minWatches)
 	}
 }
+
+// TestV3WatchDraining ensures that a draining server rejects new watch
+// streams while serving the existing ones.
+func TestV3WatchDraining(t *testing.T) {
+	integration.BeforeTest(t)
+	if integration.ThroughProxy {
+		t.Skip("grpc proxy does not drain with the server")
+	}
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	wapi := integration.ToGRPC(clus.Client(0)).Watch
+	ws, err := wapi.Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &pb.WatchRequest{RequestUnion: &pb.WatchRequest_CreateRequest{
+		CreateRequest: &pb.WatchCreateRequest{Key: []byte("foo")}}}
+	if err = ws.Send(req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ws.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	clus.Members[0].Server.Drain()
+
+	ws2, err := wapi.Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ws2.Recv(); !eqErrGRPC(err, rpctypes.ErrGRPCStopped) {
+		t.Fatalf("expected %v on new watch stream, got %v", rpctypes.ErrGRPCStopped, err)
+	}
+
+	kvc := integration.ToGRPC(clus.Client(0)).KV
+	if _, err = kvc.Put(ctx, &pb.PutRequest{Key: []byte("foo"), Value: []byte("bar")}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := ws.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Events) != 1 || string(resp.Events[0].Kv.Key) != "foo" {
+		t.Fatalf("unexpected watch response %+v", resp)
+	}
+}
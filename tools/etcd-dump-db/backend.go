@@ -26,6 +26,7 @@ import (
 	"go.etcd.io/etcd/api/v3/mvccpb"
 	"go.etcd.io/etcd/server/v3/lease/leasepb"
 	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
 
 	bolt "go.etcd.io/bbolt"
 )
@@ -83,7 +84,7 @@ func defaultDecoder(k, v []byte) {
 func keyDecoder(k, v []byte) {
 	rev := bytesToRev(k)
 	var kv mvccpb.KeyValue
-	if err := kv.Unmarshal(v); err != nil {
+	if err := mvcc.UnmarshalKeyValue(v, &kv); err != nil {
 		panic(err)
 	}
 	fmt.Printf("rev=%+v, value=[key %q | val %q | created %d | mod %d | ver %d]\n", rev, string(kv.Key), string(kv.Value), kv.CreateRevision, kv.ModRevision, kv.Version)